@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config describes a workload and the erebusd it runs against
+type Config struct {
+	Addr                string // Base URL of erebusd
+	Tenants             int
+	TenantPrefix        string // Tenants are named <prefix>-<n>
+	Duration            time.Duration
+	Concurrency         int     // Requests in flight
+	Rate                float64 // Requests per second across all workers; unlimited if 0
+	Mix                 Mix
+	SeedAtoms           int // Concepts created in each tenant before the run
+	MinAtoms            int // Concepts deletes leave in each tenant
+	InferenceIterations int
+	Header              http.Header // Sent with every request, e.g. the API key
+	Timeout             time.Duration
+	Cleanup             bool      // Purge the tenants' data after the run
+	Progress            io.Writer // Receives a line per ProgressInterval; none if nil
+	ProgressInterval    time.Duration
+	Seed                int64
+}
+
+// generator runs a workload
+type generator struct {
+	cfg     Config
+	client  *http.Client
+	tenants []*tenant
+	rec     *recorder
+
+	// Pacing of requests when the rate is limited
+	next   time.Time
+	paceMu sync.Mutex
+}
+
+// Run initializes and seeds the tenants, then runs the workload until its
+// duration elapses or ctx is done, and reports the requests of the run.
+// Setup requests are not reported.
+func Run(ctx context.Context, cfg Config) (Report, error) {
+	g := &generator{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		rec:    newRecorder(),
+	}
+	if err := g.setUp(ctx); err != nil {
+		return Report{}, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+	started := time.Now()
+	g.next = started
+
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			g.work(runCtx, rand.New(rand.NewSource(seed)))
+		}(cfg.Seed + int64(i) + 1)
+	}
+	progressDone := make(chan struct{})
+	go func() {
+		defer close(progressDone)
+		g.progress(runCtx, started)
+	}()
+	wg.Wait()
+	<-progressDone
+
+	report := g.rec.report(len(g.tenants), time.Since(started))
+	for _, t := range g.tenants {
+		report.Atoms += t.size()
+	}
+	if cfg.Cleanup {
+		if err := g.cleanUp(); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// setUp initializes the tenants not initialized by an earlier run and
+// creates their seed concepts
+func (g *generator) setUp(ctx context.Context) error {
+	var listed struct {
+		Tenants []string `json:"tenants"`
+	}
+	if _, err := g.expect(ctx, http.MethodGet, "/tenants", nil, &listed); err != nil {
+		return fmt.Errorf("listing tenants failed: %w", err)
+	}
+	initialized := make(map[string]bool, len(listed.Tenants))
+	for _, id := range listed.Tenants {
+		initialized[id] = true
+	}
+
+	// Concept names are unique to the run, so runs can share tenants
+	run := fmt.Sprintf("lg%x", time.Now().UnixNano()&0xffffff)
+	for i := 0; i < g.cfg.Tenants; i++ {
+		t := &tenant{id: fmt.Sprintf("%s-%d", g.cfg.TenantPrefix, i), run: run}
+		if !initialized[t.id] {
+			if _, err := g.expect(ctx, http.MethodPost, "/tenants/"+url.PathEscape(t.id)+"/init", nil, nil); err != nil {
+				return fmt.Errorf("initializing tenant %s failed: %w", t.id, err)
+			}
+		}
+		for j := 0; j < g.cfg.SeedAtoms; j++ {
+			var created struct {
+				AtomID string `json:"atom_id"`
+			}
+			name := t.name()
+			if _, err := g.expect(ctx, http.MethodPost, g.tenantPath(t, "/concepts"), map[string]string{"name": name}, &created); err != nil {
+				return fmt.Errorf("seeding tenant %s failed: %w", t.id, err)
+			}
+			t.add(created.AtomID, name)
+		}
+		g.tenants = append(g.tenants, t)
+	}
+	return nil
+}
+
+// cleanUp purges the data of the tenants
+func (g *generator) cleanUp() error {
+	for _, t := range g.tenants {
+		path := g.tenantPath(t, "/data") + "?confirm=" + url.QueryEscape(t.id)
+		if _, err := g.expect(context.Background(), http.MethodDelete, path, nil, nil); err != nil {
+			return fmt.Errorf("purging tenant %s failed: %w", t.id, err)
+		}
+	}
+	return nil
+}
+
+// work runs operations until ctx is done
+func (g *generator) work(ctx context.Context, r *rand.Rand) {
+	for g.pace(ctx) {
+		g.run(ctx, g.cfg.Mix.Pick(r), g.tenants[r.Intn(len(g.tenants))], r)
+	}
+}
+
+// pace waits for the next request the rate allows and reports whether the
+// run goes on
+func (g *generator) pace(ctx context.Context) bool {
+	if g.cfg.Rate <= 0 {
+		return ctx.Err() == nil
+	}
+	g.paceMu.Lock()
+	at := g.next
+	if now := time.Now(); at.Before(now) {
+		at = now
+	}
+	g.next = at.Add(time.Duration(float64(time.Second) / g.cfg.Rate))
+	g.paceMu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// run sends the request of an operation and records its outcome
+func (g *generator) run(ctx context.Context, op Op, t *tenant, r *rand.Rand) {
+	var method, path string
+	var body interface{}
+	var onSuccess func(response []byte)
+
+	switch op {
+	case OpCreate:
+		name := t.name()
+		method, path, body = http.MethodPost, g.tenantPath(t, "/concepts"), map[string]string{"name": name}
+		onSuccess = func(response []byte) {
+			var created struct {
+				AtomID string `json:"atom_id"`
+			}
+			if json.Unmarshal(response, &created) == nil && created.AtomID != "" {
+				t.add(created.AtomID, name)
+			}
+		}
+	case OpLink:
+		source, _, ok := t.pick(r)
+		target, _, _ := t.pick(r)
+		if !ok || source == target {
+			g.rec.skip(op)
+			return
+		}
+		method, path = http.MethodPost, g.tenantPath(t, "/links/inheritance")
+		body = map[string]string{"source_id": source, "target_id": target}
+	case OpUpdate:
+		atomID, _, ok := t.pick(r)
+		if !ok {
+			g.rec.skip(op)
+			return
+		}
+		method, path = http.MethodPut, g.tenantPath(t, "/atoms/"+url.PathEscape(atomID))
+		body = map[string]interface{}{"strength": r.Float64(), "confidence": r.Float64(), "sti": r.Intn(200)}
+	case OpDelete:
+		atomID, ok := t.take(r, g.cfg.MinAtoms)
+		if !ok {
+			g.rec.skip(op)
+			return
+		}
+		method, path = http.MethodDelete, g.tenantPath(t, "/atoms/"+url.PathEscape(atomID))
+	case OpQuery:
+		method, path = http.MethodGet, g.tenantPath(t, "/atoms?type=concept&min_confidence=0.5&limit=100")
+	case OpSearch:
+		_, name, ok := t.pick(r)
+		if !ok {
+			g.rec.skip(op)
+			return
+		}
+		// Matches the concepts whose sequence differs in the last digit
+		prefix := name[:len(name)-1]
+		method, path = http.MethodGet, g.tenantPath(t, "/atoms/search?mode=prefix&limit=20&q="+url.QueryEscape(prefix))
+	case OpInference:
+		method, path = http.MethodPost, g.tenantPath(t, "/inference")
+		body = map[string]int{"max_iterations": g.cfg.InferenceIterations}
+	}
+
+	began := time.Now()
+	status, response, err := g.do(ctx, method, path, body)
+	latency := time.Since(began)
+	if err != nil && ctx.Err() != nil {
+		// Cut off by the end of the run
+		return
+	}
+	g.rec.record(op, latency, status)
+	if status/100 == 2 && onSuccess != nil {
+		onSuccess(response)
+	}
+}
+
+// progress writes the throughput and errors of each interval until ctx is
+// done
+func (g *generator) progress(ctx context.Context, started time.Time) {
+	if g.cfg.Progress == nil || g.cfg.ProgressInterval <= 0 {
+		<-ctx.Done()
+		return
+	}
+	ticker := time.NewTicker(g.cfg.ProgressInterval)
+	defer ticker.Stop()
+	lastRequests, lastErrors, last := 0, 0, started
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			requests, errors := g.rec.count()
+			fmt.Fprintf(g.cfg.Progress, "%s: %d requests (%.1f/s), %d errors\n",
+				now.Sub(started).Round(time.Second), requests, float64(requests-lastRequests)/now.Sub(last).Seconds(), errors-lastErrors)
+			lastRequests, lastErrors, last = requests, errors, now
+		}
+	}
+}
+
+func (g *generator) tenantPath(t *tenant, path string) string {
+	return "/tenants/" + url.PathEscape(t.id) + path
+}
+
+// do sends a request to the cognitive API and returns the status and body
+// of the response
+func (g *generator) do(ctx context.Context, method, path string, body interface{}) (int, []byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(g.cfg.Addr, "/")+"/api/cognitive"+path, reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	for key, values := range g.cfg.Header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+	return resp.StatusCode, data, nil
+}
+
+// expect sends a setup request, failing unless it succeeds, and decodes
+// the response into out if set
+func (g *generator) expect(ctx context.Context, method, path string, body, out interface{}) (int, error) {
+	status, data, err := g.do(ctx, method, path, body)
+	if err != nil {
+		return status, err
+	}
+	if status/100 != 2 {
+		return status, fmt.Errorf("%s %s: %d %s", method, path, status, strings.TrimSpace(string(data)))
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return status, fmt.Errorf("%s %s: %w", method, path, err)
+		}
+	}
+	return status, nil
+}
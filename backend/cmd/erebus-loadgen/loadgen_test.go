@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/api"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestParseMix(t *testing.T) {
+	mix, err := ParseMix("create=3, query=1,delete=0")
+	if err != nil {
+		t.Fatalf("ParseMix failed: %v", err)
+	}
+	picked := make(map[Op]int)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 4000; i++ {
+		picked[mix.Pick(r)]++
+	}
+	if len(picked) != 2 || picked[OpCreate] < 2700 || picked[OpCreate] > 3300 {
+		t.Errorf("picked = %v, want about 3000 creates and 1000 queries", picked)
+	}
+
+	for _, invalid := range []string{"", "create=0", "drop=1", "create", "create=-1"} {
+		if _, err := ParseMix(invalid); err == nil {
+			t.Errorf("ParseMix(%q) succeeded", invalid)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[len(latencies)-1-i] = time.Duration(i+1) * time.Millisecond
+	}
+	l := summarize(latencies)
+	if l.P50 != 50*time.Millisecond || l.P99 != 99*time.Millisecond || l.Max != 100*time.Millisecond || l.Mean != 50500*time.Microsecond {
+		t.Errorf("summarize = %+v", l)
+	}
+	if l := summarize(nil); l != (Latencies{}) {
+		t.Errorf("summarize(nil) = %+v", l)
+	}
+}
+
+func TestRun(t *testing.T) {
+	engine := cognitive.NewCognitiveEngine(cognitive.DefaultConfig())
+	defer engine.Close()
+	router := chi.NewRouter()
+	api.NewCognitiveHandler(engine).RegisterRoutes(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	mix, _ := ParseMix(DefaultMix)
+	cfg := Config{
+		Addr:                server.URL,
+		Tenants:             2,
+		TenantPrefix:        "loadgen",
+		Duration:            300 * time.Millisecond,
+		Concurrency:         4,
+		Mix:                 mix,
+		SeedAtoms:           20,
+		MinAtoms:            5,
+		InferenceIterations: 1,
+		Timeout:             5 * time.Second,
+		Seed:                1,
+	}
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.Requests == 0 || report.Errors != 0 || len(report.Ops) != len(Ops) {
+		t.Fatalf("report = %+v", report)
+	}
+	for _, op := range report.Ops {
+		if op.Requests > 0 && op.Latencies.Max == 0 {
+			t.Errorf("no latencies of %s: %+v", op.Op, op)
+		}
+	}
+	if got := len(engine.ListTenants()); got != 2 {
+		t.Errorf("tenants = %d, want 2", got)
+	}
+
+	// A second run shares the tenants, and cleans them up
+	cfg.Cleanup = true
+	if report, err = Run(context.Background(), cfg); err != nil || report.Errors != 0 {
+		t.Fatalf("second run: %+v (%v)", report, err)
+	}
+	for _, tenantID := range engine.ListTenants() {
+		if atoms := engine.QueryAtoms(tenantID, nil); len(atoms) != 0 {
+			t.Errorf("tenant %s holds %d atoms after the cleanup", tenantID, len(atoms))
+		}
+	}
+}
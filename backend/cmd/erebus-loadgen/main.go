@@ -0,0 +1,109 @@
+// Command erebus-loadgen runs a synthetic workload against the cognitive
+// API of a running erebusd and reports the latency percentiles of each
+// operation. It is used to soak-test sharding and worker-pool changes at
+// scale:
+//
+//	erebus-loadgen -addr http://localhost:8080 -tenants 20 -concurrency 64 \
+//		-duration 30m -mix create=40,delete=20,query=30,inference=10
+//
+// Each tenant is initialized if needed and seeded with concepts, which the
+// workload then creates, links, updates, deletes, queries and searches,
+// triggering inference now and then. The mix weighs the operations, so its
+// create and delete weights set the churn of atoms. With -max-error-rate or
+// -max-p99 set, the command fails if the run exceeds them.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	os.Exit(loadgen(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// loadgen runs the command and returns its exit code. Interrupting a run
+// reports the requests made so far.
+func loadgen(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("erebus-loadgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cfg := Config{Header: make(http.Header)}
+	fs.StringVar(&cfg.Addr, "addr", "http://localhost:8080", "base URL of erebusd")
+	fs.IntVar(&cfg.Tenants, "tenants", 4, "tenants to spread the workload across")
+	fs.StringVar(&cfg.TenantPrefix, "tenant-prefix", "loadgen", "tenants are named <prefix>-<n>")
+	fs.DurationVar(&cfg.Duration, "duration", time.Minute, "how long the workload runs")
+	fs.IntVar(&cfg.Concurrency, "concurrency", 16, "requests in flight")
+	fs.Float64Var(&cfg.Rate, "rate", 0, "requests per second across all workers; 0 sends as fast as answered")
+	mix := fs.String("mix", DefaultMix, "operations with their relative weights")
+	fs.IntVar(&cfg.SeedAtoms, "seed-atoms", 100, "concepts created in each tenant before the run")
+	fs.IntVar(&cfg.MinAtoms, "min-atoms", 10, "concepts deletes leave in each tenant")
+	fs.IntVar(&cfg.InferenceIterations, "inference-iterations", 3, "iterations of each inference run")
+	fs.DurationVar(&cfg.Timeout, "timeout", 30*time.Second, "longest a request may take")
+	fs.BoolVar(&cfg.Cleanup, "cleanup", false, "purge the tenants' data after the run")
+	fs.DurationVar(&cfg.ProgressInterval, "progress", 10*time.Second, "how often progress is written to stderr; 0 never")
+	fs.Int64Var(&cfg.Seed, "seed", time.Now().UnixNano(), "seed of the random operations")
+	key := fs.String("key", "", "API key sent as X-Erebus-Key")
+	user := fs.String("user", "", "user sent as X-Erebus-User")
+	roles := fs.String("roles", "", "comma-separated roles sent as X-Erebus-Roles")
+	asJSON := fs.Bool("json", false, "write the report as JSON")
+	maxErrorRate := fs.Float64("max-error-rate", -1, "fail if more of the requests failed, e.g. 0.01; negative never fails")
+	maxP99 := fs.Duration("max-p99", 0, "fail if the p99 latency of all requests is higher; 0 never fails")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var err error
+	if cfg.Mix, err = ParseMix(*mix); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	if cfg.Tenants < 1 || cfg.Concurrency < 1 || cfg.Duration <= 0 || cfg.Rate < 0 || cfg.SeedAtoms < 0 || cfg.MinAtoms < 0 {
+		fmt.Fprintln(stderr, "tenants, concurrency and duration must be positive, and rate, seed-atoms and min-atoms not negative")
+		return 2
+	}
+	for header, value := range map[string]string{"X-Erebus-Key": *key, "X-Erebus-User": *user, "X-Erebus-Roles": *roles} {
+		if value != "" {
+			cfg.Header.Set(header, value)
+		}
+	}
+	cfg.Progress = stderr
+
+	report, err := Run(ctx, cfg)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		if report.Requests == 0 {
+			return 1
+		}
+	}
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		report.print(stdout)
+	}
+
+	failed := err != nil
+	if *maxErrorRate >= 0 && report.ErrorRate() > *maxErrorRate {
+		fmt.Fprintf(stderr, "error rate %.4f exceeds %.4f\n", report.ErrorRate(), *maxErrorRate)
+		failed = true
+	}
+	if *maxP99 > 0 && report.Overall.P99 > *maxP99 {
+		fmt.Fprintf(stderr, "p99 latency %s exceeds %s\n", report.Overall.P99, *maxP99)
+		failed = true
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
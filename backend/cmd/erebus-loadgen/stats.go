@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Latencies summarizes the latencies of an operation
+type Latencies struct {
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P99  time.Duration `json:"p99"`
+	P999 time.Duration `json:"p999"`
+	Max  time.Duration `json:"max"`
+	Mean time.Duration `json:"mean"`
+}
+
+// OpReport is what happened to the requests of an operation
+type OpReport struct {
+	Op        Op          `json:"op"`
+	Requests  int         `json:"requests"`
+	Rejected  int         `json:"rejected"` // Answered 4xx, such as links to concepts just deleted
+	Errors    int         `json:"errors"`   // Failed or answered 5xx
+	Skipped   int         `json:"skipped"`  // Not sent, as the tenant had too few concepts
+	Statuses  map[int]int `json:"statuses"`
+	Latencies Latencies   `json:"latencies"`
+}
+
+// Report is the outcome of a run
+type Report struct {
+	Tenants    int           `json:"tenants"`
+	Duration   time.Duration `json:"duration"`
+	Requests   int           `json:"requests"`
+	Errors     int           `json:"errors"`
+	Throughput float64       `json:"throughput"` // Requests per second
+	Atoms      int           `json:"atoms"`      // Concepts held at the end
+	Ops        []OpReport    `json:"ops"`
+	Overall    Latencies     `json:"overall"`
+}
+
+// ErrorRate is the share of requests that failed
+func (r *Report) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Errors) / float64(r.Requests)
+}
+
+// recorder collects the outcome of every request
+type recorder struct {
+	ops map[Op]*opSamples
+	mu  sync.Mutex
+}
+
+type opSamples struct {
+	latencies []time.Duration
+	statuses  map[int]int
+	errors    int
+	skipped   int
+}
+
+func newRecorder() *recorder {
+	return &recorder{ops: make(map[Op]*opSamples)}
+}
+
+func (r *recorder) samples(op Op) *opSamples {
+	s := r.ops[op]
+	if s == nil {
+		s = &opSamples{statuses: make(map[int]int)}
+		r.ops[op] = s
+	}
+	return s
+}
+
+// record adds a request answered with a status, or failed if status is 0
+func (r *recorder) record(op Op, latency time.Duration, status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.samples(op)
+	s.latencies = append(s.latencies, latency)
+	if status == 0 || status >= 500 {
+		s.errors++
+	}
+	if status != 0 {
+		s.statuses[status]++
+	}
+}
+
+// skip counts an operation that could not be sent
+func (r *recorder) skip(op Op) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.samples(op).skipped++
+}
+
+// count returns the requests and errors recorded so far
+func (r *recorder) count() (int, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	requests, errors := 0, 0
+	for _, s := range r.ops {
+		requests += len(s.latencies)
+		errors += s.errors
+	}
+	return requests, errors
+}
+
+// report summarizes the requests recorded over a run
+func (r *recorder) report(tenants int, elapsed time.Duration) Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := Report{Tenants: tenants, Duration: elapsed, Ops: make([]OpReport, 0, len(r.ops))}
+	var all []time.Duration
+	for _, op := range Ops {
+		s := r.ops[op]
+		if s == nil {
+			continue
+		}
+		rejected := 0
+		for status, n := range s.statuses {
+			if status >= 400 && status < 500 {
+				rejected += n
+			}
+		}
+		report.Ops = append(report.Ops, OpReport{
+			Op:        op,
+			Requests:  len(s.latencies),
+			Rejected:  rejected,
+			Errors:    s.errors,
+			Skipped:   s.skipped,
+			Statuses:  s.statuses,
+			Latencies: summarize(s.latencies),
+		})
+		report.Requests += len(s.latencies)
+		report.Errors += s.errors
+		all = append(all, s.latencies...)
+	}
+	report.Overall = summarize(all)
+	if elapsed > 0 {
+		report.Throughput = float64(report.Requests) / elapsed.Seconds()
+	}
+	return report
+}
+
+// summarize returns the percentiles of latencies, sorting them
+func summarize(latencies []time.Duration) Latencies {
+	if len(latencies) == 0 {
+		return Latencies{}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+	at := func(p float64) time.Duration {
+		i := int(p*float64(len(latencies))+0.5) - 1
+		return latencies[min(max(i, 0), len(latencies)-1)]
+	}
+	return Latencies{
+		P50:  at(0.50),
+		P90:  at(0.90),
+		P99:  at(0.99),
+		P999: at(0.999),
+		Max:  latencies[len(latencies)-1],
+		Mean: total / time.Duration(len(latencies)),
+	}
+}
+
+// print writes a report as a table
+func (r *Report) print(w io.Writer) {
+	fmt.Fprintf(w, "%d requests to %d tenants in %s: %.1f/s, %d errors (%.2f%%), %d concepts held\n\n",
+		r.Requests, r.Tenants, r.Duration.Round(time.Millisecond), r.Throughput, r.Errors, 100*r.ErrorRate(), r.Atoms)
+	fmt.Fprintf(w, "%-10s %9s %8s %7s %7s %10s %10s %10s %10s %10s\n",
+		"op", "requests", "rejected", "errors", "skipped", "p50", "p90", "p99", "p99.9", "max")
+	row := func(name string, requests, rejected, errors, skipped int, l Latencies) {
+		fmt.Fprintf(w, "%-10s %9d %8d %7d %7d %10s %10s %10s %10s %10s\n", name, requests, rejected, errors, skipped,
+			round(l.P50), round(l.P90), round(l.P99), round(l.P999), round(l.Max))
+	}
+	rejected, skipped := 0, 0
+	for _, op := range r.Ops {
+		row(string(op.Op), op.Requests, op.Rejected, op.Errors, op.Skipped, op.Latencies)
+		rejected += op.Rejected
+		skipped += op.Skipped
+	}
+	row("all", r.Requests, rejected, r.Errors, skipped, r.Overall)
+}
+
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(time.Microsecond)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Op is an operation of the workload
+type Op string
+
+const (
+	OpCreate    Op = "create"    // Create a concept
+	OpLink      Op = "link"      // Link two concepts by inheritance
+	OpUpdate    Op = "update"    // Change a concept's truth and attention values
+	OpDelete    Op = "delete"    // Delete a concept
+	OpQuery     Op = "query"     // Query concepts by type and confidence
+	OpSearch    Op = "search"    // Search concept names by prefix
+	OpInference Op = "inference" // Run inference
+)
+
+// Ops lists the operations in the order they are reported
+var Ops = []Op{OpCreate, OpLink, OpUpdate, OpDelete, OpQuery, OpSearch, OpInference}
+
+// DefaultMix is a write-heavy mix with some churn and occasional inference
+const DefaultMix = "create=30,link=15,update=20,delete=5,query=15,search=12,inference=3"
+
+// Mix weighs the operations of a workload
+type Mix struct {
+	ops     []Op
+	weights []int // Cumulative
+}
+
+// ParseMix parses a mix such as "create=3,query=1": operations with their
+// relative weights. Operations not listed are not run.
+func ParseMix(s string) (Mix, error) {
+	var m Mix
+	known := make(map[Op]bool, len(Ops))
+	for _, op := range Ops {
+		known[op] = true
+	}
+	total := 0
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, weight, ok := strings.Cut(part, "=")
+		op := Op(strings.TrimSpace(name))
+		if !ok || !known[op] {
+			return Mix{}, fmt.Errorf("invalid mix entry %q: want one of %v with a weight", part, Ops)
+		}
+		w, err := strconv.Atoi(strings.TrimSpace(weight))
+		if err != nil || w < 0 {
+			return Mix{}, fmt.Errorf("invalid weight of %s: %q", op, weight)
+		}
+		if w == 0 {
+			continue
+		}
+		total += w
+		m.ops = append(m.ops, op)
+		m.weights = append(m.weights, total)
+	}
+	if total == 0 {
+		return Mix{}, fmt.Errorf("mix %q runs no operations", s)
+	}
+	return m, nil
+}
+
+// Pick draws an operation by weight
+func (m Mix) Pick(r *rand.Rand) Op {
+	n := r.Intn(m.weights[len(m.weights)-1])
+	return m.ops[sort.SearchInts(m.weights, n+1)]
+}
+
+// tenant is a tenant the workload runs against, with the concepts it
+// created and not yet deleted
+type tenant struct {
+	id    string
+	run   string   // Prefix of the concept names of the run
+	atoms []string // IDs
+	names []string // Of the atoms, by index
+	next  int      // Sequence of concept names
+	mu    sync.Mutex
+}
+
+// name returns the name of the next concept
+func (t *tenant) name() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.next++
+	return fmt.Sprintf("%s-%d", t.run, t.next)
+}
+
+func (t *tenant) add(atomID, name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.atoms = append(t.atoms, atomID)
+	t.names = append(t.names, name)
+}
+
+// pick returns a random concept, or false if there is none
+func (t *tenant) pick(r *rand.Rand) (string, string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.atoms) == 0 {
+		return "", "", false
+	}
+	i := r.Intn(len(t.atoms))
+	return t.atoms[i], t.names[i], true
+}
+
+// take removes a random concept so no other operation picks it, keeping
+// at least keep
+func (t *tenant) take(r *rand.Rand, keep int) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.atoms) <= keep {
+		return "", false
+	}
+	i := r.Intn(len(t.atoms))
+	atomID := t.atoms[i]
+	last := len(t.atoms) - 1
+	t.atoms[i], t.names[i] = t.atoms[last], t.names[last]
+	t.atoms, t.names = t.atoms[:last], t.names[:last]
+	return atomID, true
+}
+
+func (t *tenant) size() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.atoms)
+}
@@ -3,24 +3,45 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/Avik2024/erebus/backend/internal/cognitive"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
 	"github.com/Avik2024/erebus/backend/internal/cognitive/api"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/connectors"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/federation"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/gitops"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/leader"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/metering"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/partition"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/pipeline"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sharding"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/watchdog"
 	"github.com/Avik2024/erebus/backend/internal/config"
+	"github.com/Avik2024/erebus/backend/internal/cors"
 	"github.com/Avik2024/erebus/backend/internal/health"
 	"github.com/Avik2024/erebus/backend/internal/logging"
 	"github.com/Avik2024/erebus/backend/internal/metrics"
+	"github.com/Avik2024/erebus/backend/internal/ui"
 	"github.com/Avik2024/erebus/backend/internal/version"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
 )
 
 // ----------------------------
@@ -78,10 +99,135 @@ func CreateProject(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// newLeaderLock creates the lock replicas elect the one running agents
+// with, or none if every replica runs them
+func newLeaderLock(cfg *config.Config) (leader.Lock, error) {
+	identity := cfg.Leader.Identity
+	if identity == "" {
+		identity, _ = os.Hostname()
+	}
+	switch cfg.Leader.Election {
+	case "":
+		return nil, nil
+	case "kubernetes":
+		if cfg.Leader.RetryPeriod >= cfg.Leader.LeaseDuration {
+			return nil, fmt.Errorf("leader retry period must be shorter than the lease duration")
+		}
+		leaseConfig, err := leader.InClusterLeaseConfig(cfg.Leader.Name, identity)
+		if err != nil {
+			return nil, err
+		}
+		leaseConfig.Duration = cfg.Leader.LeaseDuration
+		return leader.NewLeaseLock(leaseConfig)
+	case "postgres":
+		db, err := gorm.Open(postgres.Open(cfg.Database.URL), &gorm.Config{})
+		if err != nil {
+			return nil, err
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		return leader.NewPostgresLock(sqlDB, cfg.Leader.Name), nil
+	default:
+		return nil, fmt.Errorf("unknown leader election %q", cfg.Leader.Election)
+	}
+}
+
+// newFederationConnections opens the SQL connections federated lookups may
+// query
+func newFederationConnections(cfg *config.Config) (map[string]federation.Connection, error) {
+	connections := make(map[string]federation.Connection, len(cfg.Federation.Connections))
+	for name, url := range cfg.Federation.Connections {
+		db, err := gorm.Open(postgres.Open(url), &gorm.Config{})
+		if err != nil {
+			return nil, fmt.Errorf("connection %s: %w", name, err)
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, fmt.Errorf("connection %s: %w", name, err)
+		}
+		connections[name] = federation.Connection{DB: sqlDB, Tenants: cfg.Federation.Tenants[name], Queries: cfg.Federation.Queries[name]}
+	}
+	return connections, nil
+}
+
+// newMembership creates the membership replicas spread agents across, or
+// none if leader election decides which replica runs them
+func newMembership(cfg *config.Config) (partition.Membership, error) {
+	identity := cfg.Partition.Identity
+	if identity == "" {
+		identity, _ = os.Hostname()
+	}
+	if cfg.Partition.Membership != "" && cfg.Partition.HeartbeatInterval >= cfg.Partition.TTL {
+		return nil, fmt.Errorf("partition heartbeat interval must be shorter than the TTL")
+	}
+	switch cfg.Partition.Membership {
+	case "":
+		return nil, nil
+	case "kubernetes":
+		leaseConfig, err := leader.InClusterLeaseConfig(cfg.Partition.Group, identity)
+		if err != nil {
+			return nil, err
+		}
+		leaseConfig.Duration = cfg.Partition.TTL
+		return partition.NewLeaseMembership(leaseConfig)
+	case "postgres":
+		db, err := gorm.Open(postgres.Open(cfg.Database.URL), &gorm.Config{})
+		if err != nil {
+			return nil, err
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		return partition.NewPostgresMembership(sqlDB, cfg.Partition.Group, identity, cfg.Partition.TTL), nil
+	default:
+		return nil, fmt.Errorf("unknown partition membership %q", cfg.Partition.Membership)
+	}
+}
+
+// newPipelineQueue creates the queue submitted pipeline executions wait
+// in, or none if they wait in memory
+func newPipelineQueue(cfg *config.Config) (*pipeline.RedisQueue, error) {
+	switch cfg.Pipelines.Queue {
+	case "", "memory":
+		return nil, nil
+	case "redis":
+		redisConfig := pipeline.RedisConfig{
+			Addr:              cfg.Redis.URL,
+			Password:          cfg.Redis.Password,
+			DB:                cfg.Redis.DB,
+			Key:               cfg.Pipelines.QueueKey,
+			VisibilityTimeout: cfg.Pipelines.VisibilityTimeout,
+			PollInterval:      cfg.Pipelines.PollInterval,
+		}
+		// Accept redis://[:password@]host:port[/db] as well as host:port
+		if u, err := url.Parse(cfg.Redis.URL); err == nil && u.Scheme == "redis" && u.Host != "" {
+			redisConfig.Addr = u.Host
+			if password, set := u.User.Password(); set {
+				redisConfig.Password = password
+			}
+			if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+				if redisConfig.DB, err = strconv.Atoi(db); err != nil {
+					return nil, fmt.Errorf("invalid redis database %q", db)
+				}
+			}
+		}
+		return pipeline.NewRedisQueue(redisConfig), nil
+	default:
+		return nil, fmt.Errorf("unknown pipeline queue %q", cfg.Pipelines.Queue)
+	}
+}
+
 // ----------------------------
 // Main
 // ----------------------------
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate-data" {
+		os.Exit(migrateData(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	// ----------------------------
 	// Load configuration
 	// ----------------------------
@@ -90,7 +236,7 @@ func main() {
 		log.Fatalf("failed to load config: %v", err)
 	}
 	log.Printf("App config loaded: Env=%s, Port=%s, DB=%s, Redis Enabled=%v",
-		cfg.App.Env, cfg.App.Port, cfg.Database.URL, cfg.Redis.Enabled)
+		cfg.App.Env, cfg.App.Port, cfg.Redacted().Database.URL, cfg.Redis.Enabled)
 
 	// ----------------------------
 	// Create structured logger
@@ -119,8 +265,152 @@ func main() {
 	// ----------------------------
 	logger.Info("initializing cognitive engine...")
 	cognitiveConfig := cognitive.DefaultConfig()
+	agentMetrics, err := agents.NewPrometheusMetrics(prometheus.DefaultRegisterer)
+	if err != nil {
+		logger.Fatal("failed to register agent metrics", zap.Error(err))
+	}
+	cognitiveConfig.AgentMetrics = agentMetrics
+	shardMetrics, err := sharding.NewPrometheusMetrics(prometheus.DefaultRegisterer)
+	if err != nil {
+		logger.Fatal("failed to register shard metrics", zap.Error(err))
+	}
+	cognitiveConfig.ShardMetrics = shardMetrics
+	connectorMetrics, err := connectors.NewPrometheusMetrics(prometheus.DefaultRegisterer)
+	if err != nil {
+		logger.Fatal("failed to register connector metrics", zap.Error(err))
+	}
+	cognitiveConfig.ConnectorMetrics = connectorMetrics
+	cognitiveConfig.ValueLog.Dir = cfg.Persistence.ValueLogDir
+	cognitiveConfig.ValueLog.Interval = cfg.Persistence.ValueLogInterval
+	cognitiveConfig.ValueLog.LossWindow = cfg.Persistence.ValueLogLossWindow
+	cognitiveConfig.ValueLog.CompactionRatio = cfg.Persistence.ValueLogCompactionRatio
+	cognitiveConfig.ValueLog.CompactionMinRecords = cfg.Persistence.ValueLogCompactionMinRecords
+	cognitiveConfig.ValueLog.Retention = cfg.Persistence.ValueLogRetention
+	cognitiveConfig.SnapshotDir = cfg.Persistence.SnapshotDir
+	cognitiveConfig.SnapshotInterval = cfg.Persistence.SnapshotInterval
+	cognitiveConfig.StartupIntegrity = cognitive.IntegrityMode(cfg.Persistence.StartupIntegrity)
+	if err := cognitiveConfig.StartupIntegrity.Validate(); err != nil {
+		logger.Fatal("invalid startup integrity mode", zap.Error(err))
+	}
+	cognitiveConfig.Replicas.Enabled = cfg.Sharding.ReadReplicas
+	cognitiveConfig.Replicas.SyncInterval = cfg.Sharding.ReplicaSyncInterval
+	cognitiveConfig.Usage.Resolution = cfg.Usage.Resolution
+	cognitiveConfig.Usage.Retention = cfg.Usage.Retention
+	cognitiveConfig.StatsHistory.Resolution = cfg.Stats.HistoryResolution
+	cognitiveConfig.StatsHistory.Retention = cfg.Stats.HistoryRetention
+	cognitiveConfig.StatsCacheTTL = cfg.Stats.CacheTTL
+	cognitiveConfig.Artifacts.Dir = cfg.Pipelines.ArtifactDir
+	cognitiveConfig.Artifacts.MaxSize = cfg.Pipelines.ArtifactMaxBytes
+	cognitiveConfig.Artifacts.MaxExecutionSize = cfg.Pipelines.ArtifactExecutionMaxBytes
+	cognitiveConfig.Artifacts.Retention = cfg.Pipelines.ArtifactRetention
+	cognitiveConfig.Decay.Interval = cfg.Decay.Interval
+	cognitiveConfig.Watches.Interval = cfg.Watches.Interval
+	cognitiveConfig.Shares.Secret = []byte(cfg.Shares.Secret)
+	cognitiveConfig.Shares.DefaultTTL = cfg.Shares.DefaultTTL
+	cognitiveConfig.Shares.MaxTTL = cfg.Shares.MaxTTL
+	cognitiveConfig.Federation.AllowedHosts = cfg.Federation.AllowedHosts
+	cognitiveConfig.Federation.Timeout = cfg.Federation.Timeout
+	cognitiveConfig.Federation.MaxRows = cfg.Federation.MaxRows
+	if cognitiveConfig.Federation.Connections, err = newFederationConnections(cfg); err != nil {
+		logger.Fatal("failed to open federation connections", zap.Error(err))
+	}
+	cognitiveConfig.Secrets.EnvPrefix = cfg.Secrets.EnvPrefix
+	cognitiveConfig.Secrets.FileDir = cfg.Secrets.FileDir
+	cognitiveConfig.Secrets.VaultAddr = cfg.Secrets.VaultAddr
+	cognitiveConfig.Secrets.VaultToken = cfg.Secrets.VaultToken
+	cognitiveConfig.Secrets.VaultMount = cfg.Secrets.VaultMount
+	cognitiveConfig.Secrets.VaultPrefix = cfg.Secrets.VaultPrefix
+	cognitiveConfig.Secrets.CacheTTL = cfg.Secrets.CacheTTL
+	cognitiveConfig.Connectors.Interval = cfg.Connectors.Interval
+	cognitiveConfig.Connectors.Backoff.InitialBackoff = cfg.Connectors.InitialBackoff
+	cognitiveConfig.Connectors.Backoff.MaxBackoff = cfg.Connectors.MaxBackoff
+	cognitiveConfig.Watchdog.Enabled = cfg.Watchdog.Enabled
+	cognitiveConfig.Watchdog.SystemTenant = cfg.Watchdog.SystemTenant
+	if cfg.Watchdog.WebhookURL != "" {
+		cognitiveConfig.Watchdog.Channels = append(cognitiveConfig.Watchdog.Channels, watchdog.Channel{
+			Type: watchdog.ChannelWebhook, URL: cfg.Watchdog.WebhookURL, MinSeverity: cfg.Watchdog.MinSeverity,
+		})
+	}
+	if cfg.Watchdog.SlackWebhookURL != "" {
+		cognitiveConfig.Watchdog.Channels = append(cognitiveConfig.Watchdog.Channels, watchdog.Channel{
+			Type: watchdog.ChannelSlack, URL: cfg.Watchdog.SlackWebhookURL, MinSeverity: cfg.Watchdog.MinSeverity,
+		})
+	}
+	if err := cognitiveConfig.Watchdog.Validate(); err != nil {
+		logger.Fatal("invalid watchdog configuration", zap.Error(err))
+	}
+	cognitiveConfig.Notifications.Timeout = cfg.Notifications.Timeout
+	cognitiveConfig.Notifications.PagerDutyURL = cfg.Notifications.PagerDutyURL
+	cognitiveConfig.Metering.Interval = cfg.Metering.Interval
+	if cfg.Metering.CSVPath != "" {
+		cognitiveConfig.MeteringExporters = append(cognitiveConfig.MeteringExporters, metering.NewCSVExporter(cfg.Metering.CSVPath))
+	}
+	if cfg.Metering.StripeSecretKey != "" {
+		stripeConfig := metering.StripeConfig{SecretKey: cfg.Metering.StripeSecretKey}
+		if cfg.Metering.StripeItemsFile != "" {
+			data, err := os.ReadFile(cfg.Metering.StripeItemsFile)
+			if err != nil {
+				logger.Fatal("failed to read stripe subscription items", zap.Error(err))
+			}
+			if err := json.Unmarshal(data, &stripeConfig.Items); err != nil {
+				logger.Fatal("invalid stripe subscription items", zap.Error(err))
+			}
+		}
+		stripeExporter, err := metering.NewStripeExporter(stripeConfig)
+		if err != nil {
+			logger.Fatal("failed to create stripe exporter", zap.Error(err))
+		}
+		cognitiveConfig.MeteringExporters = append(cognitiveConfig.MeteringExporters, stripeExporter)
+	}
+	cognitiveConfig.GitOps = gitops.Config{
+		Dir:      cfg.GitOps.Dir,
+		Repo:     cfg.GitOps.Repo,
+		Branch:   cfg.GitOps.Branch,
+		Path:     cfg.GitOps.Path,
+		Interval: cfg.GitOps.Interval,
+		Prune:    cfg.GitOps.Prune,
+	}
+	if err := cognitiveConfig.GitOps.Validate(); err != nil {
+		logger.Fatal("invalid gitops configuration", zap.Error(err))
+	}
+	if cognitiveConfig.LeaderLock, err = newLeaderLock(cfg); err != nil {
+		logger.Fatal("failed to set up leader election", zap.Error(err))
+	}
+	cognitiveConfig.Leader = leader.Config{Identity: cfg.Leader.Identity, RetryPeriod: cfg.Leader.RetryPeriod}
+	if cognitiveConfig.Membership, err = newMembership(cfg); err != nil {
+		logger.Fatal("failed to set up agent partitioning", zap.Error(err))
+	}
+	cognitiveConfig.Partition = partition.Config{Identity: cfg.Partition.Identity, HeartbeatInterval: cfg.Partition.HeartbeatInterval}
+	pipelineQueue, err := newPipelineQueue(cfg)
+	if err != nil {
+		logger.Fatal("failed to set up the pipeline queue", zap.Error(err))
+	}
+	if pipelineQueue != nil {
+		defer pipelineQueue.Close()
+		cognitiveConfig.PipelineQueue = pipelineQueue
+	}
+	if cognitiveConfig.IDScheme, err = atomspace.ParseIDScheme(cfg.Atoms.IDScheme); err != nil {
+		logger.Fatal("invalid atom ID scheme", zap.Error(err))
+	}
 	cognitiveEngine := cognitive.NewCognitiveEngine(cognitiveConfig)
 	defer cognitiveEngine.Close()
+	if cfg.Connectors.File != "" {
+		data, err := os.ReadFile(cfg.Connectors.File)
+		if err != nil {
+			logger.Fatal("failed to read connectors", zap.Error(err))
+		}
+		var declared map[string][]connectors.Spec
+		if err := json.Unmarshal(data, &declared); err != nil {
+			logger.Fatal("invalid connectors", zap.Error(err))
+		}
+		for tenantID, specs := range declared {
+			for _, spec := range specs {
+				if _, err := cognitiveEngine.SetConnector(tenantID, spec); err != nil {
+					logger.Fatal("invalid connector", zap.String("tenant", tenantID), zap.Error(err))
+				}
+			}
+		}
+	}
 	
 	logger.Info("cognitive engine initialized",
 		zap.Int("num_shards", cognitiveConfig.NumShards),
@@ -132,25 +422,89 @@ func main() {
 	// ----------------------------
 	// Create router & middlewares
 	// ----------------------------
+	corsConfig := cors.DefaultConfig(cfg.App.Env)
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		corsConfig.AllowedOrigins = cfg.CORS.AllowedOrigins
+	}
+	if len(cfg.CORS.AllowedHeaders) > 0 {
+		corsConfig.AllowedHeaders = cfg.CORS.AllowedHeaders
+	}
+	corsConfig.AllowCredentials = cfg.CORS.AllowCredentials
+	if cfg.CORS.MaxAge > 0 {
+		corsConfig.MaxAge = cfg.CORS.MaxAge
+	}
+	if err := corsConfig.Validate(); err != nil {
+		logger.Fatal("invalid CORS configuration", zap.Error(err))
+	}
+
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)             // generate request ID
 	r.Use(middleware.RealIP)                // get real client IP
 	r.Use(middleware.Recoverer)             // recover from panics
 	r.Use(logging.LoggerMiddleware(logger)) // structured logging
 	r.Use(metrics.InstrumentHandler)        // Prometheus metrics with request_id
+	r.Use(cors.Handler(corsConfig))         // cross-origin requests of allowed origins
 
 	// ----------------------------
 	// API Endpoints
 	// ----------------------------
 	r.Get("/api/healthz", health.Handler)
 	r.Get("/api/version", version.Handler)
+	r.Get("/api/readyz", health.ReadyHandler)
+	health.RegisterCheck("agents", cognitiveEngine.CheckAgents)
+	health.RegisterCheck("value_logs", cognitiveEngine.CheckValueLogs)
+	health.RegisterCheck("draining", cognitiveEngine.CheckDraining)
+
+	// Startup probe: reports unavailable until the shards are hydrated and
+	// the manifests first reconciled, distinct from readiness so that
+	// orchestrators wait for warm-up instead of restarting the pod
+	r.Get("/api/startupz", health.StartupHandler)
+	health.RegisterStartupCheck("engine", cognitiveEngine.CheckStartup)
+
+	// ----------------------------
+	// Admin Endpoints
+	// ----------------------------
+	r.With(api.Authenticate, api.RequireRole(acl.AdminRole)).Get("/api/admin/config", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"app":    cfg.Redacted(),
+			"engine": cognitiveEngine.RuntimeConfig(),
+		})
+	})
+	r.With(api.Authenticate, api.RequireRole(acl.AdminRole)).Get("/api/admin/compaction", func(w http.ResponseWriter, r *http.Request) {
+		report, err := cognitiveEngine.GetLogCompaction()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	})
+	r.With(api.Authenticate, api.RequireRole(acl.AdminRole)).Get("/api/admin/watchdog", func(w http.ResponseWriter, r *http.Request) {
+		report, err := cognitiveEngine.GetWatchdog()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	})
 
 	// ----------------------------
 	// Cognitive API Endpoints
 	// ----------------------------
 	cognitiveHandler := api.NewCognitiveHandler(cognitiveEngine)
+	cognitiveHandler.SetBodyLimits(api.BodyLimits{Max: cfg.HTTP.MaxBodyBytes, Import: cfg.HTTP.MaxImportBytes})
+	cognitiveHandler.SetHandlerTimeout(cfg.HTTP.HandlerTimeout)
 	cognitiveHandler.RegisterRoutes(r)
 
+	// ----------------------------
+	// Web UI
+	// ----------------------------
+	uiHandler := ui.Handler("/ui")
+	r.Handle("/ui", uiHandler)
+	r.Handle("/ui/*", uiHandler)
+
 	// ----------------------------
 	// User & Projects Endpoints
 	// ----------------------------
@@ -174,8 +528,12 @@ func main() {
 	// ----------------------------
 	addr := ":" + cfg.App.Port
 	srv := &http.Server{
-		Addr:    addr,
-		Handler: r,
+		Addr:              addr,
+		Handler:           r,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
 	}
 
 	// ----------------------------
@@ -196,7 +554,12 @@ func main() {
 	<-quit
 	logger.Info("shutting down server...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Report unready and refuse new cognitive requests, giving load
+	// balancers time to stop routing here before connections are closed
+	cognitiveEngine.Drain()
+	time.Sleep(cfg.HTTP.DrainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Fatal("server forced to shutdown", zap.Error(err))
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/persistence"
+	"github.com/Avik2024/erebus/backend/internal/config"
+)
+
+// migrateData rewrites the snapshots and value logs of the configured
+// directories in another schema version: the current one after an upgrade,
+// so that the files no longer need upgrading at startup, or an older one
+// before rolling back to a build that cannot read the current one. erebusd
+// must not be running.
+func migrateData(args []string, stdout, stderr io.Writer) int {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+
+	fs := flag.NewFlagSet("migrate-data", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	snapshotDir := fs.String("snapshots", cfg.Persistence.SnapshotDir, "directory of the tenant snapshots (*.snap)")
+	valueLogDir := fs.String("value-logs", cfg.Persistence.ValueLogDir, "directory of the value logs (*.vlog)")
+	to := fs.Int("to", persistence.SchemaVersion, "schema version to write")
+	dryRun := fs.Bool("dry-run", false, "report what would change without writing")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if err := persistence.CheckSchemaVersion(*to); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	if *snapshotDir == "" && *valueLogDir == "" {
+		fmt.Fprintln(stderr, "no snapshot or value log directory configured")
+		return 2
+	}
+
+	codec := persistence.NewCodec()
+	failed := false
+	migrate := func(dir, pattern string, fn func(path string) (persistence.MigrationReport, error)) {
+		if dir == "" {
+			return
+		}
+		paths, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, path := range paths {
+			report, err := fn(path)
+			switch {
+			case errors.Is(err, persistence.ErrEncryptedSnapshot):
+				fmt.Fprintf(stdout, "%s: skipped, encrypted snapshots are upgraded when restored\n", path)
+			case errors.Is(err, persistence.ErrEncryptedValueLog):
+				fmt.Fprintf(stdout, "%s: skipped, encrypted value logs are upgraded when opened\n", path)
+			case err != nil:
+				fmt.Fprintf(stderr, "%s: %v\n", path, err)
+				failed = true
+			default:
+				fmt.Fprintf(stdout, "%s: version %d -> %d, %d records", path, report.From, report.To, report.Records)
+				if report.Lossy > 0 {
+					fmt.Fprintf(stdout, ", %d lose information", report.Lossy)
+				}
+				if !report.Rewritten {
+					fmt.Fprint(stdout, ", not rewritten")
+				}
+				fmt.Fprintln(stdout)
+			}
+		}
+	}
+	migrate(*snapshotDir, "*.snap", func(path string) (persistence.MigrationReport, error) {
+		return codec.MigrateSnapshotFile(path, *to, *dryRun)
+	})
+	migrate(*valueLogDir, "*.vlog", func(path string) (persistence.MigrationReport, error) {
+		return persistence.MigrateValueLog(path, *to, *dryRun)
+	})
+
+	if failed {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,61 @@
+package cognitive
+
+import (
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+)
+
+// SetACL protects one of a tenant's atoms or pipelines, so that only its
+// owner and editors may change it. This lets several engineers share a
+// tenant without overwriting each other's curated knowledge.
+func (ce *CognitiveEngine) SetACL(tenantID string, kind acl.Kind, id string, a acl.ACL, p acl.Principal) (acl.ACL, error) {
+	if err := ce.resourceExists(tenantID, kind, id); err != nil {
+		return acl.ACL{}, err
+	}
+	return ce.acls.Set(tenantID, kind, id, a, p)
+}
+
+// GetACL returns the ACL of one of a tenant's atoms or pipelines
+func (ce *CognitiveEngine) GetACL(tenantID string, kind acl.Kind, id string) (acl.ACL, error) {
+	a, exists := ce.acls.Get(tenantID, kind, id)
+	if !exists {
+		return acl.ACL{}, fmt.Errorf("%s %s has no ACL", kind, id)
+	}
+	return a, nil
+}
+
+// ListACLs returns the ACLs of a tenant's resources of a kind, by ID
+func (ce *CognitiveEngine) ListACLs(tenantID string, kind acl.Kind) map[string]acl.ACL {
+	return ce.acls.List(tenantID, kind)
+}
+
+// RemoveACL unprotects one of a tenant's atoms or pipelines
+func (ce *CognitiveEngine) RemoveACL(tenantID string, kind acl.Kind, id string, p acl.Principal) error {
+	return ce.acls.Remove(tenantID, kind, id, p)
+}
+
+// AuthorizeWrite returns an error unless the principal may change one of
+// a tenant's atoms or pipelines
+func (ce *CognitiveEngine) AuthorizeWrite(tenantID string, kind acl.Kind, id string, p acl.Principal) error {
+	return ce.acls.Authorize(tenantID, kind, id, p)
+}
+
+// resourceExists returns an error unless the tenant owns the resource
+func (ce *CognitiveEngine) resourceExists(tenantID string, kind acl.Kind, id string) error {
+	switch kind {
+	case acl.KindAtom:
+		_, err := ce.shardManager.GetAtom(id, tenantID)
+		return err
+	case acl.KindPipeline:
+		p, err := ce.pipelineOrch.GetPipeline(id)
+		if err != nil {
+			return err
+		}
+		if p.TenantID != tenantID {
+			return fmt.Errorf("pipeline %s not found", id)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown resource kind: %s", kind)
+}
@@ -0,0 +1,258 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is the kind of resource an ACL protects
+type Kind string
+
+const (
+	KindAtom     Kind = "atom"
+	KindPipeline Kind = "pipeline"
+)
+
+// AdminRole is the role allowed to change every resource of a tenant
+const AdminRole = "admin"
+
+// Principal is the user making a request and the roles they hold
+type Principal struct {
+	User  string   `json:"user,omitempty"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+// Anonymous reports whether the request carried no identity
+func (p Principal) Anonymous() bool {
+	return p.User == "" && len(p.Roles) == 0
+}
+
+// Matches reports whether the principal is the user or holds the role an
+// entry names, as in "user:alice" or "role:sre"
+func (p Principal) Matches(entry string) bool {
+	kind, name, _ := strings.Cut(entry, ":")
+	switch kind {
+	case "user":
+		return name != "" && name == p.User
+	case "role":
+		return p.HasRole(name)
+	}
+	return false
+}
+
+// HasRole reports whether the principal holds a role
+func (p Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Entry returns the entry naming the principal's user
+func (p Principal) Entry() string {
+	if p.User == "" {
+		return ""
+	}
+	return "user:" + p.User
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the principal of a request
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal of a request, anonymous if none
+func PrincipalFrom(ctx context.Context) Principal {
+	p, _ := ctx.Value(principalKey{}).(Principal)
+	return p
+}
+
+// ACL restricts who may change a resource. Resources without an ACL may be
+// changed by anyone in the tenant.
+type ACL struct {
+	Owner     string    `json:"owner"`             // "user:<name>" or "role:<name>"
+	Editors   []string  `json:"editors,omitempty"` // Entries also allowed to change the resource
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the ACL's entries
+func (a *ACL) Validate() error {
+	if err := validateEntry(a.Owner); err != nil {
+		return fmt.Errorf("invalid owner: %w", err)
+	}
+	for _, e := range a.Editors {
+		if err := validateEntry(e); err != nil {
+			return fmt.Errorf("invalid editor: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateEntry(entry string) error {
+	kind, name, _ := strings.Cut(entry, ":")
+	if (kind != "user" && kind != "role") || name == "" {
+		return fmt.Errorf("%q must be user:<name> or role:<name>", entry)
+	}
+	return nil
+}
+
+// IsOwner reports whether the principal owns the resource
+func (a ACL) IsOwner(p Principal) bool {
+	return p.Matches(a.Owner) || p.HasRole(AdminRole)
+}
+
+// CanWrite reports whether the principal may change the resource
+func (a ACL) CanWrite(p Principal) bool {
+	if a.IsOwner(p) {
+		return true
+	}
+	for _, e := range a.Editors {
+		if p.Matches(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// ForbiddenError is returned for changes the ACL of a resource does not allow
+type ForbiddenError struct {
+	Kind Kind
+	ID   string
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("not allowed to change %s %s", e.Kind, e.ID)
+}
+
+// IsForbidden reports whether a change was refused by an ACL
+func IsForbidden(err error) bool {
+	var forbidden *ForbiddenError
+	return errors.As(err, &forbidden)
+}
+
+// Registry holds the ACLs of each tenant's resources
+type Registry struct {
+	acls map[string]map[Kind]map[string]ACL // tenantID -> kind -> resource ID -> ACL
+	mu   sync.RWMutex
+}
+
+// NewRegistry creates an empty ACL registry
+func NewRegistry() *Registry {
+	return &Registry{acls: make(map[string]map[Kind]map[string]ACL)}
+}
+
+// Set creates or replaces the ACL of a resource on behalf of a principal.
+// Only the owner of a protected resource may change its ACL; anyone in the
+// tenant may protect an unprotected one.
+func (r *Registry) Set(tenantID string, kind Kind, id string, a ACL, p Principal) (ACL, error) {
+	if err := a.Validate(); err != nil {
+		return ACL{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, exists := r.acls[tenantID][kind][id]; exists && !existing.IsOwner(p) {
+		return ACL{}, &ForbiddenError{Kind: kind, ID: id}
+	}
+	if r.acls[tenantID] == nil {
+		r.acls[tenantID] = make(map[Kind]map[string]ACL)
+	}
+	if r.acls[tenantID][kind] == nil {
+		r.acls[tenantID][kind] = make(map[string]ACL)
+	}
+	a.Editors = append([]string{}, a.Editors...)
+	a.UpdatedAt = time.Now()
+	r.acls[tenantID][kind][id] = a
+	return a, nil
+}
+
+// Get returns the ACL of a resource
+func (r *Registry) Get(tenantID string, kind Kind, id string) (ACL, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, exists := r.acls[tenantID][kind][id]
+	return a, exists
+}
+
+// List returns the ACLs of a tenant's resources of a kind, by resource ID
+func (r *Registry) List(tenantID string, kind Kind) map[string]ACL {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	acls := make(map[string]ACL, len(r.acls[tenantID][kind]))
+	for id, a := range r.acls[tenantID][kind] {
+		acls[id] = a
+	}
+	return acls
+}
+
+// Remove deletes the ACL of a resource on behalf of a principal, leaving
+// the resource unprotected
+func (r *Registry) Remove(tenantID string, kind Kind, id string, p Principal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.acls[tenantID][kind][id]
+	if !exists {
+		return fmt.Errorf("%s %s has no ACL", kind, id)
+	}
+	if !existing.IsOwner(p) {
+		return &ForbiddenError{Kind: kind, ID: id}
+	}
+	delete(r.acls[tenantID][kind], id)
+	return nil
+}
+
+// Forget deletes the ACL of a resource that no longer exists
+func (r *Registry) Forget(tenantID string, kind Kind, id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.acls[tenantID][kind], id)
+}
+
+// Authorize returns an error unless the principal may change a resource
+func (r *Registry) Authorize(tenantID string, kind Kind, id string, p Principal) error {
+	a, exists := r.Get(tenantID, kind, id)
+	if exists && !a.CanWrite(p) {
+		return &ForbiddenError{Kind: kind, ID: id}
+	}
+	return nil
+}
+
+// Purge removes all of a tenant's ACLs and returns how many there were
+func (r *Registry) Purge(tenantID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := 0
+	for _, resources := range r.acls[tenantID] {
+		purged += len(resources)
+	}
+	delete(r.acls, tenantID)
+	return purged
+}
+
+// GetStats returns registry statistics
+func (r *Registry) GetStats() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byKind := make(map[Kind]int)
+	for _, tenant := range r.acls {
+		for kind, resources := range tenant {
+			byKind[kind] += len(resources)
+		}
+	}
+	return map[string]interface{}{
+		"tenants": len(r.acls),
+		"acls":    byKind,
+	}
+}
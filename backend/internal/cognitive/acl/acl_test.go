@@ -0,0 +1,78 @@
+package acl
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPrincipal(t *testing.T) {
+	p := Principal{User: "alice", Roles: []string{"sre"}}
+	if !p.Matches("user:alice") || !p.Matches("role:sre") {
+		t.Error("Expected the principal to match its user and role")
+	}
+	if p.Matches("user:bob") || p.Matches("role:dev") || p.Matches("alice") {
+		t.Error("Expected the principal not to match other entries")
+	}
+	if (Principal{}).Matches("user:") {
+		t.Error("Expected an anonymous principal not to match an empty user")
+	}
+
+	ctx := WithPrincipal(context.Background(), p)
+	if got := PrincipalFrom(ctx); got.User != "alice" {
+		t.Errorf("Expected alice from the context, got %+v", got)
+	}
+	if !PrincipalFrom(context.Background()).Anonymous() {
+		t.Error("Expected requests without identity to be anonymous")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	alice := Principal{User: "alice"}
+	bob := Principal{User: "bob", Roles: []string{"sre"}}
+	carol := Principal{User: "carol"}
+	admin := Principal{User: "dave", Roles: []string{AdminRole}}
+
+	if _, err := r.Set("t", KindAtom, "a1", ACL{Owner: "alice"}, alice); err == nil {
+		t.Error("Expected an owner without user: or role: to be rejected")
+	}
+	if err := r.Authorize("t", KindAtom, "a1", Principal{}); err != nil {
+		t.Errorf("Expected unprotected atoms to be writable, got %v", err)
+	}
+	if _, err := r.Set("t", KindAtom, "a1", ACL{Owner: "user:alice", Editors: []string{"role:sre"}}, alice); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	if r.Authorize("t", KindAtom, "a1", alice) != nil || r.Authorize("t", KindAtom, "a1", bob) != nil || r.Authorize("t", KindAtom, "a1", admin) != nil {
+		t.Error("Expected the owner, editors and admins to be allowed")
+	}
+	if err := r.Authorize("t", KindAtom, "a1", carol); !IsForbidden(err) {
+		t.Errorf("Expected others to be forbidden, got %v", err)
+	}
+	if r.Authorize("t", KindPipeline, "a1", carol) != nil || r.Authorize("t2", KindAtom, "a1", carol) != nil {
+		t.Error("Expected ACLs to be scoped by kind and tenant")
+	}
+
+	// Editors may change the resource but not its ACL
+	if _, err := r.Set("t", KindAtom, "a1", ACL{Owner: "user:bob"}, bob); !IsForbidden(err) {
+		t.Errorf("Expected an editor not to take ownership, got %v", err)
+	}
+	if err := r.Remove("t", KindAtom, "a1", bob); !IsForbidden(err) {
+		t.Errorf("Expected an editor not to remove the ACL, got %v", err)
+	}
+	if _, err := r.Set("t", KindAtom, "a1", ACL{Owner: "user:carol"}, alice); err != nil {
+		t.Fatalf("Expected the owner to hand over the atom, got %v", err)
+	}
+	if r.Authorize("t", KindAtom, "a1", carol) != nil || r.Authorize("t", KindAtom, "a1", alice) == nil {
+		t.Error("Expected the new owner alone to be allowed")
+	}
+	if err := r.Remove("t", KindAtom, "a1", admin); err != nil {
+		t.Errorf("Expected an admin to remove the ACL, got %v", err)
+	}
+
+	r.Set("t", KindAtom, "a2", ACL{Owner: "role:sre"}, bob)
+	r.Set("t", KindPipeline, "p1", ACL{Owner: "role:sre"}, bob)
+	if n := r.Purge("t"); n != 2 || len(r.List("t", KindAtom)) != 0 {
+		t.Errorf("Expected 2 purged ACLs, got %d", n)
+	}
+}
@@ -0,0 +1,28 @@
+package cognitive
+
+import (
+	"github.com/Avik2024/erebus/backend/internal/cognitive/admission"
+)
+
+// SetAdmissionHook creates or replaces one of a tenant's admission
+// webhooks. Mutating webhooks may patch atoms before they are created or
+// updated and validating webhooks may deny the write, so tenants can
+// normalize and validate their knowledge with their own services.
+func (ce *CognitiveEngine) SetAdmissionHook(tenantID string, hook admission.Hook) (admission.Hook, error) {
+	return ce.admissionHooks.Set(tenantID, hook)
+}
+
+// GetAdmissionHook returns one of a tenant's admission webhooks
+func (ce *CognitiveEngine) GetAdmissionHook(tenantID, name string) (admission.Hook, error) {
+	return ce.admissionHooks.Get(tenantID, name)
+}
+
+// ListAdmissionHooks returns a tenant's admission webhooks
+func (ce *CognitiveEngine) ListAdmissionHooks(tenantID string) []admission.Hook {
+	return ce.admissionHooks.List(tenantID)
+}
+
+// DeleteAdmissionHook removes one of a tenant's admission webhooks
+func (ce *CognitiveEngine) DeleteAdmissionHook(tenantID, name string) error {
+	return ce.admissionHooks.Delete(tenantID, name)
+}
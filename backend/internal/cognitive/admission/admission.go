@@ -0,0 +1,293 @@
+package admission
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// HookType is what a webhook may do with the atoms it reviews
+type HookType string
+
+const (
+	HookValidating HookType = "validating" // Allow or deny the write
+	HookMutating   HookType = "mutating"   // Also patch the atom before it is written
+)
+
+// Operation is the kind of write a webhook reviews
+type Operation string
+
+const (
+	OperationCreate Operation = "create"
+	OperationUpdate Operation = "update"
+)
+
+// FailurePolicy is what happens to a write when its webhook cannot be
+// called or answers with an invalid response
+type FailurePolicy string
+
+const (
+	FailurePolicyFail   FailurePolicy = "fail"   // Deny the write
+	FailurePolicyIgnore FailurePolicy = "ignore" // Proceed as if the webhook allowed it
+)
+
+// Timeouts of webhook calls
+const (
+	DefaultTimeout = 5 * time.Second
+	MaxTimeout     = 30 * time.Second
+)
+
+// Hook is an external webhook reviewing a tenant's atom writes
+type Hook struct {
+	Name          string               `json:"name"`
+	Type          HookType             `json:"type"`
+	URL           string               `json:"url"`
+	Operations    []Operation          `json:"operations,omitempty"` // Empty for all operations
+	AtomTypes     []atomspace.AtomType `json:"atom_types,omitempty"` // Empty for all atom types
+	Timeout       time.Duration        `json:"timeout_ns,omitempty"`
+	FailurePolicy FailurePolicy        `json:"failure_policy,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+	Stats         HookStats            `json:"stats"`
+}
+
+// HookStats counts the calls of a webhook
+type HookStats struct {
+	Calls      int64     `json:"calls"`
+	Denied     int64     `json:"denied"`
+	Mutated    int64     `json:"mutated"`
+	Failures   int64     `json:"failures"` // Calls that failed, whatever the failure policy
+	LastError  string    `json:"last_error,omitempty"`
+	LastCalled time.Time `json:"last_called,omitempty"`
+}
+
+// Validate checks the hook and fills in defaults
+func (h *Hook) Validate() error {
+	if h.Name == "" || strings.ContainsAny(h.Name, "/\r\n") {
+		return fmt.Errorf("hook name must be non-empty without slashes or line breaks")
+	}
+	switch h.Type {
+	case HookValidating, HookMutating:
+	default:
+		return fmt.Errorf("unknown hook type: %s", h.Type)
+	}
+	if !strings.HasPrefix(h.URL, "http://") && !strings.HasPrefix(h.URL, "https://") {
+		return fmt.Errorf("hook requires an http(s) url")
+	}
+	for _, op := range h.Operations {
+		if op != OperationCreate && op != OperationUpdate {
+			return fmt.Errorf("unknown operation: %s", op)
+		}
+	}
+	if h.Timeout == 0 {
+		h.Timeout = DefaultTimeout
+	}
+	if h.Timeout < 0 || h.Timeout > MaxTimeout {
+		return fmt.Errorf("timeout must be positive and at most %s", MaxTimeout)
+	}
+	switch h.FailurePolicy {
+	case "":
+		h.FailurePolicy = FailurePolicyFail
+	case FailurePolicyFail, FailurePolicyIgnore:
+	default:
+		return fmt.Errorf("unknown failure policy: %s", h.FailurePolicy)
+	}
+	return nil
+}
+
+// Matches reports whether the hook reviews an operation on an atom type
+func (h *Hook) Matches(op Operation, atomType atomspace.AtomType) bool {
+	if len(h.Operations) > 0 {
+		found := false
+		for _, o := range h.Operations {
+			found = found || o == op
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(h.AtomTypes) > 0 {
+		found := false
+		for _, t := range h.AtomTypes {
+			found = found || t == atomType
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// DeniedError is returned for writes a webhook denied, or that failed
+// calling a webhook whose failure policy is fail
+type DeniedError struct {
+	Hook   string
+	Reason string
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("admission webhook %s denied the request: %s", e.Hook, e.Reason)
+}
+
+// IsDenied reports whether a write was refused by admission
+func IsDenied(err error) bool {
+	var denied *DeniedError
+	return errors.As(err, &denied)
+}
+
+// Registry holds the webhooks of each tenant
+type Registry struct {
+	hooks map[string]map[string]*Hook // tenantID -> name -> hook
+	mu    sync.RWMutex
+}
+
+// NewRegistry creates an empty webhook registry
+func NewRegistry() *Registry {
+	return &Registry{hooks: make(map[string]map[string]*Hook)}
+}
+
+// Set creates or replaces a hook. The statistics of a replaced hook are
+// kept.
+func (r *Registry) Set(tenantID string, h Hook) (Hook, error) {
+	if err := h.Validate(); err != nil {
+		return Hook{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tenant, exists := r.hooks[tenantID]
+	if !exists {
+		tenant = make(map[string]*Hook)
+		r.hooks[tenantID] = tenant
+	}
+	if previous, exists := tenant[h.Name]; exists {
+		h.CreatedAt = previous.CreatedAt
+		h.Stats = previous.Stats
+	} else {
+		h.CreatedAt = time.Now()
+		h.Stats = HookStats{}
+	}
+	tenant[h.Name] = &h
+	return h, nil
+}
+
+// Get returns a hook
+func (r *Registry) Get(tenantID, name string) (Hook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	h, exists := r.hooks[tenantID][name]
+	if !exists {
+		return Hook{}, fmt.Errorf("admission hook %s not found", name)
+	}
+	return *h, nil
+}
+
+// List returns a tenant's hooks sorted by name
+func (r *Registry) List(tenantID string) []Hook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]Hook, 0, len(r.hooks[tenantID]))
+	for _, h := range r.hooks[tenantID] {
+		result = append(result, *h)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Delete removes a hook
+func (r *Registry) Delete(tenantID, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.hooks[tenantID][name]; !exists {
+		return fmt.Errorf("admission hook %s not found", name)
+	}
+	delete(r.hooks[tenantID], name)
+	return nil
+}
+
+// Matching returns the hooks reviewing an operation on an atom type in the
+// order they are called: mutating hooks first, so validating hooks see the
+// atom as it will be written, each kind sorted by name
+func (r *Registry) Matching(tenantID string, op Operation, atomType atomspace.AtomType) []Hook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []Hook
+	for _, h := range r.hooks[tenantID] {
+		if h.Matches(op, atomType) {
+			result = append(result, *h)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Type != result[j].Type {
+			return result[i].Type == HookMutating
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// record updates the statistics of a hook after a call
+func (r *Registry) record(tenantID, name string, denied, mutated bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h, exists := r.hooks[tenantID][name]
+	if !exists {
+		return
+	}
+	h.Stats.Calls++
+	h.Stats.LastCalled = time.Now()
+	if denied {
+		h.Stats.Denied++
+	}
+	if mutated {
+		h.Stats.Mutated++
+	}
+	if err != nil {
+		h.Stats.Failures++
+		h.Stats.LastError = err.Error()
+	}
+}
+
+// Purge deletes all hooks of a tenant and returns how many were removed
+func (r *Registry) Purge(tenantID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := len(r.hooks[tenantID])
+	delete(r.hooks, tenantID)
+	return purged
+}
+
+// GetStats returns registry statistics
+func (r *Registry) GetStats() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hooks := 0
+	var calls, denied, failures int64
+	for _, tenant := range r.hooks {
+		hooks += len(tenant)
+		for _, h := range tenant {
+			calls += h.Stats.Calls
+			denied += h.Stats.Denied
+			failures += h.Stats.Failures
+		}
+	}
+	return map[string]interface{}{
+		"tenants":  len(r.hooks),
+		"hooks":    hooks,
+		"calls":    calls,
+		"denied":   denied,
+		"failures": failures,
+	}
+}
@@ -0,0 +1,154 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func concept(name string) *atomspace.Node {
+	return atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, "t", atomspace.ConceptNodeType)
+}
+
+func TestValidate(t *testing.T) {
+	h := Hook{Name: "lint", Type: HookValidating, URL: "https://hooks.example.com/lint"}
+	if err := h.Validate(); err != nil {
+		t.Fatalf("Expected a valid hook: %v", err)
+	}
+	if h.Timeout != DefaultTimeout || h.FailurePolicy != FailurePolicyFail {
+		t.Errorf("Expected defaults to be filled in, got %+v", h)
+	}
+
+	invalid := []Hook{
+		{Type: HookValidating, URL: "https://x"},
+		{Name: "a/b", Type: HookValidating, URL: "https://x"},
+		{Name: "x", Type: "audit", URL: "https://x"},
+		{Name: "x", Type: HookMutating, URL: "ftp://x"},
+		{Name: "x", Type: HookMutating, URL: "https://x", Operations: []Operation{"delete"}},
+		{Name: "x", Type: HookMutating, URL: "https://x", Timeout: time.Hour},
+		{Name: "x", Type: HookMutating, URL: "https://x", FailurePolicy: "retry"},
+	}
+	for i, h := range invalid {
+		if h.Validate() == nil {
+			t.Errorf("Expected hook %d to be rejected", i)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.Set("t", Hook{Name: "b-lint", Type: HookValidating, URL: "https://x"})
+	r.Set("t", Hook{Name: "z-normalize", Type: HookMutating, URL: "https://x", Operations: []Operation{OperationCreate}})
+	r.Set("t", Hook{Name: "links", Type: HookValidating, URL: "https://x", AtomTypes: []atomspace.AtomType{atomspace.InheritanceLinkType}})
+
+	hooks := r.Matching("t", OperationCreate, atomspace.ConceptNodeType)
+	if len(hooks) != 2 || hooks[0].Name != "z-normalize" || hooks[1].Name != "b-lint" {
+		t.Errorf("Expected mutating hooks first, got %+v", hooks)
+	}
+	if hooks := r.Matching("t", OperationUpdate, atomspace.InheritanceLinkType); len(hooks) != 2 {
+		t.Errorf("Expected the hooks of updates to links, got %+v", hooks)
+	}
+	if len(r.Matching("other", OperationCreate, atomspace.ConceptNodeType)) != 0 {
+		t.Error("Expected hooks to be per tenant")
+	}
+
+	r.record("t", "b-lint", true, false, nil)
+	replaced, _ := r.Set("t", Hook{Name: "b-lint", Type: HookValidating, URL: "https://y"})
+	if replaced.Stats.Calls != 1 || replaced.Stats.Denied != 1 {
+		t.Errorf("Expected the statistics to be kept, got %+v", replaced.Stats)
+	}
+	if err := r.Delete("t", "b-lint"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := r.Get("t", "b-lint"); err == nil {
+		t.Error("Expected the hook to be deleted")
+	}
+}
+
+func TestAdmit(t *testing.T) {
+	var reviews []Review
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var review Review
+		json.NewDecoder(r.Body).Decode(&review)
+		reviews = append(reviews, review)
+
+		switch r.URL.Path {
+		case "/normalize":
+			name := strings.ToLower(review.Atom.Name)
+			json.NewEncoder(w).Encode(Response{Allowed: true, Patch: &Patch{Name: &name, TruthValue: &TruthValue{Strength: 0.9, Confidence: 0.8}}})
+		case "/lint":
+			allowed := !strings.Contains(review.Atom.Name, " ")
+			json.NewEncoder(w).Encode(Response{Allowed: allowed, Reason: "names must not contain spaces"})
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	r := NewRegistry()
+	r.Set("t", Hook{Name: "normalize", Type: HookMutating, URL: server.URL + "/normalize"})
+	r.Set("t", Hook{Name: "lint", Type: HookValidating, URL: server.URL + "/lint"})
+	c := NewController(r)
+
+	atom := concept("Checkout-API")
+	if err := c.Admit(context.Background(), OperationCreate, atom, nil); err != nil {
+		t.Fatalf("Expected the atom to be admitted: %v", err)
+	}
+	if atom.GetName() != "checkout-api" || atom.GetID() != concept("checkout-api").GetID() || atom.GetTruthValue().Strength != 0.9 {
+		t.Errorf("Expected the atom to be normalized, got %s %s %+v", atom.GetName(), atom.GetID(), atom.GetTruthValue())
+	}
+	if len(reviews) != 2 || reviews[1].Hook != "lint" || reviews[1].Atom.Name != "checkout-api" || reviews[0].UID == "" {
+		t.Errorf("Expected the validating hook to review the mutated atom, got %+v", reviews)
+	}
+
+	err := c.Admit(context.Background(), OperationCreate, concept("checkout api"), nil)
+	if !IsDenied(err) || !strings.Contains(err.Error(), "names must not contain spaces") {
+		t.Errorf("Expected the atom to be denied, got %v", err)
+	}
+
+	// Existing atoms cannot be renamed, so the mutating hook fails on update
+	old := concept("Checkout")
+	proposed := old.Clone()
+	reviews = nil
+	if err := c.Admit(context.Background(), OperationUpdate, proposed, old); !IsDenied(err) {
+		t.Errorf("Expected a rename on update to fail, got %v", err)
+	}
+	if reviews[0].OldAtom == nil || reviews[0].OldAtom.Name != "Checkout" {
+		t.Errorf("Expected the stored atom in the review, got %+v", reviews[0])
+	}
+
+	stats, _ := r.Get("t", "normalize")
+	if stats.Stats.Calls != 3 || stats.Stats.Mutated != 2 || stats.Stats.Failures != 1 || stats.Stats.LastError == "" {
+		t.Errorf("Unexpected statistics: %+v", stats.Stats)
+	}
+}
+
+func TestFailurePolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	r := NewRegistry()
+	r.Set("t", Hook{Name: "slow", Type: HookValidating, URL: server.URL, Timeout: 20 * time.Millisecond, FailurePolicy: FailurePolicyIgnore})
+	c := NewController(r)
+	if err := c.Admit(context.Background(), OperationCreate, concept("x"), nil); err != nil {
+		t.Errorf("Expected a timeout to be ignored, got %v", err)
+	}
+
+	r.Set("t", Hook{Name: "slow", Type: HookValidating, URL: server.URL, Timeout: 20 * time.Millisecond})
+	if err := c.Admit(context.Background(), OperationCreate, concept("x"), nil); !IsDenied(err) {
+		t.Errorf("Expected a timeout to deny the write, got %v", err)
+	}
+	if h, _ := r.Get("t", "slow"); h.Stats.Failures != 2 || h.Stats.Denied != 0 {
+		t.Errorf("Expected 2 failures, got %+v", h.Stats)
+	}
+}
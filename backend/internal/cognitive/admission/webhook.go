@@ -0,0 +1,207 @@
+package admission
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// maxResponseSize bounds the body read from a webhook
+const maxResponseSize = 1 << 20
+
+// TruthValue is the JSON form of an atom's truth value
+type TruthValue struct {
+	Strength   float64 `json:"strength"`
+	Confidence float64 `json:"confidence"`
+}
+
+// AttentionValue is the JSON form of an atom's attention value
+type AttentionValue struct {
+	STI  int16 `json:"sti"`
+	LTI  int16 `json:"lti"`
+	VLTI int16 `json:"vlti"`
+}
+
+// Object is an atom as sent to webhooks
+type Object struct {
+	ID             string             `json:"id"`
+	Type           atomspace.AtomType `json:"type"`
+	Name           string             `json:"name"`
+	TenantID       string             `json:"tenant_id"`
+	TruthValue     TruthValue         `json:"truth_value"`
+	AttentionValue AttentionValue     `json:"attention_value"`
+	Outgoing       []string           `json:"outgoing,omitempty"` // IDs of the atoms a link connects
+}
+
+// NewObject returns the webhook form of an atom
+func NewObject(atom atomspace.Atom) Object {
+	tv := atom.GetTruthValue()
+	av := atom.GetAttentionValue()
+	o := Object{
+		ID:             atom.GetID(),
+		Type:           atom.GetType(),
+		Name:           atom.GetName(),
+		TenantID:       atom.GetTenantID(),
+		TruthValue:     TruthValue{Strength: tv.Strength, Confidence: tv.Confidence},
+		AttentionValue: AttentionValue{STI: av.STI, LTI: av.LTI, VLTI: av.VLTI},
+	}
+	if link, ok := atom.(*atomspace.Link); ok {
+		for _, target := range link.GetOutgoing() {
+			o.Outgoing = append(o.Outgoing, target.GetID())
+		}
+	}
+	return o
+}
+
+// Review is the body posted to a webhook
+type Review struct {
+	UID       string    `json:"uid"`
+	Hook      string    `json:"hook"`
+	TenantID  string    `json:"tenant_id"`
+	Operation Operation `json:"operation"`
+	Atom      Object    `json:"atom"`
+	OldAtom   *Object   `json:"old_atom,omitempty"` // The stored atom, on update
+}
+
+// Response is a webhook's answer to a review
+type Response struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+	Patch   *Patch `json:"patch,omitempty"` // Mutating hooks only
+}
+
+// Patch lists the changes a mutating hook makes to an atom. Renaming a node
+// or link on create changes its ID; existing atoms cannot be renamed.
+type Patch struct {
+	Name           *string         `json:"name,omitempty"`
+	TruthValue     *TruthValue     `json:"truth_value,omitempty"`
+	AttentionValue *AttentionValue `json:"attention_value,omitempty"`
+}
+
+// Controller calls the webhooks of a tenant on its atom writes
+type Controller struct {
+	registry *Registry
+	client   *http.Client
+}
+
+// NewController creates a controller calling the hooks of a registry
+func NewController(registry *Registry) *Controller {
+	return &Controller{registry: registry, client: &http.Client{}}
+}
+
+// Admit reviews a write with the matching hooks of the atom's tenant.
+// Patches of mutating hooks are applied to atom in place; old is the stored
+// atom on update. It returns a *DeniedError if the write must not proceed.
+func (c *Controller) Admit(ctx context.Context, op Operation, atom, old atomspace.Atom) error {
+	tenantID := atom.GetTenantID()
+	for _, hook := range c.registry.Matching(tenantID, op, atom.GetType()) {
+		resp, err := c.call(ctx, hook, op, atom, old)
+		if err == nil && !resp.Allowed {
+			c.registry.record(tenantID, hook.Name, true, false, nil)
+			reason := resp.Reason
+			if reason == "" {
+				reason = "no reason given"
+			}
+			return &DeniedError{Hook: hook.Name, Reason: reason}
+		}
+		mutated := false
+		if err == nil && hook.Type == HookMutating && resp.Patch != nil {
+			err = apply(resp.Patch, op, atom)
+			mutated = err == nil
+		}
+		c.registry.record(tenantID, hook.Name, false, mutated, err)
+		if err != nil && hook.FailurePolicy == FailurePolicyFail {
+			return &DeniedError{Hook: hook.Name, Reason: err.Error()}
+		}
+	}
+	return nil
+}
+
+// call posts a review to a hook and decodes its response
+func (c *Controller) call(ctx context.Context, hook Hook, op Operation, atom, old atomspace.Atom) (Response, error) {
+	review := Review{
+		UID:       newUID(),
+		Hook:      hook.Name,
+		TenantID:  atom.GetTenantID(),
+		Operation: op,
+		Atom:      NewObject(atom),
+	}
+	if old != nil {
+		o := NewObject(old)
+		review.OldAtom = &o
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		return Response{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, hook.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return Response{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return Response{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return Response{}, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	var result Response
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&result); err != nil {
+		return Response{}, fmt.Errorf("invalid webhook response: %w", err)
+	}
+	return result, nil
+}
+
+// apply patches an atom in place
+func apply(patch *Patch, op Operation, atom atomspace.Atom) error {
+	if tv := patch.TruthValue; tv != nil {
+		if tv.Strength < 0 || tv.Strength > 1 || tv.Confidence < 0 || tv.Confidence > 1 {
+			return fmt.Errorf("patched truth value must be within [0, 1]")
+		}
+	}
+	if patch.Name != nil && *patch.Name != atom.GetName() {
+		if op != OperationCreate {
+			return fmt.Errorf("existing atoms cannot be renamed")
+		}
+		if *patch.Name == "" {
+			return fmt.Errorf("patched name must not be empty")
+		}
+		switch a := atom.(type) {
+		case *atomspace.Node:
+			a.Name = atomspace.Intern(*patch.Name)
+			a.ID = atomspace.GenerateAtomID(a.Type, a.Name, nil)
+		case *atomspace.Link:
+			a.Name = atomspace.Intern(*patch.Name)
+			a.ID = atomspace.GenerateAtomID(a.Type, a.Name, a.Outgoing)
+		default:
+			return fmt.Errorf("atoms of type %T cannot be renamed", atom)
+		}
+	}
+	if tv := patch.TruthValue; tv != nil {
+		atom.SetTruthValue(atomspace.TruthValue{Strength: tv.Strength, Confidence: tv.Confidence})
+	}
+	if av := patch.AttentionValue; av != nil {
+		atom.SetAttentionValue(atomspace.AttentionValue{STI: av.STI, LTI: av.LTI, VLTI: av.VLTI})
+	}
+	return nil
+}
+
+func newUID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,107 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/debugger"
+)
+
+// recordRun wraps the runs of agents in debug mode, and of agents stepped
+// on demand, to record the attentional focus they saw, the decisions they
+// reported and the atoms that changed
+func (ce *CognitiveEngine) recordRun(agent agents.Agent, run func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		step := debugger.StepFrom(ctx)
+		if step == nil && !ce.debugger.Enabled(agent.GetID()) {
+			return run(ctx)
+		}
+
+		tenantID := agent.GetTenantID()
+		focusSize := ce.debugger.Config().FocusSize
+		before := debugger.Capture(ce.QueryAtoms(tenantID, nil), focusSize)
+		startedAt := time.Now()
+		traced, trace := ce.debugger.Begin(ctx)
+		err := run(traced)
+		after := debugger.Capture(ce.QueryAtoms(tenantID, nil), focusSize)
+
+		rec := ce.debugger.Finish(tenantID, agent.GetID(), step != nil, trace, before, after, startedAt, err)
+		if step != nil {
+			step.Set(rec)
+		}
+		return err
+	}
+}
+
+// tenantAgent returns a registered agent of a tenant
+func (ce *CognitiveEngine) tenantAgent(tenantID, agentID string) (agents.Agent, error) {
+	agent, exists := ce.agentScheduler.GetAgent(agentID)
+	if !exists || agent.GetTenantID() != tenantID {
+		return nil, fmt.Errorf("agent %s not found", agentID)
+	}
+	return agent, nil
+}
+
+// SetAgentDebug puts an agent in or out of debug mode. Every run of an
+// agent in debug mode is recorded; recordings are kept when it leaves.
+func (ce *CognitiveEngine) SetAgentDebug(tenantID, agentID string, enabled bool) error {
+	if _, err := ce.tenantAgent(tenantID, agentID); err != nil {
+		return err
+	}
+	ce.debugger.SetEnabled(tenantID, agentID, enabled)
+	return nil
+}
+
+// AgentDebugEnabled reports whether an agent is in debug mode
+func (ce *CognitiveEngine) AgentDebugEnabled(tenantID, agentID string) (bool, error) {
+	if _, err := ce.tenantAgent(tenantID, agentID); err != nil {
+		return false, err
+	}
+	return ce.debugger.Enabled(agentID), nil
+}
+
+// GetAgentRecordings returns the recorded runs of an agent, oldest first
+func (ce *CognitiveEngine) GetAgentRecordings(tenantID, agentID string) ([]debugger.Recording, error) {
+	if _, err := ce.tenantAgent(tenantID, agentID); err != nil {
+		return nil, err
+	}
+	return ce.debugger.Recordings(agentID), nil
+}
+
+// GetAgentRecording returns one recorded run of an agent by sequence
+func (ce *CognitiveEngine) GetAgentRecording(tenantID, agentID string, sequence int64) (debugger.Recording, error) {
+	if _, err := ce.tenantAgent(tenantID, agentID); err != nil {
+		return debugger.Recording{}, err
+	}
+	return ce.debugger.Get(agentID, sequence)
+}
+
+// ClearAgentRecordings forgets the recorded runs of an agent, returning how
+// many were dropped
+func (ce *CognitiveEngine) ClearAgentRecordings(tenantID, agentID string) (int, error) {
+	if _, err := ce.tenantAgent(tenantID, agentID); err != nil {
+		return 0, err
+	}
+	return ce.debugger.Clear(agentID), nil
+}
+
+// StepAgent runs one cycle of an agent on demand, whether or not it is in
+// debug mode, and returns the recording of the run. A failing run is
+// recorded too; its error is in the recording.
+func (ce *CognitiveEngine) StepAgent(ctx context.Context, tenantID, agentID string) (debugger.Recording, error) {
+	if _, err := ce.tenantAgent(tenantID, agentID); err != nil {
+		return debugger.Recording{}, err
+	}
+	step := &debugger.Step{}
+	err := ce.agentScheduler.RunAgent(debugger.WithStep(ctx, step), agentID)
+	rec, ok := step.Recording()
+	if !ok {
+		if err == nil {
+			err = fmt.Errorf("agent %s was not run", agentID)
+		}
+		return debugger.Recording{}, err
+	}
+	return rec, nil
+}
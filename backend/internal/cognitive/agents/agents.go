@@ -2,11 +2,16 @@ package agents
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/attention"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/budget"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/debugger"
 	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/retry"
 )
 
 // Agent represents an autonomous cognitive agent
@@ -117,27 +122,33 @@ func (ma *MindAgent) Run(ctx context.Context) error {
 		ma.RunCount++
 		ma.LastRun = time.Now()
 		ma.TotalTime += duration
-		ma.State = AgentStateIdle
+		if ma.State != AgentStateError {
+			ma.State = AgentStateIdle
+		}
 		ma.mu.Unlock()
 	}()
 	
 	// Run inference cycle
-	_, err := ma.inference.RunInference(ctx, ma.TenantID, 5)
+	var report inference.RunReport
+	results, err := ma.inference.RunInference(inference.WithReport(ctx, &report), ma.TenantID, 5)
 	if err != nil {
 		ma.mu.Lock()
 		ma.State = AgentStateError
 		ma.mu.Unlock()
 		return err
 	}
+	debugger.Decide(ctx, "inference", ma.TenantID, report.Status,
+		map[string]interface{}{"iterations": report.Iterations, "results": len(results), "depth": report.Depth, "depth_limited": report.DepthLimited, "cycles": report.Cycles})
 	
 	return nil
 }
 
-// AttentionAgent manages attention allocation across atoms
+// AttentionAgent manages attention allocation across atoms, following the
+// attention params of its tenant
 type AttentionAgent struct {
 	BaseAgent
 	atomSpace atomspace.AtomSpaceInterface
-	focusSize int
+	params    func(tenantID string) attention.Params // Nil uses the default params
 }
 
 // NewAttentionAgent creates a new attention allocation agent
@@ -151,10 +162,17 @@ func NewAttentionAgent(id, name, tenantID string, atomSpace atomspace.AtomSpaceI
 			State:    AgentStateIdle,
 		},
 		atomSpace: atomSpace,
-		focusSize: 100,
 	}
 }
 
+// SetParams sets the function returning the tenant's attention params,
+// read at every run
+func (aa *AttentionAgent) SetParams(params func(tenantID string) attention.Params) {
+	aa.mu.Lock()
+	defer aa.mu.Unlock()
+	aa.params = params
+}
+
 // Run executes the attention allocation cycle
 func (aa *AttentionAgent) Run(ctx context.Context) error {
 	aa.mu.Lock()
@@ -172,25 +190,27 @@ func (aa *AttentionAgent) Run(ctx context.Context) error {
 		aa.mu.Unlock()
 	}()
 	
+	aa.mu.RLock()
+	params := attention.DefaultParams()
+	if aa.params != nil {
+		params = aa.params(aa.TenantID)
+	}
+	aa.mu.RUnlock()
+	
 	// Get all atoms for this tenant
 	atoms := aa.atomSpace.QueryAtoms(aa.TenantID, nil)
 	
-	// Update attention values based on usage and importance
+	// Decay STI over time and boost important atoms
+	boosted := 0
 	for _, atom := range atoms {
-		av := atom.GetAttentionValue()
-		
-		// Decay STI over time
-		av.STI = int16(float64(av.STI) * 0.95)
-		
-		// Boost important atoms (high truth value)
-		tv := atom.GetTruthValue()
-		if tv.Strength > 0.8 && tv.Confidence > 0.8 {
-			av.STI += 10
-			av.LTI += 1
+		av, raised := params.Apply(atom.GetAttentionValue(), atom.GetTruthValue())
+		if raised {
+			boosted++
 		}
-		
 		atom.SetAttentionValue(av)
 	}
+	debugger.Decide(ctx, "attention", aa.TenantID, fmt.Sprintf("STI decayed by %.0f%%, boosted by %d rules", (1-params.DecayRate)*100, len(params.Boosts)),
+		map[string]interface{}{"atoms": len(atoms), "boosted": boosted})
 	
 	return nil
 }
@@ -207,6 +227,47 @@ type AgentScheduler struct {
 	runChan        chan agentRunRequest
 	done           chan struct{}
 	
+	// Called when a scheduled agent run fails or times out
+	failureHandler func(agent Agent, err error)
+	
+	// Called after every scheduled or on-demand agent run
+	runHandler func(agent Agent, err error)
+	
+	// Wraps every run of an agent, e.g. to record it in debug mode
+	runWrapper func(agent Agent, run func(ctx context.Context) error) func(ctx context.Context) error
+	
+	// Reports whether an agent is paused, e.g. by a maintenance window;
+	// paused agents are left out of scheduling cycles
+	paused func(agent Agent) bool
+	
+	// Learned offsets added to agent priorities
+	priorityAdjustments map[string]int
+	
+	// Tracks failures and restarts of scheduled agents
+	supervisor *supervisor
+	
+	// Per-tenant resource accounting and budgets
+	budgets *budget.Tracker
+	
+	// Retry behaviour of agent runs, overridable per agent
+	retryPolicy   retry.Policy
+	agentPolicies map[string]retry.Policy
+	retries       map[string]int64 // agentID -> retries performed
+	
+	// Per-tenant run groups ordering agents within a cycle
+	runPlans map[string]*compiledPlan
+	
+	// Supervised daemon agents, started outside the scheduling cycle
+	daemons map[string]*daemonRunner
+	closing bool
+	
+	// Set on replicas that are not leader: no cycles run and no daemons
+	// are started, so that they do not run twice across replicas
+	standby bool
+	
+	// Partition keys of the agents this replica runs; nil owns every agent
+	owns func(key string) bool
+	
 	workers int
 }
 
@@ -225,6 +286,14 @@ func NewAgentScheduler(workers int) *AgentScheduler {
 		unregisterChan: make(chan string, 100),
 		runChan:        make(chan agentRunRequest, 1000),
 		done:           make(chan struct{}),
+		supervisor:     newSupervisor(DefaultSupervisorPolicy()),
+		budgets:        budget.NewTracker(),
+		retryPolicy:    retry.NoRetry(),
+		agentPolicies:  make(map[string]retry.Policy),
+		retries:        make(map[string]int64),
+		runPlans:       make(map[string]*compiledPlan),
+		daemons:        make(map[string]*daemonRunner),
+		priorityAdjustments: make(map[string]int),
 		workers:        workers,
 	}
 	
@@ -244,7 +313,23 @@ func (as *AgentScheduler) worker() {
 	for {
 		select {
 		case req := <-as.runChan:
-			err := req.agent.Run(req.ctx)
+			as.mu.RLock()
+			policy, ok := as.agentPolicies[req.agent.GetID()]
+			if !ok {
+				policy = as.retryPolicy
+			}
+			run := req.agent.Run
+			if as.runWrapper != nil {
+				run = as.runWrapper(req.agent, run)
+			}
+			as.mu.RUnlock()
+			
+			retries, err := policy.Do(req.ctx, run)
+			if retries > 0 {
+				as.mu.Lock()
+				as.retries[req.agent.GetID()] += int64(retries)
+				as.mu.Unlock()
+			}
 			req.response <- err
 		case <-as.done:
 			return
@@ -279,10 +364,24 @@ func (as *AgentScheduler) RegisterAgent(agent Agent) {
 // registerInternal is the internal implementation
 func (as *AgentScheduler) registerInternal(agent Agent) {
 	as.mu.Lock()
-	defer as.mu.Unlock()
 	
+	// A re-registered daemon replaces the running instance
+	previous := as.stopDaemonLocked(agent.GetID())
 	as.agents[agent.GetID()] = agent
 	as.rebuildPriorityQueue()
+	as.mu.Unlock()
+	
+	daemon, isDaemon := agent.(DaemonAgent)
+	if !isDaemon {
+		return
+	}
+	if previous != nil {
+		<-previous
+	}
+	
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.startDaemonLocked(daemon)
 }
 
 // UnregisterAgent removes an agent
@@ -296,6 +395,9 @@ func (as *AgentScheduler) unregisterInternal(agentID string) {
 	defer as.mu.Unlock()
 	
 	delete(as.agents, agentID)
+	delete(as.agentPolicies, agentID)
+	as.stopDaemonLocked(agentID)
+	as.supervisor.forget(agentID)
 	as.rebuildPriorityQueue()
 }
 
@@ -303,29 +405,111 @@ func (as *AgentScheduler) unregisterInternal(agentID string) {
 func (as *AgentScheduler) rebuildPriorityQueue() {
 	as.priority = make([]Agent, 0, len(as.agents))
 	for _, agent := range as.agents {
+		// Daemons run continuously rather than once per cycle
+		if _, isDaemon := agent.(DaemonAgent); isDaemon {
+			continue
+		}
 		as.priority = append(as.priority, agent)
 	}
 	
 	// Sort by priority (higher priority first)
 	for i := 0; i < len(as.priority); i++ {
 		for j := i + 1; j < len(as.priority); j++ {
-			if as.priority[i].GetPriority() < as.priority[j].GetPriority() {
+			if as.effectivePriorityLocked(as.priority[i]) < as.effectivePriorityLocked(as.priority[j]) {
 				as.priority[i], as.priority[j] = as.priority[j], as.priority[i]
 			}
 		}
 	}
 }
 
-// scheduleAgents runs agents in priority order
+// PartitionKey returns the key an agent is partitioned across replicas
+// by: its ID, or its tenant's if the tenant has a run plan, so that the
+// tenant's run groups run in order on one replica. Daemons are always
+// partitioned by ID, as run plans do not order them.
+func (as *AgentScheduler) PartitionKey(agent Agent) string {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+	return as.partitionKeyLocked(agent)
+}
+
+func (as *AgentScheduler) partitionKeyLocked(agent Agent) string {
+	if _, isDaemon := agent.(DaemonAgent); !isDaemon {
+		if _, planned := as.runPlans[agent.GetTenantID()]; planned {
+			return "tenant:" + agent.GetTenantID()
+		}
+	}
+	return agent.GetID()
+}
+
+// ownsLocked reports whether this replica runs an agent
+func (as *AgentScheduler) ownsLocked(agent Agent) bool {
+	return as.owns == nil || as.owns(as.partitionKeyLocked(agent))
+}
+
+// scheduleAgents runs agents in priority order, following the run groups of
+// tenants that have them
 func (as *AgentScheduler) scheduleAgents() {
 	as.mu.RLock()
-	agentsToRun := make([]Agent, len(as.priority))
-	copy(agentsToRun, as.priority)
+	if as.standby {
+		as.mu.RUnlock()
+		return
+	}
+	agentsToRun := as.cycleOrderLocked()
+	if as.owns != nil {
+		owned := agentsToRun[:0]
+		for _, entry := range agentsToRun {
+			if as.ownsLocked(entry.agent) {
+				owned = append(owned, entry)
+			}
+		}
+		agentsToRun = owned
+	}
 	as.mu.RUnlock()
 	
-	// Run agents in priority order
-	for _, agent := range agentsToRun {
+	as.mu.RLock()
+	paused := as.paused
+	as.mu.RUnlock()
+	
+	// Tenants over budget are paused or moved behind everyone else
+	now := time.Now()
+	ordered := make([]scheduledAgent, 0, len(agentsToRun))
+	var deprioritized []scheduledAgent
+	for _, entry := range agentsToRun {
+		if paused != nil && paused(entry.agent) {
+			continue
+		}
+		exhausted, action := as.budgets.Exhausted(entry.agent.GetTenantID(), now)
+		switch {
+		case !exhausted:
+			ordered = append(ordered, entry)
+		case action == budget.ActionDeprioritize:
+			deprioritized = append(deprioritized, entry)
+		}
+	}
+	ordered = append(ordered, deprioritized...)
+	
+	// Run groups that failed or were skipped in this cycle, keyed by
+	// tenant/group
+	failedGroups := make(map[string]bool)
+	markFailed := func(entry scheduledAgent) {
+		if entry.group != "" {
+			failedGroups[entry.agent.GetTenantID()+"/"+entry.group] = true
+		}
+	}
+	
+	// Run agents in order, skipping those in restart backoff or quarantine
+	// and those whose required run groups failed
+	for _, entry := range ordered {
+		agent := entry.agent
+		if as.groupBlocked(agent.GetTenantID(), entry.group, failedGroups) || !as.supervisor.allow(agent, time.Now()) {
+			markFailed(entry)
+			continue
+		}
+		
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		meter := &budget.Meter{}
+		ctx = budget.WithMeter(ctx, meter)
+		start := time.Now()
 		
 		response := make(chan error, 1)
 		as.runChan <- agentRunRequest{
@@ -335,17 +519,160 @@ func (as *AgentScheduler) scheduleAgents() {
 		}
 		
 		// Wait for completion or timeout
+		var err error
 		select {
-		case <-response:
+		case err = <-response:
 			// Agent completed
 		case <-ctx.Done():
-			// Timeout
+			err = fmt.Errorf("agent %s timed out: %w", agent.GetID(), ctx.Err())
 		}
 		
 		cancel()
+		as.budgets.Charge(agent.GetTenantID(), agent.GetID(), time.Since(start), meter.Iterations(), time.Now())
+		as.notifyRun(agent, err)
+		
+		if err == nil {
+			as.supervisor.success(agent)
+		} else {
+			markFailed(entry)
+			as.supervisor.failure(agent, err, time.Now())
+			
+			as.mu.RLock()
+			handler := as.failureHandler
+			as.mu.RUnlock()
+			if handler != nil {
+				handler(agent, err)
+			}
+		}
 	}
 }
 
+// SetRetryPolicy sets the retry policy of agents without their own policy
+func (as *AgentScheduler) SetRetryPolicy(policy retry.Policy) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.retryPolicy = policy
+}
+
+// SetAgentRetryPolicy overrides the retry policy of one agent
+func (as *AgentScheduler) SetAgentRetryPolicy(agentID string, policy retry.Policy) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.agentPolicies[agentID] = policy
+}
+
+// OnFailure sets the handler called when a scheduled agent run fails
+func (as *AgentScheduler) OnFailure(handler func(agent Agent, err error)) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.failureHandler = handler
+}
+
+// OnRun sets the handler called after every agent run
+func (as *AgentScheduler) OnRun(handler func(agent Agent, err error)) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.runHandler = handler
+}
+
+// WrapRuns sets the function wrapping every run of an agent, scheduled or
+// on demand; each attempt of a retried run is wrapped
+func (as *AgentScheduler) WrapRuns(wrapper func(agent Agent, run func(ctx context.Context) error) func(ctx context.Context) error) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.runWrapper = wrapper
+}
+
+func (as *AgentScheduler) notifyRun(agent Agent, err error) {
+	as.mu.RLock()
+	handler := as.runHandler
+	as.mu.RUnlock()
+	if handler != nil {
+		handler(agent, err)
+	}
+}
+
+// SetPause sets the function reporting whether an agent is paused. Paused
+// agents are skipped by scheduling cycles but can still be run on demand.
+func (as *AgentScheduler) SetPause(paused func(agent Agent) bool) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.paused = paused
+}
+
+// SetPriorityAdjustment offsets an agent's priority, e.g. by learned
+// feedback, and reorders the schedule
+func (as *AgentScheduler) SetPriorityAdjustment(agentID string, delta int) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	
+	if delta == 0 {
+		delete(as.priorityAdjustments, agentID)
+	} else {
+		as.priorityAdjustments[agentID] = delta
+	}
+	as.rebuildPriorityQueue()
+}
+
+// GetEffectivePriority returns an agent's priority including its adjustment
+func (as *AgentScheduler) GetEffectivePriority(agentID string) (int, bool) {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+	
+	agent, exists := as.agents[agentID]
+	if !exists {
+		return 0, false
+	}
+	return as.effectivePriorityLocked(agent), true
+}
+
+func (as *AgentScheduler) effectivePriorityLocked(agent Agent) int {
+	return agent.GetPriority() + as.priorityAdjustments[agent.GetID()]
+}
+
+// RunAgent runs a registered agent once on a scheduler worker
+func (as *AgentScheduler) RunAgent(ctx context.Context, agentID string) error {
+	agent, exists := as.GetAgent(agentID)
+	if !exists {
+		return fmt.Errorf("agent %s not found", agentID)
+	}
+	if _, isDaemon := agent.(DaemonAgent); isDaemon {
+		return fmt.Errorf("agent %s is a daemon and cannot be run on demand", agentID)
+	}
+	
+	meter := &budget.Meter{}
+	start := time.Now()
+	
+	response := make(chan error, 1)
+	as.runChan <- agentRunRequest{
+		agent:    agent,
+		ctx:      budget.WithMeter(ctx, meter),
+		response: response,
+	}
+	
+	var err error
+	select {
+	case err = <-response:
+	case <-ctx.Done():
+		err = fmt.Errorf("agent %s timed out: %w", agentID, ctx.Err())
+	}
+	
+	as.budgets.Charge(agent.GetTenantID(), agentID, time.Since(start), meter.Iterations(), time.Now())
+	as.notifyRun(agent, err)
+	
+	if err == nil {
+		as.supervisor.success(agent)
+	} else {
+		as.supervisor.failure(agent, err, time.Now())
+	}
+	return err
+}
+
+// Budgets returns the per-tenant resource tracker
+func (as *AgentScheduler) Budgets() *budget.Tracker {
+	return as.budgets
+}
+
 // GetAgent retrieves an agent by ID
 func (as *AgentScheduler) GetAgent(agentID string) (Agent, bool) {
 	as.mu.RLock()
@@ -393,15 +720,31 @@ func (as *AgentScheduler) GetStats() map[string]interface{} {
 		agentStats = append(agentStats, agent.GetStats())
 	}
 	
+	retries := make(map[string]int64, len(as.retries))
+	var totalRetries int64
+	for agentID, count := range as.retries {
+		retries[agentID] = count
+		totalRetries += count
+	}
+	
 	return map[string]interface{}{
 		"total_agents": len(as.agents),
 		"workers":      as.workers,
 		"agents":       agentStats,
+		"retries":       totalRetries,
+		"agent_retries": retries,
+		"health":        as.healthSummary(),
+		"failures":      as.totalFailures(),
+		"budgets":       as.budgets.GetStats(),
+		"run_plans":     len(as.runPlans),
+		"daemons":       len(as.daemons),
+		"standby":       as.standby,
 	}
 }
 
-// Close shuts down the scheduler
+// Close drains daemon agents and shuts down the scheduler
 func (as *AgentScheduler) Close() {
+	as.stopDaemons()
 	close(as.done)
 }
 
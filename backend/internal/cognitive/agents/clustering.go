@@ -0,0 +1,441 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// ClusterPrefix starts the names of the parent concepts created by the
+// clustering agent
+const ClusterPrefix = "cluster:"
+
+// EmbeddingFunc returns the embedding of an atom, if it has one
+type EmbeddingFunc func(atom atomspace.Atom) ([]float64, bool)
+
+// ClusteringConfig controls how concepts are grouped
+type ClusteringConfig struct {
+	Threshold       float64       `json:"threshold"` // Minimum similarity for two concepts to join a cluster
+	MinClusterSize  int           `json:"min_cluster_size"`
+	MaxClusterSize  int           `json:"max_cluster_size"`
+	MaxConcepts     int           `json:"max_concepts"` // Concepts considered per run
+	LinkWeight      float64       `json:"link_weight"`
+	LabelWeight     float64       `json:"label_weight"`
+	EmbeddingWeight float64       `json:"embedding_weight"` // Only used when an embedding function is set
+	Interval        time.Duration `json:"interval_ns"`      // Minimum time between scheduled runs
+}
+
+// DefaultClusteringConfig returns the default clustering configuration
+func DefaultClusteringConfig() ClusteringConfig {
+	return ClusteringConfig{
+		Threshold:       0.5,
+		MinClusterSize:  3,
+		MaxClusterSize:  100,
+		MaxConcepts:     5000,
+		LinkWeight:      0.6,
+		LabelWeight:     0.4,
+		EmbeddingWeight: 0.5,
+		Interval:        5 * time.Minute,
+	}
+}
+
+// Cluster is a group of similar concepts under a materialized parent concept
+type Cluster struct {
+	ID       string   `json:"id"` // ID of the parent concept
+	Name     string   `json:"name"`
+	Members  []string `json:"members"`
+	Cohesion float64  `json:"cohesion"` // Average similarity of linked members
+}
+
+// ClusteringAgent groups ConceptNodes by structural similarity and links
+// each group to a new parent concept with InheritanceLinks
+type ClusteringAgent struct {
+	BaseAgent
+	atomSpace atomspace.AtomSpaceInterface
+	config    ClusteringConfig
+	embed     EmbeddingFunc
+	clusters  []Cluster
+	lastRun   time.Time
+	runMu     sync.Mutex
+}
+
+// NewClusteringAgent creates a new concept clustering agent
+func NewClusteringAgent(id, name, tenantID string, atomSpace atomspace.AtomSpaceInterface, config ClusteringConfig) *ClusteringAgent {
+	return &ClusteringAgent{
+		BaseAgent: BaseAgent{
+			ID:       id,
+			Name:     name,
+			TenantID: tenantID,
+			Priority: 1,
+			State:    AgentStateIdle,
+		},
+		atomSpace: atomSpace,
+		config:    config,
+	}
+}
+
+// SetConfig replaces the clustering configuration
+func (ca *ClusteringAgent) SetConfig(config ClusteringConfig) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	ca.config = config
+}
+
+// GetConfig returns the clustering configuration
+func (ca *ClusteringAgent) GetConfig() ClusteringConfig {
+	ca.mu.RLock()
+	defer ca.mu.RUnlock()
+	return ca.config
+}
+
+// SetEmbeddingFunc enables embedding distance as a similarity signal
+func (ca *ClusteringAgent) SetEmbeddingFunc(embed EmbeddingFunc) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	ca.embed = embed
+}
+
+// GetClusters returns the clusters found by the latest run
+func (ca *ClusteringAgent) GetClusters() []Cluster {
+	ca.mu.RLock()
+	defer ca.mu.RUnlock()
+	return append([]Cluster(nil), ca.clusters...)
+}
+
+// Run clusters concepts once the configured interval has elapsed
+func (ca *ClusteringAgent) Run(ctx context.Context) error {
+	ca.mu.RLock()
+	due := time.Since(ca.lastRun) >= ca.config.Interval
+	ca.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	_, err := ca.Cluster(ctx)
+	return err
+}
+
+// Cluster groups the tenant's concepts now and returns the clusters found
+func (ca *ClusteringAgent) Cluster(ctx context.Context) ([]Cluster, error) {
+	ca.runMu.Lock()
+	defer ca.runMu.Unlock()
+
+	ca.mu.Lock()
+	ca.State = AgentStateRunning
+	config := ca.config
+	embed := ca.embed
+	ca.mu.Unlock()
+
+	start := time.Now()
+	clusters, err := ca.cluster(ctx, config, embed)
+
+	ca.mu.Lock()
+	ca.RunCount++
+	ca.LastRun = time.Now()
+	ca.TotalTime += time.Since(start)
+	ca.lastRun = start
+	if err != nil {
+		ca.State = AgentStateError
+	} else {
+		ca.State = AgentStateIdle
+		ca.clusters = clusters
+	}
+	ca.mu.Unlock()
+
+	return clusters, err
+}
+
+// conceptProfile holds the similarity signals of one concept
+type conceptProfile struct {
+	atom      atomspace.Atom
+	features  map[string]bool
+	tokens    map[string]bool
+	embedding []float64
+}
+
+func (ca *ClusteringAgent) cluster(ctx context.Context, config ClusteringConfig, embed EmbeddingFunc) ([]Cluster, error) {
+	atoms := ca.atomSpace.QueryAtoms(ca.TenantID, nil)
+
+	// Profile every flat concept by its links and name tokens
+	profiles := make(map[string]*conceptProfile)
+	var names []string
+	for _, atom := range atoms {
+		if atom.GetType() != atomspace.ConceptNodeType || !isClusterCandidate(atom.GetName()) {
+			continue
+		}
+		profile := &conceptProfile{atom: atom, features: make(map[string]bool), tokens: nameTokens(atom.GetName())}
+		if embed != nil {
+			profile.embedding, _ = embed(atom)
+		}
+		profiles[atom.GetName()] = profile
+		names = append(names, atom.GetName())
+	}
+	sort.Strings(names)
+	if config.MaxConcepts > 0 && len(names) > config.MaxConcepts {
+		for _, name := range names[config.MaxConcepts:] {
+			delete(profiles, name)
+		}
+		names = names[:config.MaxConcepts]
+	}
+
+	for _, atom := range atoms {
+		link, ok := atom.(*atomspace.Link)
+		if !ok {
+			continue
+		}
+		for subject, feature := range linkFeatures(link, nil) {
+			// Membership in earlier clusters is not a similarity signal
+			if profile, exists := profiles[subject]; exists && !strings.Contains(feature, ClusterPrefix) {
+				profile.features[feature] = true
+			}
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Candidate pairs share a link feature or a name token; with
+	// embeddings every pair is a candidate
+	index := make(map[string][]string)
+	for _, name := range names {
+		for feature := range profiles[name].features {
+			index["f:"+feature] = append(index["f:"+feature], name)
+		}
+		for token := range profiles[name].tokens {
+			index["t:"+token] = append(index["t:"+token], name)
+		}
+	}
+	candidates := make(map[[2]string]bool)
+	if embed != nil && config.EmbeddingWeight > 0 {
+		for i := range names {
+			for j := i + 1; j < len(names); j++ {
+				candidates[[2]string{names[i], names[j]}] = true
+			}
+		}
+	} else {
+		for _, group := range index {
+			for i := range group {
+				for j := i + 1; j < len(group); j++ {
+					a, b := group[i], group[j]
+					if a > b {
+						a, b = b, a
+					}
+					candidates[[2]string{a, b}] = true
+				}
+			}
+		}
+	}
+
+	type edge struct {
+		a, b       string
+		similarity float64
+	}
+	var edges []edge
+	for pair := range candidates {
+		sim := similarity(profiles[pair[0]], profiles[pair[1]], config)
+		if sim >= config.Threshold {
+			edges = append(edges, edge{a: pair[0], b: pair[1], similarity: sim})
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].similarity != edges[j].similarity {
+			return edges[i].similarity > edges[j].similarity
+		}
+		if edges[i].a != edges[j].a {
+			return edges[i].a < edges[j].a
+		}
+		return edges[i].b < edges[j].b
+	})
+
+	// Merge the most similar pairs first, bounded by the maximum size
+	parent := make(map[string]string, len(names))
+	size := make(map[string]int, len(names))
+	for _, name := range names {
+		parent[name] = name
+		size[name] = 1
+	}
+	var find func(string) string
+	find = func(x string) string {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	simSum := make(map[string]float64)
+	simCount := make(map[string]int)
+	for _, e := range edges {
+		ra, rb := find(e.a), find(e.b)
+		if ra != rb {
+			if config.MaxClusterSize > 0 && size[ra]+size[rb] > config.MaxClusterSize {
+				continue
+			}
+			parent[rb] = ra
+			size[ra] += size[rb]
+			simSum[ra] += simSum[rb]
+			simCount[ra] += simCount[rb]
+		}
+		simSum[ra] += e.similarity
+		simCount[ra]++
+	}
+
+	groups := make(map[string][]string)
+	for _, name := range names {
+		root := find(name)
+		groups[root] = append(groups[root], name)
+	}
+
+	var clusters []Cluster
+	used := make(map[string]bool)
+	for root, members := range groups {
+		if len(members) < config.MinClusterSize {
+			continue
+		}
+		name := ClusterPrefix + clusterLabel(members, profiles)
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s%s-%d", ClusterPrefix, clusterLabel(members, profiles), i)
+		}
+		used[name] = true
+
+		clusters = append(clusters, Cluster{
+			Name:     name,
+			Members:  members,
+			Cohesion: simSum[root] / float64(simCount[root]),
+		})
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].Name < clusters[j].Name })
+
+	for i := range clusters {
+		id, err := ca.recordCluster(clusters[i], profiles)
+		if err != nil {
+			return nil, err
+		}
+		clusters[i].ID = id
+	}
+	return clusters, nil
+}
+
+// similarity combines link overlap, label overlap and embedding distance,
+// weighted over the signals available for both concepts
+func similarity(a, b *conceptProfile, config ClusteringConfig) float64 {
+	var total, weights float64
+	if len(a.features) > 0 || len(b.features) > 0 {
+		total += config.LinkWeight * jaccard(a.features, b.features)
+		weights += config.LinkWeight
+	}
+	if len(a.tokens) > 0 || len(b.tokens) > 0 {
+		total += config.LabelWeight * jaccard(a.tokens, b.tokens)
+		weights += config.LabelWeight
+	}
+	if len(a.embedding) > 0 && len(a.embedding) == len(b.embedding) {
+		total += config.EmbeddingWeight * math.Max(0, cosine(a.embedding, b.embedding))
+		weights += config.EmbeddingWeight
+	}
+	if weights == 0 {
+		return 0
+	}
+	return total / weights
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for k := range a {
+		if b[k] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+func cosine(a, b []float64) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// nameTokens splits a concept name into lowercase words, ignoring numbers
+func nameTokens(name string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len(word) > 1 {
+			tokens[word] = true
+		}
+	}
+	return tokens
+}
+
+// clusterLabel names a cluster after the name tokens or link feature its
+// members share most
+func clusterLabel(members []string, profiles map[string]*conceptProfile) string {
+	counts := make(map[string]int)
+	for _, member := range members {
+		for token := range profiles[member].tokens {
+			counts[token]++
+		}
+	}
+
+	var shared []string
+	for token, count := range counts {
+		if count == len(members) {
+			shared = append(shared, token)
+		}
+	}
+	if len(shared) > 0 {
+		sort.Strings(shared)
+		return strings.Join(shared, "-")
+	}
+
+	featureCounts := make(map[string]int)
+	for _, member := range members {
+		for feature := range profiles[member].features {
+			featureCounts[feature]++
+		}
+	}
+	best := ""
+	for feature, count := range featureCounts {
+		if count > featureCounts[best] || (count == featureCounts[best] && feature < best) {
+			best = feature
+		}
+	}
+	if best != "" {
+		return best
+	}
+	return members[0]
+}
+
+func isClusterCandidate(name string) bool {
+	return !strings.HasPrefix(name, ClusterPrefix) && !strings.HasPrefix(name, PatternPrefix)
+}
+
+// recordCluster materializes the parent concept of a cluster and links its
+// members to it
+func (ca *ClusteringAgent) recordCluster(c Cluster, profiles map[string]*conceptProfile) (string, error) {
+	parent, err := upsertConcept(ca.atomSpace, ca.TenantID, c.Name, atomspace.TruthValue{Strength: 1.0, Confidence: c.Cohesion})
+	if err != nil {
+		return "", err
+	}
+	for _, member := range c.Members {
+		tv := atomspace.TruthValue{Strength: 1.0, Confidence: c.Cohesion}
+		if err := upsertInheritance(ca.atomSpace, ca.TenantID, profiles[member].atom, parent, tv); err != nil {
+			return "", err
+		}
+	}
+	return parent.GetID(), nil
+}
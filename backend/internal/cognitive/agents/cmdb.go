@@ -0,0 +1,508 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/cmdb"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/drift"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sandbox"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/secrets"
+)
+
+// Names written by the CMDB agent
+const (
+	CMDBPrefix       = "cmdb:"
+	CMDBSourcePrefix = "cmdbsource:"
+
+	CMDBItemConcept      = "CMDBItem"
+	recordedInPredicate  = "recorded_in"
+	driftAttributePrefix = DriftPrefix + string(drift.KindAttribute) + ":"
+)
+
+// CMDBConfig lists the CMDBs the CMDB agent mirrors and bounds what it
+// writes back
+type CMDBConfig struct {
+	Sources       []cmdb.Source `json:"sources"`
+	Interval      time.Duration `json:"interval_ns"`    // Minimum time between scheduled syncs
+	MinStrength   float64       `json:"min_strength"`   // Of discovered relationships written back
+	MinConfidence float64       `json:"min_confidence"` // Of discovered relationships written back
+	MaxUpdates    int           `json:"max_updates"`    // Updates planned per source and sync
+}
+
+// DefaultCMDBConfig returns the default CMDB agent configuration
+func DefaultCMDBConfig() CMDBConfig {
+	return CMDBConfig{
+		Interval:      10 * time.Minute,
+		MinStrength:   0.8,
+		MinConfidence: 0.7,
+		MaxUpdates:    50,
+	}
+}
+
+// CMDBSourceStatus describes the latest sync of one CMDB
+type CMDBSourceStatus struct {
+	Source        string        `json:"source"`
+	Revision      string        `json:"revision,omitempty"` // Content hash of the synced snapshot
+	Items         int           `json:"items"`
+	Relationships int           `json:"relationships"`
+	Updates       []cmdb.Update `json:"updates"` // Planned, and applied unless dry run, by the latest sync
+	CheckedAt     time.Time     `json:"checked_at"`
+	ChangedAt     time.Time     `json:"changed_at,omitempty"`
+	LastError     string        `json:"last_error,omitempty"`
+	snapshot      *cmdb.Snapshot
+	atoms         map[string]bool // Atoms written for the snapshot
+	applied       map[string]bool // Keys of updates applied
+}
+
+// CMDBAgent mirrors CMDB records into the AtomSpace. Each CI becomes
+//
+//	cmdb:NAME inherits CMDBItem and its class
+//	recorded_in(cmdb:NAME, cmdbsource:SOURCE)
+//	declares(cmdb:NAME, attr:KEY=VALUE)     for the mapped attributes
+//	PREDICATE(cmdb:NAME, TARGET)            for mapped references and relationships
+//
+// so the drift agent compares CMDB records with observed state. Snapshots
+// are re-read every interval but only written when their content changes;
+// CIs and relations that leave the CMDB keep their atoms with a strength of
+// 0.
+//
+// Sources with write-back plan updates from what the engine knows better
+// than the CMDB: attribute drift of a CI sets the mapped field to the
+// observed value, and relations between two CIs of a mapped relationship
+// type that the CMDB lacks are recorded there. Dry-run sources only report
+// the updates.
+type CMDBAgent struct {
+	BaseAgent
+	atomSpace atomspace.AtomSpaceInterface
+	config    CMDBConfig
+	client    *http.Client
+	secrets   secrets.Resolver
+	sandbox   *sandbox.Tenant              // Answers the sources while the tenant is in sandbox mode
+	statuses  map[string]*CMDBSourceStatus // source name -> status
+	lastRun   time.Time
+	runMu     sync.Mutex
+}
+
+// NewCMDBAgent creates a new CMDB agent
+func NewCMDBAgent(id, name, tenantID string, atomSpace atomspace.AtomSpaceInterface, config CMDBConfig) *CMDBAgent {
+	return &CMDBAgent{
+		BaseAgent: BaseAgent{
+			ID:       id,
+			Name:     name,
+			TenantID: tenantID,
+			Priority: 5,
+			State:    AgentStateIdle,
+		},
+		atomSpace: atomSpace,
+		config:    config,
+		client:    &http.Client{Timeout: 30 * time.Second},
+		secrets:   secrets.None,
+		statuses:  make(map[string]*CMDBSourceStatus),
+	}
+}
+
+// SetSecrets gives the agent the resolver of the tenant's secret
+// references, which source tokens and passwords may be
+func (ca *CMDBAgent) SetSecrets(resolver secrets.Resolver) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	ca.secrets = resolver
+}
+
+// SetSandbox gives the agent the tenant's sandbox, whose canned snapshots
+// stand in for the sources while it is enabled
+func (ca *CMDBAgent) SetSandbox(sb *sandbox.Tenant) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	ca.sandbox = sb
+}
+
+// SetConfig replaces the CMDB agent configuration
+func (ca *CMDBAgent) SetConfig(config CMDBConfig) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	ca.config = config
+}
+
+// GetConfig returns the CMDB agent configuration
+func (ca *CMDBAgent) GetConfig() CMDBConfig {
+	ca.mu.RLock()
+	defer ca.mu.RUnlock()
+	config := ca.config
+	config.Sources = append([]cmdb.Source(nil), ca.config.Sources...)
+	return config
+}
+
+// GetStatuses returns the sync status of each source sorted by name
+func (ca *CMDBAgent) GetStatuses() []CMDBSourceStatus {
+	ca.mu.RLock()
+	defer ca.mu.RUnlock()
+
+	result := make([]CMDBSourceStatus, 0, len(ca.statuses))
+	for _, status := range ca.statuses {
+		s := *status
+		s.Updates = append([]cmdb.Update(nil), status.Updates...)
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Source < result[j].Source })
+	return result
+}
+
+// Run syncs the CMDBs once the configured interval has elapsed
+func (ca *CMDBAgent) Run(ctx context.Context) error {
+	ca.mu.RLock()
+	due := time.Since(ca.lastRun) >= ca.config.Interval
+	ca.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	_, err := ca.Sync(ctx, false, false)
+	return err
+}
+
+// Sync reads every CMDB, writes the snapshots that changed since the last
+// sync, or all of them with force, and plans updates for the sources with
+// write-back. With dryRun no update is applied. It returns the statuses of
+// all sources.
+func (ca *CMDBAgent) Sync(ctx context.Context, force, dryRun bool) ([]CMDBSourceStatus, error) {
+	ca.runMu.Lock()
+	defer ca.runMu.Unlock()
+
+	ca.mu.Lock()
+	ca.State = AgentStateRunning
+	config := ca.config
+	ca.mu.Unlock()
+
+	start := time.Now()
+	err := ca.sync(ctx, config, force, dryRun)
+
+	ca.mu.Lock()
+	ca.RunCount++
+	ca.LastRun = time.Now()
+	ca.TotalTime += time.Since(start)
+	ca.lastRun = start
+	if err != nil {
+		ca.State = AgentStateError
+	} else {
+		ca.State = AgentStateIdle
+	}
+	ca.mu.Unlock()
+
+	return ca.GetStatuses(), err
+}
+
+func (ca *CMDBAgent) sync(ctx context.Context, config CMDBConfig, force, dryRun bool) error {
+	configured := make(map[string]bool, len(config.Sources))
+	var errs []error
+	for _, source := range config.Sources {
+		configured[source.Name] = true
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		ca.mu.Lock()
+		status, exists := ca.statuses[source.Name]
+		if !exists {
+			status = &CMDBSourceStatus{Source: source.Name, Updates: []cmdb.Update{}, applied: make(map[string]bool)}
+			ca.statuses[source.Name] = status
+		}
+		previous := status.Revision
+		previousAtoms := status.atoms
+		ca.mu.Unlock()
+
+		client, err := ca.connect(ctx, source)
+		var snapshot *cmdb.Snapshot
+		if err == nil {
+			snapshot, err = client.Fetch(ctx)
+		}
+		if err == nil && (force || snapshot.Revision() != previous) {
+			var atoms map[string]bool
+			atoms, err = ca.writeSnapshot(source, snapshot, previousAtoms)
+			if err == nil {
+				ca.mu.Lock()
+				status.Revision = snapshot.Revision()
+				status.Items = len(snapshot.Items)
+				status.Relationships = len(snapshot.Relationships)
+				status.snapshot = snapshot
+				status.atoms = atoms
+				status.ChangedAt = time.Now()
+				ca.mu.Unlock()
+			}
+		}
+
+		var updates []cmdb.Update
+		if err == nil && source.WriteBack != cmdb.WriteBackOff {
+			ca.mu.RLock()
+			updates = ca.plan(source, status, config)
+			ca.mu.RUnlock()
+			if source.WriteBack == cmdb.WriteBackApply && !dryRun {
+				ca.apply(ctx, client, status, updates)
+			}
+		}
+
+		ca.mu.Lock()
+		status.CheckedAt = time.Now()
+		status.LastError = ""
+		if updates != nil {
+			status.Updates = updates
+		}
+		if err != nil {
+			status.LastError = ca.secrets.Scrub(err.Error())
+			errs = append(errs, fmt.Errorf("cmdb source %s: %w", source.Name, err))
+		}
+		ca.mu.Unlock()
+	}
+
+	// Statuses of removed sources are dropped; their atoms are kept
+	ca.mu.Lock()
+	for name := range ca.statuses {
+		if !configured[name] {
+			delete(ca.statuses, name)
+		}
+	}
+	ca.mu.Unlock()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d cmdb source(s) failed, first: %w", len(errs), errs[0])
+	}
+	return nil
+}
+
+// writeSnapshot records the CIs and relations of a snapshot and retires
+// the atoms written for the previous one that it no longer has. It returns
+// the atoms written.
+func (ca *CMDBAgent) writeSnapshot(source cmdb.Source, snapshot *cmdb.Snapshot, previous map[string]bool) (map[string]bool, error) {
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	recorded := atomspace.TruthValue{Strength: 1.0, Confidence: 0.9}
+
+	sourceNode, err := upsertConcept(ca.atomSpace, ca.TenantID, CMDBSourcePrefix+source.Name, full)
+	if err != nil {
+		return nil, err
+	}
+	category, err := upsertConcept(ca.atomSpace, ca.TenantID, CMDBItemConcept, full)
+	if err != nil {
+		return nil, err
+	}
+
+	written := make(map[string]bool)
+	relate := func(predicate string, args ...atomspace.Atom) error {
+		link, err := upsertRelation(ca.atomSpace, ca.TenantID, predicate, args, recorded)
+		if err == nil {
+			written[link.GetID()] = true
+		}
+		return err
+	}
+
+	nodes := make(map[string]atomspace.Atom, len(snapshot.Items)) // CI ID -> node
+	byName := make(map[string]atomspace.Atom, len(snapshot.Items))
+	for _, ci := range snapshot.Items {
+		node, err := upsertConcept(ca.atomSpace, ca.TenantID, CMDBPrefix+ci.Name, recorded)
+		if err != nil {
+			return nil, err
+		}
+		nodes[ci.ID] = node
+		byName[ci.Name] = node
+		written[node.GetID()] = true
+
+		parents := []atomspace.Atom{category}
+		if ci.Class != "" {
+			class, err := ensureConcept(ca.atomSpace, ca.TenantID, ci.Class, full)
+			if err != nil {
+				return nil, err
+			}
+			parents = append(parents, class)
+		}
+		for _, parent := range parents {
+			if err := upsertInheritance(ca.atomSpace, ca.TenantID, node, parent, full); err != nil {
+				return nil, err
+			}
+		}
+		if err := relate(recordedInPredicate, node, sourceNode); err != nil {
+			return nil, err
+		}
+		for key, value := range ci.Attributes {
+			attr, err := upsertConcept(ca.atomSpace, ca.TenantID, drift.AttributeName(key, value), full)
+			if err != nil {
+				return nil, err
+			}
+			if err := relate(DeclaresPredicate, node, attr); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// References name other CIs of the snapshot or concepts such as sites
+	for _, ci := range snapshot.Items {
+		for predicate, value := range ci.References {
+			target, ok := byName[value]
+			if !ok {
+				var err error
+				if target, err = ensureConcept(ca.atomSpace, ca.TenantID, value, full); err != nil {
+					return nil, err
+				}
+			}
+			if err := relate(predicate, nodes[ci.ID], target); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, r := range snapshot.Relationships {
+		if err := relate(r.Predicate, nodes[r.Parent], nodes[r.Child]); err != nil {
+			return nil, err
+		}
+	}
+
+	for id := range previous {
+		if written[id] {
+			continue
+		}
+		ca.atomSpace.UpdateAtom(id, ca.TenantID, func(a atomspace.Atom) error {
+			tv := a.GetTruthValue()
+			tv.Strength = 0
+			a.SetTruthValue(tv)
+			return nil
+		})
+	}
+	return written, nil
+}
+
+// plan derives the updates of a source from the drift and relations of its
+// CIs, skipping those already applied. The caller holds ca.mu.
+func (ca *CMDBAgent) plan(source cmdb.Source, status *CMDBSourceStatus, config CMDBConfig) []cmdb.Update {
+	updates := make([]cmdb.Update, 0)
+	if status.snapshot == nil {
+		return updates
+	}
+	mapping := source.Fields()
+
+	items := make(map[string]*cmdb.CI, len(status.snapshot.Items)) // concept name -> CI
+	for i := range status.snapshot.Items {
+		items[CMDBPrefix+status.snapshot.Items[i].Name] = &status.snapshot.Items[i]
+	}
+	recorded := make(map[string]bool, len(status.snapshot.Relationships))
+	for _, r := range status.snapshot.Relationships {
+		recorded[r.Predicate+"|"+r.Parent+"|"+r.Child] = true
+	}
+	types := make(map[string]string) // predicate -> relationship type
+	for typeName, predicate := range mapping.Relationships {
+		if existing, ok := types[predicate]; !ok || typeName < existing {
+			types[predicate] = typeName
+		}
+	}
+
+	observed := make(map[string]map[string]string) // subject -> key -> value
+	var drifts, relations []*atomspace.Link
+	for _, atom := range ca.atomSpace.QueryAtoms(ca.TenantID, nil) {
+		link, ok := atom.(*atomspace.Link)
+		if !ok || link.GetType() != atomspace.EvaluationLinkType || link.GetTruthValue().Strength <= 0 {
+			continue
+		}
+		outgoing := link.GetOutgoing()
+		switch {
+		case link.GetName() == drift.ObservesPredicate && len(outgoing) == 3:
+			if key, value, ok := drift.ParseAttribute(outgoing[2].GetName()); ok {
+				if observed[outgoing[1].GetName()] == nil {
+					observed[outgoing[1].GetName()] = make(map[string]string)
+				}
+				observed[outgoing[1].GetName()][key] = value
+			}
+		case link.GetName() == DriftPredicate && len(outgoing) == 4:
+			drifts = append(drifts, link)
+		case types[link.GetName()] != "" && len(outgoing) == 3 && !status.atoms[link.GetID()]:
+			relations = append(relations, link)
+		}
+	}
+
+	for _, link := range drifts {
+		outgoing := link.GetOutgoing()
+		ci, ok := items[outgoing[1].GetName()]
+		if !ok || !strings.HasPrefix(outgoing[3].GetName(), driftAttributePrefix) {
+			continue
+		}
+		key := strings.TrimPrefix(outgoing[3].GetName(), driftAttributePrefix)
+		value, ok := observed[outgoing[2].GetName()][key]
+		if field := mapping.Attributes[key]; ok && cmdb.Writable(field) {
+			updates = append(updates, cmdb.Update{
+				Kind:     cmdb.UpdateAttribute,
+				CI:       ci.ID,
+				Field:    field,
+				Value:    value,
+				Previous: ci.Attributes[key],
+				Reason:   link.GetID(),
+			})
+		}
+	}
+	for _, link := range relations {
+		tv := link.GetTruthValue()
+		outgoing := link.GetOutgoing()
+		parent, child := items[outgoing[1].GetName()], items[outgoing[2].GetName()]
+		if parent == nil || child == nil || tv.Strength < config.MinStrength || tv.Confidence < config.MinConfidence ||
+			recorded[link.GetName()+"|"+parent.ID+"|"+child.ID] {
+			continue
+		}
+		updates = append(updates, cmdb.Update{
+			Kind:      cmdb.UpdateRelationship,
+			CI:        parent.ID,
+			Predicate: link.GetName(),
+			Type:      types[link.GetName()],
+			Child:     child.ID,
+			Reason:    link.GetID(),
+		})
+	}
+
+	pending := updates[:0]
+	for _, u := range updates {
+		if !status.applied[u.Key()] {
+			pending = append(pending, u)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Key() < pending[j].Key() })
+	if config.MaxUpdates > 0 && len(pending) > config.MaxUpdates {
+		pending = pending[:config.MaxUpdates]
+	}
+	return pending
+}
+
+// connect creates the client of a source with its credentials resolved
+func (ca *CMDBAgent) connect(ctx context.Context, source cmdb.Source) (cmdb.Client, error) {
+	ca.mu.RLock()
+	resolver := ca.secrets
+	sb := ca.sandbox
+	ca.mu.RUnlock()
+	if sb.Enabled() {
+		return sb.CMDBClient(source), nil
+	}
+
+	var err error
+	if source.Token, err = resolver.Resolve(ctx, source.Token); err != nil {
+		return nil, err
+	}
+	if source.Password, err = resolver.Resolve(ctx, source.Password); err != nil {
+		return nil, err
+	}
+	return cmdb.NewClient(source, ca.client), nil
+}
+
+// apply writes planned updates to the CMDB, recording the outcome of each
+func (ca *CMDBAgent) apply(ctx context.Context, client cmdb.Client, status *CMDBSourceStatus, updates []cmdb.Update) {
+	for i := range updates {
+		if err := client.Apply(ctx, updates[i]); err != nil {
+			ca.mu.RLock()
+			updates[i].Error = ca.secrets.Scrub(err.Error())
+			ca.mu.RUnlock()
+			continue
+		}
+		updates[i].Applied = true
+		ca.mu.Lock()
+		status.applied[updates[i].Key()] = true
+		ca.mu.Unlock()
+	}
+}
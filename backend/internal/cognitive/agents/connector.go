@@ -0,0 +1,49 @@
+package agents
+
+import (
+	"context"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/connectors"
+)
+
+// ConnectorAgent syncs a tenant's connectors when they are due. Running
+// them as an agent keeps them on the replica the scheduler runs the
+// tenant's agents on. Failing connectors back off on their own and do not
+// fail the agent.
+type ConnectorAgent struct {
+	BaseAgent
+	manager *connectors.Manager
+}
+
+// NewConnectorAgent creates a new connector agent
+func NewConnectorAgent(id, name, tenantID string, manager *connectors.Manager) *ConnectorAgent {
+	return &ConnectorAgent{
+		BaseAgent: BaseAgent{
+			ID:       id,
+			Name:     name,
+			TenantID: tenantID,
+			Priority: 5,
+			State:    AgentStateIdle,
+		},
+		manager: manager,
+	}
+}
+
+// Run syncs the connectors that are due
+func (ca *ConnectorAgent) Run(ctx context.Context) error {
+	ca.mu.Lock()
+	ca.State = AgentStateRunning
+	ca.mu.Unlock()
+
+	start := time.Now()
+	ca.manager.RunDue(ctx, ca.TenantID)
+
+	ca.mu.Lock()
+	ca.RunCount++
+	ca.LastRun = time.Now()
+	ca.TotalTime += time.Since(start)
+	ca.State = AgentStateIdle
+	ca.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,332 @@
+package agents
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/cost"
+)
+
+// Concept and predicate names written by the cost agent
+const (
+	SavingsProposalConcept = "SavingsProposal"
+
+	hourlyCostPredicate = "hourly_cost"
+	hourlyRatePredicate = "hourly_rate"
+	savesPredicate      = "saves"
+)
+
+// CostConfig controls cost annotation and savings proposals
+type CostConfig struct {
+	CostScale            float64       `json:"cost_scale"`            // Hourly cost mapped to a strength of 1
+	UtilizationPredicate string        `json:"utilization_predicate"` // Metric relation giving a resource's utilization
+	IdleThreshold        float64       `json:"idle_threshold"`        // Utilization below which savings are proposed
+	Interval             time.Duration `json:"interval_ns"`           // Minimum time between scheduled runs
+}
+
+// DefaultCostConfig returns the default cost agent configuration
+func DefaultCostConfig() CostConfig {
+	return CostConfig{
+		CostScale:            10,
+		UtilizationPredicate: "cpu_usage",
+		IdleThreshold:        0.1,
+		Interval:             5 * time.Minute,
+	}
+}
+
+// ResourceCost is the cost annotated on one resource
+type ResourceCost struct {
+	Resource string  `json:"resource"`
+	Category string  `json:"category,omitempty"`
+	Hourly   float64 `json:"hourly"`
+	Monthly  float64 `json:"monthly"`
+	Currency string  `json:"currency"`
+	AtomID   string  `json:"atom_id"`
+}
+
+// Saving is a proposal to scale down an underutilized resource
+type Saving struct {
+	Resource       string  `json:"resource"`
+	Utilization    float64 `json:"utilization"`
+	MonthlySavings float64 `json:"monthly_savings"`
+	Currency       string  `json:"currency"`
+	ProposalID     string  `json:"proposal_id"`
+}
+
+// CostReport summarises the spend found by the latest run
+type CostReport struct {
+	Resources    []ResourceCost     `json:"resources"`
+	ByCategory   map[string]float64 `json:"by_category"` // Monthly cost per category
+	TotalHourly  float64            `json:"total_hourly"`
+	TotalMonthly float64            `json:"total_monthly"`
+	Savings      []Saving           `json:"savings"`
+	GeneratedAt  time.Time          `json:"generated_at"`
+}
+
+// CostAgent annotates a tenant's infrastructure atoms with their cost as
+//
+//	hourly_cost(resource, usd:0.096)
+//
+// links whose strength is the cost relative to CostScale, and proposes
+// savings for resources whose utilization is below IdleThreshold as
+// SavingsProposal concepts related by saves(proposal, resource)
+type CostAgent struct {
+	BaseAgent
+	atomSpace atomspace.AtomSpaceInterface
+	model     *cost.Model
+	config    CostConfig
+	report    *CostReport
+	costLinks map[string]string // resource -> ID of its current hourly_cost link
+	lastRun   time.Time
+	runMu     sync.Mutex
+}
+
+// NewCostAgent creates a new cost agent over a cost model
+func NewCostAgent(id, name, tenantID string, atomSpace atomspace.AtomSpaceInterface, model *cost.Model, config CostConfig) *CostAgent {
+	return &CostAgent{
+		BaseAgent: BaseAgent{
+			ID:       id,
+			Name:     name,
+			TenantID: tenantID,
+			Priority: 3,
+			State:    AgentStateIdle,
+		},
+		atomSpace: atomSpace,
+		model:     model,
+		config:    config,
+		costLinks: make(map[string]string),
+	}
+}
+
+// SetConfig replaces the cost agent configuration
+func (ca *CostAgent) SetConfig(config CostConfig) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	ca.config = config
+}
+
+// GetConfig returns the cost agent configuration
+func (ca *CostAgent) GetConfig() CostConfig {
+	ca.mu.RLock()
+	defer ca.mu.RUnlock()
+	return ca.config
+}
+
+// GetReport returns the report of the latest run, nil before the first
+func (ca *CostAgent) GetReport() *CostReport {
+	ca.mu.RLock()
+	defer ca.mu.RUnlock()
+	return ca.report
+}
+
+// Run annotates costs once the configured interval has elapsed
+func (ca *CostAgent) Run(ctx context.Context) error {
+	ca.mu.RLock()
+	due := time.Since(ca.lastRun) >= ca.config.Interval
+	ca.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	_, err := ca.Annotate(ctx)
+	return err
+}
+
+// Annotate prices every classified resource of the tenant now
+func (ca *CostAgent) Annotate(ctx context.Context) (*CostReport, error) {
+	ca.runMu.Lock()
+	defer ca.runMu.Unlock()
+
+	ca.mu.Lock()
+	ca.State = AgentStateRunning
+	config := ca.config
+	ca.mu.Unlock()
+
+	start := time.Now()
+	report, err := ca.annotate(ctx, config)
+
+	ca.mu.Lock()
+	ca.RunCount++
+	ca.LastRun = time.Now()
+	ca.TotalTime += time.Since(start)
+	ca.lastRun = start
+	if err != nil {
+		ca.State = AgentStateError
+	} else {
+		ca.State = AgentStateIdle
+		ca.report = report
+	}
+	ca.mu.Unlock()
+
+	return report, err
+}
+
+func (ca *CostAgent) annotate(ctx context.Context, config CostConfig) (*CostReport, error) {
+	if config.CostScale <= 0 {
+		config.CostScale = DefaultCostConfig().CostScale
+	}
+
+	// Resources are concept nodes classified by an InheritanceLink, and
+	// their utilization comes from metric relations
+	resources := make(map[string]atomspace.Atom)
+	categories := make(map[string][]string)
+	utilization := make(map[string]float64)
+	for _, atom := range ca.atomSpace.QueryAtoms(ca.TenantID, nil) {
+		link, ok := atom.(*atomspace.Link)
+		if !ok {
+			continue
+		}
+		outgoing := link.GetOutgoing()
+		switch {
+		case link.GetType() == atomspace.InheritanceLinkType && len(outgoing) == 2:
+			child := outgoing[0]
+			if child.GetType() != atomspace.ConceptNodeType || isDerivedConcept(child.GetName()) {
+				continue
+			}
+			resources[child.GetName()] = child
+			categories[child.GetName()] = append(categories[child.GetName()], outgoing[1].GetName())
+		case link.GetType() == atomspace.EvaluationLinkType && len(outgoing) == 2 &&
+			outgoing[0].GetType() == atomspace.PredicateNodeType && outgoing[0].GetName() == config.UtilizationPredicate:
+			utilization[outgoing[1].GetName()] = link.GetTruthValue().Strength
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := &CostReport{
+		Resources:   make([]ResourceCost, 0),
+		ByCategory:  make(map[string]float64),
+		Savings:     make([]Saving, 0),
+		GeneratedAt: time.Now(),
+	}
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	costLinks := make(map[string]string)
+	ratesWritten := make(map[string]bool)
+
+	for _, name := range names {
+		rate, ok := ca.model.Lookup(ca.TenantID, name, categories[name])
+		if !ok {
+			continue
+		}
+		resource := resources[name]
+
+		amount, err := upsertConcept(ca.atomSpace, ca.TenantID, cost.FormatAmount(rate.Hourly, rate.Currency), full)
+		if err != nil {
+			return nil, err
+		}
+		tv := atomspace.TruthValue{Strength: math.Min(1, rate.Hourly/config.CostScale), Confidence: 0.9}
+		link, err := upsertRelation(ca.atomSpace, ca.TenantID, hourlyCostPredicate, []atomspace.Atom{resource, amount}, tv)
+		if err != nil {
+			return nil, err
+		}
+		costLinks[name] = link.GetID()
+
+		// Category rates are recorded once on the category concept
+		if rate.Category != "" && !ratesWritten[rate.Category] {
+			category, err := upsertConcept(ca.atomSpace, ca.TenantID, rate.Category, full)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := upsertRelation(ca.atomSpace, ca.TenantID, hourlyRatePredicate, []atomspace.Atom{category, amount}, tv); err != nil {
+				return nil, err
+			}
+			ratesWritten[rate.Category] = true
+		}
+
+		monthly := rate.Hourly * cost.HoursPerMonth
+		report.Resources = append(report.Resources, ResourceCost{
+			Resource: name,
+			Category: rate.Category,
+			Hourly:   rate.Hourly,
+			Monthly:  monthly,
+			Currency: rate.Currency,
+			AtomID:   link.GetID(),
+		})
+		report.ByCategory[rate.Category] += monthly
+		report.TotalHourly += rate.Hourly
+		report.TotalMonthly += monthly
+
+		used, measured := utilization[name]
+		if !measured || used >= config.IdleThreshold {
+			continue
+		}
+		saving, err := ca.proposeSaving(resource, rate, used)
+		if err != nil {
+			return nil, err
+		}
+		report.Savings = append(report.Savings, saving)
+	}
+
+	sort.Slice(report.Savings, func(i, j int) bool {
+		return report.Savings[i].MonthlySavings > report.Savings[j].MonthlySavings
+	})
+
+	// Drop the cost links of resources that lost their rate or changed price
+	ca.mu.RLock()
+	previous := ca.costLinks
+	ca.mu.RUnlock()
+	for name, id := range previous {
+		if costLinks[name] != id {
+			ca.atomSpace.DeleteAtom(id, ca.TenantID)
+		}
+	}
+	ca.mu.Lock()
+	ca.costLinks = costLinks
+	ca.mu.Unlock()
+
+	return report, nil
+}
+
+// proposeSaving records a rightsizing proposal for an underutilized resource
+func (ca *CostAgent) proposeSaving(resource atomspace.Atom, rate cost.Rate, utilization float64) (Saving, error) {
+	monthly := rate.Hourly * cost.HoursPerMonth * (1 - utilization)
+	tv := atomspace.TruthValue{Strength: 1 - utilization, Confidence: 0.8}
+
+	proposal, err := upsertConcept(ca.atomSpace, ca.TenantID, "savings:rightsize:"+resource.GetName(), tv)
+	if err != nil {
+		return Saving{}, err
+	}
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	category, err := upsertConcept(ca.atomSpace, ca.TenantID, SavingsProposalConcept, full)
+	if err != nil {
+		return Saving{}, err
+	}
+	if err := upsertInheritance(ca.atomSpace, ca.TenantID, proposal, category, full); err != nil {
+		return Saving{}, err
+	}
+	if _, err := upsertRelation(ca.atomSpace, ca.TenantID, savesPredicate, []atomspace.Atom{proposal, resource}, tv); err != nil {
+		return Saving{}, err
+	}
+
+	return Saving{
+		Resource:       resource.GetName(),
+		Utilization:    utilization,
+		MonthlySavings: monthly,
+		Currency:       rate.Currency,
+		ProposalID:     proposal.GetID(),
+	}, nil
+}
+
+// isDerivedConcept reports concepts written by agents rather than ingested
+// resources
+func isDerivedConcept(name string) bool {
+	for _, prefix := range []string{PatternPrefix, ClusterPrefix, TimeSeriesPrefix, RunbookPrefix, TerraformPrefix, TerraformStatePrefix, DriftPrefix, RecommendationPrefix, CounterfactualPrefix, BlockerPrefix, "savings:"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
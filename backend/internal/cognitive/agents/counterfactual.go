@@ -0,0 +1,55 @@
+package agents
+
+import (
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/counterfactuals"
+)
+
+// Names written for suppressed actions
+const (
+	CounterfactualPrefix  = "counterfactual:"
+	CounterfactualConcept = "Counterfactual"
+	BlockerPrefix         = "blocker:"
+
+	wouldHavePredicate    = "would_have"    // would_have(counterfactual:ID, atom asking for the action)
+	suppressedByPredicate = "suppressed_by" // suppressed_by(counterfactual:ID, blocker:BLOCKER)
+)
+
+// RecordCounterfactual records an action a policy or threshold suppressed
+// in the store and as atoms, so rules and operators can reason about what
+// autonomy would have done:
+//
+//	counterfactual:ID inherits Counterfactual, as strong and confident as the belief behind the action
+//	would_have(counterfactual:ID, ATOM)   if the action was asked for by an atom
+//	suppressed_by(counterfactual:ID, blocker:BLOCKER)
+func RecordCounterfactual(space atomspace.AtomSpaceInterface, store *counterfactuals.Store, tenantID string, c counterfactuals.Counterfactual, atom atomspace.Atom) (counterfactuals.Counterfactual, error) {
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	if c.ID == "" {
+		c.ID = counterfactuals.ID(c.Source, c.AtomID)
+	}
+
+	node, err := upsertConcept(space, tenantID, CounterfactualPrefix+c.ID, c.TruthValue)
+	if err != nil {
+		return c, err
+	}
+	parent, err := ensureConcept(space, tenantID, CounterfactualConcept, full)
+	if err != nil {
+		return c, err
+	}
+	if err := upsertInheritance(space, tenantID, node, parent, full); err != nil {
+		return c, err
+	}
+	if atom != nil {
+		if _, err := upsertRelation(space, tenantID, wouldHavePredicate, []atomspace.Atom{node, atom}, full); err != nil {
+			return c, err
+		}
+	}
+	blocker, err := ensureConcept(space, tenantID, BlockerPrefix+c.Blocker, full)
+	if err != nil {
+		return c, err
+	}
+	if _, err := upsertRelation(space, tenantID, suppressedByPredicate, []atomspace.Atom{node, blocker}, full); err != nil {
+		return c, err
+	}
+	return store.Record(tenantID, c), nil
+}
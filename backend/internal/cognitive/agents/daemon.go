@@ -0,0 +1,283 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DaemonAgent is a continuously running agent such as a watcher or stream
+// consumer. Instead of calling Run each cycle, the scheduler starts it on
+// registration, restarts it under supervision when it fails and stops it on
+// unregistration or shutdown.
+type DaemonAgent interface {
+	Agent
+
+	// Start runs the daemon until ctx is cancelled or Stop is called. It
+	// returns nil after a requested stop; any other return is a failure.
+	Start(ctx context.Context) error
+
+	// Stop asks the daemon to drain in-flight work and return from Start
+	Stop()
+}
+
+// daemonRunner supervises one daemon agent
+type daemonRunner struct {
+	agent     DaemonAgent
+	stop      chan struct{}
+	done      chan struct{}
+	starts    int64
+	running   bool
+	startedAt time.Time
+}
+
+// DaemonStatus describes a supervised daemon agent
+type DaemonStatus struct {
+	AgentID   string    `json:"agent_id"`
+	TenantID  string    `json:"tenant_id"`
+	Running   bool      `json:"running"`
+	Starts    int64     `json:"starts"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+}
+
+// startDaemonLocked launches the supervision loop of a daemon
+func (as *AgentScheduler) startDaemonLocked(agent DaemonAgent) {
+	if _, running := as.daemons[agent.GetID()]; running || as.closing || as.standby || !as.ownsLocked(agent) {
+		return
+	}
+	runner := &daemonRunner{
+		agent: agent,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	as.daemons[agent.GetID()] = runner
+	go as.superviseDaemon(runner)
+}
+
+// superviseDaemon keeps a daemon running until it is stopped, restarting it
+// after failures as the supervisor policy allows
+func (as *AgentScheduler) superviseDaemon(runner *daemonRunner) {
+	defer close(runner.done)
+	agent := runner.agent
+
+	for {
+		if !as.supervisor.allow(agent, time.Now()) {
+			if !as.waitOrStop(runner, 100*time.Millisecond) {
+				return
+			}
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		exited := make(chan error, 1)
+		as.mu.Lock()
+		runner.starts++
+		runner.running = true
+		runner.startedAt = time.Now()
+		as.mu.Unlock()
+		go func() {
+			exited <- agent.Start(ctx)
+		}()
+
+		policy := as.supervisorPolicy()
+		var stable <-chan time.Time
+		var stableTimer *time.Timer
+		if policy.DaemonStableAfter > 0 {
+			stableTimer = time.NewTimer(policy.DaemonStableAfter)
+			stable = stableTimer.C
+		}
+
+		var err error
+		stopped := false
+	wait:
+		for {
+			select {
+			case err = <-exited:
+				break wait
+			case <-stable:
+				// Long enough uptime clears earlier failures
+				as.supervisor.success(agent)
+				stable = nil
+			case <-runner.stop:
+				stopped = true
+				err = as.drainDaemon(agent, exited, cancel, policy.DaemonDrainTimeout)
+				break wait
+			}
+		}
+		cancel()
+		if stableTimer != nil {
+			stableTimer.Stop()
+		}
+
+		as.mu.Lock()
+		runner.running = false
+		handler := as.failureHandler
+		as.mu.Unlock()
+
+		// The agent may already be unregistered, so a failed drain is only
+		// reported to the failure handler
+		if stopped {
+			if err != nil && handler != nil {
+				handler(agent, err)
+			}
+			return
+		}
+
+		if err == nil {
+			err = fmt.Errorf("daemon agent %s exited unexpectedly", agent.GetID())
+		}
+		as.supervisor.failure(agent, err, time.Now())
+		if handler != nil {
+			handler(agent, err)
+		}
+
+		if !as.waitOrStop(runner, policy.RestartBackoff) {
+			return
+		}
+	}
+}
+
+// drainDaemon stops a daemon and waits for it to return, cancelling its
+// context once the drain timeout expires
+func (as *AgentScheduler) drainDaemon(agent DaemonAgent, exited <-chan error, cancel context.CancelFunc, timeout time.Duration) error {
+	agent.Stop()
+
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case err := <-exited:
+			return err
+		case <-timer.C:
+		}
+	}
+
+	cancel()
+	if err := <-exited; err != nil && err != context.Canceled {
+		return err
+	}
+	if timeout > 0 {
+		return fmt.Errorf("daemon agent %s did not drain within %v", agent.GetID(), timeout)
+	}
+	return nil
+}
+
+// waitOrStop sleeps for d and reports false if the daemon was stopped meanwhile
+func (as *AgentScheduler) waitOrStop(runner *daemonRunner, d time.Duration) bool {
+	if d <= 0 {
+		d = 100 * time.Millisecond
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-runner.stop:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// stopDaemonLocked signals a daemon to stop and returns a channel closed once
+// it has drained
+func (as *AgentScheduler) stopDaemonLocked(agentID string) <-chan struct{} {
+	runner, exists := as.daemons[agentID]
+	if !exists {
+		return nil
+	}
+	delete(as.daemons, agentID)
+	close(runner.stop)
+	return runner.done
+}
+
+// stopDaemons stops all daemons and waits for them to drain
+func (as *AgentScheduler) stopDaemons() {
+	as.mu.Lock()
+	as.closing = true
+	pending := make([]<-chan struct{}, 0, len(as.daemons))
+	for agentID := range as.daemons {
+		pending = append(pending, as.stopDaemonLocked(agentID))
+	}
+	as.mu.Unlock()
+
+	for _, done := range pending {
+		<-done
+	}
+}
+
+// SetStandby pauses scheduling on a replica that is not leader: cycles
+// stop and daemons are stopped, waiting for them to drain, while agents
+// stay registered. Leaving standby resumes cycles and starts the daemons
+// again. Agents can still be run on demand in standby.
+func (as *AgentScheduler) SetStandby(standby bool) {
+	as.mu.Lock()
+	as.standby = standby
+	pending := as.rebalanceDaemonsLocked()
+	as.mu.Unlock()
+
+	for _, done := range pending {
+		<-done
+	}
+}
+
+// SetOwnership partitions agents across replicas: this replica only runs
+// the agents whose partition key owns reports true for, nil owning every
+// agent. Daemons it no longer owns are stopped, waiting for them to drain,
+// and those it now owns are started.
+func (as *AgentScheduler) SetOwnership(owns func(key string) bool) {
+	as.mu.Lock()
+	as.owns = owns
+	pending := as.rebalanceDaemonsLocked()
+	as.mu.Unlock()
+
+	for _, done := range pending {
+		<-done
+	}
+}
+
+// rebalanceDaemonsLocked stops the daemons this replica should not run and
+// starts those it should, returning channels closed once the stopped ones
+// have drained
+func (as *AgentScheduler) rebalanceDaemonsLocked() []<-chan struct{} {
+	var pending []<-chan struct{}
+	for agentID, runner := range as.daemons {
+		if as.standby || !as.ownsLocked(runner.agent) {
+			pending = append(pending, as.stopDaemonLocked(agentID))
+		}
+	}
+	for _, agent := range as.agents {
+		if daemon, isDaemon := agent.(DaemonAgent); isDaemon {
+			as.startDaemonLocked(daemon)
+		}
+	}
+	return pending
+}
+
+func (as *AgentScheduler) supervisorPolicy() SupervisorPolicy {
+	as.supervisor.mu.Lock()
+	defer as.supervisor.mu.Unlock()
+	return as.supervisor.policy
+}
+
+// GetDaemons returns the status of daemon agents, optionally restricted to a
+// tenant
+func (as *AgentScheduler) GetDaemons(tenantID string) []DaemonStatus {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	result := make([]DaemonStatus, 0, len(as.daemons))
+	for agentID, runner := range as.daemons {
+		if tenantID != "" && runner.agent.GetTenantID() != tenantID {
+			continue
+		}
+		result = append(result, DaemonStatus{
+			AgentID:   agentID,
+			TenantID:  runner.agent.GetTenantID(),
+			Running:   runner.running,
+			Starts:    runner.starts,
+			StartedAt: runner.startedAt,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].AgentID < result[j].AgentID })
+	return result
+}
@@ -0,0 +1,297 @@
+package agents
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/drift"
+)
+
+// Names written by the drift agent
+const (
+	DriftPredicate = "drift"
+	DriftPrefix    = "drift:"
+)
+
+// RecordObservations writes observed attributes of a subject, as reported
+// by connectors without their own pipeline stage:
+//
+//	observes(SUBJECT, attr:KEY=VALUE)
+//
+// The subject concept is created if needed and inherits category when one
+// is given. Attributes the subject was previously observed with under the
+// same key are kept with a strength of 0.
+func RecordObservations(space atomspace.AtomSpaceInterface, tenantID, subject, category string, attributes map[string]string) error {
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	node, err := ensureConcept(space, tenantID, subject, full)
+	if err != nil {
+		return err
+	}
+	if category != "" {
+		parent, err := ensureConcept(space, tenantID, category, full)
+		if err != nil {
+			return err
+		}
+		if err := upsertInheritance(space, tenantID, node, parent, full); err != nil {
+			return err
+		}
+	}
+
+	current := make(map[string]bool, len(attributes))
+	for key, value := range attributes {
+		attr, err := upsertConcept(space, tenantID, drift.AttributeName(key, value), full)
+		if err != nil {
+			return err
+		}
+		link, err := upsertRelation(space, tenantID, drift.ObservesPredicate, []atomspace.Atom{node, attr}, full)
+		if err != nil {
+			return err
+		}
+		current[link.GetID()] = true
+	}
+
+	for _, atom := range space.QueryAtoms(tenantID, nil) {
+		link, ok := atom.(*atomspace.Link)
+		if !ok || link.GetType() != atomspace.EvaluationLinkType || link.GetName() != drift.ObservesPredicate || current[link.GetID()] {
+			continue
+		}
+		outgoing := link.GetOutgoing()
+		if len(outgoing) != 3 || outgoing[1].GetName() != subject {
+			continue
+		}
+		key, _, ok := drift.ParseAttribute(outgoing[2].GetName())
+		if _, reported := attributes[key]; !ok || !reported {
+			continue
+		}
+		space.UpdateAtom(link.GetID(), tenantID, func(a atomspace.Atom) error {
+			tv := a.GetTruthValue()
+			tv.Strength = 0
+			a.SetTruthValue(tv)
+			return nil
+		})
+	}
+	return nil
+}
+
+// DriftConfig controls drift detection
+type DriftConfig struct {
+	Detection drift.Config  `json:"detection"`
+	Interval  time.Duration `json:"interval_ns"` // Minimum time between scheduled runs
+}
+
+// DefaultDriftConfig returns the default drift agent configuration
+func DefaultDriftConfig() DriftConfig {
+	return DriftConfig{
+		Detection: drift.DefaultConfig(),
+		Interval:  5 * time.Minute,
+	}
+}
+
+// DriftAgent compares the declared and observed subgraphs of a tenant's
+// AtomSpace, built from declares and observes links, and records each
+// finding as a drift link whose strength is its severity:
+//
+//	drift(DECLARED, OBSERVED, drift:attribute:KEY)
+//	drift(DECLARED, drift:missing)
+//	drift(OBSERVED, drift:unmanaged)
+//
+// Drift links of findings that no longer hold are kept with a strength of
+// 0.
+type DriftAgent struct {
+	BaseAgent
+	atomSpace atomspace.AtomSpaceInterface
+	config    DriftConfig
+	rules     []drift.Rule
+	findings  []drift.Finding
+	links     map[string]string // finding key -> drift link ID
+	lastRun   time.Time
+	runMu     sync.Mutex
+}
+
+// NewDriftAgent creates a new drift agent
+func NewDriftAgent(id, name, tenantID string, atomSpace atomspace.AtomSpaceInterface, config DriftConfig) *DriftAgent {
+	return &DriftAgent{
+		BaseAgent: BaseAgent{
+			ID:       id,
+			Name:     name,
+			TenantID: tenantID,
+			Priority: 4,
+			State:    AgentStateIdle,
+		},
+		atomSpace: atomSpace,
+		config:    config,
+		rules:     drift.DefaultRules(config.Detection),
+		links:     make(map[string]string),
+	}
+}
+
+// SetConfig replaces the drift agent configuration
+func (da *DriftAgent) SetConfig(config DriftConfig) {
+	da.mu.Lock()
+	defer da.mu.Unlock()
+	da.config = config
+	da.rules = drift.DefaultRules(config.Detection)
+}
+
+// GetConfig returns the drift agent configuration
+func (da *DriftAgent) GetConfig() DriftConfig {
+	da.mu.RLock()
+	defer da.mu.RUnlock()
+	return da.config
+}
+
+// GetFindings returns the findings of the latest run, most severe first
+func (da *DriftAgent) GetFindings() []drift.Finding {
+	da.mu.RLock()
+	defer da.mu.RUnlock()
+	return append([]drift.Finding(nil), da.findings...)
+}
+
+// Run detects drift once the configured interval has elapsed
+func (da *DriftAgent) Run(ctx context.Context) error {
+	da.mu.RLock()
+	due := time.Since(da.lastRun) >= da.config.Interval
+	da.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	_, err := da.Detect(ctx)
+	return err
+}
+
+// Detect compares declared and observed state now
+func (da *DriftAgent) Detect(ctx context.Context) ([]drift.Finding, error) {
+	da.runMu.Lock()
+	defer da.runMu.Unlock()
+
+	da.mu.Lock()
+	da.State = AgentStateRunning
+	config := da.config
+	rules := da.rules
+	da.mu.Unlock()
+
+	start := time.Now()
+	findings, err := da.detect(ctx, config, rules)
+
+	da.mu.Lock()
+	da.RunCount++
+	da.LastRun = time.Now()
+	da.TotalTime += time.Since(start)
+	da.lastRun = start
+	if err != nil {
+		da.State = AgentStateError
+	} else {
+		da.State = AgentStateIdle
+		da.findings = findings
+	}
+	da.mu.Unlock()
+
+	return findings, err
+}
+
+func (da *DriftAgent) detect(ctx context.Context, config DriftConfig, rules []drift.Rule) ([]drift.Finding, error) {
+	graph := drift.NewGraph()
+	inactive := make(map[string]bool) // Subjects with zero strength, e.g. resources retired from a state
+	subjects := make(map[string]atomspace.Atom)
+	categories := make(map[string][]string)
+
+	for _, atom := range da.atomSpace.QueryAtoms(da.TenantID, nil) {
+		if atom.GetType() == atomspace.ConceptNodeType && atom.GetTruthValue().Strength == 0 {
+			inactive[atom.GetName()] = true
+			continue
+		}
+		link, ok := atom.(*atomspace.Link)
+		if !ok {
+			continue
+		}
+		outgoing := link.GetOutgoing()
+		switch {
+		case link.GetType() == atomspace.InheritanceLinkType && len(outgoing) == 2:
+			if parent := outgoing[1].GetName(); parent != TerraformResourceConcept {
+				categories[outgoing[0].GetName()] = append(categories[outgoing[0].GetName()], parent)
+			}
+		case link.GetType() == atomspace.EvaluationLinkType && len(outgoing) == 3 && link.GetTruthValue().Strength > 0 &&
+			(link.GetName() == drift.DeclaresPredicate || link.GetName() == drift.ObservesPredicate):
+			key, value, ok := drift.ParseAttribute(outgoing[2].GetName())
+			if !ok {
+				continue
+			}
+			subject := outgoing[1]
+			subjects[subject.GetName()] = subject
+			if link.GetName() == drift.DeclaresPredicate {
+				graph.Declare(subject.GetName(), key, value)
+			} else {
+				graph.Observe(subject.GetName(), key, value)
+			}
+		}
+	}
+
+	for name := range inactive {
+		delete(graph.Declared, name)
+		delete(graph.Observed, name)
+	}
+	for name, parents := range categories {
+		for _, parent := range parents {
+			graph.SetCategory(true, name, parent)
+			graph.SetCategory(false, name, parent)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	findings := drift.Detect(graph, config.Detection, rules)
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	current := make(map[string]string, len(findings))
+
+	for i := range findings {
+		f := &findings[i]
+		kindName := DriftPrefix + string(f.Kind)
+		if f.Attribute != "" {
+			kindName += ":" + f.Attribute
+		}
+		kind, err := upsertConcept(da.atomSpace, da.TenantID, kindName, full)
+		if err != nil {
+			return nil, err
+		}
+		args := make([]atomspace.Atom, 0, 3)
+		for _, name := range []string{f.Declared, f.Observed} {
+			if name != "" {
+				args = append(args, subjects[name])
+			}
+		}
+		args = append(args, kind)
+
+		link, err := upsertRelation(da.atomSpace, da.TenantID, DriftPredicate, args, atomspace.TruthValue{Strength: f.Severity, Confidence: 0.9})
+		if err != nil {
+			return nil, err
+		}
+		f.LinkID = link.GetID()
+		current[f.Key()] = link.GetID()
+	}
+
+	// Findings that no longer hold keep their links at zero strength
+	da.mu.RLock()
+	previous := da.links
+	da.mu.RUnlock()
+	for key, linkID := range previous {
+		if _, exists := current[key]; exists {
+			continue
+		}
+		da.atomSpace.UpdateAtom(linkID, da.TenantID, func(a atomspace.Atom) error {
+			tv := a.GetTruthValue()
+			tv.Strength = 0
+			a.SetTruthValue(tv)
+			return nil
+		})
+	}
+
+	da.mu.Lock()
+	da.links = current
+	da.mu.Unlock()
+	return findings, nil
+}
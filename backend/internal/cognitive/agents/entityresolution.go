@@ -0,0 +1,382 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/debugger"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/recommendations"
+)
+
+// maxCandidateGroup bounds the concepts sharing a name trigram or a link
+// that are compared pairwise; larger groups are too common to tell
+// duplicates apart
+const maxCandidateGroup = 100
+
+// MergeFunc merges atoms of the agent's tenant into another
+type MergeFunc func(into string, from []string) error
+
+// EntityResolutionConfig controls which concepts are taken for the same
+// entity
+type EntityResolutionConfig struct {
+	NameWeight      float64       `json:"name_weight"`
+	LinkWeight      float64       `json:"link_weight"`
+	EmbeddingWeight float64       `json:"embedding_weight"`           // Only used when an embedding function is set
+	MinConfidence   float64       `json:"min_confidence"`             // Less similar concepts are not proposed
+	AutoMergeAbove  float64       `json:"auto_merge_above,omitempty"` // Confidence from which concepts are merged without approval; 0 never
+	MaxConcepts     int           `json:"max_concepts"`               // Concepts considered per run
+	Interval        time.Duration `json:"interval_ns"`                // Minimum time between scheduled runs
+}
+
+// DefaultEntityResolutionConfig returns the default entity resolution
+// configuration, which only proposes merges
+func DefaultEntityResolutionConfig() EntityResolutionConfig {
+	return EntityResolutionConfig{
+		NameWeight:      0.5,
+		LinkWeight:      0.5,
+		EmbeddingWeight: 0.5,
+		MinConfidence:   0.6,
+		MaxConcepts:     5000,
+		Interval:        10 * time.Minute,
+	}
+}
+
+// MergeCandidate is a concept taken for the same entity as another, into
+// which it is merged
+type MergeCandidate struct {
+	Into             string  `json:"into"` // ID of the concept kept
+	IntoName         string  `json:"into_name"`
+	Atom             string  `json:"atom"` // ID of the concept merged
+	AtomName         string  `json:"atom_name"`
+	Confidence       float64 `json:"confidence"`
+	NameSimilarity   float64 `json:"name_similarity"`
+	LinkSimilarity   float64 `json:"link_similarity"`
+	EmbeddingSim     float64 `json:"embedding_similarity,omitempty"`
+	Merged           bool    `json:"merged"`                      // Merged without approval
+	RecommendationID string  `json:"recommendation_id,omitempty"` // Of the merge proposed in the approval queue
+}
+
+// EntityResolutionAgent finds concepts standing for the same entity, such
+// as the same host ingested by several connectors, from the similarity of
+// their names, the links they share and the distance of their embeddings.
+// Each candidate is proposed as a merge recommendation in the approval
+// queue, or merged at once when at least AutoMergeAbove confident. The
+// concept kept is the one more linked to.
+type EntityResolutionAgent struct {
+	BaseAgent
+	atomSpace  atomspace.AtomSpaceInterface
+	store      *recommendations.Store
+	merge      MergeFunc
+	embed      EmbeddingFunc
+	config     EntityResolutionConfig
+	candidates []MergeCandidate
+	lastRun    time.Time
+	runMu      sync.Mutex
+}
+
+// NewEntityResolutionAgent creates a new entity resolution agent proposing
+// merges in store and merging with merge
+func NewEntityResolutionAgent(id, name, tenantID string, atomSpace atomspace.AtomSpaceInterface, store *recommendations.Store, merge MergeFunc, config EntityResolutionConfig) *EntityResolutionAgent {
+	return &EntityResolutionAgent{
+		BaseAgent: BaseAgent{
+			ID:       id,
+			Name:     name,
+			TenantID: tenantID,
+			Priority: 1,
+			State:    AgentStateIdle,
+		},
+		atomSpace: atomSpace,
+		store:     store,
+		merge:     merge,
+		config:    config,
+	}
+}
+
+// SetConfig replaces the entity resolution configuration
+func (ea *EntityResolutionAgent) SetConfig(config EntityResolutionConfig) {
+	ea.mu.Lock()
+	defer ea.mu.Unlock()
+	ea.config = config
+}
+
+// GetConfig returns the entity resolution configuration
+func (ea *EntityResolutionAgent) GetConfig() EntityResolutionConfig {
+	ea.mu.RLock()
+	defer ea.mu.RUnlock()
+	return ea.config
+}
+
+// SetEmbeddingFunc enables embedding distance as a similarity signal
+func (ea *EntityResolutionAgent) SetEmbeddingFunc(embed EmbeddingFunc) {
+	ea.mu.Lock()
+	defer ea.mu.Unlock()
+	ea.embed = embed
+}
+
+// GetCandidates returns the candidates found by the latest run
+func (ea *EntityResolutionAgent) GetCandidates() []MergeCandidate {
+	ea.mu.RLock()
+	defer ea.mu.RUnlock()
+	return append([]MergeCandidate(nil), ea.candidates...)
+}
+
+// Run resolves entities once the configured interval has elapsed
+func (ea *EntityResolutionAgent) Run(ctx context.Context) error {
+	ea.mu.RLock()
+	due := time.Since(ea.lastRun) >= ea.config.Interval
+	ea.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	_, err := ea.Resolve(ctx)
+	return err
+}
+
+// Resolve looks for duplicate concepts now and returns the candidates
+// proposed or merged
+func (ea *EntityResolutionAgent) Resolve(ctx context.Context) ([]MergeCandidate, error) {
+	ea.runMu.Lock()
+	defer ea.runMu.Unlock()
+
+	ea.mu.Lock()
+	ea.State = AgentStateRunning
+	config := ea.config
+	embed := ea.embed
+	ea.mu.Unlock()
+
+	start := time.Now()
+	candidates, err := ea.resolve(ctx, config, embed)
+
+	ea.mu.Lock()
+	ea.RunCount++
+	ea.LastRun = time.Now()
+	ea.TotalTime += time.Since(start)
+	ea.lastRun = start
+	if err != nil {
+		ea.State = AgentStateError
+	} else {
+		ea.State = AgentStateIdle
+		ea.candidates = candidates
+	}
+	ea.mu.Unlock()
+
+	return candidates, err
+}
+
+// entityProfile holds the similarity signals of one concept
+type entityProfile struct {
+	atom      atomspace.Atom
+	trigrams  map[string]bool
+	features  map[string]bool
+	embedding []float64
+	incoming  int
+}
+
+func (ea *EntityResolutionAgent) resolve(ctx context.Context, config EntityResolutionConfig, embed EmbeddingFunc) ([]MergeCandidate, error) {
+	atoms := ea.atomSpace.QueryAtoms(ea.TenantID, nil)
+
+	profiles := make(map[string]*entityProfile)
+	var names []string
+	for _, atom := range atoms {
+		if atom.GetType() != atomspace.ConceptNodeType || isDerivedConcept(atom.GetName()) || atomspace.IsProtected(atom) {
+			continue
+		}
+		profile := &entityProfile{atom: atom, trigrams: nameTrigrams(atom.GetName()), features: make(map[string]bool)}
+		if embed != nil {
+			profile.embedding, _ = embed(atom)
+		}
+		profiles[atom.GetName()] = profile
+		names = append(names, atom.GetName())
+	}
+	sort.Strings(names)
+	if config.MaxConcepts > 0 && len(names) > config.MaxConcepts {
+		for _, name := range names[config.MaxConcepts:] {
+			delete(profiles, name)
+		}
+		names = names[:config.MaxConcepts]
+	}
+
+	for _, atom := range atoms {
+		link, ok := atom.(*atomspace.Link)
+		if !ok {
+			continue
+		}
+		for _, target := range link.GetOutgoing() {
+			if profile, exists := profiles[target.GetName()]; exists && profile.atom.GetID() == target.GetID() {
+				profile.incoming++
+			}
+		}
+		for subject, feature := range linkFeatures(link, nil) {
+			if profile, exists := profiles[subject]; exists {
+				profile.features[feature] = true
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Candidate pairs share a name trigram or a link feature; with
+	// embeddings every pair is a candidate
+	candidates := make(map[[2]string]bool)
+	if embed != nil && config.EmbeddingWeight > 0 {
+		for i := range names {
+			for j := i + 1; j < len(names); j++ {
+				candidates[[2]string{names[i], names[j]}] = true
+			}
+		}
+	} else {
+		index := make(map[string][]string)
+		for _, name := range names {
+			for trigram := range profiles[name].trigrams {
+				index["t:"+trigram] = append(index["t:"+trigram], name)
+			}
+			for feature := range profiles[name].features {
+				index["f:"+feature] = append(index["f:"+feature], name)
+			}
+		}
+		for _, group := range index {
+			if len(group) > maxCandidateGroup {
+				continue
+			}
+			for i := range group {
+				for j := i + 1; j < len(group); j++ {
+					candidates[[2]string{group[i], group[j]}] = true
+				}
+			}
+		}
+	}
+
+	var found []MergeCandidate
+	for pair := range candidates {
+		a, b := profiles[pair[0]], profiles[pair[1]]
+		c := MergeCandidate{
+			NameSimilarity: jaccard(a.trigrams, b.trigrams),
+			LinkSimilarity: jaccard(a.features, b.features),
+		}
+		total, weights := config.NameWeight*c.NameSimilarity, config.NameWeight
+		if len(a.features) > 0 || len(b.features) > 0 {
+			total += config.LinkWeight * c.LinkSimilarity
+			weights += config.LinkWeight
+		}
+		if len(a.embedding) > 0 && len(a.embedding) == len(b.embedding) {
+			c.EmbeddingSim = math.Max(0, cosine(a.embedding, b.embedding))
+			total += config.EmbeddingWeight * c.EmbeddingSim
+			weights += config.EmbeddingWeight
+		}
+		if weights == 0 {
+			continue
+		}
+		if c.Confidence = total / weights; c.Confidence < config.MinConfidence {
+			continue
+		}
+		into, merged := a, b
+		if keepOver(b, a) {
+			into, merged = b, a
+		}
+		c.Into, c.IntoName = into.atom.GetID(), into.atom.GetName()
+		c.Atom, c.AtomName = merged.atom.GetID(), merged.atom.GetName()
+		found = append(found, c)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Confidence != found[j].Confidence {
+			return found[i].Confidence > found[j].Confidence
+		}
+		if found[i].IntoName != found[j].IntoName {
+			return found[i].IntoName < found[j].IntoName
+		}
+		return found[i].AtomName < found[j].AtomName
+	})
+
+	// Each concept is merged away at most once, most confident first, and
+	// a concept merged away keeps nothing merged into it
+	var result []MergeCandidate
+	var recs []recommendations.Recommendation
+	mergedAway := make(map[string]bool)
+	for _, c := range found {
+		if mergedAway[c.Atom] || mergedAway[c.Into] {
+			continue
+		}
+		mergedAway[c.Atom] = true
+		reason := fmt.Sprintf("name similarity %.2f, shared links %.2f", c.NameSimilarity, c.LinkSimilarity)
+		if c.EmbeddingSim > 0 {
+			reason += fmt.Sprintf(", embedding similarity %.2f", c.EmbeddingSim)
+		}
+
+		if config.AutoMergeAbove > 0 && c.Confidence >= config.AutoMergeAbove && ea.merge != nil {
+			if err := ea.merge(c.Into, []string{c.Atom}); err == nil {
+				c.Merged = true
+			} else {
+				reason += fmt.Sprintf("; merging failed: %v", err)
+			}
+		}
+		if !c.Merged {
+			subject := fmt.Sprintf("%s into %s", c.AtomName, c.IntoName)
+			c.RecommendationID = recommendations.ID(recommendations.KindMerge, c.Atom+"/"+c.Into)
+			recs = append(recs, recommendations.Recommendation{
+				ID:         c.RecommendationID,
+				Kind:       recommendations.KindMerge,
+				Subject:    subject,
+				Resources:  []string{c.Into, c.Atom},
+				Reason:     reason,
+				Confidence: c.Confidence,
+				AtomID:     c.Into,
+			})
+		}
+		debugger.Decide(ctx, "entity-resolution", c.AtomName+"/"+c.IntoName, reason,
+			map[string]interface{}{"confidence": c.Confidence, "merged": c.Merged})
+		result = append(result, c)
+	}
+
+	if ea.store != nil {
+		ea.store.Update(ea.TenantID, recs, recommendations.KindMerge)
+		for _, rec := range recs {
+			// Proposals submitted or decided before stay as they are
+			ea.store.Submit(ea.TenantID, rec.ID, ea.ID)
+		}
+	}
+	return result, nil
+}
+
+// keepOver reports whether concept a is kept over b: the one more linked
+// to, then the more confident, then the shorter name
+func keepOver(a, b *entityProfile) bool {
+	if a.incoming != b.incoming {
+		return a.incoming > b.incoming
+	}
+	if ca, cb := a.atom.GetTruthValue().Confidence, b.atom.GetTruthValue().Confidence; ca != cb {
+		return ca > cb
+	}
+	if len(a.atom.GetName()) != len(b.atom.GetName()) {
+		return len(a.atom.GetName()) < len(b.atom.GetName())
+	}
+	return a.atom.GetName() < b.atom.GetName()
+}
+
+// nameTrigrams returns the trigrams of a name's lowercase letters and
+// digits, so that node-7 and k8s-node-7 share most of theirs
+func nameTrigrams(name string) map[string]bool {
+	normalized := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+	runes := []rune(normalized)
+	trigrams := make(map[string]bool)
+	if len(runes) > 0 && len(runes) < 3 {
+		trigrams[string(runes)] = true
+	}
+	for i := 0; i+3 <= len(runes); i++ {
+		trigrams[string(runes[i:i+3])] = true
+	}
+	return trigrams
+}
@@ -0,0 +1,378 @@
+package agents
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/counterfactuals"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/debugger"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/decisions"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/schemas"
+)
+
+// Names written for executed schema calls
+const (
+	ExecutedPredicate        = "executed"         // executed(call, outcome:OUTCOME)
+	ExecutionOutputPredicate = "execution_output" // execution_output(call, output)
+
+	CounterfactualSourceExecution = "execution" // Source of the counterfactuals of held calls
+)
+
+// ExecutionConfig controls how the execution agent runs schema calls
+type ExecutionConfig struct {
+	Interval    time.Duration `json:"interval_ns"`  // Minimum time between scheduled runs
+	MaxCalls    int           `json:"max_calls"`    // Calls executed per run
+	MaxAttempts int           `json:"max_attempts"` // Attempts of a failing call before it is recorded as failed
+	MaxResults  int           `json:"max_results"`  // Results kept in the history
+}
+
+// DefaultExecutionConfig returns the default execution agent configuration
+func DefaultExecutionConfig() ExecutionConfig {
+	return ExecutionConfig{
+		Interval:    10 * time.Second,
+		MaxCalls:    100,
+		MaxAttempts: 3,
+		MaxResults:  100,
+	}
+}
+
+// ExecutionAgent executes ExecutionLink(GroundedSchemaNode, arguments...)
+// atoms of a tenant with a positive strength by calling the Go function
+// registered for the schema, with the arguments as currently stored. Each
+// call is executed once and recorded as
+//
+//	executed(call, outcome:OUTCOME)
+//	execution_output(call, output)   if the function returned an atom
+//
+// A failing call is retried on later runs up to MaxAttempts. Calls of
+// schemas not registered for the tenant wait until they are, and calls
+// whose truth value falls short of the tenant's decision threshold for the
+// schema's action class are held until it meets it, and recorded as
+// counterfactuals. Calls are also held while the hold function set with
+// SetHold gives a reason, e.g. during a maintenance window.
+type ExecutionAgent struct {
+	BaseAgent
+	atomSpace       atomspace.AtomSpaceInterface
+	registry        *schemas.Registry
+	policies        *decisions.Registry
+	counterfactuals *counterfactuals.Store
+	config          ExecutionConfig
+	results         []schemas.Result
+	attempts        map[string]int               // Failed attempts of pending calls
+	held            map[string]heldCall          // Calls held, as last recorded
+	holdFunc        func(tenantID string) string // Why calls are held regardless of policy; "" if not
+	lastRun         time.Time
+	runMu           sync.Mutex
+}
+
+// NewExecutionAgent creates a new execution agent
+func NewExecutionAgent(id, name, tenantID string, atomSpace atomspace.AtomSpaceInterface, registry *schemas.Registry, policies *decisions.Registry, counterfactuals *counterfactuals.Store, config ExecutionConfig) *ExecutionAgent {
+	return &ExecutionAgent{
+		BaseAgent: BaseAgent{
+			ID:       id,
+			Name:     name,
+			TenantID: tenantID,
+			Priority: 7,
+			State:    AgentStateIdle,
+		},
+		atomSpace:       atomSpace,
+		registry:        registry,
+		policies:        policies,
+		counterfactuals: counterfactuals,
+		config:          config,
+		attempts:        make(map[string]int),
+		held:            make(map[string]heldCall),
+	}
+}
+
+// SetConfig replaces the execution agent configuration
+func (ea *ExecutionAgent) SetConfig(config ExecutionConfig) {
+	ea.mu.Lock()
+	defer ea.mu.Unlock()
+	ea.config = config
+}
+
+// GetConfig returns the execution agent configuration
+func (ea *ExecutionAgent) GetConfig() ExecutionConfig {
+	ea.mu.RLock()
+	defer ea.mu.RUnlock()
+	return ea.config
+}
+
+// SetHold sets the function telling why the tenant's calls are held
+// regardless of their truth value, or an empty string if they are not
+func (ea *ExecutionAgent) SetHold(hold func(tenantID string) string) {
+	ea.mu.Lock()
+	defer ea.mu.Unlock()
+	ea.holdFunc = hold
+}
+
+// GetResults returns the results of recent calls, oldest first
+func (ea *ExecutionAgent) GetResults() []schemas.Result {
+	ea.mu.RLock()
+	defer ea.mu.RUnlock()
+	return append([]schemas.Result(nil), ea.results...)
+}
+
+// Run executes pending calls once the configured interval has elapsed
+func (ea *ExecutionAgent) Run(ctx context.Context) error {
+	ea.mu.RLock()
+	due := time.Since(ea.lastRun) >= ea.config.Interval
+	ea.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	_, err := ea.Execute(ctx)
+	return err
+}
+
+// Execute runs the pending calls of registered schemas, up to MaxCalls,
+// and returns their results
+func (ea *ExecutionAgent) Execute(ctx context.Context) ([]schemas.Result, error) {
+	ea.runMu.Lock()
+	defer ea.runMu.Unlock()
+
+	ea.mu.Lock()
+	ea.State = AgentStateRunning
+	config := ea.config
+	ea.mu.Unlock()
+
+	start := time.Now()
+	results, err := ea.execute(ctx, config)
+
+	ea.mu.Lock()
+	ea.RunCount++
+	ea.LastRun = time.Now()
+	ea.TotalTime += time.Since(start)
+	ea.lastRun = start
+	ea.results = append(ea.results, results...)
+	if config.MaxResults > 0 && len(ea.results) > config.MaxResults {
+		ea.results = append([]schemas.Result(nil), ea.results[len(ea.results)-config.MaxResults:]...)
+	}
+	if err != nil {
+		ea.State = AgentStateError
+	} else {
+		ea.State = AgentStateIdle
+	}
+	ea.mu.Unlock()
+
+	return results, err
+}
+
+func (ea *ExecutionAgent) execute(ctx context.Context, config ExecutionConfig) ([]schemas.Result, error) {
+	pending := ea.pending()
+	policy, _ := ea.policies.Get(ea.TenantID)
+	ea.mu.RLock()
+	holdFunc := ea.holdFunc
+	ea.mu.RUnlock()
+	results := make([]schemas.Result, 0)
+	executed := 0
+	for _, call := range pending {
+		if config.MaxCalls > 0 && executed >= config.MaxCalls {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		schema, err := ea.registry.Get(ea.TenantID, call.Outgoing[0].GetName())
+		if err != nil {
+			continue
+		}
+		class := policy.ClassOf(schema.Name, schema.Class)
+		blocker, reason := counterfactuals.BlockerDecisionPolicy, policy.Decide(class, call.GetTruthValue())
+		if holdFunc != nil {
+			if held := holdFunc(ea.TenantID); held != "" {
+				blocker, reason = counterfactuals.BlockerMaintenance, held
+			}
+		}
+		if reason != "" {
+			if result, changed := ea.hold(call, class, reason); changed {
+				if err := ea.suppress(call, blocker, result); err != nil {
+					return results, err
+				}
+				results = append(results, result)
+			}
+			debugger.Decide(ctx, "execution", schema.Name, reason,
+				map[string]interface{}{"call": call.GetID(), "outcome": schemas.OutcomeHeld, "class": class})
+			continue
+		}
+		ea.mu.Lock()
+		_, wasHeld := ea.held[call.GetID()]
+		delete(ea.held, call.GetID())
+		ea.mu.Unlock()
+		if wasHeld {
+			ea.counterfactuals.Release(ea.TenantID, counterfactuals.ID(CounterfactualSourceExecution, call.GetID()))
+		}
+
+		executed++
+		result, err := ea.call(ctx, call, class, config)
+		if err != nil {
+			return results, err
+		}
+		debugger.Decide(ctx, "execution", result.Schema, result.Error,
+			map[string]interface{}{"call": result.Call, "outcome": result.Outcome, "attempt": result.Attempt})
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// heldCall is the truth value of a held call and why it was held
+type heldCall struct {
+	tv     atomspace.TruthValue
+	reason string
+}
+
+// hold returns the result of a held call, and whether it should be
+// recorded: the first time it is held, and whenever its truth value or the
+// reason changed since
+func (ea *ExecutionAgent) hold(call *atomspace.Link, class decisions.Class, reason string) (schemas.Result, bool) {
+	current := heldCall{tv: call.GetTruthValue(), reason: reason}
+
+	ea.mu.Lock()
+	previous, wasHeld := ea.held[call.GetID()]
+	ea.held[call.GetID()] = current
+	ea.mu.Unlock()
+
+	outgoing := call.GetOutgoing()
+	result := schemas.Result{
+		Call:      call.GetID(),
+		Schema:    outgoing[0].GetName(),
+		Class:     class,
+		Arguments: make([]string, 0, len(outgoing)-1),
+		Outcome:   schemas.OutcomeHeld,
+		Error:     reason,
+		StartedAt: time.Now(),
+	}
+	for _, arg := range outgoing[1:] {
+		result.Arguments = append(result.Arguments, arg.GetName())
+	}
+	return result, !wasHeld || previous != current
+}
+
+// suppress records a held call as a counterfactual
+func (ea *ExecutionAgent) suppress(call *atomspace.Link, blocker string, result schemas.Result) error {
+	_, err := RecordCounterfactual(ea.atomSpace, ea.counterfactuals, ea.TenantID, counterfactuals.Counterfactual{
+		Source:     CounterfactualSourceExecution,
+		Action:     result.Schema,
+		Class:      string(result.Class),
+		Arguments:  result.Arguments,
+		AtomID:     call.GetID(),
+		Blocker:    blocker,
+		Reason:     result.Error,
+		TruthValue: call.GetTruthValue(),
+	}, call)
+	return err
+}
+
+// pending returns the tenant's calls with a positive strength that were not
+// executed yet, sorted by ID
+func (ea *ExecutionAgent) pending() []*atomspace.Link {
+	calls := make([]*atomspace.Link, 0)
+	executed := make(map[string]bool)
+	for _, atom := range ea.atomSpace.QueryAtoms(ea.TenantID, nil) {
+		link, ok := atom.(*atomspace.Link)
+		if !ok {
+			continue
+		}
+		outgoing := link.GetOutgoing()
+		switch {
+		case link.GetType() == atomspace.ExecutionLinkType && len(outgoing) > 0 &&
+			outgoing[0].GetType() == atomspace.GroundedSchemaNodeType && link.GetTruthValue().Strength > 0:
+			calls = append(calls, link)
+		case link.GetType() == atomspace.EvaluationLinkType && link.GetName() == ExecutedPredicate && len(outgoing) == 3:
+			executed[outgoing[1].GetID()] = true
+		}
+	}
+
+	pending := calls[:0]
+	for _, call := range calls {
+		if !executed[call.GetID()] {
+			pending = append(pending, call)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].GetID() < pending[j].GetID() })
+	return pending
+}
+
+// call executes one call and records its outcome. Only failures to write
+// the outcome are returned as errors.
+func (ea *ExecutionAgent) call(ctx context.Context, link *atomspace.Link, class decisions.Class, config ExecutionConfig) (schemas.Result, error) {
+	outgoing := link.GetOutgoing()
+	result := schemas.Result{
+		Call:      link.GetID(),
+		Schema:    outgoing[0].GetName(),
+		Class:     class,
+		Arguments: make([]string, 0, len(outgoing)-1),
+		StartedAt: time.Now(),
+	}
+
+	args := make([]atomspace.Atom, 0, len(outgoing)-1)
+	var err error
+	for _, arg := range outgoing[1:] {
+		result.Arguments = append(result.Arguments, arg.GetName())
+		stored, getErr := ea.atomSpace.GetAtom(arg.GetID(), ea.TenantID)
+		if getErr != nil && err == nil {
+			err = getErr
+		}
+		args = append(args, stored)
+	}
+	var output atomspace.Atom
+	if err == nil {
+		output, err = ea.registry.Call(ctx, schemas.Call{
+			TenantID:  ea.TenantID,
+			Schema:    result.Schema,
+			Link:      link,
+			Arguments: args,
+		})
+	}
+	result.Duration = time.Since(result.StartedAt)
+
+	ea.mu.Lock()
+	ea.attempts[link.GetID()]++
+	result.Attempt = ea.attempts[link.GetID()]
+	ea.mu.Unlock()
+
+	if err != nil {
+		result.Outcome, result.Error = schemas.OutcomeFailed, err.Error()
+		if config.MaxAttempts > 0 && result.Attempt < config.MaxAttempts {
+			return result, nil
+		}
+	} else {
+		result.Outcome = schemas.OutcomeSucceeded
+	}
+
+	ea.mu.Lock()
+	delete(ea.attempts, link.GetID())
+	ea.mu.Unlock()
+	return result, ea.record(link, output, &result)
+}
+
+// record writes the outcome and output of a call
+func (ea *ExecutionAgent) record(link *atomspace.Link, output atomspace.Atom, result *schemas.Result) error {
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	if output != nil {
+		if output.GetTenantID() != ea.TenantID {
+			output = atomspace.WithTenant(output, ea.TenantID)
+		}
+		if _, err := ea.atomSpace.GetAtom(output.GetID(), ea.TenantID); err != nil {
+			if err := ea.atomSpace.AddAtom(output); err != nil {
+				return err
+			}
+		}
+		if _, err := upsertRelation(ea.atomSpace, ea.TenantID, ExecutionOutputPredicate, []atomspace.Atom{link, output}, full); err != nil {
+			return err
+		}
+		result.Output = output.GetID()
+	}
+
+	outcome, err := upsertConcept(ea.atomSpace, ea.TenantID, OutcomePrefix+string(result.Outcome), full)
+	if err != nil {
+		return err
+	}
+	_, err = upsertRelation(ea.atomSpace, ea.TenantID, ExecutedPredicate, []atomspace.Atom{link, outcome}, full)
+	return err
+}
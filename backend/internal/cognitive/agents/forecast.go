@@ -0,0 +1,303 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/forecast"
+)
+
+// TimeSeriesPrefix starts the names of the concept nodes (TimeSeriesNodes)
+// standing for a tenant's time series
+const TimeSeriesPrefix = "timeseries:"
+
+// ForecastThreshold asks whether series starting with SeriesPrefix will
+// exceed Value within the given time
+type ForecastThreshold struct {
+	SeriesPrefix string        `json:"series_prefix"`
+	Value        float64       `json:"value"`
+	Within       time.Duration `json:"within_ns"`
+}
+
+// ForecastConfig controls the models fitted and the atoms written
+type ForecastConfig struct {
+	Params      forecast.Params     `json:"params"`
+	Horizon     time.Duration       `json:"horizon_ns"`
+	Checkpoints []time.Duration     `json:"checkpoints_ns"` // Horizons written as PredictionLinks
+	Thresholds  []ForecastThreshold `json:"thresholds"`
+	Interval    time.Duration       `json:"interval_ns"` // Minimum time between scheduled runs
+}
+
+// DefaultForecastConfig forecasts a week ahead with a linear trend
+func DefaultForecastConfig() ForecastConfig {
+	return ForecastConfig{
+		Params:      forecast.DefaultParams(),
+		Horizon:     7 * 24 * time.Hour,
+		Checkpoints: []time.Duration{time.Hour, 24 * time.Hour, 7 * 24 * time.Hour},
+		Interval:    5 * time.Minute,
+	}
+}
+
+// ThresholdForecast is the outcome of a threshold question for one series
+type ThresholdForecast struct {
+	Series      string    `json:"series"`
+	Threshold   float64   `json:"threshold"`
+	Within      string    `json:"within"`
+	Probability float64   `json:"probability"`
+	ReachedAt   time.Time `json:"reached_at,omitempty"`
+	AtomID      string    `json:"atom_id"`
+}
+
+// ForecastAgent fits forecasting models over a tenant's time series and
+// records the results as PredictionLinks:
+//
+//	predicted_value(TimeSeriesNode, horizon:24h, value:V, lower:L, upper:U)
+//
+// and, for configured thresholds, will_exceed(TimeSeriesNode, threshold:T,
+// within:D) links whose strength is the probability of exceeding T
+type ForecastAgent struct {
+	BaseAgent
+	atomSpace   atomspace.AtomSpaceInterface
+	store       *forecast.Store
+	config      ForecastConfig
+	forecasts   map[string]*forecast.Forecast // series -> latest forecast
+	thresholds  []ThresholdForecast
+	predictions map[string][]string // series -> IDs of its current PredictionLinks
+	lastRun     time.Time
+	runMu       sync.Mutex
+}
+
+// NewForecastAgent creates a new forecasting agent over a sample store
+func NewForecastAgent(id, name, tenantID string, atomSpace atomspace.AtomSpaceInterface, store *forecast.Store, config ForecastConfig) *ForecastAgent {
+	return &ForecastAgent{
+		BaseAgent: BaseAgent{
+			ID:       id,
+			Name:     name,
+			TenantID: tenantID,
+			Priority: 3,
+			State:    AgentStateIdle,
+		},
+		atomSpace:   atomSpace,
+		store:       store,
+		config:      config,
+		forecasts:   make(map[string]*forecast.Forecast),
+		predictions: make(map[string][]string),
+	}
+}
+
+// SetConfig replaces the forecasting configuration
+func (fa *ForecastAgent) SetConfig(config ForecastConfig) {
+	fa.mu.Lock()
+	defer fa.mu.Unlock()
+	fa.config = config
+}
+
+// GetConfig returns the forecasting configuration
+func (fa *ForecastAgent) GetConfig() ForecastConfig {
+	fa.mu.RLock()
+	defer fa.mu.RUnlock()
+	return fa.config
+}
+
+// GetForecasts returns the latest forecast of every series
+func (fa *ForecastAgent) GetForecasts() []*forecast.Forecast {
+	fa.mu.RLock()
+	defer fa.mu.RUnlock()
+
+	result := make([]*forecast.Forecast, 0, len(fa.forecasts))
+	for _, f := range fa.forecasts {
+		result = append(result, f)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Series < result[j].Series })
+	return result
+}
+
+// GetThresholds returns the latest threshold forecasts
+func (fa *ForecastAgent) GetThresholds() []ThresholdForecast {
+	fa.mu.RLock()
+	defer fa.mu.RUnlock()
+	return append([]ThresholdForecast(nil), fa.thresholds...)
+}
+
+// Run forecasts once the configured interval has elapsed
+func (fa *ForecastAgent) Run(ctx context.Context) error {
+	fa.mu.RLock()
+	due := time.Since(fa.lastRun) >= fa.config.Interval
+	fa.mu.RUnlock()
+	if !due {
+		return nil
+	}
+	return fa.Forecast(ctx)
+}
+
+// Forecast refits every series of the tenant now
+func (fa *ForecastAgent) Forecast(ctx context.Context) error {
+	fa.runMu.Lock()
+	defer fa.runMu.Unlock()
+
+	fa.mu.Lock()
+	fa.State = AgentStateRunning
+	config := fa.config
+	fa.mu.Unlock()
+
+	start := time.Now()
+	forecasts, thresholds, err := fa.forecast(ctx, config)
+
+	fa.mu.Lock()
+	fa.RunCount++
+	fa.LastRun = time.Now()
+	fa.TotalTime += time.Since(start)
+	fa.lastRun = start
+	if err != nil {
+		fa.State = AgentStateError
+	} else {
+		fa.State = AgentStateIdle
+		fa.forecasts = forecasts
+		fa.thresholds = thresholds
+	}
+	fa.mu.Unlock()
+
+	return err
+}
+
+func (fa *ForecastAgent) forecast(ctx context.Context, config ForecastConfig) (map[string]*forecast.Forecast, []ThresholdForecast, error) {
+	forecasts := make(map[string]*forecast.Forecast)
+	var thresholds []ThresholdForecast
+
+	for _, series := range fa.store.Series(fa.TenantID) {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		f, err := forecast.Fit(fa.store.Samples(fa.TenantID, series), config.Params, config.Horizon)
+		if err != nil {
+			// Series with too few samples are forecast once they have more
+			continue
+		}
+		f.Series = series
+		forecasts[series] = f
+
+		node, err := fa.seriesNode(series)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Confidence grows with the amount of history behind the fit
+		confidence := float64(f.Samples) / float64(f.Samples+20)
+
+		if err := fa.writePredictions(series, node, f, config.Checkpoints, confidence); err != nil {
+			return nil, nil, err
+		}
+
+		for _, threshold := range config.Thresholds {
+			if !strings.HasPrefix(series, threshold.SeriesPrefix) {
+				continue
+			}
+			tf, err := fa.writeThreshold(series, node, f, threshold, confidence)
+			if err != nil {
+				return nil, nil, err
+			}
+			thresholds = append(thresholds, tf)
+		}
+	}
+	return forecasts, thresholds, nil
+}
+
+// seriesNode gets or creates the TimeSeriesNode of a series
+func (fa *ForecastAgent) seriesNode(series string) (atomspace.Atom, error) {
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	node, err := upsertConcept(fa.atomSpace, fa.TenantID, TimeSeriesPrefix+series, full)
+	if err != nil {
+		return nil, err
+	}
+	category, err := upsertConcept(fa.atomSpace, fa.TenantID, "TimeSeries", full)
+	if err != nil {
+		return nil, err
+	}
+	return node, upsertInheritance(fa.atomSpace, fa.TenantID, node, category, full)
+}
+
+// writePredictions replaces a series' PredictionLinks with the new forecast
+func (fa *ForecastAgent) writePredictions(series string, node atomspace.Atom, f *forecast.Forecast, checkpoints []time.Duration, confidence float64) error {
+	fa.mu.RLock()
+	previous := fa.predictions[series]
+	fa.mu.RUnlock()
+
+	var ids []string
+	for _, ahead := range checkpoints {
+		point, ok := f.At(ahead)
+		if !ok {
+			continue
+		}
+		id, err := fa.relation("predicted_value", atomspace.TruthValue{Strength: 1.0, Confidence: confidence},
+			node,
+			"horizon:"+ahead.String(),
+			fmt.Sprintf("value:%.4g", point.Value),
+			fmt.Sprintf("lower:%.4g", point.Lower),
+			fmt.Sprintf("upper:%.4g", point.Upper),
+		)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	current := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		current[id] = true
+	}
+	for _, id := range previous {
+		if !current[id] {
+			fa.atomSpace.DeleteAtom(id, fa.TenantID)
+		}
+	}
+
+	fa.mu.Lock()
+	fa.predictions[series] = ids
+	fa.mu.Unlock()
+	return nil
+}
+
+// writeThreshold records the probability that a series exceeds a threshold
+func (fa *ForecastAgent) writeThreshold(series string, node atomspace.Atom, f *forecast.Forecast, threshold ForecastThreshold, confidence float64) (ThresholdForecast, error) {
+	probability, reachedAt := f.ExceedProbability(threshold.Value, threshold.Within)
+	id, err := fa.relation("will_exceed", atomspace.TruthValue{Strength: probability, Confidence: confidence},
+		node,
+		fmt.Sprintf("threshold:%g", threshold.Value),
+		"within:"+threshold.Within.String(),
+	)
+	if err != nil {
+		return ThresholdForecast{}, err
+	}
+	return ThresholdForecast{
+		Series:      series,
+		Threshold:   threshold.Value,
+		Within:      threshold.Within.String(),
+		Probability: probability,
+		ReachedAt:   reachedAt,
+		AtomID:      id,
+	}, nil
+}
+
+// relation upserts predicate(subject, concepts...) as an EvaluationLink
+func (fa *ForecastAgent) relation(predicate string, tv atomspace.TruthValue, subject atomspace.Atom, concepts ...string) (string, error) {
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	args := []atomspace.Atom{subject}
+	for _, name := range concepts {
+		concept, err := upsertConcept(fa.atomSpace, fa.TenantID, name, full)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, concept)
+	}
+
+	link, err := upsertRelation(fa.atomSpace, fa.TenantID, predicate, args, tv)
+	if err != nil {
+		return "", err
+	}
+	return link.GetID(), nil
+}
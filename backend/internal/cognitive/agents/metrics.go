@@ -0,0 +1,90 @@
+package agents
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics receives the supervision metrics of agents. The scheduler
+// reports none until metrics are set, so embedding the engine registers
+// nothing globally.
+type Metrics interface {
+	AgentHealth(tenantID, agentID string, state HealthState)
+	AgentFailed(tenantID, agentID string)
+	AgentRestarted(tenantID, agentID string)
+	AgentRemoved(tenantID, agentID string)
+}
+
+type noMetrics struct{}
+
+func (noMetrics) AgentHealth(tenantID, agentID string, state HealthState) {}
+func (noMetrics) AgentFailed(tenantID, agentID string)                    {}
+func (noMetrics) AgentRestarted(tenantID, agentID string)                 {}
+func (noMetrics) AgentRemoved(tenantID, agentID string)                   {}
+
+// healthGaugeValue maps health states onto the erebus_agent_health gauge
+var healthGaugeValue = map[HealthState]float64{
+	HealthHealthy:     0,
+	HealthFailing:     1,
+	HealthBackoff:     2,
+	HealthQuarantined: 3,
+}
+
+// PrometheusMetrics exports supervision metrics as erebus_agent_* series
+type PrometheusMetrics struct {
+	health   *prometheus.GaugeVec
+	failures *prometheus.CounterVec
+	restarts *prometheus.CounterVec
+}
+
+// NewPrometheusMetrics creates supervision metrics registered with reg
+func NewPrometheusMetrics(reg prometheus.Registerer) (*PrometheusMetrics, error) {
+	m := &PrometheusMetrics{
+		health: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "erebus_agent_health",
+				Help: "Agent health: 0 healthy, 1 failing, 2 restart backoff, 3 quarantined",
+			},
+			[]string{"tenant", "agent"},
+		),
+		failures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "erebus_agent_failures_total",
+				Help: "Total number of failed agent runs",
+			},
+			[]string{"tenant", "agent"},
+		),
+		restarts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "erebus_agent_restarts_total",
+				Help: "Total number of supervisor agent restarts",
+			},
+			[]string{"tenant", "agent"},
+		),
+	}
+	for _, c := range []prometheus.Collector{m.health, m.failures, m.restarts} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// AgentHealth sets the health gauge of an agent
+func (m *PrometheusMetrics) AgentHealth(tenantID, agentID string, state HealthState) {
+	m.health.WithLabelValues(tenantID, agentID).Set(healthGaugeValue[state])
+}
+
+// AgentFailed counts a failed run
+func (m *PrometheusMetrics) AgentFailed(tenantID, agentID string) {
+	m.failures.WithLabelValues(tenantID, agentID).Inc()
+}
+
+// AgentRestarted counts a restart
+func (m *PrometheusMetrics) AgentRestarted(tenantID, agentID string) {
+	m.restarts.WithLabelValues(tenantID, agentID).Inc()
+}
+
+// AgentRemoved drops the health gauge of an unregistered agent
+func (m *PrometheusMetrics) AgentRemoved(tenantID, agentID string) {
+	m.health.DeleteLabelValues(tenantID, agentID)
+}
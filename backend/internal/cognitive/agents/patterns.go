@@ -0,0 +1,429 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/linktypes"
+)
+
+// PatternPrefix starts the names of the concept nodes representing mined
+// patterns
+const PatternPrefix = "pattern:"
+
+// PatternMinerConfig controls which patterns are reported
+type PatternMinerConfig struct {
+	MinSupport    int           `json:"min_support"`    // Subjects that must exhibit the whole pattern
+	MinConfidence float64       `json:"min_confidence"` // Share of antecedent subjects that also show the consequent
+	MaxPatterns   int           `json:"max_patterns"`
+	Interval      time.Duration `json:"interval_ns"` // Minimum time between scheduled mining runs
+}
+
+// DefaultPatternMinerConfig returns the default pattern miner configuration
+func DefaultPatternMinerConfig() PatternMinerConfig {
+	return PatternMinerConfig{
+		MinSupport:    3,
+		MinConfidence: 0.8,
+		MaxPatterns:   100,
+		Interval:      time.Minute,
+	}
+}
+
+// Pattern is a frequent two-edge subgraph around a variable subject $X,
+// read as "subjects with the antecedent edge tend to have the consequent
+// edge", e.g. has_label($X, team-a) => inherits($X, PaymentService)
+type Pattern struct {
+	ID              string    `json:"id"` // ID of the pattern's concept node
+	Name            string    `json:"name"`
+	Antecedent      string    `json:"antecedent"`
+	Consequent      string    `json:"consequent"`
+	Support         int       `json:"support"`
+	AntecedentCount int       `json:"antecedent_count"`
+	Confidence      float64   `json:"confidence"`
+	Examples        []string  `json:"examples"` // Names of some supporting subjects
+	DiscoveredAt    time.Time `json:"discovered_at"`
+}
+
+// PatternMinerAgent discovers frequent subgraph patterns in a tenant's
+// AtomSpace and records them as PatternNodes: concept nodes named
+// "pattern:..." that inherit from the Pattern concept
+type PatternMinerAgent struct {
+	BaseAgent
+	atomSpace atomspace.AtomSpaceInterface
+	config    PatternMinerConfig
+	linkTypes func() linktypes.Table
+	patterns  []Pattern
+	lastMined time.Time
+	mineMu    sync.Mutex
+}
+
+// NewPatternMinerAgent creates a new pattern mining agent
+func NewPatternMinerAgent(id, name, tenantID string, atomSpace atomspace.AtomSpaceInterface, config PatternMinerConfig) *PatternMinerAgent {
+	return &PatternMinerAgent{
+		BaseAgent: BaseAgent{
+			ID:       id,
+			Name:     name,
+			TenantID: tenantID,
+			Priority: 2,
+			State:    AgentStateIdle,
+		},
+		atomSpace: atomSpace,
+		config:    config,
+	}
+}
+
+// SetConfig replaces the miner configuration
+func (pm *PatternMinerAgent) SetConfig(config PatternMinerConfig) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.config = config
+}
+
+// SetLinkTypes gives the miner the semantics of the tenant's relations:
+// symmetric relations read the same from either argument, and subjects of
+// transitive relations also exhibit what the relation reaches through
+// others, e.g. inherits($X, Service) through inherits($X, PaymentService)
+func (pm *PatternMinerAgent) SetLinkTypes(linkTypes func() linktypes.Table) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.linkTypes = linkTypes
+}
+
+// GetConfig returns the miner configuration
+func (pm *PatternMinerAgent) GetConfig() PatternMinerConfig {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.config
+}
+
+// GetPatterns returns the patterns found by the latest mining run
+func (pm *PatternMinerAgent) GetPatterns() []Pattern {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return append([]Pattern(nil), pm.patterns...)
+}
+
+// Run mines patterns once the configured interval has elapsed
+func (pm *PatternMinerAgent) Run(ctx context.Context) error {
+	pm.mu.RLock()
+	due := time.Since(pm.lastMined) >= pm.config.Interval
+	pm.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	_, err := pm.Mine(ctx)
+	return err
+}
+
+// Mine scans the AtomSpace now and returns the patterns found
+func (pm *PatternMinerAgent) Mine(ctx context.Context) ([]Pattern, error) {
+	pm.mineMu.Lock()
+	defer pm.mineMu.Unlock()
+
+	pm.mu.Lock()
+	pm.State = AgentStateRunning
+	config := pm.config
+	var table linktypes.Table
+	if pm.linkTypes != nil {
+		table = pm.linkTypes()
+	}
+	pm.mu.Unlock()
+
+	start := time.Now()
+	patterns, err := pm.mine(ctx, config, table)
+
+	pm.mu.Lock()
+	pm.RunCount++
+	pm.LastRun = time.Now()
+	pm.TotalTime += time.Since(start)
+	pm.lastMined = start
+	if err != nil {
+		pm.State = AgentStateError
+	} else {
+		pm.State = AgentStateIdle
+		pm.patterns = patterns
+	}
+	pm.mu.Unlock()
+
+	return patterns, err
+}
+
+func (pm *PatternMinerAgent) mine(ctx context.Context, config PatternMinerConfig, table linktypes.Table) ([]Pattern, error) {
+	// Collect the edges of every subject as features with the subject
+	// replaced by $X
+	features := make(map[string]map[string]bool) // subject name -> features
+	addFeature := func(subject, feature string) {
+		if features[subject] == nil {
+			features[subject] = make(map[string]bool)
+		}
+		features[subject][feature] = true
+	}
+	transitive := make(map[string]map[string][]string) // relation -> subject -> targets
+	for _, atom := range pm.atomSpace.QueryAtoms(pm.TenantID, nil) {
+		link, ok := atom.(*atomspace.Link)
+		if !ok {
+			continue
+		}
+		for subject, feature := range linkFeatures(link, table) {
+			addFeature(subject, feature)
+		}
+		if relation, a, b, ok := linktypes.Binary(link); ok && table[relation].Transitive && isPatternSubject(a) {
+			if transitive[relation] == nil {
+				transitive[relation] = make(map[string][]string)
+			}
+			transitive[relation][a.GetName()] = append(transitive[relation][a.GetName()], b.GetName())
+		}
+	}
+	for relation, edges := range transitive {
+		name := relation
+		if relation == linktypes.InheritanceRelation {
+			name = linkLabel(atomspace.InheritanceLinkType)
+		}
+		for subject := range edges {
+			for _, target := range reachedThrough(edges, subject) {
+				addFeature(subject, fmt.Sprintf("%s($X, %s)", name, target))
+			}
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Count single features and co-occurring pairs
+	single := make(map[string]int)
+	pairs := make(map[[2]string][]string) // antecedent, consequent -> subjects
+	for subject, set := range features {
+		list := make([]string, 0, len(set))
+		for feature := range set {
+			list = append(list, feature)
+			single[feature]++
+		}
+		for _, a := range list {
+			for _, b := range list {
+				if a != b {
+					key := [2]string{a, b}
+					pairs[key] = append(pairs[key], subject)
+				}
+			}
+		}
+	}
+
+	now := time.Now()
+	var patterns []Pattern
+	for key, subjects := range pairs {
+		support := len(subjects)
+		if support < config.MinSupport {
+			continue
+		}
+		confidence := float64(support) / float64(single[key[0]])
+		if confidence < config.MinConfidence {
+			continue
+		}
+
+		sort.Strings(subjects)
+		if len(subjects) > 5 {
+			subjects = subjects[:5]
+		}
+		patterns = append(patterns, Pattern{
+			Name:            fmt.Sprintf("%s%s => %s", PatternPrefix, key[0], key[1]),
+			Antecedent:      key[0],
+			Consequent:      key[1],
+			Support:         support,
+			AntecedentCount: single[key[0]],
+			Confidence:      confidence,
+			Examples:        subjects,
+			DiscoveredAt:    now,
+		})
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i].Support != patterns[j].Support {
+			return patterns[i].Support > patterns[j].Support
+		}
+		if patterns[i].Confidence != patterns[j].Confidence {
+			return patterns[i].Confidence > patterns[j].Confidence
+		}
+		return patterns[i].Name < patterns[j].Name
+	})
+	if config.MaxPatterns > 0 && len(patterns) > config.MaxPatterns {
+		patterns = patterns[:config.MaxPatterns]
+	}
+
+	for i := range patterns {
+		id, err := pm.recordPattern(patterns[i])
+		if err != nil {
+			return nil, err
+		}
+		patterns[i].ID = id
+	}
+	return patterns, nil
+}
+
+// reachedThrough returns the targets a subject reaches over a transitive
+// relation in two or more hops, at most linktypes.MaxHops
+func reachedThrough(edges map[string][]string, subject string) []string {
+	seen := map[string]bool{subject: true}
+	for _, target := range edges[subject] {
+		seen[target] = true
+	}
+	var reached []string
+	frontier := edges[subject]
+	for depth := 1; depth < linktypes.MaxHops && len(frontier) > 0; depth++ {
+		var next []string
+		for _, name := range frontier {
+			for _, target := range edges[name] {
+				if !seen[target] {
+					seen[target] = true
+					reached = append(reached, target)
+					next = append(next, target)
+				}
+			}
+		}
+		frontier = next
+	}
+	return reached
+}
+
+// linkFeatures returns, for each subject node of a link, the link written
+// with that subject replaced by $X. Symmetric relations of table are
+// written with $X first whichever argument the subject is.
+func linkFeatures(link *atomspace.Link, table linktypes.Table) map[string]string {
+	outgoing := link.GetOutgoing()
+	name := linkLabel(link.GetType())
+
+	// EvaluationLinks carry their predicate as the first outgoing atom
+	if link.GetType() == atomspace.EvaluationLinkType && len(outgoing) >= 2 && outgoing[0].GetType() == atomspace.PredicateNodeType {
+		name = outgoing[0].GetName()
+		outgoing = outgoing[1:]
+	}
+	symmetric := len(outgoing) == 2 && table[name].Symmetric
+
+	result := make(map[string]string)
+	for i, subject := range outgoing {
+		if !isPatternSubject(subject) {
+			continue
+		}
+		// Only the first position of directed links is a subject, e.g. the
+		// child of an inheritance
+		if i > 0 && link.GetType() == atomspace.InheritanceLinkType {
+			break
+		}
+
+		args := make([]string, len(outgoing))
+		for j, arg := range outgoing {
+			if j == i {
+				args[j] = "$X"
+			} else {
+				args[j] = arg.GetName()
+			}
+		}
+		if symmetric && i == 1 {
+			args[0], args[1] = args[1], args[0]
+		}
+		result[subject.GetName()] = fmt.Sprintf("%s(%s)", name, strings.Join(args, ", "))
+	}
+	return result
+}
+
+func isPatternSubject(atom atomspace.Atom) bool {
+	switch atom.GetType() {
+	case atomspace.NodeType, atomspace.ConceptNodeType:
+		return !strings.HasPrefix(atom.GetName(), PatternPrefix)
+	}
+	return false
+}
+
+// linkLabel names the relation of links without a predicate
+func linkLabel(t atomspace.AtomType) string {
+	switch t {
+	case atomspace.InheritanceLinkType:
+		return "inherits"
+	case atomspace.SimilarityLinkType:
+		return "similar"
+	case atomspace.ExecutionLinkType:
+		return "execution"
+	case atomspace.EvaluationLinkType:
+		return "evaluation"
+	}
+	return "link"
+}
+
+// recordPattern upserts the PatternNode of a pattern and classifies it
+func (pm *PatternMinerAgent) recordPattern(p Pattern) (string, error) {
+	tv := atomspace.TruthValue{
+		Strength:   p.Confidence,
+		Confidence: float64(p.Support) / float64(p.Support+10),
+	}
+	node, err := upsertConcept(pm.atomSpace, pm.TenantID, p.Name, tv)
+	if err != nil {
+		return "", err
+	}
+
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	category, err := upsertConcept(pm.atomSpace, pm.TenantID, "Pattern", full)
+	if err != nil {
+		return "", err
+	}
+	if err := upsertInheritance(pm.atomSpace, pm.TenantID, node, category, full); err != nil {
+		return "", err
+	}
+	return node.GetID(), nil
+}
+
+// upsertAtom adds an atom or refreshes the truth value of the existing one
+func upsertAtom(space atomspace.AtomSpaceInterface, tenantID string, atom atomspace.Atom, tv atomspace.TruthValue) (atomspace.Atom, error) {
+	atom.SetTruthValue(tv)
+	if _, err := space.GetAtom(atom.GetID(), tenantID); err != nil {
+		return atom, space.AddAtom(atom)
+	}
+	return atom, space.UpdateAtom(atom.GetID(), tenantID, func(existing atomspace.Atom) error {
+		existing.SetTruthValue(tv)
+		return nil
+	})
+}
+
+// upsertConcept gets or creates a ConceptNode
+func upsertConcept(space atomspace.AtomSpaceInterface, tenantID, name string, tv atomspace.TruthValue) (atomspace.Atom, error) {
+	node := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, tenantID, atomspace.ConceptNodeType)
+	return upsertAtom(space, tenantID, node, tv)
+}
+
+// ensureConcept gets a ConceptNode, creating it with tv only if it does not
+// exist, so ingested concepts keep their truth values
+func ensureConcept(space atomspace.AtomSpaceInterface, tenantID, name string, tv atomspace.TruthValue) (atomspace.Atom, error) {
+	id := atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil)
+	if existing, err := space.GetAtom(id, tenantID); err == nil {
+		return existing, nil
+	}
+	return upsertConcept(space, tenantID, name, tv)
+}
+
+// upsertInheritance gets or creates the InheritanceLink child -> parent
+func upsertInheritance(space atomspace.AtomSpaceInterface, tenantID string, child, parent atomspace.Atom, tv atomspace.TruthValue) error {
+	outgoing := []atomspace.Atom{child, parent}
+	link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", outgoing), "inheritance", tenantID, atomspace.InheritanceLinkType, outgoing)
+	_, err := upsertAtom(space, tenantID, link, tv)
+	return err
+}
+
+// upsertRelation gets or creates predicate(args...) as an EvaluationLink
+// whose outgoing set is the predicate node followed by the arguments
+func upsertRelation(space atomspace.AtomSpaceInterface, tenantID, predicate string, args []atomspace.Atom, tv atomspace.TruthValue) (atomspace.Atom, error) {
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, predicate, nil), predicate, tenantID, atomspace.PredicateNodeType)
+	predAtom, err := upsertAtom(space, tenantID, pred, full)
+	if err != nil {
+		return nil, err
+	}
+
+	outgoing := append([]atomspace.Atom{predAtom}, args...)
+	link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, predicate, outgoing), predicate, tenantID, atomspace.EvaluationLinkType, outgoing)
+	return upsertAtom(space, tenantID, link, tv)
+}
@@ -0,0 +1,444 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/cost"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/debugger"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/recommendations"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+)
+
+// Names written by the recommendation agent
+const (
+	RecommendationPrefix  = "recommendation:"
+	RecommendationConcept = "Recommendation"
+
+	recommendsPredicate      = "recommends"
+	expectedSavingsPredicate = "expected_savings"
+)
+
+// RecommendationConfig controls the recommendations made from utilization,
+// cost and topology
+type RecommendationConfig struct {
+	UtilizationPredicates []string         `json:"utilization_predicates"` // Metric relations giving utilization; the highest counts
+	ResizeBelow           float64          `json:"resize_below"`           // Utilization below which resources are downsized
+	ScaleAbove            float64          `json:"scale_above"`            // Utilization above which capacity is added
+	TargetUtilization     float64          `json:"target_utilization"`     // Utilization recommended changes aim for
+	HostRelation          string           `json:"host_relation"`          // Topology relation workload -> host, e.g. runs_on(pod, node)
+	MinConfidence         float64          `json:"min_confidence"`         // Less confident recommendations are not made
+	SubmitAbove           float64          `json:"submit_above,omitempty"` // Confidence from which recommendations enter the approval queue by themselves; 0 never
+	OnApproval            *triggers.Action `json:"on_approval,omitempty"`  // Performed with each approved recommendation
+	Interval              time.Duration    `json:"interval_ns"`            // Minimum time between scheduled runs
+}
+
+// DefaultRecommendationConfig returns the default recommendation agent
+// configuration
+func DefaultRecommendationConfig() RecommendationConfig {
+	return RecommendationConfig{
+		UtilizationPredicates: []string{"cpu_usage", "memory_usage"},
+		ResizeBelow:           0.3,
+		ScaleAbove:            0.85,
+		TargetUtilization:     0.6,
+		HostRelation:          "runs_on",
+		MinConfidence:         0.3,
+		Interval:              15 * time.Minute,
+	}
+}
+
+// utilization is the measured utilization of a resource
+type utilization struct {
+	value      float64
+	confidence float64
+}
+
+// RecommendationAgent combines a tenant's utilization metrics, its cost
+// model and its topology into recommendations:
+//
+//   - resize a resource used below ResizeBelow to one run at
+//     TargetUtilization
+//   - scale a resource used above ScaleAbove, at a cost
+//   - consolidate hosts of the same category, those that workloads run on
+//     through HostRelation, when their load fits on fewer of them
+//
+// Each is written as a recommendation:KIND:SUBJECT concept inheriting from
+// Recommendation, whose strength is its confidence, related to its
+// resources by recommends(recommendation, resource) and to its monthly
+// savings by expected_savings(recommendation, usd:AMOUNT). Recommendations
+// are kept in a store, from which they are submitted for approval.
+type RecommendationAgent struct {
+	BaseAgent
+	atomSpace atomspace.AtomSpaceInterface
+	model     *cost.Model
+	store     *recommendations.Store
+	config    RecommendationConfig
+	written   map[string][]string // recommendation ID -> IDs of its atoms, concept first
+	lastRun   time.Time
+	runMu     sync.Mutex
+}
+
+// NewRecommendationAgent creates a new recommendation agent over a cost
+// model, keeping its recommendations in store
+func NewRecommendationAgent(id, name, tenantID string, atomSpace atomspace.AtomSpaceInterface, model *cost.Model, store *recommendations.Store, config RecommendationConfig) *RecommendationAgent {
+	return &RecommendationAgent{
+		BaseAgent: BaseAgent{
+			ID:       id,
+			Name:     name,
+			TenantID: tenantID,
+			Priority: 3,
+			State:    AgentStateIdle,
+		},
+		atomSpace: atomSpace,
+		model:     model,
+		store:     store,
+		config:    config,
+		written:   make(map[string][]string),
+	}
+}
+
+// SetConfig replaces the recommendation agent configuration
+func (ra *RecommendationAgent) SetConfig(config RecommendationConfig) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	ra.config = config
+}
+
+// GetConfig returns the recommendation agent configuration
+func (ra *RecommendationAgent) GetConfig() RecommendationConfig {
+	ra.mu.RLock()
+	defer ra.mu.RUnlock()
+	return ra.config
+}
+
+// Run recommends once the configured interval has elapsed
+func (ra *RecommendationAgent) Run(ctx context.Context) error {
+	ra.mu.RLock()
+	due := time.Since(ra.lastRun) >= ra.config.Interval
+	ra.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	_, err := ra.Recommend(ctx)
+	return err
+}
+
+// Recommend reviews the tenant's resources now and returns the
+// recommendations made
+func (ra *RecommendationAgent) Recommend(ctx context.Context) ([]recommendations.Recommendation, error) {
+	ra.runMu.Lock()
+	defer ra.runMu.Unlock()
+
+	ra.mu.Lock()
+	ra.State = AgentStateRunning
+	config := ra.config
+	ra.mu.Unlock()
+
+	start := time.Now()
+	recs, err := ra.recommend(ctx, config)
+
+	ra.mu.Lock()
+	ra.RunCount++
+	ra.LastRun = time.Now()
+	ra.TotalTime += time.Since(start)
+	ra.lastRun = start
+	if err != nil {
+		ra.State = AgentStateError
+	} else {
+		ra.State = AgentStateIdle
+	}
+	ra.mu.Unlock()
+
+	return recs, err
+}
+
+func (ra *RecommendationAgent) recommend(ctx context.Context, config RecommendationConfig) ([]recommendations.Recommendation, error) {
+	defaults := DefaultRecommendationConfig()
+	if config.TargetUtilization <= 0 || config.TargetUtilization > 1 {
+		config.TargetUtilization = defaults.TargetUtilization
+	}
+	if len(config.UtilizationPredicates) == 0 {
+		config.UtilizationPredicates = defaults.UtilizationPredicates
+	}
+	metric := make(map[string]bool, len(config.UtilizationPredicates))
+	for _, predicate := range config.UtilizationPredicates {
+		metric[predicate] = true
+	}
+
+	// Resources are classified concept nodes, as for the cost agent, and
+	// hosts are the targets of the host relation
+	resources := make(map[string]atomspace.Atom)
+	categories := make(map[string][]string)
+	used := make(map[string]utilization)
+	workloads := make(map[string]int)
+	for _, atom := range ra.atomSpace.QueryAtoms(ra.TenantID, nil) {
+		link, ok := atom.(*atomspace.Link)
+		if !ok {
+			continue
+		}
+		outgoing := link.GetOutgoing()
+		switch {
+		case link.GetType() == atomspace.InheritanceLinkType && len(outgoing) == 2:
+			child := outgoing[0]
+			if child.GetType() != atomspace.ConceptNodeType || isDerivedConcept(child.GetName()) {
+				continue
+			}
+			resources[child.GetName()] = child
+			categories[child.GetName()] = append(categories[child.GetName()], outgoing[1].GetName())
+		case link.GetType() == atomspace.EvaluationLinkType && len(outgoing) == 2 &&
+			outgoing[0].GetType() == atomspace.PredicateNodeType && metric[outgoing[0].GetName()]:
+			tv := link.GetTruthValue()
+			if current, measured := used[outgoing[1].GetName()]; !measured || tv.Strength > current.value {
+				used[outgoing[1].GetName()] = utilization{value: tv.Strength, confidence: tv.Confidence}
+			}
+		case link.GetType() == atomspace.EvaluationLinkType && len(outgoing) == 3 &&
+			outgoing[0].GetType() == atomspace.PredicateNodeType && outgoing[0].GetName() == config.HostRelation:
+			workloads[outgoing[2].GetName()]++
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Hosts of a category are consolidated together; the others, and hosts
+	// left out of a consolidation, are resized or scaled on their own
+	var recs []recommendations.Recommendation
+	groups := make(map[string][]string)
+	rates := make(map[string]cost.Rate)
+	for _, name := range names {
+		rate, ok := ra.model.Lookup(ra.TenantID, name, categories[name])
+		if _, measured := used[name]; !ok || !measured {
+			continue
+		}
+		rates[name] = rate
+		if category := hostCategory(rate, categories[name]); workloads[name] > 0 && category != "" {
+			groups[category] = append(groups[category], name)
+		}
+	}
+	consolidated := make(map[string]bool)
+	groupNames := make([]string, 0, len(groups))
+	for category := range groups {
+		groupNames = append(groupNames, category)
+	}
+	sort.Strings(groupNames)
+	for _, category := range groupNames {
+		rec := consolidate(category, groups[category], used, rates, config.TargetUtilization)
+		if rec == nil {
+			continue
+		}
+		recs = append(recs, *rec)
+		for _, host := range groups[category] {
+			consolidated[host] = true
+		}
+	}
+
+	for _, name := range names {
+		rate, priced := rates[name]
+		if !priced || consolidated[name] {
+			continue
+		}
+		u := used[name]
+		monthly := rate.Hourly * cost.HoursPerMonth
+		confidence := u.confidence * rateWeight(rate)
+		switch {
+		case u.value < config.ResizeBelow:
+			recs = append(recs, recommendations.Recommendation{
+				ID:             recommendations.ID(recommendations.KindResize, name),
+				Kind:           recommendations.KindResize,
+				Subject:        name,
+				Resources:      []string{name},
+				Utilization:    u.value,
+				Reason:         fmt.Sprintf("used at %.0f%%, below %.0f%%; a size run at %.0f%% costs less", u.value*100, config.ResizeBelow*100, config.TargetUtilization*100),
+				MonthlySavings: monthly * (1 - u.value/config.TargetUtilization),
+				Currency:       rate.Currency,
+				Confidence:     confidence,
+			})
+		case u.value > config.ScaleAbove:
+			recs = append(recs, recommendations.Recommendation{
+				ID:             recommendations.ID(recommendations.KindScale, name),
+				Kind:           recommendations.KindScale,
+				Subject:        name,
+				Resources:      []string{name},
+				Utilization:    u.value,
+				Reason:         fmt.Sprintf("used at %.0f%%, above %.0f%%; capacity for %.0f%% costs more", u.value*100, config.ScaleAbove*100, config.TargetUtilization*100),
+				MonthlySavings: -monthly * (u.value/config.TargetUtilization - 1),
+				Currency:       rate.Currency,
+				Confidence:     confidence,
+			})
+		}
+	}
+
+	made := make([]recommendations.Recommendation, 0, len(recs))
+	written := make(map[string][]string, len(recs))
+	for _, rec := range recs {
+		if rec.Confidence < config.MinConfidence {
+			continue
+		}
+		ids, err := ra.write(&rec, resources)
+		if err != nil {
+			return nil, err
+		}
+		written[rec.ID] = ids
+		made = append(made, rec)
+		debugger.Decide(ctx, "recommendation", string(rec.Kind)+"/"+rec.Subject, rec.Reason,
+			map[string]interface{}{"monthly_savings": rec.MonthlySavings, "confidence": rec.Confidence})
+	}
+
+	dropped := ra.store.Update(ra.TenantID, made, recommendations.KindResize, recommendations.KindScale, recommendations.KindConsolidate)
+	ra.prune(written, dropped)
+
+	if config.SubmitAbove > 0 {
+		for _, rec := range made {
+			if rec.Confidence >= config.SubmitAbove {
+				// Recommendations submitted or decided before stay as they are
+				ra.store.Submit(ra.TenantID, rec.ID, ra.ID)
+			}
+		}
+	}
+	return made, nil
+}
+
+// consolidate recommends draining the least used hosts of a category when
+// the load of all of them fits on fewer at the target utilization
+func consolidate(category string, hosts []string, used map[string]utilization, rates map[string]cost.Rate, target float64) *recommendations.Recommendation {
+	if len(hosts) < 2 {
+		return nil
+	}
+	load := 0.0
+	confidence := 1.0
+	for _, host := range hosts {
+		load += used[host].value
+		confidence = math.Min(confidence, used[host].confidence*rateWeight(rates[host]))
+	}
+	needed := int(math.Max(1, math.Ceil(load/target)))
+	if needed >= len(hosts) {
+		return nil
+	}
+
+	sorted := append([]string(nil), hosts...)
+	sort.SliceStable(sorted, func(i, j int) bool { return used[sorted[i]].value < used[sorted[j]].value })
+	drained := sorted[:len(hosts)-needed]
+	savings := 0.0
+	for _, host := range drained {
+		savings += rates[host].Hourly * cost.HoursPerMonth
+	}
+	sort.Strings(drained)
+
+	return &recommendations.Recommendation{
+		ID:             recommendations.ID(recommendations.KindConsolidate, category),
+		Kind:           recommendations.KindConsolidate,
+		Subject:        category,
+		Resources:      drained,
+		Utilization:    load / float64(len(hosts)),
+		Reason:         fmt.Sprintf("%d %s hosts at %.0f%% average utilization fit on %d at %.0f%%", len(hosts), category, load/float64(len(hosts))*100, needed, target*100),
+		MonthlySavings: savings,
+		Currency:       rates[drained[0]].Currency,
+		Confidence:     confidence,
+	}
+}
+
+// hostCategory is the category hosts are consolidated within: that of
+// their rate, or their first category for rates of a single resource
+func hostCategory(rate cost.Rate, categories []string) string {
+	if rate.Category != "" {
+		return rate.Category
+	}
+	if len(categories) > 0 {
+		return categories[0]
+	}
+	return ""
+}
+
+// rateWeight discounts savings estimated from a category's rate rather
+// than the resource's own
+func rateWeight(rate cost.Rate) float64 {
+	if rate.Resource != "" {
+		return 1.0
+	}
+	return 0.8
+}
+
+// write records a recommendation's atoms and returns their IDs
+func (ra *RecommendationAgent) write(rec *recommendations.Recommendation, resources map[string]atomspace.Atom) ([]string, error) {
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	tv := atomspace.TruthValue{Strength: rec.Confidence, Confidence: 0.9}
+
+	node, err := upsertConcept(ra.atomSpace, ra.TenantID, RecommendationPrefix+string(rec.Kind)+":"+rec.Subject, tv)
+	if err != nil {
+		return nil, err
+	}
+	rec.AtomID = node.GetID()
+	category, err := upsertConcept(ra.atomSpace, ra.TenantID, RecommendationConcept, full)
+	if err != nil {
+		return nil, err
+	}
+	if err := upsertInheritance(ra.atomSpace, ra.TenantID, node, category, full); err != nil {
+		return nil, err
+	}
+	ids := []string{node.GetID(), atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", []atomspace.Atom{node, category})}
+
+	for _, name := range rec.Resources {
+		link, err := upsertRelation(ra.atomSpace, ra.TenantID, recommendsPredicate, []atomspace.Atom{node, resources[name]}, tv)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, link.GetID())
+	}
+	amount, err := upsertConcept(ra.atomSpace, ra.TenantID, cost.FormatAmount(rec.MonthlySavings, rec.Currency), full)
+	if err != nil {
+		return nil, err
+	}
+	link, err := upsertRelation(ra.atomSpace, ra.TenantID, expectedSavingsPredicate, []atomspace.Atom{node, amount}, tv)
+	if err != nil {
+		return nil, err
+	}
+	return append(ids, link.GetID()), nil
+}
+
+// prune deletes the atoms of dropped recommendations, and those of
+// recommendations made again that no longer apply. The atoms of
+// recommendations in the approval queue or decided are kept.
+func (ra *RecommendationAgent) prune(written map[string][]string, dropped []string) {
+	isDropped := make(map[string]bool, len(dropped))
+	for _, id := range dropped {
+		isDropped[id] = true
+	}
+
+	ra.mu.RLock()
+	previous := ra.written
+	ra.mu.RUnlock()
+	for recID, ids := range previous {
+		current, made := written[recID]
+		if !made && !isDropped[recID] {
+			written[recID] = ids
+			continue
+		}
+		keep := make(map[string]bool, len(current))
+		for _, id := range current {
+			keep[id] = true
+		}
+		// Links go before the concept they point to
+		for i := len(ids) - 1; i >= 0; i-- {
+			if !keep[ids[i]] {
+				ra.atomSpace.DeleteAtom(ids[i], ra.TenantID)
+			}
+		}
+	}
+
+	ra.mu.Lock()
+	ra.written = written
+	ra.mu.Unlock()
+}
@@ -0,0 +1,215 @@
+package agents
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/reports"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sandbox"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/secrets"
+)
+
+// DigestCollector gathers the content of a report over a period
+type DigestCollector interface {
+	Collect(ctx context.Context, tenantID string, schedule reports.Schedule, from, to time.Time) (*reports.Digest, error)
+}
+
+// ReportConfig controls how often the report agent looks for due reports
+type ReportConfig struct {
+	Interval time.Duration `json:"interval_ns"` // Minimum time between scheduled runs
+}
+
+// DefaultReportConfig returns the default report agent configuration
+func DefaultReportConfig() ReportConfig {
+	return ReportConfig{
+		Interval: time.Minute,
+	}
+}
+
+// ReportAgent generates the reports of a tenant's schedules once their
+// interval has elapsed, renders them and delivers them to their sinks. Each
+// report covers the period since the previous report of its schedule.
+type ReportAgent struct {
+	BaseAgent
+	registry  *reports.Registry
+	collector DigestCollector
+	sender    *reports.Sender
+	secrets   secrets.Resolver
+	sandbox   *sandbox.Tenant // Records deliveries instead while the tenant is in sandbox mode
+	config    ReportConfig
+	lastRun   time.Time
+	runMu     sync.Mutex
+}
+
+// NewReportAgent creates a new report agent
+func NewReportAgent(id, name, tenantID string, registry *reports.Registry, collector DigestCollector, sender *reports.Sender, config ReportConfig) *ReportAgent {
+	return &ReportAgent{
+		BaseAgent: BaseAgent{
+			ID:       id,
+			Name:     name,
+			TenantID: tenantID,
+			Priority: 2,
+			State:    AgentStateIdle,
+		},
+		registry:  registry,
+		collector: collector,
+		sender:    sender,
+		secrets:   secrets.None,
+		config:    config,
+	}
+}
+
+// SetSecrets gives the agent the resolver of the tenant's secret
+// references, which sink URLs and passwords may be
+func (ra *ReportAgent) SetSecrets(resolver secrets.Resolver) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	ra.secrets = resolver
+}
+
+// SetSandbox gives the agent the tenant's sandbox, which records
+// deliveries instead of sending them while it is enabled
+func (ra *ReportAgent) SetSandbox(sb *sandbox.Tenant) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	ra.sandbox = sb
+}
+
+// SetConfig replaces the report agent configuration
+func (ra *ReportAgent) SetConfig(config ReportConfig) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	ra.config = config
+}
+
+// GetConfig returns the report agent configuration
+func (ra *ReportAgent) GetConfig() ReportConfig {
+	ra.mu.RLock()
+	defer ra.mu.RUnlock()
+	return ra.config
+}
+
+// Run generates due reports once the configured interval has elapsed
+func (ra *ReportAgent) Run(ctx context.Context) error {
+	ra.mu.RLock()
+	due := time.Since(ra.lastRun) >= ra.config.Interval
+	ra.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	_, err := ra.GenerateDue(ctx)
+	return err
+}
+
+// GenerateDue generates the reports of every due schedule
+func (ra *ReportAgent) GenerateDue(ctx context.Context) ([]reports.Report, error) {
+	ra.runMu.Lock()
+	defer ra.runMu.Unlock()
+
+	ra.mu.Lock()
+	ra.State = AgentStateRunning
+	ra.mu.Unlock()
+
+	start := time.Now()
+	generated := make([]reports.Report, 0)
+	var err error
+	for _, schedule := range ra.registry.Due(ra.TenantID, start) {
+		var report reports.Report
+		if report, err = ra.generate(ctx, schedule); err != nil {
+			break
+		}
+		generated = append(generated, report)
+	}
+
+	ra.mu.Lock()
+	ra.RunCount++
+	ra.LastRun = time.Now()
+	ra.TotalTime += time.Since(start)
+	ra.lastRun = start
+	if err != nil {
+		ra.State = AgentStateError
+	} else {
+		ra.State = AgentStateIdle
+	}
+	ra.mu.Unlock()
+
+	return generated, err
+}
+
+// Generate generates a schedule's report immediately, regardless of its
+// interval
+func (ra *ReportAgent) Generate(ctx context.Context, name string) (reports.Report, error) {
+	schedule, err := ra.registry.Get(ra.TenantID, name)
+	if err != nil {
+		return reports.Report{}, err
+	}
+
+	ra.runMu.Lock()
+	defer ra.runMu.Unlock()
+	return ra.generate(ctx, schedule)
+}
+
+// generate collects, renders, delivers and records one report. Delivery
+// failures are recorded with the report rather than failing it.
+func (ra *ReportAgent) generate(ctx context.Context, schedule reports.Schedule) (reports.Report, error) {
+	now := time.Now()
+	from := now.Add(-schedule.Interval)
+	var previous *reports.Digest
+	if latest, exists := ra.registry.Latest(ra.TenantID, schedule.Name); exists {
+		from, previous = latest.Digest.To, latest.Digest
+	}
+
+	digest, err := ra.collector.Collect(ctx, ra.TenantID, schedule, from, now)
+	if err != nil {
+		return reports.Report{}, err
+	}
+	digest.Compare(previous)
+
+	content, err := reports.Render(digest, schedule.Format)
+	if err != nil {
+		return reports.Report{}, err
+	}
+	report := reports.Report{
+		Schedule:    schedule.Name,
+		Format:      schedule.Format,
+		Digest:      digest,
+		Content:     content,
+		GeneratedAt: now,
+	}
+	report.Deliveries = ra.deliver(ctx, schedule.Sinks, report)
+	return ra.registry.Record(ra.TenantID, report)
+}
+
+// deliver sends a report to sinks with their secrets resolved. Deliveries
+// name the sinks as configured, and a sink whose secrets do not resolve
+// records the failure as its delivery.
+func (ra *ReportAgent) deliver(ctx context.Context, sinks []reports.Sink, report reports.Report) []reports.Delivery {
+	ra.mu.RLock()
+	resolver := ra.secrets
+	sb := ra.sandbox
+	ra.mu.RUnlock()
+	if sb.Enabled() {
+		return sb.Deliver(sinks, report)
+	}
+
+	deliveries := make([]reports.Delivery, 0, len(sinks))
+	for _, configured := range sinks {
+		sink := configured
+		var err error
+		if sink.URL, err = resolver.Resolve(ctx, sink.URL); err == nil {
+			sink.Password, err = resolver.Resolve(ctx, sink.Password)
+		}
+		if err != nil {
+			deliveries = append(deliveries, reports.Delivery{Sink: sink.Type, Target: configured.Target(), Error: err.Error(), DeliveredAt: time.Now()})
+			continue
+		}
+		for _, delivery := range ra.sender.Deliver(ctx, ra.TenantID, []reports.Sink{sink}, report) {
+			delivery.Target = configured.Target()
+			delivery.Error = resolver.Scrub(delivery.Error)
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries
+}
@@ -0,0 +1,430 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/debugger"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/incidents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/runbooks"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+)
+
+// Names written for runbooks and their runs
+const (
+	RunbookPrefix = "runbook:"
+	OutcomePrefix = "outcome:"
+
+	StepResultPredicate = "step_result"
+	RunbookRunPredicate = "runbook_run"
+	runbookStepLink     = "runbook_step"
+	nextStepPredicate   = "next_step"
+	requiresPredicate   = "requires"
+)
+
+// ActionPerformer carries out runbook step actions
+type ActionPerformer interface {
+	Perform(ctx context.Context, action triggers.Action, input []atomspace.Atom, payload map[string]interface{}) error
+}
+
+// RunbookConfig controls how the runbook agent applies runbooks
+type RunbookConfig struct {
+	DryRun   bool          `json:"dry_run"`     // Propose the steps of execute-mode runbooks too
+	MaxRuns  int           `json:"max_runs"`    // Runs kept in the history
+	Interval time.Duration `json:"interval_ns"` // Minimum time between scheduled runs
+}
+
+// DefaultRunbookConfig returns the default runbook agent configuration
+func DefaultRunbookConfig() RunbookConfig {
+	return RunbookConfig{
+		MaxRuns:  100,
+		Interval: 30 * time.Second,
+	}
+}
+
+// RecordRunbook writes the definition of a runbook to the AtomSpace:
+//
+//	runbook:NAME inherits Runbook
+//	runbook_step(action:TYPE:TARGET, runbook:NAME, runbook:NAME/STEP)   ExecutionLink
+//	next_step(runbook:NAME/STEP, runbook:NAME/NEXT)
+//	requires(runbook:NAME/STEP, condition:PREDICATE>=MIN)
+func RecordRunbook(space atomspace.AtomSpaceInterface, tenantID string, rb runbooks.Runbook) error {
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	node, err := upsertConcept(space, tenantID, RunbookPrefix+rb.Name, full)
+	if err != nil {
+		return err
+	}
+	category, err := upsertConcept(space, tenantID, "Runbook", full)
+	if err != nil {
+		return err
+	}
+	if err := upsertInheritance(space, tenantID, node, category, full); err != nil {
+		return err
+	}
+
+	var previous atomspace.Atom
+	for _, step := range rb.Steps {
+		stepNode, err := upsertConcept(space, tenantID, stepName(rb.Name, step.Name), full)
+		if err != nil {
+			return err
+		}
+		action, err := upsertConcept(space, tenantID, actionName(step.Action), full)
+		if err != nil {
+			return err
+		}
+		outgoing := []atomspace.Atom{action, node, stepNode}
+		link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.ExecutionLinkType, runbookStepLink, outgoing), runbookStepLink, tenantID, atomspace.ExecutionLinkType, outgoing)
+		if _, err := upsertAtom(space, tenantID, link, full); err != nil {
+			return err
+		}
+		if previous != nil {
+			if _, err := upsertRelation(space, tenantID, nextStepPredicate, []atomspace.Atom{previous, stepNode}, full); err != nil {
+				return err
+			}
+		}
+		for _, c := range step.Conditions {
+			condition, err := upsertConcept(space, tenantID, "condition:"+c.String(), full)
+			if err != nil {
+				return err
+			}
+			if _, err := upsertRelation(space, tenantID, requiresPredicate, []atomspace.Atom{stepNode, condition}, full); err != nil {
+				return err
+			}
+		}
+		previous = stepNode
+	}
+	return nil
+}
+
+func stepName(runbook, step string) string {
+	return RunbookPrefix + runbook + "/" + step
+}
+
+func actionName(action triggers.Action) string {
+	target := action.PipelineID
+	switch action.Type {
+	case triggers.ActionWebhook:
+		target = action.URL
+	case triggers.ActionNotify:
+		target = action.Channel
+	}
+	return fmt.Sprintf("action:%s:%s", action.Type, target)
+}
+
+// RunbookAgent matches active Incident atoms to a tenant's runbooks and
+// executes or proposes their steps through the action framework. Each step
+// is recorded as
+//
+//	step_result(runbook:NAME/STEP, incident:ID, outcome:OUTCOME)
+//
+// and each run as runbook_run(runbook:NAME, incident:ID, outcome:OUTCOME),
+// which also keeps the runbook from being applied to the incident again.
+type RunbookAgent struct {
+	BaseAgent
+	atomSpace atomspace.AtomSpaceInterface
+	registry  *runbooks.Registry
+	incidents *incidents.Manager
+	performer ActionPerformer
+	config    RunbookConfig
+	runs      []runbooks.Run
+	lastRun   time.Time
+	runMu     sync.Mutex
+}
+
+// NewRunbookAgent creates a new runbook agent
+func NewRunbookAgent(id, name, tenantID string, atomSpace atomspace.AtomSpaceInterface, registry *runbooks.Registry, manager *incidents.Manager, performer ActionPerformer, config RunbookConfig) *RunbookAgent {
+	return &RunbookAgent{
+		BaseAgent: BaseAgent{
+			ID:       id,
+			Name:     name,
+			TenantID: tenantID,
+			Priority: 7,
+			State:    AgentStateIdle,
+		},
+		atomSpace: atomSpace,
+		registry:  registry,
+		incidents: manager,
+		performer: performer,
+		config:    config,
+	}
+}
+
+// SetConfig replaces the runbook agent configuration
+func (ra *RunbookAgent) SetConfig(config RunbookConfig) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	ra.config = config
+}
+
+// GetConfig returns the runbook agent configuration
+func (ra *RunbookAgent) GetConfig() RunbookConfig {
+	ra.mu.RLock()
+	defer ra.mu.RUnlock()
+	return ra.config
+}
+
+// GetRuns returns the run history, oldest first
+func (ra *RunbookAgent) GetRuns() []runbooks.Run {
+	ra.mu.RLock()
+	defer ra.mu.RUnlock()
+	return append([]runbooks.Run(nil), ra.runs...)
+}
+
+// Run applies runbooks once the configured interval has elapsed
+func (ra *RunbookAgent) Run(ctx context.Context) error {
+	ra.mu.RLock()
+	due := time.Since(ra.lastRun) >= ra.config.Interval
+	ra.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	_, err := ra.Apply(ctx)
+	return err
+}
+
+// Apply matches every active incident without a run of a runbook against
+// that runbook and returns the new runs
+func (ra *RunbookAgent) Apply(ctx context.Context) ([]runbooks.Run, error) {
+	ra.runMu.Lock()
+	defer ra.runMu.Unlock()
+
+	ra.mu.Lock()
+	ra.State = AgentStateRunning
+	config := ra.config
+	ra.mu.Unlock()
+
+	start := time.Now()
+	runs, err := ra.apply(ctx, config)
+
+	ra.mu.Lock()
+	ra.RunCount++
+	ra.LastRun = time.Now()
+	ra.TotalTime += time.Since(start)
+	ra.lastRun = start
+	if err != nil {
+		ra.State = AgentStateError
+	} else {
+		ra.State = AgentStateIdle
+	}
+	ra.mu.Unlock()
+
+	return runs, err
+}
+
+// Execute performs a runbook's steps on an incident regardless of its mode
+// and of earlier runs, e.g. to carry out a proposal
+func (ra *RunbookAgent) Execute(ctx context.Context, runbookName, incidentID string) (runbooks.Run, error) {
+	rb, err := ra.registry.Get(ra.TenantID, runbookName)
+	if err != nil {
+		return runbooks.Run{}, err
+	}
+	inc, err := ra.incidents.Get(ra.TenantID, incidentID)
+	if err != nil {
+		return runbooks.Run{}, err
+	}
+
+	ra.runMu.Lock()
+	defer ra.runMu.Unlock()
+	return ra.run(ctx, rb, inc, runbooks.ModeExecute)
+}
+
+func (ra *RunbookAgent) apply(ctx context.Context, config RunbookConfig) ([]runbooks.Run, error) {
+	defined := ra.registry.List(ra.TenantID)
+	if len(defined) == 0 {
+		return nil, nil
+	}
+
+	// Active incidents are Incident concepts with a positive strength
+	active := make([]string, 0)
+	applied := make(map[string]bool) // runbook name + incident name
+	for _, atom := range ra.atomSpace.QueryAtoms(ra.TenantID, nil) {
+		link, ok := atom.(*atomspace.Link)
+		if !ok {
+			continue
+		}
+		outgoing := link.GetOutgoing()
+		switch {
+		case link.GetType() == atomspace.InheritanceLinkType && len(outgoing) == 2 &&
+			outgoing[1].GetName() == incidents.IncidentConcept && strings.HasPrefix(outgoing[0].GetName(), incidents.IncidentPrefix):
+			if incident, err := ra.atomSpace.GetAtom(outgoing[0].GetID(), ra.TenantID); err == nil && incident.GetTruthValue().Strength > 0 {
+				active = append(active, strings.TrimPrefix(incident.GetName(), incidents.IncidentPrefix))
+			}
+		case link.GetType() == atomspace.EvaluationLinkType && link.GetName() == RunbookRunPredicate && len(outgoing) >= 3:
+			applied[outgoing[1].GetName()+"|"+outgoing[2].GetName()] = true
+		}
+	}
+	sort.Strings(active)
+
+	runs := make([]runbooks.Run, 0)
+	for _, incidentID := range active {
+		inc, err := ra.incidents.Get(ra.TenantID, incidentID)
+		if err != nil || inc.Status == incidents.StatusResolved {
+			continue
+		}
+		alerts, _ := ra.incidents.GetAlerts(ra.TenantID, incidentID)
+		alertNames := make([]string, 0, len(alerts))
+		for _, a := range alerts {
+			alertNames = append(alertNames, a.Name)
+		}
+
+		for _, rb := range defined {
+			if err := ctx.Err(); err != nil {
+				return runs, err
+			}
+			if applied[RunbookPrefix+rb.Name+"|"+incidents.IncidentName(incidentID)] || !rb.Match.Matches(inc, alertNames) {
+				continue
+			}
+			mode := rb.Mode
+			if config.DryRun {
+				mode = runbooks.ModePropose
+			}
+			run, err := ra.run(ctx, rb, inc, mode)
+			if err != nil {
+				return runs, err
+			}
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+// run applies one runbook to an incident and records the results
+func (ra *RunbookAgent) run(ctx context.Context, rb runbooks.Runbook, inc incidents.Incident, mode runbooks.Mode) (runbooks.Run, error) {
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	if err := RecordRunbook(ra.atomSpace, ra.TenantID, rb); err != nil {
+		return runbooks.Run{}, err
+	}
+	incident, err := ensureConcept(ra.atomSpace, ra.TenantID, incidents.IncidentName(inc.ID), full)
+	if err != nil {
+		return runbooks.Run{}, err
+	}
+
+	run := runbooks.Run{
+		Runbook:    rb.Name,
+		IncidentID: inc.ID,
+		Mode:       mode,
+		Outcome:    runbooks.OutcomeSucceeded,
+		Steps:      make([]runbooks.StepResult, 0, len(rb.Steps)),
+		StartedAt:  time.Now(),
+	}
+	if mode == runbooks.ModePropose {
+		run.Outcome = runbooks.OutcomeProposed
+	}
+
+	previousFailed := false
+	for _, step := range rb.Steps {
+		start := time.Now()
+		result := runbooks.StepResult{Step: step.Name}
+
+		switch {
+		case step.When == runbooks.WhenOnSuccess && previousFailed:
+			result.Outcome, result.Reason = runbooks.OutcomeSkipped, "previous step failed"
+		case step.When == runbooks.WhenOnFailure && !previousFailed:
+			result.Outcome, result.Reason = runbooks.OutcomeSkipped, "previous step succeeded"
+		default:
+			if unmet := ra.unmetCondition(step, inc); unmet != "" {
+				result.Outcome, result.Reason = runbooks.OutcomeSkipped, "condition not met: "+unmet
+			} else if mode == runbooks.ModePropose {
+				result.Outcome = runbooks.OutcomeProposed
+			} else if err := ra.performer.Perform(ctx, step.Action, []atomspace.Atom{incident}, stepPayload(ra.TenantID, rb, step, inc)); err != nil {
+				result.Outcome, result.Reason = runbooks.OutcomeFailed, err.Error()
+			} else {
+				result.Outcome = runbooks.OutcomeSucceeded
+			}
+		}
+		result.Duration = time.Since(start)
+
+		// A step that did not run leaves the chain's state as it was
+		if result.Outcome == runbooks.OutcomeFailed {
+			previousFailed = true
+			run.Outcome = runbooks.OutcomeFailed
+		} else if result.Outcome == runbooks.OutcomeSucceeded {
+			previousFailed = false
+		}
+		run.Steps = append(run.Steps, result)
+		debugger.Decide(ctx, "runbook_step", rb.Name+"/"+step.Name, result.Reason,
+			map[string]interface{}{"incident": inc.ID, "outcome": result.Outcome, "mode": mode})
+
+		if err := ra.recordStep(rb, step, incident, result); err != nil {
+			return run, err
+		}
+		entry := incidents.TimelineEntry{Kind: "runbook_step", Message: fmt.Sprintf("%s/%s %s", rb.Name, step.Name, result.Outcome), Actor: ra.ID}
+		if result.Reason != "" {
+			entry.Message += ": " + result.Reason
+		}
+		ra.incidents.AddTimelineEntry(ra.TenantID, inc.ID, entry)
+	}
+
+	runbook, err := ensureConcept(ra.atomSpace, ra.TenantID, RunbookPrefix+rb.Name, full)
+	if err != nil {
+		return run, err
+	}
+	outcome, err := upsertConcept(ra.atomSpace, ra.TenantID, OutcomePrefix+string(run.Outcome), full)
+	if err != nil {
+		return run, err
+	}
+	if _, err := upsertRelation(ra.atomSpace, ra.TenantID, RunbookRunPredicate, []atomspace.Atom{runbook, incident, outcome}, full); err != nil {
+		return run, err
+	}
+
+	ra.mu.Lock()
+	ra.runs = append(ra.runs, run)
+	if max := ra.config.MaxRuns; max > 0 && len(ra.runs) > max {
+		ra.runs = ra.runs[len(ra.runs)-max:]
+	}
+	ra.mu.Unlock()
+	return run, nil
+}
+
+// unmetCondition returns the first condition of a step that some subject
+// of the incident does not satisfy
+func (ra *RunbookAgent) unmetCondition(step runbooks.Step, inc incidents.Incident) string {
+	for _, c := range step.Conditions {
+		pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, c.Predicate, nil), c.Predicate, ra.TenantID, atomspace.PredicateNodeType)
+		for _, subject := range inc.Subjects {
+			node := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, subject, nil), subject, ra.TenantID, atomspace.ConceptNodeType)
+			outgoing := []atomspace.Atom{pred, node}
+			link, err := ra.atomSpace.GetAtom(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, c.Predicate, outgoing), ra.TenantID)
+			if err != nil || !c.Holds(link.GetTruthValue().Strength) {
+				return fmt.Sprintf("%s on %s", c.String(), subject)
+			}
+		}
+	}
+	return ""
+}
+
+func (ra *RunbookAgent) recordStep(rb runbooks.Runbook, step runbooks.Step, incident atomspace.Atom, result runbooks.StepResult) error {
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	stepNode, err := ensureConcept(ra.atomSpace, ra.TenantID, stepName(rb.Name, step.Name), full)
+	if err != nil {
+		return err
+	}
+	outcome, err := upsertConcept(ra.atomSpace, ra.TenantID, OutcomePrefix+string(result.Outcome), full)
+	if err != nil {
+		return err
+	}
+	_, err = upsertRelation(ra.atomSpace, ra.TenantID, StepResultPredicate, []atomspace.Atom{stepNode, incident, outcome}, full)
+	return err
+}
+
+// stepPayload is the body posted to webhook steps
+func stepPayload(tenantID string, rb runbooks.Runbook, step runbooks.Step, inc incidents.Incident) map[string]interface{} {
+	return map[string]interface{}{
+		"tenant_id": tenantID,
+		"runbook":   rb.Name,
+		"step":      step.Name,
+		"incident": map[string]interface{}{
+			"id":       inc.ID,
+			"title":    inc.Title,
+			"severity": inc.Severity,
+			"subjects": inc.Subjects,
+		},
+		"timestamp": time.Now(),
+	}
+}
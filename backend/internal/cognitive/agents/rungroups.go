@@ -0,0 +1,210 @@
+package agents
+
+import (
+	"fmt"
+)
+
+// RunGroup is a set of agents that runs after the groups it depends on
+// within each scheduling cycle
+type RunGroup struct {
+	Name   string   `json:"name"`
+	Agents []string `json:"agents"` // Agent IDs, run in priority order
+	After  []string `json:"after,omitempty"`
+
+	// RequireSuccess skips the group for the cycle when any agent of a
+	// group it depends on failed or was skipped
+	RequireSuccess bool `json:"require_success,omitempty"`
+}
+
+// RunPlan orders a tenant's agents within a scheduling cycle. Agents not in
+// any group run first, by priority.
+type RunPlan struct {
+	Groups []RunGroup `json:"groups"`
+}
+
+// compiledPlan is a validated run plan in execution order
+type compiledPlan struct {
+	groups  []RunGroup     // topologically sorted
+	rank    map[string]int // group name -> position in groups
+	agentOf map[string]string
+}
+
+// compile validates the plan and sorts its groups topologically, keeping
+// declaration order among independent groups
+func (p RunPlan) compile() (*compiledPlan, error) {
+	byName := make(map[string]RunGroup, len(p.Groups))
+	agentOf := make(map[string]string)
+	for _, g := range p.Groups {
+		if g.Name == "" {
+			return nil, fmt.Errorf("run group name is required")
+		}
+		if _, exists := byName[g.Name]; exists {
+			return nil, fmt.Errorf("duplicate run group %s", g.Name)
+		}
+		byName[g.Name] = g
+		for _, agentID := range g.Agents {
+			if other, exists := agentOf[agentID]; exists {
+				return nil, fmt.Errorf("agent %s is in run groups %s and %s", agentID, other, g.Name)
+			}
+			agentOf[agentID] = g.Name
+		}
+	}
+
+	indegree := make(map[string]int, len(p.Groups))
+	dependents := make(map[string][]string)
+	for _, g := range p.Groups {
+		for _, dep := range g.After {
+			if _, exists := byName[dep]; !exists {
+				return nil, fmt.Errorf("run group %s depends on unknown group %s", g.Name, dep)
+			}
+			indegree[g.Name]++
+			dependents[dep] = append(dependents[dep], g.Name)
+		}
+	}
+
+	sorted := make([]RunGroup, 0, len(p.Groups))
+	done := make(map[string]bool, len(p.Groups))
+	for len(sorted) < len(p.Groups) {
+		progressed := false
+		for _, g := range p.Groups {
+			if done[g.Name] || indegree[g.Name] > 0 {
+				continue
+			}
+			done[g.Name] = true
+			sorted = append(sorted, g)
+			for _, dependent := range dependents[g.Name] {
+				indegree[dependent]--
+			}
+			progressed = true
+			break
+		}
+		if !progressed {
+			return nil, fmt.Errorf("run groups contain a dependency cycle")
+		}
+	}
+
+	rank := make(map[string]int, len(sorted))
+	for i, g := range sorted {
+		rank[g.Name] = i
+	}
+
+	return &compiledPlan{
+		groups:  sorted,
+		rank:    rank,
+		agentOf: agentOf,
+	}, nil
+}
+
+// SetRunPlan sets the run groups of a tenant
+func (as *AgentScheduler) SetRunPlan(tenantID string, plan RunPlan) error {
+	compiled, err := plan.compile()
+	if err != nil {
+		return err
+	}
+
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.runPlans[tenantID] = compiled
+	return nil
+}
+
+// RemoveRunPlan restores plain priority ordering for a tenant
+func (as *AgentScheduler) RemoveRunPlan(tenantID string) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	delete(as.runPlans, tenantID)
+}
+
+// GetRunPlan returns a tenant's run groups in execution order
+func (as *AgentScheduler) GetRunPlan(tenantID string) (RunPlan, bool) {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	compiled, exists := as.runPlans[tenantID]
+	if !exists {
+		return RunPlan{}, false
+	}
+	return RunPlan{Groups: append([]RunGroup(nil), compiled.groups...)}, true
+}
+
+// scheduledAgent is one entry of a scheduling cycle
+type scheduledAgent struct {
+	agent Agent
+	group string // run group, empty when ungrouped
+}
+
+// cycleOrderLocked returns the agents of one cycle. Cross-tenant
+// interleaving follows priority; the slots held by a tenant with a run plan
+// are refilled with its agents in group order.
+func (as *AgentScheduler) cycleOrderLocked() []scheduledAgent {
+	order := make([]scheduledAgent, len(as.priority))
+	slots := make(map[string][]int)
+	for i, agent := range as.priority {
+		order[i] = scheduledAgent{agent: agent}
+		slots[agent.GetTenantID()] = append(slots[agent.GetTenantID()], i)
+	}
+
+	for tenantID, plan := range as.runPlans {
+		positions := slots[tenantID]
+		if len(positions) == 0 {
+			continue
+		}
+
+		// Ungrouped agents first, then groups in topological order; the
+		// priority order is kept within each bucket
+		buckets := make([][]Agent, len(plan.groups)+1)
+		for _, pos := range positions {
+			agent := order[pos].agent
+			bucket := 0
+			if group, ok := plan.agentOf[agent.GetID()]; ok {
+				bucket = plan.rank[group] + 1
+			}
+			buckets[bucket] = append(buckets[bucket], agent)
+		}
+
+		i := 0
+		for b, bucket := range buckets {
+			group := ""
+			if b > 0 {
+				group = plan.groups[b-1].Name
+			}
+			for _, agent := range bucket {
+				order[positions[i]] = scheduledAgent{agent: agent, group: group}
+				i++
+			}
+		}
+	}
+
+	return order
+}
+
+// groupBlocked reports whether a RequireSuccess group must be skipped
+// because one of its dependencies failed in this cycle
+func (as *AgentScheduler) groupBlocked(tenantID, group string, failed map[string]bool) bool {
+	if group == "" {
+		return false
+	}
+
+	as.mu.RLock()
+	plan, exists := as.runPlans[tenantID]
+	as.mu.RUnlock()
+	if !exists {
+		return false
+	}
+
+	// The plan may have been replaced since the cycle was ordered
+	rank, exists := plan.rank[group]
+	if !exists {
+		return false
+	}
+	g := plan.groups[rank]
+	if !g.RequireSuccess {
+		return false
+	}
+	for _, dep := range g.After {
+		if failed[tenantID+"/"+dep] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package agents
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/forecast"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/slo"
+)
+
+// Names written by the SLO agent
+const (
+	SLOPrefix = "slo:"
+
+	ErrorBudgetPredicate = "error_budget_remaining"
+	BurnRatePredicate    = "burn_rate"
+	objectiveOfPredicate = "objective_of"
+)
+
+// SLOConfig controls how SLO status is written to the AtomSpace
+type SLOConfig struct {
+	FastBurnRate float64       `json:"fast_burn_rate"` // Burn rate mapped to a strength of 1
+	Interval     time.Duration `json:"interval_ns"`    // Minimum time between scheduled runs
+}
+
+// DefaultSLOConfig returns the default SLO agent configuration. A burn rate
+// of 14.4 spends a 30 day budget in about two days.
+func DefaultSLOConfig() SLOConfig {
+	return SLOConfig{
+		FastBurnRate: 14.4,
+		Interval:     time.Minute,
+	}
+}
+
+// SLOAgent computes error budget consumption of a tenant's SLOs from their
+// SLI time series and records it as
+//
+//	error_budget_remaining(slo:NAME)   strength = share of budget left
+//	error_budget_remaining(SERVICE)    strength = lowest share of its SLOs
+//	burn_rate(slo:NAME)                strength = burn rate / FastBurnRate
+//
+// so rules can gate risky actions on a service's remaining budget
+type SLOAgent struct {
+	BaseAgent
+	atomSpace atomspace.AtomSpaceInterface
+	registry  *slo.Registry
+	store     *forecast.Store
+	config    SLOConfig
+	statuses  []slo.Status
+	lastRun   time.Time
+	runMu     sync.Mutex
+}
+
+// NewSLOAgent creates a new SLO agent
+func NewSLOAgent(id, name, tenantID string, atomSpace atomspace.AtomSpaceInterface, registry *slo.Registry, store *forecast.Store, config SLOConfig) *SLOAgent {
+	return &SLOAgent{
+		BaseAgent: BaseAgent{
+			ID:       id,
+			Name:     name,
+			TenantID: tenantID,
+			Priority: 6,
+			State:    AgentStateIdle,
+		},
+		atomSpace: atomSpace,
+		registry:  registry,
+		store:     store,
+		config:    config,
+	}
+}
+
+// SetConfig replaces the SLO agent configuration
+func (sa *SLOAgent) SetConfig(config SLOConfig) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+	sa.config = config
+}
+
+// GetConfig returns the SLO agent configuration
+func (sa *SLOAgent) GetConfig() SLOConfig {
+	sa.mu.RLock()
+	defer sa.mu.RUnlock()
+	return sa.config
+}
+
+// GetStatuses returns the SLO statuses of the latest run
+func (sa *SLOAgent) GetStatuses() []slo.Status {
+	sa.mu.RLock()
+	defer sa.mu.RUnlock()
+	return append([]slo.Status(nil), sa.statuses...)
+}
+
+// Run evaluates SLOs once the configured interval has elapsed
+func (sa *SLOAgent) Run(ctx context.Context) error {
+	sa.mu.RLock()
+	due := time.Since(sa.lastRun) >= sa.config.Interval
+	sa.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	_, err := sa.Evaluate(ctx)
+	return err
+}
+
+// Evaluate computes every SLO of the tenant now
+func (sa *SLOAgent) Evaluate(ctx context.Context) ([]slo.Status, error) {
+	sa.runMu.Lock()
+	defer sa.runMu.Unlock()
+
+	sa.mu.Lock()
+	sa.State = AgentStateRunning
+	config := sa.config
+	sa.mu.Unlock()
+
+	start := time.Now()
+	statuses, err := sa.evaluate(ctx, config)
+
+	sa.mu.Lock()
+	sa.RunCount++
+	sa.LastRun = time.Now()
+	sa.TotalTime += time.Since(start)
+	sa.lastRun = start
+	if err != nil {
+		sa.State = AgentStateError
+	} else {
+		sa.State = AgentStateIdle
+		sa.statuses = statuses
+	}
+	sa.mu.Unlock()
+
+	return statuses, err
+}
+
+func (sa *SLOAgent) evaluate(ctx context.Context, config SLOConfig) ([]slo.Status, error) {
+	if config.FastBurnRate <= 0 {
+		config.FastBurnRate = DefaultSLOConfig().FastBurnRate
+	}
+
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	now := time.Now()
+	statuses := make([]slo.Status, 0)
+	services := make(map[string]atomspace.TruthValue) // service -> lowest budget
+
+	for _, objective := range sa.registry.List(sa.TenantID) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		status := slo.Evaluate(objective, sa.store.Samples(sa.TenantID, objective.Series), now)
+		statuses = append(statuses, status)
+
+		node, err := upsertConcept(sa.atomSpace, sa.TenantID, SLOPrefix+objective.Name, full)
+		if err != nil {
+			return nil, err
+		}
+		category, err := upsertConcept(sa.atomSpace, sa.TenantID, "SLO", full)
+		if err != nil {
+			return nil, err
+		}
+		if err := upsertInheritance(sa.atomSpace, sa.TenantID, node, category, full); err != nil {
+			return nil, err
+		}
+		service, err := ensureConcept(sa.atomSpace, sa.TenantID, objective.Service, full)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := upsertRelation(sa.atomSpace, sa.TenantID, objectiveOfPredicate, []atomspace.Atom{node, service}, full); err != nil {
+			return nil, err
+		}
+
+		// Few samples make for an uncertain budget
+		confidence := float64(status.Samples) / float64(status.Samples+10)
+		budget := atomspace.TruthValue{Strength: status.BudgetRemaining, Confidence: confidence}
+		if _, err := upsertRelation(sa.atomSpace, sa.TenantID, ErrorBudgetPredicate, []atomspace.Atom{node}, budget); err != nil {
+			return nil, err
+		}
+		burn := atomspace.TruthValue{Strength: math.Min(1, status.BurnRate/config.FastBurnRate), Confidence: confidence}
+		if _, err := upsertRelation(sa.atomSpace, sa.TenantID, BurnRatePredicate, []atomspace.Atom{node}, burn); err != nil {
+			return nil, err
+		}
+
+		if lowest, seen := services[objective.Service]; !seen || budget.Strength < lowest.Strength {
+			services[objective.Service] = budget
+		}
+	}
+
+	for name, budget := range services {
+		service, err := ensureConcept(sa.atomSpace, sa.TenantID, name, full)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := upsertRelation(sa.atomSpace, sa.TenantID, ErrorBudgetPredicate, []atomspace.Atom{service}, budget); err != nil {
+			return nil, err
+		}
+	}
+	return statuses, nil
+}
@@ -0,0 +1,321 @@
+package agents
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HealthState describes how an agent is doing under supervision
+type HealthState string
+
+const (
+	HealthHealthy     HealthState = "healthy"
+	HealthFailing     HealthState = "failing"     // Recent failures, still scheduled
+	HealthBackoff     HealthState = "backoff"     // Restarted, waiting before the next run
+	HealthQuarantined HealthState = "quarantined" // Flapping, not scheduled
+)
+
+// SupervisorPolicy controls how the scheduler restarts failing agents
+type SupervisorPolicy struct {
+	MaxConsecutiveFailures int           // Failures before an agent is restarted
+	RestartBackoff         time.Duration // Delay after the first restart, doubled per restart in the flap window
+	MaxRestartBackoff      time.Duration
+	FlapWindow             time.Duration // Window in which restarts are counted
+	FlapThreshold          int           // Restarts within FlapWindow that quarantine an agent
+	QuarantineDuration     time.Duration // Zero keeps the agent quarantined until released
+	DaemonStableAfter      time.Duration // Uptime after which a daemon's earlier failures are cleared
+	DaemonDrainTimeout     time.Duration // Time a stopping daemon may drain before its context is cancelled
+}
+
+// DefaultSupervisorPolicy returns the default supervision policy
+func DefaultSupervisorPolicy() SupervisorPolicy {
+	return SupervisorPolicy{
+		MaxConsecutiveFailures: 3,
+		RestartBackoff:         time.Second,
+		MaxRestartBackoff:      time.Minute,
+		FlapWindow:             10 * time.Minute,
+		FlapThreshold:          5,
+		QuarantineDuration:     10 * time.Minute,
+		DaemonStableAfter:      30 * time.Second,
+		DaemonDrainTimeout:     10 * time.Second,
+	}
+}
+
+// AgentHealth is the supervision record of an agent
+type AgentHealth struct {
+	AgentID             string      `json:"agent_id"`
+	TenantID            string      `json:"tenant_id"`
+	State               HealthState `json:"state"`
+	ConsecutiveFailures int         `json:"consecutive_failures"`
+	TotalFailures       int64       `json:"total_failures"`
+	Restarts            int64       `json:"restarts"`
+	LastError           string      `json:"last_error,omitempty"`
+	LastFailure         time.Time   `json:"last_failure,omitempty"`
+	NextRunAt           time.Time   `json:"next_run_at,omitempty"`
+	QuarantinedUntil    time.Time   `json:"quarantined_until,omitempty"`
+
+	restartTimes []time.Time // restarts within the flap window
+}
+
+// restartable is implemented by agents whose error state can be cleared
+type restartable interface {
+	Restart()
+}
+
+// Restart clears the agent's error state so it can be scheduled again
+func (a *BaseAgent) Restart() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.State == AgentStateError {
+		a.State = AgentStateIdle
+	}
+}
+
+// supervisor tracks agent failures and decides when agents may run
+type supervisor struct {
+	policy  SupervisorPolicy
+	health  map[string]*AgentHealth // agentID -> health
+	metrics Metrics
+	mu      sync.Mutex
+}
+
+func newSupervisor(policy SupervisorPolicy) *supervisor {
+	return &supervisor{
+		policy:  policy,
+		health:  make(map[string]*AgentHealth),
+		metrics: noMetrics{},
+	}
+}
+
+func (s *supervisor) record(agent Agent) *AgentHealth {
+	h, exists := s.health[agent.GetID()]
+	if !exists {
+		h = &AgentHealth{
+			AgentID:  agent.GetID(),
+			TenantID: agent.GetTenantID(),
+			State:    HealthHealthy,
+		}
+		s.health[agent.GetID()] = h
+	}
+	return h
+}
+
+// allow reports whether an agent may be scheduled now
+func (s *supervisor) allow(agent Agent, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := s.record(agent)
+	switch h.State {
+	case HealthQuarantined:
+		if h.QuarantinedUntil.IsZero() || now.Before(h.QuarantinedUntil) {
+			return false
+		}
+		h.State = HealthFailing
+		h.QuarantinedUntil = time.Time{}
+		s.restart(agent, h)
+	case HealthBackoff:
+		if now.Before(h.NextRunAt) {
+			return false
+		}
+	}
+	return true
+}
+
+// success records a successful run
+func (s *supervisor) success(agent Agent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := s.record(agent)
+	h.ConsecutiveFailures = 0
+	h.NextRunAt = time.Time{}
+	h.State = HealthHealthy
+	s.export(h)
+}
+
+// failure records a failed run, restarting or quarantining the agent as the
+// policy dictates
+func (s *supervisor) failure(agent Agent, err error, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := s.record(agent)
+	h.ConsecutiveFailures++
+	h.TotalFailures++
+	h.LastError = err.Error()
+	h.LastFailure = now
+	h.State = HealthFailing
+	s.metrics.AgentFailed(h.TenantID, h.AgentID)
+
+	if h.ConsecutiveFailures >= s.policy.MaxConsecutiveFailures {
+		h.ConsecutiveFailures = 0
+
+		// Only restarts inside the flap window count towards quarantine
+		recent := h.restartTimes[:0]
+		for _, t := range h.restartTimes {
+			if now.Sub(t) < s.policy.FlapWindow {
+				recent = append(recent, t)
+			}
+		}
+		h.restartTimes = append(recent, now)
+
+		if len(h.restartTimes) >= s.policy.FlapThreshold {
+			h.State = HealthQuarantined
+			h.restartTimes = nil
+			if s.policy.QuarantineDuration > 0 {
+				h.QuarantinedUntil = now.Add(s.policy.QuarantineDuration)
+			}
+		} else {
+			backoff := s.policy.RestartBackoff << (len(h.restartTimes) - 1)
+			if s.policy.MaxRestartBackoff > 0 && (backoff > s.policy.MaxRestartBackoff || backoff <= 0) {
+				backoff = s.policy.MaxRestartBackoff
+			}
+			h.State = HealthBackoff
+			h.NextRunAt = now.Add(backoff)
+			s.restart(agent, h)
+		}
+	}
+	s.export(h)
+}
+
+// restart clears the agent's error state and counts the restart
+func (s *supervisor) restart(agent Agent, h *AgentHealth) {
+	h.Restarts++
+	s.metrics.AgentRestarted(h.TenantID, h.AgentID)
+	if r, ok := agent.(restartable); ok {
+		r.Restart()
+	}
+}
+
+// release lifts an agent's quarantine or backoff
+func (s *supervisor) release(agent Agent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := s.record(agent)
+	wasQuarantined := h.State == HealthQuarantined
+	h.State = HealthHealthy
+	h.ConsecutiveFailures = 0
+	h.NextRunAt = time.Time{}
+	h.QuarantinedUntil = time.Time{}
+	h.restartTimes = nil
+	if wasQuarantined {
+		s.restart(agent, h)
+	}
+	s.export(h)
+}
+
+func (s *supervisor) forget(agentID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if h, exists := s.health[agentID]; exists {
+		s.metrics.AgentRemoved(h.TenantID, h.AgentID)
+		delete(s.health, agentID)
+	}
+}
+
+func (s *supervisor) export(h *AgentHealth) {
+	s.metrics.AgentHealth(h.TenantID, h.AgentID, h.State)
+}
+
+func (s *supervisor) get(agentID string) (AgentHealth, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h, exists := s.health[agentID]
+	if !exists {
+		return AgentHealth{}, false
+	}
+	return h.snapshot(), true
+}
+
+func (s *supervisor) all() []AgentHealth {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]AgentHealth, 0, len(s.health))
+	for _, h := range s.health {
+		result = append(result, h.snapshot())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].AgentID < result[j].AgentID })
+	return result
+}
+
+func (h *AgentHealth) snapshot() AgentHealth {
+	snapshot := *h
+	snapshot.restartTimes = nil
+	return snapshot
+}
+
+// SetSupervisorPolicy replaces the supervision policy
+func (as *AgentScheduler) SetSupervisorPolicy(policy SupervisorPolicy) {
+	as.supervisor.mu.Lock()
+	defer as.supervisor.mu.Unlock()
+	as.supervisor.policy = policy
+}
+
+// SetMetrics sets where supervision metrics are reported
+func (as *AgentScheduler) SetMetrics(m Metrics) {
+	as.supervisor.mu.Lock()
+	defer as.supervisor.mu.Unlock()
+	as.supervisor.metrics = m
+}
+
+// GetAgentHealth returns the supervision record of an agent
+func (as *AgentScheduler) GetAgentHealth(agentID string) (AgentHealth, bool) {
+	return as.supervisor.get(agentID)
+}
+
+// GetHealth returns the supervision records of all agents, optionally
+// restricted to a tenant
+func (as *AgentScheduler) GetHealth(tenantID string) []AgentHealth {
+	all := as.supervisor.all()
+	if tenantID == "" {
+		return all
+	}
+
+	result := make([]AgentHealth, 0, len(all))
+	for _, h := range all {
+		if h.TenantID == tenantID {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
+// ReleaseAgent lifts the quarantine or restart backoff of an agent
+func (as *AgentScheduler) ReleaseAgent(agentID string) error {
+	agent, exists := as.GetAgent(agentID)
+	if !exists {
+		return fmt.Errorf("agent %s not found", agentID)
+	}
+	as.supervisor.release(agent)
+	return nil
+}
+
+// healthSummary counts agents per health state
+func (as *AgentScheduler) healthSummary() map[HealthState]int {
+	summary := map[HealthState]int{
+		HealthHealthy:     0,
+		HealthFailing:     0,
+		HealthBackoff:     0,
+		HealthQuarantined: 0,
+	}
+	for _, h := range as.supervisor.all() {
+		summary[h.State]++
+	}
+	return summary
+}
+
+// totalFailures counts the failed runs of supervised agents
+func (as *AgentScheduler) totalFailures() int64 {
+	var total int64
+	for _, h := range as.supervisor.all() {
+		total += h.TotalFailures
+	}
+	return total
+}
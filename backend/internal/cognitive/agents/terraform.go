@@ -0,0 +1,359 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/drift"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sandbox"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/secrets"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/terraform"
+)
+
+// Names written by the Terraform agent
+const (
+	TerraformPrefix      = "tf:"
+	TerraformStatePrefix = "tfstate:"
+
+	TerraformResourceConcept = "TerraformResource"
+	DeclaresPredicate        = drift.DeclaresPredicate
+	declaredInPredicate      = "declared_in"
+	dependsOnPredicate       = "depends_on"
+)
+
+// TerraformConfig lists the states the Terraform agent mirrors
+type TerraformConfig struct {
+	Sources    []terraform.Source `json:"sources"`
+	Attributes []string           `json:"attributes"`  // Attributes recorded as declares links
+	Interval   time.Duration      `json:"interval_ns"` // Minimum time between scheduled checks for changes
+}
+
+// DefaultTerraformConfig returns the default Terraform agent configuration
+func DefaultTerraformConfig() TerraformConfig {
+	return TerraformConfig{
+		Attributes: []string{"id", "arn", "name", "instance_type", "ami", "availability_zone", "region", "machine_type", "zone", "location", "size"},
+		Interval:   time.Minute,
+	}
+}
+
+// TerraformSourceStatus describes the latest sync of one state
+type TerraformSourceStatus struct {
+	Source    string               `json:"source"`
+	Revision  string               `json:"revision,omitempty"` // lineage/serial of the synced state
+	Resources []terraform.Resource `json:"resources"`
+	CheckedAt time.Time            `json:"checked_at"`
+	ChangedAt time.Time            `json:"changed_at,omitempty"`
+	LastError string               `json:"last_error,omitempty"`
+	addresses map[string]bool
+}
+
+// TerraformAgent mirrors Terraform states into the AtomSpace. Each managed
+// resource instance becomes
+//
+//	tf:ADDRESS inherits TerraformResource and its resource type
+//	declared_in(tf:ADDRESS, tfstate:SOURCE)
+//	depends_on(tf:ADDRESS, tf:DEPENDENCY)
+//	declares(tf:ADDRESS, attr:NAME=VALUE)   for the configured attributes
+//
+// States are re-read every interval but only written when their
+// lineage/serial changes. Resources that leave a state keep their atoms
+// with a strength of 0.
+type TerraformAgent struct {
+	BaseAgent
+	atomSpace atomspace.AtomSpaceInterface
+	config    TerraformConfig
+	client    *http.Client
+	secrets   secrets.Resolver
+	sandbox   *sandbox.Tenant                   // Answers the sources while the tenant is in sandbox mode
+	statuses  map[string]*TerraformSourceStatus // source name -> status
+	lastRun   time.Time
+	runMu     sync.Mutex
+}
+
+// NewTerraformAgent creates a new Terraform agent
+func NewTerraformAgent(id, name, tenantID string, atomSpace atomspace.AtomSpaceInterface, config TerraformConfig) *TerraformAgent {
+	return &TerraformAgent{
+		BaseAgent: BaseAgent{
+			ID:       id,
+			Name:     name,
+			TenantID: tenantID,
+			Priority: 5,
+			State:    AgentStateIdle,
+		},
+		atomSpace: atomSpace,
+		config:    config,
+		client:    &http.Client{Timeout: 30 * time.Second},
+		secrets:   secrets.None,
+		statuses:  make(map[string]*TerraformSourceStatus),
+	}
+}
+
+// SetSecrets gives the agent the resolver of the tenant's secret
+// references, which backend tokens may be
+func (ta *TerraformAgent) SetSecrets(resolver secrets.Resolver) {
+	ta.mu.Lock()
+	defer ta.mu.Unlock()
+	ta.secrets = resolver
+}
+
+// SetSandbox gives the agent the tenant's sandbox, whose canned states
+// stand in for the sources while it is enabled
+func (ta *TerraformAgent) SetSandbox(sb *sandbox.Tenant) {
+	ta.mu.Lock()
+	defer ta.mu.Unlock()
+	ta.sandbox = sb
+}
+
+// SetConfig replaces the Terraform agent configuration
+func (ta *TerraformAgent) SetConfig(config TerraformConfig) {
+	ta.mu.Lock()
+	defer ta.mu.Unlock()
+	ta.config = config
+}
+
+// GetConfig returns the Terraform agent configuration
+func (ta *TerraformAgent) GetConfig() TerraformConfig {
+	ta.mu.RLock()
+	defer ta.mu.RUnlock()
+	config := ta.config
+	config.Sources = append([]terraform.Source(nil), ta.config.Sources...)
+	return config
+}
+
+// GetStatuses returns the sync status of each source sorted by name
+func (ta *TerraformAgent) GetStatuses() []TerraformSourceStatus {
+	ta.mu.RLock()
+	defer ta.mu.RUnlock()
+
+	result := make([]TerraformSourceStatus, 0, len(ta.statuses))
+	for _, status := range ta.statuses {
+		result = append(result, *status)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Source < result[j].Source })
+	return result
+}
+
+// Run checks the states for changes once the configured interval has
+// elapsed
+func (ta *TerraformAgent) Run(ctx context.Context) error {
+	ta.mu.RLock()
+	due := time.Since(ta.lastRun) >= ta.config.Interval
+	ta.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	_, err := ta.Sync(ctx, false)
+	return err
+}
+
+// Sync reads every state and writes those that changed since the last
+// sync, or all of them with force. It returns the statuses of all sources.
+func (ta *TerraformAgent) Sync(ctx context.Context, force bool) ([]TerraformSourceStatus, error) {
+	ta.runMu.Lock()
+	defer ta.runMu.Unlock()
+
+	ta.mu.Lock()
+	ta.State = AgentStateRunning
+	config := ta.config
+	ta.mu.Unlock()
+
+	start := time.Now()
+	err := ta.sync(ctx, config, force)
+
+	ta.mu.Lock()
+	ta.RunCount++
+	ta.LastRun = time.Now()
+	ta.TotalTime += time.Since(start)
+	ta.lastRun = start
+	if err != nil {
+		ta.State = AgentStateError
+	} else {
+		ta.State = AgentStateIdle
+	}
+	ta.mu.Unlock()
+
+	return ta.GetStatuses(), err
+}
+
+func (ta *TerraformAgent) sync(ctx context.Context, config TerraformConfig, force bool) error {
+	configured := make(map[string]bool, len(config.Sources))
+	var errs []error
+	for _, source := range config.Sources {
+		configured[source.Name] = true
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		ta.mu.Lock()
+		status, exists := ta.statuses[source.Name]
+		if !exists {
+			status = &TerraformSourceStatus{Source: source.Name, Resources: []terraform.Resource{}}
+			ta.statuses[source.Name] = status
+		}
+		previous := status.Revision
+		previousAddresses := status.addresses
+		ta.mu.Unlock()
+
+		state, err := ta.load(ctx, source)
+		if err == nil && (force || state.Revision() != previous) {
+			var addresses map[string]bool
+			addresses, err = ta.writeState(source.Name, state, previousAddresses, config.Attributes)
+			if err == nil {
+				ta.mu.Lock()
+				status.Revision = state.Revision()
+				status.Resources = state.Resources
+				status.addresses = addresses
+				status.ChangedAt = time.Now()
+				ta.mu.Unlock()
+			}
+		}
+
+		ta.mu.Lock()
+		status.CheckedAt = time.Now()
+		status.LastError = ""
+		if err != nil {
+			status.LastError = ta.secrets.Scrub(err.Error())
+			errs = append(errs, err)
+		}
+		ta.mu.Unlock()
+	}
+
+	// Statuses of removed sources are dropped; their atoms are kept
+	ta.mu.Lock()
+	for name := range ta.statuses {
+		if !configured[name] {
+			delete(ta.statuses, name)
+		}
+	}
+	ta.mu.Unlock()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d terraform source(s) failed, first: %w", len(errs), errs[0])
+	}
+	return nil
+}
+
+// load reads the state of a source with its token resolved
+func (ta *TerraformAgent) load(ctx context.Context, source terraform.Source) (*terraform.State, error) {
+	ta.mu.RLock()
+	resolver := ta.secrets
+	sb := ta.sandbox
+	ta.mu.RUnlock()
+	if sb.Enabled() {
+		return sb.TerraformState(source)
+	}
+
+	token, err := resolver.Resolve(ctx, source.Token)
+	if err != nil {
+		return nil, err
+	}
+	source.Token = token
+	return source.Load(ctx, ta.client)
+}
+
+// writeState records the managed resources of a state and retires those
+// that were in the previous revision but are gone now. It returns the
+// addresses written.
+func (ta *TerraformAgent) writeState(sourceName string, state *terraform.State, previous map[string]bool, attributes []string) (map[string]bool, error) {
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	declared := atomspace.TruthValue{Strength: 1.0, Confidence: 0.95}
+
+	source, err := upsertConcept(ta.atomSpace, ta.TenantID, TerraformStatePrefix+sourceName, full)
+	if err != nil {
+		return nil, err
+	}
+	category, err := upsertConcept(ta.atomSpace, ta.TenantID, "TerraformState", full)
+	if err != nil {
+		return nil, err
+	}
+	if err := upsertInheritance(ta.atomSpace, ta.TenantID, source, category, full); err != nil {
+		return nil, err
+	}
+	resourceCategory, err := upsertConcept(ta.atomSpace, ta.TenantID, TerraformResourceConcept, full)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dependencies name resources without their instance keys
+	byBase := make(map[string][]string)
+	for _, r := range state.Resources {
+		if r.Mode == "managed" {
+			byBase[terraform.BaseAddress(r.Address)] = append(byBase[terraform.BaseAddress(r.Address)], r.Address)
+		}
+	}
+
+	written := make(map[string]bool)
+	nodes := make(map[string]atomspace.Atom)
+	for _, r := range state.Resources {
+		if r.Mode != "managed" {
+			continue
+		}
+		node, err := upsertConcept(ta.atomSpace, ta.TenantID, TerraformPrefix+r.Address, declared)
+		if err != nil {
+			return nil, err
+		}
+		nodes[r.Address] = node
+		written[r.Address] = true
+
+		typeConcept, err := ensureConcept(ta.atomSpace, ta.TenantID, r.Type, full)
+		if err != nil {
+			return nil, err
+		}
+		for _, parent := range []atomspace.Atom{resourceCategory, typeConcept} {
+			if err := upsertInheritance(ta.atomSpace, ta.TenantID, node, parent, full); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := upsertRelation(ta.atomSpace, ta.TenantID, declaredInPredicate, []atomspace.Atom{node, source}, declared); err != nil {
+			return nil, err
+		}
+		for _, name := range attributes {
+			value, ok := r.Attributes[name]
+			if !ok || value == "" {
+				continue
+			}
+			attr, err := upsertConcept(ta.atomSpace, ta.TenantID, drift.AttributeName(name, value), full)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := upsertRelation(ta.atomSpace, ta.TenantID, DeclaresPredicate, []atomspace.Atom{node, attr}, declared); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, r := range state.Resources {
+		node, ok := nodes[r.Address]
+		if !ok {
+			continue
+		}
+		for _, dependency := range r.Dependencies {
+			for _, address := range byBase[dependency] {
+				if _, err := upsertRelation(ta.atomSpace, ta.TenantID, dependsOnPredicate, []atomspace.Atom{node, nodes[address]}, declared); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	retired := atomspace.TruthValue{Strength: 0, Confidence: 0.95}
+	for address := range previous {
+		if written[address] {
+			continue
+		}
+		node, err := upsertConcept(ta.atomSpace, ta.TenantID, TerraformPrefix+address, retired)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := upsertRelation(ta.atomSpace, ta.TenantID, declaredInPredicate, []atomspace.Atom{node, source}, retired); err != nil {
+			return nil, err
+		}
+	}
+	return written, nil
+}
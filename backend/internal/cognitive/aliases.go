@@ -0,0 +1,153 @@
+package cognitive
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// AddAlias records another name of a node of a tenant with an AliasLink.
+// Nodes of its type that agents, connectors and pipelines add under the
+// alias from then on resolve to it instead.
+func (ce *CognitiveEngine) AddAlias(tenantID, atomID, alias string) (atomspace.Atom, error) {
+	if alias == "" {
+		return nil, fmt.Errorf("alias is required")
+	}
+	canonical, err := ce.shardManager.GetAtom(atomID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if atomspace.IsLinkType(canonical.GetType()) {
+		return nil, fmt.Errorf("only nodes have aliases")
+	}
+	if alias == canonical.GetName() {
+		return nil, fmt.Errorf("%s is the name of atom %s", alias, atomID)
+	}
+	link := atomspace.NewAliasLink(alias, tenantID, canonical)
+	if existing, err := ce.shardManager.GetAtom(link.GetID(), tenantID); err == nil {
+		return existing, nil
+	}
+	if other, ok := ce.ResolveAlias(tenantID, canonical.GetType(), alias); ok {
+		return nil, fmt.Errorf("%s is already an alias of atom %s", alias, other.GetID())
+	}
+	if _, err := ce.shardManager.GetAtom(atomspace.GenerateAtomID(canonical.GetType(), alias, nil), tenantID); err == nil {
+		return nil, fmt.Errorf("an atom named %s exists; merge it instead", alias)
+	}
+	if err := ce.AddAtom(link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// ListAliases returns the aliases of an atom of a tenant, sorted
+func (ce *CognitiveEngine) ListAliases(tenantID, atomID string) ([]string, error) {
+	if _, err := ce.shardManager.GetAtom(atomID, tenantID); err != nil {
+		return nil, err
+	}
+	links := ce.shardManager.QueryAtoms(tenantID, func(atom atomspace.Atom) bool {
+		target, ok := atomspace.AliasTarget(atom)
+		return ok && target.GetID() == atomID
+	})
+	aliases := make([]string, 0, len(links))
+	for _, link := range links {
+		aliases = append(aliases, link.GetName())
+	}
+	sort.Strings(aliases)
+	return aliases, nil
+}
+
+// DeleteAlias removes an alias of an atom of a tenant
+func (ce *CognitiveEngine) DeleteAlias(tenantID, atomID, alias string) error {
+	canonical, err := ce.shardManager.GetAtom(atomID, tenantID)
+	if err != nil {
+		return err
+	}
+	link := atomspace.NewAliasLink(alias, tenantID, canonical)
+	if _, err := ce.shardManager.GetAtom(link.GetID(), tenantID); err != nil {
+		return fmt.Errorf("%s is not an alias of atom %s", alias, atomID)
+	}
+	return ce.DeleteAtom(link.GetID(), tenantID)
+}
+
+// ResolveAlias returns the atom of a type an alias of a tenant stands for
+func (ce *CognitiveEngine) ResolveAlias(tenantID string, atomType atomspace.AtomType, alias string) (atomspace.Atom, bool) {
+	aliasType := atomspace.AliasLinkType
+	links, _ := ce.FindAtoms(tenantID, atomspace.Query{Type: &aliasType, Name: alias})
+	for _, link := range links {
+		target, ok := atomspace.AliasTarget(link)
+		if !ok || target.GetType() != atomType {
+			continue
+		}
+		if canonical, err := ce.GetAtom(target.GetID(), tenantID); err == nil {
+			return canonical, true
+		}
+	}
+	return nil, false
+}
+
+// hasAliases reports whether a tenant has any alias
+func (ce *CognitiveEngine) hasAliases(tenantID string) bool {
+	aliasType := atomspace.AliasLinkType
+	links, _ := ce.FindAtoms(tenantID, atomspace.Query{Type: &aliasType, Limit: 1})
+	return len(links) > 0
+}
+
+// ingestAtom adds an atom written by agents, connectors or pipelines,
+// resolving aliases. A node named by an alias is not added: the atom the
+// alias stands for takes its place. A link referring to such nodes is
+// added referring to those atoms instead, or, if that link exists, updates
+// its truth value.
+func (ce *CognitiveEngine) ingestAtom(atom atomspace.Atom) error {
+	if !ce.hasAliases(atom.GetTenantID()) {
+		return ce.AddAtom(atom)
+	}
+	resolved, ok := ce.resolveAliases(atom)
+	if !ok {
+		return ce.AddAtom(atom)
+	}
+	if _, isLink := resolved.(*atomspace.Link); !isLink {
+		return nil
+	}
+	if _, err := ce.shardManager.GetAtom(resolved.GetID(), resolved.GetTenantID()); err == nil {
+		tv := resolved.GetTruthValue()
+		return ce.UpdateAtom(resolved.GetID(), resolved.GetTenantID(), func(existing atomspace.Atom) error {
+			existing.SetTruthValue(tv)
+			return nil
+		})
+	}
+	return ce.AddAtom(resolved)
+}
+
+// resolveAliases returns the atom an atom not stored resolves to through
+// aliases: the atom a node's alias stands for, or a link with its outgoing
+// atoms resolved
+func (ce *CognitiveEngine) resolveAliases(atom atomspace.Atom) (atomspace.Atom, bool) {
+	tenantID := atom.GetTenantID()
+	if _, err := ce.shardManager.GetAtom(atom.GetID(), tenantID); err == nil {
+		return atom, false
+	}
+	link, ok := atom.(*atomspace.Link)
+	if !ok {
+		return ce.ResolveAlias(tenantID, atom.GetType(), atom.GetName())
+	}
+	if link.GetType() == atomspace.AliasLinkType {
+		return atom, false
+	}
+
+	changed := false
+	outgoing := make([]atomspace.Atom, 0, len(link.GetOutgoing()))
+	for _, target := range link.GetOutgoing() {
+		if resolved, ok := ce.resolveAliases(target); ok {
+			target, changed = resolved, true
+		}
+		outgoing = append(outgoing, target)
+	}
+	if !changed {
+		return atom, false
+	}
+	resolved := atomspace.NewLink(atomspace.GenerateAtomID(link.GetType(), link.GetName(), outgoing), link.GetName(), tenantID, link.GetType(), outgoing)
+	resolved.SetTruthValue(link.GetTruthValue())
+	resolved.SetAttentionValue(link.GetAttentionValue())
+	return resolved, true
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/go-chi/chi/v5"
+)
+
+// Identity headers set by the authenticating proxy in front of the API
+const (
+	UserHeader  = "X-Erebus-User"
+	RolesHeader = "X-Erebus-Roles" // Comma-separated
+)
+
+// Authenticate attaches the principal named by the identity headers to
+// the request. Requests without them are anonymous and may only change
+// resources that have no ACL.
+func Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := acl.Principal{User: strings.TrimSpace(r.Header.Get(UserHeader))}
+		for _, role := range strings.Split(r.Header.Get(RolesHeader), ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				p.Roles = append(p.Roles, role)
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(acl.WithPrincipal(r.Context(), p)))
+	})
+}
+
+// RequireRole refuses requests whose principal, attached by Authenticate,
+// lacks a role
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !acl.PrincipalFrom(r.Context()).HasRole(role) {
+				http.Error(w, fmt.Sprintf("role %s required", role), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authorizeWrite refuses requests whose principal may not change the
+// resource named by a URL parameter
+func (h *CognitiveHandler) authorizeWrite(kind acl.Kind, param string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantID := chi.URLParam(r, "tenantID")
+			id := chi.URLParam(r, param)
+			if err := h.engine.AuthorizeWrite(tenantID, kind, id, acl.PrincipalFrom(r.Context())); err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GetAtomACL returns the ACL of an atom
+func (h *CognitiveHandler) GetAtomACL(w http.ResponseWriter, r *http.Request) {
+	h.getACL(w, r, acl.KindAtom, chi.URLParam(r, "atomID"))
+}
+
+// SetAtomACL protects an atom
+func (h *CognitiveHandler) SetAtomACL(w http.ResponseWriter, r *http.Request) {
+	h.setACL(w, r, acl.KindAtom, chi.URLParam(r, "atomID"))
+}
+
+// RemoveAtomACL unprotects an atom
+func (h *CognitiveHandler) RemoveAtomACL(w http.ResponseWriter, r *http.Request) {
+	h.removeACL(w, r, acl.KindAtom, chi.URLParam(r, "atomID"))
+}
+
+// GetPipelineACL returns the ACL of a pipeline
+func (h *CognitiveHandler) GetPipelineACL(w http.ResponseWriter, r *http.Request) {
+	h.getACL(w, r, acl.KindPipeline, chi.URLParam(r, "pipelineID"))
+}
+
+// SetPipelineACL protects a pipeline
+func (h *CognitiveHandler) SetPipelineACL(w http.ResponseWriter, r *http.Request) {
+	h.setACL(w, r, acl.KindPipeline, chi.URLParam(r, "pipelineID"))
+}
+
+// RemovePipelineACL unprotects a pipeline
+func (h *CognitiveHandler) RemovePipelineACL(w http.ResponseWriter, r *http.Request) {
+	h.removeACL(w, r, acl.KindPipeline, chi.URLParam(r, "pipelineID"))
+}
+
+func (h *CognitiveHandler) getACL(w http.ResponseWriter, r *http.Request, kind acl.Kind, id string) {
+	a, err := h.engine.GetACL(chi.URLParam(r, "tenantID"), kind, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
+
+func (h *CognitiveHandler) setACL(w http.ResponseWriter, r *http.Request, kind acl.Kind, id string) {
+	var req acl.ACL
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req, err := requestedACL(r, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	a, err := h.engine.SetACL(chi.URLParam(r, "tenantID"), kind, id, req, acl.PrincipalFrom(r.Context()))
+	if err != nil {
+		writeACLError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
+
+func (h *CognitiveHandler) removeACL(w http.ResponseWriter, r *http.Request, kind acl.Kind, id string) {
+	p := acl.PrincipalFrom(r.Context())
+	if err := h.engine.RemoveACL(chi.URLParam(r, "tenantID"), kind, id, p); err != nil {
+		writeACLError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "ACL removed",
+		"kind":    kind,
+		"id":      id,
+	})
+}
+
+// requestedACL validates an ACL from a request, owned by the requesting
+// user unless it names an owner
+func requestedACL(r *http.Request, a acl.ACL) (acl.ACL, error) {
+	if a.Owner == "" {
+		a.Owner = acl.PrincipalFrom(r.Context()).Entry()
+	}
+	return a, a.Validate()
+}
+
+// writeACLError writes an error, as forbidden if an ACL refused the change
+func writeACLError(w http.ResponseWriter, err error, status int) {
+	if acl.IsForbidden(err) {
+		status = http.StatusForbidden
+	}
+	http.Error(w, err.Error(), status)
+}
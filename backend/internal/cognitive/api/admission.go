@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/admission"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListAdmissionHooks returns a tenant's admission webhooks
+func (h *CognitiveHandler) ListAdmissionHooks(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	hooks := h.engine.ListAdmissionHooks(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"hooks": hooks,
+		"count": len(hooks),
+	})
+}
+
+// SetAdmissionHook creates or replaces an admission webhook
+func (h *CognitiveHandler) SetAdmissionHook(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	var hook admission.Hook
+	if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	hook.Name = name
+
+	hook, err := h.engine.SetAdmissionHook(tenantID, hook)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hook)
+}
+
+// GetAdmissionHook returns an admission webhook and its call statistics
+func (h *CognitiveHandler) GetAdmissionHook(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	hook, err := h.engine.GetAdmissionHook(tenantID, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hook)
+}
+
+// DeleteAdmissionHook removes an admission webhook
+func (h *CognitiveHandler) DeleteAdmissionHook(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	if err := h.engine.DeleteAdmissionHook(tenantID, name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Admission hook deleted successfully",
+		"name":    name,
+	})
+}
+
+// writeError writes err with status, 403 if admission or an ACL denied the
+// write, or 409 if the atom is protected
+func writeError(w http.ResponseWriter, err error, status int) {
+	switch {
+	case admission.IsDenied(err), acl.IsForbidden(err):
+		status = http.StatusForbidden
+	case errors.Is(err, atomspace.ErrProtected):
+		status = http.StatusConflict
+	}
+	http.Error(w, err.Error(), status)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/go-chi/chi/v5"
+)
+
+// MergeAtoms merges duplicate atoms into the atom, whose aliases their
+// names become
+func (h *CognitiveHandler) MergeAtoms(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Atoms []string `json:"atoms"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tenantID := chi.URLParam(r, "tenantID")
+	// Merged atoms are deleted, which their ACLs must allow
+	for _, id := range req.Atoms {
+		if err := h.engine.AuthorizeWrite(tenantID, acl.KindAtom, id, acl.PrincipalFrom(r.Context())); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+
+	result, err := h.engine.MergeAtoms(tenantID, chi.URLParam(r, "atomID"), req.Atoms)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ListAliases returns the aliases of an atom
+func (h *CognitiveHandler) ListAliases(w http.ResponseWriter, r *http.Request) {
+	aliases, err := h.engine.ListAliases(chi.URLParam(r, "tenantID"), chi.URLParam(r, "atomID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"aliases": aliases,
+		"count":   len(aliases),
+	})
+}
+
+// AddAlias gives an atom another name
+func (h *CognitiveHandler) AddAlias(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Alias string `json:"alias"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.engine.AddAlias(chi.URLParam(r, "tenantID"), chi.URLParam(r, "atomID"), req.Alias)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"atom_id": link.GetID(),
+		"alias":   req.Alias,
+	})
+}
+
+// DeleteAlias removes an alias of an atom
+func (h *CognitiveHandler) DeleteAlias(w http.ResponseWriter, r *http.Request) {
+	alias := chi.URLParam(r, "alias")
+	if err := h.engine.DeleteAlias(chi.URLParam(r, "tenantID"), chi.URLParam(r, "atomID"), alias); err != nil {
+		writeError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Alias deleted successfully",
+		"alias":   alias,
+	})
+}
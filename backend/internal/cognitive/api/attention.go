@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/attention"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetAttentionParams returns a tenant's attention params
+func (h *CognitiveHandler) GetAttentionParams(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	p, custom := h.engine.GetAttentionParams(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id": tenantID,
+		"params":    p,
+		"default":   !custom,
+	})
+}
+
+// SetAttentionParams replaces a tenant's attention params
+func (h *CognitiveHandler) SetAttentionParams(w http.ResponseWriter, r *http.Request) {
+	var p attention.Params
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.engine.SetAttentionParams(chi.URLParam(r, "tenantID"), p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// DeleteAttentionParams restores a tenant's default attention params
+func (h *CognitiveHandler) DeleteAttentionParams(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	if err := h.engine.DeleteAttentionParams(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Attention params reset to the default",
+		"tenant_id": tenantID,
+	})
+}
+
+// GetAttentionalFocus returns the atoms of highest short-term importance
+func (h *CognitiveHandler) GetAttentionalFocus(w http.ResponseWriter, r *http.Request) {
+	focus, err := h.engine.GetAttentionalFocus(chi.URLParam(r, "tenantID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	results := atomResults(focus)
+	for i, atom := range focus {
+		av := atom.GetAttentionValue()
+		results[i]["attention_value"] = map[string]int16{
+			"sti":  av.STI,
+			"lti":  av.LTI,
+			"vlti": av.VLTI,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"atoms": results,
+		"count": len(results),
+	})
+}
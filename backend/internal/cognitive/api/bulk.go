@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/go-chi/chi/v5"
+)
+
+// importBatch is the number of atoms decoded before they are imported, which
+// bounds the memory an import takes beyond the atoms themselves
+const importBatch = 10000
+
+// bulkAtom is a node of a bulk import
+type bulkAtom struct {
+	Type       int     `json:"type"`
+	Name       string  `json:"name"`
+	Strength   float64 `json:"strength"`
+	Confidence float64 `json:"confidence"`
+}
+
+// BulkCreateAtoms creates many nodes in one request, allocated from an
+// arena and stored in per-shard batches. The body, {"atoms": [...]} or
+// newline-delimited atoms (application/x-ndjson), is decoded as it streams
+// in and imported every importBatch atoms, so its size is bounded by the
+// import limit rather than memory. A batch is admitted as a whole; on an
+// error, the atoms of earlier batches stay imported. Nodes that already
+// exist are skipped and counted.
+func (h *CognitiveHandler) BulkCreateAtoms(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	imported, total := 0, 0
+	batch := make([]bulkAtom, 0, importBatch)
+	flush := func() error {
+		n, err := h.importBulk(tenantID, batch)
+		if err != nil {
+			return err
+		}
+		imported += n
+		total += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	err := decodeBulkAtoms(r, func(a bulkAtom) error {
+		batch = append(batch, a)
+		if len(batch) == importBatch {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil && len(batch) > 0 {
+		err = flush()
+	}
+	if err != nil {
+		if imported > 0 {
+			err = fmt.Errorf("%w (%d atoms were imported before)", err, imported)
+		}
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"imported": imported,
+		"skipped":  total - imported,
+	})
+}
+
+// importBulk imports a batch of nodes
+func (h *CognitiveHandler) importBulk(tenantID string, batch []bulkAtom) (int, error) {
+	arena := atomspace.NewArena(len(batch), 0, 0)
+	atoms := make([]atomspace.Atom, len(batch))
+	for i, a := range batch {
+		atomType := atomspace.AtomType(a.Type)
+		node := arena.NewNode(atomspace.GenerateAtomID(atomType, a.Name, nil), a.Name, tenantID, atomType)
+		if a.Strength > 0 || a.Confidence > 0 {
+			node.TruthVal = atomspace.TruthValue{Strength: a.Strength, Confidence: a.Confidence}
+		}
+		atoms[i] = node
+	}
+	return h.engine.ImportAtoms(atoms)
+}
+
+// decodeBulkAtoms calls fn with each atom of a bulk import body, one at a
+// time, stopping at the first error
+func decodeBulkAtoms(r *http.Request, fn func(bulkAtom) error) error {
+	dec := json.NewDecoder(r.Body)
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/x-ndjson" {
+		for {
+			var a bulkAtom
+			if err := dec.Decode(&a); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if err := fn(a); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key != "atoms" {
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := expectDelim(dec, '['); err != nil {
+			return err
+		}
+		for dec.More() {
+			var a bulkAtom
+			if err := dec.Decode(&a); err != nil {
+				return err
+			}
+			if err := fn(a); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// expectDelim reads the next JSON token, which must be the delimiter
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %q, got %v", delim, token)
+	}
+	return nil
+}
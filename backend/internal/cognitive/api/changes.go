@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/changes"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/secrets"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetChangeHook returns the tenant's webhook configuration with its secret
+// hidden unless it is a reference
+func (h *CognitiveHandler) GetChangeHook(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	hook, err := h.engine.GetChangeHook(tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	hook.Secret = secrets.Redact(hook.Secret)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hook)
+}
+
+// SetChangeHook configures the secret of the tenant's GitHub and GitLab
+// webhooks and the services their repositories change
+func (h *CognitiveHandler) SetChangeHook(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var hook changes.Hook
+	if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.engine.SetChangeHook(tenantID, hook); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	hook.Secret = secrets.Redact(hook.Secret)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hook)
+}
+
+// RemoveChangeHook stops accepting the tenant's webhooks
+func (h *CognitiveHandler) RemoveChangeHook(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.RemoveChangeHook(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Change webhooks removed successfully",
+	})
+}
+
+// IngestGitHubChanges records deployments and merged pull requests from a
+// GitHub webhook signed with the tenant's secret
+func (h *CognitiveHandler) IngestGitHubChanges(w http.ResponseWriter, r *http.Request) {
+	h.ingestChangeWebhook(w, r, changes.ProviderGitHub)
+}
+
+// IngestGitLabChanges records deployments and merged merge requests from a
+// GitLab webhook carrying the tenant's secret token
+func (h *CognitiveHandler) IngestGitLabChanges(w http.ResponseWriter, r *http.Request) {
+	h.ingestChangeWebhook(w, r, changes.ProviderGitLab)
+}
+
+func (h *CognitiveHandler) ingestChangeWebhook(w http.ResponseWriter, r *http.Request, provider changes.Provider) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	recorded, err := h.engine.IngestChangeWebhook(r.Context(), tenantID, provider, r.Header, data)
+	if errors.Is(err, changes.ErrUnauthorized) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.writeChanges(w, recorded)
+}
+
+// RecordChanges records changes reported by other tools, such as deploy
+// scripts
+func (h *CognitiveHandler) RecordChanges(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		Changes []changes.Event `json:"changes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	recorded, err := h.engine.RecordChanges(r.Context(), tenantID, req.Changes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.writeChanges(w, recorded)
+}
+
+// ListChanges returns the tenant's recent changes, most recent first.
+// ?since= is a duration, 24h by default.
+func (h *CognitiveHandler) ListChanges(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	since := 24 * time.Hour
+	if value := r.URL.Query().Get("since"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			http.Error(w, "invalid since duration: "+value, http.StatusBadRequest)
+			return
+		}
+		since = d
+	}
+
+	h.writeChanges(w, h.engine.ListChanges(tenantID, time.Now().Add(-since)))
+}
+
+func (h *CognitiveHandler) writeChanges(w http.ResponseWriter, events []changes.Event) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"changes": events,
+		"count":   len(events),
+	})
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetClusters returns the concept clusters found for a tenant
+func (h *CognitiveHandler) GetClusters(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	clusters, err := h.engine.GetClusters(tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"clusters": clusters,
+		"count":    len(clusters),
+	})
+}
+
+// ClusterConcepts clusters a tenant's concepts immediately
+func (h *CognitiveHandler) ClusterConcepts(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	clusters, err := h.engine.ClusterConcepts(r.Context(), tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"clusters": clusters,
+		"count":    len(clusters),
+	})
+}
+
+// ConfigureClustering enables concept clustering for a tenant or updates
+// its configuration
+func (h *CognitiveHandler) ConfigureClustering(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		Threshold       float64 `json:"threshold"`
+		MinClusterSize  int     `json:"min_cluster_size"`
+		MaxClusterSize  int     `json:"max_cluster_size"`
+		LinkWeight      float64 `json:"link_weight"`
+		LabelWeight     float64 `json:"label_weight"`
+		EmbeddingWeight float64 `json:"embedding_weight"`
+		IntervalSeconds int     `json:"interval_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Threshold < 0 || req.Threshold > 1 {
+		http.Error(w, "threshold must be between 0 and 1", http.StatusBadRequest)
+		return
+	}
+
+	config := agents.DefaultClusteringConfig()
+	if req.Threshold > 0 {
+		config.Threshold = req.Threshold
+	}
+	if req.MinClusterSize > 0 {
+		config.MinClusterSize = req.MinClusterSize
+	}
+	if req.MaxClusterSize > 0 {
+		config.MaxClusterSize = req.MaxClusterSize
+	}
+	if req.LinkWeight > 0 {
+		config.LinkWeight = req.LinkWeight
+	}
+	if req.LabelWeight > 0 {
+		config.LabelWeight = req.LabelWeight
+	}
+	if req.EmbeddingWeight > 0 {
+		config.EmbeddingWeight = req.EmbeddingWeight
+	}
+	if req.IntervalSeconds > 0 {
+		config.Interval = time.Duration(req.IntervalSeconds) * time.Second
+	}
+
+	agent := h.engine.EnableClustering(tenantID, config)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agent_id": agent.GetID(),
+		"config":   agent.GetConfig(),
+	})
+}
+
+// DisableClustering stops concept clustering for a tenant
+func (h *CognitiveHandler) DisableClustering(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.DisableClustering(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Clustering disabled successfully",
+		"tenant_id": tenantID,
+	})
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/cmdb"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/secrets"
+	"github.com/go-chi/chi/v5"
+)
+
+// redactCMDBConfig hides the credentials of a CMDB configuration in
+// responses
+func redactCMDBConfig(config agents.CMDBConfig) agents.CMDBConfig {
+	for i := range config.Sources {
+		config.Sources[i].Token = secrets.Redact(config.Sources[i].Token)
+		config.Sources[i].Password = secrets.Redact(config.Sources[i].Password)
+	}
+	return config
+}
+
+// GetCMDB returns the configuration and sync status of the tenant's CMDBs,
+// including the updates planned by the latest sync
+func (h *CognitiveHandler) GetCMDB(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	config, statuses, err := h.engine.GetCMDBStatus(tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"config":  redactCMDBConfig(config),
+		"sources": statuses,
+	})
+}
+
+// SetCMDBSource adds or replaces a CMDB source: a ServiceNow instance or a
+// NetBox endpoint, with its field mapping and write-back mode
+func (h *CognitiveHandler) SetCMDBSource(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	var source cmdb.Source
+	if err := json.NewDecoder(r.Body).Decode(&source); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	source.Name = name
+
+	config, err := h.engine.SetCMDBSource(tenantID, source)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(redactCMDBConfig(config))
+}
+
+// RemoveCMDBSource stops mirroring a CMDB
+func (h *CognitiveHandler) RemoveCMDBSource(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	if err := h.engine.RemoveCMDBSource(tenantID, name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "CMDB source removed successfully",
+		"name":    name,
+	})
+}
+
+// SyncCMDB reads the tenant's CMDBs immediately. With ?force=true unchanged
+// snapshots are written too, and with ?dry_run=true updates are planned
+// but not applied.
+func (h *CognitiveHandler) SyncCMDB(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	force := r.URL.Query().Get("force") == "true"
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	statuses, err := h.engine.SyncCMDB(r.Context(), tenantID, force, dryRun)
+	if statuses == nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	response := map[string]interface{}{
+		"sources": statuses,
+	}
+	status := http.StatusOK
+	if err != nil {
+		response["error"] = err.Error()
+		status = http.StatusBadGateway
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ConfigureCMDB enables the CMDB agent for a tenant or replaces its
+// configuration
+func (h *CognitiveHandler) ConfigureCMDB(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		Sources         []cmdb.Source `json:"sources"`
+		IntervalSeconds int           `json:"interval_seconds"`
+		MinStrength     *float64      `json:"min_strength"`
+		MinConfidence   *float64      `json:"min_confidence"`
+		MaxUpdates      int           `json:"max_updates"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	config := agents.DefaultCMDBConfig()
+	config.Sources = req.Sources
+	if req.IntervalSeconds > 0 {
+		config.Interval = time.Duration(req.IntervalSeconds) * time.Second
+	}
+	if req.MinStrength != nil {
+		config.MinStrength = *req.MinStrength
+	}
+	if req.MinConfidence != nil {
+		config.MinConfidence = *req.MinConfidence
+	}
+	if req.MaxUpdates > 0 {
+		config.MaxUpdates = req.MaxUpdates
+	}
+
+	agent, err := h.engine.EnableCMDB(tenantID, config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agent_id": agent.GetID(),
+		"config":   redactCMDBConfig(agent.GetConfig()),
+	})
+}
+
+// DisableCMDB stops the CMDB agent of a tenant
+func (h *CognitiveHandler) DisableCMDB(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.DisableCMDB(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "CMDB agent disabled successfully",
+		"tenant_id": tenantID,
+	})
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// minCompressSize is the smallest response body worth compressing
+const minCompressSize = 1024
+
+// compressible lists the content types Compress compresses
+var compressible = map[string]bool{
+	"application/json":       true,
+	"application/msgpack":    true,
+	"application/x-protobuf": true,
+	"text/plain":             true,
+	"text/csv":               true,
+	"text/markdown":          true,
+	"text/html":              true,
+}
+
+// Encoders are pooled, as setting one up costs far more than a small
+// response
+var (
+	gzipWriters = sync.Pool{New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	}}
+	zstdWriters = sync.Pool{New: func() interface{} {
+		w, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault), zstd.WithEncoderConcurrency(1))
+		return w
+	}}
+)
+
+// Compress compresses responses with zstd or gzip, whichever the client
+// accepts with the higher quality value, preferring zstd. Bodies under
+// minCompressSize, of types not worth compressing, or already encoded are
+// sent as they are.
+func Compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiate(r.Header.Get("Accept-Encoding"), []string{"zstd", "gzip"}, "identity")
+		if encoding == "" || encoding == "identity" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, status: http.StatusOK}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// compressWriter holds back the start of a response until it is known to
+// be large enough to compress
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string
+	status      int
+	wroteHeader bool
+	buf         []byte
+	started     bool
+	enc         io.WriteCloser
+	release     func()
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		cw.status = status
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	cw.wroteHeader = true
+	if cw.started {
+		if cw.enc != nil {
+			return cw.enc.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= minCompressSize {
+		if err := cw.start(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// start sends the header, compressing the body if it is worth it, and
+// whatever of the body was held back
+func (cw *compressWriter) start() error {
+	cw.started = true
+	header := cw.Header()
+	contentType, _, _ := strings.Cut(header.Get("Content-Type"), ";")
+	if len(cw.buf) >= minCompressSize && compressible[strings.TrimSpace(contentType)] && header.Get("Content-Encoding") == "" {
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		switch cw.encoding {
+		case "zstd":
+			enc := zstdWriters.Get().(*zstd.Encoder)
+			enc.Reset(cw.ResponseWriter)
+			cw.enc, cw.release = enc, func() { zstdWriters.Put(enc) }
+		case "gzip":
+			enc := gzipWriters.Get().(*gzip.Writer)
+			enc.Reset(cw.ResponseWriter)
+			cw.enc, cw.release = enc, func() { gzipWriters.Put(enc) }
+		}
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// Flush sends what has been written so far, compressed if the response is
+// already being compressed
+func (cw *compressWriter) Flush() {
+	if !cw.started {
+		cw.start()
+	}
+	if f, ok := cw.enc.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close ends the response, sending a body held back whole uncompressed
+func (cw *compressWriter) Close() error {
+	if !cw.started {
+		if !cw.wroteHeader {
+			return nil
+		}
+		if err := cw.start(); err != nil {
+			return err
+		}
+	}
+	if cw.enc == nil {
+		return nil
+	}
+	err := cw.enc.Close()
+	cw.release()
+	cw.enc = nil
+	return err
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// negotiate picks the offer an Accept-style header gives the highest
+// quality value. An exact match outranks a wildcard, and ties go to the
+// earlier offer. An empty header accepts the fallback, if any; it returns
+// "" if nothing offered is acceptable.
+func negotiate(header string, offers []string, fallback string) string {
+	if strings.TrimSpace(header) == "" {
+		return fallback
+	}
+
+	best, bestQ, bestExact := "", 0.0, false
+	for _, offer := range offers {
+		q, exact := quality(header, offer)
+		if q > bestQ || (q == bestQ && q > 0 && exact && !bestExact) {
+			best, bestQ, bestExact = offer, q, exact
+		}
+	}
+	return best
+}
+
+// quality returns the quality value a header gives an offer, taken from its
+// most specific matching range, and whether that range names the offer
+func quality(header, offer string) (float64, bool) {
+	q, specificity := 0.0, -1
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+
+		s := -1
+		switch {
+		case name == offer:
+			s = 2
+		case name == "*" || name == "*/*":
+			s = 0
+		case strings.HasSuffix(name, "/*") && strings.HasPrefix(offer, strings.TrimSuffix(name, "*")):
+			s = 1
+		}
+		if s <= specificity {
+			continue
+		}
+
+		specificity, q = s, 1
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if v, err := strconv.ParseFloat(value, 64); err == nil {
+					q = v
+				}
+			}
+		}
+	}
+	return q, specificity == 2
+}
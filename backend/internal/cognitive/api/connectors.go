@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/connectors"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListConnectors returns the health of the tenant's connectors and the
+// kinds that may be declared
+func (h *CognitiveHandler) ListConnectors(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"connectors": h.engine.ListConnectors(tenantID),
+		"kinds":      h.engine.ConnectorKinds(),
+	})
+}
+
+// GetConnector returns the status of one connector. Its settings are never
+// returned since they may hold credentials.
+func (h *CognitiveHandler) GetConnector(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	status, err := h.engine.GetConnector(tenantID, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// SetConnector creates or replaces a connector. It starts unless the spec
+// declares it stopped.
+func (h *CognitiveHandler) SetConnector(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	var spec connectors.Spec
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	spec.Name = name
+
+	status, err := h.engine.SetConnector(tenantID, spec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// RemoveConnector removes a connector, keeping what it wrote
+func (h *CognitiveHandler) RemoveConnector(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	if err := h.engine.RemoveConnector(tenantID, name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Connector removed successfully",
+		"name":    name,
+	})
+}
+
+// StartConnector resumes a stopped connector, syncing it at the next run
+func (h *CognitiveHandler) StartConnector(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	status, err := h.engine.StartConnector(tenantID, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// StopConnector stops syncing a connector until it is started again
+func (h *CognitiveHandler) StopConnector(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	status, err := h.engine.StopConnector(tenantID, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// SyncConnector syncs a connector immediately, whatever its state
+func (h *CognitiveHandler) SyncConnector(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	status, err := h.engine.SyncConnector(r.Context(), tenantID, name)
+	if status.Name == "" {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	response := map[string]interface{}{
+		"connector": status,
+	}
+	code := http.StatusOK
+	if err != nil {
+		response["error"] = err.Error()
+		code = http.StatusBadGateway
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(response)
+}
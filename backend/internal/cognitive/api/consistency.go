@@ -0,0 +1,43 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sharding"
+)
+
+// Consistency hints of read requests, also accepted as the consistency
+// and max_staleness query parameters, which take precedence
+const (
+	ConsistencyHeader  = "X-Erebus-Consistency"   // strong, bounded or eventual
+	MaxStalenessHeader = "X-Erebus-Max-Staleness" // Duration bounding bounded reads, such as 2s
+)
+
+// readConsistency returns the consistency a read request demands,
+// eventual if it gives no hint
+func readConsistency(r *http.Request) (sharding.Consistency, error) {
+	params := r.URL.Query()
+	level := params.Get("consistency")
+	if level == "" {
+		level = r.Header.Get(ConsistencyHeader)
+	}
+	maxStaleness := params.Get("max_staleness")
+	if maxStaleness == "" {
+		maxStaleness = r.Header.Get(MaxStalenessHeader)
+	}
+
+	c := sharding.Consistency{Level: sharding.ConsistencyLevel(level)}
+	if maxStaleness != "" {
+		d, err := time.ParseDuration(maxStaleness)
+		if err != nil {
+			return c, fmt.Errorf("invalid max staleness: %w", err)
+		}
+		c.MaxStaleness = d
+		if c.Level == "" {
+			c.Level = sharding.ConsistencyBounded
+		}
+	}
+	return c, c.Validate()
+}
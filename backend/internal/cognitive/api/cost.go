@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/cost"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetCostRates returns a tenant's cost rates
+func (h *CognitiveHandler) GetCostRates(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	rates := h.engine.GetCostRates(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rates": rates,
+		"count": len(rates),
+	})
+}
+
+// SetCostRates replaces a tenant's static cost table
+func (h *CognitiveHandler) SetCostRates(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		Rates []cost.Rate `json:"rates"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.engine.SetCostRates(tenantID, req.Rates); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rates := h.engine.GetCostRates(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rates": rates,
+		"count": len(rates),
+	})
+}
+
+// SyncCostRates loads a tenant's rates from a billing export
+func (h *CognitiveHandler) SyncCostRates(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		URL   string `json:"url"`
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	count, err := h.engine.SyncCostRates(r.Context(), tenantID, req.URL, req.Token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"synced": count,
+		"rates":  h.engine.GetCostRates(tenantID),
+	})
+}
+
+// GetCostReport returns the report of a tenant's latest cost run
+func (h *CognitiveHandler) GetCostReport(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	report, err := h.engine.GetCostReport(tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// AnalyzeCosts annotates a tenant's resources with their cost immediately
+func (h *CognitiveHandler) AnalyzeCosts(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	report, err := h.engine.AnalyzeCosts(r.Context(), tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// ConfigureCostTracking enables cost tracking for a tenant or updates its
+// configuration
+func (h *CognitiveHandler) ConfigureCostTracking(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		CostScale            float64 `json:"cost_scale"`
+		UtilizationPredicate string  `json:"utilization_predicate"`
+		IdleThreshold        float64 `json:"idle_threshold"`
+		IntervalSeconds      int     `json:"interval_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.IdleThreshold < 0 || req.IdleThreshold > 1 {
+		http.Error(w, "idle_threshold must be between 0 and 1", http.StatusBadRequest)
+		return
+	}
+
+	config := agents.DefaultCostConfig()
+	if req.CostScale > 0 {
+		config.CostScale = req.CostScale
+	}
+	if req.UtilizationPredicate != "" {
+		config.UtilizationPredicate = req.UtilizationPredicate
+	}
+	if req.IdleThreshold > 0 {
+		config.IdleThreshold = req.IdleThreshold
+	}
+	if req.IntervalSeconds > 0 {
+		config.Interval = time.Duration(req.IntervalSeconds) * time.Second
+	}
+
+	agent := h.engine.EnableCostTracking(tenantID, config)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agent_id": agent.GetID(),
+		"config":   agent.GetConfig(),
+	})
+}
+
+// DisableCostTracking stops cost tracking for a tenant
+func (h *CognitiveHandler) DisableCostTracking(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.DisableCostTracking(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Cost tracking disabled successfully",
+		"tenant_id": tenantID,
+	})
+}
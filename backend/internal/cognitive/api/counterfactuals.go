@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/counterfactuals"
+	"github.com/go-chi/chi/v5"
+)
+
+// counterfactualsSince parses the since duration of a request, 7 days by
+// default
+func counterfactualsSince(r *http.Request) (time.Time, error) {
+	since := 7 * 24 * time.Hour
+	if value := r.URL.Query().Get("since"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return time.Time{}, fmt.Errorf("invalid since duration: %s", value)
+		}
+		since = d
+	}
+	return time.Now().Add(-since), nil
+}
+
+// ListCounterfactuals returns the actions a tenant's policies suppressed,
+// optionally by source, blocker and class
+func (h *CognitiveHandler) ListCounterfactuals(w http.ResponseWriter, r *http.Request) {
+	since, err := counterfactualsSince(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	list := h.engine.ListCounterfactuals(chi.URLParam(r, "tenantID"), counterfactuals.Filter{
+		Since:   since,
+		Source:  query.Get("source"),
+		Blocker: query.Get("blocker"),
+		Class:   query.Get("class"),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"counterfactuals": list,
+		"count":           len(list),
+	})
+}
+
+// GetCounterfactual returns a suppressed action
+func (h *CognitiveHandler) GetCounterfactual(w http.ResponseWriter, r *http.Request) {
+	c, err := h.engine.GetCounterfactual(chi.URLParam(r, "tenantID"), chi.URLParam(r, "counterfactualID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// GetCounterfactualReport summarizes what a tenant's autonomy would have
+// done, per blocker and action class
+func (h *CognitiveHandler) GetCounterfactualReport(w http.ResponseWriter, r *http.Request) {
+	since, err := counterfactualsSince(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.engine.CounterfactualReport(chi.URLParam(r, "tenantID"), since))
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetDeadLetters lists a tenant's failed pipeline executions and agent runs
+func (h *CognitiveHandler) GetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	entries := h.engine.GetDeadLetters(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"dead_letters": entries,
+		"count":        len(entries),
+	})
+}
+
+// GetDeadLetter returns a single dead-letter entry
+func (h *CognitiveHandler) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	entryID := chi.URLParam(r, "entryID")
+
+	entry, err := h.engine.GetDeadLetter(entryID, tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+// DeleteDeadLetter discards a dead-letter entry
+func (h *CognitiveHandler) DeleteDeadLetter(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	entryID := chi.URLParam(r, "entryID")
+
+	if err := h.engine.DeleteDeadLetter(entryID, tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":  "Dead letter deleted successfully",
+		"entry_id": entryID,
+	})
+}
+
+// PurgeDeadLetters discards all of a tenant's dead-letter entries
+func (h *CognitiveHandler) PurgeDeadLetters(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	purged := h.engine.PurgeDeadLetters(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Dead letters purged successfully",
+		"purged":  purged,
+	})
+}
+
+// RetryDeadLetter replays a failed execution or agent run
+func (h *CognitiveHandler) RetryDeadLetter(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	entryID := chi.URLParam(r, "entryID")
+
+	if _, err := h.engine.GetDeadLetter(entryID, tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if _, err := h.engine.RetryDeadLetter(r.Context(), entryID, tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":  "Dead letter replayed successfully",
+		"entry_id": entryID,
+	})
+}
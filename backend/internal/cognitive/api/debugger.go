@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetAgentDebug reports whether the agent is in debug mode
+func (h *CognitiveHandler) GetAgentDebug(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	agentID := chi.URLParam(r, "agentID")
+
+	enabled, err := h.engine.AgentDebugEnabled(tenantID, agentID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agent_id": agentID,
+		"enabled":  enabled,
+	})
+}
+
+// EnableAgentDebug puts the agent in debug mode, recording every run
+func (h *CognitiveHandler) EnableAgentDebug(w http.ResponseWriter, r *http.Request) {
+	h.setAgentDebug(w, r, true)
+}
+
+// DisableAgentDebug takes the agent out of debug mode, keeping its
+// recordings
+func (h *CognitiveHandler) DisableAgentDebug(w http.ResponseWriter, r *http.Request) {
+	h.setAgentDebug(w, r, false)
+}
+
+func (h *CognitiveHandler) setAgentDebug(w http.ResponseWriter, r *http.Request, enabled bool) {
+	tenantID := chi.URLParam(r, "tenantID")
+	agentID := chi.URLParam(r, "agentID")
+
+	if err := h.engine.SetAgentDebug(tenantID, agentID, enabled); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agent_id": agentID,
+		"enabled":  enabled,
+	})
+}
+
+// GetAgentRecordings returns the recorded runs of the agent, oldest first
+func (h *CognitiveHandler) GetAgentRecordings(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	agentID := chi.URLParam(r, "agentID")
+
+	recordings, err := h.engine.GetAgentRecordings(tenantID, agentID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"recordings": recordings,
+		"count":      len(recordings),
+	})
+}
+
+// GetAgentRecording returns one recorded run of the agent by sequence
+func (h *CognitiveHandler) GetAgentRecording(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	agentID := chi.URLParam(r, "agentID")
+
+	sequence, err := strconv.ParseInt(chi.URLParam(r, "sequence"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid sequence", http.StatusBadRequest)
+		return
+	}
+
+	rec, err := h.engine.GetAgentRecording(tenantID, agentID, sequence)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec)
+}
+
+// ClearAgentRecordings forgets the recorded runs of the agent
+func (h *CognitiveHandler) ClearAgentRecordings(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	agentID := chi.URLParam(r, "agentID")
+
+	cleared, err := h.engine.ClearAgentRecordings(tenantID, agentID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"cleared": cleared,
+	})
+}
+
+// StepAgent runs one cycle of the agent and returns its recording inline
+func (h *CognitiveHandler) StepAgent(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	agentID := chi.URLParam(r, "agentID")
+
+	if _, err := h.engine.AgentDebugEnabled(tenantID, agentID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	rec, err := h.engine.StepAgent(r.Context(), tenantID, agentID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec)
+}
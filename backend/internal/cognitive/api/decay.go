@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/decay"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListDecayPolicies lists a tenant's truth-value decay policies
+func (h *CognitiveHandler) ListDecayPolicies(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	list := h.engine.ListDecayPolicies(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id": tenantID,
+		"policies":  list,
+		"count":     len(list),
+	})
+}
+
+// GetDecayPolicy returns a decay policy
+func (h *CognitiveHandler) GetDecayPolicy(w http.ResponseWriter, r *http.Request) {
+	p, err := h.engine.GetDecayPolicy(chi.URLParam(r, "tenantID"), chi.URLParam(r, "name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// SetDecayPolicy creates or replaces a decay policy
+func (h *CognitiveHandler) SetDecayPolicy(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var p decay.Policy
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	p.Name = chi.URLParam(r, "name")
+
+	p, err := h.engine.SetDecayPolicy(tenantID, p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// DeleteDecayPolicy removes a decay policy
+func (h *CognitiveHandler) DeleteDecayPolicy(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if err := h.engine.DeleteDecayPolicy(chi.URLParam(r, "tenantID"), name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Decay policy deleted successfully",
+		"name":    name,
+	})
+}
+
+// RunDecay applies a tenant's decay policies now
+func (h *CognitiveHandler) RunDecay(w http.ResponseWriter, r *http.Request) {
+	run, err := h.engine.DecayTruthValues(chi.URLParam(r, "tenantID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// GetDecayRun returns the outcome of the last application of a tenant's
+// decay policies
+func (h *CognitiveHandler) GetDecayRun(w http.ResponseWriter, r *http.Request) {
+	run, err := h.engine.GetDecayRun(chi.URLParam(r, "tenantID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
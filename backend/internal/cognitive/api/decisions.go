@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/decisions"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetDecisionPolicy returns the thresholds a tenant's schema calls must
+// meet per action class
+func (h *CognitiveHandler) GetDecisionPolicy(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	p, custom := h.engine.GetDecisionPolicy(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id": tenantID,
+		"policy":    p,
+		"default":   !custom,
+	})
+}
+
+// SetDecisionPolicy replaces a tenant's decision policy
+func (h *CognitiveHandler) SetDecisionPolicy(w http.ResponseWriter, r *http.Request) {
+	var p decisions.Policy
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.engine.SetDecisionPolicy(chi.URLParam(r, "tenantID"), p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// DeleteDecisionPolicy restores a tenant's default decision policy
+func (h *CognitiveHandler) DeleteDecisionPolicy(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	if err := h.engine.DeleteDecisionPolicy(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Decision policy reset to the default",
+		"tenant_id": tenantID,
+	})
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/diff"
+	"github.com/go-chi/chi/v5"
+)
+
+// DiffAtoms returns the atoms added, removed and changed between two
+// times (?from=&to=, RFC 3339, to defaulting to now) or between a tenant
+// and a shared ontology (?shared_space=)
+func (h *CognitiveHandler) DiffAtoms(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	query := r.URL.Query()
+
+	var (
+		d   *diff.Diff
+		err error
+	)
+	if spaceID := query.Get("shared_space"); spaceID != "" {
+		d, err = h.engine.DiffSharedSpace(tenantID, spaceID)
+	} else {
+		var from, to time.Time
+		if from, err = time.Parse(time.RFC3339, query.Get("from")); err != nil {
+			http.Error(w, "from must be an RFC 3339 time, or shared_space set", http.StatusBadRequest)
+			return
+		}
+		to = time.Now()
+		if query.Get("to") != "" {
+			if to, err = time.Parse(time.RFC3339, query.Get("to")); err != nil {
+				http.Error(w, "to must be an RFC 3339 time", http.StatusBadRequest)
+				return
+			}
+		}
+		if to.Before(from) {
+			http.Error(w, "from must not be after to", http.StatusBadRequest)
+			return
+		}
+		d, err = h.engine.DiffAtoms(tenantID, from, to)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d)
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/drift"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetDrift lists the current drift of a tenant, most severe first. With
+// ?min_severity=X only findings of at least that severity are returned.
+func (h *CognitiveHandler) GetDrift(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	minSeverity := 0.0
+	if v := r.URL.Query().Get("min_severity"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			http.Error(w, "invalid min_severity", http.StatusBadRequest)
+			return
+		}
+		minSeverity = parsed
+	}
+
+	findings, err := h.engine.GetDrift(tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	filtered := make([]drift.Finding, 0, len(findings))
+	for _, f := range findings {
+		if f.Severity >= minSeverity {
+			filtered = append(filtered, f)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"drift": filtered,
+		"count": len(filtered),
+	})
+}
+
+// DetectDrift compares the tenant's declared and observed state immediately
+func (h *CognitiveHandler) DetectDrift(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	findings, err := h.engine.DetectDrift(r.Context(), tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"drift": findings,
+		"count": len(findings),
+	})
+}
+
+// ConfigureDrift enables the drift agent for a tenant or replaces its
+// configuration. Omitted fields keep their defaults.
+func (h *CognitiveHandler) ConfigureDrift(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		Identity          string             `json:"identity"`
+		AttributeWeights  map[string]float64 `json:"attribute_weights"`
+		DefaultWeight     *float64           `json:"default_weight"`
+		MissingSeverity   *float64           `json:"missing_severity"`
+		UnmanagedSeverity *float64           `json:"unmanaged_severity"`
+		IntervalSeconds   int                `json:"interval_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	config := agents.DefaultDriftConfig()
+	if req.Identity != "" {
+		config.Detection.Identity = req.Identity
+	}
+	for key, weight := range req.AttributeWeights {
+		config.Detection.AttributeWeights[key] = weight
+	}
+	for _, v := range []struct {
+		value  *float64
+		target *float64
+	}{
+		{req.DefaultWeight, &config.Detection.DefaultWeight},
+		{req.MissingSeverity, &config.Detection.MissingSeverity},
+		{req.UnmanagedSeverity, &config.Detection.UnmanagedSeverity},
+	} {
+		if v.value == nil {
+			continue
+		}
+		if *v.value < 0 || *v.value > 1 {
+			http.Error(w, "severities must be between 0 and 1", http.StatusBadRequest)
+			return
+		}
+		*v.target = *v.value
+	}
+	if req.IntervalSeconds > 0 {
+		config.Interval = time.Duration(req.IntervalSeconds) * time.Second
+	}
+
+	agent := h.engine.EnableDrift(tenantID, config)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agent_id": agent.GetID(),
+		"config":   agent.GetConfig(),
+	})
+}
+
+// DisableDrift stops the drift agent of a tenant
+func (h *CognitiveHandler) DisableDrift(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.DisableDrift(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Drift agent disabled successfully",
+		"tenant_id": tenantID,
+	})
+}
+
+// RecordObservations records the observed attributes of resources that no
+// pipeline stage observes, e.g. from a cloud inventory
+func (h *CognitiveHandler) RecordObservations(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		Observations []struct {
+			Subject    string            `json:"subject"`
+			Category   string            `json:"category"`
+			Attributes map[string]string `json:"attributes"`
+		} `json:"observations"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, o := range req.Observations {
+		if err := h.engine.RecordObservations(tenantID, o.Subject, o.Category, o.Attributes); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Observations recorded successfully",
+		"count":   len(req.Observations),
+	})
+}
@@ -0,0 +1,236 @@
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Response encodings clients may ask for in place of JSON
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeMsgpack  = "application/msgpack"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// acceptOffers are the media types Encode negotiates, JSON first so that it
+// wins ties; the alternative names are answered with the canonical type
+var acceptOffers = []string{
+	ContentTypeJSON,
+	ContentTypeMsgpack,
+	"application/x-msgpack",
+	"application/vnd.msgpack",
+	ContentTypeProtobuf,
+	"application/protobuf",
+}
+
+// Encode answers clients that accept msgpack or protobuf better than JSON
+// in that encoding. JSON responses are transcoded: msgpack maps the JSON
+// document value for value, and protobuf sends it as a
+// google.protobuf.Value message. Other responses are sent as they are, as
+// are WebSocket upgrades and responses flushed while they are streamed.
+func Encode(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		if headerHasToken(r.Header, "Connection", "upgrade") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		var contentType string
+		switch negotiate(r.Header.Get("Accept"), acceptOffers, ContentTypeJSON) {
+		case ContentTypeMsgpack, "application/x-msgpack", "application/vnd.msgpack":
+			contentType = ContentTypeMsgpack
+		case ContentTypeProtobuf, "application/protobuf":
+			contentType = ContentTypeProtobuf
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &recorder{ResponseWriter: w, header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if rec.streaming {
+			return
+		}
+
+		for key, values := range rec.header {
+			w.Header()[key] = values
+		}
+		body := rec.body.Bytes()
+		if responseType, _, _ := strings.Cut(rec.header.Get("Content-Type"), ";"); strings.TrimSpace(responseType) == ContentTypeJSON {
+			if encoded, err := transcode(body, contentType); err == nil {
+				body = encoded
+				if contentType == ContentTypeProtobuf {
+					w.Header().Set("Content-Type", contentType+`; messageType="google.protobuf.Value"`)
+				} else {
+					w.Header().Set("Content-Type", contentType)
+				}
+				w.Header().Del("Content-Length")
+			}
+		}
+		w.WriteHeader(rec.status)
+		w.Write(body)
+	})
+}
+
+// recorder holds a response back so it can be transcoded, unless it is
+// flushed
+type recorder struct {
+	http.ResponseWriter
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	streaming   bool // Flushed, so written through untranscoded
+}
+
+func (rec *recorder) Header() http.Header {
+	if rec.streaming {
+		return rec.ResponseWriter.Header()
+	}
+	return rec.header
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.wroteHeader = true
+		rec.status = status
+	}
+}
+
+func (rec *recorder) Write(p []byte) (int, error) {
+	rec.wroteHeader = true
+	if rec.streaming {
+		return rec.ResponseWriter.Write(p)
+	}
+	return rec.body.Write(p)
+}
+
+// Flush gives up transcoding, as a streamed response is not one document,
+// and sends what was held back
+func (rec *recorder) Flush() {
+	if !rec.streaming {
+		rec.streaming = true
+		for key, values := range rec.header {
+			rec.ResponseWriter.Header()[key] = values
+		}
+		rec.ResponseWriter.WriteHeader(rec.status)
+		rec.ResponseWriter.Write(rec.body.Bytes())
+		rec.body.Reset()
+	}
+	http.NewResponseController(rec.ResponseWriter).Flush()
+}
+
+func (rec *recorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// transcode re-encodes a JSON document, which may be followed by a newline
+func transcode(body []byte, contentType string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if contentType == ContentTypeMsgpack {
+		decoder.UseNumber()
+	}
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	if contentType == ContentTypeProtobuf {
+		value, err := structpb.NewValue(v)
+		if err != nil {
+			return nil, err
+		}
+		return proto.Marshal(value)
+	}
+	return appendMsgpack(make([]byte, 0, len(body)), v), nil
+}
+
+// appendMsgpack appends the msgpack encoding of a decoded JSON value to b.
+// Numbers are encoded as integers when they are integral, and map keys in
+// sorted order.
+func appendMsgpack(b []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xc0)
+	case bool:
+		if v {
+			return append(b, 0xc3)
+		}
+		return append(b, 0xc2)
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return appendMsgpackInt(b, i)
+		}
+		f, _ := v.Float64()
+		return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(f))
+	case string:
+		n := len(v)
+		switch {
+		case n < 32:
+			b = append(b, 0xa0|byte(n))
+		case n <= math.MaxUint8:
+			b = append(b, 0xd9, byte(n))
+		case n <= math.MaxUint16:
+			b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+		default:
+			b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+		}
+		return append(b, v...)
+	case []interface{}:
+		b = appendMsgpackHeader(b, len(v), 0x90, 0xdc)
+		for _, item := range v {
+			b = appendMsgpack(b, item)
+		}
+		return b
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b = appendMsgpackHeader(b, len(v), 0x80, 0xde)
+		for _, key := range keys {
+			b = appendMsgpack(b, key)
+			b = appendMsgpack(b, v[key])
+		}
+		return b
+	}
+	return append(b, 0xc0)
+}
+
+func appendMsgpackInt(b []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i < 128:
+		return append(b, byte(i))
+	case i < 0 && i >= -32:
+		return append(b, byte(i))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		return append(b, 0xd0, byte(i))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(i))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(i))
+}
+
+// appendMsgpackHeader appends the header of an array or map of n elements:
+// the fix format up to 15, then the 16 and 32-bit formats
+func appendMsgpackHeader(b []byte, n int, fix, format16 byte) []byte {
+	switch {
+	case n < 16:
+		return append(b, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, format16), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, format16+1), uint32(n))
+}
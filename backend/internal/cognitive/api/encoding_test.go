@@ -0,0 +1,81 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestEncodeWebSocketUpgrade(t *testing.T) {
+	engine := cognitive.NewCognitiveEngine(cognitive.DefaultConfig())
+	defer engine.Close()
+	router := chi.NewRouter()
+	NewCognitiveHandler(engine).RegisterRoutes(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// Negotiating msgpack must not keep the stream from upgrading
+	fmt.Fprintf(conn, "GET /api/cognitive/stats/stream?interval=10ms HTTP/1.1\r\n"+
+		"Host: %s\r\n"+
+		"Accept: application/msgpack\r\n"+
+		"Accept-Encoding: gzip\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n", server.Listener.Addr())
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("Failed to read the handshake response: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected 101 Switching Protocols, got %s", resp.Status)
+	}
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Unexpected Sec-WebSocket-Accept %q", accept)
+	}
+
+	header := make([]byte, 2)
+	if _, err := br.Read(header); err != nil {
+		t.Fatalf("Failed to read the first message: %v", err)
+	}
+	if header[0] != 0x80|wsText {
+		t.Errorf("Expected a final text frame, got %#x", header[0])
+	}
+}
+
+func TestEncodeStreaming(t *testing.T) {
+	handler := Encode(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"event":1}`)
+		http.NewResponseController(w).Flush()
+		fmt.Fprintln(w, `{"event":2}`)
+	}))
+
+	// A flushed response is streamed as it is, not transcoded
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/msgpack")
+	handler.ServeHTTP(rec, req)
+	if !rec.Flushed || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected the response streamed as JSON, got %v", rec.Header())
+	}
+	if lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n"); len(lines) != 2 {
+		t.Errorf("Expected both events, got %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ShredTenantData destroys a tenant's encryption keys, making its
+// encrypted snapshots unreadable
+func (h *CognitiveHandler) ShredTenantData(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.ShredTenantData(r.Context(), tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Tenant encryption keys destroyed",
+		"tenant_id": tenantID,
+	})
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetMergeCandidates returns the duplicate concepts found for a tenant
+func (h *CognitiveHandler) GetMergeCandidates(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	candidates, err := h.engine.GetMergeCandidates(tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"candidates": candidates,
+		"count":      len(candidates),
+	})
+}
+
+// ResolveEntities looks for a tenant's duplicate concepts immediately
+func (h *CognitiveHandler) ResolveEntities(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	candidates, err := h.engine.ResolveEntities(r.Context(), tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"candidates": candidates,
+		"count":      len(candidates),
+	})
+}
+
+// ConfigureEntityResolution enables entity resolution for a tenant or
+// updates its configuration
+func (h *CognitiveHandler) ConfigureEntityResolution(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		NameWeight      float64 `json:"name_weight"`
+		LinkWeight      float64 `json:"link_weight"`
+		EmbeddingWeight float64 `json:"embedding_weight"`
+		MinConfidence   float64 `json:"min_confidence"`
+		AutoMergeAbove  float64 `json:"auto_merge_above"`
+		MaxConcepts     int     `json:"max_concepts"`
+		IntervalSeconds int     `json:"interval_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, v := range []float64{req.MinConfidence, req.AutoMergeAbove} {
+		if v < 0 || v > 1 {
+			http.Error(w, "thresholds must be between 0 and 1", http.StatusBadRequest)
+			return
+		}
+	}
+
+	config := agents.DefaultEntityResolutionConfig()
+	if req.NameWeight > 0 {
+		config.NameWeight = req.NameWeight
+	}
+	if req.LinkWeight > 0 {
+		config.LinkWeight = req.LinkWeight
+	}
+	if req.EmbeddingWeight > 0 {
+		config.EmbeddingWeight = req.EmbeddingWeight
+	}
+	if req.MinConfidence > 0 {
+		config.MinConfidence = req.MinConfidence
+	}
+	config.AutoMergeAbove = req.AutoMergeAbove
+	if req.MaxConcepts > 0 {
+		config.MaxConcepts = req.MaxConcepts
+	}
+	if req.IntervalSeconds > 0 {
+		config.Interval = time.Duration(req.IntervalSeconds) * time.Second
+	}
+	if config.AutoMergeAbove > 0 && config.AutoMergeAbove < config.MinConfidence {
+		http.Error(w, "auto_merge_above must not be below min_confidence", http.StatusBadRequest)
+		return
+	}
+
+	agent := h.engine.EnableEntityResolution(tenantID, config)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agent_id": agent.GetID(),
+		"config":   agent.GetConfig(),
+	})
+}
+
+// DisableEntityResolution stops entity resolution for a tenant
+func (h *CognitiveHandler) DisableEntityResolution(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.DisableEntityResolution(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Entity resolution disabled successfully",
+		"tenant_id": tenantID,
+	})
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/artifacts"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetPipelineExecutions lists the queued, running and recent executions of a
+// pipeline
+func (h *CognitiveHandler) GetPipelineExecutions(w http.ResponseWriter, r *http.Request) {
+	pipelineID := chi.URLParam(r, "pipelineID")
+
+	p, err := h.engine.GetPipeline(pipelineID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	executions := p.GetExecutions()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"pipeline_id": pipelineID,
+		"executions":  executions,
+		"count":       len(executions),
+	})
+}
+
+// GetPipelineExecution returns one execution, including its queue position
+func (h *CognitiveHandler) GetPipelineExecution(w http.ResponseWriter, r *http.Request) {
+	pipelineID := chi.URLParam(r, "pipelineID")
+	executionID := chi.URLParam(r, "executionID")
+
+	p, err := h.engine.GetPipeline(pipelineID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	exec, exists := p.GetExecution(executionID)
+	if !exists {
+		http.Error(w, "execution "+executionID+" not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(exec)
+}
+
+// SetPipelineConcurrency changes how many executions of a pipeline may run
+// at once
+func (h *CognitiveHandler) SetPipelineConcurrency(w http.ResponseWriter, r *http.Request) {
+	pipelineID := chi.URLParam(r, "pipelineID")
+
+	var req struct {
+		MaxConcurrency int `json:"max_concurrency"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.engine.GetPipeline(pipelineID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := p.SetMaxConcurrency(req.MaxConcurrency); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p.GetStats())
+}
+
+// ListExecutionArtifacts lists the artifacts the stages of a pipeline
+// execution attached, such as reports
+func (h *CognitiveHandler) ListExecutionArtifacts(w http.ResponseWriter, r *http.Request) {
+	list := h.engine.ListArtifacts(chi.URLParam(r, "tenantID"), chi.URLParam(r, "pipelineID"), chi.URLParam(r, "executionID"))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"artifacts": list,
+		"count":     len(list),
+	})
+}
+
+// GetExecutionArtifact downloads an artifact of a pipeline execution with
+// the content type its stage gave it
+func (h *CognitiveHandler) GetExecutionArtifact(w http.ResponseWriter, r *http.Request) {
+	a, data, err := h.engine.GetArtifact(r.Context(), chi.URLParam(r, "tenantID"), chi.URLParam(r, "pipelineID"), chi.URLParam(r, "executionID"), chi.URLParam(r, "name"))
+	if errors.Is(err, artifacts.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Name}))
+	w.Header().Set("ETag", `"`+a.SHA256+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Write(data)
+}
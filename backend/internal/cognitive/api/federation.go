@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/federation"
+	"github.com/go-chi/chi/v5"
+)
+
+// FederatedQuery selects atoms like FindAtoms, or by a saved query, and
+// returns each with the rows external sources hold for it under the names
+// of their lookups. Failing sources answer 502.
+func (h *CognitiveHandler) FederatedQuery(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	consistency, err := readConsistency(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var q federation.Query
+	if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.engine.Reader(consistency).FederatedQuery(r.Context(), tenantID, q)
+	if err != nil {
+		var lookupErr *federation.LookupError
+		status := http.StatusBadRequest
+		switch {
+		case errors.As(err, &lookupErr):
+			status = http.StatusBadGateway
+		case strings.Contains(err.Error(), "not found"):
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	atoms := make([]atomspace.Atom, len(result.Rows))
+	for i, row := range result.Rows {
+		atoms[i] = row.Atom
+	}
+	rows := atomResults(atoms)
+	for i, row := range result.Rows {
+		rows[i]["data"] = row.Data
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rows":    rows,
+		"count":   len(rows),
+		"lookups": result.Lookups,
+	})
+}
+
+// ListFederationConnections lists the SQL connections a tenant's lookups
+// may query and their named queries
+func (h *CognitiveHandler) ListFederationConnections(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	connections := h.engine.FederationConnections(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id":   tenantID,
+		"connections": connections,
+		"count":       len(connections),
+	})
+}
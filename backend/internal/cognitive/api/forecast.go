@@ -0,0 +1,283 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/forecast"
+	"github.com/go-chi/chi/v5"
+)
+
+// AppendSamples records observations of a tenant's time series
+func (h *CognitiveHandler) AppendSamples(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	series := chi.URLParam(r, "series")
+
+	var req struct {
+		Samples []forecast.Sample `json:"samples"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for i := range req.Samples {
+		if req.Samples[i].Time.IsZero() {
+			req.Samples[i].Time = time.Now()
+		}
+	}
+
+	if err := h.engine.AppendSamples(tenantID, series, req.Samples); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"series":  series,
+		"count":   len(req.Samples),
+		"samples": len(h.engine.GetSamples(tenantID, series)),
+	})
+}
+
+// ListTimeSeries returns the names of a tenant's time series
+func (h *CognitiveHandler) ListTimeSeries(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	series := h.engine.GetTimeSeries(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"series": series,
+		"count":  len(series),
+	})
+}
+
+// DeleteTimeSeries discards the samples of a time series
+func (h *CognitiveHandler) DeleteTimeSeries(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	series := chi.URLParam(r, "series")
+
+	if err := h.engine.DeleteTimeSeries(tenantID, series); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Time series deleted successfully",
+		"series":  series,
+	})
+}
+
+// ForecastSeries fits a model to one time series. The model, horizon,
+// season_length and an optional threshold/within question are taken from
+// the query string.
+func (h *CognitiveHandler) ForecastSeries(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	series := chi.URLParam(r, "series")
+	query := r.URL.Query()
+
+	params := forecast.DefaultParams()
+	if model := query.Get("model"); model != "" {
+		params.Model = forecast.Model(model)
+	}
+	if s := query.Get("season_length"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			http.Error(w, "invalid season_length", http.StatusBadRequest)
+			return
+		}
+		params.SeasonLength = n
+	}
+
+	horizon := agents.DefaultForecastConfig().Horizon
+	if s := query.Get("horizon"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			http.Error(w, "invalid horizon", http.StatusBadRequest)
+			return
+		}
+		horizon = d
+	}
+
+	f, err := h.engine.ForecastSeries(tenantID, series, params, horizon)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := map[string]interface{}{
+		"forecast": f,
+	}
+	if s := query.Get("threshold"); s != "" {
+		threshold, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			http.Error(w, "invalid threshold", http.StatusBadRequest)
+			return
+		}
+		within := horizon
+		if s := query.Get("within"); s != "" {
+			if within, err = time.ParseDuration(s); err != nil {
+				http.Error(w, "invalid within", http.StatusBadRequest)
+				return
+			}
+		}
+		probability, reachedAt := f.ExceedProbability(threshold, within)
+		response["threshold"] = agents.ThresholdForecast{
+			Series:      series,
+			Threshold:   threshold,
+			Within:      within.String(),
+			Probability: probability,
+			ReachedAt:   reachedAt,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetForecasts returns the latest forecasts of a tenant's forecasting agent
+func (h *CognitiveHandler) GetForecasts(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	agent, err := h.engine.GetForecastAgent(tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	forecasts := agent.GetForecasts()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"forecasts":  forecasts,
+		"thresholds": agent.GetThresholds(),
+		"count":      len(forecasts),
+	})
+}
+
+// RunForecasts refits a tenant's time series immediately
+func (h *CognitiveHandler) RunForecasts(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	agent, err := h.engine.RunForecasts(r.Context(), tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	forecasts := agent.GetForecasts()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"forecasts":  forecasts,
+		"thresholds": agent.GetThresholds(),
+		"count":      len(forecasts),
+	})
+}
+
+// ConfigureForecasting enables forecasting for a tenant or updates its
+// configuration
+func (h *CognitiveHandler) ConfigureForecasting(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		Model           string   `json:"model"`
+		Alpha           float64  `json:"alpha"`
+		Beta            float64  `json:"beta"`
+		Gamma           float64  `json:"gamma"`
+		SeasonLength    int      `json:"season_length"`
+		Horizon         string   `json:"horizon"`
+		Checkpoints     []string `json:"checkpoints"`
+		IntervalSeconds int      `json:"interval_seconds"`
+		Thresholds      []struct {
+			SeriesPrefix string  `json:"series_prefix"`
+			Value        float64 `json:"value"`
+			Within       string  `json:"within"`
+		} `json:"thresholds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	config := agents.DefaultForecastConfig()
+	if req.Model != "" {
+		config.Params.Model = forecast.Model(req.Model)
+	}
+	if req.Alpha > 0 {
+		config.Params.Alpha = req.Alpha
+	}
+	if req.Beta > 0 {
+		config.Params.Beta = req.Beta
+	}
+	if req.Gamma > 0 {
+		config.Params.Gamma = req.Gamma
+	}
+	config.Params.SeasonLength = req.SeasonLength
+	if req.Horizon != "" {
+		d, err := time.ParseDuration(req.Horizon)
+		if err != nil || d <= 0 {
+			http.Error(w, "invalid horizon", http.StatusBadRequest)
+			return
+		}
+		config.Horizon = d
+	}
+	if len(req.Checkpoints) > 0 {
+		config.Checkpoints = nil
+		for _, s := range req.Checkpoints {
+			d, err := time.ParseDuration(s)
+			if err != nil || d <= 0 {
+				http.Error(w, "invalid checkpoint: "+s, http.StatusBadRequest)
+				return
+			}
+			config.Checkpoints = append(config.Checkpoints, d)
+		}
+	}
+	if req.IntervalSeconds > 0 {
+		config.Interval = time.Duration(req.IntervalSeconds) * time.Second
+	}
+	for _, t := range req.Thresholds {
+		within := config.Horizon
+		if t.Within != "" {
+			d, err := time.ParseDuration(t.Within)
+			if err != nil || d <= 0 {
+				http.Error(w, "invalid threshold within: "+t.Within, http.StatusBadRequest)
+				return
+			}
+			within = d
+		}
+		config.Thresholds = append(config.Thresholds, agents.ForecastThreshold{
+			SeriesPrefix: t.SeriesPrefix,
+			Value:        t.Value,
+			Within:       within,
+		})
+	}
+
+	agent := h.engine.EnableForecasting(tenantID, config)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agent_id": agent.GetID(),
+		"config":   agent.GetConfig(),
+	})
+}
+
+// DisableForecasting stops forecasting for a tenant
+func (h *CognitiveHandler) DisableForecasting(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.DisableForecasting(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Forecasting disabled successfully",
+		"tenant_id": tenantID,
+	})
+}
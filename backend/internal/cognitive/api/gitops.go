@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/gitops"
+)
+
+// GetGitOpsStatus returns the outcome of the latest reconciliation with
+// the manifests
+func (h *CognitiveHandler) GetGitOpsStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.engine.GetGitOpsStatus()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// ReconcileGitOps converges the engine to the manifests immediately
+func (h *CognitiveHandler) ReconcileGitOps(w http.ResponseWriter, r *http.Request) {
+	status, err := h.engine.ReconcileGitOps(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), gitOpsErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// PlanGitOps reports how the declared tenants differ from the manifests,
+// without changing them
+func (h *CognitiveHandler) PlanGitOps(w http.ResponseWriter, r *http.Request) {
+	status, err := h.engine.PlanGitOps(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), gitOpsErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// gitOpsErrorStatus maps a reconciliation error to a status code: the
+// manifests are not configured, or could not be read from their source
+func gitOpsErrorStatus(err error) int {
+	if errors.Is(err, gitops.ErrNotConfigured) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadGateway
+}
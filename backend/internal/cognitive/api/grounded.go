@@ -0,0 +1,35 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/go-chi/chi/v5"
+)
+
+// CreateGroundedEvaluation adds an evaluation of a grounded predicate, such
+// as greater_than over a measurement and a number, and returns it with its
+// computed truth value
+func (h *CognitiveHandler) CreateGroundedEvaluation(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		Predicate string                       `json:"predicate"`
+		Arguments []cognitive.GroundedArgument `json:"arguments"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	atom, err := h.engine.AddGroundedEvaluation(tenantID, req.Predicate, req.Arguments)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(atomResults([]atomspace.Atom{atom})[0])
+}
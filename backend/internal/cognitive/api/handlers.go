@@ -1,78 +1,452 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/Avik2024/erebus/backend/internal/cognitive"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
 	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/budget"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/onboarding"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/pipeline"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/retry"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sharding"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/statsview"
 	"github.com/go-chi/chi/v5"
 )
 
 // CognitiveHandler handles HTTP requests for the cognitive engine
 type CognitiveHandler struct {
 	engine *cognitive.CognitiveEngine
+	limits  BodyLimits
+	timeout time.Duration // Of expensive endpoints
 }
 
 // NewCognitiveHandler creates a new cognitive API handler
 func NewCognitiveHandler(engine *cognitive.CognitiveEngine) *CognitiveHandler {
-	return &CognitiveHandler{engine: engine}
+	return &CognitiveHandler{engine: engine, limits: DefaultBodyLimits(), timeout: DefaultHandlerTimeout}
 }
 
 // RegisterRoutes registers all cognitive API routes
 func (h *CognitiveHandler) RegisterRoutes(r chi.Router) {
 	r.Route("/api/cognitive", func(r chi.Router) {
+		r.Use(h.rejectDraining)
+		r.Use(Compress)
+		r.Use(Encode)
+		r.Use(Authenticate)
+		r.Use(h.authenticateKey)
+		r.Use(h.trackUsage)
+		r.Use(h.limitBody)
+		
 		// Tenant management
+		r.Get("/tenants", h.ListTenants)
 		r.Post("/tenants/{tenantID}/init", h.InitializeTenant)
+		r.Get("/templates", h.ListTemplates)
+		r.Get("/templates/{name}", h.GetTemplate)
+		r.Put("/templates/{name}", h.SetTemplate)
+		r.Delete("/templates/{name}", h.DeleteTemplate)
+		
+		// Organizations owning project tenants
+		r.Get("/orgs", h.ListOrganizations)
+		r.Get("/orgs/{orgID}", h.GetOrganization)
+		r.Put("/orgs/{orgID}", h.SetOrganization)
+		r.Delete("/orgs/{orgID}", h.DeleteOrganization)
+		r.Put("/orgs/{orgID}/projects/{tenantID}", h.AddProject)
+		r.Delete("/orgs/{orgID}/projects/{tenantID}", h.RemoveProject)
+		r.Get("/orgs/{orgID}/stats", h.GetOrganizationStats)
+		r.With(RequireRole(acl.AdminRole)).Get("/orgs/{orgID}/keys", h.ListAPIKeys)
+		r.With(RequireRole(acl.AdminRole)).Post("/orgs/{orgID}/keys", h.CreateAPIKey)
+		r.With(RequireRole(acl.AdminRole)).Delete("/orgs/{orgID}/keys/{keyID}", h.RevokeAPIKey)
+		r.Get("/tenants/{tenantID}/organization", h.GetTenantOrganization)
+		
+		// Artifact bundles and promotion between environments
+		r.With(h.expensive).Post("/tenants/{tenantID}/bundles", h.ExportBundle)
+		r.Get("/tenants/{tenantID}/bundles", h.ListBundles)
+		r.Get("/tenants/{tenantID}/bundles/{version}", h.GetBundle)
+		r.Post("/tenants/{tenantID}/bundles/import", h.ImportBundle)
+		r.Get("/promotion-tracks", h.ListPromotionTracks)
+		r.Get("/promotion-tracks/{name}", h.GetPromotionTrack)
+		r.Put("/promotion-tracks/{name}", h.SetPromotionTrack)
+		r.Delete("/promotion-tracks/{name}", h.DeletePromotionTrack)
+		r.With(h.expensive).Post("/promotion-tracks/{name}/promote", h.Promote)
+		r.Get("/promotion-tracks/{name}/history", h.GetPromotions)
+		
+		// Reconciliation with declarative manifests
+		r.Get("/gitops", h.GetGitOpsStatus)
+		r.With(h.expensive).Get("/gitops/plan", h.PlanGitOps)
+		r.With(h.expensive).Post("/gitops/sync", h.ReconcileGitOps)
+		
+		// Election of the replica running agents, or their partitioning
+		// across replicas
+		r.Get("/leader", h.GetLeaderStatus)
+		r.Get("/partitions", h.GetPartitionReport)
 		
 		// AtomSpace operations
 		r.Post("/tenants/{tenantID}/atoms", h.CreateAtom)
+		r.With(h.importBody).Post("/tenants/{tenantID}/atoms/bulk", h.BulkCreateAtoms)
 		r.Get("/tenants/{tenantID}/atoms/{atomID}", h.GetAtom)
-		r.Get("/tenants/{tenantID}/atoms", h.QueryAtoms)
-		r.Put("/tenants/{tenantID}/atoms/{atomID}", h.UpdateAtom)
-		r.Delete("/tenants/{tenantID}/atoms/{atomID}", h.DeleteAtom)
+		r.With(h.expensive).Get("/tenants/{tenantID}/atoms", h.QueryAtoms)
+		r.With(h.expensive).Get("/tenants/{tenantID}/atoms/search", h.SearchAtoms)
+		r.With(h.expensive).Get("/tenants/{tenantID}/diff", h.DiffAtoms)
+		r.With(h.authorizeWrite(acl.KindAtom, "atomID")).Put("/tenants/{tenantID}/atoms/{atomID}", h.UpdateAtom)
+		r.With(h.authorizeWrite(acl.KindAtom, "atomID")).Delete("/tenants/{tenantID}/atoms/{atomID}", h.DeleteAtom)
+		r.Get("/tenants/{tenantID}/atoms/{atomID}/verify", h.VerifyAtom)
+		r.Get("/tenants/{tenantID}/atoms/protected", h.ListProtectedAtoms)
+		r.Get("/tenants/{tenantID}/atoms/{atomID}/protection", h.GetAtomProtection)
+		r.With(h.authorizeWrite(acl.KindAtom, "atomID")).Put("/tenants/{tenantID}/atoms/{atomID}/protection", h.ProtectAtom)
+		r.Delete("/tenants/{tenantID}/atoms/{atomID}/protection", h.UnprotectAtom)
+		r.Get("/tenants/{tenantID}/atoms/{atomID}/acl", h.GetAtomACL)
+		r.Put("/tenants/{tenantID}/atoms/{atomID}/acl", h.SetAtomACL)
+		r.Delete("/tenants/{tenantID}/atoms/{atomID}/acl", h.RemoveAtomACL)
+		r.With(h.authorizeWrite(acl.KindAtom, "atomID")).Post("/tenants/{tenantID}/atoms/{atomID}/merge", h.MergeAtoms)
+		r.Get("/tenants/{tenantID}/atoms/{atomID}/aliases", h.ListAliases)
+		r.With(h.authorizeWrite(acl.KindAtom, "atomID")).Post("/tenants/{tenantID}/atoms/{atomID}/aliases", h.AddAlias)
+		r.With(h.authorizeWrite(acl.KindAtom, "atomID")).Delete("/tenants/{tenantID}/atoms/{atomID}/aliases/{alias}", h.DeleteAlias)
+		r.Get("/tenants/{tenantID}/queries", h.ListSavedQueries)
+		r.Get("/tenants/{tenantID}/queries/{name}", h.GetSavedQuery)
+		r.Put("/tenants/{tenantID}/queries/{name}", h.SetSavedQuery)
+		r.Delete("/tenants/{tenantID}/queries/{name}", h.DeleteSavedQuery)
+		r.With(h.expensive).Post("/tenants/{tenantID}/queries/{name}/run", h.RunSavedQuery)
+		r.Get("/tenants/{tenantID}/shares", h.ListShares)
+		r.Post("/tenants/{tenantID}/shares", h.CreateShare)
+		r.Get("/tenants/{tenantID}/shares/{shareID}", h.GetShare)
+		r.Delete("/tenants/{tenantID}/shares/{shareID}", h.RevokeShare)
+		r.With(h.expensive).Get("/shared/{token}", h.ViewShare)
+		r.With(h.expensive).Post("/tenants/{tenantID}/federated-query", h.FederatedQuery)
+		r.Get("/tenants/{tenantID}/federation/connections", h.ListFederationConnections)
+		r.Get("/tenants/{tenantID}/decay-policies", h.ListDecayPolicies)
+		r.Get("/tenants/{tenantID}/decay-policies/{name}", h.GetDecayPolicy)
+		r.Put("/tenants/{tenantID}/decay-policies/{name}", h.SetDecayPolicy)
+		r.Delete("/tenants/{tenantID}/decay-policies/{name}", h.DeleteDecayPolicy)
+		r.Get("/tenants/{tenantID}/decay", h.GetDecayRun)
+		r.With(h.expensive).Post("/tenants/{tenantID}/decay", h.RunDecay)
+		r.Get("/tenants/{tenantID}/link-types", h.ListLinkTypes)
+		r.Get("/tenants/{tenantID}/link-types/{name}", h.GetLinkType)
+		r.Put("/tenants/{tenantID}/link-types/{name}", h.SetLinkType)
+		r.Delete("/tenants/{tenantID}/link-types/{name}", h.DeleteLinkType)
+		r.With(h.expensive).Post("/tenants/{tenantID}/paths", h.FindPath)
 		
 		// Concept nodes
 		r.Post("/tenants/{tenantID}/concepts", h.CreateConcept)
 		
 		// Links
 		r.Post("/tenants/{tenantID}/links/inheritance", h.CreateInheritanceLink)
+		r.Post("/tenants/{tenantID}/grounded-evaluations", h.CreateGroundedEvaluation)
 		
 		// Inference
-		r.Post("/tenants/{tenantID}/inference", h.RunInference)
+		r.With(h.expensive).Post("/tenants/{tenantID}/inference", h.RunInference)
+		r.Get("/tenants/{tenantID}/inference/selection", h.GetRuleSelection)
+		r.Put("/tenants/{tenantID}/inference/selection", h.SetRuleSelection)
+		r.Get("/tenants/{tenantID}/inference/pipelining", h.GetInferencePipelining)
+		r.Put("/tenants/{tenantID}/inference/pipelining", h.SetInferencePipelining)
+		r.Get("/tenants/{tenantID}/inference/analysis", h.AnalyzeRules)
+		r.Get("/tenants/{tenantID}/inference/limits", h.GetInferenceLimits)
+		r.Put("/tenants/{tenantID}/inference/limits", h.SetInferenceLimits)
 		
 		// Pipelines
 		r.Post("/tenants/{tenantID}/pipelines", h.CreatePipeline)
+		r.Get("/tenants/{tenantID}/pipelines", h.GetPipelines)
 		r.Get("/tenants/{tenantID}/pipelines/{pipelineID}", h.GetPipeline)
-		r.Post("/tenants/{tenantID}/pipelines/{pipelineID}/execute", h.ExecutePipeline)
+		r.With(h.expensive).Post("/tenants/{tenantID}/pipelines/{pipelineID}/execute", h.ExecutePipeline)
+		r.Get("/tenants/{tenantID}/pipelines/{pipelineID}/executions", h.GetPipelineExecutions)
+		r.Get("/tenants/{tenantID}/pipelines/{pipelineID}/executions/{executionID}", h.GetPipelineExecution)
+		r.Get("/tenants/{tenantID}/pipelines/{pipelineID}/executions/{executionID}/artifacts", h.ListExecutionArtifacts)
+		r.Get("/tenants/{tenantID}/pipelines/{pipelineID}/executions/{executionID}/artifacts/{name}", h.GetExecutionArtifact)
+		r.With(h.authorizeWrite(acl.KindPipeline, "pipelineID")).Put("/tenants/{tenantID}/pipelines/{pipelineID}/concurrency", h.SetPipelineConcurrency)
+		r.Get("/tenants/{tenantID}/pipelines/{pipelineID}/acl", h.GetPipelineACL)
+		r.Put("/tenants/{tenantID}/pipelines/{pipelineID}/acl", h.SetPipelineACL)
+		r.Delete("/tenants/{tenantID}/pipelines/{pipelineID}/acl", h.RemovePipelineACL)
+		r.Get("/pipeline-stages", h.GetStageKinds)
+		
+		// Dead-letter queue
+		r.Get("/tenants/{tenantID}/dead-letters", h.GetDeadLetters)
+		r.Delete("/tenants/{tenantID}/dead-letters", h.PurgeDeadLetters)
+		r.Get("/tenants/{tenantID}/dead-letters/{entryID}", h.GetDeadLetter)
+		r.Delete("/tenants/{tenantID}/dead-letters/{entryID}", h.DeleteDeadLetter)
+		r.Post("/tenants/{tenantID}/dead-letters/{entryID}/retry", h.RetryDeadLetter)
+		
+		// Shared ontology spaces
+		r.Post("/shared-spaces", h.CreateSharedSpace)
+		r.Get("/shared-spaces", h.GetSharedSpaces)
+		r.Post("/shared-spaces/{spaceID}/concepts", h.CreateSharedConcept)
+		r.Post("/shared-spaces/{spaceID}/links/inheritance", h.CreateSharedInheritanceLink)
+		r.Get("/tenants/{tenantID}/mounts", h.GetMounts)
+		r.Post("/tenants/{tenantID}/mounts/{spaceID}", h.MountSharedSpace)
+		r.Delete("/tenants/{tenantID}/mounts/{spaceID}", h.UnmountSharedSpace)
+		
+		// Sessions (interactive working memory)
+		r.Post("/tenants/{tenantID}/sessions", h.CreateSession)
+		r.Get("/tenants/{tenantID}/sessions/{sessionID}", h.GetSession)
+		r.Delete("/tenants/{tenantID}/sessions/{sessionID}", h.DeleteSession)
+		r.Post("/tenants/{tenantID}/sessions/{sessionID}/atoms", h.CreateSessionAtom)
+		r.Get("/tenants/{tenantID}/sessions/{sessionID}/atoms", h.GetSessionAtoms)
+		r.Post("/tenants/{tenantID}/sessions/{sessionID}/links/inheritance", h.CreateSessionInheritanceLink)
+		r.With(h.expensive).Post("/tenants/{tenantID}/sessions/{sessionID}/inference", h.RunSessionInference)
+		
+		// Triggers
+		r.Post("/tenants/{tenantID}/triggers", h.CreateTrigger)
+		r.Get("/tenants/{tenantID}/triggers", h.GetTriggers)
+		r.Get("/tenants/{tenantID}/triggers/{triggerID}", h.GetTrigger)
+		r.Put("/tenants/{tenantID}/triggers/{triggerID}/enabled", h.SetTriggerEnabled)
+		r.Delete("/tenants/{tenantID}/triggers/{triggerID}", h.DeleteTrigger)
 		
 		// Agents
 		r.Get("/tenants/{tenantID}/agents", h.GetAgents)
+		r.Get("/tenants/{tenantID}/agents/health", h.GetAgentHealth)
+		r.Post("/tenants/{tenantID}/agents/{agentID}/release", h.ReleaseAgent)
+		r.Put("/tenants/{tenantID}/budget", h.SetTenantBudget)
+		r.Delete("/tenants/{tenantID}/budget", h.RemoveTenantBudget)
+		r.Get("/tenants/{tenantID}/usage", h.GetTenantUsage)
+		r.Get("/tenants/{tenantID}/agents/daemons", h.GetAgentDaemons)
+		r.Post("/tenants/{tenantID}/feedback", h.SubmitFeedback)
+		r.Get("/tenants/{tenantID}/patterns", h.GetPatterns)
+		r.With(h.expensive).Post("/tenants/{tenantID}/patterns/mine", h.MinePatterns)
+		r.Put("/tenants/{tenantID}/patterns/miner", h.ConfigurePatternMiner)
+		r.Delete("/tenants/{tenantID}/patterns/miner", h.DisablePatternMiner)
+		r.Get("/tenants/{tenantID}/clusters", h.GetClusters)
+		r.With(h.expensive).Post("/tenants/{tenantID}/clusters/run", h.ClusterConcepts)
+		r.Put("/tenants/{tenantID}/clusters/agent", h.ConfigureClustering)
+		r.Delete("/tenants/{tenantID}/clusters/agent", h.DisableClustering)
+		r.Get("/tenants/{tenantID}/timeseries", h.ListTimeSeries)
+		r.Post("/tenants/{tenantID}/timeseries/{series}/samples", h.AppendSamples)
+		r.Delete("/tenants/{tenantID}/timeseries/{series}", h.DeleteTimeSeries)
+		r.Get("/tenants/{tenantID}/timeseries/{series}/forecast", h.ForecastSeries)
+		r.Get("/tenants/{tenantID}/forecasts", h.GetForecasts)
+		r.With(h.expensive).Post("/tenants/{tenantID}/forecasts/run", h.RunForecasts)
+		r.Put("/tenants/{tenantID}/forecasts/agent", h.ConfigureForecasting)
+		r.Delete("/tenants/{tenantID}/forecasts/agent", h.DisableForecasting)
+		r.Get("/tenants/{tenantID}/cost/rates", h.GetCostRates)
+		r.Put("/tenants/{tenantID}/cost/rates", h.SetCostRates)
+		r.Post("/tenants/{tenantID}/cost/rates/sync", h.SyncCostRates)
+		r.Get("/tenants/{tenantID}/cost/report", h.GetCostReport)
+		r.With(h.expensive).Post("/tenants/{tenantID}/cost/run", h.AnalyzeCosts)
+		r.Put("/tenants/{tenantID}/cost/agent", h.ConfigureCostTracking)
+		r.Delete("/tenants/{tenantID}/cost/agent", h.DisableCostTracking)
+		r.Get("/tenants/{tenantID}/recommendations", h.ListRecommendations)
+		r.With(h.expensive).Post("/tenants/{tenantID}/recommendations/run", h.RunRecommendations)
+		r.Put("/tenants/{tenantID}/recommendations/agent", h.ConfigureRecommendations)
+		r.Delete("/tenants/{tenantID}/recommendations/agent", h.DisableRecommendations)
+		r.Get("/tenants/{tenantID}/recommendations/{recommendationID}", h.GetRecommendation)
+		r.Post("/tenants/{tenantID}/recommendations/{recommendationID}/submit", h.SubmitRecommendation)
+		r.Get("/tenants/{tenantID}/approvals", h.ListApprovals)
+		r.With(RequireRole(acl.AdminRole)).Post("/tenants/{tenantID}/approvals/{recommendationID}", h.DecideApproval)
+		r.Get("/tenants/{tenantID}/entity-resolution", h.GetMergeCandidates)
+		r.With(h.expensive).Post("/tenants/{tenantID}/entity-resolution/run", h.ResolveEntities)
+		r.Put("/tenants/{tenantID}/entity-resolution/agent", h.ConfigureEntityResolution)
+		r.Delete("/tenants/{tenantID}/entity-resolution/agent", h.DisableEntityResolution)
+		r.Get("/tenants/{tenantID}/slos", h.ListSLOs)
+		r.Post("/tenants/{tenantID}/slos/evaluate", h.EvaluateSLOs)
+		r.Get("/tenants/{tenantID}/slos/{name}", h.GetSLO)
+		r.Put("/tenants/{tenantID}/slos/{name}", h.SetSLO)
+		r.Delete("/tenants/{tenantID}/slos/{name}", h.DeleteSLO)
+		r.Put("/tenants/{tenantID}/slo-agent", h.ConfigureSLOTracking)
+		r.Delete("/tenants/{tenantID}/slo-agent", h.DisableSLOTracking)
+		r.Post("/tenants/{tenantID}/alerts", h.IngestAlerts)
+		r.Post("/tenants/{tenantID}/alerts/alertmanager", h.IngestAlertmanager)
+		r.Get("/tenants/{tenantID}/change-hook", h.GetChangeHook)
+		r.Put("/tenants/{tenantID}/change-hook", h.SetChangeHook)
+		r.Delete("/tenants/{tenantID}/change-hook", h.RemoveChangeHook)
+		r.Get("/tenants/{tenantID}/changes", h.ListChanges)
+		r.Post("/tenants/{tenantID}/changes", h.RecordChanges)
+		r.Post("/tenants/{tenantID}/changes/github", h.IngestGitHubChanges)
+		r.Post("/tenants/{tenantID}/changes/gitlab", h.IngestGitLabChanges)
+		r.Post("/tenants/{tenantID}/v1/traces", h.IngestTraces)
+		r.Get("/tenants/{tenantID}/dependencies", h.GetDependencies)
+		r.With(h.expensive).Post("/tenants/{tenantID}/impact", h.AnalyzeImpact)
+		r.With(h.expensive).Post("/tenants/{tenantID}/simulate", h.Simulate)
+		r.Get("/tenants/{tenantID}/incidents", h.ListIncidents)
+		r.Get("/tenants/{tenantID}/incidents/{incidentID}", h.GetIncident)
+		r.Get("/tenants/{tenantID}/incidents/{incidentID}/timeline", h.GetIncidentTimeline)
+		r.Post("/tenants/{tenantID}/incidents/{incidentID}/ack", h.AcknowledgeIncident)
+		r.Post("/tenants/{tenantID}/incidents/{incidentID}/resolve", h.ResolveIncident)
+		r.Post("/tenants/{tenantID}/incidents/{incidentID}/runbooks/{name}/execute", h.ExecuteRunbook)
+		r.Get("/tenants/{tenantID}/runbooks", h.ListRunbooks)
+		r.Post("/tenants/{tenantID}/runbooks", h.LoadRunbooks)
+		r.With(h.expensive).Post("/tenants/{tenantID}/runbooks/apply", h.ApplyRunbooks)
+		r.Get("/tenants/{tenantID}/runbooks/runs", h.GetRunbookRuns)
+		r.Get("/tenants/{tenantID}/runbooks/{name}", h.GetRunbook)
+		r.Put("/tenants/{tenantID}/runbooks/{name}", h.SetRunbook)
+		r.Delete("/tenants/{tenantID}/runbooks/{name}", h.DeleteRunbook)
+		r.Put("/tenants/{tenantID}/runbook-agent", h.ConfigureRunbooks)
+		r.Delete("/tenants/{tenantID}/runbook-agent", h.DisableRunbooks)
+		r.Get("/tenants/{tenantID}/schemas", h.ListSchemas)
+		r.Post("/tenants/{tenantID}/executions", h.CreateExecution)
+		r.Get("/tenants/{tenantID}/executions", h.GetExecutionResults)
+		r.With(h.expensive).Post("/tenants/{tenantID}/executions/run", h.ExecuteSchemas)
+		r.Put("/tenants/{tenantID}/execution-agent", h.ConfigureExecution)
+		r.Delete("/tenants/{tenantID}/execution-agent", h.DisableExecution)
+		r.Get("/tenants/{tenantID}/decision-policy", h.GetDecisionPolicy)
+		r.With(RequireRole(acl.AdminRole)).Put("/tenants/{tenantID}/decision-policy", h.SetDecisionPolicy)
+		r.With(RequireRole(acl.AdminRole)).Delete("/tenants/{tenantID}/decision-policy", h.DeleteDecisionPolicy)
+		r.Get("/tenants/{tenantID}/attention", h.GetAttentionParams)
+		r.With(RequireRole(acl.AdminRole)).Put("/tenants/{tenantID}/attention", h.SetAttentionParams)
+		r.With(RequireRole(acl.AdminRole)).Delete("/tenants/{tenantID}/attention", h.DeleteAttentionParams)
+		r.Get("/tenants/{tenantID}/attention/focus", h.GetAttentionalFocus)
+		r.Get("/tenants/{tenantID}/counterfactuals", h.ListCounterfactuals)
+		r.Get("/tenants/{tenantID}/counterfactuals/report", h.GetCounterfactualReport)
+		r.Get("/tenants/{tenantID}/counterfactuals/{counterfactualID}", h.GetCounterfactual)
+		r.Get("/tenants/{tenantID}/terraform", h.GetTerraform)
+		r.Put("/tenants/{tenantID}/terraform", h.ConfigureTerraform)
+		r.Delete("/tenants/{tenantID}/terraform", h.DisableTerraform)
+		r.Post("/tenants/{tenantID}/terraform/sync", h.SyncTerraform)
+		r.Put("/tenants/{tenantID}/terraform/sources/{name}", h.SetTerraformSource)
+		r.Delete("/tenants/{tenantID}/terraform/sources/{name}", h.RemoveTerraformSource)
+		r.Post("/tenants/{tenantID}/secrets/check", h.CheckSecrets)
+		r.Get("/tenants/{tenantID}/connectors", h.ListConnectors)
+		r.Get("/tenants/{tenantID}/connectors/{name}", h.GetConnector)
+		r.Put("/tenants/{tenantID}/connectors/{name}", h.SetConnector)
+		r.Delete("/tenants/{tenantID}/connectors/{name}", h.RemoveConnector)
+		r.Post("/tenants/{tenantID}/connectors/{name}/start", h.StartConnector)
+		r.Post("/tenants/{tenantID}/connectors/{name}/stop", h.StopConnector)
+		r.Post("/tenants/{tenantID}/connectors/{name}/sync", h.SyncConnector)
+		r.Get("/tenants/{tenantID}/sandbox", h.GetSandbox)
+		r.Put("/tenants/{tenantID}/sandbox", h.SetSandbox)
+		r.Delete("/tenants/{tenantID}/sandbox", h.RemoveSandbox)
+		r.Get("/tenants/{tenantID}/sandbox/requests", h.GetSandboxRequests)
+		r.Delete("/tenants/{tenantID}/sandbox/requests", h.ClearSandboxRequests)
+		r.Get("/tenants/{tenantID}/cmdb", h.GetCMDB)
+		r.Put("/tenants/{tenantID}/cmdb", h.ConfigureCMDB)
+		r.Delete("/tenants/{tenantID}/cmdb", h.DisableCMDB)
+		r.Post("/tenants/{tenantID}/cmdb/sync", h.SyncCMDB)
+		r.Put("/tenants/{tenantID}/cmdb/sources/{name}", h.SetCMDBSource)
+		r.Delete("/tenants/{tenantID}/cmdb/sources/{name}", h.RemoveCMDBSource)
+		r.Get("/tenants/{tenantID}/drift", h.GetDrift)
+		r.With(h.expensive).Post("/tenants/{tenantID}/drift/run", h.DetectDrift)
+		r.Put("/tenants/{tenantID}/drift/agent", h.ConfigureDrift)
+		r.Delete("/tenants/{tenantID}/drift/agent", h.DisableDrift)
+		r.Post("/tenants/{tenantID}/observations", h.RecordObservations)
+		r.Get("/tenants/{tenantID}/reports", h.ListReports)
+		r.Get("/tenants/{tenantID}/reports/{name}", h.GetReport)
+		r.Put("/tenants/{tenantID}/reports/{name}", h.SetReport)
+		r.Delete("/tenants/{tenantID}/reports/{name}", h.DeleteReport)
+		r.Post("/tenants/{tenantID}/reports/{name}/generate", h.GenerateReport)
+		r.Get("/tenants/{tenantID}/reports/{name}/history", h.GetReportHistory)
+		r.Get("/tenants/{tenantID}/reports/{name}/latest", h.GetGeneratedReport)
+		r.Get("/tenants/{tenantID}/reports/{name}/history/{sequence}", h.GetGeneratedReport)
+		r.Put("/tenants/{tenantID}/report-agent", h.ConfigureReports)
+		r.Delete("/tenants/{tenantID}/report-agent", h.DisableReports)
+		r.Get("/tenants/{tenantID}/notifications/channels", h.ListNotificationChannels)
+		r.Get("/tenants/{tenantID}/notifications/channels/{name}", h.GetNotificationChannel)
+		r.Put("/tenants/{tenantID}/notifications/channels/{name}", h.SetNotificationChannel)
+		r.Delete("/tenants/{tenantID}/notifications/channels/{name}", h.DeleteNotificationChannel)
+		r.Post("/tenants/{tenantID}/notifications/channels/{name}/test", h.TestNotificationChannel)
+		r.Get("/tenants/{tenantID}/notifications/deliveries", h.GetNotificationDeliveries)
+		r.Get("/tenants/{tenantID}/maintenance/windows", h.ListMaintenanceWindows)
+		r.Post("/tenants/{tenantID}/maintenance/windows", h.AddMaintenanceWindow)
+		r.Get("/tenants/{tenantID}/maintenance/windows/{windowID}", h.GetMaintenanceWindow)
+		r.Delete("/tenants/{tenantID}/maintenance/windows/{windowID}", h.DeleteMaintenanceWindow)
+		r.Get("/tenants/{tenantID}/watches", h.ListWatches)
+		r.Post("/tenants/{tenantID}/watches", h.AddWatch)
+		r.Get("/tenants/{tenantID}/watches/{watchID}", h.GetWatch)
+		r.Delete("/tenants/{tenantID}/watches/{watchID}", h.DeleteWatch)
+		r.With(h.expensive).Get("/tenants/{tenantID}/hygiene", h.GetHygiene)
+		r.With(h.expensive).Post("/tenants/{tenantID}/hygiene/apply", h.ApplyHygiene)
+		r.Get("/tenants/{tenantID}/admission-hooks", h.ListAdmissionHooks)
+		r.Get("/tenants/{tenantID}/admission-hooks/{name}", h.GetAdmissionHook)
+		r.Put("/tenants/{tenantID}/admission-hooks/{name}", h.SetAdmissionHook)
+		r.Delete("/tenants/{tenantID}/admission-hooks/{name}", h.DeleteAdmissionHook)
+		r.Get("/tenants/{tenantID}/provenance", h.GetProvenance)
+		r.Put("/tenants/{tenantID}/provenance", h.ConfigureProvenance)
+		r.Delete("/tenants/{tenantID}/provenance", h.DisableProvenance)
+		r.Post("/tenants/{tenantID}/provenance/keys/rotate", h.RotateSigningKey)
+		r.Get("/tenants/{tenantID}/provenance/ledger", h.GetLedger)
+		r.Get("/tenants/{tenantID}/provenance/ledger/verify", h.VerifyLedger)
+		r.Delete("/tenants/{tenantID}/encryption-keys", h.ShredTenantData)
+		r.With(h.expensive).Post("/tenants/{tenantID}/export", h.ExportTenant)
+		r.Delete("/tenants/{tenantID}/data", h.PurgeTenantData)
+		r.Get("/tenants/{tenantID}/value-log/retention", h.GetValueLogRetention)
+		r.Put("/tenants/{tenantID}/value-log/retention", h.SetValueLogRetention)
+		r.Get("/tenants/{tenantID}/learning", h.GetLearnedValues)
+		r.Delete("/tenants/{tenantID}/learning", h.ResetLearning)
+		r.Get("/tenants/{tenantID}/agents/run-plan", h.GetAgentRunPlan)
+		r.Put("/tenants/{tenantID}/agents/run-plan", h.SetAgentRunPlan)
+		r.Delete("/tenants/{tenantID}/agents/run-plan", h.RemoveAgentRunPlan)
 		r.Get("/tenants/{tenantID}/agents/{agentID}", h.GetAgent)
+		r.Get("/tenants/{tenantID}/agents/{agentID}/debug", h.GetAgentDebug)
+		r.Put("/tenants/{tenantID}/agents/{agentID}/debug", h.EnableAgentDebug)
+		r.Delete("/tenants/{tenantID}/agents/{agentID}/debug", h.DisableAgentDebug)
+		r.Get("/tenants/{tenantID}/agents/{agentID}/recordings", h.GetAgentRecordings)
+		r.Delete("/tenants/{tenantID}/agents/{agentID}/recordings", h.ClearAgentRecordings)
+		r.Get("/tenants/{tenantID}/agents/{agentID}/recordings/{sequence}", h.GetAgentRecording)
+		r.With(h.expensive).Post("/tenants/{tenantID}/agents/{agentID}/step", h.StepAgent)
+		r.Get("/tenants/{tenantID}/placement", h.GetPlacement)
+		r.Put("/tenants/{tenantID}/placement", h.SetPlacement)
+		r.Delete("/tenants/{tenantID}/placement", h.RemovePlacement)
+		r.Get("/shards", h.GetShards)
+		r.Put("/shards", h.ResizeShards)
+		r.With(RequireRole(acl.AdminRole), h.expensive).Get("/integrity", h.CheckIntegrity)
+		r.With(RequireRole(acl.AdminRole), h.expensive).Post("/integrity/repair", h.RepairIntegrity)
 		
 		// Statistics
 		r.Get("/tenants/{tenantID}/stats", h.GetStats)
+		r.Get("/tenants/{tenantID}/stats/history", h.GetStatsHistory)
+		r.Get("/tenants/{tenantID}/stats/stream", h.StreamStats)
 		r.Get("/stats", h.GetGlobalStats)
+		r.Get("/stats/history", h.GetStatsHistory)
+		r.Get("/stats/stream", h.StreamStats)
+		r.Get("/memory", h.GetMemoryReport)
+		r.Get("/usage", h.GetUsage)
+		r.Get("/usage/export", h.ExportUsage)
+		r.Get("/tenants/{tenantID}/api-usage", h.GetTenantAPIUsage)
+		r.Get("/metering", h.GetMetering)
+		r.With(h.expensive).Post("/metering/flush", h.FlushMetering)
+		r.Get("/tenants/{tenantID}/metering", h.GetTenantMetering)
 		
 		// Health
 		r.Get("/health", h.Health)
 	})
 }
 
-// InitializeTenant initializes a new tenant
+// InitializeTenant initializes a new tenant, onboarding it with a template
+// when the body names one or describes one inline
 func (h *CognitiveHandler) InitializeTenant(w http.ResponseWriter, r *http.Request) {
 	tenantID := chi.URLParam(r, "tenantID")
 	
-	if err := h.engine.InitializeTenant(tenantID); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	req, err := decodeOnboardingRequest(r)
+	if err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 	
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	result, err := h.onboardTenant(tenantID, req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, onboarding.ErrTemplateNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	
+	response := map[string]interface{}{
 		"message":   "Tenant initialized successfully",
 		"tenant_id": tenantID,
+	}
+	if result != nil {
+		response["onboarding"] = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ListTenants lists the initialized tenants
+func (h *CognitiveHandler) ListTenants(w http.ResponseWriter, r *http.Request) {
+	tenants := h.engine.ListTenants()
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenants": tenants,
+		"count":   len(tenants),
 	})
 }
 
@@ -85,6 +459,7 @@ func (h *CognitiveHandler) CreateAtom(w http.ResponseWriter, r *http.Request) {
 		Name   string  `json:"name"`
 		Strength float64 `json:"strength"`
 		Confidence float64 `json:"confidence"`
+		ACL *acl.ACL `json:"acl"` // Optional; owned by the requesting user unless it names an owner
 	}
 	
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -92,6 +467,15 @@ func (h *CognitiveHandler) CreateAtom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	if req.ACL != nil {
+		a, err := requestedACL(r, *req.ACL)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.ACL = &a
+	}
+	
 	atomID := atomspace.GenerateAtomID(atomspace.AtomType(req.Type), req.Name, nil)
 	node := atomspace.NewNode(atomID, req.Name, tenantID, atomspace.AtomType(req.Type))
 	
@@ -103,14 +487,22 @@ func (h *CognitiveHandler) CreateAtom(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	if err := h.engine.AddAtom(node); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 	
+	if req.ACL != nil {
+		if _, err := h.engine.SetACL(tenantID, acl.KindAtom, node.GetID(), *req.ACL, acl.PrincipalFrom(r.Context())); err != nil {
+			writeACLError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	
+	// Admission webhooks may have renamed the atom
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"atom_id": atomID,
-		"name":    req.Name,
+		"atom_id": node.GetID(),
+		"name":    node.GetName(),
 		"type":    req.Type,
 	})
 }
@@ -143,20 +535,29 @@ func (h *CognitiveHandler) GetAtom(w http.ResponseWriter, r *http.Request) {
 			"lti":  av.LTI,
 			"vlti": av.VLTI,
 		},
+		"protected": atomspace.IsProtected(atom),
 	})
 }
 
-// QueryAtoms queries atoms
+// QueryAtoms queries atoms. The type, name, label and truth value range
+// parameters are answered from indices where possible; explain=true adds
+// the plan each shard used. Reads are as consistent as the request's
+// consistency hint demands.
 func (h *CognitiveHandler) QueryAtoms(w http.ResponseWriter, r *http.Request) {
 	tenantID := chi.URLParam(r, "tenantID")
+	params := r.URL.Query()
+	consistency, err := readConsistency(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	
 	// Optional query parameters
-	atomTypeStr := r.URL.Query().Get("type")
-	name := r.URL.Query().Get("name")
-	
-	var atoms []atomspace.Atom
-	
-	if atomTypeStr != "" {
+	query := atomspace.Query{
+		Name:   params.Get("name"),
+		Labels: params["label"],
+	}
+	if atomTypeStr := params.Get("type"); atomTypeStr != "" {
 		// Parse atom type
 		var atomType atomspace.AtomType
 		switch atomTypeStr {
@@ -164,24 +565,75 @@ func (h *CognitiveHandler) QueryAtoms(w http.ResponseWriter, r *http.Request) {
 			atomType = atomspace.NodeType
 		case "concept":
 			atomType = atomspace.ConceptNodeType
+		case "number":
+			atomType = atomspace.NumberNodeType
+		case "grounded_predicate":
+			atomType = atomspace.GroundedPredicateNodeType
+		case "grounded_schema":
+			atomType = atomspace.GroundedSchemaNodeType
 		case "inheritance":
 			atomType = atomspace.InheritanceLinkType
+		case "alias":
+			atomType = atomspace.AliasLinkType
 		default:
 			atomType = atomspace.NodeType
 		}
-		
-		atoms = h.engine.QueryAtoms(tenantID, func(a atomspace.Atom) bool {
-			return a.GetType() == atomType
-		})
-	} else if name != "" {
-		atoms = h.engine.QueryAtoms(tenantID, func(a atomspace.Atom) bool {
-			return a.GetName() == name
-		})
+		query.Type = &atomType
+	}
+	if query.Strength, err = parseRange(params, "strength"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if query.Confidence, err = parseRange(params, "confidence"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if l := params.Get("limit"); l != "" {
+		if query.Limit, err = strconv.Atoi(l); err != nil || query.Limit < 0 {
+			http.Error(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+	
+	var atoms []atomspace.Atom
+	var plans []sharding.ShardPlan
+	if asOf := params.Get("as_of"); asOf != "" {
+		// Time-travel read of the atoms held at a past time
+		if len(query.Labels) > 0 {
+			http.Error(w, "label cannot be combined with as_of", http.StatusBadRequest)
+			return
+		}
+		at, err := time.Parse(time.RFC3339, asOf)
+		if err != nil {
+			http.Error(w, "as_of must be an RFC 3339 time: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		atoms, err = h.engine.QueryAtomsAsOf(tenantID, at, query.Matches)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if query.Limit > 0 && len(atoms) > query.Limit {
+			atoms = atoms[:query.Limit]
+		}
 	} else {
-		atoms = h.engine.QueryAtoms(tenantID, nil)
+		atoms, plans = h.engine.Reader(consistency).FindAtoms(tenantID, query)
 	}
 	
-	// Convert to JSON-friendly format
+	response := map[string]interface{}{
+		"atoms": atomResults(atoms),
+		"count": len(atoms),
+	}
+	if params.Get("explain") == "true" && plans != nil {
+		response["plan"] = plans
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// atomResults converts query results to a JSON-friendly format
+func atomResults(atoms []atomspace.Atom) []map[string]interface{} {
 	result := make([]map[string]interface{}, len(atoms))
 	for i, atom := range atoms {
 		tv := atom.GetTruthValue()
@@ -194,16 +646,103 @@ func (h *CognitiveHandler) QueryAtoms(w http.ResponseWriter, r *http.Request) {
 				"confidence": tv.Confidence,
 			},
 		}
+		if link, ok := atom.(*atomspace.Link); ok {
+			outgoing := make([]string, 0, len(link.GetOutgoing()))
+			for _, target := range link.GetOutgoing() {
+				outgoing = append(outgoing, target.GetID())
+			}
+			result[i]["outgoing"] = outgoing
+		}
+	}
+	return result
+}
+
+// parseRange reads the min_<key> and max_<key> parameters of a truth value
+// range, either of which defaults to the bound of [0, 1]. It returns nil if
+// neither is set.
+func parseRange(params url.Values, key string) (*atomspace.Range, error) {
+	minStr, maxStr := params.Get("min_"+key), params.Get("max_"+key)
+	if minStr == "" && maxStr == "" {
+		return nil, nil
+	}
+	
+	rng := &atomspace.Range{Min: 0, Max: 1}
+	var err error
+	if minStr != "" {
+		if rng.Min, err = strconv.ParseFloat(minStr, 64); err != nil {
+			return nil, fmt.Errorf("min_%s must be a number", key)
+		}
+	}
+	if maxStr != "" {
+		if rng.Max, err = strconv.ParseFloat(maxStr, 64); err != nil {
+			return nil, fmt.Errorf("max_%s must be a number", key)
+		}
+	}
+	if rng.Min > rng.Max {
+		return nil, fmt.Errorf("min_%s exceeds max_%s", key, key)
+	}
+	return rng, nil
+}
+
+// SearchAtoms searches atoms by name (prefix, substring or fuzzy)
+func (h *CognitiveHandler) SearchAtoms(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	consistency, err := readConsistency(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		http.Error(w, "query parameter 'q' is required", http.StatusBadRequest)
+		return
+	}
+	
+	mode := atomspace.SearchModeAuto
+	switch m := atomspace.SearchMode(r.URL.Query().Get("mode")); m {
+	case "":
+	case atomspace.SearchModeAuto, atomspace.SearchModePrefix, atomspace.SearchModeSubstring, atomspace.SearchModeFuzzy:
+		mode = m
+	default:
+		http.Error(w, "invalid search mode: "+string(m), http.StatusBadRequest)
+		return
+	}
+	
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 0 {
+			http.Error(w, "invalid limit: "+l, http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	
+	results := h.engine.Reader(consistency).SearchAtoms(tenantID, query, mode, limit)
+	
+	result := make([]map[string]interface{}, len(results))
+	for i, res := range results {
+		result[i] = map[string]interface{}{
+			"atom_id": res.Atom.GetID(),
+			"name":    res.Atom.GetName(),
+			"type":    res.Atom.GetType(),
+			"score":   res.Score,
+			"match":   res.Match,
+		}
 	}
 	
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"atoms": result,
-		"count": len(result),
+		"query":   query,
+		"mode":    mode,
+		"results": result,
+		"count":   len(result),
 	})
 }
 
-// UpdateAtom updates an atom
+// UpdateAtom updates an atom. Protected atoms are refused unless an admin
+// asks to override=true.
 func (h *CognitiveHandler) UpdateAtom(w http.ResponseWriter, r *http.Request) {
 	tenantID := chi.URLParam(r, "tenantID")
 	atomID := chi.URLParam(r, "atomID")
@@ -219,7 +758,7 @@ func (h *CognitiveHandler) UpdateAtom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	err := h.engine.UpdateAtom(atomID, tenantID, func(atom atomspace.Atom) error {
+	updater := func(atom atomspace.Atom) error {
 		if req.Strength != nil || req.Confidence != nil {
 			tv := atom.GetTruthValue()
 			if req.Strength != nil {
@@ -238,10 +777,16 @@ func (h *CognitiveHandler) UpdateAtom(w http.ResponseWriter, r *http.Request) {
 		}
 		
 		return nil
-	})
+	}
 	
+	var err error
+	if r.URL.Query().Get("override") == "true" {
+		err = h.engine.OverrideUpdateAtom(atomID, tenantID, updater, acl.PrincipalFrom(r.Context()))
+	} else {
+		err = h.engine.UpdateAtom(atomID, tenantID, updater)
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, err, http.StatusNotFound)
 		return
 	}
 	
@@ -252,13 +797,20 @@ func (h *CognitiveHandler) UpdateAtom(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// DeleteAtom deletes an atom
+// DeleteAtom deletes an atom. Protected atoms are refused unless an admin
+// asks to override=true.
 func (h *CognitiveHandler) DeleteAtom(w http.ResponseWriter, r *http.Request) {
 	tenantID := chi.URLParam(r, "tenantID")
 	atomID := chi.URLParam(r, "atomID")
 	
-	if err := h.engine.DeleteAtom(atomID, tenantID); err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	var err error
+	if r.URL.Query().Get("override") == "true" {
+		err = h.engine.OverrideDeleteAtom(atomID, tenantID, acl.PrincipalFrom(r.Context()))
+	} else {
+		err = h.engine.DeleteAtom(atomID, tenantID)
+	}
+	if err != nil {
+		writeError(w, err, http.StatusNotFound)
 		return
 	}
 	
@@ -284,7 +836,7 @@ func (h *CognitiveHandler) CreateConcept(w http.ResponseWriter, r *http.Request)
 	
 	atom, err := h.engine.CreateConceptNode(req.Name, tenantID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 	
@@ -312,7 +864,7 @@ func (h *CognitiveHandler) CreateInheritanceLink(w http.ResponseWriter, r *http.
 	
 	link, err := h.engine.CreateInheritanceLink(req.SourceID, req.TargetID, tenantID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 	
@@ -337,7 +889,8 @@ func (h *CognitiveHandler) RunInference(w http.ResponseWriter, r *http.Request)
 		req.MaxIterations = 10
 	}
 	
-	ctx := r.Context()
+	var report inference.RunReport
+	ctx := inference.WithReport(r.Context(), &report)
 	newAtoms, err := h.engine.RunInference(ctx, tenantID, req.MaxIterations)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -348,6 +901,8 @@ func (h *CognitiveHandler) RunInference(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"new_atoms_count": len(newAtoms),
 		"max_iterations":  req.MaxIterations,
+		"status":          report.Status,
+		"report":          report,
 	})
 }
 
@@ -358,6 +913,11 @@ func (h *CognitiveHandler) CreatePipeline(w http.ResponseWriter, r *http.Request
 	var req struct {
 		Name string `json:"name"`
 		UseDefault bool `json:"use_default"`
+		InputType string `json:"input_type"`
+		Stages []pipeline.StageSpec `json:"stages"`
+		MaxConcurrency int `json:"max_concurrency"`
+		Retry *retry.Spec `json:"retry"`
+		ACL *acl.ACL `json:"acl"` // Optional; owned by the requesting user unless it names an owner
 	}
 	
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -365,14 +925,43 @@ func (h *CognitiveHandler) CreatePipeline(w http.ResponseWriter, r *http.Request
 		return
 	}
 	
+	if req.ACL != nil {
+		a, err := requestedACL(r, *req.ACL)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.ACL = &a
+	}
+	
 	var pipelineID string
 	var err error
 	
 	if req.UseDefault {
 		pipelineID, err = h.engine.CreateDefaultPipeline(tenantID)
+	} else if len(req.Stages) > 0 {
+		// Declarative pipeline built from the stage registry
+		pipelineID = req.Name + "-" + time.Now().Format("20060102150405")
+		_, err = h.engine.CreatePipelineFromSpec(pipelineID, tenantID, pipeline.PipelineSpec{
+			Name:      req.Name,
+			InputType:      pipeline.DataType(req.InputType),
+			Stages:         req.Stages,
+			MaxConcurrency: req.MaxConcurrency,
+			Retry:          req.Retry,
+		})
 	} else {
 		pipelineID = req.Name + "-" + time.Now().Format("20060102150405")
-		_, err = h.engine.CreatePipeline(pipelineID, req.Name, tenantID)
+		var p *pipeline.Pipeline
+		p, err = h.engine.CreatePipeline(pipelineID, req.Name, tenantID)
+		if err == nil && req.InputType != "" {
+			err = p.SetInputType(pipeline.DataType(req.InputType))
+		}
+		if err == nil && req.MaxConcurrency > 0 {
+			err = p.SetMaxConcurrency(req.MaxConcurrency)
+		}
+		if err == nil && req.Retry != nil {
+			p.SetRetryPolicy(req.Retry.Policy())
+		}
 	}
 	
 	if err != nil {
@@ -380,6 +969,13 @@ func (h *CognitiveHandler) CreatePipeline(w http.ResponseWriter, r *http.Request
 		return
 	}
 	
+	if req.ACL != nil {
+		if _, err := h.engine.SetACL(tenantID, acl.KindPipeline, pipelineID, *req.ACL, acl.PrincipalFrom(r.Context())); err != nil {
+			writeACLError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"pipeline_id": pipelineID,
@@ -387,6 +983,24 @@ func (h *CognitiveHandler) CreatePipeline(w http.ResponseWriter, r *http.Request
 	})
 }
 
+// GetPipelines lists a tenant's pipelines
+func (h *CognitiveHandler) GetPipelines(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	
+	pipelines := h.engine.GetPipelinesByTenant(tenantID)
+	
+	pipelineStats := make([]map[string]interface{}, len(pipelines))
+	for i, p := range pipelines {
+		pipelineStats[i] = p.GetStats()
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"pipelines": pipelineStats,
+		"count":     len(pipelines),
+	})
+}
+
 // GetPipeline gets a specific pipeline
 func (h *CognitiveHandler) GetPipeline(w http.ResponseWriter, r *http.Request) {
 	pipelineID := chi.URLParam(r, "pipelineID")
@@ -401,21 +1015,89 @@ func (h *CognitiveHandler) GetPipeline(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(pipeline.GetStats())
 }
 
-// ExecutePipeline executes a pipeline
+// ExecutePipeline executes a pipeline. With async=true the execution is
+// queued and its ID and queue position are returned immediately; at (an
+// RFC 3339 time) or delay (a duration) schedule it to start later. The
+// optional body {"input": {...}} gives the input, as inline atoms, a saved
+// query or a dataset artifact.
 func (h *CognitiveHandler) ExecutePipeline(w http.ResponseWriter, r *http.Request) {
 	pipelineID := chi.URLParam(r, "pipelineID")
+	query := r.URL.Query()
+	
+	var req struct {
+		Input *pipeline.Input `json:"input"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var input interface{}
+	if req.Input != nil {
+		resolved, err := h.engine.ResolvePipelineInput(r.Context(), pipelineID, *req.Input)
+		if err != nil {
+			http.Error(w, "Invalid input: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		input = resolved
+	}
+	
+	var runAt time.Time
+	if at := query.Get("at"); at != "" {
+		parsed, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			http.Error(w, "Invalid at: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		runAt = parsed
+	} else if delay := query.Get("delay"); delay != "" {
+		parsed, err := time.ParseDuration(delay)
+		if err != nil {
+			http.Error(w, "Invalid delay: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		runAt = time.Now().Add(parsed)
+	}
+	
+	if query.Get("async") == "true" || !runAt.IsZero() {
+		exec, err := h.engine.SchedulePipeline(context.WithoutCancel(r.Context()), pipelineID, input, runAt)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(exec)
+		return
+	}
 	
 	ctx := r.Context()
-	_, err := h.engine.ExecutePipeline(ctx, pipelineID, nil)
+	output, err := h.engine.ExecutePipeline(ctx, pipelineID, input)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	response := map[string]interface{}{
 		"message":     "Pipeline executed successfully",
 		"pipeline_id": pipelineID,
+	}
+	if report, ok := output.(*pipeline.Report); ok {
+		response["report"] = report
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetStageKinds lists the stage kinds available to declarative pipelines
+func (h *CognitiveHandler) GetStageKinds(w http.ResponseWriter, r *http.Request) {
+	kinds := h.engine.StageRegistry().Kinds()
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"stage_kinds": kinds,
+		"count":       len(kinds),
 	})
 }
 
@@ -437,6 +1119,147 @@ func (h *CognitiveHandler) GetAgents(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetAgentHealth returns the supervision state of a tenant's agents
+func (h *CognitiveHandler) GetAgentHealth(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	
+	agentHealth := h.engine.GetAgentHealth(tenantID)
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agents": agentHealth,
+		"count":  len(agentHealth),
+	})
+}
+
+// ReleaseAgent lifts an agent's quarantine or restart backoff
+func (h *CognitiveHandler) ReleaseAgent(w http.ResponseWriter, r *http.Request) {
+	agentID := chi.URLParam(r, "agentID")
+	
+	if err := h.engine.ReleaseAgent(agentID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":  "Agent released successfully",
+		"agent_id": agentID,
+	})
+}
+
+// SetTenantBudget sets the resource budget of a tenant's agents
+func (h *CognitiveHandler) SetTenantBudget(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	
+	var req struct {
+		WindowSeconds int    `json:"window_seconds"`
+		MaxWallTimeMS int64  `json:"max_wall_time_ms"`
+		MaxIterations int64  `json:"max_iterations"`
+		Action        string `json:"action"`
+	}
+	
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Action == "" {
+		req.Action = string(budget.ActionDeprioritize)
+	}
+	
+	b := budget.Budget{
+		Window:        time.Duration(req.WindowSeconds) * time.Second,
+		MaxWallTime:   time.Duration(req.MaxWallTimeMS) * time.Millisecond,
+		MaxIterations: req.MaxIterations,
+		Action:        budget.Action(req.Action),
+	}
+	if err := h.engine.SetTenantBudget(tenantID, b); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.engine.GetTenantUsage(tenantID))
+}
+
+// RemoveTenantBudget lifts the resource budget of a tenant's agents
+func (h *CognitiveHandler) RemoveTenantBudget(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	
+	h.engine.RemoveTenantBudget(tenantID)
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Budget removed successfully",
+		"tenant_id": tenantID,
+	})
+}
+
+// GetTenantUsage returns a tenant's agent resource usage and budget
+func (h *CognitiveHandler) GetTenantUsage(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.engine.GetTenantUsage(tenantID))
+}
+
+// GetAgentDaemons returns the status of a tenant's daemon agents
+func (h *CognitiveHandler) GetAgentDaemons(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"daemons": h.engine.GetAgentDaemons(tenantID),
+	})
+}
+
+// GetAgentRunPlan returns the run groups of a tenant's agents
+func (h *CognitiveHandler) GetAgentRunPlan(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	
+	plan, exists := h.engine.GetAgentRunPlan(tenantID)
+	if !exists {
+		http.Error(w, "Run plan not found", http.StatusNotFound)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
+
+// SetAgentRunPlan orders a tenant's agents into run groups
+func (h *CognitiveHandler) SetAgentRunPlan(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	
+	var plan agents.RunPlan
+	if err := json.NewDecoder(r.Body).Decode(&plan); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	if err := h.engine.SetAgentRunPlan(tenantID, plan); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	plan, _ = h.engine.GetAgentRunPlan(tenantID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
+
+// RemoveAgentRunPlan restores priority ordering of a tenant's agents
+func (h *CognitiveHandler) RemoveAgentRunPlan(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	
+	h.engine.RemoveAgentRunPlan(tenantID)
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Run plan removed successfully",
+		"tenant_id": tenantID,
+	})
+}
+
 // GetAgent gets a specific agent
 func (h *CognitiveHandler) GetAgent(w http.ResponseWriter, r *http.Request) {
 	agentID := chi.URLParam(r, "agentID")
@@ -451,24 +1274,32 @@ func (h *CognitiveHandler) GetAgent(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(agent.GetStats())
 }
 
-// GetStats gets statistics for a tenant
+// GetStats gets statistics for a tenant. include and exclude list the
+// sections or dotted paths returned, e.g. include=sharding,agents
 func (h *CognitiveHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 	tenantID := chi.URLParam(r, "tenantID")
 	
-	stats := h.engine.GetStats(tenantID)
+	stats := h.engine.SelectStats(tenantID, statsSelection(r))
 	
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
 }
 
-// GetGlobalStats gets global statistics
+// GetGlobalStats gets global statistics, selected like GetStats
 func (h *CognitiveHandler) GetGlobalStats(w http.ResponseWriter, r *http.Request) {
-	stats := h.engine.GetStats("")
+	stats := h.engine.SelectStats("", statsSelection(r))
 	
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
 }
 
+// statsSelection reads the sections of statistics a request includes
+// and excludes
+func statsSelection(r *http.Request) statsview.Selection {
+	params := r.URL.Query()
+	return statsview.ParseSelection(params.Get("include"), params.Get("exclude"))
+}
+
 // Health returns health status
 func (h *CognitiveHandler) Health(w http.ResponseWriter, r *http.Request) {
 	health := h.engine.Health()
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/hygiene"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetHygiene returns a hygiene report of the tenant's atoms. The options
+// may be given as ?min_confidence=X&max_operations=N.
+func (h *CognitiveHandler) GetHygiene(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := hygiene.DefaultOptions()
+	if v := query.Get("min_confidence"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			http.Error(w, "invalid min_confidence", http.StatusBadRequest)
+			return
+		}
+		opts.MinConfidence = parsed
+	}
+	if v := query.Get("max_operations"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid max_operations", http.StatusBadRequest)
+			return
+		}
+		opts.MaxOperations = n
+	}
+
+	report, err := h.engine.AnalyzeHygiene(chi.URLParam(r, "tenantID"), opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// ApplyHygiene applies operations of the tenant's hygiene report, those
+// listed by ID or all of them, analyzed with the same options
+func (h *CognitiveHandler) ApplyHygiene(w http.ResponseWriter, r *http.Request) {
+	req := struct {
+		Operations []string `json:"operations"`
+		All        bool     `json:"all"`
+		hygiene.Options
+	}{Options: hygiene.DefaultOptions()}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Operations) == 0 && !req.All {
+		http.Error(w, "operations or all is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.engine.ApplyHygiene(chi.URLParam(r, "tenantID"), req.Operations, req.All, req.Options)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
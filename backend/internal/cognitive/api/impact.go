@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/impact"
+	"github.com/go-chi/chi/v5"
+)
+
+// AnalyzeImpact returns the atoms impacted by a change of an atom, e.g. a
+// node, a service or an availability zone attribute, ranked by confidence.
+// Impact propagates over the tenant's link types. Weights override their
+// weights or add relations propagating to dependents; relations replace
+// them entirely.
+func (h *CognitiveHandler) AnalyzeImpact(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		Atom          string                     `json:"atom"`
+		Depth         int                        `json:"depth"`
+		MinConfidence *float64                   `json:"min_confidence"`
+		Limit         int                        `json:"limit"`
+		Weights       map[string]float64         `json:"weights"`
+		Relations     map[string]impact.Relation `json:"relations"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts := impact.DefaultOptions()
+	opts.Relations = h.engine.ImpactRelations(tenantID)
+	if req.Depth != 0 {
+		opts.Depth = req.Depth
+	}
+	if req.MinConfidence != nil {
+		opts.MinConfidence = *req.MinConfidence
+	}
+	opts.Limit = req.Limit
+	if req.Relations != nil {
+		opts.Relations = req.Relations
+	}
+	for name, weight := range req.Weights {
+		relation, exists := opts.Relations[name]
+		if !exists {
+			relation.Direction = impact.Dependents
+		}
+		relation.Weight = weight
+		opts.Relations[name] = relation
+	}
+
+	result, err := h.engine.AnalyzeImpact(tenantID, req.Atom, opts)
+	if err != nil {
+		status := http.StatusBadRequest
+		if req.Atom != "" && opts.Validate() == nil {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"source":   result.Source,
+		"impacted": result.Impacted,
+		"count":    len(result.Impacted),
+	})
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/incidents"
+	"github.com/go-chi/chi/v5"
+)
+
+// IngestAlerts records alerts from a generic webhook and correlates them
+// into incidents
+func (h *CognitiveHandler) IngestAlerts(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		Alerts []incidents.Alert `json:"alerts"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.writeIngestedAlerts(w, r, tenantID, req.Alerts)
+}
+
+// IngestAlertmanager records alerts from an Alertmanager webhook payload
+func (h *CognitiveHandler) IngestAlertmanager(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	alerts, err := incidents.ParseAlertmanager(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.writeIngestedAlerts(w, r, tenantID, alerts)
+}
+
+func (h *CognitiveHandler) writeIngestedAlerts(w http.ResponseWriter, r *http.Request, tenantID string, alerts []incidents.Alert) {
+	result, err := h.engine.IngestAlerts(r.Context(), tenantID, alerts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"incidents": result,
+		"count":     len(result),
+	})
+}
+
+// ListIncidents returns a tenant's incidents, optionally filtered with
+// ?status=open|acknowledged|resolved
+func (h *CognitiveHandler) ListIncidents(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	status := incidents.Status(r.URL.Query().Get("status"))
+	switch status {
+	case "", incidents.StatusOpen, incidents.StatusAcknowledged, incidents.StatusResolved:
+	default:
+		http.Error(w, "unknown incident status: "+string(status), http.StatusBadRequest)
+		return
+	}
+
+	result := h.engine.ListIncidents(tenantID, status)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"incidents": result,
+		"count":     len(result),
+	})
+}
+
+// GetIncident returns an incident with its alerts and timeline
+func (h *CognitiveHandler) GetIncident(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	incidentID := chi.URLParam(r, "incidentID")
+
+	inc, err := h.engine.GetIncident(tenantID, incidentID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	alerts, err := h.engine.GetIncidentAlerts(tenantID, incidentID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"incident": inc,
+		"alerts":   alerts,
+	})
+}
+
+// GetIncidentTimeline returns the timeline of an incident
+func (h *CognitiveHandler) GetIncidentTimeline(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	incidentID := chi.URLParam(r, "incidentID")
+
+	inc, err := h.engine.GetIncident(tenantID, incidentID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"incident_id": inc.ID,
+		"timeline":    inc.Timeline,
+		"count":       len(inc.Timeline),
+	})
+}
+
+// AcknowledgeIncident marks an incident as being worked on
+func (h *CognitiveHandler) AcknowledgeIncident(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	incidentID := chi.URLParam(r, "incidentID")
+
+	var req struct {
+		By string `json:"by"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	inc, err := h.engine.AcknowledgeIncident(tenantID, incidentID, req.By)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(inc)
+}
+
+// ResolveIncident closes an incident
+func (h *CognitiveHandler) ResolveIncident(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	incidentID := chi.URLParam(r, "incidentID")
+
+	var req struct {
+		By   string `json:"by"`
+		Note string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	inc, err := h.engine.ResolveIncident(tenantID, incidentID, req.By, req.Note)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(inc)
+}
@@ -0,0 +1,20 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// CheckIntegrity scans the shards for inconsistent indices and dangling
+// links, changing nothing
+func (h *CognitiveHandler) CheckIntegrity(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.engine.CheckIntegrity(false))
+}
+
+// RepairIntegrity scans the shards and rebuilds the indices found
+// inconsistent, reporting what was corrected
+func (h *CognitiveHandler) RepairIntegrity(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.engine.CheckIntegrity(true))
+}
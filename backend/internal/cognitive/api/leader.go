@@ -0,0 +1,19 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// GetLeaderStatus returns whether this replica is the leader running
+// agents and billing samples
+func (h *CognitiveHandler) GetLeaderStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.engine.GetLeaderStatus()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/learning"
+	"github.com/go-chi/chi/v5"
+)
+
+// SubmitFeedback reports an outcome that adjusts agent priorities and rule
+// weights
+func (h *CognitiveHandler) SubmitFeedback(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var fb learning.Feedback
+	if err := json.NewDecoder(r.Body).Decode(&fb); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fb.TenantID = tenantID
+
+	adjustments, err := h.engine.Feedback(fb)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"outcome":     fb.Outcome,
+		"adjustments": adjustments,
+	})
+}
+
+// GetLearnedValues returns the learned values of a tenant's agents and rules
+func (h *CognitiveHandler) GetLearnedValues(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id": tenantID,
+		"arms":      h.engine.GetLearnedValues(tenantID),
+	})
+}
+
+// ResetLearning restores a tenant's configured priorities and rule weights
+func (h *CognitiveHandler) ResetLearning(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	h.engine.ResetLearning(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Learning reset successfully",
+		"tenant_id": tenantID,
+	})
+}
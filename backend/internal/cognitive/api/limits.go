@@ -0,0 +1,108 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// BodyLimits bounds the size of request bodies
+type BodyLimits struct {
+	Max    int64 // Largest body of most requests, in bytes
+	Import int64 // Largest body of imports, which are decoded as they stream in
+}
+
+// DefaultBodyLimits returns limits of 10 MiB, and 4 GiB for imports
+func DefaultBodyLimits() BodyLimits {
+	return BodyLimits{Max: 10 << 20, Import: 4 << 30}
+}
+
+// SetBodyLimits changes the request body limits; zero fields keep theirs
+func (h *CognitiveHandler) SetBodyLimits(limits BodyLimits) {
+	if limits.Max > 0 {
+		h.limits.Max = limits.Max
+	}
+	if limits.Import > 0 {
+		h.limits.Import = limits.Import
+	}
+}
+
+// limitedBody is a request body cut off at a limit. A body declared longer
+// fails on its first read, before anything is decoded.
+type limitedBody struct {
+	reader   io.ReadCloser
+	original io.ReadCloser
+	declared int64
+	limit    int64
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.declared > b.limit {
+		b.exceeded = true
+		return 0, &http.MaxBytesError{Limit: b.limit}
+	}
+	n, err := b.reader.Read(p)
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.reader.Close()
+}
+
+func (b *limitedBody) setLimit(w http.ResponseWriter, limit int64) {
+	b.limit = limit
+	b.reader = http.MaxBytesReader(w, b.original, limit)
+}
+
+// limitWriter answers 413 instead of 400 once the body was cut off, so
+// handlers need not tell a body too large from a malformed one
+type limitWriter struct {
+	http.ResponseWriter
+	body *limitedBody
+}
+
+func (lw *limitWriter) WriteHeader(status int) {
+	if status == http.StatusBadRequest && lw.body.exceeded {
+		status = http.StatusRequestEntityTooLarge
+	}
+	lw.ResponseWriter.WriteHeader(status)
+}
+
+func (lw *limitWriter) Flush() {
+	if f, ok := lw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (lw *limitWriter) Unwrap() http.ResponseWriter {
+	return lw.ResponseWriter
+}
+
+// limitBody cuts request bodies off at the general limit
+func (h *CognitiveHandler) limitBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body := &limitedBody{original: r.Body, declared: r.ContentLength}
+		body.setLimit(w, h.limits.Max)
+		r.Body = body
+		next.ServeHTTP(&limitWriter{ResponseWriter: w, body: body}, r)
+	})
+}
+
+// importBody lifts the limit of an import route's body to the import limit
+func (h *CognitiveHandler) importBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body, ok := r.Body.(*limitedBody); ok {
+			body.setLimit(w, h.limits.Import)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/linktypes"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListLinkTypes lists the semantics of a tenant's relations, defaults
+// included
+func (h *CognitiveHandler) ListLinkTypes(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	list := h.engine.ListLinkTypes(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id":  tenantID,
+		"link_types": list,
+		"count":      len(list),
+	})
+}
+
+// GetLinkType returns a link type
+func (h *CognitiveHandler) GetLinkType(w http.ResponseWriter, r *http.Request) {
+	lt, err := h.engine.GetLinkType(chi.URLParam(r, "tenantID"), chi.URLParam(r, "name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lt)
+}
+
+// SetLinkType creates or replaces a link type
+func (h *CognitiveHandler) SetLinkType(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var lt linktypes.LinkType
+	if err := json.NewDecoder(r.Body).Decode(&lt); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	lt.Name = chi.URLParam(r, "name")
+
+	lt, err := h.engine.SetLinkType(tenantID, lt)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lt)
+}
+
+// DeleteLinkType removes a tenant's link type, restoring the default
+func (h *CognitiveHandler) DeleteLinkType(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if err := h.engine.DeleteLinkType(chi.URLParam(r, "tenantID"), name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Link type deleted successfully",
+		"name":    name,
+	})
+}
+
+// FindPath returns the cheapest path between two atoms over the tenant's
+// relations, weighted by the traversal cost of their link types. Omitted
+// options keep their defaults.
+func (h *CognitiveHandler) FindPath(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	req := struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+		linktypes.PathOptions
+	}{PathOptions: linktypes.DefaultPathOptions()}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	path, err := h.engine.FindPath(tenantID, req.From, req.To, req.PathOptions)
+	if err != nil {
+		status := http.StatusBadRequest
+		if req.From != "" && req.To != "" && req.PathOptions.Validate() == nil {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(path)
+}
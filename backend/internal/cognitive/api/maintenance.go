@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/maintenance"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListMaintenanceWindows returns a tenant's maintenance and freeze windows
+func (h *CognitiveHandler) ListMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	list := h.engine.ListMaintenanceWindows(chi.URLParam(r, "tenantID"))
+	active := 0
+	for _, window := range list {
+		if window.State == maintenance.StateActive {
+			active++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"windows": list,
+		"count":   len(list),
+		"active":  active,
+	})
+}
+
+// AddMaintenanceWindow schedules a maintenance or freeze window
+func (h *CognitiveHandler) AddMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	var window maintenance.Window
+	if err := json.NewDecoder(r.Body).Decode(&window); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	window, err := h.engine.AddMaintenanceWindow(chi.URLParam(r, "tenantID"), window, acl.PrincipalFrom(r.Context()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(window)
+}
+
+// GetMaintenanceWindow returns a maintenance window
+func (h *CognitiveHandler) GetMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	window, err := h.engine.GetMaintenanceWindow(chi.URLParam(r, "tenantID"), chi.URLParam(r, "windowID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(window)
+}
+
+// DeleteMaintenanceWindow removes a maintenance window, ending it if active
+func (h *CognitiveHandler) DeleteMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "windowID")
+	if err := h.engine.DeleteMaintenanceWindow(chi.URLParam(r, "tenantID"), id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Maintenance window deleted successfully",
+		"id":      id,
+	})
+}
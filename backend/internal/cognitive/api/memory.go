@@ -0,0 +1,13 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// GetMemoryReport returns the engine's memory use, including the savings
+// of interned atom strings
+func (h *CognitiveHandler) GetMemoryReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.engine.MemoryReport())
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetMetering returns the billable usage of every tenant and the state of
+// the metering exporters
+func (h *CognitiveHandler) GetMetering(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.engine.MeteringReport())
+}
+
+// GetTenantMetering returns a tenant's billable usage since the engine
+// started
+func (h *CognitiveHandler) GetTenantMetering(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id": tenantID,
+		"totals":    h.engine.MeteringReport().Totals[tenantID],
+	})
+}
+
+// FlushMetering exports billable usage now rather than at the next interval
+func (h *CognitiveHandler) FlushMetering(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"exporters": h.engine.FlushMetering(r.Context()),
+	})
+}
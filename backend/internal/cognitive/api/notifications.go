@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/notifications"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListNotificationChannels returns a tenant's notification channels
+func (h *CognitiveHandler) ListNotificationChannels(w http.ResponseWriter, r *http.Request) {
+	list := h.engine.ListNotificationChannels(chi.URLParam(r, "tenantID"))
+	for i := range list {
+		list[i] = list[i].Redacted()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"channels": list,
+		"count":    len(list),
+		"topics":   notifications.Topics(),
+	})
+}
+
+// SetNotificationChannel creates or replaces a notification channel
+func (h *CognitiveHandler) SetNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	var channel notifications.Channel
+	if err := json.NewDecoder(r.Body).Decode(&channel); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	channel.Name = chi.URLParam(r, "name")
+
+	channel, err := h.engine.SetNotificationChannel(chi.URLParam(r, "tenantID"), channel)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(channel.Redacted())
+}
+
+// GetNotificationChannel returns a notification channel
+func (h *CognitiveHandler) GetNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	channel, err := h.engine.GetNotificationChannel(chi.URLParam(r, "tenantID"), chi.URLParam(r, "name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(channel.Redacted())
+}
+
+// DeleteNotificationChannel removes a notification channel
+func (h *CognitiveHandler) DeleteNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if err := h.engine.DeleteNotificationChannel(chi.URLParam(r, "tenantID"), name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Notification channel deleted successfully",
+		"name":    name,
+	})
+}
+
+// TestNotificationChannel sends a test notification to a channel
+func (h *CognitiveHandler) TestNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	delivery, err := h.engine.TestNotificationChannel(r.Context(), chi.URLParam(r, "tenantID"), chi.URLParam(r, "name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// GetNotificationDeliveries returns the latest notifications sent to a
+// tenant's channels
+func (h *CognitiveHandler) GetNotificationDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries := h.engine.GetNotificationDeliveries(chi.URLParam(r, "tenantID"))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"deliveries": deliveries,
+		"count":      len(deliveries),
+	})
+}
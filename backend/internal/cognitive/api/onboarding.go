@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/onboarding"
+	"github.com/go-chi/chi/v5"
+)
+
+// onboardingRequest is the optional body of a tenant initialization: the
+// name of a registered template, or a template described inline
+type onboardingRequest struct {
+	Template     string               `json:"template"`
+	TemplateSpec *onboarding.Template `json:"template_spec"`
+}
+
+// decodeOnboardingRequest decodes the body of a tenant initialization. An
+// empty body initializes the tenant without a template.
+func decodeOnboardingRequest(r *http.Request) (onboardingRequest, error) {
+	var req onboardingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		return req, err
+	}
+	if req.Template != "" && req.TemplateSpec != nil {
+		return req, fmt.Errorf("template and template_spec are mutually exclusive")
+	}
+	return req, nil
+}
+
+// onboardTenant initializes a tenant as requested, returning what
+// onboarding created, or nil without a template
+func (h *CognitiveHandler) onboardTenant(tenantID string, req onboardingRequest) (*onboarding.Result, error) {
+	switch {
+	case req.Template != "":
+		return h.engine.InitializeTenantFromTemplate(tenantID, req.Template)
+	case req.TemplateSpec != nil:
+		return h.engine.OnboardTenant(tenantID, *req.TemplateSpec)
+	default:
+		return nil, h.engine.InitializeTenant(tenantID)
+	}
+}
+
+// ListTemplates lists the onboarding templates
+func (h *CognitiveHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	templates := h.engine.ListTemplates()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+// GetTemplate returns an onboarding template
+func (h *CognitiveHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	t, err := h.engine.GetTemplate(chi.URLParam(r, "name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// SetTemplate creates or replaces an onboarding template
+func (h *CognitiveHandler) SetTemplate(w http.ResponseWriter, r *http.Request) {
+	var t onboarding.Template
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	t.Name = chi.URLParam(r, "name")
+
+	t, err := h.engine.SetTemplate(t)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// DeleteTemplate removes an onboarding template
+func (h *CognitiveHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if err := h.engine.DeleteTemplate(name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Template deleted successfully",
+		"name":    name,
+	})
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/orgs"
+	"github.com/go-chi/chi/v5"
+)
+
+// KeyHeader carries an organization's API key
+const KeyHeader = "X-Erebus-Key"
+
+// authenticateKey replaces the principal of requests carrying an API key
+// with the key's. A key only reaches its organization and the organization's
+// projects; requests with an unknown key are refused.
+func (h *CognitiveHandler) authenticateKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret := r.Header.Get(KeyHeader)
+		if secret == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key, ok := h.engine.AuthenticateAPIKey(secret)
+		if !ok {
+			http.Error(w, "invalid API key", http.StatusUnauthorized)
+			return
+		}
+
+		// Routes are not matched yet, so the scope is read from the path
+		segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/cognitive"), "/"), "/")
+		reached := false
+		if len(segments) >= 2 {
+			switch segments[0] {
+			case "orgs":
+				reached = segments[1] == key.OrgID
+			case "tenants":
+				reached = h.engine.APIKeyReaches(key.OrgID, segments[1])
+			}
+		}
+		if !reached {
+			http.Error(w, fmt.Sprintf("API key of organization %s does not reach %s", key.OrgID, r.URL.Path), http.StatusForbidden)
+			return
+		}
+
+		p := acl.Principal{User: "key/" + key.ID, Roles: key.Roles}
+		next.ServeHTTP(w, r.WithContext(acl.WithPrincipal(r.Context(), p)))
+	})
+}
+
+// ListOrganizations returns the organizations
+func (h *CognitiveHandler) ListOrganizations(w http.ResponseWriter, r *http.Request) {
+	list := h.engine.ListOrganizations()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"organizations": list,
+		"count":         len(list),
+	})
+}
+
+// GetOrganization returns an organization
+func (h *CognitiveHandler) GetOrganization(w http.ResponseWriter, r *http.Request) {
+	o, err := h.engine.GetOrganization(chi.URLParam(r, "orgID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(o)
+}
+
+// SetOrganization creates or replaces an organization's settings and
+// passes them on to its projects
+func (h *CognitiveHandler) SetOrganization(w http.ResponseWriter, r *http.Request) {
+	var o orgs.Organization
+	if err := json.NewDecoder(r.Body).Decode(&o); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	o.ID = chi.URLParam(r, "orgID")
+
+	set, err := h.engine.SetOrganization(o)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(set)
+}
+
+// DeleteOrganization removes an organization, detaching its projects
+func (h *CognitiveHandler) DeleteOrganization(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+
+	if err := h.engine.DeleteOrganization(orgID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Organization deleted successfully",
+		"org_id":  orgID,
+	})
+}
+
+// AddProject makes a tenant a project of an organization
+func (h *CognitiveHandler) AddProject(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.AddProject(orgID, tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	o, inherited, err := h.engine.GetTenantOrganization(tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"organization": o,
+		"inherited":    inherited,
+	})
+}
+
+// RemoveProject detaches a project from its organization
+func (h *CognitiveHandler) RemoveProject(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.RemoveProject(orgID, tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Project removed successfully",
+		"org_id":    orgID,
+		"tenant_id": tenantID,
+	})
+}
+
+// GetTenantOrganization returns the organization of a project and what the
+// project inherited from it
+func (h *CognitiveHandler) GetTenantOrganization(w http.ResponseWriter, r *http.Request) {
+	o, inherited, err := h.engine.GetTenantOrganization(chi.URLParam(r, "tenantID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"organization": o,
+		"inherited":    inherited,
+	})
+}
+
+// GetOrganizationStats returns the activity of an organization's projects
+// and their sum
+func (h *CognitiveHandler) GetOrganizationStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.engine.GetOrganizationStats(chi.URLParam(r, "orgID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// ListAPIKeys returns an organization's API keys, without their secrets
+func (h *CognitiveHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.engine.ListAPIKeys(chi.URLParam(r, "orgID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys":  keys,
+		"count": len(keys),
+	})
+}
+
+// CreateAPIKey creates an API key of an organization. The secret is only
+// returned in this response.
+func (h *CognitiveHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name  string   `json:"name"`
+		Roles []string `json:"roles"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	key, secret, err := h.engine.CreateAPIKey(chi.URLParam(r, "orgID"), req.Name, req.Roles)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"key":    key,
+		"secret": secret,
+	})
+}
+
+// RevokeAPIKey deletes an API key of an organization
+func (h *CognitiveHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID := chi.URLParam(r, "keyID")
+
+	if err := h.engine.RevokeAPIKey(chi.URLParam(r, "orgID"), keyID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "API key revoked successfully",
+		"key_id":  keyID,
+	})
+}
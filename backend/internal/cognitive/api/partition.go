@@ -0,0 +1,19 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// GetPartitionReport returns the replicas agents are spread across and
+// where each agent runs
+func (h *CognitiveHandler) GetPartitionReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.engine.GetPartitionReport()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
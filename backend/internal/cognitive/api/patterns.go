@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetPatterns returns the patterns found by a tenant's pattern miner
+func (h *CognitiveHandler) GetPatterns(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	patterns, err := h.engine.GetPatterns(tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"patterns": patterns,
+		"count":    len(patterns),
+	})
+}
+
+// MinePatterns mines a tenant's AtomSpace for patterns immediately
+func (h *CognitiveHandler) MinePatterns(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	patterns, err := h.engine.MinePatterns(r.Context(), tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"patterns": patterns,
+		"count":    len(patterns),
+	})
+}
+
+// ConfigurePatternMiner enables pattern mining for a tenant or updates its
+// configuration
+func (h *CognitiveHandler) ConfigurePatternMiner(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		MinSupport      int     `json:"min_support"`
+		MinConfidence   float64 `json:"min_confidence"`
+		MaxPatterns     int     `json:"max_patterns"`
+		IntervalSeconds int     `json:"interval_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.MinConfidence < 0 || req.MinConfidence > 1 {
+		http.Error(w, "min_confidence must be between 0 and 1", http.StatusBadRequest)
+		return
+	}
+
+	config := agents.DefaultPatternMinerConfig()
+	if req.MinSupport > 0 {
+		config.MinSupport = req.MinSupport
+	}
+	if req.MinConfidence > 0 {
+		config.MinConfidence = req.MinConfidence
+	}
+	if req.MaxPatterns > 0 {
+		config.MaxPatterns = req.MaxPatterns
+	}
+	if req.IntervalSeconds > 0 {
+		config.Interval = time.Duration(req.IntervalSeconds) * time.Second
+	}
+
+	miner := h.engine.EnablePatternMining(tenantID, config)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agent_id": miner.GetID(),
+		"config":   miner.GetConfig(),
+	})
+}
+
+// DisablePatternMiner stops pattern mining for a tenant
+func (h *CognitiveHandler) DisablePatternMiner(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.DisablePatternMining(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Pattern mining disabled successfully",
+		"tenant_id": tenantID,
+	})
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sharding"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetPlacement returns the shards a tenant is pinned to
+func (h *CognitiveHandler) GetPlacement(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	p, pinned := h.engine.GetPlacement(tenantID)
+	if !pinned {
+		http.Error(w, "tenant is not pinned to shards", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// SetPlacement pins a tenant to a set of shards, optionally dedicated to
+// it, and moves its atoms there
+func (h *CognitiveHandler) SetPlacement(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		Shards    []int `json:"shards"`
+		Dedicated bool  `json:"dedicated"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p := sharding.Placement{TenantID: tenantID, Shards: req.Shards, Dedicated: req.Dedicated}
+	moved, err := h.engine.PinTenant(p)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, sharding.ErrMigrating) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	p, _ = h.engine.GetPlacement(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"placement":   p,
+		"atoms_moved": moved,
+	})
+}
+
+// RemovePlacement returns a tenant to the shards shared by all tenants
+func (h *CognitiveHandler) RemovePlacement(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	moved, err := h.engine.UnpinTenant(tenantID)
+	if err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, sharding.ErrMigrating) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":     "Placement removed successfully",
+		"tenant_id":   tenantID,
+		"atoms_moved": moved,
+	})
+}
+
+// GetShards returns the recent operation rate of each shard, flagging hot
+// shards and their busiest tenants, with the placements of pinned tenants
+// and the progress of the last shard count change
+func (h *CognitiveHandler) GetShards(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"num_shards": h.engine.NumShards(),
+		"shards":     h.engine.HotShards(),
+		"placements": h.engine.ListPlacements(),
+		"migration":  h.engine.ShardMigration(),
+	})
+}
+
+// ResizeShards changes the number of shards. Atoms move in the background;
+// the response is the migration just started.
+func (h *CognitiveHandler) ResizeShards(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		NumShards int `json:"num_shards"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.engine.ResizeShards(req.NumShards); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, sharding.ErrMigrating) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(h.engine.ShardMigration())
+}
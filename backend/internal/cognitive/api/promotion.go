@@ -0,0 +1,184 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/promotion"
+	"github.com/go-chi/chi/v5"
+)
+
+// ExportBundle exports a tenant's artifacts as a new bundle version
+func (h *CognitiveHandler) ExportBundle(w http.ResponseWriter, r *http.Request) {
+	b, err := h.engine.ExportBundle(chi.URLParam(r, "tenantID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(b)
+}
+
+// ListBundles lists the bundles exported from a tenant
+func (h *CognitiveHandler) ListBundles(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	bundles := h.engine.ListBundles(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id": tenantID,
+		"bundles":   bundles,
+		"count":     len(bundles),
+	})
+}
+
+// GetBundle returns a bundle exported from a tenant, ready to be imported
+// elsewhere
+func (h *CognitiveHandler) GetBundle(w http.ResponseWriter, r *http.Request) {
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil || version < 1 {
+		http.Error(w, "version must be a positive integer", http.StatusBadRequest)
+		return
+	}
+
+	b, err := h.engine.GetBundle(chi.URLParam(r, "tenantID"), version)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(b)
+}
+
+// ImportBundle applies a bundle to a tenant
+func (h *CognitiveHandler) ImportBundle(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var b promotion.Bundle
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	applied, err := h.engine.ImportBundle(tenantID, b)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id": tenantID,
+		"source":    b.TenantID,
+		"version":   b.Version,
+		"checksum":  b.Checksum,
+		"applied":   applied,
+	})
+}
+
+// ListPromotionTracks lists the promotion tracks
+func (h *CognitiveHandler) ListPromotionTracks(w http.ResponseWriter, r *http.Request) {
+	tracks := h.engine.ListPromotionTracks()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tracks": tracks,
+		"count":  len(tracks),
+	})
+}
+
+// GetPromotionTrack returns a promotion track and the bundle each of its
+// environments last received
+func (h *CognitiveHandler) GetPromotionTrack(w http.ResponseWriter, r *http.Request) {
+	status, err := h.engine.GetPromotionTrack(chi.URLParam(r, "name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// SetPromotionTrack creates or replaces a promotion track
+func (h *CognitiveHandler) SetPromotionTrack(w http.ResponseWriter, r *http.Request) {
+	var t promotion.Track
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	t.Name = chi.URLParam(r, "name")
+
+	t, err := h.engine.SetPromotionTrack(t)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// DeletePromotionTrack removes a promotion track
+func (h *CognitiveHandler) DeletePromotionTrack(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if err := h.engine.DeletePromotionTrack(name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Track deleted successfully",
+		"name":    name,
+	})
+}
+
+// Promote promotes an environment's artifacts to the next environment of
+// the track
+func (h *CognitiveHandler) Promote(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		From    string `json:"from"`
+		Version int    `json:"version"` // Bundle of the source tenant; 0 exports a new one
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.From == "" {
+		http.Error(w, "from is required", http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.engine.Promote(chi.URLParam(r, "name"), req.From, req.Version)
+	if err != nil {
+		status := http.StatusBadRequest
+		if p.ID != "" {
+			// The bundle was chosen but could not be applied
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// GetPromotions lists the promotions of a track
+func (h *CognitiveHandler) GetPromotions(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	history := h.engine.GetPromotions(name)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"track":      name,
+		"promotions": history,
+		"count":      len(history),
+	})
+}
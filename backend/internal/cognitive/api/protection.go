@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListProtectedAtoms returns the IDs of a tenant's protected atoms
+func (h *CognitiveHandler) ListProtectedAtoms(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	atoms := h.engine.ListProtectedAtoms(tenantID)
+	ids := make([]string, 0, len(atoms))
+	for _, atom := range atoms {
+		ids = append(ids, atom.GetID())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"atom_ids": ids,
+		"count":    len(ids),
+	})
+}
+
+// GetAtomProtection reports whether an atom is protected
+func (h *CognitiveHandler) GetAtomProtection(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	atomID := chi.URLParam(r, "atomID")
+
+	atom, err := h.engine.GetAtom(atomID, tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"atom_id":   atomID,
+		"protected": atomspace.IsProtected(atom),
+	})
+}
+
+// ProtectAtom protects an atom from agents, decay and API changes
+func (h *CognitiveHandler) ProtectAtom(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	atomID := chi.URLParam(r, "atomID")
+
+	if err := h.engine.ProtectAtom(tenantID, atomID); err != nil {
+		writeError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"atom_id":   atomID,
+		"protected": true,
+	})
+}
+
+// UnprotectAtom lifts the protection of an atom. Only admins may.
+func (h *CognitiveHandler) UnprotectAtom(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	atomID := chi.URLParam(r, "atomID")
+
+	if err := h.engine.UnprotectAtom(tenantID, atomID, acl.PrincipalFrom(r.Context())); err != nil {
+		writeError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"atom_id":   atomID,
+		"protected": false,
+	})
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/queries"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListSavedQueries lists a tenant's saved queries
+func (h *CognitiveHandler) ListSavedQueries(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	list := h.engine.ListSavedQueries(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id": tenantID,
+		"queries":   list,
+		"count":     len(list),
+	})
+}
+
+// GetSavedQuery returns a saved query
+func (h *CognitiveHandler) GetSavedQuery(w http.ResponseWriter, r *http.Request) {
+	q, err := h.engine.GetSavedQuery(chi.URLParam(r, "tenantID"), chi.URLParam(r, "name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(q)
+}
+
+// SetSavedQuery creates or replaces a saved query
+func (h *CognitiveHandler) SetSavedQuery(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var q queries.Query
+	if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.Name = chi.URLParam(r, "name")
+
+	q, err := h.engine.SetSavedQuery(tenantID, q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(q)
+}
+
+// DeleteSavedQuery removes a saved query
+func (h *CognitiveHandler) DeleteSavedQuery(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if err := h.engine.DeleteSavedQuery(chi.URLParam(r, "tenantID"), name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Query deleted successfully",
+		"name":    name,
+	})
+}
+
+// RunSavedQuery runs a saved query, as consistent as the request's
+// consistency hint demands
+func (h *CognitiveHandler) RunSavedQuery(w http.ResponseWriter, r *http.Request) {
+	consistency, err := readConsistency(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	atoms, plans, err := h.engine.Reader(consistency).RunSavedQuery(chi.URLParam(r, "tenantID"), chi.URLParam(r, "name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	response := map[string]interface{}{
+		"atoms": atomResults(atoms),
+		"count": len(atoms),
+	}
+	if r.URL.Query().Get("explain") == "true" && plans != nil {
+		response["plan"] = plans
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/recommendations"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListRecommendations returns a tenant's recommendations, optionally only
+// those with a status
+func (h *CognitiveHandler) ListRecommendations(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	status, err := recommendations.ParseStatus(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	recs := h.engine.ListRecommendations(tenantID, status)
+
+	savings := 0.0
+	for _, rec := range recs {
+		savings += rec.MonthlySavings
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"recommendations": recs,
+		"count":           len(recs),
+		"monthly_savings": savings,
+	})
+}
+
+// GetRecommendation returns a recommendation
+func (h *CognitiveHandler) GetRecommendation(w http.ResponseWriter, r *http.Request) {
+	rec, err := h.engine.GetRecommendation(chi.URLParam(r, "tenantID"), chi.URLParam(r, "recommendationID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec)
+}
+
+// RunRecommendations reviews a tenant's resources immediately
+func (h *CognitiveHandler) RunRecommendations(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	recs, err := h.engine.RunRecommendations(r.Context(), tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"recommendations": recs,
+		"count":           len(recs),
+	})
+}
+
+// ConfigureRecommendations enables the recommendation agent for a tenant or
+// updates its configuration
+func (h *CognitiveHandler) ConfigureRecommendations(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		UtilizationPredicates []string         `json:"utilization_predicates"`
+		ResizeBelow           float64          `json:"resize_below"`
+		ScaleAbove            float64          `json:"scale_above"`
+		TargetUtilization     float64          `json:"target_utilization"`
+		HostRelation          string           `json:"host_relation"`
+		MinConfidence         float64          `json:"min_confidence"`
+		SubmitAbove           float64          `json:"submit_above"`
+		OnApproval            *triggers.Action `json:"on_approval"`
+		IntervalSeconds       int              `json:"interval_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, v := range []float64{req.ResizeBelow, req.ScaleAbove, req.TargetUtilization, req.MinConfidence, req.SubmitAbove} {
+		if v < 0 || v > 1 {
+			http.Error(w, "thresholds must be between 0 and 1", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.OnApproval != nil {
+		if err := req.OnApproval.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	config := agents.DefaultRecommendationConfig()
+	if len(req.UtilizationPredicates) > 0 {
+		config.UtilizationPredicates = req.UtilizationPredicates
+	}
+	if req.ResizeBelow > 0 {
+		config.ResizeBelow = req.ResizeBelow
+	}
+	if req.ScaleAbove > 0 {
+		config.ScaleAbove = req.ScaleAbove
+	}
+	if req.TargetUtilization > 0 {
+		config.TargetUtilization = req.TargetUtilization
+	}
+	if req.HostRelation != "" {
+		config.HostRelation = req.HostRelation
+	}
+	if req.MinConfidence > 0 {
+		config.MinConfidence = req.MinConfidence
+	}
+	config.SubmitAbove = req.SubmitAbove
+	config.OnApproval = req.OnApproval
+	if req.IntervalSeconds > 0 {
+		config.Interval = time.Duration(req.IntervalSeconds) * time.Second
+	}
+	if config.ResizeBelow >= config.TargetUtilization || config.ScaleAbove <= config.TargetUtilization {
+		http.Error(w, "target_utilization must lie between resize_below and scale_above", http.StatusBadRequest)
+		return
+	}
+
+	agent := h.engine.EnableRecommendations(tenantID, config)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agent_id": agent.GetID(),
+		"config":   agent.GetConfig(),
+	})
+}
+
+// DisableRecommendations stops the recommendation agent of a tenant
+func (h *CognitiveHandler) DisableRecommendations(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.DisableRecommendations(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Recommendation agent disabled successfully",
+		"tenant_id": tenantID,
+	})
+}
+
+// SubmitRecommendation puts a recommendation in the approval queue
+func (h *CognitiveHandler) SubmitRecommendation(w http.ResponseWriter, r *http.Request) {
+	rec, err := h.engine.SubmitRecommendation(chi.URLParam(r, "tenantID"), chi.URLParam(r, "recommendationID"), acl.PrincipalFrom(r.Context()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec)
+}
+
+// ListApprovals returns the recommendations waiting for approval
+func (h *CognitiveHandler) ListApprovals(w http.ResponseWriter, r *http.Request) {
+	recs := h.engine.ListRecommendations(chi.URLParam(r, "tenantID"), recommendations.StatusPending)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"approvals": recs,
+		"count":     len(recs),
+	})
+}
+
+// DecideApproval approves or rejects a recommendation waiting for approval
+func (h *CognitiveHandler) DecideApproval(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Approve bool   `json:"approve"`
+		Note    string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rec, err := h.engine.DecideRecommendation(r.Context(), chi.URLParam(r, "tenantID"), chi.URLParam(r, "recommendationID"), req.Approve, req.Note, acl.PrincipalFrom(r.Context()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec)
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/reports"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListReports returns a tenant's report schedules
+func (h *CognitiveHandler) ListReports(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	list := h.engine.ListReports(tenantID)
+	for i := range list {
+		list[i] = list[i].Redacted()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"reports": list,
+		"count":   len(list),
+	})
+}
+
+// SetReport creates or replaces a report schedule
+func (h *CognitiveHandler) SetReport(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	var schedule reports.Schedule
+	if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	schedule.Name = name
+
+	schedule, err := h.engine.SetReport(tenantID, schedule)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schedule.Redacted())
+}
+
+// GetReport returns a report schedule
+func (h *CognitiveHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	schedule, err := h.engine.GetReport(tenantID, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schedule.Redacted())
+}
+
+// DeleteReport removes a report schedule and its reports
+func (h *CognitiveHandler) DeleteReport(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	if err := h.engine.DeleteReport(tenantID, name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Report deleted successfully",
+		"name":    name,
+	})
+}
+
+// GenerateReport generates and delivers a report immediately
+func (h *CognitiveHandler) GenerateReport(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	if _, err := h.engine.GetReport(tenantID, name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	report, err := h.engine.GenerateReport(r.Context(), tenantID, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetReportHistory returns the reports kept for a schedule
+func (h *CognitiveHandler) GetReportHistory(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	history, err := h.engine.GetReportHistory(tenantID, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"reports": history,
+		"count":   len(history),
+	})
+}
+
+// GetGeneratedReport returns the rendering of a generated report, the
+// latest one unless a sequence number is given. The format query parameter
+// renders it in another format than the schedule's.
+func (h *CognitiveHandler) GetGeneratedReport(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	sequence := 0
+	if s := chi.URLParam(r, "sequence"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid sequence number", http.StatusBadRequest)
+			return
+		}
+		sequence = n
+	}
+
+	report, err := h.engine.GetGeneratedReport(tenantID, name, sequence)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	content := report.Content
+	format := report.Format
+	if f := reports.Format(r.URL.Query().Get("format")); f != "" && f != format {
+		if content, err = reports.Render(report.Digest, f); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		format = f
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Write([]byte(content))
+}
+
+// ConfigureReports enables the report agent for a tenant or updates its
+// configuration
+func (h *CognitiveHandler) ConfigureReports(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		IntervalSeconds int `json:"interval_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	config := agents.DefaultReportConfig()
+	if req.IntervalSeconds > 0 {
+		config.Interval = time.Duration(req.IntervalSeconds) * time.Second
+	}
+
+	agent := h.engine.EnableReports(tenantID, config)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agent_id": agent.GetID(),
+		"config":   agent.GetConfig(),
+	})
+}
+
+// DisableReports stops the report agent of a tenant
+func (h *CognitiveHandler) DisableReports(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.DisableReports(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Report agent disabled successfully",
+		"tenant_id": tenantID,
+	})
+}
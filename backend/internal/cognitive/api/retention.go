@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetValueLogRetention returns how long a tenant's values stay in the value
+// logs once a periodic snapshot holds them
+func (h *CognitiveHandler) GetValueLogRetention(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	retention, err := h.engine.GetValueLogRetention(tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id": tenantID,
+		"retention": retention.String(),
+	})
+}
+
+// SetValueLogRetention sets how long a tenant's values stay in the value
+// logs once a periodic snapshot holds them, as a duration such as "24h"
+func (h *CognitiveHandler) SetValueLogRetention(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		Retention string `json:"retention"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	retention, err := time.ParseDuration(req.Retention)
+	if err != nil || retention < 0 {
+		http.Error(w, "retention must be a non-negative duration", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.engine.SetValueLogRetention(tenantID, retention); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id": tenantID,
+		"retention": retention.String(),
+	})
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// AnalyzeRules reports overlapping, looping and unreachable rules of the
+// tenant, or of the comma-separated built-in rules in the rules parameter
+func (h *CognitiveHandler) AnalyzeRules(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var names []string
+	if rules := r.URL.Query().Get("rules"); rules != "" {
+		names = strings.Split(rules, ",")
+	}
+
+	analysis, err := h.engine.AnalyzeRules(tenantID, names)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analysis)
+}
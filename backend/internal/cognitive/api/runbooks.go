@@ -0,0 +1,197 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/runbooks"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListRunbooks returns a tenant's runbooks
+func (h *CognitiveHandler) ListRunbooks(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	list := h.engine.ListRunbooks(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"runbooks": list,
+		"count":    len(list),
+	})
+}
+
+// LoadRunbooks sets one runbook or a list of runbooks from the request body
+func (h *CognitiveHandler) LoadRunbooks(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	loaded, err := h.engine.LoadRunbooks(tenantID, data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"runbooks": loaded,
+		"count":    len(loaded),
+	})
+}
+
+// SetRunbook creates or replaces a runbook
+func (h *CognitiveHandler) SetRunbook(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	var rb runbooks.Runbook
+	if err := json.NewDecoder(r.Body).Decode(&rb); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rb.Name = name
+
+	rb, err := h.engine.SetRunbook(tenantID, rb)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rb)
+}
+
+// GetRunbook returns a runbook
+func (h *CognitiveHandler) GetRunbook(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	rb, err := h.engine.GetRunbook(tenantID, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rb)
+}
+
+// DeleteRunbook removes a runbook
+func (h *CognitiveHandler) DeleteRunbook(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	if err := h.engine.DeleteRunbook(tenantID, name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Runbook deleted successfully",
+		"name":    name,
+	})
+}
+
+// ApplyRunbooks matches active incidents to runbooks immediately
+func (h *CognitiveHandler) ApplyRunbooks(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	runs, err := h.engine.ApplyRunbooks(r.Context(), tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"runs":  runs,
+		"count": len(runs),
+	})
+}
+
+// ExecuteRunbook performs a runbook's steps on an incident
+func (h *CognitiveHandler) ExecuteRunbook(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	incidentID := chi.URLParam(r, "incidentID")
+	name := chi.URLParam(r, "name")
+
+	run, err := h.engine.ExecuteRunbook(r.Context(), tenantID, name, incidentID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// GetRunbookRuns returns the run history of a tenant's runbook agent
+func (h *CognitiveHandler) GetRunbookRuns(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	runs := h.engine.GetRunbookRuns(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"runs":  runs,
+		"count": len(runs),
+	})
+}
+
+// ConfigureRunbooks enables the runbook agent for a tenant or updates its
+// configuration
+func (h *CognitiveHandler) ConfigureRunbooks(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		DryRun          bool `json:"dry_run"`
+		MaxRuns         int  `json:"max_runs"`
+		IntervalSeconds int  `json:"interval_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	config := agents.DefaultRunbookConfig()
+	config.DryRun = req.DryRun
+	if req.MaxRuns > 0 {
+		config.MaxRuns = req.MaxRuns
+	}
+	if req.IntervalSeconds > 0 {
+		config.Interval = time.Duration(req.IntervalSeconds) * time.Second
+	}
+
+	agent := h.engine.EnableRunbooks(tenantID, config)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agent_id": agent.GetID(),
+		"config":   agent.GetConfig(),
+	})
+}
+
+// DisableRunbooks stops the runbook agent of a tenant
+func (h *CognitiveHandler) DisableRunbooks(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.DisableRunbooks(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Runbook agent disabled successfully",
+		"tenant_id": tenantID,
+	})
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sandbox"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetSandbox returns the tenant's sandbox config and canned data
+func (h *CognitiveHandler) GetSandbox(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	config, err := h.engine.GetSandbox(tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(config)
+}
+
+// SetSandbox switches the tenant's connectors and actions to canned data,
+// or back to real systems with "enabled": false
+func (h *CognitiveHandler) SetSandbox(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var config sandbox.Config
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	config, err := h.engine.SetSandbox(tenantID, config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(config)
+}
+
+// RemoveSandbox takes the tenant out of sandbox mode, dropping its canned
+// data and recorded requests
+func (h *CognitiveHandler) RemoveSandbox(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.RemoveSandbox(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Sandbox removed successfully",
+	})
+}
+
+// GetSandboxRequests returns the requests the tenant's sandbox answered
+// instead of its external systems, oldest first
+func (h *CognitiveHandler) GetSandboxRequests(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	requests := h.engine.SandboxRequests(tenantID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"requests": requests,
+		"count":    len(requests),
+		"enabled":  h.engine.SandboxEnabled(tenantID),
+	})
+}
+
+// ClearSandboxRequests forgets the requests the tenant's sandbox recorded
+func (h *CognitiveHandler) ClearSandboxRequests(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	cleared := h.engine.ClearSandboxRequests(tenantID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"cleared": cleared,
+	})
+}
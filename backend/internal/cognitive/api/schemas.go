@@ -0,0 +1,134 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListSchemas returns the grounded schemas a tenant's atoms may call
+func (h *CognitiveHandler) ListSchemas(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	list := h.engine.ListSchemas(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"schemas": list,
+		"count":   len(list),
+	})
+}
+
+// CreateExecution adds a call of a grounded schema for the execution agent
+// to execute
+func (h *CognitiveHandler) CreateExecution(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		Schema    string                       `json:"schema"`
+		Arguments []cognitive.GroundedArgument `json:"arguments"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	atom, err := h.engine.AddExecution(tenantID, req.Schema, req.Arguments)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(atomResults([]atomspace.Atom{atom})[0])
+}
+
+// ExecuteSchemas executes a tenant's pending calls immediately
+func (h *CognitiveHandler) ExecuteSchemas(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	results, err := h.engine.ExecuteSchemas(r.Context(), tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"results": results,
+		"count":   len(results),
+	})
+}
+
+// GetExecutionResults returns the results of a tenant's recent calls
+func (h *CognitiveHandler) GetExecutionResults(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	results := h.engine.GetExecutionResults(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"results": results,
+		"count":   len(results),
+	})
+}
+
+// ConfigureExecution enables the execution agent for a tenant or updates
+// its configuration
+func (h *CognitiveHandler) ConfigureExecution(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		MaxCalls        int `json:"max_calls"`
+		MaxAttempts     int `json:"max_attempts"`
+		MaxResults      int `json:"max_results"`
+		IntervalSeconds int `json:"interval_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	config := agents.DefaultExecutionConfig()
+	if req.MaxCalls > 0 {
+		config.MaxCalls = req.MaxCalls
+	}
+	if req.MaxAttempts > 0 {
+		config.MaxAttempts = req.MaxAttempts
+	}
+	if req.MaxResults > 0 {
+		config.MaxResults = req.MaxResults
+	}
+	if req.IntervalSeconds > 0 {
+		config.Interval = time.Duration(req.IntervalSeconds) * time.Second
+	}
+
+	agent := h.engine.EnableExecution(tenantID, config)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agent_id": agent.GetID(),
+		"config":   agent.GetConfig(),
+	})
+}
+
+// DisableExecution stops the execution agent of a tenant
+func (h *CognitiveHandler) DisableExecution(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.DisableExecution(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Execution agent disabled successfully",
+		"tenant_id": tenantID,
+	})
+}
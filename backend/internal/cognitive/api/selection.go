@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetRuleSelection returns a tenant's rule selection strategy and the yield
+// of each rule
+func (h *CognitiveHandler) GetRuleSelection(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	config, yields, err := h.engine.GetRuleSelection(tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"config": config,
+		"yields": yields,
+	})
+}
+
+// SetRuleSelection replaces a tenant's rule selection strategy. Omitted
+// fields take their default values.
+func (h *CognitiveHandler) SetRuleSelection(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	config := inference.DefaultSelectionConfig()
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := config.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.engine.SetRuleSelection(tenantID, config); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id": tenantID,
+		"config":    config,
+	})
+}
+
+// GetInferencePipelining returns whether a tenant's inference iterations
+// overlap and the conflicts resolved when merging their results
+func (h *CognitiveHandler) GetInferencePipelining(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	pipelined, merges, err := h.engine.GetInferencePipelining(tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"pipelined": pipelined,
+		"merges":    merges,
+	})
+}
+
+// SetInferencePipelining enables or disables overlapping inference
+// iterations for a tenant
+func (h *CognitiveHandler) SetInferencePipelining(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		Pipelined bool `json:"pipelined"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.engine.SetInferencePipelining(tenantID, req.Pipelined); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id": tenantID,
+		"pipelined": req.Pipelined,
+	})
+}
+
+// GetInferenceLimits returns the derivation limits of the tenant's
+// inference runs and how the last run ended
+func (h *CognitiveHandler) GetInferenceLimits(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	limits, lastRun, err := h.engine.GetInferenceLimits(tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"limits":   limits,
+		"last_run": lastRun,
+	})
+}
+
+// SetInferenceLimits sets the derivation depth limit and cycle detection
+// of the tenant's inference runs
+func (h *CognitiveHandler) SetInferenceLimits(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var limits inference.Limits
+	if err := json.NewDecoder(r.Body).Decode(&limits); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.engine.SetInferenceLimits(tenantID, limits); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id": tenantID,
+		"limits":    limits,
+	})
+}
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sessions"
+	"github.com/go-chi/chi/v5"
+)
+
+// atomSummary converts an atom into its JSON-friendly list representation
+func atomSummary(atom atomspace.Atom) map[string]interface{} {
+	tv := atom.GetTruthValue()
+	return map[string]interface{}{
+		"atom_id": atom.GetID(),
+		"name":    atom.GetName(),
+		"type":    atom.GetType(),
+		"truth_value": map[string]float64{
+			"strength":   tv.Strength,
+			"confidence": tv.Confidence,
+		},
+	}
+}
+
+// session resolves the session addressed by the request, writing a 404 when
+// it does not exist or has expired
+func (h *CognitiveHandler) session(w http.ResponseWriter, r *http.Request) (*sessions.Session, bool) {
+	tenantID := chi.URLParam(r, "tenantID")
+	sessionID := chi.URLParam(r, "sessionID")
+
+	s, err := h.engine.GetSession(sessionID, tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, false
+	}
+	return s, true
+}
+
+// CreateSession opens an interactive reasoning session
+func (h *CognitiveHandler) CreateSession(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		TTLSeconds int `json:"ttl_seconds"`
+	}
+
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	s, err := h.engine.CreateSession(tenantID, time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"session_id": s.ID,
+		"tenant_id":  tenantID,
+		"expires_at": s.ExpiresAt(),
+	})
+}
+
+// GetSession returns session statistics
+func (h *CognitiveHandler) GetSession(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.GetStats())
+}
+
+// DeleteSession discards a session and its working memory
+func (h *CognitiveHandler) DeleteSession(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	sessionID := chi.URLParam(r, "sessionID")
+
+	if err := h.engine.CloseSession(sessionID, tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":    "Session closed successfully",
+		"session_id": sessionID,
+	})
+}
+
+// CreateSessionAtom adds a node to the session's working memory
+func (h *CognitiveHandler) CreateSessionAtom(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Type       int     `json:"type"`
+		Name       string  `json:"name"`
+		Strength   float64 `json:"strength"`
+		Confidence float64 `json:"confidence"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	atomID := atomspace.GenerateAtomID(atomspace.AtomType(req.Type), req.Name, nil)
+	node := atomspace.NewNode(atomID, req.Name, s.TenantID, atomspace.AtomType(req.Type))
+
+	if req.Strength > 0 || req.Confidence > 0 {
+		node.SetTruthValue(atomspace.TruthValue{
+			Strength:   req.Strength,
+			Confidence: req.Confidence,
+		})
+	}
+
+	if err := s.Space.AddAtom(node); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(atomSummary(node))
+}
+
+// CreateSessionInheritanceLink links two atoms inside a session
+func (h *CognitiveHandler) CreateSessionInheritanceLink(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		SourceID string `json:"source_id"`
+		TargetID string `json:"target_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	source, err := s.Space.GetAtom(req.SourceID, s.TenantID)
+	if err != nil {
+		http.Error(w, "source atom not found: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	target, err := s.Space.GetAtom(req.TargetID, s.TenantID)
+	if err != nil {
+		http.Error(w, "target atom not found: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	outgoing := []atomspace.Atom{source, target}
+	linkID := atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", outgoing)
+	link := atomspace.NewLink(linkID, "inheritance", s.TenantID, atomspace.InheritanceLinkType, outgoing)
+
+	if err := s.Space.AddAtom(link); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"link_id":   linkID,
+		"source_id": req.SourceID,
+		"target_id": req.TargetID,
+		"type":      "inheritance",
+	})
+}
+
+// GetSessionAtoms lists atoms visible in a session. With local=true only the
+// session's own atoms are returned.
+func (h *CognitiveHandler) GetSessionAtoms(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	var atoms []atomspace.Atom
+	if r.URL.Query().Get("local") == "true" {
+		atoms = s.Space.LocalAtoms(s.TenantID)
+	} else {
+		atoms = s.Space.QueryAtoms(s.TenantID, nil)
+	}
+
+	result := make([]map[string]interface{}, len(atoms))
+	for i, atom := range atoms {
+		result[i] = atomSummary(atom)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"atoms": result,
+		"count": len(result),
+	})
+}
+
+// RunSessionInference runs inference inside a session without touching the
+// tenant's main space
+func (h *CognitiveHandler) RunSessionInference(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		MaxIterations int `json:"max_iterations"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		req.MaxIterations = 10
+	}
+
+	newAtoms, err := s.RunInference(r.Context(), req.MaxIterations)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]map[string]interface{}, len(newAtoms))
+	for i, atom := range newAtoms {
+		result[i] = atomSummary(atom)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"new_atoms":       result,
+		"new_atoms_count": len(newAtoms),
+		"max_iterations":  req.MaxIterations,
+	})
+}
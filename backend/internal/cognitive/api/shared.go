@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive"
+	"github.com/go-chi/chi/v5"
+)
+
+// CreateSharedSpace creates a shared read-only ontology space
+func (h *CognitiveHandler) CreateSharedSpace(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	space, err := h.engine.CreateSharedSpace(req.ID, req.Name, req.Description)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sharedSpaceSummary(h.engine, space))
+}
+
+// GetSharedSpaces lists shared spaces
+func (h *CognitiveHandler) GetSharedSpaces(w http.ResponseWriter, r *http.Request) {
+	spaces := h.engine.GetSharedSpaces()
+
+	result := make([]map[string]interface{}, len(spaces))
+	for i, space := range spaces {
+		result[i] = sharedSpaceSummary(h.engine, space)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"shared_spaces": result,
+		"count":         len(result),
+	})
+}
+
+// CreateSharedConcept creates a concept node in a shared space
+func (h *CognitiveHandler) CreateSharedConcept(w http.ResponseWriter, r *http.Request) {
+	spaceID := chi.URLParam(r, "spaceID")
+
+	var req struct {
+		Name string `json:"name"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	atom, err := h.engine.CreateSharedConceptNode(spaceID, req.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"atom_id":  atom.GetID(),
+		"name":     atom.GetName(),
+		"type":     "concept",
+		"space_id": spaceID,
+	})
+}
+
+// CreateSharedInheritanceLink creates an inheritance link in a shared space
+func (h *CognitiveHandler) CreateSharedInheritanceLink(w http.ResponseWriter, r *http.Request) {
+	spaceID := chi.URLParam(r, "spaceID")
+
+	var req struct {
+		SourceID string `json:"source_id"`
+		TargetID string `json:"target_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.engine.CreateSharedInheritanceLink(spaceID, req.SourceID, req.TargetID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"link_id":   link.GetID(),
+		"source_id": req.SourceID,
+		"target_id": req.TargetID,
+		"type":      "inheritance",
+		"space_id":  spaceID,
+	})
+}
+
+// MountSharedSpace mounts a shared space into a tenant
+func (h *CognitiveHandler) MountSharedSpace(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	spaceID := chi.URLParam(r, "spaceID")
+
+	if err := h.engine.MountSharedSpace(tenantID, spaceID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Shared space mounted successfully",
+		"tenant_id": tenantID,
+		"mounts":    h.engine.GetMounts(tenantID),
+	})
+}
+
+// UnmountSharedSpace detaches a shared space from a tenant
+func (h *CognitiveHandler) UnmountSharedSpace(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	spaceID := chi.URLParam(r, "spaceID")
+
+	if err := h.engine.UnmountSharedSpace(tenantID, spaceID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Shared space unmounted successfully",
+		"tenant_id": tenantID,
+		"mounts":    h.engine.GetMounts(tenantID),
+	})
+}
+
+// GetMounts lists the shared spaces mounted by a tenant
+func (h *CognitiveHandler) GetMounts(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id": tenantID,
+		"mounts":    h.engine.GetMounts(tenantID),
+	})
+}
+
+func sharedSpaceSummary(engine *cognitive.CognitiveEngine, space *cognitive.SharedSpace) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          space.ID,
+		"name":        space.Name,
+		"description": space.Description,
+		"created_at":  space.CreatedAt,
+		"total_atoms": len(engine.QueryAtoms(cognitive.SharedTenantID(space.ID), nil)),
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/shares"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListShares returns a tenant's live share links
+func (h *CognitiveHandler) ListShares(w http.ResponseWriter, r *http.Request) {
+	list := h.engine.ListShares(chi.URLParam(r, "tenantID"))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"shares": list,
+		"count":  len(list),
+	})
+}
+
+// CreateShare mints a read-only share link to a saved query or a subgraph
+func (h *CognitiveHandler) CreateShare(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Description string `json:"description"`
+		shares.Scope
+		TTLSeconds int `json:"ttl_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	share := shares.Share{Description: req.Description, Scope: req.Scope}
+	ttl := time.Duration(req.TTLSeconds) * time.Second
+	share, token, err := h.engine.CreateShare(chi.URLParam(r, "tenantID"), share, ttl, acl.PrincipalFrom(r.Context()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"share": share,
+		"token": token,
+		"path":  "/api/cognitive/shared/" + token,
+	})
+}
+
+// GetShare returns a live share link
+func (h *CognitiveHandler) GetShare(w http.ResponseWriter, r *http.Request) {
+	share, err := h.engine.GetShare(chi.URLParam(r, "tenantID"), chi.URLParam(r, "shareID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(share)
+}
+
+// RevokeShare removes a share link before it expires
+func (h *CognitiveHandler) RevokeShare(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "shareID")
+	if err := h.engine.RevokeShare(chi.URLParam(r, "tenantID"), id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Share revoked successfully",
+		"id":      id,
+	})
+}
+
+// ViewShare returns the atoms a share token grants a read-only view of.
+// It needs no principal: the token is the credential.
+func (h *CognitiveHandler) ViewShare(w http.ResponseWriter, r *http.Request) {
+	view, err := h.engine.ViewShare(chi.URLParam(r, "token"))
+	if err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, shares.ErrInvalid) {
+			status = http.StatusUnauthorized
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id":   view.Share.TenantID,
+		"description": view.Share.Description,
+		"scope":       view.Share.Scope,
+		"expires_at":  view.Share.ExpiresAt,
+		"atoms":       atomResults(view.Atoms),
+		"count":       len(view.Atoms),
+		"truncated":   view.Truncated,
+	})
+}
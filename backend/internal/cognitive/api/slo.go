@@ -0,0 +1,177 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/slo"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListSLOs returns a tenant's SLOs with the statuses of the latest run
+func (h *CognitiveHandler) ListSLOs(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	slos := h.engine.ListSLOs(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"slos":     slos,
+		"statuses": h.engine.GetSLOStatuses(tenantID),
+		"count":    len(slos),
+	})
+}
+
+// SetSLO creates or replaces an SLO. Windows are Go durations, e.g. "720h".
+func (h *CognitiveHandler) SetSLO(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	var req struct {
+		Service    string  `json:"service"`
+		Series     string  `json:"series"`
+		Target     float64 `json:"target"`
+		Window     string  `json:"window"`
+		BurnWindow string  `json:"burn_window"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	objective := slo.SLO{
+		Name:    name,
+		Service: req.Service,
+		Series:  req.Series,
+		Target:  req.Target,
+	}
+	for _, field := range []struct {
+		value string
+		out   *time.Duration
+	}{{req.Window, &objective.Window}, {req.BurnWindow, &objective.BurnWindow}} {
+		if field.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(field.value)
+		if err != nil {
+			http.Error(w, "invalid window: "+field.value, http.StatusBadRequest)
+			return
+		}
+		*field.out = d
+	}
+
+	objective, err := h.engine.SetSLO(tenantID, objective)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(objective)
+}
+
+// GetSLO returns an SLO with its current status
+func (h *CognitiveHandler) GetSLO(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	objective, err := h.engine.GetSLO(tenantID, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	status, err := h.engine.GetSLOStatus(tenantID, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"slo":    objective,
+		"status": status,
+	})
+}
+
+// DeleteSLO removes an SLO
+func (h *CognitiveHandler) DeleteSLO(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	if err := h.engine.DeleteSLO(tenantID, name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "SLO deleted successfully",
+		"name":    name,
+	})
+}
+
+// EvaluateSLOs computes a tenant's error budgets immediately
+func (h *CognitiveHandler) EvaluateSLOs(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	statuses, err := h.engine.EvaluateSLOs(r.Context(), tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"statuses": statuses,
+		"count":    len(statuses),
+	})
+}
+
+// ConfigureSLOTracking enables SLO tracking for a tenant or updates its
+// configuration
+func (h *CognitiveHandler) ConfigureSLOTracking(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		FastBurnRate    float64 `json:"fast_burn_rate"`
+		IntervalSeconds int     `json:"interval_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	config := agents.DefaultSLOConfig()
+	if req.FastBurnRate > 0 {
+		config.FastBurnRate = req.FastBurnRate
+	}
+	if req.IntervalSeconds > 0 {
+		config.Interval = time.Duration(req.IntervalSeconds) * time.Second
+	}
+
+	agent := h.engine.EnableSLOTracking(tenantID, config)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agent_id": agent.GetID(),
+		"config":   agent.GetConfig(),
+	})
+}
+
+// DisableSLOTracking stops SLO tracking for a tenant
+func (h *CognitiveHandler) DisableSLOTracking(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.DisableSLOTracking(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "SLO tracking disabled successfully",
+		"tenant_id": tenantID,
+	})
+}
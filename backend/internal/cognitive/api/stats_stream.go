@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DefaultStreamInterval is how often streamed statistics are collected
+// unless a client asks otherwise
+const DefaultStreamInterval = 5 * time.Second
+
+// StreamStats upgrades to a WebSocket pushing the live statistics of the
+// engine, or of a tenant under /tenants/{tenantID}, every interval (5s by
+// default). The first message holds every value and later ones only the
+// values that changed, so dashboards need not poll GetStats.
+func (h *CognitiveHandler) StreamStats(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	interval := DefaultStreamInterval
+	if v := r.URL.Query().Get("interval"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			http.Error(w, "interval must be a positive duration", http.StatusBadRequest)
+			return
+		}
+		interval = d
+	}
+
+	sub, err := h.engine.SubscribeStats(tenantID, interval)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer sub.Close()
+
+	conn, err := upgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.serveControl()
+	}()
+
+	for {
+		select {
+		case <-closed:
+			conn.conn.Close()
+			return
+		case u, ok := <-sub.C:
+			if !ok {
+				conn.close(wsCloseGoingAway, "engine is shutting down")
+				return
+			}
+			message, err := json.Marshal(u)
+			if err != nil || conn.writeText(message) != nil {
+				conn.conn.Close()
+				return
+			}
+		}
+	}
+}
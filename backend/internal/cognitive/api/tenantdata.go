@@ -0,0 +1,46 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ExportTenant returns a full machine-readable dump of a tenant's data
+func (h *CognitiveHandler) ExportTenant(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	export, err := h.engine.ExportTenant(tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+tenantID+`-export.json"`)
+	json.NewEncoder(w).Encode(export)
+}
+
+// PurgeTenantData deletes all of a tenant's data. The tenant ID must be
+// repeated in the confirm query parameter.
+func (h *CognitiveHandler) PurgeTenantData(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if r.URL.Query().Get("confirm") != tenantID {
+		http.Error(w, "confirm must be set to the tenant ID", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.engine.PurgeTenant(r.Context(), tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Tenant data purged",
+		"report":  report,
+	})
+}
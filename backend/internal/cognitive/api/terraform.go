@@ -0,0 +1,158 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/secrets"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/terraform"
+	"github.com/go-chi/chi/v5"
+)
+
+// redactTerraformConfig hides the backend tokens of a Terraform
+// configuration in responses
+func redactTerraformConfig(config agents.TerraformConfig) agents.TerraformConfig {
+	for i := range config.Sources {
+		config.Sources[i].Token = secrets.Redact(config.Sources[i].Token)
+	}
+	return config
+}
+
+// GetTerraform returns the configuration and sync status of the tenant's
+// Terraform states, including the resources of the latest revisions
+func (h *CognitiveHandler) GetTerraform(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	config, statuses, err := h.engine.GetTerraformStatus(tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"config":  redactTerraformConfig(config),
+		"sources": statuses,
+	})
+}
+
+// SetTerraformSource adds or replaces a state source: a local path or the
+// URL of an HTTP backend
+func (h *CognitiveHandler) SetTerraformSource(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	var source terraform.Source
+	if err := json.NewDecoder(r.Body).Decode(&source); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	source.Name = name
+
+	config, err := h.engine.SetTerraformSource(tenantID, source)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(redactTerraformConfig(config))
+}
+
+// RemoveTerraformSource stops mirroring a state
+func (h *CognitiveHandler) RemoveTerraformSource(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	name := chi.URLParam(r, "name")
+
+	if err := h.engine.RemoveTerraformSource(tenantID, name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Terraform source removed successfully",
+		"name":    name,
+	})
+}
+
+// SyncTerraform reads the tenant's states immediately. With ?force=true
+// unchanged states are written too.
+func (h *CognitiveHandler) SyncTerraform(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	force := r.URL.Query().Get("force") == "true"
+
+	statuses, err := h.engine.SyncTerraform(r.Context(), tenantID, force)
+	if statuses == nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	response := map[string]interface{}{
+		"sources": statuses,
+	}
+	status := http.StatusOK
+	if err != nil {
+		response["error"] = err.Error()
+		status = http.StatusBadGateway
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ConfigureTerraform enables the Terraform agent for a tenant or replaces
+// its configuration
+func (h *CognitiveHandler) ConfigureTerraform(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		Sources         []terraform.Source `json:"sources"`
+		Attributes      []string           `json:"attributes"`
+		IntervalSeconds int                `json:"interval_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	config := agents.DefaultTerraformConfig()
+	config.Sources = req.Sources
+	if req.Attributes != nil {
+		config.Attributes = req.Attributes
+	}
+	if req.IntervalSeconds > 0 {
+		config.Interval = time.Duration(req.IntervalSeconds) * time.Second
+	}
+
+	agent, err := h.engine.EnableTerraform(tenantID, config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agent_id": agent.GetID(),
+		"config":   redactTerraformConfig(agent.GetConfig()),
+	})
+}
+
+// DisableTerraform stops the Terraform agent of a tenant
+func (h *CognitiveHandler) DisableTerraform(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if err := h.engine.DisableTerraform(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Terraform agent disabled successfully",
+		"tenant_id": tenantID,
+	})
+}
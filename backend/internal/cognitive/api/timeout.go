@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultHandlerTimeout bounds the expensive cognitive endpoints
+const DefaultHandlerTimeout = time.Minute
+
+// SetHandlerTimeout changes how long expensive endpoints, like inference
+// and simulations, may run; 0 lets them run unbounded
+func (h *CognitiveHandler) SetHandlerTimeout(timeout time.Duration) {
+	h.timeout = timeout
+}
+
+// rejectDraining answers 503 once the engine is draining for shutdown
+func (h *CognitiveHandler) rejectDraining(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.engine.Draining() {
+			w.Header().Set("Retry-After", "5")
+			w.Header().Set("Connection", "close")
+			http.Error(w, "engine is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// expensive bounds a route by the handler timeout. Its request context
+// expires at the deadline, so engine calls given it give up; a handler that
+// has not responded by then is answered with 504, and one already streaming
+// its response is left to finish.
+func (h *CognitiveHandler) expensive(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := h.timeout
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		tw := &timeoutWriter{w: w, header: make(http.Header), ctx: ctx}
+		done := make(chan struct{})
+		panicked := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+				close(done)
+			}()
+			next.ServeHTTP(tw, r.WithContext(ctx))
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			tw.mu.Lock()
+			responding := tw.wroteHeader
+			if !responding {
+				tw.timedOut = true
+				http.Error(w, "request timed out after "+timeout.String(), http.StatusGatewayTimeout)
+			}
+			tw.mu.Unlock()
+			if responding {
+				<-done
+			}
+		}
+		select {
+		case p := <-panicked:
+			panic(p)
+		default:
+		}
+	})
+}
+
+// timeoutWriter serializes a handler's response with the timeout's. The
+// handler's headers are kept apart until it responds, so a timeout can
+// answer while the handler still sets them.
+type timeoutWriter struct {
+	w           http.ResponseWriter
+	header      http.Header
+	ctx         context.Context
+	mu          sync.Mutex
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.writeHeader(status)
+}
+
+func (tw *timeoutWriter) writeHeader(status int) {
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	// A handler failing because its context expired timed out
+	if status >= http.StatusInternalServerError && errors.Is(tw.ctx.Err(), context.DeadlineExceeded) {
+		status = http.StatusGatewayTimeout
+	}
+	for key, values := range tw.header {
+		tw.w.Header()[key] = values
+	}
+	tw.w.WriteHeader(status)
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.writeHeader(http.StatusOK)
+	return tw.w.Write(p)
+}
+
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return
+	}
+	tw.writeHeader(http.StatusOK)
+	if f, ok := tw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
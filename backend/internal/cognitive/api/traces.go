@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/traces"
+	"github.com/go-chi/chi/v5"
+)
+
+// IngestTraces is an OTLP/HTTP trace receiver. Exporters point their
+// traces endpoint at /tenants/{tenantID}/v1/traces; only the JSON encoding
+// is accepted. The response is an empty ExportTraceServiceResponse.
+func (h *CognitiveHandler) IngestTraces(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		http.Error(w, "only application/json OTLP exports are supported", http.StatusUnsupportedMediaType)
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	spans, err := traces.ParseOTLP(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.engine.IngestTraces(tenantID, spans); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{})
+}
+
+// GetDependencies returns the service dependencies derived from a tenant's
+// traces with their call rates, error rates and latencies
+func (h *CognitiveHandler) GetDependencies(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	deps := h.engine.GetDependencies(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"dependencies": deps,
+		"count":        len(deps),
+	})
+}
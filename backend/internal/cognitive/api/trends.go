@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/trends"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetStatsHistory returns the history of an engine metric, or of a tenant's
+// under /tenants/{tenantID}. Without a metric it lists the metrics with a
+// history. range (1h by default) selects the latest points, step averages
+// them into buckets and rate=true turns counters into per-second rates.
+func (h *CognitiveHandler) GetStatsHistory(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	params := r.URL.Query()
+
+	metric := params.Get("metric")
+	if metric == "" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"metrics": h.engine.StatsMetrics(tenantID),
+		})
+		return
+	}
+
+	q := trends.Query{Metric: metric, Rate: params.Get("rate") == "true"}
+	window := time.Hour
+	if v := params.Get("range"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			http.Error(w, "range must be a positive duration", http.StatusBadRequest)
+			return
+		}
+		window = d
+	}
+	q.Since = time.Now().Add(-window)
+	if v := params.Get("step"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			http.Error(w, "step must be a positive duration", http.StatusBadRequest)
+			return
+		}
+		q.Step = d
+	}
+
+	points, err := h.engine.StatsHistory(tenantID, q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"metric": metric,
+		"range":  window.String(),
+		"rate":   q.Rate,
+		"points": points,
+	})
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+	"github.com/go-chi/chi/v5"
+)
+
+// CreateTrigger registers a trigger rule binding a condition to actions
+func (h *CognitiveHandler) CreateTrigger(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	var req struct {
+		ID              string             `json:"id"`
+		Name            string             `json:"name"`
+		Condition       triggers.Condition `json:"condition"`
+		Actions         []triggers.Action  `json:"actions"`
+		CooldownSeconds int                `json:"cooldown_seconds"`
+		Enabled         *bool              `json:"enabled"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.ID == "" {
+		req.ID = fmt.Sprintf("%s-%s", req.Name, time.Now().Format("20060102150405"))
+	}
+
+	trigger := &triggers.Trigger{
+		ID:        req.ID,
+		TenantID:  tenantID,
+		Name:      req.Name,
+		Condition: req.Condition,
+		Actions:   req.Actions,
+		Enabled:   req.Enabled == nil || *req.Enabled,
+		Cooldown:  time.Duration(req.CooldownSeconds) * time.Second,
+	}
+
+	if err := h.engine.Triggers().AddTrigger(trigger); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trigger.GetStats())
+}
+
+// GetTriggers lists all triggers of a tenant
+func (h *CognitiveHandler) GetTriggers(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	list := h.engine.Triggers().GetTriggersByTenant(tenantID)
+
+	result := make([]map[string]interface{}, len(list))
+	for i, t := range list {
+		result[i] = t.GetStats()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"triggers": result,
+		"count":    len(result),
+	})
+}
+
+// GetTrigger retrieves a single trigger
+func (h *CognitiveHandler) GetTrigger(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	triggerID := chi.URLParam(r, "triggerID")
+
+	trigger, err := h.engine.Triggers().GetTrigger(triggerID, tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trigger.GetStats())
+}
+
+// SetTriggerEnabled enables or disables a trigger
+func (h *CognitiveHandler) SetTriggerEnabled(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	triggerID := chi.URLParam(r, "triggerID")
+
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.engine.Triggers().SetEnabled(triggerID, tenantID, req.Enabled); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"trigger_id": triggerID,
+		"enabled":    req.Enabled,
+	})
+}
+
+// DeleteTrigger removes a trigger
+func (h *CognitiveHandler) DeleteTrigger(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	triggerID := chi.URLParam(r, "triggerID")
+
+	if err := h.engine.Triggers().RemoveTrigger(triggerID, tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":    "Trigger deleted successfully",
+		"trigger_id": triggerID,
+	})
+}
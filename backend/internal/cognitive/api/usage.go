@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/usage"
+	"github.com/go-chi/chi/v5"
+)
+
+// inferenceRoutes are the routes whose handling time is charged as
+// inference seconds
+var inferenceRoutes = map[string]bool{
+	"POST /tenants/{tenantID}/inference":                      true,
+	"POST /tenants/{tenantID}/sessions/{sessionID}/inference": true,
+}
+
+// trackUsage records each request's size, response size, status and time
+// against its tenant, principal and route
+func (h *CognitiveHandler) trackUsage(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		var body *countingReader
+		if r.Body != nil && r.Body != http.NoBody {
+			body = &countingReader{ReadCloser: r.Body}
+			r.Body = body
+		}
+		uw := &usageWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(uw, r)
+
+		rctx := chi.RouteContext(r.Context())
+		endpoint := r.Method + " " + strings.TrimPrefix(rctx.RoutePattern(), "/api/cognitive")
+		if rctx.RoutePattern() == "" {
+			endpoint = r.Method + " (unmatched)"
+		}
+		counters := usage.Counters{
+			Requests: 1,
+			BytesOut: uw.written,
+			Seconds:  time.Since(start).Seconds(),
+		}
+		if body != nil {
+			counters.BytesIn = body.read
+		}
+		if uw.status >= http.StatusBadRequest {
+			counters.Errors = 1
+		}
+		if inferenceRoutes[endpoint] {
+			counters.InferenceSeconds = counters.Seconds
+		}
+		h.engine.RecordUsage(usage.Key{
+			TenantID:  rctx.URLParam("tenantID"),
+			Principal: acl.PrincipalFrom(r.Context()).User,
+			Endpoint:  endpoint,
+		}, counters)
+	})
+}
+
+// countingReader counts the bytes of a request body read
+type countingReader struct {
+	io.ReadCloser
+	read int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.read += int64(n)
+	return n, err
+}
+
+// usageWriter records the status and size of a response
+type usageWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	written     int64
+}
+
+func (uw *usageWriter) WriteHeader(status int) {
+	if !uw.wroteHeader {
+		uw.wroteHeader = true
+		uw.status = status
+	}
+	uw.ResponseWriter.WriteHeader(status)
+}
+
+func (uw *usageWriter) Write(p []byte) (int, error) {
+	uw.wroteHeader = true
+	n, err := uw.ResponseWriter.Write(p)
+	uw.written += int64(n)
+	return n, err
+}
+
+func (uw *usageWriter) Flush() {
+	if f, ok := uw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (uw *usageWriter) Unwrap() http.ResponseWriter {
+	return uw.ResponseWriter
+}
+
+// GetUsage sums API usage over a rolling window (window, 1h by default),
+// filtered by tenant, principal and endpoint and grouped by group_by
+// (comma-separated tenant, principal and endpoint; tenant by default)
+func (h *CognitiveHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	h.writeUsage(w, r, usage.Key{TenantID: q.Get("tenant"), Principal: q.Get("principal"), Endpoint: q.Get("endpoint")}, usage.ByTenant)
+}
+
+// GetTenantAPIUsage sums a tenant's API usage over a rolling window,
+// grouped by endpoint unless group_by says otherwise
+func (h *CognitiveHandler) GetTenantAPIUsage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	h.writeUsage(w, r, usage.Key{TenantID: chi.URLParam(r, "tenantID"), Principal: q.Get("principal"), Endpoint: q.Get("endpoint")}, usage.ByEndpoint)
+}
+
+func (h *CognitiveHandler) writeUsage(w http.ResponseWriter, r *http.Request, filter usage.Key, defaultGroup string) {
+	config := h.engine.UsageConfig()
+	window := time.Hour
+	if v := r.URL.Query().Get("window"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			http.Error(w, "window must be a positive duration", http.StatusBadRequest)
+			return
+		}
+		window = d
+	}
+	if window > config.Retention {
+		window = config.Retention
+	}
+	groupBy := []string{defaultGroup}
+	if v := r.URL.Query().Get("group_by"); v != "" {
+		groupBy = strings.Split(v, ",")
+	}
+
+	entries, err := h.engine.UsageSummary(window, filter, groupBy)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"window":     window.String(),
+		"resolution": config.Resolution.String(),
+		"group_by":   groupBy,
+		"usage":      entries,
+	})
+}
+
+// ExportUsage returns API usage bucket by bucket between since and until
+// (RFC 3339; the whole retention by default), optionally of one tenant, as
+// JSON or, with format=csv, CSV for billing and chargeback
+func (h *CognitiveHandler) ExportUsage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	until := time.Now()
+	since := until.Add(-h.engine.UsageConfig().Retention)
+	for name, t := range map[string]*time.Time{"since": &since, "until": &until} {
+		if v := q.Get(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				http.Error(w, name+" must be an RFC 3339 time", http.StatusBadRequest)
+				return
+			}
+			*t = parsed
+		}
+	}
+
+	entries := h.engine.UsageBuckets(since, until, usage.Key{TenantID: q.Get("tenant"), Principal: q.Get("principal"), Endpoint: q.Get("endpoint")})
+	if q.Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="usage.csv"`)
+		usage.WriteCSV(w, entries)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"since": since,
+		"until": until,
+		"usage": entries,
+	})
+}
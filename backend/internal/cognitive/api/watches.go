@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/watches"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListWatches returns a tenant's atom watches
+func (h *CognitiveHandler) ListWatches(w http.ResponseWriter, r *http.Request) {
+	list := h.engine.ListWatches(chi.URLParam(r, "tenantID"))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"watches": list,
+		"count":   len(list),
+	})
+}
+
+// AddWatch subscribes the tenant to an atom or a pattern of atoms
+func (h *CognitiveHandler) AddWatch(w http.ResponseWriter, r *http.Request) {
+	var watch watches.Watch
+	if err := json.NewDecoder(r.Body).Decode(&watch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	watch, err := h.engine.AddWatch(chi.URLParam(r, "tenantID"), watch, acl.PrincipalFrom(r.Context()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(watch)
+}
+
+// GetWatch returns an atom watch
+func (h *CognitiveHandler) GetWatch(w http.ResponseWriter, r *http.Request) {
+	watch, err := h.engine.GetWatch(chi.URLParam(r, "tenantID"), chi.URLParam(r, "watchID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(watch)
+}
+
+// DeleteWatch removes an atom watch
+func (h *CognitiveHandler) DeleteWatch(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "watchID")
+	if err := h.engine.DeleteWatch(chi.URLParam(r, "tenantID"), id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Watch deleted successfully",
+		"id":      id,
+	})
+}
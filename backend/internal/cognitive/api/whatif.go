@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/whatif"
+	"github.com/go-chi/chi/v5"
+)
+
+// Simulate runs a what-if simulation: it applies hypothetical changes such
+// as removing a node or scaling a service to zero in a scratch space and
+// reports the predicted outcome without touching the tenant's state.
+// Omitted options keep their defaults; impact propagates over the tenant's
+// link types plus the relations given.
+func (h *CognitiveHandler) Simulate(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	req := whatif.DefaultRequest()
+	req.Impact.Relations = h.engine.ImpactRelations(tenantID)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.engine.Simulate(r.Context(), tenantID, req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	newAtoms := make([]map[string]interface{}, len(report.NewAtoms))
+	for i, atom := range report.NewAtoms {
+		newAtoms[i] = atomSummary(atom)
+	}
+	modified := make([]map[string]interface{}, len(report.ModifiedAtoms))
+	for i, atom := range report.ModifiedAtoms {
+		modified[i] = atomSummary(atom)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"changes":          report.Changes,
+		"at_risk":          report.AtRisk,
+		"at_risk_services": report.AtRiskServices,
+		"violations":       report.Violations,
+		"pipelines":        report.Pipelines,
+		"new_atoms":        newAtoms,
+		"modified_atoms":   modified,
+		"removed_atom_ids": report.RemovedAtomIDs,
+	})
+}
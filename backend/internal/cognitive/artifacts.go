@@ -0,0 +1,53 @@
+package cognitive
+
+import (
+	"context"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/artifacts"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/pipeline"
+)
+
+// artifactSweepInterval is how often expired artifacts are removed
+const artifactSweepInterval = time.Hour
+
+// attachArtifact stores an artifact a stage attaches to its execution
+func (ce *CognitiveEngine) attachArtifact(ctx context.Context, ref pipeline.ArtifactRef, data []byte) error {
+	_, err := ce.artifacts.Put(ctx, artifacts.Artifact{
+		Name:        ref.Name,
+		TenantID:    ref.TenantID,
+		PipelineID:  ref.PipelineID,
+		ExecutionID: ref.ExecutionID,
+		Stage:       ref.Stage,
+		ContentType: ref.ContentType,
+	}, data)
+	return err
+}
+
+// ListArtifacts returns the artifacts the stages of a tenant's pipeline
+// execution attached. They outlive the execution's record, and the
+// pipeline, until their retention expires.
+func (ce *CognitiveEngine) ListArtifacts(tenantID, pipelineID, executionID string) []artifacts.Artifact {
+	return ce.artifacts.List(tenantID, pipelineID, executionID)
+}
+
+// GetArtifact returns an artifact of a tenant's pipeline execution with its
+// content
+func (ce *CognitiveEngine) GetArtifact(ctx context.Context, tenantID, pipelineID, executionID, name string) (artifacts.Artifact, []byte, error) {
+	return ce.artifacts.Get(ctx, tenantID, pipelineID, executionID, name)
+}
+
+// sweepArtifacts removes expired artifacts every interval until the engine
+// is closed
+func (ce *CognitiveEngine) sweepArtifacts(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ce.done:
+			return
+		case <-ticker.C:
+			ce.artifacts.Expire()
+		}
+	}
+}
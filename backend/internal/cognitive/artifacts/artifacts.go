@@ -0,0 +1,444 @@
+package artifacts
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/persistence"
+)
+
+// ErrNotFound is returned for an artifact that does not exist or expired
+var ErrNotFound = errors.New("artifact not found")
+
+// ErrTooLarge is returned when an artifact exceeds the size limits
+var ErrTooLarge = errors.New("artifact too large")
+
+// validName matches artifact names, which become file names
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// Config bounds the artifacts stages attach to pipeline executions
+type Config struct {
+	Dir              string        // Where artifacts are written; kept in memory if empty
+	MaxSize          int64         // Largest artifact
+	MaxExecutionSize int64         // Most bytes of artifacts one execution may attach
+	Retention        time.Duration // How long artifacts are kept; forever if 0
+}
+
+// DefaultConfig keeps artifacts in memory for a week
+func DefaultConfig() Config {
+	return Config{
+		MaxSize:          8 << 20,
+		MaxExecutionSize: 64 << 20,
+		Retention:        7 * 24 * time.Hour,
+	}
+}
+
+// Artifact describes a stored artifact, such as a report, an exported
+// subgraph or an action transcript
+type Artifact struct {
+	Name        string    `json:"name"`
+	TenantID    string    `json:"tenant_id"`
+	PipelineID  string    `json:"pipeline_id"`
+	ExecutionID string    `json:"execution_id"`
+	Stage       string    `json:"stage,omitempty"` // Stage that attached it
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at,omitempty"`
+}
+
+func (a Artifact) expired(now time.Time) bool {
+	return !a.ExpiresAt.IsZero() && !now.Before(a.ExpiresAt)
+}
+
+// Sealer encrypts artifacts at rest for their tenant, like
+// persistence.Encryptor
+type Sealer interface {
+	Writer(ctx context.Context, tenantID string, w io.Writer) (io.WriteCloser, error)
+	Reader(ctx context.Context, r io.Reader) (io.Reader, string, error)
+}
+
+// Store keeps the artifacts of pipeline executions, in memory or as files
+// in a directory that survive restarts
+type Store struct {
+	config     Config
+	sealer     Sealer
+	executions map[executionKey]map[string]*stored
+	stored     int64 // Bytes held
+	rejected   int64
+	expired    int64
+	err        error // Why the directory cannot be used; artifacts are rejected while set
+	mu         sync.RWMutex
+}
+
+type executionKey struct {
+	pipelineID  string
+	executionID string
+}
+
+type stored struct {
+	Artifact
+	data []byte // Content, when kept in memory
+}
+
+// NewStore creates a store. With a directory, the artifacts written to it
+// before are loaded, dropping the expired ones; if it cannot be used,
+// artifacts are rejected with the reason, which Err returns.
+func NewStore(config Config) *Store {
+	s := &Store{config: config, executions: make(map[executionKey]map[string]*stored)}
+	if config.Dir == "" {
+		return s
+	}
+	if err := os.MkdirAll(config.Dir, 0o700); err != nil {
+		s.err = fmt.Errorf("failed to create artifact directory: %w", err)
+	} else if err := s.load(); err != nil {
+		s.err = fmt.Errorf("failed to load artifacts: %w", err)
+	}
+	return s
+}
+
+// Err returns why the store's directory cannot be used, if it cannot
+func (s *Store) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.err
+}
+
+// SetSealer encrypts the artifacts written to the directory from now on
+func (s *Store) SetSealer(sealer Sealer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sealer = sealer
+}
+
+// load indexes the artifacts in the directory by their metadata files
+func (s *Store) load() error {
+	now := time.Now()
+	return filepath.WalkDir(s.config.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".meta") {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var a Artifact
+		if err := json.Unmarshal(data, &a); err != nil {
+			return fmt.Errorf("invalid artifact metadata %s: %w", path, err)
+		}
+		if a.expired(now) {
+			s.removeFiles(a)
+			return nil
+		}
+		s.add(&stored{Artifact: a})
+		return nil
+	})
+}
+
+// Put stores an artifact of an execution, replacing the artifact of the
+// same name. a names it; its size, checksum and times are set.
+func (s *Store) Put(ctx context.Context, a Artifact, data []byte) (Artifact, error) {
+	if !validName.MatchString(a.Name) {
+		return Artifact{}, fmt.Errorf("invalid artifact name %q: use up to 128 letters, digits, dots, dashes and underscores", a.Name)
+	}
+	if a.PipelineID == "" || a.ExecutionID == "" {
+		return Artifact{}, errors.New("an artifact belongs to a pipeline execution")
+	}
+	if a.ContentType == "" {
+		a.ContentType = "application/octet-stream"
+	}
+	a.Size = int64(len(data))
+	sum := sha256.Sum256(data)
+	a.SHA256 = hex.EncodeToString(sum[:])
+	a.CreatedAt = time.Now()
+	if s.config.Retention > 0 {
+		a.ExpiresAt = a.CreatedAt.Add(s.config.Retention)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return Artifact{}, s.err
+	}
+	s.expireLocked(a.CreatedAt)
+	if s.config.MaxSize > 0 && a.Size > s.config.MaxSize {
+		s.rejected++
+		return Artifact{}, fmt.Errorf("%w: %d bytes exceed %d", ErrTooLarge, a.Size, s.config.MaxSize)
+	}
+	if s.config.MaxExecutionSize > 0 {
+		total := a.Size
+		for name, existing := range s.executions[executionKey{a.PipelineID, a.ExecutionID}] {
+			if name != a.Name {
+				total += existing.Size
+			}
+		}
+		if total > s.config.MaxExecutionSize {
+			s.rejected++
+			return Artifact{}, fmt.Errorf("%w: the execution's artifacts would take %d bytes, more than %d", ErrTooLarge, total, s.config.MaxExecutionSize)
+		}
+	}
+
+	entry := &stored{Artifact: a}
+	if s.config.Dir == "" {
+		entry.data = append([]byte(nil), data...)
+	} else if err := s.writeFiles(ctx, a, data); err != nil {
+		return Artifact{}, err
+	}
+	s.add(entry)
+	return a, nil
+}
+
+// add indexes an artifact, replacing the one of the same name
+func (s *Store) add(entry *stored) {
+	key := executionKey{entry.PipelineID, entry.ExecutionID}
+	artifacts := s.executions[key]
+	if artifacts == nil {
+		artifacts = make(map[string]*stored)
+		s.executions[key] = artifacts
+	}
+	if existing, ok := artifacts[entry.Name]; ok {
+		s.stored -= existing.Size
+	}
+	artifacts[entry.Name] = entry
+	s.stored += entry.Size
+}
+
+// Get returns an artifact of a tenant's execution with its content
+func (s *Store) Get(ctx context.Context, tenantID, pipelineID, executionID, name string) (Artifact, []byte, error) {
+	s.mu.RLock()
+	entry, ok := s.executions[executionKey{pipelineID, executionID}][name]
+	sealer := s.sealer
+	s.mu.RUnlock()
+	if !ok || entry.TenantID != tenantID || entry.expired(time.Now()) {
+		return Artifact{}, nil, ErrNotFound
+	}
+	if s.config.Dir == "" {
+		return entry.Artifact, entry.data, nil
+	}
+
+	data, err := s.readContent(ctx, entry.Artifact, sealer)
+	if err != nil {
+		return Artifact{}, nil, err
+	}
+	return entry.Artifact, data, nil
+}
+
+// List returns the artifacts of a tenant's execution by name
+func (s *Store) List(tenantID, pipelineID, executionID string) []Artifact {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	var list []Artifact
+	for _, entry := range s.executions[executionKey{pipelineID, executionID}] {
+		if entry.TenantID == tenantID && !entry.expired(now) {
+			list = append(list, entry.Artifact)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Purge removes the artifacts of a tenant and returns how many there were
+func (s *Store) Purge(tenantID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for key, artifacts := range s.executions {
+		for name, entry := range artifacts {
+			if entry.TenantID == tenantID {
+				s.removeLocked(key, name)
+				removed++
+			}
+		}
+	}
+	return removed
+}
+
+// Expire removes the expired artifacts and returns how many there were
+func (s *Store) Expire() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.expireLocked(time.Now())
+}
+
+func (s *Store) expireLocked(now time.Time) int {
+	removed := 0
+	for key, artifacts := range s.executions {
+		for name, entry := range artifacts {
+			if entry.expired(now) {
+				s.removeLocked(key, name)
+				removed++
+			}
+		}
+	}
+	s.expired += int64(removed)
+	return removed
+}
+
+func (s *Store) removeLocked(key executionKey, name string) {
+	entry := s.executions[key][name]
+	delete(s.executions[key], name)
+	if len(s.executions[key]) == 0 {
+		delete(s.executions, key)
+	}
+	s.stored -= entry.Size
+	if s.config.Dir != "" {
+		s.removeFiles(entry.Artifact)
+	}
+}
+
+// GetStats returns store statistics
+func (s *Store) GetStats() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	artifacts := 0
+	for _, list := range s.executions {
+		artifacts += len(list)
+	}
+	stats := map[string]interface{}{
+		"artifacts":     artifacts,
+		"executions":    len(s.executions),
+		"stored_bytes":  s.stored,
+		"rejected":      s.rejected,
+		"expired":       s.expired,
+		"persistent":    s.config.Dir != "",
+		"retention_sec": int64(s.config.Retention.Seconds()),
+	}
+	if s.err != nil {
+		stats["error"] = s.err.Error()
+	}
+	return stats
+}
+
+// path returns where an artifact's content is written, beside its
+// metadata. IDs are encoded so that any ID makes a safe directory name.
+func (s *Store) path(a Artifact) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return filepath.Join(s.config.Dir, encode([]byte(a.TenantID)), encode([]byte(a.PipelineID)), encode([]byte(a.ExecutionID)), a.Name)
+}
+
+// writeFiles writes an artifact's content, sealed if a sealer is set, and
+// then its metadata, each beside its path and moved in place once synced
+func (s *Store) writeFiles(ctx context.Context, a Artifact, data []byte) error {
+	path := s.path(a)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+
+	content := data
+	if s.sealer != nil {
+		var sealed bytes.Buffer
+		w, err := s.sealer.Writer(ctx, a.TenantID, &sealed)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt artifact: %w", err)
+		}
+		w.Write(data)
+		if err := w.Close(); err != nil {
+			return fmt.Errorf("failed to encrypt artifact: %w", err)
+		}
+		content = sealed.Bytes()
+	}
+	meta, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(path+".data", content); err != nil {
+		return fmt.Errorf("failed to write artifact: %w", err)
+	}
+	if err := writeFile(path+".meta", meta); err != nil {
+		os.Remove(path + ".data")
+		return fmt.Errorf("failed to write artifact metadata: %w", err)
+	}
+	return nil
+}
+
+func writeFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// readContent reads an artifact's content from its file, opening it if it
+// was sealed
+func (s *Store) readContent(ctx context.Context, a Artifact, sealer Sealer) ([]byte, error) {
+	f, err := os.Open(s.path(a) + ".data")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if persistence.IsEncrypted(br) {
+		if sealer == nil {
+			return nil, errors.New("artifact is encrypted and no key provider is configured")
+		}
+		var tenantID string
+		if r, tenantID, err = sealer.Reader(ctx, br); err != nil {
+			return nil, fmt.Errorf("failed to decrypt artifact: %w", err)
+		}
+		if tenantID != a.TenantID {
+			return nil, fmt.Errorf("artifact %s was sealed for another tenant", a.Name)
+		}
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact: %w", err)
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != a.SHA256 {
+		return nil, fmt.Errorf("artifact %s is corrupt: checksum mismatch", a.Name)
+	}
+	return data, nil
+}
+
+// removeFiles removes an artifact's files, and the directories left empty
+func (s *Store) removeFiles(a Artifact) {
+	path := s.path(a)
+	os.Remove(path + ".data")
+	os.Remove(path + ".meta")
+	for dir := filepath.Dir(path); dir != s.config.Dir && strings.HasPrefix(dir, s.config.Dir); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+}
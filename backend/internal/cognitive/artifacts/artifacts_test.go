@@ -0,0 +1,122 @@
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/persistence"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(Config{MaxSize: 16, MaxExecutionSize: 24})
+
+	a, err := s.Put(ctx, Artifact{Name: "report.json", TenantID: "t1", PipelineID: "p", ExecutionID: "e"}, []byte(`{"ok":true}`))
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if a.Size != 11 || a.SHA256 == "" || a.ContentType != "application/octet-stream" {
+		t.Errorf("Put = %+v", a)
+	}
+	if _, data, err := s.Get(ctx, "t1", "p", "e", "report.json"); err != nil || string(data) != `{"ok":true}` {
+		t.Errorf("Get = %q, %v", data, err)
+	}
+	if _, _, err := s.Get(ctx, "t2", "p", "e", "report.json"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of another tenant's artifact = %v, want ErrNotFound", err)
+	}
+	if list := s.List("t2", "p", "e"); len(list) != 0 {
+		t.Errorf("List of another tenant = %v", list)
+	}
+
+	for _, name := range []string{"", "../x", ".hidden", "a/b"} {
+		if _, err := s.Put(ctx, Artifact{Name: name, TenantID: "t1", PipelineID: "p", ExecutionID: "e"}, nil); err == nil {
+			t.Errorf("Put accepted the name %q", name)
+		}
+	}
+	if _, err := s.Put(ctx, Artifact{Name: "big", TenantID: "t1", PipelineID: "p", ExecutionID: "e"}, make([]byte, 17)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Put of an artifact over MaxSize = %v", err)
+	}
+	if _, err := s.Put(ctx, Artifact{Name: "more", TenantID: "t1", PipelineID: "p", ExecutionID: "e"}, make([]byte, 14)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Put over MaxExecutionSize = %v", err)
+	}
+	// Replacing an artifact only counts its new size
+	if _, err := s.Put(ctx, Artifact{Name: "report.json", TenantID: "t1", PipelineID: "p", ExecutionID: "e"}, make([]byte, 16)); err != nil {
+		t.Errorf("Put replacing an artifact: %v", err)
+	}
+	if list := s.List("t1", "p", "e"); len(list) != 1 || list[0].Size != 16 {
+		t.Errorf("List = %v", list)
+	}
+
+	if removed := s.Purge("t1"); removed != 1 {
+		t.Errorf("Purge = %d, want 1", removed)
+	}
+	if stats := s.GetStats(); stats["artifacts"] != 0 || stats["stored_bytes"] != int64(0) || stats["rejected"] != int64(2) {
+		t.Errorf("stats = %v", stats)
+	}
+}
+
+func TestStoreExpiry(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(Config{Retention: 10 * time.Millisecond})
+	s.Put(ctx, Artifact{Name: "transcript.txt", TenantID: "t", PipelineID: "p", ExecutionID: "e"}, []byte("done"))
+	time.Sleep(20 * time.Millisecond)
+
+	if _, _, err := s.Get(ctx, "t", "p", "e", "transcript.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of an expired artifact = %v", err)
+	}
+	if removed := s.Expire(); removed != 1 {
+		t.Errorf("Expire = %d, want 1", removed)
+	}
+}
+
+func TestStoreDir(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	provider, _ := persistence.NewLocalKeyProvider(bytes.Repeat([]byte{7}, 32), "")
+	encryptor := persistence.NewEncryptor(provider)
+
+	s := NewStore(Config{Dir: dir})
+	s.SetSealer(encryptor)
+	if err := s.Err(); err != nil {
+		t.Fatalf("Err: %v", err)
+	}
+	content := []byte("# Report\nsecret findings")
+	if _, err := s.Put(ctx, Artifact{Name: "report.md", TenantID: "t", PipelineID: "p/1", ExecutionID: "e", ContentType: "text/markdown"}, content); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	var files []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+		return err
+	})
+	if len(files) != 2 {
+		t.Fatalf("expected a data and a metadata file, got %v", files)
+	}
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		if bytes.Contains(data, []byte("secret findings")) {
+			t.Errorf("%s holds the content in the clear", file)
+		}
+	}
+
+	// Artifacts survive restarts
+	s = NewStore(Config{Dir: dir})
+	s.SetSealer(encryptor)
+	a, data, err := s.Get(ctx, "t", "p/1", "e", "report.md")
+	if err != nil || !bytes.Equal(data, content) || a.ContentType != "text/markdown" {
+		t.Errorf("Get after reload = %+v, %q, %v", a, data, err)
+	}
+
+	s.Purge("t")
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Purge left %d entries in the directory", len(entries))
+	}
+}
@@ -0,0 +1,17 @@
+package atomspace
+
+// NewAliasLink creates the AliasLink recording that alias is another name
+// of the canonical atom
+func NewAliasLink(alias, tenantID string, canonical Atom) *Link {
+	outgoing := []Atom{canonical}
+	return NewLink(GenerateAtomID(AliasLinkType, alias, outgoing), alias, tenantID, AliasLinkType, outgoing)
+}
+
+// AliasTarget returns the canonical atom an AliasLink links to
+func AliasTarget(atom Atom) (Atom, bool) {
+	link, ok := atom.(*Link)
+	if !ok || link.GetType() != AliasLinkType || len(link.GetOutgoing()) != 1 {
+		return nil, false
+	}
+	return link.GetOutgoing()[0], true
+}
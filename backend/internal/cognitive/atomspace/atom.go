@@ -1,6 +1,7 @@
 package atomspace
 
 import (
+	"sort"
 	"sync"
 	"time"
 )
@@ -21,8 +22,35 @@ const (
 	SimilarityLinkType
 	ExecutionLinkType
 	EvaluationLinkType
+	
+	// Node types added after the link types, keeping the numbers of
+	// persisted types stable
+	NumberNodeType
+	GroundedPredicateNodeType
+	GroundedSchemaNodeType
+	
+	// Link types added later. An AliasLink, named after an alias, links to
+	// the canonical atom the alias stands for.
+	AliasLinkType
 )
 
+// IsLinkType reports whether atoms of a type are links
+func IsLinkType(atomType AtomType) bool {
+	return atomType >= LinkType && atomType <= EvaluationLinkType || atomType == AliasLinkType
+}
+
+// unorderedLinkTypes lists symmetric link types whose outgoing set carries
+// no order, e.g. Similarity(A, B) is the same statement as Similarity(B, A)
+var unorderedLinkTypes = map[AtomType]bool{
+	SimilarityLinkType: true,
+}
+
+// IsOrderedLinkType reports whether the order of a link type's outgoing
+// atoms is significant
+func IsOrderedLinkType(atomType AtomType) bool {
+	return !unorderedLinkTypes[atomType]
+}
+
 // TruthValue represents probabilistic truth with strength and confidence
 type TruthValue struct {
 	Strength   float64 // [0, 1] - probability that the statement is true
@@ -59,6 +87,9 @@ type BaseAtom struct {
 	TenantID       string
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
+	RefreshedAt    time.Time // When the truth value was last set, other than by decay
+	DecayedAt      time.Time // When the confidence last decayed since the refresh; zero if it has not
+	Protected      bool      // Updates and deletes are refused without the admin override
 	mu             sync.RWMutex
 }
 
@@ -85,6 +116,18 @@ func (a *BaseAtom) SetTruthValue(tv TruthValue) {
 	defer a.mu.Unlock()
 	a.TruthVal = tv
 	a.UpdatedAt = time.Now()
+	a.RefreshedAt = a.UpdatedAt
+	a.DecayedAt = time.Time{}
+}
+
+// DecayTruthValue sets a truth value whose confidence decayed at a time,
+// keeping when it was last refreshed
+func (a *BaseAtom) DecayTruthValue(tv TruthValue, at time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.TruthVal = tv
+	a.UpdatedAt = time.Now()
+	a.DecayedAt = at
 }
 
 func (a *BaseAtom) GetAttentionValue() AttentionValue {
@@ -104,6 +147,60 @@ func (a *BaseAtom) GetTenantID() string {
 	return a.TenantID
 }
 
+// GetCreatedAt returns when the atom was created
+func (a *BaseAtom) GetCreatedAt() time.Time {
+	return a.CreatedAt
+}
+
+// GetUpdatedAt returns when the atom's values last changed
+func (a *BaseAtom) GetUpdatedAt() time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.UpdatedAt
+}
+
+// GetRefreshedAt returns when the atom's truth value was last set, other
+// than by decay. Atoms persisted without it were refreshed when last updated.
+func (a *BaseAtom) GetRefreshedAt() time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.RefreshedAt.IsZero() {
+		return a.UpdatedAt
+	}
+	return a.RefreshedAt
+}
+
+// GetDecayedAt returns when the atom's confidence last decayed since it was
+// refreshed, or the zero time
+func (a *BaseAtom) GetDecayedAt() time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.DecayedAt
+}
+
+// RestoreFreshness sets when the truth value was refreshed and decayed, as
+// persisted
+func (a *BaseAtom) RestoreFreshness(refreshedAt, decayedAt time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.RefreshedAt = refreshedAt
+	a.DecayedAt = decayedAt
+}
+
+// IsProtected reports whether the atom is protected
+func (a *BaseAtom) IsProtected() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.Protected
+}
+
+// SetProtected protects the atom or lifts its protection
+func (a *BaseAtom) SetProtected(protected bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.Protected = protected
+}
+
 // Node represents a simple named atom
 type Node struct {
 	BaseAtom
@@ -115,12 +212,13 @@ func NewNode(id, name, tenantID string, atomType AtomType) *Node {
 		BaseAtom: BaseAtom{
 			ID:             id,
 			Type:           atomType,
-			Name:           name,
-			TenantID:       tenantID,
+			Name:           Intern(name),
+			TenantID:       Intern(tenantID),
 			TruthVal:       TruthValue{Strength: 1.0, Confidence: 1.0},
 			AttentionVal:   AttentionValue{STI: 0, LTI: 0, VLTI: 0},
 			CreatedAt:      now,
 			UpdatedAt:      now,
+			RefreshedAt:    now,
 		},
 	}
 }
@@ -136,6 +234,9 @@ func (n *Node) Clone() Atom {
 			AttentionVal: n.AttentionVal,
 			CreatedAt:    n.CreatedAt,
 			UpdatedAt:    n.UpdatedAt,
+			RefreshedAt:  n.RefreshedAt,
+			DecayedAt:    n.DecayedAt,
+			Protected:    n.IsProtected(),
 		},
 	}
 }
@@ -147,17 +248,28 @@ type Link struct {
 }
 
 func NewLink(id, name, tenantID string, atomType AtomType, outgoing []Atom) *Link {
+	if !IsOrderedLinkType(atomType) {
+		// Store symmetric links in canonical order so equal links are identical
+		sorted := make([]Atom, len(outgoing))
+		copy(sorted, outgoing)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].GetID() < sorted[j].GetID()
+		})
+		outgoing = sorted
+	}
+	
 	now := time.Now()
 	return &Link{
 		BaseAtom: BaseAtom{
 			ID:             id,
 			Type:           atomType,
-			Name:           name,
-			TenantID:       tenantID,
+			Name:           Intern(name),
+			TenantID:       Intern(tenantID),
 			TruthVal:       TruthValue{Strength: 1.0, Confidence: 1.0},
 			AttentionVal:   AttentionValue{STI: 0, LTI: 0, VLTI: 0},
 			CreatedAt:      now,
 			UpdatedAt:      now,
+			RefreshedAt:    now,
 		},
 		Outgoing: outgoing,
 	}
@@ -180,7 +292,23 @@ func (l *Link) Clone() Atom {
 			AttentionVal: l.AttentionVal,
 			CreatedAt:    l.CreatedAt,
 			UpdatedAt:    l.UpdatedAt,
+			RefreshedAt:  l.RefreshedAt,
+			DecayedAt:    l.DecayedAt,
+			Protected:    l.IsProtected(),
 		},
 		Outgoing: outgoingCopy,
 	}
 }
+
+// WithTenant returns a copy of atom owned by tenantID. Atoms derived from
+// shared or foreign knowledge are re-homed this way before being stored.
+func WithTenant(atom Atom, tenantID string) Atom {
+	clone := atom.Clone()
+	switch a := clone.(type) {
+	case *Node:
+		a.TenantID = Intern(tenantID)
+	case *Link:
+		a.TenantID = Intern(tenantID)
+	}
+	return clone
+}
@@ -1,18 +1,28 @@
 package atomspace
 
 import (
-	"crypto/sha256"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 )
 
+// ErrOtherTenant is returned for an atom ID held by another tenant. Atom IDs
+// are global, so within an AtomSpace only one tenant holds each.
+var ErrOtherTenant = errors.New("atom does not belong to tenant")
+
 // AtomSpace is a thread-safe, multi-tenant knowledge store with concurrent access
 type AtomSpace struct {
 	atoms    map[string]Atom          // atomID -> Atom
-	byTenant map[string]map[string]Atom // tenantID -> atomID -> Atom
+	byTenant map[string]*atomList       // tenantID -> atoms
 	byType   map[AtomType]map[string]Atom // atomType -> atomID -> Atom
 	indices  map[string]map[string]bool  // name -> atomID -> exists (for fast lookups)
+	search   map[string]*nameIndex       // tenantID -> name search index
+	unsearched map[string][]string       // tenantID -> IDs of bulk-added atoms not yet in its search index
+	incoming map[string]map[string]bool  // atomID -> IDs of links with it in their outgoing set
+	generations map[string]uint64        // tenantID -> number of changes to its atoms
 	mu       sync.RWMutex
+	scanSlots chan struct{}              // Pool of goroutines for large scans
 	
 	// Concurrency channels for multiplexed operations
 	addChan    chan atomRequest
@@ -37,12 +47,14 @@ type updateRequest struct {
 	atomID   string
 	tenantID string
 	updater  func(Atom) error
+	force    bool // Also update protected atoms
 	response chan error
 }
 
 type deleteRequest struct {
 	atomID   string
 	tenantID string
+	force    bool // Also delete protected atoms
 	response chan error
 }
 
@@ -50,9 +62,14 @@ type deleteRequest struct {
 func NewAtomSpace(workers int) *AtomSpace {
 	as := &AtomSpace{
 		atoms:      make(map[string]Atom),
-		byTenant:   make(map[string]map[string]Atom),
+		byTenant:   make(map[string]*atomList),
 		byType:     make(map[AtomType]map[string]Atom),
 		indices:    make(map[string]map[string]bool),
+		search:     make(map[string]*nameIndex),
+		unsearched: make(map[string][]string),
+		incoming:   make(map[string]map[string]bool),
+		generations: make(map[string]uint64),
+		scanSlots:  newScanSlots(),
 		addChan:    make(chan atomRequest, 1000),
 		queryChan:  make(chan queryRequest, 1000),
 		updateChan: make(chan updateRequest, 1000),
@@ -77,9 +94,9 @@ func (as *AtomSpace) worker() {
 		case req := <-as.queryChan:
 			req.response <- as.queryAtomsInternal(req.tenantID, req.filter)
 		case req := <-as.updateChan:
-			req.response <- as.updateAtomInternal(req.atomID, req.tenantID, req.updater)
+			req.response <- as.updateAtomInternal(req.atomID, req.tenantID, req.updater, req.force)
 		case req := <-as.deleteChan:
-			req.response <- as.deleteAtomInternal(req.atomID, req.tenantID)
+			req.response <- as.deleteAtomInternal(req.atomID, req.tenantID, req.force)
 		case <-as.done:
 			return
 		}
@@ -100,7 +117,6 @@ func (as *AtomSpace) addAtomInternal(atom Atom) error {
 	
 	atomID := atom.GetID()
 	tenantID := atom.GetTenantID()
-	atomType := atom.GetType()
 	
 	// Check if atom already exists
 	if _, exists := as.atoms[atomID]; exists {
@@ -109,12 +125,29 @@ func (as *AtomSpace) addAtomInternal(atom Atom) error {
 	
 	// Add to main store
 	as.atoms[atomID] = atom
+	as.index(atom)
+	
+	// Add to tenant search index
+	if as.search[tenantID] == nil {
+		as.search[tenantID] = newNameIndex()
+	}
+	as.search[tenantID].add(atom.GetName(), atomID)
+	
+	as.generations[tenantID]++
+	return nil
+}
+
+// index adds a stored atom to the tenant, type, name and incoming indices
+func (as *AtomSpace) index(atom Atom) {
+	atomID := atom.GetID()
+	tenantID := atom.GetTenantID()
+	atomType := atom.GetType()
 	
 	// Add to tenant index
 	if as.byTenant[tenantID] == nil {
-		as.byTenant[tenantID] = make(map[string]Atom)
+		as.byTenant[tenantID] = newAtomList()
 	}
-	as.byTenant[tenantID][atomID] = atom
+	as.byTenant[tenantID].add(atom)
 	
 	// Add to type index
 	if as.byType[atomType] == nil {
@@ -129,7 +162,10 @@ func (as *AtomSpace) addAtomInternal(atom Atom) error {
 	}
 	as.indices[name][atomID] = true
 	
-	return nil
+	// Add to incoming index
+	as.indexIncoming(atom)
+	
+	registerID(atom)
 }
 
 // GetAtom retrieves an atom by ID and tenant
@@ -143,7 +179,7 @@ func (as *AtomSpace) GetAtom(atomID, tenantID string) (Atom, error) {
 	}
 	
 	if atom.GetTenantID() != tenantID {
-		return nil, fmt.Errorf("atom does not belong to tenant %s", tenantID)
+		return nil, fmt.Errorf("%w %s", ErrOtherTenant, tenantID)
 	}
 	
 	return atom, nil
@@ -156,28 +192,37 @@ func (as *AtomSpace) QueryAtoms(tenantID string, filter func(Atom) bool) []Atom
 	return <-response
 }
 
-// queryAtomsInternal is the internal implementation
+// queryAtomsInternal is the internal implementation. Large tenants are
+// scanned in parallel, so the filter may be called concurrently.
 func (as *AtomSpace) queryAtomsInternal(tenantID string, filter func(Atom) bool) []Atom {
 	as.mu.RLock()
 	defer as.mu.RUnlock()
 	
-	var results []Atom
-	tenantAtoms := as.byTenant[tenantID]
-	
-	for _, atom := range tenantAtoms {
-		if filter == nil || filter(atom) {
-			results = append(results, atom)
-		}
+	tenantAtoms := as.byTenant[tenantID].all()
+	if filter == nil {
+		return append([]Atom(nil), tenantAtoms...)
 	}
 	
+	results, _ := as.scan(tenantAtoms, filter, 0)
 	return results
 }
 
+// AllAtoms returns the atoms of every tenant
+func (as *AtomSpace) AllAtoms() []Atom {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+	
+	atoms := make([]Atom, 0, len(as.atoms))
+	for _, atom := range as.atoms {
+		atoms = append(atoms, atom)
+	}
+	return atoms
+}
+
 // GetAtomsByType returns all atoms of a specific type for a tenant
 func (as *AtomSpace) GetAtomsByType(tenantID string, atomType AtomType) []Atom {
-	return as.QueryAtoms(tenantID, func(a Atom) bool {
-		return a.GetType() == atomType
-	})
+	atoms, _ := as.Find(tenantID, Query{Type: &atomType})
+	return atoms
 }
 
 // GetAtomsByName returns all atoms with a specific name for a tenant
@@ -198,7 +243,36 @@ func (as *AtomSpace) GetAtomsByName(tenantID string, name string) []Atom {
 	return results
 }
 
-// UpdateAtom updates an atom using an updater function (thread-safe)
+// indexIncoming records a link against each of its outgoing atoms
+func (as *AtomSpace) indexIncoming(atom Atom) {
+	link, ok := atom.(*Link)
+	if !ok {
+		return
+	}
+	for _, target := range link.GetOutgoing() {
+		if as.incoming[target.GetID()] == nil {
+			as.incoming[target.GetID()] = make(map[string]bool)
+		}
+		as.incoming[target.GetID()][link.GetID()] = true
+	}
+}
+
+// unindexIncoming removes a link from the incoming index
+func (as *AtomSpace) unindexIncoming(atom Atom) {
+	link, ok := atom.(*Link)
+	if !ok {
+		return
+	}
+	for _, target := range link.GetOutgoing() {
+		delete(as.incoming[target.GetID()], link.GetID())
+		if len(as.incoming[target.GetID()]) == 0 {
+			delete(as.incoming, target.GetID())
+		}
+	}
+}
+
+// UpdateAtom updates an atom using an updater function (thread-safe).
+// Protected atoms are refused with ErrProtected.
 func (as *AtomSpace) UpdateAtom(atomID, tenantID string, updater func(Atom) error) error {
 	response := make(chan error, 1)
 	as.updateChan <- updateRequest{atomID: atomID, tenantID: tenantID, updater: updater, response: response}
@@ -206,7 +280,7 @@ func (as *AtomSpace) UpdateAtom(atomID, tenantID string, updater func(Atom) erro
 }
 
 // updateAtomInternal is the internal implementation
-func (as *AtomSpace) updateAtomInternal(atomID, tenantID string, updater func(Atom) error) error {
+func (as *AtomSpace) updateAtomInternal(atomID, tenantID string, updater func(Atom) error, force bool) error {
 	as.mu.Lock()
 	defer as.mu.Unlock()
 	
@@ -216,13 +290,22 @@ func (as *AtomSpace) updateAtomInternal(atomID, tenantID string, updater func(At
 	}
 	
 	if atom.GetTenantID() != tenantID {
-		return fmt.Errorf("atom does not belong to tenant %s", tenantID)
+		return fmt.Errorf("%w %s", ErrOtherTenant, tenantID)
+	}
+	
+	if !force && IsProtected(atom) {
+		return fmt.Errorf("%w: %s", ErrProtected, atomID)
 	}
 	
-	return updater(atom)
+	if err := updater(atom); err != nil {
+		return err
+	}
+	as.generations[tenantID]++
+	return nil
 }
 
-// DeleteAtom removes an atom (thread-safe)
+// DeleteAtom removes an atom (thread-safe). Protected atoms are refused
+// with ErrProtected.
 func (as *AtomSpace) DeleteAtom(atomID, tenantID string) error {
 	response := make(chan error, 1)
 	as.deleteChan <- deleteRequest{atomID: atomID, tenantID: tenantID, response: response}
@@ -230,7 +313,7 @@ func (as *AtomSpace) DeleteAtom(atomID, tenantID string) error {
 }
 
 // deleteAtomInternal is the internal implementation
-func (as *AtomSpace) deleteAtomInternal(atomID, tenantID string) error {
+func (as *AtomSpace) deleteAtomInternal(atomID, tenantID string, force bool) error {
 	as.mu.Lock()
 	defer as.mu.Unlock()
 	
@@ -240,14 +323,18 @@ func (as *AtomSpace) deleteAtomInternal(atomID, tenantID string) error {
 	}
 	
 	if atom.GetTenantID() != tenantID {
-		return fmt.Errorf("atom does not belong to tenant %s", tenantID)
+		return fmt.Errorf("%w %s", ErrOtherTenant, tenantID)
+	}
+	
+	if !force && IsProtected(atom) {
+		return fmt.Errorf("%w: %s", ErrProtected, atomID)
 	}
 	
 	// Remove from main store
 	delete(as.atoms, atomID)
 	
 	// Remove from tenant index
-	delete(as.byTenant[tenantID], atomID)
+	as.byTenant[tenantID].remove(atomID)
 	
 	// Remove from type index
 	delete(as.byType[atom.GetType()], atomID)
@@ -259,9 +346,68 @@ func (as *AtomSpace) deleteAtomInternal(atomID, tenantID string) error {
 		delete(as.indices, name)
 	}
 	
+	// Remove from tenant search index
+	if idx := as.search[tenantID]; idx != nil {
+		idx.remove(name, atomID)
+	}
+	
+	// Remove from incoming index
+	as.unindexIncoming(atom)
+	
+	as.generations[tenantID]++
 	return nil
 }
 
+// PurgeTenant removes all atoms of a tenant along with its indices and
+// returns the number of atoms removed
+func (as *AtomSpace) PurgeTenant(tenantID string) int {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	
+	removed := 0
+	for _, atom := range as.byTenant[tenantID].all() {
+		atomID := atom.GetID()
+		delete(as.atoms, atomID)
+		delete(as.byType[atom.GetType()], atomID)
+		name := atom.GetName()
+		delete(as.indices[name], atomID)
+		if len(as.indices[name]) == 0 {
+			delete(as.indices, name)
+		}
+		as.unindexIncoming(atom)
+		removed++
+	}
+	delete(as.byTenant, tenantID)
+	delete(as.search, tenantID)
+	delete(as.unsearched, tenantID)
+	
+	if removed > 0 {
+		as.generations[tenantID]++
+	}
+	return removed
+}
+
+// Generation returns the number of changes made to a tenant's atoms. It
+// grows with every add, update and delete, so an unchanged generation means
+// unchanged atoms.
+func (as *AtomSpace) Generation(tenantID string) uint64 {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+	return as.generations[tenantID]
+}
+
+// Generations returns the generation of every tenant with changes
+func (as *AtomSpace) Generations() map[string]uint64 {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+	
+	generations := make(map[string]uint64, len(as.generations))
+	for tenantID, generation := range as.generations {
+		generations[tenantID] = generation
+	}
+	return generations
+}
+
 // GetStats returns statistics about the AtomSpace
 func (as *AtomSpace) GetStats(tenantID string) map[string]interface{} {
 	as.mu.RLock()
@@ -270,11 +416,11 @@ func (as *AtomSpace) GetStats(tenantID string) map[string]interface{} {
 	tenantAtoms := as.byTenant[tenantID]
 	
 	stats := map[string]interface{}{
-		"total_atoms": len(tenantAtoms),
+		"total_atoms": tenantAtoms.len(),
 		"atoms_by_type": make(map[AtomType]int),
 	}
 	
-	for _, atom := range tenantAtoms {
+	for _, atom := range tenantAtoms.all() {
 		typeCount := stats["atoms_by_type"].(map[AtomType]int)
 		typeCount[atom.GetType()]++
 	}
@@ -287,12 +433,15 @@ func (as *AtomSpace) Close() {
 	close(as.done)
 }
 
-// GenerateAtomID generates a unique ID for an atom based on its content
-func GenerateAtomID(atomType AtomType, name string, outgoing []Atom) string {
-	h := sha256.New()
-	h.Write([]byte(fmt.Sprintf("%d:%s", atomType, name)))
-	for _, atom := range outgoing {
-		h.Write([]byte(atom.GetID()))
-	}
-	return fmt.Sprintf("%x", h.Sum(nil))
+// CanonicalOutgoingIDs returns the IDs of a link's outgoing atoms in
+// canonical order: as given for ordered link types, sorted for unordered ones
+func CanonicalOutgoingIDs(atomType AtomType, outgoing []Atom) []string {
+	ids := make([]string, len(outgoing))
+	for i, atom := range outgoing {
+		ids[i] = atom.GetID()
+	}
+	if !IsOrderedLinkType(atomType) {
+		sort.Strings(ids)
+	}
+	return ids
 }
@@ -0,0 +1,22 @@
+package atomspace
+
+// NewConceptNode creates the ConceptNode of a name
+func NewConceptNode(name, tenantID string) *Node {
+	return NewNode(GenerateAtomID(ConceptNodeType, name, nil), name, tenantID, ConceptNodeType)
+}
+
+// NewPredicateNode creates the PredicateNode of a name
+func NewPredicateNode(name, tenantID string) *Node {
+	return NewNode(GenerateAtomID(PredicateNodeType, name, nil), name, tenantID, PredicateNodeType)
+}
+
+// Ensure gets an atom, adding it with tv only if it does not exist, so
+// writers recording the same concepts repeatedly leave existing truth values
+// alone
+func Ensure(space AtomSpaceInterface, atom Atom, tv TruthValue) (Atom, error) {
+	if existing, err := space.GetAtom(atom.GetID(), atom.GetTenantID()); err == nil {
+		return existing, nil
+	}
+	atom.SetTruthValue(tv)
+	return atom, space.AddAtom(atom)
+}
@@ -0,0 +1,147 @@
+package atomspace
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// Arena allocates the atoms of a bulk load from slabs, instead of one heap
+// object per atom, and stamps them all with its creation time. Atoms stay
+// valid after the arena is dropped; a slab is freed with its last atom.
+type Arena struct {
+	nodes    []Node
+	links    []Link
+	outgoing []Atom
+	slab     int
+	now      time.Time
+}
+
+// NewArena returns an arena with room for the given numbers of nodes,
+// links and outgoing references. When a slab fills another is allocated.
+func NewArena(nodes, links, outgoing int) *Arena {
+	slab := max(nodes, links, outgoing/2, 64)
+	return &Arena{
+		nodes:    make([]Node, 0, max(nodes, 1)),
+		links:    make([]Link, 0, max(links, 1)),
+		outgoing: make([]Atom, 0, max(outgoing, 1)),
+		slab:     slab,
+		now:      time.Now(),
+	}
+}
+
+// NewNode is NewNode allocated from the arena
+func (a *Arena) NewNode(id, name, tenantID string, atomType AtomType) *Node {
+	if len(a.nodes) == cap(a.nodes) {
+		a.nodes = make([]Node, 0, a.slab)
+	}
+	a.nodes = a.nodes[:len(a.nodes)+1]
+	n := &a.nodes[len(a.nodes)-1]
+	a.init(&n.BaseAtom, id, name, tenantID, atomType)
+	return n
+}
+
+// NewLink is NewLink allocated from the arena, outgoing set included
+func (a *Arena) NewLink(id, name, tenantID string, atomType AtomType, outgoing []Atom) *Link {
+	if len(a.links) == cap(a.links) {
+		a.links = make([]Link, 0, a.slab)
+	}
+	if cap(a.outgoing)-len(a.outgoing) < len(outgoing) {
+		a.outgoing = make([]Atom, 0, max(a.slab, len(outgoing)))
+	}
+
+	start := len(a.outgoing)
+	a.outgoing = append(a.outgoing, outgoing...)
+	held := a.outgoing[start:len(a.outgoing):len(a.outgoing)]
+	if !IsOrderedLinkType(atomType) {
+		// Store symmetric links in canonical order, as NewLink does
+		sort.SliceStable(held, func(i, j int) bool {
+			return held[i].GetID() < held[j].GetID()
+		})
+	}
+
+	a.links = a.links[:len(a.links)+1]
+	l := &a.links[len(a.links)-1]
+	a.init(&l.BaseAtom, id, name, tenantID, atomType)
+	l.Outgoing = held
+	return l
+}
+
+func (a *Arena) init(b *BaseAtom, id, name, tenantID string, atomType AtomType) {
+	b.ID = id
+	b.Type = atomType
+	b.Name = Intern(name)
+	b.TenantID = Intern(tenantID)
+	b.TruthVal = TruthValue{Strength: 1.0, Confidence: 1.0}
+	b.CreatedAt = a.now
+	b.UpdatedAt = a.now
+	b.RefreshedAt = a.now
+}
+
+// AddAtoms adds a batch of atoms under a single lock and without a round
+// trip to the workers per atom. The batch is stored first and indexed
+// after, into maps sized for it; the name search index, the costliest to
+// build, is only built on the tenant's next search. Atoms whose ID is
+// already held, including earlier in the batch, are skipped; the added
+// atoms are returned.
+func (as *AtomSpace) AddAtoms(atoms []Atom) []Atom {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	if len(as.atoms) == 0 {
+		as.atoms = make(map[string]Atom, len(atoms))
+	}
+	added := make([]Atom, 0, len(atoms))
+	for _, atom := range atoms {
+		if _, exists := as.atoms[atom.GetID()]; exists {
+			continue
+		}
+		as.atoms[atom.GetID()] = atom
+		added = append(added, atom)
+	}
+
+	// Size the per-tenant and per-type indices before filling them
+	byTenant := make(map[string]int)
+	byType := make(map[AtomType]int)
+	for _, atom := range added {
+		byTenant[atom.GetTenantID()]++
+		byType[atom.GetType()]++
+	}
+	for tenantID, n := range byTenant {
+		if as.byTenant[tenantID] == nil {
+			as.byTenant[tenantID] = &atomList{pos: make(map[string]int, n)}
+		}
+		as.byTenant[tenantID].atoms = slices.Grow(as.byTenant[tenantID].atoms, n)
+		as.generations[tenantID] += uint64(n)
+	}
+	for atomType, n := range byType {
+		if len(as.byType[atomType]) == 0 {
+			as.byType[atomType] = make(map[string]Atom, n)
+		}
+	}
+
+	for _, atom := range added {
+		as.index(atom)
+		as.unsearched[atom.GetTenantID()] = append(as.unsearched[atom.GetTenantID()], atom.GetID())
+	}
+	return added
+}
+
+// indexSearch adds a tenant's bulk-added atoms to its search index. Those
+// since deleted are skipped. The caller holds the write lock.
+func (as *AtomSpace) indexSearch(tenantID string) {
+	pending := as.unsearched[tenantID]
+	if len(pending) == 0 {
+		return
+	}
+	delete(as.unsearched, tenantID)
+
+	if as.search[tenantID] == nil {
+		as.search[tenantID] = newNameIndex()
+	}
+	for _, atomID := range pending {
+		if atom, held := as.atoms[atomID]; held && atom.GetTenantID() == tenantID {
+			as.search[tenantID].add(atom.GetName(), atomID)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package atomspace
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// IDScheme selects how GenerateAtomID derives the IDs of atoms
+type IDScheme string
+
+const (
+	// IDSchemeSHA256 is the hex SHA-256 of an atom's content: 64 bytes
+	IDSchemeSHA256 IDScheme = "sha256"
+	// IDSchemeHash is the SHA-256 truncated to 128 bits in base32: 26 bytes
+	IDSchemeHash IDScheme = "hash"
+	// IDSchemeULID is a ULID minted the first time the content is seen and
+	// reused for it after, through a content-hash index: 26 bytes, ordered
+	// by creation time
+	IDSchemeULID IDScheme = "ulid"
+)
+
+// ParseIDScheme parses the name of an ID scheme; empty selects the default
+func ParseIDScheme(s string) (IDScheme, error) {
+	switch scheme := IDScheme(s); scheme {
+	case "":
+		return IDSchemeSHA256, nil
+	case IDSchemeSHA256, IDSchemeHash, IDSchemeULID:
+		return scheme, nil
+	}
+	return "", fmt.Errorf("unknown ID scheme %q, want sha256, hash or ulid", s)
+}
+
+// crockford is the base32 alphabet of ULIDs, without ambiguous letters
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var (
+	idScheme   atomic.Value // IDScheme
+	idEncoding = base32.NewEncoding(crockford).WithPadding(base32.NoPadding)
+)
+
+// SetIDScheme selects the ID scheme of atoms created from now on. Atom IDs
+// are global, so the scheme is process-wide. Existing atoms keep their IDs,
+// and with the ULID scheme the content of atoms added to any AtomSpace maps
+// to their ID; the hash schemes derive IDs from content alone, so switching
+// between them makes atoms created again get another ID.
+func SetIDScheme(scheme IDScheme) {
+	idScheme.Store(scheme)
+}
+
+// CurrentIDScheme returns the ID scheme atoms are created with
+func CurrentIDScheme() IDScheme {
+	if scheme, ok := idScheme.Load().(IDScheme); ok {
+		return scheme
+	}
+	return IDSchemeSHA256
+}
+
+// GenerateAtomID returns the ID of the atom with the given content under the
+// current ID scheme. The outgoing set is canonicalized first, so symmetric
+// links connecting the same atoms in a different order get the same ID.
+func GenerateAtomID(atomType AtomType, name string, outgoing []Atom) string {
+	sum := contentHash(atomType, name, outgoing)
+	switch CurrentIDScheme() {
+	case IDSchemeHash:
+		return idEncoding.EncodeToString(sum[:16])
+	case IDSchemeULID:
+		return mintID(sum)
+	default:
+		return hex.EncodeToString(sum[:])
+	}
+}
+
+func contentHash(atomType AtomType, name string, outgoing []Atom) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write([]byte(fmt.Sprintf("%d:%s", atomType, name)))
+	for _, id := range CanonicalOutgoingIDs(atomType, outgoing) {
+		h.Write([]byte(id))
+	}
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// idEntry is the ID minted for some content, with the sweep generation it
+// was minted in
+type idEntry struct {
+	id         string
+	generation uint64
+}
+
+// contentIDs maps the content hashes of atoms, truncated to 128 bits, to the
+// ULIDs minted for them, split in shards like the intern table
+var (
+	contentIDs [internShards]struct {
+		mu  sync.Mutex
+		ids map[[16]byte]idEntry
+	}
+	idGeneration atomic.Uint64
+)
+
+// mintID returns the ULID of some content, minting it on first sight
+func mintID(sum [sha256.Size]byte) string {
+	key := [16]byte(sum[:16])
+	shard := &contentIDs[binary.LittleEndian.Uint64(key[:8])%internShards]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if entry, exists := shard.ids[key]; exists {
+		return entry.id
+	}
+	if shard.ids == nil {
+		shard.ids = make(map[[16]byte]idEntry)
+	}
+	id := newULID()
+	shard.ids[key] = idEntry{id: id, generation: idGeneration.Load()}
+	return id
+}
+
+// registerID maps an atom's content to its ID under the ULID scheme, so
+// atoms restored from snapshots or other processes are found again by their
+// content. Content already mapped keeps its ID.
+func registerID(atom Atom) {
+	if CurrentIDScheme() != IDSchemeULID {
+		return
+	}
+	var outgoing []Atom
+	if link, isLink := atom.(*Link); isLink {
+		outgoing = link.GetOutgoing()
+	}
+	sum := contentHash(atom.GetType(), atom.GetName(), outgoing)
+	key := [16]byte(sum[:16])
+	shard := &contentIDs[binary.LittleEndian.Uint64(key[:8])%internShards]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if _, exists := shard.ids[key]; exists {
+		return
+	}
+	if shard.ids == nil {
+		shard.ids = make(map[[16]byte]idEntry)
+	}
+	shard.ids[key] = idEntry{id: atom.GetID(), generation: idGeneration.Load()}
+}
+
+// SweepIDs drops the minted IDs none of the atoms has, and returns how many
+// were dropped. IDs minted since the previous sweep are kept, as their atoms
+// may not have been added yet.
+func SweepIDs(atoms []Atom) int {
+	live := make(map[string]bool, len(atoms))
+	for _, atom := range atoms {
+		live[atom.GetID()] = true
+	}
+	previous := idGeneration.Add(1) - 1
+
+	dropped := 0
+	for i := range contentIDs {
+		shard := &contentIDs[i]
+		shard.mu.Lock()
+		for key, entry := range shard.ids {
+			if entry.generation < previous && !live[entry.id] {
+				delete(shard.ids, key)
+				dropped++
+			}
+		}
+		shard.mu.Unlock()
+	}
+	return dropped
+}
+
+// newULID returns a ULID: the time in milliseconds and 80 random bits, in
+// 26 base32 characters
+func newULID() string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+	rand.Read(b[6:])
+
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
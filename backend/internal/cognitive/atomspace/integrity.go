@@ -0,0 +1,278 @@
+package atomspace
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// IssueKind is the kind of an inconsistency between the atoms an AtomSpace
+// holds and its indices
+type IssueKind string
+
+const (
+	// IssueDanglingLink is a link whose outgoing set holds an atom that is
+	// no longer stored. Repairs leave such links alone.
+	IssueDanglingLink IssueKind = "dangling_link"
+	// IssueOrphanEntry is an index entry for an atom that is not stored, or
+	// stored under another type or name
+	IssueOrphanEntry IssueKind = "orphan_index_entry"
+	// IssueMissingEntry is a stored atom an index does not list
+	IssueMissingEntry IssueKind = "missing_index_entry"
+	// IssueTenantMismatch is an atom listed in another tenant's index
+	IssueTenantMismatch IssueKind = "tenant_mismatch"
+)
+
+// Indices an integrity issue may be found in, besides the tenant, type and
+// name indices queries read
+const (
+	IndexSearch   = "search"   // Each tenant's name search index
+	IndexIncoming = "incoming" // Links by their outgoing atoms
+)
+
+// IntegrityIssue is an inconsistency found by an integrity check
+type IntegrityIssue struct {
+	Kind     IssueKind `json:"kind"`
+	Index    string    `json:"index,omitempty"` // Empty for dangling links
+	AtomID   string    `json:"atom_id"`
+	TenantID string    `json:"tenant_id,omitempty"`
+	Detail   string    `json:"detail"`
+	Repaired bool      `json:"repaired"`
+}
+
+// IntegrityReport is the outcome of an integrity check
+type IntegrityReport struct {
+	Atoms    int              `json:"atoms"`
+	Links    int              `json:"links"`
+	Issues   []IntegrityIssue `json:"issues"`
+	Repaired int              `json:"repaired"` // Issues corrected by rebuilding the indices
+}
+
+// CheckIntegrity compares the indices of the AtomSpace with the atoms it
+// stores. With repair set, indices found inconsistent are rebuilt from the
+// stored atoms, and the generations of the tenants involved advance.
+//
+// held reports whether the outgoing atom of a link is stored elsewhere,
+// for links spanning AtomSpaces such as shards. It is called after the
+// AtomSpace is unlocked; if nil, only this AtomSpace is looked in.
+func (as *AtomSpace) CheckIntegrity(repair bool, held func(target Atom) bool) IntegrityReport {
+	var report IntegrityReport
+	var missing []danglingRef
+	if repair {
+		as.mu.Lock()
+		report, missing = as.checkIndices()
+		if len(report.Issues) > 0 {
+			as.rebuildIndices(report.Issues)
+			for i := range report.Issues {
+				report.Issues[i].Repaired = true
+			}
+			report.Repaired = len(report.Issues)
+		}
+		as.mu.Unlock()
+	} else {
+		as.mu.RLock()
+		report, missing = as.checkIndices()
+		as.mu.RUnlock()
+	}
+
+	for _, ref := range missing {
+		if held != nil && held(ref.target) {
+			continue
+		}
+		report.Issues = append(report.Issues, IntegrityIssue{
+			Kind:     IssueDanglingLink,
+			AtomID:   ref.link.GetID(),
+			TenantID: ref.link.GetTenantID(),
+			Detail:   fmt.Sprintf("outgoing atom %s is not stored", ref.target.GetID()),
+		})
+	}
+	if report.Issues == nil {
+		report.Issues = make([]IntegrityIssue, 0)
+	}
+	return report
+}
+
+// danglingRef is an outgoing atom of a link the AtomSpace does not store
+type danglingRef struct {
+	link   *Link
+	target Atom
+}
+
+// checkIndices checks every index against the stored atoms and returns the
+// links' outgoing atoms not stored here. The caller holds the lock.
+func (as *AtomSpace) checkIndices() (IntegrityReport, []danglingRef) {
+	report := IntegrityReport{Atoms: len(as.atoms)}
+	issue := func(kind IssueKind, index, atomID, tenantID, format string, args ...interface{}) {
+		report.Issues = append(report.Issues, IntegrityIssue{
+			Kind: kind, Index: index, AtomID: atomID, TenantID: tenantID, Detail: fmt.Sprintf(format, args...),
+		})
+	}
+	stored := func(atom Atom) bool {
+		return atom != nil && as.atoms[atom.GetID()] == atom
+	}
+
+	// Tenant index
+	for tenantID, list := range as.byTenant {
+		for i, atom := range list.all() {
+			switch {
+			case !stored(atom):
+				issue(IssueOrphanEntry, IndexTenant, atomIDOf(atom), tenantID, "listed for tenant %s but not stored", tenantID)
+			case atom.GetTenantID() != tenantID:
+				issue(IssueTenantMismatch, IndexTenant, atom.GetID(), atom.GetTenantID(), "listed for tenant %s", tenantID)
+			case list.pos[atom.GetID()] != i:
+				issue(IssueOrphanEntry, IndexTenant, atom.GetID(), tenantID, "listed at position %d, recorded at %d", i, list.pos[atom.GetID()])
+			}
+		}
+		if len(list.pos) != list.len() {
+			issue(IssueOrphanEntry, IndexTenant, "", tenantID, "%d positions recorded for %d atoms", len(list.pos), list.len())
+		}
+	}
+
+	// Type and name indices
+	for atomType, atoms := range as.byType {
+		for atomID, atom := range atoms {
+			switch {
+			case !stored(atom):
+				issue(IssueOrphanEntry, IndexType, atomID, "", "listed as type %d but not stored", atomType)
+			case atom.GetType() != atomType:
+				issue(IssueOrphanEntry, IndexType, atomID, atom.GetTenantID(), "listed as type %d, stored as type %d", atomType, atom.GetType())
+			}
+		}
+	}
+	for name, ids := range as.indices {
+		for atomID := range ids {
+			atom, exists := as.atoms[atomID]
+			switch {
+			case !exists:
+				issue(IssueOrphanEntry, IndexName, atomID, "", "listed under name %q but not stored", name)
+			case atom.GetName() != name:
+				issue(IssueOrphanEntry, IndexName, atomID, atom.GetTenantID(), "listed under name %q, stored as %q", name, atom.GetName())
+			}
+		}
+	}
+
+	// Search indices, which skip bulk-added atoms until the next search
+	unsearched := make(map[string]map[string]bool, len(as.unsearched))
+	for tenantID, ids := range as.unsearched {
+		unsearched[tenantID] = make(map[string]bool, len(ids))
+		for _, atomID := range ids {
+			unsearched[tenantID][atomID] = true
+		}
+	}
+	for tenantID, idx := range as.search {
+		for folded, ids := range idx.atoms {
+			for atomID := range ids {
+				atom, exists := as.atoms[atomID]
+				switch {
+				case !exists:
+					issue(IssueOrphanEntry, IndexSearch, atomID, tenantID, "searchable as %q but not stored", folded)
+				case atom.GetTenantID() != tenantID:
+					issue(IssueTenantMismatch, IndexSearch, atomID, atom.GetTenantID(), "searchable by tenant %s", tenantID)
+				case strings.ToLower(atom.GetName()) != folded:
+					issue(IssueOrphanEntry, IndexSearch, atomID, tenantID, "searchable as %q, stored as %q", folded, atom.GetName())
+				}
+			}
+		}
+	}
+
+	// Incoming index
+	for targetID, links := range as.incoming {
+		for linkID := range links {
+			link, isLink := as.atoms[linkID].(*Link)
+			if !isLink || !hasOutgoing(link, targetID) {
+				issue(IssueOrphanEntry, IndexIncoming, linkID, "", "listed as a link to %s but no such link is stored", targetID)
+			}
+		}
+	}
+
+	// Every stored atom in every index
+	var missing []danglingRef
+	for atomID, atom := range as.atoms {
+		tenantID := atom.GetTenantID()
+		if list := as.byTenant[tenantID]; list == nil || list.pos[atomID] >= list.len() || list.atoms[list.pos[atomID]] != atom {
+			issue(IssueMissingEntry, IndexTenant, atomID, tenantID, "not listed for its tenant")
+		}
+		if as.byType[atom.GetType()][atomID] != atom {
+			issue(IssueMissingEntry, IndexType, atomID, tenantID, "not listed as type %d", atom.GetType())
+		}
+		if !as.indices[atom.GetName()][atomID] {
+			issue(IssueMissingEntry, IndexName, atomID, tenantID, "not listed under its name %q", atom.GetName())
+		}
+		if idx := as.search[tenantID]; !unsearched[tenantID][atomID] && (idx == nil || !idx.atoms[strings.ToLower(atom.GetName())][atomID]) {
+			issue(IssueMissingEntry, IndexSearch, atomID, tenantID, "not searchable by its name %q", atom.GetName())
+		}
+
+		link, isLink := atom.(*Link)
+		if !isLink {
+			continue
+		}
+		report.Links++
+		for _, target := range link.GetOutgoing() {
+			if !as.incoming[target.GetID()][atomID] {
+				issue(IssueMissingEntry, IndexIncoming, atomID, tenantID, "not listed as a link to %s", target.GetID())
+			}
+			if _, exists := as.atoms[target.GetID()]; !exists {
+				missing = append(missing, danglingRef{link: link, target: target})
+			}
+		}
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.Index != b.Index {
+			return a.Index < b.Index
+		}
+		return a.AtomID < b.AtomID
+	})
+	return report, missing
+}
+
+// rebuildIndices replaces the indices with ones built from the stored
+// atoms and advances the generations of the tenants with issues. The
+// caller holds the write lock.
+func (as *AtomSpace) rebuildIndices(issues []IntegrityIssue) {
+	tenants := make(map[string]int)
+	for _, atom := range as.atoms {
+		tenants[atom.GetTenantID()]++
+	}
+	as.byTenant = make(map[string]*atomList, len(tenants))
+	for tenantID, n := range tenants {
+		as.byTenant[tenantID] = &atomList{atoms: make([]Atom, 0, n), pos: make(map[string]int, n)}
+	}
+	as.byType = make(map[AtomType]map[string]Atom)
+	as.indices = make(map[string]map[string]bool)
+	as.incoming = make(map[string]map[string]bool)
+	as.search = make(map[string]*nameIndex, len(tenants))
+	as.unsearched = make(map[string][]string)
+
+	for atomID, atom := range as.atoms {
+		as.index(atom)
+		tenantID := atom.GetTenantID()
+		if as.search[tenantID] == nil {
+			as.search[tenantID] = newNameIndex()
+		}
+		as.search[tenantID].add(atom.GetName(), atomID)
+	}
+
+	for _, issue := range issues {
+		if issue.TenantID != "" {
+			as.generations[issue.TenantID]++
+		}
+	}
+}
+
+func atomIDOf(atom Atom) string {
+	if atom == nil {
+		return ""
+	}
+	return atom.GetID()
+}
+
+func hasOutgoing(link *Link, atomID string) bool {
+	for _, target := range link.GetOutgoing() {
+		if target.GetID() == atomID {
+			return true
+		}
+	}
+	return false
+}
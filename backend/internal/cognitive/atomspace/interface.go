@@ -10,5 +10,14 @@ type AtomSpaceInterface interface {
 	GetStats(tenantID string) map[string]interface{}
 }
 
-// Ensure AtomSpace implements the interface
-var _ AtomSpaceInterface = (*AtomSpace)(nil)
+// Versioned is implemented by stores that count the changes to each
+// tenant's atoms. Equal generations mean the atoms have not changed.
+type Versioned interface {
+	Generation(tenantID string) uint64
+}
+
+// Ensure AtomSpace implements the interfaces
+var (
+	_ AtomSpaceInterface = (*AtomSpace)(nil)
+	_ Versioned          = (*AtomSpace)(nil)
+)
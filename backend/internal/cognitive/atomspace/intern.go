@@ -0,0 +1,146 @@
+package atomspace
+
+import (
+	"hash/maphash"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+const internShards = 64
+
+// internTable holds the canonical copy of each interned string, split in
+// shards to keep concurrent interning from contending on one lock
+var (
+	internTable [internShards]struct {
+		mu      sync.Mutex
+		strings map[string]string
+	}
+	internSeed = maphash.MakeSeed()
+	interned   atomic.Int64 // Strings passed through Intern
+	internHits atomic.Int64 // Of which already had a canonical copy
+)
+
+// Intern returns the canonical copy of s, so that the names and tenant IDs
+// repeated across many atoms are stored once. The first copy is cloned, so
+// it never pins a larger buffer s was sliced from. Copies no atom uses any
+// more leave the table at the next SweepInterned.
+func Intern(s string) string {
+	if s == "" {
+		return s
+	}
+	interned.Add(1)
+	shard := &internTable[maphash.String(internSeed, s)%internShards]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if canonical, exists := shard.strings[s]; exists {
+		internHits.Add(1)
+		return canonical
+	}
+	if shard.strings == nil {
+		shard.strings = make(map[string]string)
+	}
+	canonical := strings.Clone(s)
+	shard.strings[canonical] = canonical
+	return canonical
+}
+
+// SweepInterned drops the canonical copies of strings that none of the
+// atoms uses as name or tenant ID, and returns how many were dropped.
+// Strings still held elsewhere stay valid; they are only no longer shared.
+func SweepInterned(atoms []Atom) int {
+	live := make(map[string]bool)
+	for _, atom := range atoms {
+		live[atom.GetName()] = true
+		live[atom.GetTenantID()] = true
+	}
+
+	dropped := 0
+	for i := range internTable {
+		shard := &internTable[i]
+		shard.mu.Lock()
+		for s := range shard.strings {
+			if !live[s] {
+				delete(shard.strings, s)
+				dropped++
+			}
+		}
+		shard.mu.Unlock()
+	}
+	return dropped
+}
+
+func internedCount() int {
+	n := 0
+	for i := range internTable {
+		shard := &internTable[i]
+		shard.mu.Lock()
+		n += len(shard.strings)
+		shard.mu.Unlock()
+	}
+	return n
+}
+
+// StringStats measures the memory the names and tenant IDs of atoms take
+type StringStats struct {
+	Interned       int64 `json:"interned"`    // Strings passed through Intern since start
+	InternHits     int64 `json:"intern_hits"` // Of which already had a canonical copy
+	Table          int   `json:"table"`       // Canonical copies held for interning
+	Atoms          int   `json:"atoms"`
+	Names          int   `json:"names"`           // Distinct names
+	TenantIDs      int   `json:"tenant_ids"`      // Distinct tenant IDs
+	Bytes          int64 `json:"bytes"`           // Bytes the strings would take unshared
+	HeldBytes      int64 `json:"held_bytes"`      // Bytes of the distinct copies actually held
+	SavedBytes     int64 `json:"saved_bytes"`     // Bytes - HeldBytes
+	Duplicates     int   `json:"duplicates"`      // Equal strings held in separate copies
+	DuplicateBytes int64 `json:"duplicate_bytes"` // Memory interning them would free
+}
+
+// MeasureStrings counts the copies of atom names and tenant IDs actually
+// held, by the address of their bytes, against what unshared strings would
+// take
+func MeasureStrings(atoms []Atom) StringStats {
+	stats := StringStats{
+		Interned:   interned.Load(),
+		InternHits: internHits.Load(),
+		Table:      internedCount(),
+		Atoms:      len(atoms),
+	}
+	names := make(map[string]bool)
+	tenants := make(map[string]bool)
+	held := make(map[*byte]bool)
+	copies := make(map[string]*byte)
+
+	count := func(s string) {
+		if s == "" {
+			return
+		}
+		stats.Bytes += int64(len(s))
+		data := unsafe.StringData(s)
+		if held[data] {
+			return
+		}
+		held[data] = true
+		stats.HeldBytes += int64(len(s))
+		if first, seen := copies[s]; seen && first != data {
+			stats.Duplicates++
+			stats.DuplicateBytes += int64(len(s))
+		} else {
+			copies[s] = data
+		}
+	}
+	for _, atom := range atoms {
+		name, tenantID := atom.GetName(), atom.GetTenantID()
+		names[name] = true
+		tenants[tenantID] = true
+		count(name)
+		count(tenantID)
+	}
+
+	stats.Names = len(names)
+	stats.TenantIDs = len(tenants)
+	stats.SavedBytes = stats.Bytes - stats.HeldBytes
+	return stats
+}
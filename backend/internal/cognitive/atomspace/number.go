@@ -0,0 +1,42 @@
+package atomspace
+
+import (
+	"math"
+	"strconv"
+)
+
+// NumberName is the canonical name of the NumberNode of a value, so equal
+// values share one atom
+func NumberName(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// NewNumberNode creates the NumberNode of a value
+func NewNumberNode(value float64, tenantID string) *Node {
+	name := NumberName(value)
+	return NewNode(GenerateAtomID(NumberNodeType, name, nil), name, tenantID, NumberNodeType)
+}
+
+// NumberValue returns the value of a NumberNode
+func NumberValue(atom Atom) (float64, bool) {
+	if atom == nil || atom.GetType() != NumberNodeType {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(atom.GetName(), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false
+	}
+	return value, true
+}
+
+// NewGroundedPredicateNode creates the node naming a grounded predicate or
+// function, one whose truth value or result is computed rather than stated
+func NewGroundedPredicateNode(name, tenantID string) *Node {
+	return NewNode(GenerateAtomID(GroundedPredicateNodeType, name, nil), name, tenantID, GroundedPredicateNodeType)
+}
+
+// NewGroundedSchemaNode creates the node naming a grounded schema, a Go
+// function ExecutionLinks call
+func NewGroundedSchemaNode(name, tenantID string) *Node {
+	return NewNode(GenerateAtomID(GroundedSchemaNodeType, name, nil), name, tenantID, GroundedSchemaNodeType)
+}
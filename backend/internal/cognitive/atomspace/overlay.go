@@ -0,0 +1,167 @@
+package atomspace
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Overlay is a copy-on-write view over a base atomspace. Reads see local
+// atoms merged with the base; writes, updates and deletes only ever touch
+// the local layer, so the base is never modified. Overlays back scratch
+// spaces such as interactive sessions and what-if simulations.
+type Overlay struct {
+	base       AtomSpaceInterface
+	local      *AtomSpace
+	tombstones map[string]bool // base atom IDs hidden by local deletes
+	mu         sync.RWMutex
+}
+
+// Ensure Overlay implements the interface
+var _ AtomSpaceInterface = (*Overlay)(nil)
+
+// NewOverlay creates an overlay on top of base
+func NewOverlay(base AtomSpaceInterface, workers int) *Overlay {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &Overlay{
+		base:       base,
+		local:      NewAtomSpace(workers),
+		tombstones: make(map[string]bool),
+	}
+}
+
+// AddAtom adds an atom to the local layer
+func (o *Overlay) AddAtom(atom Atom) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !o.tombstones[atom.GetID()] {
+		if _, err := o.base.GetAtom(atom.GetID(), atom.GetTenantID()); err == nil {
+			return fmt.Errorf("atom with ID %s already exists", atom.GetID())
+		}
+	}
+	if err := o.local.AddAtom(atom); err != nil {
+		return err
+	}
+	delete(o.tombstones, atom.GetID())
+	return nil
+}
+
+// GetAtom retrieves an atom, preferring the local layer
+func (o *Overlay) GetAtom(atomID, tenantID string) (Atom, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if atom, err := o.local.GetAtom(atomID, tenantID); err == nil {
+		return atom, nil
+	}
+	if o.tombstones[atomID] {
+		return nil, fmt.Errorf("atom with ID %s not found", atomID)
+	}
+	return o.base.GetAtom(atomID, tenantID)
+}
+
+// QueryAtoms returns matching atoms from both layers; local copies shadow
+// their base originals
+func (o *Overlay) QueryAtoms(tenantID string, filter func(Atom) bool) []Atom {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	results := o.local.QueryAtoms(tenantID, filter)
+	seen := make(map[string]bool, len(results))
+	for _, atom := range results {
+		seen[atom.GetID()] = true
+	}
+
+	for _, atom := range o.base.QueryAtoms(tenantID, filter) {
+		if seen[atom.GetID()] || o.tombstones[atom.GetID()] {
+			continue
+		}
+		results = append(results, atom)
+	}
+
+	return results
+}
+
+// UpdateAtom updates an atom in the local layer, copying it up from the base
+// first if needed
+func (o *Overlay) UpdateAtom(atomID, tenantID string, updater func(Atom) error) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, err := o.local.GetAtom(atomID, tenantID); err != nil {
+		if o.tombstones[atomID] {
+			return fmt.Errorf("atom with ID %s not found", atomID)
+		}
+		atom, err := o.base.GetAtom(atomID, tenantID)
+		if err != nil {
+			return err
+		}
+		if err := o.local.AddAtom(atom.Clone()); err != nil {
+			return err
+		}
+	}
+
+	// The local copy is scratch, so protection of the base atom does not apply
+	return o.local.ForceUpdateAtom(atomID, tenantID, updater)
+}
+
+// DeleteAtom removes an atom from the view without touching the base
+func (o *Overlay) DeleteAtom(atomID, tenantID string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	localErr := o.local.ForceDeleteAtom(atomID, tenantID)
+	if o.tombstones[atomID] {
+		return localErr
+	}
+	if _, err := o.base.GetAtom(atomID, tenantID); err == nil {
+		o.tombstones[atomID] = true
+		return nil
+	}
+	return localErr
+}
+
+// GetStats returns statistics for the merged view
+func (o *Overlay) GetStats(tenantID string) map[string]interface{} {
+	atoms := o.QueryAtoms(tenantID, nil)
+	atomsByType := make(map[AtomType]int)
+	for _, atom := range atoms {
+		atomsByType[atom.GetType()]++
+	}
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	localStats := o.local.GetStats(tenantID)
+
+	return map[string]interface{}{
+		"total_atoms":   len(atoms),
+		"atoms_by_type": atomsByType,
+		"local_atoms":   localStats["total_atoms"],
+		"hidden_atoms":  len(o.tombstones),
+	}
+}
+
+// LocalAtoms returns only the atoms added or modified in the overlay
+func (o *Overlay) LocalAtoms(tenantID string) []Atom {
+	return o.local.QueryAtoms(tenantID, nil)
+}
+
+// HiddenAtomIDs returns the IDs of base atoms deleted in the overlay
+func (o *Overlay) HiddenAtomIDs() []string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	ids := make([]string, 0, len(o.tombstones))
+	for id := range o.tombstones {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Close releases the local layer's workers
+func (o *Overlay) Close() {
+	o.local.Close()
+}
@@ -0,0 +1,52 @@
+package atomspace
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrProtected is returned for updates and deletes of a protected atom made
+// without the admin override
+var ErrProtected = errors.New("atom is protected")
+
+// Protectable is implemented by atoms that can be protected, such as seed
+// ontology and system configuration atoms. AtomSpace refuses updates and
+// deletes of protected atoms, so agents, decay and pruning leave them as
+// they are; only the Force methods change them.
+type Protectable interface {
+	IsProtected() bool
+	SetProtected(protected bool)
+}
+
+// IsProtected reports whether an atom is protected
+func IsProtected(atom Atom) bool {
+	p, ok := atom.(Protectable)
+	return ok && p.IsProtected()
+}
+
+// ForceUpdateAtom updates an atom whether or not it is protected. It is
+// the admin override, and moves atoms between internal copies.
+func (as *AtomSpace) ForceUpdateAtom(atomID, tenantID string, updater func(Atom) error) error {
+	response := make(chan error, 1)
+	as.updateChan <- updateRequest{atomID: atomID, tenantID: tenantID, updater: updater, force: true, response: response}
+	return <-response
+}
+
+// ForceDeleteAtom removes an atom whether or not it is protected
+func (as *AtomSpace) ForceDeleteAtom(atomID, tenantID string) error {
+	response := make(chan error, 1)
+	as.deleteChan <- deleteRequest{atomID: atomID, tenantID: tenantID, force: true, response: response}
+	return <-response
+}
+
+// SetProtected protects an atom or lifts its protection
+func (as *AtomSpace) SetProtected(atomID, tenantID string, protected bool) error {
+	return as.ForceUpdateAtom(atomID, tenantID, func(atom Atom) error {
+		p, ok := atom.(Protectable)
+		if !ok {
+			return fmt.Errorf("atom %s cannot be protected", atomID)
+		}
+		p.SetProtected(protected)
+		return nil
+	})
+}
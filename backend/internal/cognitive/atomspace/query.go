@@ -0,0 +1,186 @@
+package atomspace
+
+import "strconv"
+
+// Index paths a query can read its candidate atoms from
+const (
+	IndexIDs    = "ids"    // Listed atom IDs, e.g. resolved from labels
+	IndexName   = "name"   // Atoms with the queried name
+	IndexType   = "type"   // Atoms of the queried type
+	IndexTenant = "tenant" // Every atom of the tenant: a full scan
+)
+
+// Range bounds a value, inclusively
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+func (r *Range) contains(v float64) bool {
+	return r == nil || (v >= r.Min && v <= r.Max)
+}
+
+// Query is a structured query over a tenant's atoms. Unlike a filter
+// function it can be inspected, so it is answered from the cheapest index
+// instead of a scan of all the tenant's atoms. Unset fields match any atom.
+type Query struct {
+	Type       *AtomType `json:"type,omitempty"`
+	Name       string    `json:"name,omitempty"`
+	Labels     []string  `json:"labels,omitempty"` // Labels atoms must carry through has_label links
+	IDs        []string  `json:"ids,omitempty"`    // Candidate atom IDs; nil allows any, empty none
+	Strength   *Range    `json:"strength,omitempty"`
+	Confidence *Range    `json:"confidence,omitempty"`
+	Limit      int       `json:"limit,omitempty"` // Most atoms to return; 0 returns all
+}
+
+// Matches reports whether an atom satisfies the query's type, name and
+// truth value constraints. Labels and IDs are not checked.
+func (q Query) Matches(atom Atom) bool {
+	if q.Type != nil && atom.GetType() != *q.Type {
+		return false
+	}
+	if q.Name != "" && atom.GetName() != q.Name {
+		return false
+	}
+	tv := atom.GetTruthValue()
+	return q.Strength.contains(tv.Strength) && q.Confidence.contains(tv.Confidence)
+}
+
+// Plan describes how a query was answered: the index its candidates were
+// read from, and the checks applied to each candidate
+type Plan struct {
+	Index        string         `json:"index"`
+	Key          string         `json:"key,omitempty"`
+	Candidates   int            `json:"candidates"`
+	Results      int            `json:"results"`
+	Workers      int            `json:"workers"` // Goroutines the candidates were checked on
+	Filters      []string       `json:"filters,omitempty"`
+	Alternatives map[string]int `json:"alternatives"` // Candidates each usable index would have read
+}
+
+// plan picks the usable index with the fewest candidates. The name and
+// type indices span tenants, so for a small tenant the scan can be cheaper.
+func (as *AtomSpace) plan(tenantID string, q Query) Plan {
+	alternatives := map[string]int{IndexTenant: as.byTenant[tenantID].len()}
+	if q.IDs != nil {
+		alternatives[IndexIDs] = len(q.IDs)
+	}
+	if q.Name != "" {
+		alternatives[IndexName] = len(as.indices[q.Name])
+	}
+	if q.Type != nil {
+		alternatives[IndexType] = len(as.byType[*q.Type])
+	}
+
+	p := Plan{Index: IndexTenant, Candidates: alternatives[IndexTenant], Alternatives: alternatives}
+	// Ties go to the earlier, more selective index
+	for _, index := range []string{IndexType, IndexName, IndexIDs} {
+		if n, usable := alternatives[index]; usable && n <= p.Candidates {
+			p.Index, p.Candidates = index, n
+		}
+	}
+
+	if p.Index != IndexTenant {
+		p.Filters = append(p.Filters, "tenant")
+	}
+	switch p.Index {
+	case IndexName:
+		p.Key = q.Name
+	case IndexType:
+		p.Key = strconv.Itoa(int(*q.Type))
+	}
+	if q.Type != nil && p.Index != IndexType {
+		p.Filters = append(p.Filters, "type")
+	}
+	if q.Name != "" && p.Index != IndexName {
+		p.Filters = append(p.Filters, "name")
+	}
+	if q.IDs != nil && p.Index != IndexIDs {
+		p.Filters = append(p.Filters, "ids")
+	}
+	if q.Strength != nil {
+		p.Filters = append(p.Filters, "strength")
+	}
+	if q.Confidence != nil {
+		p.Filters = append(p.Filters, "confidence")
+	}
+	return p
+}
+
+// Find returns a tenant's atoms matching a query, read from the index the
+// planner picked, along with the plan. Labels must already be resolved to
+// IDs. A full scan of a large tenant is split across scan workers, which
+// stop as soon as the limit is reached.
+func (as *AtomSpace) Find(tenantID string, q Query) ([]Atom, Plan) {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	p := as.plan(tenantID, q)
+
+	var ids map[string]bool
+	if q.IDs != nil && p.Index != IndexIDs {
+		ids = make(map[string]bool, len(q.IDs))
+		for _, atomID := range q.IDs {
+			ids[atomID] = true
+		}
+	}
+
+	matches := func(atom Atom) bool {
+		if atom == nil || atom.GetTenantID() != tenantID || !q.Matches(atom) {
+			return false
+		}
+		return ids == nil || ids[atom.GetID()]
+	}
+
+	var results []Atom
+	// match adds an atom read from an index and reports whether the limit
+	// has been reached
+	match := func(atom Atom) bool {
+		if matches(atom) {
+			results = append(results, atom)
+		}
+		return q.Limit > 0 && len(results) >= q.Limit
+	}
+
+	p.Workers = 1
+	switch p.Index {
+	case IndexIDs:
+		for _, atomID := range q.IDs {
+			if match(as.atoms[atomID]) {
+				break
+			}
+		}
+	case IndexName:
+		for atomID := range as.indices[q.Name] {
+			if match(as.atoms[atomID]) {
+				break
+			}
+		}
+	case IndexType:
+		for _, atom := range as.byType[*q.Type] {
+			if match(atom) {
+				break
+			}
+		}
+	default:
+		results, p.Workers = as.scan(as.byTenant[tenantID].all(), matches, q.Limit)
+	}
+
+	p.Results = len(results)
+	return results, p
+}
+
+// Incoming returns a tenant's links that have the atom among their
+// outgoing atoms. The atom itself need not be held here.
+func (as *AtomSpace) Incoming(tenantID, atomID string) []Atom {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	var links []Atom
+	for linkID := range as.incoming[atomID] {
+		if link := as.atoms[linkID]; link != nil && link.GetTenantID() == tenantID {
+			links = append(links, link)
+		}
+	}
+	return links
+}
@@ -0,0 +1,140 @@
+package atomspace
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// parallelScanMin is the fewest atoms a scan is split across workers for
+	parallelScanMin = 16384
+	// scanChunk is the number of atoms a scan worker takes at a time
+	scanChunk = 4096
+)
+
+// atomList holds a tenant's atoms in a slice, so scans can split them into
+// chunks, with each atom's position for constant-time removal
+type atomList struct {
+	atoms []Atom
+	pos   map[string]int
+}
+
+func newAtomList() *atomList {
+	return &atomList{pos: make(map[string]int)}
+}
+
+func (l *atomList) add(atom Atom) {
+	l.pos[atom.GetID()] = len(l.atoms)
+	l.atoms = append(l.atoms, atom)
+}
+
+// remove swaps the last atom into the removed atom's place
+func (l *atomList) remove(atomID string) {
+	i, exists := l.pos[atomID]
+	if !exists {
+		return
+	}
+	last := len(l.atoms) - 1
+	if i != last {
+		l.atoms[i] = l.atoms[last]
+		l.pos[l.atoms[i].GetID()] = i
+	}
+	l.atoms[last] = nil
+	l.atoms = l.atoms[:last]
+	delete(l.pos, atomID)
+}
+
+func (l *atomList) len() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.atoms)
+}
+
+func (l *atomList) all() []Atom {
+	if l == nil {
+		return nil
+	}
+	return l.atoms
+}
+
+// newScanSlots returns the pool of goroutines an AtomSpace's scans may run
+// on besides their callers, shared by concurrent scans
+func newScanSlots() chan struct{} {
+	return make(chan struct{}, runtime.GOMAXPROCS(0)-1)
+}
+
+// scan returns the atoms matching a predicate, stopping once limit atoms
+// (if positive) are found. Large scans are split into chunks evaluated by
+// the caller and as many pool workers as are free; match must therefore be
+// safe to call concurrently. It returns the number of goroutines used.
+func (as *AtomSpace) scan(atoms []Atom, match func(Atom) bool, limit int) ([]Atom, int) {
+	if len(atoms) < parallelScanMin {
+		var results []Atom
+		for _, atom := range atoms {
+			if match(atom) {
+				results = append(results, atom)
+				if len(results) == limit {
+					break
+				}
+			}
+		}
+		return results, 1
+	}
+
+	var next, found atomic.Int64
+	work := func() []Atom {
+		var results []Atom
+		for {
+			if limit > 0 && found.Load() >= int64(limit) {
+				return results
+			}
+			start := int(next.Add(scanChunk)) - scanChunk
+			if start >= len(atoms) {
+				return results
+			}
+			end := min(start+scanChunk, len(atoms))
+			for _, atom := range atoms[start:end] {
+				if match(atom) {
+					results = append(results, atom)
+					if limit > 0 && found.Add(1) >= int64(limit) {
+						return results
+					}
+				}
+			}
+		}
+	}
+
+	chunks := (len(atoms) + scanChunk - 1) / scanChunk
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var results []Atom
+	workers := 1
+acquire:
+	for workers < chunks {
+		select {
+		case as.scanSlots <- struct{}{}:
+		default:
+			break acquire
+		}
+		workers++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-as.scanSlots }()
+			partial := work()
+			mu.Lock()
+			results = append(results, partial...)
+			mu.Unlock()
+		}()
+	}
+	partial := work()
+	wg.Wait()
+	results = append(results, partial...)
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, workers
+}
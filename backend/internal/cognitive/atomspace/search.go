@@ -0,0 +1,318 @@
+package atomspace
+
+import (
+	"sort"
+	"strings"
+)
+
+// SearchMode selects how a name search query is matched
+type SearchMode string
+
+const (
+	SearchModeAuto      SearchMode = "auto" // exact, prefix, substring, then fuzzy
+	SearchModePrefix    SearchMode = "prefix"
+	SearchModeSubstring SearchMode = "substring"
+	SearchModeFuzzy     SearchMode = "fuzzy"
+)
+
+// Scores assigned per match kind so better matches rank first
+const (
+	scoreExact     = 1.0
+	scorePrefix    = 0.9
+	scoreSubstring = 0.7
+	scoreFuzzyMax  = 0.6
+)
+
+// SearchResult is a single atom matched by a name search
+type SearchResult struct {
+	Atom  Atom
+	Score float64
+	Match SearchMode
+}
+
+// nameIndex is a per-tenant search index over atom names. Names are folded
+// to lower case; a trie serves prefix queries and a trigram inverted index
+// narrows substring and fuzzy candidates.
+type nameIndex struct {
+	atoms    map[string]map[string]bool // folded name -> atomID -> exists
+	trie     *trieNode
+	trigrams map[string]map[string]bool // trigram -> folded name -> exists
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	terminal bool
+}
+
+func newNameIndex() *nameIndex {
+	return &nameIndex{
+		atoms:    make(map[string]map[string]bool),
+		trie:     &trieNode{children: make(map[rune]*trieNode)},
+		trigrams: make(map[string]map[string]bool),
+	}
+}
+
+func (idx *nameIndex) add(name, atomID string) {
+	folded := strings.ToLower(name)
+	if idx.atoms[folded] == nil {
+		idx.atoms[folded] = make(map[string]bool)
+
+		node := idx.trie
+		for _, r := range folded {
+			child := node.children[r]
+			if child == nil {
+				child = &trieNode{children: make(map[rune]*trieNode)}
+				node.children[r] = child
+			}
+			node = child
+		}
+		node.terminal = true
+
+		for _, tg := range trigrams(folded) {
+			if idx.trigrams[tg] == nil {
+				idx.trigrams[tg] = make(map[string]bool)
+			}
+			idx.trigrams[tg][folded] = true
+		}
+	}
+	idx.atoms[folded][atomID] = true
+}
+
+func (idx *nameIndex) remove(name, atomID string) {
+	folded := strings.ToLower(name)
+	ids := idx.atoms[folded]
+	if ids == nil {
+		return
+	}
+	delete(ids, atomID)
+	if len(ids) > 0 {
+		return
+	}
+	delete(idx.atoms, folded)
+
+	for _, tg := range trigrams(folded) {
+		delete(idx.trigrams[tg], folded)
+		if len(idx.trigrams[tg]) == 0 {
+			delete(idx.trigrams, tg)
+		}
+	}
+
+	// Unmark the terminal and prune now-empty branches
+	path := []*trieNode{idx.trie}
+	runes := []rune(folded)
+	for _, r := range runes {
+		next := path[len(path)-1].children[r]
+		if next == nil {
+			return
+		}
+		path = append(path, next)
+	}
+	path[len(path)-1].terminal = false
+	for i := len(runes) - 1; i >= 0; i-- {
+		node := path[i+1]
+		if node.terminal || len(node.children) > 0 {
+			break
+		}
+		delete(path[i].children, runes[i])
+	}
+}
+
+// search returns matching folded names with their score and match kind
+func (idx *nameIndex) search(query string, mode SearchMode) map[string]SearchResult {
+	q := strings.ToLower(strings.TrimSpace(query))
+	matches := make(map[string]SearchResult)
+	if q == "" {
+		return matches
+	}
+
+	record := func(name string, score float64, kind SearchMode) {
+		if existing, ok := matches[name]; !ok || existing.Score < score {
+			matches[name] = SearchResult{Score: score, Match: kind}
+		}
+	}
+
+	if mode == SearchModeAuto || mode == SearchModePrefix {
+		for _, name := range idx.prefixNames(q) {
+			if name == q {
+				record(name, scoreExact, SearchModePrefix)
+			} else {
+				record(name, scorePrefix, SearchModePrefix)
+			}
+		}
+	}
+
+	if mode == SearchModeAuto || mode == SearchModeSubstring {
+		for _, name := range idx.candidates(q, true) {
+			if strings.Contains(name, q) {
+				record(name, scoreSubstring, SearchModeSubstring)
+			}
+		}
+	}
+
+	if mode == SearchModeAuto || mode == SearchModeFuzzy {
+		maxDist := len([]rune(q)) / 4
+		if maxDist < 1 {
+			maxDist = 1
+		}
+		for _, name := range idx.candidates(q, false) {
+			if _, ok := matches[name]; ok {
+				continue
+			}
+			dist := levenshtein(q, name)
+			if dist > maxDist {
+				continue
+			}
+			longest := len([]rune(name))
+			if l := len([]rune(q)); l > longest {
+				longest = l
+			}
+			record(name, scoreFuzzyMax*(1-float64(dist)/float64(longest)), SearchModeFuzzy)
+		}
+	}
+
+	return matches
+}
+
+// prefixNames walks the trie and collects every indexed name under prefix
+func (idx *nameIndex) prefixNames(prefix string) []string {
+	node := idx.trie
+	for _, r := range prefix {
+		node = node.children[r]
+		if node == nil {
+			return nil
+		}
+	}
+
+	var names []string
+	var walk func(n *trieNode, acc []rune)
+	walk = func(n *trieNode, acc []rune) {
+		if n.terminal {
+			names = append(names, string(acc))
+		}
+		for r, child := range n.children {
+			walk(child, append(acc, r))
+		}
+	}
+	walk(node, []rune(prefix))
+
+	return names
+}
+
+// candidates narrows the name set using the trigram index. With requireAll
+// a name must contain every trigram of the query (substring search);
+// otherwise sharing any trigram is enough (fuzzy search). Queries too short
+// to form trigrams fall back to all names.
+func (idx *nameIndex) candidates(q string, requireAll bool) []string {
+	grams := trigrams(q)
+	if len(grams) == 0 {
+		names := make([]string, 0, len(idx.atoms))
+		for name := range idx.atoms {
+			names = append(names, name)
+		}
+		return names
+	}
+
+	counts := make(map[string]int)
+	for _, tg := range grams {
+		for name := range idx.trigrams[tg] {
+			counts[name]++
+		}
+	}
+
+	names := make([]string, 0, len(counts))
+	for name, count := range counts {
+		if !requireAll || count == len(grams) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// trigrams returns the distinct 3-rune substrings of s
+func trigrams(s string) []string {
+	runes := []rune(s)
+	if len(runes) < 3 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var grams []string
+	for i := 0; i+3 <= len(runes); i++ {
+		tg := string(runes[i : i+3])
+		if !seen[tg] {
+			seen[tg] = true
+			grams = append(grams, tg)
+		}
+	}
+	return grams
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// SearchAtoms searches atom names of a tenant. Results are ordered by score
+// (best first) and truncated to limit when limit > 0.
+func (as *AtomSpace) SearchAtoms(tenantID, query string, mode SearchMode, limit int) []SearchResult {
+	as.mu.RLock()
+	if len(as.unsearched[tenantID]) > 0 {
+		as.mu.RUnlock()
+		as.mu.Lock()
+		as.indexSearch(tenantID)
+		as.mu.Unlock()
+		as.mu.RLock()
+	}
+	defer as.mu.RUnlock()
+
+	idx := as.search[tenantID]
+	if idx == nil {
+		return nil
+	}
+
+	var results []SearchResult
+	for name, match := range idx.search(query, mode) {
+		for atomID := range idx.atoms[name] {
+			atom, exists := as.atoms[atomID]
+			if !exists {
+				continue
+			}
+			results = append(results, SearchResult{Atom: atom, Score: match.Score, Match: match.Match})
+		}
+	}
+
+	SortSearchResults(results)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// SortSearchResults orders results by descending score, then by name
+func SortSearchResults(results []SearchResult) {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Atom.GetName() != results[j].Atom.GetName() {
+			return results[i].Atom.GetName() < results[j].Atom.GetName()
+		}
+		return results[i].Atom.GetID() < results[j].Atom.GetID()
+	})
+}
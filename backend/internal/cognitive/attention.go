@@ -0,0 +1,40 @@
+package cognitive
+
+import (
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/attention"
+)
+
+// SetAttentionParams replaces a tenant's attention dynamics: the STI decay
+// rate, the boosts important atoms earn and the size of the attentional
+// focus. Attention agents and allocation stages follow them from their
+// next run.
+func (ce *CognitiveEngine) SetAttentionParams(tenantID string, p attention.Params) (attention.Params, error) {
+	return ce.attentionParams.Set(tenantID, p)
+}
+
+// GetAttentionParams returns a tenant's attention params, the default ones
+// if the tenant has not set its own, and whether it has
+func (ce *CognitiveEngine) GetAttentionParams(tenantID string) (attention.Params, bool) {
+	return ce.attentionParams.Get(tenantID)
+}
+
+// DeleteAttentionParams restores a tenant's default attention params
+func (ce *CognitiveEngine) DeleteAttentionParams(tenantID string) error {
+	return ce.attentionParams.Delete(tenantID)
+}
+
+// GetAttentionalFocus returns the atoms of a tenant with the highest
+// short-term importance, as many as its focus size
+func (ce *CognitiveEngine) GetAttentionalFocus(tenantID string) ([]atomspace.Atom, error) {
+	ce.mu.RLock()
+	_, exists := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+	p := ce.attentionParams.Params(tenantID)
+	return p.Focus(ce.QueryAtoms(tenantID, nil)), nil
+}
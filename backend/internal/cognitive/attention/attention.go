@@ -0,0 +1,175 @@
+package attention
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// MaxFocusSize bounds the attentional focus a tenant may ask for
+const MaxFocusSize = 10000
+
+// Boost raises the importance of atoms whose truth value is strictly above
+// its minimums, each time attention is allocated
+type Boost struct {
+	MinStrength   float64 `json:"min_strength"`
+	MinConfidence float64 `json:"min_confidence"`
+	STI           int16   `json:"sti"`
+	LTI           int16   `json:"lti,omitempty"`
+}
+
+// Params are a tenant's attention dynamics: how fast short-term importance
+// decays, which atoms are boosted, and how many atoms the attentional
+// focus holds
+type Params struct {
+	DecayRate float64 `json:"decay_rate"` // Fraction of STI kept per allocation
+	Boosts    []Boost `json:"boosts"`     // Every matching boost applies, after the decay
+	FocusSize int     `json:"focus_size"` // Atoms of highest STI in the focus
+}
+
+// DefaultParams keeps 95% of STI per allocation, boosts atoms of strength
+// and confidence above 0.8 by 10 STI and 1 LTI, and holds 100 atoms in
+// focus
+func DefaultParams() Params {
+	return Params{
+		DecayRate: 0.95,
+		Boosts:    []Boost{{MinStrength: 0.8, MinConfidence: 0.8, STI: 10, LTI: 1}},
+		FocusSize: 100,
+	}
+}
+
+// Validate checks params
+func (p *Params) Validate() error {
+	if p.DecayRate < 0 || p.DecayRate > 1 {
+		return fmt.Errorf("decay_rate must be between 0 and 1")
+	}
+	if p.FocusSize < 1 || p.FocusSize > MaxFocusSize {
+		return fmt.Errorf("focus_size must be between 1 and %d", MaxFocusSize)
+	}
+	for i, b := range p.Boosts {
+		if b.MinStrength < 0 || b.MinStrength > 1 || b.MinConfidence < 0 || b.MinConfidence > 1 {
+			return fmt.Errorf("boost %d: min_strength and min_confidence must be between 0 and 1", i)
+		}
+	}
+	return nil
+}
+
+// Apply decays an attention value and applies the boosts its truth value
+// earns, reporting whether any did
+func (p *Params) Apply(av atomspace.AttentionValue, tv atomspace.TruthValue) (atomspace.AttentionValue, bool) {
+	av.STI = int16(float64(av.STI) * p.DecayRate)
+	boosted := false
+	for _, b := range p.Boosts {
+		if tv.Strength > b.MinStrength && tv.Confidence > b.MinConfidence {
+			av.STI = addImportance(av.STI, b.STI)
+			av.LTI = addImportance(av.LTI, b.LTI)
+			boosted = true
+		}
+	}
+	return av, boosted
+}
+
+// addImportance adds importance without wrapping around
+func addImportance(value, delta int16) int16 {
+	sum := int32(value) + int32(delta)
+	switch {
+	case sum > 32767:
+		return 32767
+	case sum < -32768:
+		return -32768
+	}
+	return int16(sum)
+}
+
+// Focus returns the FocusSize atoms of highest STI, by STI then ID
+func (p *Params) Focus(atoms []atomspace.Atom) []atomspace.Atom {
+	focus := append([]atomspace.Atom(nil), atoms...)
+	sort.Slice(focus, func(i, j int) bool {
+		si, sj := focus[i].GetAttentionValue().STI, focus[j].GetAttentionValue().STI
+		if si != sj {
+			return si > sj
+		}
+		return focus[i].GetID() < focus[j].GetID()
+	})
+	if len(focus) > p.FocusSize {
+		focus = focus[:p.FocusSize]
+	}
+	return focus
+}
+
+func (p *Params) clone() Params {
+	c := *p
+	c.Boosts = append([]Boost{}, p.Boosts...)
+	return c
+}
+
+// Registry holds each tenant's attention params. Tenants without their own
+// use the default params.
+type Registry struct {
+	params map[string]Params // tenantID -> params
+	mu     sync.RWMutex
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{params: make(map[string]Params)}
+}
+
+// Set replaces a tenant's params. They apply from the next allocation.
+func (r *Registry) Set(tenantID string, p Params) (Params, error) {
+	if err := p.Validate(); err != nil {
+		return Params{}, err
+	}
+	stored := p.clone()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.params[tenantID] = stored
+	return stored.clone(), nil
+}
+
+// Get returns a tenant's params and whether the tenant set them
+func (r *Registry) Get(tenantID string) (Params, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, exists := r.params[tenantID]
+	if !exists {
+		return DefaultParams(), false
+	}
+	return p.clone(), true
+}
+
+// Params returns a tenant's params, the default ones if it set none
+func (r *Registry) Params(tenantID string) Params {
+	p, _ := r.Get(tenantID)
+	return p
+}
+
+// Delete restores a tenant's default params
+func (r *Registry) Delete(tenantID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.params[tenantID]; !exists {
+		return fmt.Errorf("tenant %s has no attention params", tenantID)
+	}
+	delete(r.params, tenantID)
+	return nil
+}
+
+// Purge removes a tenant's params and returns 1 if it had any
+func (r *Registry) Purge(tenantID string) int {
+	if r.Delete(tenantID) != nil {
+		return 0
+	}
+	return 1
+}
+
+// GetStats returns registry statistics
+func (r *Registry) GetStats() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return map[string]interface{}{
+		"tenants": len(r.params),
+	}
+}
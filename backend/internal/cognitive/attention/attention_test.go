@@ -0,0 +1,70 @@
+package attention
+
+import (
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func TestParams(t *testing.T) {
+	for _, p := range []Params{
+		{DecayRate: 1.2, FocusSize: 10},
+		{DecayRate: 0.9, FocusSize: 0},
+		{DecayRate: 0.9, FocusSize: MaxFocusSize + 1},
+		{DecayRate: 0.9, FocusSize: 10, Boosts: []Boost{{MinStrength: 2}}},
+	} {
+		if err := p.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", p)
+		}
+	}
+
+	p := DefaultParams()
+	av, boosted := p.Apply(atomspace.AttentionValue{STI: 100}, atomspace.TruthValue{Strength: 0.9, Confidence: 0.9})
+	if !boosted || av.STI != 105 || av.LTI != 1 {
+		t.Errorf("Apply = %+v, %v", av, boosted)
+	}
+	if av, boosted := p.Apply(atomspace.AttentionValue{STI: 100}, atomspace.TruthValue{Strength: 0.9, Confidence: 0.5}); boosted || av.STI != 95 {
+		t.Errorf("Apply of a weak atom = %+v, %v", av, boosted)
+	}
+
+	// Every matching boost applies, without wrapping around
+	p = Params{DecayRate: 1, FocusSize: 1, Boosts: []Boost{{STI: 30000}, {MinConfidence: 0.5, STI: 30000}}}
+	if av, _ := p.Apply(atomspace.AttentionValue{}, atomspace.TruthValue{Strength: 0.1, Confidence: 0.9}); av.STI != 32767 {
+		t.Errorf("expected STI capped, got %d", av.STI)
+	}
+}
+
+func TestFocus(t *testing.T) {
+	atoms := make([]atomspace.Atom, 0)
+	for i, sti := range []int16{5, 50, 20, 50} {
+		n := atomspace.NewNode(string(rune('a'+i)), "n", "t", atomspace.ConceptNodeType)
+		n.SetAttentionValue(atomspace.AttentionValue{STI: sti})
+		atoms = append(atoms, n)
+	}
+	p := Params{DecayRate: 1, FocusSize: 3}
+	focus := p.Focus(atoms)
+	if len(focus) != 3 || focus[0].GetID() != "b" || focus[1].GetID() != "d" || focus[2].GetID() != "c" {
+		t.Errorf("Focus = %v", focus)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	if p, custom := r.Get("t"); custom || p.DecayRate != 0.95 {
+		t.Errorf("expected the default params, got %+v", p)
+	}
+	if _, err := r.Set("t", Params{DecayRate: 2, FocusSize: 1}); err == nil {
+		t.Error("expected invalid params rejected")
+	}
+	set, err := r.Set("t", Params{DecayRate: 0.5, FocusSize: 10, Boosts: []Boost{{STI: 1}}})
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	set.Boosts[0].STI = 99
+	if p := r.Params("t"); p.DecayRate != 0.5 || p.Boosts[0].STI != 1 {
+		t.Errorf("Params = %+v", p)
+	}
+	if removed := r.Purge("t"); removed != 1 || r.Delete("t") == nil {
+		t.Errorf("Purge = %d", removed)
+	}
+}
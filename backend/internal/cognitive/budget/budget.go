@@ -0,0 +1,267 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Action is what the scheduler does with a tenant whose budget is exhausted
+type Action string
+
+const (
+	// ActionDeprioritize runs the tenant's agents after all other agents
+	ActionDeprioritize Action = "deprioritize"
+	// ActionPause skips the tenant's agents until the window resets
+	ActionPause Action = "pause"
+)
+
+// Budget limits the resources a tenant's agents may consume per window. A
+// zero limit is unlimited.
+type Budget struct {
+	Window        time.Duration `json:"window_ns"`
+	MaxWallTime   time.Duration `json:"max_wall_time_ns"`
+	MaxIterations int64         `json:"max_iterations"`
+	Action        Action        `json:"action"`
+}
+
+// Validate checks the budget for consistency
+func (b Budget) Validate() error {
+	if b.Window <= 0 {
+		return fmt.Errorf("budget window must be positive")
+	}
+	if b.MaxWallTime < 0 || b.MaxIterations < 0 {
+		return fmt.Errorf("budget limits must not be negative")
+	}
+	switch b.Action {
+	case ActionDeprioritize, ActionPause:
+	default:
+		return fmt.Errorf("unknown budget action: %s", b.Action)
+	}
+	return nil
+}
+
+// Usage is the resources consumed within a window
+type Usage struct {
+	Runs       int64         `json:"runs"`
+	WallTime   time.Duration `json:"wall_time_ns"`
+	Iterations int64         `json:"iterations"`
+}
+
+func (u *Usage) add(wallTime time.Duration, iterations int64) {
+	u.Runs++
+	u.WallTime += wallTime
+	u.Iterations += iterations
+}
+
+// Meter counts inference iterations consumed while running an agent. It is
+// carried in the run context so the inference engine can charge it.
+type Meter struct {
+	iterations atomic.Int64
+}
+
+// AddIterations charges inference iterations. A nil meter ignores the charge.
+func (m *Meter) AddIterations(n int64) {
+	if m != nil {
+		m.iterations.Add(n)
+	}
+}
+
+// Iterations returns the iterations charged so far
+func (m *Meter) Iterations() int64 {
+	if m == nil {
+		return 0
+	}
+	return m.iterations.Load()
+}
+
+type meterKey struct{}
+
+// WithMeter attaches a meter to a context
+func WithMeter(ctx context.Context, m *Meter) context.Context {
+	return context.WithValue(ctx, meterKey{}, m)
+}
+
+// MeterFrom returns the meter of a context, or nil
+func MeterFrom(ctx context.Context) *Meter {
+	m, _ := ctx.Value(meterKey{}).(*Meter)
+	return m
+}
+
+// tenantAccount tracks a tenant's consumption in the current window
+type tenantAccount struct {
+	windowStart time.Time
+	usage       Usage
+	agents      map[string]*Usage // agentID -> usage in the window
+	exhausted   int64             // windows in which the budget ran out
+}
+
+// Tracker accounts agent resource usage per tenant and enforces budgets
+type Tracker struct {
+	budgets  map[string]Budget // tenantID -> budget
+	accounts map[string]*tenantAccount
+	mu       sync.Mutex
+}
+
+// NewTracker creates an empty usage tracker
+func NewTracker() *Tracker {
+	return &Tracker{
+		budgets:  make(map[string]Budget),
+		accounts: make(map[string]*tenantAccount),
+	}
+}
+
+// SetBudget sets a tenant's budget and starts a new window
+func (t *Tracker) SetBudget(tenantID string, b Budget) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.budgets[tenantID] = b
+	t.account(tenantID).reset(time.Now())
+	return nil
+}
+
+// RemoveBudget lifts a tenant's budget; usage is still accounted
+func (t *Tracker) RemoveBudget(tenantID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.budgets, tenantID)
+}
+
+// GetBudget returns a tenant's budget
+func (t *Tracker) GetBudget(tenantID string) (Budget, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b, exists := t.budgets[tenantID]
+	return b, exists
+}
+
+func (t *Tracker) account(tenantID string) *tenantAccount {
+	acct, exists := t.accounts[tenantID]
+	if !exists {
+		acct = &tenantAccount{windowStart: time.Now(), agents: make(map[string]*Usage)}
+		t.accounts[tenantID] = acct
+	}
+	return acct
+}
+
+func (a *tenantAccount) reset(now time.Time) {
+	a.windowStart = now
+	a.usage = Usage{}
+	a.agents = make(map[string]*Usage)
+}
+
+// rollLocked starts a new window when the current one has elapsed
+func (t *Tracker) rollLocked(tenantID string, now time.Time) *tenantAccount {
+	acct := t.account(tenantID)
+	if b, exists := t.budgets[tenantID]; exists && now.Sub(acct.windowStart) >= b.Window {
+		acct.reset(now)
+	}
+	return acct
+}
+
+// Charge records the resources consumed by one agent run
+func (t *Tracker) Charge(tenantID, agentID string, wallTime time.Duration, iterations int64, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	acct := t.rollLocked(tenantID, now)
+	wasExhausted := t.exhaustedLocked(tenantID, acct)
+
+	acct.usage.add(wallTime, iterations)
+	agentUsage, exists := acct.agents[agentID]
+	if !exists {
+		agentUsage = &Usage{}
+		acct.agents[agentID] = agentUsage
+	}
+	agentUsage.add(wallTime, iterations)
+
+	if !wasExhausted && t.exhaustedLocked(tenantID, acct) {
+		acct.exhausted++
+	}
+}
+
+func (t *Tracker) exhaustedLocked(tenantID string, acct *tenantAccount) bool {
+	b, exists := t.budgets[tenantID]
+	if !exists {
+		return false
+	}
+	return (b.MaxWallTime > 0 && acct.usage.WallTime >= b.MaxWallTime) ||
+		(b.MaxIterations > 0 && acct.usage.Iterations >= b.MaxIterations)
+}
+
+// Exhausted reports whether a tenant has used up its budget in the current
+// window, and the action to apply if so
+func (t *Tracker) Exhausted(tenantID string, now time.Time) (bool, Action) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	acct := t.rollLocked(tenantID, now)
+	if !t.exhaustedLocked(tenantID, acct) {
+		return false, ""
+	}
+	return true, t.budgets[tenantID].Action
+}
+
+// GetUsage returns a tenant's budget, usage in the current window and
+// per-agent breakdown
+func (t *Tracker) GetUsage(tenantID string) map[string]interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	acct := t.rollLocked(tenantID, now)
+
+	agents := make(map[string]Usage, len(acct.agents))
+	for agentID, usage := range acct.agents {
+		agents[agentID] = *usage
+	}
+
+	result := map[string]interface{}{
+		"tenant_id":         tenantID,
+		"window_start":      acct.windowStart,
+		"usage":             acct.usage,
+		"agents":            agents,
+		"exhausted":         t.exhaustedLocked(tenantID, acct),
+		"exhausted_windows": acct.exhausted,
+	}
+	if b, exists := t.budgets[tenantID]; exists {
+		result["budget"] = b
+		result["window_resets_at"] = acct.windowStart.Add(b.Window)
+	}
+	return result
+}
+
+// Purge removes a tenant's budget and usage accounting
+func (t *Tracker) Purge(tenantID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.budgets, tenantID)
+	delete(t.accounts, tenantID)
+}
+
+// GetStats returns tracker statistics
+func (t *Tracker) GetStats() map[string]interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	exhausted := 0
+	for tenantID, acct := range t.accounts {
+		if t.exhaustedLocked(tenantID, acct) {
+			exhausted++
+		}
+	}
+
+	return map[string]interface{}{
+		"budgets":           len(t.budgets),
+		"tracked_tenants":   len(t.accounts),
+		"exhausted_tenants": exhausted,
+	}
+}
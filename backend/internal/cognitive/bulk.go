@@ -0,0 +1,41 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/admission"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// ImportAtoms bulk-loads atoms, typically allocated from an
+// atomspace.Arena, storing each shard's share in one batch. The tenants'
+// admission webhooks review every atom first, and any denial rejects the
+// whole import. Atoms already held are skipped; the number imported is
+// returned. Imported atoms are not announced as events.
+func (ce *CognitiveEngine) ImportAtoms(atoms []atomspace.Atom) (int, error) {
+	for _, atom := range atoms {
+		if err := ce.admission.Admit(context.Background(), admission.OperationCreate, atom, nil); err != nil {
+			return 0, fmt.Errorf("atom %s: %w", atom.GetID(), err)
+		}
+		if _, shared := ce.findMountedAtom(atom.GetID(), atom.GetTenantID()); shared {
+			return 0, fmt.Errorf("atom with ID %s already exists in a mounted shared space", atom.GetID())
+		}
+	}
+	return ce.importAtoms(atoms), nil
+}
+
+// importAtoms stores atoms in batches and records their creation. Replicas
+// are not told of them and rebuild.
+func (ce *CognitiveEngine) importAtoms(atoms []atomspace.Atom) int {
+	added := ce.shardManager.AddAtoms(atoms)
+	now := time.Now()
+	for _, atom := range added {
+		ce.history.Created(atom.GetTenantID(), atom.GetID(), now)
+	}
+	if len(added) > 0 {
+		ce.shardManager.MarkReplicasStale()
+	}
+	return len(added)
+}
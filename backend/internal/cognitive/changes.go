@@ -0,0 +1,83 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/changes"
+)
+
+// SetChangeHook configures the GitHub and GitLab webhooks of a tenant: the
+// shared secret and the services each repository changes
+func (ce *CognitiveEngine) SetChangeHook(tenantID string, hook changes.Hook) error {
+	return ce.changes.SetHook(tenantID, hook)
+}
+
+// GetChangeHook returns a tenant's webhook configuration
+func (ce *CognitiveEngine) GetChangeHook(tenantID string) (changes.Hook, error) {
+	return ce.changes.GetHook(tenantID)
+}
+
+// RemoveChangeHook stops accepting a tenant's webhooks
+func (ce *CognitiveEngine) RemoveChangeHook(tenantID string) error {
+	return ce.changes.RemoveHook(tenantID)
+}
+
+// IngestChangeWebhook verifies a GitHub or GitLab webhook payload against
+// the tenant's secret, resolved if it is a reference, and records the
+// deployments and merges it reports
+func (ce *CognitiveEngine) IngestChangeWebhook(ctx context.Context, tenantID string, provider changes.Provider, header http.Header, body []byte) ([]changes.Event, error) {
+	hook, err := ce.changes.GetHook(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := ce.secrets.Resolve(ctx, tenantID, hook.Secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := changes.Verify(secret, provider, header, body); err != nil {
+		return nil, err
+	}
+	events, err := changes.Parse(provider, header, body)
+	if err != nil {
+		return nil, err
+	}
+	return ce.RecordChanges(ctx, tenantID, events)
+}
+
+// RecordChanges records changes as ChangeEvent atoms linked to the
+// services they affect. While alerts are firing, inference runs so the
+// change correlation rule relates them to the new changes.
+func (ce *CognitiveEngine) RecordChanges(ctx context.Context, tenantID string, events []changes.Event) ([]changes.Event, error) {
+	ce.mu.RLock()
+	_, initialized := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+	if !initialized {
+		return nil, fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+
+	recorded, err := ce.changes.Record(tenantID, events)
+	if err != nil {
+		return nil, err
+	}
+	space := &tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID}
+	for _, e := range recorded {
+		if _, err := changes.WriteEvent(space, tenantID, e); err != nil {
+			return nil, fmt.Errorf("recording change %s failed: %w", e.ID, err)
+		}
+	}
+
+	if len(recorded) > 0 && len(ce.incidents.Firing(tenantID)) > 0 {
+		if _, err := ce.RunInference(ctx, tenantID, 1); err != nil {
+			return nil, err
+		}
+	}
+	return recorded, nil
+}
+
+// ListChanges returns a tenant's changes since a time, most recent first
+func (ce *CognitiveEngine) ListChanges(tenantID string, since time.Time) []changes.Event {
+	return ce.changes.List(tenantID, since)
+}
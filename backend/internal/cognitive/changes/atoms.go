@@ -0,0 +1,128 @@
+package changes
+
+import (
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// Names of the atoms written for changes
+const (
+	ChangePrefix       = "change:"
+	ChangeEventConcept = "ChangeEvent"
+	AffectsPredicate   = "affects"
+	ChangedAtPredicate = "changed_at" // changed_at(change:ID, NumberNode of the Unix time)
+
+	repositoryPrefix      = "repo:"
+	environmentPrefix     = "environment:"
+	inRepositoryPredicate = "in_repository"
+	deployedToPredicate   = "deployed_to"
+)
+
+var fullTV = atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+
+// kindConcepts name the category of each kind of change
+var kindConcepts = map[Kind]string{
+	KindDeployment: "Deployment",
+	KindMerge:      "Merge",
+}
+
+// ChangeName is the concept name of a change
+func ChangeName(id string) string {
+	return ChangePrefix + id
+}
+
+func ensureRelation(space atomspace.AtomSpaceInterface, tenantID, predicate string, args ...atomspace.Atom) error {
+	pred, err := atomspace.Ensure(space, atomspace.NewPredicateNode(predicate, tenantID), fullTV)
+	if err != nil {
+		return err
+	}
+	outgoing := append([]atomspace.Atom{pred}, args...)
+	link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, predicate, outgoing), predicate, tenantID, atomspace.EvaluationLinkType, outgoing)
+	_, err = atomspace.Ensure(space, link, fullTV)
+	return err
+}
+
+func ensureInheritance(space atomspace.AtomSpaceInterface, tenantID string, child, parent atomspace.Atom) error {
+	outgoing := []atomspace.Atom{child, parent}
+	link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", outgoing), "inheritance", tenantID, atomspace.InheritanceLinkType, outgoing)
+	_, err := atomspace.Ensure(space, link, fullTV)
+	return err
+}
+
+// WriteEvent records a change as a ChangeEvent concept linked to the
+// services it affects:
+//
+//	change:ID inherits ChangeEvent and Deployment or Merge
+//	affects(change:ID, SERVICE)
+//	changed_at(change:ID, UNIX_TIME)
+//	in_repository(change:ID, repo:REPOSITORY)
+//	deployed_to(change:ID, environment:ENV)   for deployments
+//
+// Deployments are stated with a confidence of 0.9 and merges, which may not
+// be deployed yet, with 0.5. A later status of the same deployment updates
+// its time.
+func WriteEvent(space atomspace.AtomSpaceInterface, tenantID string, e Event) (atomspace.Atom, error) {
+	tv := atomspace.TruthValue{Strength: 1.0, Confidence: 0.9}
+	if e.Kind == KindMerge {
+		tv.Confidence = 0.5
+	}
+	node, err := atomspace.Ensure(space, atomspace.NewConceptNode(ChangeName(e.ID), tenantID), tv)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range []string{ChangeEventConcept, kindConcepts[e.Kind]} {
+		parent, err := atomspace.Ensure(space, atomspace.NewConceptNode(name, tenantID), fullTV)
+		if err != nil {
+			return nil, err
+		}
+		if err := ensureInheritance(space, tenantID, node, parent); err != nil {
+			return nil, err
+		}
+	}
+	for _, service := range e.Services {
+		target, err := atomspace.Ensure(space, atomspace.NewConceptNode(service, tenantID), fullTV)
+		if err != nil {
+			return nil, err
+		}
+		if err := ensureRelation(space, tenantID, AffectsPredicate, node, target); err != nil {
+			return nil, err
+		}
+	}
+
+	// A changed time supersedes the previous one
+	at := atomspace.NewNumberNode(float64(e.At.Unix()), tenantID)
+	if _, err := atomspace.Ensure(space, at, fullTV); err != nil {
+		return nil, err
+	}
+	for _, atom := range space.QueryAtoms(tenantID, func(a atomspace.Atom) bool {
+		return a.GetType() == atomspace.EvaluationLinkType && a.GetName() == ChangedAtPredicate
+	}) {
+		outgoing := atom.(*atomspace.Link).GetOutgoing()
+		if len(outgoing) == 3 && outgoing[1].GetID() == node.GetID() && outgoing[2].GetID() != at.GetID() {
+			space.DeleteAtom(atom.GetID(), tenantID)
+		}
+	}
+	if err := ensureRelation(space, tenantID, ChangedAtPredicate, node, at); err != nil {
+		return nil, err
+	}
+
+	if e.Repository != "" {
+		repo, err := atomspace.Ensure(space, atomspace.NewConceptNode(repositoryPrefix+e.Repository, tenantID), fullTV)
+		if err != nil {
+			return nil, err
+		}
+		if err := ensureRelation(space, tenantID, inRepositoryPredicate, node, repo); err != nil {
+			return nil, err
+		}
+	}
+	if e.Kind == KindDeployment && e.Environment != "" {
+		env, err := atomspace.Ensure(space, atomspace.NewConceptNode(environmentPrefix+e.Environment, tenantID), fullTV)
+		if err != nil {
+			return nil, err
+		}
+		if err := ensureRelation(space, tenantID, deployedToPredicate, node, env); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
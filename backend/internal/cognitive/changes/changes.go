@@ -0,0 +1,238 @@
+package changes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider is the source of change events
+type Provider string
+
+const (
+	ProviderGitHub  Provider = "github"
+	ProviderGitLab  Provider = "gitlab"
+	ProviderGeneric Provider = "generic"
+)
+
+// Kind classifies a change event
+type Kind string
+
+const (
+	KindDeployment Kind = "deployment" // Code was deployed to an environment
+	KindMerge      Kind = "merge"      // A pull or merge request was merged
+)
+
+// MaxEvents is the number of events kept per tenant
+const MaxEvents = 1000
+
+// Event is one change to the tenant's services
+type Event struct {
+	ID          string    `json:"id"`
+	Provider    Provider  `json:"provider"`
+	Kind        Kind      `json:"kind"`
+	Repository  string    `json:"repository"` // e.g. acme/payments
+	Ref         string    `json:"ref,omitempty"`
+	Commit      string    `json:"commit,omitempty"`
+	Title       string    `json:"title,omitempty"`
+	Author      string    `json:"author,omitempty"`
+	Environment string    `json:"environment,omitempty"` // Of deployments
+	Status      string    `json:"status,omitempty"`      // success, failure or in_progress for deployments, merged for merges
+	URL         string    `json:"url,omitempty"`
+	Services    []string  `json:"services,omitempty"` // Concept names of the affected services
+	At          time.Time `json:"at"`
+}
+
+// Normalize checks an event and fills in its defaults: an ID over its
+// content and the current time
+func (e *Event) Normalize() error {
+	if e.Kind != KindDeployment && e.Kind != KindMerge {
+		return fmt.Errorf("unknown change kind %q", e.Kind)
+	}
+	if e.Repository == "" && len(e.Services) == 0 {
+		return fmt.Errorf("change requires a repository or services")
+	}
+	if e.Provider == "" {
+		e.Provider = ProviderGeneric
+	}
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	if e.ID == "" {
+		sum := sha256.Sum256([]byte(strings.Join([]string{string(e.Provider), string(e.Kind), e.Repository, e.Commit, e.Environment, e.At.UTC().Format(time.RFC3339)}, "|")))
+		e.ID = string(e.Provider) + "-" + hex.EncodeToString(sum[:8])
+	}
+	return nil
+}
+
+// Hook configures the webhooks of a tenant
+type Hook struct {
+	Secret       string              `json:"secret"`                 // GitHub webhook secret or GitLab secret token, or a secret reference
+	Services     map[string][]string `json:"services,omitempty"`     // Repository -> services it changes; service/NAME after the repository by default
+	Environments []string            `json:"environments,omitempty"` // Environments whose deployments are recorded; all if empty
+}
+
+// Validate checks a hook
+func (h *Hook) Validate() error {
+	if len(h.Secret) < 8 {
+		return fmt.Errorf("webhook secret must have at least 8 characters")
+	}
+	return nil
+}
+
+// services returns the services a change affects
+func (h *Hook) services(e *Event) []string {
+	if len(e.Services) > 0 {
+		return e.Services
+	}
+	if services, ok := h.Services[e.Repository]; ok {
+		return services
+	}
+	if e.Repository == "" {
+		return nil
+	}
+	return []string{"service/" + path.Base(e.Repository)}
+}
+
+// recorded reports whether a hook keeps an event
+func (h *Hook) recorded(e *Event) bool {
+	if e.Kind != KindDeployment || len(h.Environments) == 0 {
+		return true
+	}
+	for _, env := range h.Environments {
+		if env == e.Environment {
+			return true
+		}
+	}
+	return false
+}
+
+// Manager keeps the webhooks and recent changes of each tenant
+type Manager struct {
+	hooks  map[string]Hook    // tenantID -> hook
+	events map[string][]Event // tenantID -> events, oldest first
+	mu     sync.RWMutex
+}
+
+// NewManager creates a change manager
+func NewManager() *Manager {
+	return &Manager{
+		hooks:  make(map[string]Hook),
+		events: make(map[string][]Event),
+	}
+}
+
+// SetHook configures a tenant's webhooks
+func (m *Manager) SetHook(tenantID string, hook Hook) error {
+	if err := hook.Validate(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks[tenantID] = hook
+	return nil
+}
+
+// GetHook returns a tenant's webhook configuration
+func (m *Manager) GetHook(tenantID string) (Hook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	hook, exists := m.hooks[tenantID]
+	if !exists {
+		return Hook{}, fmt.Errorf("change webhooks not configured for tenant %s", tenantID)
+	}
+	return hook, nil
+}
+
+// RemoveHook stops accepting a tenant's webhooks. Recorded changes are
+// kept.
+func (m *Manager) RemoveHook(tenantID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.hooks[tenantID]; !exists {
+		return fmt.Errorf("change webhooks not configured for tenant %s", tenantID)
+	}
+	delete(m.hooks, tenantID)
+	return nil
+}
+
+// Record normalizes events, fills in the services they affect and keeps
+// them, replacing earlier events of the same ID such as the previous
+// status of a deployment. It returns the events recorded; deployments to
+// environments the hook does not track are dropped.
+func (m *Manager) Record(tenantID string, events []Event) ([]Event, error) {
+	for i := range events {
+		if err := events[i].Normalize(); err != nil {
+			return nil, err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hook := m.hooks[tenantID]
+	recorded := make([]Event, 0, len(events))
+	for _, e := range events {
+		if !hook.recorded(&e) {
+			continue
+		}
+		e.Services = append([]string(nil), hook.services(&e)...)
+		recorded = append(recorded, e)
+
+		kept := m.events[tenantID][:0]
+		for _, existing := range m.events[tenantID] {
+			if existing.ID != e.ID {
+				kept = append(kept, existing)
+			}
+		}
+		kept = append(kept, e)
+		sort.SliceStable(kept, func(i, j int) bool { return kept[i].At.Before(kept[j].At) })
+		if len(kept) > MaxEvents {
+			kept = kept[len(kept)-MaxEvents:]
+		}
+		m.events[tenantID] = kept
+	}
+	return recorded, nil
+}
+
+// List returns a tenant's changes since a time, most recent first
+func (m *Manager) List(tenantID string, since time.Time) []Event {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]Event, 0)
+	events := m.events[tenantID]
+	for i := len(events) - 1; i >= 0 && !events[i].At.Before(since); i-- {
+		result = append(result, events[i])
+	}
+	return result
+}
+
+// Purge removes a tenant's hook and changes, returning the number of
+// changes removed
+func (m *Manager) Purge(tenantID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	removed := len(m.events[tenantID])
+	delete(m.events, tenantID)
+	delete(m.hooks, tenantID)
+	return removed
+}
+
+// GetStats returns change statistics
+func (m *Manager) GetStats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	events := 0
+	for _, e := range m.events {
+		events += len(e)
+	}
+	return map[string]interface{}{
+		"hooks":  len(m.hooks),
+		"events": events,
+	}
+}
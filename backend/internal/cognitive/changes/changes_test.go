@@ -0,0 +1,152 @@
+package changes
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseGitHub(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "deployment_status")
+	body := []byte(`{
+		"deployment_status": {"state": "success", "environment": "production", "created_at": "2024-01-01T12:00:00Z"},
+		"deployment": {"id": 42, "sha": "abc123", "ref": "main", "creator": {"login": "alice"}},
+		"repository": {"full_name": "acme/web"}
+	}`)
+	events, err := Parse(ProviderGitHub, header, body)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	e := events[0]
+	if e.Kind != KindDeployment || e.Environment != "production" || e.Commit != "abc123" || e.Author != "alice" || e.Status != "success" {
+		t.Errorf("Unexpected deployment: %+v", e)
+	}
+	if !e.At.Equal(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the status time, got %v", e.At)
+	}
+
+	// Pending deployments and unmerged pull requests are not changes
+	pending := []byte(`{"deployment_status": {"state": "pending"}, "repository": {"full_name": "acme/web"}}`)
+	if events, _ := Parse(ProviderGitHub, header, pending); len(events) != 0 {
+		t.Errorf("Expected no events for a pending deployment, got %+v", events)
+	}
+	header.Set("X-GitHub-Event", "pull_request")
+	closed := []byte(`{"action": "closed", "pull_request": {"number": 7, "merged": false}, "repository": {"full_name": "acme/web"}}`)
+	if events, _ := Parse(ProviderGitHub, header, closed); len(events) != 0 {
+		t.Errorf("Expected no events for a closed pull request, got %+v", events)
+	}
+	merged := []byte(`{"action": "closed", "pull_request": {"number": 7, "merged": true, "merged_at": "2024-01-01T11:00:00Z", "title": "Bump", "base": {"ref": "main"}}, "repository": {"full_name": "acme/web"}}`)
+	events, err = Parse(ProviderGitHub, header, merged)
+	if err != nil || len(events) != 1 || events[0].Kind != KindMerge || events[0].ID != "github-acme/web#7" {
+		t.Errorf("Expected a merge, got %+v (%v)", events, err)
+	}
+}
+
+func TestParseGitLab(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Gitlab-Event", "Deployment Hook")
+	body := []byte(`{
+		"object_kind": "deployment", "status": "failed", "deployment_id": 9, "environment": "staging",
+		"status_changed_at": "2024-01-01 12:00:00 +0000", "commit_url": "https://gitlab.example/acme/api/-/commit/def456",
+		"project": {"path_with_namespace": "acme/api"}
+	}`)
+	events, err := Parse(ProviderGitLab, header, body)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %+v (%v)", events, err)
+	}
+	e := events[0]
+	if e.Status != "failure" || e.Commit != "def456" || e.Environment != "staging" || e.At.Unix() != time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC).Unix() {
+		t.Errorf("Unexpected deployment: %+v", e)
+	}
+
+	header.Set("X-Gitlab-Event", "Merge Request Hook")
+	mr := []byte(`{"object_kind": "merge_request", "object_attributes": {"iid": 3, "action": "merge", "target_branch": "main"}, "project": {"path_with_namespace": "acme/api"}}`)
+	events, err = Parse(ProviderGitLab, header, mr)
+	if err != nil || len(events) != 1 || events[0].Kind != KindMerge || events[0].Ref != "main" {
+		t.Errorf("Expected a merge, got %+v (%v)", events, err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	m := NewManager()
+	if err := m.SetHook("t1", Hook{Secret: "short"}); err == nil {
+		t.Error("Expected a short secret to be rejected")
+	}
+
+	body := []byte(`{}`)
+	if err := Verify("s3cret-token", ProviderGitHub, http.Header{}, body); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected an unsigned body to be unauthorized, got %v", err)
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret-token"))
+	mac.Write(body)
+	header := http.Header{}
+	header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	if err := Verify("s3cret-token", ProviderGitHub, header, body); err != nil {
+		t.Errorf("Expected a valid signature to verify: %v", err)
+	}
+	if err := Verify("s3cret-token", ProviderGitHub, header, []byte(`{"tampered":true}`)); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected a tampered body to be unauthorized, got %v", err)
+	}
+
+	header = http.Header{}
+	header.Set("X-Gitlab-Token", "s3cret-token")
+	if err := Verify("s3cret-token", ProviderGitLab, header, body); err != nil {
+		t.Errorf("Expected a valid token to verify: %v", err)
+	}
+	header.Set("X-Gitlab-Token", "wrong")
+	if err := Verify("s3cret-token", ProviderGitLab, header, body); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected a wrong token to be unauthorized, got %v", err)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	m := NewManager()
+	m.SetHook("t1", Hook{
+		Secret:       "s3cret-token",
+		Services:     map[string][]string{"acme/monorepo": {"service/web", "service/api"}},
+		Environments: []string{"production"},
+	})
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	recorded, err := m.Record("t1", []Event{
+		{ID: "d1", Kind: KindDeployment, Repository: "acme/payments", Environment: "production", Status: "in_progress", At: start},
+		{ID: "d2", Kind: KindDeployment, Repository: "acme/monorepo", Environment: "production", At: start.Add(time.Minute)},
+		{ID: "d3", Kind: KindDeployment, Repository: "acme/web", Environment: "staging", At: start},
+		{ID: "m1", Kind: KindMerge, Repository: "acme/web", At: start.Add(-time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	// The staging deployment is not tracked
+	if len(recorded) != 3 {
+		t.Fatalf("Expected 3 recorded events, got %d", len(recorded))
+	}
+	if got := recorded[0].Services; len(got) != 1 || got[0] != "service/payments" {
+		t.Errorf("Expected the service named after the repository, got %v", got)
+	}
+	if got := recorded[1].Services; len(got) != 2 {
+		t.Errorf("Expected the mapped services, got %v", got)
+	}
+
+	// A later status of the same deployment replaces it
+	m.Record("t1", []Event{{ID: "d1", Kind: KindDeployment, Repository: "acme/payments", Environment: "production", Status: "success", At: start.Add(2 * time.Minute)}})
+	list := m.List("t1", start)
+	if len(list) != 2 || list[0].ID != "d1" || list[0].Status != "success" {
+		t.Errorf("Expected d1 replaced and listed first, got %+v", list)
+	}
+
+	if _, err := m.Record("t1", []Event{{Kind: "push", Repository: "acme/web"}}); err == nil {
+		t.Error("Expected an unknown kind to be rejected")
+	}
+	if removed := m.Purge("t1"); removed != 3 {
+		t.Errorf("Expected 3 events purged, got %d", removed)
+	}
+}
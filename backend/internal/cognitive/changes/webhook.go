@@ -0,0 +1,257 @@
+package changes
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrUnauthorized is returned for webhooks not signed with the tenant's
+// secret
+var ErrUnauthorized = fmt.Errorf("webhook signature invalid or missing")
+
+// Verify checks that a webhook payload was sent with a hook's secret:
+// GitHub signs the body with it in X-Hub-Signature-256 and GitLab sends it
+// as X-Gitlab-Token
+func Verify(secret string, provider Provider, header http.Header, body []byte) error {
+	switch provider {
+	case ProviderGitHub:
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		if !hmac.Equal([]byte(header.Get("X-Hub-Signature-256")), []byte(expected)) {
+			return ErrUnauthorized
+		}
+	case ProviderGitLab:
+		if subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), []byte(secret)) != 1 {
+			return ErrUnauthorized
+		}
+	default:
+		return fmt.Errorf("unknown webhook provider %q", provider)
+	}
+	return nil
+}
+
+// Parse decodes the changes of a webhook payload. Events other than
+// deployments and merges, such as pings and pushes, have none.
+func Parse(provider Provider, header http.Header, body []byte) ([]Event, error) {
+	switch provider {
+	case ProviderGitHub:
+		return parseGitHub(header.Get("X-GitHub-Event"), body)
+	case ProviderGitLab:
+		return parseGitLab(header.Get("X-Gitlab-Event"), body)
+	}
+	return nil, fmt.Errorf("unknown webhook provider %q", provider)
+}
+
+type gitHubUser struct {
+	Login string `json:"login"`
+}
+
+type gitHubPayload struct {
+	Action           string `json:"action"`
+	DeploymentStatus struct {
+		State       string     `json:"state"`
+		Environment string     `json:"environment"`
+		TargetURL   string     `json:"target_url"`
+		CreatedAt   time.Time  `json:"created_at"`
+		Creator     gitHubUser `json:"creator"`
+	} `json:"deployment_status"`
+	Deployment struct {
+		ID          int64      `json:"id"`
+		SHA         string     `json:"sha"`
+		Ref         string     `json:"ref"`
+		Environment string     `json:"environment"`
+		Description string     `json:"description"`
+		Creator     gitHubUser `json:"creator"`
+	} `json:"deployment"`
+	PullRequest struct {
+		Number         int        `json:"number"`
+		Title          string     `json:"title"`
+		HTMLURL        string     `json:"html_url"`
+		Merged         bool       `json:"merged"`
+		MergedAt       *time.Time `json:"merged_at"`
+		MergeCommitSHA string     `json:"merge_commit_sha"`
+		User           gitHubUser `json:"user"`
+		Base           struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// parseGitHub reads deployment_status events and merged pull requests.
+// Statuses of deployments not yet started are skipped.
+func parseGitHub(event string, body []byte) ([]Event, error) {
+	if event != "deployment_status" && event != "pull_request" {
+		return nil, nil
+	}
+	var p gitHubPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid github payload: %w", err)
+	}
+	repo := p.Repository.FullName
+
+	if event == "pull_request" {
+		pr := p.PullRequest
+		if p.Action != "closed" || !pr.Merged || pr.MergedAt == nil {
+			return nil, nil
+		}
+		return []Event{{
+			ID:         fmt.Sprintf("github-%s#%d", repo, pr.Number),
+			Provider:   ProviderGitHub,
+			Kind:       KindMerge,
+			Repository: repo,
+			Ref:        pr.Base.Ref,
+			Commit:     pr.MergeCommitSHA,
+			Title:      pr.Title,
+			Author:     pr.User.Login,
+			Status:     "merged",
+			URL:        pr.HTMLURL,
+			At:         *pr.MergedAt,
+		}}, nil
+	}
+
+	status := deploymentStatus(p.DeploymentStatus.State)
+	if status == "" {
+		return nil, nil
+	}
+	env := p.DeploymentStatus.Environment
+	if env == "" {
+		env = p.Deployment.Environment
+	}
+	author := p.Deployment.Creator.Login
+	if author == "" {
+		author = p.DeploymentStatus.Creator.Login
+	}
+	return []Event{{
+		ID:          fmt.Sprintf("github-%s-deployment-%d", repo, p.Deployment.ID),
+		Provider:    ProviderGitHub,
+		Kind:        KindDeployment,
+		Repository:  repo,
+		Ref:         p.Deployment.Ref,
+		Commit:      p.Deployment.SHA,
+		Title:       p.Deployment.Description,
+		Author:      author,
+		Environment: env,
+		Status:      status,
+		URL:         p.DeploymentStatus.TargetURL,
+		At:          p.DeploymentStatus.CreatedAt,
+	}}, nil
+}
+
+type gitLabPayload struct {
+	ObjectKind      string `json:"object_kind"`
+	Status          string `json:"status"`
+	DeploymentID    int64  `json:"deployment_id"`
+	Environment     string `json:"environment"`
+	StatusChangedAt string `json:"status_changed_at"`
+	DeployableURL   string `json:"deployable_url"`
+	CommitURL       string `json:"commit_url"`
+	CommitTitle     string `json:"commit_title"`
+	Ref             string `json:"ref"`
+	User            struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID            int    `json:"iid"`
+		Title          string `json:"title"`
+		URL            string `json:"url"`
+		Action         string `json:"action"`
+		TargetBranch   string `json:"target_branch"`
+		MergeCommitSHA string `json:"merge_commit_sha"`
+		UpdatedAt      string `json:"updated_at"`
+	} `json:"object_attributes"`
+}
+
+// parseGitLab reads deployment events and merged merge requests
+func parseGitLab(event string, body []byte) ([]Event, error) {
+	if event != "Deployment Hook" && event != "Merge Request Hook" {
+		return nil, nil
+	}
+	var p gitLabPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid gitlab payload: %w", err)
+	}
+	repo := p.Project.PathWithNamespace
+
+	if p.ObjectKind == "merge_request" {
+		mr := p.ObjectAttributes
+		if mr.Action != "merge" {
+			return nil, nil
+		}
+		return []Event{{
+			ID:         fmt.Sprintf("gitlab-%s!%d", repo, mr.IID),
+			Provider:   ProviderGitLab,
+			Kind:       KindMerge,
+			Repository: repo,
+			Ref:        mr.TargetBranch,
+			Commit:     mr.MergeCommitSHA,
+			Title:      mr.Title,
+			Author:     p.User.Username,
+			Status:     "merged",
+			URL:        mr.URL,
+			At:         parseGitLabTime(mr.UpdatedAt),
+		}}, nil
+	}
+
+	status := deploymentStatus(p.Status)
+	if p.ObjectKind != "deployment" || status == "" {
+		return nil, nil
+	}
+	commit := p.CommitURL
+	if i := strings.LastIndex(commit, "/"); i >= 0 {
+		commit = commit[i+1:]
+	}
+	return []Event{{
+		ID:          fmt.Sprintf("gitlab-%s-deployment-%d", repo, p.DeploymentID),
+		Provider:    ProviderGitLab,
+		Kind:        KindDeployment,
+		Repository:  repo,
+		Ref:         p.Ref,
+		Commit:      commit,
+		Title:       p.CommitTitle,
+		Author:      p.User.Username,
+		Environment: p.Environment,
+		Status:      status,
+		URL:         p.DeployableURL,
+		At:          parseGitLabTime(p.StatusChangedAt),
+	}}, nil
+}
+
+// deploymentStatus maps the states of GitHub and GitLab deployments to
+// success, failure or in_progress. Deployments not started yet, canceled
+// or superseded map to "".
+func deploymentStatus(state string) string {
+	switch state {
+	case "success":
+		return "success"
+	case "failure", "error", "failed":
+		return "failure"
+	case "in_progress", "running":
+		return "in_progress"
+	}
+	return ""
+}
+
+// parseGitLabTime reads the timestamps of GitLab payloads, which come in
+// several layouts. Unreadable ones give the current time.
+func parseGitLabTime(value string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05 -0700", "2006-01-02 15:04:05 MST"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Now()
+}
@@ -0,0 +1,71 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+)
+
+// EnableClustering registers a concept clustering agent for a tenant, or
+// updates the configuration of the existing one
+func (ce *CognitiveEngine) EnableClustering(tenantID string, config agents.ClusteringConfig) *agents.ClusteringAgent {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if agent, exists := ce.clusterAgents[tenantID]; exists {
+		agent.SetConfig(config)
+		return agent
+	}
+
+	agent := agents.NewClusteringAgent(
+		fmt.Sprintf("clusters-%s", tenantID),
+		"ClusteringAgent",
+		tenantID,
+		&tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID},
+		config,
+	)
+	ce.clusterAgents[tenantID] = agent
+	ce.agentScheduler.RegisterAgent(agent)
+	return agent
+}
+
+// DisableClustering unregisters a tenant's clustering agent. Parent concepts
+// already created are kept.
+func (ce *CognitiveEngine) DisableClustering(tenantID string) error {
+	ce.mu.Lock()
+	agent, exists := ce.clusterAgents[tenantID]
+	delete(ce.clusterAgents, tenantID)
+	ce.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("clustering not enabled for tenant %s", tenantID)
+	}
+	ce.agentScheduler.UnregisterAgent(agent.GetID())
+	return nil
+}
+
+// ClusterConcepts clusters a tenant's concepts immediately, enabling
+// clustering with the default configuration if needed
+func (ce *CognitiveEngine) ClusterConcepts(ctx context.Context, tenantID string) ([]agents.Cluster, error) {
+	ce.mu.RLock()
+	agent, exists := ce.clusterAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		agent = ce.EnableClustering(tenantID, agents.DefaultClusteringConfig())
+	}
+	return agent.Cluster(ctx)
+}
+
+// GetClusters returns the clusters found by a tenant's latest clustering run
+func (ce *CognitiveEngine) GetClusters(tenantID string) ([]agents.Cluster, error) {
+	ce.mu.RLock()
+	agent, exists := ce.clusterAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("clustering not enabled for tenant %s", tenantID)
+	}
+	return agent.GetClusters(), nil
+}
@@ -0,0 +1,145 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/cmdb"
+)
+
+// EnableCMDB registers a CMDB agent for a tenant, or updates the
+// configuration of the existing one
+func (ce *CognitiveEngine) EnableCMDB(tenantID string, config agents.CMDBConfig) (*agents.CMDBAgent, error) {
+	names := make(map[string]bool, len(config.Sources))
+	for i := range config.Sources {
+		if err := config.Sources[i].Validate(); err != nil {
+			return nil, err
+		}
+		if names[config.Sources[i].Name] {
+			return nil, fmt.Errorf("duplicate cmdb source: %s", config.Sources[i].Name)
+		}
+		names[config.Sources[i].Name] = true
+	}
+
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if agent, exists := ce.cmdbAgents[tenantID]; exists {
+		agent.SetConfig(config)
+		return agent, nil
+	}
+
+	agent := agents.NewCMDBAgent(
+		fmt.Sprintf("cmdb-%s", tenantID),
+		"CMDBAgent",
+		tenantID,
+		&tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID},
+		config,
+	)
+	agent.SetSecrets(ce.secrets.ForTenant(tenantID))
+	agent.SetSandbox(ce.sandbox.ForTenant(tenantID))
+	ce.cmdbAgents[tenantID] = agent
+	ce.agentScheduler.RegisterAgent(agent)
+	return agent, nil
+}
+
+// DisableCMDB unregisters a tenant's CMDB agent. Atoms already written
+// are kept and nothing more is written back.
+func (ce *CognitiveEngine) DisableCMDB(tenantID string) error {
+	ce.mu.Lock()
+	agent, exists := ce.cmdbAgents[tenantID]
+	delete(ce.cmdbAgents, tenantID)
+	ce.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("cmdb sync not enabled for tenant %s", tenantID)
+	}
+	ce.agentScheduler.UnregisterAgent(agent.GetID())
+	return nil
+}
+
+// SetCMDBSource adds or replaces a CMDB source, enabling the CMDB agent
+// with the default configuration if needed
+func (ce *CognitiveEngine) SetCMDBSource(tenantID string, source cmdb.Source) (agents.CMDBConfig, error) {
+	if err := source.Validate(); err != nil {
+		return agents.CMDBConfig{}, err
+	}
+
+	config := agents.DefaultCMDBConfig()
+	ce.mu.RLock()
+	agent, exists := ce.cmdbAgents[tenantID]
+	ce.mu.RUnlock()
+	if exists {
+		config = agent.GetConfig()
+	}
+
+	replaced := false
+	for i := range config.Sources {
+		if config.Sources[i].Name == source.Name {
+			config.Sources[i] = source
+			replaced = true
+		}
+	}
+	if !replaced {
+		config.Sources = append(config.Sources, source)
+	}
+
+	agent, err := ce.EnableCMDB(tenantID, config)
+	if err != nil {
+		return agents.CMDBConfig{}, err
+	}
+	return agent.GetConfig(), nil
+}
+
+// RemoveCMDBSource stops mirroring a CMDB. Atoms already written are
+// kept.
+func (ce *CognitiveEngine) RemoveCMDBSource(tenantID, name string) error {
+	ce.mu.RLock()
+	agent, exists := ce.cmdbAgents[tenantID]
+	ce.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("cmdb source %s not found", name)
+	}
+
+	config := agent.GetConfig()
+	sources := make([]cmdb.Source, 0, len(config.Sources))
+	for _, s := range config.Sources {
+		if s.Name != name {
+			sources = append(sources, s)
+		}
+	}
+	if len(sources) == len(config.Sources) {
+		return fmt.Errorf("cmdb source %s not found", name)
+	}
+	config.Sources = sources
+	agent.SetConfig(config)
+	return nil
+}
+
+// SyncCMDB reads a tenant's CMDBs immediately, writing those that changed
+// or, with force, all of them. Updates are planned for sources with
+// write-back, and only reported with dryRun.
+func (ce *CognitiveEngine) SyncCMDB(ctx context.Context, tenantID string, force, dryRun bool) ([]agents.CMDBSourceStatus, error) {
+	ce.mu.RLock()
+	agent, exists := ce.cmdbAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("cmdb sync not enabled for tenant %s", tenantID)
+	}
+	return agent.Sync(ctx, force, dryRun)
+}
+
+// GetCMDBStatus returns the configuration and sync status of a tenant's
+// CMDB agent
+func (ce *CognitiveEngine) GetCMDBStatus(tenantID string) (agents.CMDBConfig, []agents.CMDBSourceStatus, error) {
+	ce.mu.RLock()
+	agent, exists := ce.cmdbAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		return agents.CMDBConfig{}, nil, fmt.Errorf("cmdb sync not enabled for tenant %s", tenantID)
+	}
+	return agent.GetConfig(), agent.GetStatuses(), nil
+}
@@ -0,0 +1,371 @@
+package cmdb
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind is the CMDB product a source is read from
+type Kind string
+
+const (
+	KindServiceNow Kind = "servicenow"
+	KindNetBox     Kind = "netbox"
+)
+
+// WriteBack tells what happens to the updates planned for a source
+type WriteBack string
+
+const (
+	WriteBackOff    WriteBack = ""        // No updates are planned
+	WriteBackDryRun WriteBack = "dry_run" // Updates are planned and reported but not applied
+	WriteBackApply  WriteBack = "apply"   // Updates are applied to the CMDB
+)
+
+// Limits of one fetch
+const (
+	PageSize   = 500
+	MaxRecords = 100000
+)
+
+// Mapping maps the fields of CMDB records to atoms. Fields may be dotted
+// paths into nested objects, e.g. site.name in NetBox or dot-walked
+// reference fields such as location.name in ServiceNow.
+type Mapping struct {
+	Identity      string            `json:"identity,omitempty"`      // Field identifying a CI in the CMDB
+	Name          string            `json:"name,omitempty"`          // Field naming the CI's concept
+	Class         string            `json:"class,omitempty"`         // Field giving the CI's category
+	Attributes    map[string]string `json:"attributes,omitempty"`    // Attribute key -> field, recorded as declares links
+	References    map[string]string `json:"references,omitempty"`    // Predicate -> field naming a related CI or concept
+	Relationships map[string]string `json:"relationships,omitempty"` // CMDB relationship type -> predicate
+}
+
+// DefaultMapping returns the mapping of a kind's default CI table
+func DefaultMapping(kind Kind) Mapping {
+	if kind == KindNetBox {
+		return Mapping{
+			Identity: "id",
+			Name:     "name",
+			Class:    "role.slug",
+			Attributes: map[string]string{
+				"serial":      "serial",
+				"status":      "status.value",
+				"platform":    "platform.slug",
+				"device_type": "device_type.model",
+				"ip_address":  "primary_ip.address",
+			},
+			References: map[string]string{
+				"located_in": "site.name",
+				"in_rack":    "rack.name",
+				"member_of":  "cluster.name",
+			},
+			// NetBox has no relationship records; these name the journal
+			// entries discovered relationships are written as
+			Relationships: map[string]string{
+				"depends_on":  "depends_on",
+				"connects_to": "connects_to",
+			},
+		}
+	}
+	return Mapping{
+		Identity: "sys_id",
+		Name:     "name",
+		Class:    "sys_class_name",
+		Attributes: map[string]string{
+			"ip_address":         "ip_address",
+			"fqdn":               "fqdn",
+			"serial_number":      "serial_number",
+			"operational_status": "operational_status",
+			"environment":        "environment",
+		},
+		References: map[string]string{
+			"located_in":   "location.name",
+			"supported_by": "support_group.name",
+		},
+		Relationships: map[string]string{
+			"Depends on::Used by":       "depends_on",
+			"Runs on::Runs":             "runs_on",
+			"Hosted on::Hosts":          "hosted_on",
+			"Connects to::Connected by": "connects_to",
+			"Contains::Contained by":    "contains",
+		},
+	}
+}
+
+// Source is a CMDB whose CIs are mirrored into a tenant's AtomSpace
+type Source struct {
+	Name      string    `json:"name"`
+	Kind      Kind      `json:"kind"`
+	URL       string    `json:"url"`                  // Instance URL, e.g. https://acme.service-now.com
+	Table     string    `json:"table,omitempty"`      // ServiceNow CI table or NetBox endpoint; cmdb_ci or dcim/devices by default
+	Query     string    `json:"query,omitempty"`      // sysparm_query, or NetBox filters such as status=active&site=ams1
+	Token     string    `json:"token,omitempty"`      // Bearer token for ServiceNow, API token for NetBox
+	Username  string    `json:"username,omitempty"`   // ServiceNow basic authentication
+	Password  string    `json:"password,omitempty"`   // ServiceNow basic authentication
+	Mapping   Mapping   `json:"mapping"`              // Fields not set take the kind's defaults
+	WriteBack WriteBack `json:"write_back,omitempty"` // Whether drift and discovered relationships are written back
+}
+
+// Validate checks a source
+func (s *Source) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("cmdb source name is required")
+	}
+	if s.Kind != KindServiceNow && s.Kind != KindNetBox {
+		return fmt.Errorf("cmdb source %s has unknown kind %q", s.Name, s.Kind)
+	}
+	if !strings.HasPrefix(s.URL, "http://") && !strings.HasPrefix(s.URL, "https://") {
+		return fmt.Errorf("cmdb source %s requires an http(s) url", s.Name)
+	}
+	if s.Password != "" && s.Username == "" {
+		return fmt.Errorf("cmdb source %s has a password without a username", s.Name)
+	}
+	switch s.WriteBack {
+	case WriteBackOff, WriteBackDryRun, WriteBackApply:
+	default:
+		return fmt.Errorf("cmdb source %s has unknown write_back %q", s.Name, s.WriteBack)
+	}
+	return nil
+}
+
+// Fields returns the source's mapping with the kind's defaults for the
+// fields not set
+func (s *Source) Fields() Mapping {
+	m := s.Mapping
+	defaults := DefaultMapping(s.Kind)
+	if m.Identity == "" {
+		m.Identity = defaults.Identity
+	}
+	if m.Name == "" {
+		m.Name = defaults.Name
+	}
+	if m.Class == "" {
+		m.Class = defaults.Class
+	}
+	if m.Attributes == nil {
+		m.Attributes = defaults.Attributes
+	}
+	if m.References == nil {
+		m.References = defaults.References
+	}
+	if m.Relationships == nil {
+		m.Relationships = defaults.Relationships
+	}
+	return m
+}
+
+// table returns the CI table or endpoint the source reads
+func (s *Source) table() string {
+	if s.Table != "" {
+		return strings.Trim(s.Table, "/")
+	}
+	if s.Kind == KindNetBox {
+		return "dcim/devices"
+	}
+	return "cmdb_ci"
+}
+
+// CI is one configuration item read from a CMDB
+type CI struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Class      string            `json:"class,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	References map[string]string `json:"references,omitempty"` // Predicate -> name of the related CI or concept
+}
+
+// Relationship is a typed relationship between two CIs, by ID
+type Relationship struct {
+	Predicate string `json:"predicate"`
+	Parent    string `json:"parent"`
+	Child     string `json:"child"`
+}
+
+// Snapshot is the content of a CMDB source at one time
+type Snapshot struct {
+	Items         []CI           `json:"items"`
+	Relationships []Relationship `json:"relationships"`
+}
+
+// Revision identifies the content of a snapshot; it changes whenever a
+// mapped field of a CI or a relationship does
+func (s *Snapshot) Revision() string {
+	data, _ := json.Marshal(s)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// UpdateKind classifies an update written back to a CMDB
+type UpdateKind string
+
+const (
+	UpdateAttribute    UpdateKind = "attribute"    // Set a field of a CI to the observed value
+	UpdateRelationship UpdateKind = "relationship" // Record a relationship discovered by the engine
+)
+
+// Update is a change written back to a CMDB
+type Update struct {
+	Kind      UpdateKind `json:"kind"`
+	CI        string     `json:"ci"` // ID of the CI updated, or of the relationship's parent
+	Field     string     `json:"field,omitempty"`
+	Value     string     `json:"value,omitempty"`
+	Previous  string     `json:"previous,omitempty"`
+	Predicate string     `json:"predicate,omitempty"`
+	Type      string     `json:"type,omitempty"` // CMDB relationship type
+	Child     string     `json:"child,omitempty"`
+	Reason    string     `json:"reason"` // ID of the drift or relation link the update is planned from
+	Applied   bool       `json:"applied"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// Key identifies an update across syncs
+func (u *Update) Key() string {
+	return strings.Join([]string{string(u.Kind), u.CI, u.Field, u.Value, u.Type, u.Child}, "|")
+}
+
+// Writable reports whether a mapped field can be written back. Dotted
+// fields read nested or referenced records and cannot.
+func Writable(field string) bool {
+	return field != "" && !strings.Contains(field, ".")
+}
+
+// Client reads and updates one CMDB source
+type Client interface {
+	Fetch(ctx context.Context) (*Snapshot, error)
+	Apply(ctx context.Context, update Update) error
+}
+
+// NewClient returns the client of a source's kind
+func NewClient(source Source, client *http.Client) Client {
+	if source.Kind == KindNetBox {
+		return &netBox{source: source, mapping: source.Fields(), client: client}
+	}
+	return &serviceNow{source: source, mapping: source.Fields(), client: client}
+}
+
+// Field reads a field of a record. A dotted path walks nested objects
+// unless the record has the dotted name as a key itself, as ServiceNow
+// returns dot-walked fields. Objects with a value key, such as NetBox
+// choices and ServiceNow references, read as that value.
+func Field(record map[string]interface{}, path string) string {
+	var value interface{} = record
+	if v, ok := record[path]; ok {
+		value = v
+	} else {
+		for _, part := range strings.Split(path, ".") {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return ""
+			}
+			value = object[part]
+		}
+	}
+	if object, ok := value.(map[string]interface{}); ok {
+		value = object["value"]
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+// buildCI maps a record to a CI. Records without an identity are skipped.
+func buildCI(record map[string]interface{}, m Mapping) (CI, bool) {
+	ci := CI{
+		ID:    Field(record, m.Identity),
+		Name:  Field(record, m.Name),
+		Class: Field(record, m.Class),
+	}
+	if ci.ID == "" {
+		return ci, false
+	}
+	if ci.Name == "" {
+		ci.Name = ci.ID
+	}
+	for key, field := range m.Attributes {
+		if value := Field(record, field); value != "" {
+			if ci.Attributes == nil {
+				ci.Attributes = make(map[string]string)
+			}
+			ci.Attributes[key] = value
+		}
+	}
+	for predicate, field := range m.References {
+		if value := Field(record, field); value != "" {
+			if ci.References == nil {
+				ci.References = make(map[string]string)
+			}
+			ci.References[predicate] = value
+		}
+	}
+	return ci, true
+}
+
+// sortSnapshot orders a snapshot so equal content gets an equal revision
+func sortSnapshot(s *Snapshot) {
+	sort.Slice(s.Items, func(i, j int) bool { return s.Items[i].ID < s.Items[j].ID })
+	sort.Slice(s.Relationships, func(i, j int) bool {
+		a, b := s.Relationships[i], s.Relationships[j]
+		if a.Parent != b.Parent {
+			return a.Parent < b.Parent
+		}
+		if a.Child != b.Child {
+			return a.Child < b.Child
+		}
+		return a.Predicate < b.Predicate
+	})
+}
+
+// send issues one request with a JSON body and decodes the JSON response
+// into out, if given
+func send(ctx context.Context, client *http.Client, method, target string, auth func(*http.Request), body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	auth(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned status %d", method, req.URL.Path, resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", req.URL.Host, err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package cmdb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestField(t *testing.T) {
+	record := map[string]interface{}{
+		"name":          "web-1",
+		"location.name": "AMS1",
+		"status":        map[string]interface{}{"value": "active", "label": "Active"},
+		"site":          map[string]interface{}{"name": "ams1"},
+		"id":            float64(42),
+		"virtual":       true,
+	}
+	for path, want := range map[string]string{
+		"name":          "web-1",
+		"location.name": "AMS1",
+		"status":        "active",
+		"site.name":     "ams1",
+		"id":            "42",
+		"virtual":       "true",
+		"missing.name":  "",
+	} {
+		if got := Field(record, path); got != want {
+			t.Errorf("Field(%s): expected %q, got %q", path, want, got)
+		}
+	}
+}
+
+func TestServiceNow(t *testing.T) {
+	var patched, posted map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, _ := r.BasicAuth(); user != "erebus" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/now/table/cmdb_ci_server":
+			if !strings.Contains(r.URL.Query().Get("sysparm_fields"), "location.name") {
+				t.Errorf("Expected dot-walked fields requested, got %s", r.URL.Query().Get("sysparm_fields"))
+			}
+			w.Write([]byte(`{"result": [
+				{"sys_id": "a1", "name": "web-1", "sys_class_name": "cmdb_ci_linux_server", "ip_address": "10.0.0.1", "location.name": "AMS1"},
+				{"sys_id": "b2", "name": "db-1", "sys_class_name": "cmdb_ci_linux_server"},
+				{"name": "orphan"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/now/table/cmdb_rel_ci":
+			w.Write([]byte(`{"result": [
+				{"parent": "a1", "child": "b2", "type.name": "Depends on::Used by"},
+				{"parent": "a1", "child": "b2", "type.name": "Backs up::Backed up by"},
+				{"parent": "a1", "child": "zz", "type.name": "Depends on::Used by"}]}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/api/now/table/cmdb_ci_server/a1":
+			json.NewDecoder(r.Body).Decode(&patched)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/now/table/cmdb_rel_ci":
+			json.NewDecoder(r.Body).Decode(&posted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	source := Source{Name: "sn", Kind: KindServiceNow, URL: server.URL, Table: "cmdb_ci_server", Username: "erebus", Password: "secret"}
+	if err := source.Validate(); err != nil {
+		t.Fatalf("Expected a valid source: %v", err)
+	}
+	client := NewClient(source, server.Client())
+	snapshot, err := client.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(snapshot.Items) != 2 || snapshot.Items[0].Name != "web-1" || snapshot.Items[0].Attributes["ip_address"] != "10.0.0.1" ||
+		snapshot.Items[0].References["located_in"] != "AMS1" {
+		t.Errorf("Unexpected items: %+v", snapshot.Items)
+	}
+	if len(snapshot.Relationships) != 2 || snapshot.Relationships[0].Predicate != "backs_up" || snapshot.Relationships[1].Predicate != "depends_on" {
+		t.Errorf("Unexpected relationships: %+v", snapshot.Relationships)
+	}
+	again, _ := client.Fetch(context.Background())
+	if again.Revision() != snapshot.Revision() {
+		t.Error("Expected equal content to have an equal revision")
+	}
+
+	if err := client.Apply(context.Background(), Update{Kind: UpdateAttribute, CI: "a1", Field: "ip_address", Value: "10.0.0.9"}); err != nil || patched["ip_address"] != "10.0.0.9" {
+		t.Errorf("Expected the field patched, got %v: %v", patched, err)
+	}
+	if err := client.Apply(context.Background(), Update{Kind: UpdateRelationship, CI: "a1", Child: "b2", Type: "Runs on::Runs"}); err != nil || posted["type"] != "Runs on::Runs" {
+		t.Errorf("Expected the relationship inserted, got %v: %v", posted, err)
+	}
+}
+
+func TestNetBox(t *testing.T) {
+	var journal map[string]interface{}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token t0k" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/dcim/devices/" && r.URL.Query().Get("offset") == "":
+			if r.URL.Query().Get("status") != "active" {
+				t.Errorf("Expected the filters sent, got %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"next": "` + server.URL + `/api/dcim/devices/?offset=1", "results": [
+				{"id": 1, "name": "sw-1", "role": {"slug": "switch"}, "status": {"value": "active"}, "site": {"name": "ams1"}}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/dcim/devices/":
+			w.Write([]byte(`{"next": null, "results": [{"id": 2, "name": "sw-2", "serial": "X1"}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/extras/journal-entries/":
+			json.NewDecoder(r.Body).Decode(&journal)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	source := Source{Name: "nb", Kind: KindNetBox, URL: server.URL, Token: "t0k", Query: "status=active"}
+	client := NewClient(source, server.Client())
+	snapshot, err := client.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(snapshot.Items) != 2 || snapshot.Items[0].Class != "switch" || snapshot.Items[0].Attributes["status"] != "active" ||
+		snapshot.Items[0].References["located_in"] != "ams1" || snapshot.Items[1].Attributes["serial"] != "X1" {
+		t.Errorf("Unexpected items: %+v", snapshot.Items)
+	}
+
+	if err := client.Apply(context.Background(), Update{Kind: UpdateRelationship, CI: "1", Child: "2", Type: "connects_to"}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if journal["assigned_object_type"] != "dcim.device" || journal["assigned_object_id"] != float64(1) {
+		t.Errorf("Unexpected journal entry: %v", journal)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, s := range []Source{
+		{Kind: KindNetBox, URL: "https://netbox"},
+		{Name: "x", Kind: "itop", URL: "https://itop"},
+		{Name: "x", Kind: KindNetBox, URL: "netbox.internal"},
+		{Name: "x", Kind: KindServiceNow, URL: "https://sn", Password: "p"},
+		{Name: "x", Kind: KindServiceNow, URL: "https://sn", WriteBack: "always"},
+	} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("Expected %+v rejected", s)
+		}
+	}
+}
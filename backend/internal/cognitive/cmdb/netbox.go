@@ -0,0 +1,105 @@
+package cmdb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// netBox reads objects of one REST endpoint, such as dcim/devices.
+// References to sites, racks and clusters are read as mapped fields.
+type netBox struct {
+	source  Source
+	mapping Mapping
+	client  *http.Client
+}
+
+func (n *netBox) auth(req *http.Request) {
+	if n.source.Token != "" {
+		req.Header.Set("Authorization", "Token "+n.source.Token)
+	}
+}
+
+func (n *netBox) apiURL(endpoint string) string {
+	return strings.TrimRight(n.source.URL, "/") + "/api/" + endpoint + "/"
+}
+
+// Fetch reads the objects of the source's endpoint matching its filters,
+// following the pages NetBox links to
+func (n *netBox) Fetch(ctx context.Context) (*Snapshot, error) {
+	base, err := url.Parse(n.source.URL)
+	if err != nil {
+		return nil, err
+	}
+	params, err := url.ParseQuery(n.source.Query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid netbox filters: %w", err)
+	}
+	params.Set("limit", strconv.Itoa(PageSize))
+
+	snapshot := &Snapshot{Items: []CI{}, Relationships: []Relationship{}}
+	count := 0
+	for next := n.apiURL(n.source.table()) + "?" + params.Encode(); next != ""; {
+		var page struct {
+			Next    *string                  `json:"next"`
+			Results []map[string]interface{} `json:"results"`
+		}
+		if err := send(ctx, n.client, http.MethodGet, next, n.auth, nil, &page); err != nil {
+			return nil, err
+		}
+		count += len(page.Results)
+		if count > MaxRecords {
+			return nil, fmt.Errorf("%s has more than %d records", n.source.table(), MaxRecords)
+		}
+		for _, record := range page.Results {
+			if ci, ok := buildCI(record, n.mapping); ok {
+				snapshot.Items = append(snapshot.Items, ci)
+			}
+		}
+
+		next = ""
+		if page.Next != nil && *page.Next != "" {
+			// The token is only sent to the configured host
+			u, err := url.Parse(*page.Next)
+			if err != nil || u.Host != base.Host {
+				return nil, fmt.Errorf("netbox linked to an unexpected next page %q", *page.Next)
+			}
+			next = *page.Next
+		}
+	}
+	sortSnapshot(snapshot)
+	return snapshot, nil
+}
+
+// Apply patches a field of an object. NetBox has no generic relationships
+// between objects, so discovered relationships are written as journal
+// entries of the parent object.
+func (n *netBox) Apply(ctx context.Context, update Update) error {
+	switch update.Kind {
+	case UpdateAttribute:
+		return send(ctx, n.client, http.MethodPatch, n.apiURL(n.source.table()+"/"+url.PathEscape(update.CI)), n.auth,
+			map[string]string{update.Field: update.Value}, nil)
+	case UpdateRelationship:
+		id, err := strconv.Atoi(update.CI)
+		if err != nil {
+			return fmt.Errorf("netbox object id %q is not numeric", update.CI)
+		}
+		return send(ctx, n.client, http.MethodPost, n.apiURL("extras/journal-entries"), n.auth, map[string]interface{}{
+			"assigned_object_type": objectType(n.source.table()),
+			"assigned_object_id":   id,
+			"kind":                 "info",
+			"comments":             fmt.Sprintf("Relationship discovered by Erebus: %s %s", update.Type, update.Child),
+		}, nil)
+	}
+	return fmt.Errorf("unknown update kind %q", update.Kind)
+}
+
+// objectType returns the content type of an endpoint's objects, e.g.
+// dcim.device for dcim/devices
+func objectType(endpoint string) string {
+	app, model, _ := strings.Cut(endpoint, "/")
+	return app + "." + strings.TrimSuffix(strings.ReplaceAll(model, "-", ""), "s")
+}
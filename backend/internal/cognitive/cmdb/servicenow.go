@@ -0,0 +1,142 @@
+package cmdb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// relationshipTable holds ServiceNow's CI relationships
+const relationshipTable = "cmdb_rel_ci"
+
+// serviceNow reads CIs and their relationships through the Table API
+type serviceNow struct {
+	source  Source
+	mapping Mapping
+	client  *http.Client
+}
+
+func (s *serviceNow) auth(req *http.Request) {
+	if s.source.Username != "" {
+		req.SetBasicAuth(s.source.Username, s.source.Password)
+	} else if s.source.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.source.Token)
+	}
+}
+
+// tableURL returns the Table API URL of a table or of one of its records
+func (s *serviceNow) tableURL(table, sysID string) string {
+	target := strings.TrimRight(s.source.URL, "/") + "/api/now/table/" + table
+	if sysID != "" {
+		target += "/" + url.PathEscape(sysID)
+	}
+	return target
+}
+
+// list reads all records of a table matching a query, page by page
+func (s *serviceNow) list(ctx context.Context, table, query string, fields []string) ([]map[string]interface{}, error) {
+	records := make([]map[string]interface{}, 0)
+	for offset := 0; ; offset += PageSize {
+		params := url.Values{}
+		params.Set("sysparm_limit", strconv.Itoa(PageSize))
+		params.Set("sysparm_offset", strconv.Itoa(offset))
+		params.Set("sysparm_exclude_reference_link", "true")
+		params.Set("sysparm_fields", strings.Join(fields, ","))
+		if query != "" {
+			params.Set("sysparm_query", query)
+		}
+
+		var page struct {
+			Result []map[string]interface{} `json:"result"`
+		}
+		if err := send(ctx, s.client, http.MethodGet, s.tableURL(table, "")+"?"+params.Encode(), s.auth, nil, &page); err != nil {
+			return nil, err
+		}
+		records = append(records, page.Result...)
+		if len(records) > MaxRecords {
+			return nil, fmt.Errorf("%s has more than %d records", table, MaxRecords)
+		}
+		if len(page.Result) < PageSize {
+			return records, nil
+		}
+	}
+}
+
+// Fetch reads the CIs of the source's table and the relationships between
+// them. Relationship types without a mapping get a predicate derived from
+// their name, e.g. "Depends on::Used by" becomes depends_on.
+func (s *serviceNow) Fetch(ctx context.Context) (*Snapshot, error) {
+	fields := map[string]bool{s.mapping.Identity: true, s.mapping.Name: true, s.mapping.Class: true}
+	for _, field := range s.mapping.Attributes {
+		fields[field] = true
+	}
+	for _, field := range s.mapping.References {
+		fields[field] = true
+	}
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	records, err := s.list(ctx, s.source.table(), s.source.Query, names)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{Items: make([]CI, 0, len(records)), Relationships: []Relationship{}}
+	ids := make(map[string]bool, len(records))
+	for _, record := range records {
+		if ci, ok := buildCI(record, s.mapping); ok {
+			snapshot.Items = append(snapshot.Items, ci)
+			ids[ci.ID] = true
+		}
+	}
+
+	relations, err := s.list(ctx, relationshipTable, "", []string{"parent", "child", "type.name"})
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range relations {
+		parent, child := Field(record, "parent"), Field(record, "child")
+		if !ids[parent] || !ids[child] {
+			continue
+		}
+		typeName := Field(record, "type.name")
+		predicate, mapped := s.mapping.Relationships[typeName]
+		if !mapped {
+			predicate = RelationshipPredicate(typeName)
+		}
+		if predicate != "" {
+			snapshot.Relationships = append(snapshot.Relationships, Relationship{Predicate: predicate, Parent: parent, Child: child})
+		}
+	}
+	sortSnapshot(snapshot)
+	return snapshot, nil
+}
+
+// Apply patches a field of a CI, or inserts a relationship with the type
+// named by the update
+func (s *serviceNow) Apply(ctx context.Context, update Update) error {
+	switch update.Kind {
+	case UpdateAttribute:
+		return send(ctx, s.client, http.MethodPatch, s.tableURL(s.source.table(), update.CI), s.auth,
+			map[string]string{update.Field: update.Value}, nil)
+	case UpdateRelationship:
+		return send(ctx, s.client, http.MethodPost, s.tableURL(relationshipTable, "")+"?sysparm_input_display_value=true", s.auth,
+			map[string]string{"parent": update.CI, "child": update.Child, "type": update.Type}, nil)
+	}
+	return fmt.Errorf("unknown update kind %q", update.Kind)
+}
+
+// RelationshipPredicate derives a predicate from the parent side of a
+// ServiceNow relationship type name
+func RelationshipPredicate(typeName string) string {
+	if i := strings.Index(typeName, "::"); i >= 0 {
+		typeName = typeName[:i]
+	}
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(typeName)), " ", "_")
+}
@@ -0,0 +1,234 @@
+package cognitive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/cmdb"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/connectors"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/terraform"
+)
+
+// Kinds of the built-in connectors
+const (
+	ConnectorCMDB      = "cmdb"      // Settings are a cmdb.Source
+	ConnectorTerraform = "terraform" // Settings are a terraform.Source and the attributes to record
+	ConnectorCost      = "cost"      // Settings are the url and token of a billing export
+)
+
+// registerConnectorKinds makes the built-in connectors available
+func (ce *CognitiveEngine) registerConnectorKinds() {
+	ce.connectors.RegisterKind(ConnectorCMDB, ce.newCMDBConnector)
+	ce.connectors.RegisterKind(ConnectorTerraform, ce.newTerraformConnector)
+	ce.connectors.RegisterKind(ConnectorCost, ce.newCostConnector)
+}
+
+// decodeSettings reads the settings of a connector spec
+func decodeSettings(spec connectors.Spec, settings interface{}) error {
+	if len(spec.Settings) == 0 {
+		return fmt.Errorf("%s connector requires settings", spec.Kind)
+	}
+	if err := json.Unmarshal(spec.Settings, settings); err != nil {
+		return fmt.Errorf("invalid %s connector settings: %w", spec.Kind, err)
+	}
+	return nil
+}
+
+// cmdbConnector mirrors one CMDB source with a CMDB agent of its own,
+// writing the same atoms as the tenant's CMDB agent
+type cmdbConnector struct {
+	agent *agents.CMDBAgent
+}
+
+func (ce *CognitiveEngine) newCMDBConnector(tenantID string, spec connectors.Spec) (connectors.Connector, error) {
+	var source cmdb.Source
+	if err := decodeSettings(spec, &source); err != nil {
+		return nil, err
+	}
+	source.Name = spec.Name
+	if err := source.Validate(); err != nil {
+		return nil, err
+	}
+	config := agents.DefaultCMDBConfig()
+	config.Sources = []cmdb.Source{source}
+	agent := agents.NewCMDBAgent(fmt.Sprintf("connector-%s-%s", tenantID, spec.Name), "CMDBConnector", tenantID,
+		&tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID}, config)
+	agent.SetSecrets(ce.secrets.ForTenant(tenantID))
+	agent.SetSandbox(ce.sandbox.ForTenant(tenantID))
+	return &cmdbConnector{agent: agent}, nil
+}
+
+func (c *cmdbConnector) Sync(ctx context.Context) error {
+	_, err := c.agent.Sync(ctx, false, false)
+	return err
+}
+
+func (c *cmdbConnector) Details() interface{} {
+	if statuses := c.agent.GetStatuses(); len(statuses) > 0 {
+		return statuses[0]
+	}
+	return nil
+}
+
+// terraformConnector mirrors one Terraform state with a Terraform agent of
+// its own
+type terraformConnector struct {
+	agent *agents.TerraformAgent
+}
+
+func (ce *CognitiveEngine) newTerraformConnector(tenantID string, spec connectors.Spec) (connectors.Connector, error) {
+	var settings struct {
+		terraform.Source
+		Attributes []string `json:"attributes,omitempty"`
+	}
+	if err := decodeSettings(spec, &settings); err != nil {
+		return nil, err
+	}
+	settings.Name = spec.Name
+	if err := settings.Source.Validate(); err != nil {
+		return nil, err
+	}
+	config := agents.DefaultTerraformConfig()
+	config.Sources = []terraform.Source{settings.Source}
+	if settings.Attributes != nil {
+		config.Attributes = settings.Attributes
+	}
+	agent := agents.NewTerraformAgent(fmt.Sprintf("connector-%s-%s", tenantID, spec.Name), "TerraformConnector", tenantID,
+		&tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID}, config)
+	agent.SetSecrets(ce.secrets.ForTenant(tenantID))
+	agent.SetSandbox(ce.sandbox.ForTenant(tenantID))
+	return &terraformConnector{agent: agent}, nil
+}
+
+func (c *terraformConnector) Sync(ctx context.Context) error {
+	_, err := c.agent.Sync(ctx, false)
+	return err
+}
+
+func (c *terraformConnector) Details() interface{} {
+	statuses := c.agent.GetStatuses()
+	if len(statuses) == 0 {
+		return nil
+	}
+	// The resources are listed by the Terraform endpoints
+	status := statuses[0]
+	return map[string]interface{}{
+		"revision":   status.Revision,
+		"resources":  len(status.Resources),
+		"changed_at": status.ChangedAt,
+	}
+}
+
+// costConnector replaces the tenant's cost rates with a billing export's
+type costConnector struct {
+	engine   *CognitiveEngine
+	tenantID string
+	url      string
+	token    string
+	rates    atomic.Int64
+}
+
+func (ce *CognitiveEngine) newCostConnector(tenantID string, spec connectors.Spec) (connectors.Connector, error) {
+	var settings struct {
+		URL   string `json:"url"`
+		Token string `json:"token,omitempty"`
+	}
+	if err := decodeSettings(spec, &settings); err != nil {
+		return nil, err
+	}
+	if settings.URL == "" {
+		return nil, fmt.Errorf("billing export url is required")
+	}
+	return &costConnector{engine: ce, tenantID: tenantID, url: settings.URL, token: settings.Token}, nil
+}
+
+func (c *costConnector) Sync(ctx context.Context) error {
+	rates, err := c.engine.SyncCostRates(ctx, c.tenantID, c.url, c.token)
+	if err == nil {
+		c.rates.Store(int64(rates))
+	}
+	return err
+}
+
+func (c *costConnector) Details() interface{} {
+	return map[string]interface{}{"rates": c.rates.Load()}
+}
+
+// SetConnector creates or replaces a tenant's connector and starts it
+// unless the spec declares it stopped
+func (ce *CognitiveEngine) SetConnector(tenantID string, spec connectors.Spec) (connectors.Status, error) {
+	status, err := ce.connectors.Set(tenantID, spec)
+	if err != nil {
+		return connectors.Status{}, err
+	}
+	ce.connectorAgent(tenantID)
+	return status, nil
+}
+
+// GetConnector returns the status of a tenant's connector
+func (ce *CognitiveEngine) GetConnector(tenantID, name string) (connectors.Status, error) {
+	return ce.connectors.Get(tenantID, name)
+}
+
+// ListConnectors returns the statuses of a tenant's connectors
+func (ce *CognitiveEngine) ListConnectors(tenantID string) []connectors.Status {
+	return ce.connectors.List(tenantID)
+}
+
+// ConnectorKinds returns the kinds of connectors that may be declared
+func (ce *CognitiveEngine) ConnectorKinds() []string {
+	return ce.connectors.Kinds()
+}
+
+// StartConnector resumes a stopped connector
+func (ce *CognitiveEngine) StartConnector(tenantID, name string) (connectors.Status, error) {
+	return ce.connectors.Start(tenantID, name)
+}
+
+// StopConnector stops syncing a connector
+func (ce *CognitiveEngine) StopConnector(tenantID, name string) (connectors.Status, error) {
+	return ce.connectors.Stop(tenantID, name)
+}
+
+// SyncConnector syncs a connector immediately
+func (ce *CognitiveEngine) SyncConnector(ctx context.Context, tenantID, name string) (connectors.Status, error) {
+	return ce.connectors.Sync(ctx, tenantID, name)
+}
+
+// RemoveConnector removes a tenant's connector, and its agent with the
+// last connector. What the connector wrote is kept.
+func (ce *CognitiveEngine) RemoveConnector(tenantID, name string) error {
+	if err := ce.connectors.Remove(tenantID, name); err != nil {
+		return err
+	}
+	if ce.connectors.HasConnectors(tenantID) {
+		return nil
+	}
+
+	ce.mu.Lock()
+	agent, exists := ce.connectorAgents[tenantID]
+	delete(ce.connectorAgents, tenantID)
+	ce.mu.Unlock()
+	if exists {
+		ce.agentScheduler.UnregisterAgent(agent.GetID())
+	}
+	return nil
+}
+
+// connectorAgent returns a tenant's connector agent, registering it if
+// needed
+func (ce *CognitiveEngine) connectorAgent(tenantID string) *agents.ConnectorAgent {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if agent, exists := ce.connectorAgents[tenantID]; exists {
+		return agent
+	}
+	agent := agents.NewConnectorAgent(fmt.Sprintf("connectors-%s", tenantID), "ConnectorAgent", tenantID, ce.connectors)
+	ce.connectorAgents[tenantID] = agent
+	ce.agentScheduler.RegisterAgent(agent)
+	return agent
+}
@@ -0,0 +1,441 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/retry"
+)
+
+// State is the lifecycle state of a connector
+type State string
+
+const (
+	StateStopped State = "stopped" // Not synced until started
+	StateRunning State = "running" // Synced every interval
+	StateBackoff State = "backoff" // Failing; retried after an exponential backoff
+)
+
+// Connector is an integration with an external system, such as a CMDB or
+// a Terraform state backend, synced periodically
+type Connector interface {
+	// Sync performs one round of the connector's work, e.g. reading its
+	// source into the AtomSpace
+	Sync(ctx context.Context) error
+}
+
+// Detailer is implemented by connectors reporting kind-specific details,
+// such as the revision last read, in their status
+type Detailer interface {
+	Details() interface{}
+}
+
+// Factory creates a connector of a kind from its spec
+type Factory func(tenantID string, spec Spec) (Connector, error)
+
+// Spec declares a connector
+type Spec struct {
+	Name     string          `json:"name"`
+	Kind     string          `json:"kind"`
+	Interval time.Duration   `json:"interval_ns,omitempty"` // Time between syncs; the default if 0
+	Settings json.RawMessage `json:"settings,omitempty"`    // Kind-specific configuration, e.g. a CMDB source
+	Stopped  bool            `json:"stopped,omitempty"`     // Declared but not started
+}
+
+// Validate checks a spec
+func (s *Spec) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("connector name is required")
+	}
+	if strings.ContainsAny(s.Name, "/\r\n") {
+		return fmt.Errorf("invalid connector name: %q", s.Name)
+	}
+	if s.Kind == "" {
+		return fmt.Errorf("connector kind is required")
+	}
+	if s.Interval < 0 {
+		return fmt.Errorf("connector interval must not be negative")
+	}
+	return nil
+}
+
+// Config configures connector lifecycles
+type Config struct {
+	Interval time.Duration // Default time between syncs
+	Backoff  retry.Policy  // Delay before retrying a failing connector; MaxAttempts is unused
+}
+
+// DefaultConfig syncs every 5 minutes and retries failing connectors after
+// 10 seconds, doubling up to 10 minutes
+func DefaultConfig() Config {
+	return Config{
+		Interval: 5 * time.Minute,
+		Backoff: retry.Policy{
+			InitialBackoff: 10 * time.Second,
+			MaxBackoff:     10 * time.Minute,
+			Multiplier:     2.0,
+		},
+	}
+}
+
+// Status reports the lifecycle, health and metrics of a connector. Settings
+// are left out as they may hold credentials.
+type Status struct {
+	Name                string        `json:"name"`
+	Kind                string        `json:"kind"`
+	State               State         `json:"state"`
+	Healthy             bool          `json:"healthy"`
+	Interval            time.Duration `json:"interval_ns"`
+	StartedAt           time.Time     `json:"started_at,omitempty"`
+	LastSyncAt          time.Time     `json:"last_sync_at,omitempty"`
+	LastSuccessAt       time.Time     `json:"last_success_at,omitempty"`
+	LastError           string        `json:"last_error,omitempty"`
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+	NextSyncAt          time.Time     `json:"next_sync_at,omitempty"`
+	Syncs               int64         `json:"syncs"`
+	Failures            int64         `json:"failures"`
+	LastDuration        time.Duration `json:"last_duration_ns"`
+	TotalDuration       time.Duration `json:"total_duration_ns"`
+	Details             interface{}   `json:"details,omitempty"`
+}
+
+// instance is a connector and its lifecycle
+type instance struct {
+	spec      Spec
+	connector Connector
+	status    Status
+	syncMu    sync.Mutex // Serializes syncs of the connector
+}
+
+// Manager keeps the connectors of each tenant, runs those due and backs
+// off failing ones
+type Manager struct {
+	config    Config
+	factories map[string]Factory
+	tenants   map[string]map[string]*instance // tenantID -> name -> connector
+	metrics   Metrics
+	scrub     func(string) string
+	mu        sync.RWMutex
+}
+
+// NewManager creates a connector manager
+func NewManager(config Config) *Manager {
+	if config.Interval <= 0 {
+		config.Interval = DefaultConfig().Interval
+	}
+	return &Manager{
+		config:    config,
+		factories: make(map[string]Factory),
+		tenants:   make(map[string]map[string]*instance),
+		metrics:   noMetrics{},
+		scrub:     func(s string) string { return s },
+	}
+}
+
+// SetMetrics sets where connector metrics are reported
+func (m *Manager) SetMetrics(metrics Metrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metrics = metrics
+}
+
+// SetScrubber sets the function masking secrets in recorded errors
+func (m *Manager) SetScrubber(scrub func(string) string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scrub = scrub
+}
+
+// RegisterKind makes a kind of connector available
+func (m *Manager) RegisterKind(kind string, factory Factory) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.factories[kind] = factory
+}
+
+// Kinds returns the available kinds of connectors
+func (m *Manager) Kinds() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	kinds := make([]string, 0, len(m.factories))
+	for kind := range m.factories {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Set creates or replaces a tenant's connector. It is started, and synced
+// at the next run, unless the spec declares it stopped. A replaced
+// connector starts over with the new spec.
+func (m *Manager) Set(tenantID string, spec Spec) (Status, error) {
+	if err := spec.Validate(); err != nil {
+		return Status{}, err
+	}
+	m.mu.RLock()
+	factory, exists := m.factories[spec.Kind]
+	m.mu.RUnlock()
+	if !exists {
+		return Status{}, fmt.Errorf("unknown connector kind: %s", spec.Kind)
+	}
+	connector, err := factory(tenantID, spec)
+	if err != nil {
+		return Status{}, fmt.Errorf("connector %s: %w", spec.Name, err)
+	}
+
+	interval := spec.Interval
+	if interval == 0 {
+		interval = m.config.Interval
+	}
+	inst := &instance{
+		spec:      spec,
+		connector: connector,
+		status:    Status{Name: spec.Name, Kind: spec.Kind, State: StateStopped, Interval: interval},
+	}
+	if !spec.Stopped {
+		now := time.Now()
+		inst.status.State = StateRunning
+		inst.status.StartedAt = now
+		inst.status.NextSyncAt = now
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.tenants[tenantID] == nil {
+		m.tenants[tenantID] = make(map[string]*instance)
+	}
+	m.tenants[tenantID][spec.Name] = inst
+	m.metrics.ConnectorState(tenantID, spec.Name, spec.Kind, inst.status.State)
+	return inst.snapshot(), nil
+}
+
+func (m *Manager) get(tenantID, name string) (*instance, error) {
+	inst, exists := m.tenants[tenantID][name]
+	if !exists {
+		return nil, fmt.Errorf("connector %s not found for tenant %s", name, tenantID)
+	}
+	return inst, nil
+}
+
+// snapshot copies the status, with the connector's details
+func (inst *instance) snapshot() Status {
+	status := inst.status
+	status.Healthy = status.State == StateRunning && status.ConsecutiveFailures == 0
+	if detailer, ok := inst.connector.(Detailer); ok {
+		status.Details = detailer.Details()
+	}
+	return status
+}
+
+// Get returns the status of a tenant's connector
+func (m *Manager) Get(tenantID, name string) (Status, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	inst, err := m.get(tenantID, name)
+	if err != nil {
+		return Status{}, err
+	}
+	return inst.snapshot(), nil
+}
+
+// GetSpec returns the spec of a tenant's connector
+func (m *Manager) GetSpec(tenantID, name string) (Spec, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	inst, err := m.get(tenantID, name)
+	if err != nil {
+		return Spec{}, err
+	}
+	return inst.spec, nil
+}
+
+// List returns the statuses of a tenant's connectors sorted by name
+func (m *Manager) List(tenantID string) []Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]Status, 0, len(m.tenants[tenantID]))
+	for _, inst := range m.tenants[tenantID] {
+		result = append(result, inst.snapshot())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Start resumes a stopped connector, which is synced at the next run. A
+// connector backing off is retried at the next run.
+func (m *Manager) Start(tenantID, name string) (Status, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	inst, err := m.get(tenantID, name)
+	if err != nil {
+		return Status{}, err
+	}
+	now := time.Now()
+	if inst.status.State == StateStopped {
+		inst.status.StartedAt = now
+		inst.status.ConsecutiveFailures = 0
+		inst.status.State = StateRunning
+	}
+	inst.status.NextSyncAt = now
+	inst.spec.Stopped = false
+	m.metrics.ConnectorState(tenantID, name, inst.spec.Kind, inst.status.State)
+	return inst.snapshot(), nil
+}
+
+// Stop stops syncing a connector until it is started again. A sync in
+// progress completes.
+func (m *Manager) Stop(tenantID, name string) (Status, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	inst, err := m.get(tenantID, name)
+	if err != nil {
+		return Status{}, err
+	}
+	inst.status.State = StateStopped
+	inst.status.NextSyncAt = time.Time{}
+	inst.spec.Stopped = true
+	m.metrics.ConnectorState(tenantID, name, inst.spec.Kind, StateStopped)
+	return inst.snapshot(), nil
+}
+
+// Remove deletes a tenant's connector. What it wrote is kept.
+func (m *Manager) Remove(tenantID, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, err := m.get(tenantID, name); err != nil {
+		return err
+	}
+	delete(m.tenants[tenantID], name)
+	if len(m.tenants[tenantID]) == 0 {
+		delete(m.tenants, tenantID)
+	}
+	m.metrics.ConnectorRemoved(tenantID, name)
+	return nil
+}
+
+// Sync syncs a tenant's connector now, whatever its state and schedule
+func (m *Manager) Sync(ctx context.Context, tenantID, name string) (Status, error) {
+	m.mu.RLock()
+	inst, err := m.get(tenantID, name)
+	m.mu.RUnlock()
+	if err != nil {
+		return Status{}, err
+	}
+	err = m.sync(ctx, tenantID, inst)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return inst.snapshot(), err
+}
+
+// RunDue syncs the tenant's running connectors whose next sync is due,
+// one after the other. A failing connector is retried after a backoff
+// growing with its consecutive failures; its failure does not fail the
+// run.
+func (m *Manager) RunDue(ctx context.Context, tenantID string) {
+	now := time.Now()
+	m.mu.RLock()
+	due := make([]*instance, 0)
+	for _, inst := range m.tenants[tenantID] {
+		if inst.status.State != StateStopped && !now.Before(inst.status.NextSyncAt) {
+			due = append(due, inst)
+		}
+	}
+	m.mu.RUnlock()
+	sort.Slice(due, func(i, j int) bool { return due[i].spec.Name < due[j].spec.Name })
+
+	for _, inst := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		m.sync(ctx, tenantID, inst)
+	}
+}
+
+// sync runs one sync of a connector and records its outcome
+func (m *Manager) sync(ctx context.Context, tenantID string, inst *instance) error {
+	inst.syncMu.Lock()
+	defer inst.syncMu.Unlock()
+
+	start := time.Now()
+	err := inst.connector.Sync(ctx)
+	duration := time.Since(start)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := &inst.status
+	s.Syncs++
+	s.LastSyncAt = start
+	s.LastDuration = duration
+	s.TotalDuration += duration
+	if err != nil {
+		s.Failures++
+		s.ConsecutiveFailures++
+		s.LastError = m.scrub(err.Error())
+		if s.State != StateStopped {
+			s.State = StateBackoff
+			s.NextSyncAt = time.Now().Add(m.config.Backoff.Backoff(s.ConsecutiveFailures))
+		}
+	} else {
+		s.LastSuccessAt = time.Now()
+		s.LastError = ""
+		s.ConsecutiveFailures = 0
+		if s.State != StateStopped {
+			s.State = StateRunning
+			s.NextSyncAt = start.Add(s.Interval)
+		}
+	}
+	m.metrics.ConnectorSynced(tenantID, s.Name, s.Kind, duration, err)
+	m.metrics.ConnectorState(tenantID, s.Name, s.Kind, s.State)
+	if err != nil {
+		return fmt.Errorf("connector %s: %s", s.Name, s.LastError)
+	}
+	return nil
+}
+
+// HasConnectors reports whether a tenant has connectors
+func (m *Manager) HasConnectors(tenantID string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.tenants[tenantID]) > 0
+}
+
+// Purge removes a tenant's connectors, returning how many were removed
+func (m *Manager) Purge(tenantID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	removed := len(m.tenants[tenantID])
+	for name := range m.tenants[tenantID] {
+		m.metrics.ConnectorRemoved(tenantID, name)
+	}
+	delete(m.tenants, tenantID)
+	return removed
+}
+
+// GetStats returns connector statistics
+func (m *Manager) GetStats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	byState := make(map[State]int)
+	total := 0
+	var syncs, failures int64
+	for _, connectors := range m.tenants {
+		for _, inst := range connectors {
+			total++
+			byState[inst.status.State]++
+			syncs += inst.status.Syncs
+			failures += inst.status.Failures
+		}
+	}
+	return map[string]interface{}{
+		"connectors": total,
+		"by_state":   byState,
+		"syncs":      syncs,
+		"failures":   failures,
+	}
+}
@@ -0,0 +1,195 @@
+package connectors
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/retry"
+)
+
+type fakeConnector struct {
+	err   error
+	syncs int
+}
+
+func (c *fakeConnector) Sync(ctx context.Context) error {
+	c.syncs++
+	return c.err
+}
+
+func (c *fakeConnector) Details() interface{} {
+	return map[string]int{"syncs": c.syncs}
+}
+
+type recordingMetrics struct {
+	states   []State
+	syncs    int
+	failures int
+	removed  []string
+}
+
+func (m *recordingMetrics) ConnectorState(tenantID, name, kind string, state State) {
+	m.states = append(m.states, state)
+}
+
+func (m *recordingMetrics) ConnectorSynced(tenantID, name, kind string, duration time.Duration, err error) {
+	m.syncs++
+	if err != nil {
+		m.failures++
+	}
+}
+
+func (m *recordingMetrics) ConnectorRemoved(tenantID, name string) {
+	m.removed = append(m.removed, tenantID+"/"+name)
+}
+
+func newTestManager(connector *fakeConnector) (*Manager, *recordingMetrics) {
+	m := NewManager(Config{
+		Interval: time.Hour,
+		Backoff:  retry.Policy{InitialBackoff: time.Minute, MaxBackoff: 4 * time.Minute, Multiplier: 2},
+	})
+	metrics := &recordingMetrics{}
+	m.SetMetrics(metrics)
+	m.RegisterKind("fake", func(tenantID string, spec Spec) (Connector, error) {
+		if string(spec.Settings) == `"bad"` {
+			return nil, errors.New("bad settings")
+		}
+		return connector, nil
+	})
+	return m, metrics
+}
+
+func TestSpecValidate(t *testing.T) {
+	m, _ := newTestManager(&fakeConnector{})
+	for _, spec := range []Spec{
+		{Kind: "fake"},
+		{Name: "a/b", Kind: "fake"},
+		{Name: "a"},
+		{Name: "a", Kind: "fake", Interval: -time.Second},
+		{Name: "a", Kind: "unknown"},
+		{Name: "a", Kind: "fake", Settings: []byte(`"bad"`)},
+	} {
+		if _, err := m.Set("t1", spec); err == nil {
+			t.Errorf("Expected %+v to be rejected", spec)
+		}
+	}
+	if m.HasConnectors("t1") {
+		t.Error("Expected rejected connectors not kept")
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	connector := &fakeConnector{}
+	m, metrics := newTestManager(connector)
+	ctx := context.Background()
+
+	status, err := m.Set("t1", Spec{Name: "netbox", Kind: "fake"})
+	if err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if status.State != StateRunning || status.Interval != time.Hour {
+		t.Errorf("Expected a running connector with the default interval, got %+v", status)
+	}
+
+	m.RunDue(ctx, "t1")
+	m.RunDue(ctx, "t1")
+	if connector.syncs != 1 {
+		t.Errorf("Expected one sync until the interval passes, got %d", connector.syncs)
+	}
+	status, _ = m.Get("t1", "netbox")
+	if !status.Healthy || status.Syncs != 1 || status.NextSyncAt.Before(time.Now().Add(59*time.Minute)) {
+		t.Errorf("Unexpected status after a sync: %+v", status)
+	}
+
+	if status, _ = m.Stop("t1", "netbox"); status.State != StateStopped || status.Healthy {
+		t.Errorf("Expected a stopped connector, got %+v", status)
+	}
+	if spec, _ := m.GetSpec("t1", "netbox"); !spec.Stopped {
+		t.Error("Expected the spec to record the stop")
+	}
+	m.RunDue(ctx, "t1")
+	if connector.syncs != 1 {
+		t.Error("Expected a stopped connector not synced")
+	}
+	// Syncing on demand works whatever the state
+	if _, err := m.Sync(ctx, "t1", "netbox"); err != nil || connector.syncs != 2 {
+		t.Errorf("Expected a forced sync, got %d syncs (%v)", connector.syncs, err)
+	}
+
+	if status, _ = m.Start("t1", "netbox"); status.State != StateRunning {
+		t.Errorf("Expected a running connector, got %+v", status)
+	}
+	m.RunDue(ctx, "t1")
+	if connector.syncs != 3 {
+		t.Errorf("Expected a started connector synced at once, got %d syncs", connector.syncs)
+	}
+
+	if list := m.List("t1"); len(list) != 1 || list[0].Details == nil {
+		t.Errorf("Expected the connector listed with its details, got %+v", list)
+	}
+	if len(m.List("t2")) != 0 {
+		t.Error("Expected other tenants to have no connectors")
+	}
+	if err := m.Remove("t1", "netbox"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := m.Get("t1", "netbox"); err == nil {
+		t.Error("Expected the connector removed")
+	}
+	if len(metrics.removed) != 1 || metrics.syncs != 3 {
+		t.Errorf("Unexpected metrics: %+v", metrics)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	connector := &fakeConnector{err: errors.New("401 Unauthorized: token s3cr3t-token rejected")}
+	m, metrics := newTestManager(connector)
+	m.SetScrubber(func(text string) string { return strings.ReplaceAll(text, "s3cr3t-token", "***") })
+	ctx := context.Background()
+	m.Set("t1", Spec{Name: "servicenow", Kind: "fake"})
+
+	var delays []time.Duration
+	for i := 0; i < 4; i++ {
+		start := time.Now()
+		status, err := m.Sync(ctx, "t1", "servicenow")
+		if err == nil {
+			t.Fatal("Expected the sync to fail")
+		}
+		if status.State != StateBackoff || status.Healthy {
+			t.Errorf("Expected a backing off connector, got %+v", status)
+		}
+		delays = append(delays, status.NextSyncAt.Sub(start).Round(time.Minute))
+	}
+	expected := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute}
+	for i := range expected {
+		if delays[i] != expected[i] {
+			t.Errorf("Expected backoff %v after %d failures, got %v", expected[i], i+1, delays[i])
+		}
+	}
+	// Not due again until the backoff has passed
+	m.RunDue(ctx, "t1")
+	if connector.syncs != 4 {
+		t.Errorf("Expected no sync during the backoff, got %d syncs", connector.syncs)
+	}
+
+	status, _ := m.Get("t1", "servicenow")
+	if strings.Contains(status.LastError, "s3cr3t-token") || status.ConsecutiveFailures != 4 {
+		t.Errorf("Expected a scrubbed error and 4 failures, got %+v", status)
+	}
+
+	connector.err = nil
+	status, _ = m.Sync(ctx, "t1", "servicenow")
+	if status.State != StateRunning || !status.Healthy || status.ConsecutiveFailures != 0 || status.Failures != 4 {
+		t.Errorf("Expected the connector recovered, got %+v", status)
+	}
+	if metrics.failures != 4 || metrics.states[len(metrics.states)-1] != StateRunning {
+		t.Errorf("Unexpected metrics: %+v", metrics)
+	}
+
+	if removed := m.Purge("t1"); removed != 1 || m.HasConnectors("t1") {
+		t.Errorf("Expected 1 connector purged, got %d", removed)
+	}
+}
@@ -0,0 +1,93 @@
+package connectors
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics receives the metrics of connectors. The manager reports none
+// until metrics are set, so embedding the engine registers nothing
+// globally.
+type Metrics interface {
+	ConnectorState(tenantID, name, kind string, state State)
+	ConnectorSynced(tenantID, name, kind string, duration time.Duration, err error)
+	ConnectorRemoved(tenantID, name string)
+}
+
+type noMetrics struct{}
+
+func (noMetrics) ConnectorState(tenantID, name, kind string, state State)                        {}
+func (noMetrics) ConnectorSynced(tenantID, name, kind string, duration time.Duration, err error) {}
+func (noMetrics) ConnectorRemoved(tenantID, name string)                                         {}
+
+// stateGaugeValue maps states onto the erebus_connector_state gauge
+var stateGaugeValue = map[State]float64{
+	StateRunning: 0,
+	StateBackoff: 1,
+	StateStopped: 2,
+}
+
+// PrometheusMetrics exports connector metrics to Prometheus
+type PrometheusMetrics struct {
+	state    *prometheus.GaugeVec
+	syncs    *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewPrometheusMetrics creates connector metrics registered with reg
+func NewPrometheusMetrics(reg prometheus.Registerer) (*PrometheusMetrics, error) {
+	m := &PrometheusMetrics{
+		state: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "erebus_connector_state",
+				Help: "Connector state: 0 running, 1 backing off after failures, 2 stopped",
+			},
+			[]string{"tenant", "connector", "kind"},
+		),
+		syncs: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "erebus_connector_syncs_total",
+				Help: "Total number of connector syncs by result",
+			},
+			[]string{"tenant", "connector", "kind", "result"},
+		),
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "erebus_connector_sync_duration_seconds",
+				Help:    "Duration of connector syncs",
+				Buckets: prometheus.ExponentialBuckets(0.05, 2, 12),
+			},
+			[]string{"tenant", "connector", "kind"},
+		),
+	}
+	for _, c := range []prometheus.Collector{m.state, m.syncs, m.duration} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// ConnectorState sets the state gauge of a connector
+func (m *PrometheusMetrics) ConnectorState(tenantID, name, kind string, state State) {
+	m.state.WithLabelValues(tenantID, name, kind).Set(stateGaugeValue[state])
+}
+
+// ConnectorSynced counts a sync and observes its duration
+func (m *PrometheusMetrics) ConnectorSynced(tenantID, name, kind string, duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.syncs.WithLabelValues(tenantID, name, kind, result).Inc()
+	m.duration.WithLabelValues(tenantID, name, kind).Observe(duration.Seconds())
+}
+
+// ConnectorRemoved drops the metrics of a removed connector
+func (m *PrometheusMetrics) ConnectorRemoved(tenantID, name string) {
+	labels := prometheus.Labels{"tenant": tenantID, "connector": name}
+	m.state.DeletePartialMatch(labels)
+	m.syncs.DeletePartialMatch(labels)
+	m.duration.DeletePartialMatch(labels)
+}
@@ -0,0 +1,70 @@
+package cognitive
+
+import (
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sharding"
+)
+
+// AtomReader reads the atoms tenants see at a consistency level. Strong
+// reads see every completed write, for reasoning that acts on the result;
+// eventual and bounded reads may be served by shard replicas, sparing the
+// primaries the scans of dashboards that tolerate stale atoms.
+type AtomReader struct {
+	engine *CognitiveEngine
+	shards sharding.Reader
+}
+
+// Reader returns a reader of atoms at the given consistency, which must be
+// valid
+func (ce *CognitiveEngine) Reader(c sharding.Consistency) AtomReader {
+	return AtomReader{engine: ce, shards: ce.shardManager.Reader(c)}
+}
+
+// QueryAtoms queries atoms for a tenant, including its mounted shared spaces
+func (r AtomReader) QueryAtoms(tenantID string, filter func(atomspace.Atom) bool) []atomspace.Atom {
+	atoms := r.shards.QueryAtoms(tenantID, filter)
+	for _, sharedID := range r.engine.mountedTenantIDs(tenantID) {
+		atoms = append(atoms, r.shards.QueryAtoms(sharedID, filter)...)
+	}
+	return atoms
+}
+
+// FindAtoms answers a structured query over the atoms a tenant sees, like
+// CognitiveEngine.FindAtoms
+func (r AtomReader) FindAtoms(tenantID string, query atomspace.Query) ([]atomspace.Atom, []sharding.ShardPlan) {
+	atoms, plans := r.shards.FindAtoms(tenantID, query)
+	for _, sharedID := range r.engine.mountedTenantIDs(tenantID) {
+		shared, sharedPlans := r.shards.FindAtoms(sharedID, query)
+		atoms = append(atoms, shared...)
+		plans = append(plans, sharedPlans...)
+	}
+	if query.Limit > 0 && len(atoms) > query.Limit {
+		atoms = atoms[:query.Limit]
+	}
+	return atoms, plans
+}
+
+// Incoming returns the links referring to an atom among those a tenant
+// sees, including its mounted shared spaces
+func (r AtomReader) Incoming(tenantID, atomID string) []atomspace.Atom {
+	links := r.shards.Incoming(tenantID, atomID)
+	for _, sharedID := range r.engine.mountedTenantIDs(tenantID) {
+		links = append(links, r.shards.Incoming(sharedID, atomID)...)
+	}
+	return links
+}
+
+// SearchAtoms performs a prefix, substring or fuzzy search over atom names
+func (r AtomReader) SearchAtoms(tenantID, query string, mode atomspace.SearchMode, limit int) []atomspace.SearchResult {
+	return r.shards.SearchAtoms(tenantID, query, mode, limit)
+}
+
+// RunSavedQuery runs a tenant's saved query, like FindAtoms
+func (r AtomReader) RunSavedQuery(tenantID, name string) ([]atomspace.Atom, []sharding.ShardPlan, error) {
+	q, err := r.engine.savedQueries.Get(tenantID, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	atoms, plans := r.FindAtoms(tenantID, q.Query)
+	return atoms, plans, nil
+}
@@ -0,0 +1,109 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/cost"
+)
+
+// SetCostRates replaces a tenant's static cost table
+func (ce *CognitiveEngine) SetCostRates(tenantID string, rates []cost.Rate) error {
+	for i := range rates {
+		if rates[i].Source == "" {
+			rates[i].Source = "static"
+		}
+	}
+	return ce.costModel.SetRates(tenantID, rates)
+}
+
+// SyncCostRates replaces a tenant's rates with those of a billing export.
+// The token may be a secret reference.
+func (ce *CognitiveEngine) SyncCostRates(ctx context.Context, tenantID, url, token string) (int, error) {
+	if url == "" {
+		return 0, fmt.Errorf("billing export url is required")
+	}
+	if sb := ce.sandbox.ForTenant(tenantID); sb.Enabled() {
+		return ce.costModel.Sync(ctx, tenantID, sb.CostSource(url))
+	}
+	token, err := ce.secrets.Resolve(ctx, tenantID, token)
+	if err != nil {
+		return 0, err
+	}
+	return ce.costModel.Sync(ctx, tenantID, cost.NewBillingSource(url, token, 30*time.Second))
+}
+
+// GetCostRates returns a tenant's rates
+func (ce *CognitiveEngine) GetCostRates(tenantID string) []cost.Rate {
+	return ce.costModel.Rates(tenantID)
+}
+
+// EnableCostTracking registers a cost agent for a tenant, or updates the
+// configuration of the existing one
+func (ce *CognitiveEngine) EnableCostTracking(tenantID string, config agents.CostConfig) *agents.CostAgent {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if agent, exists := ce.costAgents[tenantID]; exists {
+		agent.SetConfig(config)
+		return agent
+	}
+
+	agent := agents.NewCostAgent(
+		fmt.Sprintf("cost-%s", tenantID),
+		"CostAgent",
+		tenantID,
+		&tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID},
+		ce.costModel,
+		config,
+	)
+	ce.costAgents[tenantID] = agent
+	ce.agentScheduler.RegisterAgent(agent)
+	return agent
+}
+
+// DisableCostTracking unregisters a tenant's cost agent. Cost annotations
+// already written are kept.
+func (ce *CognitiveEngine) DisableCostTracking(tenantID string) error {
+	ce.mu.Lock()
+	agent, exists := ce.costAgents[tenantID]
+	delete(ce.costAgents, tenantID)
+	ce.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("cost tracking not enabled for tenant %s", tenantID)
+	}
+	ce.agentScheduler.UnregisterAgent(agent.GetID())
+	return nil
+}
+
+// AnalyzeCosts annotates a tenant's resources with their cost immediately,
+// enabling cost tracking with the default configuration if needed
+func (ce *CognitiveEngine) AnalyzeCosts(ctx context.Context, tenantID string) (*agents.CostReport, error) {
+	ce.mu.RLock()
+	agent, exists := ce.costAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		agent = ce.EnableCostTracking(tenantID, agents.DefaultCostConfig())
+	}
+	return agent.Annotate(ctx)
+}
+
+// GetCostReport returns the report of a tenant's latest cost run
+func (ce *CognitiveEngine) GetCostReport(tenantID string) (*agents.CostReport, error) {
+	ce.mu.RLock()
+	agent, exists := ce.costAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("cost tracking not enabled for tenant %s", tenantID)
+	}
+	report := agent.GetReport()
+	if report == nil {
+		return nil, fmt.Errorf("no cost report for tenant %s yet", tenantID)
+	}
+	return report, nil
+}
@@ -0,0 +1,233 @@
+package cost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HoursPerMonth converts hourly costs to monthly estimates
+const HoursPerMonth = 730
+
+// Rate is the hourly cost of a class of resources, or of one resource
+type Rate struct {
+	Category string  `json:"category,omitempty"` // Concept the resources inherit from, e.g. KubernetesNode
+	Resource string  `json:"resource,omitempty"` // Name of a single resource, overrides its category rate
+	Hourly   float64 `json:"hourly"`
+	Currency string  `json:"currency"`
+	Source   string  `json:"source,omitempty"`
+}
+
+func (r Rate) key() string {
+	if r.Resource != "" {
+		return "resource/" + r.Resource
+	}
+	return "category/" + r.Category
+}
+
+// Validate checks a rate and fills in its default currency
+func (r *Rate) Validate() error {
+	if r.Category == "" && r.Resource == "" {
+		return fmt.Errorf("rate requires a category or a resource")
+	}
+	if r.Hourly < 0 {
+		return fmt.Errorf("hourly cost must not be negative")
+	}
+	if r.Currency == "" {
+		r.Currency = "USD"
+	}
+	return nil
+}
+
+// Source provides rates, e.g. a static table or a cloud billing export
+type Source interface {
+	Name() string
+	Rates(ctx context.Context) ([]Rate, error)
+}
+
+// StaticTable is a fixed list of rates
+type StaticTable struct {
+	rates []Rate
+}
+
+// NewStaticTable creates a source serving the given rates
+func NewStaticTable(rates []Rate) *StaticTable {
+	return &StaticTable{rates: append([]Rate(nil), rates...)}
+}
+
+func (t *StaticTable) Name() string {
+	return "static"
+}
+
+func (t *StaticTable) Rates(ctx context.Context) ([]Rate, error) {
+	return append([]Rate(nil), t.rates...), nil
+}
+
+// BillingSource fetches rates from a billing export endpoint returning
+// {"rates": [...]} in the Rate JSON format
+type BillingSource struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewBillingSource creates a billing source. The token, if set, is sent as
+// a bearer token.
+func NewBillingSource(url, token string, timeout time.Duration) *BillingSource {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &BillingSource{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (b *BillingSource) Name() string {
+	return "billing"
+}
+
+func (b *BillingSource) Rates(ctx context.Context) ([]Rate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("billing export returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Rates []Rate `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding billing export failed: %w", err)
+	}
+	return body.Rates, nil
+}
+
+// Model holds the rates of each tenant
+type Model struct {
+	rates  map[string]map[string]Rate // tenantID -> rate key -> rate
+	synced map[string]time.Time       // tenantID -> last sync
+	mu     sync.RWMutex
+}
+
+// NewModel creates an empty cost model
+func NewModel() *Model {
+	return &Model{
+		rates:  make(map[string]map[string]Rate),
+		synced: make(map[string]time.Time),
+	}
+}
+
+// SetRates replaces a tenant's rates
+func (m *Model) SetRates(tenantID string, rates []Rate) error {
+	table := make(map[string]Rate, len(rates))
+	for _, rate := range rates {
+		if err := rate.Validate(); err != nil {
+			return err
+		}
+		table[rate.key()] = rate
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rates[tenantID] = table
+	m.synced[tenantID] = time.Now()
+	return nil
+}
+
+// Sync replaces a tenant's rates with those of a source
+func (m *Model) Sync(ctx context.Context, tenantID string, source Source) (int, error) {
+	rates, err := source.Rates(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("syncing rates from %s failed: %w", source.Name(), err)
+	}
+	for i := range rates {
+		if rates[i].Source == "" {
+			rates[i].Source = source.Name()
+		}
+	}
+	if err := m.SetRates(tenantID, rates); err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+// Rates returns a tenant's rates, categories first
+func (m *Model) Rates(tenantID string) []Rate {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]Rate, 0, len(m.rates[tenantID]))
+	for _, rate := range m.rates[tenantID] {
+		result = append(result, rate)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].key() < result[j].key() })
+	return result
+}
+
+// Lookup returns the rate of a resource: its own rate if there is one,
+// otherwise the highest rate among its categories
+func (m *Model) Lookup(tenantID, resource string, categories []string) (Rate, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	table := m.rates[tenantID]
+	if rate, ok := table["resource/"+resource]; ok {
+		return rate, true
+	}
+
+	var best Rate
+	found := false
+	for _, category := range categories {
+		if rate, ok := table["category/"+category]; ok && (!found || rate.Hourly > best.Hourly) {
+			best, found = rate, true
+		}
+	}
+	return best, found
+}
+
+// Delete removes a tenant's rates
+func (m *Model) Delete(tenantID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.rates, tenantID)
+	delete(m.synced, tenantID)
+}
+
+// GetStats returns cost model statistics
+func (m *Model) GetStats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rates := 0
+	for _, table := range m.rates {
+		rates += len(table)
+	}
+	return map[string]interface{}{
+		"tenants": len(m.rates),
+		"rates":   rates,
+	}
+}
+
+// FormatAmount renders an amount as the name of its value concept, e.g.
+// "usd:0.096"
+func FormatAmount(amount float64, currency string) string {
+	return fmt.Sprintf("%s:%.4g", strings.ToLower(currency), amount)
+}
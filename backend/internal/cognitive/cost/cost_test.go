@@ -0,0 +1,62 @@
+package cost
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLookupPrefersResourceRates(t *testing.T) {
+	m := NewModel()
+	err := m.SetRates("t1", []Rate{
+		{Category: "KubernetesNode", Hourly: 0.1},
+		{Category: "GPUNode", Hourly: 2.5},
+		{Resource: "node/big", Hourly: 4},
+	})
+	if err != nil {
+		t.Fatalf("SetRates failed: %v", err)
+	}
+
+	if rate, ok := m.Lookup("t1", "node/a", []string{"KubernetesNode"}); !ok || rate.Hourly != 0.1 || rate.Currency != "USD" {
+		t.Errorf("Expected the category rate, got %+v", rate)
+	}
+	if rate, _ := m.Lookup("t1", "node/gpu", []string{"KubernetesNode", "GPUNode"}); rate.Hourly != 2.5 {
+		t.Errorf("Expected the highest category rate, got %+v", rate)
+	}
+	if rate, _ := m.Lookup("t1", "node/big", []string{"KubernetesNode"}); rate.Hourly != 4 {
+		t.Errorf("Expected the resource rate, got %+v", rate)
+	}
+	if _, ok := m.Lookup("t2", "node/a", []string{"KubernetesNode"}); ok {
+		t.Error("Expected rates to be isolated per tenant")
+	}
+
+	if err := m.SetRates("t1", []Rate{{Hourly: 1}}); err == nil {
+		t.Error("Expected a rate without category or resource to be rejected")
+	}
+}
+
+func TestBillingSourceSync(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"rates":[{"category":"Pod","hourly":0.02,"currency":"EUR"}]}`))
+	}))
+	defer server.Close()
+
+	m := NewModel()
+	if _, err := m.Sync(context.Background(), "t1", NewBillingSource(server.URL, "wrong", 0)); err == nil {
+		t.Error("Expected an unauthorized sync to fail")
+	}
+
+	n, err := m.Sync(context.Background(), "t1", NewBillingSource(server.URL, "secret", 0))
+	if err != nil || n != 1 {
+		t.Fatalf("Expected 1 synced rate, got %d (%v)", n, err)
+	}
+	rates := m.Rates("t1")
+	if rates[0].Source != "billing" || rates[0].Currency != "EUR" {
+		t.Errorf("Expected a billing rate in EUR, got %+v", rates[0])
+	}
+}
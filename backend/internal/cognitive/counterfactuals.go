@@ -0,0 +1,24 @@
+package cognitive
+
+import (
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/counterfactuals"
+)
+
+// ListCounterfactuals returns the actions a tenant's policies and thresholds
+// suppressed, the most recent first
+func (ce *CognitiveEngine) ListCounterfactuals(tenantID string, f counterfactuals.Filter) []counterfactuals.Counterfactual {
+	return ce.counterfactuals.List(tenantID, f)
+}
+
+// GetCounterfactual returns a suppressed action of a tenant
+func (ce *CognitiveEngine) GetCounterfactual(tenantID, id string) (counterfactuals.Counterfactual, error) {
+	return ce.counterfactuals.Get(tenantID, id)
+}
+
+// CounterfactualReport summarizes what a tenant's autonomy would have done
+// since a time, per blocker and action class, to tune its thresholds by
+func (ce *CognitiveEngine) CounterfactualReport(tenantID string, since time.Time) counterfactuals.Report {
+	return ce.counterfactuals.Report(tenantID, since)
+}
@@ -0,0 +1,196 @@
+package counterfactuals
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// MaxCounterfactuals is the number of counterfactuals kept per tenant
+const MaxCounterfactuals = 1000
+
+// Blockers of the actions recorded
+const (
+	BlockerDecisionPolicy = "decision_policy" // The belief fell short of the action class's threshold
+	BlockerMaintenance    = "maintenance"     // A maintenance or freeze window held the action
+)
+
+// Counterfactual is an action the engine wanted to take but a policy or
+// threshold suppressed: what it would have done, and why it did not
+type Counterfactual struct {
+	ID         string               `json:"id"`     // Stable for the same source and atom
+	Source     string               `json:"source"` // What wanted to act, e.g. execution
+	Action     string               `json:"action"` // What it would have done, e.g. the schema called
+	Class      string               `json:"class,omitempty"`
+	Arguments  []string             `json:"arguments,omitempty"`
+	AtomID     string               `json:"atom_id,omitempty"` // The atom that asked for the action, e.g. the call
+	Blocker    string               `json:"blocker"`
+	Reason     string               `json:"reason"`
+	TruthValue atomspace.TruthValue `json:"truth_value"` // The belief behind the action when last suppressed
+	Count      int                  `json:"count"`       // Times suppressed, once per change of belief
+	FirstAt    time.Time            `json:"first_at"`
+	LastAt     time.Time            `json:"last_at"`
+	ReleasedAt time.Time            `json:"released_at,omitempty"` // When the action was finally taken
+}
+
+// ID returns the ID of the counterfactual of a source about an atom
+func ID(source, atomID string) string {
+	sum := sha256.Sum256([]byte(source + "/" + atomID))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Released reports whether the suppressed action was taken since
+func (c *Counterfactual) Released() bool {
+	return !c.ReleasedAt.IsZero()
+}
+
+func (c *Counterfactual) clone() Counterfactual {
+	cf := *c
+	cf.Arguments = append([]string(nil), c.Arguments...)
+	return cf
+}
+
+// Filter selects counterfactuals; empty fields match all
+type Filter struct {
+	Since   time.Time
+	Source  string
+	Blocker string
+	Class   string
+}
+
+func (f *Filter) matches(c *Counterfactual) bool {
+	return !c.LastAt.Before(f.Since) &&
+		(f.Source == "" || c.Source == f.Source) &&
+		(f.Blocker == "" || c.Blocker == f.Blocker) &&
+		(f.Class == "" || c.Class == f.Class)
+}
+
+// Store keeps each tenant's most recent counterfactuals
+type Store struct {
+	counterfactuals map[string]map[string]*Counterfactual // tenantID -> ID -> counterfactual
+	mu              sync.RWMutex
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{counterfactuals: make(map[string]map[string]*Counterfactual)}
+}
+
+// Record adds a suppressed action, or counts it again if the same source
+// already recorded it for the atom, and returns it. An action suppressed
+// again after it was released starts over.
+func (s *Store) Record(tenantID string, c Counterfactual) Counterfactual {
+	now := time.Now()
+	if c.ID == "" {
+		c.ID = ID(c.Source, c.AtomID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenant := s.counterfactuals[tenantID]
+	if tenant == nil {
+		tenant = make(map[string]*Counterfactual)
+		s.counterfactuals[tenantID] = tenant
+	}
+	stored := c.clone()
+	stored.Count, stored.FirstAt, stored.LastAt, stored.ReleasedAt = 1, now, now, time.Time{}
+	if previous, exists := tenant[c.ID]; exists && !previous.Released() {
+		stored.Count, stored.FirstAt = previous.Count+1, previous.FirstAt
+	}
+	tenant[c.ID] = &stored
+
+	if len(tenant) > MaxCounterfactuals {
+		oldest := ""
+		for id, cf := range tenant {
+			if oldest == "" || cf.LastAt.Before(tenant[oldest].LastAt) {
+				oldest = id
+			}
+		}
+		delete(tenant, oldest)
+	}
+	return stored.clone()
+}
+
+// Release marks the action of a counterfactual as taken. It returns false
+// if there is no such counterfactual or it was already released.
+func (s *Store) Release(tenantID, id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, exists := s.counterfactuals[tenantID][id]
+	if !exists || c.Released() {
+		return false
+	}
+	c.ReleasedAt = time.Now()
+	return true
+}
+
+// List returns a tenant's counterfactuals matching a filter, the most
+// recent first
+func (s *Store) List(tenantID string, f Filter) []Counterfactual {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Counterfactual, 0)
+	for _, c := range s.counterfactuals[tenantID] {
+		if f.matches(c) {
+			result = append(result, c.clone())
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].LastAt.Equal(result[j].LastAt) {
+			return result[i].LastAt.After(result[j].LastAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Get returns a counterfactual of a tenant
+func (s *Store) Get(tenantID, id string) (Counterfactual, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, exists := s.counterfactuals[tenantID][id]
+	if !exists {
+		return Counterfactual{}, fmt.Errorf("counterfactual %s not found", id)
+	}
+	return c.clone(), nil
+}
+
+// Purge removes a tenant's counterfactuals and returns how many there were
+func (s *Store) Purge(tenantID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := len(s.counterfactuals[tenantID])
+	delete(s.counterfactuals, tenantID)
+	return removed
+}
+
+// GetStats returns store statistics
+func (s *Store) GetStats() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total, released := 0, 0
+	for _, tenant := range s.counterfactuals {
+		for _, c := range tenant {
+			total++
+			if c.Released() {
+				released++
+			}
+		}
+	}
+	return map[string]interface{}{
+		"tenants":         len(s.counterfactuals),
+		"counterfactuals": total,
+		"released":        released,
+	}
+}
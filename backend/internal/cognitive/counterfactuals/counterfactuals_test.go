@@ -0,0 +1,95 @@
+package counterfactuals
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func held(action, class, atomID string, confidence float64) Counterfactual {
+	return Counterfactual{
+		Source:     "execution",
+		Action:     action,
+		Class:      class,
+		AtomID:     atomID,
+		Blocker:    BlockerDecisionPolicy,
+		Reason:     "not confident enough",
+		TruthValue: atomspace.TruthValue{Strength: 0.9, Confidence: confidence},
+	}
+}
+
+func TestRecordAndRelease(t *testing.T) {
+	s := NewStore()
+	first := s.Record("t1", held("delete_volume", "delete", "call-1", 0.4))
+	if first.ID != ID("execution", "call-1") || first.Count != 1 {
+		t.Fatalf("Expected a new counterfactual, got %+v", first)
+	}
+	again := s.Record("t1", held("delete_volume", "delete", "call-1", 0.6))
+	if again.Count != 2 || !again.FirstAt.Equal(first.FirstAt) || again.TruthValue.Confidence != 0.6 {
+		t.Errorf("Expected the counterfactual counted again with its new belief, got %+v", again)
+	}
+
+	if !s.Release("t1", first.ID) {
+		t.Fatal("Expected the counterfactual released")
+	}
+	if s.Release("t1", first.ID) {
+		t.Error("Expected a released counterfactual not released again")
+	}
+	if c, _ := s.Get("t1", first.ID); !c.Released() {
+		t.Errorf("Expected the release kept, got %+v", c)
+	}
+
+	// Suppressed again after its release, the action starts over
+	if c := s.Record("t1", held("delete_volume", "delete", "call-1", 0.5)); c.Count != 1 || c.Released() {
+		t.Errorf("Expected the counterfactual to start over, got %+v", c)
+	}
+	if removed := s.Purge("t1"); removed != 1 {
+		t.Errorf("Expected one counterfactual purged, got %d", removed)
+	}
+}
+
+func TestList(t *testing.T) {
+	s := NewStore()
+	s.Record("t1", held("delete_volume", "delete", "call-1", 0.4))
+	s.Record("t1", held("restart_pod", "restart", "call-2", 0.5))
+	s.Record("t2", held("delete_volume", "delete", "call-3", 0.4))
+
+	if list := s.List("t1", Filter{}); len(list) != 2 {
+		t.Errorf("Expected the tenant's counterfactuals, got %+v", list)
+	}
+	if list := s.List("t1", Filter{Class: "restart"}); len(list) != 1 || list[0].Action != "restart_pod" {
+		t.Errorf("Expected the restart, got %+v", list)
+	}
+	if list := s.List("t1", Filter{Since: time.Now().Add(time.Hour)}); len(list) != 0 {
+		t.Errorf("Expected nothing in the future, got %+v", list)
+	}
+}
+
+func TestReport(t *testing.T) {
+	s := NewStore()
+	s.Record("t1", held("delete_volume", "delete", "call-1", 0.3))
+	s.Record("t1", held("delete_volume", "delete", "call-2", 0.75))
+	s.Record("t1", held("delete_volume", "delete", "call-2", 0.85))
+	s.Record("t1", held("drain_node", "delete", "call-3", 0.5))
+	s.Record("t1", held("restart_pod", "restart", "call-4", 0.6))
+	s.Release("t1", ID("execution", "call-2"))
+
+	report := s.Report("t1", time.Time{})
+	if report.Total != 4 || report.Released != 1 || len(report.Groups) != 2 {
+		t.Fatalf("Expected 4 counterfactuals in 2 groups, got %+v", report)
+	}
+	deletes := report.Groups[0]
+	if deletes.Class != "delete" || deletes.Count != 3 || deletes.Suppressions != 4 || deletes.Released != 1 {
+		t.Errorf("Expected the deletions first, got %+v", deletes)
+	}
+	if deletes.Actions["delete_volume"] != 2 || deletes.Actions["drain_node"] != 1 {
+		t.Errorf("Expected the deletions by action, got %v", deletes.Actions)
+	}
+	if deletes.Confidence.Min != 0.3 || deletes.Confidence.Max != 0.85 {
+		t.Errorf("Expected the confidence range of the last beliefs, got %+v", deletes.Confidence)
+	}
+	if deletes.ConfidenceAtLeast[0] != 3 || deletes.ConfidenceAtLeast[5] != 2 || deletes.ConfidenceAtLeast[8] != 1 {
+		t.Errorf("Expected how many lower thresholds would let through, got %v", deletes.ConfidenceAtLeast)
+	}
+}
@@ -0,0 +1,90 @@
+package counterfactuals
+
+import (
+	"sort"
+	"time"
+)
+
+// Distribution summarizes the beliefs behind suppressed actions
+type Distribution struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	Max  float64 `json:"max"`
+}
+
+// Group is what a blocker suppressed of an action class
+type Group struct {
+	Blocker      string         `json:"blocker"`
+	Class        string         `json:"class,omitempty"`
+	Count        int            `json:"count"`        // Actions suppressed
+	Suppressions int            `json:"suppressions"` // Times they were, once per change of belief
+	Released     int            `json:"released"`     // Actions taken since
+	Actions      map[string]int `json:"actions"`      // Action -> actions suppressed
+	Strength     Distribution   `json:"strength"`
+	Confidence   Distribution   `json:"confidence"`
+	// Actions whose confidence was at least 0.0, 0.1, ... 0.9: how many a
+	// lower minimum confidence would have let through
+	ConfidenceAtLeast [10]int `json:"confidence_at_least"`
+}
+
+// Report summarizes what a tenant's autonomy would have done without the
+// policies and thresholds that held it back, to tune them by
+type Report struct {
+	TenantID    string    `json:"tenant_id"`
+	Since       time.Time `json:"since"`
+	Total       int       `json:"total"`
+	Released    int       `json:"released"`
+	Groups      []Group   `json:"groups"` // By blocker and class, the most suppressed first
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// Report summarizes a tenant's counterfactuals suppressed since a time
+func (s *Store) Report(tenantID string, since time.Time) Report {
+	report := Report{TenantID: tenantID, Since: since, Groups: make([]Group, 0), GeneratedAt: time.Now()}
+
+	groups := make(map[[2]string]*Group)
+	for _, c := range s.List(tenantID, Filter{Since: since}) {
+		key := [2]string{c.Blocker, c.Class}
+		g := groups[key]
+		if g == nil {
+			g = &Group{Blocker: c.Blocker, Class: c.Class, Actions: make(map[string]int)}
+			g.Strength.Min, g.Confidence.Min = 1, 1
+			groups[key] = g
+		}
+		tv := c.TruthValue
+		g.Count++
+		g.Suppressions += c.Count
+		g.Actions[c.Action]++
+		g.Strength.Min, g.Strength.Max = min(g.Strength.Min, tv.Strength), max(g.Strength.Max, tv.Strength)
+		g.Strength.Mean += tv.Strength
+		g.Confidence.Min, g.Confidence.Max = min(g.Confidence.Min, tv.Confidence), max(g.Confidence.Max, tv.Confidence)
+		g.Confidence.Mean += tv.Confidence
+		for i := range g.ConfidenceAtLeast {
+			if tv.Confidence >= float64(i)/10 {
+				g.ConfidenceAtLeast[i]++
+			}
+		}
+		if c.Released() {
+			g.Released++
+			report.Released++
+		}
+		report.Total++
+	}
+
+	for _, g := range groups {
+		g.Strength.Mean /= float64(g.Count)
+		g.Confidence.Mean /= float64(g.Count)
+		report.Groups = append(report.Groups, *g)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Blocker != b.Blocker {
+			return a.Blocker < b.Blocker
+		}
+		return a.Class < b.Class
+	})
+	return report
+}
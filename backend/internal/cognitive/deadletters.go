@@ -0,0 +1,57 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/dlq"
+)
+
+// GetDeadLetters returns a tenant's failed pipeline executions and agent runs
+func (ce *CognitiveEngine) GetDeadLetters(tenantID string) []dlq.Entry {
+	return ce.deadLetters.List(tenantID)
+}
+
+// GetDeadLetter returns a single dead-letter entry
+func (ce *CognitiveEngine) GetDeadLetter(entryID, tenantID string) (dlq.Entry, error) {
+	return ce.deadLetters.Get(entryID, tenantID)
+}
+
+// DeleteDeadLetter discards a dead-letter entry without replaying it
+func (ce *CognitiveEngine) DeleteDeadLetter(entryID, tenantID string) error {
+	_, err := ce.deadLetters.Remove(entryID, tenantID)
+	return err
+}
+
+// PurgeDeadLetters discards all of a tenant's dead-letter entries
+func (ce *CognitiveEngine) PurgeDeadLetters(tenantID string) int {
+	return ce.deadLetters.Purge(tenantID)
+}
+
+// RetryDeadLetter replays a failed pipeline execution with its original
+// input, or reruns a failed agent. The entry is removed; a failing replay is
+// dead-lettered again with its attempt count incremented.
+func (ce *CognitiveEngine) RetryDeadLetter(ctx context.Context, entryID, tenantID string) (interface{}, error) {
+	entry, err := ce.deadLetters.Remove(entryID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch entry.Kind {
+	case dlq.KindPipeline:
+		return ce.executePipeline(ctx, entry.PipelineID, entry.Input, entry.Attempts)
+	case dlq.KindAgent:
+		if err := ce.agentScheduler.RunAgent(ctx, entry.AgentID); err != nil {
+			ce.deadLetters.Add(dlq.Entry{
+				Kind:     dlq.KindAgent,
+				TenantID: entry.TenantID,
+				AgentID:  entry.AgentID,
+				Error:    ce.secrets.Scrub(err.Error()),
+				Attempts: entry.Attempts + 1,
+			})
+			return nil, err
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown dead letter kind: %s", entry.Kind)
+}
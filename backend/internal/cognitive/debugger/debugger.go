@@ -0,0 +1,434 @@
+package debugger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// Config bounds what is recorded of agent runs
+type Config struct {
+	MaxRecordings int // Recordings kept per agent
+	FocusSize     int // Atoms of the attentional focus captured before a run
+	MaxChanges    int // Changed atoms listed per recording
+	MaxDecisions  int // Decisions listed per recording
+}
+
+// DefaultConfig keeps the last 50 runs of each agent, with a focus of 20
+// atoms and up to 200 changes and decisions each
+func DefaultConfig() Config {
+	return Config{
+		MaxRecordings: 50,
+		FocusSize:     20,
+		MaxChanges:    200,
+		MaxDecisions:  200,
+	}
+}
+
+// FocusAtom is an atom of the attentional focus, the atoms with the
+// highest short-term importance
+type FocusAtom struct {
+	AtomID     string             `json:"atom_id"`
+	Name       string             `json:"name"`
+	Type       atomspace.AtomType `json:"type"`
+	STI        int16              `json:"sti"`
+	Strength   float64            `json:"strength"`
+	Confidence float64            `json:"confidence"`
+}
+
+// Decision is a choice an agent reported during a run, such as a runbook
+// step it skipped and why
+type Decision struct {
+	Kind    string                 `json:"kind"`
+	Subject string                 `json:"subject,omitempty"`
+	Reason  string                 `json:"reason,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+	At      time.Time              `json:"at"`
+}
+
+// Values are the truth and attention values of an atom
+type Values struct {
+	Strength   float64 `json:"strength"`
+	Confidence float64 `json:"confidence"`
+	STI        int16   `json:"sti"`
+	LTI        int16   `json:"lti"`
+}
+
+// Change kinds
+const (
+	ChangeAdded     = "added"
+	ChangeRemoved   = "removed"
+	ChangeTruth     = "truth"
+	ChangeAttention = "attention"
+)
+
+// Change is an atom that differs after a run. Agents of the same tenant
+// running at the same time may have made it.
+type Change struct {
+	AtomID string             `json:"atom_id"`
+	Name   string             `json:"name"`
+	Type   atomspace.AtomType `json:"type"`
+	Kind   string             `json:"kind"`
+	Before *Values            `json:"before,omitempty"`
+	After  *Values            `json:"after,omitempty"`
+}
+
+// Recording is what an agent saw, decided and changed during one run
+type Recording struct {
+	Sequence         int64         `json:"sequence"` // Per agent, starting at 1
+	AgentID          string        `json:"agent_id"`
+	TenantID         string        `json:"tenant_id"`
+	Step             bool          `json:"step"` // Run on demand rather than by the scheduler
+	StartedAt        time.Time     `json:"started_at"`
+	Duration         time.Duration `json:"duration_ns"`
+	Error            string        `json:"error,omitempty"`
+	Focus            []FocusAtom   `json:"focus"`
+	Decisions        []Decision    `json:"decisions"`
+	Changes          []Change      `json:"changes"`
+	DroppedDecisions int           `json:"dropped_decisions,omitempty"`
+	DroppedChanges   int           `json:"dropped_changes,omitempty"`
+}
+
+// Trace collects the decisions of a run recorded in debug mode
+type Trace struct {
+	decisions []Decision
+	max       int
+	dropped   int
+	mu        sync.Mutex
+}
+
+type traceKey struct{}
+
+// WithTrace returns a context collecting the decisions reported with it
+func WithTrace(ctx context.Context, trace *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// Decide reports a decision of the run of ctx. It does nothing unless the
+// run is recorded, so agents may report decisions unconditionally.
+func Decide(ctx context.Context, kind, subject, reason string, details map[string]interface{}) {
+	trace, _ := ctx.Value(traceKey{}).(*Trace)
+	if trace == nil {
+		return
+	}
+	trace.mu.Lock()
+	defer trace.mu.Unlock()
+	if trace.max > 0 && len(trace.decisions) >= trace.max {
+		trace.dropped++
+		return
+	}
+	trace.decisions = append(trace.decisions, Decision{Kind: kind, Subject: subject, Reason: reason, Details: details, At: time.Now()})
+}
+
+// Recording returns the decisions collected so far and how many were
+// dropped beyond the limit
+func (t *Trace) Recording() ([]Decision, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Decision{}, t.decisions...), t.dropped
+}
+
+// Step asks for a run to be recorded whether or not its agent is in debug
+// mode, and receives the recording
+type Step struct {
+	recording *Recording
+	mu        sync.Mutex
+}
+
+// Set hands the recording of the run to the step
+func (s *Step) Set(rec Recording) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recording = &rec
+}
+
+// Recording returns the recording of the run, once it finished
+func (s *Step) Recording() (Recording, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.recording == nil {
+		return Recording{}, false
+	}
+	return *s.recording, true
+}
+
+type stepKey struct{}
+
+// WithStep returns a context asking for its run to be recorded into step
+func WithStep(ctx context.Context, step *Step) context.Context {
+	return context.WithValue(ctx, stepKey{}, step)
+}
+
+// StepFrom returns the step a run's context asks to record, or nil
+func StepFrom(ctx context.Context) *Step {
+	step, _ := ctx.Value(stepKey{}).(*Step)
+	return step
+}
+
+// snapshotAtom is the state of an atom captured before or after a run
+type snapshotAtom struct {
+	name   string
+	typ    atomspace.AtomType
+	values Values
+}
+
+// Snapshot is the state of a tenant's atoms at one time
+type Snapshot struct {
+	atoms map[string]snapshotAtom
+	focus []FocusAtom
+}
+
+// Capture records the values of atoms and the focusSize of them with the
+// highest short-term importance
+func Capture(atoms []atomspace.Atom, focusSize int) Snapshot {
+	s := Snapshot{atoms: make(map[string]snapshotAtom, len(atoms))}
+	focus := make([]FocusAtom, 0, len(atoms))
+	for _, atom := range atoms {
+		tv := atom.GetTruthValue()
+		av := atom.GetAttentionValue()
+		s.atoms[atom.GetID()] = snapshotAtom{
+			name:   atom.GetName(),
+			typ:    atom.GetType(),
+			values: Values{Strength: tv.Strength, Confidence: tv.Confidence, STI: av.STI, LTI: av.LTI},
+		}
+		focus = append(focus, FocusAtom{
+			AtomID:     atom.GetID(),
+			Name:       atom.GetName(),
+			Type:       atom.GetType(),
+			STI:        av.STI,
+			Strength:   tv.Strength,
+			Confidence: tv.Confidence,
+		})
+	}
+	sort.Slice(focus, func(i, j int) bool {
+		if focus[i].STI != focus[j].STI {
+			return focus[i].STI > focus[j].STI
+		}
+		return focus[i].AtomID < focus[j].AtomID
+	})
+	if len(focus) > focusSize {
+		focus = focus[:focusSize]
+	}
+	s.focus = focus
+	return s
+}
+
+// Focus returns the attentional focus of a snapshot
+func (s Snapshot) Focus() []FocusAtom {
+	return s.focus
+}
+
+// Diff lists the atoms added, removed or whose values differ from before
+// to after, sorted by atom ID, and how many beyond max were left out
+func Diff(before, after Snapshot, max int) ([]Change, int) {
+	changes := make([]Change, 0)
+	for id, a := range after.atoms {
+		current := a.values
+		b, existed := before.atoms[id]
+		previous := b.values
+		switch {
+		case !existed:
+			changes = append(changes, Change{AtomID: id, Name: a.name, Type: a.typ, Kind: ChangeAdded, After: &current})
+		case previous.Strength != current.Strength || previous.Confidence != current.Confidence:
+			changes = append(changes, Change{AtomID: id, Name: a.name, Type: a.typ, Kind: ChangeTruth, Before: &previous, After: &current})
+		case previous.STI != current.STI || previous.LTI != current.LTI:
+			changes = append(changes, Change{AtomID: id, Name: a.name, Type: a.typ, Kind: ChangeAttention, Before: &previous, After: &current})
+		}
+	}
+	for id, b := range before.atoms {
+		if _, exists := after.atoms[id]; !exists {
+			previous := b.values
+			changes = append(changes, Change{AtomID: id, Name: b.name, Type: b.typ, Kind: ChangeRemoved, Before: &previous})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].AtomID < changes[j].AtomID })
+	if max > 0 && len(changes) > max {
+		return changes[:max], len(changes) - max
+	}
+	return changes, 0
+}
+
+// agentRecordings are the debug mode and recordings of one agent
+type agentRecordings struct {
+	tenantID   string
+	enabled    bool
+	sequence   int64
+	recordings []Recording
+}
+
+// Recorder keeps which agents are in debug mode and the recordings of
+// their runs
+type Recorder struct {
+	config Config
+	agents map[string]*agentRecordings // agentID -> recordings
+	mu     sync.RWMutex
+}
+
+// NewRecorder creates a recorder with no agent in debug mode
+func NewRecorder(config Config) *Recorder {
+	defaults := DefaultConfig()
+	if config.MaxRecordings <= 0 {
+		config.MaxRecordings = defaults.MaxRecordings
+	}
+	if config.FocusSize <= 0 {
+		config.FocusSize = defaults.FocusSize
+	}
+	if config.MaxChanges <= 0 {
+		config.MaxChanges = defaults.MaxChanges
+	}
+	if config.MaxDecisions <= 0 {
+		config.MaxDecisions = defaults.MaxDecisions
+	}
+	return &Recorder{config: config, agents: make(map[string]*agentRecordings)}
+}
+
+// Config returns the recorder's limits
+func (r *Recorder) Config() Config {
+	return r.config
+}
+
+// SetEnabled puts an agent in or out of debug mode. Recordings are kept
+// when it leaves.
+func (r *Recorder) SetEnabled(tenantID, agentID string, enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, exists := r.agents[agentID]
+	if !exists {
+		if !enabled {
+			return
+		}
+		a = &agentRecordings{tenantID: tenantID}
+		r.agents[agentID] = a
+	}
+	a.enabled = enabled
+}
+
+// Enabled reports whether an agent is in debug mode
+func (r *Recorder) Enabled(agentID string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, exists := r.agents[agentID]
+	return exists && a.enabled
+}
+
+// Begin starts recording a run, returning the context the run reports its
+// decisions with
+func (r *Recorder) Begin(ctx context.Context) (context.Context, *Trace) {
+	trace := &Trace{max: r.config.MaxDecisions}
+	return WithTrace(ctx, trace), trace
+}
+
+// Finish completes the recording of a run from the snapshots taken around
+// it and keeps it, returning the recording
+func (r *Recorder) Finish(tenantID, agentID string, step bool, trace *Trace, before, after Snapshot, startedAt time.Time, err error) Recording {
+	rec := Recording{
+		AgentID:   agentID,
+		TenantID:  tenantID,
+		Step:      step,
+		StartedAt: startedAt,
+		Duration:  time.Since(startedAt),
+		Focus:     before.Focus(),
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	rec.Decisions, rec.DroppedDecisions = trace.Recording()
+	rec.Changes, rec.DroppedChanges = Diff(before, after, r.config.MaxChanges)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, exists := r.agents[agentID]
+	if !exists {
+		a = &agentRecordings{tenantID: tenantID}
+		r.agents[agentID] = a
+	}
+	a.sequence++
+	rec.Sequence = a.sequence
+	a.recordings = append(a.recordings, rec)
+	if over := len(a.recordings) - r.config.MaxRecordings; over > 0 {
+		a.recordings = append([]Recording(nil), a.recordings[over:]...)
+	}
+	return rec
+}
+
+// Recordings returns an agent's recordings, oldest first
+func (r *Recorder) Recordings(agentID string) []Recording {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, exists := r.agents[agentID]
+	if !exists {
+		return []Recording{}
+	}
+	return append([]Recording{}, a.recordings...)
+}
+
+// Get returns one recording of an agent by sequence
+func (r *Recorder) Get(agentID string, sequence int64) (Recording, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if a, exists := r.agents[agentID]; exists {
+		for _, rec := range a.recordings {
+			if rec.Sequence == sequence {
+				return rec, nil
+			}
+		}
+	}
+	return Recording{}, fmt.Errorf("recording %d of agent %s not found", sequence, agentID)
+}
+
+// Clear forgets an agent's recordings, keeping its debug mode, and returns
+// how many were dropped
+func (r *Recorder) Clear(agentID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, exists := r.agents[agentID]
+	if !exists {
+		return 0
+	}
+	cleared := len(a.recordings)
+	a.recordings = nil
+	return cleared
+}
+
+// Forget drops an agent's debug mode and recordings
+func (r *Recorder) Forget(agentID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.agents, agentID)
+}
+
+// Purge drops the debug modes and recordings of a tenant's agents,
+// returning how many recordings were dropped
+func (r *Recorder) Purge(tenantID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	removed := 0
+	for agentID, a := range r.agents {
+		if a.tenantID == tenantID {
+			removed += len(a.recordings)
+			delete(r.agents, agentID)
+		}
+	}
+	return removed
+}
+
+// GetStats returns debugger statistics
+func (r *Recorder) GetStats() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	enabled, recordings := 0, 0
+	for _, a := range r.agents {
+		if a.enabled {
+			enabled++
+		}
+		recordings += len(a.recordings)
+	}
+	return map[string]interface{}{
+		"agents_in_debug": enabled,
+		"recordings":      recordings,
+	}
+}
@@ -0,0 +1,82 @@
+package debugger
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func node(id string, strength float64, sti int16) atomspace.Atom {
+	n := atomspace.NewNode(id, id, "t1", atomspace.ConceptNodeType)
+	n.SetTruthValue(atomspace.TruthValue{Strength: strength, Confidence: 0.9})
+	n.SetAttentionValue(atomspace.AttentionValue{STI: sti})
+	return n
+}
+
+func TestDecideWithoutTrace(t *testing.T) {
+	// Agents report decisions unconditionally; without a trace nothing happens
+	Decide(context.Background(), "inference", "t1", "", nil)
+
+	trace := &Trace{max: 2}
+	ctx := WithTrace(context.Background(), trace)
+	for i := 0; i < 3; i++ {
+		Decide(ctx, "step", fmt.Sprintf("s%d", i), "", nil)
+	}
+	decisions, dropped := trace.Recording()
+	if len(decisions) != 2 || dropped != 1 {
+		t.Errorf("Expected 2 decisions and 1 dropped, got %d and %d", len(decisions), dropped)
+	}
+}
+
+func TestCaptureAndDiff(t *testing.T) {
+	before := Capture([]atomspace.Atom{node("a", 0.5, 10), node("b", 0.5, 30), node("c", 0.5, 20)}, 2)
+	if focus := before.Focus(); len(focus) != 2 || focus[0].AtomID != "b" || focus[1].AtomID != "c" {
+		t.Errorf("Expected a focus of b and c, got %+v", focus)
+	}
+
+	after := Capture([]atomspace.Atom{node("a", 0.7, 10), node("b", 0.5, 5), node("d", 0.5, 0)}, 2)
+	changes, dropped := Diff(before, after, 0)
+	kinds := make(map[string]string)
+	for _, c := range changes {
+		kinds[c.AtomID] = c.Kind
+	}
+	want := map[string]string{"a": ChangeTruth, "b": ChangeAttention, "c": ChangeRemoved, "d": ChangeAdded}
+	if dropped != 0 || fmt.Sprint(kinds) != fmt.Sprint(want) {
+		t.Errorf("Expected changes %v, got %v", want, kinds)
+	}
+
+	if changes, dropped := Diff(before, after, 3); len(changes) != 3 || dropped != 1 {
+		t.Errorf("Expected 3 changes and 1 dropped, got %d and %d", len(changes), dropped)
+	}
+}
+
+func TestRecorderKeepsLastRecordings(t *testing.T) {
+	r := NewRecorder(Config{MaxRecordings: 2})
+	r.SetEnabled("t1", "agent-1", true)
+	if !r.Enabled("agent-1") {
+		t.Fatal("Expected agent-1 in debug mode")
+	}
+
+	for i := 0; i < 3; i++ {
+		_, trace := r.Begin(context.Background())
+		r.Finish("t1", "agent-1", false, trace, Snapshot{}, Snapshot{}, time.Now(), nil)
+	}
+	recordings := r.Recordings("agent-1")
+	if len(recordings) != 2 || recordings[0].Sequence != 2 || recordings[1].Sequence != 3 {
+		t.Errorf("Expected recordings 2 and 3, got %+v", recordings)
+	}
+	if _, err := r.Get("agent-1", 1); err == nil {
+		t.Error("Expected recording 1 to be dropped")
+	}
+
+	r.SetEnabled("t1", "agent-1", false)
+	if r.Enabled("agent-1") || len(r.Recordings("agent-1")) != 2 {
+		t.Error("Expected recordings kept after leaving debug mode")
+	}
+	if removed := r.Purge("t1"); removed != 2 {
+		t.Errorf("Expected 2 recordings purged, got %d", removed)
+	}
+}
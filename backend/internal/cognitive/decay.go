@@ -0,0 +1,132 @@
+package cognitive
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/decay"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/events"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/orgs"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sharding"
+)
+
+// refreshable is implemented by atoms that know when their truth value was
+// refreshed, and whose confidence can decay without refreshing it
+type refreshable interface {
+	GetRefreshedAt() time.Time
+	GetDecayedAt() time.Time
+	DecayTruthValue(tv atomspace.TruthValue, at time.Time)
+	RestoreFreshness(refreshedAt, decayedAt time.Time)
+}
+
+// SetDecayPolicy creates or replaces a tenant's truth-value decay policy.
+// A project's own policy replaces its organization's of the same name.
+func (ce *CognitiveEngine) SetDecayPolicy(tenantID string, p decay.Policy) (decay.Policy, error) {
+	set, err := ce.decayPolicies.Set(tenantID, p)
+	if err != nil {
+		return decay.Policy{}, err
+	}
+	ce.orgs.Own(tenantID, orgs.SettingDecayPolicy, p.Name)
+	return set, nil
+}
+
+// GetDecayPolicy returns a tenant's decay policy
+func (ce *CognitiveEngine) GetDecayPolicy(tenantID, name string) (decay.Policy, error) {
+	return ce.decayPolicies.Get(tenantID, name)
+}
+
+// ListDecayPolicies returns a tenant's decay policies
+func (ce *CognitiveEngine) ListDecayPolicies(tenantID string) []decay.Policy {
+	return ce.decayPolicies.List(tenantID)
+}
+
+// DeleteDecayPolicy removes a tenant's decay policy. Confidence already
+// decayed is kept until the atoms are refreshed.
+func (ce *CognitiveEngine) DeleteDecayPolicy(tenantID, name string) error {
+	if err := ce.decayPolicies.Delete(tenantID, name); err != nil {
+		return err
+	}
+	ce.orgs.Own(tenantID, orgs.SettingDecayPolicy, name)
+	return nil
+}
+
+// GetDecayRun returns the outcome of the last application of a tenant's
+// decay policies
+func (ce *CognitiveEngine) GetDecayRun(tenantID string) (decay.Run, error) {
+	run, ok := ce.decayPolicies.LastRun(tenantID)
+	if !ok {
+		return decay.Run{}, fmt.Errorf("decay policies of tenant %s have not run", tenantID)
+	}
+	return run, nil
+}
+
+// runDecay applies every tenant's decay policies each interval until the
+// engine is closed
+func (ce *CognitiveEngine) runDecay(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ce.done:
+			return
+		case <-ticker.C:
+			for _, tenantID := range ce.decayPolicies.Tenants() {
+				ce.DecayTruthValues(tenantID)
+			}
+		}
+	}
+}
+
+// DecayTruthValues applies a tenant's decay policies now, lowering the
+// confidence of its own atoms that were not refreshed recently. An atom
+// several policies select decays by the first of them, by name. Decayed
+// atoms are updated in place, without admission review, and published as
+// updated.
+func (ce *CognitiveEngine) DecayTruthValues(tenantID string) (decay.Run, error) {
+	ce.mu.RLock()
+	_, initialized := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+	if !initialized {
+		return decay.Run{}, fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+
+	now := time.Now()
+	run := decay.Run{At: now, Policy: make(map[string]int)}
+	primaries := ce.shardManager.Reader(sharding.Consistency{Level: sharding.ConsistencyStrong})
+	for _, policy := range ce.decayPolicies.List(tenantID) {
+		atoms, _ := primaries.FindAtoms(tenantID, policy.Query())
+		for _, atom := range atoms {
+			run.Checked++
+			var previous atomspace.Atom
+			decayed := false
+			ce.shardManager.UpdateAtom(atom.GetID(), tenantID, func(a atomspace.Atom) error {
+				r, ok := a.(refreshable)
+				if !ok {
+					return nil
+				}
+				tv, changed := policy.Apply(a.GetTruthValue(), r.GetRefreshedAt(), r.GetDecayedAt(), now)
+				if changed {
+					previous = a.Clone()
+					r.DecayTruthValue(tv, now)
+					decayed = true
+				}
+				return nil
+			})
+			if !decayed {
+				continue
+			}
+			run.Decayed++
+			run.Policy[policy.Name]++
+			ce.history.Changed(tenantID, atom.GetID(), previous, now)
+			ce.eventBus.Publish(events.Event{
+				Type:     events.AtomUpdated,
+				TenantID: tenantID,
+				AtomID:   atom.GetID(),
+				Atom:     atom,
+			})
+		}
+	}
+	ce.decayPolicies.RecordRun(tenantID, run)
+	return run, nil
+}
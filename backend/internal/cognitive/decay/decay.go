@@ -0,0 +1,229 @@
+package decay
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// Config configures truth-value decay
+type Config struct {
+	Interval time.Duration // How often tenants' policies are applied; 0 only applies them on demand
+}
+
+// DefaultConfig applies policies every minute
+func DefaultConfig() Config {
+	return Config{Interval: time.Minute}
+}
+
+// Policy lowers the confidence of atoms that have not been refreshed, so
+// that old observations stop driving confident conclusions. Confidence is
+// kept for Grace after an atom's truth value was last set, then halves every
+// HalfLife down to MinConfidence. Strength is left as observed. Unlike
+// attention decay, which tracks what is being thought about, it tracks how
+// much what was observed can still be believed.
+type Policy struct {
+	Name          string              `json:"name"`
+	Type          *atomspace.AtomType `json:"type,omitempty"`   // Atoms of this type; any if unset
+	Labels        []string            `json:"labels,omitempty"` // Atoms carrying every label through has_label links
+	HalfLife      time.Duration       `json:"half_life_ns"`
+	Grace         time.Duration       `json:"grace_ns,omitempty"`
+	MinConfidence float64             `json:"min_confidence,omitempty"`
+}
+
+// Validate checks a policy for consistency
+func (p *Policy) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("policy name is required")
+	}
+	if p.HalfLife <= 0 {
+		return fmt.Errorf("policy %s needs a positive half-life", p.Name)
+	}
+	if p.Grace < 0 {
+		return fmt.Errorf("policy %s has a negative grace period", p.Name)
+	}
+	if p.MinConfidence < 0 || p.MinConfidence > 1 {
+		return fmt.Errorf("policy %s minimum confidence must be in [0, 1]", p.Name)
+	}
+	return nil
+}
+
+// Query selects the atoms the policy applies to
+func (p *Policy) Query() atomspace.Query {
+	return atomspace.Query{Type: p.Type, Labels: p.Labels}
+}
+
+// Apply returns the truth value of an atom refreshed at refreshedAt and
+// last decayed at decayedAt, if ever since, decayed up to now. It reports
+// false when the confidence is unchanged: the atom is within its grace
+// period or at the policy's minimum. Decaying in steps compounds to the
+// same confidence as decaying at once.
+func (p *Policy) Apply(tv atomspace.TruthValue, refreshedAt, decayedAt, now time.Time) (atomspace.TruthValue, bool) {
+	from := refreshedAt.Add(p.Grace)
+	if decayedAt.After(from) {
+		from = decayedAt
+	}
+	elapsed := now.Sub(from)
+	if elapsed <= 0 || tv.Confidence <= p.MinConfidence {
+		return tv, false
+	}
+
+	decayed := tv
+	decayed.Confidence = math.Max(tv.Confidence*math.Exp2(-float64(elapsed)/float64(p.HalfLife)), p.MinConfidence)
+	return decayed, decayed.Confidence != tv.Confidence
+}
+
+// Run is the outcome of applying a tenant's policies
+type Run struct {
+	At      time.Time      `json:"at"`
+	Checked int            `json:"checked"`
+	Decayed int            `json:"decayed"`
+	Policy  map[string]int `json:"policy"` // Atoms decayed by each policy
+}
+
+// Registry holds the decay policies of each tenant and their last runs
+type Registry struct {
+	config   Config
+	policies map[string]map[string]Policy // tenantID -> name -> policy
+	runs     map[string]Run               // tenantID -> last run
+	mu       sync.RWMutex
+}
+
+// NewRegistry creates an empty policy registry
+func NewRegistry(config Config) *Registry {
+	if config.Interval < 0 {
+		config.Interval = 0
+	}
+	return &Registry{
+		config:   config,
+		policies: make(map[string]map[string]Policy),
+		runs:     make(map[string]Run),
+	}
+}
+
+// Config returns the registry's configuration
+func (r *Registry) Config() Config {
+	return r.config
+}
+
+// Set creates or replaces a policy
+func (r *Registry) Set(tenantID string, p Policy) (Policy, error) {
+	if err := p.Validate(); err != nil {
+		return Policy{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tenant, exists := r.policies[tenantID]
+	if !exists {
+		tenant = make(map[string]Policy)
+		r.policies[tenantID] = tenant
+	}
+	tenant[p.Name] = p
+	return p, nil
+}
+
+// Get returns a policy
+func (r *Registry) Get(tenantID, name string) (Policy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, exists := r.policies[tenantID][name]
+	if !exists {
+		return Policy{}, fmt.Errorf("decay policy %s not found", name)
+	}
+	return p, nil
+}
+
+// List returns a tenant's policies sorted by name
+func (r *Registry) List(tenantID string) []Policy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]Policy, 0, len(r.policies[tenantID]))
+	for _, p := range r.policies[tenantID] {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Delete removes a policy. Confidence already decayed is kept.
+func (r *Registry) Delete(tenantID, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.policies[tenantID][name]; !exists {
+		return fmt.Errorf("decay policy %s not found", name)
+	}
+	delete(r.policies[tenantID], name)
+	if len(r.policies[tenantID]) == 0 {
+		delete(r.policies, tenantID)
+	}
+	return nil
+}
+
+// Tenants returns the tenants with policies, sorted
+func (r *Registry) Tenants() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tenants := make([]string, 0, len(r.policies))
+	for tenantID := range r.policies {
+		tenants = append(tenants, tenantID)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// RecordRun keeps the outcome of applying a tenant's policies
+func (r *Registry) RecordRun(tenantID string, run Run) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs[tenantID] = run
+}
+
+// LastRun returns the outcome of the last application of a tenant's
+// policies
+func (r *Registry) LastRun(tenantID string) (Run, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	run, ok := r.runs[tenantID]
+	return run, ok
+}
+
+// Purge deletes all policies of a tenant and returns how many were removed
+func (r *Registry) Purge(tenantID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := len(r.policies[tenantID])
+	delete(r.policies, tenantID)
+	delete(r.runs, tenantID)
+	return purged
+}
+
+// GetStats returns registry statistics
+func (r *Registry) GetStats() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	policies, decayed := 0, 0
+	for _, tenant := range r.policies {
+		policies += len(tenant)
+	}
+	for _, run := range r.runs {
+		decayed += run.Decayed
+	}
+	return map[string]interface{}{
+		"interval":           r.config.Interval.String(),
+		"tenants":            len(r.policies),
+		"policies":           policies,
+		"last_decayed_atoms": decayed,
+	}
+}
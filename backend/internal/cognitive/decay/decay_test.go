@@ -0,0 +1,93 @@
+package decay
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func TestApply(t *testing.T) {
+	p := Policy{Name: "nodes", HalfLife: time.Hour, Grace: 30 * time.Minute, MinConfidence: 0.2}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("expected a valid policy: %v", err)
+	}
+
+	refreshed := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tv := atomspace.TruthValue{Strength: 0.9, Confidence: 0.8}
+
+	// Confidence is kept during the grace period
+	if _, changed := p.Apply(tv, refreshed, time.Time{}, refreshed.Add(20*time.Minute)); changed {
+		t.Error("expected no decay within the grace period")
+	}
+
+	// One half-life after the grace period, confidence halves; strength is kept
+	once, changed := p.Apply(tv, refreshed, time.Time{}, refreshed.Add(90*time.Minute))
+	if !changed || math.Abs(once.Confidence-0.4) > 1e-9 || once.Strength != 0.9 {
+		t.Errorf("expected confidence halved, got %+v", once)
+	}
+
+	// Decaying in steps compounds to the same confidence
+	step, _ := p.Apply(tv, refreshed, time.Time{}, refreshed.Add(60*time.Minute))
+	step, _ = p.Apply(step, refreshed, refreshed.Add(60*time.Minute), refreshed.Add(90*time.Minute))
+	if math.Abs(step.Confidence-once.Confidence) > 1e-9 {
+		t.Errorf("expected stepwise decay to match, got %v and %v", step.Confidence, once.Confidence)
+	}
+
+	// Confidence stops at the minimum
+	floor, _ := p.Apply(tv, refreshed, time.Time{}, refreshed.Add(48*time.Hour))
+	if floor.Confidence != 0.2 {
+		t.Errorf("expected the minimum confidence, got %v", floor.Confidence)
+	}
+	if _, changed := p.Apply(floor, refreshed, refreshed.Add(48*time.Hour), refreshed.Add(49*time.Hour)); changed {
+		t.Error("expected no decay below the minimum")
+	}
+
+	for _, invalid := range []Policy{
+		{HalfLife: time.Hour},
+		{Name: "a"},
+		{Name: "a", HalfLife: time.Hour, Grace: -time.Minute},
+		{Name: "a", HalfLife: time.Hour, MinConfidence: 1.5},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("expected %+v rejected", invalid)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry(DefaultConfig())
+	nodeType := atomspace.ConceptNodeType
+	if _, err := r.Set("acme", Policy{Name: "observed", Type: &nodeType, Labels: []string{"observed"}, HalfLife: time.Hour}); err != nil {
+		t.Fatalf("failed to set policy: %v", err)
+	}
+	if _, err := r.Set("acme", Policy{Name: "bad"}); err == nil {
+		t.Error("expected an invalid policy rejected")
+	}
+
+	p, err := r.Get("acme", "observed")
+	if err != nil {
+		t.Fatalf("failed to get policy: %v", err)
+	}
+	if q := p.Query(); q.Type == nil || *q.Type != nodeType || len(q.Labels) != 1 {
+		t.Errorf("expected the policy to select its type and labels, got %+v", q)
+	}
+	if tenants := r.Tenants(); len(tenants) != 1 || tenants[0] != "acme" {
+		t.Errorf("expected acme to have policies, got %v", tenants)
+	}
+
+	r.RecordRun("acme", Run{Decayed: 3})
+	if run, ok := r.LastRun("acme"); !ok || run.Decayed != 3 {
+		t.Errorf("expected the last run kept, got %+v", run)
+	}
+	if purged := r.Purge("acme"); purged != 1 || len(r.List("acme")) != 0 {
+		t.Errorf("expected the tenant's policy purged, got %d", purged)
+	}
+	if _, ok := r.LastRun("acme"); ok {
+		t.Error("expected the tenant's runs purged")
+	}
+	if err := r.Delete("acme", "observed"); err == nil {
+		t.Error("expected deleting a missing policy to fail")
+	}
+}
@@ -0,0 +1,20 @@
+package cognitive
+
+import "github.com/Avik2024/erebus/backend/internal/cognitive/decisions"
+
+// SetDecisionPolicy replaces the thresholds a tenant's schema calls must
+// meet, per action class, before the execution agent runs them
+func (ce *CognitiveEngine) SetDecisionPolicy(tenantID string, p decisions.Policy) (decisions.Policy, error) {
+	return ce.decisionPolicies.Set(tenantID, p)
+}
+
+// GetDecisionPolicy returns a tenant's decision policy, the default one if
+// the tenant has not set its own, and whether it has
+func (ce *CognitiveEngine) GetDecisionPolicy(tenantID string) (decisions.Policy, bool) {
+	return ce.decisionPolicies.Get(tenantID)
+}
+
+// DeleteDecisionPolicy restores a tenant's default decision policy
+func (ce *CognitiveEngine) DeleteDecisionPolicy(tenantID string) error {
+	return ce.decisionPolicies.Delete(tenantID)
+}
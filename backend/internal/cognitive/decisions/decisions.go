@@ -0,0 +1,209 @@
+package decisions
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// Class groups actions by how much harm a mistaken one does
+type Class string
+
+const (
+	ClassNotify  Class = "notify"
+	ClassScale   Class = "scale"
+	ClassRestart Class = "restart"
+	ClassDelete  Class = "delete"
+)
+
+// maxConfidence keeps the evidence count of a truth value finite
+const maxConfidence = 0.9999
+
+// Evidence returns the evidence count n = c/(1-c) a confidence stands for,
+// as in PLN revision
+func Evidence(confidence float64) float64 {
+	if confidence > maxConfidence {
+		confidence = maxConfidence
+	}
+	if confidence <= 0 {
+		return 0
+	}
+	return confidence / (1 - confidence)
+}
+
+// Threshold is the belief an action of a class needs: the truth value of
+// the call must be at least as strong and confident
+type Threshold struct {
+	MinStrength   float64 `json:"min_strength,omitempty"`
+	MinConfidence float64 `json:"min_confidence,omitempty"`
+	MinEvidence   float64 `json:"min_evidence,omitempty"` // Evidence count of the confidence
+}
+
+// Validate checks a threshold
+func (t *Threshold) Validate() error {
+	if t.MinStrength < 0 || t.MinStrength > 1 || t.MinConfidence < 0 || t.MinConfidence > 1 {
+		return fmt.Errorf("min_strength and min_confidence must be between 0 and 1")
+	}
+	if t.MinEvidence < 0 {
+		return fmt.Errorf("min_evidence must not be negative")
+	}
+	return nil
+}
+
+// Check returns why a truth value falls short of the threshold, or "" if
+// it meets it
+func (t *Threshold) Check(tv atomspace.TruthValue) string {
+	switch {
+	case tv.Strength < t.MinStrength:
+		return fmt.Sprintf("strength %.2f below %.2f", tv.Strength, t.MinStrength)
+	case tv.Confidence < t.MinConfidence:
+		return fmt.Sprintf("confidence %.2f below %.2f", tv.Confidence, t.MinConfidence)
+	case Evidence(tv.Confidence) < t.MinEvidence:
+		return fmt.Sprintf("evidence %.1f below %.1f", Evidence(tv.Confidence), t.MinEvidence)
+	}
+	return ""
+}
+
+// Policy maps a tenant's action classes to the belief they need. Schemas
+// are classified by the class they declare unless the policy names
+// another; actions of no class, or of a class without a threshold, are
+// not held back.
+type Policy struct {
+	Thresholds map[Class]Threshold `json:"thresholds"`
+	Schemas    map[string]Class    `json:"schemas,omitempty"` // Schema name -> class, overriding the declared one
+}
+
+// DefaultPolicy asks more of riskier actions: notifications need no
+// particular belief, deletions a near-certain one
+func DefaultPolicy() Policy {
+	return Policy{
+		Thresholds: map[Class]Threshold{
+			ClassNotify:  {},
+			ClassScale:   {MinStrength: 0.5, MinConfidence: 0.5, MinEvidence: 1},
+			ClassRestart: {MinStrength: 0.6, MinConfidence: 0.7, MinEvidence: 2},
+			ClassDelete:  {MinStrength: 0.8, MinConfidence: 0.9, MinEvidence: 9},
+		},
+		Schemas: map[string]Class{},
+	}
+}
+
+// Validate checks a policy
+func (p *Policy) Validate() error {
+	for class, threshold := range p.Thresholds {
+		if class == "" {
+			return fmt.Errorf("threshold class is required")
+		}
+		if err := threshold.Validate(); err != nil {
+			return fmt.Errorf("class %s: %w", class, err)
+		}
+	}
+	for schema, class := range p.Schemas {
+		if schema == "" || class == "" {
+			return fmt.Errorf("schema classes need a schema name and a class")
+		}
+	}
+	return nil
+}
+
+// ClassOf returns the class of a schema declaring a class, possibly none
+func (p *Policy) ClassOf(schema string, declared Class) Class {
+	if class, ok := p.Schemas[schema]; ok {
+		return class
+	}
+	return declared
+}
+
+// Decide returns why an action of a class backed by a truth value is held
+// back, or "" if it may proceed
+func (p *Policy) Decide(class Class, tv atomspace.TruthValue) string {
+	threshold, ok := p.Thresholds[class]
+	if class == "" || !ok {
+		return ""
+	}
+	if reason := threshold.Check(tv); reason != "" {
+		return fmt.Sprintf("%s action needs more belief: %s", class, reason)
+	}
+	return ""
+}
+
+func (p *Policy) clone() Policy {
+	c := Policy{
+		Thresholds: make(map[Class]Threshold, len(p.Thresholds)),
+		Schemas:    make(map[string]Class, len(p.Schemas)),
+	}
+	for class, threshold := range p.Thresholds {
+		c.Thresholds[class] = threshold
+	}
+	for schema, class := range p.Schemas {
+		c.Schemas[schema] = class
+	}
+	return c
+}
+
+// Registry holds each tenant's decision policy. Tenants without one use
+// the default policy.
+type Registry struct {
+	policies map[string]Policy // tenantID -> policy
+	mu       sync.RWMutex
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{policies: make(map[string]Policy)}
+}
+
+// Set replaces a tenant's policy
+func (r *Registry) Set(tenantID string, p Policy) (Policy, error) {
+	if err := p.Validate(); err != nil {
+		return Policy{}, err
+	}
+	stored := p.clone()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policies[tenantID] = stored
+	return stored.clone(), nil
+}
+
+// Get returns a tenant's policy and whether the tenant set it
+func (r *Registry) Get(tenantID string) (Policy, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, exists := r.policies[tenantID]
+	if !exists {
+		return DefaultPolicy(), false
+	}
+	return p.clone(), true
+}
+
+// Delete restores a tenant's default policy
+func (r *Registry) Delete(tenantID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.policies[tenantID]; !exists {
+		return fmt.Errorf("tenant %s has no decision policy", tenantID)
+	}
+	delete(r.policies, tenantID)
+	return nil
+}
+
+// Purge removes a tenant's policy and returns 1 if it had one
+func (r *Registry) Purge(tenantID string) int {
+	if r.Delete(tenantID) != nil {
+		return 0
+	}
+	return 1
+}
+
+// GetStats returns registry statistics
+func (r *Registry) GetStats() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return map[string]interface{}{
+		"tenants": len(r.policies),
+	}
+}
@@ -0,0 +1,80 @@
+package decisions
+
+import (
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func TestEvidence(t *testing.T) {
+	if n := Evidence(0.9); n < 8.99 || n > 9.01 {
+		t.Errorf("Expected a confidence of 0.9 to stand for 9 observations, got %f", n)
+	}
+	if n := Evidence(1); n > 10000 {
+		t.Errorf("Expected the evidence of full confidence bounded, got %f", n)
+	}
+	if n := Evidence(0); n != 0 {
+		t.Errorf("Expected no evidence, got %f", n)
+	}
+}
+
+func TestDecide(t *testing.T) {
+	p := DefaultPolicy()
+
+	weak := atomspace.TruthValue{Strength: 0.9, Confidence: 0.8}
+	if reason := p.Decide(ClassNotify, weak); reason != "" {
+		t.Errorf("Expected notifications to proceed, got %q", reason)
+	}
+	if reason := p.Decide(ClassRestart, weak); reason != "" {
+		t.Errorf("Expected the restart to proceed, got %q", reason)
+	}
+	if reason := p.Decide(ClassDelete, weak); reason == "" {
+		t.Error("Expected the deletion held back")
+	}
+	if reason := p.Decide("", atomspace.TruthValue{}); reason != "" {
+		t.Errorf("Expected unclassified actions to proceed, got %q", reason)
+	}
+
+	// Evidence can be asked for beyond the confidence
+	p.Thresholds[ClassScale] = Threshold{MinEvidence: 5}
+	if reason := p.Decide(ClassScale, weak); reason == "" {
+		t.Error("Expected 4 observations to fall short of 5")
+	}
+}
+
+func TestClassOf(t *testing.T) {
+	p := DefaultPolicy()
+	p.Schemas["drain_node"] = ClassDelete
+
+	if class := p.ClassOf("drain_node", ClassRestart); class != ClassDelete {
+		t.Errorf("Expected the policy's class, got %s", class)
+	}
+	if class := p.ClassOf("restart_pod", ClassRestart); class != ClassRestart {
+		t.Errorf("Expected the declared class, got %s", class)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	if _, custom := r.Get("t1"); custom {
+		t.Error("Expected the default policy")
+	}
+
+	p := Policy{Thresholds: map[Class]Threshold{ClassDelete: {MinConfidence: 0.95}}}
+	if _, err := r.Set("t1", p); err != nil {
+		t.Fatalf("Failed to set policy: %v", err)
+	}
+	p.Thresholds[ClassDelete] = Threshold{}
+	if got, _ := r.Get("t1"); got.Thresholds[ClassDelete].MinConfidence != 0.95 {
+		t.Errorf("Expected the stored policy unaffected by the caller, got %+v", got)
+	}
+	if _, err := r.Set("t1", Policy{Thresholds: map[Class]Threshold{ClassScale: {MinEvidence: -1}}}); err == nil {
+		t.Error("Expected negative evidence rejected")
+	}
+	if removed := r.Purge("t1"); removed != 1 {
+		t.Errorf("Expected the policy purged, got %d", removed)
+	}
+	if err := r.Delete("t1"); err == nil {
+		t.Error("Expected deleting a missing policy to fail")
+	}
+}
@@ -0,0 +1,38 @@
+package cognitive
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/diff"
+)
+
+// DiffAtoms returns how a tenant's atoms changed between two times within
+// the retained history, e.g. to review what a pipeline run changed
+func (ce *CognitiveEngine) DiffAtoms(tenantID string, from, to time.Time) (*diff.Diff, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("from must not be after to")
+	}
+	before, err := ce.QueryAtomsAsOf(tenantID, from, nil)
+	if err != nil {
+		return nil, err
+	}
+	after, err := ce.QueryAtomsAsOf(tenantID, to, nil)
+	if err != nil {
+		return nil, err
+	}
+	return diff.Compare(before, after), nil
+}
+
+// DiffSharedSpace compares a tenant's own atoms with a shared ontology:
+// added atoms are the tenant's alone, removed ones the ontology's alone,
+// and changed ones hold different truth values in the tenant
+func (ce *CognitiveEngine) DiffSharedSpace(tenantID, spaceID string) (*diff.Diff, error) {
+	if err := ce.requireSharedSpace(spaceID); err != nil {
+		return nil, err
+	}
+
+	shared := ce.shardManager.QueryAtoms(SharedTenantID(spaceID), nil)
+	own := ce.shardManager.QueryAtoms(tenantID, nil)
+	return diff.Compare(shared, own), nil
+}
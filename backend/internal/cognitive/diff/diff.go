@@ -0,0 +1,117 @@
+package diff
+
+import (
+	"sort"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// Atom is an atom added or removed between two sets of atoms
+type Atom struct {
+	AtomID     string             `json:"atom_id"`
+	Name       string             `json:"name"`
+	Type       atomspace.AtomType `json:"type"`
+	TruthValue map[string]float64 `json:"truth_value"`
+	Outgoing   []string           `json:"outgoing,omitempty"`
+}
+
+// Change is an atom whose truth value differs between two sets of atoms
+type Change struct {
+	AtomID          string             `json:"atom_id"`
+	Name            string             `json:"name"`
+	Type            atomspace.AtomType `json:"type"`
+	Before          map[string]float64 `json:"before"`
+	After           map[string]float64 `json:"after"`
+	StrengthDelta   float64            `json:"strength_delta"`
+	ConfidenceDelta float64            `json:"confidence_delta"`
+}
+
+// Diff is what changed from one set of atoms to another. Atoms are matched
+// by ID, which derives from their type, name and outgoing set, so the same
+// statement matches across tenants too.
+type Diff struct {
+	Added     []Atom   `json:"added"`
+	Removed   []Atom   `json:"removed"`
+	Changed   []Change `json:"changed"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// Compare returns the atoms added, removed and changed from before to
+// after, each sorted by name
+func Compare(before, after []atomspace.Atom) *Diff {
+	old := make(map[string]atomspace.Atom, len(before))
+	for _, atom := range before {
+		old[atom.GetID()] = atom
+	}
+
+	d := &Diff{Added: []Atom{}, Removed: []Atom{}, Changed: []Change{}}
+	seen := make(map[string]bool, len(after))
+	for _, atom := range after {
+		seen[atom.GetID()] = true
+		previous, existed := old[atom.GetID()]
+		if !existed {
+			d.Added = append(d.Added, newAtom(atom))
+			continue
+		}
+		from, to := previous.GetTruthValue(), atom.GetTruthValue()
+		if from == to {
+			d.Unchanged++
+			continue
+		}
+		d.Changed = append(d.Changed, Change{
+			AtomID:          atom.GetID(),
+			Name:            atom.GetName(),
+			Type:            atom.GetType(),
+			Before:          truthValue(from),
+			After:           truthValue(to),
+			StrengthDelta:   to.Strength - from.Strength,
+			ConfidenceDelta: to.Confidence - from.Confidence,
+		})
+	}
+	for _, atom := range before {
+		if !seen[atom.GetID()] {
+			d.Removed = append(d.Removed, newAtom(atom))
+		}
+	}
+
+	sortAtoms(d.Added)
+	sortAtoms(d.Removed)
+	sort.Slice(d.Changed, func(i, j int) bool {
+		if d.Changed[i].Name != d.Changed[j].Name {
+			return d.Changed[i].Name < d.Changed[j].Name
+		}
+		return d.Changed[i].AtomID < d.Changed[j].AtomID
+	})
+	return d
+}
+
+func newAtom(atom atomspace.Atom) Atom {
+	a := Atom{
+		AtomID:     atom.GetID(),
+		Name:       atom.GetName(),
+		Type:       atom.GetType(),
+		TruthValue: truthValue(atom.GetTruthValue()),
+	}
+	if link, ok := atom.(*atomspace.Link); ok {
+		for _, target := range link.GetOutgoing() {
+			a.Outgoing = append(a.Outgoing, target.GetID())
+		}
+	}
+	return a
+}
+
+func truthValue(tv atomspace.TruthValue) map[string]float64 {
+	return map[string]float64{
+		"strength":   tv.Strength,
+		"confidence": tv.Confidence,
+	}
+}
+
+func sortAtoms(atoms []Atom) {
+	sort.Slice(atoms, func(i, j int) bool {
+		if atoms[i].Name != atoms[j].Name {
+			return atoms[i].Name < atoms[j].Name
+		}
+		return atoms[i].AtomID < atoms[j].AtomID
+	})
+}
@@ -0,0 +1,41 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func concept(name string, strength float64) *atomspace.Node {
+	n := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, "tenant", atomspace.ConceptNodeType)
+	n.SetTruthValue(atomspace.TruthValue{Strength: strength, Confidence: 0.8})
+	return n
+}
+
+func TestCompare(t *testing.T) {
+	db, cache := concept("db", 0.9), concept("cache", 0.5)
+	link := atomspace.NewLink("link", "", "tenant", atomspace.InheritanceLinkType, []atomspace.Atom{cache, db})
+	before := []atomspace.Atom{db, cache, concept("queue", 0.7)}
+	after := []atomspace.Atom{concept("db", 0.6), cache, link}
+
+	d := Compare(before, after)
+	if len(d.Added) != 1 || d.Added[0].AtomID != "link" || len(d.Added[0].Outgoing) != 2 {
+		t.Errorf("Expected the link added, got %+v", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].Name != "queue" {
+		t.Errorf("Expected queue removed, got %+v", d.Removed)
+	}
+	if len(d.Changed) != 1 || d.Changed[0].Name != "db" || d.Changed[0].Before["strength"] != 0.9 {
+		t.Fatalf("Expected db changed, got %+v", d.Changed)
+	}
+	if delta := d.Changed[0].StrengthDelta; delta > -0.29 || delta < -0.31 {
+		t.Errorf("Expected a strength delta of -0.3, got %f", delta)
+	}
+	if d.Unchanged != 1 {
+		t.Errorf("Expected 1 unchanged atom, got %d", d.Unchanged)
+	}
+
+	if d := Compare(after, after); len(d.Added)+len(d.Removed)+len(d.Changed) != 0 || d.Unchanged != 3 {
+		t.Errorf("Expected no differences, got %+v", d)
+	}
+}
@@ -0,0 +1,190 @@
+package dlq
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// Kind identifies what failed
+type Kind string
+
+const (
+	KindPipeline Kind = "pipeline"
+	KindAgent    Kind = "agent"
+)
+
+// Entry is a failed pipeline execution or agent run awaiting replay
+type Entry struct {
+	ID          string    `json:"id"`
+	Kind        Kind      `json:"kind"`
+	TenantID    string    `json:"tenant_id"`
+	PipelineID  string    `json:"pipeline_id,omitempty"`
+	ExecutionID string    `json:"execution_id,omitempty"`
+	AgentID     string    `json:"agent_id,omitempty"`
+	Stage       string    `json:"stage,omitempty"`
+	Error       string    `json:"error"`
+	FailedAt    time.Time `json:"failed_at"`
+	Attempts    int       `json:"attempts"`    // Runs including replays
+	Occurrences int       `json:"occurrences"` // Scheduled failures coalesced into this entry
+
+	// InputAtomIDs references the atoms the failed execution was started
+	// with; Input keeps the value itself for replay
+	InputAtomIDs []string    `json:"input_atom_ids,omitempty"`
+	Input        interface{} `json:"-"`
+}
+
+// Queue holds dead-lettered failures in arrival order. When full, the oldest
+// entry is evicted.
+type Queue struct {
+	entries  map[string]*Entry
+	order    []string
+	capacity int
+	seq      int64
+	evicted  int64
+	mu       sync.RWMutex
+}
+
+// NewQueue creates a dead-letter queue holding up to capacity entries
+func NewQueue(capacity int) *Queue {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &Queue{
+		entries:  make(map[string]*Entry),
+		capacity: capacity,
+	}
+}
+
+// Add records a failure and returns the stored entry. Repeated failures of
+// a scheduled agent are coalesced into its existing entry.
+func (q *Queue) Add(entry Entry) Entry {
+	if atoms, ok := entry.Input.([]atomspace.Atom); ok && entry.InputAtomIDs == nil {
+		entry.InputAtomIDs = make([]string, len(atoms))
+		for i, atom := range atoms {
+			entry.InputAtomIDs[i] = atom.GetID()
+		}
+	}
+	if entry.FailedAt.IsZero() {
+		entry.FailedAt = time.Now()
+	}
+
+	entry.Occurrences = 1
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if entry.Kind == KindAgent {
+		for _, id := range q.order {
+			existing := q.entries[id]
+			if existing.Kind == KindAgent && existing.AgentID == entry.AgentID && existing.TenantID == entry.TenantID {
+				existing.Error = entry.Error
+				existing.FailedAt = entry.FailedAt
+				existing.Occurrences++
+				return *existing
+			}
+		}
+	}
+
+	q.seq++
+	entry.ID = fmt.Sprintf("dlq-%d", q.seq)
+	q.entries[entry.ID] = &entry
+	q.order = append(q.order, entry.ID)
+
+	for len(q.order) > q.capacity {
+		delete(q.entries, q.order[0])
+		q.order = q.order[1:]
+		q.evicted++
+	}
+
+	return entry
+}
+
+// Get returns an entry of a tenant
+func (q *Queue) Get(entryID, tenantID string) (Entry, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	entry, exists := q.entries[entryID]
+	if !exists || entry.TenantID != tenantID {
+		return Entry{}, fmt.Errorf("dead letter %s not found", entryID)
+	}
+	return *entry, nil
+}
+
+// List returns a tenant's entries, oldest first
+func (q *Queue) List(tenantID string) []Entry {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	result := make([]Entry, 0)
+	for _, id := range q.order {
+		if entry := q.entries[id]; entry.TenantID == tenantID {
+			result = append(result, *entry)
+		}
+	}
+	return result
+}
+
+// Remove deletes an entry of a tenant and returns it
+func (q *Queue) Remove(entryID, tenantID string) (Entry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, exists := q.entries[entryID]
+	if !exists || entry.TenantID != tenantID {
+		return Entry{}, fmt.Errorf("dead letter %s not found", entryID)
+	}
+
+	delete(q.entries, entryID)
+	q.removeFromOrder(entryID)
+	return *entry, nil
+}
+
+// Purge deletes all entries of a tenant and returns how many were removed
+func (q *Queue) Purge(tenantID string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	kept := q.order[:0]
+	purged := 0
+	for _, id := range q.order {
+		if q.entries[id].TenantID == tenantID {
+			delete(q.entries, id)
+			purged++
+			continue
+		}
+		kept = append(kept, id)
+	}
+	q.order = kept
+	return purged
+}
+
+func (q *Queue) removeFromOrder(entryID string) {
+	for i, id := range q.order {
+		if id == entryID {
+			q.order = append(q.order[:i], q.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// GetStats returns dead-letter queue statistics
+func (q *Queue) GetStats() map[string]interface{} {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	byKind := make(map[Kind]int)
+	for _, entry := range q.entries {
+		byKind[entry.Kind]++
+	}
+
+	return map[string]interface{}{
+		"entries":  len(q.entries),
+		"capacity": q.capacity,
+		"evicted":  q.evicted,
+		"by_kind":  byKind,
+	}
+}
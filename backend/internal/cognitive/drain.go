@@ -0,0 +1,25 @@
+package cognitive
+
+import (
+	"github.com/Avik2024/erebus/backend/internal/health"
+)
+
+// Drain marks the engine as shutting down. Its readiness check turns
+// unavailable, so load balancers stop routing to it, and the API answers
+// new requests with 503 while those already running complete.
+func (ce *CognitiveEngine) Drain() {
+	ce.draining.Store(true)
+}
+
+// Draining reports whether the engine is shutting down
+func (ce *CognitiveEngine) Draining() bool {
+	return ce.draining.Load()
+}
+
+// CheckDraining reports the engine unavailable once it is draining
+func (ce *CognitiveEngine) CheckDraining() health.CheckResult {
+	if ce.Draining() {
+		return health.CheckResult{Status: health.StatusUnavailable, Details: map[string]interface{}{"draining": true}}
+	}
+	return health.CheckResult{Status: health.StatusOK}
+}
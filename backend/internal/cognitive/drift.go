@@ -0,0 +1,86 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/drift"
+)
+
+// EnableDrift registers a drift agent for a tenant, or updates the
+// configuration of the existing one
+func (ce *CognitiveEngine) EnableDrift(tenantID string, config agents.DriftConfig) *agents.DriftAgent {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if agent, exists := ce.driftAgents[tenantID]; exists {
+		agent.SetConfig(config)
+		return agent
+	}
+
+	agent := agents.NewDriftAgent(
+		fmt.Sprintf("drift-%s", tenantID),
+		"DriftAgent",
+		tenantID,
+		&tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID},
+		config,
+	)
+	ce.driftAgents[tenantID] = agent
+	ce.agentScheduler.RegisterAgent(agent)
+	return agent
+}
+
+// DisableDrift unregisters a tenant's drift agent. Drift links already
+// written are kept.
+func (ce *CognitiveEngine) DisableDrift(tenantID string) error {
+	ce.mu.Lock()
+	agent, exists := ce.driftAgents[tenantID]
+	delete(ce.driftAgents, tenantID)
+	ce.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("drift detection not enabled for tenant %s", tenantID)
+	}
+	ce.agentScheduler.UnregisterAgent(agent.GetID())
+	return nil
+}
+
+// DetectDrift compares a tenant's declared and observed state immediately,
+// enabling the drift agent with the default configuration if needed
+func (ce *CognitiveEngine) DetectDrift(ctx context.Context, tenantID string) ([]drift.Finding, error) {
+	ce.mu.RLock()
+	agent, exists := ce.driftAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		agent = ce.EnableDrift(tenantID, agents.DefaultDriftConfig())
+	}
+	return agent.Detect(ctx)
+}
+
+// GetDrift returns the drift found by the latest run of a tenant's drift
+// agent
+func (ce *CognitiveEngine) GetDrift(tenantID string) ([]drift.Finding, error) {
+	ce.mu.RLock()
+	agent, exists := ce.driftAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("drift detection not enabled for tenant %s", tenantID)
+	}
+	return agent.GetFindings(), nil
+}
+
+// RecordObservations writes observed attributes of a subject for drift
+// detection
+func (ce *CognitiveEngine) RecordObservations(tenantID, subject, category string, attributes map[string]string) error {
+	if subject == "" {
+		return fmt.Errorf("subject is required")
+	}
+	if len(attributes) == 0 {
+		return fmt.Errorf("at least one attribute is required")
+	}
+	space := &tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID}
+	return agents.RecordObservations(space, tenantID, subject, category, attributes)
+}
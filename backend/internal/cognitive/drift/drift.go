@@ -0,0 +1,313 @@
+package drift
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Names of the atoms compared for drift. Connectors of declared state
+// write declares(RESOURCE, attr:KEY=VALUE) and connectors of observed state
+// write observes(RESOURCE, attr:KEY=VALUE).
+const (
+	AttributePrefix   = "attr:"
+	DeclaresPredicate = "declares"
+	ObservesPredicate = "observes"
+)
+
+// Kind classifies a drift finding
+type Kind string
+
+const (
+	KindAttribute Kind = "attribute" // A declared attribute differs from the observed one
+	KindMissing   Kind = "missing"   // A declared resource is not observed
+	KindUnmanaged Kind = "unmanaged" // An observed resource is not declared
+)
+
+// Resource is one side of the comparison: a subject and its attributes
+type Resource struct {
+	Name       string
+	Category   string // Resource type of declared resources, category of observed ones
+	Attributes map[string]string
+}
+
+// Graph holds the declared and observed resources of a tenant
+type Graph struct {
+	Declared map[string]*Resource // name -> resource
+	Observed map[string]*Resource
+}
+
+// NewGraph creates an empty graph
+func NewGraph() *Graph {
+	return &Graph{
+		Declared: make(map[string]*Resource),
+		Observed: make(map[string]*Resource),
+	}
+}
+
+// resource gets or creates an entry of one side
+func resource(side map[string]*Resource, name string) *Resource {
+	r, exists := side[name]
+	if !exists {
+		r = &Resource{Name: name, Attributes: make(map[string]string)}
+		side[name] = r
+	}
+	return r
+}
+
+// Declare records a declared attribute of a resource
+func (g *Graph) Declare(name, key, value string) {
+	resource(g.Declared, name).Attributes[key] = value
+}
+
+// Observe records an observed attribute of a resource
+func (g *Graph) Observe(name, key, value string) {
+	resource(g.Observed, name).Attributes[key] = value
+}
+
+// SetCategory classifies a resource of one side
+func (g *Graph) SetCategory(declared bool, name, category string) {
+	side := g.Observed
+	if declared {
+		side = g.Declared
+	}
+	if r, exists := side[name]; exists && r.Category == "" {
+		r.Category = category
+	}
+}
+
+// Pair is a declared resource matched to the observed resource sharing its
+// identity attribute
+type Pair struct {
+	Declared *Resource
+	Observed *Resource
+}
+
+// Match pairs declared and observed resources by the value of the identity
+// attribute
+func (g *Graph) Match(identity string) []Pair {
+	byID := make(map[string]*Resource)
+	for _, o := range g.Observed {
+		if id := o.Attributes[identity]; id != "" {
+			byID[id] = o
+		}
+	}
+
+	pairs := make([]Pair, 0)
+	for _, d := range g.Declared {
+		if o, ok := byID[d.Attributes[identity]]; ok && d.Attributes[identity] != "" {
+			pairs = append(pairs, Pair{Declared: d, Observed: o})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Declared.Name < pairs[j].Declared.Name })
+	return pairs
+}
+
+// Finding is one difference between declared and observed state
+type Finding struct {
+	Kind          Kind    `json:"kind"`
+	Rule          string  `json:"rule"`
+	Declared      string  `json:"declared,omitempty"`
+	Observed      string  `json:"observed,omitempty"`
+	Attribute     string  `json:"attribute,omitempty"`
+	DeclaredValue string  `json:"declared_value,omitempty"`
+	ObservedValue string  `json:"observed_value,omitempty"`
+	Severity      float64 `json:"severity"` // In [0, 1]
+	LinkID        string  `json:"link_id,omitempty"`
+}
+
+// Key identifies a finding across runs
+func (f *Finding) Key() string {
+	return strings.Join([]string{string(f.Kind), f.Declared, f.Observed, f.Attribute}, "|")
+}
+
+// Config controls drift severity
+type Config struct {
+	Identity          string             `json:"identity"`           // Attribute pairing declared and observed resources
+	AttributeWeights  map[string]float64 `json:"attribute_weights"`  // Severity of a mismatch per attribute
+	DefaultWeight     float64            `json:"default_weight"`     // Severity of mismatches of other attributes
+	MissingSeverity   float64            `json:"missing_severity"`   // Severity of declared resources not observed
+	UnmanagedSeverity float64            `json:"unmanaged_severity"` // Severity of observed resources not declared
+}
+
+// DefaultConfig returns the default drift configuration
+func DefaultConfig() Config {
+	return Config{
+		Identity: "id",
+		AttributeWeights: map[string]float64{
+			"instance_type":     0.7,
+			"machine_type":      0.7,
+			"ami":               0.6,
+			"availability_zone": 0.5,
+			"zone":              0.5,
+		},
+		DefaultWeight:     0.4,
+		MissingSeverity:   0.8,
+		UnmanagedSeverity: 0.3,
+	}
+}
+
+// Rule derives drift findings from a graph
+type Rule interface {
+	Name() string
+	Evaluate(g *Graph, pairs []Pair) []Finding
+}
+
+// DefaultRules returns the attribute, missing and unmanaged rules
+func DefaultRules(config Config) []Rule {
+	return []Rule{
+		&AttributeRule{config: config},
+		&MissingRule{config: config},
+		&UnmanagedRule{config: config},
+	}
+}
+
+// Detect runs rules over a graph and returns the findings sorted by
+// decreasing severity
+func Detect(g *Graph, config Config, rules []Rule) []Finding {
+	pairs := g.Match(config.Identity)
+	findings := make([]Finding, 0)
+	for _, rule := range rules {
+		findings = append(findings, rule.Evaluate(g, pairs)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Severity != findings[j].Severity {
+			return findings[i].Severity > findings[j].Severity
+		}
+		return findings[i].Key() < findings[j].Key()
+	})
+	return findings
+}
+
+// AttributeRule reports attributes that matched resources both declare and
+// observe with different values
+type AttributeRule struct {
+	config Config
+}
+
+func (r *AttributeRule) Name() string {
+	return "attribute-mismatch"
+}
+
+func (r *AttributeRule) Evaluate(g *Graph, pairs []Pair) []Finding {
+	findings := make([]Finding, 0)
+	for _, p := range pairs {
+		keys := make([]string, 0, len(p.Declared.Attributes))
+		for key := range p.Declared.Attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			declared := p.Declared.Attributes[key]
+			observed, ok := p.Observed.Attributes[key]
+			if !ok || observed == declared || key == r.config.Identity {
+				continue
+			}
+			severity, weighted := r.config.AttributeWeights[key]
+			if !weighted {
+				severity = r.config.DefaultWeight
+			}
+			findings = append(findings, Finding{
+				Kind:          KindAttribute,
+				Rule:          r.Name(),
+				Declared:      p.Declared.Name,
+				Observed:      p.Observed.Name,
+				Attribute:     key,
+				DeclaredValue: declared,
+				ObservedValue: observed,
+				Severity:      severity,
+			})
+		}
+	}
+	return findings
+}
+
+// MissingRule reports declared resources without an observed counterpart.
+// Only resource types of which at least one resource was matched count, as
+// other types are not covered by any connector.
+type MissingRule struct {
+	config Config
+}
+
+func (r *MissingRule) Name() string {
+	return "missing-resource"
+}
+
+func (r *MissingRule) Evaluate(g *Graph, pairs []Pair) []Finding {
+	matched := make(map[string]bool)
+	covered := make(map[string]bool)
+	for _, p := range pairs {
+		matched[p.Declared.Name] = true
+		covered[p.Declared.Category] = true
+	}
+
+	findings := make([]Finding, 0)
+	for _, d := range g.Declared {
+		if matched[d.Name] || !covered[d.Category] {
+			continue
+		}
+		findings = append(findings, Finding{
+			Kind:          KindMissing,
+			Rule:          r.Name(),
+			Declared:      d.Name,
+			DeclaredValue: d.Attributes[r.config.Identity],
+			Severity:      r.config.MissingSeverity,
+		})
+	}
+	return findings
+}
+
+// UnmanagedRule reports observed resources with an identity that no
+// declared resource has. Only categories of which at least one resource
+// was matched count, so infrastructure outside Terraform's scope is not
+// reported.
+type UnmanagedRule struct {
+	config Config
+}
+
+func (r *UnmanagedRule) Name() string {
+	return "unmanaged-resource"
+}
+
+func (r *UnmanagedRule) Evaluate(g *Graph, pairs []Pair) []Finding {
+	matched := make(map[string]bool)
+	covered := make(map[string]bool)
+	for _, p := range pairs {
+		matched[p.Observed.Name] = true
+		covered[p.Observed.Category] = true
+	}
+
+	findings := make([]Finding, 0)
+	for _, o := range g.Observed {
+		if matched[o.Name] || !covered[o.Category] || o.Attributes[r.config.Identity] == "" {
+			continue
+		}
+		findings = append(findings, Finding{
+			Kind:          KindUnmanaged,
+			Rule:          r.Name(),
+			Observed:      o.Name,
+			ObservedValue: o.Attributes[r.config.Identity],
+			Severity:      r.config.UnmanagedSeverity,
+		})
+	}
+	return findings
+}
+
+// ParseAttribute splits an attribute concept name "attr:KEY=VALUE"
+func ParseAttribute(name string) (string, string, bool) {
+	if !strings.HasPrefix(name, AttributePrefix) {
+		return "", "", false
+	}
+	kv := strings.TrimPrefix(name, AttributePrefix)
+	i := strings.Index(kv, "=")
+	if i <= 0 {
+		return "", "", false
+	}
+	return kv[:i], kv[i+1:], true
+}
+
+// AttributeName is the concept name of an attribute value
+func AttributeName(key, value string) string {
+	return fmt.Sprintf("%s%s=%s", AttributePrefix, key, value)
+}
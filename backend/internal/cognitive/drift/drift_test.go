@@ -0,0 +1,91 @@
+package drift
+
+import "testing"
+
+func testGraph() *Graph {
+	g := NewGraph()
+	g.Declare("tf:web", "id", "i-1")
+	g.Declare("tf:web", "instance_type", "t3.micro")
+	g.Declare("tf:web", "ami", "ami-1")
+	g.Declare("tf:worker", "id", "i-2")
+	g.Declare("tf:vpc", "id", "vpc-1")
+	g.SetCategory(true, "tf:web", "aws_instance")
+	g.SetCategory(true, "tf:worker", "aws_instance")
+	g.SetCategory(true, "tf:vpc", "aws_vpc")
+
+	g.Observe("node/a", "id", "i-1")
+	g.Observe("node/a", "instance_type", "t3.large")
+	g.Observe("node/a", "ami", "ami-1")
+	g.Observe("node/b", "id", "i-3")
+	g.Observe("node/c", "instance_type", "t3.large")
+	g.SetCategory(false, "node/a", "KubernetesNode")
+	g.SetCategory(false, "node/b", "KubernetesNode")
+	g.SetCategory(false, "node/c", "KubernetesNode")
+	return g
+}
+
+func TestMatch(t *testing.T) {
+	pairs := testGraph().Match("id")
+	if len(pairs) != 1 || pairs[0].Declared.Name != "tf:web" || pairs[0].Observed.Name != "node/a" {
+		t.Fatalf("Expected tf:web to match node/a, got %+v", pairs)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	config := DefaultConfig()
+	findings := Detect(testGraph(), config, DefaultRules(config))
+
+	// tf:vpc is not reported missing, as no VPC is observed at all, and
+	// node/c has no identity to compare
+	if len(findings) != 3 {
+		t.Fatalf("Expected 3 findings, got %+v", findings)
+	}
+	want := []struct {
+		kind     Kind
+		subject  string
+		severity float64
+	}{
+		{KindMissing, "tf:worker", 0.8},
+		{KindAttribute, "tf:web", 0.7},
+		{KindUnmanaged, "node/b", 0.3},
+	}
+	for i, w := range want {
+		f := findings[i]
+		subject := f.Declared
+		if subject == "" {
+			subject = f.Observed
+		}
+		if f.Kind != w.kind || subject != w.subject || f.Severity != w.severity {
+			t.Errorf("Finding %d: expected %s %s %.1f, got %+v", i, w.kind, w.subject, w.severity, f)
+		}
+	}
+	if findings[1].Attribute != "instance_type" || findings[1].DeclaredValue != "t3.micro" || findings[1].ObservedValue != "t3.large" {
+		t.Errorf("Unexpected attribute finding: %+v", findings[1])
+	}
+}
+
+func TestDefaultWeight(t *testing.T) {
+	g := NewGraph()
+	g.Declare("tf:web", "id", "i-1")
+	g.Declare("tf:web", "tenancy", "default")
+	g.Observe("node/a", "id", "i-1")
+	g.Observe("node/a", "tenancy", "dedicated")
+
+	config := DefaultConfig()
+	findings := Detect(g, config, []Rule{&AttributeRule{config: config}})
+	if len(findings) != 1 || findings[0].Severity != config.DefaultWeight {
+		t.Fatalf("Expected one finding with the default weight, got %+v", findings)
+	}
+}
+
+func TestParseAttribute(t *testing.T) {
+	key, value, ok := ParseAttribute(AttributeName("zone", "us-east-1a=b"))
+	if !ok || key != "zone" || value != "us-east-1a=b" {
+		t.Errorf("Unexpected parse: %q %q %v", key, value, ok)
+	}
+	for _, name := range []string{"zone=a", "attr:=a", "attr:zone"} {
+		if _, _, ok := ParseAttribute(name); ok {
+			t.Errorf("Expected %q not to parse", name)
+		}
+	}
+}
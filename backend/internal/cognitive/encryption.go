@@ -0,0 +1,17 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+)
+
+// ShredTenantData destroys a tenant's encryption keys. Every snapshot
+// encrypted for the tenant becomes unreadable, including copies the engine
+// no longer controls, so deleting a tenant does not require finding all of
+// its backups. Snapshots taken afterwards use a new key.
+func (ce *CognitiveEngine) ShredTenantData(ctx context.Context, tenantID string) error {
+	if ce.encryptor == nil {
+		return fmt.Errorf("encryption at rest is not configured")
+	}
+	return ce.encryptor.Shred(ctx, tenantID)
+}
@@ -1,16 +1,74 @@
 package cognitive
 
 import (
+	"bufio"
 	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/admission"
 	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/artifacts"
 	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/attention"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/budget"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/changes"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/connectors"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/cost"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/counterfactuals"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/debugger"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/decay"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/decisions"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/dlq"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/events"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/federation"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/forecast"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/gitops"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/history"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/incidents"
 	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/leader"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/learning"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/linktypes"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/livestats"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/maintenance"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/metering"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/notifications"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/onboarding"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/orgs"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/partition"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/persistence"
 	"github.com/Avik2024/erebus/backend/internal/cognitive/pipeline"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/promotion"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/provenance"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/queries"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/recommendations"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/reports"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/retry"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/runbooks"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sandbox"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/schemas"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/secrets"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sessions"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/shares"
 	"github.com/Avik2024/erebus/backend/internal/cognitive/sharding"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/slo"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/statsview"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/traces"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/trends"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/usage"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/watchdog"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/watches"
+	"github.com/Avik2024/erebus/backend/internal/health"
 )
 
 // CognitiveEngine is the main orchestrator for the OpenCog-inspired cognitive architecture
@@ -19,6 +77,91 @@ type CognitiveEngine struct {
 	inferenceEngines map[string]*inference.InferenceEngine // tenantID -> engine
 	agentScheduler   *agents.AgentScheduler
 	pipelineOrch     *pipeline.PipelineOrchestrator
+	stageRegistry    *pipeline.StageRegistry
+	snapshotCodec    *persistence.Codec
+	encryptor        *persistence.Encryptor // nil if persisted artifacts are not encrypted
+	deadLetters      *dlq.Queue
+	learner          *learning.Learner
+	eventBus         *events.Bus
+	triggerManager   *triggers.Manager
+	sessionManager   *sessions.Manager
+	sharedSpaces     map[string]*SharedSpace // spaceID -> shared ontology space
+	mounts           map[string][]string     // tenantID -> mounted shared space IDs
+	patternMiners    map[string]*agents.PatternMinerAgent // tenantID -> miner
+	clusterAgents    map[string]*agents.ClusteringAgent   // tenantID -> clustering agent
+	forecastAgents   map[string]*agents.ForecastAgent     // tenantID -> forecasting agent
+	timeSeries       *forecast.Store
+	costAgents       map[string]*agents.CostAgent         // tenantID -> cost agent
+	costModel        *cost.Model
+	recommenders     map[string]*agents.RecommendationAgent // tenantID -> recommendation agent
+	resolvers        map[string]*agents.EntityResolutionAgent // tenantID -> entity resolution agent
+	recommendations  *recommendations.Store
+	sloAgents        map[string]*agents.SLOAgent          // tenantID -> SLO agent
+	sloRegistry      *slo.Registry
+	incidents        *incidents.Manager
+	changes          *changes.Manager
+	secrets          *secrets.Manager
+	connectors       *connectors.Manager
+	connectorAgents  map[string]*agents.ConnectorAgent     // tenantID -> connector agent
+	sandbox          *sandbox.Manager
+	debugger         *debugger.Recorder
+	runbookAgents    map[string]*agents.RunbookAgent      // tenantID -> runbook agent
+	runbookRegistry  *runbooks.Registry
+	terraformAgents  map[string]*agents.TerraformAgent    // tenantID -> Terraform agent
+	cmdbAgents       map[string]*agents.CMDBAgent         // tenantID -> CMDB agent
+	driftAgents      map[string]*agents.DriftAgent        // tenantID -> drift agent
+	traceTracker     *traces.Tracker
+	reportAgents     map[string]*agents.ReportAgent       // tenantID -> report agent
+	reportRegistry   *reports.Registry
+	reportSender     *reports.Sender
+	ruleSelection    inference.SelectionConfig            // Default rule selection of new tenants
+	ruleSelections   map[string]inference.SelectionConfig // tenantID -> rule selection
+	pipelined        bool                                 // Whether new tenants overlap inference iterations
+	inferenceLimits  inference.Limits                     // Derivation limits of new tenants
+	admissionHooks   *admission.Registry
+	admission        *admission.Controller
+	provenance       *provenance.Manager
+	acls             *acl.Registry
+	history          *history.Store
+	valueLogs        *valueLogs // Nil unless values are persisted
+	snapshots        *periodicSnapshots // Nil unless tenants are snapshotted periodically
+	draining         atomic.Bool // Set once shutdown begins
+	usageTracker     *usage.Tracker
+	statsHistory     *trends.Store
+	watchdog         *watchdog.Watchdog // Nil unless enabled
+	meter            *metering.Meter
+	atomsMeteredAt   time.Time  // When stored atoms were last sampled for billing
+	meteringMu       sync.Mutex // Serializes samples of stored atoms
+	templates        *onboarding.Registry
+	savedQueries     *queries.Registry
+	decayPolicies    *decay.Registry
+	orgs             *orgs.Registry
+	linkTypes        *linktypes.Registry
+	federation       *federation.Federator
+	schemas          *schemas.Registry
+	executionAgents  map[string]*agents.ExecutionAgent // tenantID -> execution agent
+	decisionPolicies *decisions.Registry
+	attentionParams  *attention.Registry
+	counterfactuals  *counterfactuals.Store
+	liveStats        *livestats.Hub
+	statsCache       *statsview.Cache
+	artifacts        *artifacts.Store
+	notifications    *notifications.Service
+	maintenance      *maintenance.Manager
+	watches          *watches.Manager
+	shares           *shares.Manager
+	bundles          *promotion.Store
+	tracks           *promotion.Tracks
+	gitopsConfig     gitops.Config
+	gitopsStatus     gitops.Status // Outcome of the latest reconciliation
+	gitopsMu         sync.Mutex    // Serializes reconciliations
+	warmUps          []*warmUp     // Components brought up in the background, in start order
+	warmUpMu         sync.Mutex
+	config           Config        // Effective configuration, reported by RuntimeConfig
+	elector          *leader.Elector // Nil unless replicas elect a leader
+	electionDone     chan struct{}   // Closed once the elector released the lock
+	partitioner      *partition.Partitioner // Nil unless agents are spread across replicas
+	partitionDone    chan struct{}          // Closed once this replica left the membership
 	
 	// Configuration
 	numShards     int
@@ -26,6 +169,7 @@ type CognitiveEngine struct {
 	inferenceWorkers int
 	agentWorkers     int
 	pipelineWorkers  int
+	stageRetryPolicy retry.Policy
 	
 	mu sync.RWMutex
 	done chan struct{}
@@ -38,6 +182,52 @@ type Config struct {
 	InferenceWorkers int
 	AgentWorkers     int
 	PipelineWorkers  int
+	PipelineQueue    pipeline.JobQueue // Durable queue of submitted and scheduled pipeline executions, shared by replicas; in memory if nil
+	SessionTTL       time.Duration // Idle lifetime of interactive sessions
+	MaxSessionTTL    time.Duration
+	StageRetryPolicy retry.Policy // Default retry policy of pipeline stages
+	AgentRetryPolicy retry.Policy // Default retry policy of agent runs
+	SupervisorPolicy agents.SupervisorPolicy // Restart and quarantine policy of failing agents
+	AgentMetrics     agents.Metrics          // Receives agent supervision metrics; none are reported if nil
+	Learning         learning.Config         // Feedback-driven agent priorities and rule weights
+	Incidents        incidents.Config        // Alert correlation into incidents
+	Traces           traces.Config           // Service dependencies derived from traces
+	Reports          reports.Config          // Scheduled digests and their delivery
+	RuleSelection    inference.SelectionConfig // Which inference rules fire in each iteration
+	PipelinedInference bool                    // Start inference iterations on partial results
+	InferenceLimits  inference.Limits          // Depth of derivation chains and cycle detection in each inference run
+	KeyProvider      persistence.KeyProvider   // Encrypts persisted artifacts with per-tenant keys if set
+	History          history.Config            // Retention of past atom states for time-travel reads
+	ValueLog         persistence.ValueLogConfig // Batched persistence of truth and attention values if Dir is set
+	HotShards        sharding.HotConfig         // Detection of shards receiving far more operations than others
+	ShardMetrics     sharding.Metrics           // Receives shard heat; none is reported if nil
+	Replicas         sharding.ReplicaConfig     // Read replicas of shards serving queries, if enabled
+	InternSweepInterval time.Duration           // How often interned strings and minted IDs no atom uses are dropped; 0 never
+	IDScheme         atomspace.IDScheme         // How atom IDs are derived, process-wide so engines sharing a process must agree; unchanged if empty, sha256 until set
+	Usage            usage.Config               // Buckets of API usage per tenant, principal and endpoint
+	StatsHistory     trends.Config              // How often engine and tenant statistics are sampled into their history, and how long it is kept
+	StatsCacheTTL    time.Duration              // How long the expensive sections of GetStats are cached; 0 never
+	Artifacts        artifacts.Config           // Where the artifacts stages attach to pipeline executions are kept, their size limits and retention
+	Notifications    notifications.Config       // Timeout of notification deliveries and the PagerDuty endpoint
+	Watchdog         watchdog.Config            // Rules over the stats history that raise alerts on the engine's own health; needs the history
+	Decay            decay.Config               // How often tenants' truth-value decay policies are applied
+	Watches          watches.Config             // How often watched atoms are checked against their thresholds
+	Shares           shares.Config              // Secret signing share links and their lifetimes
+	Federation       federation.Config          // SQL connections and HTTP hosts federated queries may join atoms with
+	Secrets          secrets.Config             // Backends resolving the secret references of connector credentials
+	Connectors       connectors.Config          // Default sync interval of connectors and how failing ones back off
+	ConnectorMetrics connectors.Metrics         // Receives connector states and syncs; none are reported if nil
+	Metering         metering.Config            // How often billable usage is sampled and exported
+	MeteringExporters []metering.Exporter       // Receive billable events; usage is only totalled if none
+	GitOps           gitops.Config              // Manifests the engine converges to, if Dir is set
+	SnapshotDir      string                     // Directory of *.snap snapshots restored at startup, if set
+	SnapshotInterval time.Duration              // How often each tenant's snapshot in SnapshotDir is replaced, pruning the logged values it holds; 0 never
+	StartupIntegrity IntegrityMode              // Whether shard indices are checked, or repaired, once snapshots are restored
+	LeaderLock       leader.Lock                // Elects the replica running agents and billing samples; every replica runs them if nil
+	Leader           leader.Config              // Identity of this replica and how often it renews the leader lock
+	Membership       partition.Membership       // Spreads agents across the live replicas it lists, instead of running them on the leader
+	Partition        partition.Config           // Identity of this replica and how often it heartbeats the membership
+	Debugger         debugger.Config            // How much of agent runs in debug mode is recorded
 }
 
 // DefaultConfig returns a default configuration
@@ -48,6 +238,38 @@ func DefaultConfig() *Config {
 		InferenceWorkers: 16,
 		AgentWorkers:     8,
 		PipelineWorkers:  8,
+		SessionTTL:       15 * time.Minute,
+		MaxSessionTTL:    24 * time.Hour,
+		StageRetryPolicy: retry.DefaultPolicy(),
+		AgentRetryPolicy: retry.DefaultPolicy(),
+		SupervisorPolicy: agents.DefaultSupervisorPolicy(),
+		Learning:         learning.DefaultConfig(),
+		Incidents:        incidents.DefaultConfig(),
+		Traces:           traces.DefaultConfig(),
+		Reports:          reports.DefaultConfig(),
+		RuleSelection:    inference.DefaultSelectionConfig(),
+		InferenceLimits:  inference.DefaultLimits(),
+		History:          history.DefaultConfig(),
+		ValueLog:         persistence.DefaultValueLogConfig(),
+		HotShards:        sharding.DefaultHotConfig(),
+		Replicas:         sharding.DefaultReplicaConfig(),
+		InternSweepInterval: 10 * time.Minute,
+		Usage:            usage.DefaultConfig(),
+		StatsHistory:     trends.DefaultConfig(),
+		Artifacts:        artifacts.DefaultConfig(),
+		Notifications:    notifications.DefaultConfig(),
+		Watchdog:         watchdog.DefaultConfig(),
+		Decay:            decay.DefaultConfig(),
+		Watches:          watches.DefaultConfig(),
+		Shares:           shares.DefaultConfig(),
+		Federation:       federation.DefaultConfig(),
+		Secrets:          secrets.DefaultConfig(),
+		Connectors:       connectors.DefaultConfig(),
+		Metering:         metering.DefaultConfig(),
+		GitOps:           gitops.DefaultConfig(),
+		Leader:           leader.DefaultConfig(),
+		Partition:        partition.DefaultConfig(),
+		Debugger:         debugger.DefaultConfig(),
 	}
 }
 
@@ -56,25 +278,208 @@ func NewCognitiveEngine(cfg *Config) *CognitiveEngine {
 	if cfg == nil {
 		cfg = DefaultConfig()
 	}
+	if cfg.IDScheme != "" {
+		atomspace.SetIDScheme(cfg.IDScheme)
+	}
+	sessionTTL := cfg.SessionTTL
+	if sessionTTL <= 0 {
+		sessionTTL = 15 * time.Minute
+	}
+	ruleSelection := cfg.RuleSelection
+	if ruleSelection.Validate() != nil {
+		ruleSelection = inference.DefaultSelectionConfig()
+	}
+	inferenceLimits := cfg.InferenceLimits
+	if inferenceLimits.Validate() != nil {
+		inferenceLimits = inference.DefaultLimits()
+	}
 	
 	ce := &CognitiveEngine{
 		shardManager:     sharding.NewShardManager(cfg.NumShards, cfg.WorkersPerShard*cfg.NumShards),
 		inferenceEngines: make(map[string]*inference.InferenceEngine),
 		agentScheduler:   agents.NewAgentScheduler(cfg.AgentWorkers),
-		pipelineOrch:     pipeline.NewPipelineOrchestrator(cfg.PipelineWorkers),
+		pipelineOrch:     newPipelineOrchestrator(cfg),
+		stageRegistry:    pipeline.NewDefaultStageRegistry(),
+		snapshotCodec:    persistence.NewCodec(),
+		deadLetters:      dlq.NewQueue(1000),
+		learner:          learning.NewLearner(cfg.Learning),
+		eventBus:         events.NewBus(1000),
+		sessionManager:   sessions.NewManager(sessionTTL, cfg.MaxSessionTTL, 2),
+		sharedSpaces:     make(map[string]*SharedSpace),
+		mounts:           make(map[string][]string),
+		patternMiners:    make(map[string]*agents.PatternMinerAgent),
+		clusterAgents:    make(map[string]*agents.ClusteringAgent),
+		forecastAgents:   make(map[string]*agents.ForecastAgent),
+		timeSeries:       forecast.NewStore(1000),
+		costAgents:       make(map[string]*agents.CostAgent),
+		costModel:        cost.NewModel(),
+		recommenders:     make(map[string]*agents.RecommendationAgent),
+		resolvers:        make(map[string]*agents.EntityResolutionAgent),
+		recommendations:  recommendations.NewStore(),
+		sloAgents:        make(map[string]*agents.SLOAgent),
+		sloRegistry:      slo.NewRegistry(),
+		incidents:        incidents.NewManager(cfg.Incidents),
+		changes:          changes.NewManager(),
+		secrets:          secrets.NewManager(cfg.Secrets),
+		connectors:       connectors.NewManager(cfg.Connectors),
+		connectorAgents:  make(map[string]*agents.ConnectorAgent),
+		sandbox:          sandbox.NewManager(),
+		debugger:         debugger.NewRecorder(cfg.Debugger),
+		runbookAgents:    make(map[string]*agents.RunbookAgent),
+		runbookRegistry:  runbooks.NewRegistry(),
+		terraformAgents:  make(map[string]*agents.TerraformAgent),
+		cmdbAgents:       make(map[string]*agents.CMDBAgent),
+		driftAgents:      make(map[string]*agents.DriftAgent),
+		traceTracker:     traces.NewTracker(cfg.Traces),
+		reportAgents:     make(map[string]*agents.ReportAgent),
+		reportRegistry:   reports.NewRegistry(cfg.Reports),
+		reportSender:     reports.NewSender(cfg.Reports.DeliveryTimeout),
+		ruleSelection:    ruleSelection,
+		ruleSelections:   make(map[string]inference.SelectionConfig),
+		pipelined:        cfg.PipelinedInference,
+		inferenceLimits:  inferenceLimits,
+		admissionHooks:   admission.NewRegistry(),
+		provenance:       provenance.NewManager(),
+		acls:             acl.NewRegistry(),
+		history:          history.NewStore(cfg.History),
+		usageTracker:     usage.NewTracker(cfg.Usage),
+		statsHistory:     trends.NewStore(cfg.StatsHistory),
+		meter:            metering.NewMeter(cfg.Metering),
+		atomsMeteredAt:   time.Now(),
+		templates:        onboarding.NewRegistry(),
+		savedQueries:     queries.NewRegistry(),
+		decayPolicies:    decay.NewRegistry(cfg.Decay),
+		orgs:             orgs.NewRegistry(),
+		linkTypes:        linktypes.NewRegistry(),
+		federation:       federation.New(cfg.Federation),
+		schemas:          schemas.NewRegistry(),
+		executionAgents:  make(map[string]*agents.ExecutionAgent),
+		decisionPolicies: decisions.NewRegistry(),
+		attentionParams:  attention.NewRegistry(),
+		counterfactuals:  counterfactuals.NewStore(),
+		statsCache:       statsview.NewCache(cfg.StatsCacheTTL),
+		artifacts:        artifacts.NewStore(cfg.Artifacts),
+		notifications:    notifications.NewService(cfg.Notifications),
+		maintenance:      maintenance.NewManager(),
+		watches:          watches.NewManager(),
+		shares:           shares.NewManager(cfg.Shares),
+		bundles:          promotion.NewStore(),
+		tracks:           promotion.NewTracks(),
+		gitopsConfig:     cfg.GitOps,
+		config:           *cfg,
 		numShards:        cfg.NumShards,
 		workersPerShard:  cfg.WorkersPerShard,
 		inferenceWorkers: cfg.InferenceWorkers,
 		agentWorkers:     cfg.AgentWorkers,
 		pipelineWorkers:  cfg.PipelineWorkers,
+		stageRetryPolicy: cfg.StageRetryPolicy,
 		done:            make(chan struct{}),
 	}
 	
+	ce.liveStats = livestats.NewHub(ce.collectLiveStats)
+	ce.triggerManager = triggers.NewManager(ce.eventBus, ce)
+	ce.triggerManager.SetPerformers(ce.tenantActions)
+	ce.notifications.SetSecrets(ce.secrets.ForTenant)
+	ce.notifications.SetSenders(func(tenantID string) notifications.Sender {
+		return ce.sandbox.ForTenant(tenantID).Notifications(ce.notifications)
+	})
+	ce.recommendations.OnSubmit(ce.notifyApproval)
+	ce.pipelineOrch.SetHold(ce.holdPipelines)
+	ce.admission = admission.NewController(ce.admissionHooks)
+	if cfg.KeyProvider != nil {
+		ce.encryptor = persistence.NewEncryptor(cfg.KeyProvider)
+		ce.artifacts.SetSealer(ce.encryptor)
+	}
+	ce.pipelineOrch.SetArtifactSink(ce.attachArtifact)
+	if cfg.Artifacts.Retention > 0 {
+		go ce.sweepArtifacts(artifactSweepInterval)
+	}
+	if cfg.ValueLog.Dir != "" {
+		ce.startValueLogs(cfg.ValueLog)
+	}
+	if cfg.SnapshotDir != "" && cfg.SnapshotInterval > 0 {
+		ce.snapshots = &periodicSnapshots{
+			dir:      cfg.SnapshotDir,
+			interval: cfg.SnapshotInterval,
+			tenants:  make(map[string]SnapshotStatus),
+		}
+	}
+	ce.connectors.SetScrubber(ce.secrets.Scrub)
+	if cfg.ConnectorMetrics != nil {
+		ce.connectors.SetMetrics(cfg.ConnectorMetrics)
+	}
+	ce.registerConnectorKinds()
+	ce.shardManager.SetHotConfig(cfg.HotShards)
+	if cfg.ShardMetrics != nil {
+		ce.shardManager.SetMetrics(cfg.ShardMetrics)
+	}
+	if cfg.Replicas.Enabled {
+		ce.startReplication(cfg.Replicas)
+	}
+	if cfg.InternSweepInterval > 0 {
+		go ce.sweepInterned(cfg.InternSweepInterval)
+	}
+	for _, exporter := range cfg.MeteringExporters {
+		ce.meter.AddExporter(exporter)
+	}
+	ce.sessionManager.OnWork(ce.meterInference)
+	ce.pipelineOrch.OnJobFinished(func(pipelineID string, input interface{}, err error) {
+		ce.pipelineFinished(pipelineID, input, 0, err)
+	})
+	go ce.runMetering(ce.meter.Config().Interval)
+	if cfg.StatsHistory.Resolution > 0 {
+		if cfg.Watchdog.Enabled {
+			if wd, err := watchdog.New(cfg.Watchdog, ce.statsHistory); err == nil {
+				ce.watchdog = wd
+			}
+		}
+		go ce.runStatsHistory(cfg.StatsHistory.Resolution)
+	}
+	if cfg.Decay.Interval > 0 {
+		go ce.runDecay(cfg.Decay.Interval)
+	}
+	ce.eventBus.Subscribe("watches", func(e events.Event) bool { return e.Type == events.AtomDeleted }, ce.watchDeletions)
+	if cfg.Watches.Interval > 0 {
+		go ce.runWatches(cfg.Watches.Interval)
+	}
+	if cfg.Membership != nil {
+		ce.startPartitioning(cfg.Membership, cfg.Partition)
+	}
+	if cfg.LeaderLock != nil {
+		ce.startElection(cfg.LeaderLock, cfg.Leader)
+	}
+	ce.startUp(cfg)
+	ce.agentScheduler.SetRetryPolicy(cfg.AgentRetryPolicy)
+	if cfg.SupervisorPolicy.MaxConsecutiveFailures > 0 {
+		ce.agentScheduler.SetSupervisorPolicy(cfg.SupervisorPolicy)
+	}
+	if cfg.AgentMetrics != nil {
+		ce.agentScheduler.SetMetrics(cfg.AgentMetrics)
+	}
+	ce.agentScheduler.OnFailure(func(agent agents.Agent, err error) {
+		ce.deadLetters.Add(dlq.Entry{
+			Kind:     dlq.KindAgent,
+			TenantID: agent.GetTenantID(),
+			AgentID:  agent.GetID(),
+			Error:    ce.secrets.Scrub(err.Error()),
+			Attempts: 1,
+		})
+	})
+	ce.agentScheduler.OnRun(func(agent agents.Agent, err error) {
+		ce.learner.RecordAgentRun(agent.GetTenantID(), agent.GetID())
+	})
+	ce.agentScheduler.WrapRuns(ce.recordRun)
+	ce.agentScheduler.SetPause(ce.agentPaused)
+	
 	return ce
 }
 
 // InitializeTenant initializes cognitive resources for a new tenant
 func (ce *CognitiveEngine) InitializeTenant(tenantID string) error {
+	if IsSharedTenantID(tenantID) {
+		return fmt.Errorf("tenant ID %s is reserved for shared spaces", tenantID)
+	}
+	
 	ce.mu.Lock()
 	defer ce.mu.Unlock()
 	
@@ -85,8 +490,8 @@ func (ce *CognitiveEngine) InitializeTenant(tenantID string) error {
 	
 	// Create a tenant-specific atomspace wrapper that queries across shards
 	tenantAtomSpace := &tenantAtomSpaceWrapper{
-		shardManager: ce.shardManager,
-		tenantID:     tenantID,
+		engine:   ce,
+		tenantID: tenantID,
 	}
 	
 	// Create inference engine for this tenant
@@ -96,6 +501,20 @@ func (ce *CognitiveEngine) InitializeTenant(tenantID string) error {
 	inferenceEngine.AddRule(inference.NewDeductionRule())
 	inferenceEngine.AddRule(inference.NewInductionRule())
 	inferenceEngine.AddRule(inference.NewAbductionRule())
+	inferenceEngine.AddRule(inference.NewGroundedRule())
+	inferenceEngine.AddRule(incidents.NewCorrelationRule(ce.incidents, tenantID))
+	inferenceEngine.AddRule(incidents.NewChangeRule(ce.incidents, tenantID))
+	if selector, err := inference.NewRuleSelector(ce.ruleSelection); err == nil {
+		inferenceEngine.SetSelector(selector)
+		ce.ruleSelections[tenantID] = ce.ruleSelection
+	}
+	inferenceEngine.SetPipelined(ce.pipelined)
+	inferenceEngine.SetLimits(ce.inferenceLimits)
+	inferenceEngine.OnDerived(func(tenantID, rule string, atom atomspace.Atom) {
+		ce.learner.RecordDerivation(tenantID, rule, atom.GetID())
+		ce.incidents.ObserveDerived(tenantID, rule, atom)
+	})
+	inferenceEngine.OnWork(ce.meterInference)
 	
 	ce.inferenceEngines[tenantID] = inferenceEngine
 	
@@ -113,59 +532,217 @@ func (ce *CognitiveEngine) InitializeTenant(tenantID string) error {
 	return nil
 }
 
-// tenantAtomSpaceWrapper wraps the shard manager to provide atomspace interface for a tenant
+// tenantAtomSpaceWrapper exposes the engine as an atomspace interface for a
+// tenant, so agents and inference go through the same write path (events,
+// hooks) as API callers
 type tenantAtomSpaceWrapper struct {
-	shardManager *sharding.ShardManager
-	tenantID     string
+	engine   *CognitiveEngine
+	tenantID string
 }
 
 func (w *tenantAtomSpaceWrapper) AddAtom(atom atomspace.Atom) error {
-	return w.shardManager.AddAtom(atom)
+	return w.engine.ingestAtom(atom)
 }
 
 func (w *tenantAtomSpaceWrapper) GetAtom(atomID, tenantID string) (atomspace.Atom, error) {
-	return w.shardManager.GetAtom(atomID, tenantID)
+	return w.engine.GetAtom(atomID, tenantID)
 }
 
 func (w *tenantAtomSpaceWrapper) QueryAtoms(tenantID string, filter func(atomspace.Atom) bool) []atomspace.Atom {
-	return w.shardManager.QueryAtoms(tenantID, filter)
+	return w.engine.QueryAtoms(tenantID, filter)
 }
 
 func (w *tenantAtomSpaceWrapper) UpdateAtom(atomID, tenantID string, updater func(atomspace.Atom) error) error {
-	return w.shardManager.UpdateAtom(atomID, tenantID, updater)
+	return w.engine.UpdateAtom(atomID, tenantID, updater)
 }
 
 func (w *tenantAtomSpaceWrapper) DeleteAtom(atomID, tenantID string) error {
-	return w.shardManager.DeleteAtom(atomID, tenantID)
+	return w.engine.DeleteAtom(atomID, tenantID)
 }
 
 func (w *tenantAtomSpaceWrapper) GetStats(tenantID string) map[string]interface{} {
-	return w.shardManager.GetTenantStats(tenantID)
+	return w.engine.shardManager.GetTenantStats(tenantID)
+}
+
+func (w *tenantAtomSpaceWrapper) Generation(tenantID string) uint64 {
+	return w.engine.Generation(tenantID)
 }
 
-// AddAtom adds an atom to the cognitive engine
+// ListTenants returns the IDs of the initialized tenants, sorted
+func (ce *CognitiveEngine) ListTenants() []string {
+	ce.mu.RLock()
+	defer ce.mu.RUnlock()
+	
+	tenants := make([]string, 0, len(ce.inferenceEngines))
+	for tenantID := range ce.inferenceEngines {
+		tenants = append(tenants, tenantID)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// AddAtom adds an atom to the cognitive engine. The tenant's admission
+// webhooks review it first and may patch it.
 func (ce *CognitiveEngine) AddAtom(atom atomspace.Atom) error {
-	return ce.shardManager.AddAtom(atom)
+	if err := ce.admission.Admit(context.Background(), admission.OperationCreate, atom, nil); err != nil {
+		return err
+	}
+	
+	if _, shared := ce.findMountedAtom(atom.GetID(), atom.GetTenantID()); shared {
+		return fmt.Errorf("atom with ID %s already exists in a mounted shared space", atom.GetID())
+	}
+	
+	if err := ce.shardManager.AddAtom(atom); err != nil {
+		return err
+	}
+	ce.provenance.Record(provenance.OperationCreate, atom.GetTenantID(), atom.GetID(), atom)
+	ce.history.Created(atom.GetTenantID(), atom.GetID(), time.Now())
+	
+	ce.eventBus.Publish(events.Event{
+		Type:     events.AtomAdded,
+		TenantID: atom.GetTenantID(),
+		AtomID:   atom.GetID(),
+		Atom:     atom,
+	})
+	return nil
 }
 
-// GetAtom retrieves an atom
+// GetAtom retrieves an atom, falling back to the tenant's mounted shared spaces
 func (ce *CognitiveEngine) GetAtom(atomID, tenantID string) (atomspace.Atom, error) {
-	return ce.shardManager.GetAtom(atomID, tenantID)
+	atom, err := ce.shardManager.GetAtom(atomID, tenantID)
+	if err != nil {
+		if shared, ok := ce.findMountedAtom(atomID, tenantID); ok {
+			return shared, nil
+		}
+	}
+	return atom, err
 }
 
-// QueryAtoms queries atoms for a tenant
+// QueryAtoms queries atoms for a tenant, including its mounted shared
+// spaces, eventually consistent
 func (ce *CognitiveEngine) QueryAtoms(tenantID string, filter func(atomspace.Atom) bool) []atomspace.Atom {
-	return ce.shardManager.QueryAtoms(tenantID, filter)
+	return ce.Reader(sharding.Consistency{}).QueryAtoms(tenantID, filter)
+}
+
+// FindAtoms answers a structured query over the atoms a tenant sees, its
+// own and those of its mounted shared spaces, from indices rather than
+// scans where the query allows. It returns the plan each shard used.
+// Reads are eventually consistent.
+func (ce *CognitiveEngine) FindAtoms(tenantID string, query atomspace.Query) ([]atomspace.Atom, []sharding.ShardPlan) {
+	return ce.Reader(sharding.Consistency{}).FindAtoms(tenantID, query)
+}
+
+// Generation identifies the state of the atoms a tenant sees, its own and
+// those of its mounted shared spaces. It changes whenever any of them
+// changes or the mounts do.
+func (ce *CognitiveEngine) Generation(tenantID string) uint64 {
+	generation := ce.shardManager.Generation(tenantID)
+	mounted := ce.mountedTenantIDs(tenantID)
+	if len(mounted) == 0 {
+		return generation
+	}
+	
+	h := fnv.New64a()
+	binary.Write(h, binary.LittleEndian, generation)
+	for _, sharedID := range mounted {
+		h.Write([]byte(sharedID))
+		binary.Write(h, binary.LittleEndian, ce.shardManager.Generation(sharedID))
+	}
+	return h.Sum64()
+}
+
+// SearchAtoms performs a prefix, substring or fuzzy search over atom
+// names, eventually consistent
+func (ce *CognitiveEngine) SearchAtoms(tenantID, query string, mode atomspace.SearchMode, limit int) []atomspace.SearchResult {
+	return ce.Reader(sharding.Consistency{}).SearchAtoms(tenantID, query, mode, limit)
 }
 
-// UpdateAtom updates an atom
+// UpdateAtom updates an atom. If the tenant has admission webhooks for
+// updates, the updater is applied to a copy that the webhooks review, and
+// the admitted truth and attention values are then written. Protected
+// atoms are refused with atomspace.ErrProtected.
 func (ce *CognitiveEngine) UpdateAtom(atomID, tenantID string, updater func(atomspace.Atom) error) error {
-	return ce.shardManager.UpdateAtom(atomID, tenantID, updater)
+	return ce.updateAtom(atomID, tenantID, updater, false)
 }
 
-// DeleteAtom deletes an atom
+// updateAtom updates an atom, and protected atoms too if forced
+func (ce *CognitiveEngine) updateAtom(atomID, tenantID string, updater func(atomspace.Atom) error, force bool) error {
+	if current, err := ce.shardManager.GetAtom(atomID, tenantID); err == nil && len(ce.admissionHooks.Matching(tenantID, admission.OperationUpdate, current.GetType())) > 0 {
+		proposed := current.Clone()
+		if err := updater(proposed); err != nil {
+			return err
+		}
+		if err := ce.admission.Admit(context.Background(), admission.OperationUpdate, proposed, current); err != nil {
+			return err
+		}
+		updater = func(atom atomspace.Atom) error {
+			atom.SetTruthValue(proposed.GetTruthValue())
+			atom.SetAttentionValue(proposed.GetAttentionValue())
+			return nil
+		}
+	}
+	
+	update := ce.shardManager.UpdateAtom
+	if force {
+		update = ce.shardManager.ForceUpdateAtom
+	}
+	var updated, previous atomspace.Atom
+	err := update(atomID, tenantID, func(atom atomspace.Atom) error {
+		updated, previous = atom, atom.Clone()
+		return updater(atom)
+	})
+	if err != nil {
+		if _, shared := ce.findMountedAtom(atomID, tenantID); shared {
+			return fmt.Errorf("atom %s belongs to a read-only shared space", atomID)
+		}
+		return err
+	}
+	ce.provenance.Record(provenance.OperationUpdate, tenantID, atomID, updated)
+	if previous.GetTruthValue() != updated.GetTruthValue() {
+		ce.history.Changed(tenantID, atomID, previous, time.Now())
+	}
+	
+	ce.eventBus.Publish(events.Event{
+		Type:     events.AtomUpdated,
+		TenantID: tenantID,
+		AtomID:   atomID,
+		Atom:     updated,
+	})
+	return nil
+}
+
+// DeleteAtom deletes an atom. Protected atoms are refused with
+// atomspace.ErrProtected.
 func (ce *CognitiveEngine) DeleteAtom(atomID, tenantID string) error {
-	return ce.shardManager.DeleteAtom(atomID, tenantID)
+	return ce.deleteAtom(atomID, tenantID, false)
+}
+
+// deleteAtom deletes an atom, and a protected atom too if forced
+func (ce *CognitiveEngine) deleteAtom(atomID, tenantID string, force bool) error {
+	remove := ce.shardManager.DeleteAtom
+	if force {
+		remove = ce.shardManager.ForceDeleteAtom
+	}
+	atom, _ := ce.shardManager.GetAtom(atomID, tenantID)
+	if err := remove(atomID, tenantID); err != nil {
+		if _, shared := ce.findMountedAtom(atomID, tenantID); shared {
+			return fmt.Errorf("atom %s belongs to a read-only shared space", atomID)
+		}
+		return err
+	}
+	ce.provenance.Record(provenance.OperationDelete, tenantID, atomID, nil)
+	if atom != nil {
+		ce.history.Deleted(tenantID, atomID, atom, time.Now())
+	}
+	ce.acls.Forget(tenantID, acl.KindAtom, atomID)
+	
+	ce.eventBus.Publish(events.Event{
+		Type:     events.AtomDeleted,
+		TenantID: tenantID,
+		AtomID:   atomID,
+		Atom:     atom,
+	})
+	return nil
 }
 
 // RunInference runs inference for a tenant
@@ -181,9 +758,83 @@ func (ce *CognitiveEngine) RunInference(ctx context.Context, tenantID string, ma
 	return inferenceEngine.RunInference(ctx, tenantID, maxIterations)
 }
 
+// SnapshotTenant writes all atoms of a tenant to w in the compressed
+// persistence encoding, encrypted with the tenant's data key if a key
+// provider is configured
+func (ce *CognitiveEngine) SnapshotTenant(tenantID string, w io.Writer) error {
+	atoms := ce.shardManager.QueryAtoms(tenantID, nil)
+	if ce.encryptor == nil {
+		return ce.snapshotCodec.WriteSnapshot(w, atoms)
+	}
+	
+	sealed, err := ce.encryptor.Writer(context.Background(), tenantID, w)
+	if err != nil {
+		return err
+	}
+	if err := ce.snapshotCodec.WriteSnapshot(sealed, atoms); err != nil {
+		return err
+	}
+	return sealed.Close()
+}
+
+// RestoreSnapshot loads atoms from a snapshot stream into the engine. Atoms
+// that already exist are skipped; the number of restored atoms is returned.
+// Encrypted snapshots may only contain atoms of the tenant they were
+// encrypted for.
+func (ce *CognitiveEngine) RestoreSnapshot(r io.Reader) (int, error) {
+	br := bufio.NewReader(r)
+	tenantID := ""
+	if persistence.IsEncrypted(br) {
+		if ce.encryptor == nil {
+			return 0, fmt.Errorf("snapshot is encrypted but no key provider is configured")
+		}
+		opened, sealedFor, err := ce.encryptor.Reader(context.Background(), br)
+		if err != nil {
+			return 0, err
+		}
+		r, tenantID = opened, sealedFor
+	} else {
+		r = br
+	}
+	
+	atoms, err := ce.snapshotCodec.LoadSnapshot(r)
+	if err != nil {
+		return 0, err
+	}
+	for _, atom := range atoms {
+		if tenantID != "" && atom.GetTenantID() != tenantID {
+			return 0, fmt.Errorf("snapshot encrypted for tenant %s contains atoms of tenant %s", tenantID, atom.GetTenantID())
+		}
+	}
+
+	restored := ce.importAtoms(atoms)
+	if ce.valueLogs != nil {
+		ce.valueLogs.replay(atoms)
+	}
+
+	return restored, nil
+}
+
+// CreateSession opens a short-lived working memory over a tenant's space
+func (ce *CognitiveEngine) CreateSession(tenantID string, ttl time.Duration) (*sessions.Session, error) {
+	base := &tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID}
+	return ce.sessionManager.CreateSession(tenantID, base, ttl)
+}
+
+// GetSession retrieves a live session
+func (ce *CognitiveEngine) GetSession(sessionID, tenantID string) (*sessions.Session, error) {
+	return ce.sessionManager.GetSession(sessionID, tenantID)
+}
+
+// CloseSession discards a session
+func (ce *CognitiveEngine) CloseSession(sessionID, tenantID string) error {
+	return ce.sessionManager.CloseSession(sessionID, tenantID)
+}
+
 // CreatePipeline creates a new cognitive pipeline
 func (ce *CognitiveEngine) CreatePipeline(pipelineID, name, tenantID string) (*pipeline.Pipeline, error) {
 	p := pipeline.NewPipeline(pipelineID, name, tenantID)
+	p.SetRetryPolicy(ce.stageRetryPolicy)
 	
 	if err := ce.pipelineOrch.CreatePipeline(p); err != nil {
 		return nil, err
@@ -199,13 +850,159 @@ func (ce *CognitiveEngine) AddPipelineStage(pipelineID string, stage pipeline.Pi
 		return err
 	}
 	
-	p.AddStage(stage)
-	return nil
+	return p.AddStage(stage)
+}
+
+// StageRegistry returns the registry of stage kinds usable in declarative
+// pipelines
+func (ce *CognitiveEngine) StageRegistry() *pipeline.StageRegistry {
+	return ce.stageRegistry
+}
+
+// CreatePipelineFromSpec creates a pipeline whose stages are built from the
+// stage registry
+func (ce *CognitiveEngine) CreatePipelineFromSpec(pipelineID, tenantID string, spec pipeline.PipelineSpec) (*pipeline.Pipeline, error) {
+	p, err := ce.buildPipeline(pipelineID, tenantID, spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := ce.pipelineOrch.CreatePipeline(p); err != nil {
+		return nil, err
+	}
+	
+	return p, nil
+}
+
+// buildPipeline builds a pipeline from a spec without registering it
+func (ce *CognitiveEngine) buildPipeline(pipelineID, tenantID string, spec pipeline.PipelineSpec) (*pipeline.Pipeline, error) {
+	ce.mu.RLock()
+	inferenceEngine := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+	
+	sc := pipeline.StageContext{
+		TenantID:  tenantID,
+		AtomSpace: &tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID},
+		Inference: inferenceEngine,
+		Scheduler: ce.agentScheduler,
+		Series:    ce.timeSeries,
+		Attention: ce.attentionParams.Params,
+	}
+	
+	p := pipeline.NewPipeline(pipelineID, spec.Name, tenantID)
+	p.Spec = &spec
+	p.SetRetryPolicy(ce.stageRetryPolicy)
+	if spec.Retry != nil {
+		p.SetRetryPolicy(spec.Retry.Policy())
+	}
+	if spec.InputType != "" {
+		if err := p.SetInputType(spec.InputType); err != nil {
+			return nil, err
+		}
+	}
+	if spec.MaxConcurrency > 0 {
+		if err := p.SetMaxConcurrency(spec.MaxConcurrency); err != nil {
+			return nil, err
+		}
+	}
+	for i, stageSpec := range spec.Stages {
+		stage, err := ce.stageRegistry.Build(sc, stageSpec)
+		if err != nil {
+			return nil, fmt.Errorf("stage %d: %w", i, err)
+		}
+		if err := p.AddStage(stage); err != nil {
+			return nil, err
+		}
+		if stageSpec.Retry != nil {
+			p.SetStageRetryPolicy(stage.GetName(), stageSpec.Retry.Policy())
+		}
+	}
+	
+	return p, nil
 }
 
 // ExecutePipeline executes a pipeline
 func (ce *CognitiveEngine) ExecutePipeline(ctx context.Context, pipelineID string, input interface{}) (interface{}, error) {
-	return ce.pipelineOrch.ExecutePipeline(ctx, pipelineID, input)
+	return ce.executePipeline(ctx, pipelineID, input, 0)
+}
+
+func (ce *CognitiveEngine) executePipeline(ctx context.Context, pipelineID string, input interface{}, attempts int) (interface{}, error) {
+	output, err := ce.pipelineOrch.ExecutePipeline(ctx, pipelineID, input)
+	ce.pipelineFinished(pipelineID, input, attempts, err)
+	
+	return output, err
+}
+
+// SubmitPipeline queues a pipeline execution without waiting for it
+func (ce *CognitiveEngine) SubmitPipeline(ctx context.Context, pipelineID string, input interface{}) (pipeline.Execution, error) {
+	return ce.pipelineOrch.SubmitPipeline(ctx, pipelineID, input)
+}
+
+// SchedulePipeline queues a pipeline execution to start no earlier than
+// runAt
+func (ce *CognitiveEngine) SchedulePipeline(ctx context.Context, pipelineID string, input interface{}, runAt time.Time) (pipeline.Execution, error) {
+	return ce.pipelineOrch.SchedulePipeline(ctx, pipelineID, input, runAt)
+}
+
+// newPipelineOrchestrator creates the orchestrator of the configured
+// job queue
+func newPipelineOrchestrator(cfg *Config) *pipeline.PipelineOrchestrator {
+	if cfg.PipelineQueue != nil {
+		return pipeline.NewPipelineOrchestratorWithQueue(cfg.PipelineWorkers, cfg.PipelineQueue)
+	}
+	return pipeline.NewPipelineOrchestrator(cfg.PipelineWorkers)
+}
+
+// pipelineFinished emits the completion event of a pipeline execution and
+// dead-letters failed stages
+func (ce *CognitiveEngine) pipelineFinished(pipelineID string, input interface{}, attempts int, err error) {
+	event := events.Event{Type: events.PipelineCompleted, PipelineID: pipelineID}
+	if p, getErr := ce.pipelineOrch.GetPipeline(pipelineID); getErr == nil {
+		event.TenantID = p.TenantID
+	}
+	if err != nil {
+		event.Type = events.PipelineFailed
+		event.Error = err.Error()
+		
+		var execErr *pipeline.ExecutionError
+		if errors.As(err, &execErr) {
+			ce.deadLetters.Add(dlq.Entry{
+				Kind:        dlq.KindPipeline,
+				TenantID:    event.TenantID,
+				PipelineID:  pipelineID,
+				ExecutionID: execErr.ExecutionID,
+				Stage:       execErr.Stage,
+				Error:       ce.secrets.Scrub(err.Error()),
+				Attempts:    attempts + 1,
+				Input:       input,
+			})
+		}
+	}
+	ce.eventBus.Publish(event)
+	
+	// Pipeline outcomes reward the agents and rules active since the last
+	// feedback
+	outcome := learning.OutcomePipelineSucceeded
+	if err != nil {
+		outcome = learning.OutcomePipelineFailed
+	}
+	if event.TenantID != "" {
+		ce.Feedback(learning.Feedback{TenantID: event.TenantID, Outcome: outcome})
+		if err == nil {
+			ce.meter.Add(event.TenantID, metering.PipelineExecutions, 1)
+		} else {
+			ce.notifyPipelineFailed(event.TenantID, pipelineID, err)
+		}
+	}
+}
+
+// Events returns the engine event bus
+func (ce *CognitiveEngine) Events() *events.Bus {
+	return ce.eventBus
+}
+
+// Triggers returns the trigger manager
+func (ce *CognitiveEngine) Triggers() *triggers.Manager {
+	return ce.triggerManager
 }
 
 // GetPipeline retrieves a pipeline
@@ -213,14 +1010,98 @@ func (ce *CognitiveEngine) GetPipeline(pipelineID string) (*pipeline.Pipeline, e
 	return ce.pipelineOrch.GetPipeline(pipelineID)
 }
 
+// GetAgentHealth returns the supervision records of a tenant's agents
+func (ce *CognitiveEngine) GetAgentHealth(tenantID string) []agents.AgentHealth {
+	return ce.agentScheduler.GetHealth(tenantID)
+}
+
+// ReleaseAgent lifts the quarantine or restart backoff of an agent
+func (ce *CognitiveEngine) ReleaseAgent(agentID string) error {
+	return ce.agentScheduler.ReleaseAgent(agentID)
+}
+
+// SetTenantBudget limits the resources a tenant's agents may consume. A
+// project's own budget replaces its organization's.
+func (ce *CognitiveEngine) SetTenantBudget(tenantID string, b budget.Budget) error {
+	if err := ce.agentScheduler.Budgets().SetBudget(tenantID, b); err != nil {
+		return err
+	}
+	ce.orgs.Own(tenantID, orgs.SettingBudget, "")
+	return nil
+}
+
+// RemoveTenantBudget lifts a tenant's agent budget
+func (ce *CognitiveEngine) RemoveTenantBudget(tenantID string) {
+	ce.agentScheduler.Budgets().RemoveBudget(tenantID)
+	ce.orgs.Own(tenantID, orgs.SettingBudget, "")
+}
+
+// GetTenantUsage returns a tenant's agent resource usage in the current
+// budget window
+func (ce *CognitiveEngine) GetTenantUsage(tenantID string) map[string]interface{} {
+	return ce.agentScheduler.Budgets().GetUsage(tenantID)
+}
+
+// GetAgentDaemons returns the status of a tenant's daemon agents
+func (ce *CognitiveEngine) GetAgentDaemons(tenantID string) []agents.DaemonStatus {
+	return ce.agentScheduler.GetDaemons(tenantID)
+}
+
+// SetAgentRunPlan orders a tenant's agents into run groups
+func (ce *CognitiveEngine) SetAgentRunPlan(tenantID string, plan agents.RunPlan) error {
+	for _, group := range plan.Groups {
+		for _, agentID := range group.Agents {
+			if agent, exists := ce.agentScheduler.GetAgent(agentID); exists && agent.GetTenantID() != tenantID {
+				return fmt.Errorf("agent %s does not belong to tenant %s", agentID, tenantID)
+			}
+		}
+	}
+	return ce.agentScheduler.SetRunPlan(tenantID, plan)
+}
+
+// RemoveAgentRunPlan restores priority ordering of a tenant's agents
+func (ce *CognitiveEngine) RemoveAgentRunPlan(tenantID string) {
+	ce.agentScheduler.RemoveRunPlan(tenantID)
+}
+
+// GetAgentRunPlan returns a tenant's run groups in execution order
+func (ce *CognitiveEngine) GetAgentRunPlan(tenantID string) (agents.RunPlan, bool) {
+	return ce.agentScheduler.GetRunPlan(tenantID)
+}
+
+// CheckAgents is a readiness check reporting degraded while any agent is
+// in restart backoff or quarantined
+func (ce *CognitiveEngine) CheckAgents() health.CheckResult {
+	status := health.StatusOK
+	var unhealthy []agents.AgentHealth
+	for _, h := range ce.agentScheduler.GetHealth("") {
+		if h.State == agents.HealthBackoff || h.State == agents.HealthQuarantined {
+			status = health.StatusDegraded
+			unhealthy = append(unhealthy, h)
+		}
+	}
+	
+	return health.CheckResult{
+		Status: status,
+		Details: map[string]interface{}{
+			"total_agents":     len(ce.agentScheduler.GetAllAgents()),
+			"unhealthy_agents": unhealthy,
+		},
+	}
+}
+
 // RegisterAgent registers a cognitive agent
 func (ce *CognitiveEngine) RegisterAgent(agent agents.Agent) {
+	if aa, ok := agent.(*agents.AttentionAgent); ok {
+		aa.SetParams(ce.attentionParams.Params)
+	}
 	ce.agentScheduler.RegisterAgent(agent)
 }
 
 // UnregisterAgent unregisters an agent
 func (ce *CognitiveEngine) UnregisterAgent(agentID string) {
 	ce.agentScheduler.UnregisterAgent(agentID)
+	ce.debugger.Forget(agentID)
 }
 
 // GetAgent retrieves an agent
@@ -228,6 +1109,11 @@ func (ce *CognitiveEngine) GetAgent(agentID string) (agents.Agent, bool) {
 	return ce.agentScheduler.GetAgent(agentID)
 }
 
+// GetPipelinesByTenant retrieves all pipelines of a tenant
+func (ce *CognitiveEngine) GetPipelinesByTenant(tenantID string) []*pipeline.Pipeline {
+	return ce.pipelineOrch.GetPipelinesByTenant(tenantID)
+}
+
 // GetAgentsByTenant retrieves all agents for a tenant
 func (ce *CognitiveEngine) GetAgentsByTenant(tenantID string) []agents.Agent {
 	return ce.agentScheduler.GetAgentsByTenant(tenantID)
@@ -235,29 +1121,142 @@ func (ce *CognitiveEngine) GetAgentsByTenant(tenantID string) []agents.Agent {
 
 // GetStats returns comprehensive statistics about the cognitive engine
 func (ce *CognitiveEngine) GetStats(tenantID string) map[string]interface{} {
-	stats := map[string]interface{}{
-		"config": map[string]interface{}{
-			"num_shards":        ce.numShards,
-			"workers_per_shard": ce.workersPerShard,
-			"inference_workers": ce.inferenceWorkers,
-			"agent_workers":     ce.agentWorkers,
-			"pipeline_workers":  ce.pipelineWorkers,
+	return ce.SelectStats(tenantID, statsview.Selection{})
+}
+
+// SelectStats returns the statistics of GetStats a selection includes.
+// Sections left out are not computed, and the expensive aggregations of
+// shards, agents, pipelines and the tenant's atoms are cached for
+// StatsCacheTTL.
+func (ce *CognitiveEngine) SelectStats(tenantID string, sel statsview.Selection) map[string]interface{} {
+	sections := map[string]func() interface{}{
+		"config": func() interface{} {
+			return map[string]interface{}{
+				"num_shards":        ce.numShards,
+				"workers_per_shard": ce.workersPerShard,
+				"inference_workers": ce.inferenceWorkers,
+				"agent_workers":     ce.agentWorkers,
+				"pipeline_workers":  ce.pipelineWorkers,
+			}
 		},
-		"sharding": ce.shardManager.GetShardStats(),
-		"agents":   ce.agentScheduler.GetStats(),
-		"pipelines": ce.pipelineOrch.GetStats(),
+		"sharding":          ce.cachedStats("sharding", "", func() interface{} { return ce.shardManager.GetShardStats() }),
+		"agents":            ce.cachedStats("agents", "", func() interface{} { return ce.agentScheduler.GetStats() }),
+		"pipelines":         ce.cachedStats("pipelines", "", func() interface{} { return ce.pipelineOrch.GetStats() }),
+		"events":            func() interface{} { return ce.eventBus.GetStats() },
+		"triggers":          func() interface{} { return ce.triggerManager.GetStats() },
+		"sessions":          func() interface{} { return ce.sessionManager.GetStats() },
+		"dead_letters":      func() interface{} { return ce.deadLetters.GetStats() },
+		"learning":          func() interface{} { return ce.learner.GetStats() },
+		"time_series":       func() interface{} { return ce.timeSeries.GetStats() },
+		"cost":              func() interface{} { return ce.costModel.GetStats() },
+		"recommendations":   func() interface{} { return ce.recommendations.GetStats() },
+		"slos":              func() interface{} { return ce.sloRegistry.GetStats() },
+		"incidents":         func() interface{} { return ce.incidents.GetStats() },
+		"changes":           func() interface{} { return ce.changes.GetStats() },
+		"secrets":           func() interface{} { return ce.secrets.GetStats() },
+		"connectors":        func() interface{} { return ce.connectors.GetStats() },
+		"sandbox":           func() interface{} { return ce.sandbox.GetStats() },
+		"debugger":          func() interface{} { return ce.debugger.GetStats() },
+		"runbooks":          func() interface{} { return ce.runbookRegistry.GetStats() },
+		"traces":            func() interface{} { return ce.traceTracker.GetStats() },
+		"reports":           func() interface{} { return ce.reportRegistry.GetStats() },
+		"admission":         func() interface{} { return ce.admissionHooks.GetStats() },
+		"provenance":        func() interface{} { return ce.provenance.GetStats() },
+		"acls":              func() interface{} { return ce.acls.GetStats() },
+		"history":           func() interface{} { return ce.history.GetStats() },
+		"metering":          func() interface{} { return ce.meter.GetStats() },
+		"stats_history":     func() interface{} { return ce.statsHistory.GetStats() },
+		"decay":             func() interface{} { return ce.decayPolicies.GetStats() },
+		"organizations":     func() interface{} { return ce.orgs.GetStats() },
+		"link_types":        func() interface{} { return ce.linkTypes.GetStats() },
+		"federation":        func() interface{} { return ce.federation.GetStats() },
+		"schemas":           func() interface{} { return ce.schemas.GetStats() },
+		"decision_policies": func() interface{} { return ce.decisionPolicies.GetStats() },
+		"attention_params":  func() interface{} { return ce.attentionParams.GetStats() },
+		"counterfactuals":   func() interface{} { return ce.counterfactuals.GetStats() },
+		"stats_stream":      func() interface{} { return ce.liveStats.GetStats() },
+		"artifacts":         func() interface{} { return ce.artifacts.GetStats() },
+		"notifications":     func() interface{} { return ce.notifications.GetStats() },
+		"maintenance":       func() interface{} { return ce.maintenance.GetStats() },
+		"watches":           func() interface{} { return ce.watches.GetStats() },
+		"shares":            func() interface{} { return ce.shares.GetStats() },
+	}
+	
+	if ce.encryptor != nil {
+		sections["encryption"] = func() interface{} { return ce.encryptor.GetStats() }
+	}
+	if ce.valueLogs != nil {
+		sections["value_logs"] = func() interface{} { return ce.valueLogs.GetStats() }
+	}
+	if ce.snapshots != nil {
+		sections["snapshots"] = func() interface{} { return ce.snapshots.GetStats() }
+	}
+	if ce.elector != nil {
+		sections["leader"] = func() interface{} { return ce.elector.Status() }
+	}
+	if ce.partitioner != nil {
+		sections["partition"] = func() interface{} { return ce.partitioner.Status() }
+	}
+	if ce.watchdog != nil {
+		sections["watchdog"] = func() interface{} { return ce.watchdog.GetStats() }
 	}
 	
 	if tenantID != "" {
-		stats["tenant"] = ce.shardManager.GetTenantStats(tenantID)
+		sections["tenant"] = ce.cachedStats("tenant", tenantID, func() interface{} { return ce.shardManager.GetTenantStats(tenantID) })
+		sections["mounts"] = func() interface{} { return ce.GetMounts(tenantID) }
+		ce.mu.RLock()
+		inferenceEngine, exists := ce.inferenceEngines[tenantID]
+		ce.mu.RUnlock()
+		if exists {
+			sections["inference_merges"] = func() interface{} { return inferenceEngine.GetMergeStats() }
+			sections["inference_fixpoint"] = func() interface{} { return inferenceEngine.GetFixpointStats() }
+			sections["inference_last_run"] = func() interface{} { return inferenceEngine.LastRun() }
+		}
 	}
 	
-	return stats
+	stats := make(map[string]interface{}, len(sections))
+	for name, compute := range sections {
+		if sel.Wants(name) {
+			stats[name] = compute()
+		}
+	}
+	// Computed last, to count the hits of the sections above
+	if sel.Wants("stats_cache") {
+		stats["stats_cache"] = ce.statsCache.GetStats()
+	}
+	return sel.Apply(stats)
+}
+
+// cachedStats computes a section of the statistics of a tenant, or of the
+// engine, through the stats cache
+func (ce *CognitiveEngine) cachedStats(section, tenantID string, compute func() interface{}) func() interface{} {
+	return func() interface{} {
+		return ce.statsCache.Get(section+"/"+tenantID, compute)
+	}
 }
 
 // Close shuts down the cognitive engine gracefully
 func (ce *CognitiveEngine) Close() error {
 	close(ce.done)
+	ce.liveStats.Close()
+	
+	// Hand leadership and agents over before agents are stopped
+	if ce.elector != nil {
+		<-ce.electionDone
+	}
+	if ce.partitioner != nil {
+		<-ce.partitionDone
+	}
+	
+	// Drain daemon agents before the stores they use are closed
+	ce.agentScheduler.Close()
+	ce.flushMetering(meteringCloseTimeout)
+	if ce.snapshots != nil {
+		ce.snapshots.wg.Wait()
+	}
+	if ce.valueLogs != nil {
+		ce.valueLogs.close()
+	}
 	
 	// Close all components
 	ce.shardManager.Close()
@@ -268,8 +1267,10 @@ func (ce *CognitiveEngine) Close() error {
 	}
 	ce.mu.RUnlock()
 	
-	ce.agentScheduler.Close()
 	ce.pipelineOrch.Close()
+	ce.triggerManager.Close()
+	ce.sessionManager.Close()
+	ce.eventBus.Close()
 	
 	return nil
 }
@@ -335,9 +1336,16 @@ func (ce *CognitiveEngine) CreateDefaultPipeline(tenantID string) (string, error
 	// In a real implementation, we'd create a tenant-specific view
 	shard, _ := ce.shardManager.GetShardByID(0)
 	
-	p.AddStage(pipeline.NewInferenceStage(inferenceEngine, tenantID, 5))
-	p.AddStage(pipeline.NewAttentionAllocationStage(shard.AtomSpace, tenantID))
-	p.AddStage(pipeline.NewAgentExecutionStage(ce.agentScheduler, tenantID))
+	stages := []pipeline.PipelineStage{
+		pipeline.NewInferenceStage(inferenceEngine, tenantID, 5),
+		pipeline.NewAttentionAllocationStage(shard.AtomSpace, tenantID, ce.attentionParams.Params),
+		pipeline.NewAgentExecutionStage(ce.agentScheduler, tenantID),
+	}
+	for _, stage := range stages {
+		if err := p.AddStage(stage); err != nil {
+			return "", err
+		}
+	}
 	
 	return pipelineID, nil
 }
@@ -351,7 +1359,7 @@ func (ce *CognitiveEngine) Health() map[string]interface{} {
 	return map[string]interface{}{
 		"status":      "healthy",
 		"num_tenants": numTenants,
-		"num_shards":  ce.numShards,
+		"num_shards":  ce.NumShards(),
 		"timestamp":   time.Now().UTC(),
 	}
 }
@@ -1,9 +1,82 @@
 package cognitive
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/admission"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/attention"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/budget"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/changes"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/cmdb"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/connectors"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/cost"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/counterfactuals"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/debugger"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/decay"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/decisions"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/drift"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/federation"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/forecast"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/gitops"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/hygiene"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/impact"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/incidents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/leader"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/learning"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/linktypes"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/livestats"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/maintenance"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/metering"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/notifications"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/onboarding"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/orgs"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/partition"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/persistence"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/pipeline"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/promotion"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/provenance"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/queries"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/recommendations"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/reports"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/retry"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/runbooks"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sandbox"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/schemas"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/secrets"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sharding"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/shares"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/slo"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/statsview"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/terraform"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/traces"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/trends"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/usage"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/watchdog"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/watches"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/whatif"
+	"github.com/Avik2024/erebus/backend/internal/health"
 )
 
 func TestNewCognitiveEngine(t *testing.T) {
@@ -218,3 +291,6029 @@ func TestGetStats(t *testing.T) {
 		t.Error("Expected 'sharding' in stats")
 	}
 }
+
+func TestSymmetricLinkDeduplication(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	a, _ := engine.CreateConceptNode("ServiceA", tenantID)
+	b, _ := engine.CreateConceptNode("ServiceB", tenantID)
+	
+	forward := []atomspace.Atom{a, b}
+	reverse := []atomspace.Atom{b, a}
+	
+	simID := atomspace.GenerateAtomID(atomspace.SimilarityLinkType, "similarity", forward)
+	if simID != atomspace.GenerateAtomID(atomspace.SimilarityLinkType, "similarity", reverse) {
+		t.Error("Expected symmetric links to share an ID regardless of outgoing order")
+	}
+	
+	inhID := atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", forward)
+	if inhID == atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", reverse) {
+		t.Error("Expected ordered links to keep distinct IDs for different outgoing orders")
+	}
+	
+	if err := engine.AddAtom(atomspace.NewLink(simID, "similarity", tenantID, atomspace.SimilarityLinkType, forward)); err != nil {
+		t.Fatalf("Failed to add similarity link: %v", err)
+	}
+	if err := engine.AddAtom(atomspace.NewLink(simID, "similarity", tenantID, atomspace.SimilarityLinkType, reverse)); err == nil {
+		t.Error("Expected duplicate similarity link to be rejected")
+	}
+}
+
+func TestSearchAtoms(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	for _, name := range []string{"payments-service", "payments-db", "checkout-service", "PaymentGateway"} {
+		if _, err := engine.CreateConceptNode(name, tenantID); err != nil {
+			t.Fatalf("Failed to create concept: %v", err)
+		}
+	}
+	engine.CreateConceptNode("payments-service", "other-tenant")
+	
+	prefix := engine.SearchAtoms(tenantID, "payment", atomspace.SearchModePrefix, 0)
+	if len(prefix) != 3 {
+		t.Errorf("Expected 3 prefix matches, got %d", len(prefix))
+	}
+	
+	substring := engine.SearchAtoms(tenantID, "service", atomspace.SearchModeSubstring, 0)
+	if len(substring) != 2 {
+		t.Errorf("Expected 2 substring matches, got %d", len(substring))
+	}
+	
+	fuzzy := engine.SearchAtoms(tenantID, "paymnets-service", atomspace.SearchModeFuzzy, 0)
+	if len(fuzzy) != 1 || fuzzy[0].Atom.GetName() != "payments-service" {
+		t.Errorf("Expected fuzzy match on payments-service, got %v", fuzzy)
+	}
+	
+	auto := engine.SearchAtoms(tenantID, "payments-service", atomspace.SearchModeAuto, 1)
+	if len(auto) != 1 || auto[0].Atom.GetName() != "payments-service" {
+		t.Errorf("Expected exact match ranked first, got %v", auto)
+	}
+	
+	engine.DeleteAtom(auto[0].Atom.GetID(), tenantID)
+	if results := engine.SearchAtoms(tenantID, "payments-service", atomspace.SearchModePrefix, 0); len(results) != 0 {
+		t.Errorf("Expected deleted atom to leave the index, got %d results", len(results))
+	}
+}
+
+func TestTriggerExecutesPipeline(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if _, err := engine.CreatePipeline("on-anomaly", "On Anomaly", tenantID); err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	
+	conceptType := atomspace.ConceptNodeType
+	err := engine.Triggers().AddTrigger(&triggers.Trigger{
+		ID:       "anomaly-trigger",
+		TenantID: tenantID,
+		Condition: triggers.Condition{
+			AtomType:      &conceptType,
+			NamePrefix:    "anomaly-",
+			MinConfidence: 0.9,
+		},
+		Actions: []triggers.Action{{Type: triggers.ActionExecutePipeline, PipelineID: "on-anomaly"}},
+		Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to add trigger: %v", err)
+	}
+	
+	engine.CreateConceptNode("healthy-node", tenantID)
+	engine.CreateConceptNode("anomaly-disk-pressure", tenantID)
+	
+	trigger, _ := engine.Triggers().GetTrigger("anomaly-trigger", tenantID)
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		p, _ := engine.GetPipeline("on-anomaly")
+		if p.GetStats()["state"] == pipeline.PipelineStateCompleted {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	
+	if fired := trigger.GetStats()["fire_count"].(int64); fired != 1 {
+		t.Errorf("Expected trigger to fire once, fired %d times", fired)
+	}
+	p, _ := engine.GetPipeline("on-anomaly")
+	if state := p.GetStats()["state"]; state != pipeline.PipelineStateCompleted {
+		t.Errorf("Expected triggered pipeline to complete, state %v", state)
+	}
+}
+
+func TestSessionWorkingMemory(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	cat, _ := engine.CreateConceptNode("Cat", tenantID)
+	mammal, _ := engine.CreateConceptNode("Mammal", tenantID)
+	engine.CreateInheritanceLink(cat.GetID(), mammal.GetID(), tenantID)
+	
+	session, err := engine.CreateSession(tenantID, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	
+	animalID := atomspace.GenerateAtomID(atomspace.ConceptNodeType, "Animal", nil)
+	animal := atomspace.NewNode(animalID, "Animal", tenantID, atomspace.ConceptNodeType)
+	if err := session.Space.AddAtom(animal); err != nil {
+		t.Fatalf("Failed to add session atom: %v", err)
+	}
+	outgoing := []atomspace.Atom{mammal, animal}
+	linkID := atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", outgoing)
+	session.Space.AddAtom(atomspace.NewLink(linkID, "inheritance", tenantID, atomspace.InheritanceLinkType, outgoing))
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	newAtoms, err := session.RunInference(ctx, 5)
+	if err != nil {
+		t.Fatalf("Failed to run session inference: %v", err)
+	}
+	if len(newAtoms) == 0 {
+		t.Error("Expected session inference to derive new atoms")
+	}
+	
+	if got := len(engine.QueryAtoms(tenantID, nil)); got != 3 {
+		t.Errorf("Expected main space to keep 3 atoms, got %d", got)
+	}
+	
+	if err := engine.CloseSession(session.ID, tenantID); err != nil {
+		t.Fatalf("Failed to close session: %v", err)
+	}
+	if _, err := engine.GetSession(session.ID, tenantID); err == nil {
+		t.Error("Expected closed session to be gone")
+	}
+}
+
+func TestSharedOntologyMount(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	if _, err := engine.CreateSharedSpace("k8s", "Kubernetes kinds", ""); err != nil {
+		t.Fatalf("Failed to create shared space: %v", err)
+	}
+	pod, _ := engine.CreateSharedConceptNode("k8s", "Pod")
+	workload, _ := engine.CreateSharedConceptNode("k8s", "Workload")
+	engine.CreateSharedInheritanceLink("k8s", pod.GetID(), workload.GetID())
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	if err := engine.MountSharedSpace(tenantID, "k8s"); err != nil {
+		t.Fatalf("Failed to mount shared space: %v", err)
+	}
+	
+	checkout, _ := engine.CreateConceptNode("checkout-pod", tenantID)
+	if _, err := engine.CreateInheritanceLink(checkout.GetID(), pod.GetID(), tenantID); err != nil {
+		t.Fatalf("Failed to link tenant atom to shared atom: %v", err)
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	newAtoms, err := engine.RunInference(ctx, tenantID, 5)
+	if err != nil {
+		t.Fatalf("Failed to run inference: %v", err)
+	}
+	for _, atom := range newAtoms {
+		if atom.GetTenantID() != tenantID {
+			t.Errorf("Expected inferred atom to be tenant-local, got tenant %s", atom.GetTenantID())
+		}
+	}
+	if len(newAtoms) == 0 {
+		t.Error("Expected inference over shared and tenant atoms to derive new atoms")
+	}
+	
+	if err := engine.DeleteAtom(pod.GetID(), tenantID); err == nil {
+		t.Error("Expected deleting a shared atom through a tenant to fail")
+	}
+	if len(engine.QueryAtoms("other-tenant", nil)) != 0 {
+		t.Error("Expected shared atoms to be invisible to tenants without the mount")
+	}
+}
+
+func TestPipelineStageTypeValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	p, err := engine.CreatePipeline("typed", "Typed", tenantID)
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	if err := p.SetInputType(pipeline.DataTypeNone); err != nil {
+		t.Fatalf("Failed to set input type: %v", err)
+	}
+	
+	tenantSpace := &tenantAtomSpaceWrapper{engine: engine, tenantID: tenantID}
+	if err := p.AddStage(pipeline.NewAtomIngestionStage(tenantSpace, tenantID)); err == nil {
+		t.Error("Expected ingestion stage without atom input to be rejected at assembly")
+	}
+	
+	if err := p.AddStage(pipeline.NewQueryStage(tenantSpace, tenantID, nil)); err != nil {
+		t.Fatalf("Failed to add query adapter stage: %v", err)
+	}
+	if err := p.AddStage(pipeline.NewAtomIngestionStage(tenantSpace, tenantID)); err != nil {
+		t.Errorf("Expected ingestion stage after query adapter to be accepted: %v", err)
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := engine.ExecutePipeline(ctx, "typed", nil); err != nil {
+		t.Errorf("Expected typed pipeline to execute: %v", err)
+	}
+}
+
+func TestDeclarativeInfraPipeline(t *testing.T) {
+	prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[` +
+			`{"metric":{"instance":"web-1"},"value":[1700000000,"0.21"]},` +
+			`{"metric":{"instance":"web-2"},"value":[1700000000,"0.19"]},` +
+			`{"metric":{"instance":"web-3"},"value":[1700000000,"0.20"]},` +
+			`{"metric":{"instance":"web-4"},"value":[1700000000,"0.22"]},` +
+			`{"metric":{"instance":"web-5"},"value":[1700000000,"0.97"]}]}}`))
+	}))
+	defer prometheus.Close()
+	
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	spec := pipeline.PipelineSpec{
+		Name:      "infra",
+		InputType: pipeline.DataTypeNone,
+		Stages: []pipeline.StageSpec{
+			{Kind: "metric-ingest", Params: pipeline.StageParams{"url": prometheus.URL, "query": "cpu_usage"}},
+			{Kind: "anomaly-score", Params: pipeline.StageParams{"threshold": 1.5}},
+			{Kind: "remediation-proposal", Params: pipeline.StageParams{"actions": map[string]interface{}{"cpu_usage": "scale_up"}}},
+			{Kind: "report", Params: pipeline.StageParams{"format": "markdown", "title": "CPU"}},
+		},
+	}
+	if _, err := engine.CreatePipelineFromSpec("infra", tenantID, spec); err != nil {
+		t.Fatalf("Failed to create declarative pipeline: %v", err)
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	output, err := engine.ExecutePipeline(ctx, "infra", nil)
+	if err != nil {
+		t.Fatalf("Failed to execute pipeline: %v", err)
+	}
+	
+	report, ok := output.(*pipeline.Report)
+	if !ok {
+		t.Fatalf("Expected *Report output, got %T", output)
+	}
+	if len(report.Items) != 1 || report.Items[0].Name != "remediation:scale_up:web-5" {
+		t.Errorf("Expected a single scale_up proposal for web-5, got %+v", report.Items)
+	}
+	if !strings.Contains(report.Content, "remediation:scale_up:web-5") {
+		t.Errorf("Expected markdown report to list the proposal, got %q", report.Content)
+	}
+	
+	badSpec := pipeline.PipelineSpec{
+		Name:   "bad",
+		Stages: []pipeline.StageSpec{{Kind: "report"}, {Kind: "anomaly-score"}},
+	}
+	if _, err := engine.CreatePipelineFromSpec("bad", tenantID, badSpec); err == nil {
+		t.Error("Expected a report followed by an atom stage to be rejected")
+	}
+}
+
+// blockingStage holds each execution until release is closed
+type blockingStage struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStage) GetName() string {
+	return "blocking"
+}
+
+func (s *blockingStage) Execute(ctx context.Context, input interface{}) (interface{}, error) {
+	s.started <- struct{}{}
+	<-s.release
+	return input, nil
+}
+
+func TestPipelineConcurrencyQueue(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	p, err := engine.CreatePipeline("queued", "Queued", "test-tenant")
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	stage := &blockingStage{started: make(chan struct{}, 3), release: make(chan struct{})}
+	if err := p.AddStage(stage); err != nil {
+		t.Fatalf("Failed to add stage: %v", err)
+	}
+	if err := p.SetMaxConcurrency(1); err != nil {
+		t.Fatalf("Failed to set max concurrency: %v", err)
+	}
+	
+	ctx := context.Background()
+	var ids []string
+	for i := 0; i < 3; i++ {
+		exec, err := engine.SubmitPipeline(ctx, "queued", nil)
+		if err != nil {
+			t.Fatalf("Failed to submit execution: %v", err)
+		}
+		ids = append(ids, exec.ID)
+	}
+	<-stage.started
+	
+	for i, id := range ids {
+		exec, ok := p.GetExecution(id)
+		if !ok {
+			t.Fatalf("Execution %s not found", id)
+		}
+		if exec.QueuePosition != i {
+			t.Errorf("Expected execution %d at queue position %d, got %d (%s)", i, i, exec.QueuePosition, exec.State)
+		}
+	}
+	
+	close(stage.release)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		last, _ := p.GetExecution(ids[2])
+		if last.State == pipeline.ExecutionStateCompleted {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	
+	executions := p.GetExecutions()
+	if len(executions) != 3 {
+		t.Fatalf("Expected 3 executions, got %d", len(executions))
+	}
+	for i, exec := range executions {
+		if exec.State != pipeline.ExecutionStateCompleted {
+			t.Errorf("Expected execution %d to complete, got %s", i, exec.State)
+		}
+		if i > 0 && exec.StartedAt.Before(executions[i-1].CompletedAt) {
+			t.Errorf("Expected execution %d to start after execution %d finished", i, i-1)
+		}
+	}
+}
+
+// flakyStage fails until fixed is set
+type flakyStage struct {
+	fixed bool
+}
+
+func (s *flakyStage) GetName() string {
+	return "flaky"
+}
+
+func (s *flakyStage) Execute(ctx context.Context, input interface{}) (interface{}, error) {
+	if !s.fixed {
+		return nil, fmt.Errorf("connector unavailable")
+	}
+	return input, nil
+}
+
+func TestDeadLetterRetry(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	p, err := engine.CreatePipeline("flaky", "Flaky", tenantID)
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	stage := &flakyStage{}
+	if err := p.AddStage(stage); err != nil {
+		t.Fatalf("Failed to add stage: %v", err)
+	}
+	
+	input := []atomspace.Atom{atomspace.NewNode("n1", "server", tenantID, atomspace.ConceptNodeType)}
+	ctx := context.Background()
+	if _, err := engine.ExecutePipeline(ctx, "flaky", input); err == nil {
+		t.Fatal("Expected pipeline to fail")
+	}
+	
+	entries := engine.GetDeadLetters(tenantID)
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 dead letter, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Stage != "flaky" || entry.PipelineID != "flaky" || entry.Attempts != 1 {
+		t.Errorf("Unexpected dead letter: %+v", entry)
+	}
+	if len(entry.InputAtomIDs) != 1 || entry.InputAtomIDs[0] != "n1" {
+		t.Errorf("Expected input reference to n1, got %v", entry.InputAtomIDs)
+	}
+	
+	// A failing replay is dead-lettered again with an incremented attempt count
+	if _, err := engine.RetryDeadLetter(ctx, entry.ID, tenantID); err == nil {
+		t.Fatal("Expected replay to fail before the fix")
+	}
+	entries = engine.GetDeadLetters(tenantID)
+	if len(entries) != 1 || entries[0].Attempts != 2 {
+		t.Fatalf("Expected one dead letter with 2 attempts, got %+v", entries)
+	}
+	
+	stage.fixed = true
+	output, err := engine.RetryDeadLetter(ctx, entries[0].ID, tenantID)
+	if err != nil {
+		t.Fatalf("Expected replay to succeed: %v", err)
+	}
+	if atoms, ok := output.([]atomspace.Atom); !ok || len(atoms) != 1 {
+		t.Errorf("Expected replay to run with the original input, got %v", output)
+	}
+	if len(engine.GetDeadLetters(tenantID)) != 0 {
+		t.Error("Expected dead letter to be removed after a successful replay")
+	}
+}
+
+// transientStage fails the first failures calls
+type transientStage struct {
+	failures int
+	calls    int
+}
+
+func (s *transientStage) GetName() string {
+	return "transient"
+}
+
+func (s *transientStage) Execute(ctx context.Context, input interface{}) (interface{}, error) {
+	s.calls++
+	if s.calls <= s.failures {
+		return nil, fmt.Errorf("temporary network error")
+	}
+	return input, nil
+}
+
+func TestStageRetryPolicy(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.StageRetryPolicy = retry.Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 2}
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	p, err := engine.CreatePipeline("retrying", "Retrying", "test-tenant")
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	stage := &transientStage{failures: 2}
+	if err := p.AddStage(stage); err != nil {
+		t.Fatalf("Failed to add stage: %v", err)
+	}
+	
+	ctx := context.Background()
+	if _, err := engine.ExecutePipeline(ctx, "retrying", nil); err != nil {
+		t.Fatalf("Expected transient failures to be retried: %v", err)
+	}
+	executions := p.GetExecutions()
+	if len(executions) != 1 || executions[0].Retries != 2 {
+		t.Errorf("Expected 2 retries recorded on the execution, got %+v", executions)
+	}
+	if retries := p.GetStats()["retries"].(int64); retries != 2 {
+		t.Errorf("Expected 2 retries in pipeline stats, got %d", retries)
+	}
+	
+	// Non-retryable errors fail immediately
+	p.SetStageRetryPolicy("transient", retry.Spec{MaxAttempts: 5, InitialBackoffMS: 1, NonRetryable: []string{"network"}}.Policy())
+	stage.calls, stage.failures = 0, 1
+	if _, err := engine.ExecutePipeline(ctx, "retrying", nil); err == nil {
+		t.Fatal("Expected non-retryable failure")
+	}
+	if stage.calls != 1 {
+		t.Errorf("Expected a single attempt for a non-retryable error, got %d", stage.calls)
+	}
+}
+
+// failingAgent always fails its run
+type failingAgent struct {
+	agents.BaseAgent
+}
+
+func (a *failingAgent) Run(ctx context.Context) error {
+	return fmt.Errorf("watcher crashed")
+}
+
+func TestAgentSupervisorQuarantine(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.AgentRetryPolicy = retry.NoRetry()
+	cfg.SupervisorPolicy = agents.SupervisorPolicy{
+		MaxConsecutiveFailures: 2,
+		RestartBackoff:         10 * time.Millisecond,
+		FlapWindow:             time.Minute,
+		FlapThreshold:          2,
+	}
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	engine.RegisterAgent(&failingAgent{BaseAgent: agents.BaseAgent{ID: "flapper", Name: "Flapper", TenantID: tenantID}})
+	
+	var health agents.AgentHealth
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if h := engine.GetAgentHealth(tenantID); len(h) == 1 && h[0].State == agents.HealthQuarantined {
+			health = h[0]
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	
+	if health.State != agents.HealthQuarantined {
+		t.Fatalf("Expected flapping agent to be quarantined, got %+v", engine.GetAgentHealth(tenantID))
+	}
+	if health.Restarts != 1 || health.TotalFailures != 4 {
+		t.Errorf("Expected 1 restart and 4 failures before quarantine, got %d and %d", health.Restarts, health.TotalFailures)
+	}
+	if status := engine.CheckAgents().Status; status != "degraded" {
+		t.Errorf("Expected degraded readiness with a quarantined agent, got %s", status)
+	}
+	
+	// A quarantined agent is no longer scheduled
+	time.Sleep(300 * time.Millisecond)
+	if h := engine.GetAgentHealth(tenantID); h[0].TotalFailures != health.TotalFailures {
+		t.Errorf("Expected quarantined agent not to run, failures went from %d to %d", health.TotalFailures, h[0].TotalFailures)
+	}
+	
+	if err := engine.ReleaseAgent("flapper"); err != nil {
+		t.Fatalf("Failed to release agent: %v", err)
+	}
+	if h := engine.GetAgentHealth(tenantID); h[0].State == agents.HealthQuarantined {
+		t.Error("Expected release to lift the quarantine")
+	}
+}
+
+// meteredAgent charges a fixed number of inference iterations per run
+type meteredAgent struct {
+	agents.BaseAgent
+	runs atomic.Int64
+}
+
+func (a *meteredAgent) Run(ctx context.Context) error {
+	a.runs.Add(1)
+	budget.MeterFrom(ctx).AddIterations(5)
+	return nil
+}
+
+func TestTenantBudgetPausesAgents(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.SetTenantBudget(tenantID, budget.Budget{Window: time.Hour, MaxIterations: 10, Action: budget.ActionPause}); err != nil {
+		t.Fatalf("Failed to set budget: %v", err)
+	}
+	
+	agent := &meteredAgent{BaseAgent: agents.BaseAgent{ID: "metered", Name: "Metered", TenantID: tenantID}}
+	engine.RegisterAgent(agent)
+	
+	// Enough scheduler ticks for many runs without a budget
+	time.Sleep(600 * time.Millisecond)
+	
+	if runs := agent.runs.Load(); runs != 2 {
+		t.Errorf("Expected the agent to be paused after 2 runs, got %d", runs)
+	}
+	usage := engine.GetTenantUsage(tenantID)
+	if usage["exhausted"] != true {
+		t.Errorf("Expected tenant budget to be exhausted, got %v", usage)
+	}
+	if u := usage["usage"].(budget.Usage); u.Iterations != 10 {
+		t.Errorf("Expected 10 iterations charged, got %d", u.Iterations)
+	}
+	
+	engine.RemoveTenantBudget(tenantID)
+	time.Sleep(300 * time.Millisecond)
+	if agent.runs.Load() <= 2 {
+		t.Error("Expected the agent to resume once the budget is lifted")
+	}
+}
+
+type orderedAgent struct {
+	agents.BaseAgent
+	log *runLog
+}
+
+type runLog struct {
+	mu   sync.Mutex
+	runs []string
+}
+
+func (a *orderedAgent) Run(ctx context.Context) error {
+	a.log.mu.Lock()
+	defer a.log.mu.Unlock()
+	a.log.runs = append(a.log.runs, a.ID)
+	return nil
+}
+
+func TestAgentRunPlanOrdering(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	
+	// Cycles are rejected
+	err := engine.SetAgentRunPlan(tenantID, agents.RunPlan{Groups: []agents.RunGroup{
+		{Name: "a", After: []string{"b"}},
+		{Name: "b", After: []string{"a"}},
+	}})
+	if err == nil {
+		t.Fatal("Expected a dependency cycle to be rejected")
+	}
+	
+	// Forgetting has the highest priority but must run last
+	err = engine.SetAgentRunPlan(tenantID, agents.RunPlan{Groups: []agents.RunGroup{
+		{Name: "forgetting", Agents: []string{"forget"}, After: []string{"attention"}},
+		{Name: "attention", Agents: []string{"attend"}, After: []string{"inference"}},
+		{Name: "inference", Agents: []string{"infer"}},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to set run plan: %v", err)
+	}
+	plan, _ := engine.GetAgentRunPlan(tenantID)
+	if plan.Groups[0].Name != "inference" || plan.Groups[2].Name != "forgetting" {
+		t.Errorf("Expected groups in dependency order, got %+v", plan.Groups)
+	}
+	
+	log := &runLog{}
+	priorities := map[string]int{"forget": 10, "attend": 5, "infer": 1}
+	for _, id := range []string{"forget", "attend", "infer"} {
+		engine.RegisterAgent(&orderedAgent{
+			BaseAgent: agents.BaseAgent{ID: id, Name: id, TenantID: tenantID, Priority: priorities[id]},
+			log:       log,
+		})
+	}
+	
+	
+	// Discard cycles that ran before all agents were registered
+	for _, id := range []string{"forget", "attend", "infer"} {
+		for {
+			if _, exists := engine.GetAgent(id); exists {
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	log.mu.Lock()
+	log.runs = nil
+	log.mu.Unlock()
+	time.Sleep(350 * time.Millisecond)
+	
+	// The first entries may belong to the cycle in flight
+	log.mu.Lock()
+	runs := append([]string(nil), log.runs...)
+	log.mu.Unlock()
+	for len(runs) > 0 && runs[0] != "infer" {
+		runs = runs[1:]
+	}
+	if len(runs) < 3 {
+		t.Fatalf("Expected at least one full cycle, got %v", runs)
+	}
+	for i := 0; i+3 <= len(runs); i += 3 {
+		if got := strings.Join(runs[i:i+3], ","); got != "infer,attend,forget" {
+			t.Errorf("Expected infer,attend,forget, got %s", got)
+		}
+	}
+	
+	if err := engine.SetAgentRunPlan("other-tenant", agents.RunPlan{Groups: []agents.RunGroup{{Name: "x", Agents: []string{"infer"}}}}); err == nil {
+		t.Error("Expected another tenant's agent to be rejected")
+	}
+}
+
+type watcherAgent struct {
+	agents.BaseAgent
+	crashes atomic.Int64 // Starts that fail immediately
+	runs    atomic.Int64
+	drained atomic.Bool
+	stop    chan struct{}
+}
+
+func (a *watcherAgent) Run(ctx context.Context) error {
+	a.runs.Add(1)
+	return nil
+}
+
+func (a *watcherAgent) Start(ctx context.Context) error {
+	if a.crashes.Add(-1) >= 0 {
+		return fmt.Errorf("stream disconnected")
+	}
+	select {
+	case <-a.stop:
+		a.drained.Store(true)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *watcherAgent) Stop() {
+	close(a.stop)
+}
+
+func TestDaemonAgentLifecycle(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SupervisorPolicy = agents.DefaultSupervisorPolicy()
+	cfg.SupervisorPolicy.RestartBackoff = 10 * time.Millisecond
+	engine := NewCognitiveEngine(cfg)
+	
+	tenantID := "test-tenant"
+	agent := &watcherAgent{
+		BaseAgent: agents.BaseAgent{ID: "watcher", Name: "Watcher", TenantID: tenantID},
+		stop:      make(chan struct{}),
+	}
+	agent.crashes.Store(1)
+	engine.RegisterAgent(agent)
+	
+	// The daemon crashes once and is restarted by the supervisor
+	var daemons []agents.DaemonStatus
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		daemons = engine.GetAgentDaemons(tenantID)
+		if len(daemons) == 1 && daemons[0].Starts == 2 && daemons[0].Running {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(daemons) != 1 || daemons[0].Starts != 2 || !daemons[0].Running {
+		t.Fatalf("Expected the daemon to be restarted and running, got %+v", daemons)
+	}
+	if health := engine.GetAgentHealth(tenantID); len(health) != 1 || health[0].TotalFailures != 1 {
+		t.Errorf("Expected 1 recorded failure, got %+v", health)
+	}
+	
+	time.Sleep(250 * time.Millisecond)
+	if runs := agent.runs.Load(); runs != 0 {
+		t.Errorf("Expected the daemon not to be run per cycle, got %d runs", runs)
+	}
+	
+	// Shutdown drains the daemon
+	engine.Close()
+	if !agent.drained.Load() {
+		t.Error("Expected the daemon to be drained on shutdown")
+	}
+}
+
+func TestFeedbackAdjustsPrioritiesAndRuleWeights(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Learning.LearningRate = 0.5
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	engine.RegisterAgent(&meteredAgent{BaseAgent: agents.BaseAgent{ID: "remediator", Name: "Remediator", TenantID: tenantID, Priority: 5}})
+	time.Sleep(50 * time.Millisecond)
+	
+	for i := 0; i < 3; i++ {
+		if _, err := engine.Feedback(learning.Feedback{TenantID: tenantID, Outcome: learning.OutcomeActionRejected, AgentIDs: []string{"remediator"}}); err != nil {
+			t.Fatalf("Feedback failed: %v", err)
+		}
+	}
+	if priority, _ := engine.agentScheduler.GetEffectivePriority("remediator"); priority >= 5 {
+		t.Errorf("Expected rejected actions to lower the priority, got %d", priority)
+	}
+	
+	adjustments, err := engine.Feedback(learning.Feedback{TenantID: tenantID, Outcome: learning.OutcomeAnomalyDismissed, Rules: []string{"abduction"}})
+	if err != nil {
+		t.Fatalf("Feedback failed: %v", err)
+	}
+	if len(adjustments) != 1 || adjustments[0].Weight >= 1 {
+		t.Errorf("Expected the rule weight to drop, got %+v", adjustments)
+	}
+	if weights := engine.inferenceEngines[tenantID].GetRuleWeights(); weights["abduction"] != adjustments[0].Weight {
+		t.Errorf("Expected the inference engine to use the learned weight, got %v", weights)
+	}
+	
+	if _, err := engine.Feedback(learning.Feedback{TenantID: "other-tenant", Outcome: learning.OutcomeActionAccepted, AgentIDs: []string{"remediator"}}); err == nil {
+		t.Error("Expected feedback for another tenant's agent to be rejected")
+	}
+	
+	engine.ResetLearning(tenantID)
+	if priority, _ := engine.agentScheduler.GetEffectivePriority("remediator"); priority != 5 {
+		t.Errorf("Expected the priority to be restored, got %d", priority)
+	}
+	if weights := engine.inferenceEngines[tenantID].GetRuleWeights(); len(weights) != 0 {
+		t.Errorf("Expected rule weights to be restored, got %v", weights)
+	}
+}
+
+func TestPatternMining(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	node := func(atomType atomspace.AtomType, name string) atomspace.Atom {
+		n := atomspace.NewNode(atomspace.GenerateAtomID(atomType, name, nil), name, tenantID, atomType)
+		engine.AddAtom(n)
+		return n
+	}
+	link := func(atomType atomspace.AtomType, name string, outgoing ...atomspace.Atom) {
+		engine.AddAtom(atomspace.NewLink(atomspace.GenerateAtomID(atomType, name, outgoing), name, tenantID, atomType, outgoing))
+	}
+	
+	hasLabel := node(atomspace.PredicateNodeType, "has_label")
+	teamA := node(atomspace.ConceptNodeType, "team-a")
+	payments := node(atomspace.ConceptNodeType, "PaymentService")
+	for i := 0; i < 4; i++ {
+		svc := node(atomspace.ConceptNodeType, fmt.Sprintf("svc-%d", i))
+		link(atomspace.EvaluationLinkType, "has_label", hasLabel, svc, teamA)
+		link(atomspace.InheritanceLinkType, "inheritance", svc, payments)
+	}
+	
+	patterns, err := engine.MinePatterns(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Mining failed: %v", err)
+	}
+	
+	var found *agents.Pattern
+	for i := range patterns {
+		if patterns[i].Antecedent == "has_label($X, team-a)" && patterns[i].Consequent == "inherits($X, PaymentService)" {
+			found = &patterns[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("Expected the label => inheritance pattern, got %+v", patterns)
+	}
+	if found.Support != 4 || found.Confidence != 1 {
+		t.Errorf("Expected support 4 and confidence 1, got %d and %f", found.Support, found.Confidence)
+	}
+	
+	atom, err := engine.GetAtom(found.ID, tenantID)
+	if err != nil || atom.GetName() != found.Name {
+		t.Errorf("Expected a PatternNode for the pattern, got %v (%v)", atom, err)
+	}
+	
+	// Mining again does not mine its own PatternNodes
+	again, _ := engine.MinePatterns(context.Background(), tenantID)
+	if len(again) != len(patterns) {
+		t.Errorf("Expected %d patterns on the second run, got %d", len(patterns), len(again))
+	}
+	if stored, _ := engine.GetPatterns(tenantID); len(stored) != len(patterns) {
+		t.Errorf("Expected stored patterns, got %d", len(stored))
+	}
+}
+
+func TestConceptClustering(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	concept := func(name string) atomspace.Atom {
+		n := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, tenantID, atomspace.ConceptNodeType)
+		engine.AddAtom(n)
+		return n
+	}
+	inherit := func(child, parent atomspace.Atom) {
+		outgoing := []atomspace.Atom{child, parent}
+		engine.AddAtom(atomspace.NewLink(atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", outgoing), "inheritance", tenantID, atomspace.InheritanceLinkType, outgoing))
+	}
+	
+	service := concept("Service")
+	database := concept("Database")
+	for i := 0; i < 4; i++ {
+		inherit(concept(fmt.Sprintf("payment-api-%d", i)), service)
+	}
+	for i := 0; i < 3; i++ {
+		inherit(concept(fmt.Sprintf("orders-db-%d", i)), database)
+	}
+	concept("lonely-host")
+	
+	clusters, err := engine.ClusterConcepts(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Clustering failed: %v", err)
+	}
+	if len(clusters) != 2 {
+		t.Fatalf("Expected 2 clusters, got %+v", clusters)
+	}
+	if clusters[0].Name != "cluster:api-payment" || len(clusters[0].Members) != 4 {
+		t.Errorf("Expected the payment API cluster, got %+v", clusters[0])
+	}
+	if clusters[1].Name != "cluster:db-orders" || len(clusters[1].Members) != 3 {
+		t.Errorf("Expected the orders database cluster, got %+v", clusters[1])
+	}
+	
+	// Members inherit from the materialized parent concept
+	parent, err := engine.GetAtom(clusters[0].ID, tenantID)
+	if err != nil {
+		t.Fatalf("Expected a parent concept: %v", err)
+	}
+	member, _ := engine.GetAtom(atomspace.GenerateAtomID(atomspace.ConceptNodeType, "payment-api-0", nil), tenantID)
+	linkID := atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", []atomspace.Atom{member, parent})
+	if _, err := engine.GetAtom(linkID, tenantID); err != nil {
+		t.Errorf("Expected an InheritanceLink to the cluster: %v", err)
+	}
+	
+	// Re-clustering ignores the cluster membership links it created
+	again, _ := engine.ClusterConcepts(context.Background(), tenantID)
+	if len(again) != 2 || again[0].Name != clusters[0].Name {
+		t.Errorf("Expected stable clusters, got %+v", again)
+	}
+}
+
+func TestForecastAgent(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	
+	// Disk usage rising about 1.5% a day from 70%
+	start := time.Now().Add(-14 * 24 * time.Hour)
+	var samples []forecast.Sample
+	for i := 0; i < 15; i++ {
+		noise := float64(i%3-1) * 0.3
+		samples = append(samples, forecast.Sample{
+			Time:  start.Add(time.Duration(i) * 24 * time.Hour),
+			Value: 70 + 1.5*float64(i) + noise,
+		})
+	}
+	if err := engine.AppendSamples(tenantID, "disk_usage:host-1", samples); err != nil {
+		t.Fatalf("Failed to append samples: %v", err)
+	}
+	
+	config := agents.DefaultForecastConfig()
+	config.Thresholds = []agents.ForecastThreshold{{SeriesPrefix: "disk_usage:", Value: 90, Within: 7 * 24 * time.Hour}}
+	engine.EnableForecasting(tenantID, config)
+	
+	agent, err := engine.RunForecasts(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Forecasting failed: %v", err)
+	}
+	
+	thresholds := agent.GetThresholds()
+	if len(thresholds) != 1 {
+		t.Fatalf("Expected 1 threshold forecast, got %+v", thresholds)
+	}
+	if thresholds[0].Probability < 0.9 {
+		t.Errorf("Expected disk to likely exceed 90%% within 7 days, got %+v", thresholds[0])
+	}
+	
+	link, err := engine.GetAtom(thresholds[0].AtomID, tenantID)
+	if err != nil {
+		t.Fatalf("Expected a will_exceed link: %v", err)
+	}
+	if link.GetTruthValue().Strength != thresholds[0].Probability {
+		t.Errorf("Expected the link strength to be the probability, got %v", link.GetTruthValue())
+	}
+	
+	predictions := 0
+	for _, atom := range engine.QueryAtoms(tenantID, nil) {
+		if atom.GetType() == atomspace.EvaluationLinkType && atom.GetName() == "predicted_value" {
+			predictions++
+		}
+	}
+	if predictions != 3 {
+		t.Errorf("Expected 3 predicted_value links, got %d", predictions)
+	}
+	
+	// Refitting replaces rather than accumulates predictions
+	engine.AppendSamples(tenantID, "disk_usage:host-1", []forecast.Sample{{Time: time.Now(), Value: 92}})
+	engine.RunForecasts(context.Background(), tenantID)
+	predictions = 0
+	for _, atom := range engine.QueryAtoms(tenantID, nil) {
+		if atom.GetType() == atomspace.EvaluationLinkType && atom.GetName() == "predicted_value" {
+			predictions++
+		}
+	}
+	if predictions != 3 {
+		t.Errorf("Expected predictions to be replaced, got %d", predictions)
+	}
+}
+
+func TestCostAgent(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	concept := func(name string) atomspace.Atom {
+		n := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, tenantID, atomspace.ConceptNodeType)
+		n.SetTruthValue(full)
+		engine.AddAtom(n)
+		return n
+	}
+	k8sNode := concept("KubernetesNode")
+	for _, name := range []string{"node/a", "node/b"} {
+		outgoing := []atomspace.Atom{concept(name), k8sNode}
+		engine.AddAtom(atomspace.NewLink(atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", outgoing), "inheritance", tenantID, atomspace.InheritanceLinkType, outgoing))
+	}
+	
+	// node/b is nearly idle
+	pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, "cpu_usage", nil), "cpu_usage", tenantID, atomspace.PredicateNodeType)
+	engine.AddAtom(pred)
+	for name, usage := range map[string]float64{"node/a": 0.7, "node/b": 0.05} {
+		node, _ := engine.GetAtom(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), tenantID)
+		outgoing := []atomspace.Atom{pred, node}
+		link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, "cpu_usage", outgoing), "cpu_usage", tenantID, atomspace.EvaluationLinkType, outgoing)
+		link.SetTruthValue(atomspace.TruthValue{Strength: usage, Confidence: 0.9})
+		engine.AddAtom(link)
+	}
+	
+	if err := engine.SetCostRates(tenantID, []cost.Rate{{Category: "KubernetesNode", Hourly: 0.5}}); err != nil {
+		t.Fatalf("Failed to set rates: %v", err)
+	}
+	
+	report, err := engine.AnalyzeCosts(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Cost analysis failed: %v", err)
+	}
+	if len(report.Resources) != 2 || report.TotalHourly != 1.0 {
+		t.Fatalf("Expected 2 priced nodes costing 1.0/h, got %+v", report)
+	}
+	if report.ByCategory["KubernetesNode"] != 2*0.5*cost.HoursPerMonth {
+		t.Errorf("Expected monthly cost per category, got %v", report.ByCategory)
+	}
+	
+	link, err := engine.GetAtom(report.Resources[0].AtomID, tenantID)
+	if err != nil || link.GetName() != "hourly_cost" {
+		t.Fatalf("Expected an hourly_cost link: %v", err)
+	}
+	if link.GetTruthValue().Strength != 0.05 {
+		t.Errorf("Expected strength relative to the cost scale, got %v", link.GetTruthValue())
+	}
+	
+	if len(report.Savings) != 1 || report.Savings[0].Resource != "node/b" {
+		t.Fatalf("Expected a saving for the idle node, got %+v", report.Savings)
+	}
+	if _, err := engine.GetAtom(report.Savings[0].ProposalID, tenantID); err != nil {
+		t.Errorf("Expected a savings proposal atom: %v", err)
+	}
+	
+	// A price change replaces the annotation
+	engine.SetCostRates(tenantID, []cost.Rate{{Category: "KubernetesNode", Hourly: 1}})
+	engine.AnalyzeCosts(context.Background(), tenantID)
+	if _, err := engine.GetAtom(report.Resources[0].AtomID, tenantID); err == nil {
+		t.Error("Expected the old cost link to be removed")
+	}
+}
+
+func TestSLOErrorBudgetGating(t *testing.T) {
+	prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[` +
+			`{"metric":{"instance":"web-1"},"value":[1700000000,"0.21"]},` +
+			`{"metric":{"instance":"web-2"},"value":[1700000000,"0.19"]},` +
+			`{"metric":{"instance":"web-3"},"value":[1700000000,"0.20"]},` +
+			`{"metric":{"instance":"web-4"},"value":[1700000000,"0.22"]},` +
+			`{"metric":{"instance":"web-5"},"value":[1700000000,"0.97"]}]}}`))
+	}))
+	defer prometheus.Close()
+	
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	
+	// web-5 served 97% of requests successfully against a 99% objective
+	now := time.Now()
+	var samples []forecast.Sample
+	for i := 0; i < 10; i++ {
+		samples = append(samples, forecast.Sample{Time: now.Add(-time.Duration(i) * time.Minute), Value: 0.97})
+	}
+	engine.AppendSamples(tenantID, "availability:web-5", samples)
+	
+	if _, err := engine.SetSLO(tenantID, slo.SLO{Name: "web-5-availability", Service: "web-5", Series: "availability:web-5", Target: 0.99, Window: time.Hour}); err != nil {
+		t.Fatalf("Failed to set SLO: %v", err)
+	}
+	if _, err := engine.SetSLO(tenantID, slo.SLO{Name: "bad", Service: "web-5", Series: "x", Target: 1.5}); err == nil {
+		t.Error("Expected an invalid target to be rejected")
+	}
+	
+	statuses, err := engine.EvaluateSLOs(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("SLO evaluation failed: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].BudgetRemaining != 0 || statuses[0].BurnRate < 2.9 {
+		t.Fatalf("Expected an exhausted budget burning at 3x, got %+v", statuses)
+	}
+	if budget := engine.GetErrorBudget(tenantID, "web-5"); budget != 0 {
+		t.Errorf("Expected no error budget left for web-5, got %f", budget)
+	}
+	
+	service, _ := engine.GetAtom(atomspace.GenerateAtomID(atomspace.ConceptNodeType, "web-5", nil), tenantID)
+	pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, agents.ErrorBudgetPredicate, nil), agents.ErrorBudgetPredicate, tenantID, atomspace.PredicateNodeType)
+	budgetLink, err := engine.GetAtom(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, agents.ErrorBudgetPredicate, []atomspace.Atom{pred, service}), tenantID)
+	if err != nil {
+		t.Fatalf("Expected an error_budget_remaining link for the service: %v", err)
+	}
+	if budgetLink.GetTruthValue().Strength != 0 {
+		t.Errorf("Expected zero budget strength, got %v", budgetLink.GetTruthValue())
+	}
+	
+	// Remediation of web-5 is withheld while its budget is spent
+	spec := pipeline.PipelineSpec{
+		Name:      "gated",
+		InputType: pipeline.DataTypeNone,
+		Stages: []pipeline.StageSpec{
+			{Kind: "metric-ingest", Params: pipeline.StageParams{"url": prometheus.URL, "query": "cpu_usage"}},
+			{Kind: "anomaly-score", Params: pipeline.StageParams{"threshold": 1.5}},
+			{Kind: "remediation-proposal", Params: pipeline.StageParams{"min_error_budget": 0.2}},
+		},
+	}
+	if _, err := engine.CreatePipelineFromSpec("gated", tenantID, spec); err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	output, err := engine.ExecutePipeline(context.Background(), "gated", nil)
+	if err != nil {
+		t.Fatalf("Failed to execute pipeline: %v", err)
+	}
+	if proposals := output.([]atomspace.Atom); len(proposals) != 0 {
+		t.Errorf("Expected the proposal to be withheld, got %d", len(proposals))
+	}
+	blocked := engine.QueryAtoms(tenantID, func(a atomspace.Atom) bool {
+		return a.GetType() == atomspace.EvaluationLinkType && a.GetName() == "blocked_by_error_budget"
+	})
+	if len(blocked) != 1 {
+		t.Errorf("Expected a blocked_by_error_budget link, got %d", len(blocked))
+	}
+}
+
+func TestIncidentCorrelation(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	
+	// web-1 runs on n1; api is unrelated
+	pod, _ := engine.CreateConceptNode("pod/default/web-1", tenantID)
+	node, _ := engine.CreateConceptNode("node/n1", tenantID)
+	pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, "runs_on", nil), "runs_on", tenantID, atomspace.PredicateNodeType)
+	engine.AddAtom(pred)
+	outgoing := []atomspace.Atom{pred, pod, node}
+	engine.AddAtom(atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, "runs_on", outgoing), "runs_on", tenantID, atomspace.EvaluationLinkType, outgoing))
+	
+	now := time.Now()
+	alerts := []incidents.Alert{
+		{Name: "NodeNotReady", Subject: "node/n1", Severity: "critical", StartsAt: now},
+		{Name: "PodCrashLooping", Subject: "pod/default/web-1", StartsAt: now.Add(time.Minute)},
+		{Name: "HighLatency", Subject: "api", StartsAt: now.Add(time.Minute)},
+	}
+	result, err := engine.IngestAlerts(context.Background(), tenantID, alerts)
+	if err != nil {
+		t.Fatalf("Failed to ingest alerts: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 incidents, got %d", len(result))
+	}
+	
+	var grouped incidents.Incident
+	for _, inc := range result {
+		if len(inc.Alerts) == 2 {
+			grouped = inc
+		}
+	}
+	if grouped.ID == "" || grouped.Severity != "critical" {
+		t.Fatalf("Expected a critical incident grouping the node and pod alerts, got %+v", result)
+	}
+	
+	// The correlation rule derived the incident atom and its membership links
+	incidentAtom, err := engine.GetAtom(atomspace.GenerateAtomID(atomspace.ConceptNodeType, incidents.IncidentName(grouped.ID), nil), tenantID)
+	if err != nil {
+		t.Fatalf("Expected an incident atom: %v", err)
+	}
+	members := engine.QueryAtoms(tenantID, func(a atomspace.Atom) bool {
+		link, ok := a.(*atomspace.Link)
+		return ok && a.GetName() == "part_of_incident" && link.GetOutgoing()[2].GetID() == incidentAtom.GetID()
+	})
+	if len(members) != 2 {
+		t.Errorf("Expected 2 part_of_incident links, got %d", len(members))
+	}
+	
+	// The timeline is built from the derivations
+	inc, _ := engine.GetIncident(tenantID, grouped.ID)
+	correlated := 0
+	for _, e := range inc.Timeline {
+		if e.Kind == "correlated" && e.Rule == incidents.CorrelationRuleName {
+			correlated++
+		}
+	}
+	// The incident's inheritance link and the two memberships
+	if correlated != 3 {
+		t.Errorf("Expected 3 correlated timeline entries, got %d: %+v", correlated, inc.Timeline)
+	}
+	
+	if _, err := engine.AcknowledgeIncident(tenantID, grouped.ID, ""); err == nil {
+		t.Error("Expected acknowledgement without a user to fail")
+	}
+	if _, err := engine.AcknowledgeIncident(tenantID, grouped.ID, "alice"); err != nil {
+		t.Fatalf("Failed to acknowledge incident: %v", err)
+	}
+	if _, err := engine.ResolveIncident(tenantID, grouped.ID, "alice", "rebooted n1"); err != nil {
+		t.Fatalf("Failed to resolve incident: %v", err)
+	}
+	incidentAtom, _ = engine.GetAtom(incidentAtom.GetID(), tenantID)
+	if incidentAtom.GetTruthValue().Strength != 0 {
+		t.Errorf("Expected a resolved incident atom to have zero strength, got %v", incidentAtom.GetTruthValue())
+	}
+	if open := engine.ListIncidents(tenantID, incidents.StatusOpen); len(open) != 1 {
+		t.Errorf("Expected 1 open incident, got %d", len(open))
+	}
+}
+
+func TestRunbookExecution(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.URL.Path)
+		mu.Unlock()
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer webhook.Close()
+	
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	
+	_, err := engine.LoadRunbooks(tenantID, []byte(`[
+		{"name":"crashloop","mode":"execute","match":{"alert_names":["PodCrashLooping"]},"steps":[
+			{"name":"restart","action":{"type":"webhook","url":"`+webhook.URL+`/fail"}},
+			{"name":"scale","action":{"type":"webhook","url":"`+webhook.URL+`/scale"}},
+			{"name":"page","when":"on_failure","action":{"type":"webhook","url":"`+webhook.URL+`/page"}},
+			{"name":"drain","when":"always","action":{"type":"webhook","url":"`+webhook.URL+`/drain"},
+			 "conditions":[{"predicate":"error_budget_remaining","min":0.5}]}]},
+		{"name":"latency","match":{"alert_names":["HighLatency"]},"steps":[
+			{"action":{"type":"webhook","url":"`+webhook.URL+`/latency"}}]}]`))
+	if err != nil {
+		t.Fatalf("Failed to load runbooks: %v", err)
+	}
+	if _, err := engine.LoadRunbooks(tenantID, []byte(`{"name":"bad","steps":[]}`)); err == nil {
+		t.Error("Expected a runbook without steps to be rejected")
+	}
+	
+	result, err := engine.IngestAlerts(context.Background(), tenantID, []incidents.Alert{
+		{Name: "PodCrashLooping", Subject: "pod/default/web-1"},
+		{Name: "HighLatency", Subject: "api"},
+	})
+	if err != nil || len(result) != 2 {
+		t.Fatalf("Expected 2 incidents, got %d: %v", len(result), err)
+	}
+	
+	runs, err := engine.ApplyRunbooks(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Failed to apply runbooks: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("Expected 2 runs, got %d", len(runs))
+	}
+	var crashloop, latency runbooks.Run
+	for _, run := range runs {
+		if run.Runbook == "crashloop" {
+			crashloop = run
+		} else {
+			latency = run
+		}
+	}
+	
+	// restart fails, so scale is skipped and page runs; drain lacks an
+	// error budget relation for the pod
+	want := []runbooks.Outcome{runbooks.OutcomeFailed, runbooks.OutcomeSkipped, runbooks.OutcomeSucceeded, runbooks.OutcomeSkipped}
+	if crashloop.Outcome != runbooks.OutcomeFailed || len(crashloop.Steps) != len(want) {
+		t.Fatalf("Unexpected crashloop run: %+v", crashloop)
+	}
+	for i, outcome := range want {
+		if crashloop.Steps[i].Outcome != outcome {
+			t.Errorf("Step %s: expected %s, got %s", crashloop.Steps[i].Step, outcome, crashloop.Steps[i].Outcome)
+		}
+	}
+	if latency.Outcome != runbooks.OutcomeProposed {
+		t.Errorf("Expected the latency runbook to be proposed, got %s", latency.Outcome)
+	}
+	mu.Lock()
+	if got := strings.Join(calls, ","); got != "/fail,/page" {
+		t.Errorf("Unexpected webhook calls: %s", got)
+	}
+	mu.Unlock()
+	
+	// Step results are recorded as atoms and on the timeline
+	stepResults := engine.QueryAtoms(tenantID, func(a atomspace.Atom) bool {
+		return a.GetType() == atomspace.EvaluationLinkType && a.GetName() == agents.StepResultPredicate
+	})
+	if len(stepResults) != 5 {
+		t.Errorf("Expected 5 step_result links, got %d", len(stepResults))
+	}
+	steps := engine.QueryAtoms(tenantID, func(a atomspace.Atom) bool {
+		return a.GetType() == atomspace.ExecutionLinkType
+	})
+	if len(steps) != 5 {
+		t.Errorf("Expected 5 runbook step ExecutionLinks, got %d", len(steps))
+	}
+	inc, _ := engine.GetIncident(tenantID, latency.IncidentID)
+	if last := inc.Timeline[len(inc.Timeline)-1]; last.Kind != "runbook_step" {
+		t.Errorf("Expected a runbook_step timeline entry, got %+v", last)
+	}
+	
+	// Runbooks are applied to an incident once; a proposal can be executed
+	if again, _ := engine.ApplyRunbooks(context.Background(), tenantID); len(again) != 0 {
+		t.Errorf("Expected no new runs, got %d", len(again))
+	}
+	run, err := engine.ExecuteRunbook(context.Background(), tenantID, "latency", latency.IncidentID)
+	if err != nil {
+		t.Fatalf("Failed to execute runbook: %v", err)
+	}
+	if run.Outcome != runbooks.OutcomeSucceeded {
+		t.Errorf("Expected the executed proposal to succeed, got %+v", run)
+	}
+	if history := engine.GetRunbookRuns(tenantID); len(history) != 3 {
+		t.Errorf("Expected 3 runs in the history, got %d", len(history))
+	}
+}
+
+func TestTerraformStateSync(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	writeState := func(serial int, withDB bool) {
+		resources := `{"mode":"managed","type":"aws_vpc","name":"main","provider":"provider[\"registry.terraform.io/hashicorp/aws\"]",
+			"instances":[{"attributes":{"id":"vpc-1"}}]},
+			{"mode":"managed","type":"aws_instance","name":"web","provider":"provider[\"registry.terraform.io/hashicorp/aws\"]",
+			"instances":[{"index_key":0,"attributes":{"id":"i-1","instance_type":"t3.micro"},"dependencies":["aws_vpc.main"]}]}`
+		if withDB {
+			resources += `,{"mode":"managed","type":"aws_db_instance","name":"db","provider":"provider[\"registry.terraform.io/hashicorp/aws\"]",
+			"instances":[{"attributes":{"id":"db-1"},"dependencies":["aws_vpc.main"]}]}`
+		}
+		data := fmt.Sprintf(`{"version":4,"serial":%d,"lineage":"l1","resources":[%s]}`, serial, resources)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatalf("Failed to write state: %v", err)
+		}
+	}
+	writeState(1, true)
+	
+	if _, err := engine.SetTerraformSource(tenantID, terraform.Source{Name: "prod"}); err == nil {
+		t.Error("Expected a source without a location to be rejected")
+	}
+	if _, err := engine.SetTerraformSource(tenantID, terraform.Source{Name: "prod", Path: path}); err != nil {
+		t.Fatalf("Failed to set source: %v", err)
+	}
+	statuses, err := engine.SyncTerraform(context.Background(), tenantID, false)
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Revision != "l1/1" || len(statuses[0].Resources) != 3 {
+		t.Fatalf("Unexpected status: %+v", statuses)
+	}
+	
+	concept := func(name string) atomspace.Atom {
+		atom, err := engine.GetAtom(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), tenantID)
+		if err != nil {
+			t.Fatalf("Expected concept %s: %v", name, err)
+		}
+		return atom
+	}
+	web := concept("tf:aws_instance.web[0]")
+	vpc := concept("tf:aws_vpc.main")
+	pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, "depends_on", nil), "depends_on", tenantID, atomspace.PredicateNodeType)
+	if _, err := engine.GetAtom(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, "depends_on", []atomspace.Atom{pred, web, vpc}), tenantID); err != nil {
+		t.Errorf("Expected depends_on(web, vpc): %v", err)
+	}
+	declares := engine.QueryAtoms(tenantID, func(a atomspace.Atom) bool {
+		return a.GetType() == atomspace.EvaluationLinkType && a.GetName() == agents.DeclaresPredicate
+	})
+	if len(declares) != 4 {
+		t.Errorf("Expected 4 declares links, got %d", len(declares))
+	}
+	
+	// An unchanged state is not rewritten
+	changedAt := statuses[0].ChangedAt
+	statuses, _ = engine.SyncTerraform(context.Background(), tenantID, false)
+	if !statuses[0].ChangedAt.Equal(changedAt) {
+		t.Error("Expected an unchanged state to be skipped")
+	}
+	
+	// A resource leaving the state is retired
+	writeState(2, false)
+	statuses, err = engine.SyncTerraform(context.Background(), tenantID, false)
+	if err != nil || statuses[0].Revision != "l1/2" {
+		t.Fatalf("Expected the new revision to be synced, got %+v: %v", statuses, err)
+	}
+	if db := concept("tf:aws_db_instance.db"); db.GetTruthValue().Strength != 0 {
+		t.Errorf("Expected the removed resource to have zero strength, got %v", db.GetTruthValue())
+	}
+	if concept("tf:aws_vpc.main").GetTruthValue().Strength != 1 {
+		t.Error("Expected remaining resources to keep their strength")
+	}
+	
+	if err := engine.RemoveTerraformSource(tenantID, "prod"); err != nil {
+		t.Fatalf("Failed to remove source: %v", err)
+	}
+	if statuses, _ := engine.SyncTerraform(context.Background(), tenantID, false); len(statuses) != 0 {
+		t.Errorf("Expected no sources after removal, got %d", len(statuses))
+	}
+}
+
+func TestDriftDetection(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	state := `{"version":4,"serial":1,"lineage":"l1","resources":[
+		{"mode":"managed","type":"aws_vpc","name":"main","provider":"provider[\"registry.terraform.io/hashicorp/aws\"]",
+		"instances":[{"attributes":{"id":"vpc-1"}}]},
+		{"mode":"managed","type":"aws_instance","name":"web","provider":"provider[\"registry.terraform.io/hashicorp/aws\"]",
+		"instances":[{"attributes":{"id":"i-1","instance_type":"t3.micro"}}]}]}`
+	if err := os.WriteFile(path, []byte(state), 0o600); err != nil {
+		t.Fatalf("Failed to write state: %v", err)
+	}
+	if _, err := engine.SetTerraformSource(tenantID, terraform.Source{Name: "prod", Path: path}); err != nil {
+		t.Fatalf("Failed to set source: %v", err)
+	}
+	if _, err := engine.SyncTerraform(context.Background(), tenantID, false); err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	
+	if err := engine.RecordObservations(tenantID, "ec2:i-1", "Instance", map[string]string{"id": "i-1", "instance_type": "t3.large"}); err != nil {
+		t.Fatalf("Failed to record observations: %v", err)
+	}
+	if err := engine.RecordObservations(tenantID, "ec2:i-9", "Instance", map[string]string{"id": "i-9"}); err != nil {
+		t.Fatalf("Failed to record observations: %v", err)
+	}
+	if err := engine.RecordObservations(tenantID, "", "", map[string]string{"id": "x"}); err == nil {
+		t.Error("Expected observations without a subject to be rejected")
+	}
+	
+	if _, err := engine.GetDrift(tenantID); err == nil {
+		t.Error("Expected drift to be unavailable before detection is enabled")
+	}
+	findings, err := engine.DetectDrift(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Failed to detect drift: %v", err)
+	}
+	// The VPC is not observed, but no VPC is, so it is not reported missing
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %+v", findings)
+	}
+	mismatch := findings[0]
+	if mismatch.Kind != drift.KindAttribute || mismatch.Attribute != "instance_type" ||
+		mismatch.Declared != "tf:aws_instance.web" || mismatch.Observed != "ec2:i-1" ||
+		mismatch.DeclaredValue != "t3.micro" || mismatch.ObservedValue != "t3.large" {
+		t.Errorf("Unexpected mismatch: %+v", mismatch)
+	}
+	if findings[1].Kind != drift.KindUnmanaged || findings[1].Observed != "ec2:i-9" {
+		t.Errorf("Expected ec2:i-9 to be unmanaged, got %+v", findings[1])
+	}
+	
+	link, err := engine.GetAtom(mismatch.LinkID, tenantID)
+	if err != nil {
+		t.Fatalf("Expected the drift link: %v", err)
+	}
+	if link.GetName() != agents.DriftPredicate || link.GetTruthValue().Strength != mismatch.Severity {
+		t.Errorf("Unexpected drift link: %s %v", link.GetName(), link.GetTruthValue())
+	}
+	
+	// Once the instance type is reconciled the mismatch is retired
+	if err := engine.RecordObservations(tenantID, "ec2:i-1", "Instance", map[string]string{"instance_type": "t3.micro"}); err != nil {
+		t.Fatalf("Failed to record observations: %v", err)
+	}
+	if findings, _ = engine.DetectDrift(context.Background(), tenantID); len(findings) != 1 {
+		t.Fatalf("Expected 1 finding after reconciling, got %+v", findings)
+	}
+	if link, _ := engine.GetAtom(mismatch.LinkID, tenantID); link.GetTruthValue().Strength != 0 {
+		t.Errorf("Expected the retired drift link to have zero strength, got %v", link.GetTruthValue())
+	}
+	if current, _ := engine.GetDrift(tenantID); len(current) != 1 {
+		t.Errorf("Expected GetDrift to return the latest findings, got %d", len(current))
+	}
+}
+
+func TestTraceDependencies(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	now := time.Now()
+	spans := []traces.Span{
+		{TraceID: "t1", SpanID: "a", Service: "frontend", Kind: traces.SpanKindServer, Start: now, End: now.Add(300 * time.Millisecond)},
+		{TraceID: "t1", SpanID: "b", ParentID: "a", Service: "frontend", Kind: traces.SpanKindClient, Start: now, End: now.Add(200 * time.Millisecond)},
+		{TraceID: "t1", SpanID: "c", ParentID: "b", Service: "cart", Kind: traces.SpanKindServer, Start: now, End: now.Add(100 * time.Millisecond)},
+	}
+	if _, err := engine.IngestTraces(tenantID, spans); err == nil {
+		t.Error("Expected traces of an uninitialized tenant to be rejected")
+	}
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	
+	deps, err := engine.IngestTraces(tenantID, spans)
+	if err != nil {
+		t.Fatalf("Failed to ingest traces: %v", err)
+	}
+	if len(deps) != 1 || deps[0].Caller != "frontend" || deps[0].Callee != "cart" || deps[0].MeanLatencyMs != 100 {
+		t.Fatalf("Expected frontend -> cart, got %+v", deps)
+	}
+	
+	frontend, err := engine.GetAtom(atomspace.GenerateAtomID(atomspace.ConceptNodeType, "service/frontend", nil), tenantID)
+	if err != nil {
+		t.Fatalf("Expected the frontend service concept: %v", err)
+	}
+	cart, err := engine.GetAtom(atomspace.GenerateAtomID(atomspace.ConceptNodeType, "service/cart", nil), tenantID)
+	if err != nil {
+		t.Fatalf("Expected the cart service concept: %v", err)
+	}
+	pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, traces.CallsPredicate, nil), traces.CallsPredicate, tenantID, atomspace.PredicateNodeType)
+	link, err := engine.GetAtom(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, traces.CallsPredicate, []atomspace.Atom{pred, frontend, cart}), tenantID)
+	if err != nil {
+		t.Fatalf("Expected calls(frontend, cart): %v", err)
+	}
+	if tv := link.GetTruthValue(); tv.Strength != 1 || tv.Confidence <= 0 {
+		t.Errorf("Unexpected calls truth value: %v", tv)
+	}
+	
+	_, latency, _ := traces.SeriesNames(deps[0].Edge)
+	if samples := engine.GetSamples(tenantID, latency); len(samples) != 1 || samples[0].Value != 100 {
+		t.Errorf("Expected one latency sample of 100ms, got %+v", samples)
+	}
+	if current := engine.GetDependencies(tenantID); len(current) != 1 || current[0].Calls != 1 {
+		t.Errorf("Expected the dependency to be listed, got %+v", current)
+	}
+}
+
+func TestImpactAnalysis(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	
+	// cart runs on n1 and is called by the frontend
+	pod, _ := engine.CreateConceptNode("service/cart", tenantID)
+	node, _ := engine.CreateConceptNode("node/n1", tenantID)
+	pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, "runs_on", nil), "runs_on", tenantID, atomspace.PredicateNodeType)
+	engine.AddAtom(pred)
+	outgoing := []atomspace.Atom{pred, pod, node}
+	engine.AddAtom(atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, "runs_on", outgoing), "runs_on", tenantID, atomspace.EvaluationLinkType, outgoing))
+	
+	now := time.Now()
+	spans := []traces.Span{
+		{TraceID: "t1", SpanID: "a", Service: "frontend", Start: now, End: now},
+		{TraceID: "t1", SpanID: "b", ParentID: "a", Service: "cart", Start: now, End: now},
+	}
+	if _, err := engine.IngestTraces(tenantID, spans); err != nil {
+		t.Fatalf("Failed to ingest traces: %v", err)
+	}
+	
+	result, err := engine.AnalyzeImpact(tenantID, node.GetID(), impact.DefaultOptions())
+	if err != nil {
+		t.Fatalf("Failed to analyze impact: %v", err)
+	}
+	if result.Source != "node/n1" || len(result.Impacted) != 2 {
+		t.Fatalf("Expected cart and frontend to be impacted by node/n1, got %+v", result)
+	}
+	if result.Impacted[0].Name != "service/cart" || result.Impacted[1].Name != "service/frontend" {
+		t.Errorf("Unexpected ranking: %+v", result.Impacted)
+	}
+	if last := result.Impacted[1].Path; len(last) != 2 || last[1].Relation != traces.CallsPredicate {
+		t.Errorf("Expected the frontend to be reached over calls, got %+v", last)
+	}
+	
+	opts := impact.DefaultOptions()
+	opts.Depth = 1
+	if result, _ := engine.AnalyzeImpact(tenantID, "node/n1", opts); len(result.Impacted) != 1 {
+		t.Errorf("Expected only cart within one hop, got %+v", result.Impacted)
+	}
+	if _, err := engine.AnalyzeImpact(tenantID, "node/missing", opts); err == nil {
+		t.Error("Expected an unknown atom to fail")
+	}
+	opts.Depth = 0
+	if _, err := engine.AnalyzeImpact(tenantID, "node/n1", opts); err == nil {
+		t.Error("Expected invalid options to be rejected")
+	}
+}
+
+func TestWhatIfSimulation(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	
+	// cart runs on n1 and is called by the frontend, which has an SLO
+	cart, _ := engine.CreateConceptNode("service/cart", tenantID)
+	node, _ := engine.CreateConceptNode("node/n1", tenantID)
+	pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, "runs_on", nil), "runs_on", tenantID, atomspace.PredicateNodeType)
+	engine.AddAtom(pred)
+	outgoing := []atomspace.Atom{pred, cart, node}
+	runsOn := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, "runs_on", outgoing), "runs_on", tenantID, atomspace.EvaluationLinkType, outgoing)
+	engine.AddAtom(runsOn)
+	
+	now := time.Now()
+	spans := []traces.Span{
+		{TraceID: "t1", SpanID: "a", Service: "frontend", Start: now, End: now},
+		{TraceID: "t1", SpanID: "b", ParentID: "a", Service: "cart", Start: now, End: now},
+	}
+	if _, err := engine.IngestTraces(tenantID, spans); err != nil {
+		t.Fatalf("Failed to ingest traces: %v", err)
+	}
+	if _, err := engine.SetSLO(tenantID, slo.SLO{Name: "frontend-availability", Service: "service/frontend", Series: "frontend_sli", Target: 0.99}); err != nil {
+		t.Fatalf("Failed to set SLO: %v", err)
+	}
+	before := len(engine.QueryAtoms(tenantID, nil))
+	
+	req := whatif.DefaultRequest()
+	req.Changes = []whatif.Change{{Type: whatif.ChangeRemove, Target: "node/n1"}}
+	req.Rules = []string{"deduction"}
+	report, err := engine.Simulate(context.Background(), tenantID, req)
+	if err != nil {
+		t.Fatalf("Simulation failed: %v", err)
+	}
+	if len(report.AtRiskServices) != 2 || report.AtRiskServices[0].Name != "service/cart" || report.AtRiskServices[1].Name != "service/frontend" {
+		t.Errorf("Expected cart and frontend to be at risk, got %+v", report.AtRiskServices)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].SLO != "frontend-availability" {
+		t.Errorf("Expected the frontend SLO to be violated, got %+v", report.Violations)
+	}
+	if len(report.RemovedAtomIDs) != 2 {
+		t.Errorf("Expected the node and runs_on to be removed, got %v", report.RemovedAtomIDs)
+	}
+	
+	// The tenant's space is untouched
+	if _, err := engine.GetAtom(runsOn.GetID(), tenantID); err != nil {
+		t.Errorf("Expected runs_on to survive the simulation: %v", err)
+	}
+	if after := len(engine.QueryAtoms(tenantID, nil)); after != before {
+		t.Errorf("Expected %d atoms after the simulation, got %d", before, after)
+	}
+	
+	// Scaling cart to 0 puts only its callers at risk
+	zero := 0
+	req = whatif.DefaultRequest()
+	req.Changes = []whatif.Change{{Type: whatif.ChangeScale, Target: "service/cart", Replicas: &zero}}
+	report, err = engine.Simulate(context.Background(), tenantID, req)
+	if err != nil {
+		t.Fatalf("Simulation failed: %v", err)
+	}
+	if len(report.AtRisk) != 1 || report.AtRisk[0].Name != "service/frontend" || len(report.ModifiedAtoms) != 1 {
+		t.Errorf("Expected the frontend at risk and cart modified, got %+v / %d", report.AtRisk, len(report.ModifiedAtoms))
+	}
+	if cart, _ := engine.GetAtom(cart.GetID(), tenantID); cart.GetTruthValue().Strength != 1 {
+		t.Errorf("Expected the live cart concept to keep its strength, got %v", cart.GetTruthValue())
+	}
+	
+	req.Changes[0].Target = "service/missing"
+	if _, err := engine.Simulate(context.Background(), tenantID, req); err == nil {
+		t.Error("Expected an unknown target to fail")
+	}
+}
+
+func TestScheduledReports(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	
+	cat, _ := engine.CreateConceptNode("Cat", tenantID)
+	mammal, _ := engine.CreateConceptNode("Mammal", tenantID)
+	animal, _ := engine.CreateConceptNode("Animal", tenantID)
+	engine.CreateInheritanceLink(cat.GetID(), mammal.GetID(), tenantID)
+	engine.CreateInheritanceLink(mammal.GetID(), animal.GetID(), tenantID)
+	derived, err := engine.RunInference(context.Background(), tenantID, 5)
+	if err != nil {
+		t.Fatalf("Failed to run inference: %v", err)
+	}
+	
+	anomaly, _ := engine.CreateConceptNode("anomaly:cpu:node/n1", tenantID)
+	anomaly.SetTruthValue(atomspace.TruthValue{Strength: 0.8, Confidence: 0.9})
+	category, _ := engine.CreateConceptNode(pipeline.AnomalyConcept, tenantID)
+	engine.CreateInheritanceLink(anomaly.GetID(), category.GetID(), tenantID)
+	
+	var delivered []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		delivered = append(delivered, r.Header.Get("Content-Type"))
+		mu.Unlock()
+	}))
+	defer server.Close()
+	
+	if _, err := engine.SetReport(tenantID, reports.Schedule{Name: "daily", Format: "pdf"}); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+	schedule, err := engine.SetReport(tenantID, reports.Schedule{
+		Name:   "daily",
+		Format: reports.FormatHTML,
+		Sinks:  []reports.Sink{{Type: reports.SinkWebhook, URL: server.URL}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set report: %v", err)
+	}
+	if len(schedule.Sections) != len(reports.AllSections()) || schedule.Interval != reports.DefaultInterval {
+		t.Errorf("Expected a daily digest of every section, got %+v", schedule)
+	}
+	
+	report, err := engine.GenerateReport(context.Background(), tenantID, "daily")
+	if err != nil {
+		t.Fatalf("Failed to generate report: %v", err)
+	}
+	d := report.Digest
+	if report.Sequence != 1 || d.To.Sub(d.From) != reports.DefaultInterval {
+		t.Errorf("Expected the first report to cover one interval, got %+v", report)
+	}
+	if d.Inferences == nil || d.Inferences.Count != len(derived) || (len(derived) > 0 && d.Inferences.ByRule["deduction"] == 0) {
+		t.Errorf("Expected the %d derived atoms to be reported, got %+v", len(derived), d.Inferences)
+	}
+	if d.Anomalies == nil || d.Anomalies.Active != 1 || d.Anomalies.New != 1 || d.Anomalies.Items[0].Name != "anomaly:cpu:node/n1" {
+		t.Errorf("Expected the anomaly to be reported, got %+v", d.Anomalies)
+	}
+	if d.Drift != nil || d.Cost != nil || len(d.Notes) != 2 {
+		t.Errorf("Expected drift and cost to be noted as unavailable, got %+v", d.Notes)
+	}
+	if d.Agents == nil || len(d.Agents.Agents) < 2 {
+		t.Errorf("Expected the mind and report agents to be reported, got %+v", d.Agents)
+	}
+	if !strings.Contains(report.Content, "<h2>Anomalies</h2>") {
+		t.Errorf("Expected an HTML rendering, got %s", report.Content)
+	}
+	if len(report.Deliveries) != 1 || report.Deliveries[0].Error != "" {
+		t.Errorf("Expected the report to be delivered, got %+v", report.Deliveries)
+	}
+	mu.Lock()
+	if len(delivered) != 1 || delivered[0] != reports.FormatHTML.ContentType() {
+		t.Errorf("Expected the webhook to receive HTML, got %v", delivered)
+	}
+	mu.Unlock()
+	
+	// The next report covers the period since the previous one
+	if _, err := engine.DetectDrift(context.Background(), tenantID); err != nil {
+		t.Fatalf("Failed to detect drift: %v", err)
+	}
+	next, err := engine.GenerateReport(context.Background(), tenantID, "daily")
+	if err != nil {
+		t.Fatalf("Failed to generate report: %v", err)
+	}
+	if next.Sequence != 2 || !next.Digest.From.Equal(d.To) {
+		t.Errorf("Expected the second report to start where the first ended, got %+v", next.Digest)
+	}
+	if next.Digest.Inferences.Count != 0 || next.Digest.Anomalies.New != 0 || next.Digest.Drift == nil {
+		t.Errorf("Expected no new inferences or anomalies and a drift section, got %+v", next.Digest)
+	}
+	
+	history, err := engine.GetReportHistory(tenantID, "daily")
+	if err != nil || len(history) != 2 {
+		t.Errorf("Expected 2 reports in the history, got %d: %v", len(history), err)
+	}
+	latest, err := engine.GetGeneratedReport(tenantID, "daily", 0)
+	if err != nil || latest.Sequence != 2 {
+		t.Errorf("Expected the latest report, got %+v: %v", latest, err)
+	}
+	if _, err := engine.GenerateReport(context.Background(), tenantID, "weekly"); err == nil {
+		t.Error("Expected an unknown report to fail")
+	}
+	
+	if err := engine.DeleteReport(tenantID, "daily"); err != nil {
+		t.Fatalf("Failed to delete report: %v", err)
+	}
+	if _, err := engine.GetGeneratedReport(tenantID, "daily", 1); err == nil {
+		t.Error("Expected the reports to be deleted with the schedule")
+	}
+	if err := engine.DisableReports(tenantID); err != nil {
+		t.Errorf("Failed to disable reports: %v", err)
+	}
+}
+
+func TestRuleSelection(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	config, _, err := engine.GetRuleSelection(tenantID)
+	if err != nil || config.Strategy != inference.StrategyAll {
+		t.Fatalf("Expected every rule to fire by default, got %+v: %v", config, err)
+	}
+	
+	config = inference.DefaultSelectionConfig()
+	config.Strategy = "random"
+	if err := engine.SetRuleSelection(tenantID, config); err == nil {
+		t.Error("Expected an unknown strategy to be rejected")
+	}
+	config.Strategy = inference.StrategyUCB
+	if err := engine.SetRuleSelection("missing", config); err == nil {
+		t.Error("Expected an uninitialized tenant to be rejected")
+	}
+	if err := engine.SetRuleSelection(tenantID, config); err != nil {
+		t.Fatalf("Failed to set rule selection: %v", err)
+	}
+	
+	cat, _ := engine.CreateConceptNode("Cat", tenantID)
+	mammal, _ := engine.CreateConceptNode("Mammal", tenantID)
+	animal, _ := engine.CreateConceptNode("Animal", tenantID)
+	engine.CreateInheritanceLink(cat.GetID(), mammal.GetID(), tenantID)
+	engine.CreateInheritanceLink(mammal.GetID(), animal.GetID(), tenantID)
+	
+	derived, err := engine.RunInference(context.Background(), tenantID, 10)
+	if err != nil {
+		t.Fatalf("Failed to run inference: %v", err)
+	}
+	config, yields, _ := engine.GetRuleSelection(tenantID)
+	if config.Strategy != inference.StrategyUCB {
+		t.Errorf("Expected the UCB strategy, got %+v", config)
+	}
+	var total int64
+	for _, y := range yields {
+		total += y.Total
+	}
+	if len(yields) == 0 || total != int64(len(derived)) || yields["deduction"].Total == 0 {
+		t.Errorf("Expected the yields to account for the %d derived atoms, got %+v", len(derived), yields)
+	}
+}
+
+func TestInferencePipelining(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PipelinedInference = true
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	if pipelined, _, err := engine.GetInferencePipelining(tenantID); err != nil || !pipelined {
+		t.Fatalf("Expected the configured pipelining, got %v: %v", pipelined, err)
+	}
+	if err := engine.SetInferencePipelining("missing", true); err == nil {
+		t.Error("Expected an uninitialized tenant to be rejected")
+	}
+	
+	cat, _ := engine.CreateConceptNode("Cat", tenantID)
+	mammal, _ := engine.CreateConceptNode("Mammal", tenantID)
+	animal, _ := engine.CreateConceptNode("Animal", tenantID)
+	engine.CreateInheritanceLink(cat.GetID(), mammal.GetID(), tenantID)
+	engine.CreateInheritanceLink(mammal.GetID(), animal.GetID(), tenantID)
+	
+	derived, err := engine.RunInference(context.Background(), tenantID, 10)
+	if err != nil {
+		t.Fatalf("Failed to run inference: %v", err)
+	}
+	found := false
+	for _, atom := range derived {
+		link, ok := atom.(*atomspace.Link)
+		if ok && link.GetType() == atomspace.InheritanceLinkType && link.GetOutgoing()[0].GetID() == cat.GetID() && link.GetOutgoing()[1].GetID() == animal.GetID() {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected Cat -> Animal to be derived")
+	}
+	
+	_, merges, _ := engine.GetInferencePipelining(tenantID)
+	if merges.Derived != int64(len(derived)) {
+		t.Errorf("Expected the merge stats to count the %d derived atoms, got %+v", len(derived), merges)
+	}
+	if stats := engine.GetStats(tenantID); stats["inference_merges"] == nil {
+		t.Error("Expected the merge stats in the tenant stats")
+	}
+	
+	if err := engine.SetInferencePipelining(tenantID, false); err != nil {
+		t.Fatalf("Failed to disable pipelining: %v", err)
+	}
+	if pipelined, _, _ := engine.GetInferencePipelining(tenantID); pipelined {
+		t.Error("Expected pipelining to be disabled")
+	}
+}
+
+func TestInferenceFixpointCache(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	if _, err := engine.CreateSharedSpace("k8s", "Kubernetes kinds", ""); err != nil {
+		t.Fatalf("Failed to create shared space: %v", err)
+	}
+	pod, _ := engine.CreateSharedConceptNode("k8s", "Pod")
+	workload, _ := engine.CreateSharedConceptNode("k8s", "Workload")
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	checkout, _ := engine.CreateConceptNode("checkout-pod", tenantID)
+	
+	fixpoint := func() inference.FixpointStats {
+		return engine.GetStats(tenantID)["inference_fixpoint"].(inference.FixpointStats)
+	}
+	
+	ctx := context.Background()
+	engine.RunInference(ctx, tenantID, 10)
+	generation := engine.Generation(tenantID)
+	if derived, _ := engine.RunInference(ctx, tenantID, 10); len(derived) != 0 || fixpoint().Hits != 1 {
+		t.Fatalf("Expected a cached fixpoint, got %d atoms and %+v", len(derived), fixpoint())
+	}
+	
+	// Mounting a space changes the atoms the tenant sees
+	if err := engine.MountSharedSpace(tenantID, "k8s"); err != nil {
+		t.Fatalf("Failed to mount shared space: %v", err)
+	}
+	if engine.Generation(tenantID) == generation {
+		t.Error("Expected a mount to change the generation")
+	}
+	if _, err := engine.CreateInheritanceLink(checkout.GetID(), pod.GetID(), tenantID); err != nil {
+		t.Fatalf("Failed to link tenant atom to shared atom: %v", err)
+	}
+	engine.RunInference(ctx, tenantID, 10)
+	
+	// So do changes to a mounted space
+	engine.CreateSharedInheritanceLink("k8s", pod.GetID(), workload.GetID())
+	derived, _ := engine.RunInference(ctx, tenantID, 10)
+	found := false
+	for _, atom := range derived {
+		if link, ok := atom.(*atomspace.Link); ok && link.GetOutgoing()[0].GetID() == checkout.GetID() && link.GetOutgoing()[1].GetID() == workload.GetID() {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected checkout-pod -> Workload to be derived after the shared space changed")
+	}
+	if stats := fixpoint(); stats.Hits != 1 || stats.Misses != 3 {
+		t.Errorf("Unexpected fixpoint cache stats: %+v", stats)
+	}
+}
+
+func TestAdmissionHooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var review admission.Review
+		json.NewDecoder(r.Body).Decode(&review)
+		
+		switch r.URL.Path {
+		case "/normalize":
+			name := strings.ToLower(review.Atom.Name)
+			json.NewEncoder(w).Encode(admission.Response{Allowed: true, Patch: &admission.Patch{Name: &name}})
+		case "/confidence":
+			allowed := review.Atom.TruthValue.Confidence <= 0.95
+			json.NewEncoder(w).Encode(admission.Response{Allowed: allowed, Reason: "confidence is capped at 0.95"})
+		}
+	}))
+	defer server.Close()
+	
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	if _, err := engine.SetAdmissionHook(tenantID, admission.Hook{Name: "normalize", Type: admission.HookMutating, URL: server.URL + "/normalize", Operations: []admission.Operation{admission.OperationCreate}}); err != nil {
+		t.Fatalf("Failed to set admission hook: %v", err)
+	}
+	engine.SetAdmissionHook(tenantID, admission.Hook{Name: "confidence", Type: admission.HookValidating, URL: server.URL + "/confidence", Operations: []admission.Operation{admission.OperationUpdate}})
+	
+	node, err := engine.CreateConceptNode("Checkout", tenantID)
+	if err != nil {
+		t.Fatalf("Failed to create concept: %v", err)
+	}
+	if node.GetName() != "checkout" {
+		t.Errorf("Expected the concept to be renamed, got %s", node.GetName())
+	}
+	if _, err := engine.GetAtom(atomspace.GenerateAtomID(atomspace.ConceptNodeType, "checkout", nil), tenantID); err != nil {
+		t.Errorf("Expected the renamed concept to be stored: %v", err)
+	}
+	
+	err = engine.UpdateAtom(node.GetID(), tenantID, func(atom atomspace.Atom) error {
+		atom.SetTruthValue(atomspace.TruthValue{Strength: 1, Confidence: 0.99})
+		return nil
+	})
+	if !admission.IsDenied(err) {
+		t.Fatalf("Expected the update to be denied, got %v", err)
+	}
+	stored, _ := engine.GetAtom(node.GetID(), tenantID)
+	if stored.GetTruthValue().Confidence == 0.99 {
+		t.Error("Expected a denied update to leave the atom unchanged")
+	}
+	
+	err = engine.UpdateAtom(node.GetID(), tenantID, func(atom atomspace.Atom) error {
+		atom.SetTruthValue(atomspace.TruthValue{Strength: 1, Confidence: 0.9})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected the update to be admitted: %v", err)
+	}
+	if stored, _ := engine.GetAtom(node.GetID(), tenantID); stored.GetTruthValue().Confidence != 0.9 {
+		t.Errorf("Expected the admitted update to be written, got %+v", stored.GetTruthValue())
+	}
+	
+	if stats := engine.GetStats("")["admission"].(map[string]interface{}); stats["calls"].(int64) != 3 || stats["denied"].(int64) != 1 {
+		t.Errorf("Unexpected admission stats: %+v", stats)
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	existing, _ := engine.CreateConceptNode("existing", tenantID)
+	
+	info, err := engine.ConfigureProvenance(tenantID, provenance.Config{Signing: true, Ledger: true})
+	if err != nil {
+		t.Fatalf("Failed to configure provenance: %v", err)
+	}
+	if info.Signatures != 1 || len(info.Keys) != 1 {
+		t.Errorf("Expected the existing atom to be signed, got %+v", info)
+	}
+	
+	checkout, _ := engine.CreateConceptNode("checkout", tenantID)
+	engine.UpdateAtom(checkout.GetID(), tenantID, func(atom atomspace.Atom) error {
+		atom.SetTruthValue(atomspace.TruthValue{Strength: 0.7, Confidence: 0.9})
+		return nil
+	})
+	engine.DeleteAtom(existing.GetID(), tenantID)
+	
+	entries, _ := engine.GetLedger(tenantID, 0, 0)
+	if len(entries) != 3 || entries[0].Operation != provenance.OperationCreate || entries[2].Operation != provenance.OperationDelete {
+		t.Fatalf("Expected create, update and delete entries, got %+v", entries)
+	}
+	if v, _ := engine.VerifyAtom(tenantID, checkout.GetID()); v.Status != provenance.StatusValid {
+		t.Errorf("Expected the atom to verify, got %+v", v)
+	}
+	
+	// A change that bypasses the engine is detected
+	engine.shardManager.UpdateAtom(checkout.GetID(), tenantID, func(atom atomspace.Atom) error {
+		atom.SetTruthValue(atomspace.TruthValue{Strength: 0.1, Confidence: 0.9})
+		return nil
+	})
+	if v, _ := engine.VerifyAtom(tenantID, checkout.GetID()); v.Status != provenance.StatusTampered {
+		t.Errorf("Expected the atom to be reported as tampered, got %+v", v)
+	}
+	if v, _ := engine.VerifyLedger(tenantID); !v.Valid || v.Entries != 3 {
+		t.Errorf("Expected a valid ledger, got %+v", v)
+	}
+	
+	if _, err := engine.VerifyAtom(tenantID, existing.GetID()); err == nil {
+		t.Error("Expected verifying a deleted atom to fail")
+	}
+	if err := engine.DisableProvenance(tenantID); err != nil {
+		t.Fatalf("Failed to disable provenance: %v", err)
+	}
+	if v, _ := engine.VerifyAtom(tenantID, checkout.GetID()); v.Status != provenance.StatusUnsigned {
+		t.Errorf("Expected no provenance after disabling it, got %+v", v)
+	}
+}
+
+func TestEncryptedSnapshots(t *testing.T) {
+	provider, _ := persistence.NewLocalKeyProvider(bytes.Repeat([]byte{3}, 32), t.TempDir())
+	cfg := DefaultConfig()
+	cfg.KeyProvider = provider
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	engine.CreateConceptNode("payments-api", tenantID)
+	
+	var snapshot bytes.Buffer
+	if err := engine.SnapshotTenant(tenantID, &snapshot); err != nil {
+		t.Fatalf("Failed to snapshot tenant: %v", err)
+	}
+	if bytes.Contains(snapshot.Bytes(), []byte("EREBSNAP")) {
+		t.Fatal("Expected the snapshot to be encrypted")
+	}
+	
+	restored := NewCognitiveEngine(cfg)
+	defer restored.Close()
+	if n, err := restored.RestoreSnapshot(bytes.NewReader(snapshot.Bytes())); err != nil || n != 1 {
+		t.Fatalf("Expected 1 restored atom, got %d: %v", n, err)
+	}
+	plain := NewCognitiveEngine(DefaultConfig())
+	defer plain.Close()
+	if _, err := plain.RestoreSnapshot(bytes.NewReader(snapshot.Bytes())); err == nil {
+		t.Error("Expected an encrypted snapshot to require a key provider")
+	}
+	
+	if err := engine.ShredTenantData(context.Background(), tenantID); err != nil {
+		t.Fatalf("Failed to shred tenant data: %v", err)
+	}
+	if _, err := restored.RestoreSnapshot(bytes.NewReader(snapshot.Bytes())); err == nil {
+		t.Error("Expected a shredded tenant's snapshot to be unreadable")
+	}
+	if err := plain.ShredTenantData(context.Background(), tenantID); err == nil {
+		t.Error("Expected shredding to require a key provider")
+	}
+}
+
+func TestTenantExportAndPurge(t *testing.T) {
+	provider, _ := persistence.NewLocalKeyProvider(bytes.Repeat([]byte{5}, 32), t.TempDir())
+	cfg := DefaultConfig()
+	cfg.KeyProvider = provider
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	if err := engine.InitializeTenant("other-tenant"); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	if _, err := engine.ConfigureProvenance(tenantID, provenance.Config{Signing: true, Ledger: true}); err != nil {
+		t.Fatalf("Failed to configure provenance: %v", err)
+	}
+	engine.CreateConceptNode("payments-api", tenantID)
+	engine.CreateConceptNode("checkout-api", tenantID)
+	engine.CreateConceptNode("inventory-api", "other-tenant")
+	if _, err := engine.SetSLO(tenantID, slo.SLO{Name: "payments-availability", Service: "payments-api", Series: "payments_sli", Target: 0.99}); err != nil {
+		t.Fatalf("Failed to set SLO: %v", err)
+	}
+	if _, err := engine.CreatePipeline("export-pipeline", "Export Pipeline", tenantID); err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	var snapshot bytes.Buffer
+	if err := engine.SnapshotTenant(tenantID, &snapshot); err != nil {
+		t.Fatalf("Failed to snapshot tenant: %v", err)
+	}
+	
+	export, err := engine.ExportTenant(tenantID)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(export.Atoms) != 2 || len(export.SLOs) != 1 || len(export.Pipelines) != 1 || len(export.Audit) != 2 {
+		t.Errorf("Expected 2 atoms, 1 SLO, 1 pipeline and 2 audit entries, got %d, %d, %d and %d",
+			len(export.Atoms), len(export.SLOs), len(export.Pipelines), len(export.Audit))
+	}
+	if _, err := json.Marshal(export); err != nil {
+		t.Errorf("Expected the export to be JSON, got %v", err)
+	}
+	
+	report, err := engine.PurgeTenant(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if !report.Verified || !report.KeysShredded || report.Removed["atoms"] != 2 || report.Removed["pipelines"] != 1 {
+		t.Errorf("Expected a verified purge of 2 atoms and 1 pipeline, got %+v", report)
+	}
+	if len(engine.QueryAtoms("other-tenant", nil)) != 1 {
+		t.Error("Expected other tenants to be unaffected")
+	}
+	if _, err := engine.RestoreSnapshot(bytes.NewReader(snapshot.Bytes())); err == nil {
+		t.Error("Expected the purged tenant's snapshot to be unreadable")
+	}
+	if _, err := engine.ExportTenant(tenantID); err == nil {
+		t.Error("Expected nothing to export after the purge")
+	}
+	
+	// The tenant can be set up again from scratch
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize purged tenant: %v", err)
+	}
+	if atoms := engine.QueryAtoms(tenantID, nil); len(atoms) != 0 {
+		t.Errorf("Expected no atoms after re-initialization, got %d", len(atoms))
+	}
+}
+
+func TestAtomACLs(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	atom, _ := engine.CreateConceptNode("payments-api", tenantID)
+	alice := acl.Principal{User: "alice"}
+	bob := acl.Principal{User: "bob"}
+	
+	if _, err := engine.SetACL(tenantID, acl.KindAtom, "missing", acl.ACL{Owner: "user:alice"}, alice); err == nil {
+		t.Error("Expected an ACL on a missing atom to be rejected")
+	}
+	if _, err := engine.SetACL("other-tenant", acl.KindAtom, atom.GetID(), acl.ACL{Owner: "user:bob"}, bob); err == nil {
+		t.Error("Expected an ACL on another tenant's atom to be rejected")
+	}
+	if _, err := engine.SetACL(tenantID, acl.KindAtom, atom.GetID(), acl.ACL{Owner: "user:alice"}, alice); err != nil {
+		t.Fatalf("Failed to set ACL: %v", err)
+	}
+	if err := engine.AuthorizeWrite(tenantID, acl.KindAtom, atom.GetID(), bob); !acl.IsForbidden(err) {
+		t.Errorf("Expected bob not to change alice's atom, got %v", err)
+	}
+	
+	p, _ := engine.CreatePipeline("curated", "Curated", tenantID)
+	if _, err := engine.SetACL(tenantID, acl.KindPipeline, p.ID, acl.ACL{Owner: "role:sre"}, bob); err != nil {
+		t.Fatalf("Failed to set pipeline ACL: %v", err)
+	}
+	if _, err := engine.SetACL("other-tenant", acl.KindPipeline, p.ID, acl.ACL{Owner: "user:bob"}, bob); err == nil {
+		t.Error("Expected an ACL on another tenant's pipeline to be rejected")
+	}
+	
+	// Deleted atoms lose their ACL, so a new atom with the same ID is unprotected
+	if err := engine.DeleteAtom(atom.GetID(), tenantID); err != nil {
+		t.Fatalf("Failed to delete atom: %v", err)
+	}
+	if _, err := engine.GetACL(tenantID, acl.KindAtom, atom.GetID()); err == nil {
+		t.Error("Expected the ACL of a deleted atom to be removed")
+	}
+}
+
+func TestQueryAtomsAsOf(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	db, _ := engine.CreateConceptNode("orders-db", tenantID)
+	cache, _ := engine.CreateConceptNode("orders-cache", tenantID)
+	time.Sleep(time.Millisecond)
+	decided := time.Now()
+	time.Sleep(time.Millisecond)
+	
+	engine.UpdateAtom(db.GetID(), tenantID, func(atom atomspace.Atom) error {
+		atom.SetTruthValue(atomspace.TruthValue{Strength: 0.1, Confidence: 0.9})
+		return nil
+	})
+	engine.DeleteAtom(cache.GetID(), tenantID)
+	engine.CreateConceptNode("orders-queue", tenantID)
+	
+	atoms, err := engine.QueryAtomsAsOf(tenantID, decided, nil)
+	if err != nil {
+		t.Fatalf("QueryAtomsAsOf failed: %v", err)
+	}
+	byName := make(map[string]atomspace.Atom)
+	for _, atom := range atoms {
+		byName[atom.GetName()] = atom
+	}
+	if len(byName) != 2 || byName["orders-cache"] == nil {
+		t.Errorf("Expected the atoms held before the changes, got %v", byName)
+	}
+	if db, ok := byName["orders-db"]; !ok || db.GetTruthValue().Strength == 0.1 {
+		t.Error("Expected the truth value held before the update")
+	}
+	
+	atoms, _ = engine.QueryAtomsAsOf(tenantID, time.Now(), nil)
+	if len(atoms) != 2 {
+		t.Errorf("Expected the current atoms at the current time, got %d", len(atoms))
+	}
+	if _, err := engine.QueryAtomsAsOf(tenantID, decided.Add(-48*time.Hour), nil); err == nil {
+		t.Error("Expected a time before the retained history to be refused")
+	}
+}
+
+func TestDiffAtoms(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	db, _ := engine.CreateConceptNode("billing-db", tenantID)
+	cache, _ := engine.CreateConceptNode("billing-cache", tenantID)
+	time.Sleep(time.Millisecond)
+	from := time.Now()
+	time.Sleep(time.Millisecond)
+	
+	engine.UpdateAtom(db.GetID(), tenantID, func(atom atomspace.Atom) error {
+		atom.SetTruthValue(atomspace.TruthValue{Strength: 0.4, Confidence: 0.9})
+		return nil
+	})
+	engine.DeleteAtom(cache.GetID(), tenantID)
+	engine.CreateConceptNode("billing-queue", tenantID)
+	
+	d, err := engine.DiffAtoms(tenantID, from, time.Now())
+	if err != nil {
+		t.Fatalf("DiffAtoms failed: %v", err)
+	}
+	if len(d.Added) != 1 || d.Added[0].Name != "billing-queue" {
+		t.Errorf("Expected billing-queue added, got %+v", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].Name != "billing-cache" {
+		t.Errorf("Expected billing-cache removed, got %+v", d.Removed)
+	}
+	if len(d.Changed) != 1 || d.Changed[0].After["strength"] != 0.4 {
+		t.Errorf("Expected billing-db changed, got %+v", d.Changed)
+	}
+	if _, err := engine.DiffAtoms(tenantID, time.Now(), from); err == nil {
+		t.Error("Expected from after to to be rejected")
+	}
+	
+	engine.CreateSharedSpace("ontology", "Ontology", "")
+	engine.CreateSharedConceptNode("ontology", "service")
+	d, err = engine.DiffSharedSpace(tenantID, "ontology")
+	if err != nil {
+		t.Fatalf("DiffSharedSpace failed: %v", err)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].Name != "service" || len(d.Added) != 2 {
+		t.Errorf("Expected the ontology's and the tenant's own atoms, got %+v", d)
+	}
+	if _, err := engine.DiffSharedSpace(tenantID, "missing"); err == nil {
+		t.Error("Expected a missing shared space to be rejected")
+	}
+}
+
+func TestValueLogs(t *testing.T) {
+	config := DefaultConfig()
+	config.ValueLog.Dir = t.TempDir()
+	config.ValueLog.Interval = 10 * time.Millisecond
+	config.ValueLog.LossWindow = 10 * time.Millisecond
+	
+	engine := NewCognitiveEngine(config)
+	tenantID := "test-tenant"
+	atom, _ := engine.CreateConceptNode("ledger-db", tenantID)
+	var snapshot bytes.Buffer
+	if err := engine.SnapshotTenant(tenantID, &snapshot); err != nil {
+		t.Fatalf("Failed to snapshot: %v", err)
+	}
+	
+	// Agents change attention values in place, without a snapshot
+	time.Sleep(time.Millisecond)
+	atom.SetAttentionValue(atomspace.AttentionValue{STI: 77, LTI: 3})
+	if result := engine.CheckValueLogs(); result.Status != health.StatusOK {
+		t.Errorf("Expected value logs to be healthy, got %+v", result)
+	}
+	engine.Close()
+	
+	engine = NewCognitiveEngine(config)
+	defer engine.Close()
+	if _, err := engine.RestoreSnapshot(&snapshot); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	restored, err := engine.GetAtom(atom.GetID(), tenantID)
+	if err != nil {
+		t.Fatalf("Failed to get restored atom: %v", err)
+	}
+	if av := restored.GetAttentionValue(); av.STI != 77 || av.LTI != 3 {
+		t.Errorf("Expected the logged attention value replayed, got %+v", av)
+	}
+	
+	report, _ := engine.PurgeTenant(context.Background(), tenantID)
+	if report.Removed["logged_values"] != 1 || !report.Verified {
+		t.Errorf("Expected logged values purged, got %+v", report)
+	}
+}
+
+func TestEncryptedValueLogs(t *testing.T) {
+	provider, _ := persistence.NewLocalKeyProvider(bytes.Repeat([]byte{4}, 32), t.TempDir())
+	config := DefaultConfig()
+	config.KeyProvider = provider
+	config.ValueLog.Dir = t.TempDir()
+	
+	engine := NewCognitiveEngine(config)
+	tenantID := "test-tenant"
+	atom, _ := engine.CreateConceptNode("ledger-db", tenantID)
+	atom.SetAttentionValue(atomspace.AttentionValue{STI: 77})
+	engine.Close()
+	
+	// Logged values are sealed like snapshots
+	paths, _ := filepath.Glob(filepath.Join(config.ValueLog.Dir, "*.vlog"))
+	if len(paths) == 0 {
+		t.Fatal("Expected value logs to be written")
+	}
+	for _, path := range paths {
+		data, _ := os.ReadFile(path)
+		if bytes.Contains(data, []byte(atom.GetID())) {
+			t.Errorf("Expected %s to hold no plaintext values", path)
+		}
+	}
+	
+	engine = NewCognitiveEngine(config)
+	defer engine.Close()
+	if result := engine.CheckValueLogs(); result.Status != health.StatusOK {
+		t.Errorf("Expected sealed value logs to reopen, got %+v", result)
+	}
+	if n := engine.valueLogs.count(tenantID); n != 1 {
+		t.Errorf("Expected the sealed value logged, got %d", n)
+	}
+}
+
+func TestHotShardsAndPlacement(t *testing.T) {
+	config := DefaultConfig()
+	config.NumShards = 4
+	config.HotShards.Interval = 20 * time.Millisecond
+	config.HotShards.MinRate = 1
+	
+	engine := NewCognitiveEngine(config)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	hammered, _ := engine.CreateConceptNode("checkout-db", tenantID)
+	for i := 0; i < 20; i++ {
+		engine.CreateConceptNode(fmt.Sprintf("service-%d", i), "quiet-tenant")
+	}
+	
+	// One atom read far more than any other makes its shard hot
+	var hot []sharding.ShardHeat
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && len(hot) == 0 {
+		for i := 0; i < 200; i++ {
+			engine.GetAtom(hammered.GetID(), tenantID)
+		}
+		time.Sleep(5 * time.Millisecond)
+		for _, heat := range engine.HotShards() {
+			if heat.Hot {
+				hot = append(hot, heat)
+			}
+		}
+	}
+	if len(hot) != 1 || len(hot[0].Tenants) == 0 || hot[0].Tenants[0].TenantID != tenantID {
+		t.Fatalf("Expected one hot shard driven by %s, got %+v", tenantID, hot)
+	}
+	
+	// Dedicating the hot shard to its tenant moves the others off it
+	if _, err := engine.PinTenant(sharding.Placement{TenantID: tenantID, Shards: []int{hot[0].ShardID}, Dedicated: true}); err != nil {
+		t.Fatalf("PinTenant failed: %v", err)
+	}
+	distribution := engine.shardManager.GetTenantStats("quiet-tenant")["shard_distribution"].(map[int]int)
+	if distribution[hot[0].ShardID] != 0 {
+		t.Errorf("Expected no quiet-tenant atoms on the dedicated shard, got %v", distribution)
+	}
+	if atoms := engine.QueryAtoms("quiet-tenant", nil); len(atoms) != 20 {
+		t.Errorf("Expected 20 quiet-tenant atoms after moving, got %d", len(atoms))
+	}
+	if _, err := engine.GetAtom(hammered.GetID(), tenantID); err != nil {
+		t.Errorf("Failed to get atom of pinned tenant: %v", err)
+	}
+	
+	report, _ := engine.PurgeTenant(context.Background(), tenantID)
+	if report.Removed["placement"] != 1 || !report.Verified {
+		t.Errorf("Expected the placement removed on purge, got %+v", report)
+	}
+	if len(engine.ListPlacements()) != 0 {
+		t.Error("Expected no placements after purge")
+	}
+}
+
+func TestResizeShards(t *testing.T) {
+	config := DefaultConfig()
+	config.NumShards = 2
+	config.ValueLog.Dir = t.TempDir()
+	
+	engine := NewCognitiveEngine(config)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	for i := 0; i < 50; i++ {
+		engine.CreateConceptNode(fmt.Sprintf("service-%d", i), tenantID)
+	}
+	
+	if err := engine.ResizeShards(6); err != nil {
+		t.Fatalf("ResizeShards failed: %v", err)
+	}
+	if engine.NumShards() != 6 {
+		t.Errorf("Expected 6 shards, got %d", engine.NumShards())
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for engine.ShardMigration().Active && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if status := engine.ShardMigration(); status.Active || status.Moved == 0 {
+		t.Errorf("Expected a finished migration that moved atoms, got %+v", status)
+	}
+	if atoms := engine.QueryAtoms(tenantID, nil); len(atoms) != 50 {
+		t.Errorf("Expected 50 atoms after resizing, got %d", len(atoms))
+	}
+	
+	// Added shards get value logs of their own
+	stats := engine.valueLogs.GetStats()
+	if shards := stats["shards"].([]map[string]interface{}); len(shards) != 6 {
+		t.Errorf("Expected 6 value logs, got %d", len(shards))
+	}
+	if result := engine.CheckValueLogs(); result.Status != health.StatusOK {
+		t.Errorf("Expected value logs to be healthy, got %+v", result)
+	}
+}
+
+func TestReadReplicas(t *testing.T) {
+	config := DefaultConfig()
+	config.NumShards = 2
+	config.Replicas.Enabled = true
+	config.Replicas.SyncInterval = 10 * time.Millisecond
+	
+	engine := NewCognitiveEngine(config)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	generation := engine.Generation(tenantID)
+	for i := 0; i < 20; i++ {
+		engine.CreateConceptNode(fmt.Sprintf("service-%d", i), tenantID)
+	}
+	
+	// Queries read replicas, which catch up from the event bus
+	deadline := time.Now().Add(5 * time.Second)
+	for len(engine.QueryAtoms(tenantID, nil)) != 20 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if atoms := engine.QueryAtoms(tenantID, nil); len(atoms) != 20 {
+		t.Errorf("Expected 20 atoms from replicas, got %d", len(atoms))
+	}
+	if engine.Generation(tenantID) == generation {
+		t.Error("Expected generation to change")
+	}
+	
+	stats := engine.shardManager.ReplicaStats()
+	if len(stats) != 2 {
+		t.Fatalf("Expected 2 replicas, got %d", len(stats))
+	}
+	
+	// Strong reads and reads bounded below the replication lag see writes
+	// at once
+	for _, consistency := range []sharding.Consistency{
+		{Level: sharding.ConsistencyStrong},
+		{Level: sharding.ConsistencyBounded, MaxStaleness: time.Nanosecond},
+	} {
+		engine.CreateConceptNode(fmt.Sprintf("late-%s", consistency.Level), tenantID)
+		want := 21
+		if consistency.Level == sharding.ConsistencyBounded {
+			want = 22
+		}
+		if atoms := engine.Reader(consistency).QueryAtoms(tenantID, nil); len(atoms) != want {
+			t.Errorf("Expected %s reads to see %d atoms, got %d", consistency.Level, want, len(atoms))
+		}
+	}
+}
+
+func TestFindAtoms(t *testing.T) {
+	config := DefaultConfig()
+	config.NumShards = 2
+	
+	engine := NewCognitiveEngine(config)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	for i := 0; i < 20; i++ {
+		engine.CreateConceptNode(fmt.Sprintf("service-%d", i), tenantID)
+	}
+	
+	conceptType := atomspace.ConceptNodeType
+	atoms, plans := engine.FindAtoms(tenantID, atomspace.Query{Type: &conceptType, Name: "service-3"})
+	if len(atoms) != 1 || atoms[0].GetName() != "service-3" {
+		t.Fatalf("Expected service-3, got %d atoms", len(atoms))
+	}
+	if len(plans) != 2 {
+		t.Fatalf("Expected a plan per shard, got %d", len(plans))
+	}
+	for _, plan := range plans {
+		if plan.Index != atomspace.IndexName {
+			t.Errorf("Expected the name index on shard %d, got %s", plan.ShardID, plan.Index)
+		}
+	}
+	
+	if atoms, _ := engine.FindAtoms(tenantID, atomspace.Query{Type: &conceptType}); len(atoms) != 20 {
+		t.Errorf("Expected 20 concepts, got %d", len(atoms))
+	}
+}
+
+func TestImportAtoms(t *testing.T) {
+	config := DefaultConfig()
+	config.NumShards = 2
+	
+	engine := NewCognitiveEngine(config)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	engine.CreateConceptNode("service-0", tenantID)
+	
+	arena := atomspace.NewArena(100, 0, 0)
+	atoms := make([]atomspace.Atom, 100)
+	for i := range atoms {
+		name := fmt.Sprintf("service-%d", i)
+		atoms[i] = arena.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, tenantID, atomspace.ConceptNodeType)
+	}
+	
+	imported, err := engine.ImportAtoms(atoms)
+	if err != nil {
+		t.Fatalf("ImportAtoms failed: %v", err)
+	}
+	if imported != 99 {
+		t.Errorf("Expected 99 atoms imported past the existing one, got %d", imported)
+	}
+	if got := len(engine.QueryAtoms(tenantID, nil)); got != 100 {
+		t.Errorf("Expected 100 atoms, got %d", got)
+	}
+	if _, err := engine.QueryAtomsAsOf(tenantID, time.Now(), nil); err != nil {
+		t.Errorf("Expected imported atoms in the history, got %v", err)
+	}
+}
+
+// benchmarkIngest loads n nodes per iteration into a fresh engine
+func benchmarkIngest(b *testing.B, n int, load func(*CognitiveEngine, []string)) {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("service-%d", i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		engine := NewCognitiveEngine(DefaultConfig())
+		b.StartTimer()
+		load(engine, names)
+		b.StopTimer()
+		engine.Close()
+		b.StartTimer()
+	}
+	b.ReportMetric(float64(n)*float64(b.N)/b.Elapsed().Seconds(), "atoms/s")
+}
+
+func BenchmarkIngestAddAtom(b *testing.B) {
+	benchmarkIngest(b, 100000, func(engine *CognitiveEngine, names []string) {
+		for _, name := range names {
+			engine.AddAtom(atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, "bench", atomspace.ConceptNodeType))
+		}
+	})
+}
+
+func BenchmarkIngestImportAtoms(b *testing.B) {
+	benchmarkIngest(b, 100000, func(engine *CognitiveEngine, names []string) {
+		arena := atomspace.NewArena(len(names), 0, 0)
+		atoms := make([]atomspace.Atom, len(names))
+		for i, name := range names {
+			atoms[i] = arena.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, "bench", atomspace.ConceptNodeType)
+		}
+		engine.ImportAtoms(atoms)
+	})
+}
+
+func TestMemoryReport(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	// Each name and tenant ID is built afresh, as if decoded from a request
+	for _, tenantID := range []string{"tenant-a", "tenant-b"} {
+		for i := 0; i < 50; i++ {
+			engine.CreateConceptNode(fmt.Sprintf("service-%d", i), strings.Clone(tenantID))
+		}
+	}
+	
+	report := engine.MemoryReport()
+	if report.HeapAlloc == 0 || report.Goroutines == 0 {
+		t.Errorf("Expected runtime statistics, got %+v", report)
+	}
+	stats := report.Strings
+	if stats.Atoms != 100 || stats.Names != 50 || stats.TenantIDs != 2 {
+		t.Errorf("Expected 100 atoms with 50 names of 2 tenants, got %+v", stats)
+	}
+	if stats.Duplicates != 0 {
+		t.Errorf("Expected every string interned, got %d duplicates", stats.Duplicates)
+	}
+	// 98 tenant IDs and 50 names share a copy
+	if want := int64(98*len("tenant-a") + 50*len("service-0")); stats.SavedBytes < want {
+		t.Errorf("Expected at least %d bytes saved, got %d", want, stats.SavedBytes)
+	}
+	
+	// A purged tenant's ID leaves the intern table at the next sweep
+	if _, err := engine.PurgeTenant(context.Background(), "tenant-b"); err != nil {
+		t.Fatalf("PurgeTenant failed: %v", err)
+	}
+	atomspace.SweepInterned(engine.shardManager.AllAtoms())
+	if table := engine.MemoryReport().Strings.Table; table != 51 {
+		t.Errorf("Expected 50 names and 1 tenant ID interned after the sweep, got %d", table)
+	}
+}
+
+func TestIDSchemes(t *testing.T) {
+	defer atomspace.SetIDScheme(atomspace.IDSchemeSHA256)
+	
+	for scheme, length := range map[atomspace.IDScheme]int{
+		atomspace.IDSchemeSHA256: 64,
+		atomspace.IDSchemeHash:   26,
+		atomspace.IDSchemeULID:   26,
+	} {
+		cfg := DefaultConfig()
+		cfg.IDScheme = scheme
+		engine := NewCognitiveEngine(cfg)
+		
+		a, _ := engine.CreateConceptNode("payments-api", "test-tenant")
+		b, _ := engine.CreateConceptNode("checkout", "test-tenant")
+		link, err := engine.CreateInheritanceLink(a.GetID(), b.GetID(), "test-tenant")
+		if err != nil {
+			t.Fatalf("%s: CreateInheritanceLink failed: %v", scheme, err)
+		}
+		if len(a.GetID()) != length || len(link.GetID()) != length {
+			t.Errorf("%s: Expected %d-byte IDs, got %q and %q", scheme, length, a.GetID(), link.GetID())
+		}
+		// Content-addressed atoms are still deduplicated
+		if id := atomspace.GenerateAtomID(atomspace.ConceptNodeType, "payments-api", nil); id != a.GetID() {
+			t.Errorf("%s: Expected the same content to get ID %s, got %s", scheme, a.GetID(), id)
+		}
+		if _, err := engine.CreateConceptNode("payments-api", "test-tenant"); err == nil {
+			t.Errorf("%s: Expected a duplicate atom to be rejected", scheme)
+		}
+		if engine.MemoryReport().IDScheme != scheme {
+			t.Errorf("%s: Expected the memory report to name the scheme", scheme)
+		}
+		engine.Close()
+	}
+	
+	// ULIDs minted by another process are found again by content once
+	// their atoms are restored
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	atomspace.SetIDScheme(atomspace.IDSchemeULID)
+	node, _ := engine.CreateConceptNode("billing", "test-tenant")
+	var snapshot bytes.Buffer
+	if err := engine.SnapshotTenant("test-tenant", &snapshot); err != nil {
+		t.Fatalf("Failed to snapshot tenant: %v", err)
+	}
+	engine.DeleteAtom(node.GetID(), "test-tenant")
+	atomspace.SweepIDs(nil)
+	atomspace.SweepIDs(nil)
+	if id := atomspace.GenerateAtomID(atomspace.ConceptNodeType, "billing", nil); id == node.GetID() {
+		t.Fatal("Expected the swept ID to be minted afresh")
+	}
+	
+	restored := NewCognitiveEngine(&Config{NumShards: 2, WorkersPerShard: 1, InferenceWorkers: 1, AgentWorkers: 1, PipelineWorkers: 1})
+	defer restored.Close()
+	atomspace.SweepIDs(nil)
+	atomspace.SweepIDs(nil)
+	if _, err := restored.RestoreSnapshot(bytes.NewReader(snapshot.Bytes())); err != nil {
+		t.Fatalf("Failed to restore snapshot: %v", err)
+	}
+	if id := atomspace.GenerateAtomID(atomspace.ConceptNodeType, "billing", nil); id != node.GetID() {
+		t.Errorf("Expected the restored atom's ID %s, got %s", node.GetID(), id)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	if engine.Draining() || engine.CheckDraining().Status != health.StatusOK {
+		t.Fatal("Expected a new engine to be ready")
+	}
+	engine.Drain()
+	if !engine.Draining() || engine.CheckDraining().Status != health.StatusUnavailable {
+		t.Error("Expected a draining engine to report unavailable")
+	}
+}
+
+func TestUsage(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	query := usage.Key{TenantID: "acme", Principal: "alice", Endpoint: "POST /tenants/{tenantID}/atoms/query"}
+	infer := usage.Key{TenantID: "acme", Principal: "alice", Endpoint: "POST /tenants/{tenantID}/inference"}
+	engine.RecordUsage(query, usage.Counters{Requests: 1, BytesIn: 40, BytesOut: 200})
+	engine.RecordUsage(query, usage.Counters{Requests: 1, Errors: 1})
+	engine.RecordUsage(infer, usage.Counters{Requests: 1, Seconds: 1.5, InferenceSeconds: 1.5})
+	engine.RecordUsage(usage.Key{TenantID: "globex", Endpoint: "GET /stats"}, usage.Counters{Requests: 1})
+	
+	entries, err := engine.UsageSummary(time.Hour, usage.Key{TenantID: "acme"}, []string{usage.ByEndpoint})
+	if err != nil {
+		t.Fatalf("UsageSummary failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Endpoint != query.Endpoint || entries[0].Requests != 2 || entries[0].Errors != 1 || entries[0].BytesOut != 200 {
+		t.Fatalf("Expected acme's usage per endpoint, got %+v", entries)
+	}
+	if entries[1].InferenceSeconds != 1.5 {
+		t.Errorf("Expected 1.5 inference seconds, got %v", entries[1].InferenceSeconds)
+	}
+	if _, err := engine.UsageSummary(time.Hour, usage.Key{}, []string{"region"}); err == nil {
+		t.Error("Expected an unknown dimension to be rejected")
+	}
+	
+	buckets := engine.UsageBuckets(time.Now().Add(-engine.UsageConfig().Retention), time.Now().Add(time.Minute), usage.Key{})
+	requests := int64(0)
+	for _, b := range buckets {
+		requests += b.Requests
+	}
+	if requests != 4 {
+		t.Errorf("Expected 4 requests in the buckets, got %d", requests)
+	}
+}
+
+// meteringRecorder records the billable events exported to it
+type meteringRecorder struct {
+	events []metering.Event
+}
+
+func (m *meteringRecorder) Name() string {
+	return "recorder"
+}
+
+func (m *meteringRecorder) Export(ctx context.Context, events []metering.Event) error {
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func TestMetering(t *testing.T) {
+	recorder := &meteringRecorder{}
+	cfg := DefaultConfig()
+	cfg.MeteringExporters = []metering.Exporter{recorder}
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "acme"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	cat, _ := engine.CreateConceptNode("cat", tenantID)
+	mammal, _ := engine.CreateConceptNode("mammal", tenantID)
+	animal, _ := engine.CreateConceptNode("animal", tenantID)
+	engine.CreateInheritanceLink(cat.GetID(), mammal.GetID(), tenantID)
+	engine.CreateInheritanceLink(mammal.GetID(), animal.GetID(), tenantID)
+	
+	pipelineID, err := engine.CreateDefaultPipeline(tenantID)
+	if err != nil {
+		t.Fatalf("Failed to create default pipeline: %v", err)
+	}
+	if _, err := engine.ExecutePipeline(context.Background(), pipelineID, nil); err != nil {
+		t.Fatalf("Failed to execute pipeline: %v", err)
+	}
+	
+	status := engine.FlushMetering(context.Background())
+	if len(status) != 1 || status[0].Pending != 0 || status[0].Exported != int64(len(recorder.events)) {
+		t.Fatalf("Expected every event exported, got %+v", status)
+	}
+	quantities := make(map[metering.EventType]float64)
+	for _, event := range recorder.events {
+		if event.TenantID != tenantID || event.ID == "" {
+			t.Errorf("Unexpected event %+v", event)
+		}
+		quantities[event.Type] += event.Quantity
+	}
+	if quantities[metering.PipelineExecutions] != 1 {
+		t.Errorf("Expected 1 pipeline execution, got %v", quantities[metering.PipelineExecutions])
+	}
+	if quantities[metering.InferenceCPUSeconds] <= 0 || quantities[metering.AtomHours] <= 0 {
+		t.Errorf("Expected inference seconds and atom hours billed, got %v", quantities)
+	}
+	
+	report := engine.MeteringReport()
+	if report.Totals[tenantID][metering.PipelineExecutions] != 1 || report.Interval != "1h0m0s" {
+		t.Errorf("Expected the totals reported, got %+v", report)
+	}
+}
+
+func TestOnboarding(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	result, err := engine.InitializeTenantFromTemplate("acme", "infrastructure")
+	if err != nil {
+		t.Fatalf("Failed to onboard tenant: %v", err)
+	}
+	if result.Atoms != 16 || len(result.Pipelines) != 1 || len(result.Agents) != 3 || !result.Budget {
+		t.Fatalf("Expected the template's ontology, pipeline, agents and budget, got %+v", result)
+	}
+	if _, err := engine.GetPipeline("anomaly-triage-acme"); err != nil {
+		t.Errorf("Expected the triage pipeline created: %v", err)
+	}
+	if strings.Join(result.Agents, ",") != "slo-acme,runbook-acme,drift-acme" {
+		t.Errorf("Expected the SLO, runbook and drift agents enabled, got %v", result.Agents)
+	}
+	if _, err := engine.InitializeTenantFromTemplate("acme", "infrastructure"); err == nil {
+		t.Error("Expected onboarding an initialized tenant to fail")
+	}
+	
+	// A failed step rolls the whole tenant back
+	tmpl := onboarding.Template{
+		Name:     "broken",
+		Ontology: onboarding.Ontology{Mounts: []string{"missing"}, Concepts: []onboarding.Concept{{Name: "cat", Strength: 0.9, Confidence: 0.8}}},
+	}
+	if _, err := engine.OnboardTenant("globex", tmpl); err == nil {
+		t.Fatal("Expected mounting a missing shared space to fail")
+	}
+	for _, tenantID := range engine.ListTenants() {
+		if tenantID == "globex" {
+			t.Fatal("Expected the failed tenant purged")
+		}
+	}
+	
+	engine.CreateSharedSpace("k8s", "Kubernetes kinds", "")
+	tmpl.Ontology.Mounts = []string{"k8s"}
+	result, err = engine.OnboardTenant("globex", tmpl)
+	if err != nil {
+		t.Fatalf("Failed to onboard tenant after the rollback: %v", err)
+	}
+	cat, err := engine.GetAtom(atomspace.GenerateAtomID(atomspace.ConceptNodeType, "cat", nil), "globex")
+	if err != nil || cat.GetTruthValue().Strength != 0.9 {
+		t.Errorf("Expected the concept seeded with its truth value, got %v (%v)", cat, err)
+	}
+	
+	if _, err := engine.InitializeTenantFromTemplate("initech", "missing"); !errors.Is(err, onboarding.ErrTemplateNotFound) {
+		t.Errorf("Expected a missing template reported, got %v", err)
+	}
+}
+
+func TestPromotion(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	if _, err := engine.InitializeTenantFromTemplate("acme-dev", "infrastructure"); err != nil {
+		t.Fatalf("Failed to onboard tenant: %v", err)
+	}
+	for _, tenantID := range []string{"acme-staging", "acme-prod"} {
+		if err := engine.InitializeTenant(tenantID); err != nil {
+			t.Fatalf("Failed to initialize tenant: %v", err)
+		}
+	}
+	engine.CreateDefaultPipeline("acme-dev")
+	engine.Triggers().AddTrigger(&triggers.Trigger{
+		ID:       "triage-dev",
+		TenantID: "acme-dev",
+		Name:     "triage",
+		Actions:  []triggers.Action{{Type: triggers.ActionExecutePipeline, PipelineID: "anomaly-triage-acme-dev"}},
+		Enabled:  true,
+		Cooldown: time.Minute,
+	})
+	concept := atomspace.ConceptNodeType
+	engine.SetSavedQuery("acme-dev", queries.Query{Name: "concepts", Query: atomspace.Query{Type: &concept}})
+	ucb := inference.SelectionConfig{Strategy: inference.StrategyUCB, RulesPerIteration: 2, Exploration: 1, Decay: 0.3}
+	engine.SetRuleSelection("acme-dev", ucb)
+	
+	if _, err := engine.SetPromotionTrack(promotion.Track{Name: "acme", Environments: []promotion.Environment{
+		{Name: "dev", TenantID: "acme-dev"},
+		{Name: "staging", TenantID: "acme-staging"},
+		{Name: "prod", TenantID: "acme-prod"},
+	}}); err != nil {
+		t.Fatalf("Failed to set track: %v", err)
+	}
+	p, err := engine.Promote("acme", "dev", 0)
+	if err != nil {
+		t.Fatalf("Failed to promote: %v", err)
+	}
+	if a := p.Applied; a.Rules != 1 || a.Pipelines != 1 || a.Queries != 1 || a.Concepts != 9 || a.Links != 7 || !a.RuleSelection {
+		t.Fatalf("Expected every bundled artifact applied, got %+v", a)
+	}
+	b, _ := engine.GetBundle("acme-dev", p.Version)
+	if len(b.Skipped) != 1 || !strings.HasPrefix(b.Skipped[0], "pipeline default-pipeline-acme-dev") {
+		t.Errorf("Expected the pipeline assembled in code skipped, got %v", b.Skipped)
+	}
+	
+	// Pipeline references follow the pipelines to the new tenant
+	trigger, err := engine.Triggers().GetTrigger("triage-acme-staging", "acme-staging")
+	if err != nil {
+		t.Fatalf("Expected the rule promoted: %v", err)
+	}
+	if spec := trigger.Spec(); spec.Actions[0].PipelineID != "anomaly-triage-acme-staging" || spec.CooldownSeconds != 60 {
+		t.Errorf("Expected the rule bound to the staging pipeline, got %+v", spec)
+	}
+	if _, err := engine.GetPipeline("anomaly-triage-acme-staging"); err != nil {
+		t.Errorf("Expected the pipeline promoted: %v", err)
+	}
+	if atoms, _, err := engine.RunSavedQuery("acme-staging", "concepts"); err != nil || len(atoms) != 9 {
+		t.Errorf("Expected the saved query to find the promoted ontology, got %d atoms (%v)", len(atoms), err)
+	}
+	if config, _, _ := engine.GetRuleSelection("acme-staging"); config != ucb {
+		t.Errorf("Expected the rule selection promoted, got %+v", config)
+	}
+	
+	// Promoting again replaces what changed and leaves the rest alone
+	p, err = engine.Promote("acme", "dev", 0)
+	if err != nil {
+		t.Fatalf("Failed to promote again: %v", err)
+	}
+	if a := p.Applied; a.Concepts != 0 || a.Links != 0 || a.RuleSelection || a.Pipelines != 1 || a.Rules != 1 {
+		t.Errorf("Expected only rules and pipelines replaced, got %+v", a)
+	}
+	if n := len(engine.Triggers().GetTriggersByTenant("acme-staging")); n != 1 {
+		t.Errorf("Expected the rule replaced, got %d triggers", n)
+	}
+	
+	// Staging promotes the bundle it was tested with
+	staged, err := engine.ExportBundle("acme-staging")
+	if err != nil {
+		t.Fatalf("Failed to export staging: %v", err)
+	}
+	if p, err = engine.Promote("acme", "staging", staged.Version); err != nil || p.Checksum != staged.Checksum {
+		t.Fatalf("Failed to promote staging: %+v (%v)", p, err)
+	}
+	// Concepts another tenant holds on prod's shard are reported, not fatal
+	if a := p.Applied; a.Concepts+len(a.Conflicts) < 9 {
+		t.Errorf("Expected each concept created or reported, got %+v", a)
+	}
+	status, _ := engine.GetPromotionTrack("acme")
+	if status.Deployments["prod"].Checksum != staged.Checksum || status.Deployments["staging"].Version != 2 {
+		t.Errorf("Expected each environment's bundle tracked, got %+v", status.Deployments)
+	}
+	
+	// Tampered bundles and unknown shared spaces change nothing
+	staged.Artifacts.Queries[0].Query.Limit = 1
+	if _, err := engine.ImportBundle("acme-prod", staged); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Expected a tampered bundle rejected, got %v", err)
+	}
+	dev, _ := engine.GetBundle("acme-dev", 0)
+	dev.Artifacts.Ontology.Mounts = []string{"missing"}
+	dev.Checksum, _ = promotion.Checksum(dev.Artifacts)
+	if _, err := engine.ImportBundle("acme-prod", dev); err == nil {
+		t.Error("Expected a bundle mounting a missing shared space rejected")
+	}
+	if _, err := engine.Promote("acme", "prod", 0); err == nil {
+		t.Error("Expected the last environment not to promote")
+	}
+	if history := engine.GetPromotions("acme"); len(history) != 3 {
+		t.Errorf("Expected 3 promotions recorded, got %d", len(history))
+	}
+}
+
+func TestGitOps(t *testing.T) {
+	dir := t.TempDir()
+	manifests := `kind: Tenant
+name: acme
+spec:
+  budget: {window_ns: 3600000000000, max_wall_time_ns: 600000000000, action: pause}
+---
+kind: Pipeline
+tenant: acme
+name: triage
+spec:
+  stages: [{kind: anomaly-score}, {kind: remediation-proposal}]
+---
+kind: Rule
+tenant: acme
+name: triage-anomalies
+spec:
+  condition: {name_prefix: anomaly}
+  actions: [{type: execute_pipeline, pipeline_id: triage}]
+---
+kind: Query
+tenant: acme
+name: pods
+spec:
+  query: {type: 1, limit: 10}
+---
+kind: Agents
+tenant: acme
+spec:
+  slo: {}
+---
+kind: Ontology
+tenant: acme
+spec:
+  concepts: [{name: Pod, strength: 0.9, confidence: 0.8}, {name: Resource}]
+  inheritance: [{child: Pod, parent: Resource}]
+`
+	write := func(data string) {
+		if err := os.WriteFile(filepath.Join(dir, "acme.yaml"), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(manifests)
+	
+	cfg := DefaultConfig()
+	cfg.GitOps.Dir = dir
+	cfg.GitOps.Interval = time.Hour
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	// The engine converges as soon as it starts
+	var status gitops.Status
+	for deadline := time.Now().Add(5 * time.Second); status.Revision == "" && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		status, _ = engine.GetGitOpsStatus()
+	}
+	if len(status.Tenants) != 1 || status.Error != "" {
+		t.Fatalf("Expected tenant acme reconciled, got %+v", status)
+	}
+	acme := status.Tenants[0]
+	if acme.Error != "" || acme.Applied == nil || acme.Changes[0].Kind != gitops.KindTenant {
+		t.Fatalf("Expected tenant acme created, got %+v", acme)
+	}
+	if a := acme.Applied; a.Pipelines != 1 || a.Rules != 1 || a.Queries != 1 || a.Concepts != 2 || a.Links != 1 {
+		t.Errorf("Expected every declared artifact applied, got %+v", a)
+	}
+	rules := engine.Triggers().GetTriggersByTenant("acme")
+	if len(rules) != 1 || rules[0].Spec().Actions[0].PipelineID != "triage-acme" {
+		t.Errorf("Expected the rule to run the tenant's pipeline, got %+v", rules)
+	}
+	if _, budgeted := engine.agentScheduler.Budgets().GetBudget("acme"); !budgeted {
+		t.Error("Expected the budget set")
+	}
+	
+	// Drift is planned without being corrected, then reconciled away
+	if err := engine.DisableSLOTracking("acme"); err != nil {
+		t.Fatalf("Expected the SLO agent enabled: %v", err)
+	}
+	engine.DeleteSavedQuery("acme", "pods")
+	engine.SetSavedQuery("acme", queries.Query{Name: "adhoc"})
+	podID := atomspace.GenerateAtomID(atomspace.ConceptNodeType, "Pod", nil)
+	engine.UpdateAtom(podID, "acme", func(atom atomspace.Atom) error {
+		atom.SetTruthValue(atomspace.TruthValue{Strength: 0.1, Confidence: 0.1})
+		return nil
+	})
+	plan, err := engine.PlanGitOps(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to plan: %v", err)
+	}
+	drift := plan.Tenants[0]
+	changes := make(map[string]string)
+	for _, c := range drift.Changes {
+		changes[c.Kind+"/"+c.Name] = c.Action
+	}
+	if len(changes) != 3 || changes["Agent/slo"] != gitops.ActionCreate || changes["Query/pods"] != gitops.ActionCreate || changes["Query/adhoc"] != gitops.ActionDelete {
+		t.Errorf("Expected the agent and query drift planned, got %+v", drift.Changes)
+	}
+	if len(drift.Ontology.Changed) != 1 || drift.Ontology.Changed[0].AtomID != podID {
+		t.Errorf("Expected the truth value drift reported, got %+v", drift.Ontology)
+	}
+	if stored, _ := engine.GetGitOpsStatus(); drift.Applied != nil || !stored.SyncedAt.Equal(status.SyncedAt) {
+		t.Error("Expected planning to apply and record nothing")
+	}
+	
+	if status, err = engine.ReconcileGitOps(context.Background()); err != nil || status.Tenants[0].Error != "" {
+		t.Fatalf("Failed to reconcile: %v %+v", err, status)
+	}
+	if _, err := engine.GetSavedQuery("acme", "adhoc"); err == nil {
+		t.Error("Expected the undeclared query pruned")
+	}
+	if plan, _ = engine.PlanGitOps(context.Background()); !plan.Tenants[0].InSync() {
+		t.Errorf("Expected acme in sync, got %+v", plan.Tenants[0])
+	}
+	
+	// Changed manifests are applied; invalid ones change nothing
+	write(strings.Replace(manifests, "limit: 10", "limit: 20", 1))
+	status, _ = engine.ReconcileGitOps(context.Background())
+	if c := status.Tenants[0].Changes; len(c) != 1 || c[0] != (gitops.Change{Kind: gitops.KindQuery, Name: "pods", Action: gitops.ActionUpdate}) {
+		t.Errorf("Expected the query updated, got %+v", c)
+	}
+	if q, _ := engine.GetSavedQuery("acme", "pods"); q.Query.Limit != 20 {
+		t.Errorf("Expected limit 20, got %d", q.Query.Limit)
+	}
+	write(manifests + "---\nkind: Query\ntenant: beta\nname: all\n")
+	if status, err = engine.ReconcileGitOps(context.Background()); err == nil || status.Error == "" {
+		t.Error("Expected manifests of an undeclared tenant rejected")
+	}
+}
+
+func TestStartup(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	if engine.CheckStartup().Status != health.StatusOK {
+		t.Error("Expected an engine without warm-ups started")
+	}
+	engine.CreateConceptNode("billing", "test-tenant")
+	var snapshot bytes.Buffer
+	if err := engine.SnapshotTenant("test-tenant", &snapshot); err != nil {
+		t.Fatalf("Failed to snapshot tenant: %v", err)
+	}
+	engine.Close()
+	
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "test-tenant.snap"), snapshot.Bytes(), 0o600)
+	waitStarted := func(engine *CognitiveEngine) health.CheckResult {
+		result := engine.CheckStartup()
+		for deadline := time.Now().Add(5 * time.Second); result.Details["shards"].(map[string]interface{})["warming_up"] == true && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			result = engine.CheckStartup()
+		}
+		return result
+	}
+	
+	cfg := DefaultConfig()
+	cfg.SnapshotDir = dir
+	hydrated := NewCognitiveEngine(cfg)
+	defer hydrated.Close()
+	result := waitStarted(hydrated)
+	shards := result.Details["shards"].(map[string]interface{})
+	if result.Status != health.StatusOK || shards["snapshots"] != 1 || shards["atoms"] != 1 {
+		t.Fatalf("Expected the snapshot restored at startup, got %+v", result)
+	}
+	if nodes := hydrated.QueryAtoms("test-tenant", func(atomspace.Atom) bool { return true }); len(nodes) != 1 {
+		t.Errorf("Expected the restored concept, got %d atoms", len(nodes))
+	}
+	
+	// A snapshot that cannot be restored keeps the engine from starting
+	os.WriteFile(filepath.Join(dir, "broken.snap"), []byte("not a snapshot"), 0o600)
+	broken := NewCognitiveEngine(cfg)
+	defer broken.Close()
+	result = waitStarted(broken)
+	if result.Status != health.StatusUnavailable || !strings.Contains(result.Details["shards"].(map[string]interface{})["error"].(string), "broken.snap") {
+		t.Errorf("Expected startup unavailable naming the snapshot, got %+v", result)
+	}
+	
+	config := hydrated.RuntimeConfig()
+	if config["num_shards"] != cfg.NumShards || config["snapshot_dir"] != dir || config["encryption"] != false {
+		t.Errorf("Expected the effective configuration, got %+v", config)
+	}
+}
+
+// sharedLock is a leader lock shared by engines in one process
+type sharedLock struct {
+	holder string
+	mu     sync.Mutex
+}
+
+type sharedLockHandle struct {
+	lock     *sharedLock
+	identity string
+}
+
+func (h sharedLockHandle) Acquire(ctx context.Context) (bool, error) {
+	h.lock.mu.Lock()
+	defer h.lock.mu.Unlock()
+	if h.lock.holder == "" {
+		h.lock.holder = h.identity
+	}
+	return h.lock.holder == h.identity, nil
+}
+
+func (h sharedLockHandle) Release(ctx context.Context) error {
+	h.lock.mu.Lock()
+	defer h.lock.mu.Unlock()
+	if h.lock.holder == h.identity {
+		h.lock.holder = ""
+	}
+	return nil
+}
+
+type countingAgent struct {
+	agents.BaseAgent
+	runs atomic.Int64
+}
+
+func (a *countingAgent) Run(ctx context.Context) error {
+	a.runs.Add(1)
+	return nil
+}
+
+// connectorAgent is a daemon that can be started again after a stop
+type connectorAgent struct {
+	agents.BaseAgent
+	stops chan struct{}
+}
+
+func (a *connectorAgent) Run(ctx context.Context) error {
+	return nil
+}
+
+func (a *connectorAgent) Start(ctx context.Context) error {
+	select {
+	case <-a.stops:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *connectorAgent) Stop() {
+	a.stops <- struct{}{}
+}
+
+func TestLeaderElection(t *testing.T) {
+	lock := &sharedLock{}
+	tenantID := "test-tenant"
+	start := func(identity string) (*CognitiveEngine, *countingAgent) {
+		cfg := DefaultConfig()
+		cfg.LeaderLock = sharedLockHandle{lock, identity}
+		cfg.Leader = leader.Config{Identity: identity, RetryPeriod: 10 * time.Millisecond}
+		engine := NewCognitiveEngine(cfg)
+		agent := &countingAgent{BaseAgent: agents.BaseAgent{ID: "digest", Name: "Digest", TenantID: tenantID}}
+		engine.RegisterAgent(agent)
+		engine.RegisterAgent(&connectorAgent{BaseAgent: agents.BaseAgent{ID: "connector", Name: "Connector", TenantID: tenantID}, stops: make(chan struct{}, 1)})
+		return engine, agent
+	}
+	waitFor := func(what string, cond func() bool) {
+		for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(10 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for %s", what)
+			}
+		}
+	}
+	
+	a, agentA := start("a")
+	waitFor("a to lead", a.IsLeader)
+	b, agentB := start("b")
+	defer b.Close()
+	waitFor("a to run its agents", func() bool {
+		return agentA.runs.Load() > 0 && len(a.GetAgentDaemons(tenantID)) == 1
+	})
+	time.Sleep(250 * time.Millisecond)
+	if b.IsLeader() || agentB.runs.Load() != 0 || len(b.GetAgentDaemons(tenantID)) != 0 {
+		t.Fatalf("Expected agents to run on the leader only, got %d runs and %+v on b", agentB.runs.Load(), b.GetAgentDaemons(tenantID))
+	}
+	
+	// Closing the leader hands its agents over to the other replica
+	a.Close()
+	waitFor("b to take over", func() bool {
+		return b.IsLeader() && agentB.runs.Load() > 0 && len(b.GetAgentDaemons(tenantID)) == 1
+	})
+	if status, err := b.GetLeaderStatus(); err != nil || status.Identity != "b" || !status.Leader {
+		t.Errorf("Expected b reported leader, got %+v, %v", status, err)
+	}
+	
+	standalone := NewCognitiveEngine(DefaultConfig())
+	defer standalone.Close()
+	if _, err := standalone.GetLeaderStatus(); err != leader.ErrNotConfigured || !standalone.IsLeader() {
+		t.Error("Expected an engine without a leader lock to run everything")
+	}
+}
+
+// sharedMembership is a partition membership shared by engines in one
+// process
+type sharedMembership struct {
+	members map[string]bool
+	mu      sync.Mutex
+}
+
+type sharedMembershipHandle struct {
+	membership *sharedMembership
+	identity   string
+}
+
+func (h sharedMembershipHandle) Heartbeat(ctx context.Context) ([]string, error) {
+	h.membership.mu.Lock()
+	defer h.membership.mu.Unlock()
+	h.membership.members[h.identity] = true
+	members := []string{}
+	for member := range h.membership.members {
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+func (h sharedMembershipHandle) Leave(ctx context.Context) error {
+	h.membership.mu.Lock()
+	defer h.membership.mu.Unlock()
+	delete(h.membership.members, h.identity)
+	return nil
+}
+
+func TestAgentPartitioning(t *testing.T) {
+	membership := &sharedMembership{members: make(map[string]bool)}
+	tenantID := "test-tenant"
+	start := func(identity string) (*CognitiveEngine, []*countingAgent) {
+		cfg := DefaultConfig()
+		cfg.Membership = sharedMembershipHandle{membership, identity}
+		cfg.Partition = partition.Config{Identity: identity, HeartbeatInterval: 10 * time.Millisecond}
+		engine := NewCognitiveEngine(cfg)
+		var registered []*countingAgent
+		for i := 0; i < 8; i++ {
+			agent := &countingAgent{BaseAgent: agents.BaseAgent{ID: fmt.Sprintf("digest-%d", i), Name: "Digest", TenantID: tenantID}}
+			engine.RegisterAgent(agent)
+			registered = append(registered, agent)
+		}
+		return engine, registered
+	}
+	waitFor := func(what string, cond func() bool) {
+		for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(10 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for %s", what)
+			}
+		}
+	}
+	members := func(engine *CognitiveEngine) int {
+		report, _ := engine.GetPartitionReport()
+		return len(report.Members)
+	}
+	runs := func(registered []*countingAgent) []int64 {
+		counts := make([]int64, len(registered))
+		for i, agent := range registered {
+			counts[i] = agent.runs.Load()
+		}
+		return counts
+	}
+	
+	a, agentsA := start("a")
+	b, agentsB := start("b")
+	defer b.Close()
+	waitFor("both replicas to join", func() bool { return members(a) == 2 && members(b) == 2 })
+	
+	// Each agent runs on exactly one replica, and both replicas run some
+	time.Sleep(100 * time.Millisecond)
+	beforeA, beforeB := runs(agentsA), runs(agentsB)
+	time.Sleep(300 * time.Millisecond)
+	afterA, afterB := runs(agentsA), runs(agentsB)
+	onA, onB := 0, 0
+	for i := range agentsA {
+		ranA, ranB := afterA[i] > beforeA[i], afterB[i] > beforeB[i]
+		if ranA == ranB {
+			t.Errorf("Expected %s to run on exactly one replica, ran on a: %v, b: %v", agentsA[i].ID, ranA, ranB)
+		}
+		if ranA {
+			onA++
+		} else if ranB {
+			onB++
+		}
+	}
+	if onA == 0 || onB == 0 {
+		t.Errorf("Expected agents spread across both replicas, got %d on a and %d on b", onA, onB)
+	}
+	
+	report, err := b.GetPartitionReport()
+	if err != nil || !report.Active || len(report.Agents) != len(agentsB) {
+		t.Fatalf("Expected a report of every agent, got %+v, %v", report, err)
+	}
+	for i, placement := range report.Agents {
+		if ranOnB := afterB[i] > beforeB[i]; placement.Key != placement.AgentID || (placement.Owner == "b") != ranOnB {
+			t.Errorf("Expected the reported owner to run the agent, got %+v", placement)
+		}
+	}
+	
+	// Closing a replica hands its agents over to the remaining one
+	a.Close()
+	waitFor("b to own every agent", func() bool { return members(b) == 1 })
+	before := runs(agentsB)
+	waitFor("b to run every agent", func() bool {
+		after := runs(agentsB)
+		for i := range after {
+			if after[i] == before[i] {
+				return false
+			}
+		}
+		return true
+	})
+	
+	standalone := NewCognitiveEngine(DefaultConfig())
+	defer standalone.Close()
+	if _, err := standalone.GetPartitionReport(); err != partition.ErrNotConfigured {
+		t.Errorf("Expected partitioning not configured, got %v", err)
+	}
+}
+
+// sharedJobQueue stands in for a durable queue shared by replicas
+type sharedJobQueue struct {
+	*pipeline.MemoryQueue
+}
+
+// recordingStage passes its inputs on to a channel
+type recordingStage struct {
+	inputs chan interface{}
+}
+
+func (s *recordingStage) GetName() string {
+	return "recording"
+}
+
+func (s *recordingStage) Execute(ctx context.Context, input interface{}) (interface{}, error) {
+	s.inputs <- input
+	return input, nil
+}
+
+func TestPipelineJobQueue(t *testing.T) {
+	ctx := context.Background()
+	tenantID := "test-tenant"
+	
+	// Scheduled executions wait until due
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	p, _ := engine.CreatePipeline("nightly", "Nightly", tenantID)
+	stage := &recordingStage{inputs: make(chan interface{}, 1)}
+	p.AddStage(stage)
+	runAt := time.Now().Add(150 * time.Millisecond)
+	exec, err := engine.SchedulePipeline(ctx, "nightly", nil, runAt)
+	if err != nil || exec.State != pipeline.ExecutionStateScheduled {
+		t.Fatalf("Expected a scheduled execution, got %+v, %v", exec, err)
+	}
+	select {
+	case <-stage.inputs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the scheduled execution")
+	}
+	if started, _ := p.GetExecution(exec.ID); started.StartedAt.Before(runAt) {
+		t.Errorf("Expected the execution to start once due, started at %v for %v", started.StartedAt, runAt)
+	}
+	
+	// Jobs of a shared queue left by a replica that stopped are run by
+	// another, with their input
+	queue := sharedJobQueue{pipeline.NewMemoryQueue()}
+	cfg := DefaultConfig()
+	cfg.PipelineQueue = queue
+	stopped := NewCognitiveEngine(cfg)
+	stopped.CreatePipeline("triage", "Triage", tenantID)
+	node := atomspace.NewNode("checkout", "checkout", tenantID, atomspace.ConceptNodeType)
+	job, err := stopped.SchedulePipeline(ctx, "triage", []atomspace.Atom{node}, time.Now().Add(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to schedule execution: %v", err)
+	}
+	stopped.Close()
+	
+	replica := NewCognitiveEngine(cfg)
+	defer replica.Close()
+	p, _ = replica.CreatePipeline("triage", "Triage", tenantID)
+	stage = &recordingStage{inputs: make(chan interface{}, 1)}
+	p.AddStage(stage)
+	select {
+	case input := <-stage.inputs:
+		if atoms, _ := input.([]atomspace.Atom); len(atoms) != 1 || atoms[0].GetName() != "checkout" {
+			t.Errorf("Expected the scheduled atoms as input, got %v", input)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the job to move to the other replica")
+	}
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if exec, _ := p.GetExecution(job.ID); exec.State == pipeline.ExecutionStateCompleted {
+			return
+		}
+	}
+	t.Errorf("Expected execution %s completed on the other replica", job.ID)
+}
+
+func TestLogCompaction(t *testing.T) {
+	config := DefaultConfig()
+	config.ValueLog.Dir = t.TempDir()
+	config.ValueLog.Interval = 10 * time.Millisecond
+	config.ValueLog.LossWindow = 10 * time.Millisecond
+	config.SnapshotDir = t.TempDir()
+	config.SnapshotInterval = 30 * time.Millisecond
+	
+	engine := NewCognitiveEngine(config)
+	tenantID := "test-tenant"
+	engine.InitializeTenant(tenantID)
+	atom, _ := engine.CreateConceptNode("ledger-db", tenantID)
+	atom.SetAttentionValue(atomspace.AttentionValue{STI: 55})
+	
+	// Values a periodic snapshot holds are pruned from the value logs
+	snapshotted := func() SnapshotStatus {
+		report, err := engine.GetLogCompaction()
+		if err != nil {
+			t.Fatalf("Failed to report compaction: %v", err)
+		}
+		return report["snapshots"].(map[string]interface{})["tenants"].(map[string]SnapshotStatus)[tenantID]
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for snapshotted().PrunedValues == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status := snapshotted(); status.PrunedValues != 1 || status.SizeBytes == 0 || status.Error != "" {
+		t.Fatalf("Expected the snapshot to prune the logged value, got %+v", status)
+	}
+	if _, err := os.Stat(filepath.Join(config.SnapshotDir, "test-tenant.snap")); err != nil {
+		t.Errorf("Expected the tenant's snapshot written: %v", err)
+	}
+	
+	// Retained values outlive the snapshots that hold them
+	if err := engine.SetValueLogRetention("unknown-tenant", time.Hour); err == nil {
+		t.Error("Expected retention of an unknown tenant rejected")
+	}
+	if err := engine.SetValueLogRetention(tenantID, time.Hour); err != nil {
+		t.Fatalf("Failed to set retention: %v", err)
+	}
+	if retention, _ := engine.GetValueLogRetention(tenantID); retention != time.Hour {
+		t.Errorf("Expected an hour of retention, got %v", retention)
+	}
+	atom.SetAttentionValue(atomspace.AttentionValue{STI: 66})
+	for engine.valueLogs.count(tenantID) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := engine.valueLogs.count(tenantID); n != 1 {
+		t.Errorf("Expected the retained value kept in the logs, got %d", n)
+	}
+	report, _ := engine.GetLogCompaction()
+	shards := report["value_logs"].(map[string]interface{})["shards"].([]map[string]interface{})
+	for _, shard := range shards {
+		if _, ok := shard["size_bytes"]; !ok {
+			t.Errorf("Expected the size of each log reported, got %v", shard)
+		}
+	}
+	engine.Close()
+	
+	// The snapshot and the retained values restore the tenant
+	engine = NewCognitiveEngine(config)
+	defer engine.Close()
+	for engine.CheckStartup().Status != health.StatusOK && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	restored, err := engine.GetAtom(atom.GetID(), tenantID)
+	if err != nil {
+		t.Fatalf("Failed to get restored atom: %v", err)
+	}
+	if av := restored.GetAttentionValue(); av.STI != 66 {
+		t.Errorf("Expected the latest attention value restored, got %+v", av)
+	}
+	
+	purged, _ := engine.PurgeTenant(context.Background(), tenantID)
+	if purged.Removed["snapshot"] != 1 {
+		t.Errorf("Expected the periodic snapshot removed, got %+v", purged)
+	}
+	if _, err := os.Stat(filepath.Join(config.SnapshotDir, "test-tenant.snap")); !os.IsNotExist(err) {
+		t.Errorf("Expected the tenant's snapshot gone, got %v", err)
+	}
+}
+
+func TestStatsHistory(t *testing.T) {
+	config := DefaultConfig()
+	config.StatsHistory.Resolution = 10 * time.Millisecond
+	
+	engine := NewCognitiveEngine(config)
+	defer engine.Close()
+	tenantID := "test-tenant"
+	engine.InitializeTenant(tenantID)
+	engine.CreateConceptNode("ledger-db", tenantID)
+	engine.CreateConceptNode("ledger-api", tenantID)
+	
+	// The tenant's stats are sampled by their dotted path
+	query := trends.Query{Metric: "tenant.total_atoms"}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if points, err := engine.StatsHistory(tenantID, query); err == nil && len(points) >= 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	points, err := engine.StatsHistory(tenantID, query)
+	if err != nil {
+		t.Fatalf("Failed to query stats history: %v", err)
+	}
+	if len(points) < 2 || points[len(points)-1].Value != 2 {
+		t.Fatalf("Expected samples of the tenant's 2 atoms, got %v", points)
+	}
+	
+	// A steady counter has no throughput
+	query.Rate = true
+	rates, err := engine.StatsHistory(tenantID, query)
+	if err != nil {
+		t.Fatalf("Failed to query rate: %v", err)
+	}
+	for _, p := range rates {
+		if p.Value != 0 {
+			t.Errorf("Expected no growth, got %v", rates)
+		}
+	}
+	
+	if metrics := engine.StatsMetrics(""); len(metrics) == 0 {
+		t.Error("Expected engine metrics sampled")
+	}
+	if _, err := engine.StatsHistory(tenantID, trends.Query{Metric: "unknown"}); err == nil {
+		t.Error("Expected an unknown metric rejected")
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	notified := make(chan []incidents.Alert, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string][]incidents.Alert
+		json.NewDecoder(r.Body).Decode(&body)
+		notified <- body["alerts"]
+	}))
+	defer server.Close()
+	
+	config := DefaultConfig()
+	config.StatsHistory.Resolution = 10 * time.Millisecond
+	config.Watchdog.Enabled = true
+	config.Watchdog.Rules = []watchdog.Rule{{
+		Name:      "TenantAtomExplosion",
+		Metric:    "tenant.total_atoms",
+		Scope:     watchdog.ScopeTenants,
+		Condition: watchdog.ConditionIncrease,
+		Threshold: 2,
+		For:       time.Second,
+	}}
+	config.Watchdog.Channels = []watchdog.Channel{{Type: watchdog.ChannelWebhook, URL: server.URL}}
+	
+	engine := NewCognitiveEngine(config)
+	defer engine.Close()
+	tenantID := "test-tenant"
+	engine.InitializeTenant(tenantID)
+	time.Sleep(30 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		engine.CreateConceptNode(fmt.Sprintf("service-%d", i), tenantID)
+	}
+	
+	// The tenant's growth raises an alert in the system tenant
+	select {
+	case alerts := <-notified:
+		if len(alerts) != 1 || alerts[0].Subject != "erebus/tenant/test-tenant" || alerts[0].Status != incidents.AlertFiring {
+			t.Fatalf("Expected the tenant's alert notified, got %+v", alerts)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the growth notified")
+	}
+	report, err := engine.GetWatchdog()
+	if err != nil {
+		t.Fatalf("Failed to get watchdog: %v", err)
+	}
+	if firing := report["firing"].([]incidents.Alert); len(firing) != 1 {
+		t.Errorf("Expected one alert firing, got %+v", firing)
+	}
+	if opened := engine.ListIncidents(watchdog.DefaultSystemTenant, ""); len(opened) != 1 {
+		t.Errorf("Expected an incident in the system tenant, got %+v", opened)
+	}
+	
+	// The alert resolves once the growth leaves the rule's window
+	select {
+	case alerts := <-notified:
+		if len(alerts) != 1 || alerts[0].Status != incidents.AlertResolved {
+			t.Errorf("Expected the alert resolved, got %+v", alerts)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the resolution notified")
+	}
+	
+	disabled := NewCognitiveEngine(DefaultConfig())
+	defer disabled.Close()
+	if _, err := disabled.GetWatchdog(); err == nil {
+		t.Error("Expected the watchdog disabled by default")
+	}
+}
+
+func TestTruthValueDecay(t *testing.T) {
+	engine := NewCognitiveEngine(nil)
+	defer engine.Close()
+	tenantID := "test-tenant"
+	engine.InitializeTenant(tenantID)
+	
+	stale, _ := engine.CreateConceptNode("node/worker-1", tenantID)
+	fresh, _ := engine.CreateConceptNode("node/worker-2", tenantID)
+	stale.(*atomspace.Node).RestoreFreshness(time.Now().Add(-2*time.Hour), time.Time{})
+	
+	nodeType := atomspace.ConceptNodeType
+	if _, err := engine.SetDecayPolicy(tenantID, decay.Policy{Name: "observed-nodes", Type: &nodeType, HalfLife: time.Hour, Grace: time.Minute}); err != nil {
+		t.Fatalf("Failed to set decay policy: %v", err)
+	}
+	
+	// Only the atom last seen two hours ago loses confidence
+	run, err := engine.DecayTruthValues(tenantID)
+	if err != nil {
+		t.Fatalf("Failed to decay: %v", err)
+	}
+	if run.Decayed != 1 || run.Policy["observed-nodes"] != 1 {
+		t.Errorf("Expected one atom decayed, got %+v", run)
+	}
+	if c := stale.GetTruthValue().Confidence; c < 0.25 || c > 0.3 {
+		t.Errorf("Expected the stale atom's confidence near a quarter, got %v", c)
+	}
+	if c := fresh.GetTruthValue().Confidence; c != 1 {
+		t.Errorf("Expected the fresh atom's confidence kept, got %v", c)
+	}
+	
+	// Refreshing the observation restores its confidence and grace period
+	engine.UpdateAtom(stale.GetID(), tenantID, func(a atomspace.Atom) error {
+		a.SetTruthValue(atomspace.TruthValue{Strength: 1, Confidence: 0.9})
+		return nil
+	})
+	if run, _ := engine.DecayTruthValues(tenantID); run.Decayed != 0 {
+		t.Errorf("Expected no atom decayed after the refresh, got %+v", run)
+	}
+	if last, err := engine.GetDecayRun(tenantID); err != nil || last.Checked != 2 {
+		t.Errorf("Expected the last run kept, got %+v, %v", last, err)
+	}
+	
+	if _, err := engine.DecayTruthValues("unknown-tenant"); err == nil {
+		t.Error("Expected decay of an unknown tenant rejected")
+	}
+}
+
+func TestLinkTypes(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	relate := func(predicate string, a, b atomspace.Atom) {
+		pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, predicate, nil), predicate, tenantID, atomspace.PredicateNodeType)
+		engine.AddAtom(pred)
+		outgoing := []atomspace.Atom{pred, a, b}
+		engine.AddAtom(atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, predicate, outgoing), predicate, tenantID, atomspace.EvaluationLinkType, outgoing))
+	}
+	
+	// cart runs on n1 and peers with cache
+	cart, _ := engine.CreateConceptNode("service/cart", tenantID)
+	node, _ := engine.CreateConceptNode("node/n1", tenantID)
+	cache, _ := engine.CreateConceptNode("service/cache", tenantID)
+	relate("runs_on", cart, node)
+	relate("peers_with", cache, cart)
+	
+	// Without semantics, peers_with is traversed forward only
+	opts := linktypes.DefaultPathOptions()
+	if _, err := engine.FindPath(tenantID, "service/cart", "service/cache", opts); err == nil {
+		t.Error("Expected no path against an asymmetric relation")
+	}
+	if _, err := engine.SetLinkType(tenantID, linktypes.LinkType{Name: "peers_with", Weight: 0.5, Cost: 3, Symmetric: true}); err != nil {
+		t.Fatalf("Failed to set link type: %v", err)
+	}
+	path, err := engine.FindPath(tenantID, node.GetID(), "service/cache", linktypes.PathOptions{MaxHops: 3, Undirected: true})
+	if err != nil {
+		t.Fatalf("Failed to find path: %v", err)
+	}
+	if len(path.Hops) != 2 || path.Cost != 4 || !path.Hops[0].Reversed || path.Hops[1].Reversed {
+		t.Errorf("Expected n1 to reach cache through cart at cost 4, got %+v", path)
+	}
+	
+	// Impact propagates over the tenant's link types
+	result, err := engine.AnalyzeImpact(tenantID, "node/n1", impact.Options{Depth: 3})
+	if err != nil {
+		t.Fatalf("Failed to analyze impact: %v", err)
+	}
+	if len(result.Impacted) != 2 || result.Impacted[1].Name != "service/cache" {
+		t.Errorf("Expected cart and cache impacted, got %+v", result.Impacted)
+	}
+	if _, err := engine.SetLinkType(tenantID, linktypes.LinkType{Name: "runs_on", Weight: 0, Transitive: true}); err != nil {
+		t.Fatalf("Failed to override link type: %v", err)
+	}
+	if result, _ := engine.AnalyzeImpact(tenantID, "node/n1", impact.Options{Depth: 3}); len(result.Impacted) != 0 {
+		t.Errorf("Expected no impact over a relation weighted 0, got %+v", result.Impacted)
+	}
+	if err := engine.DeleteLinkType(tenantID, "runs_on"); err != nil {
+		t.Fatalf("Failed to delete link type: %v", err)
+	}
+	if lt, _ := engine.GetLinkType(tenantID, "runs_on"); lt.Weight == 0 {
+		t.Error("Expected the default runs_on restored")
+	}
+	if len(engine.ListLinkTypes(tenantID)) != len(linktypes.Defaults())+1 {
+		t.Errorf("Expected the defaults and peers_with, got %+v", engine.ListLinkTypes(tenantID))
+	}
+	
+	// Subjects inherit what their parents inherit from
+	service, _ := engine.CreateConceptNode("Service", tenantID)
+	payments, _ := engine.CreateConceptNode("PaymentService", tenantID)
+	engine.CreateInheritanceLink(payments.GetID(), service.GetID(), tenantID)
+	for i := 0; i < 3; i++ {
+		svc, _ := engine.CreateConceptNode(fmt.Sprintf("svc-%d", i), tenantID)
+		engine.CreateInheritanceLink(svc.GetID(), payments.GetID(), tenantID)
+		relate("peers_with", cache, svc)
+	}
+	patterns, err := engine.MinePatterns(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Mining failed: %v", err)
+	}
+	found := map[string]bool{}
+	for _, p := range patterns {
+		found[p.Antecedent+" => "+p.Consequent] = true
+	}
+	if !found["inherits($X, PaymentService) => inherits($X, Service)"] {
+		t.Errorf("Expected the inherited parent mined, got %+v", patterns)
+	}
+	if !found["inherits($X, PaymentService) => peers_with($X, service/cache)"] {
+		t.Errorf("Expected the symmetric relation mined, got %+v", patterns)
+	}
+}
+
+func TestFederatedQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"owner": "team-" + strings.TrimPrefix(r.URL.Path, "/nodes/")})
+	}))
+	defer server.Close()
+	
+	cfg := DefaultConfig()
+	cfg.Federation.AllowedHosts = []string{strings.TrimPrefix(server.URL, "http://")}
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	engine.CreateConceptNode("node/n1", tenantID)
+	engine.CreateConceptNode("node/n2", tenantID)
+	engine.CreateConceptNode("service/cart", tenantID)
+	
+	lookups := []federation.Lookup{{Name: "owners", TrimPrefix: "node/", Join: federation.JoinInner, HTTP: &federation.HTTPLookup{URL: server.URL + "/nodes/{key}"}}}
+	conceptType := atomspace.ConceptNodeType
+	if _, err := engine.SetSavedQuery(tenantID, queries.Query{Name: "concepts", Query: atomspace.Query{Type: &conceptType}}); err != nil {
+		t.Fatalf("Failed to save query: %v", err)
+	}
+	result, err := engine.FederatedQuery(context.Background(), tenantID, federation.Query{SavedQuery: "concepts", Lookups: lookups})
+	if err != nil {
+		t.Fatalf("Federated query failed: %v", err)
+	}
+	if len(result.Rows) != 3 || result.Lookups[0].Requests != 3 {
+		t.Fatalf("Expected every concept looked up once, got %+v", result)
+	}
+	owners := map[string]interface{}{}
+	for _, row := range result.Rows {
+		owners[row.Atom.GetName()] = row.Data["owners"]["owner"]
+	}
+	if owners["node/n1"] != "team-n1" || owners["service/cart"] != "team-service/cart" {
+		t.Errorf("Expected owners joined by trimmed name, got %v", owners)
+	}
+	
+	if _, err := engine.FederatedQuery(context.Background(), tenantID, federation.Query{SavedQuery: "missing", Lookups: lookups}); err == nil {
+		t.Error("Expected a missing saved query to fail")
+	}
+	lookups[0].HTTP.URL = "http://elsewhere.invalid/{key}"
+	if _, err := engine.FederatedQuery(context.Background(), tenantID, federation.Query{Lookups: lookups}); err == nil {
+		t.Error("Expected a host that is not allowed rejected")
+	}
+	if len(engine.FederationConnections(tenantID)) != 0 {
+		t.Error("Expected no SQL connections by default")
+	}
+}
+
+func TestGroundedEvaluation(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	measure := func(host string, value float64) atomspace.Atom {
+		pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, "cpu_usage", nil), "cpu_usage", tenantID, atomspace.PredicateNodeType)
+		engine.AddAtom(pred)
+		node, _ := engine.CreateConceptNode(host, tenantID)
+		number := atomspace.NewNumberNode(value, tenantID)
+		engine.AddAtom(number)
+		outgoing := []atomspace.Atom{pred, node, number}
+		link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, "cpu_usage", outgoing), "cpu_usage", tenantID, atomspace.EvaluationLinkType, outgoing)
+		if err := engine.AddAtom(link); err != nil {
+			t.Fatalf("Failed to add measurement: %v", err)
+		}
+		return link
+	}
+	
+	// CPU > 0.9, evaluated as it is added
+	threshold := 0.9
+	web := measure("web-1", 0.95)
+	atom, err := engine.AddGroundedEvaluation(tenantID, inference.PredicateGreaterThan, []GroundedArgument{{AtomID: web.GetID()}, {Number: &threshold}})
+	if err != nil {
+		t.Fatalf("Failed to add grounded evaluation: %v", err)
+	}
+	if atom.GetTruthValue().Strength != 1 {
+		t.Errorf("Expected cpu > 0.9 to hold, got %+v", atom.GetTruthValue())
+	}
+	if _, err := engine.GetAtom(atomspace.NewNumberNode(threshold, tenantID).GetID(), tenantID); err != nil {
+		t.Errorf("Expected the number node added: %v", err)
+	}
+	
+	// An evaluation stated true is corrected by inference
+	db := measure("db-1", 0.5)
+	gpn := atomspace.NewGroundedPredicateNode(inference.PredicateGreaterThan, tenantID)
+	outgoing := []atomspace.Atom{gpn, db, atomspace.NewNumberNode(threshold, tenantID)}
+	link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, gpn.GetName(), outgoing), gpn.GetName(), tenantID, atomspace.EvaluationLinkType, outgoing)
+	if err := engine.AddAtom(link); err != nil {
+		t.Fatalf("Failed to add evaluation: %v", err)
+	}
+	if _, err := engine.RunInference(context.Background(), tenantID, 5); err != nil {
+		t.Fatalf("Inference failed: %v", err)
+	}
+	if atom, _ := engine.GetAtom(link.GetID(), tenantID); atom.GetTruthValue().Strength != 0 {
+		t.Errorf("Expected cpu > 0.9 not to hold on db-1, got %+v", atom.GetTruthValue())
+	}
+	
+	// Nothing is added for evaluations that cannot be computed
+	zero, one := 0.0, 1.0
+	args := []GroundedArgument{{Function: inference.FunctionQuotient, Arguments: []GroundedArgument{{Number: &one}, {Number: &zero}}}, {Number: &one}}
+	if _, err := engine.AddGroundedEvaluation(tenantID, inference.PredicateEqual, args); err == nil {
+		t.Error("Expected a division by zero rejected")
+	}
+	if _, err := engine.GetAtom(atomspace.NewNumberNode(zero, tenantID).GetID(), tenantID); err == nil {
+		t.Error("Expected no atoms added for a rejected evaluation")
+	}
+	for _, invalid := range [][]GroundedArgument{
+		{{Number: &one}},
+		{{Number: &one}, {Number: &one, AtomID: web.GetID()}},
+		{{Function: "modulo", Arguments: []GroundedArgument{{Number: &one}}}, {Number: &one}},
+		{{AtomID: "missing"}, {Number: &one}},
+	} {
+		if _, err := engine.AddGroundedEvaluation(tenantID, inference.PredicateEqual, invalid); err == nil {
+			t.Errorf("Expected %+v rejected", invalid)
+		}
+	}
+}
+
+func TestSchemaExecution(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	
+	// scale returns the number of replicas it set; flaky fails once
+	var scaled []float64
+	err := engine.RegisterSchema(schemas.Schema{Name: "scale", Arity: 2, Func: func(ctx context.Context, call schemas.Call) (atomspace.Atom, error) {
+		replicas, ok := atomspace.NumberValue(call.Arguments[1])
+		if !ok {
+			return nil, fmt.Errorf("replicas must be a number")
+		}
+		scaled = append(scaled, replicas)
+		return atomspace.NewNumberNode(replicas, call.TenantID), nil
+	}})
+	if err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+	flakyCalls := 0
+	engine.RegisterSchema(schemas.Schema{Name: "flaky", Func: func(ctx context.Context, call schemas.Call) (atomspace.Atom, error) {
+		flakyCalls++
+		if flakyCalls == 1 {
+			return nil, fmt.Errorf("unavailable")
+		}
+		return nil, nil
+	}})
+	
+	web, _ := engine.CreateConceptNode("deployment/web", tenantID)
+	three := 3.0
+	call, err := engine.AddExecution(tenantID, "scale", []GroundedArgument{{AtomID: web.GetID()}, {Number: &three}})
+	if err != nil {
+		t.Fatalf("Failed to add execution: %v", err)
+	}
+	if _, err := engine.AddExecution(tenantID, "flaky", nil); err != nil {
+		t.Fatalf("Failed to add execution: %v", err)
+	}
+	if _, err := engine.AddExecution(tenantID, "missing", nil); err == nil {
+		t.Error("Expected calls of unknown schemas rejected")
+	}
+	
+	results, err := engine.ExecuteSchemas(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	if len(results) != 2 || len(scaled) != 1 || scaled[0] != 3 {
+		t.Fatalf("Expected both calls executed and web scaled to 3, got %+v", results)
+	}
+	
+	// The call is recorded with its output and not executed again
+	outputs := engine.QueryAtoms(tenantID, func(atom atomspace.Atom) bool {
+		link, ok := atom.(*atomspace.Link)
+		return ok && atom.GetName() == agents.ExecutionOutputPredicate && link.Outgoing[1].GetID() == call.GetID()
+	})
+	if len(outputs) != 1 || outputs[0].(*atomspace.Link).Outgoing[2].GetName() != "3" {
+		t.Errorf("Expected the output of the call recorded, got %v", outputs)
+	}
+	
+	// The flaky call is retried on the next run, alone
+	results, _ = engine.ExecuteSchemas(context.Background(), tenantID)
+	if len(results) != 1 || results[0].Schema != "flaky" || results[0].Outcome != schemas.OutcomeSucceeded || results[0].Attempt != 2 {
+		t.Errorf("Expected the flaky call to succeed on its second attempt, got %+v", results)
+	}
+	if results, _ := engine.ExecuteSchemas(context.Background(), tenantID); len(results) != 0 {
+		t.Errorf("Expected nothing left to execute, got %+v", results)
+	}
+	if history := engine.GetExecutionResults(tenantID); len(history) != 3 {
+		t.Errorf("Expected 3 results in the history, got %d", len(history))
+	}
+	
+	// Calls of schemas unregistered since wait for them
+	engine.UnregisterSchema("scale")
+	if list := engine.ListSchemas(tenantID); len(list) != 1 {
+		t.Errorf("Expected one schema left, got %d", len(list))
+	}
+	gsn := atomspace.NewGroundedSchemaNode("scale", tenantID)
+	number := atomspace.NewNumberNode(2, tenantID)
+	engine.AddAtom(gsn)
+	engine.AddAtom(number)
+	outgoing := []atomspace.Atom{gsn, web, number}
+	engine.AddAtom(atomspace.NewLink(atomspace.GenerateAtomID(atomspace.ExecutionLinkType, "scale", outgoing), "scale", tenantID, atomspace.ExecutionLinkType, outgoing))
+	if results, _ := engine.ExecuteSchemas(context.Background(), tenantID); len(results) != 0 {
+		t.Errorf("Expected the call to wait for its schema, got %+v", results)
+	}
+}
+
+func TestCMDBSync(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	var mu sync.Mutex
+	var writes []string
+	withDB := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/now/table/cmdb_ci":
+			items := `{"sys_id": "a1", "name": "web-1", "sys_class_name": "cmdb_ci_server", "object_id": "i-1", "ip_address": "10.0.0.1"}`
+			if withDB {
+				items += `, {"sys_id": "b2", "name": "db-1", "sys_class_name": "cmdb_ci_server", "object_id": "i-2"}`
+			}
+			fmt.Fprintf(w, `{"result": [%s]}`, items)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/now/table/cmdb_rel_ci":
+			w.Write([]byte(`{"result": [{"parent": "b2", "child": "a1", "type.name": "Used by::Depends on"}]}`))
+		default:
+			writes = append(writes, r.Method+" "+r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	
+	mapping := cmdb.Mapping{Attributes: map[string]string{"id": "object_id", "ip_address": "ip_address"}}
+	if _, err := engine.SetCMDBSource(tenantID, cmdb.Source{Name: "sn", Kind: "jira", URL: server.URL}); err == nil {
+		t.Error("Expected a source of an unknown kind to be rejected")
+	}
+	if _, err := engine.SetCMDBSource(tenantID, cmdb.Source{Name: "sn", Kind: cmdb.KindServiceNow, URL: server.URL, Mapping: mapping, WriteBack: cmdb.WriteBackApply}); err != nil {
+		t.Fatalf("Failed to set source: %v", err)
+	}
+	statuses, err := engine.SyncCMDB(context.Background(), tenantID, false, false)
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Items != 2 || statuses[0].Relationships != 1 || len(statuses[0].Updates) != 0 {
+		t.Fatalf("Unexpected status: %+v", statuses)
+	}
+	
+	concept := func(name string) atomspace.Atom {
+		atom, err := engine.GetAtom(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), tenantID)
+		if err != nil {
+			t.Fatalf("Expected concept %s: %v", name, err)
+		}
+		return atom
+	}
+	web, db := concept("cmdb:web-1"), concept("cmdb:db-1")
+	relation := func(predicate string, args ...atomspace.Atom) string {
+		pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, predicate, nil), predicate, tenantID, atomspace.PredicateNodeType)
+		return atomspace.GenerateAtomID(atomspace.EvaluationLinkType, predicate, append([]atomspace.Atom{pred}, args...))
+	}
+	if _, err := engine.GetAtom(relation("used_by", db, web), tenantID); err != nil {
+		t.Errorf("Expected used_by(db-1, web-1): %v", err)
+	}
+	
+	// Drift of a recorded attribute and a relation the CMDB lacks are
+	// planned as updates, and only reported by a dry run
+	if err := engine.RecordObservations(tenantID, "ec2:i-1", "", map[string]string{"id": "i-1", "ip_address": "10.0.0.9"}); err != nil {
+		t.Fatalf("Failed to record observations: %v", err)
+	}
+	if _, err := engine.DetectDrift(context.Background(), tenantID); err != nil {
+		t.Fatalf("Failed to detect drift: %v", err)
+	}
+	pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, "depends_on", nil), "depends_on", tenantID, atomspace.PredicateNodeType)
+	engine.AddAtom(pred)
+	outgoing := []atomspace.Atom{pred, web, db}
+	discovered := atomspace.NewLink(relation("depends_on", web, db), "depends_on", tenantID, atomspace.EvaluationLinkType, outgoing)
+	discovered.SetTruthValue(atomspace.TruthValue{Strength: 0.9, Confidence: 0.8})
+	if err := engine.AddAtom(discovered); err != nil {
+		t.Fatalf("Failed to add relation: %v", err)
+	}
+	
+	statuses, _ = engine.SyncCMDB(context.Background(), tenantID, false, true)
+	if updates := statuses[0].Updates; len(updates) != 2 || updates[0].Applied || updates[1].Applied {
+		t.Fatalf("Expected two planned updates, got %+v", updates)
+	}
+	if len(writes) != 0 {
+		t.Errorf("Expected a dry run to write nothing, got %v", writes)
+	}
+	statuses, _ = engine.SyncCMDB(context.Background(), tenantID, false, false)
+	updates := statuses[0].Updates
+	if len(updates) != 2 || !updates[0].Applied || updates[0].Field != "ip_address" || updates[0].Value != "10.0.0.9" ||
+		!updates[1].Applied || updates[1].Type != "Depends on::Used by" {
+		t.Fatalf("Expected both updates applied, got %+v", updates)
+	}
+	if len(writes) != 2 || writes[0] != "PATCH /api/now/table/cmdb_ci/a1" || writes[1] != "POST /api/now/table/cmdb_rel_ci" {
+		t.Errorf("Unexpected writes: %v", writes)
+	}
+	if statuses, _ = engine.SyncCMDB(context.Background(), tenantID, false, false); len(statuses[0].Updates) != 0 {
+		t.Errorf("Expected applied updates not to be repeated, got %+v", statuses[0].Updates)
+	}
+	
+	// CIs that leave the CMDB are retired
+	mu.Lock()
+	withDB = false
+	mu.Unlock()
+	engine.SyncCMDB(context.Background(), tenantID, false, false)
+	if concept("cmdb:db-1").GetTruthValue().Strength != 0 {
+		t.Error("Expected the removed CI retired")
+	}
+	if atom, err := engine.GetAtom(relation("used_by", db, web), tenantID); err != nil || atom.GetTruthValue().Strength != 0 {
+		t.Error("Expected the relationship of the removed CI retired")
+	}
+	
+	if err := engine.RemoveCMDBSource(tenantID, "sn"); err != nil {
+		t.Fatalf("Failed to remove source: %v", err)
+	}
+	if err := engine.DisableCMDB(tenantID); err != nil {
+		t.Fatalf("Failed to disable: %v", err)
+	}
+}
+
+func TestChangeCorrelation(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	if err := engine.SetChangeHook(tenantID, changes.Hook{Secret: "s3cret-token"}); err != nil {
+		t.Fatalf("Failed to set change hook: %v", err)
+	}
+	
+	now := time.Now()
+	recorded, err := engine.RecordChanges(context.Background(), tenantID, []changes.Event{
+		{ID: "deploy-1", Kind: changes.KindDeployment, Repository: "acme/web", Environment: "production", At: now.Add(-10 * time.Minute)},
+		{ID: "deploy-old", Kind: changes.KindDeployment, Repository: "acme/web", Environment: "production", At: now.Add(-5 * time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to record changes: %v", err)
+	}
+	if len(recorded) != 2 || recorded[0].Services[0] != "service/web" {
+		t.Fatalf("Expected changes affecting service/web, got %+v", recorded)
+	}
+	
+	alerts := []incidents.Alert{{Name: "HighErrorRate", Subject: "service/web", Severity: "critical", StartsAt: now}}
+	result, err := engine.IngestAlerts(context.Background(), tenantID, alerts)
+	if err != nil || len(result) != 1 {
+		t.Fatalf("Expected 1 incident, got %+v (%v)", result, err)
+	}
+	
+	// Only the deployment within the change window is a possible cause
+	causes := engine.QueryAtoms(tenantID, func(a atomspace.Atom) bool {
+		return a.GetType() == atomspace.EvaluationLinkType && a.GetName() == "possibly_caused_by"
+	})
+	if len(causes) != 1 {
+		t.Fatalf("Expected 1 possibly_caused_by link, got %d", len(causes))
+	}
+	cause := causes[0].(*atomspace.Link).GetOutgoing()[2]
+	if cause.GetName() != changes.ChangeName("deploy-1") {
+		t.Errorf("Expected deploy-1 as the cause, got %s", cause.GetName())
+	}
+	if tv := causes[0].GetTruthValue(); tv.Strength < 0.9 || tv.Confidence > 0.7 {
+		t.Errorf("Expected a strong but uncertain cause, got %+v", tv)
+	}
+	
+	inc, _ := engine.GetIncident(tenantID, result[0].ID)
+	found := false
+	for _, e := range inc.Timeline {
+		if e.Kind == "change" && e.Rule == incidents.ChangeRuleName {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a change entry on the incident timeline: %+v", inc.Timeline)
+	}
+	
+	if list := engine.ListChanges(tenantID, now.Add(-time.Hour)); len(list) != 1 {
+		t.Errorf("Expected 1 change in the last hour, got %d", len(list))
+	}
+}
+
+func TestSecretReferences(t *testing.T) {
+	var delivered int
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered++
+	}))
+	defer webhook.Close()
+	t.Setenv("EREBUS_SECRET_TEST_TENANT_GITHUB_HOOK", "hook-secret-value")
+	t.Setenv("EREBUS_SECRET_TEST_TENANT_DIGEST_WEBHOOK", webhook.URL)
+	
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	
+	// A change hook verifies webhooks with the secret its reference names
+	if err := engine.SetChangeHook(tenantID, changes.Hook{Secret: "secret://env/github-hook"}); err != nil {
+		t.Fatalf("Failed to set change hook: %v", err)
+	}
+	body := []byte(`{"deployment_status": {"state": "success", "environment": "production"}, "deployment": {"id": 1}, "repository": {"full_name": "acme/web"}}`)
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "deployment_status")
+	sign := func(secret string) {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	sign("secret://env/github-hook")
+	if _, err := engine.IngestChangeWebhook(context.Background(), tenantID, changes.ProviderGitHub, header, body); !errors.Is(err, changes.ErrUnauthorized) {
+		t.Errorf("Expected a webhook signed with the reference itself to be unauthorized, got %v", err)
+	}
+	sign("hook-secret-value")
+	if recorded, err := engine.IngestChangeWebhook(context.Background(), tenantID, changes.ProviderGitHub, header, body); err != nil || len(recorded) != 1 {
+		t.Errorf("Expected the webhook signed with the resolved secret recorded, got %v (%v)", recorded, err)
+	}
+	
+	// Report sinks are delivered to resolved URLs but listed as configured
+	_, err := engine.SetReport(tenantID, reports.Schedule{
+		Name:     "daily",
+		Sections: []reports.Section{reports.SectionAgents},
+		Sinks: []reports.Sink{
+			{Type: reports.SinkWebhook, URL: "secret://env/digest-webhook"},
+			{Type: reports.SinkEmail, SMTPAddr: "smtp.example.com:587", From: "erebus@example.com", To: []string{"ops@example.com"}, Username: "erebus", Password: "plain-password"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set report: %v", err)
+	}
+	report, err := engine.GenerateReport(context.Background(), tenantID, "daily")
+	if err != nil {
+		t.Fatalf("Failed to generate report: %v", err)
+	}
+	if delivered != 1 || report.Deliveries[0].Error != "" || report.Deliveries[0].Target != "secret://env/digest-webhook" {
+		t.Errorf("Expected delivery to the resolved webhook, got %+v", report.Deliveries)
+	}
+	
+	// Exports keep references and mask literal credentials
+	export, err := engine.ExportTenant(tenantID)
+	if err != nil {
+		t.Fatalf("Failed to export tenant: %v", err)
+	}
+	sinks := export.Reports[0].Sinks
+	if sinks[0].URL != "secret://env/digest-webhook" || sinks[1].Password != secrets.Mask {
+		t.Errorf("Expected redacted sinks in the export, got %+v", sinks)
+	}
+	
+	checks := engine.CheckSecrets(context.Background(), tenantID, []string{"secret://env/github-hook", "secret://env/missing", "plain"})
+	if !checks[0].Resolved || checks[1].Resolved || checks[2].Resolved {
+		t.Errorf("Unexpected secret checks: %+v", checks)
+	}
+	for _, check := range checks {
+		if strings.Contains(check.Error, "hook-secret-value") {
+			t.Errorf("Expected no secret value in checks: %+v", check)
+		}
+	}
+}
+
+func TestConnectors(t *testing.T) {
+	billing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer billing.Close()
+	
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	state := `{"version":4,"serial":1,"lineage":"l1","resources":[{"mode":"managed","type":"aws_vpc","name":"main",
+		"provider":"provider[\"registry.terraform.io/hashicorp/aws\"]","instances":[{"attributes":{"id":"vpc-1"}}]}]}`
+	if err := os.WriteFile(path, []byte(state), 0o600); err != nil {
+		t.Fatalf("Failed to write state: %v", err)
+	}
+	
+	if _, err := engine.SetConnector(tenantID, connectors.Spec{Name: "prod", Kind: ConnectorTerraform, Settings: json.RawMessage(`{}`)}); err == nil {
+		t.Error("Expected a Terraform connector without a location to be rejected")
+	}
+	settings, _ := json.Marshal(map[string]string{"path": path})
+	if _, err := engine.SetConnector(tenantID, connectors.Spec{Name: "prod", Kind: ConnectorTerraform, Settings: settings}); err != nil {
+		t.Fatalf("Failed to set connector: %v", err)
+	}
+	settings, _ = json.Marshal(map[string]string{"url": billing.URL})
+	if _, err := engine.SetConnector(tenantID, connectors.Spec{Name: "billing", Kind: ConnectorCost, Settings: settings}); err != nil {
+		t.Fatalf("Failed to set connector: %v", err)
+	}
+	if engine.connectorAgents[tenantID] == nil {
+		t.Error("Expected a connector agent for the tenant")
+	}
+	
+	status, err := engine.SyncConnector(context.Background(), tenantID, "prod")
+	if err != nil || !status.Healthy || status.Details == nil {
+		t.Fatalf("Expected a healthy Terraform connector, got %+v (%v)", status, err)
+	}
+	if _, err := engine.GetAtom(atomspace.GenerateAtomID(atomspace.ConceptNodeType, "tf:aws_vpc.main", nil), tenantID); err != nil {
+		t.Errorf("Expected the connector to mirror the state: %v", err)
+	}
+	
+	// A failing connector backs off without affecting the others
+	if status, err = engine.SyncConnector(context.Background(), tenantID, "billing"); err == nil || status.State != connectors.StateBackoff {
+		t.Errorf("Expected the billing connector backing off, got %+v (%v)", status, err)
+	}
+	list := engine.ListConnectors(tenantID)
+	if len(list) != 2 || list[0].Name != "billing" || list[1].State != connectors.StateRunning {
+		t.Errorf("Unexpected connectors: %+v", list)
+	}
+	if status, _ = engine.StopConnector(tenantID, "billing"); status.State != connectors.StateStopped {
+		t.Errorf("Expected the billing connector stopped, got %+v", status)
+	}
+	
+	// The agent goes with the last connector
+	engine.SetConnector("other-tenant", connectors.Spec{Name: "billing", Kind: ConnectorCost, Settings: settings})
+	if err := engine.RemoveConnector("other-tenant", "billing"); err != nil || engine.connectorAgents["other-tenant"] != nil {
+		t.Errorf("Expected the connector agent removed with the last connector (%v)", err)
+	}
+	if err := engine.RemoveConnector(tenantID, "billing"); err != nil || engine.connectorAgents[tenantID] == nil {
+		t.Errorf("Expected the connector agent kept for the remaining connector (%v)", err)
+	}
+	
+	report, err := engine.PurgeTenant(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Failed to purge tenant: %v", err)
+	}
+	if report.Removed["connectors"] != 1 || len(engine.ListConnectors(tenantID)) != 0 || engine.connectorAgents[tenantID] != nil {
+		t.Errorf("Expected the connector purged, got %v", report.Removed)
+	}
+}
+
+func TestSandbox(t *testing.T) {
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+	
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	if _, err := engine.SetSandbox(tenantID, sandbox.Config{Enabled: true, Terraform: map[string]json.RawMessage{"prod": json.RawMessage(`{"version":3}`)}}); err == nil {
+		t.Error("Expected an invalid canned state to be rejected")
+	}
+	state := `{"version":4,"serial":1,"lineage":"l1","resources":[{"mode":"managed","type":"aws_vpc","name":"main",
+		"provider":"provider[\"registry.terraform.io/hashicorp/aws\"]","instances":[{"attributes":{"id":"vpc-1"}}]}]}`
+	_, err := engine.SetSandbox(tenantID, sandbox.Config{
+		Enabled:   true,
+		Terraform: map[string]json.RawMessage{"prod": json.RawMessage(state)},
+		CMDB:      map[string]*cmdb.Snapshot{"sn": {Items: []cmdb.CI{{ID: "a1", Name: "web-1"}}}},
+		Rates:     []cost.Rate{{Category: "compute", Hourly: 0.5}},
+		Webhooks:  map[string]int{server.URL + "/down": http.StatusServiceUnavailable},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set sandbox: %v", err)
+	}
+	
+	// Connectors read canned data whatever their settings point at
+	settings, _ := json.Marshal(map[string]string{"path": filepath.Join(t.TempDir(), "missing.tfstate")})
+	engine.SetConnector(tenantID, connectors.Spec{Name: "prod", Kind: ConnectorTerraform, Settings: settings})
+	settings, _ = json.Marshal(map[string]string{"kind": "servicenow", "url": server.URL, "token": "secret://env/MISSING"})
+	engine.SetConnector(tenantID, connectors.Spec{Name: "sn", Kind: ConnectorCMDB, Settings: settings})
+	settings, _ = json.Marshal(map[string]string{"url": server.URL})
+	engine.SetConnector(tenantID, connectors.Spec{Name: "billing", Kind: ConnectorCost, Settings: settings})
+	for _, name := range []string{"prod", "sn", "billing"} {
+		if status, err := engine.SyncConnector(context.Background(), tenantID, name); err != nil || !status.Healthy {
+			t.Errorf("Expected connector %s to sync from the sandbox, got %+v (%v)", name, status, err)
+		}
+	}
+	if _, err := engine.GetAtom(atomspace.GenerateAtomID(atomspace.ConceptNodeType, "tf:aws_vpc.main", nil), tenantID); err != nil {
+		t.Errorf("Expected the canned state mirrored: %v", err)
+	}
+	if _, err := engine.GetAtom(atomspace.GenerateAtomID(atomspace.ConceptNodeType, "cmdb:web-1", nil), tenantID); err != nil {
+		t.Errorf("Expected the canned snapshot mirrored: %v", err)
+	}
+	if rates := engine.GetCostRates(tenantID); len(rates) != 1 {
+		t.Errorf("Expected the canned rates, got %+v", rates)
+	}
+	
+	// Webhooks are recorded and answered with their canned status;
+	// pipelines still run
+	actions := engine.sandbox.ForTenant(tenantID).Actions(engine.triggerManager)
+	if err := actions.Perform(context.Background(), triggers.Action{Type: triggers.ActionWebhook, URL: server.URL + "/hook"}, nil, map[string]interface{}{"n": 1}); err != nil {
+		t.Errorf("Expected the webhook answered by the sandbox: %v", err)
+	}
+	if err := actions.Perform(context.Background(), triggers.Action{Type: triggers.ActionWebhook, URL: server.URL + "/down"}, nil, nil); err == nil {
+		t.Error("Expected the canned failure of the webhook")
+	}
+	if err := actions.Perform(context.Background(), triggers.Action{Type: triggers.ActionExecutePipeline, PipelineID: "missing"}, nil, nil); err == nil {
+		t.Error("Expected the pipeline action run, failing for a missing pipeline")
+	}
+	if hits.Load() != 0 {
+		t.Errorf("Expected no request to reach the server, got %d", hits.Load())
+	}
+	
+	requests := engine.SandboxRequests(tenantID)
+	types := make([]string, len(requests))
+	for i, req := range requests {
+		types[i] = req.Type
+	}
+	want := []string{sandbox.RequestTerraformLoad, sandbox.RequestCMDBFetch, sandbox.RequestCostRates,
+		sandbox.RequestWebhook, sandbox.RequestWebhook, sandbox.RequestExecutePipeline}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected requests %v, got %v", want, types)
+	}
+	if requests[3].Status != http.StatusOK || requests[4].Status != http.StatusServiceUnavailable {
+		t.Errorf("Unexpected webhook requests: %+v", requests[3:5])
+	}
+	
+	// Leaving sandbox mode reaches the real systems again
+	engine.SetSandbox(tenantID, sandbox.Config{Enabled: false})
+	if err := actions.Perform(context.Background(), triggers.Action{Type: triggers.ActionWebhook, URL: server.URL + "/hook"}, nil, nil); err != nil || hits.Load() != 1 {
+		t.Errorf("Expected the webhook posted once out of the sandbox, got %d (%v)", hits.Load(), err)
+	}
+	
+	report, err := engine.PurgeTenant(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Failed to purge tenant: %v", err)
+	}
+	if report.Removed["sandbox_requests"] != len(want) {
+		t.Errorf("Expected the sandbox purged, got %v", report.Removed)
+	}
+	if _, err := engine.GetSandbox(tenantID); err == nil {
+		t.Error("Expected no sandbox after purging the tenant")
+	}
+}
+
+func TestAgentDebugger(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	// Only runs made by the test change the atoms
+	engine.agentScheduler.SetStandby(true)
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	node := atomspace.NewNode("n1", "server", tenantID, atomspace.ConceptNodeType)
+	node.SetTruthValue(atomspace.TruthValue{Strength: 0.9, Confidence: 0.9})
+	if err := engine.AddAtom(node); err != nil {
+		t.Fatalf("Failed to add atom: %v", err)
+	}
+	agent := agents.NewAttentionAgent("attention-1", "Attention", tenantID, &tenantAtomSpaceWrapper{engine: engine, tenantID: tenantID})
+	engine.RegisterAgent(agent)
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, exists := engine.GetAgent(agent.GetID()); exists {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	
+	if err := engine.SetAgentDebug("other-tenant", agent.GetID(), true); err == nil {
+		t.Error("Expected another tenant's agent to be refused")
+	}
+	
+	rec, err := engine.StepAgent(context.Background(), tenantID, agent.GetID())
+	if err != nil {
+		t.Fatalf("Failed to step agent: %v", err)
+	}
+	if !rec.Step || rec.Sequence != 1 {
+		t.Errorf("Expected step recording 1, got %+v", rec)
+	}
+	if len(rec.Focus) != 1 || rec.Focus[0].AtomID != "n1" {
+		t.Errorf("Expected the focus to hold n1, got %+v", rec.Focus)
+	}
+	if len(rec.Decisions) != 1 || rec.Decisions[0].Kind != "attention" || rec.Decisions[0].Details["boosted"] != 1 {
+		t.Errorf("Expected an attention decision boosting one atom, got %+v", rec.Decisions)
+	}
+	if len(rec.Changes) != 1 || rec.Changes[0].Kind != debugger.ChangeAttention || rec.Changes[0].After.STI != 10 {
+		t.Errorf("Expected n1's STI to rise to 10, got %+v", rec.Changes)
+	}
+	
+	// Other runs are only recorded in debug mode
+	if err := engine.agentScheduler.RunAgent(context.Background(), agent.GetID()); err != nil {
+		t.Fatalf("Failed to run agent: %v", err)
+	}
+	if recordings, _ := engine.GetAgentRecordings(tenantID, agent.GetID()); len(recordings) != 1 {
+		t.Errorf("Expected only the step to be recorded, got %d recordings", len(recordings))
+	}
+	
+	if err := engine.SetAgentDebug(tenantID, agent.GetID(), true); err != nil {
+		t.Fatalf("Failed to enable debug mode: %v", err)
+	}
+	if err := engine.agentScheduler.RunAgent(context.Background(), agent.GetID()); err != nil {
+		t.Fatalf("Failed to run agent: %v", err)
+	}
+	rec, err = engine.GetAgentRecording(tenantID, agent.GetID(), 2)
+	if err != nil {
+		t.Fatalf("Failed to get recording: %v", err)
+	}
+	if rec.Step || rec.Focus[0].STI == 0 {
+		t.Errorf("Expected a recorded run seeing the raised STI, got %+v", rec)
+	}
+	
+	report, err := engine.PurgeTenant(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Failed to purge tenant: %v", err)
+	}
+	if report.Removed["agent_recordings"] != 2 {
+		t.Errorf("Expected 2 recordings purged, got %d", report.Removed["agent_recordings"])
+	}
+}
+
+func TestAnalyzeRules(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	
+	analysis, err := engine.AnalyzeRules(tenantID, nil)
+	if err != nil {
+		t.Fatalf("Failed to analyze rules: %v", err)
+	}
+	if len(analysis.Rules) != 6 || len(analysis.Unanalyzed) != 0 || analysis.HasErrors() {
+		t.Errorf("Expected the 6 default rules analyzed without errors, got %+v", analysis)
+	}
+	unreachable := make(map[string]bool)
+	for _, u := range analysis.Unreachable {
+		unreachable[u.Rule] = true
+	}
+	// Alerts correlate into incidents whose inheritance links feed
+	// deduction, but no change events were recorded
+	if unreachable[incidents.CorrelationRuleName] || unreachable["deduction"] || !unreachable[incidents.ChangeRuleName] {
+		t.Errorf("Expected only rules without atoms to be unreachable, got %+v", analysis.Unreachable)
+	}
+	
+	if _, err := engine.AnalyzeRules(tenantID, []string{"magic"}); err == nil {
+		t.Error("Expected an unknown rule to be rejected")
+	}
+	analysis, err = engine.AnalyzeRules(tenantID, []string{"deduction", "induction"})
+	if err != nil {
+		t.Fatalf("Failed to analyze proposed rules: %v", err)
+	}
+	if len(analysis.Rules) != 2 || len(analysis.Overlaps) != 1 {
+		t.Errorf("Expected the proposed rules to overlap, got %+v", analysis)
+	}
+}
+
+func TestInferenceLimits(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	if err := engine.SetInferenceLimits(tenantID, inference.Limits{MaxDepth: -1}); err == nil {
+		t.Error("Expected a negative depth to be rejected")
+	}
+	if err := engine.SetInferenceLimits(tenantID, inference.Limits{MaxDepth: 1, DetectCycles: true}); err != nil {
+		t.Fatalf("Failed to set limits: %v", err)
+	}
+	
+	// a->b->c->d->e: a->c, b->d and c->e are one derivation deep
+	var previous atomspace.Atom
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		node := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, tenantID, atomspace.ConceptNodeType)
+		if err := engine.AddAtom(node); err != nil {
+			t.Fatalf("Failed to add atom: %v", err)
+		}
+		if previous != nil {
+			outgoing := []atomspace.Atom{previous, node}
+			link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", outgoing), "inheritance", tenantID, atomspace.InheritanceLinkType, outgoing)
+			link.SetTruthValue(atomspace.TruthValue{Strength: 0.9, Confidence: 0.9})
+			if err := engine.AddAtom(link); err != nil {
+				t.Fatalf("Failed to add link: %v", err)
+			}
+		}
+		previous = node
+	}
+	
+	var report inference.RunReport
+	if _, err := engine.RunInference(inference.WithReport(context.Background(), &report), tenantID, 10); err != nil {
+		t.Fatalf("Failed to run inference: %v", err)
+	}
+	inheritance := 0
+	for _, atom := range engine.QueryAtoms(tenantID, nil) {
+		if atom.GetType() == atomspace.InheritanceLinkType {
+			inheritance++
+		}
+	}
+	if report.Status != inference.StatusDepthLimit || report.Depth != 1 || inheritance != 7 {
+		t.Errorf("Expected 3 links derived within the depth limit, got %d links and %+v", inheritance, report)
+	}
+	
+	limits, lastRun, err := engine.GetInferenceLimits(tenantID)
+	if err != nil || limits.MaxDepth != 1 || lastRun.Status != inference.StatusDepthLimit {
+		t.Errorf("Expected the limits and last run, got %+v, %+v, %v", limits, lastRun, err)
+	}
+}
+
+func TestAtomProtection(t *testing.T) {
+	engine := NewCognitiveEngine(nil)
+	defer engine.Close()
+	tenantID := "test-tenant"
+	engine.InitializeTenant(tenantID)
+	
+	config, _ := engine.CreateConceptNode("config/retention", tenantID)
+	config.(*atomspace.Node).RestoreFreshness(time.Now().Add(-2*time.Hour), time.Time{})
+	if err := engine.ProtectAtom(tenantID, config.GetID()); err != nil {
+		t.Fatalf("Failed to protect atom: %v", err)
+	}
+	
+	// Updates, decay and deletes leave the protected atom as it is
+	err := engine.UpdateAtom(config.GetID(), tenantID, func(a atomspace.Atom) error {
+		a.SetTruthValue(atomspace.TruthValue{Strength: 0.1, Confidence: 0.1})
+		return nil
+	})
+	if !errors.Is(err, atomspace.ErrProtected) {
+		t.Errorf("Expected the update refused as protected, got %v", err)
+	}
+	nodeType := atomspace.ConceptNodeType
+	engine.SetDecayPolicy(tenantID, decay.Policy{Name: "concepts", Type: &nodeType, HalfLife: time.Hour, Grace: time.Minute})
+	if run, _ := engine.DecayTruthValues(tenantID); run.Decayed != 0 {
+		t.Errorf("Expected the protected atom not decayed, got %+v", run)
+	}
+	if err := engine.DeleteAtom(config.GetID(), tenantID); !errors.Is(err, atomspace.ErrProtected) {
+		t.Errorf("Expected the delete refused as protected, got %v", err)
+	}
+	if tv := config.GetTruthValue(); tv.Confidence != 1 {
+		t.Errorf("Expected the truth value kept, got %+v", tv)
+	}
+	if protected := engine.ListProtectedAtoms(tenantID); len(protected) != 1 {
+		t.Errorf("Expected one protected atom, got %d", len(protected))
+	}
+	
+	// Only admins override or lift the protection
+	operator := acl.Principal{User: "bob", Roles: []string{"sre"}}
+	admin := acl.Principal{User: "alice", Roles: []string{acl.AdminRole}}
+	if err := engine.UnprotectAtom(tenantID, config.GetID(), operator); !acl.IsForbidden(err) {
+		t.Errorf("Expected a non-admin refused, got %v", err)
+	}
+	err = engine.OverrideUpdateAtom(config.GetID(), tenantID, func(a atomspace.Atom) error {
+		a.SetTruthValue(atomspace.TruthValue{Strength: 0.5, Confidence: 0.9})
+		return nil
+	}, admin)
+	if err != nil || config.GetTruthValue().Strength != 0.5 || !atomspace.IsProtected(config) {
+		t.Errorf("Expected the admin update applied with protection kept, got %v", err)
+	}
+	if err := engine.OverrideDeleteAtom(config.GetID(), tenantID, admin); err != nil {
+		t.Fatalf("Failed to delete as admin: %v", err)
+	}
+	if _, err := engine.GetAtom(config.GetID(), tenantID); err == nil {
+		t.Error("Expected the atom deleted")
+	}
+	
+	// A protected ontology protects what onboarding seeds
+	template := onboarding.Template{Name: "protected", Ontology: onboarding.Ontology{
+		Concepts:    []onboarding.Concept{{Name: "service"}, {Name: "database"}},
+		Inheritance: []onboarding.Inheritance{{Child: "database", Parent: "service"}},
+		Protected:   true,
+	}}
+	if _, err := engine.OnboardTenant("seeded-tenant", template); err != nil {
+		t.Fatalf("Failed to onboard: %v", err)
+	}
+	if protected := engine.ListProtectedAtoms("seeded-tenant"); len(protected) != 3 {
+		t.Errorf("Expected the 2 concepts and the link protected, got %d", len(protected))
+	}
+}
+
+func TestOrganizations(t *testing.T) {
+	engine := NewCognitiveEngine(nil)
+	defer engine.Close()
+	engine.InitializeTenant("web")
+	engine.InitializeTenant("api")
+	engine.CreateSharedSpace("ontology", "Ontology", "Shared concepts")
+	engine.CreateConceptNode("service", "web")
+	engine.CreateConceptNode("service", "api")
+	
+	nodeType := atomspace.ConceptNodeType
+	org := orgs.Organization{
+		ID:            "acme",
+		Budget:        &budget.Budget{Window: time.Hour, MaxIterations: 100, Action: budget.ActionPause},
+		DecayPolicies: []decay.Policy{{Name: "concepts", Type: &nodeType, HalfLife: time.Hour}},
+		Mounts:        []string{"ontology"},
+	}
+	if _, err := engine.SetOrganization(org); err != nil {
+		t.Fatalf("Failed to set organization: %v", err)
+	}
+	
+	// The api project keeps the budget it set itself
+	own := budget.Budget{Window: time.Minute, MaxIterations: 5, Action: budget.ActionPause}
+	engine.SetTenantBudget("api", own)
+	for _, tenantID := range []string{"web", "api"} {
+		if err := engine.AddProject("acme", tenantID); err != nil {
+			t.Fatalf("Failed to add project %s: %v", tenantID, err)
+		}
+	}
+	if err := engine.AddProject("acme", "unknown"); err == nil {
+		t.Error("Expected an uninitialized tenant rejected")
+	}
+	
+	if b, _ := engine.agentScheduler.Budgets().GetBudget("web"); b.MaxIterations != 100 {
+		t.Errorf("Expected web to inherit the budget, got %+v", b)
+	}
+	if b, _ := engine.agentScheduler.Budgets().GetBudget("api"); b != own {
+		t.Errorf("Expected api to keep its own budget, got %+v", b)
+	}
+	if _, err := engine.GetDecayPolicy("web", "concepts"); err != nil {
+		t.Errorf("Expected web to inherit the decay policy: %v", err)
+	}
+	if mounts := engine.GetMounts("api"); len(mounts) != 1 || mounts[0] != "ontology" {
+		t.Errorf("Expected api to inherit the mount, got %v", mounts)
+	}
+	_, inherited, err := engine.GetTenantOrganization("api")
+	if err != nil || inherited.Budget || len(inherited.DecayPolicies) != 1 {
+		t.Errorf("Expected api to inherit all but the budget, got %+v (%v)", inherited, err)
+	}
+	
+	// Dropping a setting at the organization withdraws it from the projects
+	org.DecayPolicies = nil
+	engine.SetOrganization(org)
+	if _, err := engine.GetDecayPolicy("web", "concepts"); err == nil {
+		t.Error("Expected the dropped decay policy withdrawn")
+	}
+	
+	stats, err := engine.GetOrganizationStats("acme")
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	if len(stats.Projects) != 2 || stats.Total.Atoms != stats.Projects[0].Atoms+stats.Projects[1].Atoms || stats.Total.Atoms < 2 {
+		t.Errorf("Expected the projects' atoms summed, got %+v", stats)
+	}
+	
+	// Keys reach the organization's projects only
+	_, secret, err := engine.CreateAPIKey("acme", "ci", []string{"deployer"})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	key, ok := engine.AuthenticateAPIKey(secret)
+	if !ok || !engine.APIKeyReaches(key.OrgID, "web") || engine.APIKeyReaches(key.OrgID, "other") {
+		t.Errorf("Expected the key to reach the projects only, got %+v", key)
+	}
+	
+	// Detached projects lose what they inherited and keep their own
+	if err := engine.DeleteOrganization("acme"); err != nil {
+		t.Fatalf("Failed to delete organization: %v", err)
+	}
+	if _, exists := engine.agentScheduler.Budgets().GetBudget("web"); exists {
+		t.Error("Expected the inherited budget withdrawn")
+	}
+	if b, _ := engine.agentScheduler.Budgets().GetBudget("api"); b != own {
+		t.Errorf("Expected api's own budget kept, got %+v", b)
+	}
+	if mounts := engine.GetMounts("web"); len(mounts) != 0 {
+		t.Errorf("Expected the inherited mount withdrawn, got %v", mounts)
+	}
+	if _, ok := engine.AuthenticateAPIKey(secret); ok {
+		t.Error("Expected the organization's keys deleted")
+	}
+}
+
+func TestRecommendations(t *testing.T) {
+	engine := NewCognitiveEngine(nil)
+	defer engine.Close()
+	tenantID := "test-tenant"
+	engine.InitializeTenant(tenantID)
+	
+	full := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+	add := func(atom atomspace.Atom, tv atomspace.TruthValue) atomspace.Atom {
+		atom.SetTruthValue(tv)
+		engine.AddAtom(atom)
+		return atom
+	}
+	concept := func(name string) atomspace.Atom {
+		return add(atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, tenantID, atomspace.ConceptNodeType), full)
+	}
+	relation := func(predicate string, tv atomspace.TruthValue, args ...atomspace.Atom) {
+		pred := add(atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, predicate, nil), predicate, tenantID, atomspace.PredicateNodeType), full)
+		outgoing := append([]atomspace.Atom{pred}, args...)
+		add(atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, predicate, outgoing), predicate, tenantID, atomspace.EvaluationLinkType, outgoing), tv)
+	}
+	resource := func(name, category string, cpu float64) atomspace.Atom {
+		node := concept(name)
+		outgoing := []atomspace.Atom{node, concept(category)}
+		add(atomspace.NewLink(atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", outgoing), "inheritance", tenantID, atomspace.InheritanceLinkType, outgoing), full)
+		relation("cpu_usage", atomspace.TruthValue{Strength: cpu, Confidence: 0.9}, node)
+		return node
+	}
+	
+	// Three lightly loaded hosts, an idle cache and a saturated database
+	for i, cpu := range []float64{0.2, 0.15, 0.1} {
+		host := resource(fmt.Sprintf("node/%d", i), "KubernetesNode", cpu)
+		relation("runs_on", full, concept(fmt.Sprintf("pod/%d", i)), host)
+	}
+	resource("cache/a", "Cache", 0.1)
+	resource("db/a", "Database", 0.95)
+	engine.SetCostRates(tenantID, []cost.Rate{
+		{Category: "KubernetesNode", Hourly: 0.1},
+		{Category: "Cache", Hourly: 0.5},
+		{Resource: "db/a", Hourly: 1.0},
+	})
+	
+	recs, err := engine.RunRecommendations(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Recommendation run failed: %v", err)
+	}
+	byKind := make(map[recommendations.Kind]recommendations.Recommendation)
+	for _, rec := range recs {
+		byKind[rec.Kind] = rec
+	}
+	if len(recs) != 3 {
+		t.Fatalf("Expected a consolidation, a resize and a scale, got %+v", recs)
+	}
+	consolidation := byKind[recommendations.KindConsolidate]
+	if len(consolidation.Resources) != 2 || consolidation.Resources[0] != "node/1" || consolidation.Resources[1] != "node/2" {
+		t.Errorf("Expected the two least used hosts drained, got %+v", consolidation)
+	}
+	if math.Abs(consolidation.MonthlySavings-2*0.1*cost.HoursPerMonth) > 1e-9 {
+		t.Errorf("Expected the drained hosts' cost saved, got %v", consolidation.MonthlySavings)
+	}
+	if resize := byKind[recommendations.KindResize]; resize.Subject != "cache/a" || resize.MonthlySavings <= 0 {
+		t.Errorf("Expected the idle cache resized, got %+v", resize)
+	}
+	scale := byKind[recommendations.KindScale]
+	if scale.Subject != "db/a" || scale.MonthlySavings >= 0 || scale.Confidence <= byKind[recommendations.KindResize].Confidence {
+		t.Errorf("Expected the database scaled at a cost, more confidently than a category rate allows, got %+v", scale)
+	}
+	atom, err := engine.GetAtom(scale.AtomID, tenantID)
+	if err != nil || atom.GetName() != agents.RecommendationPrefix+"scale:db/a" {
+		t.Fatalf("Expected a recommendation atom: %v", err)
+	}
+	
+	// Recommendations are submitted, then approved by admins only
+	operator := acl.Principal{User: "bob"}
+	admin := acl.Principal{User: "alice", Roles: []string{acl.AdminRole}}
+	if _, err := engine.DecideRecommendation(context.Background(), tenantID, scale.ID, true, "", admin); err == nil {
+		t.Error("Expected an open recommendation not to be decided")
+	}
+	if _, err := engine.SubmitRecommendation(tenantID, scale.ID, operator); err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	if pending := engine.ListRecommendations(tenantID, recommendations.StatusPending); len(pending) != 1 {
+		t.Errorf("Expected one recommendation waiting for approval, got %d", len(pending))
+	}
+	approved, err := engine.DecideRecommendation(context.Background(), tenantID, scale.ID, true, "next window", admin)
+	if err != nil || approved.Status != recommendations.StatusApproved || approved.DecidedBy != "alice" {
+		t.Errorf("Expected the recommendation approved, got %+v (%v)", approved, err)
+	}
+	
+	// Once the cache is busy its recommendation and atoms go; the decided
+	// recommendation stays
+	resize := byKind[recommendations.KindResize]
+	cpu := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, "cpu_usage", nil), "cpu_usage", tenantID, atomspace.PredicateNodeType)
+	outgoing := []atomspace.Atom{cpu, concept("cache/a")}
+	engine.UpdateAtom(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, "cpu_usage", outgoing), tenantID, func(a atomspace.Atom) error {
+		a.SetTruthValue(atomspace.TruthValue{Strength: 0.5, Confidence: 0.9})
+		return nil
+	})
+	if _, err := engine.RunRecommendations(context.Background(), tenantID); err != nil {
+		t.Fatalf("Recommendation run failed: %v", err)
+	}
+	if _, err := engine.GetRecommendation(tenantID, resize.ID); err == nil {
+		t.Error("Expected the resize recommendation dropped")
+	}
+	if _, err := engine.GetAtom(resize.AtomID, tenantID); err == nil {
+		t.Error("Expected the resize recommendation atom deleted")
+	}
+	if rec, _ := engine.GetRecommendation(tenantID, scale.ID); rec.Status != recommendations.StatusApproved {
+		t.Errorf("Expected the approval kept, got %+v", rec)
+	}
+}
+
+func TestDecisionPolicies(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	
+	var deleted, notified int
+	engine.RegisterSchema(schemas.Schema{Name: "delete_volume", Class: decisions.ClassDelete, Func: func(ctx context.Context, call schemas.Call) (atomspace.Atom, error) {
+		deleted++
+		return nil, nil
+	}})
+	engine.RegisterSchema(schemas.Schema{Name: "page", Class: decisions.ClassNotify, Func: func(ctx context.Context, call schemas.Call) (atomspace.Atom, error) {
+		notified++
+		return nil, nil
+	}})
+	
+	// Both calls were derived from a weak belief
+	weak := atomspace.TruthValue{Strength: 0.9, Confidence: 0.6}
+	for _, schema := range []string{"delete_volume", "page"} {
+		call, err := engine.AddExecution(tenantID, schema, nil)
+		if err != nil {
+			t.Fatalf("Failed to add execution: %v", err)
+		}
+		engine.UpdateAtom(call.GetID(), tenantID, func(a atomspace.Atom) error {
+			a.SetTruthValue(weak)
+			return nil
+		})
+	}
+	
+	results, err := engine.ExecuteSchemas(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	if deleted != 0 || notified != 1 || len(results) != 2 {
+		t.Fatalf("Expected only the notification run, got %+v", results)
+	}
+	var held schemas.Result
+	for _, result := range results {
+		if result.Schema == "delete_volume" {
+			held = result
+		}
+	}
+	if held.Outcome != schemas.OutcomeHeld || held.Class != decisions.ClassDelete || held.Error == "" {
+		t.Errorf("Expected the deletion held with a reason, got %+v", held)
+	}
+	
+	// A held call is reported once until its belief changes
+	if results, _ := engine.ExecuteSchemas(context.Background(), tenantID); len(results) != 0 || deleted != 0 {
+		t.Errorf("Expected the held call not reported again, got %+v", results)
+	}
+	
+	// The tenant accepts weaker beliefs for deletions
+	policy, custom := engine.GetDecisionPolicy(tenantID)
+	if custom {
+		t.Error("Expected the default policy")
+	}
+	policy.Thresholds[decisions.ClassDelete] = decisions.Threshold{MinStrength: 0.8, MinConfidence: 0.5}
+	if _, err := engine.SetDecisionPolicy(tenantID, policy); err != nil {
+		t.Fatalf("Failed to set decision policy: %v", err)
+	}
+	results, _ = engine.ExecuteSchemas(context.Background(), tenantID)
+	if deleted != 1 || len(results) != 1 || results[0].Outcome != schemas.OutcomeSucceeded || results[0].Class != decisions.ClassDelete {
+		t.Errorf("Expected the deletion run under the new policy, got %+v", results)
+	}
+	
+	policy.Thresholds[decisions.ClassDelete] = decisions.Threshold{MinConfidence: 2}
+	if _, err := engine.SetDecisionPolicy(tenantID, policy); err == nil {
+		t.Error("Expected a confidence above 1 rejected")
+	}
+	if err := engine.DeleteDecisionPolicy(tenantID); err != nil {
+		t.Errorf("Failed to delete decision policy: %v", err)
+	}
+}
+
+func TestCounterfactuals(t *testing.T) {
+	cfg := DefaultConfig()
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	
+	engine.RegisterSchema(schemas.Schema{Name: "restart_pod", Class: decisions.ClassRestart, Func: func(ctx context.Context, call schemas.Call) (atomspace.Atom, error) {
+		return nil, nil
+	}})
+	pod, _ := engine.CreateConceptNode("pod/web-1", tenantID)
+	call, err := engine.AddExecution(tenantID, "restart_pod", []GroundedArgument{{AtomID: pod.GetID()}})
+	if err != nil {
+		t.Fatalf("Failed to add execution: %v", err)
+	}
+	setConfidence := func(c float64) {
+		engine.UpdateAtom(call.GetID(), tenantID, func(a atomspace.Atom) error {
+			a.SetTruthValue(atomspace.TruthValue{Strength: 0.9, Confidence: c})
+			return nil
+		})
+	}
+	
+	// The restart is held twice, at two beliefs, before it runs
+	setConfidence(0.4)
+	engine.ExecuteSchemas(context.Background(), tenantID)
+	setConfidence(0.6)
+	engine.ExecuteSchemas(context.Background(), tenantID)
+	
+	list := engine.ListCounterfactuals(tenantID, counterfactuals.Filter{Class: string(decisions.ClassRestart)})
+	if len(list) != 1 || list[0].Count != 2 || list[0].Action != "restart_pod" || list[0].TruthValue.Confidence != 0.6 || list[0].Released() {
+		t.Fatalf("Expected the held restart recorded twice, got %+v", list)
+	}
+	if len(list[0].Arguments) != 1 || list[0].Arguments[0] != "pod/web-1" || list[0].Reason == "" {
+		t.Errorf("Expected what the restart would have done and why, got %+v", list[0])
+	}
+	
+	// The counterfactual is an atom linked to the call it would have run
+	node, err := engine.GetAtom(atomspace.GenerateAtomID(atomspace.ConceptNodeType, agents.CounterfactualPrefix+list[0].ID, nil), tenantID)
+	if err != nil || node.GetTruthValue().Confidence != 0.6 {
+		t.Fatalf("Expected the counterfactual concept with the belief behind it, got %v (%v)", node, err)
+	}
+	wouldHave := engine.QueryAtoms(tenantID, func(atom atomspace.Atom) bool {
+		link, ok := atom.(*atomspace.Link)
+		return ok && atom.GetName() == "would_have" && link.Outgoing[1].GetID() == node.GetID() && link.Outgoing[2].GetID() == call.GetID()
+	})
+	if len(wouldHave) != 1 {
+		t.Errorf("Expected the counterfactual linked to the call, got %v", wouldHave)
+	}
+	
+	setConfidence(0.8)
+	engine.ExecuteSchemas(context.Background(), tenantID)
+	if c, _ := engine.GetCounterfactual(tenantID, list[0].ID); !c.Released() {
+		t.Errorf("Expected the counterfactual released once the restart ran, got %+v", c)
+	}
+	
+	report := engine.CounterfactualReport(tenantID, time.Now().Add(-time.Hour))
+	if report.Total != 1 || report.Released != 1 || len(report.Groups) != 1 || report.Groups[0].Blocker != counterfactuals.BlockerDecisionPolicy {
+		t.Errorf("Expected one released counterfactual of the decision policy, got %+v", report)
+	}
+	
+	if purged, _ := engine.PurgeTenant(context.Background(), tenantID); purged.Removed["counterfactuals"] != 1 {
+		t.Errorf("Expected the counterfactual purged, got %+v", purged.Removed)
+	}
+}
+
+func TestIntegrity(t *testing.T) {
+	config := DefaultConfig()
+	config.StartupIntegrity = IntegrityRepair
+	engine := NewCognitiveEngine(config)
+	defer engine.Close()
+	
+	result := engine.CheckStartup()
+	for deadline := time.Now().Add(5 * time.Second); result.Status != health.StatusOK && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		result = engine.CheckStartup()
+	}
+	if checked := result.Details["integrity"].(map[string]interface{}); result.Status != health.StatusOK || checked["issues"] != 0 {
+		t.Fatalf("Expected the startup check to find no issues, got %+v", result)
+	}
+	
+	tenantID := "test-tenant"
+	web, _ := engine.CreateConceptNode("web", tenantID)
+	service, _ := engine.CreateConceptNode("service", tenantID)
+	if _, err := engine.CreateInheritanceLink(web.GetID(), service.GetID(), tenantID); err != nil {
+		t.Fatalf("Failed to create link: %v", err)
+	}
+	if report := engine.CheckIntegrity(false); report.Atoms != 3 || report.Links != 1 || len(report.Issues) != 0 {
+		t.Fatalf("Expected consistent indices, got %+v", report)
+	}
+	
+	// Renaming an atom in place leaves its name entries behind until repaired
+	web.(*atomspace.Node).Name = "frontend"
+	if report := engine.CheckIntegrity(false); len(report.Issues) != 4 || report.Repaired != 0 {
+		t.Fatalf("Expected stale and missing name entries, got %+v", report.Issues)
+	}
+	if report := engine.CheckIntegrity(true); report.Repaired != 4 {
+		t.Errorf("Expected the name entries repaired, got %+v", report)
+	}
+	if report := engine.CheckIntegrity(false); len(report.Issues) != 0 {
+		t.Errorf("Expected no issues after the repair, got %+v", report.Issues)
+	}
+	if results := engine.SearchAtoms(tenantID, "frontend", atomspace.SearchModePrefix, 0); len(results) != 1 {
+		t.Errorf("Expected the renamed atom searchable, got %v", results)
+	}
+}
+
+func TestSubscribeStats(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	if _, err := engine.SubscribeStats("unknown", time.Second); err == nil {
+		t.Error("Expected subscribing to an uninitialized tenant to fail")
+	}
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	sub, err := engine.SubscribeStats(tenantID, livestats.MinInterval)
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+	
+	u := <-sub.C
+	if _, exists := u.Values["pipelines.total"]; !u.Full || !exists || u.Values["tenant.total_atoms"] != 0 {
+		t.Fatalf("Expected a full first update, got %+v", u)
+	}
+	
+	// Only the changed counters follow
+	engine.CreateConceptNode("web", tenantID)
+	select {
+	case u = <-sub.C:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected an update after creating an atom")
+	}
+	if u.Full || u.Values["tenant.total_atoms"] != 1 {
+		t.Errorf("Expected the atom count to change, got %+v", u)
+	}
+	if _, exists := u.Values["pipelines.total"]; exists {
+		t.Errorf("Expected unchanged values left out, got %+v", u)
+	}
+	
+	engine.SubscribeStats("", time.Minute)
+	if stats := engine.GetStats("")["stats_stream"].(map[string]interface{}); stats["subscribers"] != 2 {
+		t.Errorf("Expected 2 subscribers, got %v", stats)
+	}
+}
+
+func TestSelectStats(t *testing.T) {
+	config := DefaultConfig()
+	config.StatsCacheTTL = time.Hour
+	engine := NewCognitiveEngine(config)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	
+	stats := engine.SelectStats(tenantID, statsview.ParseSelection("tenant,agents.total_agents", ""))
+	if len(stats) != 2 || stats["tenant"].(map[string]interface{})["total_atoms"] != 0 {
+		t.Fatalf("Expected the tenant section and the agent count, got %v", stats)
+	}
+	if agents := stats["agents"].(map[string]interface{}); len(agents) != 1 {
+		t.Errorf("Expected only the agent count, got %v", agents)
+	}
+	
+	// The tenant's atoms are cached until the TTL expires
+	engine.CreateConceptNode("web", tenantID)
+	stats = engine.SelectStats(tenantID, statsview.ParseSelection("tenant", ""))
+	if total := stats["tenant"].(map[string]interface{})["total_atoms"]; total != 0 {
+		t.Errorf("Expected the cached atom count, got %v", total)
+	}
+	
+	stats = engine.SelectStats("", statsview.ParseSelection("", "pipelines,sharding.shards"))
+	if _, exists := stats["pipelines"]; exists {
+		t.Error("Expected the pipelines excluded")
+	}
+	if sharding := stats["sharding"].(map[string]interface{}); sharding["shards"] != nil || sharding["total_load"] == nil {
+		t.Errorf("Expected the shard list excluded, got %v", sharding)
+	}
+	if cache := stats["stats_cache"].(map[string]interface{}); cache["hits"].(int64) != 2 {
+		t.Errorf("Expected the tenant and agents cached, got %v", cache)
+	}
+}
+
+func TestPipelineArtifacts(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	engine.CreateConceptNode("web-1", tenantID)
+	
+	p, err := engine.CreatePipeline("reported", "Reported", tenantID)
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	tenantSpace := &tenantAtomSpaceWrapper{engine: engine, tenantID: tenantID}
+	report, err := pipeline.NewReportStage("Inventory", pipeline.ReportFormatJSON, 0)
+	if err != nil {
+		t.Fatalf("Failed to create report stage: %v", err)
+	}
+	if err := p.AddStage(pipeline.NewQueryStage(tenantSpace, tenantID, nil)); err != nil {
+		t.Fatalf("Failed to add query stage: %v", err)
+	}
+	if err := p.AddStage(report); err != nil {
+		t.Fatalf("Failed to add report stage: %v", err)
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := engine.ExecutePipeline(ctx, "reported", nil); err != nil {
+		t.Fatalf("Failed to execute pipeline: %v", err)
+	}
+	executions := p.GetExecutions()
+	if len(executions) != 1 {
+		t.Fatalf("Expected one execution, got %d", len(executions))
+	}
+	execID := executions[0].ID
+	
+	list := engine.ListArtifacts(tenantID, "reported", execID)
+	if len(list) != 1 || list[0].Name != "report.json" || list[0].Stage != report.GetName() {
+		t.Fatalf("Expected the report attached by its stage, got %+v", list)
+	}
+	a, data, err := engine.GetArtifact(ctx, tenantID, "reported", execID, "report.json")
+	if err != nil || a.ContentType != "application/json" || !strings.Contains(string(data), "web-1") {
+		t.Errorf("Expected the JSON report, got %+v %q %v", a, data, err)
+	}
+	if list := engine.ListArtifacts("other-tenant", "reported", execID); len(list) != 0 {
+		t.Errorf("Expected another tenant to see no artifacts, got %+v", list)
+	}
+	
+	purged, err := engine.PurgeTenant(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("Failed to purge tenant: %v", err)
+	}
+	if purged.Removed["artifacts"] != 1 {
+		t.Errorf("Expected the purge to remove the artifact, got %v", purged.Removed)
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	received := make(chan map[string]interface{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		received <- body
+	}))
+	defer server.Close()
+	next := func() map[string]interface{} {
+		select {
+		case body := <-received:
+			return body
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a notification")
+			return nil
+		}
+	}
+	
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	if _, err := engine.SetNotificationChannel(tenantID, notifications.Channel{
+		Name:   "ops",
+		Type:   notifications.ChannelWebhook,
+		URL:    server.URL,
+		Topics: []string{notifications.TopicPipelineFailed, notifications.TopicIncidentOpened, notifications.TopicTriggerFired},
+	}); err != nil {
+		t.Fatalf("Failed to set channel: %v", err)
+	}
+	
+	p, err := engine.CreatePipeline("flaky", "Flaky", tenantID)
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	p.AddStage(&flakyStage{})
+	if _, err := engine.ExecutePipeline(context.Background(), "flaky", nil); err == nil {
+		t.Fatal("Expected pipeline to fail")
+	}
+	if body := next(); body["topic"] != notifications.TopicPipelineFailed || body["fields"].(map[string]interface{})["stage"] != "flaky" {
+		t.Errorf("Expected the pipeline failure, got %v", body)
+	}
+	
+	if _, err := engine.IngestAlerts(context.Background(), tenantID, []incidents.Alert{
+		{Name: "NodeNotReady", Subject: "node/n1", Severity: "critical", StartsAt: time.Now()},
+	}); err != nil {
+		t.Fatalf("Failed to ingest alerts: %v", err)
+	}
+	if body := next(); body["topic"] != notifications.TopicIncidentOpened || body["severity"] != "critical" {
+		t.Errorf("Expected the incident, got %v", body)
+	}
+	
+	// Notify actions of triggers and runbooks
+	err = engine.tenantActions(tenantID).Perform(context.Background(), triggers.Action{Type: triggers.ActionNotify}, nil, map[string]interface{}{"trigger_id": "t1"})
+	if err != nil {
+		t.Fatalf("Failed to perform notify action: %v", err)
+	}
+	if body := next(); body["title"] != "Trigger t1 fired" {
+		t.Errorf("Expected the trigger notification, got %v", body)
+	}
+	err = engine.tenantActions(tenantID).Perform(context.Background(), triggers.Action{Type: triggers.ActionNotify, Channel: "missing"}, nil, nil)
+	if err == nil {
+		t.Error("Expected a notify action to a missing channel to fail")
+	}
+	
+	// In sandbox mode notifications are recorded instead of sent
+	if _, err := engine.SetSandbox(tenantID, sandbox.Config{Enabled: true}); err != nil {
+		t.Fatalf("Failed to enable sandbox: %v", err)
+	}
+	if delivery, err := engine.TestNotificationChannel(context.Background(), tenantID, "ops"); err != nil || delivery.Error != "" {
+		t.Fatalf("Failed to test channel: %+v %v", delivery, err)
+	}
+	if requests := engine.SandboxRequests(tenantID); len(requests) != 1 || requests[0].Type != sandbox.RequestNotification {
+		t.Errorf("Expected the notification recorded, got %+v", requests)
+	}
+	select {
+	case body := <-received:
+		t.Errorf("Expected nothing sent in sandbox mode, got %v", body)
+	default:
+	}
+	
+	purged, err := engine.PurgeTenant(context.Background(), tenantID)
+	if err != nil || purged.Removed["notifications"] != 1 {
+		t.Errorf("Expected the channel purged, got %+v %v", purged, err)
+	}
+}
+
+func TestMaintenanceWindows(t *testing.T) {
+	ctx := context.Background()
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	p, _ := engine.CreatePipeline("nightly", "Nightly", tenantID)
+	p.AddStage(&recordingStage{inputs: make(chan interface{}, 1)})
+	scheduled, _ := engine.SchedulePipeline(ctx, "nightly", nil, time.Now().Add(100*time.Millisecond))
+	
+	ran := 0
+	engine.RegisterSchema(schemas.Schema{Name: "restart_pod", Func: func(ctx context.Context, call schemas.Call) (atomspace.Atom, error) {
+		ran++
+		return nil, nil
+	}})
+	pod, _ := engine.CreateConceptNode("pod/web-1", tenantID)
+	if _, err := engine.AddExecution(tenantID, "restart_pod", []GroundedArgument{{AtomID: pod.GetID()}}); err != nil {
+		t.Fatalf("Failed to add execution: %v", err)
+	}
+	
+	if _, err := engine.AddMaintenanceWindow(tenantID, maintenance.Window{Kind: "outage", Start: time.Now(), End: time.Now().Add(time.Hour)}, acl.Principal{}); err == nil {
+		t.Error("Expected an unknown kind rejected")
+	}
+	end := time.Now().Add(time.Hour).Truncate(time.Second)
+	freeze, err := engine.AddMaintenanceWindow(tenantID, maintenance.Window{
+		Kind:   maintenance.KindFreeze,
+		Reason: "quarter close",
+		Start:  time.Now().Add(-time.Minute),
+		End:    end,
+	}, acl.Principal{User: "alice"})
+	if err != nil {
+		t.Fatalf("Failed to add window: %v", err)
+	}
+	if freeze.State != maintenance.StateActive || !freeze.Actions || !freeze.Pipelines || freeze.CreatedBy != "alice" {
+		t.Errorf("Expected an active freeze holding actions and pipelines, got %+v", freeze)
+	}
+	
+	// Calls are held and recorded as counterfactuals of the freeze
+	results, _ := engine.ExecuteSchemas(ctx, tenantID)
+	if ran != 0 || len(results) != 1 || results[0].Outcome != schemas.OutcomeHeld || !strings.Contains(results[0].Error, "quarter close") {
+		t.Errorf("Expected the restart held, got %+v", results)
+	}
+	list := engine.ListCounterfactuals(tenantID, counterfactuals.Filter{Blocker: counterfactuals.BlockerMaintenance})
+	if len(list) != 1 || list[0].Action != "restart_pod" {
+		t.Errorf("Expected a counterfactual of the freeze, got %+v", list)
+	}
+	
+	// Trigger and runbook actions are refused, notifications are not
+	actions := engine.tenantActions(tenantID)
+	if err := actions.Perform(ctx, triggers.Action{Type: triggers.ActionWebhook, URL: "http://hook"}, nil, nil); err == nil || !strings.Contains(err.Error(), freeze.ID) {
+		t.Errorf("Expected the webhook refused by the freeze, got %v", err)
+	}
+	if err := actions.Perform(ctx, triggers.Action{Type: triggers.ActionNotify}, nil, map[string]interface{}{}); err != nil {
+		t.Errorf("Expected notifications sent during the freeze, got %v", err)
+	}
+	
+	// Submitted executions, and scheduled ones falling due, wait for its end
+	submitted, err := engine.SubmitPipeline(ctx, "nightly", nil)
+	if err != nil || submitted.State != pipeline.ExecutionStateScheduled || !submitted.RunAt.Equal(end) || submitted.HeldBy == "" {
+		t.Errorf("Expected the submitted execution held until %v, got %+v (%v)", end, submitted, err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	if exec, _ := p.GetExecution(scheduled.ID); exec.State != pipeline.ExecutionStateScheduled || !exec.RunAt.Equal(end) || exec.HeldBy == "" {
+		t.Errorf("Expected the scheduled execution held until %v, got %+v", end, exec)
+	}
+	
+	// A maintenance window pausing an agent leaves actions alone
+	agent := engine.executionAgent(tenantID)
+	if engine.agentPaused(agent) {
+		t.Error("Expected the execution agent not paused by the freeze")
+	}
+	if _, err := engine.AddMaintenanceWindow(tenantID, maintenance.Window{
+		Kind:   maintenance.KindMaintenance,
+		Start:  time.Now().Add(-time.Minute),
+		End:    time.Now().Add(time.Hour),
+		Agents: []string{"ExecutionAgent"},
+	}, acl.Principal{}); err != nil {
+		t.Fatalf("Failed to add window: %v", err)
+	}
+	if !engine.agentPaused(agent) {
+		t.Error("Expected the execution agent paused")
+	}
+	
+	// Ending the freeze releases the held call
+	if err := engine.DeleteMaintenanceWindow(tenantID, freeze.ID); err != nil {
+		t.Fatalf("Failed to delete window: %v", err)
+	}
+	engine.ExecuteSchemas(ctx, tenantID)
+	if c, _ := engine.GetCounterfactual(tenantID, list[0].ID); ran != 1 || !c.Released() {
+		t.Errorf("Expected the restart run once the freeze ended, ran %d, got %+v", ran, c)
+	}
+	
+	if windows := engine.ListMaintenanceWindows(tenantID); len(windows) != 1 || windows[0].Kind != maintenance.KindMaintenance {
+		t.Errorf("Expected the maintenance window left, got %+v", windows)
+	}
+	if purged, _ := engine.PurgeTenant(ctx, tenantID); purged.Removed["maintenance"] != 1 {
+		t.Errorf("Expected the window purged, got %+v", purged.Removed)
+	}
+}
+
+func TestAttentionParams(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	for i, tv := range []atomspace.TruthValue{{Strength: 0.9, Confidence: 0.9}, {Strength: 0.9, Confidence: 0.6}} {
+		node := atomspace.NewNode(fmt.Sprintf("n%d", i), "server", tenantID, atomspace.ConceptNodeType)
+		node.SetTruthValue(tv)
+		node.SetAttentionValue(atomspace.AttentionValue{STI: 100})
+		if err := engine.AddAtom(node); err != nil {
+			t.Fatalf("Failed to add atom: %v", err)
+		}
+	}
+	sti := func(atomID string) int16 {
+		atom, _ := engine.GetAtom(atomID, tenantID)
+		return atom.GetAttentionValue().STI
+	}
+	agent := agents.NewAttentionAgent("attention-1", "Attention", tenantID, &tenantAtomSpaceWrapper{engine: engine, tenantID: tenantID})
+	engine.RegisterAgent(agent)
+	
+	// The default params keep 95% and boost strong atoms by 10
+	agent.Run(context.Background())
+	if sti("n0") != 105 || sti("n1") != 95 {
+		t.Errorf("Expected the default dynamics, got %d and %d", sti("n0"), sti("n1"))
+	}
+	
+	if _, err := engine.SetAttentionParams(tenantID, attention.Params{DecayRate: 1.5, FocusSize: 1}); err == nil {
+		t.Error("Expected a decay rate above 1 rejected")
+	}
+	if _, err := engine.SetAttentionParams(tenantID, attention.Params{
+		DecayRate: 0.5,
+		Boosts:    []attention.Boost{{MinStrength: 0.5, MinConfidence: 0.5, STI: 40}},
+		FocusSize: 1,
+	}); err != nil {
+		t.Fatalf("Failed to set params: %v", err)
+	}
+	agent.Run(context.Background())
+	if sti("n0") != 92 || sti("n1") != 87 {
+		t.Errorf("Expected the tenant's dynamics applied at once, got %d and %d", sti("n0"), sti("n1"))
+	}
+	
+	focus, err := engine.GetAttentionalFocus(tenantID)
+	if err != nil || len(focus) != 1 || focus[0].GetID() != "n0" {
+		t.Errorf("Expected a focus of n0 alone, got %v (%v)", focus, err)
+	}
+	if _, err := engine.GetAttentionalFocus("unknown"); err == nil {
+		t.Error("Expected an unknown tenant refused")
+	}
+	
+	if purged, _ := engine.PurgeTenant(context.Background(), tenantID); purged.Removed["attention_params"] != 1 {
+		t.Errorf("Expected the params purged, got %+v", purged.Removed)
+	}
+	if _, custom := engine.GetAttentionParams(tenantID); custom {
+		t.Error("Expected the default params after the purge")
+	}
+}
+
+func TestPipelineInput(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	engine.CreateConceptNode("web-1", tenantID)
+	
+	tenantSpace := &tenantAtomSpaceWrapper{engine: engine, tenantID: tenantID}
+	ingest, err := engine.CreatePipeline("ingest", "Ingest", tenantID)
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	if err := ingest.AddStage(pipeline.NewAtomIngestionStage(tenantSpace, tenantID)); err != nil {
+		t.Fatalf("Failed to add ingestion stage: %v", err)
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	run := func(in pipeline.Input) ([]atomspace.Atom, error) {
+		input, err := engine.ResolvePipelineInput(ctx, "ingest", in)
+		if err != nil {
+			return nil, err
+		}
+		output, err := engine.ExecutePipeline(ctx, "ingest", input)
+		if err != nil {
+			return nil, err
+		}
+		return output.([]atomspace.Atom), nil
+	}
+	named := func(name string) []atomspace.Atom {
+		return engine.QueryAtoms(tenantID, func(atom atomspace.Atom) bool { return atom.GetName() == name })
+	}
+	
+	// Inline atoms are created as nodes of the pipeline's tenant
+	atoms, err := run(pipeline.Input{Atoms: []pipeline.InputAtom{
+		{Type: int(atomspace.ConceptNodeType), Name: "db-1", Strength: 0.9, Confidence: 0.8},
+	}})
+	if err != nil || len(atoms) != 1 {
+		t.Fatalf("Expected the inline atom ingested, got %v %v", atoms, err)
+	}
+	if found := named("db-1"); len(found) != 1 || found[0].GetTruthValue().Strength != 0.9 {
+		t.Errorf("Expected db-1 in the tenant's AtomSpace, got %v", found)
+	}
+	
+	// A saved query is run when the request is made
+	conceptType := atomspace.ConceptNodeType
+	engine.SetSavedQuery(tenantID, queries.Query{Name: "concepts", Query: atomspace.Query{Type: &conceptType}})
+	if atoms, err := run(pipeline.Input{Query: "concepts"}); err != nil || len(atoms) != 2 {
+		t.Errorf("Expected the saved query's two concepts, got %v %v", atoms, err)
+	}
+	if _, err := run(pipeline.Input{Query: "missing"}); err == nil {
+		t.Error("Expected an error for a missing saved query")
+	}
+	
+	// A dataset is read from an artifact of an earlier execution
+	dataset := []byte("{\"type\": 1, \"name\": \"cache-1\"}\n{\"type\": 1, \"name\": \"cache-2\"}\n")
+	if err := engine.attachArtifact(ctx, pipeline.ArtifactRef{TenantID: tenantID, PipelineID: "export", ExecutionID: "export-1", Name: "nodes.ndjson"}, dataset); err != nil {
+		t.Fatalf("Failed to store dataset: %v", err)
+	}
+	ref := &pipeline.DatasetRef{PipelineID: "export", ExecutionID: "export-1", Name: "nodes.ndjson"}
+	if atoms, err := run(pipeline.Input{Dataset: ref}); err != nil || len(atoms) != 2 {
+		t.Errorf("Expected the dataset's two atoms, got %v %v", atoms, err)
+	}
+	if len(named("cache-2")) != 1 {
+		t.Error("Expected the dataset's atoms ingested")
+	}
+	
+	for _, in := range []pipeline.Input{
+		{},
+		{Query: "concepts", Dataset: ref},
+		{Atoms: []pipeline.InputAtom{{Type: int(atomspace.InheritanceLinkType), Name: "link"}}},
+	} {
+		if _, err := engine.ResolvePipelineInput(ctx, "ingest", in); err == nil {
+			t.Errorf("Expected input %+v to be rejected", in)
+		}
+	}
+	
+	// A pipeline starting with a query takes no input
+	queried, _ := engine.CreatePipeline("queried", "Queried", tenantID)
+	queried.AddStage(pipeline.NewQueryStage(tenantSpace, tenantID, nil))
+	if _, err := engine.ResolvePipelineInput(ctx, "queried", pipeline.Input{Query: "concepts"}); err == nil {
+		t.Error("Expected a pipeline taking no input to reject atoms")
+	}
+}
+
+func TestAtomWatches(t *testing.T) {
+	received := make(chan map[string]interface{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		received <- body
+	}))
+	defer server.Close()
+	next := func() map[string]interface{} {
+		select {
+		case body := <-received:
+			return body
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a notification")
+			return nil
+		}
+	}
+	
+	cfg := DefaultConfig()
+	cfg.Watches.Interval = 0
+	engine := NewCognitiveEngine(cfg)
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	if _, err := engine.SetNotificationChannel(tenantID, notifications.Channel{
+		Name:   "ops",
+		Type:   notifications.ChannelWebhook,
+		URL:    server.URL,
+		Topics: []string{notifications.TopicAtomWatch},
+	}); err != nil {
+		t.Fatalf("Failed to set channel: %v", err)
+	}
+	db, _ := engine.CreateConceptNode("db-1", tenantID)
+	db.SetTruthValue(atomspace.TruthValue{Strength: 0.9, Confidence: 0.9})
+	
+	if _, err := engine.AddWatch(tenantID, watches.Watch{AtomID: "missing", OnDelete: true}, acl.Principal{}); err == nil {
+		t.Error("Expected a watch of a missing atom to be rejected")
+	}
+	w, err := engine.AddWatch(tenantID, watches.Watch{
+		AtomID:     db.GetID(),
+		Thresholds: []watches.Threshold{{Measure: watches.MeasureConfidence, Direction: watches.DirectionBelow, Value: 0.5}},
+		OnDelete:   true,
+		Severity:   "critical",
+	}, acl.Principal{User: "alice"})
+	if err != nil {
+		t.Fatalf("Failed to add watch: %v", err)
+	}
+	if w.CreatedBy != "alice" {
+		t.Errorf("Expected the watch created by alice, got %+v", w)
+	}
+	
+	// Crossing a threshold notifies once
+	if fired := engine.checkWatches(tenantID); fired != 0 {
+		t.Errorf("Expected nothing to fire yet, got %d", fired)
+	}
+	db.SetTruthValue(atomspace.TruthValue{Strength: 0.9, Confidence: 0.2})
+	if fired := engine.checkWatches(tenantID); fired != 1 {
+		t.Fatalf("Expected the confidence drop to fire, got %d", fired)
+	}
+	body := next()
+	if body["topic"] != notifications.TopicAtomWatch || body["severity"] != "critical" || body["title"] != "Atom db-1 confidence is below 0.5" {
+		t.Errorf("Expected the threshold notification, got %v", body)
+	}
+	if fields := body["fields"].(map[string]interface{}); fields["watch_id"] != w.ID || fields["value"] != "0.2" {
+		t.Errorf("Expected the watch and value in the fields, got %v", fields)
+	}
+	if fired := engine.checkWatches(tenantID); fired != 0 {
+		t.Errorf("Expected no repeat while below, got %d", fired)
+	}
+	
+	// Deleting the atom notifies from its event
+	if err := engine.DeleteAtom(db.GetID(), tenantID); err != nil {
+		t.Fatalf("Failed to delete atom: %v", err)
+	}
+	if body := next(); body["title"] != "Atom db-1 was deleted" {
+		t.Errorf("Expected the deletion notification, got %v", body)
+	}
+	if got, _ := engine.GetWatch(tenantID, w.ID); got.Fired != 2 {
+		t.Errorf("Expected the watch to have fired twice, got %+v", got)
+	}
+	
+	purged, err := engine.PurgeTenant(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Failed to purge tenant: %v", err)
+	}
+	if purged.Removed["watches"] != 1 {
+		t.Errorf("Expected the purge to remove the watch, got %v", purged.Removed)
+	}
+}
+
+func TestKnowledgeHygiene(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if _, err := engine.AnalyzeHygiene("missing", hygiene.DefaultOptions()); err == nil {
+		t.Error("Expected analyzing an uninitialized tenant to fail")
+	}
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	db, _ := engine.CreateConceptNode("db", tenantID)
+	dbDup, _ := engine.CreateConceptNode("DB", tenantID)
+	server, _ := engine.CreateConceptNode("server", tenantID)
+	app, _ := engine.CreateConceptNode("app", tenantID)
+	stray, _ := engine.CreateConceptNode("stray", tenantID)
+	inner, _ := engine.CreateInheritanceLink(db.GetID(), server.GetID(), tenantID)
+	kept, _ := engine.CreateInheritanceLink(dbDup.GetID(), server.GetID(), tenantID)
+	if _, err := engine.CreateInheritanceLink(app.GetID(), dbDup.GetID(), tenantID); err != nil {
+		t.Fatalf("Failed to create link: %v", err)
+	}
+	outgoing := []atomspace.Atom{inner, app}
+	outer := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.SimilarityLinkType, "", outgoing), "", tenantID, atomspace.SimilarityLinkType, outgoing)
+	if err := engine.AddAtom(outer); err != nil {
+		t.Fatalf("Failed to add link: %v", err)
+	}
+	
+	report, err := engine.AnalyzeHygiene(tenantID, hygiene.DefaultOptions())
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	if report.Findings[hygiene.FindingOrphan] != 1 || report.Findings[hygiene.FindingDuplicateName] != 1 {
+		t.Fatalf("Expected an orphan and a duplicate, got %+v", report.Findings)
+	}
+	merge, ok := report.Operation("merge-" + dbDup.GetID())
+	if !ok || merge.AtomIDs[0] != db.GetID() {
+		t.Fatalf("Expected db merged into the more linked DB, got %+v", report.Operations)
+	}
+	
+	result, err := engine.ApplyHygiene(tenantID, []string{"prune-" + stray.GetID(), merge.ID, "prune-unknown"}, false, hygiene.DefaultOptions())
+	if err != nil {
+		t.Fatalf("Failed to apply: %v", err)
+	}
+	if len(result.Applied) != 2 || result.Failed["prune-unknown"] == "" {
+		t.Fatalf("Expected two operations applied and one failed, got %+v", result)
+	}
+	for _, id := range []string{stray.GetID(), db.GetID(), inner.GetID(), outer.GetID()} {
+		if _, err := engine.GetAtom(id, tenantID); err == nil {
+			t.Errorf("Expected atom %s deleted", id)
+		}
+	}
+	
+	// The links of db now refer to DB, nested ones too
+	rewired := []atomspace.Atom{kept, app}
+	if _, err := engine.GetAtom(atomspace.GenerateAtomID(atomspace.SimilarityLinkType, "", rewired), tenantID); err != nil {
+		t.Errorf("Expected the nested link rewired: %v", err)
+	}
+	if report, _ := engine.AnalyzeHygiene(tenantID, hygiene.DefaultOptions()); len(report.Operations) != 0 || report.Score != 100 {
+		t.Errorf("Expected a clean space after applying, got %+v", report)
+	}
+}
+
+func TestConceptMerge(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	node, _ := engine.CreateConceptNode("node-7", tenantID)
+	ip, _ := engine.CreateConceptNode("ip-10-0-0-7", tenantID)
+	k8s, _ := engine.CreateConceptNode("k8s-node-7", tenantID)
+	cluster, _ := engine.CreateConceptNode("cluster", tenantID)
+	for _, atom := range []atomspace.Atom{node, ip, k8s} {
+		engine.UpdateAtom(atom.GetID(), tenantID, func(a atomspace.Atom) error {
+			a.SetTruthValue(atomspace.TruthValue{Strength: 0.8, Confidence: 0.5})
+			return nil
+		})
+	}
+	if _, err := engine.CreateInheritanceLink(ip.GetID(), cluster.GetID(), tenantID); err != nil {
+		t.Fatalf("Failed to create link: %v", err)
+	}
+	
+	if _, err := engine.MergeAtoms(tenantID, node.GetID(), []string{node.GetID()}); err == nil {
+		t.Error("Expected merging an atom into itself to fail")
+	}
+	result, err := engine.MergeAtoms(tenantID, node.GetID(), []string{ip.GetID(), k8s.GetID()})
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if result.Rewired != 1 || len(result.Aliases) != 2 || result.Confidence <= 0.5 || math.Abs(result.Strength-0.8) > 1e-9 {
+		t.Errorf("Expected one link rewired, two aliases and revised confidence, got %+v", result)
+	}
+	rewired := atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", []atomspace.Atom{node, cluster})
+	if _, err := engine.GetAtom(rewired, tenantID); err != nil {
+		t.Errorf("Expected the link rewired to node-7: %v", err)
+	}
+	aliases, err := engine.ListAliases(tenantID, node.GetID())
+	if err != nil || strings.Join(aliases, ",") != "ip-10-0-0-7,k8s-node-7" {
+		t.Errorf("Expected the merged names as aliases, got %v (%v)", aliases, err)
+	}
+	
+	if _, err := engine.AddAlias(tenantID, cluster.GetID(), "k8s-node-7"); err == nil {
+		t.Error("Expected an alias of another atom to be rejected")
+	}
+	if _, err := engine.AddAlias(tenantID, node.GetID(), "cluster"); err == nil {
+		t.Error("Expected the name of an existing atom to be rejected as an alias")
+	}
+	
+	// Ingestion resolves aliases to node-7
+	space := &tenantAtomSpaceWrapper{engine: engine, tenantID: tenantID}
+	again := atomspace.NewNode(ip.GetID(), "ip-10-0-0-7", tenantID, atomspace.ConceptNodeType)
+	if err := space.AddAtom(again); err != nil {
+		t.Fatalf("Failed to ingest an aliased node: %v", err)
+	}
+	if _, err := engine.GetAtom(ip.GetID(), tenantID); err == nil {
+		t.Error("Expected the aliased node not to be recreated")
+	}
+	web, _ := engine.CreateConceptNode("web", tenantID)
+	outgoing := []atomspace.Atom{web, again}
+	link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", outgoing), "inheritance", tenantID, atomspace.InheritanceLinkType, outgoing)
+	for i := 0; i < 2; i++ {
+		if err := space.AddAtom(link); err != nil {
+			t.Fatalf("Failed to ingest a link to an aliased node: %v", err)
+		}
+	}
+	if _, err := engine.GetAtom(atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", []atomspace.Atom{web, node}), tenantID); err != nil {
+		t.Errorf("Expected the ingested link to refer to node-7: %v", err)
+	}
+	
+	if err := engine.DeleteAlias(tenantID, node.GetID(), "ip-10-0-0-7"); err != nil {
+		t.Fatalf("Failed to delete alias: %v", err)
+	}
+	if err := space.AddAtom(again); err != nil {
+		t.Fatalf("Failed to ingest node: %v", err)
+	}
+	if _, err := engine.GetAtom(ip.GetID(), tenantID); err != nil {
+		t.Error("Expected the node added once its alias is removed")
+	}
+}
+
+func TestEntityResolution(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	hostA, _ := engine.CreateConceptNode("Host-A", tenantID)
+	dupA, _ := engine.CreateConceptNode("host_a", tenantID)
+	primary, _ := engine.CreateConceptNode("db-primary", tenantID)
+	replica, _ := engine.CreateConceptNode("db-replica", tenantID)
+	host, _ := engine.CreateConceptNode("Host", tenantID)
+	database, _ := engine.CreateConceptNode("Database", tenantID)
+	pod, _ := engine.CreateConceptNode("pod-1", tenantID)
+	for _, pair := range [][2]atomspace.Atom{{hostA, host}, {dupA, host}, {primary, database}, {replica, database}} {
+		if _, err := engine.CreateInheritanceLink(pair[0].GetID(), pair[1].GetID(), tenantID); err != nil {
+			t.Fatalf("Failed to create link: %v", err)
+		}
+	}
+	if _, err := engine.CreateInheritanceLink(pod.GetID(), hostA.GetID(), tenantID); err != nil {
+		t.Fatalf("Failed to create link: %v", err)
+	}
+	
+	// The hosts are proposed for merging into the one more linked to; the
+	// databases share links but not names
+	candidates, err := engine.ResolveEntities(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Entity resolution failed: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].Into != hostA.GetID() || candidates[0].Atom != dupA.GetID() || candidates[0].Merged {
+		t.Fatalf("Expected host_a proposed for merging into Host-A, got %+v", candidates)
+	}
+	pending := engine.ListRecommendations(tenantID, recommendations.StatusPending)
+	if len(pending) != 1 || pending[0].Kind != recommendations.KindMerge || pending[0].ID != candidates[0].RecommendationID {
+		t.Fatalf("Expected the merge waiting for approval, got %+v", pending)
+	}
+	if got, _ := engine.GetMergeCandidates(tenantID); len(got) != 1 {
+		t.Errorf("Expected the latest candidates kept, got %+v", got)
+	}
+	
+	admin := acl.Principal{User: "alice", Roles: []string{acl.AdminRole}}
+	if _, err := engine.DecideRecommendation(context.Background(), tenantID, pending[0].ID, true, "", admin); err != nil {
+		t.Fatalf("Failed to approve the merge: %v", err)
+	}
+	if _, err := engine.GetAtom(dupA.GetID(), tenantID); err == nil {
+		t.Error("Expected host_a merged away")
+	}
+	if aliases, _ := engine.ListAliases(tenantID, hostA.GetID()); len(aliases) != 1 || aliases[0] != "host_a" {
+		t.Errorf("Expected host_a to become an alias, got %v", aliases)
+	}
+	
+	// Above the auto-merge threshold concepts are merged without approval
+	cache, _ := engine.CreateConceptNode("cache-1", tenantID)
+	dupCache, _ := engine.CreateConceptNode("Cache1", tenantID)
+	config := agents.DefaultEntityResolutionConfig()
+	config.AutoMergeAbove = 0.9
+	engine.EnableEntityResolution(tenantID, config)
+	candidates, err = engine.ResolveEntities(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("Entity resolution failed: %v", err)
+	}
+	if len(candidates) != 1 || !candidates[0].Merged {
+		t.Fatalf("Expected the caches merged, got %+v", candidates)
+	}
+	if _, err := engine.GetAtom(candidates[0].Atom, tenantID); err == nil {
+		t.Error("Expected the merged cache deleted")
+	}
+	if candidates[0].Into != cache.GetID() && candidates[0].Into != dupCache.GetID() {
+		t.Errorf("Expected a cache kept, got %+v", candidates[0])
+	}
+	if err := engine.DisableEntityResolution(tenantID); err != nil {
+		t.Errorf("Failed to disable entity resolution: %v", err)
+	}
+}
+
+func TestShareLinks(t *testing.T) {
+	engine := NewCognitiveEngine(DefaultConfig())
+	defer engine.Close()
+	
+	tenantID := "test-tenant"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	payment, _ := engine.CreateConceptNode("payment-service", tenantID)
+	db, _ := engine.CreateConceptNode("payments-db", tenantID)
+	engine.CreateConceptNode("unrelated", tenantID)
+	uses, err := engine.CreateInheritanceLink(payment.GetID(), db.GetID(), tenantID)
+	if err != nil {
+		t.Fatalf("Failed to create link: %v", err)
+	}
+	concept := atomspace.ConceptNodeType
+	engine.SetSavedQuery(tenantID, queries.Query{Name: "concepts", Query: atomspace.Query{Type: &concept}})
+	
+	author := acl.Principal{User: "alice"}
+	if _, _, err := engine.CreateShare(tenantID, shares.Share{Scope: shares.Scope{Query: "missing"}}, 0, author); err == nil {
+		t.Error("Expected a share of an unknown query to be rejected")
+	}
+	if _, _, err := engine.CreateShare(tenantID, shares.Share{Scope: shares.Scope{Roots: []string{"missing"}}}, 0, author); err == nil {
+		t.Error("Expected a share of an unknown atom to be rejected")
+	}
+	
+	// A subgraph share shows the atoms around its roots as they are now
+	subgraph, token, err := engine.CreateShare(tenantID, shares.Share{Description: "payment dependencies", Scope: shares.Scope{Roots: []string{payment.GetID()}}}, time.Hour, author)
+	if err != nil {
+		t.Fatalf("Failed to create share: %v", err)
+	}
+	if subgraph.CreatedBy != "alice" {
+		t.Errorf("Expected the author recorded, got %+v", subgraph)
+	}
+	view, err := engine.ViewShare(token)
+	if err != nil || len(view.Atoms) != 3 || view.Share.TenantID != tenantID {
+		t.Fatalf("Expected the service, its database and their link, got %+v (%v)", view, err)
+	}
+	engine.UpdateAtom(uses.GetID(), tenantID, func(a atomspace.Atom) error {
+		a.SetTruthValue(atomspace.TruthValue{Strength: 0.2, Confidence: 0.9})
+		return nil
+	})
+	view, _ = engine.ViewShare(token)
+	for _, atom := range view.Atoms {
+		if atom.GetID() == uses.GetID() && atom.GetTruthValue().Strength != 0.2 {
+			t.Errorf("Expected the current truth value shown, got %+v", atom.GetTruthValue())
+		}
+	}
+	
+	_, queryToken, err := engine.CreateShare(tenantID, shares.Share{Scope: shares.Scope{Query: "concepts"}}, 0, author)
+	if err != nil {
+		t.Fatalf("Failed to create share: %v", err)
+	}
+	if view, err := engine.ViewShare(queryToken); err != nil || len(view.Atoms) != 3 {
+		t.Errorf("Expected the saved query's concepts, got %+v (%v)", view, err)
+	}
+	
+	if _, err := engine.ViewShare(token + "x"); !errors.Is(err, shares.ErrInvalid) {
+		t.Errorf("Expected a tampered token rejected, got %v", err)
+	}
+	if err := engine.RevokeShare(tenantID, subgraph.ID); err != nil {
+		t.Fatalf("Failed to revoke share: %v", err)
+	}
+	if _, err := engine.ViewShare(token); !errors.Is(err, shares.ErrInvalid) {
+		t.Errorf("Expected a revoked share rejected, got %v", err)
+	}
+	if list := engine.ListShares(tenantID); len(list) != 1 || list[0].Views != 1 {
+		t.Errorf("Expected the query share with its view, got %+v", list)
+	}
+	
+	report, err := engine.PurgeTenant(context.Background(), tenantID)
+	if err != nil || report.Removed["shares"] != 1 {
+		t.Errorf("Expected the share purged, got %+v (%v)", report, err)
+	}
+	if _, err := engine.ViewShare(queryToken); !errors.Is(err, shares.ErrInvalid) {
+		t.Errorf("Expected a purged tenant's share rejected, got %v", err)
+	}
+}
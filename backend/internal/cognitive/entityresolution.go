@@ -0,0 +1,78 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+)
+
+// EnableEntityResolution registers an entity resolution agent for a tenant,
+// or updates the configuration of the existing one. Merges it proposes go
+// to the tenant's approval queue; approving one merges the concepts.
+func (ce *CognitiveEngine) EnableEntityResolution(tenantID string, config agents.EntityResolutionConfig) *agents.EntityResolutionAgent {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if agent, exists := ce.resolvers[tenantID]; exists {
+		agent.SetConfig(config)
+		return agent
+	}
+
+	agent := agents.NewEntityResolutionAgent(
+		fmt.Sprintf("entity-resolution-%s", tenantID),
+		"EntityResolutionAgent",
+		tenantID,
+		&tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID},
+		ce.recommendations,
+		func(into string, from []string) error {
+			_, err := ce.MergeAtoms(tenantID, into, from)
+			return err
+		},
+		config,
+	)
+	ce.resolvers[tenantID] = agent
+	ce.agentScheduler.RegisterAgent(agent)
+	return agent
+}
+
+// DisableEntityResolution unregisters a tenant's entity resolution agent.
+// Merges already proposed stay in the approval queue.
+func (ce *CognitiveEngine) DisableEntityResolution(tenantID string) error {
+	ce.mu.Lock()
+	agent, exists := ce.resolvers[tenantID]
+	delete(ce.resolvers, tenantID)
+	ce.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("entity resolution not enabled for tenant %s", tenantID)
+	}
+	ce.agentScheduler.UnregisterAgent(agent.GetID())
+	return nil
+}
+
+// ResolveEntities looks for a tenant's duplicate concepts immediately,
+// enabling entity resolution with the default configuration if needed
+func (ce *CognitiveEngine) ResolveEntities(ctx context.Context, tenantID string) ([]agents.MergeCandidate, error) {
+	ce.mu.RLock()
+	agent, exists := ce.resolvers[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		agent = ce.EnableEntityResolution(tenantID, agents.DefaultEntityResolutionConfig())
+	}
+	return agent.Resolve(ctx)
+}
+
+// GetMergeCandidates returns the candidates found by a tenant's latest
+// entity resolution run
+func (ce *CognitiveEngine) GetMergeCandidates(tenantID string) ([]agents.MergeCandidate, error) {
+	ce.mu.RLock()
+	agent, exists := ce.resolvers[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("entity resolution not enabled for tenant %s", tenantID)
+	}
+	return agent.GetCandidates(), nil
+}
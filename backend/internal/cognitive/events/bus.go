@@ -0,0 +1,267 @@
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// EventType identifies the kind of change an event describes
+type EventType string
+
+const (
+	AtomAdded         EventType = "atom.added"
+	AtomUpdated       EventType = "atom.updated"
+	AtomDeleted       EventType = "atom.deleted"
+	PipelineCompleted EventType = "pipeline.completed"
+	PipelineFailed    EventType = "pipeline.failed"
+)
+
+// Event describes a change inside the cognitive engine
+type Event struct {
+	Type       EventType
+	TenantID   string
+	AtomID     string
+	Atom       atomspace.Atom // Atom state at publish time (nil for non-atom events)
+	PipelineID string
+	Error      string
+	Timestamp  time.Time
+}
+
+// Handler consumes events delivered to a subscription
+type Handler func(Event)
+
+// subscription is a single consumer of the bus with its own delivery queue
+type subscription struct {
+	id      int64
+	name    string
+	filter  func(Event) bool
+	handler Handler
+	queue   chan Event
+	dropped int64
+	done    chan struct{}
+
+	// Events queued or being handled, and the time of the oldest of them
+	// or, once some were handled, of the last handled, which is no newer
+	backlog      int
+	backlogSince time.Time
+	backlogMu    sync.Mutex
+}
+
+// enter counts an event queued for the subscription
+func (s *subscription) enter(event Event) {
+	s.backlogMu.Lock()
+	defer s.backlogMu.Unlock()
+	if s.backlog == 0 {
+		s.backlogSince = event.Timestamp
+	}
+	s.backlog++
+}
+
+// leave counts a handled event out
+func (s *subscription) leave(event Event) {
+	s.backlogMu.Lock()
+	defer s.backlogMu.Unlock()
+	s.backlog--
+	s.backlogSince = event.Timestamp
+}
+
+// Bus is an in-process publish/subscribe event bus. Publishing never blocks
+// the caller: each subscriber has a bounded queue and events are dropped
+// (and counted) for subscribers that cannot keep up.
+type Bus struct {
+	subscribers map[int64]*subscription
+	nextID      int64
+	bufferSize  int
+	mu          sync.RWMutex
+
+	publishChan chan Event
+	published   int64
+	done        chan struct{}
+
+	// Events published and not yet fanned out, like a subscription's backlog
+	undispatched      int
+	undispatchedSince time.Time
+	undispatchedMu    sync.Mutex
+}
+
+// NewBus creates a new event bus with the given per-subscriber buffer size
+func NewBus(bufferSize int) *Bus {
+	if bufferSize <= 0 {
+		bufferSize = 1000
+	}
+
+	b := &Bus{
+		subscribers: make(map[int64]*subscription),
+		bufferSize:  bufferSize,
+		publishChan: make(chan Event, bufferSize),
+		done:        make(chan struct{}),
+	}
+
+	go b.dispatch()
+
+	return b
+}
+
+// dispatch fans published events out to subscriber queues
+func (b *Bus) dispatch() {
+	for {
+		select {
+		case event := <-b.publishChan:
+			b.mu.RLock()
+			for _, sub := range b.subscribers {
+				if sub.filter != nil && !sub.filter(event) {
+					continue
+				}
+				sub.enter(event)
+				select {
+				case sub.queue <- event:
+				default:
+					sub.leave(event)
+					atomic.AddInt64(&sub.dropped, 1)
+				}
+			}
+			b.mu.RUnlock()
+			b.undispatchedMu.Lock()
+			b.undispatched--
+			b.undispatchedSince = event.Timestamp
+			b.undispatchedMu.Unlock()
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// Publish submits an event to the bus
+func (b *Bus) Publish(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.undispatchedMu.Lock()
+	if b.undispatched == 0 {
+		b.undispatchedSince = event.Timestamp
+	}
+	b.undispatched++
+	b.undispatchedMu.Unlock()
+
+	select {
+	case b.publishChan <- event:
+		atomic.AddInt64(&b.published, 1)
+	case <-b.done:
+	}
+}
+
+// Subscribe registers a handler for events accepted by filter (nil accepts
+// all events). Handlers run on a dedicated goroutine per subscription, in
+// publish order. The returned ID can be passed to Unsubscribe.
+func (b *Bus) Subscribe(name string, filter func(Event) bool, handler Handler) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	sub := &subscription{
+		id:      b.nextID,
+		name:    name,
+		filter:  filter,
+		handler: handler,
+		queue:   make(chan Event, b.bufferSize),
+		done:    make(chan struct{}),
+	}
+	b.subscribers[sub.id] = sub
+
+	go func() {
+		for {
+			select {
+			case event := <-sub.queue:
+				sub.handler(event)
+				sub.leave(event)
+			case <-sub.done:
+				return
+			case <-b.done:
+				return
+			}
+		}
+	}()
+
+	return sub.id
+}
+
+// Unsubscribe removes a subscription
+func (b *Bus) Unsubscribe(id int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if sub, exists := b.subscribers[id]; exists {
+		close(sub.done)
+		delete(b.subscribers, id)
+	}
+}
+
+// Dropped returns how many events a subscription has dropped
+func (b *Bus) Dropped(id int64) int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if sub, exists := b.subscribers[id]; exists {
+		return atomic.LoadInt64(&sub.dropped)
+	}
+	return 0
+}
+
+// Lag returns how far a subscription is behind the events published so
+// far: zero once it handled all of them, else at least the age of the
+// oldest it has not handled. Events it drops are not waited for.
+func (b *Bus) Lag(id int64) time.Duration {
+	b.mu.RLock()
+	sub, exists := b.subscribers[id]
+	b.mu.RUnlock()
+
+	var oldest time.Time
+	b.undispatchedMu.Lock()
+	if b.undispatched > 0 {
+		oldest = b.undispatchedSince
+	}
+	b.undispatchedMu.Unlock()
+	if exists {
+		sub.backlogMu.Lock()
+		if sub.backlog > 0 && (oldest.IsZero() || sub.backlogSince.Before(oldest)) {
+			oldest = sub.backlogSince
+		}
+		sub.backlogMu.Unlock()
+	}
+
+	if oldest.IsZero() {
+		return 0
+	}
+	return time.Since(oldest)
+}
+
+// GetStats returns bus statistics
+func (b *Bus) GetStats() map[string]interface{} {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	subs := make([]map[string]interface{}, 0, len(b.subscribers))
+	for _, sub := range b.subscribers {
+		subs = append(subs, map[string]interface{}{
+			"id":      sub.id,
+			"name":    sub.name,
+			"pending": len(sub.queue),
+			"dropped": atomic.LoadInt64(&sub.dropped),
+		})
+	}
+
+	return map[string]interface{}{
+		"published":   atomic.LoadInt64(&b.published),
+		"pending":     len(b.publishChan),
+		"subscribers": subs,
+	}
+}
+
+// Close shuts down the bus and all subscriptions
+func (b *Bus) Close() {
+	close(b.done)
+}
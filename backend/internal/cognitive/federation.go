@@ -0,0 +1,40 @@
+package cognitive
+
+import (
+	"context"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/federation"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sharding"
+)
+
+// FederatedQuery selects a tenant's atoms and joins them server-side with
+// rows of external sources, like FindAtoms followed by one lookup per
+// source instead of one per atom
+func (ce *CognitiveEngine) FederatedQuery(ctx context.Context, tenantID string, q federation.Query) (*federation.Result, error) {
+	return ce.Reader(sharding.Consistency{}).FederatedQuery(ctx, tenantID, q)
+}
+
+// FederationConnections returns the SQL connections a tenant's lookups may
+// query, with the named queries they may run
+func (ce *CognitiveEngine) FederationConnections(tenantID string) []federation.ConnectionInfo {
+	return ce.federation.Connections(tenantID)
+}
+
+// FederatedQuery runs a federated query over the atoms a tenant sees, like
+// CognitiveEngine.FederatedQuery
+func (r AtomReader) FederatedQuery(ctx context.Context, tenantID string, q federation.Query) (*federation.Result, error) {
+	if err := r.engine.federation.Validate(tenantID, &q); err != nil {
+		return nil, err
+	}
+
+	query := q.Query
+	if q.SavedQuery != "" {
+		saved, err := r.engine.savedQueries.Get(tenantID, q.SavedQuery)
+		if err != nil {
+			return nil, err
+		}
+		query = saved.Query
+	}
+	atoms, _ := r.FindAtoms(tenantID, query)
+	return r.engine.federation.Join(ctx, tenantID, atoms, q.Lookups)
+}
@@ -0,0 +1,397 @@
+package federation
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// Connection is a SQL database lookups may query through its named queries
+type Connection struct {
+	DB      *sql.DB
+	Tenants []string          // Tenants that may query it; all if empty
+	Queries map[string]string // Queries lookups may run by name, each atom's key bound as $1, e.g. SELECT owner FROM hosts WHERE host = $1
+}
+
+// ConnectionInfo names a connection and the queries lookups may run on it
+type ConnectionInfo struct {
+	Name    string   `json:"name"`
+	Queries []string `json:"queries"`
+}
+
+// Config configures federated queries
+type Config struct {
+	Connections  map[string]Connection // SQL connections by name
+	AllowedHosts []string              // Hosts HTTP lookups may call, e.g. cmdb.internal or *.example.com; none if empty
+	Timeout      time.Duration         // Longest one lookup may take
+	MaxRows      int                   // Most rows a lookup may return
+	MaxLookups   int                   // Most lookups one query may join
+	Concurrency  int                   // Per-key HTTP requests in flight per lookup
+}
+
+// DefaultConfig allows no external source until connections or hosts are
+// configured
+func DefaultConfig() Config {
+	return Config{
+		Timeout:     10 * time.Second,
+		MaxRows:     10000,
+		MaxLookups:  5,
+		Concurrency: 8,
+	}
+}
+
+// Key is the atom field a lookup is keyed by
+type Key string
+
+const (
+	KeyName Key = "name"
+	KeyID   Key = "id"
+)
+
+// Join tells what happens to atoms a lookup has no row for
+type Join string
+
+const (
+	JoinLeft  Join = "left"  // They are kept without the lookup's data
+	JoinInner Join = "inner" // They are dropped
+)
+
+// HTTPLookup reads rows from an HTTP endpoint. Without Batch, URL is called
+// with GET once per key, with {key} replaced by the escaped key, and
+// returns one JSON object or 404. With Batch, URL receives one POST of
+// {"keys": [...]} and returns a JSON array of objects, joined on KeyField.
+type HTTPLookup struct {
+	URL      string            `json:"url"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Batch    bool              `json:"batch,omitempty"`
+	KeyField string            `json:"key_field,omitempty"` // Field of batch rows holding the key
+}
+
+// SQLLookup runs one of the named queries of a configured connection, in a
+// read-only transaction, once per key bound as $1; the first row returned
+// is the key's. Lookups only name queries, which operators configure, so
+// tenants never send SQL.
+type SQLLookup struct {
+	Connection string `json:"connection"`
+	Query      string `json:"query"` // Name of one of the connection's queries
+}
+
+// Lookup is an external source whose rows enrich the atoms of a query
+type Lookup struct {
+	Name       string      `json:"name"` // Field of each result the lookup's row is set under
+	Key        Key         `json:"key,omitempty"`
+	TrimPrefix string      `json:"trim_prefix,omitempty"` // Removed from the key, e.g. node/ to look node/n1 up as n1
+	Join       Join        `json:"join,omitempty"`
+	HTTP       *HTTPLookup `json:"http,omitempty"`
+	SQL        *SQLLookup  `json:"sql,omitempty"`
+}
+
+// Validate checks a lookup and fills in defaults
+func (l *Lookup) Validate() error {
+	if l.Name == "" {
+		return fmt.Errorf("lookup name is required")
+	}
+	if l.Key == "" {
+		l.Key = KeyName
+	}
+	if l.Key != KeyName && l.Key != KeyID {
+		return fmt.Errorf("lookup %s has invalid key %q", l.Name, l.Key)
+	}
+	if l.Join == "" {
+		l.Join = JoinLeft
+	}
+	if l.Join != JoinLeft && l.Join != JoinInner {
+		return fmt.Errorf("lookup %s has invalid join %q", l.Name, l.Join)
+	}
+	if (l.HTTP == nil) == (l.SQL == nil) {
+		return fmt.Errorf("lookup %s needs exactly one of http and sql", l.Name)
+	}
+	if l.HTTP != nil {
+		if l.HTTP.Batch && l.HTTP.KeyField == "" {
+			return fmt.Errorf("batch lookup %s needs a key_field", l.Name)
+		}
+		if !l.HTTP.Batch && !strings.Contains(l.HTTP.URL, "{key}") {
+			return fmt.Errorf("lookup %s url needs a {key} placeholder unless batched", l.Name)
+		}
+	}
+	if l.SQL != nil && (l.SQL.Connection == "" || l.SQL.Query == "") {
+		return fmt.Errorf("sql lookup %s needs a connection and a query", l.Name)
+	}
+	return nil
+}
+
+// key returns the key an atom is looked up by
+func (l *Lookup) key(atom atomspace.Atom) string {
+	key := atom.GetName()
+	if l.Key == KeyID {
+		key = atom.GetID()
+	}
+	return strings.TrimPrefix(key, l.TrimPrefix)
+}
+
+// Query selects atoms and enriches them with external rows. The atoms come
+// from Query, or from the tenant's saved query SavedQuery.
+type Query struct {
+	Query      atomspace.Query `json:"query"`
+	SavedQuery string          `json:"saved_query,omitempty"`
+	Lookups    []Lookup        `json:"lookups"`
+}
+
+// Row is an atom with the rows its lookups matched, by lookup name
+type Row struct {
+	Atom atomspace.Atom
+	Data map[string]map[string]interface{}
+}
+
+// LookupStats tells how a lookup went
+type LookupStats struct {
+	Name     string        `json:"name"`
+	Keys     int           `json:"keys"`
+	Matched  int           `json:"matched"`
+	Requests int           `json:"requests"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Result is the outcome of a federated query
+type Result struct {
+	Rows    []Row         `json:"-"`
+	Lookups []LookupStats `json:"lookups"`
+}
+
+// LookupError is a failure of an external source, as opposed to an invalid
+// query
+type LookupError struct {
+	Lookup string
+	Err    error
+}
+
+func (e *LookupError) Error() string {
+	return fmt.Sprintf("lookup %s failed: %v", e.Lookup, e.Err)
+}
+
+func (e *LookupError) Unwrap() error {
+	return e.Err
+}
+
+// Federator joins atoms with external sources
+type Federator struct {
+	config   Config
+	client   *http.Client
+	queries  int64
+	requests int64
+	failures int64
+}
+
+// New creates a federator, filling in defaults for unset limits
+func New(config Config) *Federator {
+	defaults := DefaultConfig()
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.MaxRows <= 0 {
+		config.MaxRows = defaults.MaxRows
+	}
+	if config.MaxLookups <= 0 {
+		config.MaxLookups = defaults.MaxLookups
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = defaults.Concurrency
+	}
+	return &Federator{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Validate checks that a tenant may run the query's lookups
+func (f *Federator) Validate(tenantID string, q *Query) error {
+	if len(q.Lookups) == 0 {
+		return fmt.Errorf("at least one lookup is required")
+	}
+	if len(q.Lookups) > f.config.MaxLookups {
+		return fmt.Errorf("at most %d lookups may be joined", f.config.MaxLookups)
+	}
+	names := make(map[string]bool)
+	for i := range q.Lookups {
+		l := &q.Lookups[i]
+		if err := l.Validate(); err != nil {
+			return err
+		}
+		if names[l.Name] {
+			return fmt.Errorf("duplicate lookup %s", l.Name)
+		}
+		names[l.Name] = true
+		if l.HTTP != nil {
+			if err := f.allowedURL(l.HTTP.URL); err != nil {
+				return fmt.Errorf("lookup %s: %w", l.Name, err)
+			}
+		}
+		if l.SQL != nil {
+			if _, _, err := f.namedQuery(tenantID, l.SQL.Connection, l.SQL.Query); err != nil {
+				return fmt.Errorf("lookup %s: %w", l.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Connections returns the connections a tenant may query, with their
+// named queries
+func (f *Federator) Connections(tenantID string) []ConnectionInfo {
+	infos := make([]ConnectionInfo, 0, len(f.config.Connections))
+	for name := range f.config.Connections {
+		conn, err := f.connection(tenantID, name)
+		if err != nil {
+			continue
+		}
+		queries := make([]string, 0, len(conn.Queries))
+		for query := range conn.Queries {
+			queries = append(queries, query)
+		}
+		sort.Strings(queries)
+		infos = append(infos, ConnectionInfo{Name: name, Queries: queries})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+func (f *Federator) connection(tenantID, name string) (Connection, error) {
+	conn, exists := f.config.Connections[name]
+	if !exists || conn.DB == nil {
+		return Connection{}, fmt.Errorf("connection %s not found", name)
+	}
+	if len(conn.Tenants) == 0 {
+		return conn, nil
+	}
+	for _, allowed := range conn.Tenants {
+		if allowed == tenantID {
+			return conn, nil
+		}
+	}
+	return Connection{}, fmt.Errorf("connection %s not found", name)
+}
+
+// namedQuery returns the database and SQL of a query of a connection the
+// tenant may query
+func (f *Federator) namedQuery(tenantID, connection, name string) (*sql.DB, string, error) {
+	conn, err := f.connection(tenantID, connection)
+	if err != nil {
+		return nil, "", err
+	}
+	query, exists := conn.Queries[name]
+	if !exists {
+		return nil, "", fmt.Errorf("connection %s has no query %s", connection, name)
+	}
+	return conn.DB, query, nil
+}
+
+// Join runs the query's lookups for atoms and joins their rows with them,
+// in the order of atoms. Each lookup queries its source for the distinct
+// keys of all atoms at once, per key for SQL queries, or concurrently per
+// key for HTTP endpoints without batching. Sources are not queried when there are no atoms. The
+// query must have been validated for the tenant.
+func (f *Federator) Join(ctx context.Context, tenantID string, atoms []atomspace.Atom, lookups []Lookup) (*Result, error) {
+	atomic.AddInt64(&f.queries, 1)
+
+	rows := make([]Row, len(atoms))
+	for i, atom := range atoms {
+		rows[i] = Row{Atom: atom, Data: make(map[string]map[string]interface{})}
+	}
+	result := &Result{Lookups: make([]LookupStats, 0, len(lookups))}
+	for _, l := range lookups {
+		if len(rows) == 0 {
+			break
+		}
+		seen := make(map[string]bool)
+		keys := make([]string, 0, len(atoms))
+		for _, atom := range atoms {
+			if key := l.key(atom); !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(ctx, f.config.Timeout)
+		var matched map[string]map[string]interface{}
+		var requests int
+		var err error
+		switch {
+		case l.SQL != nil:
+			matched, err = f.querySQL(ctx, tenantID, l.SQL, keys)
+			requests = len(keys)
+		case l.HTTP.Batch:
+			matched, err = f.fetchBatch(ctx, l.HTTP, keys)
+			requests = 1
+		default:
+			matched, err = f.fetchEach(ctx, l.HTTP, keys)
+			requests = len(keys)
+		}
+		cancel()
+		atomic.AddInt64(&f.requests, int64(requests))
+		if err != nil {
+			atomic.AddInt64(&f.failures, 1)
+			return nil, &LookupError{Lookup: l.Name, Err: err}
+		}
+
+		kept := rows[:0]
+		for _, row := range rows {
+			if data, ok := matched[l.key(row.Atom)]; ok {
+				row.Data[l.Name] = data
+			} else if l.Join == JoinInner {
+				continue
+			}
+			kept = append(kept, row)
+		}
+		rows = kept
+		result.Lookups = append(result.Lookups, LookupStats{
+			Name:     l.Name,
+			Keys:     len(keys),
+			Matched:  len(matched),
+			Requests: requests,
+			Duration: time.Since(start),
+		})
+	}
+	result.Rows = rows
+	return result, nil
+}
+
+// GetStats returns federation statistics
+func (f *Federator) GetStats() map[string]interface{} {
+	return map[string]interface{}{
+		"connections":   len(f.config.Connections),
+		"allowed_hosts": len(f.config.AllowedHosts),
+		"queries":       atomic.LoadInt64(&f.queries),
+		"requests":      atomic.LoadInt64(&f.requests),
+		"failures":      atomic.LoadInt64(&f.failures),
+	}
+}
+
+// collect gathers per-key results concurrently
+type collect struct {
+	rows map[string]map[string]interface{}
+	err  error
+	mu   sync.Mutex
+}
+
+func (c *collect) set(key string, row map[string]interface{}, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		if c.err == nil {
+			c.err = err
+		}
+		return
+	}
+	if row != nil {
+		c.rows[key] = row
+	}
+}
@@ -0,0 +1,233 @@
+package federation
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// testDriver serves the rows of one fixed table whose first column is the
+// key bound as $1, in read-only transactions only
+type testDriver struct{}
+
+type testConn struct{}
+
+type testStmt struct{}
+
+type testTx struct{}
+
+type testRows struct {
+	key  driver.Value
+	next int
+}
+
+var testTable = [][]driver.Value{
+	{[]byte("n1"), []byte("team-a"), int64(3)},
+	{[]byte("n2"), []byte("team-b"), int64(5)},
+	{[]byte("n9"), []byte("team-c"), int64(1)},
+}
+
+func (testDriver) Open(string) (driver.Conn, error) { return testConn{}, nil }
+
+func (testConn) Prepare(query string) (driver.Stmt, error) {
+	if !strings.Contains(query, "$1") {
+		return nil, errors.New("query does not bind the key")
+	}
+	return testStmt{}, nil
+}
+func (testConn) Close() error              { return nil }
+func (testConn) Begin() (driver.Tx, error) { return nil, errors.New("read-only transactions only") }
+func (testConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if !opts.ReadOnly {
+		return nil, errors.New("read-only transactions only")
+	}
+	return testTx{}, nil
+}
+
+func (testTx) Commit() error   { return nil }
+func (testTx) Rollback() error { return nil }
+
+func (testStmt) Close() error                               { return nil }
+func (testStmt) NumInput() int                              { return -1 }
+func (testStmt) Exec([]driver.Value) (driver.Result, error) { return nil, errors.New("read-only") }
+func (testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expected the key bound")
+	}
+	return &testRows{key: args[0]}, nil
+}
+
+func (r *testRows) Columns() []string { return []string{"host", "owner", "cores"} }
+func (r *testRows) Close() error      { return nil }
+func (r *testRows) Next(dest []driver.Value) error {
+	for ; r.next < len(testTable); r.next++ {
+		if string(testTable[r.next][0].([]byte)) == r.key {
+			copy(dest, testTable[r.next])
+			r.next++
+			return nil
+		}
+	}
+	return io.EOF
+}
+
+func init() {
+	sql.Register("federationtest", testDriver{})
+}
+
+func testAtoms(names ...string) []atomspace.Atom {
+	atoms := make([]atomspace.Atom, 0, len(names))
+	for _, name := range names {
+		atoms = append(atoms, atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, "t", atomspace.ConceptNodeType))
+	}
+	return atoms
+}
+
+func TestJoinSQL(t *testing.T) {
+	db, err := sql.Open("federationtest", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	config := DefaultConfig()
+	config.Connections = map[string]Connection{"cmdb": {DB: db, Tenants: []string{"acme"}, Queries: map[string]string{
+		"hosts":  "SELECT host, owner, cores FROM hosts WHERE host = $1",
+		"broken": "SELECT host, owner, cores FROM hosts",
+	}}}
+	f := New(config)
+
+	q := Query{Lookups: []Lookup{{
+		Name:       "cmdb",
+		TrimPrefix: "node/",
+		SQL:        &SQLLookup{Connection: "cmdb", Query: "hosts"},
+	}}}
+	if err := f.Validate("acme", &q); err != nil {
+		t.Fatalf("expected a valid query: %v", err)
+	}
+	if err := f.Validate("other", &q); err == nil {
+		t.Error("expected the connection hidden from other tenants")
+	}
+	if infos := f.Connections("acme"); len(infos) != 1 || len(infos[0].Queries) != 2 || len(f.Connections("other")) != 0 {
+		t.Errorf("expected acme alone to see cmdb and its queries, got %v", infos)
+	}
+	sent := Query{Lookups: []Lookup{{Name: "cmdb", SQL: &SQLLookup{Connection: "cmdb", Query: "SELECT * FROM secrets"}}}}
+	if err := f.Validate("acme", &sent); err == nil {
+		t.Error("expected SQL other than a named query rejected")
+	}
+
+	atoms := testAtoms("node/n1", "node/n2", "node/n3")
+	result, err := f.Join(context.Background(), "acme", atoms, q.Lookups)
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	if len(result.Rows) != 3 || result.Rows[0].Data["cmdb"]["owner"] != "team-a" || result.Rows[1].Data["cmdb"]["cores"] != int64(5) {
+		t.Errorf("expected n1 and n2 enriched, got %+v", result.Rows)
+	}
+	if _, ok := result.Rows[2].Data["cmdb"]; ok {
+		t.Error("expected n3 left without data")
+	}
+	if stats := result.Lookups[0]; stats.Keys != 3 || stats.Matched != 2 || stats.Requests != 3 {
+		t.Errorf("unexpected lookup stats %+v", stats)
+	}
+
+	q.Lookups[0].Join = JoinInner
+	if result, _ := f.Join(context.Background(), "acme", atoms, q.Lookups); len(result.Rows) != 2 {
+		t.Errorf("expected an inner join to drop n3, got %d rows", len(result.Rows))
+	}
+
+	q.Lookups[0].SQL.Query = "broken"
+	_, err = f.Join(context.Background(), "acme", atoms, q.Lookups)
+	var lookupErr *LookupError
+	if !errors.As(err, &lookupErr) {
+		t.Errorf("expected a lookup error, got %v", err)
+	}
+}
+
+func TestJoinHTTP(t *testing.T) {
+	var requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		if r.Method == http.MethodPost {
+			var body struct{ Keys []string }
+			json.NewDecoder(r.Body).Decode(&body)
+			rows := []map[string]interface{}{}
+			for _, key := range body.Keys {
+				if key != "cache" {
+					rows = append(rows, map[string]interface{}{"service": key, "tier": 1})
+				}
+			}
+			json.NewEncoder(w).Encode(rows)
+			return
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/services/")
+		if key == "cache" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"owner": "owner-of-" + key})
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	config := DefaultConfig()
+	f := New(config)
+	each := Lookup{Name: "owners", HTTP: &HTTPLookup{URL: server.URL + "/services/{key}"}}
+	if err := f.Validate("acme", &Query{Lookups: []Lookup{each}}); err == nil {
+		t.Error("expected hosts not allowed by default")
+	}
+
+	config.AllowedHosts = []string{host}
+	f = New(config)
+	batch := Lookup{Name: "tiers", HTTP: &HTTPLookup{URL: server.URL + "/tiers", Batch: true, KeyField: "service"}}
+	q := Query{Lookups: []Lookup{each, batch}}
+	if err := f.Validate("acme", &q); err != nil {
+		t.Fatalf("expected a valid query: %v", err)
+	}
+
+	atoms := testAtoms("cart", "cache", "cart")
+	result, err := f.Join(context.Background(), "acme", atoms, q.Lookups)
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	if requests := atomic.LoadInt64(&requests); requests != 3 {
+		t.Errorf("expected one request per distinct key plus one batch, got %d", requests)
+	}
+	if result.Rows[0].Data["owners"]["owner"] != "owner-of-cart" || result.Rows[0].Data["tiers"]["tier"] != float64(1) {
+		t.Errorf("expected cart enriched by both lookups, got %+v", result.Rows[0].Data)
+	}
+	if len(result.Rows[1].Data) != 0 {
+		t.Errorf("expected cache not found, got %+v", result.Rows[1].Data)
+	}
+
+	for _, invalid := range []Lookup{
+		{Name: "a", HTTP: &HTTPLookup{URL: server.URL + "/services"}},
+		{Name: "a", HTTP: &HTTPLookup{URL: server.URL, Batch: true}},
+		{Name: "a", HTTP: &HTTPLookup{URL: "file:///etc/{key}"}},
+		{Name: "a", HTTP: &HTTPLookup{URL: "http://169.254.169.254/{key}"}},
+		{Name: "a"},
+		{Name: "a", Key: "label", HTTP: &HTTPLookup{URL: server.URL + "/{key}"}},
+		{Name: "a", SQL: &SQLLookup{Connection: "missing", Query: "hosts"}},
+	} {
+		if err := f.Validate("acme", &Query{Lookups: []Lookup{invalid}}); err == nil {
+			t.Errorf("expected %+v rejected", invalid)
+		}
+	}
+	if err := f.Validate("acme", &Query{Lookups: []Lookup{each, each}}); err == nil {
+		t.Error("expected duplicate lookups rejected")
+	}
+
+	server.Close()
+	if _, err := f.Join(context.Background(), "acme", atoms, q.Lookups); err == nil {
+		t.Error("expected an unreachable source to fail the query")
+	}
+}
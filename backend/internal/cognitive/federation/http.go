@@ -0,0 +1,116 @@
+package federation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+// allowedURL checks that an HTTP lookup calls one of the allowed hosts
+func (f *Federator) allowedURL(raw string) error {
+	u, err := url.Parse(strings.ReplaceAll(raw, "{key}", "key"))
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("url must be http or https")
+	}
+	for _, pattern := range f.config.AllowedHosts {
+		if ok, _ := path.Match(pattern, u.Hostname()); ok || pattern == u.Host {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %s is not allowed", u.Host)
+}
+
+// fetchEach calls the lookup's URL once per key, with at most Concurrency
+// requests in flight. Keys the endpoint answers 404 for are not matched.
+func (f *Federator) fetchEach(ctx context.Context, lookup *HTTPLookup, keys []string) (map[string]map[string]interface{}, error) {
+	c := &collect{rows: make(map[string]map[string]interface{})}
+	slots := make(chan struct{}, f.config.Concurrency)
+	var wg sync.WaitGroup
+	for _, key := range keys {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			var row map[string]interface{}
+			found, err := f.do(ctx, lookup, http.MethodGet, strings.ReplaceAll(lookup.URL, "{key}", url.PathEscape(key)), nil, &row)
+			if !found {
+				row = nil
+			}
+			c.set(key, row, err)
+		}(key)
+	}
+	wg.Wait()
+	return c.rows, c.err
+}
+
+// fetchBatch posts all keys to the lookup's URL at once and indexes the
+// rows returned by their key field
+func (f *Federator) fetchBatch(ctx context.Context, lookup *HTTPLookup, keys []string) (map[string]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	if _, err := f.do(ctx, lookup, http.MethodPost, lookup.URL, map[string]interface{}{"keys": keys}, &rows); err != nil {
+		return nil, err
+	}
+	if len(rows) > f.config.MaxRows {
+		return nil, fmt.Errorf("more than %d rows returned", f.config.MaxRows)
+	}
+	matched := make(map[string]map[string]interface{}, len(rows))
+	for _, row := range rows {
+		if key, ok := row[lookup.KeyField]; ok && key != nil {
+			matched[fmt.Sprint(key)] = row
+		}
+	}
+	return matched, nil
+}
+
+// do sends one request and decodes its JSON response into out. It reports
+// false without error when the endpoint answers 404.
+func (f *Federator) do(ctx context.Context, lookup *HTTPLookup, method, target string, body interface{}, out interface{}) (bool, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return false, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range lookup.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode >= 300 {
+		return false, fmt.Errorf("%s returned status %d", req.URL.Host, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("invalid response from %s: %w", req.URL.Host, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,82 @@
+package federation
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// querySQL runs the lookup's named query once per key in a read-only
+// transaction, the key bound as $1, and keeps the first row of each
+func (f *Federator) querySQL(ctx context.Context, tenantID string, lookup *SQLLookup, keys []string) (map[string]map[string]interface{}, error) {
+	if len(keys) > f.config.MaxRows {
+		return nil, fmt.Errorf("more than %d keys to look up", f.config.MaxRows)
+	}
+	db, query, err := f.namedQuery(tenantID, lookup.Connection, lookup.Query)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	matched := make(map[string]map[string]interface{})
+	for _, key := range keys {
+		row, err := firstRow(ctx, stmt, key)
+		if err != nil {
+			return nil, err
+		}
+		if row != nil {
+			matched[key] = row
+		}
+	}
+	return matched, nil
+}
+
+// firstRow runs a statement for one key and returns its first row, or nil
+// if it returns none
+func firstRow(ctx context.Context, stmt *sql.Stmt, key string) (map[string]interface{}, error) {
+	rows, err := stmt.QueryContext(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := rows.Scan(pointers...); err != nil {
+		return nil, err
+	}
+	row := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		row[column] = sqlValue(values[i])
+	}
+	return row, nil
+}
+
+// sqlValue makes a scanned value JSON-friendly: drivers return text
+// columns as bytes
+func sqlValue(v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
@@ -0,0 +1,115 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/forecast"
+)
+
+// AppendSamples records observations of a tenant's time series
+func (ce *CognitiveEngine) AppendSamples(tenantID, series string, samples []forecast.Sample) error {
+	if series == "" {
+		return fmt.Errorf("series name is required")
+	}
+	ce.timeSeries.Append(tenantID, series, samples...)
+	return nil
+}
+
+// GetTimeSeries returns the names of a tenant's time series
+func (ce *CognitiveEngine) GetTimeSeries(tenantID string) []string {
+	return ce.timeSeries.Series(tenantID)
+}
+
+// GetSamples returns the recorded samples of a time series
+func (ce *CognitiveEngine) GetSamples(tenantID, series string) []forecast.Sample {
+	return ce.timeSeries.Samples(tenantID, series)
+}
+
+// DeleteTimeSeries discards the samples of a time series
+func (ce *CognitiveEngine) DeleteTimeSeries(tenantID, series string) error {
+	if !ce.timeSeries.Delete(tenantID, series) {
+		return fmt.Errorf("time series %s not found", series)
+	}
+	return nil
+}
+
+// ForecastSeries fits a model to one series without writing atoms
+func (ce *CognitiveEngine) ForecastSeries(tenantID, series string, params forecast.Params, horizon time.Duration) (*forecast.Forecast, error) {
+	samples := ce.timeSeries.Samples(tenantID, series)
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("time series %s not found", series)
+	}
+	f, err := forecast.Fit(samples, params, horizon)
+	if err != nil {
+		return nil, err
+	}
+	f.Series = series
+	return f, nil
+}
+
+// EnableForecasting registers a forecasting agent for a tenant, or updates
+// the configuration of the existing one
+func (ce *CognitiveEngine) EnableForecasting(tenantID string, config agents.ForecastConfig) *agents.ForecastAgent {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if agent, exists := ce.forecastAgents[tenantID]; exists {
+		agent.SetConfig(config)
+		return agent
+	}
+
+	agent := agents.NewForecastAgent(
+		fmt.Sprintf("forecast-%s", tenantID),
+		"ForecastAgent",
+		tenantID,
+		&tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID},
+		ce.timeSeries,
+		config,
+	)
+	ce.forecastAgents[tenantID] = agent
+	ce.agentScheduler.RegisterAgent(agent)
+	return agent
+}
+
+// DisableForecasting unregisters a tenant's forecasting agent. Prediction
+// atoms already written are kept.
+func (ce *CognitiveEngine) DisableForecasting(tenantID string) error {
+	ce.mu.Lock()
+	agent, exists := ce.forecastAgents[tenantID]
+	delete(ce.forecastAgents, tenantID)
+	ce.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("forecasting not enabled for tenant %s", tenantID)
+	}
+	ce.agentScheduler.UnregisterAgent(agent.GetID())
+	return nil
+}
+
+// RunForecasts refits a tenant's series immediately, enabling forecasting
+// with the default configuration if needed
+func (ce *CognitiveEngine) RunForecasts(ctx context.Context, tenantID string) (*agents.ForecastAgent, error) {
+	ce.mu.RLock()
+	agent, exists := ce.forecastAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		agent = ce.EnableForecasting(tenantID, agents.DefaultForecastConfig())
+	}
+	return agent, agent.Forecast(ctx)
+}
+
+// GetForecastAgent returns a tenant's forecasting agent
+func (ce *CognitiveEngine) GetForecastAgent(tenantID string) (*agents.ForecastAgent, error) {
+	ce.mu.RLock()
+	defer ce.mu.RUnlock()
+
+	agent, exists := ce.forecastAgents[tenantID]
+	if !exists {
+		return nil, fmt.Errorf("forecasting not enabled for tenant %s", tenantID)
+	}
+	return agent, nil
+}
@@ -0,0 +1,420 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Model selects the forecasting method
+type Model string
+
+const (
+	ModelLinear      Model = "linear"       // Least-squares linear trend
+	ModelHolt        Model = "holt"         // Double exponential smoothing
+	ModelHoltWinters Model = "holt-winters" // Additive triple exponential smoothing
+)
+
+// z95 is the normal quantile of a two-sided 95% interval
+const z95 = 1.96
+
+// Sample is one observation of a time series
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Params configures a forecasting model
+type Params struct {
+	Model        Model   `json:"model"`
+	Alpha        float64 `json:"alpha"`         // Level smoothing
+	Beta         float64 `json:"beta"`          // Trend smoothing
+	Gamma        float64 `json:"gamma"`         // Seasonal smoothing
+	SeasonLength int     `json:"season_length"` // Samples per season for holt-winters
+}
+
+// DefaultParams returns a linear trend model
+func DefaultParams() Params {
+	return Params{Model: ModelLinear, Alpha: 0.5, Beta: 0.3, Gamma: 0.3}
+}
+
+// Point is a forecast value with its 95% prediction interval
+type Point struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+	Lower float64   `json:"lower"`
+	Upper float64   `json:"upper"`
+}
+
+// Forecast is a fitted model projected over a horizon
+type Forecast struct {
+	Series  string        `json:"series"`
+	Model   Model         `json:"model"`
+	Samples int           `json:"samples"`
+	Step    time.Duration `json:"step_ns"`
+	RMSE    float64       `json:"rmse"` // Root mean squared in-sample error
+	Points  []Point       `json:"points"`
+}
+
+// Fit fits a model to the samples and forecasts up to horizon ahead of the
+// last sample, at the median sampling interval
+func Fit(samples []Sample, params Params, horizon time.Duration) (*Forecast, error) {
+	if len(samples) < 3 {
+		return nil, fmt.Errorf("at least 3 samples are required, got %d", len(samples))
+	}
+	samples = append([]Sample(nil), samples...)
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Time.Before(samples[j].Time) })
+
+	step := medianStep(samples)
+	if step <= 0 {
+		return nil, fmt.Errorf("samples must span more than one point in time")
+	}
+	steps := int(horizon / step)
+	if steps < 1 {
+		steps = 1
+	}
+	if steps > 1000 {
+		steps = 1000
+	}
+
+	var (
+		values []float64
+		sigma  func(h int) float64
+		err    error
+		rmse   float64
+	)
+	switch params.Model {
+	case ModelLinear, "":
+		params.Model = ModelLinear
+		values, sigma, rmse = fitLinear(samples, step, steps)
+	case ModelHolt:
+		values, sigma, rmse = fitHolt(samples, params, 0, steps)
+	case ModelHoltWinters:
+		if params.SeasonLength < 2 || len(samples) < 2*params.SeasonLength {
+			err = fmt.Errorf("holt-winters needs a season length of at least 2 and two full seasons of samples")
+			break
+		}
+		values, sigma, rmse = fitHolt(samples, params, params.SeasonLength, steps)
+	default:
+		err = fmt.Errorf("unknown forecast model: %s", params.Model)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	last := samples[len(samples)-1].Time
+	points := make([]Point, steps)
+	for h := 1; h <= steps; h++ {
+		width := z95 * sigma(h)
+		points[h-1] = Point{
+			Time:  last.Add(time.Duration(h) * step),
+			Value: values[h-1],
+			Lower: values[h-1] - width,
+			Upper: values[h-1] + width,
+		}
+	}
+
+	return &Forecast{
+		Model:   params.Model,
+		Samples: len(samples),
+		Step:    step,
+		RMSE:    rmse,
+		Points:  points,
+	}, nil
+}
+
+func medianStep(samples []Sample) time.Duration {
+	diffs := make([]time.Duration, 0, len(samples)-1)
+	for i := 1; i < len(samples); i++ {
+		if d := samples[i].Time.Sub(samples[i-1].Time); d > 0 {
+			diffs = append(diffs, d)
+		}
+	}
+	if len(diffs) == 0 {
+		return 0
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i] < diffs[j] })
+	return diffs[len(diffs)/2]
+}
+
+// fitLinear fits value = a + b*t by least squares, t in steps since the
+// first sample
+func fitLinear(samples []Sample, step time.Duration, steps int) ([]float64, func(int) float64, float64) {
+	n := float64(len(samples))
+	xs := make([]float64, len(samples))
+	var sx, sy float64
+	for i, s := range samples {
+		xs[i] = float64(s.Time.Sub(samples[0].Time)) / float64(step)
+		sx += xs[i]
+		sy += s.Value
+	}
+	mx, my := sx/n, sy/n
+
+	var sxx, sxy float64
+	for i, s := range samples {
+		sxx += (xs[i] - mx) * (xs[i] - mx)
+		sxy += (xs[i] - mx) * (s.Value - my)
+	}
+	slope := 0.0
+	if sxx > 0 {
+		slope = sxy / sxx
+	}
+	intercept := my - slope*mx
+
+	var sse float64
+	for i, s := range samples {
+		r := s.Value - (intercept + slope*xs[i])
+		sse += r * r
+	}
+	sd := 0.0
+	if n > 2 {
+		sd = math.Sqrt(sse / (n - 2))
+	}
+
+	lastX := xs[len(xs)-1]
+	values := make([]float64, steps)
+	for h := 1; h <= steps; h++ {
+		values[h-1] = intercept + slope*(lastX+float64(h))
+	}
+	sigma := func(h int) float64 {
+		x := lastX + float64(h)
+		leverage := 1 / n
+		if sxx > 0 {
+			leverage += (x - mx) * (x - mx) / sxx
+		}
+		return sd * math.Sqrt(1+leverage)
+	}
+	return values, sigma, math.Sqrt(sse / n)
+}
+
+// fitHolt runs Holt's linear method, with additive seasonality when season
+// is positive, and forecasts steps ahead
+func fitHolt(samples []Sample, params Params, season, steps int) ([]float64, func(int) float64, float64) {
+	alpha := clampUnit(params.Alpha, 0.5)
+	beta := clampUnit(params.Beta, 0.3)
+	gamma := clampUnit(params.Gamma, 0.3)
+
+	x := make([]float64, len(samples))
+	for i, s := range samples {
+		x[i] = s.Value
+	}
+
+	var level, trend float64
+	var seasonal []float64
+	start := 1
+	if season > 0 {
+		first, second := mean(x[:season]), mean(x[season:2*season])
+		level = first
+		trend = (second - first) / float64(season)
+		seasonal = make([]float64, season)
+		for i := 0; i < season; i++ {
+			seasonal[i] = x[i] - first
+		}
+		start = season
+	} else {
+		level = x[0]
+		trend = x[1] - x[0]
+	}
+
+	var sse float64
+	errors := 0
+	for t := start; t < len(x); t++ {
+		s := 0.0
+		if season > 0 {
+			s = seasonal[t%season]
+		}
+		predicted := level + trend + s
+		sse += (x[t] - predicted) * (x[t] - predicted)
+		errors++
+
+		previous := level
+		level = alpha*(x[t]-s) + (1-alpha)*(level+trend)
+		trend = beta*(level-previous) + (1-beta)*trend
+		if season > 0 {
+			seasonal[t%season] = gamma*(x[t]-level) + (1-gamma)*seasonal[t%season]
+		}
+	}
+	rmse := 0.0
+	if errors > 0 {
+		rmse = math.Sqrt(sse / float64(errors))
+	}
+
+	values := make([]float64, steps)
+	for h := 1; h <= steps; h++ {
+		v := level + float64(h)*trend
+		if season > 0 {
+			v += seasonal[(len(x)+h-1)%season]
+		}
+		values[h-1] = v
+	}
+	// Prediction error grows with the horizon for smoothing models
+	sigma := func(h int) float64 {
+		return rmse * math.Sqrt(1+float64(h-1)*alpha*alpha*(1+float64(h)*beta))
+	}
+	return values, sigma, rmse
+}
+
+func clampUnit(v, def float64) float64 {
+	if v <= 0 || v > 1 {
+		return def
+	}
+	return v
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// ExceedProbability returns the highest probability, over the points up to
+// within ahead, that the series exceeds threshold, and the time the point
+// forecast first reaches it (zero if it does not)
+func (f *Forecast) ExceedProbability(threshold float64, within time.Duration) (float64, time.Time) {
+	if len(f.Points) == 0 {
+		return 0, time.Time{}
+	}
+	start := f.Points[0].Time.Add(-f.Step)
+
+	var probability float64
+	var reachedAt time.Time
+	for _, p := range f.Points {
+		if p.Time.Sub(start) > within {
+			break
+		}
+		sigma := (p.Upper - p.Value) / z95
+		var prob float64
+		switch {
+		case sigma > 0:
+			prob = 1 - normalCDF((threshold-p.Value)/sigma)
+		case p.Value >= threshold:
+			prob = 1
+		}
+		probability = math.Max(probability, prob)
+		if reachedAt.IsZero() && p.Value >= threshold {
+			reachedAt = p.Time
+		}
+	}
+	return probability, reachedAt
+}
+
+// At returns the forecast point closest to ahead after the last sample
+func (f *Forecast) At(ahead time.Duration) (Point, bool) {
+	if len(f.Points) == 0 || f.Step <= 0 {
+		return Point{}, false
+	}
+	i := int(math.Round(float64(ahead)/float64(f.Step))) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(f.Points) {
+		return Point{}, false
+	}
+	return f.Points[i], true
+}
+
+func normalCDF(z float64) float64 {
+	return 0.5 * math.Erfc(-z/math.Sqrt2)
+}
+
+// Store keeps the most recent samples of each tenant's time series
+type Store struct {
+	series     map[string]map[string][]Sample // tenantID -> series -> samples
+	maxSamples int
+	mu         sync.RWMutex
+}
+
+// NewStore creates a store keeping up to maxSamples per series
+func NewStore(maxSamples int) *Store {
+	if maxSamples <= 0 {
+		maxSamples = 1000
+	}
+	return &Store{
+		series:     make(map[string]map[string][]Sample),
+		maxSamples: maxSamples,
+	}
+}
+
+// Append adds samples to a series, keeping them in time order
+func (s *Store) Append(tenantID, series string, samples ...Sample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenant, exists := s.series[tenantID]
+	if !exists {
+		tenant = make(map[string][]Sample)
+		s.series[tenantID] = tenant
+	}
+
+	current := append(tenant[series], samples...)
+	sort.SliceStable(current, func(i, j int) bool { return current[i].Time.Before(current[j].Time) })
+	if len(current) > s.maxSamples {
+		current = append([]Sample(nil), current[len(current)-s.maxSamples:]...)
+	}
+	tenant[series] = current
+}
+
+// Samples returns the samples of a series
+func (s *Store) Samples(tenantID, series string) []Sample {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Sample(nil), s.series[tenantID][series]...)
+}
+
+// Series returns the names of a tenant's series
+func (s *Store) Series(tenantID string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.series[tenantID]))
+	for name := range s.series[tenantID] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Delete removes a series
+func (s *Store) Delete(tenantID, series string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.series[tenantID][series]; !exists {
+		return false
+	}
+	delete(s.series[tenantID], series)
+	return true
+}
+
+// Purge deletes all series of a tenant and returns how many were removed
+func (s *Store) Purge(tenantID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := len(s.series[tenantID])
+	delete(s.series, tenantID)
+	return purged
+}
+
+// GetStats returns store statistics
+func (s *Store) GetStats() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	series, samples := 0, 0
+	for _, tenant := range s.series {
+		series += len(tenant)
+		for _, values := range tenant {
+			samples += len(values)
+		}
+	}
+	return map[string]interface{}{
+		"series":      series,
+		"samples":     samples,
+		"max_samples": s.maxSamples,
+	}
+}
@@ -0,0 +1,60 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func series(values ...float64) []Sample {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := make([]Sample, len(values))
+	for i, v := range values {
+		samples[i] = Sample{Time: start.Add(time.Duration(i) * time.Hour), Value: v}
+	}
+	return samples
+}
+
+func TestLinearForecast(t *testing.T) {
+	f, err := Fit(series(10, 20, 30, 40, 50), Params{Model: ModelLinear}, 3*time.Hour)
+	if err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+	if len(f.Points) != 3 || f.Step != time.Hour {
+		t.Fatalf("Expected 3 hourly points, got %d every %v", len(f.Points), f.Step)
+	}
+	if math.Abs(f.Points[2].Value-80) > 1e-9 {
+		t.Errorf("Expected 80 three hours ahead, got %f", f.Points[2].Value)
+	}
+
+	prob, at := f.ExceedProbability(75, 3*time.Hour)
+	if prob < 0.99 || !at.Equal(f.Points[2].Time) {
+		t.Errorf("Expected the threshold to be reached at the last point, got %f at %v", prob, at)
+	}
+	if prob, _ := f.ExceedProbability(75, time.Hour); prob > 0.01 {
+		t.Errorf("Expected no exceedance within an hour, got %f", prob)
+	}
+}
+
+func TestHoltWintersForecast(t *testing.T) {
+	values := make([]float64, 0, 24)
+	for i := 0; i < 24; i++ {
+		values = append(values, float64(i)+[]float64{0, 10, 0, -10}[i%4])
+	}
+
+	f, err := Fit(series(values...), Params{Model: ModelHoltWinters, Alpha: 0.5, Beta: 0.1, Gamma: 0.5, SeasonLength: 4}, 4*time.Hour)
+	if err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+	// The next season continues the trend with the same shape
+	expected := []float64{24, 35, 26, 17}
+	for i, want := range expected {
+		if math.Abs(f.Points[i].Value-want) > 3 {
+			t.Errorf("Point %d: expected about %f, got %f", i, want, f.Points[i].Value)
+		}
+	}
+
+	if _, err := Fit(series(1, 2, 3, 4, 5), Params{Model: ModelHoltWinters, SeasonLength: 4}, time.Hour); err == nil {
+		t.Error("Expected holt-winters to require two full seasons")
+	}
+}
@@ -0,0 +1,483 @@
+package cognitive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/diff"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/gitops"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/onboarding"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/promotion"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/queries"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+)
+
+// Kinds of changes that are not manifests of their own
+const (
+	changeKindAgent         = "Agent"
+	changeKindBudget        = "Budget"
+	changeKindRuleSelection = "RuleSelection"
+	changeKindMount         = "Mount"
+)
+
+// ReconcileGitOps converges the engine to the manifests: declared tenants
+// are initialized, and their resources created or updated to match them.
+// With Prune, rules, pipelines, saved queries, agents and budgets that no
+// manifest declares are removed; pipelines built in code, mounts and atoms
+// beyond the declared ontology are kept. Tenants no manifest declares are
+// left alone.
+func (ce *CognitiveEngine) ReconcileGitOps(ctx context.Context) (gitops.Status, error) {
+	return ce.syncGitOps(ctx, true)
+}
+
+// PlanGitOps reports how each declared tenant differs from the manifests,
+// without changing anything
+func (ce *CognitiveEngine) PlanGitOps(ctx context.Context) (gitops.Status, error) {
+	return ce.syncGitOps(ctx, false)
+}
+
+// GetGitOpsStatus returns the outcome of the latest reconciliation
+func (ce *CognitiveEngine) GetGitOpsStatus() (gitops.Status, error) {
+	if !ce.gitopsConfig.Enabled() {
+		return gitops.Status{}, gitops.ErrNotConfigured
+	}
+
+	ce.gitopsMu.Lock()
+	defer ce.gitopsMu.Unlock()
+	return ce.gitopsStatus, nil
+}
+
+// runGitOps reconciles every interval until the engine is closed, and
+// reports the outcome of the first reconciliation as a warm-up
+func (ce *CognitiveEngine) runGitOps(interval time.Duration, reconciled func(map[string]interface{}, error)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-ce.done
+		cancel()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		reconcileCtx, stop := context.WithTimeout(ctx, interval)
+		status, err := ce.ReconcileGitOps(reconcileCtx)
+		stop()
+		if reconciled != nil {
+			reconciled(map[string]interface{}{"revision": status.Revision}, firstTenantError(status, err))
+			reconciled = nil
+		}
+
+		select {
+		case <-ce.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// firstTenantError returns the error of a reconciliation, or else of the
+// first tenant that failed to apply
+func firstTenantError(status gitops.Status, err error) error {
+	if err != nil {
+		return err
+	}
+	for _, t := range status.Tenants {
+		if t.Error != "" {
+			return fmt.Errorf("tenant %s: %s", t.Tenant, t.Error)
+		}
+	}
+	return nil
+}
+
+func (ce *CognitiveEngine) syncGitOps(ctx context.Context, apply bool) (gitops.Status, error) {
+	ce.gitopsMu.Lock()
+	defer ce.gitopsMu.Unlock()
+
+	status := gitops.Status{Source: ce.gitopsConfig.Source(), SyncedAt: time.Now(), Tenants: []gitops.TenantStatus{}}
+	state, err := gitops.Fetch(ctx, ce.gitopsConfig)
+	if errors.Is(err, gitops.ErrNotConfigured) {
+		return gitops.Status{}, err
+	}
+	if err != nil {
+		status.Error = err.Error()
+	} else {
+		status.Revision = state.Revision
+		for _, t := range state.Tenants {
+			if err = ctx.Err(); err != nil {
+				status.Error = err.Error()
+				break
+			}
+			status.Tenants = append(status.Tenants, ce.reconcileTenant(t, apply))
+		}
+	}
+
+	if apply {
+		ce.gitopsStatus = status
+	}
+	return status, err
+}
+
+// reconcileTenant finds how a tenant differs from its desired state and,
+// if apply is set, corrects it
+func (ce *CognitiveEngine) reconcileTenant(t gitops.Tenant, apply bool) gitops.TenantStatus {
+	tenantID := t.Name
+	status := gitops.TenantStatus{Drift: gitops.Drift{Tenant: tenantID, Changes: []gitops.Change{}}}
+	change := func(kind, name, action string) {
+		status.Changes = append(status.Changes, gitops.Change{Kind: kind, Name: name, Action: action})
+	}
+
+	initialized := ce.isInitialized(tenantID)
+	current := promotion.Artifacts{RuleSelection: &ce.ruleSelection}
+	if initialized {
+		current, _ = ce.exportArtifacts(tenantID)
+	} else {
+		change(gitops.KindTenant, tenantID, gitops.ActionCreate)
+	}
+
+	// Only artifacts that differ are applied; the ontology is seeded whole,
+	// as seeding skips what the tenant holds
+	desired := t.Artifacts
+	delta := promotion.Artifacts{Ontology: desired.Ontology}
+	prune := ce.gitopsConfig.Prune
+
+	rules := make(map[string]triggers.Spec, len(current.Rules))
+	for _, rule := range current.Rules {
+		rules[rule.Name] = rule
+	}
+	for _, rule := range desired.Rules {
+		existing, exists := rules[rule.Name]
+		if action, differs := planChange(rule, existing, exists); differs {
+			delta.Rules = append(delta.Rules, rule)
+			change(gitops.KindRule, rule.Name, action)
+		}
+		delete(rules, rule.Name)
+	}
+
+	pipelines := make(map[string]onboarding.Pipeline, len(current.Pipelines))
+	for _, p := range current.Pipelines {
+		pipelines[p.ID] = p
+	}
+	for _, p := range desired.Pipelines {
+		existing, exists := pipelines[p.ID]
+		if action, differs := planChange(p, existing, exists); differs {
+			delta.Pipelines = append(delta.Pipelines, p)
+			change(gitops.KindPipeline, p.ID, action)
+		}
+		delete(pipelines, p.ID)
+	}
+
+	savedQueries := make(map[string]queries.Query, len(current.Queries))
+	for _, q := range current.Queries {
+		savedQueries[q.Name] = q
+	}
+	for _, q := range desired.Queries {
+		existing, exists := savedQueries[q.Name]
+		if action, differs := planChange(q, existing, exists); differs {
+			delta.Queries = append(delta.Queries, q)
+			change(gitops.KindQuery, q.Name, action)
+		}
+		delete(savedQueries, q.Name)
+	}
+
+	if desired.RuleSelection != nil && (current.RuleSelection == nil || *current.RuleSelection != *desired.RuleSelection) {
+		delta.RuleSelection = desired.RuleSelection
+		change(changeKindRuleSelection, tenantID, gitops.ActionUpdate)
+	}
+
+	mounted := make(map[string]bool)
+	for _, spaceID := range ce.GetMounts(tenantID) {
+		mounted[spaceID] = true
+	}
+	for _, spaceID := range desired.Ontology.Mounts {
+		if !mounted[spaceID] {
+			change(changeKindMount, spaceID, gitops.ActionCreate)
+		}
+	}
+	status.Ontology = ce.ontologyDrift(tenantID, desired.Ontology)
+
+	if prune {
+		var undeclared []gitops.Change
+		for name := range rules {
+			undeclared = append(undeclared, gitops.Change{Kind: gitops.KindRule, Name: name, Action: gitops.ActionDelete})
+		}
+		for id := range pipelines {
+			undeclared = append(undeclared, gitops.Change{Kind: gitops.KindPipeline, Name: id, Action: gitops.ActionDelete})
+		}
+		for name := range savedQueries {
+			undeclared = append(undeclared, gitops.Change{Kind: gitops.KindQuery, Name: name, Action: gitops.ActionDelete})
+		}
+		sort.Slice(undeclared, func(i, j int) bool {
+			a, b := undeclared[i], undeclared[j]
+			return a.Kind < b.Kind || (a.Kind == b.Kind && a.Name < b.Name)
+		})
+		status.Changes = append(status.Changes, undeclared...)
+	}
+
+	agentStates := ce.tenantAgents(tenantID, t.Agents)
+	for _, a := range agentStates {
+		switch {
+		case a.desired != nil && a.current == nil:
+			change(changeKindAgent, a.name, gitops.ActionCreate)
+		case a.desired != nil && !equalJSON(a.desired, a.current):
+			change(changeKindAgent, a.name, gitops.ActionUpdate)
+		case a.desired == nil && a.current != nil && prune:
+			change(changeKindAgent, a.name, gitops.ActionDelete)
+		}
+	}
+
+	budget, budgeted := ce.agentScheduler.Budgets().GetBudget(tenantID)
+	switch {
+	case t.Budget != nil && !budgeted:
+		change(changeKindBudget, tenantID, gitops.ActionCreate)
+	case t.Budget != nil && budget != *t.Budget:
+		change(changeKindBudget, tenantID, gitops.ActionUpdate)
+	case t.Budget == nil && budgeted && prune:
+		change(changeKindBudget, tenantID, gitops.ActionDelete)
+	}
+
+	if !apply || status.InSync() {
+		return status
+	}
+	applied, err := ce.applyTenant(t, initialized, delta, status.Changes, agentStates)
+	status.Applied = &applied
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// applyTenant applies the changes found for a tenant: the artifacts that
+// differ, then removals, agents and the budget
+func (ce *CognitiveEngine) applyTenant(t gitops.Tenant, initialized bool, delta promotion.Artifacts, changes []gitops.Change, agentStates []agentState) (promotion.Applied, error) {
+	tenantID := t.Name
+	if !initialized {
+		if err := ce.InitializeTenant(tenantID); err != nil {
+			return promotion.Applied{}, err
+		}
+	}
+	applied, err := ce.applyArtifacts(tenantID, delta)
+	if err != nil {
+		return applied, err
+	}
+
+	actions := make(map[string]map[string]string) // Kind -> name -> action
+	for _, c := range changes {
+		if actions[c.Kind] == nil {
+			actions[c.Kind] = make(map[string]string)
+		}
+		actions[c.Kind][c.Name] = c.Action
+	}
+	for _, trigger := range ce.triggerManager.GetTriggersByTenant(tenantID) {
+		if actions[gitops.KindRule][trigger.Name] == gitops.ActionDelete {
+			ce.triggerManager.RemoveTrigger(trigger.ID, tenantID)
+		}
+	}
+	for _, p := range ce.pipelineOrch.GetPipelinesByTenant(tenantID) {
+		if p.Spec != nil && actions[gitops.KindPipeline][strings.TrimSuffix(p.ID, "-"+tenantID)] == gitops.ActionDelete {
+			ce.pipelineOrch.DeletePipeline(p.ID)
+		}
+	}
+	for name, action := range actions[gitops.KindQuery] {
+		if action == gitops.ActionDelete {
+			ce.savedQueries.Delete(tenantID, name)
+		}
+	}
+
+	for _, a := range agentStates {
+		switch actions[changeKindAgent][a.name] {
+		case gitops.ActionCreate, gitops.ActionUpdate:
+			a.enable()
+		case gitops.ActionDelete:
+			if err := a.disable(); err != nil {
+				return applied, err
+			}
+		}
+	}
+
+	switch actions[changeKindBudget][tenantID] {
+	case gitops.ActionCreate, gitops.ActionUpdate:
+		if err := ce.SetTenantBudget(tenantID, *t.Budget); err != nil {
+			return applied, err
+		}
+	case gitops.ActionDelete:
+		ce.RemoveTenantBudget(tenantID)
+	}
+	return applied, nil
+}
+
+// planChange returns the action that brings a resource to its desired
+// form, if its current form differs
+func planChange(desired, current interface{}, exists bool) (string, bool) {
+	switch {
+	case !exists:
+		return gitops.ActionCreate, true
+	case !equalJSON(desired, current):
+		return gitops.ActionUpdate, true
+	}
+	return "", false
+}
+
+// equalJSON reports whether two values have the same JSON form
+func equalJSON(a, b interface{}) bool {
+	x, errA := json.Marshal(a)
+	y, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(x, y)
+}
+
+// agentState is an agent of a tenant as declared and as running
+type agentState struct {
+	name    string      // Key of the agent in manifests
+	desired interface{} // Declared configuration; nil if not declared
+	current interface{} // Configuration of the running agent; nil if none
+	enable  func()      // Registers the agent, or updates its configuration, as declared
+	disable func() error
+}
+
+// tenantAgents pairs the agents declared for a tenant with those running
+func (ce *CognitiveEngine) tenantAgents(tenantID string, declared onboarding.Agents) []agentState {
+	ce.mu.RLock()
+	defer ce.mu.RUnlock()
+
+	var states []agentState
+	{
+		s := agentState{name: "pattern_mining", disable: func() error { return ce.DisablePatternMining(tenantID) }}
+		if c := declared.PatternMining; c != nil {
+			s.desired, s.enable = *c, func() { ce.EnablePatternMining(tenantID, *c) }
+		}
+		if agent, running := ce.patternMiners[tenantID]; running {
+			s.current = agent.GetConfig()
+		}
+		states = append(states, s)
+	}
+	{
+		s := agentState{name: "clustering", disable: func() error { return ce.DisableClustering(tenantID) }}
+		if c := declared.Clustering; c != nil {
+			s.desired, s.enable = *c, func() { ce.EnableClustering(tenantID, *c) }
+		}
+		if agent, running := ce.clusterAgents[tenantID]; running {
+			s.current = agent.GetConfig()
+		}
+		states = append(states, s)
+	}
+	{
+		s := agentState{name: "forecasting", disable: func() error { return ce.DisableForecasting(tenantID) }}
+		if c := declared.Forecasting; c != nil {
+			s.desired, s.enable = *c, func() { ce.EnableForecasting(tenantID, *c) }
+		}
+		if agent, running := ce.forecastAgents[tenantID]; running {
+			s.current = agent.GetConfig()
+		}
+		states = append(states, s)
+	}
+	{
+		s := agentState{name: "cost", disable: func() error { return ce.DisableCostTracking(tenantID) }}
+		if c := declared.Cost; c != nil {
+			s.desired, s.enable = *c, func() { ce.EnableCostTracking(tenantID, *c) }
+		}
+		if agent, running := ce.costAgents[tenantID]; running {
+			s.current = agent.GetConfig()
+		}
+		states = append(states, s)
+	}
+	{
+		s := agentState{name: "slo", disable: func() error { return ce.DisableSLOTracking(tenantID) }}
+		if c := declared.SLO; c != nil {
+			s.desired, s.enable = *c, func() { ce.EnableSLOTracking(tenantID, *c) }
+		}
+		if agent, running := ce.sloAgents[tenantID]; running {
+			s.current = agent.GetConfig()
+		}
+		states = append(states, s)
+	}
+	{
+		s := agentState{name: "runbooks", disable: func() error { return ce.DisableRunbooks(tenantID) }}
+		if c := declared.Runbooks; c != nil {
+			s.desired, s.enable = *c, func() { ce.EnableRunbooks(tenantID, *c) }
+		}
+		if agent, running := ce.runbookAgents[tenantID]; running {
+			s.current = agent.GetConfig()
+		}
+		states = append(states, s)
+	}
+	{
+		s := agentState{name: "drift", disable: func() error { return ce.DisableDrift(tenantID) }}
+		if c := declared.Drift; c != nil {
+			s.desired, s.enable = *c, func() { ce.EnableDrift(tenantID, *c) }
+		}
+		if agent, running := ce.driftAgents[tenantID]; running {
+			s.current = agent.GetConfig()
+		}
+		states = append(states, s)
+	}
+	{
+		s := agentState{name: "reports", disable: func() error { return ce.DisableReports(tenantID) }}
+		if c := declared.Reports; c != nil {
+			s.desired, s.enable = *c, func() { ce.EnableReports(tenantID, *c) }
+		}
+		if agent, running := ce.reportAgents[tenantID]; running {
+			s.current = agent.GetConfig()
+		}
+		states = append(states, s)
+	}
+	return states
+}
+
+// ontologyDrift compares a tenant's ontology, its concepts linked by
+// inheritance and the declared concepts it holds, with the declared
+// ontology. Declared atoms without truth values take the tenant's.
+func (ce *CognitiveEngine) ontologyDrift(tenantID string, ontology onboarding.Ontology) *diff.Diff {
+	held := make(map[string]atomspace.Atom)
+	links := ce.shardManager.QueryAtoms(tenantID, func(atom atomspace.Atom) bool {
+		return atom.GetType() == atomspace.InheritanceLinkType
+	})
+	for _, atom := range links {
+		link, ok := atom.(*atomspace.Link)
+		if !ok || len(link.GetOutgoing()) != 2 {
+			continue
+		}
+		child, parent := link.GetOutgoing()[0], link.GetOutgoing()[1]
+		if child.GetType() != atomspace.ConceptNodeType || parent.GetType() != atomspace.ConceptNodeType {
+			continue
+		}
+		held[link.GetID()], held[child.GetID()], held[parent.GetID()] = link, child, parent
+	}
+
+	var declared []atomspace.Atom
+	nodes := make(map[string]atomspace.Atom, len(ontology.Concepts))
+	for _, c := range ontology.Concepts {
+		atomID := atomspace.GenerateAtomID(atomspace.ConceptNodeType, c.Name, nil)
+		node := atomspace.NewNode(atomID, c.Name, tenantID, atomspace.ConceptNodeType)
+		if existing, err := ce.GetAtom(atomID, tenantID); err == nil {
+			held[atomID] = existing
+			node.SetTruthValue(existing.GetTruthValue())
+		}
+		if c.Strength > 0 || c.Confidence > 0 {
+			node.SetTruthValue(atomspace.TruthValue{Strength: c.Strength, Confidence: c.Confidence})
+		}
+		nodes[c.Name] = node
+		declared = append(declared, node)
+	}
+	for _, l := range ontology.Inheritance {
+		outgoing := []atomspace.Atom{nodes[l.Child], nodes[l.Parent]}
+		atomID := atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", outgoing)
+		link := atomspace.NewLink(atomID, "inheritance", tenantID, atomspace.InheritanceLinkType, outgoing)
+		if existing, exists := held[atomID]; exists {
+			link.SetTruthValue(existing.GetTruthValue())
+		}
+		declared = append(declared, link)
+	}
+
+	current := make([]atomspace.Atom, 0, len(held))
+	for _, atom := range held {
+		current = append(current, atom)
+	}
+	return diff.Compare(current, declared)
+}
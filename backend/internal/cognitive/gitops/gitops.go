@@ -0,0 +1,318 @@
+package gitops
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/budget"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/diff"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/onboarding"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/pipeline"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/promotion"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/queries"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+	"go.yaml.in/yaml/v3"
+)
+
+// ErrNotConfigured is returned when the engine reconciles no manifests
+var ErrNotConfigured = errors.New("gitops not configured")
+
+// Kinds of manifests
+const (
+	KindTenant   = "Tenant"
+	KindRule     = "Rule"
+	KindPipeline = "Pipeline"
+	KindQuery    = "Query"
+	KindAgents   = "Agents"
+	KindOntology = "Ontology"
+)
+
+// Manifest is one YAML document describing a tenant or one of its
+// resources. The spec of each kind has the JSON form the API accepts.
+type Manifest struct {
+	Kind   string          `json:"kind"`
+	Name   string          `json:"name,omitempty"`   // Name of the resource; optional for agents and ontologies
+	Tenant string          `json:"tenant,omitempty"` // Tenant of the resource; unset for tenants
+	Spec   json.RawMessage `json:"spec,omitempty"`
+	Source string          `json:"-"` // File and document the manifest was read from
+}
+
+// TenantSpec is the spec of a Tenant manifest
+type TenantSpec struct {
+	RuleSelection *inference.SelectionConfig `json:"rule_selection,omitempty"`
+	Budget        *budget.Budget             `json:"budget,omitempty"` // Quota of the tenant's agents
+}
+
+// Tenant is the desired state of a tenant
+type Tenant struct {
+	Name      string              `json:"name"`
+	Budget    *budget.Budget      `json:"budget,omitempty"`
+	Agents    onboarding.Agents   `json:"agents"`
+	Artifacts promotion.Artifacts `json:"artifacts"` // Rules, rule selection, pipelines, saved queries and ontology
+}
+
+// State is the desired state described by a set of manifests
+type State struct {
+	Revision string   `json:"revision"` // Commit of the repository, or checksum of the manifests
+	Tenants  []Tenant `json:"tenants"`  // Sorted by name
+}
+
+// Parse reads the manifests of a YAML file, which may hold several
+// documents separated by ---
+func Parse(name string, data []byte) ([]Manifest, error) {
+	var manifests []Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for i := 1; ; i++ {
+		var doc interface{}
+		if err := decoder.Decode(&doc); errors.Is(err, io.EOF) {
+			return manifests, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if doc == nil {
+			continue
+		}
+
+		// Specs are decoded as JSON, so they keep the API's field names
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("%s, document %d: %w", name, i, err)
+		}
+		var m Manifest
+		if err := decodeStrict(data, &m); err != nil {
+			return nil, fmt.Errorf("%s, document %d: %w", name, i, err)
+		}
+		m.Source = fmt.Sprintf("%s, document %d", name, i)
+		manifests = append(manifests, m)
+	}
+}
+
+// Load reads the manifests of the YAML files under a directory, skipping
+// hidden files and directories, and builds the desired state. Its revision
+// is a checksum of the files.
+func Load(dir string) (*State, error) {
+	var manifests []Manifest
+	sum := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || (filepath.Ext(path) != ".yaml" && filepath.Ext(path) != ".yml") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		name, _ := filepath.Rel(dir, path)
+		fmt.Fprintf(sum, "%s\x00%d\x00", name, len(data))
+		sum.Write(data)
+
+		parsed, err := Parse(name, data)
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, parsed...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading manifests failed: %w", err)
+	}
+
+	state, err := Build(manifests)
+	if err != nil {
+		return nil, err
+	}
+	state.Revision = "sha256:" + hex.EncodeToString(sum.Sum(nil))
+	return state, nil
+}
+
+// Build assembles the desired state of each tenant from its manifests and
+// validates it. Every tenant a resource belongs to needs a Tenant manifest,
+// so that a misspelt tenant is not created.
+func Build(manifests []Manifest) (*State, error) {
+	tenants := make(map[string]*Tenant)
+	for _, m := range manifests {
+		if m.Kind != KindTenant {
+			continue
+		}
+		if m.Name == "" {
+			return nil, fmt.Errorf("%s: tenant name is required", m.Source)
+		}
+		if _, exists := tenants[m.Name]; exists {
+			return nil, fmt.Errorf("%s: tenant %s declared twice", m.Source, m.Name)
+		}
+		var spec TenantSpec
+		if err := decodeSpec(m, &spec); err != nil {
+			return nil, err
+		}
+		tenants[m.Name] = &Tenant{
+			Name:   m.Name,
+			Budget: spec.Budget,
+			Artifacts: promotion.Artifacts{
+				Rules:         []triggers.Spec{},
+				RuleSelection: spec.RuleSelection,
+				Pipelines:     []onboarding.Pipeline{},
+				Queries:       []queries.Query{},
+			},
+		}
+	}
+
+	agentsDeclared := make(map[string]bool)
+	for _, m := range manifests {
+		if m.Kind == KindTenant {
+			continue
+		}
+		switch m.Kind {
+		case KindRule, KindPipeline, KindQuery, KindAgents, KindOntology:
+		default:
+			return nil, fmt.Errorf("%s: unknown kind %q", m.Source, m.Kind)
+		}
+		t, exists := tenants[m.Tenant]
+		if !exists {
+			return nil, fmt.Errorf("%s: tenant %q has no Tenant manifest", m.Source, m.Tenant)
+		}
+		if m.Name == "" && m.Kind != KindAgents && m.Kind != KindOntology {
+			return nil, fmt.Errorf("%s: %s name is required", m.Source, strings.ToLower(m.Kind))
+		}
+		a := &t.Artifacts
+
+		switch m.Kind {
+		case KindRule:
+			rule := triggers.Spec{Enabled: true}
+			if err := decodeSpec(m, &rule); err != nil {
+				return nil, err
+			}
+			rule.Name = m.Name
+			a.Rules = append(a.Rules, rule)
+		case KindPipeline:
+			var spec pipeline.PipelineSpec
+			if err := decodeSpec(m, &spec); err != nil {
+				return nil, err
+			}
+			if spec.Name == "" {
+				spec.Name = m.Name
+			}
+			a.Pipelines = append(a.Pipelines, onboarding.Pipeline{ID: m.Name, PipelineSpec: spec})
+		case KindQuery:
+			var q queries.Query
+			if err := decodeSpec(m, &q); err != nil {
+				return nil, err
+			}
+			q.Name = m.Name
+			a.Queries = append(a.Queries, q)
+		case KindAgents:
+			if agentsDeclared[m.Tenant] {
+				return nil, fmt.Errorf("%s: agents of tenant %s declared twice", m.Source, m.Tenant)
+			}
+			agentsDeclared[m.Tenant] = true
+			if err := decodeSpec(m, &t.Agents); err != nil {
+				return nil, err
+			}
+		case KindOntology:
+			// Ontologies of a tenant may be split across manifests
+			var o onboarding.Ontology
+			if err := decodeSpec(m, &o); err != nil {
+				return nil, err
+			}
+			a.Ontology.Mounts = append(a.Ontology.Mounts, o.Mounts...)
+			a.Ontology.Concepts = append(a.Ontology.Concepts, o.Concepts...)
+			a.Ontology.Inheritance = append(a.Ontology.Inheritance, o.Inheritance...)
+		}
+	}
+
+	state := &State{Tenants: make([]Tenant, 0, len(tenants))}
+	for _, t := range tenants {
+		if err := t.Artifacts.Validate(); err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", t.Name, err)
+		}
+		if t.Budget != nil {
+			if err := t.Budget.Validate(); err != nil {
+				return nil, fmt.Errorf("tenant %s: %w", t.Name, err)
+			}
+		}
+		state.Tenants = append(state.Tenants, *t)
+	}
+	sort.Slice(state.Tenants, func(i, j int) bool { return state.Tenants[i].Name < state.Tenants[j].Name })
+	return state, nil
+}
+
+// decodeSpec decodes the spec of a manifest, rejecting unknown fields
+func decodeSpec(m Manifest, v interface{}) error {
+	if len(m.Spec) == 0 {
+		return nil
+	}
+	if err := decodeStrict(m.Spec, v); err != nil {
+		return fmt.Errorf("%s: invalid %s spec: %w", m.Source, m.Kind, err)
+	}
+	return nil
+}
+
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// Actions of a change
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Change is a resource of a tenant that differs from its manifest
+type Change struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Action string `json:"action"` // create, update or delete
+}
+
+// Drift is how a tenant differs from its manifests
+type Drift struct {
+	Tenant   string     `json:"tenant"`
+	Changes  []Change   `json:"changes"`
+	Ontology *diff.Diff `json:"ontology"` // From the tenant's ontology to the declared one
+}
+
+// InSync reports whether the tenant matches its manifests. Atoms the tenant
+// holds beyond its declared ontology are data and do not count.
+func (d *Drift) InSync() bool {
+	return len(d.Changes) == 0 && (d.Ontology == nil || len(d.Ontology.Added)+len(d.Ontology.Changed) == 0)
+}
+
+// TenantStatus is the outcome of reconciling a tenant: the drift found and
+// what was applied to correct it
+type TenantStatus struct {
+	Drift
+	Applied *promotion.Applied `json:"applied,omitempty"`
+	Error   string             `json:"error,omitempty"`
+}
+
+// Status is the outcome of the latest reconciliation
+type Status struct {
+	Source   string         `json:"source"`
+	Revision string         `json:"revision,omitempty"`
+	SyncedAt time.Time      `json:"synced_at,omitempty"`
+	Error    string         `json:"error,omitempty"` // Why the manifests could not be read
+	Tenants  []TenantStatus `json:"tenants"`
+}
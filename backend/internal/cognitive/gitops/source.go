@@ -0,0 +1,133 @@
+package gitops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Config configures where manifests are read from and how often the
+// engine converges to them
+type Config struct {
+	Dir      string        // Directory the manifests are read from, or the repository is checked out to
+	Repo     string        // Git repository cloned into Dir and fetched before each reconciliation, if set
+	Branch   string        // Branch of the repository; its default branch if empty
+	Path     string        // Directory of the manifests within the checkout
+	Interval time.Duration // How often the engine reconciles; a minute if not positive
+	Prune    bool          // Remove rules, pipelines, queries, agents and budgets of declared tenants that no manifest declares
+}
+
+// DefaultConfig reconciles every minute and prunes. No manifests are read
+// until Dir is set.
+func DefaultConfig() Config {
+	return Config{Interval: time.Minute, Prune: true}
+}
+
+// Enabled reports whether manifests are configured
+func (c Config) Enabled() bool {
+	return c.Dir != ""
+}
+
+// Validate checks the configuration for consistency
+func (c Config) Validate() error {
+	if c.Repo != "" && c.Dir == "" {
+		return fmt.Errorf("gitops repo requires a dir to check it out to")
+	}
+	if filepath.IsAbs(c.Path) || strings.HasPrefix(filepath.Clean(c.Path), "..") {
+		return fmt.Errorf("gitops path must be within the checkout")
+	}
+	return nil
+}
+
+// Source describes where manifests are read from, with any password in
+// the repository URL redacted
+func (c Config) Source() string {
+	if c.Repo == "" {
+		return filepath.Join(c.Dir, c.Path)
+	}
+	source := c.Repo
+	if u, err := url.Parse(c.Repo); err == nil && u.User != nil {
+		source = u.Redacted()
+	}
+	if c.Branch != "" {
+		source += "#" + c.Branch
+	}
+	if c.Path != "" {
+		source += ":" + c.Path
+	}
+	return source
+}
+
+// Fetch brings the checkout of the repository up to date, if one is
+// configured, and loads the desired state from the manifests. The revision
+// of a repository is its commit.
+func Fetch(ctx context.Context, c Config) (*State, error) {
+	if !c.Enabled() {
+		return nil, ErrNotConfigured
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	var commit string
+	if c.Repo != "" {
+		var err error
+		if commit, err = checkout(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+
+	state, err := Load(filepath.Join(c.Dir, c.Path))
+	if err != nil {
+		return nil, err
+	}
+	if commit != "" {
+		state.Revision = commit
+	}
+	return state, nil
+}
+
+// checkout clones the repository, or fetches the branch into the existing
+// clone and resets it, and returns the commit checked out
+func checkout(ctx context.Context, c Config) (string, error) {
+	if _, err := os.Stat(filepath.Join(c.Dir, ".git")); errors.Is(err, fs.ErrNotExist) {
+		args := []string{"clone", "--depth", "1"}
+		if c.Branch != "" {
+			args = append(args, "--branch", c.Branch)
+		}
+		if _, err := git(ctx, "", append(args, "--", c.Repo, c.Dir)...); err != nil {
+			return "", err
+		}
+	} else {
+		ref := c.Branch
+		if ref == "" {
+			ref = "HEAD"
+		}
+		if _, err := git(ctx, c.Dir, "fetch", "--depth", "1", "origin", ref); err != nil {
+			return "", err
+		}
+		if _, err := git(ctx, c.Dir, "reset", "--hard", "FETCH_HEAD"); err != nil {
+			return "", err
+		}
+	}
+	return git(ctx, c.Dir, "rev-parse", "HEAD")
+}
+
+// git runs a git command without prompting for credentials
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,127 @@
+package cognitive
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+)
+
+// GroundedArgument is an argument of a grounded predicate: a number, an
+// existing atom such as a measurement, or a call of a grounded function
+type GroundedArgument struct {
+	Number    *float64           `json:"number,omitempty"`
+	AtomID    string             `json:"atom_id,omitempty"`
+	Function  string             `json:"function,omitempty"`
+	Arguments []GroundedArgument `json:"arguments,omitempty"`
+}
+
+// AddGroundedEvaluation adds EvaluationLink(GroundedPredicateNode
+// predicate, arguments...) to a tenant's space with the number and function
+// call atoms its arguments need, and returns it evaluated. Nothing is added
+// if it cannot be evaluated. Inference re-evaluates it as its arguments
+// change.
+func (ce *CognitiveEngine) AddGroundedEvaluation(tenantID, predicate string, args []GroundedArgument) (atomspace.Atom, error) {
+	if !inference.IsGroundedPredicate(predicate) {
+		return nil, fmt.Errorf("unknown grounded predicate %q", predicate)
+	}
+	outgoing, err := ce.groundedCall(tenantID, predicate, args, 0)
+	if err != nil {
+		return nil, err
+	}
+	link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, predicate, outgoing), predicate, tenantID, atomspace.EvaluationLinkType, outgoing)
+	tv, err := inference.Evaluate(link)
+	if err != nil {
+		return nil, err
+	}
+	for _, atom := range outgoing {
+		if err := ce.ensureGrounded(atom); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := ce.GetAtom(link.GetID(), tenantID); err == nil {
+		err := ce.UpdateAtom(link.GetID(), tenantID, func(atom atomspace.Atom) error {
+			atom.SetTruthValue(tv)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return ce.GetAtom(link.GetID(), tenantID)
+	}
+	link.SetTruthValue(tv)
+	if err := ce.AddAtom(link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// groundedCall returns the outgoing set of a call of a grounded predicate
+// or function, building the atoms of its arguments
+func (ce *CognitiveEngine) groundedCall(tenantID, name string, args []GroundedArgument, depth int) ([]atomspace.Atom, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%s needs arguments", name)
+	}
+	outgoing := []atomspace.Atom{atomspace.NewGroundedPredicateNode(name, tenantID)}
+	for _, arg := range args {
+		atom, err := ce.groundedArgument(tenantID, arg, depth)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		outgoing = append(outgoing, atom)
+	}
+	return outgoing, nil
+}
+
+func (ce *CognitiveEngine) groundedArgument(tenantID string, arg GroundedArgument, depth int) (atomspace.Atom, error) {
+	set := 0
+	for _, given := range []bool{arg.Number != nil, arg.AtomID != "", arg.Function != ""} {
+		if given {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("an argument needs exactly one of number, atom_id and function")
+	}
+
+	switch {
+	case arg.Number != nil:
+		if math.IsNaN(*arg.Number) || math.IsInf(*arg.Number, 0) {
+			return nil, fmt.Errorf("numbers must be finite")
+		}
+		return atomspace.NewNumberNode(*arg.Number, tenantID), nil
+	case arg.AtomID != "":
+		return ce.GetAtom(arg.AtomID, tenantID)
+	}
+
+	if !inference.IsGroundedFunction(arg.Function) {
+		return nil, fmt.Errorf("unknown grounded function %q", arg.Function)
+	}
+	if depth >= inference.MaxNesting {
+		return nil, fmt.Errorf("functions nest deeper than %d", inference.MaxNesting)
+	}
+	outgoing, err := ce.groundedCall(tenantID, arg.Function, arg.Arguments, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	return atomspace.NewLink(atomspace.GenerateAtomID(atomspace.ExecutionLinkType, arg.Function, outgoing), arg.Function, tenantID, atomspace.ExecutionLinkType, outgoing), nil
+}
+
+// ensureGrounded adds the number, grounded predicate and function call
+// atoms an evaluation refers to that are not stored yet. Atoms given by ID
+// are stored already.
+func (ce *CognitiveEngine) ensureGrounded(atom atomspace.Atom) error {
+	if _, err := ce.GetAtom(atom.GetID(), atom.GetTenantID()); err == nil {
+		return nil
+	}
+	if link, ok := atom.(*atomspace.Link); ok {
+		for _, child := range link.Outgoing {
+			if err := ce.ensureGrounded(child); err != nil {
+				return err
+			}
+		}
+	}
+	return ce.AddAtom(atom)
+}
@@ -0,0 +1,27 @@
+package cognitive
+
+import (
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// QueryAtomsAsOf queries the atoms a tenant held at a past time, including
+// its mounted shared spaces as they are mounted now. It lets incidents be
+// analysed against what the engine believed when it acted. Times before
+// the retained history are refused with a *history.BeforeHistoryError.
+func (ce *CognitiveEngine) QueryAtomsAsOf(tenantID string, at time.Time, filter func(atomspace.Atom) bool) ([]atomspace.Atom, error) {
+	var atoms []atomspace.Atom
+	for _, id := range append([]string{tenantID}, ce.mountedTenantIDs(tenantID)...) {
+		held, err := ce.history.AsOf(id, at, ce.shardManager.QueryAtoms(id, nil))
+		if err != nil {
+			return nil, err
+		}
+		for _, atom := range held {
+			if filter == nil || filter(atom) {
+				atoms = append(atoms, atom)
+			}
+		}
+	}
+	return atoms, nil
+}
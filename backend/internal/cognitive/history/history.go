@@ -0,0 +1,253 @@
+package history
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// version is a superseded state of an atom, held from from until until
+type version struct {
+	from  time.Time
+	until time.Time
+	atom  atomspace.Atom
+}
+
+// Config controls how much history is retained
+type Config struct {
+	Retention   time.Duration // States superseded this long ago are dropped
+	MaxVersions int           // Superseded states kept per atom
+}
+
+// DefaultConfig returns the default history configuration
+func DefaultConfig() Config {
+	return Config{
+		Retention:   24 * time.Hour,
+		MaxVersions: 1000,
+	}
+}
+
+// pruneInterval is how often a tenant's whole history is pruned, rather
+// than only that of the atom being written
+const pruneInterval = time.Minute
+
+// BeforeHistoryError reports a time earlier than the retained history
+type BeforeHistoryError struct {
+	At    time.Time
+	Since time.Time
+}
+
+func (e *BeforeHistoryError) Error() string {
+	return fmt.Sprintf("history before %s is not retained (requested %s)", e.Since.Format(time.RFC3339), e.At.Format(time.RFC3339))
+}
+
+type tenantState struct {
+	since   time.Time            // History is complete from here on
+	current map[string]time.Time // When the content of each live atom was written
+	past    map[string][]version // Superseded states by atom, oldest first
+	pruned  time.Time
+}
+
+// Store retains the superseded states of each tenant's atoms, so that the
+// atoms a tenant held at a past time can be reconstructed. Only changes of
+// content are versioned; attention values change too often to keep.
+type Store struct {
+	config  Config
+	started time.Time
+	tenants map[string]*tenantState
+	mu      sync.Mutex
+}
+
+// NewStore creates a history store. History is complete from its creation,
+// provided every write to the atoms is recorded.
+func NewStore(config Config) *Store {
+	defaults := DefaultConfig()
+	if config.Retention <= 0 {
+		config.Retention = defaults.Retention
+	}
+	if config.MaxVersions <= 0 {
+		config.MaxVersions = defaults.MaxVersions
+	}
+	return &Store{
+		config:  config,
+		started: time.Now(),
+		tenants: make(map[string]*tenantState),
+	}
+}
+
+func (s *Store) tenant(tenantID string) *tenantState {
+	state, exists := s.tenants[tenantID]
+	if !exists {
+		state = &tenantState{
+			since:   s.started,
+			current: make(map[string]time.Time),
+			past:    make(map[string][]version),
+		}
+		s.tenants[tenantID] = state
+	}
+	return state
+}
+
+// Created records that an atom was added
+func (s *Store) Created(tenantID, atomID string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.tenant(tenantID)
+	state.current[atomID] = at
+	s.prune(state, at)
+}
+
+// Changed records that the content of an atom changed from previous,
+// which must be a copy the caller no longer modifies
+func (s *Store) Changed(tenantID, atomID string, previous atomspace.Atom, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.tenant(tenantID)
+	s.supersede(state, atomID, previous, at)
+	state.current[atomID] = at
+	s.prune(state, at)
+}
+
+// Deleted records that an atom, last holding previous, was deleted
+func (s *Store) Deleted(tenantID, atomID string, previous atomspace.Atom, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.tenant(tenantID)
+	s.supersede(state, atomID, previous, at)
+	delete(state.current, atomID)
+	s.prune(state, at)
+}
+
+func (s *Store) supersede(state *tenantState, atomID string, previous atomspace.Atom, at time.Time) {
+	from, exists := state.current[atomID]
+	if !exists {
+		from = state.since
+	}
+	versions := append(state.past[atomID], version{from: from, until: at, atom: previous})
+	if excess := len(versions) - s.config.MaxVersions; excess > 0 {
+		// States of the atom before the oldest kept one are lost, so the
+		// tenant can no longer be reconstructed before it
+		if lost := versions[excess].from; lost.After(state.since) {
+			state.since = lost
+		}
+		versions = append([]version(nil), versions[excess:]...)
+	}
+	state.past[atomID] = versions
+}
+
+// prune drops states superseded before the retention horizon, from all of
+// the tenant's atoms at most once per pruneInterval
+func (s *Store) prune(state *tenantState, now time.Time) {
+	if now.Sub(state.pruned) < pruneInterval {
+		return
+	}
+	state.pruned = now
+
+	horizon := now.Add(-s.config.Retention)
+	if horizon.After(state.since) {
+		state.since = horizon
+	}
+	for atomID, versions := range state.past {
+		kept := 0
+		for kept < len(versions) && versions[kept].until.Before(horizon) {
+			kept++
+		}
+		if kept == len(versions) {
+			delete(state.past, atomID)
+		} else if kept > 0 {
+			state.past[atomID] = append([]version(nil), versions[kept:]...)
+		}
+	}
+}
+
+// AsOf returns the atoms a tenant held at a time, given those it holds now.
+// Atoms unchanged since are returned as they are; earlier states are
+// copies. It fails if the time is before the retained history.
+func (s *Store) AsOf(tenantID string, at time.Time, live []atomspace.Atom) ([]atomspace.Atom, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, exists := s.tenants[tenantID]
+	since := s.started
+	if exists {
+		since = state.since
+	}
+	if at.Before(since) {
+		return nil, &BeforeHistoryError{At: at, Since: since}
+	}
+	if !exists {
+		return live, nil
+	}
+
+	atoms := make([]atomspace.Atom, 0, len(live))
+	for _, atom := range live {
+		written, tracked := state.current[atom.GetID()]
+		if !tracked || !written.After(at) {
+			atoms = append(atoms, atom)
+		}
+	}
+	for _, versions := range state.past {
+		for _, v := range versions {
+			if !v.from.After(at) && v.until.After(at) {
+				atoms = append(atoms, v.atom)
+				break
+			}
+		}
+	}
+	return atoms, nil
+}
+
+// Purge drops a tenant's history and returns how many states it held
+func (s *Store) Purge(tenantID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, exists := s.tenants[tenantID]
+	if !exists {
+		return 0
+	}
+	delete(s.tenants, tenantID)
+	return countVersions(state)
+}
+
+// Count returns how many superseded states are retained for a tenant
+func (s *Store) Count(tenantID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, exists := s.tenants[tenantID]
+	if !exists {
+		return 0
+	}
+	return countVersions(state)
+}
+
+// GetStats returns history statistics
+func (s *Store) GetStats() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions := 0
+	for _, state := range s.tenants {
+		versions += countVersions(state)
+	}
+	return map[string]interface{}{
+		"tenants":      len(s.tenants),
+		"versions":     versions,
+		"retention":    s.config.Retention.String(),
+		"max_versions": s.config.MaxVersions,
+	}
+}
+
+func countVersions(state *tenantState) int {
+	n := 0
+	for _, versions := range state.past {
+		n += len(versions)
+	}
+	return n
+}
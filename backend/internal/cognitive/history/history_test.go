@@ -0,0 +1,89 @@
+package history
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func node(name string, strength float64) atomspace.Atom {
+	n := atomspace.NewNode(name, name, "tenant", atomspace.ConceptNodeType)
+	n.SetTruthValue(atomspace.TruthValue{Strength: strength, Confidence: 0.9})
+	return n
+}
+
+// held returns the strengths of the atoms held at a time, by ID
+func held(t *testing.T, s *Store, at time.Time, live ...atomspace.Atom) map[string]float64 {
+	t.Helper()
+	atoms, err := s.AsOf("tenant", at, live)
+	if err != nil {
+		t.Fatalf("AsOf failed: %v", err)
+	}
+	strengths := make(map[string]float64)
+	for _, atom := range atoms {
+		strengths[atom.GetID()] = atom.GetTruthValue().Strength
+	}
+	return strengths
+}
+
+func TestAsOf(t *testing.T) {
+	s := NewStore(DefaultConfig())
+	t0 := s.started
+
+	s.Created("tenant", "db", t0.Add(1*time.Second))
+	s.Created("tenant", "cache", t0.Add(2*time.Second))
+	s.Changed("tenant", "db", node("db", 0.9), t0.Add(3*time.Second))
+	s.Deleted("tenant", "cache", node("cache", 0.5), t0.Add(4*time.Second))
+	live := node("db", 0.2)
+
+	if got := held(t, s, t0, live); len(got) != 0 {
+		t.Errorf("Expected nothing before the atoms were created, got %v", got)
+	}
+	if got := held(t, s, t0.Add(2*time.Second), live); len(got) != 2 || got["db"] != 0.9 || got["cache"] != 0.5 {
+		t.Errorf("Expected both atoms as first written, got %v", got)
+	}
+	if got := held(t, s, t0.Add(3*time.Second), live); len(got) != 2 || got["db"] != 0.2 {
+		t.Errorf("Expected the changed atom from the time of the change, got %v", got)
+	}
+	if got := held(t, s, t0.Add(5*time.Second), live); len(got) != 1 || got["db"] != 0.2 {
+		t.Errorf("Expected the deleted atom to be gone, got %v", got)
+	}
+
+	var before *BeforeHistoryError
+	if _, err := s.AsOf("tenant", t0.Add(-time.Second), nil); !errors.As(err, &before) {
+		t.Errorf("Expected a time before the store to be refused, got %v", err)
+	}
+
+	if n := s.Purge("tenant"); n != 2 || s.Count("tenant") != 0 {
+		t.Errorf("Expected 2 versions purged, got %d", n)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	s := NewStore(Config{Retention: time.Hour, MaxVersions: 2})
+	t0 := s.started
+
+	s.Created("tenant", "db", t0)
+	for i := 1; i <= 3; i++ {
+		s.Changed("tenant", "db", node("db", float64(i)/10), t0.Add(time.Duration(i)*time.Minute))
+	}
+	if s.Count("tenant") != 2 {
+		t.Errorf("Expected versions capped at 2, got %d", s.Count("tenant"))
+	}
+	if _, err := s.AsOf("tenant", t0.Add(30*time.Second), nil); err == nil {
+		t.Error("Expected the time of a dropped version to be refused")
+	}
+	if got := held(t, s, t0.Add(90*time.Second)); got["db"] != 0.2 {
+		t.Errorf("Expected the oldest kept version, got %v", got)
+	}
+
+	s.Created("tenant", "cache", t0.Add(2*time.Hour))
+	if s.Count("tenant") != 0 {
+		t.Errorf("Expected versions beyond retention pruned, got %d", s.Count("tenant"))
+	}
+	if _, err := s.AsOf("tenant", t0.Add(30*time.Minute), nil); err == nil {
+		t.Error("Expected a time beyond retention to be refused")
+	}
+}
@@ -0,0 +1,69 @@
+package cognitive
+
+import (
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/hygiene"
+)
+
+// AnalyzeHygiene scores the quality of a tenant's own atoms and suggests
+// the prune and merge operations that would clean them up
+func (ce *CognitiveEngine) AnalyzeHygiene(tenantID string, opts hygiene.Options) (*hygiene.Report, error) {
+	ce.mu.RLock()
+	_, exists := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+	return hygiene.Analyze(tenantID, ce.shardManager.QueryAtoms(tenantID, nil), opts), nil
+}
+
+// HygieneResult reports the operations of a hygiene report applied
+type HygieneResult struct {
+	Applied []string          `json:"applied"`
+	Failed  map[string]string `json:"failed,omitempty"` // Operation ID -> error
+}
+
+// ApplyHygiene analyzes a tenant's atoms again and applies the suggested
+// operations with the given IDs, or all of them. An ID no longer
+// suggested fails, so that only operations still warranted are applied.
+func (ce *CognitiveEngine) ApplyHygiene(tenantID string, ids []string, all bool, opts hygiene.Options) (*HygieneResult, error) {
+	report, err := ce.AnalyzeHygiene(tenantID, opts)
+	if err != nil {
+		return nil, err
+	}
+	ops := report.Operations
+	if !all {
+		ops = make([]hygiene.Operation, 0, len(ids))
+		for _, id := range ids {
+			op, ok := report.Operation(id)
+			if !ok {
+				op = hygiene.Operation{ID: id}
+			}
+			ops = append(ops, op)
+		}
+	}
+
+	result := &HygieneResult{Applied: make([]string, 0, len(ops)), Failed: make(map[string]string)}
+	for _, op := range ops {
+		var err error
+		switch op.Kind {
+		case hygiene.OperationPrune:
+			for _, atomID := range op.AtomIDs {
+				if err = ce.DeleteAtom(atomID, tenantID); err != nil {
+					break
+				}
+			}
+		case hygiene.OperationMerge:
+			_, err = ce.MergeAtoms(tenantID, op.Into, op.AtomIDs)
+		default:
+			err = fmt.Errorf("operation %s is not suggested", op.ID)
+		}
+		if err != nil {
+			result.Failed[op.ID] = err.Error()
+			continue
+		}
+		result.Applied = append(result.Applied, op.ID)
+	}
+	return result, nil
+}
@@ -0,0 +1,202 @@
+package hygiene
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// Findings about a tenant's atoms
+const (
+	FindingOrphan          = "orphan"           // A node no link refers to
+	FindingLowConfidence   = "low_confidence"   // An atom too uncertain to be worth keeping, that no link refers to
+	FindingDuplicateName   = "duplicate_name"   // Concepts whose names differ only in case and punctuation
+	FindingUnusedPredicate = "unused_predicate" // A predicate no evaluation refers to
+)
+
+// Kinds of operations suggested
+const (
+	OperationPrune = "prune" // Delete the atoms
+	OperationMerge = "merge" // Rewire the links of the atoms to Into, then delete them
+)
+
+// Options tune the analysis
+type Options struct {
+	MinConfidence float64 `json:"min_confidence"` // Atoms of lower confidence are clutter
+	MaxOperations int     `json:"max_operations"` // Most operations suggested per finding
+}
+
+// DefaultOptions treat atoms below 0.1 confidence as clutter and suggest
+// at most 100 operations per finding
+func DefaultOptions() Options {
+	return Options{MinConfidence: 0.1, MaxOperations: 100}
+}
+
+// Operation is a suggested fix. Its ID names the atom pruned or merged
+// into, so that it can be applied later while it is still suggested.
+type Operation struct {
+	ID      string   `json:"id"`
+	Kind    string   `json:"kind"`
+	Finding string   `json:"finding"`
+	AtomIDs []string `json:"atom_ids"`       // Atoms pruned, or merged into Into
+	Into    string   `json:"into,omitempty"` // Atom merged into
+	Reason  string   `json:"reason"`
+}
+
+// Report scores the quality of a tenant's knowledge and suggests fixes
+type Report struct {
+	TenantID    string         `json:"tenant_id"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Atoms       int            `json:"atoms"`
+	Score       float64        `json:"score"`    // Share of atoms no finding flags, 0 to 100
+	Findings    map[string]int `json:"findings"` // Finding -> atoms flagged
+	Operations  []Operation    `json:"operations"`
+	Truncated   bool           `json:"truncated,omitempty"` // More operations could be suggested
+}
+
+// Operation returns a suggested operation by ID
+func (r *Report) Operation(id string) (Operation, bool) {
+	for _, op := range r.Operations {
+		if op.ID == id {
+			return op, true
+		}
+	}
+	return Operation{}, false
+}
+
+// normalize reduces a name to its lower-case letters and digits
+func normalize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// Analyze scores a tenant's atoms. Protected atoms are never pruned or
+// merged away.
+func Analyze(tenantID string, atoms []atomspace.Atom, opts Options) *Report {
+	if opts.MaxOperations <= 0 {
+		opts.MaxOperations = DefaultOptions().MaxOperations
+	}
+	report := &Report{
+		TenantID:    tenantID,
+		GeneratedAt: time.Now(),
+		Atoms:       len(atoms),
+		Findings:    make(map[string]int),
+		Operations:  make([]Operation, 0),
+	}
+
+	incoming := make(map[string]int)
+	for _, atom := range atoms {
+		if link, ok := atom.(*atomspace.Link); ok {
+			for _, target := range link.GetOutgoing() {
+				incoming[target.GetID()]++
+			}
+		}
+	}
+
+	flagged := make(map[string]bool)
+	suggested := make(map[string]int)
+	suggest := func(op Operation) {
+		if suggested[op.Finding] >= opts.MaxOperations {
+			report.Truncated = true
+			return
+		}
+		suggested[op.Finding]++
+		report.Operations = append(report.Operations, op)
+	}
+	flag := func(finding string, atom atomspace.Atom) {
+		flagged[atom.GetID()] = true
+		report.Findings[finding]++
+	}
+	prune := func(finding string, atom atomspace.Atom, reason string) {
+		flag(finding, atom)
+		if !atomspace.IsProtected(atom) {
+			suggest(Operation{
+				ID:      "prune-" + atom.GetID(),
+				Kind:    OperationPrune,
+				Finding: finding,
+				AtomIDs: []string{atom.GetID()},
+				Reason:  reason,
+			})
+		}
+	}
+
+	sorted := append([]atomspace.Atom(nil), atoms...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].GetID() < sorted[j].GetID() })
+
+	duplicates := make(map[string][]atomspace.Atom)
+	for _, atom := range sorted {
+		if atom.GetType() == atomspace.ConceptNodeType {
+			key := normalize(atom.GetName())
+			duplicates[key] = append(duplicates[key], atom)
+		}
+		if incoming[atom.GetID()] > 0 {
+			continue
+		}
+		if atom.GetType() == atomspace.PredicateNodeType {
+			prune(FindingUnusedPredicate, atom, fmt.Sprintf("predicate %s is not evaluated", atom.GetName()))
+			continue
+		}
+		if confidence := atom.GetTruthValue().Confidence; confidence < opts.MinConfidence {
+			prune(FindingLowConfidence, atom, fmt.Sprintf("%s has confidence %.2f, below %.2f", atom.GetName(), confidence, opts.MinConfidence))
+			continue
+		}
+		switch atom.GetType() {
+		case atomspace.ConceptNodeType, atomspace.NumberNodeType, atomspace.NodeType:
+			prune(FindingOrphan, atom, fmt.Sprintf("no link refers to %s", atom.GetName()))
+		}
+	}
+
+	keys := make([]string, 0, len(duplicates))
+	for key, group := range duplicates {
+		if key != "" && len(group) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		group := duplicates[key]
+		// Keep the concept most referred to, then the most confident
+		sort.SliceStable(group, func(i, j int) bool {
+			if pi, pj := atomspace.IsProtected(group[i]), atomspace.IsProtected(group[j]); pi != pj {
+				return pi
+			}
+			if incoming[group[i].GetID()] != incoming[group[j].GetID()] {
+				return incoming[group[i].GetID()] > incoming[group[j].GetID()]
+			}
+			return group[i].GetTruthValue().Confidence > group[j].GetTruthValue().Confidence
+		})
+		into := group[0]
+		op := Operation{Kind: OperationMerge, Finding: FindingDuplicateName, Into: into.GetID()}
+		var names []string
+		for _, atom := range group[1:] {
+			if flagged[atom.GetID()] {
+				continue // Already suggested for pruning
+			}
+			flag(FindingDuplicateName, atom)
+			if !atomspace.IsProtected(atom) {
+				op.AtomIDs = append(op.AtomIDs, atom.GetID())
+				names = append(names, atom.GetName())
+			}
+		}
+		if len(op.AtomIDs) > 0 {
+			op.ID = "merge-" + into.GetID()
+			op.Reason = fmt.Sprintf("%s duplicate %s", strings.Join(names, ", "), into.GetName())
+			suggest(op)
+		}
+	}
+
+	report.Score = 100
+	if len(atoms) > 0 {
+		report.Score = math.Round(1000*(1-float64(len(flagged))/float64(len(atoms)))) / 10
+	}
+	return report
+}
@@ -0,0 +1,75 @@
+package hygiene
+
+import (
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func node(name string, atomType atomspace.AtomType, confidence float64) *atomspace.Node {
+	n := atomspace.NewNode(name, name, "t", atomType)
+	n.SetTruthValue(atomspace.TruthValue{Strength: 0.9, Confidence: confidence})
+	return n
+}
+
+func link(id string, outgoing ...atomspace.Atom) *atomspace.Link {
+	return atomspace.NewLink(id, "", "t", atomspace.InheritanceLinkType, outgoing)
+}
+
+func TestAnalyze(t *testing.T) {
+	web := node("web", atomspace.ConceptNodeType, 0.9)
+	server := node("server", atomspace.ConceptNodeType, 0.9)
+	webDup := node("Web!", atomspace.ConceptNodeType, 0.9)
+	orphan := node("orphan", atomspace.ConceptNodeType, 0.9)
+	clutter := node("clutter", atomspace.ConceptNodeType, 0.05)
+	predicate := node("is-up", atomspace.PredicateNodeType, 0.9)
+	kept := node("kept", atomspace.ConceptNodeType, 0.9)
+	kept.SetProtected(true)
+	atoms := []atomspace.Atom{
+		web, server, webDup, orphan, clutter, predicate, kept,
+		link("l1", web, server), link("l2", webDup, server),
+	}
+
+	report := Analyze("t", atoms, DefaultOptions())
+	if report.Atoms != len(atoms) {
+		t.Errorf("expected %d atoms, got %d", len(atoms), report.Atoms)
+	}
+	for finding, want := range map[string]int{
+		FindingOrphan:          2, // orphan and the protected kept
+		FindingLowConfidence:   1,
+		FindingUnusedPredicate: 1,
+		FindingDuplicateName:   1,
+	} {
+		if got := report.Findings[finding]; got != want {
+			t.Errorf("expected %d %s findings, got %d", want, finding, got)
+		}
+	}
+	// Links l1 and l2 refer to their atoms, so they are not flagged
+	if report.Score != 44.4 {
+		t.Errorf("expected a score of 44.4, got %v", report.Score)
+	}
+
+	if _, ok := report.Operation("prune-kept"); ok {
+		t.Error("expected a protected atom not to be pruned")
+	}
+	for _, id := range []string{"prune-orphan", "prune-clutter", "prune-is-up"} {
+		if _, ok := report.Operation(id); !ok {
+			t.Errorf("expected operation %s", id)
+		}
+	}
+	op, ok := report.Operation("merge-Web!")
+	if !ok {
+		op, ok = report.Operation("merge-web")
+	}
+	if !ok || op.Kind != OperationMerge || len(op.AtomIDs) != 1 {
+		t.Fatalf("expected the duplicates merged, got %+v", report.Operations)
+	}
+
+	limited := Analyze("t", append(atoms, node("orphan-2", atomspace.ConceptNodeType, 0.9)), Options{MinConfidence: 0.1, MaxOperations: 1})
+	if !limited.Truncated || len(limited.Operations) != 4 {
+		t.Errorf("expected one operation per finding, got %+v", limited.Operations)
+	}
+	if empty := Analyze("t", nil, DefaultOptions()); empty.Score != 100 {
+		t.Errorf("expected an empty space to score 100, got %v", empty.Score)
+	}
+}
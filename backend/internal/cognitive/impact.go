@@ -0,0 +1,28 @@
+package cognitive
+
+import (
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/impact"
+)
+
+// AnalyzeImpact returns the blast radius of a change of an atom, given by
+// ID or concept name: the atoms reachable over the relations of opts, or of
+// the tenant's link types if opts has none, ranked by confidence
+func (ce *CognitiveEngine) AnalyzeImpact(tenantID, source string, opts impact.Options) (*impact.Result, error) {
+	if source == "" {
+		return nil, fmt.Errorf("atom is required")
+	}
+	if opts.Relations == nil {
+		opts.Relations = ce.ImpactRelations(tenantID)
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if atom, err := ce.GetAtom(source, tenantID); err == nil {
+		source = atom.GetName()
+	}
+
+	graph := impact.NewGraph(ce.QueryAtoms(tenantID, nil), opts.Relations)
+	return graph.Analyze(source, opts)
+}
@@ -0,0 +1,271 @@
+package impact
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// InheritanceRelation is the relation name of inheritance links
+const InheritanceRelation = "inheritance"
+
+// Direction tells which way a failure propagates over a relation
+// predicate(A, B)
+type Direction string
+
+const (
+	Dependents   Direction = "dependents"   // B failing impacts A, e.g. runs_on(pod, node)
+	Dependencies Direction = "dependencies" // A failing impacts B
+	Both         Direction = "both"
+)
+
+// Relation controls how far impact propagates over one link type
+type Relation struct {
+	Weight       float64   `json:"weight"` // In [0, 1], multiplied along a path
+	Direction    Direction `json:"direction"`
+	Intransitive bool      `json:"intransitive,omitempty"` // Impact does not follow the relation twice in a row
+}
+
+// DefaultRelations returns the relations traversed by default. Inheritance
+// propagates from a category to its members, so an availability zone or a
+// shared base impacts everything inheriting from it, and observes and
+// declares propagate from an attribute value such as
+// attr:availability_zone=us-east-1a to the resources having it. Attribute
+// values do not chain, so observes and declares are intransitive.
+func DefaultRelations() map[string]Relation {
+	return map[string]Relation{
+		"runs_on":           {Weight: 0.9, Direction: Dependents},
+		"depends_on":        {Weight: 0.8, Direction: Dependents},
+		"routes_to":         {Weight: 0.8, Direction: Dependents},
+		"calls":             {Weight: 0.7, Direction: Dependents},
+		"member_of":         {Weight: 0.6, Direction: Dependents},
+		"connects_to":       {Weight: 0.5, Direction: Both},
+		"observes":          {Weight: 0.9, Direction: Dependents, Intransitive: true},
+		"declares":          {Weight: 0.9, Direction: Dependents, Intransitive: true},
+		InheritanceRelation: {Weight: 0.9, Direction: Dependents},
+	}
+}
+
+// Options controls an impact analysis
+type Options struct {
+	Depth         int                 `json:"depth"`          // Maximum number of hops from the source
+	MinConfidence float64             `json:"min_confidence"` // Atoms impacted with less confidence are dropped
+	Limit         int                 `json:"limit"`          // Maximum number of impacted atoms, 0 for all
+	Relations     map[string]Relation `json:"relations"`
+}
+
+// MaxDepth bounds the depth of an analysis
+const MaxDepth = 10
+
+// DefaultOptions returns the default analysis options
+func DefaultOptions() Options {
+	return Options{
+		Depth:         3,
+		MinConfidence: 0.05,
+		Relations:     DefaultRelations(),
+	}
+}
+
+// Validate checks the options
+func (o *Options) Validate() error {
+	if o.Depth < 1 || o.Depth > MaxDepth {
+		return fmt.Errorf("depth must be between 1 and %d", MaxDepth)
+	}
+	if o.MinConfidence < 0 || o.MinConfidence > 1 {
+		return fmt.Errorf("min_confidence must be between 0 and 1")
+	}
+	for name, r := range o.Relations {
+		if r.Weight < 0 || r.Weight > 1 {
+			return fmt.Errorf("weight of %s must be between 0 and 1", name)
+		}
+		switch r.Direction {
+		case Dependents, Dependencies, Both:
+		default:
+			return fmt.Errorf("invalid direction of %s: %q", name, r.Direction)
+		}
+	}
+	return nil
+}
+
+// edge is a step along which impact propagates
+type edge struct {
+	to           string
+	relation     string
+	linkID       string
+	weight       float64 // Relation weight times link strength
+	intransitive bool
+}
+
+// Graph is the directed impact graph of a tenant's atoms
+type Graph struct {
+	atoms map[string]atomspace.Atom // name -> atom
+	edges map[string][]edge         // name -> edges to impacted atoms
+}
+
+// NewGraph builds the impact graph from atoms using relations: binary
+// EvaluationLinks whose predicate is a relation and InheritanceLinks
+func NewGraph(atoms []atomspace.Atom, relations map[string]Relation) *Graph {
+	g := &Graph{
+		atoms: make(map[string]atomspace.Atom),
+		edges: make(map[string][]edge),
+	}
+	for _, atom := range atoms {
+		if atom.GetType() == atomspace.ConceptNodeType {
+			g.atoms[atom.GetName()] = atom
+		}
+	}
+
+	for _, atom := range atoms {
+		link, ok := atom.(*atomspace.Link)
+		if !ok {
+			continue
+		}
+		outgoing := link.GetOutgoing()
+		var relation string
+		var a, b atomspace.Atom
+		switch {
+		case link.GetType() == atomspace.InheritanceLinkType && len(outgoing) == 2:
+			relation, a, b = InheritanceRelation, outgoing[0], outgoing[1]
+		case link.GetType() == atomspace.EvaluationLinkType && len(outgoing) == 3 && outgoing[0].GetType() == atomspace.PredicateNodeType:
+			relation, a, b = outgoing[0].GetName(), outgoing[1], outgoing[2]
+		default:
+			continue
+		}
+		r, ok := relations[relation]
+		if !ok {
+			continue
+		}
+		weight := r.Weight * link.GetTruthValue().Strength
+		if weight <= 0 {
+			continue
+		}
+		if _, exists := g.atoms[a.GetName()]; !exists {
+			g.atoms[a.GetName()] = a
+		}
+		if _, exists := g.atoms[b.GetName()]; !exists {
+			g.atoms[b.GetName()] = b
+		}
+		if r.Direction == Dependents || r.Direction == Both {
+			g.edges[b.GetName()] = append(g.edges[b.GetName()], edge{to: a.GetName(), relation: relation, linkID: link.GetID(), weight: weight, intransitive: r.Intransitive})
+		}
+		if r.Direction == Dependencies || r.Direction == Both {
+			g.edges[a.GetName()] = append(g.edges[a.GetName()], edge{to: b.GetName(), relation: relation, linkID: link.GetID(), weight: weight, intransitive: r.Intransitive})
+		}
+	}
+	return g
+}
+
+// Step is one hop of the path from the source to an impacted atom
+type Step struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Relation string `json:"relation"`
+	LinkID   string `json:"link_id"`
+}
+
+// Impacted is an atom affected by a change of the source
+type Impacted struct {
+	AtomID     string             `json:"atom_id"`
+	Name       string             `json:"name"`
+	Type       atomspace.AtomType `json:"type"`
+	Confidence float64            `json:"confidence"` // Product of the weights along the most confident path
+	Depth      int                `json:"depth"`
+	Path       []Step             `json:"path"`
+}
+
+// Result is the blast radius of a change of the source
+type Result struct {
+	Source   string     `json:"source"`
+	Impacted []Impacted `json:"impacted"`
+}
+
+type candidate struct {
+	name       string
+	confidence float64
+	path       []Step
+}
+
+type candidateQueue []candidate
+
+func (q candidateQueue) Len() int { return len(q) }
+func (q candidateQueue) Less(i, j int) bool {
+	if q[i].confidence != q[j].confidence {
+		return q[i].confidence > q[j].confidence
+	}
+	return len(q[i].path) < len(q[j].path)
+}
+func (q candidateQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *candidateQueue) Push(x interface{}) { *q = append(*q, x.(candidate)) }
+func (q *candidateQueue) Pop() interface{} {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// Analyze returns the atoms impacted by a change of source ranked by
+// confidence. Each atom is reported once, with its most confident path of
+// at most Depth hops. Intransitive relations are not followed twice in a
+// row, e.g. a resource reached from an attribute value does not pass impact
+// on to the resources sharing another of its attributes.
+func (g *Graph) Analyze(source string, opts Options) (*Result, error) {
+	if _, exists := g.atoms[source]; !exists {
+		return nil, fmt.Errorf("atom %s not found", source)
+	}
+
+	// Confidences only decrease along a path, so the first time an atom is
+	// popped it is reached with its highest confidence. It is expanded again
+	// when later reached in fewer hops, as that path may go deeper.
+	hops := map[string]int{}
+	impacted := make([]Impacted, 0)
+	queue := &candidateQueue{{name: source, confidence: 1}}
+	for queue.Len() > 0 {
+		c := heap.Pop(queue).(candidate)
+		best, reached := hops[c.name]
+		if reached && len(c.path) >= best {
+			continue
+		}
+		hops[c.name] = len(c.path)
+		if !reached && c.name != source {
+			atom := g.atoms[c.name]
+			impacted = append(impacted, Impacted{
+				AtomID:     atom.GetID(),
+				Name:       c.name,
+				Type:       atom.GetType(),
+				Confidence: c.confidence,
+				Depth:      len(c.path),
+				Path:       c.path,
+			})
+		}
+		if len(c.path) >= opts.Depth {
+			continue
+		}
+		for _, e := range g.edges[c.name] {
+			if e.intransitive && len(c.path) > 0 && c.path[len(c.path)-1].Relation == e.relation {
+				continue
+			}
+			confidence := c.confidence * e.weight
+			if best, reached := hops[e.to]; (reached && len(c.path)+1 >= best) || confidence < opts.MinConfidence {
+				continue
+			}
+			path := append(append([]Step(nil), c.path...), Step{From: c.name, To: e.to, Relation: e.relation, LinkID: e.linkID})
+			heap.Push(queue, candidate{name: e.to, confidence: confidence, path: path})
+		}
+	}
+
+	sort.SliceStable(impacted, func(i, j int) bool {
+		if impacted[i].Confidence != impacted[j].Confidence {
+			return impacted[i].Confidence > impacted[j].Confidence
+		}
+		if impacted[i].Depth != impacted[j].Depth {
+			return impacted[i].Depth < impacted[j].Depth
+		}
+		return impacted[i].Name < impacted[j].Name
+	})
+	if opts.Limit > 0 && len(impacted) > opts.Limit {
+		impacted = impacted[:opts.Limit]
+	}
+	return &Result{Source: source, Impacted: impacted}, nil
+}
@@ -0,0 +1,175 @@
+package impact
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+type testSpace struct {
+	atoms []atomspace.Atom
+}
+
+func (s *testSpace) concept(name string) atomspace.Atom {
+	atom := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, "t", atomspace.ConceptNodeType)
+	s.atoms = append(s.atoms, atom)
+	return atom
+}
+
+func (s *testSpace) relate(predicate string, a, b atomspace.Atom, strength float64) {
+	pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, predicate, nil), predicate, "t", atomspace.PredicateNodeType)
+	outgoing := []atomspace.Atom{pred, a, b}
+	link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, predicate, outgoing), predicate, "t", atomspace.EvaluationLinkType, outgoing)
+	link.SetTruthValue(atomspace.TruthValue{Strength: strength, Confidence: 1})
+	s.atoms = append(s.atoms, link)
+}
+
+func (s *testSpace) inherit(child, parent atomspace.Atom) {
+	outgoing := []atomspace.Atom{child, parent}
+	s.atoms = append(s.atoms, atomspace.NewLink(atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", outgoing), "inheritance", "t", atomspace.InheritanceLinkType, outgoing))
+}
+
+// testTopology is a zone with one node running a cart pod called by the
+// frontend service, plus a retired dependency
+func testTopology() *testSpace {
+	s := &testSpace{}
+	zone := s.concept("zone/a")
+	node := s.concept("node/n1")
+	pod := s.concept("pod/default/cart-1")
+	cart := s.concept("service/cart")
+	frontend := s.concept("service/frontend")
+	legacy := s.concept("service/legacy")
+	s.concept("node/n2")
+
+	s.inherit(node, zone)
+	s.relate("runs_on", pod, node, 1)
+	s.relate("member_of", pod, cart, 1)
+	s.relate("calls", frontend, cart, 0.5)
+	s.relate("calls", legacy, cart, 0)
+	return s
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestAnalyze(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Relations["member_of"] = Relation{Weight: 0.6, Direction: Dependencies}
+	g := NewGraph(testTopology().atoms, opts.Relations)
+
+	result, err := g.Analyze("zone/a", opts)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	want := []struct {
+		name       string
+		confidence float64
+		depth      int
+	}{
+		{"node/n1", 0.9, 1},
+		{"pod/default/cart-1", 0.81, 2},
+		{"service/cart", 0.486, 3},
+	}
+	if len(result.Impacted) != len(want) {
+		t.Fatalf("Expected %d impacted atoms, got %+v", len(want), result.Impacted)
+	}
+	for i, w := range want {
+		got := result.Impacted[i]
+		if got.Name != w.name || !near(got.Confidence, w.confidence) || got.Depth != w.depth || len(got.Path) != w.depth {
+			t.Errorf("Impacted %d: expected %s %.3f at depth %d, got %+v", i, w.name, w.confidence, w.depth, got)
+		}
+	}
+
+	// The frontend is one hop further; retired links are not traversed
+	opts.Depth = 4
+	result, _ = g.Analyze("zone/a", opts)
+	if len(result.Impacted) != 4 || result.Impacted[3].Name != "service/frontend" || !near(result.Impacted[3].Confidence, 0.486*0.7*0.5) {
+		t.Errorf("Expected the frontend at depth 4, got %+v", result.Impacted)
+	}
+
+	opts.MinConfidence = 0.5
+	if result, _ = g.Analyze("zone/a", opts); len(result.Impacted) != 2 {
+		t.Errorf("Expected 2 atoms above the minimum confidence, got %+v", result.Impacted)
+	}
+
+	opts.MinConfidence = 0
+	opts.Limit = 1
+	if result, _ = g.Analyze("zone/a", opts); len(result.Impacted) != 1 || result.Impacted[0].Name != "node/n1" {
+		t.Errorf("Expected the limit to keep the most confident atom, got %+v", result.Impacted)
+	}
+
+	if _, err := g.Analyze("zone/missing", opts); err == nil {
+		t.Error("Expected an unknown source to fail")
+	}
+}
+
+func TestAnalyzeRevisitsShorterPaths(t *testing.T) {
+	// a reaches c directly with low confidence and through b with high
+	// confidence; only the direct path leaves room to reach d
+	s := &testSpace{}
+	a, b, c, d := s.concept("a"), s.concept("b"), s.concept("c"), s.concept("d")
+	s.relate("depends_on", b, a, 1)
+	s.relate("depends_on", c, b, 1)
+	s.relate("depends_on", c, a, 0.5)
+	s.relate("depends_on", d, c, 1)
+
+	opts := DefaultOptions()
+	opts.Depth = 2
+	result, err := NewGraph(s.atoms, opts.Relations).Analyze("a", opts)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	names := make(map[string]Impacted)
+	for _, i := range result.Impacted {
+		names[i.Name] = i
+	}
+	if len(names) != 3 || !near(names["c"].Confidence, 0.64) || names["d"].Depth != 2 {
+		t.Errorf("Expected b, c through b and d through the direct path, got %+v", result.Impacted)
+	}
+}
+
+func TestAnalyzeIntransitive(t *testing.T) {
+	// a and b declare the same zone, and b declares a second one shared
+	// with c; impact of the first zone stops at the resources declaring it
+	s := &testSpace{}
+	zone1, zone2 := s.concept("attr:zone=1"), s.concept("attr:zone=2")
+	a, b, c := s.concept("a"), s.concept("b"), s.concept("c")
+	s.relate("declares", a, zone1, 1)
+	s.relate("declares", b, zone1, 1)
+	s.relate("declares", b, zone2, 1)
+	s.relate("declares", c, zone2, 1)
+
+	opts := DefaultOptions()
+	opts.Relations["declares"] = Relation{Weight: 0.9, Direction: Both, Intransitive: true}
+	result, err := NewGraph(s.atoms, opts.Relations).Analyze("attr:zone=1", opts)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.Impacted) != 2 {
+		t.Errorf("Expected only a and b impacted, got %+v", result.Impacted)
+	}
+
+	opts.Relations["declares"] = Relation{Weight: 0.9, Direction: Both}
+	result, _ = NewGraph(s.atoms, opts.Relations).Analyze("attr:zone=1", opts)
+	if len(result.Impacted) != 4 {
+		t.Errorf("Expected a transitive relation to reach c, got %+v", result.Impacted)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	opts := DefaultOptions()
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Expected the defaults to be valid: %v", err)
+	}
+	opts.Depth = MaxDepth + 1
+	if opts.Validate() == nil {
+		t.Error("Expected an excessive depth to be rejected")
+	}
+	opts = DefaultOptions()
+	opts.Relations["calls"] = Relation{Weight: 0.5, Direction: "sideways"}
+	if opts.Validate() == nil {
+		t.Error("Expected an invalid direction to be rejected")
+	}
+}
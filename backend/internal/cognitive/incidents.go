@@ -0,0 +1,106 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/incidents"
+)
+
+// IngestAlerts records alerts as atoms and runs inference so the
+// correlation rule groups them into incidents. It returns the incidents the
+// alerts belong to. The tenant's channels are notified of the incidents
+// opened and resolved.
+func (ce *CognitiveEngine) IngestAlerts(ctx context.Context, tenantID string, alerts []incidents.Alert) ([]incidents.Incident, error) {
+	ce.mu.RLock()
+	_, initialized := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+	if !initialized {
+		return nil, fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+
+	known := make(map[string]bool)
+	for _, inc := range ce.incidents.List(tenantID, "") {
+		known[inc.ID] = true
+	}
+	alerts, resolved, err := ce.incidents.Ingest(tenantID, alerts)
+	if err != nil {
+		return nil, err
+	}
+
+	space := &tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID}
+	for _, alert := range alerts {
+		if _, err := incidents.WriteAlert(space, tenantID, alert); err != nil {
+			return nil, fmt.Errorf("recording alert %s failed: %w", alert.Fingerprint, err)
+		}
+	}
+	for _, incidentID := range resolved {
+		if inc, err := ce.incidents.Get(tenantID, incidentID); err == nil {
+			incidents.WriteIncidentStatus(space, tenantID, inc)
+			ce.notifyIncident(inc)
+		}
+	}
+
+	if ce.incidents.HasPending(tenantID) {
+		if _, err := ce.RunInference(ctx, tenantID, 2); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	result := make([]incidents.Incident, 0)
+	for _, alert := range alerts {
+		incidentID, ok := ce.incidents.IncidentOf(tenantID, alert.Fingerprint)
+		if !ok || seen[incidentID] {
+			continue
+		}
+		seen[incidentID] = true
+		if inc, err := ce.incidents.Get(tenantID, incidentID); err == nil {
+			result = append(result, inc)
+			if !known[incidentID] {
+				ce.notifyIncident(inc)
+			}
+		}
+	}
+	return result, nil
+}
+
+// ListIncidents returns a tenant's incidents, optionally filtered by status
+func (ce *CognitiveEngine) ListIncidents(tenantID string, status incidents.Status) []incidents.Incident {
+	return ce.incidents.List(tenantID, status)
+}
+
+// GetIncident returns an incident with its timeline
+func (ce *CognitiveEngine) GetIncident(tenantID, incidentID string) (incidents.Incident, error) {
+	return ce.incidents.Get(tenantID, incidentID)
+}
+
+// GetIncidentAlerts returns the alerts grouped into an incident
+func (ce *CognitiveEngine) GetIncidentAlerts(tenantID, incidentID string) ([]incidents.Alert, error) {
+	return ce.incidents.GetAlerts(tenantID, incidentID)
+}
+
+// AcknowledgeIncident marks an incident as being worked on
+func (ce *CognitiveEngine) AcknowledgeIncident(tenantID, incidentID, by string) (incidents.Incident, error) {
+	if by == "" {
+		return incidents.Incident{}, fmt.Errorf("acknowledging user is required")
+	}
+	inc, err := ce.incidents.Acknowledge(tenantID, incidentID, by)
+	if err != nil {
+		return incidents.Incident{}, err
+	}
+	return inc, incidents.WriteIncidentStatus(&tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID}, tenantID, inc)
+}
+
+// ResolveIncident closes an incident
+func (ce *CognitiveEngine) ResolveIncident(tenantID, incidentID, by, note string) (incidents.Incident, error) {
+	if by == "" {
+		return incidents.Incident{}, fmt.Errorf("resolving user is required")
+	}
+	inc, err := ce.incidents.Resolve(tenantID, incidentID, by, note)
+	if err != nil {
+		return incidents.Incident{}, err
+	}
+	ce.notifyIncident(inc)
+	return inc, incidents.WriteIncidentStatus(&tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID}, tenantID, inc)
+}
@@ -0,0 +1,298 @@
+package incidents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+)
+
+// Names of the atoms written for alerts and incidents
+const (
+	AlertPrefix     = "alert:"
+	IncidentPrefix  = "incident:"
+	AlertConcept    = "Alert"
+	IncidentConcept = "Incident"
+
+	CorrelationRuleName = "alert-correlation"
+
+	alertOnPredicate        = "alert_on"
+	severityPredicate       = "has_severity"
+	partOfPredicate         = "part_of_incident"
+	acknowledgedByPredicate = "acknowledged_by"
+)
+
+var fullTV = atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+
+func relationLink(tenantID, predicate string, pred atomspace.Atom, args ...atomspace.Atom) *atomspace.Link {
+	outgoing := append([]atomspace.Atom{pred}, args...)
+	return atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, predicate, outgoing), predicate, tenantID, atomspace.EvaluationLinkType, outgoing)
+}
+
+func inheritanceLink(tenantID string, child, parent atomspace.Atom) *atomspace.Link {
+	outgoing := []atomspace.Atom{child, parent}
+	return atomspace.NewLink(atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", outgoing), "inheritance", tenantID, atomspace.InheritanceLinkType, outgoing)
+}
+
+// upsert adds an atom or refreshes the truth value of the existing one
+func upsert(space atomspace.AtomSpaceInterface, atom atomspace.Atom, tv atomspace.TruthValue) (atomspace.Atom, error) {
+	atom.SetTruthValue(tv)
+	existing, err := space.GetAtom(atom.GetID(), atom.GetTenantID())
+	if err != nil {
+		return atom, space.AddAtom(atom)
+	}
+	return existing, space.UpdateAtom(atom.GetID(), atom.GetTenantID(), func(a atomspace.Atom) error {
+		a.SetTruthValue(tv)
+		return nil
+	})
+}
+
+// WriteAlert records an alert as an Alert concept related to its subject:
+//
+//	alert_on(alert:FP, SUBJECT), has_severity(alert:FP, severity:S)
+//
+// The alert's strength is 1 while firing and 0 once resolved.
+func WriteAlert(space atomspace.AtomSpaceInterface, tenantID string, alert Alert) (atomspace.Atom, error) {
+	strength := 1.0
+	if alert.Status == AlertResolved {
+		strength = 0
+	}
+	node, err := upsert(space, atomspace.NewConceptNode(AlertName(alert.Fingerprint), tenantID), atomspace.TruthValue{Strength: strength, Confidence: 0.9})
+	if err != nil {
+		return nil, err
+	}
+	category, err := atomspace.Ensure(space, atomspace.NewConceptNode(AlertConcept, tenantID), fullTV)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := atomspace.Ensure(space, inheritanceLink(tenantID, node, category), fullTV); err != nil {
+		return nil, err
+	}
+
+	subject, err := atomspace.Ensure(space, atomspace.NewConceptNode(alert.Subject, tenantID), fullTV)
+	if err != nil {
+		return nil, err
+	}
+	severity, err := atomspace.Ensure(space, atomspace.NewConceptNode("severity:"+alert.Severity, tenantID), fullTV)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range []struct {
+		predicate string
+		arg       atomspace.Atom
+	}{{alertOnPredicate, subject}, {severityPredicate, severity}} {
+		pred, err := atomspace.Ensure(space, atomspace.NewPredicateNode(rel.predicate, tenantID), fullTV)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := atomspace.Ensure(space, relationLink(tenantID, rel.predicate, pred, node, rel.arg), fullTV); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// WriteIncidentStatus reflects an incident's lifecycle on its atom: the
+// strength is 1 while unresolved and 0 once resolved, and acknowledgement
+// adds acknowledged_by(incident:ID, user:NAME)
+func WriteIncidentStatus(space atomspace.AtomSpaceInterface, tenantID string, inc Incident) error {
+	strength := 1.0
+	if inc.Status == StatusResolved {
+		strength = 0
+	}
+	node, err := upsert(space, atomspace.NewConceptNode(IncidentName(inc.ID), tenantID), atomspace.TruthValue{Strength: strength, Confidence: 0.9})
+	if err != nil {
+		return err
+	}
+	if inc.AcknowledgedBy == "" {
+		return nil
+	}
+
+	user, err := atomspace.Ensure(space, atomspace.NewConceptNode("user:"+inc.AcknowledgedBy, tenantID), fullTV)
+	if err != nil {
+		return err
+	}
+	pred, err := atomspace.Ensure(space, atomspace.NewPredicateNode(acknowledgedByPredicate, tenantID), fullTV)
+	if err != nil {
+		return err
+	}
+	_, err = atomspace.Ensure(space, relationLink(tenantID, acknowledgedByPredicate, pred, node, user), fullTV)
+	return err
+}
+
+// CorrelationRule is an inference rule grouping a tenant's pending alerts
+// into Incident atoms by topology and time proximity. It derives
+// incident:ID concepts inheriting Incident and part_of_incident(alert,
+// incident) links.
+type CorrelationRule struct {
+	manager  *Manager
+	tenantID string
+}
+
+// NewCorrelationRule creates the correlation rule of a tenant
+func NewCorrelationRule(manager *Manager, tenantID string) *CorrelationRule {
+	return &CorrelationRule{manager: manager, tenantID: tenantID}
+}
+
+func (r *CorrelationRule) GetName() string {
+	return CorrelationRuleName
+}
+
+func (r *CorrelationRule) GetPriority() int {
+	return 8
+}
+
+// Signature fires on pending alerts, deriving incident nodes
+func (r *CorrelationRule) Signature() inference.Signature {
+	return inference.Signature{
+		Conclusions: []inference.Pattern{
+			{Type: atomspace.ConceptNodeType},
+			{Type: atomspace.InheritanceLinkType},
+			{Type: atomspace.PredicateNodeType, Name: partOfPredicate},
+			{Type: atomspace.EvaluationLinkType, Name: partOfPredicate},
+		},
+		External:   true,
+		Generative: true,
+	}
+}
+
+func (r *CorrelationRule) CanApply(atoms []atomspace.Atom) bool {
+	return r.manager.HasPending(r.tenantID)
+}
+
+func (r *CorrelationRule) Apply(ctx context.Context, atoms []atomspace.Atom) ([]atomspace.Atom, error) {
+	byID := make(map[string]atomspace.Atom, len(atoms))
+	for _, atom := range atoms {
+		byID[atom.GetID()] = atom
+	}
+	lookup := func(node *atomspace.Node) atomspace.Atom {
+		if existing, ok := byID[node.GetID()]; ok {
+			return existing
+		}
+		node.SetTruthValue(fullTV)
+		return node
+	}
+
+	topology := newTopology(atoms, r.manager.config.TopologyPredicates)
+	assignments := r.manager.Correlate(r.tenantID, func(a, b string) bool {
+		return topology.within(a, b, r.manager.config.MaxHops)
+	})
+	if len(assignments) == 0 {
+		return nil, nil
+	}
+
+	category := lookup(atomspace.NewConceptNode(IncidentConcept, r.tenantID))
+	pred := lookup(atomspace.NewPredicateNode(partOfPredicate, r.tenantID))
+	derived := []atomspace.Atom{category, pred}
+	tv := atomspace.TruthValue{Strength: 1.0, Confidence: 0.9}
+
+	for _, a := range assignments {
+		incident := atomspace.NewConceptNode(IncidentName(a.IncidentID), r.tenantID)
+		if a.NewIncident {
+			incident.SetTruthValue(tv)
+			link := inheritanceLink(r.tenantID, incident, category)
+			link.SetTruthValue(fullTV)
+			derived = append(derived, incident, link)
+		}
+		alert := lookup(atomspace.NewConceptNode(AlertName(a.Alert.Fingerprint), r.tenantID))
+		link := relationLink(r.tenantID, partOfPredicate, pred, alert, incident)
+		link.SetTruthValue(tv)
+		derived = append(derived, link)
+	}
+	return derived, nil
+}
+
+// ObserveDerived adds derived atoms that mention an incident or one of its
+// alerts to the incident's timeline
+func (m *Manager) ObserveDerived(tenantID, rule string, atom atomspace.Atom) {
+	link, ok := atom.(*atomspace.Link)
+	if !ok {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, exists := m.tenants[tenantID]
+	if !exists {
+		return
+	}
+	names := make([]string, 0, len(link.GetOutgoing()))
+	seen := make(map[string]bool)
+	for _, out := range link.GetOutgoing() {
+		names = append(names, out.GetName())
+		incidentID, ok := incidentIDFromName(out.GetName())
+		if !ok && strings.HasPrefix(out.GetName(), AlertPrefix) {
+			if alert := t.alerts[strings.TrimPrefix(out.GetName(), AlertPrefix)]; alert != nil {
+				incidentID, ok = alert.Incident, alert.Incident != ""
+			}
+		}
+		if ok {
+			seen[incidentID] = true
+		}
+	}
+
+	message := fmt.Sprintf("%s(%s)", link.GetName(), strings.Join(names, ", "))
+	for incidentID := range seen {
+		m.recordDerivationLocked(t, incidentID, rule, atom.GetID(), message)
+	}
+}
+
+// topology is the adjacency of concepts through topology relations
+type topology map[string]map[string]bool
+
+func newTopology(atoms []atomspace.Atom, predicates []string) topology {
+	allowed := make(map[string]bool, len(predicates))
+	for _, p := range predicates {
+		allowed[p] = true
+	}
+
+	t := make(topology)
+	for _, atom := range atoms {
+		link, ok := atom.(*atomspace.Link)
+		if !ok || link.GetType() != atomspace.EvaluationLinkType {
+			continue
+		}
+		outgoing := link.GetOutgoing()
+		if len(outgoing) < 3 || outgoing[0].GetType() != atomspace.PredicateNodeType || !allowed[outgoing[0].GetName()] {
+			continue
+		}
+		args := outgoing[1:]
+		for _, a := range args {
+			for _, b := range args {
+				if a.GetName() == b.GetName() {
+					continue
+				}
+				if t[a.GetName()] == nil {
+					t[a.GetName()] = make(map[string]bool)
+				}
+				t[a.GetName()][b.GetName()] = true
+			}
+		}
+	}
+	return t
+}
+
+// within reports whether b is reachable from a in at most hops steps
+func (t topology) within(a, b string, hops int) bool {
+	frontier := []string{a}
+	visited := map[string]bool{a: true}
+	for step := 0; step < hops && len(frontier) > 0; step++ {
+		var next []string
+		for _, name := range frontier {
+			for neighbor := range t[name] {
+				if neighbor == b {
+					return true
+				}
+				if !visited[neighbor] {
+					visited[neighbor] = true
+					next = append(next, neighbor)
+				}
+			}
+		}
+		frontier = next
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package incidents
+
+import (
+	"context"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/changes"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+)
+
+// Names of the change correlation rule and the links it derives
+const (
+	ChangeRuleName     = "change-correlation"
+	causedByPredicate  = "possibly_caused_by"
+	minCausedStrength  = 0.05
+	causedByConfidence = 0.7
+)
+
+// ChangeRule is an inference rule relating the firing alerts of a tenant's
+// incidents to the changes that preceded them. A change recorded with
+// affects(change:ID, SERVICE) and changed_at(change:ID, TIME) is a possible
+// cause of an alert that started within the change window after it, on a
+// subject within the topology distance of the service:
+//
+//	possibly_caused_by(alert:FP, change:ID)
+//
+// Its strength falls linearly from 1 for a change right before the alert
+// to 0.05 at the end of the window, and its confidence is 0.7 of the
+// change's. The links land on the timelines of the alerts' incidents.
+type ChangeRule struct {
+	manager  *Manager
+	tenantID string
+}
+
+// NewChangeRule creates the change correlation rule of a tenant
+func NewChangeRule(manager *Manager, tenantID string) *ChangeRule {
+	return &ChangeRule{manager: manager, tenantID: tenantID}
+}
+
+func (r *ChangeRule) GetName() string {
+	return ChangeRuleName
+}
+
+func (r *ChangeRule) GetPriority() int {
+	return 7
+}
+
+// Signature relates firing alerts to the change events in the atoms
+func (r *ChangeRule) Signature() inference.Signature {
+	return inference.Signature{
+		Premises: []inference.Pattern{
+			{Type: atomspace.EvaluationLinkType, Name: changes.AffectsPredicate},
+			{Type: atomspace.EvaluationLinkType, Name: changes.ChangedAtPredicate},
+		},
+		Conclusions: []inference.Pattern{
+			{Type: atomspace.PredicateNodeType, Name: causedByPredicate},
+			{Type: atomspace.EvaluationLinkType, Name: causedByPredicate},
+		},
+		External: true,
+	}
+}
+
+func (r *ChangeRule) CanApply(atoms []atomspace.Atom) bool {
+	return len(r.manager.Firing(r.tenantID)) > 0
+}
+
+// change is a ChangeEvent read from the atoms
+type change struct {
+	node     atomspace.Atom
+	at       time.Time
+	services []string
+}
+
+func (r *ChangeRule) Apply(ctx context.Context, atoms []atomspace.Atom) ([]atomspace.Atom, error) {
+	byID := make(map[string]atomspace.Atom, len(atoms))
+	found := make(map[string]*change) // change node ID -> change
+	get := func(node atomspace.Atom) *change {
+		c, ok := found[node.GetID()]
+		if !ok {
+			c = &change{node: node}
+			found[node.GetID()] = c
+		}
+		return c
+	}
+	for _, atom := range atoms {
+		byID[atom.GetID()] = atom
+		link, ok := atom.(*atomspace.Link)
+		if !ok || link.GetType() != atomspace.EvaluationLinkType || link.GetTruthValue().Strength <= 0 {
+			continue
+		}
+		outgoing := link.GetOutgoing()
+		if len(outgoing) != 3 {
+			continue
+		}
+		switch link.GetName() {
+		case changes.AffectsPredicate:
+			c := get(outgoing[1])
+			c.services = append(c.services, outgoing[2].GetName())
+		case changes.ChangedAtPredicate:
+			if seconds, ok := atomspace.NumberValue(outgoing[2]); ok {
+				get(outgoing[1]).at = time.Unix(int64(seconds), 0)
+			}
+		}
+	}
+	lookup := func(node *atomspace.Node) atomspace.Atom {
+		if existing, ok := byID[node.GetID()]; ok {
+			return existing
+		}
+		node.SetTruthValue(fullTV)
+		return node
+	}
+
+	window := r.manager.config.ChangeWindow
+	topology := newTopology(atoms, r.manager.config.TopologyPredicates)
+	pred := lookup(atomspace.NewPredicateNode(causedByPredicate, r.tenantID))
+	derived := []atomspace.Atom{pred}
+	for _, alert := range r.manager.Firing(r.tenantID) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for _, c := range found {
+			node := byID[c.node.GetID()]
+			gap := alert.StartsAt.Sub(c.at)
+			if node == nil || node.GetTruthValue().Strength <= 0 || c.at.IsZero() || gap < 0 || gap > window {
+				continue
+			}
+			related := false
+			for _, service := range c.services {
+				if service == alert.Subject || topology.within(alert.Subject, service, r.manager.config.MaxHops) {
+					related = true
+					break
+				}
+			}
+			if !related {
+				continue
+			}
+
+			strength := 1 - float64(gap)/float64(window)
+			if strength < minCausedStrength {
+				strength = minCausedStrength
+			}
+			alertNode := lookup(atomspace.NewConceptNode(AlertName(alert.Fingerprint), r.tenantID))
+			link := relationLink(r.tenantID, causedByPredicate, pred, alertNode, node)
+			link.SetTruthValue(atomspace.TruthValue{Strength: strength, Confidence: causedByConfidence * node.GetTruthValue().Confidence})
+			derived = append(derived, link)
+		}
+	}
+	if len(derived) == 1 {
+		return nil, nil
+	}
+	return derived, nil
+}
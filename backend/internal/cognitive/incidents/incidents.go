@@ -0,0 +1,677 @@
+package incidents
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AlertStatus is the state reported by an alert source
+type AlertStatus string
+
+const (
+	AlertFiring   AlertStatus = "firing"
+	AlertResolved AlertStatus = "resolved"
+)
+
+// Status is the lifecycle state of an incident
+type Status string
+
+const (
+	StatusOpen         Status = "open"
+	StatusAcknowledged Status = "acknowledged"
+	StatusResolved     Status = "resolved"
+)
+
+// severityRank orders severities, unknown ones rank as warnings
+var severityRank = map[string]int{
+	"info":     0,
+	"warning":  1,
+	"error":    2,
+	"high":     2,
+	"critical": 3,
+	"page":     3,
+}
+
+func rank(severity string) int {
+	if r, ok := severityRank[severity]; ok {
+		return r
+	}
+	return 1
+}
+
+// SeverityAtLeast reports whether severity is at least as severe as min
+func SeverityAtLeast(severity, min string) bool {
+	return rank(severity) >= rank(min)
+}
+
+// Alert is one alert received from Alertmanager or a webhook
+type Alert struct {
+	Fingerprint string            `json:"fingerprint"`
+	Name        string            `json:"name"`
+	Subject     string            `json:"subject"` // Concept name of the affected resource, e.g. "pod/default/web-1"
+	Severity    string            `json:"severity"`
+	Status      AlertStatus       `json:"status"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	StartsAt    time.Time         `json:"starts_at"`
+	EndsAt      time.Time         `json:"ends_at,omitempty"`
+	Incident    string            `json:"incident,omitempty"`
+}
+
+// Normalize fills in an alert's defaults: its name, subject and severity
+// from the labels, a fingerprint over name and labels, and its start time
+func (a *Alert) Normalize() error {
+	if a.Name == "" {
+		a.Name = a.Labels["alertname"]
+	}
+	if a.Name == "" {
+		return fmt.Errorf("alert name is required")
+	}
+	if a.Subject == "" {
+		a.Subject = subjectFromLabels(a.Labels)
+	}
+	if a.Subject == "" {
+		return fmt.Errorf("alert %s has no subject label", a.Name)
+	}
+	if a.Severity == "" {
+		a.Severity = a.Labels["severity"]
+	}
+	if a.Severity == "" {
+		a.Severity = "warning"
+	}
+	if a.Status == "" {
+		a.Status = AlertFiring
+	}
+	if a.Status != AlertFiring && a.Status != AlertResolved {
+		return fmt.Errorf("unknown alert status: %s", a.Status)
+	}
+	if a.StartsAt.IsZero() {
+		a.StartsAt = time.Now()
+	}
+	if a.Fingerprint == "" {
+		a.Fingerprint = fingerprint(a.Name, a.Subject, a.Labels)
+	}
+	return nil
+}
+
+// subjectFromLabels names the affected resource the way the topology sync
+// stage names its concepts. An erebus_subject label takes precedence.
+func subjectFromLabels(labels map[string]string) string {
+	switch {
+	case labels["erebus_subject"] != "":
+		return labels["erebus_subject"]
+	case labels["pod"] != "" && labels["namespace"] != "":
+		return "pod/" + labels["namespace"] + "/" + labels["pod"]
+	case labels["node"] != "":
+		return "node/" + labels["node"]
+	case labels["service"] != "":
+		return labels["service"]
+	}
+	return labels["instance"]
+}
+
+func fingerprint(name, subject string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := fnv.New64a()
+	h.Write([]byte(name + "\x00" + subject))
+	for _, k := range keys {
+		h.Write([]byte("\x00" + k + "=" + labels[k]))
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// ParseAlertmanager decodes an Alertmanager webhook payload
+func ParseAlertmanager(data []byte) ([]Alert, error) {
+	var payload struct {
+		Alerts []struct {
+			Status      string            `json:"status"`
+			Labels      map[string]string `json:"labels"`
+			Annotations map[string]string `json:"annotations"`
+			StartsAt    time.Time         `json:"startsAt"`
+			EndsAt      time.Time         `json:"endsAt"`
+			Fingerprint string            `json:"fingerprint"`
+		} `json:"alerts"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("invalid alertmanager payload: %w", err)
+	}
+
+	alerts := make([]Alert, 0, len(payload.Alerts))
+	for _, a := range payload.Alerts {
+		alert := Alert{
+			Fingerprint: a.Fingerprint,
+			Status:      AlertStatus(a.Status),
+			Labels:      a.Labels,
+			Annotations: a.Annotations,
+			StartsAt:    a.StartsAt,
+		}
+		// Alertmanager sends a zero-year end time for firing alerts
+		if a.EndsAt.Year() > 1 {
+			alert.EndsAt = a.EndsAt
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
+
+// TimelineEntry is one event in the history of an incident
+type TimelineEntry struct {
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"` // alert, alert_resolved, correlated, change, derived, acknowledged, resolved, runbook_step
+	Message string    `json:"message"`
+	AtomID  string    `json:"atom_id,omitempty"`
+	Rule    string    `json:"rule,omitempty"`
+	Actor   string    `json:"actor,omitempty"`
+}
+
+// Incident groups correlated alerts
+type Incident struct {
+	ID             string          `json:"id"`
+	TenantID       string          `json:"tenant_id"`
+	Title          string          `json:"title"`
+	Status         Status          `json:"status"`
+	Severity       string          `json:"severity"`
+	Alerts         []string        `json:"alerts"` // Fingerprints
+	Subjects       []string        `json:"subjects"`
+	OpenedAt       time.Time       `json:"opened_at"`
+	LastAlertAt    time.Time       `json:"last_alert_at"`
+	AcknowledgedAt time.Time       `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string          `json:"acknowledged_by,omitempty"`
+	ResolvedAt     time.Time       `json:"resolved_at,omitempty"`
+	ResolvedBy     string          `json:"resolved_by,omitempty"`
+	Timeline       []TimelineEntry `json:"timeline"`
+}
+
+func (inc *Incident) copy() Incident {
+	c := *inc
+	c.Alerts = append([]string(nil), inc.Alerts...)
+	c.Subjects = append([]string(nil), inc.Subjects...)
+	c.Timeline = append([]TimelineEntry(nil), inc.Timeline...)
+	return c
+}
+
+func (inc *Incident) addEntry(entry TimelineEntry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	inc.Timeline = append(inc.Timeline, entry)
+	sort.SliceStable(inc.Timeline, func(i, j int) bool { return inc.Timeline[i].Time.Before(inc.Timeline[j].Time) })
+}
+
+// Config controls alert correlation
+type Config struct {
+	Window             time.Duration // Alerts this close to an incident's latest alert may join it
+	MaxHops            int           // Topology distance within which subjects are related
+	TopologyPredicates []string      // Relations that make up the topology
+	ChangeWindow       time.Duration // Changes this long before an alert may have caused it
+}
+
+// DefaultConfig returns the default correlation configuration
+func DefaultConfig() Config {
+	return Config{
+		Window:             10 * time.Minute,
+		MaxHops:            2,
+		TopologyPredicates: []string{"runs_on", "member_of", "depends_on", "connects_to", "routes_to", "calls"},
+		ChangeWindow:       2 * time.Hour,
+	}
+}
+
+type tenantState struct {
+	alerts    map[string]*Alert    // fingerprint -> alert
+	incidents map[string]*Incident // incidentID -> incident
+	pending   map[string]bool      // fingerprints of firing alerts awaiting correlation
+}
+
+// Manager keeps alerts and the lifecycle of incidents per tenant
+type Manager struct {
+	config  Config
+	tenants map[string]*tenantState
+	nextID  int64
+	mu      sync.Mutex
+}
+
+// NewManager creates an incident manager
+func NewManager(config Config) *Manager {
+	defaults := DefaultConfig()
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.MaxHops <= 0 {
+		config.MaxHops = defaults.MaxHops
+	}
+	if config.TopologyPredicates == nil {
+		config.TopologyPredicates = defaults.TopologyPredicates
+	}
+	if config.ChangeWindow <= 0 {
+		config.ChangeWindow = defaults.ChangeWindow
+	}
+	return &Manager{
+		config:  config,
+		tenants: make(map[string]*tenantState),
+	}
+}
+
+func (m *Manager) tenant(tenantID string) *tenantState {
+	t, exists := m.tenants[tenantID]
+	if !exists {
+		t = &tenantState{
+			alerts:    make(map[string]*Alert),
+			incidents: make(map[string]*Incident),
+			pending:   make(map[string]bool),
+		}
+		m.tenants[tenantID] = t
+	}
+	return t
+}
+
+// Ingest records alerts. Firing alerts await correlation; resolved alerts
+// are noted on their incident, and incidents whose alerts have all resolved
+// are resolved. It returns the normalized alerts and the IDs of incidents
+// resolved this way.
+func (m *Manager) Ingest(tenantID string, alerts []Alert) ([]Alert, []string, error) {
+	for i := range alerts {
+		if err := alerts[i].Normalize(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := m.tenant(tenantID)
+	var resolved []string
+	for i := range alerts {
+		alert := alerts[i]
+		existing, known := t.alerts[alert.Fingerprint]
+		if known {
+			alert.Incident = existing.Incident
+			if alert.Status == existing.Status {
+				*existing = alert
+				alerts[i] = alert
+				continue
+			}
+		}
+		t.alerts[alert.Fingerprint] = &alert
+		alerts[i] = alert
+
+		inc := t.incidents[alert.Incident]
+		switch {
+		case alert.Status == AlertFiring && inc == nil:
+			t.pending[alert.Fingerprint] = true
+		case alert.Status == AlertFiring:
+			inc.addEntry(TimelineEntry{Time: alert.StartsAt, Kind: "alert", Message: fmt.Sprintf("%s firing again on %s", alert.Name, alert.Subject)})
+		case inc == nil:
+			delete(t.pending, alert.Fingerprint)
+		default:
+			at := alert.EndsAt
+			if at.IsZero() {
+				at = time.Now()
+			}
+			inc.addEntry(TimelineEntry{Time: at, Kind: "alert_resolved", Message: fmt.Sprintf("%s resolved on %s", alert.Name, alert.Subject)})
+			if inc.Status != StatusResolved && m.allResolvedLocked(t, inc) {
+				m.resolveLocked(inc, "alertmanager", "all alerts resolved")
+				resolved = append(resolved, inc.ID)
+			}
+		}
+	}
+	return alerts, resolved, nil
+}
+
+func (m *Manager) allResolvedLocked(t *tenantState, inc *Incident) bool {
+	for _, fp := range inc.Alerts {
+		if a := t.alerts[fp]; a != nil && a.Status == AlertFiring {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Manager) resolveLocked(inc *Incident, by, note string) {
+	inc.Status = StatusResolved
+	inc.ResolvedAt = time.Now()
+	inc.ResolvedBy = by
+	message := "Resolved by " + by
+	if note != "" {
+		message += ": " + note
+	}
+	inc.addEntry(TimelineEntry{Time: inc.ResolvedAt, Kind: "resolved", Message: message, Actor: by})
+}
+
+// HasPending reports whether a tenant has firing alerts awaiting
+// correlation
+func (m *Manager) HasPending(tenantID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, exists := m.tenants[tenantID]
+	return exists && len(t.pending) > 0
+}
+
+// Firing returns a tenant's firing alerts that belong to an incident
+func (m *Manager) Firing(tenantID string) []Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, exists := m.tenants[tenantID]
+	if !exists {
+		return nil
+	}
+	firing := make([]Alert, 0)
+	for _, a := range t.alerts {
+		if a.Status == AlertFiring && a.Incident != "" {
+			firing = append(firing, *a)
+		}
+	}
+	sort.Slice(firing, func(i, j int) bool { return firing[i].Fingerprint < firing[j].Fingerprint })
+	return firing
+}
+
+// Assignment places an alert in an incident
+type Assignment struct {
+	Alert       Alert
+	IncidentID  string
+	NewIncident bool
+}
+
+// Correlate assigns a tenant's pending alerts to incidents. An alert joins
+// an unresolved incident when one of the incident's subjects is related to
+// its own and it started within the window of the incident's latest alert;
+// otherwise it opens a new incident.
+func (m *Manager) Correlate(tenantID string, related func(a, b string) bool) []Assignment {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, exists := m.tenants[tenantID]
+	if !exists || len(t.pending) == 0 {
+		return nil
+	}
+
+	pending := make([]*Alert, 0, len(t.pending))
+	for fp := range t.pending {
+		if a := t.alerts[fp]; a != nil {
+			pending = append(pending, a)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if !pending[i].StartsAt.Equal(pending[j].StartsAt) {
+			return pending[i].StartsAt.Before(pending[j].StartsAt)
+		}
+		return pending[i].Fingerprint < pending[j].Fingerprint
+	})
+
+	assignments := make([]Assignment, 0, len(pending))
+	for _, alert := range pending {
+		inc := m.matchLocked(t, alert, related)
+		created := inc == nil
+		if created {
+			m.nextID++
+			inc = &Incident{
+				ID:          fmt.Sprintf("inc-%d", m.nextID),
+				TenantID:    tenantID,
+				Title:       fmt.Sprintf("%s on %s", alert.Name, alert.Subject),
+				Status:      StatusOpen,
+				Severity:    alert.Severity,
+				OpenedAt:    alert.StartsAt,
+				LastAlertAt: alert.StartsAt,
+			}
+			t.incidents[inc.ID] = inc
+		}
+
+		inc.Alerts = append(inc.Alerts, alert.Fingerprint)
+		if !contains(inc.Subjects, alert.Subject) {
+			inc.Subjects = append(inc.Subjects, alert.Subject)
+		}
+		if rank(alert.Severity) > rank(inc.Severity) {
+			inc.Severity = alert.Severity
+		}
+		if alert.StartsAt.After(inc.LastAlertAt) {
+			inc.LastAlertAt = alert.StartsAt
+		}
+		inc.addEntry(TimelineEntry{Time: alert.StartsAt, Kind: "alert", Message: fmt.Sprintf("%s (%s) firing on %s", alert.Name, alert.Severity, alert.Subject)})
+
+		alert.Incident = inc.ID
+		delete(t.pending, alert.Fingerprint)
+		assignments = append(assignments, Assignment{Alert: *alert, IncidentID: inc.ID, NewIncident: created})
+	}
+	return assignments
+}
+
+func (m *Manager) matchLocked(t *tenantState, alert *Alert, related func(a, b string) bool) *Incident {
+	var best *Incident
+	for _, inc := range t.incidents {
+		if inc.Status == StatusResolved {
+			continue
+		}
+		gap := alert.StartsAt.Sub(inc.LastAlertAt)
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap > m.config.Window {
+			continue
+		}
+		for _, subject := range inc.Subjects {
+			if subject == alert.Subject || related(subject, alert.Subject) {
+				if best == nil || inc.LastAlertAt.After(best.LastAlertAt) {
+					best = inc
+				}
+				break
+			}
+		}
+	}
+	return best
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// recordDerivationLocked adds an atom derived by an inference rule to the
+// timeline of the incident it concerns
+func (m *Manager) recordDerivationLocked(t *tenantState, incidentID, rule, atomID, message string) {
+	inc, exists := t.incidents[incidentID]
+	if !exists {
+		return
+	}
+	kind := "derived"
+	switch rule {
+	case CorrelationRuleName:
+		kind = "correlated"
+	case ChangeRuleName:
+		kind = "change"
+	}
+	inc.addEntry(TimelineEntry{Kind: kind, Message: message, AtomID: atomID, Rule: rule})
+}
+
+// AddTimelineEntry appends an event recorded outside inference, such as a
+// runbook step, to an incident's timeline
+func (m *Manager) AddTimelineEntry(tenantID, incidentID string, entry TimelineEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inc, err := m.getLocked(tenantID, incidentID)
+	if err != nil {
+		return err
+	}
+	inc.addEntry(entry)
+	return nil
+}
+
+// IncidentOf returns the incident an alert belongs to
+func (m *Manager) IncidentOf(tenantID, fingerprint string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, exists := m.tenants[tenantID]
+	if !exists {
+		return "", false
+	}
+	a, exists := t.alerts[fingerprint]
+	if !exists || a.Incident == "" {
+		return "", false
+	}
+	return a.Incident, true
+}
+
+// Acknowledge marks an open incident as being worked on
+func (m *Manager) Acknowledge(tenantID, incidentID, by string) (Incident, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inc, err := m.getLocked(tenantID, incidentID)
+	if err != nil {
+		return Incident{}, err
+	}
+	if inc.Status != StatusOpen {
+		return Incident{}, fmt.Errorf("incident %s is %s", incidentID, inc.Status)
+	}
+	inc.Status = StatusAcknowledged
+	inc.AcknowledgedAt = time.Now()
+	inc.AcknowledgedBy = by
+	inc.addEntry(TimelineEntry{Time: inc.AcknowledgedAt, Kind: "acknowledged", Message: "Acknowledged by " + by, Actor: by})
+	return inc.copy(), nil
+}
+
+// Resolve closes an incident
+func (m *Manager) Resolve(tenantID, incidentID, by, note string) (Incident, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inc, err := m.getLocked(tenantID, incidentID)
+	if err != nil {
+		return Incident{}, err
+	}
+	if inc.Status == StatusResolved {
+		return Incident{}, fmt.Errorf("incident %s is already resolved", incidentID)
+	}
+	m.resolveLocked(inc, by, note)
+	return inc.copy(), nil
+}
+
+func (m *Manager) getLocked(tenantID, incidentID string) (*Incident, error) {
+	if t, exists := m.tenants[tenantID]; exists {
+		if inc, exists := t.incidents[incidentID]; exists {
+			return inc, nil
+		}
+	}
+	return nil, fmt.Errorf("incident %s not found", incidentID)
+}
+
+// Get returns an incident
+func (m *Manager) Get(tenantID, incidentID string) (Incident, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inc, err := m.getLocked(tenantID, incidentID)
+	if err != nil {
+		return Incident{}, err
+	}
+	return inc.copy(), nil
+}
+
+// List returns a tenant's incidents, newest first, optionally only those
+// with the given status
+func (m *Manager) List(tenantID string, status Status) []Incident {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]Incident, 0)
+	if t, exists := m.tenants[tenantID]; exists {
+		for _, inc := range t.incidents {
+			if status == "" || inc.Status == status {
+				result = append(result, inc.copy())
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].OpenedAt.Equal(result[j].OpenedAt) {
+			return result[i].OpenedAt.After(result[j].OpenedAt)
+		}
+		return result[i].ID > result[j].ID
+	})
+	return result
+}
+
+// GetAlerts returns the alerts of an incident
+func (m *Manager) GetAlerts(tenantID, incidentID string) ([]Alert, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inc, err := m.getLocked(tenantID, incidentID)
+	if err != nil {
+		return nil, err
+	}
+	t := m.tenants[tenantID]
+	alerts := make([]Alert, 0, len(inc.Alerts))
+	for _, fp := range inc.Alerts {
+		if a := t.alerts[fp]; a != nil {
+			alerts = append(alerts, *a)
+		}
+	}
+	return alerts, nil
+}
+
+// Purge deletes a tenant's alerts and incidents and returns how many
+// incidents were removed
+func (m *Manager) Purge(tenantID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, exists := m.tenants[tenantID]
+	if !exists {
+		return 0
+	}
+	delete(m.tenants, tenantID)
+	return len(t.incidents)
+}
+
+// GetStats returns incident statistics
+func (m *Manager) GetStats() map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alerts, pending := 0, 0
+	byStatus := make(map[Status]int)
+	for _, t := range m.tenants {
+		alerts += len(t.alerts)
+		pending += len(t.pending)
+		for _, inc := range t.incidents {
+			byStatus[inc.Status]++
+		}
+	}
+	return map[string]interface{}{
+		"alerts":    alerts,
+		"pending":   pending,
+		"incidents": byStatus,
+	}
+}
+
+// IncidentName is the concept name of an incident's atom
+func IncidentName(incidentID string) string {
+	return IncidentPrefix + incidentID
+}
+
+// AlertName is the concept name of an alert's atom
+func AlertName(fingerprint string) string {
+	return AlertPrefix + fingerprint
+}
+
+// incidentIDFromName is the inverse of IncidentName
+func incidentIDFromName(name string) (string, bool) {
+	if !strings.HasPrefix(name, IncidentPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(name, IncidentPrefix), true
+}
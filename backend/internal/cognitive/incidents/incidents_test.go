@@ -0,0 +1,120 @@
+package incidents
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCorrelateByTopologyAndTime(t *testing.T) {
+	m := NewManager(DefaultConfig())
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	alerts := []Alert{
+		{Name: "NodeDown", Subject: "node/n1", Severity: "critical", StartsAt: start},
+		{Name: "PodCrashLooping", Subject: "pod/default/web-1", StartsAt: start.Add(time.Minute)},
+		{Name: "PodCrashLooping", Subject: "pod/default/db-1", StartsAt: start.Add(2 * time.Minute)},
+		{Name: "PodCrashLooping", Subject: "pod/default/web-2", StartsAt: start.Add(time.Hour)},
+	}
+	if _, _, err := m.Ingest("t1", alerts); err != nil {
+		t.Fatalf("Ingest failed: %v", err)
+	}
+	if !m.HasPending("t1") {
+		t.Fatal("Expected pending alerts")
+	}
+
+	onN1 := map[string]bool{"pod/default/web-1": true, "pod/default/web-2": true}
+	related := func(a, b string) bool {
+		return (a == "node/n1" && onN1[b]) || (b == "node/n1" && onN1[a])
+	}
+	assignments := m.Correlate("t1", related)
+	if len(assignments) != 4 || m.HasPending("t1") {
+		t.Fatalf("Expected all 4 alerts assigned, got %d", len(assignments))
+	}
+
+	// The node and its pod share an incident; the unrelated pod and the
+	// late alert each open their own
+	list := m.List("t1", StatusOpen)
+	if len(list) != 3 {
+		t.Fatalf("Expected 3 incidents, got %d", len(list))
+	}
+	first, _ := m.IncidentOf("t1", alerts[0].Fingerprint)
+	second, _ := m.IncidentOf("t1", alerts[1].Fingerprint)
+	if first == "" || first != second {
+		t.Errorf("Expected the node and pod alerts to share an incident, got %q and %q", first, second)
+	}
+	inc, err := m.Get("t1", first)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if inc.Severity != "critical" || len(inc.Alerts) != 2 {
+		t.Errorf("Expected a critical incident with 2 alerts, got %+v", inc)
+	}
+}
+
+func TestIncidentLifecycle(t *testing.T) {
+	m := NewManager(DefaultConfig())
+	alert := Alert{Labels: map[string]string{"alertname": "HighLatency", "service": "api"}}
+	alerts, _, err := m.Ingest("t1", []Alert{alert})
+	if err != nil {
+		t.Fatalf("Ingest failed: %v", err)
+	}
+	if alerts[0].Subject != "api" || alerts[0].Fingerprint == "" {
+		t.Fatalf("Expected a normalized alert, got %+v", alerts[0])
+	}
+	m.Correlate("t1", func(a, b string) bool { return false })
+	incidentID, _ := m.IncidentOf("t1", alerts[0].Fingerprint)
+
+	if _, err := m.Acknowledge("t1", incidentID, "alice"); err != nil {
+		t.Fatalf("Acknowledge failed: %v", err)
+	}
+	if _, err := m.Acknowledge("t1", incidentID, "bob"); err == nil {
+		t.Error("Expected a second acknowledgement to fail")
+	}
+
+	// The incident resolves once its only alert does
+	resolvedAlert := alerts[0]
+	resolvedAlert.Status = AlertResolved
+	_, resolved, err := m.Ingest("t1", []Alert{resolvedAlert})
+	if err != nil {
+		t.Fatalf("Ingest failed: %v", err)
+	}
+	if len(resolved) != 1 || resolved[0] != incidentID {
+		t.Fatalf("Expected incident %s resolved, got %v", incidentID, resolved)
+	}
+	if _, err := m.Resolve("t1", incidentID, "alice", ""); err == nil {
+		t.Error("Expected resolving a resolved incident to fail")
+	}
+
+	inc, _ := m.Get("t1", incidentID)
+	kinds := make([]string, 0, len(inc.Timeline))
+	for _, e := range inc.Timeline {
+		kinds = append(kinds, e.Kind)
+	}
+	want := []string{"alert", "acknowledged", "alert_resolved", "resolved"}
+	if len(kinds) != len(want) {
+		t.Fatalf("Expected timeline %v, got %v", want, kinds)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("Expected timeline %v, got %v", want, kinds)
+		}
+	}
+}
+
+func TestParseAlertmanager(t *testing.T) {
+	payload := `{"alerts":[{"status":"firing","labels":{"alertname":"KubePodNotReady","namespace":"default","pod":"web-1","severity":"critical"},` +
+		`"startsAt":"2024-01-01T12:00:00Z","endsAt":"0001-01-01T00:00:00Z","fingerprint":"abc"}]}`
+	alerts, err := ParseAlertmanager([]byte(payload))
+	if err != nil {
+		t.Fatalf("ParseAlertmanager failed: %v", err)
+	}
+	if len(alerts) != 1 || !alerts[0].EndsAt.IsZero() {
+		t.Fatalf("Expected one firing alert, got %+v", alerts)
+	}
+	if err := alerts[0].Normalize(); err != nil {
+		t.Fatalf("Normalize failed: %v", err)
+	}
+	if alerts[0].Subject != "pod/default/web-1" || alerts[0].Severity != "critical" || alerts[0].Fingerprint != "abc" {
+		t.Errorf("Unexpected alert: %+v", alerts[0])
+	}
+}
@@ -0,0 +1,302 @@
+package inference
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// Pattern is a kind of atom a rule reads or derives: atoms of a type and,
+// if Name is set, of that name, e.g. EvaluationLinks of one predicate
+type Pattern struct {
+	Type atomspace.AtomType `json:"type"`
+	Name string             `json:"name,omitempty"`
+}
+
+// typeNames name atom types in reports
+var typeNames = map[atomspace.AtomType]string{
+	atomspace.NodeType:                  "Node",
+	atomspace.ConceptNodeType:           "ConceptNode",
+	atomspace.PredicateNodeType:         "PredicateNode",
+	atomspace.VariableNodeType:          "VariableNode",
+	atomspace.LinkType:                  "Link",
+	atomspace.InheritanceLinkType:       "InheritanceLink",
+	atomspace.SimilarityLinkType:        "SimilarityLink",
+	atomspace.ExecutionLinkType:         "ExecutionLink",
+	atomspace.EvaluationLinkType:        "EvaluationLink",
+	atomspace.NumberNodeType:            "NumberNode",
+	atomspace.GroundedPredicateNodeType: "GroundedPredicateNode",
+	atomspace.GroundedSchemaNodeType:    "GroundedSchemaNode",
+	atomspace.AliasLinkType:             "AliasLink",
+}
+
+func (p Pattern) String() string {
+	typeName, ok := typeNames[p.Type]
+	if !ok {
+		typeName = fmt.Sprintf("type %d", p.Type)
+	}
+	if p.Name == "" {
+		return typeName
+	}
+	return fmt.Sprintf("%s(%s)", typeName, p.Name)
+}
+
+// overlaps reports whether some atom matches both patterns
+func (p Pattern) overlaps(other Pattern) bool {
+	return p.Type == other.Type && (p.Name == "" || other.Name == "" || p.Name == other.Name)
+}
+
+// Signature describes what a rule reads and derives, so rule sets can be
+// analyzed without running them
+type Signature struct {
+	Premises    []Pattern // Atoms the rule needs, all of them
+	Conclusions []Pattern // Atoms the rule derives or whose truth values it sets
+	External    bool      // Also fires on input outside the atomspace, e.g. pending alerts
+	Generative  bool      // Derives nodes that were not among its premises
+}
+
+// Described is implemented by rules that declare their signature. Rules
+// that do not are listed as unanalyzed.
+type Described interface {
+	Signature() Signature
+}
+
+// Overlap is two rules firing on the same atoms. Rules that also derive the
+// same atoms conflict: their truth values are revised into one, or replaced
+// if one rule is authoritative.
+type Overlap struct {
+	Rules       []string `json:"rules"`
+	Premises    []string `json:"premises"`
+	Conclusions []string `json:"conclusions,omitempty"` // Derived by both
+	Conflict    bool     `json:"conflict"`
+}
+
+// Loop is rules whose conclusions feed each other's premises. A loop
+// through a generative rule can derive new atoms forever; others end once
+// their closure over the existing nodes is derived, which may still be
+// expensive.
+type Loop struct {
+	Rules     []string `json:"rules"`
+	Unbounded bool     `json:"unbounded"`
+}
+
+// Unreachable is a rule that cannot fire: neither the tenant's atoms nor
+// the other rules provide some of its premises
+type Unreachable struct {
+	Rule    string   `json:"rule"`
+	Missing []string `json:"missing"`
+}
+
+// Analysis is the report on a rule set
+type Analysis struct {
+	Rules       []string      `json:"rules"`
+	Overlaps    []Overlap     `json:"overlaps"`
+	Loops       []Loop        `json:"loops"`
+	Unreachable []Unreachable `json:"unreachable"`
+	Unanalyzed  []string      `json:"unanalyzed"` // Rules without a signature
+}
+
+// HasErrors reports whether the rule set can run away: it has a loop
+// through a generative rule
+func (a *Analysis) HasErrors() bool {
+	for _, loop := range a.Loops {
+		if loop.Unbounded {
+			return true
+		}
+	}
+	return false
+}
+
+// Analyze examines a rule set against the kinds of atoms present: which
+// rules overlap, which feed each other in loops and which can never fire.
+// Rules are not run. A rule feeding only itself is not reported as a loop
+// unless it is generative, as it ends at its own closure.
+func Analyze(rules []InferenceRule, present []Pattern) *Analysis {
+	analysis := &Analysis{
+		Rules:       make([]string, 0, len(rules)),
+		Overlaps:    make([]Overlap, 0),
+		Loops:       make([]Loop, 0),
+		Unreachable: make([]Unreachable, 0),
+		Unanalyzed:  make([]string, 0),
+	}
+
+	names := make([]string, 0, len(rules))
+	signatures := make(map[string]Signature, len(rules))
+	for _, rule := range rules {
+		analysis.Rules = append(analysis.Rules, rule.GetName())
+		described, ok := rule.(Described)
+		if !ok {
+			analysis.Unanalyzed = append(analysis.Unanalyzed, rule.GetName())
+			continue
+		}
+		if _, exists := signatures[rule.GetName()]; !exists {
+			names = append(names, rule.GetName())
+		}
+		signatures[rule.GetName()] = described.Signature()
+	}
+	sort.Strings(names)
+	sort.Strings(analysis.Rules)
+	sort.Strings(analysis.Unanalyzed)
+
+	for i, a := range names {
+		for _, b := range names[i+1:] {
+			if overlap, ok := overlapOf(a, b, signatures[a], signatures[b]); ok {
+				analysis.Overlaps = append(analysis.Overlaps, overlap)
+			}
+		}
+	}
+	analysis.Loops = loops(names, signatures)
+	analysis.Unreachable = unreachable(names, signatures, present)
+	return analysis
+}
+
+// overlapOf returns the premises two rules share and the conclusions both
+// derive
+func overlapOf(a, b string, sa, sb Signature) (Overlap, bool) {
+	overlap := Overlap{Rules: []string{a, b}, Premises: shared(sa.Premises, sb.Premises)}
+	if len(overlap.Premises) == 0 {
+		return overlap, false
+	}
+	if conclusions := shared(sa.Conclusions, sb.Conclusions); len(conclusions) > 0 {
+		overlap.Conclusions = conclusions
+		overlap.Conflict = true
+	}
+	return overlap, true
+}
+
+// shared lists the patterns of a overlapping some pattern of b
+func shared(a, b []Pattern) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0)
+	for _, pa := range a {
+		for _, pb := range b {
+			if pa.overlaps(pb) && !seen[pa.String()] {
+				seen[pa.String()] = true
+				result = append(result, pa.String())
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// feeds reports whether a conclusion of from matches a premise of to
+func feeds(from, to Signature) bool {
+	for _, c := range from.Conclusions {
+		for _, p := range to.Premises {
+			if c.overlaps(p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loops finds the strongly connected components of the graph of rules
+// feeding each other (Tarjan's algorithm)
+func loops(names []string, signatures map[string]Signature) []Loop {
+	index := make(map[string]int, len(names))
+	low := make(map[string]int, len(names))
+	onStack := make(map[string]bool, len(names))
+	stack := make([]string, 0, len(names))
+	next := 0
+	result := make([]Loop, 0)
+
+	var visit func(name string)
+	visit = func(name string) {
+		index[name], low[name] = next, next
+		next++
+		stack = append(stack, name)
+		onStack[name] = true
+
+		for _, other := range names {
+			if !feeds(signatures[name], signatures[other]) {
+				continue
+			}
+			if _, visited := index[other]; !visited {
+				visit(other)
+				if low[other] < low[name] {
+					low[name] = low[other]
+				}
+			} else if onStack[other] && index[other] < low[name] {
+				low[name] = index[other]
+			}
+		}
+		if low[name] != index[name] {
+			return
+		}
+
+		component := make([]string, 0)
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == name {
+				break
+			}
+		}
+		loop := Loop{Rules: component}
+		for _, rule := range component {
+			loop.Unbounded = loop.Unbounded || signatures[rule].Generative
+		}
+		selfFed := len(component) == 1 && feeds(signatures[name], signatures[name])
+		if len(component) > 1 || (selfFed && loop.Unbounded) {
+			sort.Strings(loop.Rules)
+			result = append(result, loop)
+		}
+	}
+	for _, name := range names {
+		if _, visited := index[name]; !visited {
+			visit(name)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Rules[0] < result[j].Rules[0] })
+	return result
+}
+
+// unreachable fires rules on the present patterns until no more can fire,
+// returning the rules that never did and the premises they lacked
+func unreachable(names []string, signatures map[string]Signature, present []Pattern) []Unreachable {
+	available := append([]Pattern{}, present...)
+	fired := make(map[string]bool, len(names))
+	missing := func(s Signature) []string {
+		result := make([]string, 0)
+		for _, p := range s.Premises {
+			found := false
+			for _, a := range available {
+				if a.overlaps(p) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				result = append(result, p.String())
+			}
+		}
+		return result
+	}
+
+	for progress := true; progress; {
+		progress = false
+		for _, name := range names {
+			s := signatures[name]
+			if fired[name] || len(missing(s)) > 0 || (len(s.Premises) == 0 && !s.External) {
+				continue
+			}
+			fired[name] = true
+			available = append(available, s.Conclusions...)
+			progress = true
+		}
+	}
+
+	result := make([]Unreachable, 0)
+	for _, name := range names {
+		if fired[name] {
+			continue
+		}
+		result = append(result, Unreachable{Rule: name, Missing: missing(signatures[name])})
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// describedRule declares a signature and derives nothing
+type describedRule struct {
+	name      string
+	signature Signature
+}
+
+func (r *describedRule) GetName() string                      { return r.name }
+func (r *describedRule) GetPriority() int                     { return 1 }
+func (r *describedRule) CanApply(atoms []atomspace.Atom) bool { return false }
+func (r *describedRule) Signature() Signature                 { return r.signature }
+
+func (r *describedRule) Apply(ctx context.Context, atoms []atomspace.Atom) ([]atomspace.Atom, error) {
+	return nil, nil
+}
+
+func evaluation(name string) Pattern {
+	return Pattern{Type: atomspace.EvaluationLinkType, Name: name}
+}
+
+func TestAnalyzeLoops(t *testing.T) {
+	rules := []InferenceRule{
+		// depends_on and needs feed each other
+		&describedRule{name: "forward", signature: Signature{Premises: []Pattern{evaluation("depends_on")}, Conclusions: []Pattern{evaluation("needs")}}},
+		&describedRule{name: "backward", signature: Signature{Premises: []Pattern{evaluation("needs")}, Conclusions: []Pattern{evaluation("depends_on")}}},
+		// Minting a node for each node it reads
+		&describedRule{name: "spawn", signature: Signature{
+			Premises:    []Pattern{{Type: atomspace.ConceptNodeType}},
+			Conclusions: []Pattern{{Type: atomspace.ConceptNodeType}},
+			Generative:  true,
+		}},
+		NewDeductionRule(),
+	}
+	analysis := Analyze(rules, []Pattern{evaluation("depends_on"), {Type: atomspace.ConceptNodeType}})
+
+	if got := fmt.Sprint(analysis.Loops); got != "[{[backward forward] false} {[spawn] true}]" {
+		t.Errorf("Expected a bounded and an unbounded loop, got %s", got)
+	}
+	if !analysis.HasErrors() {
+		t.Error("Expected the generative loop to be an error")
+	}
+	if len(analysis.Unreachable) != 1 || analysis.Unreachable[0].Rule != "deduction" {
+		t.Errorf("Expected deduction to be unreachable without inheritance links, got %+v", analysis.Unreachable)
+	}
+}
+
+func TestAnalyzeOverlapsAndReachability(t *testing.T) {
+	rules := []InferenceRule{
+		NewDeductionRule(),
+		NewInductionRule(),
+		&describedRule{name: "generalize", signature: Signature{
+			Premises:    []Pattern{{Type: atomspace.SimilarityLinkType}},
+			Conclusions: []Pattern{{Type: atomspace.InheritanceLinkType}},
+		}},
+		&describedRule{name: "alerts", signature: Signature{Premises: []Pattern{evaluation("fired")}, External: true}},
+		&fixedRule{name: "opaque"},
+	}
+	analysis := Analyze(rules, []Pattern{{Type: atomspace.InheritanceLinkType, Name: "inheritance"}})
+
+	if len(analysis.Overlaps) != 1 || fmt.Sprint(analysis.Overlaps[0].Rules) != "[deduction induction]" || analysis.Overlaps[0].Conflict {
+		t.Errorf("Expected deduction and induction to overlap without conflict, got %+v", analysis.Overlaps)
+	}
+	// Induction's similarity links make generalize reachable, and feed
+	// deduction back
+	if len(analysis.Unreachable) != 1 || analysis.Unreachable[0].Rule != "alerts" || analysis.Unreachable[0].Missing[0] != "EvaluationLink(fired)" {
+		t.Errorf("Expected only alerts to be unreachable, got %+v", analysis.Unreachable)
+	}
+	if len(analysis.Loops) != 1 || fmt.Sprint(analysis.Loops[0].Rules) != "[deduction generalize induction]" || analysis.HasErrors() {
+		t.Errorf("Expected a bounded loop through generalize, got %+v", analysis.Loops)
+	}
+	if fmt.Sprint(analysis.Unanalyzed) != "[opaque]" {
+		t.Errorf("Expected the rule without a signature to be unanalyzed, got %v", analysis.Unanalyzed)
+	}
+
+	rules = append(rules, &describedRule{name: "restate", signature: Signature{
+		Premises:    []Pattern{{Type: atomspace.InheritanceLinkType}},
+		Conclusions: []Pattern{{Type: atomspace.InheritanceLinkType}},
+	}})
+	conflicts := 0
+	for _, overlap := range Analyze(rules, nil).Overlaps {
+		if fmt.Sprint(overlap.Rules) == "[deduction restate]" && overlap.Conflict {
+			conflicts++
+		}
+	}
+	if conflicts != 1 {
+		t.Error("Expected rules deriving the same links to conflict")
+	}
+}
@@ -0,0 +1,221 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// Limits bound the derivation chains of each run. The depth of an atom
+// present when the run starts is 0; an atom derived in the run is one
+// deeper than the deepest of its premises.
+type Limits struct {
+	MaxDepth     int  `json:"max_depth"`     // Derivations deeper than this are dropped; 0 for no limit
+	DetectCycles bool `json:"detect_cycles"` // Drop derivations whose premises rest on the atom derived
+}
+
+// DefaultLimits allows chains of 16 derivations and detects cycles
+func DefaultLimits() Limits {
+	return Limits{MaxDepth: 16, DetectCycles: true}
+}
+
+// Validate checks the limits
+func (l Limits) Validate() error {
+	if l.MaxDepth < 0 {
+		return fmt.Errorf("max depth must not be negative")
+	}
+	return nil
+}
+
+// How a run ended
+const (
+	StatusFixpoint       = "fixpoint"        // No rule could add an atom
+	StatusDepthLimit     = "depth_limit"     // Derivations beyond the depth limit were dropped
+	StatusIterationLimit = "iteration_limit" // Rules were still adding atoms at the last iteration
+	StatusCancelled      = "cancelled"
+)
+
+// RunReport describes how a run ended
+type RunReport struct {
+	TenantID     string        `json:"tenant_id"`
+	Status       string        `json:"status"`
+	Cached       bool          `json:"cached,omitempty"` // The fixpoint was known, no rule fired
+	Iterations   int           `json:"iterations"`
+	Derived      int           `json:"derived"`
+	Depth        int           `json:"depth"`         // Deepest atom derived
+	DepthLimited int           `json:"depth_limited"` // Derivations dropped beyond the depth limit
+	Cycles       int           `json:"cycles"`        // Derivations dropped as resting on themselves
+	StartedAt    time.Time     `json:"started_at"`
+	Duration     time.Duration `json:"duration_ns"`
+}
+
+type reportKey struct{}
+
+// WithReport returns a context receiving the report of the run it is
+// passed to
+func WithReport(ctx context.Context, report *RunReport) context.Context {
+	return context.WithValue(ctx, reportKey{}, report)
+}
+
+// Conclusion is an atom a rule derived and the IDs of the atoms it was
+// derived from
+type Conclusion struct {
+	Atom     atomspace.Atom
+	Premises []string
+}
+
+// Justified is implemented by rules reporting the premises of what they
+// derive. Atoms derived by other rules are assumed to rest on the deepest
+// atom derived so far in the run, and cannot be checked for cycles.
+type Justified interface {
+	ApplyJustified(ctx context.Context, atoms []atomspace.Atom) ([]Conclusion, error)
+}
+
+// conclusionAtoms returns the atoms of conclusions, for rules implementing
+// Apply with ApplyJustified
+func conclusionAtoms(conclusions []Conclusion, err error) ([]atomspace.Atom, error) {
+	atoms := make([]atomspace.Atom, 0, len(conclusions))
+	for _, c := range conclusions {
+		atoms = append(atoms, c.Atom)
+	}
+	return atoms, err
+}
+
+// applyRule fires a rule, with the premises of its conclusions by atom ID if
+// the rule reports them
+func applyRule(ctx context.Context, rule InferenceRule, atoms []atomspace.Atom) ([]atomspace.Atom, map[string][]string, error) {
+	justified, ok := rule.(Justified)
+	if !ok {
+		newAtoms, err := rule.Apply(ctx, atoms)
+		return newAtoms, nil, err
+	}
+	conclusions, err := justified.ApplyJustified(ctx, atoms)
+	newAtoms := make([]atomspace.Atom, 0, len(conclusions))
+	premises := make(map[string][]string, len(conclusions))
+	for _, c := range conclusions {
+		newAtoms = append(newAtoms, c.Atom)
+		if _, exists := premises[c.Atom.GetID()]; !exists {
+			premises[c.Atom.GetID()] = c.Premises
+		}
+	}
+	return newAtoms, premises, err
+}
+
+// derivationChain is how an atom derived in a run was derived
+type derivationChain struct {
+	depth     int
+	ancestors map[string]bool // Atoms it rests on, if cycles are detected
+}
+
+// chainOf returns the chain of a derivation from its premises, or from the
+// deepest atom derived so far if the rule did not report them
+func (run *inferenceRun) chainOf(premises []string, reported bool) *derivationChain {
+	c := &derivationChain{depth: 1}
+	if !reported {
+		c.depth = run.deepest + 1
+		return c
+	}
+	if run.limits.DetectCycles {
+		c.ancestors = make(map[string]bool, len(premises))
+	}
+	for _, id := range premises {
+		if c.ancestors != nil {
+			c.ancestors[id] = true
+		}
+		p, derived := run.chains[id]
+		if !derived {
+			continue
+		}
+		if p.depth+1 > c.depth {
+			c.depth = p.depth + 1
+		}
+		for ancestor := range p.ancestors {
+			if c.ancestors != nil {
+				c.ancestors[ancestor] = true
+			}
+		}
+	}
+	return c
+}
+
+// admit reports whether a derivation is within the limits, counting it if
+// not. A derivation resting on the atom it derives is a cycle. The depth
+// limit only applies to atoms that do not exist yet; atoms that existed
+// before the run or were derived earlier in it may still be revised.
+func (run *inferenceRun) admit(id string, c *derivationChain) bool {
+	if c.ancestors[id] {
+		run.cycles++
+		return false
+	}
+	if run.limits.MaxDepth <= 0 || c.depth <= run.limits.MaxDepth {
+		return true
+	}
+	if _, derivedInRun := run.chains[id]; derivedInRun {
+		return true
+	}
+	if _, err := run.ie.atomSpace.GetAtom(id, run.tenantID); err == nil {
+		return true
+	}
+	run.depthLimited++
+	return false
+}
+
+// finish reports how a run ended to the context's report, if any, and as
+// the engine's last run
+func (ie *InferenceEngine) finish(ctx context.Context, report RunReport) {
+	report.Duration = time.Since(report.StartedAt)
+	if target, ok := ctx.Value(reportKey{}).(*RunReport); ok && target != nil {
+		*target = report
+	}
+	ie.mu.Lock()
+	ie.lastRun = report
+	ie.mu.Unlock()
+}
+
+// report describes the run as it ended
+func (run *inferenceRun) report(reached bool, startedAt time.Time, err error) RunReport {
+	report := RunReport{
+		TenantID:     run.tenantID,
+		Iterations:   run.iterations,
+		Derived:      len(run.derived),
+		Depth:        run.deepest,
+		DepthLimited: run.depthLimited,
+		Cycles:       run.cycles,
+		StartedAt:    startedAt,
+	}
+	switch {
+	case err != nil:
+		report.Status = StatusCancelled
+	case run.depthLimited > 0:
+		report.Status = StatusDepthLimit
+	case reached:
+		report.Status = StatusFixpoint
+	default:
+		report.Status = StatusIterationLimit
+	}
+	return report
+}
+
+// SetLimits sets the limits of the engine's runs
+func (ie *InferenceEngine) SetLimits(limits Limits) {
+	ie.mu.Lock()
+	defer ie.mu.Unlock()
+	ie.limits = limits
+	ie.rulesVersion++
+}
+
+// GetLimits returns the limits of the engine's runs
+func (ie *InferenceEngine) GetLimits() Limits {
+	ie.mu.RLock()
+	defer ie.mu.RUnlock()
+	return ie.limits
+}
+
+// LastRun returns the report of the run that ended last
+func (ie *InferenceEngine) LastRun() RunReport {
+	ie.mu.RLock()
+	defer ie.mu.RUnlock()
+	return ie.lastRun
+}
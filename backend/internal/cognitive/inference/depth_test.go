@@ -0,0 +1,118 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func concept(name string) atomspace.Atom {
+	node := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, "t", atomspace.ConceptNodeType)
+	node.SetTruthValue(atomspace.TruthValue{Strength: 1, Confidence: 0.9})
+	return node
+}
+
+// successorRule derives n<i+1> from each n<i>, without end
+type successorRule struct{}
+
+func (r *successorRule) GetName() string                      { return "successor" }
+func (r *successorRule) GetPriority() int                     { return 1 }
+func (r *successorRule) CanApply(atoms []atomspace.Atom) bool { return true }
+
+func (r *successorRule) Apply(ctx context.Context, atoms []atomspace.Atom) ([]atomspace.Atom, error) {
+	return conclusionAtoms(r.ApplyJustified(ctx, atoms))
+}
+
+func (r *successorRule) ApplyJustified(ctx context.Context, atoms []atomspace.Atom) ([]Conclusion, error) {
+	conclusions := make([]Conclusion, 0, len(atoms))
+	for _, atom := range atoms {
+		var i int
+		if _, err := fmt.Sscanf(atom.GetName(), "n%d", &i); err == nil {
+			conclusions = append(conclusions, Conclusion{Atom: concept(fmt.Sprintf("n%d", i+1)), Premises: []string{atom.GetID()}})
+		}
+	}
+	return conclusions, nil
+}
+
+// restateRule derives one concept from another
+type restateRule struct {
+	name     string
+	from, to string
+}
+
+func (r *restateRule) GetName() string                      { return r.name }
+func (r *restateRule) GetPriority() int                     { return 1 }
+func (r *restateRule) CanApply(atoms []atomspace.Atom) bool { return true }
+
+func (r *restateRule) Apply(ctx context.Context, atoms []atomspace.Atom) ([]atomspace.Atom, error) {
+	return conclusionAtoms(r.ApplyJustified(ctx, atoms))
+}
+
+func (r *restateRule) ApplyJustified(ctx context.Context, atoms []atomspace.Atom) ([]Conclusion, error) {
+	for _, atom := range atoms {
+		if atom.GetName() == r.from {
+			to := concept(r.to)
+			to.SetTruthValue(atomspace.TruthValue{Strength: 0.5, Confidence: 0.5})
+			return []Conclusion{{Atom: to, Premises: []string{atom.GetID()}}}, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestDepthLimit(t *testing.T) {
+	space := atomspace.NewAtomSpace(1)
+	defer space.Close()
+	space.AddAtom(concept("n0"))
+
+	ie := NewInferenceEngine(space, 2)
+	defer ie.Close()
+	ie.AddRule(&successorRule{})
+	ie.SetLimits(Limits{MaxDepth: 3})
+
+	var report RunReport
+	derived, err := ie.RunInference(WithReport(context.Background(), &report), "t", 100)
+	if err != nil {
+		t.Fatalf("RunInference failed: %v", err)
+	}
+	if len(derived) != 3 || report.Status != StatusDepthLimit || report.Depth != 3 || report.DepthLimited == 0 {
+		t.Errorf("Expected n1 to n3 derived before the depth limit, got %d atoms and %+v", len(derived), report)
+	}
+	if report.Iterations >= 100 {
+		t.Errorf("Expected the run to end before the iteration cap, got %d iterations", report.Iterations)
+	}
+	if last := ie.LastRun(); last.Status != StatusDepthLimit {
+		t.Errorf("Expected the last run to be kept, got %+v", last)
+	}
+
+	// Without a limit the rule runs until the iteration cap
+	ie.SetLimits(Limits{})
+	derived, _ = ie.RunInference(WithReport(context.Background(), &report), "t", 5)
+	if len(derived) != 5 || report.Status != StatusIterationLimit {
+		t.Errorf("Expected 5 more atoms up to the iteration cap, got %d and %+v", len(derived), report)
+	}
+}
+
+func TestCycleDetection(t *testing.T) {
+	space := atomspace.NewAtomSpace(1)
+	defer space.Close()
+	space.AddAtom(concept("cause"))
+
+	ie := NewInferenceEngine(space, 2)
+	defer ie.Close()
+	ie.AddRule(&restateRule{name: "forward", from: "cause", to: "effect"})
+	ie.AddRule(&restateRule{name: "backward", from: "effect", to: "cause"})
+
+	var report RunReport
+	derived, err := ie.RunInference(WithReport(context.Background(), &report), "t", 10)
+	if err != nil {
+		t.Fatalf("RunInference failed: %v", err)
+	}
+	if len(derived) != 1 || report.Status != StatusFixpoint || report.Cycles == 0 {
+		t.Errorf("Expected effect derived and cause's derivation from it dropped, got %d atoms and %+v", len(derived), report)
+	}
+	if stats := ie.GetMergeStats(); stats.Rejected != 0 {
+		t.Errorf("Expected the cyclic derivation to be dropped before merging, got %+v", stats)
+	}
+}
@@ -2,10 +2,12 @@ package inference
 
 import (
 	"context"
+	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/budget"
 )
 
 // InferenceRule represents a rule that can be applied to atoms
@@ -23,10 +25,34 @@ type InferenceEngine struct {
 	workers   int
 	mu        sync.RWMutex
 	
-	// Channels for concurrent inference
-	taskChan   chan inferenceTask
-	resultChan chan inferenceResult
-	done       chan struct{}
+	// Learned probability of applying each rule; unlisted rules always apply
+	ruleWeights map[string]float64
+	
+	// Chooses which applicable rules fire in each iteration
+	selector RuleSelector
+	
+	// Called for each atom a rule derived
+	onDerived func(tenantID, rule string, atom atomspace.Atom)
+	
+	// Called with the time each rule application took
+	onWork func(tenantID string, elapsed time.Duration)
+	
+	// Whether iterations overlap, and what merging their results resolved
+	pipelined bool
+	merges    mergeCounters
+	
+	// Fixpoints of each tenant, valid for the rules and limits of a version
+	fixpoints    *fixpointCache
+	rulesVersion uint64
+	
+	// Bounds on the derivation chains of each run, and how the last run ended
+	limits  Limits
+	lastRun RunReport
+	
+	// Channel for concurrent inference; each run collects its results on
+	// its own channel
+	taskChan chan inferenceTask
+	done     chan struct{}
 }
 
 type inferenceTask struct {
@@ -34,10 +60,12 @@ type inferenceTask struct {
 	atoms    []atomspace.Atom
 	rule     InferenceRule
 	ctx      context.Context
+	results  chan<- inferenceResult
 }
 
 type inferenceResult struct {
 	newAtoms []atomspace.Atom
+	premises map[string][]string // Atom ID -> IDs of its premises, if the rule reports them
 	err      error
 	rule     string
 }
@@ -47,9 +75,12 @@ func NewInferenceEngine(atomSpace atomspace.AtomSpaceInterface, workers int) *In
 	ie := &InferenceEngine{
 		atomSpace:  atomSpace,
 		rules:      make([]InferenceRule, 0),
+		ruleWeights: make(map[string]float64),
+		selector:   &AllSelector{yieldTable: newYieldTable(DefaultSelectionConfig().Decay)},
+		fixpoints:  newFixpointCache(),
+		limits:     DefaultLimits(),
 		workers:    workers,
 		taskChan:   make(chan inferenceTask, 1000),
-		resultChan: make(chan inferenceResult, 1000),
 		done:       make(chan struct{}),
 	}
 	
@@ -66,9 +97,17 @@ func (ie *InferenceEngine) worker() {
 	for {
 		select {
 		case task := <-ie.taskChan:
-			newAtoms, err := task.rule.Apply(task.ctx, task.atoms)
-			ie.resultChan <- inferenceResult{
+			start := time.Now()
+			newAtoms, premises, err := applyRule(task.ctx, task.rule, task.atoms)
+			ie.mu.RLock()
+			onWork := ie.onWork
+			ie.mu.RUnlock()
+			if onWork != nil {
+				onWork(task.tenantID, time.Since(start))
+			}
+			task.results <- inferenceResult{
 				newAtoms: newAtoms,
+				premises: premises,
 				err:      err,
 				rule:     task.rule.GetName(),
 			}
@@ -83,70 +122,336 @@ func (ie *InferenceEngine) AddRule(rule InferenceRule) {
 	ie.mu.Lock()
 	defer ie.mu.Unlock()
 	ie.rules = append(ie.rules, rule)
+	ie.rulesVersion++
+}
+
+// GetRules returns the rules of the engine
+func (ie *InferenceEngine) GetRules() []InferenceRule {
+	ie.mu.RLock()
+	defer ie.mu.RUnlock()
+	return append([]InferenceRule{}, ie.rules...)
+}
+
+// SetRuleWeight sets the probability in (0, 1] with which a rule is applied
+// in each iteration
+func (ie *InferenceEngine) SetRuleWeight(rule string, weight float64) {
+	ie.mu.Lock()
+	defer ie.mu.Unlock()
+	if weight >= 1 {
+		delete(ie.ruleWeights, rule)
+		return
+	}
+	ie.ruleWeights[rule] = weight
+}
+
+// GetRuleWeights returns the weights of rules that are not always applied
+func (ie *InferenceEngine) GetRuleWeights() map[string]float64 {
+	ie.mu.RLock()
+	defer ie.mu.RUnlock()
+	
+	weights := make(map[string]float64, len(ie.ruleWeights))
+	for rule, weight := range ie.ruleWeights {
+		weights[rule] = weight
+	}
+	return weights
+}
+
+// SetSelector replaces the strategy choosing which rules fire
+func (ie *InferenceEngine) SetSelector(selector RuleSelector) {
+	ie.mu.Lock()
+	defer ie.mu.Unlock()
+	ie.selector = selector
 }
 
-// RunInference executes inference rules on atoms for a tenant
-func (ie *InferenceEngine) RunInference(ctx context.Context, tenantID string, maxIterations int) ([]atomspace.Atom, error) {
-	var allNewAtoms []atomspace.Atom
+// GetSelector returns the strategy choosing which rules fire
+func (ie *InferenceEngine) GetSelector() RuleSelector {
+	ie.mu.RLock()
+	defer ie.mu.RUnlock()
+	return ie.selector
+}
+
+// OnDerived sets the handler called for each atom a rule derived
+func (ie *InferenceEngine) OnDerived(handler func(tenantID, rule string, atom atomspace.Atom)) {
+	ie.mu.Lock()
+	defer ie.mu.Unlock()
+	ie.onDerived = handler
+}
+
+// OnWork sets the handler called with the time each rule application took
+// on a worker, which sums to the compute time inference used
+func (ie *InferenceEngine) OnWork(handler func(tenantID string, elapsed time.Duration)) {
+	ie.mu.Lock()
+	defer ie.mu.Unlock()
+	ie.onWork = handler
+}
+
+// SetPipelined sets whether inference iterations overlap. A pipelined run
+// fires idle rules against a fresh snapshot as soon as any rule's results
+// are merged, instead of waiting for every rule of the iteration.
+func (ie *InferenceEngine) SetPipelined(pipelined bool) {
+	ie.mu.Lock()
+	defer ie.mu.Unlock()
+	ie.pipelined = pipelined
+}
+
+// IsPipelined returns whether inference iterations overlap
+func (ie *InferenceEngine) IsPipelined() bool {
+	ie.mu.RLock()
+	defer ie.mu.RUnlock()
+	return ie.pipelined
+}
+
+// GetMergeStats returns the totals of the merge phase over all runs
+func (ie *InferenceEngine) GetMergeStats() MergeStats {
+	return ie.merges.get()
+}
+
+// GetFixpointStats returns the use of the fixpoint cache
+func (ie *InferenceEngine) GetFixpointStats() FixpointStats {
+	return ie.fixpoints.get()
+}
+
+// RunInference executes inference rules on atoms for a tenant. The
+// selector chooses which applicable rules fire in each iteration; inference
+// stops at a fixpoint, once every applicable rule has fired without adding
+// an atom. The results of each iteration are merged before being added, see
+// inferenceRun.merge.
+//
+// If the AtomSpace counts changes (atomspace.Versioned), a proven fixpoint
+// is cached and running again on unchanged atoms with the same rules
+// returns no atoms immediately.
+//
+// Derivations beyond the depth limit, or resting on the atom they derive,
+// are dropped, so mutually recursive rules end at a fixpoint within the
+// limit. How the run ended is reported to the context, see WithReport.
+func (ie *InferenceEngine) RunInference(ctx context.Context, tenantID string, maxIterations int) (_ []atomspace.Atom, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	startedAt := time.Now()
+	proof := &fixpointProof{tenantID: tenantID, fresh: true}
+	ie.mu.RLock()
+	rulesVersion := ie.rulesVersion
+	ie.mu.RUnlock()
+	if versioned, ok := ie.atomSpace.(atomspace.Versioned); ok {
+		proof.versioned = versioned
+		if known, hit := ie.fixpoints.hit(tenantID, fixpoint{generation: versioned.Generation(tenantID), rules: rulesVersion}); hit {
+			report := RunReport{TenantID: tenantID, Status: StatusFixpoint, Cached: true, StartedAt: startedAt}
+			if known.depthLimited {
+				report.Status = StatusDepthLimit
+			}
+			ie.finish(ctx, report)
+			return nil, nil
+		}
+	}
+	
+	ie.mu.RLock()
+	run := &inferenceRun{
+		ie:            ie,
+		tenantID:      tenantID,
+		priority:      make(map[string]int, len(ie.rules)),
+		authoritative: make(map[string]bool),
+		onDerived:     ie.onDerived,
+		results:       make(chan inferenceResult, len(ie.rules)),
+		evidence:      make(map[string]map[string]bool),
+		limits:        ie.limits,
+		chains:        make(map[string]*derivationChain),
+	}
+	for _, rule := range ie.rules {
+		run.priority[rule.GetName()] = rule.GetPriority()
+		if a, ok := rule.(Authoritative); ok && a.Authoritative() {
+			run.authoritative[rule.GetName()] = true
+		}
+	}
+	selector := ie.selector
+	pipelined := ie.pipelined
+	ie.mu.RUnlock()
+	defer func() { ie.merges.add(run.stats) }()
+	defer func() { ie.finish(ctx, run.report(proof.reached, startedAt, err)) }()
+	
+	// Cache the fixpoint a run proves
+	defer func() {
+		if generation, ok := proof.proven(); ok {
+			ie.fixpoints.store(tenantID, fixpoint{generation: generation, rules: rulesVersion, depthLimited: run.depthLimited > 0})
+		}
+	}()
 	
+	if pipelined {
+		err := ie.runPipelined(ctx, run, selector, proof, maxIterations)
+		return run.derived, err
+	}
+	
+	barren := make(map[string]bool) // rules fired since the last new atom
 	for iteration := 0; iteration < maxIterations; iteration++ {
 		select {
 		case <-ctx.Done():
-			return allNewAtoms, ctx.Err()
+			return run.derived, ctx.Err()
 		default:
 		}
 		
-		// Get all atoms for this tenant
-		atoms := ie.atomSpace.QueryAtoms(tenantID, nil)
+		// Charge the iteration to the budget of the calling agent, if any
+		budget.MeterFrom(ctx).AddIterations(1)
+		
+		run.iterations++
 		
+		applicable, candidates, atoms, skipped := ie.candidates(tenantID, barren, nil)
 		if len(atoms) == 0 {
+			proof.reached = true
 			break
 		}
+		proof.snapshot(skipped)
 		
-		// Try to apply each rule in parallel
-		ie.mu.RLock()
+		// Try to apply each selected rule in parallel
 		tasksSubmitted := 0
-		for _, rule := range ie.rules {
-			if rule.CanApply(atoms) {
+		if len(candidates) > 0 {
+			for _, name := range selector.Select(candidates) {
 				ie.taskChan <- inferenceTask{
 					tenantID: tenantID,
 					atoms:    atoms,
-					rule:     rule,
+					rule:     applicable[name],
 					ctx:      ctx,
+					results:  run.results,
 				}
 				tasksSubmitted++
 			}
 		}
-		ie.mu.RUnlock()
 		
 		// Collect results from parallel inference
 		if tasksSubmitted == 0 {
+			proof.reached = true
 			break
 		}
 		
-		newAtomsThisIteration := 0
+		results := make([]inferenceResult, 0, tasksSubmitted)
 		for i := 0; i < tasksSubmitted; i++ {
-			result := <-ie.resultChan
-			if result.err != nil {
-				continue
+			results = append(results, <-run.results)
+		}
+		yields := run.merge(results)
+		newAtomsThisIteration := 0
+		for _, result := range results {
+			selector.Observe(result.rule, yields[result.rule])
+			newAtomsThisIteration += yields[result.rule]
+			if yields[result.rule] == 0 {
+				barren[result.rule] = true
 			}
-			
-			// Add new atoms to the atomspace
-			for _, atom := range result.newAtoms {
-				if err := ie.atomSpace.AddAtom(atom); err == nil {
-					allNewAtoms = append(allNewAtoms, atom)
-					newAtomsThisIteration++
+		}
+		
+		// If no applicable rule creates new atoms, we've reached fixpoint
+		if newAtomsThisIteration > 0 {
+			barren = map[string]bool{}
+			proof.progress()
+			continue
+		}
+		fixpoint := true
+		for name := range applicable {
+			if !barren[name] {
+				fixpoint = false
+				break
+			}
+		}
+		if fixpoint {
+			proof.reached = true
+			break
+		}
+	}
+	
+	return run.derived, nil
+}
+
+// runPipelined overlaps iterations: whenever a rule's results are merged,
+// the idle candidate rules fire against a fresh snapshot while slower rules
+// still work on older ones. Each snapshot counts as an iteration. The run
+// ends when no rule is in flight and every applicable rule is barren.
+func (ie *InferenceEngine) runPipelined(ctx context.Context, run *inferenceRun, selector RuleSelector, proof *fixpointProof, maxIterations int) error {
+	// The generation counts the merges that added atoms. A rule that fired on
+	// the snapshot of an older generation may yield on a fresh one, so it is
+	// only barren if nothing was added since its snapshot.
+	generation := 0
+	barren := make(map[string]bool)
+	inFlight := make(map[string]int) // rule -> generation of its snapshot
+	var err error
+	
+	for {
+		if err == nil {
+			err = ctx.Err()
+		}
+		queried := false
+		if err == nil && run.iterations < maxIterations {
+			queried = true
+			busy := make(map[string]bool, len(inFlight))
+			for name := range inFlight {
+				busy[name] = true
+			}
+			applicable, candidates, atoms, skipped := ie.candidates(run.tenantID, barren, busy)
+			proof.snapshot(skipped)
+			if len(atoms) > 0 && len(candidates) > 0 {
+				run.iterations++
+				budget.MeterFrom(ctx).AddIterations(1)
+				for _, name := range selector.Select(candidates) {
+					inFlight[name] = generation
+					ie.taskChan <- inferenceTask{
+						tenantID: run.tenantID,
+						atoms:    atoms,
+						rule:     applicable[name],
+						ctx:      ctx,
+						results:  run.results,
+					}
 				}
 			}
 		}
+		if len(inFlight) == 0 {
+			// Nothing could fire on the latest snapshot
+			proof.reached = queried
+			return err
+		}
 		
-		// If no new atoms were created, we've reached fixpoint
-		if newAtomsThisIteration == 0 {
-			break
+		// Merge results one at a time as they arrive
+		result := <-run.results
+		snapshot := inFlight[result.rule]
+		delete(inFlight, result.rule)
+		yield := run.merge([]inferenceResult{result})[result.rule]
+		selector.Observe(result.rule, yield)
+		if yield > 0 {
+			generation++
+			barren = map[string]bool{}
+			proof.progress()
+		} else if snapshot == generation {
+			barren[result.rule] = true
 		}
 	}
+}
+
+// candidates takes a snapshot of a tenant's atoms and returns the rules
+// applicable to it and, among them, those that may fire: a rule that fired
+// without adding an atom cannot add one before another rule does, and a
+// rule in flight is busy. It also reports whether rules were skipped by
+// their weight.
+func (ie *InferenceEngine) candidates(tenantID string, barren, busy map[string]bool) (map[string]InferenceRule, []string, []atomspace.Atom, bool) {
+	atoms := ie.atomSpace.QueryAtoms(tenantID, nil)
+	
+	ie.mu.RLock()
+	defer ie.mu.RUnlock()
 	
-	return allNewAtoms, nil
+	applicable := make(map[string]InferenceRule)
+	candidates := make([]string, 0, len(ie.rules))
+	skipped := false
+	if len(atoms) == 0 {
+		return applicable, candidates, atoms, skipped
+	}
+	for _, rule := range ie.rules {
+		if weight, weighted := ie.ruleWeights[rule.GetName()]; weighted && rand.Float64() >= weight {
+			skipped = true
+			continue
+		}
+		if rule.CanApply(atoms) {
+			applicable[rule.GetName()] = rule
+			if !barren[rule.GetName()] && !busy[rule.GetName()] {
+				candidates = append(candidates, rule.GetName())
+			}
+		}
+	}
+	return applicable, candidates, atoms, skipped
 }
 
 // Close shuts down the inference engine
@@ -175,6 +480,15 @@ func (r *DeductionRule) GetPriority() int {
 	return r.priority
 }
 
+// Signature chains inheritance links into inheritance links between the
+// same nodes
+func (r *DeductionRule) Signature() Signature {
+	return Signature{
+		Premises:    []Pattern{{Type: atomspace.InheritanceLinkType}},
+		Conclusions: []Pattern{{Type: atomspace.InheritanceLinkType}},
+	}
+}
+
 func (r *DeductionRule) CanApply(atoms []atomspace.Atom) bool {
 	// Check if we have at least one inheritance link and related nodes
 	hasInheritance := false
@@ -188,7 +502,12 @@ func (r *DeductionRule) CanApply(atoms []atomspace.Atom) bool {
 }
 
 func (r *DeductionRule) Apply(ctx context.Context, atoms []atomspace.Atom) ([]atomspace.Atom, error) {
-	var newAtoms []atomspace.Atom
+	return conclusionAtoms(r.ApplyJustified(ctx, atoms))
+}
+
+// ApplyJustified derives A->C from the links A->B and B->C
+func (r *DeductionRule) ApplyJustified(ctx context.Context, atoms []atomspace.Atom) ([]Conclusion, error) {
+	var newAtoms []Conclusion
 	
 	// Find inheritance links: A->B and B->C, infer A->C
 	inheritanceLinks := make([]*atomspace.Link, 0)
@@ -229,7 +548,7 @@ func (r *DeductionRule) Apply(ctx context.Context, atoms []atomspace.Atom) ([]at
 				}
 				newLink.SetTruthValue(newTV)
 				
-				newAtoms = append(newAtoms, newLink)
+				newAtoms = append(newAtoms, Conclusion{Atom: newLink, Premises: []string{link1.GetID(), link2.GetID()}})
 			}
 		}
 	}
@@ -254,6 +573,14 @@ func (r *InductionRule) GetPriority() int {
 	return r.priority
 }
 
+// Signature relates the sources of inheritance links by similarity links
+func (r *InductionRule) Signature() Signature {
+	return Signature{
+		Premises:    []Pattern{{Type: atomspace.InheritanceLinkType}},
+		Conclusions: []Pattern{{Type: atomspace.SimilarityLinkType}},
+	}
+}
+
 func (r *InductionRule) CanApply(atoms []atomspace.Atom) bool {
 	// Need multiple similar inheritance links to generalize
 	count := 0
@@ -266,7 +593,12 @@ func (r *InductionRule) CanApply(atoms []atomspace.Atom) bool {
 }
 
 func (r *InductionRule) Apply(ctx context.Context, atoms []atomspace.Atom) ([]atomspace.Atom, error) {
-	var newAtoms []atomspace.Atom
+	return conclusionAtoms(r.ApplyJustified(ctx, atoms))
+}
+
+// ApplyJustified derives A~B from the links A->C and B->C
+func (r *InductionRule) ApplyJustified(ctx context.Context, atoms []atomspace.Atom) ([]Conclusion, error) {
+	var newAtoms []Conclusion
 	
 	// Find common patterns in inheritance links
 	inheritanceLinks := make([]*atomspace.Link, 0)
@@ -308,7 +640,7 @@ func (r *InductionRule) Apply(ctx context.Context, atoms []atomspace.Atom) ([]at
 					}
 					newLink.SetTruthValue(newTV)
 					
-					newAtoms = append(newAtoms, newLink)
+					newAtoms = append(newAtoms, Conclusion{Atom: newLink, Premises: []string{group[i].GetID(), group[j].GetID()}})
 				}
 			}
 		}
@@ -334,6 +666,11 @@ func (r *AbductionRule) GetPriority() int {
 	return r.priority
 }
 
+// Signature reads inheritance links; hypotheses are not derived yet
+func (r *AbductionRule) Signature() Signature {
+	return Signature{Premises: []Pattern{{Type: atomspace.InheritanceLinkType}}}
+}
+
 func (r *AbductionRule) CanApply(atoms []atomspace.Atom) bool {
 	return len(atoms) >= 2
 }
@@ -0,0 +1,103 @@
+package inference
+
+import (
+	"sync"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// FixpointStats counts the use of the fixpoint cache
+type FixpointStats struct {
+	Hits   int64 `json:"hits"`   // Runs skipped as the atoms had not changed since a fixpoint
+	Misses int64 `json:"misses"` // Runs on atoms without a known fixpoint
+	Stored int64 `json:"stored"` // Fixpoints proven and cached
+}
+
+// fixpoint is the state of a tenant's atoms and of the rules at which every
+// applicable rule fired without adding an atom
+type fixpoint struct {
+	generation   uint64
+	rules        uint64
+	depthLimited bool // Reached by dropping derivations beyond the depth limit
+}
+
+// fixpointCache remembers the last fixpoint of each tenant, so running
+// inference again on unchanged atoms returns immediately
+type fixpointCache struct {
+	fixpoints map[string]fixpoint
+	stats     FixpointStats
+	mu        sync.Mutex
+}
+
+func newFixpointCache() *fixpointCache {
+	return &fixpointCache{fixpoints: make(map[string]fixpoint)}
+}
+
+// hit returns the fixpoint of a tenant, if it is known to be at one
+func (c *fixpointCache) hit(tenantID string, state fixpoint) (fixpoint, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if known, exists := c.fixpoints[tenantID]; exists && known.generation == state.generation && known.rules == state.rules {
+		c.stats.Hits++
+		return known, true
+	}
+	c.stats.Misses++
+	return fixpoint{}, false
+}
+
+func (c *fixpointCache) store(tenantID string, state fixpoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fixpoints[tenantID] = state
+	c.stats.Stored++
+}
+
+func (c *fixpointCache) get() FixpointStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// fixpointProof tracks whether a run proves a fixpoint: the rules found
+// barren must all have fired on the atoms of the same generation, and no
+// rule may have been skipped by its weight. The generation is taken at the
+// first snapshot after the last new atom; a later change of the atoms
+// voids the proof.
+type fixpointProof struct {
+	versioned  atomspace.Versioned // nil if the AtomSpace does not count changes
+	tenantID   string
+	generation uint64
+	fresh      bool // The next snapshot is the first since the last new atom
+	valid      bool
+	reached    bool // The run ended at a fixpoint rather than out of budget
+}
+
+// progress notes that atoms were added, which starts a new proof
+func (p *fixpointProof) progress() {
+	p.fresh = true
+}
+
+// snapshot notes that the atoms were queried for an iteration, and whether
+// weighted rules were skipped
+func (p *fixpointProof) snapshot(skipped bool) {
+	if p.versioned == nil {
+		return
+	}
+	if p.fresh {
+		p.generation = p.versioned.Generation(p.tenantID)
+		p.fresh = false
+		p.valid = true
+	}
+	if skipped {
+		p.valid = false
+	}
+}
+
+// proven returns the generation at which the run reached a fixpoint
+func (p *fixpointProof) proven() (uint64, bool) {
+	if p.versioned == nil || !p.reached || !p.valid || p.fresh {
+		return 0, false
+	}
+	return p.generation, p.versioned.Generation(p.tenantID) == p.generation
+}
@@ -0,0 +1,116 @@
+package inference
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// unversioned hides the generation of an AtomSpace
+type unversioned struct {
+	atomspace.AtomSpaceInterface
+}
+
+func TestFixpointCache(t *testing.T) {
+	space := atomspace.NewAtomSpace(1)
+	defer space.Close()
+	chain(t, space, 3)
+
+	ie := NewInferenceEngine(space, 2)
+	defer ie.Close()
+	ie.AddRule(NewDeductionRule())
+
+	if _, err := ie.RunInference(context.Background(), "t", 10); err != nil {
+		t.Fatalf("RunInference failed: %v", err)
+	}
+	if stats := ie.GetFixpointStats(); stats.Misses != 1 || stats.Stored != 1 {
+		t.Fatalf("Expected the fixpoint to be cached, got %+v", stats)
+	}
+	firings := ie.GetSelector().Yields()["deduction"].Firings
+
+	again, err := ie.RunInference(context.Background(), "t", 10)
+	if err != nil || len(again) != 0 {
+		t.Fatalf("Expected no atoms from a cached fixpoint, got %d: %v", len(again), err)
+	}
+	if stats := ie.GetFixpointStats(); stats.Hits != 1 || ie.GetSelector().Yields()["deduction"].Firings != firings {
+		t.Errorf("Expected a cache hit without firing rules, got %+v", stats)
+	}
+
+	// Another tenant has its own fixpoint
+	if _, err := ie.RunInference(context.Background(), "other", 10); err != nil {
+		t.Fatalf("RunInference failed: %v", err)
+	}
+	if stats := ie.GetFixpointStats(); stats.Misses != 2 {
+		t.Errorf("Expected a miss for another tenant, got %+v", stats)
+	}
+
+	// Extending the chain invalidates the fixpoint
+	chainLink(t, space, 3, 4)
+	derived, _ := ie.RunInference(context.Background(), "t", 10)
+	if len(derived) != 3 {
+		t.Errorf("Expected the 3 links reaching c4, got %d", len(derived))
+	}
+
+	// So do new rules
+	ie.AddRule(NewInductionRule())
+	if derived, _ := ie.RunInference(context.Background(), "t", 10); len(derived) == 0 {
+		t.Error("Expected the new rule to derive atoms")
+	}
+	if stats := ie.GetFixpointStats(); stats.Hits != 1 || stats.Misses != 4 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestFixpointNotProven(t *testing.T) {
+	space := atomspace.NewAtomSpace(1)
+	defer space.Close()
+	chain(t, space, 4)
+
+	ie := NewInferenceEngine(space, 2)
+	defer ie.Close()
+	ie.AddRule(NewDeductionRule())
+
+	// Running out of iterations proves nothing
+	ie.RunInference(context.Background(), "t", 1)
+	if stats := ie.GetFixpointStats(); stats.Stored != 0 {
+		t.Errorf("Expected no fixpoint after running out of iterations, got %+v", stats)
+	}
+
+	// Neither does a run in which a rule was skipped by its weight
+	ie.SetRuleWeight("deduction", 0)
+	ie.RunInference(context.Background(), "t", 10)
+	if stats := ie.GetFixpointStats(); stats.Stored != 0 {
+		t.Errorf("Expected no fixpoint with weighted rules, got %+v", stats)
+	}
+
+	// Nor without generations
+	plain := NewInferenceEngine(unversioned{space}, 2)
+	defer plain.Close()
+	plain.AddRule(NewDeductionRule())
+	plain.RunInference(context.Background(), "t", 10)
+	plain.RunInference(context.Background(), "t", 10)
+	if stats := plain.GetFixpointStats(); stats != (FixpointStats{}) {
+		t.Errorf("Expected no caching without generations, got %+v", stats)
+	}
+}
+
+func TestPipelinedFixpointCache(t *testing.T) {
+	space := atomspace.NewAtomSpace(1)
+	defer space.Close()
+	chain(t, space, 4)
+
+	ie := NewInferenceEngine(space, 2)
+	defer ie.Close()
+	ie.AddRule(NewDeductionRule())
+	ie.AddRule(NewInductionRule())
+	ie.SetPipelined(true)
+
+	ie.RunInference(context.Background(), "t", 50)
+	if again, _ := ie.RunInference(context.Background(), "t", 50); len(again) != 0 {
+		t.Errorf("Expected a fixpoint, got %d atoms", len(again))
+	}
+	if stats := ie.GetFixpointStats(); stats.Stored != 1 || stats.Hits != 1 {
+		t.Errorf("Expected the pipelined fixpoint to be cached, got %+v", stats)
+	}
+}
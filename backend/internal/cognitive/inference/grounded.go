@@ -0,0 +1,268 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// GroundedRuleName is the name of the rule evaluating grounded predicates
+const GroundedRuleName = "grounded"
+
+// Grounded predicates, evaluated as EvaluationLink(GroundedPredicateNode,
+// arguments...)
+const (
+	PredicateGreaterThan    = "greater_than"     // a > b
+	PredicateGreaterOrEqual = "greater_or_equal" // a >= b
+	PredicateLessThan       = "less_than"        // a < b
+	PredicateLessOrEqual    = "less_or_equal"    // a <= b
+	PredicateEqual          = "equal"            // a == b
+	PredicateThreshold      = "threshold"        // x ramping from low to high
+)
+
+// Grounded functions, computed as ExecutionLink(GroundedPredicateNode,
+// arguments...) where a number is expected
+const (
+	FunctionSum        = "sum"
+	FunctionDifference = "difference"
+	FunctionProduct    = "product"
+	FunctionQuotient   = "quotient"
+	FunctionMin        = "min"
+	FunctionMax        = "max"
+)
+
+// MaxNesting bounds how deep grounded function calls may nest
+const MaxNesting = 16
+
+// equalTolerance is how close numbers must be to be equal
+const equalTolerance = 1e-9
+
+// IsGroundedPredicate reports whether name is a grounded predicate
+func IsGroundedPredicate(name string) bool {
+	switch name {
+	case PredicateGreaterThan, PredicateGreaterOrEqual, PredicateLessThan, PredicateLessOrEqual, PredicateEqual, PredicateThreshold:
+		return true
+	}
+	return false
+}
+
+// IsGroundedFunction reports whether name is a grounded function
+func IsGroundedFunction(name string) bool {
+	switch name {
+	case FunctionSum, FunctionDifference, FunctionProduct, FunctionQuotient, FunctionMin, FunctionMax:
+		return true
+	}
+	return false
+}
+
+// groundedCall returns the grounded predicate or function a link applies
+// and its arguments
+func groundedCall(atom atomspace.Atom, linkType atomspace.AtomType) (string, []atomspace.Atom, bool) {
+	link, ok := atom.(*atomspace.Link)
+	if !ok || link.GetType() != linkType || len(link.Outgoing) < 2 || link.Outgoing[0].GetType() != atomspace.GroundedPredicateNodeType {
+		return "", nil, false
+	}
+	return link.Outgoing[0].GetName(), link.Outgoing[1:], true
+}
+
+// IsGroundedEvaluation reports whether an atom is an evaluation of a
+// grounded predicate
+func IsGroundedEvaluation(atom atomspace.Atom) bool {
+	name, _, ok := groundedCall(atom, atomspace.EvaluationLinkType)
+	return ok && IsGroundedPredicate(name)
+}
+
+// Evaluate computes the truth value of an evaluation of a grounded
+// predicate. Comparisons are true or false; a threshold ramps linearly from
+// false at low to true at high. The confidence is the lowest confidence of
+// the arguments.
+func Evaluate(atom atomspace.Atom) (atomspace.TruthValue, error) {
+	name, args, ok := groundedCall(atom, atomspace.EvaluationLinkType)
+	if !ok || !IsGroundedPredicate(name) {
+		return atomspace.TruthValue{}, fmt.Errorf("atom %s is not an evaluation of a grounded predicate", atom.GetID())
+	}
+	values, confidence, err := computeAll(args, 0)
+	if err != nil {
+		return atomspace.TruthValue{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	var strength float64
+	switch name {
+	case PredicateThreshold:
+		if len(values) != 3 {
+			return atomspace.TruthValue{}, fmt.Errorf("%s takes 3 arguments, got %d", name, len(values))
+		}
+		x, low, high := values[0], values[1], values[2]
+		if high <= low {
+			return atomspace.TruthValue{}, fmt.Errorf("%s needs low below high", name)
+		}
+		strength = math.Max(0, math.Min(1, (x-low)/(high-low)))
+	default:
+		if len(values) != 2 {
+			return atomspace.TruthValue{}, fmt.Errorf("%s takes 2 arguments, got %d", name, len(values))
+		}
+		if compare(name, values[0], values[1]) {
+			strength = 1
+		}
+	}
+	return atomspace.TruthValue{Strength: strength, Confidence: confidence}, nil
+}
+
+func compare(predicate string, a, b float64) bool {
+	switch predicate {
+	case PredicateGreaterThan:
+		return a > b
+	case PredicateGreaterOrEqual:
+		return a >= b
+	case PredicateLessThan:
+		return a < b
+	case PredicateLessOrEqual:
+		return a <= b
+	}
+	return math.Abs(a-b) <= equalTolerance
+}
+
+// Compute returns the number an argument of a grounded predicate stands for
+// and the confidence in it:
+//   - a NumberNode is its value
+//   - an ExecutionLink of a grounded function is the function's result
+//   - any other link ending in a NumberNode is a measurement of that value,
+//     e.g. EvaluationLink(cpu_usage, host, NumberNode 0.93), as confident as
+//     the link
+func Compute(atom atomspace.Atom) (float64, float64, error) {
+	return compute(atom, 0)
+}
+
+func compute(atom atomspace.Atom, depth int) (float64, float64, error) {
+	if depth > MaxNesting {
+		return 0, 0, fmt.Errorf("functions nest deeper than %d", MaxNesting)
+	}
+	if value, ok := atomspace.NumberValue(atom); ok {
+		return value, atom.GetTruthValue().Confidence, nil
+	}
+	if name, args, ok := groundedCall(atom, atomspace.ExecutionLinkType); ok && IsGroundedFunction(name) {
+		values, confidence, err := computeAll(args, depth+1)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%s: %w", name, err)
+		}
+		value, err := apply(name, values)
+		return value, confidence, err
+	}
+	if link, ok := atom.(*atomspace.Link); ok && len(link.Outgoing) > 0 {
+		if value, ok := atomspace.NumberValue(link.Outgoing[len(link.Outgoing)-1]); ok {
+			return value, link.GetTruthValue().Confidence, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("atom %s is not a number", atom.GetID())
+}
+
+// computeAll computes arguments and the lowest confidence among them
+func computeAll(args []atomspace.Atom, depth int) ([]float64, float64, error) {
+	values := make([]float64, len(args))
+	confidence := 1.0
+	for i, arg := range args {
+		value, c, err := compute(arg, depth)
+		if err != nil {
+			return nil, 0, err
+		}
+		values[i] = value
+		confidence = math.Min(confidence, c)
+	}
+	return values, confidence, nil
+}
+
+func apply(function string, values []float64) (float64, error) {
+	switch function {
+	case FunctionDifference, FunctionQuotient:
+		if len(values) != 2 {
+			return 0, fmt.Errorf("%s takes 2 arguments, got %d", function, len(values))
+		}
+		if function == FunctionDifference {
+			return values[0] - values[1], nil
+		}
+		if values[1] == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return values[0] / values[1], nil
+	}
+
+	result := values[0]
+	for _, value := range values[1:] {
+		switch function {
+		case FunctionSum:
+			result += value
+		case FunctionProduct:
+			result *= value
+		case FunctionMin:
+			result = math.Min(result, value)
+		case FunctionMax:
+			result = math.Max(result, value)
+		}
+	}
+	return result, nil
+}
+
+// GroundedRule executes grounded predicates: it sets the truth value of
+// each EvaluationLink(GroundedPredicateNode, arguments...) to what the
+// predicate computes, so rules can state e.g. cpu_usage > 0.9 natively.
+// Evaluations whose arguments are not numbers are left alone. The rule is
+// authoritative: its truth values replace the ones links were stated with.
+type GroundedRule struct {
+	priority int
+}
+
+func NewGroundedRule() *GroundedRule {
+	return &GroundedRule{priority: 12}
+}
+
+func (r *GroundedRule) GetName() string {
+	return GroundedRuleName
+}
+
+func (r *GroundedRule) GetPriority() int {
+	return r.priority
+}
+
+func (r *GroundedRule) Authoritative() bool {
+	return true
+}
+
+// Signature lists no conclusions: the rule only sets the truth values of
+// its premises from their numeric arguments, so no rule feeds it
+func (r *GroundedRule) Signature() Signature {
+	return Signature{Premises: []Pattern{
+		{Type: atomspace.EvaluationLinkType},
+		{Type: atomspace.GroundedPredicateNodeType},
+	}}
+}
+
+func (r *GroundedRule) CanApply(atoms []atomspace.Atom) bool {
+	for _, atom := range atoms {
+		if IsGroundedEvaluation(atom) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *GroundedRule) Apply(ctx context.Context, atoms []atomspace.Atom) ([]atomspace.Atom, error) {
+	var evaluated []atomspace.Atom
+	for _, atom := range atoms {
+		if !IsGroundedEvaluation(atom) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return evaluated, err
+		}
+		tv, err := Evaluate(atom)
+		if err != nil || sameTruth(tv, atom.GetTruthValue()) {
+			continue
+		}
+		result := atom.Clone()
+		result.SetTruthValue(tv)
+		evaluated = append(evaluated, result)
+	}
+	return evaluated, nil
+}
@@ -0,0 +1,113 @@
+package inference
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func groundedLink(linkType atomspace.AtomType, name string, args ...atomspace.Atom) *atomspace.Link {
+	outgoing := append([]atomspace.Atom{atomspace.NewGroundedPredicateNode(name, "t")}, args...)
+	return atomspace.NewLink(atomspace.GenerateAtomID(linkType, name, outgoing), name, "t", linkType, outgoing)
+}
+
+func number(value float64) atomspace.Atom {
+	return atomspace.NewNumberNode(value, "t")
+}
+
+// measurement is cpu_usage(host, value), as confident as given
+func measurement(host string, value, confidence float64) *atomspace.Link {
+	pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, "cpu_usage", nil), "cpu_usage", "t", atomspace.PredicateNodeType)
+	node := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, host, nil), host, "t", atomspace.ConceptNodeType)
+	outgoing := []atomspace.Atom{pred, node, number(value)}
+	link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, "cpu_usage", outgoing), "cpu_usage", "t", atomspace.EvaluationLinkType, outgoing)
+	link.SetTruthValue(atomspace.TruthValue{Strength: 1, Confidence: confidence})
+	return link
+}
+
+func TestEvaluate(t *testing.T) {
+	cpu := measurement("web-1", 0.95, 0.8)
+	for _, c := range []struct {
+		link     *atomspace.Link
+		strength float64
+	}{
+		{groundedLink(atomspace.EvaluationLinkType, PredicateGreaterThan, cpu, number(0.9)), 1},
+		{groundedLink(atomspace.EvaluationLinkType, PredicateLessThan, cpu, number(0.9)), 0},
+		{groundedLink(atomspace.EvaluationLinkType, PredicateEqual, number(0.3), groundedLink(atomspace.ExecutionLinkType, FunctionSum, number(0.1), number(0.2))), 1},
+		{groundedLink(atomspace.EvaluationLinkType, PredicateThreshold, cpu, number(0.9), number(1)), 0.5},
+		{groundedLink(atomspace.EvaluationLinkType, PredicateGreaterOrEqual,
+			groundedLink(atomspace.ExecutionLinkType, FunctionQuotient, number(3), groundedLink(atomspace.ExecutionLinkType, FunctionMax, number(1), number(4))), number(0.75)), 1},
+	} {
+		tv, err := Evaluate(c.link)
+		if err != nil {
+			t.Fatalf("Failed to evaluate %s: %v", c.link.GetName(), err)
+		}
+		if tv.Strength < c.strength-1e-9 || tv.Strength > c.strength+1e-9 {
+			t.Errorf("Expected %s to have strength %v, got %v", c.link.GetName(), c.strength, tv.Strength)
+		}
+	}
+
+	tv, _ := Evaluate(groundedLink(atomspace.EvaluationLinkType, PredicateGreaterThan, cpu, number(0.9)))
+	if tv.Confidence != 0.8 {
+		t.Errorf("Expected the confidence of the measurement, got %v", tv.Confidence)
+	}
+
+	concept := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, "web-1", nil), "web-1", "t", atomspace.ConceptNodeType)
+	for _, invalid := range []*atomspace.Link{
+		groundedLink(atomspace.EvaluationLinkType, PredicateGreaterThan, concept, number(0.9)),
+		groundedLink(atomspace.EvaluationLinkType, PredicateGreaterThan, number(1)),
+		groundedLink(atomspace.EvaluationLinkType, PredicateThreshold, cpu, number(1), number(0.9)),
+		groundedLink(atomspace.EvaluationLinkType, PredicateEqual, groundedLink(atomspace.ExecutionLinkType, FunctionQuotient, number(1), number(0)), number(1)),
+		groundedLink(atomspace.EvaluationLinkType, "unknown", number(1), number(1)),
+	} {
+		if _, err := Evaluate(invalid); err == nil {
+			t.Errorf("Expected %s rejected", invalid.GetName())
+		}
+	}
+}
+
+func TestGroundedRule(t *testing.T) {
+	space := atomspace.NewAtomSpace(1)
+	defer space.Close()
+
+	cpu := measurement("web-1", 0.95, 0.8)
+	high := groundedLink(atomspace.EvaluationLinkType, PredicateGreaterThan, cpu, number(0.9))
+	low := groundedLink(atomspace.EvaluationLinkType, PredicateLessThan, cpu, number(0.5))
+	for _, link := range []*atomspace.Link{cpu, high, low} {
+		for _, atom := range append(link.Outgoing, link) {
+			space.AddAtom(atom)
+		}
+	}
+
+	ie := NewInferenceEngine(space, 2)
+	defer ie.Close()
+	ie.AddRule(NewGroundedRule())
+
+	derived, err := ie.RunInference(context.Background(), "t", 5)
+	if err != nil {
+		t.Fatalf("RunInference failed: %v", err)
+	}
+	if len(derived) != 0 {
+		t.Errorf("Expected evaluations to update atoms rather than derive new ones, got %d", len(derived))
+	}
+
+	// Both were stated true; the rule replaces their truth values
+	if atom, _ := space.GetAtom(high.GetID(), "t"); atom.GetTruthValue() != (atomspace.TruthValue{Strength: 1, Confidence: 0.8}) {
+		t.Errorf("Expected cpu > 0.9 true with the measurement's confidence, got %+v", atom.GetTruthValue())
+	}
+	if atom, _ := space.GetAtom(low.GetID(), "t"); atom.GetTruthValue().Strength != 0 {
+		t.Errorf("Expected cpu < 0.5 false, got %+v", atom.GetTruthValue())
+	}
+	if stats := ie.GetMergeStats(); stats.Replaced != 2 || stats.Rejected != 0 {
+		t.Errorf("Expected both evaluations to replace truth values, got %+v", stats)
+	}
+
+	// Evaluated atoms are stable
+	if _, err := ie.RunInference(context.Background(), "t", 5); err != nil {
+		t.Fatalf("RunInference failed: %v", err)
+	}
+	if stats := ie.GetMergeStats(); stats.Replaced != 2 {
+		t.Errorf("Expected no further replacements, got %+v", stats)
+	}
+}
@@ -0,0 +1,263 @@
+package inference
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// maxConfidence keeps the evidence count of a truth value finite
+const maxConfidence = 0.9999
+
+// Revise merges two truth values of the same atom derived from independent
+// evidence (PLN revision). Confidence c stands for the evidence count
+// n = c/(1-c); strengths are averaged weighted by evidence and the revised
+// confidence reflects the evidence of both.
+func Revise(a, b atomspace.TruthValue) atomspace.TruthValue {
+	na, nb := evidence(a.Confidence), evidence(b.Confidence)
+	if na+nb == 0 {
+		return atomspace.TruthValue{Strength: (a.Strength + b.Strength) / 2}
+	}
+	n := na + nb
+	return atomspace.TruthValue{
+		Strength:   (na*a.Strength + nb*b.Strength) / n,
+		Confidence: n / (n + 1),
+	}
+}
+
+func evidence(confidence float64) float64 {
+	if confidence > maxConfidence {
+		confidence = maxConfidence
+	}
+	if confidence <= 0 {
+		return 0
+	}
+	return confidence / (1 - confidence)
+}
+
+// MergeStats counts what the merge phase of inference resolved
+type MergeStats struct {
+	Derived    int64 `json:"derived"`    // Atoms added
+	Duplicates int64 `json:"duplicates"` // Derivations of an atom with the same truth value
+	Conflicts  int64 `json:"conflicts"`  // Derivations of an atom with differing truth values
+	Revisions  int64 `json:"revisions"`  // Atoms derived earlier in the run revised with new evidence
+	Rejected   int64 `json:"rejected"`   // Conflicts with atoms not derived in the run, which are kept
+	Replaced   int64 `json:"replaced"`   // Conflicts an authoritative rule resolved by replacing the atom's truth value
+}
+
+// Authoritative is implemented by rules that compute truth values exactly,
+// such as evaluations of grounded predicates. Their derivations of an
+// existing atom replace its truth value instead of conflicting with it.
+type Authoritative interface {
+	Authoritative() bool
+}
+
+type mergeCounters struct {
+	stats MergeStats
+	mu    sync.Mutex
+}
+
+func (c *mergeCounters) add(s MergeStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Derived += s.Derived
+	c.stats.Duplicates += s.Duplicates
+	c.stats.Conflicts += s.Conflicts
+	c.stats.Revisions += s.Revisions
+	c.stats.Rejected += s.Rejected
+	c.stats.Replaced += s.Replaced
+}
+
+func (c *mergeCounters) get() MergeStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// derivation is an atom derived by one or more rules in a merge
+type derivation struct {
+	atom  atomspace.Atom
+	tv    atomspace.TruthValue
+	rules []string         // Deriving rules, the first one credited with the atom
+	chain *derivationChain // Shallowest derivation chain, preferring one without a cycle
+}
+
+// inferenceRun is the state of one RunInference call. Rules apply against
+// a frozen snapshot of the atoms, so results of rules fired in parallel, or
+// fired on an older snapshot while pipelining, may derive the same atom
+// twice; the merge phase reconciles them.
+type inferenceRun struct {
+	ie       *InferenceEngine
+	tenantID string
+	priority map[string]int
+	// Rules whose derivations replace the truth value of existing atoms
+	authoritative map[string]bool
+	onDerived     func(tenantID, rule string, atom atomspace.Atom)
+	results       chan inferenceResult
+
+	derived []atomspace.Atom
+	// Rules whose evidence is already part of the truth value of each atom
+	// derived in this run; a rule's evidence is never counted twice
+	evidence map[string]map[string]bool
+	stats    MergeStats
+
+	// Derivation chains of the atoms derived in this run, and what the
+	// limits dropped
+	limits       Limits
+	chains       map[string]*derivationChain
+	deepest      int
+	depthLimited int
+	cycles       int
+	iterations   int
+}
+
+// merge adds the atoms derived by a batch of results to the AtomSpace and
+// returns how many atoms each rule added. Derivations of the same atom are
+// merged first: identical truth values are duplicates and differing ones
+// are revised together. An atom derived earlier in the run is revised with
+// the evidence of rules that did not contribute to it yet; a conflict with
+// an atom that existed before the run keeps the existing atom, unless an
+// authoritative rule is credited with the derivation. Replacing a truth
+// value counts as a yield of the rule.
+func (run *inferenceRun) merge(results []inferenceResult) map[string]int {
+	// Merge in rule priority order so the outcome does not depend on the
+	// order in which workers finished
+	sort.SliceStable(results, func(i, j int) bool {
+		if run.priority[results[i].rule] != run.priority[results[j].rule] {
+			return run.priority[results[i].rule] > run.priority[results[j].rule]
+		}
+		return results[i].rule < results[j].rule
+	})
+
+	yields := make(map[string]int, len(results))
+	order := make([]string, 0)
+	byID := make(map[string]*derivation)
+	for _, result := range results {
+		if result.err != nil {
+			continue
+		}
+		for _, atom := range result.newAtoms {
+			// Conclusions drawn from shared knowledge belong to the tenant
+			if atom.GetTenantID() != run.tenantID {
+				atom = atomspace.WithTenant(atom, run.tenantID)
+			}
+			tv := atom.GetTruthValue()
+			premises, reported := result.premises[atom.GetID()]
+			c := run.chainOf(premises, reported)
+			d, exists := byID[atom.GetID()]
+			if !exists {
+				byID[atom.GetID()] = &derivation{atom: atom, tv: tv, rules: []string{result.rule}, chain: c}
+				order = append(order, atom.GetID())
+				continue
+			}
+			if c.ancestors[atom.GetID()] == d.chain.ancestors[atom.GetID()] && c.depth < d.chain.depth || d.chain.ancestors[atom.GetID()] && !c.ancestors[atom.GetID()] {
+				d.chain = c
+			}
+			if sameTruth(tv, d.tv) {
+				run.stats.Duplicates++
+			} else {
+				run.stats.Conflicts++
+				d.tv = Revise(d.tv, tv)
+			}
+			if !contains(d.rules, result.rule) {
+				d.rules = append(d.rules, result.rule)
+			}
+		}
+	}
+
+	for _, id := range order {
+		d := byID[id]
+		d.atom.SetTruthValue(d.tv)
+		if !run.admit(id, d.chain) {
+			continue
+		}
+		if err := run.ie.atomSpace.AddAtom(d.atom); err == nil {
+			run.stats.Derived++
+			run.chains[id] = d.chain
+			if d.chain.depth > run.deepest {
+				run.deepest = d.chain.depth
+			}
+			run.derived = append(run.derived, d.atom)
+			run.evidence[id] = make(map[string]bool, len(d.rules))
+			for _, rule := range d.rules {
+				run.evidence[id][rule] = true
+			}
+			yields[d.rules[0]]++
+			if run.onDerived != nil {
+				run.onDerived(run.tenantID, d.rules[0], d.atom)
+			}
+			continue
+		}
+		if run.reconcile(id, d) {
+			yields[d.rules[0]]++
+		}
+	}
+	return yields
+}
+
+// reconcile merges a derivation of an atom that already exists and reports
+// whether an authoritative rule replaced its truth value
+func (run *inferenceRun) reconcile(id string, d *derivation) bool {
+	existing, err := run.ie.atomSpace.GetAtom(id, run.tenantID)
+	if err != nil || sameTruth(existing.GetTruthValue(), d.tv) {
+		run.stats.Duplicates++
+		return false
+	}
+	run.stats.Conflicts++
+
+	if run.authoritative[d.rules[0]] {
+		err = run.ie.atomSpace.UpdateAtom(id, run.tenantID, func(atom atomspace.Atom) error {
+			atom.SetTruthValue(d.tv)
+			return nil
+		})
+		if err != nil {
+			return false
+		}
+		run.stats.Replaced++
+		return true
+	}
+
+	contributed, derivedInRun := run.evidence[id]
+	if !derivedInRun {
+		run.stats.Rejected++
+		return false
+	}
+	fresh := make([]string, 0, len(d.rules))
+	for _, rule := range d.rules {
+		if !contributed[rule] {
+			fresh = append(fresh, rule)
+		}
+	}
+	if len(fresh) == 0 {
+		return false
+	}
+	err = run.ie.atomSpace.UpdateAtom(id, run.tenantID, func(atom atomspace.Atom) error {
+		atom.SetTruthValue(Revise(atom.GetTruthValue(), d.tv))
+		return nil
+	})
+	if err != nil {
+		return false
+	}
+	run.stats.Revisions++
+	for _, rule := range fresh {
+		contributed[rule] = true
+	}
+	return false
+}
+
+// sameTruth compares truth values up to rounding, as revising the same
+// derivations in another order may differ in the last bits
+func sameTruth(a, b atomspace.TruthValue) bool {
+	return math.Abs(a.Strength-b.Strength) < 1e-9 && math.Abs(a.Confidence-b.Confidence) < 1e-9
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
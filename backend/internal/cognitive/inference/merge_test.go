@@ -0,0 +1,152 @@
+package inference
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// fixedRule derives one concept with a fixed truth value, after a delay
+type fixedRule struct {
+	name    string
+	concept string
+	tv      atomspace.TruthValue
+	delay   time.Duration
+}
+
+func (r *fixedRule) GetName() string                      { return r.name }
+func (r *fixedRule) GetPriority() int                     { return 1 }
+func (r *fixedRule) CanApply(atoms []atomspace.Atom) bool { return true }
+
+func (r *fixedRule) Apply(ctx context.Context, atoms []atomspace.Atom) ([]atomspace.Atom, error) {
+	time.Sleep(r.delay)
+	node := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, r.concept, nil), r.concept, "t", atomspace.ConceptNodeType)
+	node.SetTruthValue(r.tv)
+	return []atomspace.Atom{node}, nil
+}
+
+func TestRevise(t *testing.T) {
+	tv := Revise(atomspace.TruthValue{Strength: 1, Confidence: 0.5}, atomspace.TruthValue{Strength: 0, Confidence: 0.5})
+	if tv.Strength != 0.5 || tv.Confidence <= 0.5 {
+		t.Errorf("Expected equal evidence to average strengths and add up, got %+v", tv)
+	}
+
+	tv = Revise(atomspace.TruthValue{Strength: 1, Confidence: 0.9}, atomspace.TruthValue{Strength: 0, Confidence: 0.1})
+	if tv.Strength < 0.9 || tv.Confidence <= 0.9 || tv.Confidence >= 1 {
+		t.Errorf("Expected the stronger evidence to dominate, got %+v", tv)
+	}
+
+	if tv := Revise(atomspace.TruthValue{Strength: 1, Confidence: 1}, atomspace.TruthValue{Strength: 1, Confidence: 1}); tv.Confidence >= 1 {
+		t.Errorf("Expected confidence to stay below 1, got %+v", tv)
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	space := atomspace.NewAtomSpace(1)
+	defer space.Close()
+	seed := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, "seed", nil), "seed", "t", atomspace.ConceptNodeType)
+	space.AddAtom(seed)
+
+	ie := NewInferenceEngine(space, 4)
+	defer ie.Close()
+	ie.AddRule(&fixedRule{name: "a", concept: "x", tv: atomspace.TruthValue{Strength: 0.9, Confidence: 0.5}})
+	ie.AddRule(&fixedRule{name: "b", concept: "x", tv: atomspace.TruthValue{Strength: 0.3, Confidence: 0.5}})
+	ie.AddRule(&fixedRule{name: "c", concept: "x", tv: atomspace.TruthValue{Strength: 0.3, Confidence: 0.5}})
+	ie.AddRule(&fixedRule{name: "d", concept: "seed", tv: atomspace.TruthValue{Strength: 0.1, Confidence: 0.1}})
+
+	var credited []string
+	ie.OnDerived(func(tenantID, rule string, atom atomspace.Atom) { credited = append(credited, rule) })
+
+	derived, err := ie.RunInference(context.Background(), "t", 5)
+	if err != nil {
+		t.Fatalf("RunInference failed: %v", err)
+	}
+	if len(derived) != 1 || len(credited) != 1 || credited[0] != "a" {
+		t.Fatalf("Expected one atom credited to the first rule, got %d atoms credited to %v", len(derived), credited)
+	}
+
+	x, _ := space.GetAtom(derived[0].GetID(), "t")
+	if tv := x.GetTruthValue(); tv.Strength <= 0.3 || tv.Strength >= 0.9 || tv.Confidence <= 0.5 {
+		t.Errorf("Expected the conflicting derivations to be revised, got %+v", tv)
+	}
+	if tv, _ := space.GetAtom(seed.GetID(), "t"); tv.GetTruthValue().Strength == 0.1 {
+		t.Error("Expected an atom that existed before the run to be kept")
+	}
+
+	// Each of the 2 iterations merges 2 conflicting derivations of x and
+	// rejects a conflicting seed; the second one derives x as it was added
+	stats := ie.GetMergeStats()
+	if stats.Derived != 1 || stats.Revisions != 0 || stats.Rejected != 2 || stats.Conflicts != 6 || stats.Duplicates != 1 {
+		t.Errorf("Unexpected merge stats: %+v", stats)
+	}
+}
+
+func TestMergeRevisesEarlierDerivations(t *testing.T) {
+	space := atomspace.NewAtomSpace(1)
+	defer space.Close()
+	space.AddAtom(atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, "seed", nil), "seed", "t", atomspace.ConceptNodeType))
+
+	ie := NewInferenceEngine(space, 2)
+	defer ie.Close()
+	ie.AddRule(&fixedRule{name: "fast", concept: "x", tv: atomspace.TruthValue{Strength: 0.9, Confidence: 0.5}})
+	ie.AddRule(&fixedRule{name: "slow", concept: "x", tv: atomspace.TruthValue{Strength: 0.1, Confidence: 0.5}, delay: 50 * time.Millisecond})
+	ie.SetPipelined(true)
+
+	derived, err := ie.RunInference(context.Background(), "t", 10)
+	if err != nil {
+		t.Fatalf("RunInference failed: %v", err)
+	}
+	if len(derived) != 1 {
+		t.Fatalf("Expected one derived atom, got %d", len(derived))
+	}
+
+	// fast adds x on its own; slow's conflicting result arrives later and
+	// revises it once
+	x, _ := space.GetAtom(derived[0].GetID(), "t")
+	if tv := x.GetTruthValue(); tv.Strength < 0.49 || tv.Strength > 0.51 || tv.Confidence <= 0.5 {
+		t.Errorf("Expected x to be revised with both derivations, got %+v", tv)
+	}
+	if stats := ie.GetMergeStats(); stats.Revisions != 1 {
+		t.Errorf("Expected one revision, got %+v", stats)
+	}
+}
+
+func TestRunInferencePipelined(t *testing.T) {
+	space := atomspace.NewAtomSpace(1)
+	defer space.Close()
+	chain(t, space, 5)
+
+	ie := NewInferenceEngine(space, 4)
+	defer ie.Close()
+	ie.AddRule(NewDeductionRule())
+	ie.AddRule(NewInductionRule())
+	ie.SetPipelined(true)
+
+	derived, err := ie.RunInference(context.Background(), "t", 50)
+	if err != nil {
+		t.Fatalf("RunInference failed: %v", err)
+	}
+	links := 0
+	for _, atom := range derived {
+		if atom.GetType() == atomspace.InheritanceLinkType {
+			links++
+		}
+	}
+	// The closure of a chain of 5 links adds the 10 links spanning 2 to 5 hops
+	if links != 10 {
+		t.Errorf("Expected 10 derived inheritance links, got %d", links)
+	}
+
+	again, _ := ie.RunInference(context.Background(), "t", 50)
+	if len(again) != 0 {
+		t.Errorf("Expected a fixpoint, got %d atoms", len(again))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ie.RunInference(ctx, "t", 50); err != context.Canceled {
+		t.Errorf("Expected a cancelled run to fail, got %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package inference
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// Rule selection strategies
+const (
+	StrategyAll           = "all"            // Fire every applicable rule in each iteration
+	StrategyEpsilonGreedy = "epsilon-greedy" // Fire the best yielding rules, exploring with probability Epsilon
+	StrategyUCB           = "ucb"            // Fire the rules with the highest upper confidence bound on their yield
+)
+
+// RuleSelector chooses which of the applicable rules fire in an inference
+// iteration and learns from the number of atoms each firing yielded
+type RuleSelector interface {
+	Select(candidates []string) []string
+	Observe(rule string, yield int)
+	Yields() map[string]RuleYield
+}
+
+// RuleYield is what firing a rule has produced
+type RuleYield struct {
+	Firings int64   `json:"firings"`
+	Total   int64   `json:"total"`  // Atoms added
+	Recent  float64 `json:"recent"` // Exponentially weighted mean of atoms added per firing
+}
+
+// SelectionConfig configures a rule selector
+type SelectionConfig struct {
+	Strategy          string  `json:"strategy"`
+	RulesPerIteration int     `json:"rules_per_iteration"` // Bandit strategies only
+	Epsilon           float64 `json:"epsilon"`             // Exploration probability of epsilon-greedy
+	Exploration       float64 `json:"exploration"`         // Weight of the UCB confidence bound
+	Decay             float64 `json:"decay"`               // Weight of the latest firing in the recent yield
+}
+
+// DefaultSelectionConfig returns the default configuration, which fires
+// every applicable rule
+func DefaultSelectionConfig() SelectionConfig {
+	return SelectionConfig{
+		Strategy:          StrategyAll,
+		RulesPerIteration: 1,
+		Epsilon:           0.1,
+		Exploration:       1,
+		Decay:             0.3,
+	}
+}
+
+// Validate checks the configuration
+func (c *SelectionConfig) Validate() error {
+	switch c.Strategy {
+	case StrategyAll, StrategyEpsilonGreedy, StrategyUCB:
+	default:
+		return fmt.Errorf("unknown rule selection strategy: %s", c.Strategy)
+	}
+	if c.RulesPerIteration < 1 {
+		return fmt.Errorf("rules_per_iteration must be at least 1")
+	}
+	if c.Epsilon < 0 || c.Epsilon > 1 {
+		return fmt.Errorf("epsilon must be between 0 and 1")
+	}
+	if c.Exploration < 0 {
+		return fmt.Errorf("exploration must not be negative")
+	}
+	if c.Decay <= 0 || c.Decay > 1 {
+		return fmt.Errorf("decay must be in (0, 1]")
+	}
+	return nil
+}
+
+// NewRuleSelector creates the selector of a configuration
+func NewRuleSelector(c SelectionConfig) (RuleSelector, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	yields := newYieldTable(c.Decay)
+	switch c.Strategy {
+	case StrategyEpsilonGreedy:
+		return &EpsilonGreedySelector{yieldTable: yields, slots: c.RulesPerIteration, epsilon: c.Epsilon}, nil
+	case StrategyUCB:
+		return &UCBSelector{yieldTable: yields, slots: c.RulesPerIteration, exploration: c.Exploration}, nil
+	}
+	return &AllSelector{yieldTable: yields}, nil
+}
+
+// yieldTable keeps the yield of each rule
+type yieldTable struct {
+	decay  float64
+	yields map[string]*RuleYield
+	mu     sync.Mutex
+}
+
+func newYieldTable(decay float64) *yieldTable {
+	return &yieldTable{decay: decay, yields: make(map[string]*RuleYield)}
+}
+
+// Observe records the atoms added by one firing of a rule
+func (t *yieldTable) Observe(rule string, yield int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	y, exists := t.yields[rule]
+	if !exists {
+		y = &RuleYield{Recent: float64(yield)}
+		t.yields[rule] = y
+	}
+	y.Firings++
+	y.Total += int64(yield)
+	y.Recent += t.decay * (float64(yield) - y.Recent)
+}
+
+// Yields returns the yield of each rule fired so far
+func (t *yieldTable) Yields() map[string]RuleYield {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make(map[string]RuleYield, len(t.yields))
+	for rule, y := range t.yields {
+		result[rule] = *y
+	}
+	return result
+}
+
+// untried splits the candidates into rules never fired and the others
+func (t *yieldTable) untried(candidates []string) (untried, tried []string) {
+	for _, rule := range candidates {
+		if _, fired := t.yields[rule]; fired {
+			tried = append(tried, rule)
+		} else {
+			untried = append(untried, rule)
+		}
+	}
+	return untried, tried
+}
+
+// AllSelector fires every applicable rule
+type AllSelector struct {
+	*yieldTable
+}
+
+// Select returns all candidates
+func (s *AllSelector) Select(candidates []string) []string {
+	return candidates
+}
+
+// EpsilonGreedySelector fires the rules with the highest recent yield,
+// replacing each with a random rule with probability epsilon. Rules never
+// fired are tried first.
+type EpsilonGreedySelector struct {
+	*yieldTable
+	slots   int
+	epsilon float64
+}
+
+// Select returns up to RulesPerIteration candidates
+func (s *EpsilonGreedySelector) Select(candidates []string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	selected, tried := s.untried(candidates)
+	if len(selected) >= s.slots {
+		return selected[:s.slots]
+	}
+	sort.SliceStable(tried, func(i, j int) bool {
+		return s.yields[tried[i]].Recent > s.yields[tried[j]].Recent
+	})
+	for len(selected) < s.slots && len(tried) > 0 {
+		i := 0
+		if rand.Float64() < s.epsilon {
+			i = rand.Intn(len(tried))
+		}
+		selected = append(selected, tried[i])
+		tried = append(tried[:i], tried[i+1:]...)
+	}
+	return selected
+}
+
+// UCBSelector fires the rules with the highest upper confidence bound on
+// their recent yield (UCB1). Yields are scaled by the best recent yield so
+// the exploration weight does not depend on how many atoms rules derive.
+// Rules never fired are tried first.
+type UCBSelector struct {
+	*yieldTable
+	slots       int
+	exploration float64
+}
+
+// Select returns up to RulesPerIteration candidates
+func (s *UCBSelector) Select(candidates []string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	selected, tried := s.untried(candidates)
+	if len(selected) >= s.slots {
+		return selected[:s.slots]
+	}
+
+	var firings int64
+	best := 0.0
+	for _, rule := range tried {
+		firings += s.yields[rule].Firings
+		best = math.Max(best, s.yields[rule].Recent)
+	}
+	bound := make(map[string]float64, len(tried))
+	for _, rule := range tried {
+		y := s.yields[rule]
+		mean := 0.0
+		if best > 0 {
+			mean = y.Recent / best
+		}
+		bound[rule] = mean + s.exploration*math.Sqrt(2*math.Log(float64(firings))/float64(y.Firings))
+	}
+	sort.SliceStable(tried, func(i, j int) bool { return bound[tried[i]] > bound[tried[j]] })
+
+	for _, rule := range tried {
+		if len(selected) == s.slots {
+			break
+		}
+		selected = append(selected, rule)
+	}
+	return selected
+}
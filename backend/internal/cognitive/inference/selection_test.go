@@ -0,0 +1,144 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func TestEpsilonGreedySelector(t *testing.T) {
+	config := DefaultSelectionConfig()
+	config.Strategy = StrategyEpsilonGreedy
+	config.Epsilon = 0
+	selector, err := NewRuleSelector(config)
+	if err != nil {
+		t.Fatalf("NewRuleSelector failed: %v", err)
+	}
+
+	candidates := []string{"deduction", "induction"}
+	if got := selector.Select(candidates); len(got) != 1 || got[0] != "deduction" {
+		t.Fatalf("Expected the first untried rule, got %v", got)
+	}
+	selector.Observe("deduction", 1)
+	if got := selector.Select(candidates); got[0] != "induction" {
+		t.Fatalf("Expected the other untried rule, got %v", got)
+	}
+	selector.Observe("induction", 5)
+	if got := selector.Select(candidates); got[0] != "induction" {
+		t.Errorf("Expected the best yielding rule, got %v", got)
+	}
+
+	// Recent yields outweigh old ones
+	for i := 0; i < 5; i++ {
+		selector.Observe("induction", 0)
+	}
+	if got := selector.Select(candidates); got[0] != "deduction" {
+		t.Errorf("Expected the rule with the better recent yield, got %v (%+v)", got, selector.Yields())
+	}
+	if y := selector.Yields()["induction"]; y.Firings != 6 || y.Total != 5 {
+		t.Errorf("Unexpected yield: %+v", y)
+	}
+}
+
+func TestUCBSelector(t *testing.T) {
+	config := DefaultSelectionConfig()
+	config.Strategy = StrategyUCB
+	config.RulesPerIteration = 2
+	selector, _ := NewRuleSelector(config)
+
+	for i := 0; i < 20; i++ {
+		selector.Observe("deduction", 4)
+	}
+	selector.Observe("induction", 3)
+	for i := 0; i < 10; i++ {
+		selector.Observe("abduction", 0)
+	}
+
+	// induction has a lower mean than deduction but is barely explored, and
+	// abduction has been explored enough
+	got := selector.Select([]string{"abduction", "deduction", "induction"})
+	if len(got) != 2 || got[0] != "induction" || got[1] != "deduction" {
+		t.Errorf("Expected induction then deduction, got %v", got)
+	}
+}
+
+func TestSelectionConfigValidate(t *testing.T) {
+	invalid := []SelectionConfig{
+		{Strategy: "random", RulesPerIteration: 1, Decay: 0.5},
+		{Strategy: StrategyUCB, Decay: 0.5},
+		{Strategy: StrategyUCB, RulesPerIteration: 1},
+		{Strategy: StrategyEpsilonGreedy, RulesPerIteration: 1, Decay: 0.5, Epsilon: 2},
+	}
+	for i, c := range invalid {
+		if c.Validate() == nil {
+			t.Errorf("Expected config %d to be rejected", i)
+		}
+	}
+}
+
+// chain adds the inheritance chain c0 -> c1 -> ... -> cn
+func chain(t *testing.T, space *atomspace.AtomSpace, n int) {
+	for i := 0; i < n; i++ {
+		chainLink(t, space, i, i+1)
+	}
+}
+
+// chainLink adds the inheritance link ci -> cj and the concepts it links
+func chainLink(t *testing.T, space *atomspace.AtomSpace, i, j int) {
+	nodes := make([]atomspace.Atom, 0, 2)
+	for _, k := range []int{i, j} {
+		name := fmt.Sprintf("c%d", k)
+		node := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, "t", atomspace.ConceptNodeType)
+		if existing, err := space.GetAtom(node.GetID(), "t"); err == nil {
+			nodes = append(nodes, existing)
+			continue
+		}
+		if err := space.AddAtom(node); err != nil {
+			t.Fatalf("AddAtom failed: %v", err)
+		}
+		nodes = append(nodes, node)
+	}
+	link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", nodes), "inheritance", "t", atomspace.InheritanceLinkType, nodes)
+	if err := space.AddAtom(link); err != nil {
+		t.Fatalf("AddAtom failed: %v", err)
+	}
+}
+
+func TestRunInferenceWithSelector(t *testing.T) {
+	space := atomspace.NewAtomSpace(1)
+	defer space.Close()
+	chain(t, space, 4)
+
+	ie := NewInferenceEngine(space, 2)
+	defer ie.Close()
+	ie.AddRule(NewAbductionRule())
+	ie.AddRule(NewDeductionRule())
+
+	config := DefaultSelectionConfig()
+	config.Strategy = StrategyEpsilonGreedy
+	config.Epsilon = 0
+	selector, _ := NewRuleSelector(config)
+	ie.SetSelector(selector)
+
+	derived, err := ie.RunInference(context.Background(), "t", 20)
+	if err != nil {
+		t.Fatalf("RunInference failed: %v", err)
+	}
+	// The closure of a chain of 4 links adds the 6 links spanning 2 to 4 hops
+	if len(derived) != 6 {
+		t.Errorf("Expected 6 derived links, got %d", len(derived))
+	}
+	yields := selector.Yields()
+	if yields["abduction"].Total != 0 || yields["deduction"].Total != 6 || yields["deduction"].Recent <= yields["abduction"].Recent {
+		t.Errorf("Expected deduction to do the work, got %+v", yields)
+	}
+
+	// The fixpoint is cached, so no rule fires again
+	again, _ := ie.RunInference(context.Background(), "t", 20)
+	after := selector.Yields()
+	if len(again) != 0 || after["deduction"].Firings != yields["deduction"].Firings || after["abduction"].Firings != yields["abduction"].Firings {
+		t.Errorf("Expected a fixpoint, got %d atoms and %+v", len(again), selector.Yields())
+	}
+}
@@ -0,0 +1,62 @@
+package cognitive
+
+import (
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sharding"
+)
+
+// IntegrityMode is what the engine does about the consistency of shard
+// indices once snapshots are restored
+type IntegrityMode string
+
+const (
+	IntegrityOff    IntegrityMode = ""
+	IntegrityCheck  IntegrityMode = "check"  // Report inconsistencies
+	IntegrityRepair IntegrityMode = "repair" // Rebuild the indices found inconsistent
+)
+
+// Validate checks an integrity mode
+func (m IntegrityMode) Validate() error {
+	switch m {
+	case IntegrityOff, IntegrityCheck, IntegrityRepair:
+		return nil
+	}
+	return fmt.Errorf("unknown integrity mode %q", m)
+}
+
+// CheckIntegrity scans every shard for index entries without atoms, atoms
+// missing from indices or listed under another tenant, and dangling links.
+// With repair set, inconsistent indices are rebuilt from the stored atoms;
+// dangling links are only reported.
+func (ce *CognitiveEngine) CheckIntegrity(repair bool) sharding.IntegrityReport {
+	return ce.shardManager.CheckIntegrity(repair)
+}
+
+// checkStartupIntegrity runs the integrity check of the startup and
+// reports it as a warm-up, failing if index issues were left unrepaired
+func (ce *CognitiveEngine) checkStartupIntegrity(mode IntegrityMode, done func(map[string]interface{}, error)) {
+	report := ce.CheckIntegrity(mode == IntegrityRepair)
+	dangling, unrepaired := 0, 0
+	for _, issue := range report.Issues {
+		switch {
+		case issue.Kind == atomspace.IssueDanglingLink:
+			dangling++
+		case !issue.Repaired:
+			unrepaired++
+		}
+	}
+	details := map[string]interface{}{
+		"mode":           string(mode),
+		"atoms":          report.Atoms,
+		"issues":         len(report.Issues),
+		"repaired":       report.Repaired,
+		"dangling_links": dangling,
+	}
+	var err error
+	if unrepaired > 0 {
+		err = fmt.Errorf("%d index issues found", unrepaired)
+	}
+	done(details, err)
+}
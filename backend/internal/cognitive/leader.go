@@ -0,0 +1,49 @@
+package cognitive
+
+import (
+	"context"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/leader"
+)
+
+// startElection campaigns for the leader lock until the engine is closed.
+// Unless agents are partitioned across replicas, they run on the leader
+// only: the scheduler stays in standby until this replica is elected, and
+// returns to it when the replica steps down.
+func (ce *CognitiveEngine) startElection(lock leader.Lock, config leader.Config) {
+	ce.elector = leader.NewElector(lock, config)
+	ce.electionDone = make(chan struct{})
+	if ce.partitioner == nil {
+		ce.agentScheduler.SetStandby(true)
+		ce.elector.OnChange(func(leading bool) {
+			ce.agentScheduler.SetStandby(!leading)
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-ce.done
+		cancel()
+	}()
+	go func() {
+		defer close(ce.electionDone)
+		ce.elector.Run(ctx)
+	}()
+}
+
+// IsLeader reports whether this replica runs the subsystems that must not
+// run twice: scheduled and daemon agents, and the sampling of stored atoms
+// for billing, unless those are partitioned across replicas. Without a
+// leader lock every replica runs them.
+func (ce *CognitiveEngine) IsLeader() bool {
+	return ce.elector == nil || ce.elector.IsLeader()
+}
+
+// GetLeaderStatus returns the state of the election as seen by this
+// replica
+func (ce *CognitiveEngine) GetLeaderStatus() (leader.Status, error) {
+	if ce.elector == nil {
+		return leader.Status{}, leader.ErrNotConfigured
+	}
+	return ce.elector.Status(), nil
+}
@@ -0,0 +1,153 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrNotConfigured is returned when replicas do not elect a leader
+var ErrNotConfigured = errors.New("leader election not configured")
+
+// Lock is held by at most one replica at a time
+type Lock interface {
+	// Acquire takes the lock if it is free, or renews it if this replica
+	// holds it, and reports whether this replica holds it now
+	Acquire(ctx context.Context) (bool, error)
+
+	// Release gives the lock up, so another replica may take it without
+	// waiting for it to expire
+	Release(ctx context.Context) error
+}
+
+// Config configures the election
+type Config struct {
+	Identity    string        // Name of this replica; the hostname if empty
+	RetryPeriod time.Duration // How often the lock is acquired or renewed; 2s if not positive
+}
+
+// DefaultConfig renews the lock every 2 seconds
+func DefaultConfig() Config {
+	return Config{RetryPeriod: 2 * time.Second}
+}
+
+// Status describes the election as seen by this replica
+type Status struct {
+	Identity  string    `json:"identity"`
+	Leader    bool      `json:"leader"`
+	Since     time.Time `json:"since"` // When this replica last became leader or follower
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Elector keeps trying to acquire a lock, so that subsystems which must not
+// run twice across replicas run on the one holding it. A replica that
+// cannot renew the lock steps down at once, before the lock could expire
+// and be taken by another.
+type Elector struct {
+	lock     Lock
+	config   Config
+	leader   bool
+	since    time.Time
+	err      error
+	handlers []func(leading bool)
+	mu       sync.Mutex
+}
+
+// NewElector creates an elector; it is a follower until Run acquires the
+// lock
+func NewElector(lock Lock, config Config) *Elector {
+	if config.Identity == "" {
+		config.Identity, _ = os.Hostname()
+	}
+	if config.RetryPeriod <= 0 {
+		config.RetryPeriod = DefaultConfig().RetryPeriod
+	}
+	return &Elector{lock: lock, config: config, since: time.Now()}
+}
+
+// Identity returns the name of this replica
+func (e *Elector) Identity() string {
+	return e.config.Identity
+}
+
+// RetryPeriod returns how often the lock is acquired or renewed
+func (e *Elector) RetryPeriod() time.Duration {
+	return e.config.RetryPeriod
+}
+
+// OnChange registers a handler called with true when this replica becomes
+// leader and false when it steps down. Handlers run in order on the
+// elector's goroutine.
+func (e *Elector) OnChange(handler func(leading bool)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.handlers = append(e.handlers, handler)
+}
+
+// IsLeader reports whether this replica holds the lock
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+// Status returns the state of the election
+func (e *Elector) Status() Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	status := Status{Identity: e.config.Identity, Leader: e.leader, Since: e.since}
+	if e.err != nil {
+		status.LastError = e.err.Error()
+	}
+	return status
+}
+
+// Run acquires or renews the lock every retry period until ctx is
+// cancelled, then steps down and releases the lock if it is held
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.config.RetryPeriod)
+	defer ticker.Stop()
+	for {
+		e.try(ctx)
+		select {
+		case <-ctx.Done():
+			if e.IsLeader() {
+				e.set(false, nil)
+				releaseCtx, cancel := context.WithTimeout(context.Background(), e.config.RetryPeriod)
+				e.lock.Release(releaseCtx)
+				cancel()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Elector) try(ctx context.Context) {
+	attemptCtx, cancel := context.WithTimeout(ctx, e.config.RetryPeriod)
+	defer cancel()
+	held, err := e.lock.Acquire(attemptCtx)
+	e.set(held && err == nil, err)
+}
+
+// set records the outcome of an attempt and calls the handlers when
+// leadership changed
+func (e *Elector) set(leading bool, err error) {
+	e.mu.Lock()
+	e.err = err
+	changed := e.leader != leading
+	if changed {
+		e.leader = leading
+		e.since = time.Now()
+	}
+	handlers := append([]func(bool){}, e.handlers...)
+	e.mu.Unlock()
+
+	if changed {
+		for _, handler := range handlers {
+			handler(leading)
+		}
+	}
+}
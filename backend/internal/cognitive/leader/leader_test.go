@@ -0,0 +1,246 @@
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLock is held by whichever replica it was last granted to
+type fakeLock struct {
+	holder string
+	err    error
+	mu     sync.Mutex
+}
+
+type fakeHandle struct {
+	lock     *fakeLock
+	identity string
+}
+
+func (h fakeHandle) Acquire(ctx context.Context) (bool, error) {
+	h.lock.mu.Lock()
+	defer h.lock.mu.Unlock()
+	if h.lock.err != nil {
+		return false, h.lock.err
+	}
+	if h.lock.holder == "" {
+		h.lock.holder = h.identity
+	}
+	return h.lock.holder == h.identity, nil
+}
+
+func (h fakeHandle) Release(ctx context.Context) error {
+	h.lock.mu.Lock()
+	defer h.lock.mu.Unlock()
+	if h.lock.holder == h.identity {
+		h.lock.holder = ""
+	}
+	return nil
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+func TestElectorFailover(t *testing.T) {
+	lock := &fakeLock{}
+	config := Config{RetryPeriod: 10 * time.Millisecond}
+	config.Identity = "a"
+	a := NewElector(fakeHandle{lock, "a"}, config)
+	config.Identity = "b"
+	b := NewElector(fakeHandle{lock, "b"}, config)
+
+	var changes []bool
+	var mu sync.Mutex
+	a.OnChange(func(leading bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, leading)
+	})
+
+	ctxA, stopA := context.WithCancel(context.Background())
+	doneA := make(chan struct{})
+	go func() {
+		a.Run(ctxA)
+		close(doneA)
+	}()
+	waitFor(t, "a to lead", a.IsLeader)
+
+	ctxB, stopB := context.WithCancel(context.Background())
+	defer stopB()
+	go b.Run(ctxB)
+	time.Sleep(50 * time.Millisecond)
+	if b.IsLeader() {
+		t.Fatal("expected a single leader")
+	}
+
+	// A replica that cannot renew steps down at once
+	lock.mu.Lock()
+	lock.err = errors.New("connection refused")
+	lock.mu.Unlock()
+	waitFor(t, "a to step down", func() bool { return !a.IsLeader() })
+	if a.Status().LastError != "connection refused" {
+		t.Errorf("expected the error reported, got %+v", a.Status())
+	}
+	lock.mu.Lock()
+	lock.err = nil
+	lock.mu.Unlock()
+	waitFor(t, "a to lead again", a.IsLeader)
+
+	// Stopping the leader releases the lock to the other replica
+	stopA()
+	<-doneA
+	waitFor(t, "b to take over", b.IsLeader)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != 4 || !changes[0] || changes[1] || !changes[2] || changes[3] {
+		t.Errorf("expected a to lead, step down, lead and step down, got %v", changes)
+	}
+}
+
+// fakeLeaseServer serves leases, rejecting updates of stale resource
+// versions as the API server does
+type fakeLeaseServer struct {
+	leases  map[string]*lease
+	version int
+	mu      sync.Mutex
+}
+
+func (s *fakeLeaseServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer token" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/apis/coordination.k8s.io/v1/namespaces/erebus/leases")
+	name = strings.TrimPrefix(name, "/")
+
+	switch {
+	case r.Method == http.MethodGet && name == "":
+		selector := strings.SplitN(r.URL.Query().Get("labelSelector"), "=", 2)
+		items := []*lease{}
+		for _, l := range s.leases {
+			if labels, _ := l.Metadata["labels"].(map[string]interface{}); labels[selector[0]] == selector[1] {
+				items = append(items, l)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	case r.Method == http.MethodGet:
+		if s.leases[name] == nil {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(s.leases[name])
+	case r.Method == http.MethodPost, r.Method == http.MethodPut:
+		var l lease
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &l)
+		if r.Method == http.MethodPost {
+			name = l.Metadata["name"].(string)
+			if s.leases[name] != nil {
+				http.Error(w, "exists", http.StatusConflict)
+				return
+			}
+		} else if s.leases[name] == nil || l.Metadata["resourceVersion"] != s.leases[name].Metadata["resourceVersion"] {
+			http.Error(w, "conflict", http.StatusConflict)
+			return
+		}
+		s.version++
+		l.Metadata["resourceVersion"] = strconv.Itoa(s.version)
+		// Store the lease as it would be read back
+		data, _ = json.Marshal(l)
+		var stored lease
+		json.Unmarshal(data, &stored)
+		s.leases[name] = &stored
+		w.Write(data)
+	}
+}
+
+func TestLeaseLock(t *testing.T) {
+	server := &fakeLeaseServer{leases: make(map[string]*lease)}
+	api := httptest.NewServer(server)
+	defer api.Close()
+	tokenFile := t.TempDir() + "/token"
+	if err := os.WriteFile(tokenFile, []byte("token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newLock := func(identity string) *LeaseLock {
+		lock, err := NewLeaseLock(LeaseConfig{Server: api.URL, TokenFile: tokenFile, Namespace: "erebus", Name: "erebusd", Identity: identity, Duration: 10 * time.Second})
+		if err != nil {
+			t.Fatalf("NewLeaseLock failed: %v", err)
+		}
+		lock.now = func() time.Time { return now }
+		return lock
+	}
+	a, b := newLock("a"), newLock("b")
+
+	if held, err := a.Acquire(context.Background()); !held || err != nil {
+		t.Fatalf("expected a to create the lease, got %v, %v", held, err)
+	}
+	if held, err := b.Acquire(context.Background()); held || err != nil {
+		t.Fatalf("expected b to wait while the lease is renewed, got %v, %v", held, err)
+	}
+	now = now.Add(5 * time.Second)
+	if held, _ := a.Acquire(context.Background()); !held {
+		t.Fatal("expected a to renew the lease")
+	}
+
+	// b takes the lease over once a stops renewing it
+	now = now.Add(11 * time.Second)
+	if held, err := b.Acquire(context.Background()); !held || err != nil {
+		t.Fatalf("expected b to take the expired lease over, got %v, %v", held, err)
+	}
+	if spec := server.leases["erebusd"].Spec; spec.HolderIdentity != "b" || spec.LeaseTransitions != 1 {
+		t.Errorf("expected b holding the lease after one transition, got %+v", spec)
+	}
+	if held, _ := a.Acquire(context.Background()); held {
+		t.Error("expected a to find the lease taken")
+	}
+
+	// Releasing hands the lease over without waiting for it to expire
+	if err := b.Release(context.Background()); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if held, _ := a.Acquire(context.Background()); !held {
+		t.Error("expected a to take the released lease")
+	}
+
+	// Holders lists the live leases sharing the lock's labels
+	member := func(identity string) *LeaseLock {
+		lock, _ := NewLeaseLock(LeaseConfig{Server: api.URL, TokenFile: tokenFile, Namespace: "erebus", Name: "agents-" + identity, Identity: identity, Labels: map[string]string{"group": "agents"}})
+		lock.now = func() time.Time { return now }
+		lock.Acquire(context.Background())
+		return lock
+	}
+	member("x")
+	now = now.Add(20 * time.Second)
+	y := member("y")
+	if holders, err := y.Holders(context.Background()); err != nil || len(holders) != 1 || holders[0] != "y" {
+		t.Errorf("expected only the unexpired member listed, got %v, %v", holders, err)
+	}
+}
+
+func TestPostgresLockKey(t *testing.T) {
+	if LockKey("erebusd") != LockKey("erebusd") || LockKey("erebusd") == LockKey("erebusd-staging") {
+		t.Error("expected keys stable per name and distinct across names")
+	}
+}
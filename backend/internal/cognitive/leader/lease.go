@@ -0,0 +1,283 @@
+package leader
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// serviceAccountDir holds the credentials Kubernetes mounts into pods
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// microTime is the timestamp format of lease times
+const microTime = "2006-01-02T15:04:05.000000Z07:00"
+
+// LeaseConfig configures a lock on a Kubernetes coordination.k8s.io/v1 Lease
+type LeaseConfig struct {
+	Server    string            // API server URL
+	TokenFile string            // Bearer token, read before each request as it is rotated
+	Namespace string            // Namespace of the lease
+	Name      string            // Name of the lease
+	Identity  string            // Holder identity written to the lease
+	Duration  time.Duration     // How long the lease is held without renewal; 15s if not positive
+	Labels    map[string]string // Set on the lease when it is created, and selecting the leases Holders lists
+	Client    *http.Client      // Client of the API server; http.DefaultClient if nil
+}
+
+// InClusterLeaseConfig configures a lease in the namespace of the pod,
+// using its service account
+func InClusterLeaseConfig(name, identity string) (LeaseConfig, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return LeaseConfig{}, fmt.Errorf("not running in a Kubernetes pod")
+	}
+	namespace, err := os.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+	if err != nil {
+		return LeaseConfig{}, err
+	}
+	ca, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return LeaseConfig{}, err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(ca) {
+		return LeaseConfig{}, fmt.Errorf("invalid service account CA certificate")
+	}
+
+	return LeaseConfig{
+		Server:    "https://" + net.JoinHostPort(host, port),
+		TokenFile: filepath.Join(serviceAccountDir, "token"),
+		Namespace: strings.TrimSpace(string(namespace)),
+		Name:      name,
+		Identity:  identity,
+		Client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}},
+		},
+	}, nil
+}
+
+// LeaseLock is held by the replica named as holder of a Kubernetes Lease
+// that has been renewed within its duration. Concurrent takeovers are
+// resolved by the API server, which accepts only one update of a given
+// resource version.
+type LeaseLock struct {
+	config LeaseConfig
+	now    func() time.Time
+}
+
+// NewLeaseLock creates a lock on a Kubernetes Lease
+func NewLeaseLock(config LeaseConfig) (*LeaseLock, error) {
+	if config.Server == "" || config.Namespace == "" || config.Name == "" || config.Identity == "" {
+		return nil, fmt.Errorf("lease lock requires a server, namespace, name and identity")
+	}
+	config.Server = strings.TrimSuffix(config.Server, "/")
+	if config.Duration <= 0 {
+		config.Duration = 15 * time.Second
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	return &LeaseLock{config: config, now: time.Now}, nil
+}
+
+// lease is the part of a Lease object the lock reads and writes. Its
+// metadata is written back as read, so updates carry the resource version
+// they were based on.
+type lease struct {
+	APIVersion string                 `json:"apiVersion"`
+	Kind       string                 `json:"kind"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	Spec       leaseSpec              `json:"spec"`
+}
+
+type leaseSpec struct {
+	HolderIdentity       string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds int    `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          string `json:"acquireTime,omitempty"`
+	RenewTime            string `json:"renewTime,omitempty"`
+	LeaseTransitions     int    `json:"leaseTransitions,omitempty"`
+}
+
+// expired reports whether the holder failed to renew the lease in time
+func (s leaseSpec) expired(now time.Time) bool {
+	renewed, err := time.Parse(microTime, s.RenewTime)
+	if err != nil {
+		return true
+	}
+	return now.After(renewed.Add(time.Duration(s.LeaseDurationSeconds) * time.Second))
+}
+
+// Acquire creates the lease, renews it, or takes it over once the holder
+// let it expire
+func (l *LeaseLock) Acquire(ctx context.Context) (bool, error) {
+	current, status, err := l.get(ctx)
+	if err != nil {
+		return false, err
+	}
+	now := l.now()
+	if status == http.StatusNotFound {
+		created := lease{
+			APIVersion: "coordination.k8s.io/v1",
+			Kind:       "Lease",
+			Metadata:   map[string]interface{}{"name": l.config.Name, "namespace": l.config.Namespace},
+		}
+		if len(l.config.Labels) > 0 {
+			created.Metadata["labels"] = l.config.Labels
+		}
+		l.hold(&created.Spec, now, true)
+		return l.write(ctx, http.MethodPost, l.collectionURL(), created)
+	}
+
+	spec := &current.Spec
+	if spec.HolderIdentity != l.config.Identity && spec.HolderIdentity != "" && !spec.expired(now) {
+		return false, nil
+	}
+	l.hold(spec, now, spec.HolderIdentity != l.config.Identity)
+	return l.write(ctx, http.MethodPut, l.leaseURL(), *current)
+}
+
+// hold names this replica holder of the lease, as of now
+func (l *LeaseLock) hold(spec *leaseSpec, now time.Time, takeover bool) {
+	if takeover {
+		if spec.HolderIdentity != "" {
+			spec.LeaseTransitions++
+		}
+		spec.HolderIdentity = l.config.Identity
+		spec.AcquireTime = now.UTC().Format(microTime)
+	}
+	spec.LeaseDurationSeconds = int((l.config.Duration + time.Second - 1) / time.Second)
+	spec.RenewTime = now.UTC().Format(microTime)
+}
+
+// Release clears the holder of the lease if this replica holds it
+func (l *LeaseLock) Release(ctx context.Context) error {
+	current, status, err := l.get(ctx)
+	if err != nil || status == http.StatusNotFound || current.Spec.HolderIdentity != l.config.Identity {
+		return err
+	}
+	current.Spec.HolderIdentity = ""
+	current.Spec.LeaseDurationSeconds = 1
+	_, err = l.write(ctx, http.MethodPut, l.leaseURL(), *current)
+	return err
+}
+
+// Holders lists the replicas holding an unexpired lease with the labels of
+// this one, sorted
+func (l *LeaseLock) Holders(ctx context.Context) ([]string, error) {
+	selector := make([]string, 0, len(l.config.Labels))
+	for key, value := range l.config.Labels {
+		selector = append(selector, key+"="+value)
+	}
+	sort.Strings(selector)
+	resp, err := l.do(ctx, http.MethodGet, l.collectionURL()+"?labelSelector="+url.QueryEscape(strings.Join(selector, ",")), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+	var list struct {
+		Items []lease `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("invalid lease list: %w", err)
+	}
+
+	now := l.now()
+	holders := []string{}
+	for _, item := range list.Items {
+		if item.Spec.HolderIdentity != "" && !item.Spec.expired(now) {
+			holders = append(holders, item.Spec.HolderIdentity)
+		}
+	}
+	sort.Strings(holders)
+	return holders, nil
+}
+
+func (l *LeaseLock) collectionURL() string {
+	return fmt.Sprintf("%s/apis/coordination.k8s.io/v1/namespaces/%s/leases", l.config.Server, l.config.Namespace)
+}
+
+func (l *LeaseLock) leaseURL() string {
+	return l.collectionURL() + "/" + l.config.Name
+}
+
+// get reads the lease; a missing lease is reported by its status
+func (l *LeaseLock) get(ctx context.Context) (*lease, int, error) {
+	resp, err := l.do(ctx, http.MethodGet, l.leaseURL(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, resp.StatusCode, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, apiError(resp)
+	}
+	var current lease
+	if err := json.NewDecoder(resp.Body).Decode(&current); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("invalid lease: %w", err)
+	}
+	return &current, resp.StatusCode, nil
+}
+
+// write creates or updates the lease. A conflict means another replica
+// wrote it first, which is not an error.
+func (l *LeaseLock) write(ctx context.Context, method, target string, body lease) (bool, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return false, err
+	}
+	resp, err := l.do(ctx, method, target, data)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		return true, nil
+	case http.StatusConflict:
+		return false, nil
+	default:
+		return false, apiError(resp)
+	}
+}
+
+func (l *LeaseLock) do(ctx context.Context, method, target string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if l.config.TokenFile != "" {
+		token, err := os.ReadFile(l.config.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	return l.config.Client.Do(req)
+}
+
+func apiError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("kubernetes API returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
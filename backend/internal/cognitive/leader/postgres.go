@@ -0,0 +1,91 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"hash/fnv"
+	"sync"
+)
+
+// PostgresLock is a session-level Postgres advisory lock, held on a
+// connection of its own. The server releases it when that session ends, so
+// a replica that dies or loses its connection gives the lock up; an
+// unreachable replica holds it until the server notices the session is
+// gone.
+type PostgresLock struct {
+	db   *sql.DB
+	key  int64
+	conn *sql.Conn // Session holding the lock; nil while not held
+	mu   sync.Mutex
+}
+
+// NewPostgresLock creates an advisory lock keyed by a name shared by the
+// replicas
+func NewPostgresLock(db *sql.DB, name string) *PostgresLock {
+	return &PostgresLock{db: db, key: LockKey(name)}
+}
+
+// LockKey derives the advisory lock key of a name
+func LockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// Acquire takes the lock on a new session, or checks that the session
+// holding it is still alive
+func (l *PostgresLock) Acquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if _, err := l.conn.ExecContext(ctx, "SELECT 1"); err != nil {
+			l.discard()
+			return false, err
+		}
+		return true, nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	var held bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&held); err != nil {
+		l.conn = conn
+		l.discard()
+		return false, err
+	}
+	if !held {
+		conn.Close()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+// Release unlocks the lock if it is held
+func (l *PostgresLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	if _, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key); err != nil {
+		l.discard()
+		return err
+	}
+	err := l.conn.Close()
+	l.conn = nil
+	return err
+}
+
+// discard closes the session rather than returning it to the pool, where
+// it would keep holding the lock
+func (l *PostgresLock) discard() {
+	l.conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	l.conn.Close()
+	l.conn = nil
+}
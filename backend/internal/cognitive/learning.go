@@ -0,0 +1,52 @@
+package cognitive
+
+import (
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/learning"
+)
+
+// Feedback reports an outcome and adapts agent priorities and rule weights
+// of the tenant accordingly
+func (ce *CognitiveEngine) Feedback(fb learning.Feedback) ([]learning.Adjustment, error) {
+	for _, agentID := range fb.AgentIDs {
+		if agent, exists := ce.agentScheduler.GetAgent(agentID); exists && agent.GetTenantID() != fb.TenantID {
+			return nil, fmt.Errorf("agent %s does not belong to tenant %s", agentID, fb.TenantID)
+		}
+	}
+
+	adjustments, err := ce.learner.Feedback(fb)
+	if err != nil {
+		return nil, err
+	}
+	ce.applyAdjustments(fb.TenantID, adjustments)
+	return adjustments, nil
+}
+
+// GetLearnedValues returns the learned values of a tenant's agents and rules
+func (ce *CognitiveEngine) GetLearnedValues(tenantID string) []learning.Arm {
+	return ce.learner.GetArms(tenantID)
+}
+
+// ResetLearning restores the configured priorities and rule weights of a
+// tenant
+func (ce *CognitiveEngine) ResetLearning(tenantID string) {
+	ce.applyAdjustments(tenantID, ce.learner.Reset(tenantID))
+}
+
+func (ce *CognitiveEngine) applyAdjustments(tenantID string, adjustments []learning.Adjustment) {
+	ce.mu.RLock()
+	inferenceEngine := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+
+	for _, adj := range adjustments {
+		switch adj.Kind {
+		case learning.ArmAgent:
+			ce.agentScheduler.SetPriorityAdjustment(adj.ID, adj.Priority)
+		case learning.ArmRule:
+			if inferenceEngine != nil {
+				inferenceEngine.SetRuleWeight(adj.ID, adj.Weight)
+			}
+		}
+	}
+}
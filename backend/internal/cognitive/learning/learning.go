@@ -0,0 +1,320 @@
+package learning
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outcome is an observed result that rewards or penalizes the agents and
+// rules that contributed to it
+type Outcome string
+
+const (
+	OutcomePipelineSucceeded Outcome = "pipeline_succeeded"
+	OutcomePipelineFailed    Outcome = "pipeline_failed"
+	OutcomeActionAccepted    Outcome = "action_accepted"
+	OutcomeActionRejected    Outcome = "action_rejected"
+	OutcomeAnomalyConfirmed  Outcome = "anomaly_confirmed"
+	OutcomeAnomalyDismissed  Outcome = "anomaly_dismissed"
+)
+
+// outcomeRewards are the default rewards of outcomes
+var outcomeRewards = map[Outcome]float64{
+	OutcomePipelineSucceeded: 1,
+	OutcomePipelineFailed:    -1,
+	OutcomeActionAccepted:    1,
+	OutcomeActionRejected:    -1,
+	OutcomeAnomalyConfirmed:  1,
+	OutcomeAnomalyDismissed:  -1,
+}
+
+// ArmKind distinguishes what an arm adjusts
+type ArmKind string
+
+const (
+	ArmAgent ArmKind = "agent"
+	ArmRule  ArmKind = "rule"
+)
+
+// Config controls how quickly feedback changes priorities and weights
+type Config struct {
+	LearningRate  float64 // Step size of value updates
+	TraceDecay    float64 // Eligibility kept after each feedback
+	PriorityScale float64 // Priority adjustment at a value of ±1
+	MinRuleWeight float64 // Lower bound of rule weights, keeps exploring
+	MaxProvenance int     // Derived atoms remembered for credit assignment
+}
+
+// DefaultConfig returns the default learning configuration
+func DefaultConfig() Config {
+	return Config{
+		LearningRate:  0.1,
+		TraceDecay:    0.5,
+		PriorityScale: 5,
+		MinRuleWeight: 0.05,
+		MaxProvenance: 10000,
+	}
+}
+
+// Feedback reports an outcome. Explicit agents and rules, and the rules that
+// derived the referenced atoms, are credited; without any of them the
+// credit goes to recently active agents and rules by eligibility.
+type Feedback struct {
+	TenantID string   `json:"tenant_id"`
+	Outcome  Outcome  `json:"outcome"`
+	Reward   *float64 `json:"reward,omitempty"` // Overrides the outcome's reward, clamped to [-1, 1]
+	AgentIDs []string `json:"agent_ids,omitempty"`
+	Rules    []string `json:"rules,omitempty"`
+	AtomIDs  []string `json:"atom_ids,omitempty"`
+}
+
+// Arm is the learned value of one agent or rule
+type Arm struct {
+	Kind        ArmKind   `json:"kind"`
+	ID          string    `json:"id"`
+	Value       float64   `json:"value"` // Estimated reward in [-1, 1]
+	Updates     int64     `json:"updates"`
+	TotalReward float64   `json:"total_reward"`
+	Eligibility float64   `json:"eligibility"`
+	LastUpdate  time.Time `json:"last_update,omitempty"`
+}
+
+// Adjustment is a changed agent priority or rule weight produced by feedback
+type Adjustment struct {
+	Kind     ArmKind `json:"kind"`
+	ID       string  `json:"id"`
+	Priority int     `json:"priority,omitempty"` // Agents: added to the base priority
+	Weight   float64 `json:"weight,omitempty"`   // Rules: probability of being applied
+}
+
+type armKey struct {
+	kind ArmKind
+	id   string
+}
+
+// Learner assigns credit for outcomes to agents and rules per tenant
+type Learner struct {
+	config     Config
+	arms       map[string]map[armKey]*Arm // tenantID -> arms
+	provenance map[string]string          // tenantID/atomID -> rule
+	order      []string                   // provenance keys, oldest first
+	feedback   map[Outcome]int64
+	mu         sync.Mutex
+}
+
+// NewLearner creates a learner
+func NewLearner(config Config) *Learner {
+	defaults := DefaultConfig()
+	if config.LearningRate <= 0 {
+		config.LearningRate = defaults.LearningRate
+	}
+	if config.TraceDecay < 0 || config.TraceDecay > 1 {
+		config.TraceDecay = defaults.TraceDecay
+	}
+	if config.MaxProvenance <= 0 {
+		config.MaxProvenance = defaults.MaxProvenance
+	}
+	return &Learner{
+		config:     config,
+		arms:       make(map[string]map[armKey]*Arm),
+		provenance: make(map[string]string),
+		feedback:   make(map[Outcome]int64),
+	}
+}
+
+func (l *Learner) arm(tenantID string, kind ArmKind, id string) *Arm {
+	arms, exists := l.arms[tenantID]
+	if !exists {
+		arms = make(map[armKey]*Arm)
+		l.arms[tenantID] = arms
+	}
+	key := armKey{kind: kind, id: id}
+	a, exists := arms[key]
+	if !exists {
+		a = &Arm{Kind: kind, ID: id}
+		arms[key] = a
+	}
+	return a
+}
+
+// RecordAgentRun makes an agent eligible for credit from the next feedback
+func (l *Learner) RecordAgentRun(tenantID, agentID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.arm(tenantID, ArmAgent, agentID).Eligibility++
+}
+
+// RecordDerivation remembers which rule derived an atom
+func (l *Learner) RecordDerivation(tenantID, rule, atomID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.arm(tenantID, ArmRule, rule).Eligibility++
+
+	key := tenantID + "/" + atomID
+	if _, exists := l.provenance[key]; !exists {
+		l.order = append(l.order, key)
+	}
+	l.provenance[key] = rule
+	for len(l.order) > l.config.MaxProvenance {
+		delete(l.provenance, l.order[0])
+		l.order = l.order[1:]
+	}
+}
+
+// DerivedBy returns the rule that derived an atom, if it is remembered
+func (l *Learner) DerivedBy(tenantID, atomID string) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rule, exists := l.provenance[tenantID+"/"+atomID]
+	return rule, exists
+}
+
+// Feedback applies an outcome and returns the resulting adjustments
+func (l *Learner) Feedback(fb Feedback) ([]Adjustment, error) {
+	reward, known := outcomeRewards[fb.Outcome]
+	if fb.Reward != nil {
+		reward = math.Max(-1, math.Min(1, *fb.Reward))
+	} else if !known {
+		return nil, fmt.Errorf("unknown outcome: %s", fb.Outcome)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Explicit targets get full credit
+	credit := make(map[*Arm]float64)
+	for _, agentID := range fb.AgentIDs {
+		credit[l.arm(fb.TenantID, ArmAgent, agentID)] = 1
+	}
+	for _, rule := range fb.Rules {
+		credit[l.arm(fb.TenantID, ArmRule, rule)] = 1
+	}
+	for _, atomID := range fb.AtomIDs {
+		if rule, exists := l.provenance[fb.TenantID+"/"+atomID]; exists {
+			credit[l.arm(fb.TenantID, ArmRule, rule)] = 1
+		}
+	}
+
+	// Otherwise share it by eligibility within each kind
+	if len(credit) == 0 {
+		totals := make(map[ArmKind]float64)
+		for _, a := range l.arms[fb.TenantID] {
+			totals[a.Kind] += a.Eligibility
+		}
+		for _, a := range l.arms[fb.TenantID] {
+			if a.Eligibility > 0 {
+				credit[a] = a.Eligibility / totals[a.Kind]
+			}
+		}
+	}
+
+	now := time.Now()
+	adjustments := make([]Adjustment, 0, len(credit))
+	for a, share := range credit {
+		a.Value += l.config.LearningRate * share * (reward - a.Value)
+		a.Updates++
+		a.TotalReward += reward * share
+		a.LastUpdate = now
+		adjustments = append(adjustments, l.adjustment(a))
+	}
+	for _, a := range l.arms[fb.TenantID] {
+		a.Eligibility *= l.config.TraceDecay
+	}
+	l.feedback[fb.Outcome]++
+
+	sort.Slice(adjustments, func(i, j int) bool {
+		if adjustments[i].Kind != adjustments[j].Kind {
+			return adjustments[i].Kind < adjustments[j].Kind
+		}
+		return adjustments[i].ID < adjustments[j].ID
+	})
+	return adjustments, nil
+}
+
+func (l *Learner) adjustment(a *Arm) Adjustment {
+	if a.Kind == ArmAgent {
+		return Adjustment{Kind: a.Kind, ID: a.ID, Priority: int(math.Round(a.Value * l.config.PriorityScale))}
+	}
+	return Adjustment{Kind: a.Kind, ID: a.ID, Weight: math.Max(l.config.MinRuleWeight, math.Min(1, 1+a.Value))}
+}
+
+// GetArms returns the learned values of a tenant's agents and rules
+func (l *Learner) GetArms(tenantID string) []Arm {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := make([]Arm, 0, len(l.arms[tenantID]))
+	for _, a := range l.arms[tenantID] {
+		result = append(result, *a)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Reset forgets what was learned for a tenant and returns the adjustments
+// that restore the defaults
+func (l *Learner) Reset(tenantID string) []Adjustment {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	adjustments := make([]Adjustment, 0, len(l.arms[tenantID]))
+	for _, a := range l.arms[tenantID] {
+		adjustments = append(adjustments, l.adjustment(&Arm{Kind: a.Kind, ID: a.ID}))
+	}
+	delete(l.arms, tenantID)
+	return adjustments
+}
+
+// Purge forgets everything learned for a tenant, including which rules
+// derived its atoms, and returns how many arms were removed
+func (l *Learner) Purge(tenantID string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	purged := len(l.arms[tenantID])
+	delete(l.arms, tenantID)
+
+	prefix := tenantID + "/"
+	kept := l.order[:0]
+	for _, key := range l.order {
+		if strings.HasPrefix(key, prefix) {
+			delete(l.provenance, key)
+			continue
+		}
+		kept = append(kept, key)
+	}
+	l.order = kept
+	return purged
+}
+
+// GetStats returns learner statistics
+func (l *Learner) GetStats() map[string]interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	arms := 0
+	for _, tenantArms := range l.arms {
+		arms += len(tenantArms)
+	}
+	feedback := make(map[Outcome]int64, len(l.feedback))
+	for outcome, count := range l.feedback {
+		feedback[outcome] = count
+	}
+
+	return map[string]interface{}{
+		"tenants":    len(l.arms),
+		"arms":       arms,
+		"provenance": len(l.provenance),
+		"feedback":   feedback,
+	}
+}
@@ -0,0 +1,49 @@
+package learning
+
+import (
+	"testing"
+)
+
+func TestFeedbackCreditsEligibleAgents(t *testing.T) {
+	l := NewLearner(Config{LearningRate: 0.5, TraceDecay: 0.5, PriorityScale: 4})
+
+	l.RecordAgentRun("t1", "a")
+	l.RecordAgentRun("t1", "a")
+	l.RecordAgentRun("t1", "a")
+	l.RecordAgentRun("t1", "b")
+
+	adjustments, err := l.Feedback(Feedback{TenantID: "t1", Outcome: OutcomeActionAccepted})
+	if err != nil {
+		t.Fatalf("Feedback failed: %v", err)
+	}
+	if len(adjustments) != 2 {
+		t.Fatalf("Expected both agents to be credited, got %+v", adjustments)
+	}
+
+	arms := l.GetArms("t1")
+	if arms[0].ID != "a" || arms[0].Value != 0.375 || arms[1].Value != 0.125 {
+		t.Errorf("Expected credit shared by eligibility, got %+v", arms)
+	}
+	if adjustments[0].Priority != 2 {
+		t.Errorf("Expected a priority adjustment of 2, got %d", adjustments[0].Priority)
+	}
+}
+
+func TestFeedbackCreditsDerivingRule(t *testing.T) {
+	l := NewLearner(Config{LearningRate: 1, MinRuleWeight: 0.1})
+
+	l.RecordDerivation("t1", "deduction", "atom-1")
+	l.RecordDerivation("t1", "induction", "atom-2")
+
+	adjustments, err := l.Feedback(Feedback{TenantID: "t1", Outcome: OutcomeAnomalyDismissed, AtomIDs: []string{"atom-2"}})
+	if err != nil {
+		t.Fatalf("Feedback failed: %v", err)
+	}
+	if len(adjustments) != 1 || adjustments[0].ID != "induction" || adjustments[0].Weight != 0.1 {
+		t.Errorf("Expected only the deriving rule to drop to the minimum weight, got %+v", adjustments)
+	}
+
+	if _, err := l.Feedback(Feedback{TenantID: "t1", Outcome: "unknown"}); err == nil {
+		t.Error("Expected an unknown outcome without a reward to be rejected")
+	}
+}
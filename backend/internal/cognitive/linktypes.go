@@ -0,0 +1,57 @@
+package cognitive
+
+import (
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/impact"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/linktypes"
+)
+
+// SetLinkType creates or replaces the semantics of one of a tenant's
+// relations, overriding the default if there is one
+func (ce *CognitiveEngine) SetLinkType(tenantID string, lt linktypes.LinkType) (linktypes.LinkType, error) {
+	return ce.linkTypes.Set(tenantID, lt)
+}
+
+// GetLinkType returns the semantics of one of a tenant's relations
+func (ce *CognitiveEngine) GetLinkType(tenantID, name string) (linktypes.LinkType, error) {
+	return ce.linkTypes.Get(tenantID, name)
+}
+
+// ListLinkTypes returns a tenant's link types, defaults included
+func (ce *CognitiveEngine) ListLinkTypes(tenantID string) []linktypes.LinkType {
+	return ce.linkTypes.List(tenantID)
+}
+
+// DeleteLinkType removes a tenant's link type, reverting the relation to
+// its default semantics
+func (ce *CognitiveEngine) DeleteLinkType(tenantID, name string) error {
+	return ce.linkTypes.Delete(tenantID, name)
+}
+
+// ImpactRelations returns the relations impact propagates over in a
+// tenant's space, from its link types
+func (ce *CognitiveEngine) ImpactRelations(tenantID string) map[string]impact.Relation {
+	return ce.linkTypes.Table(tenantID).Relations()
+}
+
+// FindPath returns the cheapest path between two atoms, given by ID or
+// concept name, over a tenant's relations weighted by the traversal cost of
+// their link types
+func (ce *CognitiveEngine) FindPath(tenantID, from, to string, opts linktypes.PathOptions) (*linktypes.Path, error) {
+	if from == "" || to == "" {
+		return nil, fmt.Errorf("from and to atoms are required")
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if atom, err := ce.GetAtom(from, tenantID); err == nil {
+		from = atom.GetName()
+	}
+	if atom, err := ce.GetAtom(to, tenantID); err == nil {
+		to = atom.GetName()
+	}
+
+	graph := linktypes.NewGraph(ce.QueryAtoms(tenantID, nil), ce.linkTypes.Table(tenantID), opts)
+	return graph.ShortestPath(from, to)
+}
@@ -0,0 +1,238 @@
+package linktypes
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/impact"
+)
+
+// InheritanceRelation is the link type of inheritance links
+const InheritanceRelation = impact.InheritanceRelation
+
+// DefaultCost is the traversal cost of link types without semantics
+const DefaultCost = 1.0
+
+// LinkType gives a relation its semantics: binary EvaluationLinks whose
+// predicate is Name, or inheritance links for InheritanceRelation. Path
+// queries, blast-radius analysis and the pattern miner read them instead of
+// treating every relation alike.
+type LinkType struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Weight      float64          `json:"weight"`    // In [0, 1], how much impact propagates over the relation; 0 stops it
+	Direction   impact.Direction `json:"direction"` // Which way impact propagates; both for symmetric relations
+	Cost        float64          `json:"cost"`      // Cost of one hop in path queries
+	Transitive  bool             `json:"transitive"`
+	Symmetric   bool             `json:"symmetric"`
+}
+
+// Validate checks a link type for consistency and fills in defaults
+func (lt *LinkType) Validate() error {
+	if lt.Name == "" {
+		return fmt.Errorf("link type name is required")
+	}
+	if lt.Weight < 0 || lt.Weight > 1 {
+		return fmt.Errorf("weight of %s must be between 0 and 1", lt.Name)
+	}
+	if lt.Direction == "" {
+		lt.Direction = impact.Dependents
+		if lt.Symmetric {
+			lt.Direction = impact.Both
+		}
+	}
+	switch lt.Direction {
+	case impact.Dependents, impact.Dependencies:
+		if lt.Symmetric {
+			return fmt.Errorf("symmetric link type %s must propagate both ways", lt.Name)
+		}
+	case impact.Both:
+	default:
+		return fmt.Errorf("invalid direction of %s: %q", lt.Name, lt.Direction)
+	}
+	if lt.Cost == 0 {
+		lt.Cost = DefaultCost
+	}
+	if lt.Cost < 0 {
+		return fmt.Errorf("cost of %s must be positive", lt.Name)
+	}
+	return nil
+}
+
+// Defaults returns the semantics of the relations the engine and its
+// pipelines create. Structural relations are transitive, e.g. a pod running
+// on a VM running on a host; connections are symmetric and cost more to
+// traverse, so paths prefer dependencies over network adjacency.
+func Defaults() map[string]LinkType {
+	defaults := map[string]LinkType{
+		"runs_on":           {Description: "A workload runs on a node", Cost: 1, Transitive: true},
+		"depends_on":        {Description: "A component depends on another", Cost: 1, Transitive: true},
+		"routes_to":         {Description: "Traffic is routed to a target", Cost: 1, Transitive: true},
+		"calls":             {Description: "A service calls another", Cost: 1, Transitive: true},
+		"member_of":         {Description: "A resource belongs to a group", Cost: 1, Transitive: true},
+		"connects_to":       {Description: "Two resources are connected", Cost: 2, Symmetric: true},
+		"observes":          {Description: "A resource was observed with an attribute value", Cost: 3},
+		"declares":          {Description: "A resource declares an attribute value", Cost: 3},
+		InheritanceRelation: {Description: "An atom is a kind of another", Cost: 1, Transitive: true},
+	}
+	for name, relation := range impact.DefaultRelations() {
+		lt := defaults[name]
+		lt.Name = name
+		lt.Weight = relation.Weight
+		lt.Direction = relation.Direction
+		defaults[name] = lt
+	}
+	return defaults
+}
+
+// Table is a tenant's link types by name
+type Table map[string]LinkType
+
+// Lookup returns the semantics of a relation. Relations without a link type
+// are traversed forward at the default cost, are not transitive and do not
+// propagate impact.
+func (t Table) Lookup(name string) (LinkType, bool) {
+	if lt, ok := t[name]; ok {
+		return lt, true
+	}
+	return LinkType{Name: name, Direction: impact.Dependents, Cost: DefaultCost}, false
+}
+
+// Relations returns the impact relations of the link types that propagate
+// impact
+func (t Table) Relations() map[string]impact.Relation {
+	relations := make(map[string]impact.Relation, len(t))
+	for name, lt := range t {
+		if lt.Weight > 0 {
+			relations[name] = impact.Relation{Weight: lt.Weight, Direction: lt.Direction, Intransitive: !lt.Transitive}
+		}
+	}
+	return relations
+}
+
+// Registry holds the link types each tenant overrides or adds on top of the
+// defaults
+type Registry struct {
+	defaults  Table
+	overrides map[string]Table // tenantID -> name -> link type
+	mu        sync.RWMutex
+}
+
+// NewRegistry creates a registry over the default link types
+func NewRegistry() *Registry {
+	return &Registry{
+		defaults:  Defaults(),
+		overrides: make(map[string]Table),
+	}
+}
+
+// Set creates or replaces a tenant's link type
+func (r *Registry) Set(tenantID string, lt LinkType) (LinkType, error) {
+	if err := lt.Validate(); err != nil {
+		return LinkType{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tenant, exists := r.overrides[tenantID]
+	if !exists {
+		tenant = make(Table)
+		r.overrides[tenantID] = tenant
+	}
+	tenant[lt.Name] = lt
+	return lt, nil
+}
+
+// Get returns a tenant's link type, overridden or default
+func (r *Registry) Get(tenantID, name string) (LinkType, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if lt, exists := r.overrides[tenantID][name]; exists {
+		return lt, nil
+	}
+	if lt, exists := r.defaults[name]; exists {
+		return lt, nil
+	}
+	return LinkType{}, fmt.Errorf("link type %s not found", name)
+}
+
+// Table returns a tenant's link types: the defaults with its own on top
+func (r *Registry) Table(tenantID string) Table {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	table := make(Table, len(r.defaults)+len(r.overrides[tenantID]))
+	for name, lt := range r.defaults {
+		table[name] = lt
+	}
+	for name, lt := range r.overrides[tenantID] {
+		table[name] = lt
+	}
+	return table
+}
+
+// List returns a tenant's link types sorted by name
+func (r *Registry) List(tenantID string) []LinkType {
+	return sorted(r.Table(tenantID))
+}
+
+// Overrides returns the link types a tenant set, sorted by name
+func (r *Registry) Overrides(tenantID string) []LinkType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sorted(r.overrides[tenantID])
+}
+
+// Delete removes a tenant's link type, reverting to the default if there
+// is one
+func (r *Registry) Delete(tenantID, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.overrides[tenantID][name]; !exists {
+		return fmt.Errorf("link type %s not set", name)
+	}
+	delete(r.overrides[tenantID], name)
+	if len(r.overrides[tenantID]) == 0 {
+		delete(r.overrides, tenantID)
+	}
+	return nil
+}
+
+// Purge deletes all link types of a tenant and returns how many were removed
+func (r *Registry) Purge(tenantID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := len(r.overrides[tenantID])
+	delete(r.overrides, tenantID)
+	return purged
+}
+
+// GetStats returns registry statistics
+func (r *Registry) GetStats() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	overrides := 0
+	for _, tenant := range r.overrides {
+		overrides += len(tenant)
+	}
+	return map[string]interface{}{
+		"defaults":  len(r.defaults),
+		"tenants":   len(r.overrides),
+		"overrides": overrides,
+	}
+}
+
+func sorted(table Table) []LinkType {
+	result := make([]LinkType, 0, len(table))
+	for _, lt := range table {
+		result = append(result, lt)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
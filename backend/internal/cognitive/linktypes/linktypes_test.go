@@ -0,0 +1,130 @@
+package linktypes
+
+import (
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/impact"
+)
+
+type testSpace struct {
+	atoms []atomspace.Atom
+}
+
+func (s *testSpace) concept(name string) atomspace.Atom {
+	atom := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, "t", atomspace.ConceptNodeType)
+	s.atoms = append(s.atoms, atom)
+	return atom
+}
+
+func (s *testSpace) relate(predicate string, a, b atomspace.Atom) {
+	pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, predicate, nil), predicate, "t", atomspace.PredicateNodeType)
+	outgoing := []atomspace.Atom{pred, a, b}
+	s.atoms = append(s.atoms, atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, predicate, outgoing), predicate, "t", atomspace.EvaluationLinkType, outgoing))
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	if lt, err := r.Get("acme", "connects_to"); err != nil || !lt.Symmetric || lt.Direction != impact.Both {
+		t.Fatalf("expected the default connects_to, got %+v, %v", lt, err)
+	}
+
+	if _, err := r.Set("acme", LinkType{Name: "peers_with", Symmetric: true, Weight: 0.4}); err != nil {
+		t.Fatalf("failed to set link type: %v", err)
+	}
+	lt, _ := r.Get("acme", "peers_with")
+	if lt.Direction != impact.Both || lt.Cost != DefaultCost {
+		t.Errorf("expected defaults filled in, got %+v", lt)
+	}
+	if _, err := r.Set("acme", LinkType{Name: "runs_on", Weight: 0}); err != nil {
+		t.Fatalf("failed to override link type: %v", err)
+	}
+	for _, invalid := range []LinkType{
+		{Weight: 0.5},
+		{Name: "a", Weight: 2},
+		{Name: "a", Cost: -1},
+		{Name: "a", Symmetric: true, Direction: impact.Dependents},
+		{Name: "a", Direction: "sideways"},
+	} {
+		if _, err := r.Set("acme", invalid); err == nil {
+			t.Errorf("expected %+v rejected", invalid)
+		}
+	}
+
+	relations := r.Table("acme").Relations()
+	if _, ok := relations["runs_on"]; ok {
+		t.Error("expected a zero weight to stop impact over runs_on")
+	}
+	if relations["observes"].Intransitive != true || relations["depends_on"].Intransitive {
+		t.Errorf("expected transitivity carried to impact relations, got %+v", relations)
+	}
+	if _, ok := r.Table("other")["peers_with"]; ok {
+		t.Error("expected link types kept per tenant")
+	}
+	if len(r.Overrides("acme")) != 2 || len(r.List("acme")) != len(Defaults())+1 {
+		t.Errorf("expected 2 overrides, got %+v", r.Overrides("acme"))
+	}
+
+	if err := r.Delete("acme", "runs_on"); err != nil {
+		t.Fatalf("failed to delete link type: %v", err)
+	}
+	if lt, _ := r.Get("acme", "runs_on"); lt.Weight != impact.DefaultRelations()["runs_on"].Weight {
+		t.Errorf("expected the default restored, got %+v", lt)
+	}
+	if err := r.Delete("acme", "runs_on"); err == nil {
+		t.Error("expected deleting a default link type to fail")
+	}
+	if purged := r.Purge("acme"); purged != 1 {
+		t.Errorf("expected 1 link type purged, got %d", purged)
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	// frontend calls cart and is connected to cache, which cart depends on
+	s := &testSpace{}
+	frontend, cart, cache, db := s.concept("frontend"), s.concept("cart"), s.concept("cache"), s.concept("db")
+	s.relate("calls", frontend, cart)
+	s.relate("depends_on", cart, cache)
+	s.relate("connects_to", cache, frontend)
+	s.relate("observes", cart, db)
+	s.relate("observes", db, frontend)
+
+	table := NewRegistry().Table("t")
+	opts := DefaultPathOptions()
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("expected the defaults to be valid: %v", err)
+	}
+
+	// connects_to is symmetric and cheaper than the detour
+	path, err := NewGraph(s.atoms, table, opts).ShortestPath("frontend", "cache")
+	if err != nil {
+		t.Fatalf("ShortestPath failed: %v", err)
+	}
+	if len(path.Hops) != 1 || path.Cost != 2 || path.Hops[0].Relation != "connects_to" || path.Hops[0].Reversed {
+		t.Errorf("expected the symmetric connection, got %+v", path)
+	}
+
+	// Relations are followed forward only
+	directed := PathOptions{MaxHops: 10, Relations: []string{"calls", "depends_on"}}
+	if _, err := NewGraph(s.atoms, table, directed).ShortestPath("cart", "frontend"); err == nil {
+		t.Error("expected no path against the direction of calls")
+	}
+	undirected := PathOptions{MaxHops: 10, Relations: []string{"calls"}, Undirected: true}
+	if path, err := NewGraph(s.atoms, table, undirected).ShortestPath("cart", "frontend"); err != nil || !path.Hops[0].Reversed {
+		t.Errorf("expected an undirected path, got %+v, %v", path, err)
+	}
+
+	// observes is intransitive, so cart does not reach frontend through db
+	observes := PathOptions{MaxHops: 10, Relations: []string{"observes"}}
+	if _, err := NewGraph(s.atoms, table, observes).ShortestPath("cart", "frontend"); err == nil {
+		t.Error("expected an intransitive relation not to chain")
+	}
+
+	opts.MaxCost = 1
+	if _, err := NewGraph(s.atoms, table, opts).ShortestPath("frontend", "cache"); err == nil {
+		t.Error("expected paths above the maximum cost dropped")
+	}
+	if _, err := NewGraph(s.atoms, table, opts).ShortestPath("frontend", "missing"); err == nil {
+		t.Error("expected an unknown atom to fail")
+	}
+}
@@ -0,0 +1,208 @@
+package linktypes
+
+import (
+	"container/heap"
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// MaxHops bounds the length of a path
+const MaxHops = 10
+
+// PathOptions controls a path query
+type PathOptions struct {
+	MaxHops    int      `json:"max_hops"`
+	MaxCost    float64  `json:"max_cost,omitempty"`   // Paths costing more are not followed; unbounded if 0
+	Relations  []string `json:"relations,omitempty"`  // Relations that may be traversed; all if empty
+	Undirected bool     `json:"undirected,omitempty"` // Also traverse asymmetric relations backwards
+}
+
+// DefaultPathOptions returns the default path options
+func DefaultPathOptions() PathOptions {
+	return PathOptions{MaxHops: 6}
+}
+
+// Validate checks the options
+func (o *PathOptions) Validate() error {
+	if o.MaxHops < 1 || o.MaxHops > MaxHops {
+		return fmt.Errorf("max_hops must be between 1 and %d", MaxHops)
+	}
+	if o.MaxCost < 0 {
+		return fmt.Errorf("max_cost must not be negative")
+	}
+	return nil
+}
+
+// Hop is one traversed link of a path
+type Hop struct {
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Relation string  `json:"relation"`
+	LinkID   string  `json:"link_id"`
+	Reversed bool    `json:"reversed,omitempty"` // Traversed from the second argument to the first
+	Cost     float64 `json:"cost"`
+}
+
+// Path is the cheapest path between two atoms
+type Path struct {
+	From string  `json:"from"`
+	To   string  `json:"to"`
+	Cost float64 `json:"cost"`
+	Hops []Hop   `json:"hops"`
+}
+
+type pathEdge struct {
+	hop        Hop
+	transitive bool
+}
+
+// Graph is a tenant's atoms connected by their binary relations
+type Graph struct {
+	opts  PathOptions
+	atoms map[string]atomspace.Atom // name -> atom
+	edges map[string][]pathEdge     // name -> traversable links
+}
+
+// NewGraph builds the path graph of atoms: binary EvaluationLinks connect
+// their arguments over their predicate and inheritance links a child to its
+// parent. Symmetric relations are traversable both ways, others forward
+// unless opts is undirected.
+func NewGraph(atoms []atomspace.Atom, table Table, opts PathOptions) *Graph {
+	allowed := make(map[string]bool, len(opts.Relations))
+	for _, relation := range opts.Relations {
+		allowed[relation] = true
+	}
+
+	g := &Graph{
+		opts:  opts,
+		atoms: make(map[string]atomspace.Atom),
+		edges: make(map[string][]pathEdge),
+	}
+	for _, atom := range atoms {
+		if atom.GetType() == atomspace.ConceptNodeType {
+			g.atoms[atom.GetName()] = atom
+		}
+	}
+	for _, atom := range atoms {
+		link, ok := atom.(*atomspace.Link)
+		if !ok || link.GetTruthValue().Strength <= 0 {
+			continue
+		}
+		relation, a, b, ok := Binary(link)
+		if !ok || (len(allowed) > 0 && !allowed[relation]) {
+			continue
+		}
+		lt, _ := table.Lookup(relation)
+		for _, arg := range []atomspace.Atom{a, b} {
+			if _, exists := g.atoms[arg.GetName()]; !exists {
+				g.atoms[arg.GetName()] = arg
+			}
+		}
+		g.edges[a.GetName()] = append(g.edges[a.GetName()], pathEdge{
+			hop:        Hop{From: a.GetName(), To: b.GetName(), Relation: relation, LinkID: link.GetID(), Cost: lt.Cost},
+			transitive: lt.Transitive,
+		})
+		if lt.Symmetric || opts.Undirected {
+			g.edges[b.GetName()] = append(g.edges[b.GetName()], pathEdge{
+				hop:        Hop{From: b.GetName(), To: a.GetName(), Relation: relation, LinkID: link.GetID(), Reversed: !lt.Symmetric, Cost: lt.Cost},
+				transitive: lt.Transitive,
+			})
+		}
+	}
+	return g
+}
+
+// Binary returns the relation of a binary link and its arguments in order:
+// the predicate of an EvaluationLink, or InheritanceRelation from child to
+// parent
+func Binary(link *atomspace.Link) (string, atomspace.Atom, atomspace.Atom, bool) {
+	outgoing := link.GetOutgoing()
+	switch {
+	case link.GetType() == atomspace.InheritanceLinkType && len(outgoing) == 2:
+		return InheritanceRelation, outgoing[0], outgoing[1], true
+	case link.GetType() == atomspace.EvaluationLinkType && len(outgoing) == 3 && outgoing[0].GetType() == atomspace.PredicateNodeType:
+		return outgoing[0].GetName(), outgoing[1], outgoing[2], true
+	}
+	return "", nil, nil, false
+}
+
+// pathState is an atom reached after a number of hops, over a relation that
+// may not be followed again when it is intransitive
+type pathState struct {
+	name    string
+	hops    int
+	blocked string
+}
+
+type pathCandidate struct {
+	state pathState
+	cost  float64
+	path  []Hop
+}
+
+type pathQueue []pathCandidate
+
+func (q pathQueue) Len() int { return len(q) }
+func (q pathQueue) Less(i, j int) bool {
+	if q[i].cost != q[j].cost {
+		return q[i].cost < q[j].cost
+	}
+	return len(q[i].path) < len(q[j].path)
+}
+func (q pathQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x interface{}) { *q = append(*q, x.(pathCandidate)) }
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// ShortestPath returns the cheapest path of at most MaxHops from one atom
+// to another, by the sum of the link types' costs. Intransitive relations
+// are not followed twice in a row.
+func (g *Graph) ShortestPath(from, to string) (*Path, error) {
+	opts := g.opts
+	if _, exists := g.atoms[from]; !exists {
+		return nil, fmt.Errorf("atom %s not found", from)
+	}
+	if _, exists := g.atoms[to]; !exists {
+		return nil, fmt.Errorf("atom %s not found", to)
+	}
+
+	visited := make(map[pathState]bool)
+	queue := &pathQueue{{state: pathState{name: from}}}
+	for queue.Len() > 0 {
+		c := heap.Pop(queue).(pathCandidate)
+		if c.state.name == to {
+			return &Path{From: from, To: to, Cost: c.cost, Hops: c.path}, nil
+		}
+		if visited[c.state] {
+			continue
+		}
+		visited[c.state] = true
+		if c.state.hops >= opts.MaxHops {
+			continue
+		}
+		for _, e := range g.edges[c.state.name] {
+			if e.hop.Relation == c.state.blocked {
+				continue
+			}
+			cost := c.cost + e.hop.Cost
+			if opts.MaxCost > 0 && cost > opts.MaxCost {
+				continue
+			}
+			next := pathState{name: e.hop.To, hops: c.state.hops + 1}
+			if !e.transitive {
+				next.blocked = e.hop.Relation
+			}
+			if visited[next] {
+				continue
+			}
+			path := append(append([]Hop(nil), c.path...), e.hop)
+			heap.Push(queue, pathCandidate{state: next, cost: cost, path: path})
+		}
+	}
+	return nil, fmt.Errorf("no path from %s to %s within %d hops", from, to, opts.MaxHops)
+}
@@ -0,0 +1,115 @@
+package cognitive
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/livestats"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/trends"
+)
+
+// SubscribeStats subscribes to the live statistics of a tenant or, for an
+// empty tenant ID, of the engine, collected every interval. Unlike
+// GetStats, only cheap counters are collected: atom counts, queue depths,
+// agent runs and failures. Close the subscription when done.
+func (ce *CognitiveEngine) SubscribeStats(tenantID string, interval time.Duration) (*livestats.Subscription, error) {
+	if tenantID != "" {
+		ce.mu.RLock()
+		_, initialized := ce.inferenceEngines[tenantID]
+		ce.mu.RUnlock()
+		if !initialized {
+			return nil, fmt.Errorf("tenant %s not initialized", tenantID)
+		}
+	}
+	return ce.liveStats.Subscribe(tenantID, interval), nil
+}
+
+// collectLiveStats collects the live statistics of a tenant or, for an
+// empty tenant ID, of the engine
+func (ce *CognitiveEngine) collectLiveStats(tenantID string) map[string]float64 {
+	stats := map[string]interface{}{
+		"agents":    ce.liveAgentStats(tenantID),
+		"pipelines": ce.livePipelineStats(tenantID),
+	}
+	if tenantID == "" {
+		shards := ce.shardManager.GetShardStats()
+		events := ce.eventBus.GetStats()
+		stats["atoms"] = shards["total_load"]
+		stats["events"] = map[string]interface{}{
+			"published": events["published"],
+			"pending":   events["pending"],
+		}
+		stats["dead_letters"] = ce.deadLetters.GetStats()
+		stats["sessions"] = ce.sessionManager.GetStats()
+		stats["stream"] = ce.liveStats.GetStats()
+	} else {
+		stats["tenant"] = ce.shardManager.GetTenantStats(tenantID)
+		stats["metering"] = ce.meter.Totals()[tenantID]
+	}
+	return trends.Flatten(stats)
+}
+
+// liveAgentStats counts the agents of a tenant, or of all tenants, with
+// their runs and health
+func (ce *CognitiveEngine) liveAgentStats(tenantID string) map[string]interface{} {
+	var registered []agents.Agent
+	if tenantID == "" {
+		registered = ce.agentScheduler.GetAllAgents()
+	} else {
+		registered = ce.agentScheduler.GetAgentsByTenant(tenantID)
+	}
+	var runs, running int64
+	for _, agent := range registered {
+		stats := agent.GetStats()
+		if count, ok := stats["run_count"].(int64); ok {
+			runs += count
+		}
+		if state, ok := stats["state"].(agents.AgentState); ok && state == agents.AgentStateRunning {
+			running++
+		}
+	}
+
+	var failures int64
+	health := make(map[agents.HealthState]int)
+	for _, h := range ce.agentScheduler.GetHealth(tenantID) {
+		health[h.State]++
+		failures += h.TotalFailures
+	}
+	return map[string]interface{}{
+		"total":    len(registered),
+		"running":  running,
+		"runs":     runs,
+		"failures": failures,
+		"health":   health,
+	}
+}
+
+// livePipelineStats sums the queued and running executions of the
+// pipelines of a tenant, or of all tenants
+func (ce *CognitiveEngine) livePipelineStats(tenantID string) map[string]interface{} {
+	var pipelines []map[string]interface{}
+	if tenantID == "" {
+		pipelines, _ = ce.pipelineOrch.GetStats()["pipelines"].([]map[string]interface{})
+	} else {
+		for _, p := range ce.pipelineOrch.GetPipelinesByTenant(tenantID) {
+			pipelines = append(pipelines, p.GetStats())
+		}
+	}
+	totals := map[string]interface{}{"total": len(pipelines)}
+	var queued, running int
+	var executions int64
+	for _, stats := range pipelines {
+		if n, ok := stats["queued"].(int); ok {
+			queued += n
+		}
+		if n, ok := stats["running"].(int); ok {
+			running += n
+		}
+		if n, ok := stats["executions"].(int64); ok {
+			executions += n
+		}
+	}
+	totals["queued"], totals["running"], totals["executions"] = queued, running, executions
+	return totals
+}
@@ -0,0 +1,200 @@
+package livestats
+
+import (
+	"sync"
+	"time"
+)
+
+// MinInterval is the shortest interval statistics are collected at
+const MinInterval = 500 * time.Millisecond
+
+// Collector returns the numeric statistics of a scope, such as a tenant,
+// by dotted path
+type Collector func(scope string) map[string]float64
+
+// Update is sent to subscribers every interval. The first update of a
+// subscription is full; later ones hold only the values that changed and
+// the paths that disappeared, unless the subscriber fell behind and
+// missed updates, when it is sent a full one again.
+type Update struct {
+	Scope   string             `json:"scope"`
+	At      time.Time          `json:"at"`
+	Full    bool               `json:"full"`
+	Values  map[string]float64 `json:"values"`
+	Removed []string           `json:"removed,omitempty"`
+}
+
+// Hub collects statistics once per scope and interval, however many
+// subscribers want them, and fans them out as changes
+type Hub struct {
+	collect Collector
+	feeds   map[feedKey]*feed
+	closed  bool
+	mu      sync.Mutex
+}
+
+type feedKey struct {
+	scope    string
+	interval time.Duration
+}
+
+// feed collects a scope's statistics every interval while it has
+// subscribers
+type feed struct {
+	key         feedKey
+	subscribers map[*Subscription]bool
+	last        map[string]float64 // Last values collected; nil until the first collection
+	stop        chan struct{}
+}
+
+// Subscription receives the updates of a scope. C is closed when the
+// subscription or the hub is.
+type Subscription struct {
+	C      <-chan Update
+	c      chan Update
+	hub    *Hub
+	feed   *feed
+	behind bool // Missed an update, so the next one is full
+	closed bool
+}
+
+// NewHub creates a hub collecting statistics with a collector
+func NewHub(collect Collector) *Hub {
+	return &Hub{collect: collect, feeds: make(map[feedKey]*feed)}
+}
+
+// Subscribe subscribes to the statistics of a scope every interval, at
+// least MinInterval. The first update arrives at once.
+func (h *Hub) Subscribe(scope string, interval time.Duration) *Subscription {
+	if interval < MinInterval {
+		interval = MinInterval
+	}
+	key := feedKey{scope: scope, interval: interval}
+	c := make(chan Update, 1)
+	sub := &Subscription{C: c, c: c, hub: h, behind: true}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		sub.closed = true
+		close(c)
+		return sub
+	}
+	f := h.feeds[key]
+	if f == nil {
+		f = &feed{key: key, subscribers: make(map[*Subscription]bool), stop: make(chan struct{})}
+		h.feeds[key] = f
+		go h.run(f)
+	} else if f.last != nil {
+		sub.send(Update{Scope: scope, At: time.Now(), Values: f.last})
+	}
+	sub.feed = f
+	f.subscribers[sub] = true
+	return sub
+}
+
+// Close ends a subscription. The feed stops once it has no subscribers.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.c)
+	delete(s.feed.subscribers, s)
+	if len(s.feed.subscribers) == 0 {
+		close(s.feed.stop)
+		delete(s.hub.feeds, s.feed.key)
+	}
+}
+
+// Close stops all feeds and ends their subscriptions
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for key, f := range h.feeds {
+		for sub := range f.subscribers {
+			sub.closed = true
+			close(sub.c)
+		}
+		close(f.stop)
+		delete(h.feeds, key)
+	}
+}
+
+// run collects a feed's statistics every interval until it is stopped
+func (h *Hub) run(f *feed) {
+	ticker := time.NewTicker(f.key.interval)
+	defer ticker.Stop()
+	for {
+		values := h.collect(f.key.scope)
+		at := time.Now()
+
+		h.mu.Lock()
+		if h.feeds[f.key] != f {
+			// Stopped while collecting
+			h.mu.Unlock()
+			return
+		}
+		delta := Update{Scope: f.key.scope, At: at, Values: make(map[string]float64)}
+		for path, value := range values {
+			if previous, exists := f.last[path]; !exists || previous != value {
+				delta.Values[path] = value
+			}
+		}
+		for path := range f.last {
+			if _, exists := values[path]; !exists {
+				delta.Removed = append(delta.Removed, path)
+			}
+		}
+		f.last = values
+		for sub := range f.subscribers {
+			if sub.behind {
+				sub.send(Update{Scope: f.key.scope, At: at, Values: values})
+			} else if len(delta.Values) > 0 || len(delta.Removed) > 0 {
+				sub.send(delta)
+			}
+		}
+		h.mu.Unlock()
+
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// send delivers an update unless the subscriber has not taken the last
+// one, in which case it gets a full update next. The hub is locked.
+func (s *Subscription) send(u Update) {
+	if s.behind {
+		u.Full, u.Removed = true, nil
+	}
+	select {
+	case s.c <- u:
+		s.behind = false
+	default:
+		s.behind = true
+	}
+}
+
+// GetStats returns hub statistics
+func (h *Hub) GetStats() map[string]interface{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subscribers := 0
+	for _, f := range h.feeds {
+		subscribers += len(f.subscribers)
+	}
+	return map[string]interface{}{
+		"feeds":       len(h.feeds),
+		"subscribers": subscribers,
+	}
+}
@@ -0,0 +1,106 @@
+package livestats
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHub(t *testing.T) {
+	var mu sync.Mutex
+	collections := 0
+	values := map[string]float64{"atoms": 1, "agents.runs": 0}
+	hub := NewHub(func(scope string) map[string]float64 {
+		mu.Lock()
+		defer mu.Unlock()
+		collections++
+		collected := make(map[string]float64, len(values))
+		for path, value := range values {
+			collected[path] = value
+		}
+		return collected
+	})
+	receive := func(sub *Subscription) Update {
+		t.Helper()
+		select {
+		case u := <-sub.C:
+			return u
+		case <-time.After(2 * time.Second):
+			t.Fatal("no update received")
+			return Update{}
+		}
+	}
+
+	first := hub.Subscribe("tenant-a", 0)
+	u := receive(first)
+	if !u.Full || u.Scope != "tenant-a" || len(u.Values) != 2 || u.Values["atoms"] != 1 {
+		t.Fatalf("first update = %+v, want a full one", u)
+	}
+
+	// A second subscriber shares the feed and starts with the last values
+	second := hub.Subscribe("tenant-a", MinInterval)
+	if u := receive(second); !u.Full || u.Values["atoms"] != 1 {
+		t.Fatalf("second subscriber's first update = %+v", u)
+	}
+	if stats := hub.GetStats(); stats["feeds"] != 1 || stats["subscribers"] != 2 {
+		t.Errorf("stats = %v", stats)
+	}
+
+	// Only the changes follow
+	mu.Lock()
+	values["atoms"] = 2
+	delete(values, "agents.runs")
+	mu.Unlock()
+	u = receive(first)
+	if u.Full || len(u.Values) != 1 || u.Values["atoms"] != 2 || len(u.Removed) != 1 || u.Removed[0] != "agents.runs" {
+		t.Fatalf("delta = %+v", u)
+	}
+
+	// The second subscriber did not take its update, so it missed one and
+	// is sent the full values again
+	mu.Lock()
+	values["atoms"] = 3
+	mu.Unlock()
+	receive(first)
+	receive(second)
+	if u := receive(second); !u.Full || u.Values["atoms"] != 3 || len(u.Values) != 1 {
+		t.Fatalf("update after falling behind = %+v, want a full one", u)
+	}
+
+	// The feed stops with its last subscriber
+	first.Close()
+	second.Close()
+	second.Close()
+	if stats := hub.GetStats(); stats["feeds"] != 0 || stats["subscribers"] != 0 {
+		t.Errorf("stats after closing = %v", stats)
+	}
+	time.Sleep(MinInterval + 100*time.Millisecond)
+	mu.Lock()
+	stopped := collections
+	mu.Unlock()
+	time.Sleep(MinInterval + 100*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if collections != stopped {
+		t.Errorf("collections went on after the last subscriber left")
+	}
+}
+
+func TestHubClose(t *testing.T) {
+	hub := NewHub(func(scope string) map[string]float64 {
+		return map[string]float64{"atoms": 1}
+	})
+	sub := hub.Subscribe("", time.Second)
+	hub.Close()
+	for range sub.C {
+	}
+	sub.Close()
+
+	// Subscribing to a closed hub ends at once
+	if _, open := <-hub.Subscribe("", time.Second).C; open {
+		t.Error("subscription to a closed hub received an update")
+	}
+	if stats := hub.GetStats(); stats["feeds"] != 0 {
+		t.Errorf("stats after closing = %v", stats)
+	}
+}
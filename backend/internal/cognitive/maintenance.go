@@ -0,0 +1,84 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/maintenance"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+)
+
+// AddMaintenanceWindow schedules a maintenance or freeze window for a
+// tenant. While it is active, the calls of the tenant's execution agent
+// are held and recorded as counterfactuals, trigger and runbook actions
+// other than notifications are refused, submitted and scheduled pipeline
+// executions are pushed back to its end, and the agents it names are left
+// out of scheduling cycles.
+func (ce *CognitiveEngine) AddMaintenanceWindow(tenantID string, w maintenance.Window, p acl.Principal) (maintenance.Window, error) {
+	w.CreatedBy = p.User
+	return ce.maintenance.Add(tenantID, w)
+}
+
+// GetMaintenanceWindow returns a maintenance window of a tenant
+func (ce *CognitiveEngine) GetMaintenanceWindow(tenantID, id string) (maintenance.Window, error) {
+	return ce.maintenance.Get(tenantID, id)
+}
+
+// ListMaintenanceWindows returns a tenant's maintenance windows
+func (ce *CognitiveEngine) ListMaintenanceWindows(tenantID string) []maintenance.Window {
+	return ce.maintenance.List(tenantID)
+}
+
+// DeleteMaintenanceWindow removes a maintenance window of a tenant. Held
+// actions and pipelines resume once no other window holds them.
+func (ce *CognitiveEngine) DeleteMaintenanceWindow(tenantID, id string) error {
+	return ce.maintenance.Delete(tenantID, id)
+}
+
+// holdActions returns why a tenant's schema calls are held, if they are
+func (ce *CognitiveEngine) holdActions(tenantID string) string {
+	if w, held := ce.maintenance.HoldsActions(tenantID, time.Now()); held {
+		return w.Describe()
+	}
+	return ""
+}
+
+// holdPipelines returns until when a tenant's submitted and scheduled
+// pipeline executions are held, and why
+func (ce *CognitiveEngine) holdPipelines(tenantID string) (time.Time, string) {
+	if w, held := ce.maintenance.HoldsPipelines(tenantID, time.Now()); held {
+		return w.End, w.Describe()
+	}
+	return time.Time{}, ""
+}
+
+// agentPaused reports whether a maintenance window pauses an agent
+func (ce *CognitiveEngine) agentPaused(agent agents.Agent) bool {
+	_, paused := ce.maintenance.PausesAgent(agent.GetTenantID(), agent.GetID(), agent.GetName(), time.Now())
+	return paused
+}
+
+// maintenancePerformer refuses the trigger and runbook actions of a tenant
+// held by a maintenance window. Notifications are still sent, and pipeline
+// actions are refused while pipelines are held.
+type maintenancePerformer struct {
+	engine   *CognitiveEngine
+	tenantID string
+	next     triggers.Performer
+}
+
+func (p *maintenancePerformer) Perform(ctx context.Context, action triggers.Action, input []atomspace.Atom, payload map[string]interface{}) error {
+	now := time.Now()
+	w, held := p.engine.maintenance.HoldsActions(p.tenantID, now)
+	if !held && action.Type == triggers.ActionExecutePipeline {
+		w, held = p.engine.maintenance.HoldsPipelines(p.tenantID, now)
+	}
+	if held && action.Type != triggers.ActionNotify {
+		return fmt.Errorf("%s action %s", action.Type, w.Describe())
+	}
+	return p.next.Perform(ctx, action, input, payload)
+}
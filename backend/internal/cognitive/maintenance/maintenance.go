@@ -0,0 +1,271 @@
+package maintenance
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MaxWindows is the number of windows kept per tenant. Ended windows are
+// dropped, oldest first, to make room for new ones.
+const MaxWindows = 100
+
+// Kinds of windows
+const (
+	KindMaintenance = "maintenance" // Planned work the engine should stay out of
+	KindFreeze      = "freeze"      // A change freeze, e.g. for compliance
+)
+
+// States of a window relative to now
+const (
+	StateScheduled = "scheduled"
+	StateActive    = "active"
+	StateEnded     = "ended"
+)
+
+// Window is a period during which a tenant's autonomous behavior is
+// paused. A window selecting neither actions, pipelines nor agents holds
+// actions and pipelines.
+type Window struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Reason    string    `json:"reason,omitempty"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Actions   bool      `json:"actions"`          // Hold schema calls and trigger and runbook actions
+	Pipelines bool      `json:"pipelines"`        // Hold submitted and scheduled pipeline executions
+	Agents    []string  `json:"agents,omitempty"` // IDs or names of the agents paused
+	State     string    `json:"state,omitempty"`  // Set on the windows returned
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate checks a window
+func (w *Window) Validate() error {
+	switch w.Kind {
+	case KindMaintenance, KindFreeze:
+	default:
+		return fmt.Errorf("unknown window kind: %q", w.Kind)
+	}
+	if w.Start.IsZero() || w.End.IsZero() {
+		return fmt.Errorf("window start and end are required")
+	}
+	if !w.End.After(w.Start) {
+		return fmt.Errorf("window must end after it starts")
+	}
+	for _, agent := range w.Agents {
+		if agent == "" {
+			return fmt.Errorf("window names an empty agent")
+		}
+	}
+	return nil
+}
+
+// StateAt returns the state of the window at a time
+func (w *Window) StateAt(now time.Time) string {
+	switch {
+	case now.Before(w.Start):
+		return StateScheduled
+	case now.Before(w.End):
+		return StateActive
+	}
+	return StateEnded
+}
+
+// PausesAgent reports whether the window pauses an agent
+func (w *Window) PausesAgent(agentID, name string) bool {
+	for _, agent := range w.Agents {
+		if agent == agentID || agent == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Describe returns why a window holds something, for annotations
+func (w *Window) Describe() string {
+	text := fmt.Sprintf("held by %s window %s until %s", w.Kind, w.ID, w.End.UTC().Format(time.RFC3339))
+	if w.Reason != "" {
+		text += ": " + w.Reason
+	}
+	return text
+}
+
+func (w *Window) clone(now time.Time) Window {
+	c := *w
+	c.Agents = append([]string(nil), w.Agents...)
+	c.State = w.StateAt(now)
+	return c
+}
+
+// Manager keeps each tenant's maintenance and freeze windows
+type Manager struct {
+	windows map[string]map[string]*Window // tenantID -> ID -> window
+	seq     int64
+	mu      sync.RWMutex
+}
+
+// NewManager creates a manager without windows
+func NewManager() *Manager {
+	return &Manager{windows: make(map[string]map[string]*Window)}
+}
+
+// Add schedules a window for a tenant and returns it with its ID
+func (m *Manager) Add(tenantID string, w Window) (Window, error) {
+	if err := w.Validate(); err != nil {
+		return Window{}, err
+	}
+	if !w.Actions && !w.Pipelines && len(w.Agents) == 0 {
+		w.Actions, w.Pipelines = true, true
+	}
+	now := time.Now()
+	if !w.End.After(now) {
+		return Window{}, fmt.Errorf("window already ended")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	windows, exists := m.windows[tenantID]
+	if !exists {
+		windows = make(map[string]*Window)
+		m.windows[tenantID] = windows
+	}
+	if len(windows) >= MaxWindows && !dropEndedLocked(windows, now) {
+		return Window{}, fmt.Errorf("tenant %s has %d windows that have not ended", tenantID, len(windows))
+	}
+	m.seq++
+	w.ID = fmt.Sprintf("mw-%d", m.seq)
+	w.CreatedAt = now
+	w.Agents = append([]string(nil), w.Agents...)
+	windows[w.ID] = &w
+	return w.clone(now), nil
+}
+
+// dropEndedLocked removes the window that ended first, reporting whether
+// one had
+func dropEndedLocked(windows map[string]*Window, now time.Time) bool {
+	var oldest *Window
+	for _, w := range windows {
+		if !w.End.After(now) && (oldest == nil || w.End.Before(oldest.End)) {
+			oldest = w
+		}
+	}
+	if oldest == nil {
+		return false
+	}
+	delete(windows, oldest.ID)
+	return true
+}
+
+// Get returns a window of a tenant
+func (m *Manager) Get(tenantID, id string) (Window, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	w, exists := m.windows[tenantID][id]
+	if !exists {
+		return Window{}, fmt.Errorf("maintenance window %s not found", id)
+	}
+	return w.clone(time.Now()), nil
+}
+
+// List returns a tenant's windows by start
+func (m *Manager) List(tenantID string) []Window {
+	now := time.Now()
+	m.mu.RLock()
+	list := make([]Window, 0, len(m.windows[tenantID]))
+	for _, w := range m.windows[tenantID] {
+		list = append(list, w.clone(now))
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Start.Equal(list[j].Start) {
+			return list[i].Start.Before(list[j].Start)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// Delete removes a window of a tenant, ending it if active
+func (m *Manager) Delete(tenantID, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.windows[tenantID][id]; !exists {
+		return fmt.Errorf("maintenance window %s not found", id)
+	}
+	delete(m.windows[tenantID], id)
+	if len(m.windows[tenantID]) == 0 {
+		delete(m.windows, tenantID)
+	}
+	return nil
+}
+
+// Purge removes a tenant's windows, returning how many
+func (m *Manager) Purge(tenantID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	removed := len(m.windows[tenantID])
+	delete(m.windows, tenantID)
+	return removed
+}
+
+// holding returns the active window of a tenant selected by covers that
+// ends last, so that what it holds is released once no window does
+func (m *Manager) holding(tenantID string, now time.Time, covers func(w *Window) bool) (Window, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var found *Window
+	for _, w := range m.windows[tenantID] {
+		if w.StateAt(now) == StateActive && covers(w) && (found == nil || w.End.After(found.End)) {
+			found = w
+		}
+	}
+	if found == nil {
+		return Window{}, false
+	}
+	return found.clone(now), true
+}
+
+// HoldsActions returns the active window holding a tenant's actions
+func (m *Manager) HoldsActions(tenantID string, now time.Time) (Window, bool) {
+	return m.holding(tenantID, now, func(w *Window) bool { return w.Actions })
+}
+
+// HoldsPipelines returns the active window holding a tenant's submitted
+// and scheduled pipeline executions
+func (m *Manager) HoldsPipelines(tenantID string, now time.Time) (Window, bool) {
+	return m.holding(tenantID, now, func(w *Window) bool { return w.Pipelines })
+}
+
+// PausesAgent returns the active window pausing an agent of a tenant
+func (m *Manager) PausesAgent(tenantID, agentID, name string, now time.Time) (Window, bool) {
+	return m.holding(tenantID, now, func(w *Window) bool { return w.PausesAgent(agentID, name) })
+}
+
+// GetStats returns the number of windows and of those active now
+func (m *Manager) GetStats() map[string]interface{} {
+	now := time.Now()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	windows, active, tenants := 0, 0, 0
+	for _, tenant := range m.windows {
+		frozen := false
+		for _, w := range tenant {
+			windows++
+			if w.StateAt(now) == StateActive {
+				active++
+				frozen = true
+			}
+		}
+		if frozen {
+			tenants++
+		}
+	}
+	return map[string]interface{}{
+		"windows":        windows,
+		"active":         active,
+		"tenants_paused": tenants,
+	}
+}
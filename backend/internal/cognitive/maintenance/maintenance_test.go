@@ -0,0 +1,96 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManager(t *testing.T) {
+	m := NewManager()
+	now := time.Now()
+
+	for _, w := range []Window{
+		{Kind: "outage", Start: now, End: now.Add(time.Hour)},
+		{Kind: KindFreeze, End: now.Add(time.Hour)},
+		{Kind: KindFreeze, Start: now, End: now},
+		{Kind: KindFreeze, Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)},
+		{Kind: KindMaintenance, Start: now, End: now.Add(time.Hour), Agents: []string{""}},
+	} {
+		if _, err := m.Add("t", w); err == nil {
+			t.Errorf("expected %+v rejected", w)
+		}
+	}
+
+	freeze, err := m.Add("t", Window{Kind: KindFreeze, Start: now.Add(-time.Minute), End: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if !freeze.Actions || !freeze.Pipelines || freeze.State != StateActive {
+		t.Errorf("expected an active window holding actions and pipelines, got %+v", freeze)
+	}
+	later, _ := m.Add("t", Window{Kind: KindMaintenance, Start: now.Add(-time.Minute), End: now.Add(2 * time.Hour), Pipelines: true, Agents: []string{"drift-t"}})
+	upcoming, _ := m.Add("t", Window{Kind: KindMaintenance, Start: now.Add(time.Hour), End: now.Add(3 * time.Hour), Actions: true})
+	if upcoming.State != StateScheduled || upcoming.Pipelines {
+		t.Errorf("expected a scheduled window holding actions only, got %+v", upcoming)
+	}
+
+	// The window holding the longest is returned
+	if w, held := m.HoldsActions("t", now); !held || w.ID != freeze.ID {
+		t.Errorf("HoldsActions = %+v, %v", w, held)
+	}
+	if w, held := m.HoldsPipelines("t", now); !held || w.ID != later.ID {
+		t.Errorf("HoldsPipelines = %+v, %v", w, held)
+	}
+	if _, paused := m.PausesAgent("t", "drift-t", "DriftAgent", now); !paused {
+		t.Error("expected the agent paused")
+	}
+	if _, paused := m.PausesAgent("t", "cost-t", "CostAgent", now); paused {
+		t.Error("expected another agent not paused")
+	}
+	if _, held := m.HoldsActions("t", now.Add(90*time.Minute)); !held {
+		t.Error("expected the upcoming window to hold actions once active")
+	}
+	if _, held := m.HoldsActions("other", now); held {
+		t.Error("expected another tenant's actions not held")
+	}
+
+	if list := m.List("t"); len(list) != 3 || list[2].ID != upcoming.ID {
+		t.Errorf("List = %+v", list)
+	}
+	if err := m.Delete("t", freeze.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, held := m.HoldsActions("t", now); held {
+		t.Error("expected actions released once the freeze was deleted")
+	}
+	if stats := m.GetStats(); stats["windows"] != 2 || stats["active"] != 1 {
+		t.Errorf("stats = %v", stats)
+	}
+	if removed := m.Purge("t"); removed != 2 {
+		t.Errorf("Purge = %d", removed)
+	}
+}
+
+func TestManagerLimit(t *testing.T) {
+	m := NewManager()
+	now := time.Now()
+	for i := 0; i < MaxWindows; i++ {
+		if _, err := m.Add("t", Window{Kind: KindMaintenance, Start: now, End: now.Add(time.Hour)}); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	if _, err := m.Add("t", Window{Kind: KindMaintenance, Start: now, End: now.Add(time.Hour)}); err == nil {
+		t.Error("expected windows beyond the limit rejected")
+	}
+
+	// Ended windows make room for new ones
+	m.mu.Lock()
+	for _, w := range m.windows["t"] {
+		w.End = now.Add(-time.Minute)
+		break
+	}
+	m.mu.Unlock()
+	if _, err := m.Add("t", Window{Kind: KindMaintenance, Start: now, End: now.Add(time.Hour)}); err != nil {
+		t.Errorf("Add after a window ended: %v", err)
+	}
+}
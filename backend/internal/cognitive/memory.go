@@ -0,0 +1,59 @@
+package cognitive
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// MemoryReport describes the memory the engine takes, with how much the
+// interning of atom names and tenant IDs saves
+type MemoryReport struct {
+	HeapAlloc   uint64                `json:"heap_alloc"`
+	HeapInuse   uint64                `json:"heap_inuse"`
+	HeapObjects uint64                `json:"heap_objects"`
+	Sys         uint64                `json:"sys"`
+	NumGC       uint32                `json:"num_gc"`
+	Goroutines  int                   `json:"goroutines"`
+	Strings     atomspace.StringStats `json:"strings"`
+	IDScheme    atomspace.IDScheme    `json:"id_scheme"`
+	GeneratedAt time.Time             `json:"generated_at"`
+}
+
+// MemoryReport reads the runtime's memory statistics and measures the
+// strings held by the atoms of every tenant. It walks all atoms, so it is
+// meant for occasional administrative use.
+func (ce *CognitiveEngine) MemoryReport() MemoryReport {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return MemoryReport{
+		HeapAlloc:   m.HeapAlloc,
+		HeapInuse:   m.HeapInuse,
+		HeapObjects: m.HeapObjects,
+		Sys:         m.Sys,
+		NumGC:       m.NumGC,
+		Goroutines:  runtime.NumGoroutine(),
+		Strings:     atomspace.MeasureStrings(ce.shardManager.AllAtoms()),
+		IDScheme:    atomspace.CurrentIDScheme(),
+		GeneratedAt: time.Now(),
+	}
+}
+
+// sweepInterned periodically drops the interned copies of names and tenant
+// IDs, and the IDs minted for content, that no atom holds any more, such as
+// those of purged tenants, until the engine is closed
+func (ce *CognitiveEngine) sweepInterned(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ce.done:
+			return
+		case <-ticker.C:
+			atoms := ce.shardManager.AllAtoms()
+			atomspace.SweepInterned(atoms)
+			atomspace.SweepIDs(atoms)
+		}
+	}
+}
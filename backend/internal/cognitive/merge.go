@@ -0,0 +1,238 @@
+package cognitive
+
+import (
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+)
+
+// mergeAtoms merges atoms of a tenant into another: the links referring to
+// them, directly or through other links, are recreated to refer to into
+// instead, then the old links and the merged atoms are deleted. A recreated
+// link that already exists keeps the more confident of the two truth
+// values; one whose atoms all become into is dropped. It returns the
+// number of links rewired.
+func (ce *CognitiveEngine) mergeAtoms(tenantID, into string, from []string) (int, error) {
+	if _, err := ce.shardManager.GetAtom(into, tenantID); err != nil {
+		return 0, err
+	}
+	merged := make(map[string]bool, len(from))
+	for _, id := range from {
+		if id == into {
+			return 0, fmt.Errorf("cannot merge atom %s into itself", id)
+		}
+		atom, err := ce.shardManager.GetAtom(id, tenantID)
+		if err != nil {
+			return 0, err
+		}
+		if atomspace.IsProtected(atom) {
+			return 0, fmt.Errorf("%w: %s", atomspace.ErrProtected, id)
+		}
+		merged[id] = true
+	}
+
+	// Find every link to rewire before changing any, so that a protected
+	// one leaves the atoms as they were
+	affected := make(map[string]*atomspace.Link)
+	var order []*atomspace.Link // Inner links before the links referring to them
+	for {
+		var found []atomspace.Atom
+		for _, atom := range ce.shardManager.QueryAtoms(tenantID, nil) {
+			link, ok := atom.(*atomspace.Link)
+			if !ok || affected[link.GetID()] != nil {
+				continue
+			}
+			for _, target := range link.GetOutgoing() {
+				if merged[target.GetID()] || affected[target.GetID()] != nil {
+					found = append(found, link)
+					break
+				}
+			}
+		}
+		if len(found) == 0 {
+			break
+		}
+		for _, atom := range found {
+			if atomspace.IsProtected(atom) {
+				return 0, fmt.Errorf("%w: link %s refers to a merged atom", atomspace.ErrProtected, atom.GetID())
+			}
+			affected[atom.GetID()] = atom.(*atomspace.Link)
+			order = append(order, atom.(*atomspace.Link))
+		}
+	}
+
+	target, err := ce.shardManager.GetAtom(into, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	replaced := make(map[string]atomspace.Atom, len(from)+len(order)) // Old ID -> replacement; nil if dropped
+	for id := range merged {
+		replaced[id] = target
+	}
+	for len(order) > 0 {
+		next := order[:0]
+		for _, link := range order {
+			ready := true
+			for _, atom := range link.GetOutgoing() {
+				if affected[atom.GetID()] != nil {
+					if _, done := replaced[atom.GetID()]; !done {
+						ready = false
+						break
+					}
+				}
+			}
+			if !ready {
+				next = append(next, link)
+				continue
+			}
+			rewired, err := ce.rewireLink(tenantID, into, link, replaced)
+			if err != nil {
+				return 0, err
+			}
+			replaced[link.GetID()] = rewired
+		}
+		if len(next) == len(order) {
+			return 0, fmt.Errorf("links of atoms merged into %s refer to each other", into)
+		}
+		order = next
+	}
+
+	for id := range merged {
+		if err := ce.DeleteAtom(id, tenantID); err != nil {
+			return 0, err
+		}
+	}
+	return len(affected), nil
+}
+
+// rewireLink recreates a link with its outgoing atoms replaced, and deletes
+// it. It returns the new link, or nil if the link was dropped.
+func (ce *CognitiveEngine) rewireLink(tenantID, into string, link *atomspace.Link, replaced map[string]atomspace.Atom) (atomspace.Atom, error) {
+	outgoing := make([]atomspace.Atom, 0, len(link.GetOutgoing()))
+	collapsed := true
+	for _, atom := range link.GetOutgoing() {
+		if replacement, ok := replaced[atom.GetID()]; ok {
+			if replacement == nil {
+				// Refers to a dropped link
+				return nil, ce.DeleteAtom(link.GetID(), tenantID)
+			}
+			atom = replacement
+		}
+		if atom.GetID() != into {
+			collapsed = false
+		}
+		outgoing = append(outgoing, atom)
+	}
+	if collapsed && len(outgoing) > 1 {
+		return nil, ce.DeleteAtom(link.GetID(), tenantID)
+	}
+
+	id := atomspace.GenerateAtomID(link.GetType(), link.GetName(), outgoing)
+	rewired, err := ce.shardManager.GetAtom(id, tenantID)
+	if err == nil {
+		if tv := link.GetTruthValue(); tv.Confidence > rewired.GetTruthValue().Confidence {
+			if err := ce.UpdateAtom(id, tenantID, func(atom atomspace.Atom) error {
+				atom.SetTruthValue(tv)
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		created := atomspace.NewLink(id, link.GetName(), tenantID, link.GetType(), outgoing)
+		created.SetTruthValue(link.GetTruthValue())
+		created.SetAttentionValue(link.GetAttentionValue())
+		if err := ce.AddAtom(created); err != nil {
+			return nil, err
+		}
+		rewired = created
+	}
+	if err := ce.DeleteAtom(link.GetID(), tenantID); err != nil {
+		return nil, err
+	}
+	return rewired, nil
+}
+
+// MergeResult reports a merge of atoms into a canonical atom
+type MergeResult struct {
+	Into       string   `json:"into"`
+	Merged     []string `json:"merged"`
+	Aliases    []string `json:"aliases"` // Names of the merged atoms, now aliases of Into
+	Rewired    int      `json:"rewired"` // Links recreated to refer to Into
+	Strength   float64  `json:"strength"`
+	Confidence float64  `json:"confidence"`
+}
+
+// MergeAtoms consolidates duplicate atoms of a tenant into a canonical
+// one. The links referring to them are rewired to it, their truth values
+// revise its own as independent evidence, unless it is protected, and
+// their names become its aliases, so that ingestion resolves them to it
+// from then on. Their own aliases follow them. Its attention value keeps
+// the highest importance among them.
+func (ce *CognitiveEngine) MergeAtoms(tenantID, into string, from []string) (*MergeResult, error) {
+	canonical, err := ce.shardManager.GetAtom(into, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(from) == 0 {
+		return nil, fmt.Errorf("no atoms to merge into %s", into)
+	}
+	tv := canonical.GetTruthValue()
+	av := canonical.GetAttentionValue()
+	var names []string
+	seen := make(map[string]bool)
+	for _, id := range from {
+		atom, err := ce.shardManager.GetAtom(id, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if atomspace.IsLinkType(atom.GetType()) || atomspace.IsLinkType(canonical.GetType()) {
+			return nil, fmt.Errorf("only nodes can be merged")
+		}
+		tv = inference.Revise(tv, atom.GetTruthValue())
+		other := atom.GetAttentionValue()
+		if other.STI > av.STI {
+			av.STI = other.STI
+		}
+		if other.LTI > av.LTI {
+			av.LTI = other.LTI
+		}
+		if name := atom.GetName(); name != canonical.GetName() && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	rewired, err := ce.mergeAtoms(tenantID, into, from)
+	if err != nil {
+		return nil, err
+	}
+	if !atomspace.IsProtected(canonical) {
+		if err := ce.UpdateAtom(into, tenantID, func(atom atomspace.Atom) error {
+			atom.SetTruthValue(tv)
+			atom.SetAttentionValue(av)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range names {
+		if _, err := ce.AddAlias(tenantID, into, name); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := ce.shardManager.GetAtom(into, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return &MergeResult{
+		Into:       into,
+		Merged:     from,
+		Aliases:    append([]string{}, names...),
+		Rewired:    rewired,
+		Strength:   updated.GetTruthValue().Strength,
+		Confidence: updated.GetTruthValue().Confidence,
+	}, nil
+}
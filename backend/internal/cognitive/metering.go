@@ -0,0 +1,88 @@
+package cognitive
+
+import (
+	"context"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/metering"
+)
+
+// meteringCloseTimeout bounds the last export when the engine is closed
+const meteringCloseTimeout = 10 * time.Second
+
+// MeteringReport is the billable usage of tenants and the state of its
+// exporters
+type MeteringReport struct {
+	Interval  string                                    `json:"interval"`
+	Totals    map[string]map[metering.EventType]float64 `json:"totals"` // Tenant -> event type -> quantity since the engine started
+	Exporters []metering.ExporterStatus                 `json:"exporters"`
+}
+
+// meterInference bills the time an inference rule ran to its tenant
+func (ce *CognitiveEngine) meterInference(tenantID string, elapsed time.Duration) {
+	ce.meter.Add(tenantID, metering.InferenceCPUSeconds, elapsed.Seconds())
+}
+
+// meterAtoms bills each tenant for the atoms it stores, times the hours
+// since the last sample. As replicas hold the same tenants, each tenant is
+// billed by one replica only: the one owning it when agents are
+// partitioned, or else the leader. The others keep the sample time, so
+// that a replica taking a tenant over bills from about where the previous
+// one stopped.
+func (ce *CognitiveEngine) meterAtoms() {
+	ce.meteringMu.Lock()
+	defer ce.meteringMu.Unlock()
+
+	now := time.Now()
+	hours := now.Sub(ce.atomsMeteredAt).Hours()
+	ce.atomsMeteredAt = now
+	for _, tenantID := range ce.ListTenants() {
+		if !ce.billsAtomsOf(tenantID) {
+			continue
+		}
+		atoms, _ := ce.shardManager.GetTenantStats(tenantID)["total_atoms"].(int)
+		ce.meter.Emit(metering.Event{Type: metering.AtomHours, TenantID: tenantID, Quantity: float64(atoms) * hours, Time: now})
+	}
+}
+
+// runMetering samples stored atoms and exports billable events every
+// interval until the engine is closed
+func (ce *CognitiveEngine) runMetering(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ce.done:
+			return
+		case <-ticker.C:
+			ce.flushMetering(interval)
+		}
+	}
+}
+
+// flushMetering exports billable usage, giving up after the timeout
+func (ce *CognitiveEngine) flushMetering(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	ce.FlushMetering(ctx)
+}
+
+// FlushMetering samples stored atoms, collects the usage added since the
+// last flush and exports it now rather than at the next interval, returning
+// the state of the exporters. Exporters that fail keep their events for the
+// next flush.
+func (ce *CognitiveEngine) FlushMetering(ctx context.Context) []metering.ExporterStatus {
+	ce.meterAtoms()
+	ce.meter.Collect()
+	return ce.meter.Flush(ctx)
+}
+
+// MeteringReport returns the billable usage of every tenant since the
+// engine started and the state of the exporters
+func (ce *CognitiveEngine) MeteringReport() MeteringReport {
+	return MeteringReport{
+		Interval:  ce.meter.Config().Interval.String(),
+		Totals:    ce.meter.Totals(),
+		Exporters: ce.meter.Status(),
+	}
+}
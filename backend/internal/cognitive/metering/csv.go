@@ -0,0 +1,55 @@
+package metering
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"strconv"
+	"time"
+)
+
+// CSVExporter appends events to a CSV file, with a header row when it
+// creates the file
+type CSVExporter struct {
+	path string
+}
+
+// NewCSVExporter creates an exporter appending to the file at path
+func NewCSVExporter(path string) *CSVExporter {
+	return &CSVExporter{path: path}
+}
+
+func (e *CSVExporter) Name() string {
+	return "csv"
+}
+
+func (e *CSVExporter) Export(ctx context.Context, events []Event) error {
+	f, err := os.OpenFile(e.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	if info.Size() == 0 {
+		w.Write([]string{"time", "id", "tenant_id", "event", "quantity"})
+	}
+	for _, event := range events {
+		w.Write([]string{
+			event.Time.UTC().Format(time.RFC3339),
+			event.ID,
+			event.TenantID,
+			string(event.Type),
+			strconv.FormatFloat(event.Quantity, 'f', -1, 64),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
@@ -0,0 +1,281 @@
+package metering
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EventType is a billable quantity
+type EventType string
+
+const (
+	AtomHours           EventType = "atom_hours"            // Atoms stored, times the hours they were stored
+	InferenceCPUSeconds EventType = "inference_cpu_seconds" // Time inference rules ran on workers
+	PipelineExecutions  EventType = "pipeline_executions"   // Pipeline executions that succeeded
+)
+
+// EventTypes lists the billable event types
+var EventTypes = []EventType{AtomHours, InferenceCPUSeconds, PipelineExecutions}
+
+// Event is a billable quantity a tenant used
+type Event struct {
+	ID       string    `json:"id"` // Unique; exporters use it to deduplicate retries
+	Type     EventType `json:"type"`
+	TenantID string    `json:"tenant_id"`
+	Quantity float64   `json:"quantity"`
+	Time     time.Time `json:"time"`
+}
+
+// Exporter sends billable events to a billing system. Export is retried
+// with the same events until it succeeds.
+type Exporter interface {
+	Name() string
+	Export(ctx context.Context, events []Event) error
+}
+
+// Config configures metering
+type Config struct {
+	Interval   time.Duration // How often stored atoms are sampled and events exported
+	MaxPending int           // Events kept per exporter while it fails; the oldest are dropped beyond
+}
+
+// DefaultConfig samples and exports hourly, keeping up to 100000 events per
+// failing exporter
+func DefaultConfig() Config {
+	return Config{Interval: time.Hour, MaxPending: 100000}
+}
+
+// ExporterStatus is the state of an exporter
+type ExporterStatus struct {
+	Name       string    `json:"name"`
+	Pending    int       `json:"pending"`  // Events not exported yet
+	Exported   int64     `json:"exported"` // Events exported
+	Dropped    int64     `json:"dropped"`  // Events dropped while the exporter failed
+	LastExport time.Time `json:"last_export,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+// exporterQueue holds the events an exporter has not accepted yet. A failed
+// batch is retried as it was before newer events are sent.
+type exporterQueue struct {
+	exporter Exporter
+	failed   []Event
+	pending  []Event
+	status   ExporterStatus
+}
+
+// Meter collects billable events and hands them to its exporters
+type Meter struct {
+	config    Config
+	prefix    string
+	seq       uint64
+	added     map[string]map[EventType]float64 // Tenant -> event type -> quantity added since the last collect
+	totals    map[string]map[EventType]float64 // Tenant -> event type -> quantity emitted or added
+	exporters []*exporterQueue
+	mu        sync.Mutex
+	flushMu   sync.Mutex
+}
+
+// NewMeter creates a meter. Non-positive settings take their defaults.
+func NewMeter(config Config) *Meter {
+	defaults := DefaultConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.MaxPending <= 0 {
+		config.MaxPending = defaults.MaxPending
+	}
+	return &Meter{
+		config: config,
+		prefix: fmt.Sprintf("%x", time.Now().UnixNano()),
+		added:  make(map[string]map[EventType]float64),
+		totals: make(map[string]map[EventType]float64),
+	}
+}
+
+// Config returns the meter's configuration
+func (m *Meter) Config() Config {
+	return m.config
+}
+
+// AddExporter adds an exporter, which receives the events emitted from now
+// on. An exporter of the same name is replaced, with its pending events.
+func (m *Meter) AddExporter(exporter Exporter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	queue := &exporterQueue{exporter: exporter, status: ExporterStatus{Name: exporter.Name()}}
+	for i, existing := range m.exporters {
+		if existing.status.Name == queue.status.Name {
+			queue.failed, queue.pending = existing.failed, existing.pending
+			m.exporters[i] = queue
+			return
+		}
+	}
+	m.exporters = append(m.exporters, queue)
+}
+
+// Emit records a billable event. Its ID and time are filled in if unset;
+// events with no quantity are ignored.
+func (m *Meter) Emit(event Event) {
+	if event.Quantity <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	addTotal(m.totals, event.TenantID, event.Type, event.Quantity)
+	m.emit(event)
+}
+
+// Add adds to a quantity emitted as one event per tenant and type at the
+// next Collect, for usage too fine-grained to bill event by event
+func (m *Meter) Add(tenantID string, eventType EventType, quantity float64) {
+	if quantity <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	addTotal(m.totals, tenantID, eventType, quantity)
+	addTotal(m.added, tenantID, eventType, quantity)
+}
+
+// Collect emits the quantities added since the last collect and returns
+// the number of events emitted
+func (m *Meter) Collect() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	emitted := 0
+	for tenantID, quantities := range m.added {
+		for eventType, quantity := range quantities {
+			m.emit(Event{Type: eventType, TenantID: tenantID, Quantity: quantity, Time: now})
+			emitted++
+		}
+	}
+	m.added = make(map[string]map[EventType]float64)
+	return emitted
+}
+
+func addTotal(totals map[string]map[EventType]float64, tenantID string, eventType EventType, quantity float64) {
+	if totals[tenantID] == nil {
+		totals[tenantID] = make(map[EventType]float64)
+	}
+	totals[tenantID][eventType] += quantity
+}
+
+// emit queues an event for every exporter
+func (m *Meter) emit(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	m.seq++
+	if event.ID == "" {
+		event.ID = fmt.Sprintf("%s-%d", m.prefix, m.seq)
+	}
+	for _, queue := range m.exporters {
+		queue.pending = append(queue.pending, event)
+		if over := len(queue.failed) + len(queue.pending) - m.config.MaxPending; over > 0 {
+			queue.drop(over)
+		}
+	}
+}
+
+// drop discards the oldest events of a queue
+func (q *exporterQueue) drop(n int) {
+	q.status.Dropped += int64(n)
+	if n >= len(q.failed) {
+		n -= len(q.failed)
+		q.failed = nil
+		q.pending = append(q.pending[:0:0], q.pending[n:]...)
+		return
+	}
+	q.failed = q.failed[n:]
+}
+
+// Flush exports the pending events to every exporter and returns their
+// status. An exporter that fails keeps its events for the next flush.
+func (m *Meter) Flush(ctx context.Context) []ExporterStatus {
+	m.flushMu.Lock()
+	defer m.flushMu.Unlock()
+
+	m.mu.Lock()
+	queues := append([]*exporterQueue(nil), m.exporters...)
+	m.mu.Unlock()
+
+	for _, queue := range queues {
+		m.flushQueue(ctx, queue)
+	}
+	return m.Status()
+}
+
+func (m *Meter) flushQueue(ctx context.Context, queue *exporterQueue) {
+	for {
+		m.mu.Lock()
+		if len(queue.failed) == 0 {
+			queue.failed, queue.pending = queue.pending, nil
+		}
+		batch := queue.failed
+		m.mu.Unlock()
+		if len(batch) == 0 {
+			return
+		}
+
+		err := queue.exporter.Export(ctx, batch)
+
+		m.mu.Lock()
+		if err != nil {
+			queue.status.LastError = err.Error()
+			m.mu.Unlock()
+			return
+		}
+		// What is left of the batch, if events were dropped meanwhile
+		queue.failed = nil
+		queue.status.Exported += int64(len(batch))
+		queue.status.LastExport = time.Now()
+		queue.status.LastError = ""
+		m.mu.Unlock()
+	}
+}
+
+// Status returns the state of each exporter
+func (m *Meter) Status() []ExporterStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]ExporterStatus, len(m.exporters))
+	for i, queue := range m.exporters {
+		statuses[i] = queue.status
+		statuses[i].Pending = len(queue.failed) + len(queue.pending)
+	}
+	return statuses
+}
+
+// Totals returns the quantities emitted per tenant and event type since
+// the meter started
+func (m *Meter) Totals() map[string]map[EventType]float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	totals := make(map[string]map[EventType]float64, len(m.totals))
+	for tenantID, quantities := range m.totals {
+		totals[tenantID] = make(map[EventType]float64, len(quantities))
+		for eventType, quantity := range quantities {
+			totals[tenantID][eventType] = quantity
+		}
+	}
+	return totals
+}
+
+// GetStats returns metering statistics
+func (m *Meter) GetStats() map[string]interface{} {
+	return map[string]interface{}{
+		"interval":  m.config.Interval.String(),
+		"exporters": m.Status(),
+		"tenants":   len(m.Totals()),
+	}
+}
@@ -0,0 +1,179 @@
+package metering
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingExporter records exports and fails while failing is set
+type recordingExporter struct {
+	batches [][]Event
+	failing bool
+}
+
+func (e *recordingExporter) Name() string {
+	return "recording"
+}
+
+func (e *recordingExporter) Export(ctx context.Context, events []Event) error {
+	if e.failing {
+		return errors.New("unavailable")
+	}
+	e.batches = append(e.batches, append([]Event(nil), events...))
+	return nil
+}
+
+func TestMeterRetriesFailedBatches(t *testing.T) {
+	m := NewMeter(Config{MaxPending: 3})
+	exporter := &recordingExporter{failing: true}
+	m.Emit(Event{Type: PipelineExecutions, TenantID: "acme", Quantity: 1})
+	m.AddExporter(exporter)
+
+	m.Emit(Event{Type: PipelineExecutions, TenantID: "acme", Quantity: 1})
+	m.Emit(Event{Type: AtomHours, TenantID: "acme", Quantity: 0})
+	status := m.Flush(context.Background())
+	if len(status) != 1 || status[0].Pending != 1 || status[0].LastError != "unavailable" {
+		t.Fatalf("expected one event pending after a failed export, got %+v", status)
+	}
+
+	m.Emit(Event{Type: InferenceCPUSeconds, TenantID: "acme", Quantity: 2.5})
+	m.Emit(Event{Type: InferenceCPUSeconds, TenantID: "globex", Quantity: 1})
+	m.Emit(Event{Type: InferenceCPUSeconds, TenantID: "globex", Quantity: 1})
+	if status := m.Status(); status[0].Pending != 3 || status[0].Dropped != 1 {
+		t.Fatalf("expected the oldest event dropped beyond the limit, got %+v", status)
+	}
+
+	exporter.failing = false
+	status = m.Flush(context.Background())
+	if status[0].Pending != 0 || status[0].Exported != 3 || status[0].LastError != "" {
+		t.Errorf("expected every event exported, got %+v", status)
+	}
+	if len(exporter.batches) != 1 || exporter.batches[0][0].Type != InferenceCPUSeconds {
+		t.Errorf("expected the remaining events in one batch, got %+v", exporter.batches)
+	}
+	if totals := m.Totals(); totals["acme"][PipelineExecutions] != 2 || totals["acme"][InferenceCPUSeconds] != 2.5 {
+		t.Errorf("expected totals of every event emitted, got %v", totals)
+	}
+}
+
+func TestMeterCollect(t *testing.T) {
+	m := NewMeter(DefaultConfig())
+	exporter := &recordingExporter{}
+	m.AddExporter(exporter)
+	for i := 0; i < 100; i++ {
+		m.Add("acme", InferenceCPUSeconds, 0.01)
+	}
+	m.Add("acme", PipelineExecutions, 1)
+	m.Add("acme", PipelineExecutions, 1)
+	if status := m.Status(); status[0].Pending != 0 {
+		t.Fatalf("expected added usage held until collected, got %+v", status)
+	}
+
+	if n := m.Collect(); n != 2 {
+		t.Fatalf("expected one event per tenant and type, got %d", n)
+	}
+	m.Flush(context.Background())
+	quantities := make(map[EventType]float64)
+	for _, event := range exporter.batches[0] {
+		quantities[event.Type] = event.Quantity
+	}
+	if quantities[PipelineExecutions] != 2 || quantities[InferenceCPUSeconds] < 0.99 || quantities[InferenceCPUSeconds] > 1.01 {
+		t.Errorf("expected the added quantities summed, got %v", quantities)
+	}
+	if n := m.Collect(); n != 0 {
+		t.Errorf("expected nothing left to collect, got %d events", n)
+	}
+}
+
+func TestCSVExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.csv")
+	exporter := NewCSVExporter(path)
+	at := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		err := exporter.Export(context.Background(), []Event{{ID: "e1", Type: AtomHours, TenantID: "acme", Quantity: 1500.25, Time: at}})
+		if err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 || lines[0] != "time,id,tenant_id,event,quantity" || lines[1] != "2024-01-31T12:00:00Z,e1,acme,atom_hours,1500.25" {
+		t.Errorf("unexpected CSV:\n%s", data)
+	}
+}
+
+func TestStripeExporter(t *testing.T) {
+	var mu sync.Mutex
+	var records []string
+	keys := make(map[string]bool)
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if user, _, _ := r.BasicAuth(); user != "sk_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		r.ParseForm()
+		if strings.Contains(r.URL.Path, "si_infer") && fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		// Stripe replays requests whose idempotency key it has seen
+		if key := r.Header.Get("Idempotency-Key"); !keys[key] {
+			keys[key] = true
+			records = append(records, r.URL.Path+" "+r.Form.Get("quantity")+" "+r.Form.Get("action"))
+		}
+	}))
+	defer server.Close()
+
+	exporter, err := NewStripeExporter(StripeConfig{
+		SecretKey: "sk_test",
+		BaseURL:   server.URL,
+		Items: map[string]map[EventType]string{
+			"acme": {AtomHours: "si_atoms", InferenceCPUSeconds: "si_infer"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := []Event{
+		{ID: "1", Type: AtomHours, TenantID: "acme", Quantity: 1000.5},
+		{ID: "2", Type: InferenceCPUSeconds, TenantID: "acme", Quantity: 1.75},
+		{ID: "3", Type: PipelineExecutions, TenantID: "acme", Quantity: 1},
+		{ID: "4", Type: AtomHours, TenantID: "globex", Quantity: 10},
+	}
+	if err := exporter.Export(context.Background(), events); err == nil {
+		t.Fatal("expected a failed usage record to fail the export")
+	}
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	if err := exporter.Export(context.Background(), events); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(records) != 2 || records[0] != "/v1/subscription_items/si_atoms/usage_records 1000 increment" || records[1] != "/v1/subscription_items/si_infer/usage_records 1 increment" {
+		t.Fatalf("expected each item's usage reported once, got %v", records)
+	}
+
+	// Fractions carry over to the next export
+	exporter.Export(context.Background(), []Event{{ID: "5", Type: AtomHours, TenantID: "acme", Quantity: 0.5}})
+	if len(records) != 3 || records[2] != "/v1/subscription_items/si_atoms/usage_records 1 increment" {
+		t.Errorf("expected the carried fraction reported, got %v", records)
+	}
+	if _, err := NewStripeExporter(StripeConfig{}); err == nil {
+		t.Error("expected a missing secret key to be rejected")
+	}
+}
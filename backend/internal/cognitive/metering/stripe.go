@@ -0,0 +1,155 @@
+package metering
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StripeConfig configures the Stripe exporter
+type StripeConfig struct {
+	SecretKey string                          // API key, sent as the basic auth user
+	Items     map[string]map[EventType]string // Tenant -> event type -> subscription item billed for it
+	BaseURL   string                          // https://api.stripe.com by default
+	Timeout   time.Duration                   // Of each request; 30s by default
+}
+
+// StripeExporter reports usage to Stripe as usage records of metered
+// subscription items, one increment per tenant and event type in each
+// export. Stripe counts whole units, so fractions are carried over to later
+// exports. Events of tenants or types without a subscription item are
+// skipped.
+type StripeExporter struct {
+	config StripeConfig
+	client *http.Client
+
+	// Fractions not reported yet, per subscription item
+	carry map[string]float64
+	mu    sync.Mutex
+}
+
+// NewStripeExporter creates a Stripe exporter
+func NewStripeExporter(config StripeConfig) (*StripeExporter, error) {
+	if config.SecretKey == "" {
+		return nil, fmt.Errorf("stripe exporter requires a secret key")
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = "https://api.stripe.com"
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &StripeExporter{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		carry:  make(map[string]float64),
+	}, nil
+}
+
+func (e *StripeExporter) Name() string {
+	return "stripe"
+}
+
+// stripeUsage is the usage of a subscription item within an export
+type stripeUsage struct {
+	item     string
+	quantity float64
+	last     time.Time
+	ids      []string
+}
+
+// Export sends one usage record per subscription item. Each is keyed by
+// the IDs of its events, so retrying a partly failed export does not count
+// the records Stripe already accepted twice; fractions carried over are
+// only updated once every record is accepted.
+func (e *StripeExporter) Export(ctx context.Context, events []Event) error {
+	usages := make(map[string]*stripeUsage)
+	for _, event := range events {
+		item := e.config.Items[event.TenantID][event.Type]
+		if item == "" {
+			continue
+		}
+		u := usages[item]
+		if u == nil {
+			u = &stripeUsage{item: item}
+			usages[item] = u
+		}
+		u.quantity += event.Quantity
+		if event.Time.After(u.last) {
+			u.last = event.Time
+		}
+		u.ids = append(u.ids, event.ID)
+	}
+
+	items := make([]string, 0, len(usages))
+	for item := range usages {
+		items = append(items, item)
+	}
+	sort.Strings(items)
+
+	e.mu.Lock()
+	carry := make(map[string]float64, len(usages))
+	for _, item := range items {
+		carry[item] = e.carry[item]
+	}
+	e.mu.Unlock()
+
+	for _, item := range items {
+		u := usages[item]
+		total := u.quantity + carry[item]
+		whole := math.Floor(total)
+		carry[item] = total - whole
+		if whole < 1 {
+			continue
+		}
+		if err := e.post(ctx, u, int64(whole)); err != nil {
+			return fmt.Errorf("reporting usage of %s failed: %w", item, err)
+		}
+	}
+
+	e.mu.Lock()
+	for item, fraction := range carry {
+		e.carry[item] = fraction
+	}
+	e.mu.Unlock()
+	return nil
+}
+
+// post creates a usage record incrementing an item's usage
+func (e *StripeExporter) post(ctx context.Context, u *stripeUsage, quantity int64) error {
+	form := url.Values{
+		"quantity":  {strconv.FormatInt(quantity, 10)},
+		"timestamp": {strconv.FormatInt(u.last.Unix(), 10)},
+		"action":    {"increment"},
+	}
+	endpoint := fmt.Sprintf("%s/v1/subscription_items/%s/usage_records", e.config.BaseURL, url.PathEscape(u.item))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(e.config.SecretKey, "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	key := sha256.Sum256([]byte(u.item + "\n" + strings.Join(u.ids, "\n")))
+	req.Header.Set("Idempotency-Key", "erebus-"+hex.EncodeToString(key[:16]))
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("stripe returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package cognitive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/incidents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/notifications"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/pipeline"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/recommendations"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+)
+
+// SetNotificationChannel creates or replaces a notification channel of a
+// tenant
+func (ce *CognitiveEngine) SetNotificationChannel(tenantID string, channel notifications.Channel) (notifications.Channel, error) {
+	return ce.notifications.SetChannel(tenantID, channel)
+}
+
+// GetNotificationChannel returns a notification channel of a tenant
+func (ce *CognitiveEngine) GetNotificationChannel(tenantID, name string) (notifications.Channel, error) {
+	return ce.notifications.GetChannel(tenantID, name)
+}
+
+// ListNotificationChannels returns a tenant's notification channels
+func (ce *CognitiveEngine) ListNotificationChannels(tenantID string) []notifications.Channel {
+	return ce.notifications.ListChannels(tenantID)
+}
+
+// DeleteNotificationChannel removes a notification channel of a tenant
+func (ce *CognitiveEngine) DeleteNotificationChannel(tenantID, name string) error {
+	return ce.notifications.DeleteChannel(tenantID, name)
+}
+
+// TestNotificationChannel sends a test notification to a channel of a
+// tenant and returns the outcome
+func (ce *CognitiveEngine) TestNotificationChannel(ctx context.Context, tenantID, name string) (notifications.Delivery, error) {
+	return ce.notifications.Test(ctx, tenantID, name)
+}
+
+// GetNotificationDeliveries returns the latest notifications sent to a
+// tenant's channels
+func (ce *CognitiveEngine) GetNotificationDeliveries(tenantID string) []notifications.Delivery {
+	return ce.notifications.Deliveries(tenantID)
+}
+
+// Notify sends a notification to the channels of its tenant taking it
+func (ce *CognitiveEngine) Notify(ctx context.Context, n notifications.Notification) []notifications.Delivery {
+	return ce.notifications.Notify(ctx, n)
+}
+
+// notify sends a notification in the background, so that what it tells
+// about is not held up by slow channels
+func (ce *CognitiveEngine) notify(n notifications.Notification) {
+	go ce.notifications.Notify(context.Background(), n)
+}
+
+// tenantActions returns the performer of a tenant's trigger and runbook
+// actions
+func (ce *CognitiveEngine) tenantActions(tenantID string) triggers.Performer {
+	return &maintenancePerformer{
+		engine:   ce,
+		tenantID: tenantID,
+		next:     ce.sandbox.ForTenant(tenantID).Actions(&notifyingPerformer{engine: ce, tenantID: tenantID}),
+	}
+}
+
+// notifyingPerformer sends the notifications of notify actions and leaves
+// the other actions to the trigger manager
+type notifyingPerformer struct {
+	engine   *CognitiveEngine
+	tenantID string
+}
+
+func (p *notifyingPerformer) Perform(ctx context.Context, action triggers.Action, input []atomspace.Atom, payload map[string]interface{}) error {
+	if action.Type != triggers.ActionNotify {
+		return p.engine.triggerManager.Perform(ctx, action, input, payload)
+	}
+
+	n := notifications.Notification{
+		TenantID: p.tenantID,
+		Topic:    notifications.TopicTriggerFired,
+		Severity: "warning",
+		Channel:  action.Channel,
+		Fields:   make(map[string]string),
+	}
+	source := "trigger"
+	for _, key := range []string{"trigger_id", "runbook", "step", "event"} {
+		if value, ok := payload[key]; ok {
+			n.Fields[key] = fmt.Sprint(value)
+		}
+	}
+	// Runbook steps act on an incident, whose severity they take
+	if inc, ok := payload["incident"].(map[string]interface{}); ok {
+		n.Fields["incident_id"] = fmt.Sprint(inc["id"])
+		if severity, ok := inc["severity"].(string); ok && severity != "" {
+			n.Severity = severity
+		}
+	}
+	if id, ok := n.Fields["trigger_id"]; ok {
+		source = "Trigger " + id
+	} else if runbook, ok := n.Fields["runbook"]; ok {
+		source = "Runbook " + runbook + " step " + n.Fields["step"]
+	}
+	n.Title = source + " fired"
+	var names []string
+	for _, atom := range input {
+		names = append(names, atom.GetName())
+	}
+	if len(names) > 0 {
+		n.Message = "on " + strings.Join(names, ", ")
+	}
+
+	deliveries := p.engine.notifications.Notify(ctx, n)
+	var errs []string
+	for _, delivery := range deliveries {
+		if delivery.Error != "" {
+			errs = append(errs, fmt.Sprintf("%s: %s", delivery.Channel, delivery.Error))
+		}
+	}
+	if action.Channel != "" && len(deliveries) == 0 {
+		return fmt.Errorf("notification channel %s not found", action.Channel)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("notification failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// notifyPipelineFailed tells a tenant one of its pipeline executions failed
+func (ce *CognitiveEngine) notifyPipelineFailed(tenantID, pipelineID string, err error) {
+	n := notifications.Notification{
+		TenantID: tenantID,
+		Topic:    notifications.TopicPipelineFailed,
+		Severity: "error",
+		Title:    fmt.Sprintf("Pipeline %s failed", pipelineID),
+		Message:  ce.secrets.Scrub(err.Error()),
+		Fields:   map[string]string{"pipeline_id": pipelineID},
+	}
+	var execErr *pipeline.ExecutionError
+	if errors.As(err, &execErr) {
+		n.Fields["execution_id"] = execErr.ExecutionID
+		n.Fields["stage"] = execErr.Stage
+	}
+	ce.notify(n)
+}
+
+// notifyIncident tells a tenant an incident opened or was resolved
+func (ce *CognitiveEngine) notifyIncident(inc incidents.Incident) {
+	n := notifications.Notification{
+		TenantID: inc.TenantID,
+		Topic:    notifications.TopicIncidentOpened,
+		Severity: inc.Severity,
+		Title:    inc.Title,
+		Message:  fmt.Sprintf("%d alerts on %s", len(inc.Alerts), strings.Join(inc.Subjects, ", ")),
+		Fields:   map[string]string{"incident_id": inc.ID, "subjects": strings.Join(inc.Subjects, ", ")},
+		DedupKey: inc.TenantID + "/" + inc.ID,
+	}
+	if inc.Status == incidents.StatusResolved {
+		n.Topic, n.Resolved = notifications.TopicIncidentResolved, true
+		n.Title = "Resolved: " + inc.Title
+	}
+	ce.notify(n)
+}
+
+// notifyApproval tells a tenant a recommendation waits for approval
+func (ce *CognitiveEngine) notifyApproval(tenantID string, rec recommendations.Recommendation) {
+	n := notifications.Notification{
+		TenantID: tenantID,
+		Topic:    notifications.TopicApprovalRequested,
+		Severity: "info",
+		Title:    fmt.Sprintf("Approval requested: %s of %s", rec.Kind, rec.Subject),
+		Message:  rec.Reason,
+		Fields: map[string]string{
+			"recommendation_id": rec.ID,
+			"submitted_by":      rec.SubmittedBy,
+		},
+	}
+	if rec.Currency != "" {
+		n.Fields["monthly_savings"] = strconv.FormatFloat(rec.MonthlySavings, 'f', 2, 64) + " " + rec.Currency
+	}
+	ce.notify(n)
+}
+
+// notifyWatchdog tells the system tenant's channels about self-monitoring
+// alerts that fired or resolved
+func (ce *CognitiveEngine) notifyWatchdog(alerts []incidents.Alert) {
+	systemTenant := ce.watchdog.SystemTenant()
+	for _, alert := range alerts {
+		n := notifications.Notification{
+			TenantID: systemTenant,
+			Topic:    notifications.TopicWatchdogAlert,
+			Severity: alert.Severity,
+			Title:    fmt.Sprintf("%s on %s", alert.Name, alert.Subject),
+			Message:  alert.Annotations["description"],
+			Fields:   map[string]string{"value": alert.Annotations["value"]},
+			DedupKey: systemTenant + "/" + alert.Fingerprint,
+			Resolved: alert.Status == incidents.AlertResolved,
+		}
+		if n.Resolved {
+			n.Title = "Resolved: " + n.Title
+		}
+		ce.notify(n)
+	}
+}
@@ -0,0 +1,178 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Send renders a notification and sends it to a channel, resolving the
+// channel's secret references
+func (s *Service) Send(ctx context.Context, c Channel, n Notification) error {
+	s.mu.RLock()
+	resolver := s.resolvers(n.TenantID)
+	s.mu.RUnlock()
+
+	var err error
+	if c.URL, err = resolver.Resolve(ctx, c.URL); err == nil {
+		if c.Password, err = resolver.Resolve(ctx, c.Password); err == nil {
+			c.RoutingKey, err = resolver.Resolve(ctx, c.RoutingKey)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	text, err := c.Render(n)
+	if err != nil {
+		return err
+	}
+
+	switch c.Type {
+	case ChannelSlack:
+		err = s.post(ctx, c.URL, map[string]string{"text": text})
+	case ChannelWebhook:
+		err = s.post(ctx, c.URL, map[string]interface{}{
+			"tenant_id": n.TenantID,
+			"topic":     n.Topic,
+			"severity":  n.Severity,
+			"title":     n.Title,
+			"message":   n.Message,
+			"text":      text,
+			"fields":    n.Fields,
+			"link":      n.Link,
+			"dedup_key": n.DedupKey,
+			"resolved":  n.Resolved,
+			"time":      n.Time,
+		})
+	case ChannelPagerDuty:
+		err = s.post(ctx, s.config.PagerDutyURL, pagerDutyEvent(c.RoutingKey, n, text))
+	case ChannelEmail:
+		err = s.sendEmail(c, n, text)
+	default:
+		err = fmt.Errorf("unknown channel type: %s", c.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("%s", resolver.Scrub(err.Error()))
+	}
+	return nil
+}
+
+// post delivers a body as JSON
+func (s *Service) post(ctx context.Context, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("channel returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// pagerDutyEvent returns the Events API v2 event of a notification. Events
+// of the same dedup key update one PagerDuty incident, which a resolved
+// notification resolves.
+func pagerDutyEvent(routingKey string, n Notification, text string) map[string]interface{} {
+	dedupKey := n.DedupKey
+	if dedupKey == "" {
+		dedupKey = n.TenantID + "/" + n.Topic + "/" + n.Title
+	}
+	event := map[string]interface{}{
+		"routing_key":  routingKey,
+		"event_action": "trigger",
+		"dedup_key":    dedupKey,
+	}
+	if n.Resolved {
+		event["event_action"] = "resolve"
+		return event
+	}
+	if len(text) > 1024 {
+		text = text[:1024]
+	}
+	details := map[string]interface{}{"tenant_id": n.TenantID, "topic": n.Topic}
+	for key, value := range n.Fields {
+		details[key] = value
+	}
+	event["payload"] = map[string]interface{}{
+		"summary":        text,
+		"source":         "erebus/" + n.TenantID,
+		"severity":       pagerDutySeverity(n.Severity),
+		"timestamp":      n.Time.Format(time.RFC3339),
+		"custom_details": details,
+	}
+	if n.Link != "" {
+		event["links"] = []map[string]string{{"href": n.Link, "text": n.Title}}
+	}
+	return event
+}
+
+// pagerDutySeverity maps a severity to one PagerDuty accepts
+func pagerDutySeverity(severity string) string {
+	switch severity {
+	case "critical", "page":
+		return "critical"
+	case "error", "high":
+		return "error"
+	case "info":
+		return "info"
+	}
+	return "warning"
+}
+
+// sendEmail sends the text of a notification, followed by its fields, as
+// the body of a message
+func (s *Service) sendEmail(c Channel, n Notification, text string) error {
+	var auth smtp.Auth
+	if c.Username != "" {
+		host, _, err := net.SplitHostPort(c.SMTPAddr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", c.Username, c.Password, host)
+	}
+
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(fmt.Sprintf("[%s] %s", n.Severity, n.Title))
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", c.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(c.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", n.Time.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(text, "\n", "\r\n") + "\r\n")
+	if len(n.Fields) > 0 {
+		keys := make([]string, 0, len(n.Fields))
+		for key := range n.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		msg.WriteString("\r\n")
+		for _, key := range keys {
+			fmt.Fprintf(&msg, "%s: %s\r\n", key, n.Fields[key])
+		}
+	}
+	if n.Link != "" {
+		fmt.Fprintf(&msg, "\r\n%s\r\n", n.Link)
+	}
+
+	return s.sendMail(c.SMTPAddr, auth, c.From, c.To, msg.Bytes())
+}
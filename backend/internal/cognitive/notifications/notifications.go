@@ -0,0 +1,445 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/incidents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/secrets"
+)
+
+// Topics of the notifications the engine sends
+const (
+	TopicPipelineFailed    = "pipeline.failed"    // A pipeline execution failed
+	TopicTriggerFired      = "trigger.fired"      // A trigger or runbook step ran a notify action
+	TopicIncidentOpened    = "incident.opened"    // Correlated alerts opened an incident
+	TopicIncidentResolved  = "incident.resolved"  // An incident was resolved
+	TopicApprovalRequested = "approval.requested" // A recommendation waits for approval
+	TopicWatchdogAlert     = "watchdog.alert"     // A self-monitoring alert fired or resolved, in the system tenant
+	TopicAtomWatch         = "atom.watch"         // A watched atom crossed a threshold or was deleted
+	TopicTest              = "test"               // Sent on request to check a channel
+)
+
+// Topics returns the topics channels subscribe to
+func Topics() []string {
+	return []string{TopicPipelineFailed, TopicTriggerFired, TopicIncidentOpened, TopicIncidentResolved, TopicApprovalRequested, TopicWatchdogAlert, TopicAtomWatch}
+}
+
+// ChannelType is how a notification channel is reached
+type ChannelType string
+
+const (
+	ChannelEmail     ChannelType = "email"     // Send a message through an SMTP server
+	ChannelSlack     ChannelType = "slack"     // POST the text to a Slack incoming webhook URL
+	ChannelWebhook   ChannelType = "webhook"   // POST the notification as JSON to URL
+	ChannelPagerDuty ChannelType = "pagerduty" // Trigger or resolve a PagerDuty event with the routing key
+)
+
+// DefaultTemplate renders the text of a notification unless its channel
+// has its own template
+const DefaultTemplate = `[{{upper .Severity}}] {{.Title}}{{if .Message}}: {{.Message}}{{end}}`
+
+// validName matches channel names
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+var templateFuncs = template.FuncMap{"upper": strings.ToUpper, "lower": strings.ToLower}
+
+// Channel is where a tenant's notifications are sent. URL, Password and
+// RoutingKey may be secret references.
+type Channel struct {
+	Name        string      `json:"name"`
+	Type        ChannelType `json:"type"`
+	URL         string      `json:"url,omitempty"`
+	RoutingKey  string      `json:"routing_key,omitempty"` // PagerDuty integration key
+	SMTPAddr    string      `json:"smtp_addr,omitempty"`   // host:port
+	Username    string      `json:"username,omitempty"`
+	Password    string      `json:"password,omitempty"`
+	From        string      `json:"from,omitempty"`
+	To          []string    `json:"to,omitempty"`
+	Topics      []string    `json:"topics,omitempty"`       // Topics sent to the channel; all if empty
+	MinSeverity string      `json:"min_severity,omitempty"` // Notifications less severe are not sent; all are if empty
+	Template    string      `json:"template,omitempty"`     // text/template of the text, given the Notification
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// Validate checks the channel
+func (c *Channel) Validate() error {
+	if !validName.MatchString(c.Name) {
+		return fmt.Errorf("invalid channel name %q: use up to 64 letters, digits, dots, dashes and underscores", c.Name)
+	}
+	switch c.Type {
+	case ChannelSlack, ChannelWebhook:
+		if !secrets.IsReference(c.URL) && !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+			return fmt.Errorf("%s channel requires an http(s) url", c.Type)
+		}
+	case ChannelPagerDuty:
+		if c.RoutingKey == "" {
+			return fmt.Errorf("pagerduty channel requires a routing_key")
+		}
+	case ChannelEmail:
+		if c.SMTPAddr == "" || c.From == "" || len(c.To) == 0 {
+			return fmt.Errorf("email channel requires smtp_addr, from and to")
+		}
+		for _, address := range append([]string{c.From}, c.To...) {
+			if strings.ContainsAny(address, "\r\n") {
+				return fmt.Errorf("invalid email address: %q", address)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown channel type: %s", c.Type)
+	}
+	for _, topic := range c.Topics {
+		if !contains(Topics(), topic) {
+			return fmt.Errorf("unknown topic: %s", topic)
+		}
+	}
+	if c.Template != "" {
+		if _, err := template.New(c.Name).Funcs(templateFuncs).Parse(c.Template); err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+	}
+	return nil
+}
+
+// Takes reports whether a notification is sent to the channel
+func (c *Channel) Takes(n Notification) bool {
+	if n.Channel != "" {
+		return n.Channel == c.Name
+	}
+	if len(c.Topics) > 0 && !contains(c.Topics, n.Topic) {
+		return false
+	}
+	return c.MinSeverity == "" || incidents.SeverityAtLeast(n.Severity, c.MinSeverity)
+}
+
+// Target names the destination of the channel
+func (c *Channel) Target() string {
+	switch c.Type {
+	case ChannelEmail:
+		return fmt.Sprintf("%v", c.To)
+	case ChannelPagerDuty:
+		return "pagerduty"
+	}
+	return c.URL
+}
+
+// Render returns the text of a notification sent to the channel
+func (c *Channel) Render(n Notification) (string, error) {
+	text := c.Template
+	if text == "" {
+		text = DefaultTemplate
+	}
+	t, err := template.New(c.Name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, n); err != nil {
+		return "", fmt.Errorf("rendering template failed: %w", err)
+	}
+	return b.String(), nil
+}
+
+// Redacted returns a copy of the channel without its credentials. Secret
+// references are kept.
+func (c Channel) Redacted() Channel {
+	c.Password = secrets.Redact(c.Password)
+	c.RoutingKey = secrets.Redact(c.RoutingKey)
+	return c
+}
+
+// Notification is something a tenant is told about
+type Notification struct {
+	TenantID string            `json:"tenant_id"`
+	Topic    string            `json:"topic"`
+	Severity string            `json:"severity"`
+	Title    string            `json:"title"`
+	Message  string            `json:"message,omitempty"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Link     string            `json:"link,omitempty"`
+	DedupKey string            `json:"dedup_key,omitempty"` // Groups the notifications of one subject, such as an incident
+	Resolved bool              `json:"resolved,omitempty"`  // The subject of an earlier notification was resolved
+	Channel  string            `json:"channel,omitempty"`   // Only this channel, whatever its topics; otherwise every channel taking the topic
+	Time     time.Time         `json:"time"`
+}
+
+// Delivery is the outcome of sending a notification to a channel
+type Delivery struct {
+	Channel     string      `json:"channel"`
+	Type        ChannelType `json:"type"`
+	Topic       string      `json:"topic"`
+	Title       string      `json:"title"`
+	Error       string      `json:"error,omitempty"`
+	DeliveredAt time.Time   `json:"delivered_at"`
+}
+
+// Sender sends a notification to a channel, such as the service itself or
+// a sandbox recording it
+type Sender interface {
+	Send(ctx context.Context, channel Channel, n Notification) error
+}
+
+// Config configures notification delivery
+type Config struct {
+	Timeout       time.Duration // Of each delivery
+	PagerDutyURL  string        // Events API v2 endpoint
+	MaxDeliveries int           // Deliveries kept per tenant
+}
+
+// DefaultConfig returns the default notification configuration
+func DefaultConfig() Config {
+	return Config{
+		Timeout:       10 * time.Second,
+		PagerDutyURL:  "https://events.pagerduty.com/v2/enqueue",
+		MaxDeliveries: 100,
+	}
+}
+
+// Service keeps each tenant's notification channels and sends them the
+// notifications of pipelines, triggers, incidents, approvals and the
+// watchdog
+type Service struct {
+	config     Config
+	channels   map[string]map[string]Channel // tenantID -> name -> channel
+	deliveries map[string][]Delivery         // tenantID -> latest deliveries
+	resolvers  func(tenantID string) secrets.Resolver
+	senders    func(tenantID string) Sender // Nil if the service sends every tenant's notifications
+	client     *http.Client
+	sendMail   func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+	sent       int64
+	failed     int64
+	mu         sync.RWMutex
+}
+
+// NewService creates a service without channels
+func NewService(config Config) *Service {
+	defaults := DefaultConfig()
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.PagerDutyURL == "" {
+		config.PagerDutyURL = defaults.PagerDutyURL
+	}
+	if config.MaxDeliveries <= 0 {
+		config.MaxDeliveries = defaults.MaxDeliveries
+	}
+	return &Service{
+		config:     config,
+		channels:   make(map[string]map[string]Channel),
+		deliveries: make(map[string][]Delivery),
+		resolvers:  func(string) secrets.Resolver { return secrets.None },
+		client:     &http.Client{Timeout: config.Timeout},
+		sendMail:   smtp.SendMail,
+	}
+}
+
+// SetSecrets sets the function returning the resolver of a tenant's secret
+// references
+func (s *Service) SetSecrets(resolvers func(tenantID string) secrets.Resolver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resolvers = resolvers
+}
+
+// SetSenders sets the function returning the sender of a tenant's
+// notifications
+func (s *Service) SetSenders(senders func(tenantID string) Sender) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.senders = senders
+}
+
+// SetChannel creates or replaces a channel of a tenant
+func (s *Service) SetChannel(tenantID string, c Channel) (Channel, error) {
+	if err := c.Validate(); err != nil {
+		return Channel{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenant, exists := s.channels[tenantID]
+	if !exists {
+		tenant = make(map[string]Channel)
+		s.channels[tenantID] = tenant
+	}
+	if previous, exists := tenant[c.Name]; exists {
+		c.CreatedAt = previous.CreatedAt
+	} else {
+		c.CreatedAt = time.Now()
+	}
+	c.To = append([]string(nil), c.To...)
+	c.Topics = append([]string(nil), c.Topics...)
+	tenant[c.Name] = c
+	return c, nil
+}
+
+// GetChannel returns a channel of a tenant
+func (s *Service) GetChannel(tenantID, name string) (Channel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, exists := s.channels[tenantID][name]
+	if !exists {
+		return Channel{}, fmt.Errorf("notification channel %s not found", name)
+	}
+	return c, nil
+}
+
+// ListChannels returns a tenant's channels sorted by name
+func (s *Service) ListChannels(tenantID string) []Channel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Channel, 0, len(s.channels[tenantID]))
+	for _, c := range s.channels[tenantID] {
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// DeleteChannel removes a channel of a tenant
+func (s *Service) DeleteChannel(tenantID, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.channels[tenantID][name]; !exists {
+		return fmt.Errorf("notification channel %s not found", name)
+	}
+	delete(s.channels[tenantID], name)
+	if len(s.channels[tenantID]) == 0 {
+		delete(s.channels, tenantID)
+	}
+	return nil
+}
+
+// Purge removes a tenant's channels and deliveries and returns how many
+// channels there were
+func (s *Service) Purge(tenantID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := len(s.channels[tenantID])
+	delete(s.channels, tenantID)
+	delete(s.deliveries, tenantID)
+	return removed
+}
+
+// Notify sends a notification to every channel of its tenant taking it and
+// returns the outcome of each delivery. A failed delivery does not stop the
+// others.
+func (s *Service) Notify(ctx context.Context, n Notification) []Delivery {
+	if n.Time.IsZero() {
+		n.Time = time.Now()
+	}
+	if n.Severity == "" {
+		n.Severity = "info"
+	}
+
+	s.mu.RLock()
+	var channels []Channel
+	for _, c := range s.channels[n.TenantID] {
+		if c.Takes(n) {
+			channels = append(channels, c)
+		}
+	}
+	var sender Sender = s
+	if s.senders != nil {
+		sender = s.senders(n.TenantID)
+	}
+	s.mu.RUnlock()
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Name < channels[j].Name })
+
+	deliveries := make([]Delivery, 0, len(channels))
+	for _, c := range channels {
+		delivery := Delivery{Channel: c.Name, Type: c.Type, Topic: n.Topic, Title: n.Title, DeliveredAt: time.Now()}
+		if err := sender.Send(ctx, c, n); err != nil {
+			delivery.Error = err.Error()
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	s.record(n.TenantID, deliveries)
+	return deliveries
+}
+
+// Test sends a test notification to a channel of a tenant
+func (s *Service) Test(ctx context.Context, tenantID, name string) (Delivery, error) {
+	if _, err := s.GetChannel(tenantID, name); err != nil {
+		return Delivery{}, err
+	}
+	deliveries := s.Notify(ctx, Notification{
+		TenantID: tenantID,
+		Topic:    TopicTest,
+		Severity: "info",
+		Title:    "Test notification",
+		Message:  fmt.Sprintf("Channel %s of tenant %s is set up", name, tenantID),
+		Channel:  name,
+	})
+	if len(deliveries) == 0 {
+		return Delivery{}, fmt.Errorf("notification channel %s not found", name)
+	}
+	return deliveries[0], nil
+}
+
+func (s *Service) record(tenantID string, deliveries []Delivery) {
+	if len(deliveries) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, delivery := range deliveries {
+		if delivery.Error != "" {
+			s.failed++
+		} else {
+			s.sent++
+		}
+	}
+	list := append(s.deliveries[tenantID], deliveries...)
+	if over := len(list) - s.config.MaxDeliveries; over > 0 {
+		list = append([]Delivery(nil), list[over:]...)
+	}
+	s.deliveries[tenantID] = list
+}
+
+// Deliveries returns a tenant's latest deliveries, the newest last
+func (s *Service) Deliveries(tenantID string) []Delivery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Delivery{}, s.deliveries[tenantID]...)
+}
+
+// GetStats returns notification statistics
+func (s *Service) GetStats() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	channels := 0
+	for _, tenant := range s.channels {
+		channels += len(tenant)
+	}
+	return map[string]interface{}{
+		"channels": channels,
+		"tenants":  len(s.channels),
+		"sent":     s.sent,
+		"failed":   s.failed,
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recorder is a channel endpoint keeping the JSON bodies posted to it
+type recorder struct {
+	bodies []map[string]interface{}
+	status int
+	mu     sync.Mutex
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+	rec.mu.Lock()
+	rec.bodies = append(rec.bodies, body)
+	status := rec.status
+	rec.mu.Unlock()
+	if status != 0 {
+		w.WriteHeader(status)
+	}
+}
+
+func TestChannelValidate(t *testing.T) {
+	for _, c := range []Channel{
+		{Name: "ops", Type: ChannelSlack},
+		{Name: "ops", Type: ChannelPagerDuty},
+		{Name: "ops", Type: ChannelEmail, SMTPAddr: "smtp:25", From: "a@b"},
+		{Name: "ops", Type: ChannelWebhook, URL: "http://hook", Topics: []string{"atom.added"}},
+		{Name: "ops", Type: ChannelWebhook, URL: "http://hook", Template: "{{.Title"},
+		{Name: "../ops", Type: ChannelWebhook, URL: "http://hook"},
+		{Name: "ops", Type: "sms"},
+	} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", c)
+		}
+	}
+	c := Channel{Name: "ops", Type: ChannelSlack, URL: "secret://vault/slack-url", Topics: []string{TopicIncidentOpened}}
+	if err := c.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	webhook, slack, pagerDuty := &recorder{}, &recorder{}, &recorder{}
+	servers := make([]*httptest.Server, 3)
+	for i, handler := range []http.Handler{webhook, slack, pagerDuty} {
+		servers[i] = httptest.NewServer(handler)
+		defer servers[i].Close()
+	}
+
+	s := NewService(Config{PagerDutyURL: servers[2].URL})
+	var mails []string
+	s.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		mails = append(mails, string(msg))
+		return nil
+	}
+	for _, c := range []Channel{
+		{Name: "hook", Type: ChannelWebhook, URL: servers[0].URL},
+		{Name: "chat", Type: ChannelSlack, URL: servers[1].URL, Topics: []string{TopicIncidentOpened}, Template: "{{.Title}} ({{lower .Severity}})"},
+		{Name: "pager", Type: ChannelPagerDuty, RoutingKey: "key", MinSeverity: "critical"},
+		{Name: "mail", Type: ChannelEmail, SMTPAddr: "smtp:25", From: "erebus@example.com", To: []string{"ops@example.com"}, Topics: []string{TopicPipelineFailed}},
+	} {
+		if _, err := s.SetChannel("t", c); err != nil {
+			t.Fatalf("SetChannel(%s): %v", c.Name, err)
+		}
+	}
+
+	deliveries := s.Notify(context.Background(), Notification{
+		TenantID: "t",
+		Topic:    TopicIncidentOpened,
+		Severity: "critical",
+		Title:    "HighCPU on web-1",
+		Fields:   map[string]string{"incident_id": "inc-1"},
+		DedupKey: "t/inc-1",
+	})
+	if len(deliveries) != 3 {
+		t.Fatalf("expected the webhook, Slack and PagerDuty channels, got %+v", deliveries)
+	}
+	for _, d := range deliveries {
+		if d.Error != "" {
+			t.Errorf("delivery to %s failed: %s", d.Channel, d.Error)
+		}
+	}
+	if len(webhook.bodies) != 1 || webhook.bodies[0]["text"] != "[CRITICAL] HighCPU on web-1" {
+		t.Errorf("webhook received %v", webhook.bodies)
+	}
+	if len(slack.bodies) != 1 || slack.bodies[0]["text"] != "HighCPU on web-1 (critical)" {
+		t.Errorf("Slack received %v", slack.bodies)
+	}
+	event := pagerDuty.bodies[0]
+	if event["routing_key"] != "key" || event["event_action"] != "trigger" || event["dedup_key"] != "t/inc-1" {
+		t.Errorf("PagerDuty received %v", event)
+	}
+	if payload := event["payload"].(map[string]interface{}); payload["severity"] != "critical" {
+		t.Errorf("PagerDuty payload %v", payload)
+	}
+
+	// A resolution resolves the PagerDuty incident; a warning is below the
+	// channel's minimum severity
+	s.Notify(context.Background(), Notification{TenantID: "t", Topic: TopicIncidentOpened, Severity: "critical", DedupKey: "t/inc-1", Resolved: true})
+	s.Notify(context.Background(), Notification{TenantID: "t", Topic: TopicIncidentOpened, Severity: "warning"})
+	if len(pagerDuty.bodies) != 2 || pagerDuty.bodies[1]["event_action"] != "resolve" {
+		t.Errorf("PagerDuty received %v", pagerDuty.bodies)
+	}
+
+	s.Notify(context.Background(), Notification{TenantID: "t", Topic: TopicPipelineFailed, Severity: "error", Title: "Pipeline etl failed", Fields: map[string]string{"stage": "load"}})
+	if len(mails) != 1 || !strings.Contains(mails[0], "Subject: [error] Pipeline etl failed\r\n") || !strings.Contains(mails[0], "stage: load") {
+		t.Errorf("mails = %q", mails)
+	}
+
+	webhook.status = http.StatusBadGateway
+	delivery, err := s.Test(context.Background(), "t", "hook")
+	if err != nil || delivery.Error == "" || delivery.Topic != TopicTest {
+		t.Errorf("Test = %+v, %v", delivery, err)
+	}
+	if _, err := s.Test(context.Background(), "t", "missing"); err == nil {
+		t.Error("expected an error testing a missing channel")
+	}
+
+	if list := s.Deliveries("t"); len(list) != 11 {
+		t.Errorf("expected 11 deliveries, got %d", len(list))
+	}
+	if stats := s.GetStats(); stats["sent"] != int64(10) || stats["failed"] != int64(1) {
+		t.Errorf("stats = %v", stats)
+	}
+	if list := s.ListChannels("other"); len(list) != 0 {
+		t.Errorf("another tenant sees channels %v", list)
+	}
+	if removed := s.Purge("t"); removed != 4 || len(s.Deliveries("t")) != 0 {
+		t.Errorf("Purge = %d", removed)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	c := Channel{Name: "pager", Type: ChannelPagerDuty, RoutingKey: "raw-key"}
+	if c.Redacted().RoutingKey == "raw-key" {
+		t.Error("expected the routing key redacted")
+	}
+	c.RoutingKey = "secret://env/pagerduty"
+	if c.Redacted().RoutingKey != c.RoutingKey {
+		t.Error("expected secret references kept")
+	}
+}
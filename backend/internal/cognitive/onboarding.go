@@ -0,0 +1,232 @@
+package cognitive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/onboarding"
+)
+
+// SetTemplate creates or replaces an onboarding template
+func (ce *CognitiveEngine) SetTemplate(t onboarding.Template) (onboarding.Template, error) {
+	return ce.templates.Set(t)
+}
+
+// GetTemplate returns an onboarding template
+func (ce *CognitiveEngine) GetTemplate(name string) (onboarding.Template, error) {
+	return ce.templates.Get(name)
+}
+
+// ListTemplates returns the onboarding templates, including the built-in ones
+func (ce *CognitiveEngine) ListTemplates() []onboarding.Template {
+	return ce.templates.List()
+}
+
+// DeleteTemplate removes an onboarding template
+func (ce *CognitiveEngine) DeleteTemplate(name string) error {
+	return ce.templates.Delete(name)
+}
+
+// InitializeTenantFromTemplate initializes a tenant and onboards it with a
+// registered template
+func (ce *CognitiveEngine) InitializeTenantFromTemplate(tenantID, name string) (*onboarding.Result, error) {
+	t, err := ce.templates.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return ce.OnboardTenant(tenantID, t)
+}
+
+// OnboardTenant initializes a tenant and seeds it with a template: shared
+// spaces are mounted, the ontology is created, then pipelines, agents and
+// the budget. If any step fails the tenant is purged, so onboarding either
+// completes or leaves nothing behind.
+func (ce *CognitiveEngine) OnboardTenant(tenantID string, t onboarding.Template) (*onboarding.Result, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	if err := ce.InitializeTenant(tenantID); err != nil {
+		return nil, err
+	}
+
+	result, err := ce.onboard(tenantID, t)
+	if err != nil {
+		ce.PurgeTenant(context.Background(), tenantID)
+		return nil, fmt.Errorf("onboarding tenant %s with template %s failed: %w", tenantID, t.Name, err)
+	}
+	return result, nil
+}
+
+func (ce *CognitiveEngine) onboard(tenantID string, t onboarding.Template) (*onboarding.Result, error) {
+	result := &onboarding.Result{
+		TenantID:  tenantID,
+		Template:  t.Name,
+		Mounts:    []string{},
+		Pipelines: []string{},
+		Agents:    []string{},
+	}
+
+	for _, spaceID := range t.Ontology.Mounts {
+		if err := ce.MountSharedSpace(tenantID, spaceID); err != nil {
+			return nil, err
+		}
+		result.Mounts = append(result.Mounts, spaceID)
+	}
+
+	seeded, err := ce.seedOntology(tenantID, t.Ontology)
+	if err != nil {
+		return nil, err
+	}
+	result.Atoms = seeded.concepts + seeded.links
+	result.Conflicts = seeded.conflicts
+
+	for _, p := range t.Pipelines {
+		pipelineID := p.PipelineID(tenantID)
+		if _, err := ce.CreatePipelineFromSpec(pipelineID, tenantID, p.PipelineSpec); err != nil {
+			return nil, fmt.Errorf("pipeline %s: %w", p.ID, err)
+		}
+		result.Pipelines = append(result.Pipelines, pipelineID)
+	}
+
+	a := t.Agents
+	if a.PatternMining != nil {
+		result.Agents = append(result.Agents, ce.EnablePatternMining(tenantID, *a.PatternMining).GetID())
+	}
+	if a.Clustering != nil {
+		result.Agents = append(result.Agents, ce.EnableClustering(tenantID, *a.Clustering).GetID())
+	}
+	if a.Forecasting != nil {
+		result.Agents = append(result.Agents, ce.EnableForecasting(tenantID, *a.Forecasting).GetID())
+	}
+	if a.Cost != nil {
+		result.Agents = append(result.Agents, ce.EnableCostTracking(tenantID, *a.Cost).GetID())
+	}
+	if a.SLO != nil {
+		result.Agents = append(result.Agents, ce.EnableSLOTracking(tenantID, *a.SLO).GetID())
+	}
+	if a.Runbooks != nil {
+		result.Agents = append(result.Agents, ce.EnableRunbooks(tenantID, *a.Runbooks).GetID())
+	}
+	if a.Drift != nil {
+		result.Agents = append(result.Agents, ce.EnableDrift(tenantID, *a.Drift).GetID())
+	}
+	if a.Reports != nil {
+		result.Agents = append(result.Agents, ce.EnableReports(tenantID, *a.Reports).GetID())
+	}
+
+	if t.Budget != nil {
+		if err := ce.SetTenantBudget(tenantID, *t.Budget); err != nil {
+			return nil, err
+		}
+		result.Budget = true
+	}
+	return result, nil
+}
+
+// seededOntology counts what seeding an ontology changed
+type seededOntology struct {
+	concepts  int
+	links     int
+	conflicts []string
+}
+
+// seedOntology creates the concepts and inheritance links of an ontology
+// that a tenant is missing, and sets the truth values of its existing
+// concepts. A protected ontology protects what it seeds and may set the
+// truth values of protected concepts. Concepts of mounted shared spaces
+// are used as they are. Atom
+// IDs are global, so a concept whose ID another tenant holds cannot be
+// created; it is reported as a conflict, with the links to it, and is best
+// shared through a shared space.
+func (ce *CognitiveEngine) seedOntology(tenantID string, ontology onboarding.Ontology) (seededOntology, error) {
+	var seeded seededOntology
+	ids := make(map[string]string, len(ontology.Concepts))
+	for _, c := range ontology.Concepts {
+		atomID := atomspace.GenerateAtomID(atomspace.ConceptNodeType, c.Name, nil)
+		tv := atomspace.TruthValue{Strength: c.Strength, Confidence: c.Confidence}
+		hasTV := c.Strength > 0 || c.Confidence > 0
+
+		existing, err := ce.shardManager.GetAtom(atomID, tenantID)
+		switch {
+		case err == nil:
+			if hasTV && existing.GetTruthValue() != tv {
+				err := ce.updateAtom(atomID, tenantID, func(atom atomspace.Atom) error {
+					atom.SetTruthValue(tv)
+					return nil
+				}, ontology.Protected)
+				if err != nil {
+					return seeded, fmt.Errorf("concept %s: %w", c.Name, err)
+				}
+				seeded.concepts++
+			}
+			if err := ce.protectSeeded(tenantID, existing, ontology); err != nil {
+				return seeded, fmt.Errorf("concept %s: %w", c.Name, err)
+			}
+		case errors.Is(err, atomspace.ErrOtherTenant):
+			seeded.conflicts = append(seeded.conflicts, fmt.Sprintf("concept %s: ID held by another tenant", c.Name))
+			continue
+		default:
+			if _, shared := ce.findMountedAtom(atomID, tenantID); !shared {
+				node := atomspace.NewNode(atomID, c.Name, tenantID, atomspace.ConceptNodeType)
+				if hasTV {
+					node.SetTruthValue(tv)
+				}
+				if err := ce.AddAtom(node); err != nil {
+					return seeded, fmt.Errorf("concept %s: %w", c.Name, err)
+				}
+				if err := ce.protectSeeded(tenantID, node, ontology); err != nil {
+					return seeded, fmt.Errorf("concept %s: %w", c.Name, err)
+				}
+				seeded.concepts++
+			}
+		}
+		ids[c.Name] = atomID
+	}
+
+	for _, link := range ontology.Inheritance {
+		childID, parentID := ids[link.Child], ids[link.Parent]
+		if childID == "" || parentID == "" {
+			seeded.conflicts = append(seeded.conflicts, fmt.Sprintf("inheritance %s -> %s: concept held by another tenant", link.Child, link.Parent))
+			continue
+		}
+		child, err := ce.GetAtom(childID, tenantID)
+		if err != nil {
+			return seeded, fmt.Errorf("concept %s: %w", link.Child, err)
+		}
+		parent, err := ce.GetAtom(parentID, tenantID)
+		if err != nil {
+			return seeded, fmt.Errorf("concept %s: %w", link.Parent, err)
+		}
+		linkID := atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", []atomspace.Atom{child, parent})
+		if existing, err := ce.GetAtom(linkID, tenantID); err == nil {
+			if err := ce.protectSeeded(tenantID, existing, ontology); err != nil {
+				return seeded, fmt.Errorf("inheritance %s -> %s: %w", link.Child, link.Parent, err)
+			}
+			continue
+		}
+		created, err := ce.CreateInheritanceLink(childID, parentID, tenantID)
+		if err != nil {
+			if errors.Is(err, atomspace.ErrOtherTenant) {
+				seeded.conflicts = append(seeded.conflicts, fmt.Sprintf("inheritance %s -> %s: ID held by another tenant", link.Child, link.Parent))
+				continue
+			}
+			return seeded, fmt.Errorf("inheritance %s -> %s: %w", link.Child, link.Parent, err)
+		}
+		if err := ce.protectSeeded(tenantID, created, ontology); err != nil {
+			return seeded, fmt.Errorf("inheritance %s -> %s: %w", link.Child, link.Parent, err)
+		}
+		seeded.links++
+	}
+	return seeded, nil
+}
+
+// protectSeeded protects a seeded atom of the tenant's own if the ontology
+// is protected. Atoms of mounted shared spaces are left as they are.
+func (ce *CognitiveEngine) protectSeeded(tenantID string, atom atomspace.Atom, ontology onboarding.Ontology) error {
+	if !ontology.Protected || atom.GetTenantID() != tenantID || atomspace.IsProtected(atom) {
+		return nil
+	}
+	return ce.ProtectAtom(tenantID, atom.GetID())
+}
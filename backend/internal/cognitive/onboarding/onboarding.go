@@ -0,0 +1,317 @@
+package onboarding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/budget"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/pipeline"
+)
+
+// ErrTemplateNotFound is returned for templates that are not registered
+var ErrTemplateNotFound = errors.New("template not found")
+
+// Concept is a concept node seeded into a new tenant. A zero truth value
+// seeds the node with the default one.
+type Concept struct {
+	Name       string  `json:"name"`
+	Strength   float64 `json:"strength,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+// Inheritance is an inheritance link seeded between two concepts of the
+// ontology
+type Inheritance struct {
+	Child  string `json:"child"`
+	Parent string `json:"parent"`
+}
+
+// Ontology is the knowledge a new tenant starts with: shared spaces it
+// mounts, and concepts and links seeded into its own space
+type Ontology struct {
+	Mounts      []string      `json:"mounts,omitempty"` // IDs of shared spaces
+	Concepts    []Concept     `json:"concepts,omitempty"`
+	Inheritance []Inheritance `json:"inheritance,omitempty"`
+	Protected   bool          `json:"protected,omitempty"` // Protect the seeded concepts and links from agents, decay and API changes
+}
+
+// Pipeline is a declarative pipeline created for a new tenant
+type Pipeline struct {
+	ID string `json:"id"` // Suffixed with the tenant ID, as pipeline IDs are global
+	pipeline.PipelineSpec
+}
+
+// PipelineID returns the ID of the pipeline created for a tenant
+func (p Pipeline) PipelineID(tenantID string) string {
+	return p.ID + "-" + tenantID
+}
+
+// Agents are the agents enabled for a new tenant, each with its
+// configuration. Settings missing from JSON take the agent's defaults.
+type Agents struct {
+	PatternMining *agents.PatternMinerConfig `json:"pattern_mining,omitempty"`
+	Clustering    *agents.ClusteringConfig   `json:"clustering,omitempty"`
+	Forecasting   *agents.ForecastConfig     `json:"forecasting,omitempty"`
+	Cost          *agents.CostConfig         `json:"cost,omitempty"`
+	SLO           *agents.SLOConfig          `json:"slo,omitempty"`
+	Runbooks      *agents.RunbookConfig      `json:"runbooks,omitempty"`
+	Drift         *agents.DriftConfig        `json:"drift,omitempty"`
+	Reports       *agents.ReportConfig       `json:"reports,omitempty"`
+}
+
+func (a *Agents) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	for name, value := range raw {
+		if string(value) == "null" {
+			continue
+		}
+		var config interface{}
+		switch name {
+		case "pattern_mining":
+			c := agents.DefaultPatternMinerConfig()
+			a.PatternMining, config = &c, &c
+		case "clustering":
+			c := agents.DefaultClusteringConfig()
+			a.Clustering, config = &c, &c
+		case "forecasting":
+			c := agents.DefaultForecastConfig()
+			a.Forecasting, config = &c, &c
+		case "cost":
+			c := agents.DefaultCostConfig()
+			a.Cost, config = &c, &c
+		case "slo":
+			c := agents.DefaultSLOConfig()
+			a.SLO, config = &c, &c
+		case "runbooks":
+			c := agents.DefaultRunbookConfig()
+			a.Runbooks, config = &c, &c
+		case "drift":
+			c := agents.DefaultDriftConfig()
+			a.Drift, config = &c, &c
+		case "reports":
+			c := agents.DefaultReportConfig()
+			a.Reports, config = &c, &c
+		default:
+			return fmt.Errorf("unknown agent %q", name)
+		}
+		if err := json.Unmarshal(value, config); err != nil {
+			return fmt.Errorf("agent %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Template describes what a tenant is onboarded with, so it is useful as
+// soon as it is initialized
+type Template struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Ontology    Ontology       `json:"ontology"`
+	Pipelines   []Pipeline     `json:"pipelines,omitempty"`
+	Agents      Agents         `json:"agents"`
+	Budget      *budget.Budget `json:"budget,omitempty"` // Quota of the tenant's agents
+	Builtin     bool           `json:"builtin,omitempty"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// Validate checks a template for consistency
+func (t *Template) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("template name is required")
+	}
+
+	concepts := make(map[string]bool, len(t.Ontology.Concepts))
+	for _, c := range t.Ontology.Concepts {
+		if c.Name == "" {
+			return fmt.Errorf("concept name is required")
+		}
+		if concepts[c.Name] {
+			return fmt.Errorf("duplicate concept %s", c.Name)
+		}
+		if c.Strength < 0 || c.Strength > 1 || c.Confidence < 0 || c.Confidence > 1 {
+			return fmt.Errorf("truth value of concept %s must be between 0 and 1", c.Name)
+		}
+		concepts[c.Name] = true
+	}
+	for _, link := range t.Ontology.Inheritance {
+		if !concepts[link.Child] || !concepts[link.Parent] {
+			return fmt.Errorf("inheritance %s -> %s must link concepts of the ontology", link.Child, link.Parent)
+		}
+	}
+
+	pipelines := make(map[string]bool, len(t.Pipelines))
+	for _, p := range t.Pipelines {
+		if p.ID == "" {
+			return fmt.Errorf("pipeline ID is required")
+		}
+		if pipelines[p.ID] {
+			return fmt.Errorf("duplicate pipeline %s", p.ID)
+		}
+		if len(p.Stages) == 0 {
+			return fmt.Errorf("pipeline %s has no stages", p.ID)
+		}
+		pipelines[p.ID] = true
+	}
+
+	if t.Budget != nil {
+		if err := t.Budget.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Result is what onboarding a tenant created
+type Result struct {
+	TenantID  string   `json:"tenant_id"`
+	Template  string   `json:"template"`
+	Mounts    []string `json:"mounts"`
+	Atoms     int      `json:"atoms"`
+	Pipelines []string `json:"pipelines"`
+	Agents    []string `json:"agents"`
+	Budget    bool     `json:"budget"`
+	Conflicts []string `json:"conflicts,omitempty"` // Ontology atoms whose IDs other tenants hold
+}
+
+// Registry holds the onboarding templates, starting with the built-in ones
+type Registry struct {
+	templates map[string]Template
+	mu        sync.RWMutex
+}
+
+// NewRegistry creates a registry holding the built-in templates
+func NewRegistry() *Registry {
+	r := &Registry{templates: make(map[string]Template)}
+	for _, t := range Builtin() {
+		t.Builtin = true
+		t.UpdatedAt = time.Now()
+		r.templates[t.Name] = t
+	}
+	return r
+}
+
+// Set creates or replaces a template. Built-in templates can be replaced;
+// the replacement is no longer built-in.
+func (r *Registry) Set(t Template) (Template, error) {
+	if err := t.Validate(); err != nil {
+		return Template{}, err
+	}
+	t.Builtin = false
+	t.UpdatedAt = time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.templates[t.Name] = t
+	return t, nil
+}
+
+// Get returns a template
+func (r *Registry) Get(name string) (Template, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, exists := r.templates[name]
+	if !exists {
+		return Template{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	return t, nil
+}
+
+// List returns the templates, ordered by name
+func (r *Registry) List() []Template {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	templates := make([]Template, 0, len(r.templates))
+	for _, t := range r.templates {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates
+}
+
+// Delete removes a template. Tenants onboarded with it are unaffected.
+func (r *Registry) Delete(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.templates[name]; !exists {
+		return fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	delete(r.templates, name)
+	return nil
+}
+
+// Builtin returns the templates every engine starts with
+func Builtin() []Template {
+	slo := agents.DefaultSLOConfig()
+	drift := agents.DefaultDriftConfig()
+	runbooks := agents.DefaultRunbookConfig()
+	runbooks.DryRun = true
+
+	return []Template{
+		{
+			Name:        "cognitive",
+			Description: "The default cognitive pipeline: inference, attention allocation and agent execution",
+			Pipelines: []Pipeline{{
+				ID: "cognitive",
+				PipelineSpec: pipeline.PipelineSpec{
+					Name: "Default Cognitive Pipeline",
+					Stages: []pipeline.StageSpec{
+						{Kind: "inference", Params: pipeline.StageParams{"max_iterations": 5}},
+						{Kind: "attention-allocation"},
+						{Kind: "agent-execution"},
+					},
+				},
+			}},
+		},
+		{
+			Name:        "infrastructure",
+			Description: "Infrastructure operations: a resource ontology, anomaly triage and SLO, drift and runbook agents",
+			Ontology: Ontology{
+				Concepts: []Concept{
+					{Name: "InfrastructureResource"},
+					{Name: "KubernetesNode"},
+					{Name: "Namespace"},
+					{Name: "Pod"},
+					{Name: "Service"},
+					{Name: "Database"},
+					{Name: "Finding"},
+					{Name: pipeline.AnomalyConcept},
+					{Name: pipeline.RemediationProposalConcept},
+				},
+				Inheritance: []Inheritance{
+					{Child: "KubernetesNode", Parent: "InfrastructureResource"},
+					{Child: "Namespace", Parent: "InfrastructureResource"},
+					{Child: "Pod", Parent: "InfrastructureResource"},
+					{Child: "Service", Parent: "InfrastructureResource"},
+					{Child: "Database", Parent: "InfrastructureResource"},
+					{Child: pipeline.AnomalyConcept, Parent: "Finding"},
+					{Child: pipeline.RemediationProposalConcept, Parent: "Finding"},
+				},
+			},
+			Pipelines: []Pipeline{{
+				ID: "anomaly-triage",
+				PipelineSpec: pipeline.PipelineSpec{
+					Name: "Anomaly Triage",
+					Stages: []pipeline.StageSpec{
+						{Kind: "anomaly-score"},
+						{Kind: "remediation-proposal"},
+						{Kind: "report", Params: pipeline.StageParams{"title": "Anomaly Triage"}},
+					},
+				},
+			}},
+			Agents: Agents{SLO: &slo, Drift: &drift, Runbooks: &runbooks},
+			Budget: &budget.Budget{Window: time.Hour, MaxWallTime: 10 * time.Minute, Action: budget.ActionDeprioritize},
+		},
+	}
+}
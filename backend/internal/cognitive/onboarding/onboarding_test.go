@@ -0,0 +1,90 @@
+package onboarding
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/budget"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/pipeline"
+)
+
+func TestValidate(t *testing.T) {
+	stages := []pipeline.StageSpec{{Kind: "inference"}}
+	cases := []struct {
+		name     string
+		template Template
+		valid    bool
+	}{
+		{"empty", Template{Name: "empty"}, true},
+		{"no name", Template{}, false},
+		{"duplicate concept", Template{Name: "t", Ontology: Ontology{Concepts: []Concept{{Name: "a"}, {Name: "a"}}}}, false},
+		{"truth value", Template{Name: "t", Ontology: Ontology{Concepts: []Concept{{Name: "a", Strength: 1.5}}}}, false},
+		{"unknown parent", Template{Name: "t", Ontology: Ontology{
+			Concepts:    []Concept{{Name: "a"}},
+			Inheritance: []Inheritance{{Child: "a", Parent: "b"}},
+		}}, false},
+		{"duplicate pipeline", Template{Name: "t", Pipelines: []Pipeline{
+			{ID: "p", PipelineSpec: pipeline.PipelineSpec{Stages: stages}},
+			{ID: "p", PipelineSpec: pipeline.PipelineSpec{Stages: stages}},
+		}}, false},
+		{"no stages", Template{Name: "t", Pipelines: []Pipeline{{ID: "p"}}}, false},
+		{"invalid budget", Template{Name: "t", Budget: &budget.Budget{Window: -time.Second}}, false},
+	}
+	for _, c := range cases {
+		if err := c.template.Validate(); (err == nil) != c.valid {
+			t.Errorf("%s: expected valid=%v, got %v", c.name, c.valid, err)
+		}
+	}
+	for _, tmpl := range Builtin() {
+		if err := tmpl.Validate(); err != nil {
+			t.Errorf("built-in template %s is invalid: %v", tmpl.Name, err)
+		}
+	}
+}
+
+func TestAgentsJSON(t *testing.T) {
+	var a Agents
+	if err := json.Unmarshal([]byte(`{"slo": {}, "runbooks": {"dry_run": true}, "drift": null}`), &a); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if a.SLO == nil || *a.SLO != agents.DefaultSLOConfig() {
+		t.Errorf("expected an empty config to take the defaults, got %+v", a.SLO)
+	}
+	if a.Runbooks == nil || !a.Runbooks.DryRun {
+		t.Errorf("expected settings applied over the defaults, got %+v", a.Runbooks)
+	}
+	if a.Drift != nil || a.Cost != nil {
+		t.Errorf("expected null and missing agents disabled, got %+v", a)
+	}
+	if err := json.Unmarshal([]byte(`{"unknown": {}}`), &a); err == nil {
+		t.Error("expected an unknown agent to be rejected")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	if list := r.List(); len(list) != 2 || list[0].Name != "cognitive" || !list[0].Builtin {
+		t.Fatalf("expected the built-in templates, got %+v", list)
+	}
+
+	replaced, err := r.Set(Template{Name: "cognitive", Description: "Custom"})
+	if err != nil || replaced.Builtin {
+		t.Fatalf("expected the built-in template replaced, got %+v (%v)", replaced, err)
+	}
+	if _, err := r.Set(Template{}); err == nil {
+		t.Error("expected an invalid template to be rejected")
+	}
+
+	if err := r.Delete("cognitive"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := r.Get("cognitive"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("expected the template removed, got %v", err)
+	}
+	if err := r.Delete("cognitive"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("expected deleting a missing template to fail, got %v", err)
+	}
+}
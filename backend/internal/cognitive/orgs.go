@@ -0,0 +1,254 @@
+package cognitive
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/budget"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/orgs"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/usage"
+)
+
+// SetOrganization creates or replaces an organization and passes its
+// settings on to its projects
+func (ce *CognitiveEngine) SetOrganization(o orgs.Organization) (orgs.Organization, error) {
+	ce.mu.RLock()
+	for _, spaceID := range o.Mounts {
+		if _, exists := ce.sharedSpaces[spaceID]; !exists {
+			ce.mu.RUnlock()
+			return orgs.Organization{}, fmt.Errorf("shared space %s not found", spaceID)
+		}
+	}
+	ce.mu.RUnlock()
+	set, err := ce.orgs.Set(o)
+	if err != nil {
+		return orgs.Organization{}, err
+	}
+	for _, tenantID := range set.Projects {
+		if err := ce.inherit(tenantID, set); err != nil {
+			return set, fmt.Errorf("project %s: %w", tenantID, err)
+		}
+	}
+	return set, nil
+}
+
+// GetOrganization returns an organization
+func (ce *CognitiveEngine) GetOrganization(orgID string) (orgs.Organization, error) {
+	return ce.orgs.Get(orgID)
+}
+
+// ListOrganizations returns the organizations
+func (ce *CognitiveEngine) ListOrganizations() []orgs.Organization {
+	return ce.orgs.List()
+}
+
+// DeleteOrganization removes an organization and its API keys. Its projects
+// are detached first, losing what they inherited.
+func (ce *CognitiveEngine) DeleteOrganization(orgID string) error {
+	o, err := ce.orgs.Get(orgID)
+	if err != nil {
+		return err
+	}
+	for _, tenantID := range o.Projects {
+		if err := ce.RemoveProject(orgID, tenantID); err != nil {
+			return err
+		}
+	}
+	return ce.orgs.Delete(orgID)
+}
+
+// AddProject makes an initialized tenant a project of an organization,
+// which it inherits the settings of
+func (ce *CognitiveEngine) AddProject(orgID, tenantID string) error {
+	ce.mu.RLock()
+	_, initialized := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+	if !initialized {
+		return fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+
+	if err := ce.orgs.AddProject(orgID, tenantID); err != nil {
+		return err
+	}
+	o, err := ce.orgs.Get(orgID)
+	if err != nil {
+		return err
+	}
+	return ce.inherit(tenantID, o)
+}
+
+// RemoveProject detaches a project from its organization. What it inherited
+// is removed; what it set itself is kept.
+func (ce *CognitiveEngine) RemoveProject(orgID, tenantID string) error {
+	if !ce.orgs.Reaches(orgID, tenantID) {
+		return fmt.Errorf("tenant %s is not a project of organization %s", tenantID, orgID)
+	}
+	if err := ce.inherit(tenantID, orgs.Organization{ID: orgID}); err != nil {
+		return err
+	}
+	return ce.orgs.RemoveProject(orgID, tenantID)
+}
+
+// GetTenantOrganization returns the organization a tenant is a project of
+// and what the tenant inherited from it
+func (ce *CognitiveEngine) GetTenantOrganization(tenantID string) (orgs.Organization, orgs.Inherited, error) {
+	o, ok := ce.orgs.OrgOf(tenantID)
+	if !ok {
+		return orgs.Organization{}, orgs.Inherited{}, fmt.Errorf("tenant %s is not a project of any organization", tenantID)
+	}
+	return o, ce.orgs.Inherited(tenantID), nil
+}
+
+// inherit brings a project's inherited settings in line with its
+// organization's. Settings the project set itself are left alone, and
+// inherited ones the organization dropped are removed. An unchanged budget
+// is not set again, so its window is not restarted.
+func (ce *CognitiveEngine) inherit(tenantID string, o orgs.Organization) error {
+	previous := ce.orgs.Inherited(tenantID)
+	next := orgs.Inherited{DecayPolicies: []string{}, Mounts: []string{}}
+	budgets := ce.agentScheduler.Budgets()
+
+	current, hasBudget := budgets.GetBudget(tenantID)
+	switch {
+	case o.Budget != nil && (!hasBudget || previous.Budget):
+		if !hasBudget || current != *o.Budget {
+			if err := budgets.SetBudget(tenantID, *o.Budget); err != nil {
+				return err
+			}
+		}
+		next.Budget = true
+	case o.Budget == nil && previous.Budget:
+		budgets.RemoveBudget(tenantID)
+	}
+
+	inheritedPolicy := make(map[string]bool, len(previous.DecayPolicies))
+	for _, name := range previous.DecayPolicies {
+		inheritedPolicy[name] = true
+	}
+	for _, p := range o.DecayPolicies {
+		existing, err := ce.decayPolicies.Get(tenantID, p.Name)
+		if err == nil && !inheritedPolicy[p.Name] {
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(existing, p) {
+			if _, err := ce.decayPolicies.Set(tenantID, p); err != nil {
+				return err
+			}
+		}
+		next.DecayPolicies = append(next.DecayPolicies, p.Name)
+		delete(inheritedPolicy, p.Name)
+	}
+	for name := range inheritedPolicy {
+		ce.decayPolicies.Delete(tenantID, name)
+	}
+
+	inheritedMount := make(map[string]bool, len(previous.Mounts))
+	for _, spaceID := range previous.Mounts {
+		inheritedMount[spaceID] = true
+	}
+	mounted := make(map[string]bool)
+	for _, spaceID := range ce.GetMounts(tenantID) {
+		mounted[spaceID] = true
+	}
+	for _, spaceID := range o.Mounts {
+		if mounted[spaceID] && !inheritedMount[spaceID] {
+			continue
+		}
+		if !mounted[spaceID] {
+			if err := ce.MountSharedSpace(tenantID, spaceID); err != nil {
+				return err
+			}
+		}
+		next.Mounts = append(next.Mounts, spaceID)
+		delete(inheritedMount, spaceID)
+	}
+	for spaceID := range inheritedMount {
+		ce.UnmountSharedSpace(tenantID, spaceID)
+	}
+
+	ce.orgs.SetInherited(tenantID, next)
+	return nil
+}
+
+// CreateAPIKey creates an API key reaching an organization and its
+// projects. The secret is only returned here.
+func (ce *CognitiveEngine) CreateAPIKey(orgID, name string, roles []string) (orgs.APIKey, string, error) {
+	return ce.orgs.CreateKey(orgID, name, roles)
+}
+
+// ListAPIKeys returns an organization's API keys, without their secrets
+func (ce *CognitiveEngine) ListAPIKeys(orgID string) ([]orgs.APIKey, error) {
+	if _, err := ce.orgs.Get(orgID); err != nil {
+		return nil, err
+	}
+	return ce.orgs.ListKeys(orgID), nil
+}
+
+// RevokeAPIKey deletes an API key of an organization
+func (ce *CognitiveEngine) RevokeAPIKey(orgID, keyID string) error {
+	return ce.orgs.RevokeKey(orgID, keyID)
+}
+
+// AuthenticateAPIKey returns the API key a secret belongs to
+func (ce *CognitiveEngine) AuthenticateAPIKey(secret string) (orgs.APIKey, bool) {
+	return ce.orgs.Authenticate(secret)
+}
+
+// APIKeyReaches reports whether an organization's API keys reach a tenant
+func (ce *CognitiveEngine) APIKeyReaches(orgID, tenantID string) bool {
+	return ce.orgs.Reaches(orgID, tenantID)
+}
+
+// ProjectStats is the activity of one project of an organization
+type ProjectStats struct {
+	TenantID   string       `json:"tenant_id"`
+	Atoms      int          `json:"atoms"`
+	Agents     int          `json:"agents"`
+	AgentUsage budget.Usage `json:"agent_usage"` // In the project's current budget window
+	Requests   int64        `json:"requests"`    // API requests within the usage retention
+	Errors     int64        `json:"errors"`
+}
+
+// OrganizationStats sums the activity of an organization's projects
+type OrganizationStats struct {
+	OrgID    string         `json:"org_id"`
+	Projects []ProjectStats `json:"projects"`
+	Total    ProjectStats   `json:"total"`
+}
+
+// GetOrganizationStats returns the activity of each of an organization's
+// projects and their sum
+func (ce *CognitiveEngine) GetOrganizationStats(orgID string) (OrganizationStats, error) {
+	o, err := ce.orgs.Get(orgID)
+	if err != nil {
+		return OrganizationStats{}, err
+	}
+
+	stats := OrganizationStats{OrgID: orgID, Projects: make([]ProjectStats, 0, len(o.Projects))}
+	since := time.Now().Add(-ce.usageTracker.Config().Retention)
+	for _, tenantID := range o.Projects {
+		project := ProjectStats{TenantID: tenantID, Agents: len(ce.GetAgentsByTenant(tenantID))}
+		if total, ok := ce.shardManager.GetTenantStats(tenantID)["total_atoms"].(int); ok {
+			project.Atoms = total
+		}
+		if u, ok := ce.GetTenantUsage(tenantID)["usage"].(budget.Usage); ok {
+			project.AgentUsage = u
+		}
+		totals, _ := ce.usageTracker.Summarize(since, usage.Key{TenantID: tenantID}, []string{usage.ByTenant})
+		for _, t := range totals {
+			project.Requests += t.Requests
+			project.Errors += t.Errors
+		}
+		stats.Projects = append(stats.Projects, project)
+
+		stats.Total.Atoms += project.Atoms
+		stats.Total.Agents += project.Agents
+		stats.Total.AgentUsage.Runs += project.AgentUsage.Runs
+		stats.Total.AgentUsage.WallTime += project.AgentUsage.WallTime
+		stats.Total.AgentUsage.Iterations += project.AgentUsage.Iterations
+		stats.Total.Requests += project.Requests
+		stats.Total.Errors += project.Errors
+	}
+	return stats, nil
+}
@@ -0,0 +1,411 @@
+package orgs
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/budget"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/decay"
+)
+
+// Organization owns project tenants. Its quota, decay policies and shared
+// spaces are inherited by each of its projects, which may set their own
+// instead, and its API keys reach all of them.
+type Organization struct {
+	ID            string         `json:"id"`
+	Name          string         `json:"name,omitempty"`
+	Budget        *budget.Budget `json:"budget,omitempty"`         // Quota of each project's agents
+	DecayPolicies []decay.Policy `json:"decay_policies,omitempty"` // Truth-value decay of each project's atoms
+	Mounts        []string       `json:"mounts,omitempty"`         // Shared spaces mounted into each project
+	Projects      []string       `json:"projects"`                 // Tenant IDs, set by adding projects
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// Validate checks the organization's settings
+func (o *Organization) Validate() error {
+	if o.ID == "" {
+		return fmt.Errorf("organization ID is required")
+	}
+	if o.Budget != nil {
+		if err := o.Budget.Validate(); err != nil {
+			return err
+		}
+	}
+	names := make(map[string]bool, len(o.DecayPolicies))
+	for i := range o.DecayPolicies {
+		if err := o.DecayPolicies[i].Validate(); err != nil {
+			return err
+		}
+		if names[o.DecayPolicies[i].Name] {
+			return fmt.Errorf("duplicate decay policy %s", o.DecayPolicies[i].Name)
+		}
+		names[o.DecayPolicies[i].Name] = true
+	}
+	mounts := make(map[string]bool, len(o.Mounts))
+	for _, spaceID := range o.Mounts {
+		if spaceID == "" || mounts[spaceID] {
+			return fmt.Errorf("mounts must be distinct shared space IDs")
+		}
+		mounts[spaceID] = true
+	}
+	return nil
+}
+
+// clone copies the organization so callers cannot change the registry's
+func (o *Organization) clone() Organization {
+	c := *o
+	c.DecayPolicies = append([]decay.Policy(nil), o.DecayPolicies...)
+	c.Mounts = append([]string(nil), o.Mounts...)
+	c.Projects = append([]string{}, o.Projects...)
+	if o.Budget != nil {
+		b := *o.Budget
+		c.Budget = &b
+	}
+	return c
+}
+
+// Inherited is what a project holds from its organization. Settings the
+// project set itself are not listed, and are kept when the organization's
+// change.
+type Inherited struct {
+	Budget        bool     `json:"budget"`
+	DecayPolicies []string `json:"decay_policies"`
+	Mounts        []string `json:"mounts"`
+}
+
+// Setting is a kind of setting a project may inherit
+type Setting string
+
+const (
+	SettingBudget      Setting = "budget"
+	SettingDecayPolicy Setting = "decay_policy"
+	SettingMount       Setting = "mount"
+)
+
+// APIKey authenticates requests to an organization and its projects
+type APIKey struct {
+	ID        string    `json:"id"`
+	OrgID     string    `json:"org_id"`
+	Name      string    `json:"name"`
+	Roles     []string  `json:"roles,omitempty"` // Roles of the requests it authenticates
+	CreatedAt time.Time `json:"created_at"`
+	LastUsed  time.Time `json:"last_used_at,omitempty"`
+	hash      string
+}
+
+// keyPrefix starts every API key, so leaked keys are easy to recognize
+const keyPrefix = "erebus_"
+
+// hashKey returns the form keys are stored in; the secret itself is only
+// returned when the key is created
+func hashKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Registry holds the organizations, which tenants are their projects, what
+// each project inherited and the organizations' API keys
+type Registry struct {
+	orgs      map[string]*Organization
+	projects  map[string]string    // tenantID -> orgID
+	inherited map[string]Inherited // tenantID -> settings inherited
+	keys      map[string]*APIKey   // hash -> key
+	mu        sync.RWMutex
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		orgs:      make(map[string]*Organization),
+		projects:  make(map[string]string),
+		inherited: make(map[string]Inherited),
+		keys:      make(map[string]*APIKey),
+	}
+}
+
+// Set creates or replaces an organization's settings, keeping its projects
+func (r *Registry) Set(o Organization) (Organization, error) {
+	if err := o.Validate(); err != nil {
+		return Organization{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	o.Projects, o.CreatedAt, o.UpdatedAt = nil, now, now
+	if existing, exists := r.orgs[o.ID]; exists {
+		o.Projects, o.CreatedAt = existing.Projects, existing.CreatedAt
+	}
+	stored := o.clone()
+	r.orgs[o.ID] = &stored
+	return stored.clone(), nil
+}
+
+// Get returns an organization
+func (r *Registry) Get(orgID string) (Organization, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	o, exists := r.orgs[orgID]
+	if !exists {
+		return Organization{}, fmt.Errorf("organization %s not found", orgID)
+	}
+	return o.clone(), nil
+}
+
+// List returns the organizations sorted by ID
+func (r *Registry) List() []Organization {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]Organization, 0, len(r.orgs))
+	for _, o := range r.orgs {
+		result = append(result, o.clone())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// Delete removes an organization without projects, and its API keys
+func (r *Registry) Delete(orgID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	o, exists := r.orgs[orgID]
+	if !exists {
+		return fmt.Errorf("organization %s not found", orgID)
+	}
+	if len(o.Projects) > 0 {
+		return fmt.Errorf("organization %s still has %d projects", orgID, len(o.Projects))
+	}
+	delete(r.orgs, orgID)
+	for hash, key := range r.keys {
+		if key.OrgID == orgID {
+			delete(r.keys, hash)
+		}
+	}
+	return nil
+}
+
+// AddProject makes a tenant a project of an organization. A tenant belongs
+// to one organization at most.
+func (r *Registry) AddProject(orgID, tenantID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	o, exists := r.orgs[orgID]
+	if !exists {
+		return fmt.Errorf("organization %s not found", orgID)
+	}
+	if current, exists := r.projects[tenantID]; exists {
+		if current == orgID {
+			return nil
+		}
+		return fmt.Errorf("tenant %s is a project of organization %s", tenantID, current)
+	}
+	r.projects[tenantID] = orgID
+	o.Projects = append(o.Projects, tenantID)
+	sort.Strings(o.Projects)
+	return nil
+}
+
+// RemoveProject detaches a project from its organization
+func (r *Registry) RemoveProject(orgID, tenantID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.projects[tenantID] != orgID {
+		return fmt.Errorf("tenant %s is not a project of organization %s", tenantID, orgID)
+	}
+	r.removeProjectLocked(orgID, tenantID)
+	return nil
+}
+
+func (r *Registry) removeProjectLocked(orgID, tenantID string) {
+	delete(r.projects, tenantID)
+	delete(r.inherited, tenantID)
+	o := r.orgs[orgID]
+	for i, id := range o.Projects {
+		if id == tenantID {
+			o.Projects = append(o.Projects[:i:i], o.Projects[i+1:]...)
+			break
+		}
+	}
+}
+
+// OrgOf returns the organization a tenant is a project of
+func (r *Registry) OrgOf(tenantID string) (Organization, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	orgID, exists := r.projects[tenantID]
+	if !exists {
+		return Organization{}, false
+	}
+	return r.orgs[orgID].clone(), true
+}
+
+// Inherited returns what a project holds from its organization
+func (r *Registry) Inherited(tenantID string) Inherited {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	inherited := r.inherited[tenantID]
+	inherited.DecayPolicies = append([]string{}, inherited.DecayPolicies...)
+	inherited.Mounts = append([]string{}, inherited.Mounts...)
+	return inherited
+}
+
+// SetInherited records what a project holds from its organization
+func (r *Registry) SetInherited(tenantID string, inherited Inherited) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, isProject := r.projects[tenantID]; isProject {
+		r.inherited[tenantID] = inherited
+	}
+}
+
+// Own records that a project set a setting itself, so it is no longer
+// inherited and is kept when its organization's settings change
+func (r *Registry) Own(tenantID string, setting Setting, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inherited, exists := r.inherited[tenantID]
+	if !exists {
+		return
+	}
+	switch setting {
+	case SettingBudget:
+		inherited.Budget = false
+	case SettingDecayPolicy:
+		inherited.DecayPolicies = without(inherited.DecayPolicies, name)
+	case SettingMount:
+		inherited.Mounts = without(inherited.Mounts, name)
+	}
+	r.inherited[tenantID] = inherited
+}
+
+func without(names []string, name string) []string {
+	result := make([]string, 0, len(names))
+	for _, n := range names {
+		if n != name {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// CreateKey creates an API key of an organization. The secret is returned
+// once; only its hash is kept.
+func (r *Registry) CreateKey(orgID, name string, roles []string) (APIKey, string, error) {
+	if name == "" {
+		return APIKey{}, "", fmt.Errorf("key name is required")
+	}
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return APIKey{}, "", err
+	}
+	secret := keyPrefix + hex.EncodeToString(raw)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.orgs[orgID]; !exists {
+		return APIKey{}, "", fmt.Errorf("organization %s not found", orgID)
+	}
+	key := &APIKey{
+		ID:        hex.EncodeToString(raw[:4]),
+		OrgID:     orgID,
+		Name:      name,
+		Roles:     append([]string(nil), roles...),
+		CreatedAt: time.Now(),
+		hash:      hashKey(secret),
+	}
+	r.keys[key.hash] = key
+	return *key, secret, nil
+}
+
+// ListKeys returns an organization's API keys sorted by name
+func (r *Registry) ListKeys(orgID string) []APIKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]APIKey, 0)
+	for _, key := range r.keys {
+		if key.OrgID == orgID {
+			result = append(result, *key)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// RevokeKey deletes an API key of an organization
+func (r *Registry) RevokeKey(orgID, keyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, key := range r.keys {
+		if key.OrgID == orgID && key.ID == keyID {
+			delete(r.keys, hash)
+			return nil
+		}
+	}
+	return fmt.Errorf("API key %s not found", keyID)
+}
+
+// Authenticate returns the API key a secret belongs to
+func (r *Registry) Authenticate(secret string) (APIKey, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, exists := r.keys[hashKey(secret)]
+	if !exists {
+		return APIKey{}, false
+	}
+	key.LastUsed = time.Now()
+	return *key, true
+}
+
+// Reaches reports whether an organization's keys reach a tenant: whether
+// the tenant is one of its projects
+func (r *Registry) Reaches(orgID, tenantID string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.projects[tenantID] == orgID
+}
+
+// Purge detaches a purged tenant from its organization and returns 1 if it
+// was a project
+func (r *Registry) Purge(tenantID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	orgID, exists := r.projects[tenantID]
+	if !exists {
+		return 0
+	}
+	r.removeProjectLocked(orgID, tenantID)
+	return 1
+}
+
+// GetStats returns registry statistics
+func (r *Registry) GetStats() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return map[string]interface{}{
+		"organizations": len(r.orgs),
+		"projects":      len(r.projects),
+		"api_keys":      len(r.keys),
+	}
+}
@@ -0,0 +1,127 @@
+package orgs
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/budget"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/decay"
+)
+
+func TestOrganizationValidate(t *testing.T) {
+	invalid := []Organization{
+		{},
+		{ID: "acme", Budget: &budget.Budget{}},
+		{ID: "acme", DecayPolicies: []decay.Policy{{Name: "p"}}},
+		{ID: "acme", DecayPolicies: []decay.Policy{{Name: "p", HalfLife: time.Hour}, {Name: "p", HalfLife: time.Hour}}},
+		{ID: "acme", Mounts: []string{"ontology", "ontology"}},
+	}
+	for i, o := range invalid {
+		if err := o.Validate(); err == nil {
+			t.Errorf("Expected organization %d to be rejected", i)
+		}
+	}
+}
+
+func TestProjects(t *testing.T) {
+	r := NewRegistry()
+	if _, err := r.Set(Organization{ID: "acme"}); err != nil {
+		t.Fatalf("Failed to set organization: %v", err)
+	}
+	r.Set(Organization{ID: "globex"})
+
+	if err := r.AddProject("acme", "web"); err != nil {
+		t.Fatalf("Failed to add project: %v", err)
+	}
+	if err := r.AddProject("globex", "web"); err == nil {
+		t.Error("Expected a tenant to belong to one organization only")
+	}
+	if err := r.AddProject("initech", "api"); err == nil {
+		t.Error("Expected an unknown organization rejected")
+	}
+
+	// Replacing the settings keeps the projects
+	o, _ := r.Set(Organization{ID: "acme", Name: "Acme"})
+	if len(o.Projects) != 1 || o.Projects[0] != "web" {
+		t.Errorf("Expected the project kept, got %v", o.Projects)
+	}
+	if owner, ok := r.OrgOf("web"); !ok || owner.ID != "acme" {
+		t.Errorf("Expected web to belong to acme, got %+v", owner)
+	}
+	if !r.Reaches("acme", "web") || r.Reaches("globex", "web") {
+		t.Error("Expected only acme to reach web")
+	}
+	if err := r.Delete("acme"); err == nil {
+		t.Error("Expected an organization with projects kept")
+	}
+
+	if removed := r.Purge("web"); removed != 1 {
+		t.Errorf("Expected the purged tenant detached, got %d", removed)
+	}
+	if _, ok := r.OrgOf("web"); ok {
+		t.Error("Expected web detached")
+	}
+	if err := r.Delete("acme"); err != nil {
+		t.Errorf("Failed to delete organization: %v", err)
+	}
+}
+
+func TestOwn(t *testing.T) {
+	r := NewRegistry()
+	r.Set(Organization{ID: "acme"})
+	r.AddProject("acme", "web")
+	r.SetInherited("web", Inherited{Budget: true, DecayPolicies: []string{"a", "b"}, Mounts: []string{"ontology"}})
+
+	r.Own("web", SettingBudget, "")
+	r.Own("web", SettingDecayPolicy, "a")
+	r.Own("web", SettingMount, "ontology")
+
+	inherited := r.Inherited("web")
+	if inherited.Budget || len(inherited.DecayPolicies) != 1 || inherited.DecayPolicies[0] != "b" || len(inherited.Mounts) != 0 {
+		t.Errorf("Expected only policy b still inherited, got %+v", inherited)
+	}
+
+	// Tenants outside any organization inherit nothing
+	r.SetInherited("api", Inherited{Budget: true})
+	if r.Inherited("api").Budget {
+		t.Error("Expected no inheritance outside an organization")
+	}
+}
+
+func TestAPIKeys(t *testing.T) {
+	r := NewRegistry()
+	r.Set(Organization{ID: "acme"})
+
+	key, secret, err := r.CreateKey("acme", "ci", []string{"deployer"})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	if !strings.HasPrefix(secret, keyPrefix) {
+		t.Errorf("Expected the secret prefixed, got %s", secret)
+	}
+	if _, _, err := r.CreateKey("initech", "ci", nil); err == nil {
+		t.Error("Expected a key of an unknown organization rejected")
+	}
+
+	authenticated, ok := r.Authenticate(secret)
+	if !ok || authenticated.ID != key.ID || authenticated.OrgID != "acme" {
+		t.Fatalf("Expected the secret to authenticate its key, got %+v", authenticated)
+	}
+	if authenticated.LastUsed.IsZero() {
+		t.Error("Expected the key's last use recorded")
+	}
+	if _, ok := r.Authenticate(secret + "0"); ok {
+		t.Error("Expected a wrong secret rejected")
+	}
+
+	if keys := r.ListKeys("acme"); len(keys) != 1 {
+		t.Errorf("Expected one key, got %d", len(keys))
+	}
+	if err := r.RevokeKey("acme", key.ID); err != nil {
+		t.Fatalf("Failed to revoke key: %v", err)
+	}
+	if _, ok := r.Authenticate(secret); ok {
+		t.Error("Expected a revoked key rejected")
+	}
+}
@@ -0,0 +1,76 @@
+package cognitive
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/partition"
+)
+
+// PartitionReport is the state of agent partitioning and where each
+// registered agent runs
+type PartitionReport struct {
+	partition.Status
+	Agents []AgentPlacement `json:"agents"`
+}
+
+// AgentPlacement is the replica an agent runs on
+type AgentPlacement struct {
+	AgentID  string `json:"agent_id"`
+	TenantID string `json:"tenant_id"`
+	Key      string `json:"key"`   // Partition key: the agent ID, or the tenant of agents with a run plan
+	Owner    string `json:"owner"` // Replica running the agent; empty while this replica is inactive
+}
+
+// startPartitioning spreads agents across the live replicas until the
+// engine is closed. The scheduler owns no agent until the first heartbeat
+// succeeds, and its daemons are rebalanced whenever the members change.
+func (ce *CognitiveEngine) startPartitioning(membership partition.Membership, config partition.Config) {
+	ce.partitioner = partition.NewPartitioner(membership, config)
+	ce.partitionDone = make(chan struct{})
+	ce.agentScheduler.SetOwnership(ce.partitioner.Owns)
+	ce.partitioner.OnChange(func() {
+		ce.agentScheduler.SetOwnership(ce.partitioner.Owns)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-ce.done
+		cancel()
+	}()
+	go func() {
+		defer close(ce.partitionDone)
+		ce.partitioner.Run(ctx)
+	}()
+}
+
+// GetPartitionReport returns the live replicas agents are spread across
+// and the replica each agent runs on
+func (ce *CognitiveEngine) GetPartitionReport() (PartitionReport, error) {
+	if ce.partitioner == nil {
+		return PartitionReport{}, partition.ErrNotConfigured
+	}
+
+	report := PartitionReport{Status: ce.partitioner.Status(), Agents: []AgentPlacement{}}
+	for _, agent := range ce.agentScheduler.GetAllAgents() {
+		key := ce.agentScheduler.PartitionKey(agent)
+		report.Agents = append(report.Agents, AgentPlacement{
+			AgentID:  agent.GetID(),
+			TenantID: agent.GetTenantID(),
+			Key:      key,
+			Owner:    ce.partitioner.Owner(key),
+		})
+	}
+	sort.Slice(report.Agents, func(i, j int) bool { return report.Agents[i].AgentID < report.Agents[j].AgentID })
+	return report, nil
+}
+
+// billsAtomsOf reports whether this replica bills the atoms a tenant
+// stores: the replica owning the tenant when agents are partitioned, or
+// else the leader
+func (ce *CognitiveEngine) billsAtomsOf(tenantID string) bool {
+	if ce.partitioner != nil {
+		return ce.partitioner.Owns("tenant:" + tenantID)
+	}
+	return ce.IsLeader()
+}
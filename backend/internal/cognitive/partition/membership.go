@@ -0,0 +1,113 @@
+package partition
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/leader"
+)
+
+// groupLabel names the partition group of a member's lease
+const groupLabel = "erebus.io/partition-group"
+
+// LeaseMembership keeps a Kubernetes Lease per replica, named after the
+// group and the replica and labelled with the group. Members are the
+// holders of the group's leases that have not expired.
+type LeaseMembership struct {
+	lock *leader.LeaseLock
+}
+
+// NewLeaseMembership creates a lease membership of a group. The name of
+// the config is the group; its identity names this replica.
+func NewLeaseMembership(config leader.LeaseConfig) (*LeaseMembership, error) {
+	group := config.Name
+	config.Name = group + "-" + config.Identity
+	config.Labels = map[string]string{groupLabel: group}
+	lock, err := leader.NewLeaseLock(config)
+	if err != nil {
+		return nil, err
+	}
+	return &LeaseMembership{lock: lock}, nil
+}
+
+// Heartbeat renews this replica's lease and lists the live members
+func (m *LeaseMembership) Heartbeat(ctx context.Context) ([]string, error) {
+	held, err := m.lock.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !held {
+		return nil, fmt.Errorf("lease of this replica is held by another with the same identity")
+	}
+	return m.lock.Holders(ctx)
+}
+
+// Leave releases this replica's lease
+func (m *LeaseMembership) Leave(ctx context.Context) error {
+	return m.lock.Release(ctx)
+}
+
+// PostgresMembership records a heartbeat per replica in the
+// erebus_partition_members table, created if missing. Members are the
+// replicas that heartbeated within the TTL, by the database's clock.
+type PostgresMembership struct {
+	db       *sql.DB
+	group    string
+	identity string
+	ttl      time.Duration
+	created  bool
+	mu       sync.Mutex
+}
+
+// NewPostgresMembership creates a membership of a group in a database.
+// Replicas that have not heartbeated for the TTL drop out.
+func NewPostgresMembership(db *sql.DB, group, identity string, ttl time.Duration) *PostgresMembership {
+	return &PostgresMembership{db: db, group: group, identity: identity, ttl: ttl}
+}
+
+// Heartbeat records this replica alive and lists the live members
+func (m *PostgresMembership) Heartbeat(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.created {
+		if _, err := m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS erebus_partition_members (
+			grp text NOT NULL,
+			identity text NOT NULL,
+			heartbeat_at timestamptz NOT NULL,
+			PRIMARY KEY (grp, identity)
+		)`); err != nil {
+			return nil, err
+		}
+		m.created = true
+	}
+	if _, err := m.db.ExecContext(ctx, `INSERT INTO erebus_partition_members (grp, identity, heartbeat_at) VALUES ($1, $2, now())
+		ON CONFLICT (grp, identity) DO UPDATE SET heartbeat_at = now()`, m.group, m.identity); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, `SELECT identity FROM erebus_partition_members
+		WHERE grp = $1 AND heartbeat_at > now() - make_interval(secs => $2) ORDER BY identity`, m.group, m.ttl.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	members := []string{}
+	for rows.Next() {
+		var identity string
+		if err := rows.Scan(&identity); err != nil {
+			return nil, err
+		}
+		members = append(members, identity)
+	}
+	return members, rows.Err()
+}
+
+// Leave removes this replica's heartbeat
+func (m *PostgresMembership) Leave(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `DELETE FROM erebus_partition_members WHERE grp = $1 AND identity = $2`, m.group, m.identity)
+	return err
+}
@@ -0,0 +1,211 @@
+package partition
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNotConfigured is returned when agents are not partitioned across
+// replicas
+var ErrNotConfigured = errors.New("agent partitioning not configured")
+
+// Membership tracks the live replicas sharing the agents
+type Membership interface {
+	// Heartbeat marks this replica alive and returns the live members,
+	// this replica included. Members that stop heartbeating drop out.
+	Heartbeat(ctx context.Context) ([]string, error)
+
+	// Leave removes this replica, so the others take its agents over
+	// without waiting for it to drop out
+	Leave(ctx context.Context) error
+}
+
+// Config configures partitioning
+type Config struct {
+	Identity          string        // Name of this replica; the hostname if empty
+	HeartbeatInterval time.Duration // How often membership is renewed and read; 2s if not positive
+	VirtualNodes      int           // Points per replica on the hash ring; DefaultVirtualNodes if not positive
+}
+
+// DefaultConfig heartbeats every 2 seconds
+func DefaultConfig() Config {
+	return Config{HeartbeatInterval: 2 * time.Second, VirtualNodes: DefaultVirtualNodes}
+}
+
+// Status describes partitioning as seen by this replica
+type Status struct {
+	Identity  string    `json:"identity"`
+	Active    bool      `json:"active"`  // Whether the latest heartbeat succeeded, so this replica runs its agents
+	Members   []string  `json:"members"` // Live replicas, sorted
+	Since     time.Time `json:"since"`   // When membership or activity last changed
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Partitioner heartbeats the membership and assigns keys to the live
+// replicas by consistent hashing. A replica whose heartbeat fails owns no
+// key until it succeeds again, since the others may already have taken its
+// keys over.
+type Partitioner struct {
+	membership Membership
+	config     Config
+	ring       *Ring // Nil while inactive
+	since      time.Time
+	err        error
+	handlers   []func()
+	mu         sync.RWMutex
+}
+
+// NewPartitioner creates a partitioner; it owns no key until Run's first
+// heartbeat succeeds
+func NewPartitioner(membership Membership, config Config) *Partitioner {
+	if config.Identity == "" {
+		config.Identity, _ = os.Hostname()
+	}
+	if config.HeartbeatInterval <= 0 {
+		config.HeartbeatInterval = DefaultConfig().HeartbeatInterval
+	}
+	return &Partitioner{membership: membership, config: config, since: time.Now()}
+}
+
+// Identity returns the name of this replica
+func (p *Partitioner) Identity() string {
+	return p.config.Identity
+}
+
+// HeartbeatInterval returns how often membership is renewed
+func (p *Partitioner) HeartbeatInterval() time.Duration {
+	return p.config.HeartbeatInterval
+}
+
+// OnChange registers a handler called when the keys this replica owns may
+// have changed. Handlers run in order on the partitioner's goroutine.
+func (p *Partitioner) OnChange(handler func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers = append(p.handlers, handler)
+}
+
+// Owns reports whether this replica owns a key
+func (p *Partitioner) Owns(key string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.ring != nil && p.ring.Owner(key) == p.config.Identity
+}
+
+// Owner returns the replica owning a key, or "" while this replica is
+// inactive
+func (p *Partitioner) Owner(key string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.ring == nil {
+		return ""
+	}
+	return p.ring.Owner(key)
+}
+
+// Status returns the state of partitioning
+func (p *Partitioner) Status() Status {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	status := Status{Identity: p.config.Identity, Active: p.ring != nil, Members: []string{}, Since: p.since}
+	if p.ring != nil {
+		status.Members = p.ring.Members()
+	}
+	if p.err != nil {
+		status.LastError = p.err.Error()
+	}
+	return status
+}
+
+// Run heartbeats every interval until ctx is cancelled, then gives up its
+// keys and leaves the membership
+func (p *Partitioner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.config.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		p.heartbeat(ctx)
+		select {
+		case <-ctx.Done():
+			p.set(nil, nil)
+			leaveCtx, cancel := context.WithTimeout(context.Background(), p.config.HeartbeatInterval)
+			p.membership.Leave(leaveCtx)
+			cancel()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Partitioner) heartbeat(ctx context.Context) {
+	heartbeatCtx, cancel := context.WithTimeout(ctx, p.config.HeartbeatInterval)
+	defer cancel()
+	members, err := p.membership.Heartbeat(heartbeatCtx)
+	if err != nil {
+		p.set(nil, err)
+		return
+	}
+	if members == nil {
+		members = []string{}
+	}
+	p.set(members, nil)
+}
+
+// set records the live members, nil while inactive, and calls the
+// handlers when they changed
+func (p *Partitioner) set(members []string, err error) {
+	if members != nil {
+		members = append([]string(nil), members...)
+		if !contains(members, p.config.Identity) {
+			members = append(members, p.config.Identity)
+		}
+		sort.Strings(members)
+	}
+
+	p.mu.Lock()
+	p.err = err
+	var current []string
+	if p.ring != nil {
+		current = p.ring.members
+	}
+	changed := (members == nil) != (p.ring == nil) || !equal(members, current)
+	if changed {
+		p.ring = nil
+		if members != nil {
+			p.ring = NewRing(members, p.config.VirtualNodes)
+		}
+		p.since = time.Now()
+	}
+	handlers := append([]func(){}, p.handlers...)
+	p.mu.Unlock()
+
+	if changed {
+		for _, handler := range handlers {
+			handler()
+		}
+	}
+}
+
+func contains(members []string, member string) bool {
+	for _, m := range members {
+		if m == member {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
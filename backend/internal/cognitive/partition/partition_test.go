@@ -0,0 +1,147 @@
+package partition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRingBalancesAndMovesFewKeys(t *testing.T) {
+	keys := make([]string, 10000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("agent-%d", i)
+	}
+	three := NewRing([]string{"erebusd-0", "erebusd-1", "erebusd-2"}, 0)
+	counts := make(map[string]int)
+	for _, key := range keys {
+		counts[three.Owner(key)]++
+	}
+	for member, count := range counts {
+		if count < 2500 || count > 4200 {
+			t.Errorf("expected about a third of the keys on %s, got %d", member, count)
+		}
+	}
+
+	// A fourth replica only takes keys over; none move between the others
+	four := NewRing([]string{"erebusd-0", "erebusd-1", "erebusd-2", "erebusd-3"}, 0)
+	moved := 0
+	for _, key := range keys {
+		before, after := three.Owner(key), four.Owner(key)
+		if before != after {
+			moved++
+			if after != "erebusd-3" {
+				t.Fatalf("expected %s to move to the new replica, moved to %s", key, after)
+			}
+		}
+	}
+	if moved < 1500 || moved > 3500 {
+		t.Errorf("expected about a quarter of the keys to move, got %d", moved)
+	}
+
+	if NewRing(nil, 0).Owner("agent-1") != "" {
+		t.Error("expected an empty ring to own nothing")
+	}
+}
+
+// fakeMembership lists the replicas that heartbeated and have not left
+type fakeMembership struct {
+	members map[string]bool
+	err     error
+	mu      sync.Mutex
+}
+
+type fakeMember struct {
+	m        *fakeMembership
+	identity string
+}
+
+func (f fakeMember) Heartbeat(ctx context.Context) ([]string, error) {
+	f.m.mu.Lock()
+	defer f.m.mu.Unlock()
+	if f.m.err != nil {
+		return nil, f.m.err
+	}
+	f.m.members[f.identity] = true
+	var members []string
+	for member := range f.m.members {
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+func (f fakeMember) Leave(ctx context.Context) error {
+	f.m.mu.Lock()
+	defer f.m.mu.Unlock()
+	delete(f.m.members, f.identity)
+	return nil
+}
+
+func TestPartitionerRebalances(t *testing.T) {
+	membership := &fakeMembership{members: make(map[string]bool)}
+	start := func(identity string) (*Partitioner, context.CancelFunc, chan struct{}) {
+		p := NewPartitioner(fakeMember{membership, identity}, Config{Identity: identity, HeartbeatInterval: 10 * time.Millisecond})
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			p.Run(ctx)
+			close(done)
+		}()
+		return p, cancel, done
+	}
+	waitFor := func(what string, cond func() bool) {
+		for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+		}
+	}
+
+	a, stopA, doneA := start("a")
+	b, stopB, _ := start("b")
+	defer stopB()
+	waitFor("both members", func() bool {
+		return len(a.Status().Members) == 2 && len(b.Status().Members) == 2
+	})
+
+	// Every key is owned by exactly one replica
+	ownedByA := 0
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("agent-%d", i)
+		if a.Owns(key) == b.Owns(key) {
+			t.Fatalf("expected %s owned by exactly one replica", key)
+		}
+		if a.Owns(key) {
+			ownedByA++
+		}
+	}
+	if ownedByA == 0 || ownedByA == 100 {
+		t.Errorf("expected keys spread across replicas, a owns %d", ownedByA)
+	}
+
+	// A replica that cannot heartbeat owns nothing
+	membership.mu.Lock()
+	membership.err = errors.New("connection refused")
+	membership.mu.Unlock()
+	waitFor("inactive replicas", func() bool { return !a.Status().Active && !b.Owns("agent-1") })
+	membership.mu.Lock()
+	membership.err = nil
+	membership.mu.Unlock()
+
+	// A replica leaving hands its keys over
+	stopA()
+	<-doneA
+	waitFor("b to own every key", func() bool {
+		for i := 0; i < 100; i++ {
+			if !b.Owns(fmt.Sprintf("agent-%d", i)) {
+				return false
+			}
+		}
+		return true
+	})
+	if a.Owns("agent-1") || len(b.Status().Members) != 1 {
+		t.Errorf("expected a to own nothing once stopped, got %+v", b.Status())
+	}
+}
@@ -0,0 +1,68 @@
+package partition
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"strconv"
+)
+
+// DefaultVirtualNodes is the number of points each member has on the ring
+const DefaultVirtualNodes = 128
+
+// Ring assigns keys to members by consistent hashing. Each member owns the
+// arcs ending at its virtual nodes, so adding or removing a member only
+// moves the keys of the arcs it gains or loses, about 1/N of them.
+type Ring struct {
+	members []string
+	points  []uint64          // Sorted virtual node hashes
+	owners  map[uint64]string // Virtual node hash -> member
+}
+
+// NewRing builds a ring of members with the given virtual nodes each,
+// DefaultVirtualNodes if not positive
+func NewRing(members []string, virtualNodes int) *Ring {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+	r := &Ring{
+		members: append([]string(nil), members...),
+		owners:  make(map[uint64]string, len(members)*virtualNodes),
+	}
+	sort.Strings(r.members)
+	for _, member := range r.members {
+		for i := 0; i < virtualNodes; i++ {
+			point := hash(member + "#" + strconv.Itoa(i))
+			if _, taken := r.owners[point]; taken {
+				continue
+			}
+			r.owners[point] = member
+			r.points = append(r.points, point)
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+	return r
+}
+
+// Members returns the members of the ring, sorted
+func (r *Ring) Members() []string {
+	return append([]string(nil), r.members...)
+}
+
+// Owner returns the member owning a key, or "" if the ring is empty
+func (r *Ring) Owner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := hash(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+func hash(s string) uint64 {
+	sum := sha256.Sum256([]byte(s))
+	return binary.BigEndian.Uint64(sum[:8])
+}
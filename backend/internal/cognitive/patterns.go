@@ -0,0 +1,73 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/linktypes"
+)
+
+// EnablePatternMining registers a pattern miner for a tenant, or updates the
+// configuration of the existing one
+func (ce *CognitiveEngine) EnablePatternMining(tenantID string, config agents.PatternMinerConfig) *agents.PatternMinerAgent {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if miner, exists := ce.patternMiners[tenantID]; exists {
+		miner.SetConfig(config)
+		return miner
+	}
+
+	miner := agents.NewPatternMinerAgent(
+		fmt.Sprintf("patterns-%s", tenantID),
+		"PatternMinerAgent",
+		tenantID,
+		&tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID},
+		config,
+	)
+	miner.SetLinkTypes(func() linktypes.Table { return ce.linkTypes.Table(tenantID) })
+	ce.patternMiners[tenantID] = miner
+	ce.agentScheduler.RegisterAgent(miner)
+	return miner
+}
+
+// DisablePatternMining unregisters a tenant's pattern miner. PatternNodes
+// already created are kept.
+func (ce *CognitiveEngine) DisablePatternMining(tenantID string) error {
+	ce.mu.Lock()
+	miner, exists := ce.patternMiners[tenantID]
+	delete(ce.patternMiners, tenantID)
+	ce.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("pattern mining not enabled for tenant %s", tenantID)
+	}
+	ce.agentScheduler.UnregisterAgent(miner.GetID())
+	return nil
+}
+
+// MinePatterns mines a tenant's AtomSpace immediately, enabling pattern
+// mining with the default configuration if needed
+func (ce *CognitiveEngine) MinePatterns(ctx context.Context, tenantID string) ([]agents.Pattern, error) {
+	ce.mu.RLock()
+	miner, exists := ce.patternMiners[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		miner = ce.EnablePatternMining(tenantID, agents.DefaultPatternMinerConfig())
+	}
+	return miner.Mine(ctx)
+}
+
+// GetPatterns returns the patterns found by a tenant's latest mining run
+func (ce *CognitiveEngine) GetPatterns(tenantID string) ([]agents.Pattern, error) {
+	ce.mu.RLock()
+	miner, exists := ce.patternMiners[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("pattern mining not enabled for tenant %s", tenantID)
+	}
+	return miner.GetPatterns(), nil
+}
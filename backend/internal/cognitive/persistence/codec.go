@@ -0,0 +1,308 @@
+package persistence
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the AtomRecord message (see atom.proto)
+const (
+	fieldID          protowire.Number = 1
+	fieldType        protowire.Number = 2
+	fieldName        protowire.Number = 3
+	fieldTenantID    protowire.Number = 4
+	fieldStrength    protowire.Number = 5
+	fieldConfidence  protowire.Number = 6
+	fieldSTI         protowire.Number = 7
+	fieldLTI         protowire.Number = 8
+	fieldVLTI        protowire.Number = 9
+	fieldCreatedAt   protowire.Number = 10
+	fieldUpdatedAt   protowire.Number = 11
+	fieldOutgoing    protowire.Number = 12
+	fieldRefreshedAt protowire.Number = 13
+	fieldDecayedAt   protowire.Number = 14
+	fieldProtected   protowire.Number = 15
+)
+
+// AtomRecord is the persisted form of an atom. Links reference their
+// outgoing set by atom ID so records can be stored and decoded independently.
+type AtomRecord struct {
+	ID             string
+	Type           atomspace.AtomType
+	Name           string
+	TenantID       string
+	TruthValue     atomspace.TruthValue
+	AttentionValue atomspace.AttentionValue
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	RefreshedAt    time.Time
+	DecayedAt      time.Time
+	Protected      bool
+	Outgoing       []string
+}
+
+// IsLink reports whether the record describes a link
+func (r *AtomRecord) IsLink() bool {
+	return atomspace.IsLinkType(r.Type)
+}
+
+// RecordFromAtom converts a live atom into its persisted form
+func RecordFromAtom(atom atomspace.Atom) *AtomRecord {
+	rec := &AtomRecord{
+		ID:             atom.GetID(),
+		Type:           atom.GetType(),
+		Name:           atom.GetName(),
+		TenantID:       atom.GetTenantID(),
+		TruthValue:     atom.GetTruthValue(),
+		AttentionValue: atom.GetAttentionValue(),
+		Protected:      atomspace.IsProtected(atom),
+	}
+
+	switch a := atom.(type) {
+	case *atomspace.Node:
+		rec.CreatedAt = a.CreatedAt
+		rec.UpdatedAt = a.UpdatedAt
+		rec.RefreshedAt, rec.DecayedAt = a.GetRefreshedAt(), a.GetDecayedAt()
+	case *atomspace.Link:
+		rec.CreatedAt = a.CreatedAt
+		rec.UpdatedAt = a.UpdatedAt
+		rec.RefreshedAt, rec.DecayedAt = a.GetRefreshedAt(), a.GetDecayedAt()
+		rec.Outgoing = make([]string, len(a.Outgoing))
+		for i, out := range a.Outgoing {
+			rec.Outgoing[i] = out.GetID()
+		}
+	}
+
+	return rec
+}
+
+// MarshalAtom encodes an atom using the protobuf wire format
+func MarshalAtom(atom atomspace.Atom) []byte {
+	return AppendRecord(nil, RecordFromAtom(atom))
+}
+
+// AppendRecord appends the protobuf encoding of rec to b. Zero-valued fields
+// are omitted, matching proto3 semantics.
+func AppendRecord(b []byte, rec *AtomRecord) []byte {
+	b = appendString(b, fieldID, rec.ID)
+	if rec.Type != 0 {
+		b = protowire.AppendTag(b, fieldType, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(rec.Type))
+	}
+	b = appendString(b, fieldName, rec.Name)
+	b = appendString(b, fieldTenantID, rec.TenantID)
+	b = appendDouble(b, fieldStrength, rec.TruthValue.Strength)
+	b = appendDouble(b, fieldConfidence, rec.TruthValue.Confidence)
+	b = appendSint(b, fieldSTI, int64(rec.AttentionValue.STI))
+	b = appendSint(b, fieldLTI, int64(rec.AttentionValue.LTI))
+	b = appendSint(b, fieldVLTI, int64(rec.AttentionValue.VLTI))
+	b = appendTime(b, fieldCreatedAt, rec.CreatedAt)
+	b = appendTime(b, fieldUpdatedAt, rec.UpdatedAt)
+	b = appendTime(b, fieldRefreshedAt, rec.RefreshedAt)
+	b = appendTime(b, fieldDecayedAt, rec.DecayedAt)
+	if rec.Protected {
+		b = protowire.AppendTag(b, fieldProtected, protowire.VarintType)
+		b = protowire.AppendVarint(b, 1)
+	}
+	for _, id := range rec.Outgoing {
+		b = protowire.AppendTag(b, fieldOutgoing, protowire.BytesType)
+		b = protowire.AppendString(b, id)
+	}
+	return b
+}
+
+// UnmarshalRecord decodes a protobuf-encoded AtomRecord. Unknown fields are
+// skipped so older readers can load records written by newer versions.
+func UnmarshalRecord(b []byte) (*AtomRecord, error) {
+	rec := &AtomRecord{}
+
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, fmt.Errorf("invalid atom record tag: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		switch {
+		case num == fieldID && typ == protowire.BytesType:
+			rec.ID, n = protowire.ConsumeString(b)
+		case num == fieldType && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			rec.Type = atomspace.AtomType(v)
+		case num == fieldName && typ == protowire.BytesType:
+			rec.Name, n = protowire.ConsumeString(b)
+		case num == fieldTenantID && typ == protowire.BytesType:
+			rec.TenantID, n = protowire.ConsumeString(b)
+		case num == fieldStrength && typ == protowire.Fixed64Type:
+			var v uint64
+			v, n = protowire.ConsumeFixed64(b)
+			rec.TruthValue.Strength = math.Float64frombits(v)
+		case num == fieldConfidence && typ == protowire.Fixed64Type:
+			var v uint64
+			v, n = protowire.ConsumeFixed64(b)
+			rec.TruthValue.Confidence = math.Float64frombits(v)
+		case (num == fieldSTI || num == fieldLTI || num == fieldVLTI) && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			value := int16(protowire.DecodeZigZag(v))
+			switch num {
+			case fieldSTI:
+				rec.AttentionValue.STI = value
+			case fieldLTI:
+				rec.AttentionValue.LTI = value
+			default:
+				rec.AttentionValue.VLTI = value
+			}
+		case (num == fieldCreatedAt || num == fieldUpdatedAt || num == fieldRefreshedAt || num == fieldDecayedAt) && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			ts := time.Unix(0, protowire.DecodeZigZag(v))
+			switch num {
+			case fieldCreatedAt:
+				rec.CreatedAt = ts
+			case fieldUpdatedAt:
+				rec.UpdatedAt = ts
+			case fieldRefreshedAt:
+				rec.RefreshedAt = ts
+			default:
+				rec.DecayedAt = ts
+			}
+		case num == fieldProtected && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			rec.Protected = v != 0
+		case num == fieldOutgoing && typ == protowire.BytesType:
+			var id string
+			id, n = protowire.ConsumeString(b)
+			rec.Outgoing = append(rec.Outgoing, id)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+
+		if n < 0 {
+			return nil, fmt.Errorf("invalid atom record field %d: %w", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+	}
+
+	return rec, nil
+}
+
+// BuildAtoms reconstructs live atoms from decoded records, allocated from
+// an arena sized for them. Links are resolved against the other records by
+// ID; a link whose outgoing atoms are missing from the record set is
+// reported as an error.
+func BuildAtoms(records []*AtomRecord) ([]atomspace.Atom, error) {
+	byID := make(map[string]*AtomRecord, len(records))
+	nodes, links, outgoing := 0, 0, 0
+	for _, rec := range records {
+		byID[rec.ID] = rec
+		if rec.IsLink() {
+			links++
+			outgoing += len(rec.Outgoing)
+		} else {
+			nodes++
+		}
+	}
+	arena := atomspace.NewArena(nodes, links, outgoing)
+
+	built := make(map[string]atomspace.Atom, len(records))
+	inProgress := make(map[string]bool)
+
+	var build func(rec *AtomRecord) (atomspace.Atom, error)
+	build = func(rec *AtomRecord) (atomspace.Atom, error) {
+		if atom, ok := built[rec.ID]; ok {
+			return atom, nil
+		}
+		if inProgress[rec.ID] {
+			return nil, fmt.Errorf("cyclic outgoing reference at atom %s", rec.ID)
+		}
+		inProgress[rec.ID] = true
+		defer delete(inProgress, rec.ID)
+
+		var atom atomspace.Atom
+		if rec.IsLink() {
+			outgoing := make([]atomspace.Atom, len(rec.Outgoing))
+			for i, id := range rec.Outgoing {
+				target, ok := byID[id]
+				if !ok {
+					return nil, fmt.Errorf("link %s references unknown atom %s", rec.ID, id)
+				}
+				out, err := build(target)
+				if err != nil {
+					return nil, err
+				}
+				outgoing[i] = out
+			}
+			link := arena.NewLink(rec.ID, rec.Name, rec.TenantID, rec.Type, outgoing)
+			link.TruthVal = rec.TruthValue
+			link.AttentionVal = rec.AttentionValue
+			link.CreatedAt = rec.CreatedAt
+			link.UpdatedAt = rec.UpdatedAt
+			link.RefreshedAt = rec.RefreshedAt
+			link.DecayedAt = rec.DecayedAt
+			link.Protected = rec.Protected
+			atom = link
+		} else {
+			node := arena.NewNode(rec.ID, rec.Name, rec.TenantID, rec.Type)
+			node.TruthVal = rec.TruthValue
+			node.AttentionVal = rec.AttentionValue
+			node.CreatedAt = rec.CreatedAt
+			node.UpdatedAt = rec.UpdatedAt
+			node.RefreshedAt = rec.RefreshedAt
+			node.DecayedAt = rec.DecayedAt
+			node.Protected = rec.Protected
+			atom = node
+		}
+
+		built[rec.ID] = atom
+		return atom, nil
+	}
+
+	atoms := make([]atomspace.Atom, 0, len(records))
+	for _, rec := range records {
+		atom, err := build(rec)
+		if err != nil {
+			return nil, err
+		}
+		atoms = append(atoms, atom)
+	}
+
+	return atoms, nil
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendDouble(b []byte, num protowire.Number, v float64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(v))
+}
+
+func appendSint(b []byte, num protowire.Number, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, protowire.EncodeZigZag(v))
+}
+
+func appendTime(b []byte, num protowire.Number, t time.Time) []byte {
+	if t.IsZero() {
+		return b
+	}
+	return appendSint(b, num, t.UnixNano())
+}
@@ -0,0 +1,174 @@
+package persistence
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func sampleAtoms(n int) []atomspace.Atom {
+	atoms := make([]atomspace.Atom, 0, n*2)
+	var prev atomspace.Atom
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("payments-service-pod-%d", i)
+		node := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, "tenant-a", atomspace.ConceptNodeType)
+		node.SetAttentionValue(atomspace.AttentionValue{STI: int16(i % 100), LTI: -3})
+		atoms = append(atoms, node)
+
+		if prev != nil {
+			outgoing := []atomspace.Atom{node, prev}
+			id := atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", outgoing)
+			link := atomspace.NewLink(id, "inheritance", "tenant-a", atomspace.InheritanceLinkType, outgoing)
+			link.SetTruthValue(atomspace.TruthValue{Strength: 0.8, Confidence: 0.72})
+			atoms = append(atoms, link)
+		}
+		prev = node
+	}
+	return atoms
+}
+
+func TestRecordRoundTrip(t *testing.T) {
+	atoms := sampleAtoms(2)
+	link := atoms[2]
+
+	rec, err := UnmarshalRecord(MarshalAtom(link))
+	if err != nil {
+		t.Fatalf("Failed to unmarshal record: %v", err)
+	}
+
+	if rec.ID != link.GetID() || rec.Type != link.GetType() || rec.TenantID != "tenant-a" {
+		t.Errorf("Record identity mismatch: %+v", rec)
+	}
+	if rec.TruthValue != link.GetTruthValue() {
+		t.Errorf("Expected truth value %+v, got %+v", link.GetTruthValue(), rec.TruthValue)
+	}
+	if len(rec.Outgoing) != 2 || rec.Outgoing[0] != atoms[1].GetID() {
+		t.Errorf("Outgoing set not preserved: %v", rec.Outgoing)
+	}
+
+	// Decay keeps when the truth value was refreshed
+	decayedAt := time.Now()
+	link.(*atomspace.Link).DecayTruthValue(atomspace.TruthValue{Strength: 0.8, Confidence: 0.36}, decayedAt)
+	rec, err = UnmarshalRecord(MarshalAtom(link))
+	if err != nil {
+		t.Fatalf("Failed to unmarshal record: %v", err)
+	}
+	built, err := BuildAtoms([]*AtomRecord{rec, mustRecord(t, atoms[0]), mustRecord(t, atoms[1])})
+	if err != nil {
+		t.Fatalf("Failed to build atoms: %v", err)
+	}
+	restored := built[0].(*atomspace.Link)
+	if !restored.GetRefreshedAt().Equal(link.(*atomspace.Link).GetRefreshedAt()) || !restored.GetDecayedAt().Equal(decayedAt) {
+		t.Errorf("Expected refresh and decay times preserved, got %v and %v", restored.GetRefreshedAt(), restored.GetDecayedAt())
+	}
+	if restored.IsProtected() {
+		t.Error("Expected an unprotected atom to be restored unprotected")
+	}
+
+	atoms[0].(*atomspace.Node).SetProtected(true)
+	built, err = BuildAtoms([]*AtomRecord{mustRecord(t, atoms[0])})
+	if err != nil {
+		t.Fatalf("Failed to build atoms: %v", err)
+	}
+	if !atomspace.IsProtected(built[0]) {
+		t.Error("Expected protection preserved")
+	}
+}
+
+func TestNumberNodesRoundTrip(t *testing.T) {
+	number := atomspace.NewNumberNode(0.9, "tenant-a")
+	gpn := atomspace.NewGroundedPredicateNode("greater_than", "tenant-a")
+	outgoing := []atomspace.Atom{gpn, number, number}
+	link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, "greater_than", outgoing), "greater_than", "tenant-a", atomspace.EvaluationLinkType, outgoing)
+
+	// Node types numbered after the link types are still nodes
+	rec := mustRecord(t, number)
+	if rec.IsLink() || !mustRecord(t, link).IsLink() {
+		t.Fatal("Expected number nodes recorded as nodes and evaluations as links")
+	}
+	built, err := BuildAtoms([]*AtomRecord{mustRecord(t, link), rec, mustRecord(t, gpn)})
+	if err != nil {
+		t.Fatalf("Failed to build atoms: %v", err)
+	}
+	if value, ok := atomspace.NumberValue(built[1]); !ok || value != 0.9 {
+		t.Errorf("Expected the number 0.9 restored, got %v", built[1])
+	}
+	if restored := built[0].(*atomspace.Link); restored.Outgoing[0].GetType() != atomspace.GroundedPredicateNodeType {
+		t.Errorf("Expected the grounded predicate restored, got %v", restored.Outgoing[0])
+	}
+}
+
+func mustRecord(t *testing.T, atom atomspace.Atom) *AtomRecord {
+	rec, err := UnmarshalRecord(MarshalAtom(atom))
+	if err != nil {
+		t.Fatalf("Failed to unmarshal record: %v", err)
+	}
+	return rec
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	atoms := sampleAtoms(500)
+
+	dict, err := TrainDictionary(atoms[:200], 0)
+	if err != nil {
+		t.Fatalf("Failed to train dictionary: %v", err)
+	}
+
+	for name, codec := range map[string]*Codec{
+		"plain":      NewCodec(),
+		"dictionary": NewCodec(WithDictionary(dict)),
+	} {
+		var buf bytes.Buffer
+		if err := codec.WriteSnapshot(&buf, atoms); err != nil {
+			t.Fatalf("%s: failed to write snapshot: %v", name, err)
+		}
+		size := buf.Len()
+
+		restored, err := codec.LoadSnapshot(&buf)
+		if err != nil {
+			t.Fatalf("%s: failed to load snapshot: %v", name, err)
+		}
+		if len(restored) != len(atoms) {
+			t.Fatalf("%s: expected %d atoms, got %d", name, len(atoms), len(restored))
+		}
+
+		link, ok := restored[2].(*atomspace.Link)
+		if !ok {
+			t.Fatalf("%s: expected restored link, got %T", name, restored[2])
+		}
+		if link.Outgoing[1].GetID() != atoms[0].GetID() {
+			t.Errorf("%s: link outgoing not resolved", name)
+		}
+
+		jsonSize := 0
+		for _, atom := range atoms {
+			b, _ := json.Marshal(RecordFromAtom(atom))
+			jsonSize += len(b)
+		}
+		t.Logf("%s: %d bytes (JSON: %d bytes)", name, size, jsonSize)
+		if size*5 > jsonSize {
+			t.Errorf("%s: expected snapshot to be much smaller than JSON (%d vs %d)", name, size, jsonSize)
+		}
+	}
+}
+
+func TestSnapshotRequiresDictionary(t *testing.T) {
+	atoms := sampleAtoms(50)
+	dict, err := TrainDictionary(atoms, 0)
+	if err != nil {
+		t.Fatalf("Failed to train dictionary: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewCodec(WithDictionary(dict)).WriteSnapshot(&buf, atoms); err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+
+	if _, err := NewCodec().ReadSnapshot(&buf); err == nil {
+		t.Error("Expected error reading dictionary snapshot without dictionary")
+	}
+}
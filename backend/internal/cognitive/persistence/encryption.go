@@ -0,0 +1,346 @@
+package persistence
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// envelopeMagic identifies an encrypted Erebus artifact
+var envelopeMagic = []byte("EREBENC1")
+
+const (
+	// chunkSize is the plaintext size of each sealed chunk of an envelope
+	chunkSize = 64 << 10
+
+	// maxHeaderField bounds the fields of an envelope header
+	maxHeaderField = 4 << 10
+)
+
+// ErrKeyNotFound is returned for data keys whose tenant key was destroyed
+// or never existed
+var ErrKeyNotFound = errors.New("tenant key not found")
+
+// KeyProvider wraps the data keys of encrypted artifacts with per-tenant
+// keys, typically held by a KMS. Destroying a tenant's key makes every
+// artifact encrypted for the tenant unreadable.
+type KeyProvider interface {
+	// WrapKey encrypts a data key for a tenant and returns the ID of the
+	// tenant key used
+	WrapKey(ctx context.Context, tenantID string, dataKey []byte) (keyID string, wrapped []byte, err error)
+	// UnwrapKey decrypts a data key wrapped by WrapKey
+	UnwrapKey(ctx context.Context, tenantID, keyID string, wrapped []byte) ([]byte, error)
+	// DestroyKey irrevocably deletes a tenant's keys
+	DestroyKey(ctx context.Context, tenantID string) error
+}
+
+// dataKey is the data key of a tenant in plaintext and wrapped form
+type dataKey struct {
+	plaintext []byte
+	keyID     string
+	wrapped   []byte
+}
+
+// Encryptor seals persisted artifacts in envelopes: each tenant has a data
+// key wrapped by the key provider, the wrapped key travels in the header of
+// every artifact, and the content is sealed with AES-GCM under a key derived
+// from the data key for that artifact alone.
+type Encryptor struct {
+	provider KeyProvider
+	keys     map[string]dataKey // tenantID -> data key
+	mu       sync.Mutex
+
+	encrypted int64
+	decrypted int64
+	shredded  int64
+}
+
+// NewEncryptor creates an encryptor wrapping data keys with provider
+func NewEncryptor(provider KeyProvider) *Encryptor {
+	return &Encryptor{provider: provider, keys: make(map[string]dataKey)}
+}
+
+// IsEncrypted reports whether a stream starts with an envelope, without
+// consuming it
+func IsEncrypted(r *bufio.Reader) bool {
+	magic, err := r.Peek(len(envelopeMagic))
+	return err == nil && bytes.Equal(magic, envelopeMagic)
+}
+
+// Writer returns a writer sealing everything written to it into w for a
+// tenant. The envelope is complete only once the writer is closed.
+func (e *Encryptor) Writer(ctx context.Context, tenantID string, w io.Writer) (io.WriteCloser, error) {
+	key, err := e.dataKey(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	header := append([]byte{}, envelopeMagic...)
+	header = protowire.AppendBytes(header, []byte(tenantID))
+	header = protowire.AppendBytes(header, []byte(key.keyID))
+	header = protowire.AppendBytes(header, key.wrapped)
+	header = append(header, salt...)
+	aead, err := streamCipher(key.plaintext, salt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write envelope header: %w", err)
+	}
+
+	atomic.AddInt64(&e.encrypted, 1)
+	return &sealer{w: w, aead: aead, ad: sha256.Sum256(header)}, nil
+}
+
+// Reader opens an envelope, returning the decrypted content and the tenant
+// it was sealed for. Content is authenticated chunk by chunk; a modified or
+// truncated envelope fails when the damaged chunk is read.
+func (e *Encryptor) Reader(ctx context.Context, r io.Reader) (io.Reader, string, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(envelopeMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || !bytes.Equal(magic, envelopeMagic) {
+		return nil, "", errors.New("not an erebus envelope")
+	}
+	header := append([]byte{}, magic...)
+	var fields [3][]byte
+	for i := range fields {
+		field, err := readField(br)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid envelope header: %w", err)
+		}
+		fields[i] = field
+		header = protowire.AppendBytes(header, field)
+	}
+	salt := make([]byte, 32)
+	if _, err := io.ReadFull(br, salt); err != nil {
+		return nil, "", fmt.Errorf("invalid envelope header: %w", err)
+	}
+	header = append(header, salt...)
+
+	tenantID := string(fields[0])
+	plaintext, err := e.provider.UnwrapKey(ctx, tenantID, string(fields[1]), fields[2])
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to unwrap data key of tenant %s: %w", tenantID, err)
+	}
+	aead, err := streamCipher(plaintext, salt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	atomic.AddInt64(&e.decrypted, 1)
+	return &opener{r: br, aead: aead, ad: sha256.Sum256(header)}, tenantID, nil
+}
+
+// Shred destroys a tenant's keys, so that the artifacts encrypted for the
+// tenant, wherever they are stored, can no longer be decrypted
+func (e *Encryptor) Shred(ctx context.Context, tenantID string) error {
+	if err := e.provider.DestroyKey(ctx, tenantID); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	delete(e.keys, tenantID)
+	e.mu.Unlock()
+	atomic.AddInt64(&e.shredded, 1)
+	return nil
+}
+
+// GetStats returns encryption statistics
+func (e *Encryptor) GetStats() map[string]interface{} {
+	e.mu.Lock()
+	tenants := len(e.keys)
+	e.mu.Unlock()
+	return map[string]interface{}{
+		"tenants":   tenants,
+		"encrypted": atomic.LoadInt64(&e.encrypted),
+		"decrypted": atomic.LoadInt64(&e.decrypted),
+		"shredded":  atomic.LoadInt64(&e.shredded),
+	}
+}
+
+// dataKey returns the data key of a tenant, generating and wrapping it
+// on first use
+func (e *Encryptor) dataKey(ctx context.Context, tenantID string) (dataKey, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if key, exists := e.keys[tenantID]; exists {
+		return key, nil
+	}
+	plaintext := make([]byte, 32)
+	if _, err := rand.Read(plaintext); err != nil {
+		return dataKey{}, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	keyID, wrapped, err := e.provider.WrapKey(ctx, tenantID, plaintext)
+	if err != nil {
+		return dataKey{}, fmt.Errorf("failed to wrap data key of tenant %s: %w", tenantID, err)
+	}
+	key := dataKey{plaintext: plaintext, keyID: keyID, wrapped: wrapped}
+	e.keys[tenantID] = key
+	return key, nil
+}
+
+// streamCipher returns the AES-256-GCM cipher of one envelope, keyed by
+// HMAC-SHA256 of its salt under the data key so that nonces, counting the
+// chunks, never repeat under a key
+func streamCipher(dataKey, salt []byte) (cipher.AEAD, error) {
+	mac := hmac.New(sha256.New, dataKey)
+	mac.Write(salt)
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns the nonce of a chunk
+func chunkNonce(aead cipher.AEAD, counter uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], counter)
+	return nonce
+}
+
+// chunkAD binds a chunk to its envelope header and marks the last chunk, so
+// that chunks cannot be moved between envelopes and truncation is detected
+func chunkAD(header [32]byte, final bool) []byte {
+	ad := append([]byte{}, header[:]...)
+	if final {
+		return append(ad, 1)
+	}
+	return append(ad, 0)
+}
+
+// sealer writes length-delimited sealed chunks
+type sealer struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	ad      [32]byte
+	buf     []byte
+	counter uint64
+	closed  bool
+}
+
+func (s *sealer) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errors.New("write to closed envelope")
+	}
+	n := len(p)
+	for len(p) > 0 {
+		take := chunkSize - len(s.buf)
+		if take > len(p) {
+			take = len(p)
+		}
+		s.buf = append(s.buf, p[:take]...)
+		p = p[take:]
+		if len(s.buf) == chunkSize {
+			if err := s.flush(false); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close seals the final chunk
+func (s *sealer) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.flush(true)
+}
+
+func (s *sealer) flush(final bool) error {
+	sealed := s.aead.Seal(nil, chunkNonce(s.aead, s.counter), s.buf, chunkAD(s.ad, final))
+	s.counter++
+	s.buf = s.buf[:0]
+	if _, err := s.w.Write(protowire.AppendBytes(nil, sealed)); err != nil {
+		return fmt.Errorf("failed to write envelope chunk: %w", err)
+	}
+	return nil
+}
+
+// opener reads and authenticates sealed chunks
+type opener struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	ad      [32]byte
+	buf     []byte
+	counter uint64
+	final   bool
+}
+
+func (o *opener) Read(p []byte) (int, error) {
+	for len(o.buf) == 0 {
+		if o.final {
+			return 0, io.EOF
+		}
+		sealed, err := readChunk(o.r)
+		if err == io.EOF {
+			return 0, errors.New("truncated envelope")
+		}
+		if err != nil {
+			return 0, err
+		}
+		// Only the last chunk is sealed as final; try it if the chunk is short
+		plaintext, err := o.aead.Open(nil, chunkNonce(o.aead, o.counter), sealed, chunkAD(o.ad, false))
+		if err != nil {
+			plaintext, err = o.aead.Open(nil, chunkNonce(o.aead, o.counter), sealed, chunkAD(o.ad, true))
+			if err != nil {
+				return 0, fmt.Errorf("envelope chunk %d failed authentication", o.counter)
+			}
+			o.final = true
+		}
+		o.counter++
+		o.buf = plaintext
+	}
+	n := copy(p, o.buf)
+	o.buf = o.buf[n:]
+	return n, nil
+}
+
+// readField reads a length-delimited header field
+func readField(r *bufio.Reader) ([]byte, error) {
+	size, err := readUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxHeaderField {
+		return nil, fmt.Errorf("header field of %d bytes", size)
+	}
+	field := make([]byte, size)
+	_, err = io.ReadFull(r, field)
+	return field, err
+}
+
+// readChunk reads a length-delimited sealed chunk
+func readChunk(r *bufio.Reader) ([]byte, error) {
+	size, err := readUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > chunkSize+64 {
+		return nil, fmt.Errorf("envelope chunk of %d bytes", size)
+	}
+	chunk := make([]byte, size)
+	if _, err := io.ReadFull(r, chunk); err != nil {
+		return nil, errors.New("truncated envelope")
+	}
+	return chunk, nil
+}
@@ -0,0 +1,131 @@
+package persistence
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+)
+
+func encrypt(t *testing.T, e *Encryptor, tenantID string, plaintext []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := e.Writer(context.Background(), tenantID, &buf)
+	if err != nil {
+		t.Fatalf("Failed to create envelope: %v", err)
+	}
+	w.Write(plaintext)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close envelope: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func decrypt(e *Encryptor, sealed []byte) ([]byte, string, error) {
+	r, tenantID, err := e.Reader(context.Background(), bytes.NewReader(sealed))
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext, err := io.ReadAll(r)
+	return plaintext, tenantID, err
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	provider, _ := NewLocalKeyProvider(bytes.Repeat([]byte{7}, 32), "")
+	e := NewEncryptor(provider)
+
+	plaintext := make([]byte, 3*chunkSize+100)
+	rand.Read(plaintext)
+	sealed := encrypt(t, e, "tenant-a", plaintext)
+	if !IsEncrypted(bufio.NewReader(bytes.NewReader(sealed))) {
+		t.Fatal("Expected an envelope")
+	}
+	if bytes.Contains(sealed, plaintext[:64]) {
+		t.Fatal("Expected the content to be encrypted")
+	}
+
+	got, tenantID, err := decrypt(e, sealed)
+	if err != nil || tenantID != "tenant-a" || !bytes.Equal(got, plaintext) {
+		t.Fatalf("Expected the content back for tenant-a, got %d bytes for %s: %v", len(got), tenantID, err)
+	}
+
+	// Two envelopes of the same content differ
+	if bytes.Equal(encrypt(t, e, "tenant-a", plaintext), sealed) {
+		t.Error("Expected each envelope to use its own key")
+	}
+	if empty, _, err := decrypt(e, encrypt(t, e, "tenant-a", nil)); err != nil || len(empty) != 0 {
+		t.Errorf("Expected an empty envelope to open, got %d bytes: %v", len(empty), err)
+	}
+}
+
+func TestEnvelopeTampering(t *testing.T) {
+	provider, _ := NewLocalKeyProvider(bytes.Repeat([]byte{7}, 32), "")
+	e := NewEncryptor(provider)
+	plaintext := bytes.Repeat([]byte("atom"), chunkSize)
+	sealed := encrypt(t, e, "tenant-a", plaintext)
+
+	flipped := append([]byte{}, sealed...)
+	flipped[len(flipped)/2] ^= 1
+	if _, _, err := decrypt(e, flipped); err == nil {
+		t.Error("Expected a modified envelope to fail")
+	}
+	if _, _, err := decrypt(e, sealed[:len(sealed)-chunkSize/2]); err == nil {
+		t.Error("Expected a truncated envelope to fail")
+	}
+
+	// The content fills 4 chunks, so the final chunk is empty: its length
+	// and tag. Without it, the envelope ends on a valid but non-final chunk.
+	if _, _, err := decrypt(e, sealed[:len(sealed)-17]); err == nil || err.Error() != "truncated envelope" {
+		t.Error("Expected an envelope without its final chunk to fail")
+	}
+
+	// The header binds the envelope to its tenant
+	renamed := bytes.Replace(sealed, []byte("tenant-a"), []byte("tenant-b"), 1)
+	if _, _, err := decrypt(e, renamed); err == nil {
+		t.Error("Expected an envelope moved to another tenant to fail")
+	}
+}
+
+func TestCryptoShredding(t *testing.T) {
+	master := bytes.Repeat([]byte{9}, 32)
+	dir := t.TempDir()
+	provider, err := NewLocalKeyProvider(master, dir)
+	if err != nil {
+		t.Fatalf("Failed to create key provider: %v", err)
+	}
+	e := NewEncryptor(provider)
+	a := encrypt(t, e, "tenant-a", []byte("payments"))
+	b := encrypt(t, e, "tenant-b", []byte("checkout"))
+
+	// Keys survive a restart through the key directory
+	restarted, _ := NewLocalKeyProvider(master, dir)
+	if got, _, err := decrypt(NewEncryptor(restarted), a); err != nil || string(got) != "payments" {
+		t.Fatalf("Expected the envelope to open after a restart, got %q: %v", got, err)
+	}
+	other, _ := NewLocalKeyProvider(bytes.Repeat([]byte{1}, 32), dir)
+	if _, _, err := decrypt(NewEncryptor(other), a); err == nil {
+		t.Error("Expected tenant keys to require the master key")
+	}
+
+	if err := e.Shred(context.Background(), "tenant-a"); err != nil {
+		t.Fatalf("Shred failed: %v", err)
+	}
+	if _, _, err := decrypt(e, a); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected the shredded tenant's envelope to be unreadable, got %v", err)
+	}
+	restarted, _ = NewLocalKeyProvider(master, dir)
+	if _, _, err := decrypt(NewEncryptor(restarted), a); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected the shredded key to be gone from disk, got %v", err)
+	}
+	if got, _, err := decrypt(e, b); err != nil || string(got) != "checkout" {
+		t.Errorf("Expected other tenants to be unaffected, got %q: %v", got, err)
+	}
+
+	// New envelopes of the tenant use a new key
+	if got, _, err := decrypt(e, encrypt(t, e, "tenant-a", []byte("new"))); err != nil || string(got) != "new" {
+		t.Errorf("Expected a new key after shredding, got %q: %v", got, err)
+	}
+}
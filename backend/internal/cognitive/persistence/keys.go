@@ -0,0 +1,166 @@
+package persistence
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// LocalKeyProvider wraps data keys with random per-tenant keys, kept in
+// memory and, if a directory is given, in files sealed with a master key.
+// It stands in for a KMS in development and single-node deployments.
+type LocalKeyProvider struct {
+	master cipher.AEAD
+	dir    string
+	keys   map[string][]byte // tenantID -> tenant key
+	mu     sync.Mutex
+}
+
+// NewLocalKeyProvider creates a key provider sealing tenant keys with a
+// 32-byte master key. An empty dir keeps tenant keys in memory only.
+func NewLocalKeyProvider(master []byte, dir string) (*LocalKeyProvider, error) {
+	if len(master) != 32 {
+		return nil, errors.New("master key must be 32 bytes")
+	}
+	aead, err := newGCM(master)
+	if err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create key directory: %w", err)
+		}
+	}
+	return &LocalKeyProvider{master: aead, dir: dir, keys: make(map[string][]byte)}, nil
+}
+
+// WrapKey seals a data key with the tenant's key, creating it if needed
+func (p *LocalKeyProvider) WrapKey(ctx context.Context, tenantID string, dataKey []byte) (string, []byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, err := p.load(tenantID)
+	if errors.Is(err, ErrKeyNotFound) {
+		key, err = p.create(tenantID)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", nil, err
+	}
+	return keyID(key), seal(aead, dataKey, []byte(tenantID)), nil
+}
+
+// UnwrapKey opens a data key sealed by WrapKey
+func (p *LocalKeyProvider) UnwrapKey(ctx context.Context, tenantID, id string, wrapped []byte) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, err := p.load(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if keyID(key) != id {
+		return nil, ErrKeyNotFound
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return open(aead, wrapped, []byte(tenantID))
+}
+
+// DestroyKey deletes a tenant's key from memory and disk
+func (p *LocalKeyProvider) DestroyKey(ctx context.Context, tenantID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.keys, tenantID)
+	if p.dir == "" {
+		return nil
+	}
+	if err := os.Remove(p.path(tenantID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete key of tenant %s: %w", tenantID, err)
+	}
+	return nil
+}
+
+// load returns a tenant's key from memory or disk
+func (p *LocalKeyProvider) load(tenantID string) ([]byte, error) {
+	if key, exists := p.keys[tenantID]; exists {
+		return key, nil
+	}
+	if p.dir == "" {
+		return nil, ErrKeyNotFound
+	}
+	sealed, err := os.ReadFile(p.path(tenantID))
+	if os.IsNotExist(err) {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key of tenant %s: %w", tenantID, err)
+	}
+	key, err := open(p.master, sealed, []byte(tenantID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open key of tenant %s: %w", tenantID, err)
+	}
+	p.keys[tenantID] = key
+	return key, nil
+}
+
+// create generates and stores a tenant key
+func (p *LocalKeyProvider) create(tenantID string) ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate tenant key: %w", err)
+	}
+	if p.dir != "" {
+		if err := os.WriteFile(p.path(tenantID), seal(p.master, key, []byte(tenantID)), 0o600); err != nil {
+			return nil, fmt.Errorf("failed to write key of tenant %s: %w", tenantID, err)
+		}
+	}
+	p.keys[tenantID] = key
+	return key, nil
+}
+
+func (p *LocalKeyProvider) path(tenantID string) string {
+	return filepath.Join(p.dir, hex.EncodeToString([]byte(tenantID))+".key")
+}
+
+// keyID identifies a key without revealing it
+func keyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext with a random nonce prepended
+func seal(aead cipher.AEAD, plaintext, ad []byte) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	rand.Read(nonce)
+	return aead.Seal(nonce, nonce, plaintext, ad)
+}
+
+func open(aead cipher.AEAD, sealed, ad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("sealed key too short")
+	}
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], ad)
+}
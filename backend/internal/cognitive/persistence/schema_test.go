@@ -0,0 +1,146 @@
+package persistence
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func TestMigrationsMatchSchemaVersion(t *testing.T) {
+	if len(migrations) != SchemaVersion-1 {
+		t.Fatalf("Expected a migration to each version after the first, got %d for version %d", len(migrations), SchemaVersion)
+	}
+	for i, m := range migrations {
+		if m.Version != i+2 || m.Description == "" {
+			t.Errorf("Migration %d is out of order or undescribed: %+v", i, m)
+		}
+	}
+}
+
+func TestMigrateRecord(t *testing.T) {
+	updated := time.Unix(1700000000, 0)
+	rec := &AtomRecord{
+		ID:          "disk",
+		UpdatedAt:   updated,
+		RefreshedAt: updated.Add(-time.Hour),
+		DecayedAt:   updated,
+		Protected:   true,
+	}
+
+	lost, err := MigrateRecord(rec, SchemaVersion, 2)
+	if err != nil || !lost || rec.Protected || rec.DecayedAt.IsZero() {
+		t.Fatalf("Expected only the protection dropped, got %+v (%v, %v)", rec, lost, err)
+	}
+	if lost, _ := MigrateRecord(rec, 2, 1); !lost || !rec.RefreshedAt.IsZero() || !rec.DecayedAt.IsZero() {
+		t.Fatalf("Expected the decay state dropped, got %+v", rec)
+	}
+
+	// Upgraded records of version 1 were refreshed when last updated
+	if lost, _ := MigrateRecord(rec, 1, SchemaVersion); lost || !rec.RefreshedAt.Equal(updated) {
+		t.Errorf("Expected the refresh time filled in, got %+v", rec)
+	}
+	if lost, _ := MigrateRecord(&AtomRecord{ID: "fresh"}, SchemaVersion, 1); lost {
+		t.Error("Expected a record without later fields to lose nothing")
+	}
+	if _, err := MigrateRecord(rec, SchemaVersion+1, 1); err == nil {
+		t.Error("Expected an unknown version rejected")
+	}
+}
+
+func TestSnapshotSchemaVersions(t *testing.T) {
+	atoms := sampleAtoms(3)
+	atoms[0].(*atomspace.Node).SetProtected(true)
+
+	var buf bytes.Buffer
+	if err := NewCodec(WithSchemaVersion(1)).WriteSnapshot(&buf, atoms); err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+	records, version, err := NewCodec().ReadSnapshotVersion(bytes.NewReader(buf.Bytes()))
+	if err != nil || version != 1 || len(records) != len(atoms) {
+		t.Fatalf("Expected %d records of version 1, got %d of %d (%v)", len(atoms), len(records), version, err)
+	}
+	if records[0].Protected || !records[0].RefreshedAt.Equal(records[0].UpdatedAt) {
+		t.Errorf("Expected the record as version 1 holds it, upgraded, got %+v", records[0])
+	}
+
+	buf.Reset()
+	NewCodec().WriteSnapshot(&buf, atoms)
+	records, version, _ = NewCodec().ReadSnapshotVersion(bytes.NewReader(buf.Bytes()))
+	if version != SchemaVersion || !records[0].Protected {
+		t.Errorf("Expected the current version to keep the protection, got version %d: %+v", version, records[0])
+	}
+
+	// Snapshots of later versions are refused rather than misread
+	data := buf.Bytes()
+	data[len(snapshotMagic)] = SchemaVersion + 1
+	if _, err := NewCodec().ReadSnapshot(bytes.NewReader(data)); err == nil {
+		t.Error("Expected a snapshot of a later version refused")
+	}
+}
+
+func TestMigrateSnapshotFile(t *testing.T) {
+	db := atomspace.NewNode("db", "db", "tenant-a", atomspace.ConceptNodeType)
+	db.SetProtected(true)
+	atoms := []atomspace.Atom{db, atomspace.NewNode("cache", "cache", "tenant-a", atomspace.ConceptNodeType)}
+	path := filepath.Join(t.TempDir(), "tenant-a.snap")
+	var buf bytes.Buffer
+	NewCodec().WriteSnapshot(&buf, atoms)
+	os.WriteFile(path, buf.Bytes(), 0o600)
+
+	codec := NewCodec()
+	report, err := codec.MigrateSnapshotFile(path, 1, true)
+	if err != nil || report.From != SchemaVersion || report.Records != len(atoms) || report.Lossy != 1 || report.Rewritten {
+		t.Fatalf("Expected a dry run to report the lost protection, got %+v (%v)", report, err)
+	}
+	if report, err := codec.MigrateSnapshotFile(path, 1, false); err != nil || !report.Rewritten {
+		t.Fatalf("Expected the snapshot rewritten, got %+v (%v)", report, err)
+	}
+
+	f, _ := os.Open(path)
+	_, version, err := codec.ReadSnapshotVersion(f)
+	f.Close()
+	if err != nil || version != 1 {
+		t.Errorf("Expected the snapshot in version 1, got %d (%v)", version, err)
+	}
+	if report, _ := codec.MigrateSnapshotFile(path, SchemaVersion, false); report.From != 1 || report.Lossy != 0 {
+		t.Errorf("Expected the snapshot upgraded again, got %+v", report)
+	}
+}
+
+func TestValueLogSchemaVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shard-0.vlog")
+	log, err := OpenValueLog(path)
+	if err != nil {
+		t.Fatalf("Failed to open value log: %v", err)
+	}
+	db := atomspace.NewNode("db", "db", "acme", atomspace.ConceptNodeType)
+	db.DecayTruthValue(atomspace.TruthValue{Strength: 1, Confidence: 0.5}, time.Now())
+	log.Observe([]atomspace.Atom{db}, time.Now())
+	log.Close()
+
+	report, err := MigrateValueLog(path, 1, false)
+	if err != nil || report.From != SchemaVersion || report.Records != 1 || report.Lossy != 1 || !report.Rewritten {
+		t.Fatalf("Expected the log downgraded, losing its decay state, got %+v (%v)", report, err)
+	}
+	if data, _ := os.ReadFile(path); data[len(valueLogMagic)] != 1 {
+		t.Fatalf("Expected the log in version 1, got %d", data[len(valueLogMagic)])
+	}
+
+	// Opening a log of an older version upgrades it
+	log, err = OpenValueLog(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen value log: %v", err)
+	}
+	defer log.Close()
+	values := log.Values()
+	if len(values) != 1 || values[0].TruthValue.Confidence != 0.5 || !values[0].DecayedAt.IsZero() {
+		t.Errorf("Expected the values of version 1, got %+v", values)
+	}
+	if data, _ := os.ReadFile(path); data[len(valueLogMagic)] != SchemaVersion {
+		t.Errorf("Expected the log rewritten in version %d, got %d", SchemaVersion, data[len(valueLogMagic)])
+	}
+}
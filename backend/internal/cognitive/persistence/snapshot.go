@@ -0,0 +1,243 @@
+package persistence
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/klauspost/compress/dict"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// snapshotMagic identifies an Erebus atom snapshot stream
+var snapshotMagic = []byte("EREBSNAP")
+
+// flagDictionary marks a stream compressed with a shared zstd dictionary
+const flagDictionary byte = 1 << 0
+
+// Codec compresses persisted atom streams with zstd. An optional dictionary
+// trained on representative atom records improves the ratio considerably for
+// the small, repetitive records typical of infrastructure tenants.
+// Snapshots are written in SchemaVersion unless another is set, and read in
+// any version this build supports.
+type Codec struct {
+	dictionary []byte
+	level      zstd.EncoderLevel
+	version    int
+}
+
+// CodecOption configures a Codec
+type CodecOption func(*Codec)
+
+// WithDictionary compresses and decompresses using a shared zstd dictionary
+func WithDictionary(d []byte) CodecOption {
+	return func(c *Codec) {
+		c.dictionary = d
+	}
+}
+
+// WithLevel sets the zstd compression level
+func WithLevel(level zstd.EncoderLevel) CodecOption {
+	return func(c *Codec) {
+		c.level = level
+	}
+}
+
+// WithSchemaVersion writes snapshots in an older schema version, readable
+// by the builds an installation rolls back to
+func WithSchemaVersion(version int) CodecOption {
+	return func(c *Codec) {
+		c.version = version
+	}
+}
+
+// NewCodec creates a new snapshot codec
+func NewCodec(opts ...CodecOption) *Codec {
+	c := &Codec{level: zstd.SpeedDefault, version: SchemaVersion}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// TrainDictionary builds a zstd dictionary from sample atoms. The result can
+// be stored alongside the tenant's snapshots and passed to WithDictionary.
+func TrainDictionary(samples []atomspace.Atom, maxSize int) ([]byte, error) {
+	if len(samples) == 0 {
+		return nil, errors.New("no samples to train dictionary")
+	}
+	if maxSize <= 0 {
+		maxSize = 64 << 10
+	}
+
+	input := make([][]byte, len(samples))
+	for i, atom := range samples {
+		input[i] = MarshalAtom(atom)
+	}
+
+	return dict.BuildZstdDict(input, dict.Options{
+		MaxDictSize: maxSize,
+		HashBytes:   6,
+	})
+}
+
+// WriteSnapshot writes atoms to w as a zstd-compressed stream of
+// length-delimited AtomRecord messages
+func (c *Codec) WriteSnapshot(w io.Writer, atoms []atomspace.Atom) error {
+	records := make([]*AtomRecord, len(atoms))
+	for i, atom := range atoms {
+		records[i] = RecordFromAtom(atom)
+	}
+	_, err := c.writeRecords(w, records)
+	return err
+}
+
+// WriteRecords writes records of the current schema version to w as a
+// snapshot in the codec's version, and returns how many lost information
+// they cannot hold in it. The records are not modified.
+func (c *Codec) WriteRecords(w io.Writer, records []*AtomRecord) (int, error) {
+	copies := make([]*AtomRecord, len(records))
+	for i, rec := range records {
+		cp := *rec
+		copies[i] = &cp
+	}
+	return c.writeRecords(w, copies)
+}
+
+// writeRecords downgrades records to the codec's version in place and
+// writes them
+func (c *Codec) writeRecords(w io.Writer, records []*AtomRecord) (int, error) {
+	lossy, err := migrateRecords(records, SchemaVersion, c.version)
+	if err != nil {
+		return 0, err
+	}
+
+	flags := byte(0)
+	if len(c.dictionary) > 0 {
+		flags |= flagDictionary
+	}
+
+	header := append(append([]byte{}, snapshotMagic...), byte(c.version), flags)
+	if _, err := w.Write(header); err != nil {
+		return 0, fmt.Errorf("failed to write snapshot header: %w", err)
+	}
+
+	encOpts := []zstd.EOption{zstd.WithEncoderLevel(c.level)}
+	if len(c.dictionary) > 0 {
+		encOpts = append(encOpts, zstd.WithEncoderDict(c.dictionary))
+	}
+	enc, err := zstd.NewWriter(w, encOpts...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create zstd encoder: %w", err)
+	}
+
+	var buf, record []byte
+	for _, rec := range records {
+		record = AppendRecord(record[:0], rec)
+		buf = protowire.AppendBytes(buf[:0], record)
+		if _, err := enc.Write(buf); err != nil {
+			enc.Close()
+			return 0, fmt.Errorf("failed to write atom %s: %w", rec.ID, err)
+		}
+	}
+
+	return lossy, enc.Close()
+}
+
+// ReadSnapshot decodes every record of a snapshot stream, upgraded to the
+// current schema version
+func (c *Codec) ReadSnapshot(r io.Reader) ([]*AtomRecord, error) {
+	records, _, err := c.ReadSnapshotVersion(r)
+	return records, err
+}
+
+// ReadSnapshotVersion decodes every record of a snapshot stream, upgraded
+// to the current schema version, and returns the version it was written in
+func (c *Codec) ReadSnapshotVersion(r io.Reader) ([]*AtomRecord, int, error) {
+	header := make([]byte, len(snapshotMagic)+2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, 0, fmt.Errorf("failed to read snapshot header: %w", err)
+	}
+	if !bytes.Equal(header[:len(snapshotMagic)], snapshotMagic) {
+		return nil, 0, errors.New("not an erebus snapshot")
+	}
+	version := int(header[len(snapshotMagic)])
+	if err := CheckSchemaVersion(version); err != nil {
+		return nil, 0, fmt.Errorf("snapshot: %w", err)
+	}
+	flags := header[len(snapshotMagic)+1]
+	if flags&flagDictionary != 0 && len(c.dictionary) == 0 {
+		return nil, 0, errors.New("snapshot was written with a dictionary but none is configured")
+	}
+
+	var decOpts []zstd.DOption
+	if len(c.dictionary) > 0 {
+		decOpts = append(decOpts, zstd.WithDecoderDicts(c.dictionary))
+	}
+	dec, err := zstd.NewReader(r, decOpts...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create zstd decoder: %w", err)
+	}
+	defer dec.Close()
+
+	br := bufio.NewReader(dec)
+	var records []*AtomRecord
+	for {
+		size, err := readUvarint(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read record length: %w", err)
+		}
+
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			return nil, 0, fmt.Errorf("truncated atom record: %w", err)
+		}
+
+		rec, err := UnmarshalRecord(payload)
+		if err != nil {
+			return nil, 0, err
+		}
+		records = append(records, rec)
+	}
+
+	if _, err := migrateRecords(records, version, SchemaVersion); err != nil {
+		return nil, 0, err
+	}
+	return records, version, nil
+}
+
+// LoadSnapshot reads a snapshot and rebuilds the atoms it contains
+func (c *Codec) LoadSnapshot(r io.Reader) ([]atomspace.Atom, error) {
+	records, err := c.ReadSnapshot(r)
+	if err != nil {
+		return nil, err
+	}
+	return BuildAtoms(records)
+}
+
+// readUvarint reads a protobuf varint, returning io.EOF only when the stream
+// ends cleanly before the first byte
+func readUvarint(r io.ByteReader) (uint64, error) {
+	var x uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		b, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF && shift > 0 {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		x |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return x, nil
+		}
+	}
+	return 0, errors.New("varint overflows 64 bits")
+}
@@ -0,0 +1,524 @@
+package persistence
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// valueLogMagic identifies an Erebus value log
+var valueLogMagic = []byte("EREBVLOG")
+
+// ValueLogConfig controls the batched persistence of truth and attention
+// values. Agents change these every cycle, so writing each change through
+// would thrash storage; instead changes are collected and appended in
+// batches, accepting the loss of the last LossWindow of them on a crash.
+type ValueLogConfig struct {
+	Dir                  string        // Directory of the per-shard logs; empty disables them
+	Interval             time.Duration // How often atoms are scanned for changed values
+	LossWindow           time.Duration // Longest a changed value stays unflushed
+	CompactionRatio      float64       // Compact once the log holds this many records per atom...
+	CompactionMinRecords int           // ...plus this many, so that small logs are not rewritten on every flush
+	Retention            time.Duration // How long values stay logged once a tenant snapshot covers them, unless set per tenant
+}
+
+// DefaultValueLogConfig returns the default value log configuration, with
+// logging disabled until a directory is set
+func DefaultValueLogConfig() ValueLogConfig {
+	return ValueLogConfig{
+		Interval:             5 * time.Second,
+		LossWindow:           30 * time.Second,
+		CompactionRatio:      2,
+		CompactionMinRecords: 1024,
+	}
+}
+
+// ValueLog is an append-only log of the truth and attention values of the
+// atoms of one shard. Each record is an AtomRecord holding only the atom's
+// ID, tenant, values and the time they were observed. The log is compacted
+// to the latest value of each atom once superseded records dominate it,
+// and values covered by snapshots can be pruned from it. With an
+// encryptor, the records of each tenant written together are sealed in
+// one envelope for the tenant, like snapshots.
+// It is safe for concurrent use.
+type ValueLog struct {
+	path        string
+	file        *os.File
+	encryptor   *Encryptor             // Seals records per tenant; nil writes them in plaintext
+	latest      map[string]*AtomRecord // tenantID/atomID -> last flushed values
+	pending     map[string]*AtomRecord // tenantID/atomID -> changed values not yet flushed
+	oldest      time.Time              // When the oldest pending change was observed
+	records     int                    // Records in the file
+	size        int64                  // Bytes in the file
+	ratio       float64
+	minRecords  int
+	flushed     int64
+	compacts    int64
+	reclaimed   int64 // Bytes dropped by compactions
+	compactedAt time.Time
+	mu          sync.Mutex
+}
+
+// OpenValueLog opens or creates the value log at path, loading the values
+// it holds. A record cut short by a crash is discarded, and a log of an
+// older schema version is rewritten in the current one.
+func OpenValueLog(path string) (*ValueLog, error) {
+	return OpenSealedValueLog(path, nil)
+}
+
+// OpenSealedValueLog opens or creates the value log at path like
+// OpenValueLog, sealing records per tenant with encryptor if it is not
+// nil. Plaintext records of a log written before encryption was enabled
+// are rewritten sealed, and those of tenants whose keys were shredded are
+// dropped.
+func OpenSealedValueLog(path string, encryptor *Encryptor) (*ValueLog, error) {
+	defaults := DefaultValueLogConfig()
+	l := &ValueLog{
+		path:       path,
+		encryptor:  encryptor,
+		latest:     make(map[string]*AtomRecord),
+		pending:    make(map[string]*AtomRecord),
+		ratio:      defaults.CompactionRatio,
+		minRecords: defaults.CompactionMinRecords,
+	}
+
+	size, version, stale, err := l.load()
+	if err != nil {
+		return nil, err
+	}
+	if size < 0 || version != SchemaVersion || stale {
+		if err := l.rewrite(); err != nil {
+			return nil, err
+		}
+		return l, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Truncate(size); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(size, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	l.file = file
+	l.size = size
+	return l, nil
+}
+
+// load reads the records of an existing log, upgraded to the current
+// schema version, and returns the size of its intact part, or -1 if there
+// is no log, the version it was written in, and whether the log must be
+// rewritten to be sealed as configured
+func (l *ValueLog) load() (int64, int, bool, error) {
+	records, size, version, stale, err := readValueLog(l.path, l.encryptor)
+	if err != nil || size < 0 {
+		return size, version, false, err
+	}
+	if _, err := migrateRecords(records, version, SchemaVersion); err != nil {
+		return 0, 0, false, err
+	}
+	for _, rec := range records {
+		l.latest[valueKey(rec.TenantID, rec.ID)] = rec
+	}
+	l.records = len(records)
+	return size, version, stale, nil
+}
+
+// readValueLog reads the records of the log at path as written, opening
+// sealed frames with encryptor, and returns the size of its intact part,
+// or -1 if there is no log, its schema version, and whether it holds
+// plaintext records while encryptor is set or frames of shredded tenants
+func readValueLog(path string, encryptor *Encryptor) ([]*AtomRecord, int64, int, bool, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, -1, 0, false, nil
+	}
+	if err != nil {
+		return nil, 0, 0, false, err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	header := make([]byte, len(valueLogMagic)+1)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, -1, 0, false, nil // Crashed while creating the log
+	}
+	if !bytes.Equal(header[:len(valueLogMagic)], valueLogMagic) {
+		return nil, 0, 0, false, fmt.Errorf("%s is not an erebus value log", path)
+	}
+	version := int(header[len(valueLogMagic)])
+	if err := CheckSchemaVersion(version); err != nil {
+		return nil, 0, 0, false, fmt.Errorf("value log %s: %w", path, err)
+	}
+
+	var records []*AtomRecord
+	stale := false
+	size := int64(len(header))
+	for {
+		length, err := readUvarint(r)
+		if err != nil {
+			break
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			break
+		}
+		if bytes.HasPrefix(payload, envelopeMagic) {
+			if encryptor == nil {
+				return nil, 0, 0, false, fmt.Errorf("value log %s: %w", path, ErrEncryptedValueLog)
+			}
+			sealed, err := openRecords(encryptor, payload)
+			if errors.Is(err, ErrKeyNotFound) {
+				stale = true // The tenant was shredded
+			} else if err != nil {
+				return nil, 0, 0, false, fmt.Errorf("value log %s: %w", path, err)
+			}
+			records = append(records, sealed...)
+		} else {
+			rec, err := UnmarshalRecord(payload)
+			if err != nil {
+				break
+			}
+			records = append(records, rec)
+			stale = stale || encryptor != nil
+		}
+		size += int64(protowire.SizeVarint(length)) + int64(length)
+	}
+	return records, size, version, stale, nil
+}
+
+// appendRecords appends records to buf as length-delimited frames. With
+// an encryptor, the records of each tenant are sealed into one frame.
+func appendRecords(buf []byte, records map[string]*AtomRecord, encryptor *Encryptor) ([]byte, error) {
+	if encryptor == nil {
+		for _, rec := range records {
+			buf = protowire.AppendBytes(buf, AppendRecord(nil, rec))
+		}
+		return buf, nil
+	}
+
+	byTenant := make(map[string][]byte)
+	for _, rec := range records {
+		byTenant[rec.TenantID] = protowire.AppendBytes(byTenant[rec.TenantID], AppendRecord(nil, rec))
+	}
+	for tenantID, plaintext := range byTenant {
+		var sealed bytes.Buffer
+		w, err := encryptor.Writer(context.Background(), tenantID, &sealed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seal values of tenant %s: %w", tenantID, err)
+		}
+		if _, err := w.Write(plaintext); err != nil {
+			return nil, fmt.Errorf("failed to seal values of tenant %s: %w", tenantID, err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("failed to seal values of tenant %s: %w", tenantID, err)
+		}
+		buf = protowire.AppendBytes(buf, sealed.Bytes())
+	}
+	return buf, nil
+}
+
+// openRecords decrypts a sealed frame into the records it holds
+func openRecords(encryptor *Encryptor, frame []byte) ([]*AtomRecord, error) {
+	r, tenantID, err := encryptor.Reader(context.Background(), bytes.NewReader(frame))
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open values of tenant %s: %w", tenantID, err)
+	}
+
+	var records []*AtomRecord
+	for len(plaintext) > 0 {
+		payload, n := protowire.ConsumeBytes(plaintext)
+		if n < 0 {
+			return nil, fmt.Errorf("invalid sealed values of tenant %s", tenantID)
+		}
+		rec, err := UnmarshalRecord(payload)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+		plaintext = plaintext[n:]
+	}
+	return records, nil
+}
+
+func valueKey(tenantID, atomID string) string {
+	return tenantID + "/" + atomID
+}
+
+// SetCompaction sets when the log is compacted: once it holds more than
+// ratio records per atom plus minRecords. Values that are not positive
+// keep the current setting.
+func (l *ValueLog) SetCompaction(ratio float64, minRecords int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if ratio > 0 {
+		l.ratio = ratio
+	}
+	if minRecords > 0 {
+		l.minRecords = minRecords
+	}
+}
+
+func (l *ValueLog) compactionThreshold() int {
+	return int(l.ratio*float64(len(l.latest))) + l.minRecords
+}
+
+// Observe notes the atoms whose values differ from those last flushed
+func (l *ValueLog) Observe(atoms []atomspace.Atom, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, atom := range atoms {
+		key := valueKey(atom.GetTenantID(), atom.GetID())
+		tv, av := atom.GetTruthValue(), atom.GetAttentionValue()
+		var refreshedAt, decayedAt time.Time
+		if f, ok := atom.(freshness); ok {
+			refreshedAt, decayedAt = f.GetRefreshedAt(), f.GetDecayedAt()
+		}
+		if last, ok := l.latest[key]; ok && last.TruthValue == tv && last.AttentionValue == av &&
+			last.RefreshedAt.Equal(refreshedAt) && last.DecayedAt.Equal(decayedAt) {
+			delete(l.pending, key)
+			continue
+		}
+		if len(l.pending) == 0 {
+			l.oldest = now
+		}
+		l.pending[key] = &AtomRecord{
+			ID:             atom.GetID(),
+			TenantID:       atom.GetTenantID(),
+			TruthValue:     tv,
+			AttentionValue: av,
+			UpdatedAt:      now,
+			RefreshedAt:    refreshedAt,
+			DecayedAt:      decayedAt,
+		}
+	}
+}
+
+// freshness is implemented by atoms that know when their truth value was
+// refreshed and decayed
+type freshness interface {
+	GetRefreshedAt() time.Time
+	GetDecayedAt() time.Time
+}
+
+// Due reports whether pending changes have waited as long as they may
+func (l *ValueLog) Due(now time.Time, lossWindow time.Duration) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.pending) > 0 && now.Sub(l.oldest) >= lossWindow
+}
+
+// Flush appends the pending changes and syncs the log, compacting it if
+// most of its records are superseded. It returns how many were written.
+func (l *ValueLog) Flush() (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.flush()
+}
+
+func (l *ValueLog) flush() (int, error) {
+	if len(l.pending) == 0 {
+		return 0, nil
+	}
+
+	buf, err := appendRecords(nil, l.pending, l.encryptor)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := l.file.Write(buf); err != nil {
+		return 0, fmt.Errorf("failed to append to value log: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return 0, fmt.Errorf("failed to sync value log: %w", err)
+	}
+
+	n := len(l.pending)
+	for key, rec := range l.pending {
+		l.latest[key] = rec
+	}
+	l.pending = make(map[string]*AtomRecord)
+	l.records += n
+	l.size += int64(len(buf))
+	l.flushed += int64(n)
+
+	if l.records > l.compactionThreshold() {
+		if err := l.compact(); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// compact rewrites the log, counting the bytes it reclaims
+func (l *ValueLog) compact() error {
+	before := l.size
+	if err := l.rewrite(); err != nil {
+		return err
+	}
+	l.compacts++
+	l.reclaimed += before - l.size
+	l.compactedAt = time.Now()
+	return nil
+}
+
+// rewrite replaces the log with the latest value of each atom
+func (l *ValueLog) rewrite() error {
+	buf, err := writeValueLog(l.path, SchemaVersion, l.latest, l.encryptor)
+	if err != nil {
+		return err
+	}
+	if l.file != nil {
+		l.file.Close()
+	}
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	l.file = file
+	l.records = len(l.latest)
+	l.size = int64(len(buf))
+	return nil
+}
+
+// writeValueLog atomically replaces the log at path with records in a
+// schema version, sealed with encryptor if it is not nil, and returns its
+// content
+func writeValueLog(path string, version int, records map[string]*AtomRecord, encryptor *Encryptor) ([]byte, error) {
+	buf, err := appendRecords(append(append([]byte{}, valueLogMagic...), byte(version)), records, encryptor)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write value log: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, fmt.Errorf("failed to replace value log: %w", err)
+	}
+	return buf, nil
+}
+
+// Values returns the latest logged values of each atom
+func (l *ValueLog) Values() []*AtomRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	records := make([]*AtomRecord, 0, len(l.latest))
+	for _, rec := range l.latest {
+		records = append(records, rec)
+	}
+	return records
+}
+
+// Forget drops a tenant's values, rewriting the log without them, and
+// returns how many atoms it had values for
+func (l *ValueLog) Forget(tenantID string) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, rec := range l.pending {
+		if rec.TenantID == tenantID {
+			delete(l.pending, key)
+		}
+	}
+	removed := l.drop(func(rec *AtomRecord) bool { return rec.TenantID == tenantID })
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, l.rewrite()
+}
+
+// Prune drops the flushed values matching drop, such as those a snapshot
+// holds, compacting the log without them. Pending changes are kept. It
+// returns how many atoms' values were dropped.
+func (l *ValueLog) Prune(drop func(*AtomRecord) bool) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	removed := l.drop(drop)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, l.compact()
+}
+
+func (l *ValueLog) drop(match func(*AtomRecord) bool) int {
+	removed := 0
+	for key, rec := range l.latest {
+		if match(rec) {
+			delete(l.latest, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Count returns how many of a tenant's atoms have logged values
+func (l *ValueLog) Count(tenantID string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := 0
+	for _, rec := range l.latest {
+		if rec.TenantID == tenantID {
+			n++
+		}
+	}
+	return n
+}
+
+// GetStats returns value log statistics
+func (l *ValueLog) GetStats() map[string]interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := map[string]interface{}{
+		"atoms":                len(l.latest),
+		"pending":              len(l.pending),
+		"records":              l.records,
+		"superseded":           l.records - len(l.latest),
+		"size_bytes":           l.size,
+		"compaction_threshold": l.compactionThreshold(),
+		"flushed":              l.flushed,
+		"compactions":          l.compacts,
+		"reclaimed_bytes":      l.reclaimed,
+		"encrypted":            l.encryptor != nil,
+	}
+	if !l.compactedAt.IsZero() {
+		stats["last_compacted_at"] = l.compactedAt
+	}
+	return stats
+}
+
+// Close flushes pending changes and closes the log
+func (l *ValueLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, err := l.flush()
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,193 @@
+package persistence
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func TestValueLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shard-0.vlog")
+	log, err := OpenValueLog(path)
+	if err != nil {
+		t.Fatalf("Failed to open value log: %v", err)
+	}
+
+	db := atomspace.NewNode("db", "db", "acme", atomspace.ConceptNodeType)
+	cache := atomspace.NewNode("cache", "cache", "globex", atomspace.ConceptNodeType)
+	now := time.Now()
+	log.Observe([]atomspace.Atom{db, cache}, now)
+	if log.Due(now, time.Minute) || !log.Due(now.Add(time.Minute), time.Minute) {
+		t.Error("Expected changes to be due once they waited for the loss window")
+	}
+	if n, err := log.Flush(); err != nil || n != 2 {
+		t.Fatalf("Expected 2 values flushed, got %d: %v", n, err)
+	}
+
+	log.Observe([]atomspace.Atom{db, cache}, now)
+	if log.Due(now.Add(time.Hour), time.Minute) {
+		t.Error("Expected unchanged values not to be flushed again")
+	}
+	db.SetAttentionValue(atomspace.AttentionValue{STI: 42})
+	log.Observe([]atomspace.Atom{db, cache}, now.Add(time.Second))
+	if err := log.Close(); err != nil {
+		t.Fatalf("Failed to close value log: %v", err)
+	}
+
+	// A record cut short by a crash is discarded
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	f.Write([]byte{0x20, 0x01})
+	f.Close()
+
+	log, err = OpenValueLog(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen value log: %v", err)
+	}
+	defer log.Close()
+	values := make(map[string]*AtomRecord)
+	for _, rec := range log.Values() {
+		values[rec.TenantID+"/"+rec.ID] = rec
+	}
+	if len(values) != 2 || values["acme/db"].AttentionValue.STI != 42 {
+		t.Errorf("Expected the latest values after reopening, got %+v", values)
+	}
+
+	if n, err := log.Forget("acme"); err != nil || n != 1 || log.Count("acme") != 0 || log.Count("globex") != 1 {
+		t.Errorf("Expected acme's values forgotten, got %d: %v", n, err)
+	}
+}
+
+func TestValueLogCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shard-0.vlog")
+	log, err := OpenValueLog(path)
+	if err != nil {
+		t.Fatalf("Failed to open value log: %v", err)
+	}
+	defer log.Close()
+
+	db := atomspace.NewNode("db", "db", "acme", atomspace.ConceptNodeType)
+	for i := int16(1); i <= 1100; i++ {
+		db.SetAttentionValue(atomspace.AttentionValue{STI: i})
+		log.Observe([]atomspace.Atom{db}, time.Now())
+		if _, err := log.Flush(); err != nil {
+			t.Fatalf("Flush failed: %v", err)
+		}
+	}
+	stats := log.GetStats()
+	if stats["compactions"].(int64) != 1 || stats["records"].(int) > 100 {
+		t.Errorf("Expected the log compacted, got %v", stats)
+	}
+}
+
+func TestValueLogPrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shard-0.vlog")
+	log, err := OpenValueLog(path)
+	if err != nil {
+		t.Fatalf("Failed to open value log: %v", err)
+	}
+	log.SetCompaction(1, 4)
+
+	db := atomspace.NewNode("db", "db", "acme", atomspace.ConceptNodeType)
+	cache := atomspace.NewNode("cache", "cache", "acme", atomspace.ConceptNodeType)
+	snapshotAt := time.Now()
+	log.Observe([]atomspace.Atom{db, cache}, snapshotAt.Add(-time.Minute))
+	log.Flush()
+	for i := int16(1); i <= 5; i++ {
+		db.SetAttentionValue(atomspace.AttentionValue{STI: i})
+		log.Observe([]atomspace.Atom{db}, snapshotAt.Add(time.Duration(i)*time.Second))
+		log.Flush()
+	}
+	stats := log.GetStats()
+	if stats["compactions"].(int64) != 1 || stats["reclaimed_bytes"].(int64) <= 0 || stats["last_compacted_at"] == nil {
+		t.Errorf("Expected the log compacted past its threshold, got %v", stats)
+	}
+
+	// Values older than a snapshot are dropped; newer ones are kept
+	size := stats["size_bytes"].(int64)
+	n, err := log.Prune(func(rec *AtomRecord) bool { return !rec.UpdatedAt.After(snapshotAt) })
+	if err != nil || n != 1 {
+		t.Fatalf("Expected 1 value pruned, got %d: %v", n, err)
+	}
+	if stats := log.GetStats(); stats["atoms"] != 1 || stats["size_bytes"].(int64) >= size {
+		t.Errorf("Expected the pruned values gone from the log, got %v", stats)
+	}
+	log.Close()
+
+	log, err = OpenValueLog(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen value log: %v", err)
+	}
+	defer log.Close()
+	if values := log.Values(); len(values) != 1 || values[0].ID != "db" || values[0].AttentionValue.STI != 5 {
+		t.Errorf("Expected only the value newer than the snapshot kept, got %+v", values)
+	}
+}
+
+func TestSealedValueLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shard-0.vlog")
+	provider, _ := NewLocalKeyProvider(bytes.Repeat([]byte{7}, 32), "")
+	encryptor := NewEncryptor(provider)
+
+	// A log written before encryption was enabled is rewritten sealed
+	log, err := OpenValueLog(path)
+	if err != nil {
+		t.Fatalf("Failed to open value log: %v", err)
+	}
+	db := atomspace.NewNode("payments-db", "payments-db", "acme", atomspace.ConceptNodeType)
+	cache := atomspace.NewNode("session-cache", "session-cache", "globex", atomspace.ConceptNodeType)
+	log.Observe([]atomspace.Atom{db}, time.Now())
+	log.Close()
+
+	log, err = OpenSealedValueLog(path, encryptor)
+	if err != nil {
+		t.Fatalf("Failed to open sealed value log: %v", err)
+	}
+	db.SetAttentionValue(atomspace.AttentionValue{STI: 42})
+	log.Observe([]atomspace.Atom{db, cache}, time.Now())
+	if err := log.Close(); err != nil {
+		t.Fatalf("Failed to close sealed value log: %v", err)
+	}
+
+	// Envelopes name their tenant, but the atoms and values are sealed
+	data, _ := os.ReadFile(path)
+	for _, plaintext := range []string{"payments-db", "session-cache"} {
+		if bytes.Contains(data, []byte(plaintext)) {
+			t.Errorf("Expected %q sealed, found it in the log", plaintext)
+		}
+	}
+	if _, err := OpenValueLog(path); !errors.Is(err, ErrEncryptedValueLog) {
+		t.Errorf("Expected a sealed log not to open without an encryptor, got %v", err)
+	}
+
+	log, err = OpenSealedValueLog(path, encryptor)
+	if err != nil {
+		t.Fatalf("Failed to reopen sealed value log: %v", err)
+	}
+	values := make(map[string]*AtomRecord)
+	for _, rec := range log.Values() {
+		values[rec.TenantID+"/"+rec.ID] = rec
+	}
+	if len(values) != 2 || values["acme/payments-db"].AttentionValue.STI != 42 {
+		t.Errorf("Expected the latest values after reopening, got %+v", values)
+	}
+	log.Close()
+
+	// The values of a shredded tenant are dropped
+	if err := encryptor.Shred(context.Background(), "acme"); err != nil {
+		t.Fatalf("Shred failed: %v", err)
+	}
+	log, err = OpenSealedValueLog(path, encryptor)
+	if err != nil {
+		t.Fatalf("Failed to open value log after shredding: %v", err)
+	}
+	defer log.Close()
+	if log.Count("acme") != 0 || log.Count("globex") != 1 {
+		t.Errorf("Expected only globex's values kept, got %+v", log.Values())
+	}
+}
@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoArtifacts is returned by AttachArtifact outside of a pipeline
+// execution, or when artifacts are not stored
+var ErrNoArtifacts = errors.New("artifacts cannot be attached here")
+
+// ArtifactRef names an artifact of an execution and the stage attaching it
+type ArtifactRef struct {
+	TenantID    string
+	PipelineID  string
+	ExecutionID string
+	Stage       string
+	Name        string
+	ContentType string
+}
+
+// ArtifactSink stores the artifacts stages attach to executions
+type ArtifactSink func(ctx context.Context, ref ArtifactRef, data []byte) error
+
+type artifactKey struct{}
+
+// stageArtifacts is what a running stage attaches its artifacts to
+type stageArtifacts struct {
+	sink ArtifactSink
+	ref  ArtifactRef
+}
+
+// AttachArtifact attaches an artifact, such as a report, an exported
+// subgraph or an action transcript, to the execution a stage runs in. It
+// replaces the execution's artifact of the same name.
+func AttachArtifact(ctx context.Context, name, contentType string, data []byte) error {
+	a, ok := ctx.Value(artifactKey{}).(stageArtifacts)
+	if !ok || a.sink == nil {
+		return ErrNoArtifacts
+	}
+	ref := a.ref
+	ref.Name, ref.ContentType = name, contentType
+	return a.sink(ctx, ref, data)
+}
+
+// SetArtifactSink stores the artifacts stages of the orchestrator's
+// pipelines attach, including those created already
+func (po *PipelineOrchestrator) SetArtifactSink(sink ArtifactSink) {
+	po.mu.Lock()
+	defer po.mu.Unlock()
+	po.artifacts = sink
+	for _, p := range po.pipelines {
+		p.setArtifactSink(sink)
+	}
+}
+
+func (p *Pipeline) setArtifactSink(sink ArtifactSink) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.artifacts = sink
+}
+
+// withArtifacts lets a stage of an execution attach artifacts
+func withArtifacts(ctx context.Context, sink ArtifactSink, p *Pipeline, exec *Execution, stage string) context.Context {
+	if sink == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, artifactKey{}, stageArtifacts{
+		sink: sink,
+		ref:  ArtifactRef{TenantID: p.TenantID, PipelineID: p.ID, ExecutionID: exec.ID, Stage: stage},
+	})
+}
@@ -0,0 +1,299 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// maxExecutionHistory bounds the finished executions retained per pipeline
+const maxExecutionHistory = 50
+
+// ExecutionState represents the state of a single pipeline execution
+type ExecutionState string
+
+const (
+	ExecutionStateScheduled ExecutionState = "scheduled"
+	ExecutionStateQueued    ExecutionState = "queued"
+	ExecutionStateRunning   ExecutionState = "running"
+	ExecutionStateCompleted ExecutionState = "completed"
+	ExecutionStateFailed    ExecutionState = "failed"
+	ExecutionStateCancelled ExecutionState = "cancelled"
+)
+
+// Execution holds the state of one run of a pipeline. Executions are owned
+// by their pipeline and only mutated under its lock; callers receive copies.
+type Execution struct {
+	ID            string         `json:"id"`
+	PipelineID    string         `json:"pipeline_id"`
+	State         ExecutionState `json:"state"`
+	QueuePosition int            `json:"queue_position"` // 1-based while queued, 0 otherwise
+	QueuedAt      time.Time      `json:"queued_at"`
+	RunAt         time.Time      `json:"run_at,omitempty"`  // Not admitted before, for scheduled executions
+	HeldBy        string         `json:"held_by,omitempty"` // Why the execution was pushed back, e.g. by a maintenance window
+	StartedAt     time.Time      `json:"started_at,omitempty"`
+	CompletedAt   time.Time      `json:"completed_at,omitempty"`
+	Retries       int            `json:"retries"`
+	FailedStage   string         `json:"failed_stage,omitempty"`
+	Error         string         `json:"error,omitempty"`
+
+	Output interface{}   `json:"-"`
+	seq    int64         // submission order
+	input  interface{}   // initial input, kept until the execution finishes
+	ready  chan struct{} // closed when the execution is admitted
+}
+
+// Done reports whether the execution has finished
+func (e *Execution) Done() bool {
+	switch e.State {
+	case ExecutionStateCompleted, ExecutionStateFailed, ExecutionStateCancelled:
+		return true
+	}
+	return false
+}
+
+// ExecutionError is returned when a stage of an admitted execution fails
+type ExecutionError struct {
+	ExecutionID string
+	Stage       string
+	Err         error
+}
+
+func (e *ExecutionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExecutionError) Unwrap() error {
+	return e.Err
+}
+
+// SetMaxConcurrency limits how many executions of the pipeline may run at
+// once. Further executions wait in a FIFO queue; 0 removes the limit.
+func (p *Pipeline) SetMaxConcurrency(n int) error {
+	if n < 0 {
+		return fmt.Errorf("max concurrency must not be negative")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.MaxConcurrency = n
+	p.admitLocked()
+	return nil
+}
+
+// Enqueue registers a new execution of the pipeline. The execution must be
+// admitted with Wait before it is run.
+func (p *Pipeline) Enqueue(input interface{}) (*Execution, error) {
+	return p.enqueue("", input, time.Time{})
+}
+
+// CheckInput reports whether input is of the pipeline's input type
+func (p *Pipeline) CheckInput(input interface{}) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if err := checkValue(input, p.InputType); err != nil {
+		return fmt.Errorf("invalid pipeline input: %w", err)
+	}
+	return nil
+}
+
+// enqueue registers an execution under id, or the next ID of the pipeline
+// if empty. An execution due after now is scheduled instead of queued, and
+// only joins the queue once released with queueScheduled.
+func (p *Pipeline) enqueue(id string, input interface{}, runAt time.Time) (*Execution, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := checkValue(input, p.InputType); err != nil {
+		return nil, fmt.Errorf("invalid pipeline input: %w", err)
+	}
+
+	p.executionSeq++
+	if id == "" {
+		id = fmt.Sprintf("%s-%d", p.ID, p.executionSeq)
+	}
+	if _, exists := p.executions[id]; exists {
+		return nil, fmt.Errorf("execution %s already exists", id)
+	}
+	exec := &Execution{
+		ID:         id,
+		PipelineID: p.ID,
+		State:      ExecutionStateQueued,
+		QueuedAt:   time.Now(),
+		seq:        p.executionSeq,
+		input:      input,
+		ready:      make(chan struct{}),
+	}
+	p.executions[exec.ID] = exec
+	if runAt.After(exec.QueuedAt) {
+		exec.State = ExecutionStateScheduled
+		exec.RunAt = runAt
+		return exec, nil
+	}
+	p.queue = append(p.queue, exec)
+	p.admitLocked()
+
+	return exec, nil
+}
+
+// queueScheduled moves a scheduled execution that became due to the queue
+func (p *Pipeline) queueScheduled(exec *Execution) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if exec.State != ExecutionStateScheduled {
+		return
+	}
+	exec.State = ExecutionStateQueued
+	p.queue = append(p.queue, exec)
+	p.admitLocked()
+}
+
+// hold pushes a scheduled execution back to runAt, noting why, and reports
+// whether it did. Executions already queued are not held.
+func (p *Pipeline) hold(exec *Execution, runAt time.Time, reason string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if exec.State != ExecutionStateScheduled {
+		return false
+	}
+	exec.RunAt = runAt
+	exec.HeldBy = reason
+	return true
+}
+
+// noteHeld records why an execution was pushed back before it was
+// registered
+func (p *Pipeline) noteHeld(exec *Execution, reason string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	exec.HeldBy = reason
+}
+
+// Wait blocks until the execution is admitted or ctx is done. A cancelled
+// execution is removed from the queue.
+func (p *Pipeline) Wait(ctx context.Context, exec *Execution) error {
+	select {
+	case <-exec.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-exec.ready:
+		// Admitted concurrently with the cancellation; let Run observe ctx
+		return nil
+	default:
+	}
+
+	for i, queued := range p.queue {
+		if queued == exec {
+			p.queue = append(p.queue[:i:i], p.queue[i+1:]...)
+			break
+		}
+	}
+	p.finishLocked(exec, ExecutionStateCancelled, "", ctx.Err())
+	return fmt.Errorf("pipeline execution cancelled while queued: %w", ctx.Err())
+}
+
+// admitLocked starts queued executions while concurrency slots are free
+func (p *Pipeline) admitLocked() {
+	for len(p.queue) > 0 && (p.MaxConcurrency == 0 || p.running < p.MaxConcurrency) {
+		exec := p.queue[0]
+		p.queue = p.queue[1:]
+		p.running++
+
+		exec.State = ExecutionStateRunning
+		exec.StartedAt = time.Now()
+		p.State = PipelineStateRunning
+		p.StartedAt = exec.StartedAt
+		close(exec.ready)
+	}
+}
+
+// finishLocked records the outcome of an execution and prunes history
+func (p *Pipeline) finishLocked(exec *Execution, state ExecutionState, failedStage string, err error) {
+	exec.State = state
+	exec.CompletedAt = time.Now()
+	exec.FailedStage = failedStage
+	exec.input = nil
+	if err != nil {
+		exec.Error = err.Error()
+	}
+
+	p.finished = append(p.finished, exec.ID)
+	if len(p.finished) > maxExecutionHistory {
+		delete(p.executions, p.finished[0])
+		p.finished = p.finished[1:]
+	}
+}
+
+// release frees the concurrency slot of an admitted execution
+func (p *Pipeline) release(exec *Execution, failedStage string, output interface{}, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state := ExecutionStateCompleted
+	if err != nil {
+		state = ExecutionStateFailed
+	}
+	exec.Output = output
+	p.finishLocked(exec, state, failedStage, err)
+
+	p.running--
+	p.CompletedAt = exec.CompletedAt
+	if p.running == 0 {
+		p.State = PipelineStateCompleted
+		if err != nil {
+			p.State = PipelineStateFailed
+		}
+	}
+	p.admitLocked()
+}
+
+// GetExecution returns a copy of an execution, including its current queue
+// position
+func (p *Pipeline) GetExecution(executionID string) (Execution, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	exec, exists := p.executions[executionID]
+	if !exists {
+		return Execution{}, false
+	}
+	return p.snapshotLocked(exec), true
+}
+
+// GetExecutions returns copies of the pipeline's queued, running and recently
+// finished executions in submission order
+func (p *Pipeline) GetExecutions() []Execution {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	result := make([]Execution, 0, len(p.executions))
+	for _, exec := range p.executions {
+		result = append(result, p.snapshotLocked(exec))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].seq < result[j].seq })
+	return result
+}
+
+func (p *Pipeline) snapshotLocked(exec *Execution) Execution {
+	snapshot := *exec
+	snapshot.input = nil
+	snapshot.ready = nil
+	for i, queued := range p.queue {
+		if queued == exec {
+			snapshot.QueuePosition = i + 1
+			break
+		}
+	}
+	return snapshot
+}
@@ -0,0 +1,51 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+)
+
+// HoldFunc returns until when the submitted and scheduled executions of a
+// tenant's pipelines are held and why, or a zero time if they are not
+type HoldFunc func(tenantID string) (until time.Time, reason string)
+
+// SetHold sets the function holding the submitted and scheduled executions
+// of tenants, e.g. during maintenance windows. A held execution is pushed
+// back to the end of the hold; executions run with ExecutePipeline, and
+// those already queued for admission, are not held.
+func (po *PipelineOrchestrator) SetHold(hold HoldFunc) {
+	po.mu.Lock()
+	defer po.mu.Unlock()
+	po.hold = hold
+}
+
+// heldUntil returns until when the executions of a tenant are held and
+// why, or a zero time if they are not
+func (po *PipelineOrchestrator) heldUntil(tenantID string) (time.Time, string) {
+	po.mu.RLock()
+	hold := po.hold
+	po.mu.RUnlock()
+	if hold == nil {
+		return time.Time{}, ""
+	}
+	until, reason := hold(tenantID)
+	if !until.After(time.Now()) {
+		return time.Time{}, ""
+	}
+	return until, reason
+}
+
+// holdJob returns a job that became due while its tenant's executions are
+// held to the queue, due when the hold ends, and reports whether it did
+func (po *PipelineOrchestrator) holdJob(job Job, pipeline *Pipeline) bool {
+	until, reason := po.heldUntil(pipeline.TenantID)
+	if until.IsZero() {
+		return false
+	}
+	if job.exec != nil && !pipeline.hold(job.exec, until, reason) {
+		return false
+	}
+	job.HeldBy = reason
+	po.queue.Retry(context.Background(), job, until)
+	return true
+}
@@ -0,0 +1,831 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/drift"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/forecast"
+)
+
+// Concept names used to classify atoms produced by the infrastructure stages
+const (
+	AnomalyConcept             = "Anomaly"
+	RemediationProposalConcept = "RemediationProposal"
+
+	affectsPredicate     = "affects"
+	remediatesPredicate  = "remediates"
+	errorBudgetPredicate = "error_budget_remaining"
+	blockedByPredicate   = "blocked_by_error_budget"
+)
+
+// registerInfraStages adds the infrastructure stage library to a registry
+func registerInfraStages(r *StageRegistry) {
+	r.Register("metric-ingest", func(sc StageContext, params StageParams) (PipelineStage, error) {
+		stage, err := NewMetricIngestStage(sc.AtomSpace, sc.TenantID, params)
+		if err != nil {
+			return nil, err
+		}
+		stage.series = sc.Series
+		return stage, nil
+	})
+	r.Register("topology-sync", func(sc StageContext, params StageParams) (PipelineStage, error) {
+		return NewTopologySyncStage(sc.AtomSpace, sc.TenantID, params)
+	})
+	r.Register("anomaly-score", func(sc StageContext, params StageParams) (PipelineStage, error) {
+		return NewAnomalyScoreStage(sc.AtomSpace, sc.TenantID, params.Float("threshold", 2.0), params.Int("min_samples", 3)), nil
+	})
+	r.Register("remediation-proposal", func(sc StageContext, params StageParams) (PipelineStage, error) {
+		stage := NewRemediationProposalStage(sc.AtomSpace, sc.TenantID, params.StringMap("actions"), params.String("default_action", "investigate"))
+		stage.minErrorBudget = params.Float("min_error_budget", 0)
+		return stage, nil
+	})
+	r.Register("report", func(sc StageContext, params StageParams) (PipelineStage, error) {
+		return NewReportStage(params.String("title", "Pipeline Report"), ReportFormat(params.String("format", string(ReportFormatJSON))), params.Int("limit", 0))
+	})
+}
+
+// upsertAtom adds an atom or, if it already exists, refreshes its truth
+// value. The returned atom carries the new truth value.
+func upsertAtom(space atomspace.AtomSpaceInterface, atom atomspace.Atom) (atomspace.Atom, error) {
+	existing, err := space.GetAtom(atom.GetID(), atom.GetTenantID())
+	if err != nil {
+		if err := space.AddAtom(atom); err != nil {
+			return nil, err
+		}
+		return atom, nil
+	}
+
+	tv := atom.GetTruthValue()
+	if err := space.UpdateAtom(existing.GetID(), existing.GetTenantID(), func(a atomspace.Atom) error {
+		a.SetTruthValue(tv)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return atom, nil
+}
+
+// upsertNode gets or creates a node with the given truth value
+func upsertNode(space atomspace.AtomSpaceInterface, tenantID string, atomType atomspace.AtomType, name string, tv atomspace.TruthValue) (atomspace.Atom, error) {
+	node := atomspace.NewNode(atomspace.GenerateAtomID(atomType, name, nil), name, tenantID, atomType)
+	node.SetTruthValue(tv)
+	return upsertAtom(space, node)
+}
+
+// upsertLink gets or creates a link with the given truth value
+func upsertLink(space atomspace.AtomSpaceInterface, tenantID string, atomType atomspace.AtomType, name string, outgoing []atomspace.Atom, tv atomspace.TruthValue) (atomspace.Atom, error) {
+	link := atomspace.NewLink(atomspace.GenerateAtomID(atomType, name, outgoing), name, tenantID, atomType, outgoing)
+	link.SetTruthValue(tv)
+	return upsertAtom(space, link)
+}
+
+// upsertRelation records predicate(args...) as an EvaluationLink whose
+// outgoing set is the predicate node followed by the arguments
+func upsertRelation(space atomspace.AtomSpaceInterface, tenantID, predicate string, args []atomspace.Atom, tv atomspace.TruthValue) (atomspace.Atom, error) {
+	pred, err := upsertNode(space, tenantID, atomspace.PredicateNodeType, predicate, atomspace.TruthValue{Strength: 1.0, Confidence: 1.0})
+	if err != nil {
+		return nil, err
+	}
+	return upsertLink(space, tenantID, atomspace.EvaluationLinkType, predicate, append([]atomspace.Atom{pred}, args...), tv)
+}
+
+// classify links an atom to a category concept with an InheritanceLink
+func classify(space atomspace.AtomSpaceInterface, tenantID string, atom atomspace.Atom, category string) error {
+	concept, err := upsertNode(space, tenantID, atomspace.ConceptNodeType, category, atomspace.TruthValue{Strength: 1.0, Confidence: 1.0})
+	if err != nil {
+		return err
+	}
+	_, err = upsertLink(space, tenantID, atomspace.InheritanceLinkType, "inheritance", []atomspace.Atom{atom, concept}, atomspace.TruthValue{Strength: 1.0, Confidence: 0.9})
+	return err
+}
+
+// relationArgs returns the predicate name and arguments of an EvaluationLink
+// created by upsertRelation
+func relationArgs(atom atomspace.Atom) (string, []atomspace.Atom, bool) {
+	link, ok := atom.(*atomspace.Link)
+	if !ok || link.GetType() != atomspace.EvaluationLinkType {
+		return "", nil, false
+	}
+	outgoing := link.GetOutgoing()
+	if len(outgoing) < 2 || outgoing[0].GetType() != atomspace.PredicateNodeType {
+		return "", nil, false
+	}
+	return outgoing[0].GetName(), outgoing[1:], true
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// getJSON fetches a URL and decodes its JSON body into out
+func getJSON(ctx context.Context, client *http.Client, rawURL, token string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("GET %s returned status %d", rawURL, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// ============================================================================
+// MetricIngestStage
+// ============================================================================
+
+// MetricIngestStage runs an instant query against a Prometheus server and
+// records each returned sample as metric(subject) with the sample value,
+// divided by scale and clamped to [0,1], as its strength. With a series
+// store, raw values are also appended to the series "<metric>:<subject>" for
+// forecasting.
+type MetricIngestStage struct {
+	atomSpace  atomspace.AtomSpaceInterface
+	tenantID   string
+	serverURL  string
+	query      string
+	metric     string
+	label      string
+	prefix     string
+	scale      float64
+	confidence float64
+	client     *http.Client
+	series     *forecast.Store
+}
+
+// NewMetricIngestStage creates a metric ingestion stage. Recognised params:
+// url and query (required), metric (predicate name, defaults to the query),
+// label (series label naming the subject, default "instance"), prefix,
+// scale (default 1), confidence (default 0.9) and timeout in seconds.
+func NewMetricIngestStage(atomSpace atomspace.AtomSpaceInterface, tenantID string, params StageParams) (*MetricIngestStage, error) {
+	s := &MetricIngestStage{
+		atomSpace:  atomSpace,
+		tenantID:   tenantID,
+		serverURL:  strings.TrimRight(params.String("url", ""), "/"),
+		query:      params.String("query", ""),
+		label:      params.String("label", "instance"),
+		prefix:     params.String("prefix", ""),
+		scale:      params.Float("scale", 1.0),
+		confidence: params.Float("confidence", 0.9),
+		client:     &http.Client{Timeout: params.Duration("timeout", 10*time.Second)},
+	}
+	s.metric = params.String("metric", s.query)
+
+	if s.serverURL == "" || s.query == "" {
+		return nil, fmt.Errorf("metric-ingest requires url and query")
+	}
+	if s.scale <= 0 {
+		return nil, fmt.Errorf("metric-ingest scale must be positive")
+	}
+	return s, nil
+}
+
+func (s *MetricIngestStage) GetName() string {
+	return "metric-ingest"
+}
+
+func (s *MetricIngestStage) InputType() DataType {
+	return DataTypeNone
+}
+
+func (s *MetricIngestStage) OutputType() DataType {
+	return DataTypeAtoms
+}
+
+func (s *MetricIngestStage) Execute(ctx context.Context, input interface{}) (interface{}, error) {
+	var resp struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			ResultType string `json:"resultType"`
+			Result     []struct {
+				Metric map[string]string `json:"metric"`
+				Value  []interface{}     `json:"value"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+
+	queryURL := s.serverURL + "/api/v1/query?query=" + url.QueryEscape(s.query)
+	if err := getJSON(ctx, s.client, queryURL, "", &resp); err != nil {
+		return nil, fmt.Errorf("prometheus query failed: %w", err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed: %s", resp.Error)
+	}
+	if resp.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("unsupported prometheus result type: %s", resp.Data.ResultType)
+	}
+
+	atoms := make([]atomspace.Atom, 0, len(resp.Data.Result))
+	for _, sample := range resp.Data.Result {
+		subjectName, ok := sample.Metric[s.label]
+		if !ok || len(sample.Value) != 2 {
+			continue
+		}
+		raw, ok := sample.Value[1].(string)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(value) {
+			continue
+		}
+
+		subject, err := upsertNode(s.atomSpace, s.tenantID, atomspace.ConceptNodeType, s.prefix+subjectName, atomspace.TruthValue{Strength: 1.0, Confidence: 0.9})
+		if err != nil {
+			return nil, err
+		}
+		relation, err := upsertRelation(s.atomSpace, s.tenantID, s.metric, []atomspace.Atom{subject}, atomspace.TruthValue{
+			Strength:   clamp01(value / s.scale),
+			Confidence: s.confidence,
+		})
+		if err != nil {
+			return nil, err
+		}
+		atoms = append(atoms, relation)
+
+		if s.series != nil {
+			at := time.Now()
+			if timestamp, ok := sample.Value[0].(float64); ok {
+				sec, frac := math.Modf(timestamp)
+				at = time.Unix(int64(sec), int64(frac*1e9))
+			}
+			s.series.Append(s.tenantID, s.metric+":"+s.prefix+subjectName, forecast.Sample{Time: at, Value: value})
+		}
+	}
+
+	return atoms, nil
+}
+
+// ============================================================================
+// TopologySyncStage
+// ============================================================================
+
+// TopologySyncStage mirrors Kubernetes nodes, namespaces and pods into the
+// AtomSpace, relating pods to the node they run on and their namespace.
+// Nodes are also related to their cloud instance attributes with observes
+// links.
+type TopologySyncStage struct {
+	atomSpace atomspace.AtomSpaceInterface
+	tenantID  string
+	apiServer string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+// NewTopologySyncStage creates a topology sync stage. Recognised params:
+// api_server (required), token, namespace (all namespaces when empty) and
+// timeout in seconds.
+func NewTopologySyncStage(atomSpace atomspace.AtomSpaceInterface, tenantID string, params StageParams) (*TopologySyncStage, error) {
+	s := &TopologySyncStage{
+		atomSpace: atomSpace,
+		tenantID:  tenantID,
+		apiServer: strings.TrimRight(params.String("api_server", ""), "/"),
+		token:     params.String("token", ""),
+		namespace: params.String("namespace", ""),
+		client:    &http.Client{Timeout: params.Duration("timeout", 10*time.Second)},
+	}
+
+	if s.apiServer == "" {
+		return nil, fmt.Errorf("topology-sync requires api_server")
+	}
+	return s, nil
+}
+
+func (s *TopologySyncStage) GetName() string {
+	return "topology-sync"
+}
+
+func (s *TopologySyncStage) InputType() DataType {
+	return DataTypeNone
+}
+
+func (s *TopologySyncStage) OutputType() DataType {
+	return DataTypeAtoms
+}
+
+type k8sList struct {
+	Items []struct {
+		Metadata struct {
+			Name      string            `json:"name"`
+			Namespace string            `json:"namespace"`
+			Labels    map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			NodeName   string `json:"nodeName"`
+			ProviderID string `json:"providerID"`
+		} `json:"spec"`
+	} `json:"items"`
+}
+
+// nodeObservations returns the cloud attributes of a node as drift
+// detection compares them with declared resources: the instance ID from the
+// provider ID, e.g. "aws:///us-east-1a/i-0abc" or "gce://project/zone/name",
+// and the instance type and zone from the well-known labels, named as the
+// provider's Terraform resources name them
+func nodeObservations(providerID string, labels map[string]string) map[string]string {
+	result := make(map[string]string)
+	provider := ""
+	if i := strings.Index(providerID, "://"); i > 0 {
+		provider = providerID[:i]
+		if j := strings.LastIndex(providerID, "/"); j > i+2 && j < len(providerID)-1 {
+			result["id"] = providerID[j+1:]
+		}
+	}
+
+	typeKey, zoneKey := "instance_type", "availability_zone"
+	if provider == "gce" {
+		typeKey, zoneKey = "machine_type", "zone"
+	}
+	if v := labels["node.kubernetes.io/instance-type"]; v != "" {
+		result[typeKey] = v
+	}
+	if v := labels["topology.kubernetes.io/zone"]; v != "" {
+		result[zoneKey] = v
+	}
+	return result
+}
+
+func (s *TopologySyncStage) Execute(ctx context.Context, input interface{}) (interface{}, error) {
+	var nodes, pods k8sList
+
+	if err := getJSON(ctx, s.client, s.apiServer+"/api/v1/nodes", s.token, &nodes); err != nil {
+		return nil, fmt.Errorf("listing nodes failed: %w", err)
+	}
+	podsURL := s.apiServer + "/api/v1/pods"
+	if s.namespace != "" {
+		podsURL = s.apiServer + "/api/v1/namespaces/" + url.PathEscape(s.namespace) + "/pods"
+	}
+	if err := getJSON(ctx, s.client, podsURL, s.token, &pods); err != nil {
+		return nil, fmt.Errorf("listing pods failed: %w", err)
+	}
+
+	tv := atomspace.TruthValue{Strength: 1.0, Confidence: 0.95}
+	atoms := make([]atomspace.Atom, 0)
+
+	entity := func(name, category string) (atomspace.Atom, error) {
+		atom, err := upsertNode(s.atomSpace, s.tenantID, atomspace.ConceptNodeType, name, tv)
+		if err != nil {
+			return nil, err
+		}
+		if err := classify(s.atomSpace, s.tenantID, atom, category); err != nil {
+			return nil, err
+		}
+		atoms = append(atoms, atom)
+		return atom, nil
+	}
+
+	nodeAtoms := make(map[string]atomspace.Atom)
+	for _, item := range nodes.Items {
+		atom, err := entity("node/"+item.Metadata.Name, "KubernetesNode")
+		if err != nil {
+			return nil, err
+		}
+		nodeAtoms[item.Metadata.Name] = atom
+
+		for key, value := range nodeObservations(item.Spec.ProviderID, item.Metadata.Labels) {
+			attr, err := upsertNode(s.atomSpace, s.tenantID, atomspace.ConceptNodeType, drift.AttributeName(key, value), atomspace.TruthValue{Strength: 1.0, Confidence: 1.0})
+			if err != nil {
+				return nil, err
+			}
+			observes, err := upsertRelation(s.atomSpace, s.tenantID, drift.ObservesPredicate, []atomspace.Atom{atom, attr}, tv)
+			if err != nil {
+				return nil, err
+			}
+			atoms = append(atoms, observes)
+		}
+	}
+
+	namespaceAtoms := make(map[string]atomspace.Atom)
+	for _, item := range pods.Items {
+		ns := item.Metadata.Namespace
+		nsAtom, exists := namespaceAtoms[ns]
+		if !exists {
+			var err error
+			if nsAtom, err = entity("namespace/"+ns, "Namespace"); err != nil {
+				return nil, err
+			}
+			namespaceAtoms[ns] = nsAtom
+		}
+
+		pod, err := entity("pod/"+ns+"/"+item.Metadata.Name, "Pod")
+		if err != nil {
+			return nil, err
+		}
+
+		memberOf, err := upsertRelation(s.atomSpace, s.tenantID, "member_of", []atomspace.Atom{pod, nsAtom}, tv)
+		if err != nil {
+			return nil, err
+		}
+		atoms = append(atoms, memberOf)
+
+		if node, ok := nodeAtoms[item.Spec.NodeName]; ok {
+			runsOn, err := upsertRelation(s.atomSpace, s.tenantID, "runs_on", []atomspace.Atom{pod, node}, tv)
+			if err != nil {
+				return nil, err
+			}
+			atoms = append(atoms, runsOn)
+		}
+	}
+
+	return atoms, nil
+}
+
+// ============================================================================
+// AnomalyScoreStage
+// ============================================================================
+
+// AnomalyScoreStage scores metric relations against their peers. Relations
+// are grouped by predicate and those whose strength deviates from the group
+// mean by more than threshold standard deviations are reported as anomaly
+// concepts.
+type AnomalyScoreStage struct {
+	atomSpace  atomspace.AtomSpaceInterface
+	tenantID   string
+	threshold  float64
+	minSamples int
+}
+
+func NewAnomalyScoreStage(atomSpace atomspace.AtomSpaceInterface, tenantID string, threshold float64, minSamples int) *AnomalyScoreStage {
+	if threshold <= 0 {
+		threshold = 2.0
+	}
+	if minSamples < 2 {
+		minSamples = 2
+	}
+	return &AnomalyScoreStage{
+		atomSpace:  atomSpace,
+		tenantID:   tenantID,
+		threshold:  threshold,
+		minSamples: minSamples,
+	}
+}
+
+func (s *AnomalyScoreStage) GetName() string {
+	return "anomaly-score"
+}
+
+func (s *AnomalyScoreStage) InputType() DataType {
+	return DataTypeAtoms
+}
+
+func (s *AnomalyScoreStage) OutputType() DataType {
+	return DataTypeAtoms
+}
+
+func (s *AnomalyScoreStage) Execute(ctx context.Context, input interface{}) (interface{}, error) {
+	atoms, ok := input.([]atomspace.Atom)
+	if !ok {
+		return nil, fmt.Errorf("expected []Atom, got %T", input)
+	}
+
+	groups := make(map[string][]atomspace.Atom)
+	var predicates []string
+	for _, atom := range atoms {
+		predicate, args, ok := relationArgs(atom)
+		if !ok || len(args) != 1 {
+			continue
+		}
+		if _, exists := groups[predicate]; !exists {
+			predicates = append(predicates, predicate)
+		}
+		groups[predicate] = append(groups[predicate], atom)
+	}
+
+	anomalies := make([]atomspace.Atom, 0)
+	for _, predicate := range predicates {
+		group := groups[predicate]
+		if len(group) < s.minSamples {
+			continue
+		}
+
+		var sum, sumSq float64
+		for _, atom := range group {
+			v := atom.GetTruthValue().Strength
+			sum += v
+			sumSq += v * v
+		}
+		n := float64(len(group))
+		mean := sum / n
+		stddev := math.Sqrt(math.Max(0, sumSq/n-mean*mean))
+		if stddev == 0 {
+			continue
+		}
+
+		for _, atom := range group {
+			z := math.Abs(atom.GetTruthValue().Strength-mean) / stddev
+			if z < s.threshold {
+				continue
+			}
+
+			_, args, _ := relationArgs(atom)
+			subject := args[0]
+			anomaly, err := upsertNode(s.atomSpace, s.tenantID, atomspace.ConceptNodeType,
+				fmt.Sprintf("anomaly:%s:%s", predicate, subject.GetName()),
+				atomspace.TruthValue{Strength: clamp01(z / (2 * s.threshold)), Confidence: 1 - 1/n})
+			if err != nil {
+				return nil, err
+			}
+			if err := classify(s.atomSpace, s.tenantID, anomaly, AnomalyConcept); err != nil {
+				return nil, err
+			}
+			if _, err := upsertRelation(s.atomSpace, s.tenantID, affectsPredicate, []atomspace.Atom{anomaly, subject, atom}, anomaly.GetTruthValue()); err != nil {
+				return nil, err
+			}
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+
+	return anomalies, nil
+}
+
+// ============================================================================
+// RemediationProposalStage
+// ============================================================================
+
+// RemediationProposalStage turns anomaly concepts into remediation proposal
+// concepts. The proposed action is looked up by the anomalous metric in
+// actions, falling back to defaultAction. With minErrorBudget set, proposals
+// for subjects whose error_budget_remaining is below it are recorded as
+// blocked_by_error_budget(proposal, subject) and left out of the output.
+type RemediationProposalStage struct {
+	atomSpace      atomspace.AtomSpaceInterface
+	tenantID       string
+	actions        map[string]string
+	defaultAction  string
+	minErrorBudget float64
+}
+
+func NewRemediationProposalStage(atomSpace atomspace.AtomSpaceInterface, tenantID string, actions map[string]string, defaultAction string) *RemediationProposalStage {
+	if actions == nil {
+		actions = make(map[string]string)
+	}
+	return &RemediationProposalStage{
+		atomSpace:     atomSpace,
+		tenantID:      tenantID,
+		actions:       actions,
+		defaultAction: defaultAction,
+	}
+}
+
+func (s *RemediationProposalStage) GetName() string {
+	return "remediation-proposal"
+}
+
+func (s *RemediationProposalStage) InputType() DataType {
+	return DataTypeAtoms
+}
+
+func (s *RemediationProposalStage) OutputType() DataType {
+	return DataTypeAtoms
+}
+
+func (s *RemediationProposalStage) Execute(ctx context.Context, input interface{}) (interface{}, error) {
+	atoms, ok := input.([]atomspace.Atom)
+	if !ok {
+		return nil, fmt.Errorf("expected []Atom, got %T", input)
+	}
+
+	anomalies := make(map[string]bool, len(atoms))
+	for _, atom := range atoms {
+		anomalies[atom.GetID()] = true
+	}
+
+	// Anomalies are resolved to their subject and metric through the
+	// affects(anomaly, subject, metric relation) links of AnomalyScoreStage
+	affects := s.atomSpace.QueryAtoms(s.tenantID, func(a atomspace.Atom) bool {
+		predicate, args, ok := relationArgs(a)
+		return ok && predicate == affectsPredicate && len(args) == 3 && anomalies[args[0].GetID()]
+	})
+
+	proposals := make([]atomspace.Atom, 0, len(affects))
+	for _, link := range affects {
+		_, args, _ := relationArgs(link)
+		anomaly, subject := args[0], args[1]
+		metric, _, _ := relationArgs(args[2])
+
+		action, ok := s.actions[metric]
+		if !ok {
+			action = s.defaultAction
+		}
+
+		tv := anomaly.GetTruthValue()
+		proposal, err := upsertNode(s.atomSpace, s.tenantID, atomspace.ConceptNodeType,
+			fmt.Sprintf("remediation:%s:%s", action, subject.GetName()),
+			atomspace.TruthValue{Strength: tv.Strength, Confidence: tv.Confidence * 0.9})
+		if err != nil {
+			return nil, err
+		}
+		if err := classify(s.atomSpace, s.tenantID, proposal, RemediationProposalConcept); err != nil {
+			return nil, err
+		}
+		if _, err := upsertRelation(s.atomSpace, s.tenantID, remediatesPredicate, []atomspace.Atom{proposal, anomaly}, proposal.GetTruthValue()); err != nil {
+			return nil, err
+		}
+
+		if budget, ok := s.errorBudget(subject); ok && budget < s.minErrorBudget {
+			if _, err := upsertRelation(s.atomSpace, s.tenantID, blockedByPredicate, []atomspace.Atom{proposal, subject}, atomspace.TruthValue{Strength: 1 - budget, Confidence: 0.9}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		proposals = append(proposals, proposal)
+	}
+
+	return proposals, nil
+}
+
+// errorBudget returns the remaining error budget recorded for a subject
+func (s *RemediationProposalStage) errorBudget(subject atomspace.Atom) (float64, bool) {
+	if s.minErrorBudget <= 0 {
+		return 0, false
+	}
+	pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, errorBudgetPredicate, nil), errorBudgetPredicate, s.tenantID, atomspace.PredicateNodeType)
+	id := atomspace.GenerateAtomID(atomspace.EvaluationLinkType, errorBudgetPredicate, []atomspace.Atom{pred, subject})
+	link, err := s.atomSpace.GetAtom(id, s.tenantID)
+	if err != nil {
+		return 0, false
+	}
+	return link.GetTruthValue().Strength, true
+}
+
+// ============================================================================
+// ReportStage
+// ============================================================================
+
+// ReportFormat selects how a report is rendered
+type ReportFormat string
+
+const (
+	ReportFormatJSON     ReportFormat = "json"
+	ReportFormatMarkdown ReportFormat = "markdown"
+)
+
+// ReportItem summarises one atom of a report
+type ReportItem struct {
+	AtomID     string  `json:"atom_id"`
+	Name       string  `json:"name"`
+	Type       string  `json:"type"`
+	Strength   float64 `json:"strength"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Report is the rendered summary produced by ReportStage
+type Report struct {
+	Title       string       `json:"title"`
+	Format      ReportFormat `json:"format"`
+	GeneratedAt time.Time    `json:"generated_at"`
+	AtomCount   int          `json:"atom_count"`
+	Items       []ReportItem `json:"items"`
+	Content     string       `json:"content"`
+}
+
+// ReportStage renders its input atoms, strongest first, into a Report,
+// which it attaches to the execution as report.json or report.md
+type ReportStage struct {
+	title  string
+	format ReportFormat
+	limit  int
+}
+
+func NewReportStage(title string, format ReportFormat, limit int) (*ReportStage, error) {
+	if format != ReportFormatJSON && format != ReportFormatMarkdown {
+		return nil, fmt.Errorf("unsupported report format: %s", format)
+	}
+	return &ReportStage{
+		title:  title,
+		format: format,
+		limit:  limit,
+	}, nil
+}
+
+func (s *ReportStage) GetName() string {
+	return "report"
+}
+
+func (s *ReportStage) InputType() DataType {
+	return DataTypeAtoms
+}
+
+func (s *ReportStage) OutputType() DataType {
+	return DataTypeReport
+}
+
+func (s *ReportStage) Execute(ctx context.Context, input interface{}) (interface{}, error) {
+	atoms, ok := input.([]atomspace.Atom)
+	if !ok {
+		return nil, fmt.Errorf("expected []Atom, got %T", input)
+	}
+
+	items := make([]ReportItem, len(atoms))
+	for i, atom := range atoms {
+		tv := atom.GetTruthValue()
+		items[i] = ReportItem{
+			AtomID:     atom.GetID(),
+			Name:       atom.GetName(),
+			Type:       atomTypeName(atom.GetType()),
+			Strength:   tv.Strength,
+			Confidence: tv.Confidence,
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Strength > items[j].Strength
+	})
+	if s.limit > 0 && len(items) > s.limit {
+		items = items[:s.limit]
+	}
+
+	report := &Report{
+		Title:       s.title,
+		Format:      s.format,
+		GeneratedAt: time.Now(),
+		AtomCount:   len(atoms),
+		Items:       items,
+	}
+
+	name, contentType := "report.json", "application/json"
+	switch s.format {
+	case ReportFormatMarkdown:
+		report.Content = renderMarkdown(report)
+		name, contentType = "report.md", "text/markdown"
+	default:
+		content, err := json.Marshal(items)
+		if err != nil {
+			return nil, err
+		}
+		report.Content = string(content)
+	}
+
+	// Kept with the execution too where artifacts are stored; a report
+	// over the size limits is still passed on
+	AttachArtifact(ctx, name, contentType, []byte(report.Content))
+	return report, nil
+}
+
+func renderMarkdown(report *Report) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\n", report.Title)
+	fmt.Fprintf(&b, "Generated %s from %d atoms.\n\n", report.GeneratedAt.UTC().Format(time.RFC3339), report.AtomCount)
+	if len(report.Items) == 0 {
+		b.WriteString("No findings.\n")
+		return b.String()
+	}
+	b.WriteString("| Name | Type | Strength | Confidence |\n")
+	b.WriteString("|------|------|----------|------------|\n")
+	for _, item := range report.Items {
+		fmt.Fprintf(&b, "| %s | %s | %.2f | %.2f |\n", strings.ReplaceAll(item.Name, "|", "\\|"), item.Type, item.Strength, item.Confidence)
+	}
+	return b.String()
+}
+
+func atomTypeName(t atomspace.AtomType) string {
+	switch t {
+	case atomspace.ConceptNodeType:
+		return "concept"
+	case atomspace.PredicateNodeType:
+		return "predicate"
+	case atomspace.VariableNodeType:
+		return "variable"
+	case atomspace.NumberNodeType:
+		return "number"
+	case atomspace.GroundedPredicateNodeType:
+		return "grounded_predicate"
+	case atomspace.GroundedSchemaNodeType:
+		return "grounded_schema"
+	case atomspace.InheritanceLinkType:
+		return "inheritance"
+	case atomspace.SimilarityLinkType:
+		return "similarity"
+	case atomspace.ExecutionLinkType:
+		return "execution"
+	case atomspace.EvaluationLinkType:
+		return "evaluation"
+	case atomspace.AliasLinkType:
+		return "alias"
+	case atomspace.LinkType:
+		return "link"
+	}
+	return "node"
+}
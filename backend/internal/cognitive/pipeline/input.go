@@ -0,0 +1,145 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// MaxInputAtoms bounds the atoms an execution request may carry inline or
+// load from a dataset
+const MaxInputAtoms = 100000
+
+// Input is the input of an execution request. One source is set: atoms
+// inline, a saved query of the pipeline's tenant run when the request is
+// made, or a dataset stored as an artifact of an earlier execution.
+type Input struct {
+	Atoms   []InputAtom `json:"atoms,omitempty"`
+	Query   string      `json:"query,omitempty"`
+	Dataset *DatasetRef `json:"dataset,omitempty"`
+}
+
+// InputAtom is a node given as pipeline input, like a node of a bulk import
+type InputAtom struct {
+	Type       int     `json:"type"`
+	Name       string  `json:"name"`
+	Strength   float64 `json:"strength"`
+	Confidence float64 `json:"confidence"`
+}
+
+// DatasetRef names an artifact holding input atoms, as {"atoms": [...]} or
+// newline-delimited atoms
+type DatasetRef struct {
+	PipelineID  string `json:"pipeline_id"`
+	ExecutionID string `json:"execution_id"`
+	Name        string `json:"name"`
+}
+
+// Validate checks that exactly one source is set
+func (in *Input) Validate() error {
+	sources := 0
+	if in.Atoms != nil {
+		sources++
+	}
+	if in.Query != "" {
+		sources++
+	}
+	if in.Dataset != nil {
+		sources++
+		if in.Dataset.PipelineID == "" || in.Dataset.ExecutionID == "" || in.Dataset.Name == "" {
+			return fmt.Errorf("dataset needs pipeline_id, execution_id and name")
+		}
+	}
+	if sources != 1 {
+		return fmt.Errorf("input needs exactly one of atoms, query and dataset")
+	}
+	if len(in.Atoms) > MaxInputAtoms {
+		return fmt.Errorf("input has %d atoms, more than %d", len(in.Atoms), MaxInputAtoms)
+	}
+	return nil
+}
+
+// BuildAtoms creates the nodes of a tenant that input atoms describe
+func BuildAtoms(tenantID string, inputs []InputAtom) ([]atomspace.Atom, error) {
+	atoms := make([]atomspace.Atom, 0, len(inputs))
+	for i, in := range inputs {
+		atomType := atomspace.AtomType(in.Type)
+		if atomspace.IsLinkType(atomType) {
+			return nil, fmt.Errorf("input atom %d: only nodes may be given as input", i)
+		}
+		if in.Name == "" {
+			return nil, fmt.Errorf("input atom %d: name is required", i)
+		}
+		node := atomspace.NewNode(atomspace.GenerateAtomID(atomType, in.Name, nil), in.Name, tenantID, atomType)
+		if in.Strength > 0 || in.Confidence > 0 {
+			node.SetTruthValue(atomspace.TruthValue{Strength: in.Strength, Confidence: in.Confidence})
+		}
+		atoms = append(atoms, node)
+	}
+	return atoms, nil
+}
+
+// DecodeDataset decodes the input atoms of a dataset, {"atoms": [...]} or
+// newline-delimited atoms
+func DecodeDataset(data []byte) ([]InputAtom, error) {
+	var doc map[string]json.RawMessage
+	if json.Unmarshal(data, &doc) == nil {
+		if raw, ok := doc["atoms"]; ok {
+			var atoms []InputAtom
+			if err := json.Unmarshal(raw, &atoms); err != nil {
+				return nil, fmt.Errorf("invalid dataset: %w", err)
+			}
+			return checkDatasetSize(atoms)
+		}
+	}
+
+	var atoms []InputAtom
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var a InputAtom
+		if err := dec.Decode(&a); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid dataset: %w", err)
+		}
+		atoms = append(atoms, a)
+		if len(atoms) > MaxInputAtoms {
+			break
+		}
+	}
+	return checkDatasetSize(atoms)
+}
+
+func checkDatasetSize(atoms []InputAtom) ([]InputAtom, error) {
+	if len(atoms) > MaxInputAtoms {
+		return nil, fmt.Errorf("dataset has more than %d atoms", MaxInputAtoms)
+	}
+	return atoms, nil
+}
+
+// ExpectedInput returns the type of input executions of the pipeline
+// start with: its declared input type, or else what its first stage takes
+func (p *Pipeline) ExpectedInput() DataType {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.InputType != DataTypeAny || len(p.Stages) == 0 {
+		return p.InputType
+	}
+	in, _ := stageTypes(p.Stages[0])
+	return in
+}
+
+// ConvertInput converts atoms to the input the pipeline's executions start
+// with
+func (p *Pipeline) ConvertInput(atoms []atomspace.Atom) (interface{}, error) {
+	switch expected := p.ExpectedInput(); expected {
+	case DataTypeAtoms, DataTypeAny:
+		return atoms, nil
+	default:
+		return nil, fmt.Errorf("pipeline %s takes %s input, not atoms", p.ID, expected)
+	}
+}
@@ -8,7 +8,9 @@ import (
 
 	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
 	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/attention"
 	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/retry"
 )
 
 // PipelineStage represents a stage in the cognitive pipeline
@@ -23,10 +25,30 @@ type Pipeline struct {
 	Name        string
 	TenantID    string
 	Stages      []PipelineStage
+	InputType   DataType // Type of the input the pipeline is executed with
+	Spec        *PipelineSpec // Declaration the pipeline was built from; nil for pipelines assembled in code
 	State       PipelineState
 	CreatedAt   time.Time
 	StartedAt   time.Time
 	CompletedAt time.Time
+	
+	// Concurrency control: at most MaxConcurrency executions run at once and
+	// the rest wait in FIFO order; 0 leaves executions unlimited
+	MaxConcurrency int
+	running        int
+	queue          []*Execution
+	executions     map[string]*Execution
+	finished       []string // IDs of finished executions, oldest first
+	executionSeq   int64
+	
+	// Retry behaviour of stages, overridable per stage name
+	RetryPolicy   retry.Policy
+	stageRetry    map[string]retry.Policy
+	retries       int64
+	stageRetries  map[string]int64
+	
+	artifacts   ArtifactSink // Stores the artifacts stages attach; none if nil
+	
 	mu          sync.RWMutex
 }
 
@@ -48,55 +70,159 @@ func NewPipeline(id, name, tenantID string) *Pipeline {
 		Name:      name,
 		TenantID:  tenantID,
 		Stages:    make([]PipelineStage, 0),
+		InputType: DataTypeAny,
 		State:     PipelineStateCreated,
 		CreatedAt: time.Now(),
+		executions:     make(map[string]*Execution),
+		RetryPolicy:    retry.NoRetry(),
+		stageRetry:     make(map[string]retry.Policy),
+		stageRetries:   make(map[string]int64),
 	}
 }
 
-// AddStage adds a stage to the pipeline
-func (p *Pipeline) AddStage(stage PipelineStage) {
+// AddStage adds a stage to the pipeline. The stage is rejected if its
+// declared input type is incompatible with the output of the previous stage.
+func (p *Pipeline) AddStage(stage PipelineStage) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	p.Stages = append(p.Stages, stage)
+	
+	stages := append(append([]PipelineStage{}, p.Stages...), stage)
+	if _, err := validateStages(p.InputType, stages); err != nil {
+		return fmt.Errorf("pipeline %s: %w", p.ID, err)
+	}
+	
+	p.Stages = stages
+	return nil
 }
 
-// Execute runs the pipeline
-func (p *Pipeline) Execute(ctx context.Context, initialInput interface{}) (interface{}, error) {
+// SetInputType declares the type of input the pipeline is executed with
+func (p *Pipeline) SetInputType(inputType DataType) error {
+	if !inputType.Valid() || inputType == DataTypeSame {
+		return fmt.Errorf("invalid pipeline input type: %s", inputType)
+	}
+	
 	p.mu.Lock()
-	p.State = PipelineStateRunning
-	p.StartedAt = time.Now()
-	p.mu.Unlock()
+	defer p.mu.Unlock()
+	
+	if _, err := validateStages(inputType, p.Stages); err != nil {
+		return fmt.Errorf("pipeline %s: %w", p.ID, err)
+	}
+	
+	p.InputType = inputType
+	return nil
+}
+
+// Validate checks that the stages form a type-correct chain
+func (p *Pipeline) Validate() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	
-	currentInput := initialInput
+	if _, err := validateStages(p.InputType, p.Stages); err != nil {
+		return fmt.Errorf("pipeline %s: %w", p.ID, err)
+	}
+	return nil
+}
+
+// Execute runs the pipeline, waiting for a free concurrency slot first
+func (p *Pipeline) Execute(ctx context.Context, initialInput interface{}) (interface{}, error) {
+	exec, err := p.Enqueue(initialInput)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Wait(ctx, exec); err != nil {
+		return nil, err
+	}
+	return p.Run(ctx, exec)
+}
+
+// Run executes the stages for an admitted execution and releases its
+// concurrency slot
+func (p *Pipeline) Run(ctx context.Context, exec *Execution) (interface{}, error) {
+	p.mu.RLock()
+	stages := append([]PipelineStage{}, p.Stages...)
+	policies := make([]retry.Policy, len(stages))
+	for i, stage := range stages {
+		policies[i] = p.retryPolicyLocked(stage.GetName())
+	}
+	currentInput := exec.input
+	artifacts := p.artifacts
+	p.mu.RUnlock()
 	
-	for i, stage := range p.Stages {
+	for i, stage := range stages {
 		select {
 		case <-ctx.Done():
-			p.mu.Lock()
-			p.State = PipelineStateFailed
-			p.mu.Unlock()
-			return nil, fmt.Errorf("pipeline execution cancelled at stage %d", i)
+			err := fmt.Errorf("pipeline execution cancelled at stage %d", i)
+			return nil, p.fail(exec, stage.GetName(), err)
 		default:
 		}
 		
-		output, err := stage.Execute(ctx, currentInput)
+		if in, _ := stageTypes(stage); in != DataTypeNone {
+			if err := checkValue(currentInput, in); err != nil {
+				err = fmt.Errorf("stage %s received invalid input: %w", stage.GetName(), err)
+				return nil, p.fail(exec, stage.GetName(), err)
+			}
+		}
+		
+		var output interface{}
+		retries, err := policies[i].Do(ctx, func(ctx context.Context) error {
+			var err error
+			output, err = stage.Execute(withArtifacts(ctx, artifacts, p, exec, stage.GetName()), currentInput)
+			return err
+		})
+		if retries > 0 {
+			p.recordRetries(exec, stage.GetName(), retries)
+		}
 		if err != nil {
-			p.mu.Lock()
-			p.State = PipelineStateFailed
-			p.CompletedAt = time.Now()
-			p.mu.Unlock()
-			return nil, fmt.Errorf("stage %s failed: %w", stage.GetName(), err)
+			if retries > 0 {
+				err = fmt.Errorf("stage %s failed after %d attempts: %w", stage.GetName(), retries+1, err)
+			} else {
+				err = fmt.Errorf("stage %s failed: %w", stage.GetName(), err)
+			}
+			return nil, p.fail(exec, stage.GetName(), err)
 		}
 		
 		currentInput = output
 	}
 	
+	p.release(exec, "", currentInput, nil)
+	return currentInput, nil
+}
+
+// SetRetryPolicy sets the retry policy of stages without their own policy
+func (p *Pipeline) SetRetryPolicy(policy retry.Policy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.RetryPolicy = policy
+}
+
+// SetStageRetryPolicy overrides the retry policy of the named stage
+func (p *Pipeline) SetStageRetryPolicy(stageName string, policy retry.Policy) {
 	p.mu.Lock()
-	p.State = PipelineStateCompleted
-	p.CompletedAt = time.Now()
-	p.mu.Unlock()
+	defer p.mu.Unlock()
+	p.stageRetry[stageName] = policy
+}
+
+func (p *Pipeline) retryPolicyLocked(stageName string) retry.Policy {
+	if policy, ok := p.stageRetry[stageName]; ok {
+		return policy
+	}
+	return p.RetryPolicy
+}
+
+// recordRetries accounts retries performed by a stage
+func (p *Pipeline) recordRetries(exec *Execution, stageName string, retries int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	
-	return currentInput, nil
+	exec.Retries += retries
+	p.retries += int64(retries)
+	p.stageRetries[stageName] += int64(retries)
+}
+
+// fail releases a failed execution and wraps its error
+func (p *Pipeline) fail(exec *Execution, stage string, err error) error {
+	p.release(exec, stage, nil, err)
+	return &ExecutionError{ExecutionID: exec.ID, Stage: stage, Err: err}
 }
 
 // GetStats returns pipeline statistics
@@ -111,12 +237,27 @@ func (p *Pipeline) GetStats() map[string]interface{} {
 		duration = time.Since(p.StartedAt)
 	}
 	
+	outputType, _ := validateStages(p.InputType, p.Stages)
+	
+	stageRetries := make(map[string]int64, len(p.stageRetries))
+	for name, count := range p.stageRetries {
+		stageRetries[name] = count
+	}
+	
 	return map[string]interface{}{
 		"id":           p.ID,
 		"name":         p.Name,
 		"tenant_id":    p.TenantID,
 		"state":        p.State,
 		"stages":       len(p.Stages),
+		"input_type":   p.InputType,
+		"output_type":  outputType,
+		"max_concurrency": p.MaxConcurrency,
+		"running":         p.running,
+		"queued":          len(p.queue),
+		"executions":      p.executionSeq,
+		"retries":         p.retries,
+		"stage_retries":   stageRetries,
 		"created_at":   p.CreatedAt,
 		"started_at":   p.StartedAt,
 		"completed_at": p.CompletedAt,
@@ -145,6 +286,14 @@ func (s *AtomIngestionStage) GetName() string {
 	return "atom-ingestion"
 }
 
+func (s *AtomIngestionStage) InputType() DataType {
+	return DataTypeAtoms
+}
+
+func (s *AtomIngestionStage) OutputType() DataType {
+	return DataTypeAtoms
+}
+
 func (s *AtomIngestionStage) Execute(ctx context.Context, input interface{}) (interface{}, error) {
 	atoms, ok := input.([]atomspace.Atom)
 	if !ok {
@@ -180,6 +329,14 @@ func (s *InferenceStage) GetName() string {
 	return "inference"
 }
 
+func (s *InferenceStage) InputType() DataType {
+	return DataTypeNone
+}
+
+func (s *InferenceStage) OutputType() DataType {
+	return DataTypeAtoms
+}
+
 func (s *InferenceStage) Execute(ctx context.Context, input interface{}) (interface{}, error) {
 	newAtoms, err := s.engine.RunInference(ctx, s.tenantID, s.maxIterations)
 	if err != nil {
@@ -189,16 +346,22 @@ func (s *InferenceStage) Execute(ctx context.Context, input interface{}) (interf
 	return newAtoms, nil
 }
 
-// AttentionAllocationStage allocates attention to atoms
+// AttentionAllocationStage allocates attention to atoms, following the
+// attention params of its tenant
 type AttentionAllocationStage struct {
 	atomSpace atomspace.AtomSpaceInterface
 	tenantID  string
+	params    func(tenantID string) attention.Params
 }
 
-func NewAttentionAllocationStage(atomSpace atomspace.AtomSpaceInterface, tenantID string) *AttentionAllocationStage {
+// NewAttentionAllocationStage creates an attention allocation stage reading
+// the tenant's params from params at each execution, or using the default
+// params if nil
+func NewAttentionAllocationStage(atomSpace atomspace.AtomSpaceInterface, tenantID string, params func(tenantID string) attention.Params) *AttentionAllocationStage {
 	return &AttentionAllocationStage{
 		atomSpace: atomSpace,
 		tenantID:  tenantID,
+		params:    params,
 	}
 }
 
@@ -206,22 +369,24 @@ func (s *AttentionAllocationStage) GetName() string {
 	return "attention-allocation"
 }
 
+func (s *AttentionAllocationStage) InputType() DataType {
+	return DataTypeNone
+}
+
+func (s *AttentionAllocationStage) OutputType() DataType {
+	return DataTypeAtoms
+}
+
 func (s *AttentionAllocationStage) Execute(ctx context.Context, input interface{}) (interface{}, error) {
+	params := attention.DefaultParams()
+	if s.params != nil {
+		params = s.params(s.tenantID)
+	}
 	atoms := s.atomSpace.QueryAtoms(s.tenantID, nil)
 	
-	// Update attention values
+	// Decay attention over time and boost important atoms
 	for _, atom := range atoms {
-		av := atom.GetAttentionValue()
-		tv := atom.GetTruthValue()
-		
-		// Increase attention for high-confidence atoms
-		if tv.Confidence > 0.8 {
-			av.STI += 5
-		}
-		
-		// Decay attention over time
-		av.STI = int16(float64(av.STI) * 0.95)
-		
+		av, _ := params.Apply(atom.GetAttentionValue(), atom.GetTruthValue())
 		atom.SetAttentionValue(av)
 	}
 	
@@ -245,6 +410,14 @@ func (s *AgentExecutionStage) GetName() string {
 	return "agent-execution"
 }
 
+func (s *AgentExecutionStage) InputType() DataType {
+	return DataTypeAny
+}
+
+func (s *AgentExecutionStage) OutputType() DataType {
+	return DataTypeSame
+}
+
 func (s *AgentExecutionStage) Execute(ctx context.Context, input interface{}) (interface{}, error) {
 	tenantAgents := s.scheduler.GetAgentsByTenant(s.tenantID)
 	
@@ -269,44 +442,60 @@ type PipelineOrchestrator struct {
 	
 	// Channels for concurrent pipeline management
 	createChan chan pipelineCreateRequest
-	executeChan chan pipelineExecuteRequest
 	deleteChan chan string
 	done       chan struct{}
 	
+	// Submitted executions wait in the job queue until a worker takes them.
+	// At most workers executions run at once, synchronous ones included.
+	queue    JobQueue
+	slots    chan struct{}
+	stop     context.CancelFunc
+	finished []func(pipelineID string, input interface{}, err error)
+	artifacts ArtifactSink // Given to the pipelines created
+	hold      HoldFunc     // Holds submitted and scheduled executions
+	
 	workers int
 }
 
 type pipelineCreateRequest struct {
 	pipeline *Pipeline
+	replace  bool
 	response chan error
 }
 
-type pipelineExecuteRequest struct {
-	pipelineID string
-	ctx        context.Context
-	input      interface{}
-	response   chan pipelineExecuteResponse
-}
+// MaxJobDeliveries bounds how many times a job of a durable queue is taken
+// before it fails: jobs of unknown pipelines are retried, and a job whose
+// process dies while running it is taken again
+const MaxJobDeliveries = 20
 
-type pipelineExecuteResponse struct {
-	output interface{}
-	err    error
-}
+// unknownPipelineRetryDelay is how long a job of an unknown pipeline waits
+// before it is taken again
+const unknownPipelineRetryDelay = 15 * time.Second
 
-// NewPipelineOrchestrator creates a new pipeline orchestrator
+// NewPipelineOrchestrator creates a new pipeline orchestrator whose
+// submitted executions wait in memory
 func NewPipelineOrchestrator(workers int) *PipelineOrchestrator {
+	return NewPipelineOrchestratorWithQueue(workers, NewMemoryQueue())
+}
+
+// NewPipelineOrchestratorWithQueue creates a pipeline orchestrator taking
+// submitted executions from a job queue
+func NewPipelineOrchestratorWithQueue(workers int, queue JobQueue) *PipelineOrchestrator {
+	ctx, stop := context.WithCancel(context.Background())
 	po := &PipelineOrchestrator{
 		pipelines:   make(map[string]*Pipeline),
 		createChan:  make(chan pipelineCreateRequest, 100),
-		executeChan: make(chan pipelineExecuteRequest, 1000),
 		deleteChan:  make(chan string, 100),
 		done:        make(chan struct{}),
+		queue:       queue,
+		slots:       make(chan struct{}, workers),
+		stop:        stop,
 		workers:     workers,
 	}
 	
 	// Start worker goroutines
 	for i := 0; i < workers; i++ {
-		go po.worker()
+		go po.worker(ctx)
 	}
 	
 	// Start management goroutine
@@ -315,31 +504,92 @@ func NewPipelineOrchestrator(workers int) *PipelineOrchestrator {
 	return po
 }
 
-// worker processes pipeline execution requests
-func (po *PipelineOrchestrator) worker() {
+// worker takes jobs from the queue and runs them. A job is acknowledged
+// once its execution finished, so a job of a durable queue whose process
+// dies while running it is taken again by another. Each worker holds one
+// job of a durable queue at a time, so that a replica takes no more jobs
+// than it has workers.
+func (po *PipelineOrchestrator) worker(ctx context.Context) {
 	for {
-		select {
-		case req := <-po.executeChan:
-			po.mu.RLock()
-			pipeline, exists := po.pipelines[req.pipelineID]
-			po.mu.RUnlock()
-			
-			if !exists {
-				req.response <- pipelineExecuteResponse{
-					err: fmt.Errorf("pipeline %s not found", req.pipelineID),
-				}
-				continue
+		job, err := po.queue.Pop(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
 			}
-			
-			output, err := pipeline.Execute(req.ctx, req.input)
-			req.response <- pipelineExecuteResponse{
-				output: output,
-				err:    err,
+			// The queue is unreachable; try again shortly
+			select {
+			case <-time.After(time.Second):
+				continue
+			case <-ctx.Done():
+				return
 			}
-		case <-po.done:
+		}
+		if job.exec != nil {
+			// In-memory jobs wait for admission without holding the worker
+			go po.runJob(job)
+			continue
+		}
+		po.runJob(job)
+	}
+}
+
+// runJob runs the execution of a job. Jobs of a durable queue become an
+// execution of the pipeline when taken, under the job's ID. A job of a
+// pipeline this replica does not know, as it may not have loaded it yet,
+// is retried later, until taken MaxJobDeliveries times.
+func (po *PipelineOrchestrator) runJob(job Job) {
+	pipeline, exec, ctx := job.pipeline, job.exec, job.ctx
+	var input interface{}
+	var err error
+	if exec == nil {
+		ctx = context.Background()
+		pipeline, err = po.GetPipeline(job.PipelineID)
+		if err != nil && job.Deliveries < MaxJobDeliveries {
+			po.queue.Retry(ctx, job, time.Now().Add(unknownPipelineRetryDelay))
+			return
+		}
+		if err == nil && po.holdJob(job, pipeline) {
+			return
+		}
+		if err == nil {
+			input, err = decodeInput(job.Input)
+		}
+		if err == nil && job.Deliveries > MaxJobDeliveries {
+			err = fmt.Errorf("pipeline job %s taken %d times without finishing", job.ID, MaxJobDeliveries)
+		}
+		if err == nil {
+			exec, err = pipeline.enqueue(job.ID, input, time.Time{})
+		}
+		if err == nil && job.HeldBy != "" {
+			pipeline.noteHeld(exec, job.HeldBy)
+		}
+	} else {
+		if po.holdJob(job, pipeline) {
 			return
 		}
+		input = exec.input
 	}
+	
+	if err == nil {
+		pipeline.queueScheduled(exec)
+		_, err = po.dispatch(ctx, pipeline, exec)
+	}
+	
+	po.mu.RLock()
+	finished := po.finished
+	po.mu.RUnlock()
+	for _, handler := range finished {
+		handler(job.PipelineID, input, err)
+	}
+	po.queue.Ack(context.Background(), job.ID)
+}
+
+// OnJobFinished registers a handler called when the execution of a
+// submitted or scheduled job finishes, on whichever replica took it
+func (po *PipelineOrchestrator) OnJobFinished(handler func(pipelineID string, input interface{}, err error)) {
+	po.mu.Lock()
+	defer po.mu.Unlock()
+	po.finished = append(po.finished, handler)
 }
 
 // manage handles pipeline creation and deletion
@@ -347,7 +597,7 @@ func (po *PipelineOrchestrator) manage() {
 	for {
 		select {
 		case req := <-po.createChan:
-			req.response <- po.createPipelineInternal(req.pipeline)
+			req.response <- po.createPipelineInternal(req.pipeline, req.replace)
 		case pipelineID := <-po.deleteChan:
 			po.deletePipelineInternal(pipelineID)
 		case <-po.done:
@@ -363,31 +613,123 @@ func (po *PipelineOrchestrator) CreatePipeline(pipeline *Pipeline) error {
 	return <-response
 }
 
+// ReplacePipeline creates a pipeline, or replaces the pipeline of the same
+// ID. Executions already started on the replaced pipeline complete on it.
+func (po *PipelineOrchestrator) ReplacePipeline(pipeline *Pipeline) error {
+	response := make(chan error, 1)
+	po.createChan <- pipelineCreateRequest{pipeline: pipeline, replace: true, response: response}
+	return <-response
+}
+
 // createPipelineInternal is the internal implementation
-func (po *PipelineOrchestrator) createPipelineInternal(pipeline *Pipeline) error {
+func (po *PipelineOrchestrator) createPipelineInternal(pipeline *Pipeline, replace bool) error {
 	po.mu.Lock()
 	defer po.mu.Unlock()
 	
-	if _, exists := po.pipelines[pipeline.ID]; exists {
+	if existing, exists := po.pipelines[pipeline.ID]; exists && (!replace || existing.TenantID != pipeline.TenantID) {
 		return fmt.Errorf("pipeline %s already exists", pipeline.ID)
 	}
 	
+	pipeline.setArtifactSink(po.artifacts)
 	po.pipelines[pipeline.ID] = pipeline
 	return nil
 }
 
-// ExecutePipeline executes a pipeline
+// ExecutePipeline executes a pipeline and waits for the result. The
+// execution is queued behind earlier ones when the pipeline is at its
+// concurrency limit.
 func (po *PipelineOrchestrator) ExecutePipeline(ctx context.Context, pipelineID string, input interface{}) (interface{}, error) {
-	response := make(chan pipelineExecuteResponse, 1)
-	po.executeChan <- pipelineExecuteRequest{
-		pipelineID: pipelineID,
-		ctx:        ctx,
-		input:      input,
-		response:   response,
+	pipeline, exec, err := po.enqueue(pipelineID, input)
+	if err != nil {
+		return nil, err
+	}
+	
+	return po.dispatch(ctx, pipeline, exec)
+}
+
+// SubmitPipeline queues a pipeline execution and returns immediately. The
+// execution can be followed with Pipeline.GetExecution, and OnJobFinished
+// handlers are called once it finishes.
+func (po *PipelineOrchestrator) SubmitPipeline(ctx context.Context, pipelineID string, input interface{}) (Execution, error) {
+	return po.SchedulePipeline(ctx, pipelineID, input, time.Time{})
+}
+
+// SchedulePipeline queues a pipeline execution to start no earlier than
+// runAt, or than the end of the hold of its tenant's executions. With an
+// in-memory queue the execution is registered at once; with a durable
+// queue it is registered by the replica taking it, under the ID returned
+// here.
+func (po *PipelineOrchestrator) SchedulePipeline(ctx context.Context, pipelineID string, input interface{}, runAt time.Time) (Execution, error) {
+	pipeline, err := po.GetPipeline(pipelineID)
+	if err != nil {
+		return Execution{}, err
+	}
+	
+	now := time.Now()
+	if runAt.Before(now) {
+		runAt = now
+	}
+	job := Job{PipelineID: pipelineID, RunAt: runAt, EnqueuedAt: now}
+	if until, reason := po.heldUntil(pipeline.TenantID); until.After(runAt) {
+		job.RunAt, job.HeldBy = until, reason
+	}
+	if _, inMemory := po.queue.(*MemoryQueue); inMemory {
+		exec, err := pipeline.enqueue("", input, job.RunAt)
+		if err != nil {
+			return Execution{}, err
+		}
+		if job.HeldBy != "" {
+			pipeline.noteHeld(exec, job.HeldBy)
+		}
+		job.ID, job.exec, job.pipeline, job.ctx = exec.ID, exec, pipeline, ctx
+		po.queue.Push(ctx, job)
+		
+		snapshot, _ := pipeline.GetExecution(exec.ID)
+		return snapshot, nil
+	}
+	
+	if err := pipeline.CheckInput(input); err != nil {
+		return Execution{}, err
+	}
+	job.ID = newJobID(pipelineID)
+	if job.Input, err = encodeInput(input); err != nil {
+		return Execution{}, err
+	}
+	if err := po.queue.Push(ctx, job); err != nil {
+		return Execution{}, fmt.Errorf("failed to queue pipeline execution: %w", err)
+	}
+	
+	exec := Execution{ID: job.ID, PipelineID: pipelineID, State: ExecutionStateQueued, QueuedAt: now, HeldBy: job.HeldBy}
+	if job.RunAt.After(now) {
+		exec.State = ExecutionStateScheduled
+		exec.RunAt = job.RunAt
+	}
+	return exec, nil
+}
+
+func (po *PipelineOrchestrator) enqueue(pipelineID string, input interface{}) (*Pipeline, *Execution, error) {
+	pipeline, err := po.GetPipeline(pipelineID)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	exec, err := pipeline.Enqueue(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pipeline, exec, nil
+}
+
+// dispatch waits for the execution to be admitted, then for a free worker
+// slot, and runs it. Queued executions do not occupy slots.
+func (po *PipelineOrchestrator) dispatch(ctx context.Context, pipeline *Pipeline, exec *Execution) (interface{}, error) {
+	if err := pipeline.Wait(ctx, exec); err != nil {
+		return nil, err
 	}
 	
-	result := <-response
-	return result.output, result.err
+	po.slots <- struct{}{}
+	defer func() { <-po.slots }()
+	return pipeline.Run(ctx, exec)
 }
 
 // GetPipeline retrieves a pipeline by ID
@@ -447,7 +789,9 @@ func (po *PipelineOrchestrator) GetStats() map[string]interface{} {
 	}
 }
 
-// Close shuts down the orchestrator
+// Close shuts down the orchestrator. Workers stop taking jobs; those
+// left in a durable queue are taken by other replicas.
 func (po *PipelineOrchestrator) Close() {
+	po.stop()
 	close(po.done)
 }
@@ -0,0 +1,194 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/persistence"
+)
+
+// Job is a submitted pipeline execution waiting in a JobQueue
+type Job struct {
+	ID         string    `json:"id"` // ID of the execution it becomes
+	PipelineID string    `json:"pipeline_id"`
+	Input      []byte    `json:"input,omitempty"` // Encoded input, for queues shared across processes
+	RunAt      time.Time `json:"run_at"`          // Not taken by a worker before
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	Deliveries int       `json:"deliveries"`        // Times the job was taken, this time included
+	HeldBy     string    `json:"held_by,omitempty"` // Why the job was pushed back, e.g. by a maintenance window
+
+	// Jobs of a MemoryQueue never leave the process, so they carry the
+	// execution registered when they were submitted
+	exec     *Execution
+	pipeline *Pipeline
+	ctx      context.Context
+}
+
+// JobQueue holds submitted and scheduled pipeline executions until a
+// worker takes them. Durable queues keep jobs across process crashes and
+// share them between replicas: a job taken but not acknowledged within
+// their visibility timeout is handed to another worker, so jobs run at
+// least once.
+type JobQueue interface {
+	// Push adds a job, to be taken once its RunAt is reached
+	Push(ctx context.Context, job Job) error
+
+	// Pop blocks until a job is due or ctx is done, and takes it
+	Pop(ctx context.Context) (Job, error)
+
+	// Ack removes a taken job once its execution finished
+	Ack(ctx context.Context, jobID string) error
+
+	// Retry returns a taken job to the queue, due at runAt
+	Retry(ctx context.Context, job Job, runAt time.Time) error
+}
+
+// MemoryQueue is a JobQueue of a single process. Its jobs are lost when
+// the process exits.
+type MemoryQueue struct {
+	jobs []Job         // Sorted by RunAt, then by push order
+	wake chan struct{} // Closed and replaced on each push
+	mu   sync.Mutex
+}
+
+// NewMemoryQueue creates an empty in-memory queue
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{wake: make(chan struct{})}
+}
+
+// Push adds a job
+func (q *MemoryQueue) Push(ctx context.Context, job Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := sort.Search(len(q.jobs), func(i int) bool { return q.jobs[i].RunAt.After(job.RunAt) })
+	q.jobs = append(q.jobs, Job{})
+	copy(q.jobs[i+1:], q.jobs[i:])
+	q.jobs[i] = job
+	close(q.wake)
+	q.wake = make(chan struct{})
+	return nil
+}
+
+// Pop takes the earliest due job, waiting for one if none is
+func (q *MemoryQueue) Pop(ctx context.Context) (Job, error) {
+	for {
+		q.mu.Lock()
+		wake := q.wake
+		var timer *time.Timer
+		var wait <-chan time.Time
+		if len(q.jobs) > 0 {
+			delay := time.Until(q.jobs[0].RunAt)
+			if delay <= 0 {
+				job := q.jobs[0]
+				q.jobs = q.jobs[1:]
+				q.mu.Unlock()
+				job.Deliveries++
+				return job, nil
+			}
+			timer = time.NewTimer(delay)
+			wait = timer.C
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return Job{}, ctx.Err()
+		case <-wake:
+		case <-wait:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// Ack does nothing: jobs are never handed out twice
+func (q *MemoryQueue) Ack(ctx context.Context, jobID string) error {
+	return nil
+}
+
+// Retry pushes a taken job again
+func (q *MemoryQueue) Retry(ctx context.Context, job Job, runAt time.Time) error {
+	job.RunAt = runAt
+	return q.Push(ctx, job)
+}
+
+// Len returns the number of jobs not taken yet
+func (q *MemoryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.jobs)
+}
+
+// newJobID returns an ID unique across processes for a job of a pipeline
+func newJobID(pipelineID string) string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return pipelineID + "-" + hex.EncodeToString(b)
+}
+
+// jobInput is the encoding of an execution's input in a shared queue.
+// Atoms keep their protobuf records, other inputs are JSON.
+type jobInput struct {
+	Atoms [][]byte        `json:"atoms,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// encodeInput encodes the input of a job
+func encodeInput(input interface{}) ([]byte, error) {
+	if input == nil {
+		return nil, nil
+	}
+	var encoded jobInput
+	if atoms, ok := input.([]atomspace.Atom); ok {
+		encoded.Atoms = make([][]byte, len(atoms))
+		for i, atom := range atoms {
+			encoded.Atoms[i] = persistence.MarshalAtom(atom)
+		}
+	} else {
+		value, err := json.Marshal(input)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline input cannot be queued: %w", err)
+		}
+		encoded.Value = value
+	}
+	return json.Marshal(encoded)
+}
+
+// decodeInput decodes the input of a job
+func decodeInput(data []byte) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var encoded jobInput
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, fmt.Errorf("invalid job input: %w", err)
+	}
+	if encoded.Atoms != nil {
+		records := make([]*persistence.AtomRecord, len(encoded.Atoms))
+		for i, b := range encoded.Atoms {
+			rec, err := persistence.UnmarshalRecord(b)
+			if err != nil {
+				return nil, fmt.Errorf("invalid job input: %w", err)
+			}
+			records[i] = rec
+		}
+		return persistence.BuildAtoms(records)
+	}
+	if len(encoded.Value) == 0 {
+		return nil, nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(encoded.Value, &value); err != nil {
+		return nil, fmt.Errorf("invalid job input: %w", err)
+	}
+	return value, nil
+}
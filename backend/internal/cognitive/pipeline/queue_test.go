@@ -0,0 +1,255 @@
+package pipeline
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryQueueOrdersByDueTime(t *testing.T) {
+	q := NewMemoryQueue()
+	now := time.Now()
+	q.Push(context.Background(), Job{ID: "later", RunAt: now.Add(100 * time.Millisecond)})
+	q.Push(context.Background(), Job{ID: "first", RunAt: now})
+	q.Push(context.Background(), Job{ID: "second", RunAt: now})
+
+	for _, want := range []string{"first", "second", "later"} {
+		job, err := q.Pop(context.Background())
+		if err != nil || job.ID != want {
+			t.Fatalf("expected %s, got %s, %v", want, job.ID, err)
+		}
+	}
+	if time.Since(now) < 100*time.Millisecond {
+		t.Error("expected the delayed job to wait until due")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := q.Pop(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected an empty queue to block until ctx is done, got %v", err)
+	}
+}
+
+func TestJobInputRoundTrip(t *testing.T) {
+	data, err := encodeInput(map[string]interface{}{"service": "checkout"})
+	if err != nil {
+		t.Fatalf("encodeInput failed: %v", err)
+	}
+	input, err := decodeInput(data)
+	if value, _ := input.(map[string]interface{}); err != nil || value["service"] != "checkout" {
+		t.Errorf("expected the input decoded, got %v, %v", input, err)
+	}
+	if input, err := decodeInput(nil); input != nil || err != nil {
+		t.Errorf("expected no input, got %v, %v", input, err)
+	}
+	if _, err := encodeInput(func() {}); err == nil {
+		t.Error("expected an input that cannot be encoded rejected")
+	}
+}
+
+// fakeRedis serves the commands and scripts of a RedisQueue, running the
+// scripts as Redis would
+type fakeRedis struct {
+	zsets  map[string]map[string]float64
+	hashes map[string]map[string]string
+	mu     sync.Mutex
+}
+
+func newFakeRedis(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	server := &fakeRedis{zsets: make(map[string]map[string]float64), hashes: make(map[string]map[string]string)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		request, err := readReply(r)
+		if err != nil {
+			return
+		}
+		items, _ := request.([]interface{})
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i], _ = item.(string)
+		}
+		s.mu.Lock()
+		reply := s.command(args)
+		s.mu.Unlock()
+		conn.Write(encodeReply(reply))
+	}
+}
+
+func encodeReply(reply interface{}) []byte {
+	switch v := reply.(type) {
+	case nil:
+		return []byte("$-1\r\n")
+	case error:
+		return []byte("-" + v.Error() + "\r\n")
+	case int64:
+		return []byte(":" + strconv.FormatInt(v, 10) + "\r\n")
+	case string:
+		return []byte("$" + strconv.Itoa(len(v)) + "\r\n" + v + "\r\n")
+	case []interface{}:
+		b := []byte("*" + strconv.Itoa(len(v)) + "\r\n")
+		for _, item := range v {
+			b = append(b, encodeReply(item)...)
+		}
+		return b
+	}
+	panic(fmt.Sprintf("unexpected reply %T", reply))
+}
+
+func (s *fakeRedis) zset(key string) map[string]float64 {
+	if s.zsets[key] == nil {
+		s.zsets[key] = make(map[string]float64)
+	}
+	return s.zsets[key]
+}
+
+func (s *fakeRedis) hash(key string) map[string]string {
+	if s.hashes[key] == nil {
+		s.hashes[key] = make(map[string]string)
+	}
+	return s.hashes[key]
+}
+
+// due returns the members of a sorted set scored at most max, lowest first
+func (s *fakeRedis) due(key string, max float64) []string {
+	var ids []string
+	for id, score := range s.zset(key) {
+		if score <= max {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return s.zsets[key][ids[i]] < s.zsets[key][ids[j]] })
+	return ids
+}
+
+func (s *fakeRedis) command(args []string) interface{} {
+	switch args[0] {
+	case "HSET":
+		s.hash(args[1])[args[2]] = args[3]
+		return int64(1)
+	case "ZADD":
+		if args[2] == "XX" {
+			if _, exists := s.zset(args[1])[args[4]]; !exists {
+				return int64(0)
+			}
+			args = append(args[:2], args[3:]...)
+		}
+		score, _ := strconv.ParseFloat(args[2], 64)
+		s.zset(args[1])[args[3]] = score
+		return int64(1)
+	case "EVAL":
+		keys, argv := args[3:7], args[7:]
+		switch args[1] {
+		case claimScript:
+			now, _ := strconv.ParseFloat(argv[0], 64)
+			for _, id := range s.due(keys[1], now) {
+				delete(s.zsets[keys[1]], id)
+				s.zset(keys[0])[id] = now
+			}
+			ids := s.due(keys[0], now)
+			if len(ids) == 0 {
+				return nil
+			}
+			deadline, _ := strconv.ParseFloat(argv[1], 64)
+			delete(s.zsets[keys[0]], ids[0])
+			s.zset(keys[1])[ids[0]] = deadline
+			deliveries, _ := strconv.ParseInt(s.hash(keys[3])[ids[0]], 10, 64)
+			s.hash(keys[3])[ids[0]] = strconv.FormatInt(deliveries+1, 10)
+			return []interface{}{ids[0], s.hash(keys[2])[ids[0]], deliveries + 1}
+		case ackScript:
+			delete(s.zset(keys[0]), argv[0])
+			delete(s.zset(keys[1]), argv[0])
+			delete(s.hash(keys[2]), argv[0])
+			delete(s.hash(keys[3]), argv[0])
+			return int64(1)
+		case retryScript:
+			if _, taken := s.zset(keys[1])[argv[0]]; taken {
+				delete(s.zsets[keys[1]], argv[0])
+				score, _ := strconv.ParseFloat(argv[1], 64)
+				s.zset(keys[0])[argv[0]] = score
+			}
+			return int64(1)
+		}
+	}
+	return fmt.Errorf("ERR unknown command %s", args[0])
+}
+
+func TestRedisQueue(t *testing.T) {
+	addr := newFakeRedis(t)
+	config := RedisConfig{Addr: addr, VisibilityTimeout: 150 * time.Millisecond, PollInterval: 5 * time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A job taken by a replica that dies is taken again once hidden for
+	// the visibility timeout
+	crashed := NewRedisQueue(config)
+	input, _ := encodeInput("payload")
+	if err := crashed.Push(ctx, Job{ID: "job-1", PipelineID: "p", Input: input, RunAt: time.Now()}); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if job, err := crashed.Pop(ctx); err != nil || job.ID != "job-1" || job.Deliveries != 1 {
+		t.Fatalf("expected job-1 taken, got %+v, %v", job, err)
+	}
+	crashed.Close()
+
+	q := NewRedisQueue(config)
+	defer q.Close()
+	job, err := q.Pop(ctx)
+	if err != nil || job.ID != "job-1" || job.Deliveries != 2 {
+		t.Fatalf("expected job-1 taken again, got %+v, %v", job, err)
+	}
+	if decoded, _ := decodeInput(job.Input); decoded != "payload" {
+		t.Errorf("expected the input kept, got %v", decoded)
+	}
+
+	// A job still running is not taken again while its deadline is extended
+	other := NewRedisQueue(config)
+	defer other.Close()
+	short, cancelShort := context.WithTimeout(ctx, 400*time.Millisecond)
+	if job, err := other.Pop(short); err == nil {
+		t.Errorf("expected a running job kept hidden, got %+v", job)
+	}
+	cancelShort()
+
+	// Acknowledged jobs are gone; scheduled jobs wait until due
+	if err := q.Ack(ctx, "job-1"); err != nil {
+		t.Fatalf("Ack failed: %v", err)
+	}
+	pushed := time.Now()
+	q.Push(ctx, Job{ID: "job-2", PipelineID: "p", RunAt: pushed.Add(100 * time.Millisecond)})
+	if job, err := other.Pop(ctx); err != nil || job.ID != "job-2" || time.Since(pushed) < 100*time.Millisecond {
+		t.Fatalf("expected job-2 taken once due, got %+v, %v", job, err)
+	}
+
+	// Retried jobs are taken again once due
+	if err := other.Retry(ctx, Job{ID: "job-2"}, time.Now()); err != nil {
+		t.Fatalf("Retry failed: %v", err)
+	}
+	if job, err := q.Pop(ctx); err != nil || job.ID != "job-2" || job.Deliveries != 2 {
+		t.Fatalf("expected job-2 taken after its retry, got %+v, %v", job, err)
+	}
+}
@@ -0,0 +1,340 @@
+package pipeline
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultRedisKey prefixes the keys of a Redis job queue
+const DefaultRedisKey = "erebus:pipeline-jobs"
+
+// RedisConfig configures a Redis job queue
+type RedisConfig struct {
+	Addr              string        // host:port of the server
+	Password          string        // Sent with AUTH if set
+	DB                int           // Database selected after connecting
+	Key               string        // Prefix of the queue's keys; DefaultRedisKey if empty
+	VisibilityTimeout time.Duration // How long a taken job stays hidden from other workers without being acknowledged; 30s if not positive
+	PollInterval      time.Duration // How often an empty queue is polled; 500ms if not positive
+}
+
+// claimScript requeues the jobs whose visibility timeout expired, then
+// takes the earliest due job, hiding it until the given deadline
+const claimScript = `
+local now = tonumber(ARGV[1])
+for _, id in ipairs(redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', now)) do
+	redis.call('ZREM', KEYS[2], id)
+	redis.call('ZADD', KEYS[1], now, id)
+end
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', now, 'LIMIT', 0, 1)
+if #ids == 0 then
+	return false
+end
+redis.call('ZREM', KEYS[1], ids[1])
+redis.call('ZADD', KEYS[2], ARGV[2], ids[1])
+local deliveries = redis.call('HINCRBY', KEYS[4], ids[1], 1)
+return {ids[1], redis.call('HGET', KEYS[3], ids[1]) or '', deliveries}
+`
+
+// ackScript removes a job
+const ackScript = `
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+redis.call('HDEL', KEYS[4], ARGV[1])
+return 1
+`
+
+// retryScript makes a taken job due again at the given time
+const retryScript = `
+if redis.call('ZREM', KEYS[2], ARGV[1]) == 1 then
+	redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+end
+return 1
+`
+
+// RedisQueue is a durable JobQueue shared by the replicas using the same
+// server and key. Jobs wait in a sorted set by due time; a taken job moves
+// to a second sorted set by visibility deadline, which the queue extends
+// while the job runs and which returns it to the first if its process dies.
+type RedisQueue struct {
+	config RedisConfig
+	conn   net.Conn
+	reader *bufio.Reader
+	taken  map[string]bool // Jobs taken and not acknowledged yet
+	done   chan struct{}
+	mu     sync.Mutex
+	connMu sync.Mutex
+}
+
+// NewRedisQueue creates a Redis job queue. It connects on first use.
+func NewRedisQueue(config RedisConfig) *RedisQueue {
+	if config.Key == "" {
+		config.Key = DefaultRedisKey
+	}
+	if config.VisibilityTimeout <= 0 {
+		config.VisibilityTimeout = 30 * time.Second
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 500 * time.Millisecond
+	}
+	q := &RedisQueue{config: config, taken: make(map[string]bool), done: make(chan struct{})}
+	go q.extend()
+	return q
+}
+
+func (q *RedisQueue) keys() []string {
+	return []string{q.config.Key + ":pending", q.config.Key + ":running", q.config.Key + ":jobs", q.config.Key + ":deliveries"}
+}
+
+// Push stores a job and makes it due at its RunAt
+func (q *RedisQueue) Push(ctx context.Context, job Job) error {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	keys := q.keys()
+	if _, err := q.do(ctx, "HSET", keys[2], job.ID, string(payload)); err != nil {
+		return err
+	}
+	_, err = q.do(ctx, "ZADD", keys[0], dueScore(job.RunAt), job.ID)
+	return err
+}
+
+// dueScore is the sorted-set score of a due time, in milliseconds rounded up
+// so a job is never taken before it is due
+func dueScore(t time.Time) string {
+	ms := t.UnixMilli()
+	if t.After(time.UnixMilli(ms)) {
+		ms++
+	}
+	return strconv.FormatInt(ms, 10)
+}
+
+// Pop takes the earliest due job, polling until one is
+func (q *RedisQueue) Pop(ctx context.Context) (Job, error) {
+	keys := q.keys()
+	for {
+		now := time.Now()
+		deadline := now.Add(q.config.VisibilityTimeout)
+		reply, err := q.do(ctx, "EVAL", claimScript, "4", keys[0], keys[1], keys[2], keys[3],
+			strconv.FormatInt(now.UnixMilli(), 10), strconv.FormatInt(deadline.UnixMilli(), 10))
+		if err != nil {
+			return Job{}, err
+		}
+		if claimed, ok := reply.([]interface{}); ok && len(claimed) == 3 {
+			id, _ := claimed[0].(string)
+			payload, _ := claimed[1].(string)
+			deliveries, _ := claimed[2].(int64)
+			var job Job
+			if err := json.Unmarshal([]byte(payload), &job); err != nil {
+				// Nothing can run it; drop it rather than take it forever
+				q.Ack(ctx, id)
+				continue
+			}
+			job.Deliveries = int(deliveries)
+			q.mu.Lock()
+			q.taken[id] = true
+			q.mu.Unlock()
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return Job{}, ctx.Err()
+		case <-time.After(q.config.PollInterval):
+		}
+	}
+}
+
+// Ack removes a job
+func (q *RedisQueue) Ack(ctx context.Context, jobID string) error {
+	q.mu.Lock()
+	delete(q.taken, jobID)
+	q.mu.Unlock()
+
+	keys := q.keys()
+	_, err := q.do(ctx, "EVAL", ackScript, "4", keys[0], keys[1], keys[2], keys[3], jobID)
+	return err
+}
+
+// Retry makes a taken job due again at runAt
+func (q *RedisQueue) Retry(ctx context.Context, job Job, runAt time.Time) error {
+	q.mu.Lock()
+	delete(q.taken, job.ID)
+	q.mu.Unlock()
+
+	keys := q.keys()
+	_, err := q.do(ctx, "EVAL", retryScript, "4", keys[0], keys[1], keys[2], keys[3], job.ID, dueScore(runAt))
+	return err
+}
+
+// Close stops extending taken jobs, which other workers take once their
+// visibility timeout expires, and closes the connection
+func (q *RedisQueue) Close() error {
+	close(q.done)
+	q.connMu.Lock()
+	defer q.connMu.Unlock()
+	if q.conn != nil {
+		return q.conn.Close()
+	}
+	return nil
+}
+
+// extend pushes back the visibility deadline of taken jobs three times per
+// timeout, so that jobs running longer than it are not taken again
+func (q *RedisQueue) extend() {
+	ticker := time.NewTicker(q.config.VisibilityTimeout / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.done:
+			return
+		case <-ticker.C:
+		}
+
+		q.mu.Lock()
+		ids := make([]string, 0, len(q.taken))
+		for id := range q.taken {
+			ids = append(ids, id)
+		}
+		q.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), q.config.VisibilityTimeout/3)
+		deadline := strconv.FormatInt(time.Now().Add(q.config.VisibilityTimeout).UnixMilli(), 10)
+		for _, id := range ids {
+			q.do(ctx, "ZADD", q.keys()[1], "XX", deadline, id)
+		}
+		cancel()
+	}
+}
+
+// redisError is an error reply of the server
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+// do sends a command and reads its reply, connecting first if needed. The
+// connection is dropped after a network error and reopened by the next
+// command.
+func (q *RedisQueue) do(ctx context.Context, args ...string) (interface{}, error) {
+	q.connMu.Lock()
+	defer q.connMu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil, errors.New("redis job queue closed")
+	default:
+	}
+	if q.conn == nil {
+		if err := q.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+	reply, err := q.roundTrip(ctx, args)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		q.conn.Close()
+		q.conn = nil
+	}
+	return reply, err
+}
+
+func (q *RedisQueue) connect(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", q.config.Addr)
+	if err != nil {
+		return fmt.Errorf("connecting to redis: %w", err)
+	}
+	q.conn, q.reader = conn, bufio.NewReader(conn)
+
+	var setup [][]string
+	if q.config.Password != "" {
+		setup = append(setup, []string{"AUTH", q.config.Password})
+	}
+	if q.config.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(q.config.DB)})
+	}
+	for _, args := range setup {
+		if _, err := q.roundTrip(ctx, args); err != nil {
+			conn.Close()
+			q.conn = nil
+			return fmt.Errorf("connecting to redis: %w", err)
+		}
+	}
+	return nil
+}
+
+func (q *RedisQueue) roundTrip(ctx context.Context, args []string) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(q.config.VisibilityTimeout)
+	}
+	q.conn.SetDeadline(deadline)
+
+	buf := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		buf = append(buf, "$"+strconv.Itoa(len(arg))+"\r\n"...)
+		buf = append(buf, arg...)
+		buf = append(buf, "\r\n"...)
+	}
+	if _, err := q.conn.Write(buf); err != nil {
+		return nil, err
+	}
+	return readReply(q.reader)
+}
+
+// readReply reads a RESP reply: strings and bulk strings as string,
+// integers as int64, arrays as []interface{} and nulls as nil
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("invalid redis reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("invalid redis reply %q", line)
+}
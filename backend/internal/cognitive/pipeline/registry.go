@@ -0,0 +1,185 @@
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/attention"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/forecast"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/retry"
+)
+
+// StageContext carries the tenant-scoped dependencies available to stage
+// factories when a declarative pipeline is assembled
+type StageContext struct {
+	TenantID  string
+	AtomSpace atomspace.AtomSpaceInterface
+	Inference *inference.InferenceEngine
+	Scheduler *agents.AgentScheduler
+	Series    *forecast.Store                        // Time series samples for forecasting, may be nil
+	Attention func(tenantID string) attention.Params // Attention params of tenants, the default ones if nil
+}
+
+// StageParams holds the free-form parameters of a declared stage
+type StageParams map[string]interface{}
+
+// String returns a string parameter or def when unset
+func (p StageParams) String(key, def string) string {
+	if v, ok := p[key].(string); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// Float returns a numeric parameter or def when unset
+func (p StageParams) Float(key string, def float64) float64 {
+	switch v := p[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	return def
+}
+
+// Int returns an integer parameter or def when unset
+func (p StageParams) Int(key string, def int) int {
+	return int(p.Float(key, float64(def)))
+}
+
+// Duration returns a duration parameter given in seconds, or def when unset
+func (p StageParams) Duration(key string, def time.Duration) time.Duration {
+	if _, ok := p[key]; !ok {
+		return def
+	}
+	return time.Duration(p.Float(key, 0) * float64(time.Second))
+}
+
+// StringMap returns a string-to-string map parameter
+func (p StageParams) StringMap(key string) map[string]string {
+	result := make(map[string]string)
+	if m, ok := p[key].(map[string]interface{}); ok {
+		for k, v := range m {
+			if s, ok := v.(string); ok {
+				result[k] = s
+			}
+		}
+	}
+	if m, ok := p[key].(map[string]string); ok {
+		for k, v := range m {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// StageFactory builds a stage from its declared parameters
+type StageFactory func(sc StageContext, params StageParams) (PipelineStage, error)
+
+// StageSpec declares one stage of a declarative pipeline
+type StageSpec struct {
+	Kind   string      `json:"kind"`
+	Params StageParams `json:"params,omitempty"`
+	Retry  *retry.Spec `json:"retry,omitempty"` // Overrides the pipeline retry policy
+}
+
+// PipelineSpec declares a pipeline as an ordered list of registered stages
+type PipelineSpec struct {
+	Name      string      `json:"name"`
+	InputType DataType    `json:"input_type,omitempty"`
+	Stages    []StageSpec `json:"stages"`
+
+	// MaxConcurrency limits concurrent executions (default 0, unlimited)
+	MaxConcurrency int `json:"max_concurrency,omitempty"`
+
+	// Retry is the retry policy of stages without their own
+	Retry *retry.Spec `json:"retry,omitempty"`
+}
+
+// StageRegistry maps stage kinds to their factories
+type StageRegistry struct {
+	factories map[string]StageFactory
+	mu        sync.RWMutex
+}
+
+// NewStageRegistry creates an empty stage registry
+func NewStageRegistry() *StageRegistry {
+	return &StageRegistry{
+		factories: make(map[string]StageFactory),
+	}
+}
+
+// NewDefaultStageRegistry creates a registry holding the built-in stages
+func NewDefaultStageRegistry() *StageRegistry {
+	r := NewStageRegistry()
+
+	r.Register("atom-ingestion", func(sc StageContext, params StageParams) (PipelineStage, error) {
+		return NewAtomIngestionStage(sc.AtomSpace, sc.TenantID), nil
+	})
+	r.Register("inference", func(sc StageContext, params StageParams) (PipelineStage, error) {
+		if sc.Inference == nil {
+			return nil, fmt.Errorf("tenant %s not initialized", sc.TenantID)
+		}
+		return NewInferenceStage(sc.Inference, sc.TenantID, params.Int("max_iterations", 5)), nil
+	})
+	r.Register("attention-allocation", func(sc StageContext, params StageParams) (PipelineStage, error) {
+		return NewAttentionAllocationStage(sc.AtomSpace, sc.TenantID, sc.Attention), nil
+	})
+	r.Register("agent-execution", func(sc StageContext, params StageParams) (PipelineStage, error) {
+		return NewAgentExecutionStage(sc.Scheduler, sc.TenantID), nil
+	})
+	r.Register("query", func(sc StageContext, params StageParams) (PipelineStage, error) {
+		return NewQueryStage(sc.AtomSpace, sc.TenantID, nil), nil
+	})
+
+	registerInfraStages(r)
+
+	return r
+}
+
+// Register adds a stage factory under kind
+func (r *StageRegistry) Register(kind string, factory StageFactory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.factories[kind]; exists {
+		return fmt.Errorf("stage kind %s already registered", kind)
+	}
+	r.factories[kind] = factory
+	return nil
+}
+
+// Build creates a stage of the given kind
+func (r *StageRegistry) Build(sc StageContext, spec StageSpec) (PipelineStage, error) {
+	r.mu.RLock()
+	factory, exists := r.factories[spec.Kind]
+	r.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown stage kind: %s", spec.Kind)
+	}
+
+	params := spec.Params
+	if params == nil {
+		params = StageParams{}
+	}
+	return factory(sc, params)
+}
+
+// Kinds returns the registered stage kinds in sorted order
+func (r *StageRegistry) Kinds() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	kinds := make([]string, 0, len(r.factories))
+	for kind := range r.factories {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
@@ -0,0 +1,135 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// DataType describes the values flowing between pipeline stages
+type DataType string
+
+const (
+	// DataTypeAny accepts or produces values of any type (checked at runtime)
+	DataTypeAny DataType = "any"
+	// DataTypeNone marks a stage that ignores its input or produces nothing
+	DataTypeNone DataType = "none"
+	// DataTypeAtoms is a []atomspace.Atom
+	DataTypeAtoms DataType = "atoms"
+	// DataTypeSame marks a pass-through stage whose output has the type of
+	// its input
+	DataTypeSame DataType = "same"
+	// DataTypeReport is a *Report
+	DataTypeReport DataType = "report"
+)
+
+// Valid reports whether t is a known data type
+func (t DataType) Valid() bool {
+	switch t {
+	case DataTypeAny, DataTypeNone, DataTypeAtoms, DataTypeSame, DataTypeReport:
+		return true
+	}
+	return false
+}
+
+// TypedStage is implemented by stages that declare their input and output
+// types. Stages that do not implement it are treated as any -> any.
+type TypedStage interface {
+	PipelineStage
+	InputType() DataType
+	OutputType() DataType
+}
+
+// stageTypes returns the declared types of a stage
+func stageTypes(stage PipelineStage) (DataType, DataType) {
+	if typed, ok := stage.(TypedStage); ok {
+		return typed.InputType(), typed.OutputType()
+	}
+	return DataTypeAny, DataTypeAny
+}
+
+// compatible reports whether a value of type produced can feed a stage
+// expecting type expected
+func compatible(produced, expected DataType) bool {
+	switch {
+	case expected == DataTypeNone || expected == DataTypeAny:
+		return true
+	case produced == DataTypeAny:
+		return true // unknown statically, checked at runtime
+	default:
+		return produced == expected
+	}
+}
+
+// checkValue verifies a runtime value against a declared type
+func checkValue(value interface{}, expected DataType) error {
+	switch expected {
+	case DataTypeAtoms:
+		if _, ok := value.([]atomspace.Atom); !ok {
+			return fmt.Errorf("expected %s ([]Atom), got %T", expected, value)
+		}
+	case DataTypeReport:
+		if _, ok := value.(*Report); !ok {
+			return fmt.Errorf("expected %s (*Report), got %T", expected, value)
+		}
+	}
+	return nil
+}
+
+// validateStages checks that each stage accepts what the previous stage
+// produces, starting from the pipeline input type. It returns the type of
+// the final output.
+func validateStages(inputType DataType, stages []PipelineStage) (DataType, error) {
+	current := inputType
+	for i, stage := range stages {
+		in, out := stageTypes(stage)
+		if !compatible(current, in) {
+			return "", fmt.Errorf("stage %d (%s) expects %s input but receives %s", i, stage.GetName(), in, current)
+		}
+		if out != DataTypeSame {
+			current = out
+		}
+	}
+	return current, nil
+}
+
+// ============================================================================
+// Adapter Stages
+// ============================================================================
+
+// QueryStage produces the atoms of a tenant matching a filter, adapting
+// stages that require atoms to pipelines started without input
+type QueryStage struct {
+	atomSpace atomspace.AtomSpaceInterface
+	tenantID  string
+	filter    func(atomspace.Atom) bool
+}
+
+func NewQueryStage(atomSpace atomspace.AtomSpaceInterface, tenantID string, filter func(atomspace.Atom) bool) *QueryStage {
+	return &QueryStage{
+		atomSpace: atomSpace,
+		tenantID:  tenantID,
+		filter:    filter,
+	}
+}
+
+func (s *QueryStage) GetName() string {
+	return "query"
+}
+
+func (s *QueryStage) InputType() DataType {
+	return DataTypeNone
+}
+
+func (s *QueryStage) OutputType() DataType {
+	return DataTypeAtoms
+}
+
+func (s *QueryStage) Execute(ctx context.Context, input interface{}) (interface{}, error) {
+	atoms := s.atomSpace.QueryAtoms(s.tenantID, s.filter)
+	if atoms == nil {
+		atoms = []atomspace.Atom{}
+	}
+	return atoms, nil
+}
@@ -0,0 +1,53 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/pipeline"
+)
+
+// ResolvePipelineInput converts the input of an execution request into
+// what a pipeline's executions start with. Inline atoms become nodes of
+// the pipeline's tenant, a saved query is run now, and a dataset is read
+// from the artifact it names; the atoms are then checked against the type
+// of input the pipeline takes.
+func (ce *CognitiveEngine) ResolvePipelineInput(ctx context.Context, pipelineID string, in pipeline.Input) (interface{}, error) {
+	if err := in.Validate(); err != nil {
+		return nil, err
+	}
+	p, err := ce.pipelineOrch.GetPipeline(pipelineID)
+	if err != nil {
+		return nil, err
+	}
+
+	var atoms []atomspace.Atom
+	switch {
+	case in.Query != "":
+		if atoms, _, err = ce.RunSavedQuery(p.TenantID, in.Query); err != nil {
+			return nil, err
+		}
+	case in.Dataset != nil:
+		ref := in.Dataset
+		_, data, err := ce.artifacts.Get(ctx, p.TenantID, ref.PipelineID, ref.ExecutionID, ref.Name)
+		if err != nil {
+			return nil, fmt.Errorf("dataset %s of execution %s: %w", ref.Name, ref.ExecutionID, err)
+		}
+		inputs, err := pipeline.DecodeDataset(data)
+		if err != nil {
+			return nil, err
+		}
+		if atoms, err = pipeline.BuildAtoms(p.TenantID, inputs); err != nil {
+			return nil, err
+		}
+	default:
+		if atoms, err = pipeline.BuildAtoms(p.TenantID, in.Atoms); err != nil {
+			return nil, err
+		}
+	}
+	if atoms == nil {
+		atoms = []atomspace.Atom{}
+	}
+	return p.ConvertInput(atoms)
+}
@@ -0,0 +1,57 @@
+package cognitive
+
+import (
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sharding"
+)
+
+// PinTenant places a tenant's atoms on a set of shards, optionally
+// dedicated to it, so that a large tenant stops competing with others for
+// the shards its keys hash to. Atoms are moved before it returns; the
+// number moved is returned.
+func (ce *CognitiveEngine) PinTenant(p sharding.Placement) (int, error) {
+	return ce.shardManager.PinTenant(p)
+}
+
+// UnpinTenant returns a tenant's atoms to the shards shared by all tenants
+func (ce *CognitiveEngine) UnpinTenant(tenantID string) (int, error) {
+	return ce.shardManager.UnpinTenant(tenantID)
+}
+
+// GetPlacement returns the shards a tenant is pinned to
+func (ce *CognitiveEngine) GetPlacement(tenantID string) (sharding.Placement, bool) {
+	return ce.shardManager.GetPlacement(tenantID)
+}
+
+// ListPlacements returns the placements of all pinned tenants
+func (ce *CognitiveEngine) ListPlacements() []sharding.Placement {
+	return ce.shardManager.ListPlacements()
+}
+
+// HotShards returns the recent operation rate of each shard and whether it
+// is hot, with the tenants driving it
+func (ce *CognitiveEngine) HotShards() []sharding.ShardHeat {
+	return ce.shardManager.HotShards()
+}
+
+// ResizeShards changes the number of shards atoms are hashed across, as
+// tenant data grows. It returns once added shards take writes; existing
+// atoms move in the background, tracked by ShardMigration.
+func (ce *CognitiveEngine) ResizeShards(numShards int) error {
+	if err := ce.shardManager.Resize(numShards); err != nil {
+		return err
+	}
+	if ce.valueLogs != nil {
+		ce.openValueLogs(numShards)
+	}
+	return nil
+}
+
+// NumShards returns the number of shards atoms are hashed across
+func (ce *CognitiveEngine) NumShards() int {
+	return ce.shardManager.NumShards()
+}
+
+// ShardMigration returns the progress of the last shard count change
+func (ce *CognitiveEngine) ShardMigration() sharding.MigrationStatus {
+	return ce.shardManager.Migration()
+}
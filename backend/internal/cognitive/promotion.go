@@ -0,0 +1,346 @@
+package cognitive
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/onboarding"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/pipeline"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/promotion"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+)
+
+// ExportBundle exports a tenant's curated artifacts as its next bundle
+// version: rules (its triggers and rule selection), pipelines declared from
+// specs, saved queries and its ontology, which is its mounts and the
+// concepts linked by inheritance. Pipelines assembled in code, and rules
+// running them, cannot be bundled and are listed as skipped.
+func (ce *CognitiveEngine) ExportBundle(tenantID string) (promotion.Bundle, error) {
+	if !ce.isInitialized(tenantID) {
+		return promotion.Bundle{}, fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+
+	artifacts, skipped := ce.exportArtifacts(tenantID)
+	if err := artifacts.Validate(); err != nil {
+		return promotion.Bundle{}, fmt.Errorf("artifacts of tenant %s cannot be bundled: %w", tenantID, err)
+	}
+	return ce.bundles.Add(tenantID, artifacts, skipped)
+}
+
+// exportArtifacts returns a tenant's artifacts in their bundled form, and
+// what could not be bundled
+func (ce *CognitiveEngine) exportArtifacts(tenantID string) (promotion.Artifacts, []string) {
+	artifacts := promotion.Artifacts{
+		Rules:     []triggers.Spec{},
+		Pipelines: []onboarding.Pipeline{},
+		Queries:   ce.savedQueries.List(tenantID),
+		Ontology:  ce.exportOntology(tenantID),
+	}
+	var skipped []string
+
+	// Pipeline IDs are global, so they are bundled relative to the tenant
+	pipelineIDs := make(map[string]string)
+	tenantPipelines := ce.pipelineOrch.GetPipelinesByTenant(tenantID)
+	sort.Slice(tenantPipelines, func(i, j int) bool { return tenantPipelines[i].ID < tenantPipelines[j].ID })
+	for _, p := range tenantPipelines {
+		if p.Spec == nil {
+			skipped = append(skipped, fmt.Sprintf("pipeline %s: not declared from a spec", p.ID))
+			continue
+		}
+		id := strings.TrimSuffix(p.ID, "-"+tenantID)
+		pipelineIDs[p.ID] = id
+		artifacts.Pipelines = append(artifacts.Pipelines, onboarding.Pipeline{ID: id, PipelineSpec: *p.Spec})
+	}
+
+	tenantTriggers := ce.triggerManager.GetTriggersByTenant(tenantID)
+	sort.Slice(tenantTriggers, func(i, j int) bool { return tenantTriggers[i].Name < tenantTriggers[j].Name })
+	for _, t := range tenantTriggers {
+		rule, err := bundleRule(t.Spec(), pipelineIDs)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("rule %s: %v", t.Name, err))
+			continue
+		}
+		artifacts.Rules = append(artifacts.Rules, rule)
+	}
+
+	ce.mu.RLock()
+	if config, set := ce.ruleSelections[tenantID]; set {
+		artifacts.RuleSelection = &config
+	}
+	ce.mu.RUnlock()
+	return artifacts, skipped
+}
+
+// bundleRule makes a trigger's pipeline actions refer to bundled pipelines
+func bundleRule(rule triggers.Spec, pipelineIDs map[string]string) (triggers.Spec, error) {
+	for i, action := range rule.Actions {
+		if action.Type != triggers.ActionExecutePipeline {
+			continue
+		}
+		id, bundled := pipelineIDs[action.PipelineID]
+		if !bundled {
+			return triggers.Spec{}, fmt.Errorf("runs pipeline %s, which is not bundled", action.PipelineID)
+		}
+		rule.Actions[i].PipelineID = id
+	}
+	return rule, nil
+}
+
+// exportOntology returns a tenant's mounts and its concepts linked by
+// inheritance
+func (ce *CognitiveEngine) exportOntology(tenantID string) onboarding.Ontology {
+	ontology := onboarding.Ontology{
+		Mounts:      ce.GetMounts(tenantID),
+		Concepts:    []onboarding.Concept{},
+		Inheritance: []onboarding.Inheritance{},
+	}
+	concepts := make(map[string]bool)
+	addConcept := func(atom atomspace.Atom) {
+		if concepts[atom.GetName()] {
+			return
+		}
+		concepts[atom.GetName()] = true
+		tv := atom.GetTruthValue()
+		ontology.Concepts = append(ontology.Concepts, onboarding.Concept{Name: atom.GetName(), Strength: tv.Strength, Confidence: tv.Confidence})
+	}
+
+	links := ce.shardManager.QueryAtoms(tenantID, func(atom atomspace.Atom) bool {
+		return atom.GetType() == atomspace.InheritanceLinkType
+	})
+	for _, atom := range links {
+		link, ok := atom.(*atomspace.Link)
+		if !ok || len(link.GetOutgoing()) != 2 {
+			continue
+		}
+		child, parent := link.GetOutgoing()[0], link.GetOutgoing()[1]
+		if child.GetType() != atomspace.ConceptNodeType || parent.GetType() != atomspace.ConceptNodeType {
+			continue
+		}
+		addConcept(child)
+		addConcept(parent)
+		ontology.Inheritance = append(ontology.Inheritance, onboarding.Inheritance{Child: child.GetName(), Parent: parent.GetName()})
+	}
+
+	sort.Slice(ontology.Concepts, func(i, j int) bool { return ontology.Concepts[i].Name < ontology.Concepts[j].Name })
+	sort.Slice(ontology.Inheritance, func(i, j int) bool {
+		a, b := ontology.Inheritance[i], ontology.Inheritance[j]
+		return a.Child < b.Child || (a.Child == b.Child && a.Parent < b.Parent)
+	})
+	return ontology
+}
+
+// GetBundle returns a bundle exported from a tenant; version 0 is the latest
+func (ce *CognitiveEngine) GetBundle(tenantID string, version int) (promotion.Bundle, error) {
+	return ce.bundles.Get(tenantID, version)
+}
+
+// ListBundles returns the bundles exported from a tenant, newest first
+func (ce *CognitiveEngine) ListBundles(tenantID string) []promotion.Summary {
+	return ce.bundles.List(tenantID)
+}
+
+// ImportBundle applies a bundle to a tenant. Its checksum and artifacts are
+// verified and its pipelines built before anything is changed; artifacts
+// are then created or replaced by name, and the tenant's other artifacts
+// are kept.
+func (ce *CognitiveEngine) ImportBundle(tenantID string, b promotion.Bundle) (promotion.Applied, error) {
+	var applied promotion.Applied
+	if err := b.Verify(); err != nil {
+		return applied, err
+	}
+	a := b.Artifacts
+	if err := a.Validate(); err != nil {
+		return applied, err
+	}
+	if !ce.isInitialized(tenantID) {
+		return applied, fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+	return ce.applyArtifacts(tenantID, a)
+}
+
+// applyArtifacts creates or replaces a tenant's artifacts by name, once
+// its pipelines are built and the shared spaces it mounts are known to
+// exist. The artifacts are expected to be valid.
+func (ce *CognitiveEngine) applyArtifacts(tenantID string, a promotion.Artifacts) (promotion.Applied, error) {
+	var applied promotion.Applied
+	ce.mu.RLock()
+	for _, spaceID := range a.Ontology.Mounts {
+		if _, exists := ce.sharedSpaces[spaceID]; !exists {
+			ce.mu.RUnlock()
+			return applied, fmt.Errorf("shared space %s not found", spaceID)
+		}
+	}
+	ce.mu.RUnlock()
+
+	pipelines := make([]*pipeline.Pipeline, len(a.Pipelines))
+	for i, p := range a.Pipelines {
+		built, err := ce.buildPipeline(p.PipelineID(tenantID), tenantID, p.PipelineSpec)
+		if err != nil {
+			return applied, fmt.Errorf("pipeline %s: %w", p.ID, err)
+		}
+		pipelines[i] = built
+	}
+
+	mounted := make(map[string]bool)
+	for _, spaceID := range ce.GetMounts(tenantID) {
+		mounted[spaceID] = true
+	}
+	for _, spaceID := range a.Ontology.Mounts {
+		if mounted[spaceID] {
+			continue
+		}
+		if err := ce.MountSharedSpace(tenantID, spaceID); err != nil {
+			return applied, err
+		}
+		applied.Mounts++
+	}
+
+	seeded, err := ce.seedOntology(tenantID, a.Ontology)
+	if err != nil {
+		return applied, err
+	}
+	applied.Concepts, applied.Links, applied.Conflicts = seeded.concepts, seeded.links, seeded.conflicts
+
+	for _, p := range pipelines {
+		if err := ce.pipelineOrch.ReplacePipeline(p); err != nil {
+			return applied, err
+		}
+		applied.Pipelines++
+	}
+
+	for _, rule := range a.Rules {
+		if err := ce.importRule(tenantID, rule); err != nil {
+			return applied, err
+		}
+		applied.Rules++
+	}
+
+	if a.RuleSelection != nil {
+		if current, _, err := ce.GetRuleSelection(tenantID); err != nil || current != *a.RuleSelection {
+			if err := ce.SetRuleSelection(tenantID, *a.RuleSelection); err != nil {
+				return applied, err
+			}
+			applied.RuleSelection = true
+		}
+	}
+
+	for _, q := range a.Queries {
+		if _, err := ce.savedQueries.Set(tenantID, q); err != nil {
+			return applied, err
+		}
+		applied.Queries++
+	}
+	return applied, nil
+}
+
+// importRule creates a tenant's trigger from a bundled rule, replacing the
+// trigger of the same name
+func (ce *CognitiveEngine) importRule(tenantID string, rule triggers.Spec) error {
+	for _, t := range ce.triggerManager.GetTriggersByTenant(tenantID) {
+		if t.Name == rule.Name {
+			ce.triggerManager.RemoveTrigger(t.ID, tenantID)
+		}
+	}
+
+	actions := append([]triggers.Action(nil), rule.Actions...)
+	for i := range actions {
+		if actions[i].Type == triggers.ActionExecutePipeline {
+			actions[i].PipelineID = onboarding.Pipeline{ID: actions[i].PipelineID}.PipelineID(tenantID)
+		}
+	}
+	return ce.triggerManager.AddTrigger(&triggers.Trigger{
+		ID:        rule.Name + "-" + tenantID,
+		TenantID:  tenantID,
+		Name:      rule.Name,
+		Condition: rule.Condition,
+		Actions:   actions,
+		Enabled:   rule.Enabled,
+		Cooldown:  time.Duration(rule.CooldownSeconds) * time.Second,
+	})
+}
+
+// SetPromotionTrack creates or replaces a promotion track
+func (ce *CognitiveEngine) SetPromotionTrack(t promotion.Track) (promotion.Track, error) {
+	return ce.tracks.Set(t)
+}
+
+// GetPromotionTrack returns a promotion track with the bundle each of its
+// environments last received
+func (ce *CognitiveEngine) GetPromotionTrack(name string) (promotion.TrackStatus, error) {
+	return ce.tracks.Status(name)
+}
+
+// ListPromotionTracks returns the promotion tracks
+func (ce *CognitiveEngine) ListPromotionTracks() []promotion.Track {
+	return ce.tracks.List()
+}
+
+// DeletePromotionTrack removes a promotion track and its history. The
+// tenants of its environments are unaffected.
+func (ce *CognitiveEngine) DeletePromotionTrack(name string) error {
+	return ce.tracks.Delete(name)
+}
+
+// GetPromotions returns the promotions of a track, newest first
+func (ce *CognitiveEngine) GetPromotions(name string) []promotion.Promotion {
+	return ce.tracks.History(name)
+}
+
+// Promote promotes the artifacts of an environment to the next one of its
+// track. Version 0 exports a new bundle from the environment's tenant;
+// otherwise an earlier bundle of that tenant is promoted, such as the one
+// that was tested there. Once the bundle is chosen, the promotion is
+// recorded whether or not it applies.
+func (ce *CognitiveEngine) Promote(trackName, from string, version int) (promotion.Promotion, error) {
+	t, err := ce.tracks.Get(trackName)
+	if err != nil {
+		return promotion.Promotion{}, err
+	}
+	source, target, err := t.Next(from)
+	if err != nil {
+		return promotion.Promotion{}, err
+	}
+
+	var b promotion.Bundle
+	if version == 0 {
+		b, err = ce.ExportBundle(source.TenantID)
+	} else {
+		b, err = ce.bundles.Get(source.TenantID, version)
+	}
+	if err != nil {
+		return promotion.Promotion{}, err
+	}
+
+	p := promotion.Promotion{
+		Track:      trackName,
+		From:       source.Name,
+		To:         target.Name,
+		FromTenant: source.TenantID,
+		ToTenant:   target.TenantID,
+		Version:    b.Version,
+		Checksum:   b.Checksum,
+	}
+	applied, err := ce.ImportBundle(target.TenantID, b)
+	if err != nil {
+		p.Error = err.Error()
+	} else {
+		p.Applied = &applied
+	}
+	p = ce.tracks.Record(p)
+	if err != nil {
+		return p, fmt.Errorf("promoting %s to %s failed: %w", source.Name, target.Name, err)
+	}
+	return p, nil
+}
+
+// isInitialized reports whether a tenant is initialized
+func (ce *CognitiveEngine) isInitialized(tenantID string) bool {
+	ce.mu.RLock()
+	defer ce.mu.RUnlock()
+
+	_, initialized := ce.inferenceEngines[tenantID]
+	return initialized
+}
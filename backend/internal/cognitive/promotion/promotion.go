@@ -0,0 +1,447 @@
+package promotion
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/onboarding"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/queries"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+)
+
+// Format is the version of the bundle format written by this engine
+const Format = 1
+
+const (
+	maxVersions = 20  // Bundles kept per tenant; older ones are dropped
+	maxHistory  = 100 // Promotions kept per track
+)
+
+// Artifacts are the curated configuration of a tenant carried by a bundle.
+// Pipeline IDs are relative to the tenant, and execute_pipeline actions of
+// rules refer to them.
+type Artifacts struct {
+	Rules         []triggers.Spec            `json:"rules"`
+	RuleSelection *inference.SelectionConfig `json:"rule_selection,omitempty"`
+	Pipelines     []onboarding.Pipeline      `json:"pipelines"`
+	Queries       []queries.Query            `json:"queries"`
+	Ontology      onboarding.Ontology        `json:"ontology"`
+}
+
+// Validate checks that the artifacts are consistent and complete
+func (a *Artifacts) Validate() error {
+	seed := onboarding.Template{Name: "bundle", Ontology: a.Ontology, Pipelines: a.Pipelines}
+	if err := seed.Validate(); err != nil {
+		return err
+	}
+	pipelines := make(map[string]bool, len(a.Pipelines))
+	for _, p := range a.Pipelines {
+		pipelines[p.ID] = true
+	}
+
+	rules := make(map[string]bool, len(a.Rules))
+	for _, rule := range a.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rule name is required")
+		}
+		if rules[rule.Name] {
+			return fmt.Errorf("duplicate rule %s", rule.Name)
+		}
+		rules[rule.Name] = true
+		if len(rule.Actions) == 0 {
+			return fmt.Errorf("rule %s has no actions", rule.Name)
+		}
+		for i := range rule.Actions {
+			if err := rule.Actions[i].Validate(); err != nil {
+				return fmt.Errorf("rule %s action %d: %w", rule.Name, i, err)
+			}
+			if rule.Actions[i].Type == triggers.ActionExecutePipeline && !pipelines[rule.Actions[i].PipelineID] {
+				return fmt.Errorf("rule %s runs pipeline %s, which is not in the bundle", rule.Name, rule.Actions[i].PipelineID)
+			}
+		}
+	}
+
+	if a.RuleSelection != nil {
+		if err := a.RuleSelection.Validate(); err != nil {
+			return err
+		}
+	}
+
+	names := make(map[string]bool, len(a.Queries))
+	for _, q := range a.Queries {
+		if err := q.Validate(); err != nil {
+			return err
+		}
+		if names[q.Name] {
+			return fmt.Errorf("duplicate query %s", q.Name)
+		}
+		names[q.Name] = true
+	}
+	return nil
+}
+
+// Checksum returns the SHA-256 digest of the artifacts' JSON encoding
+func Checksum(a Artifacts) (string, error) {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// Bundle is a versioned, checksummed export of a tenant's artifacts
+type Bundle struct {
+	Format    int       `json:"format"`
+	TenantID  string    `json:"tenant_id"` // Tenant the bundle was exported from
+	Version   int       `json:"version"`   // Increases with each export of the tenant
+	CreatedAt time.Time `json:"created_at"`
+	Checksum  string    `json:"checksum"`
+	Artifacts Artifacts `json:"artifacts"`
+	Skipped   []string  `json:"skipped,omitempty"` // Artifacts of the tenant that could not be bundled, and why
+}
+
+// Verify checks that the bundle's format is supported and that its
+// artifacts match its checksum
+func (b *Bundle) Verify() error {
+	if b.Format < 1 || b.Format > Format {
+		return fmt.Errorf("unsupported bundle format %d", b.Format)
+	}
+	sum, err := Checksum(b.Artifacts)
+	if err != nil {
+		return err
+	}
+	if sum != b.Checksum {
+		return fmt.Errorf("bundle checksum mismatch: expected %s, got %s", b.Checksum, sum)
+	}
+	return nil
+}
+
+// Summary describes a bundle without its artifacts
+type Summary struct {
+	TenantID  string         `json:"tenant_id"`
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	Checksum  string         `json:"checksum"`
+	Counts    map[string]int `json:"counts"` // Kind of artifact -> artifacts bundled
+}
+
+// Summary returns the bundle's summary
+func (b *Bundle) Summary() Summary {
+	counts := map[string]int{
+		"rules":     len(b.Artifacts.Rules),
+		"pipelines": len(b.Artifacts.Pipelines),
+		"queries":   len(b.Artifacts.Queries),
+		"concepts":  len(b.Artifacts.Ontology.Concepts),
+		"links":     len(b.Artifacts.Ontology.Inheritance),
+		"mounts":    len(b.Artifacts.Ontology.Mounts),
+	}
+	if b.Artifacts.RuleSelection != nil {
+		counts["rule_selection"] = 1
+	}
+	return Summary{TenantID: b.TenantID, Version: b.Version, CreatedAt: b.CreatedAt, Checksum: b.Checksum, Counts: counts}
+}
+
+// Applied counts the artifacts an import created or replaced
+type Applied struct {
+	Rules         int      `json:"rules"`
+	RuleSelection bool     `json:"rule_selection"`
+	Pipelines     int      `json:"pipelines"`
+	Queries       int      `json:"queries"`
+	Mounts        int      `json:"mounts"`
+	Concepts      int      `json:"concepts"`
+	Links         int      `json:"links"`
+	Conflicts     []string `json:"conflicts,omitempty"` // Ontology atoms whose IDs other tenants hold
+}
+
+// Store keeps the bundles exported from each tenant, up to the last 20
+type Store struct {
+	bundles map[string][]Bundle // tenantID -> bundles, oldest first
+	latest  map[string]int      // tenantID -> last version assigned
+	mu      sync.RWMutex
+}
+
+// NewStore creates an empty bundle store
+func NewStore() *Store {
+	return &Store{
+		bundles: make(map[string][]Bundle),
+		latest:  make(map[string]int),
+	}
+}
+
+// Add seals artifacts exported from a tenant into its next bundle version
+func (s *Store) Add(tenantID string, artifacts Artifacts, skipped []string) (Bundle, error) {
+	sum, err := Checksum(artifacts)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latest[tenantID]++
+	b := Bundle{
+		Format:    Format,
+		TenantID:  tenantID,
+		Version:   s.latest[tenantID],
+		CreatedAt: time.Now(),
+		Checksum:  sum,
+		Artifacts: artifacts,
+		Skipped:   skipped,
+	}
+	bundles := append(s.bundles[tenantID], b)
+	if len(bundles) > maxVersions {
+		bundles = bundles[len(bundles)-maxVersions:]
+	}
+	s.bundles[tenantID] = bundles
+	return b, nil
+}
+
+// Get returns a bundle of a tenant; version 0 is the latest
+func (s *Store) Get(tenantID string, version int) (Bundle, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bundles := s.bundles[tenantID]
+	if version == 0 && len(bundles) > 0 {
+		return bundles[len(bundles)-1], nil
+	}
+	for _, b := range bundles {
+		if b.Version == version {
+			return b, nil
+		}
+	}
+	return Bundle{}, fmt.Errorf("bundle %d of tenant %s not found", version, tenantID)
+}
+
+// List returns the summaries of a tenant's bundles, newest first
+func (s *Store) List(tenantID string) []Summary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bundles := s.bundles[tenantID]
+	result := make([]Summary, 0, len(bundles))
+	for i := len(bundles) - 1; i >= 0; i-- {
+		result = append(result, bundles[i].Summary())
+	}
+	return result
+}
+
+// Purge deletes all bundles of a tenant and returns how many were removed
+func (s *Store) Purge(tenantID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := len(s.bundles[tenantID])
+	delete(s.bundles, tenantID)
+	delete(s.latest, tenantID)
+	return purged
+}
+
+// Environment is a stage of a promotion track, backed by a tenant
+type Environment struct {
+	Name     string `json:"name"`
+	TenantID string `json:"tenant_id"`
+}
+
+// Track is the ordered chain of environments artifacts are promoted
+// through, such as dev, staging and prod
+type Track struct {
+	Name         string        `json:"name"`
+	Environments []Environment `json:"environments"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// Validate checks a track for consistency
+func (t *Track) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("track name is required")
+	}
+	if len(t.Environments) < 2 {
+		return fmt.Errorf("track %s needs at least two environments", t.Name)
+	}
+	names := make(map[string]bool, len(t.Environments))
+	tenants := make(map[string]bool, len(t.Environments))
+	for _, env := range t.Environments {
+		if env.Name == "" || env.TenantID == "" {
+			return fmt.Errorf("environments require a name and a tenant ID")
+		}
+		if names[env.Name] || tenants[env.TenantID] {
+			return fmt.Errorf("environment %s of tenant %s is not unique", env.Name, env.TenantID)
+		}
+		names[env.Name] = true
+		tenants[env.TenantID] = true
+	}
+	return nil
+}
+
+// Next returns an environment and the one it promotes to
+func (t *Track) Next(name string) (Environment, Environment, error) {
+	for i, env := range t.Environments {
+		if env.Name != name {
+			continue
+		}
+		if i == len(t.Environments)-1 {
+			return Environment{}, Environment{}, fmt.Errorf("%s is the last environment of track %s", name, t.Name)
+		}
+		return env, t.Environments[i+1], nil
+	}
+	return Environment{}, Environment{}, fmt.Errorf("environment %s not found in track %s", name, t.Name)
+}
+
+// Promotion records a bundle promoted from one environment to the next
+type Promotion struct {
+	ID         string    `json:"id"`
+	Track      string    `json:"track"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	FromTenant string    `json:"from_tenant"`
+	ToTenant   string    `json:"to_tenant"`
+	Version    int       `json:"version"` // Bundle version of the source tenant
+	Checksum   string    `json:"checksum"`
+	Applied    *Applied  `json:"applied,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	At         time.Time `json:"at"`
+}
+
+// Deployment is the bundle an environment last received
+type Deployment struct {
+	PromotionID string    `json:"promotion_id"`
+	FromTenant  string    `json:"from_tenant"`
+	Version     int       `json:"version"`
+	Checksum    string    `json:"checksum"`
+	At          time.Time `json:"at"`
+}
+
+// TrackStatus is a track with the bundle each environment last received
+type TrackStatus struct {
+	Track
+	Deployments map[string]Deployment `json:"deployments"` // Environment -> bundle
+}
+
+// Tracks holds the promotion tracks and their history
+type Tracks struct {
+	tracks  map[string]Track
+	history map[string][]Promotion // Track -> promotions, oldest first
+	seq     int64
+	mu      sync.RWMutex
+}
+
+// NewTracks creates an empty track registry
+func NewTracks() *Tracks {
+	return &Tracks{
+		tracks:  make(map[string]Track),
+		history: make(map[string][]Promotion),
+	}
+}
+
+// Set creates or replaces a track. Its history is kept.
+func (r *Tracks) Set(t Track) (Track, error) {
+	if err := t.Validate(); err != nil {
+		return Track{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if previous, exists := r.tracks[t.Name]; exists {
+		t.CreatedAt = previous.CreatedAt
+	} else {
+		t.CreatedAt = time.Now()
+	}
+	r.tracks[t.Name] = t
+	return t, nil
+}
+
+// Get returns a track
+func (r *Tracks) Get(name string) (Track, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, exists := r.tracks[name]
+	if !exists {
+		return Track{}, fmt.Errorf("track %s not found", name)
+	}
+	return t, nil
+}
+
+// Status returns a track with the bundle each environment last received
+func (r *Tracks) Status(name string) (TrackStatus, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, exists := r.tracks[name]
+	if !exists {
+		return TrackStatus{}, fmt.Errorf("track %s not found", name)
+	}
+	status := TrackStatus{Track: t, Deployments: make(map[string]Deployment)}
+	for _, p := range r.history[name] {
+		if p.Error == "" {
+			status.Deployments[p.To] = Deployment{PromotionID: p.ID, FromTenant: p.FromTenant, Version: p.Version, Checksum: p.Checksum, At: p.At}
+		}
+	}
+	return status, nil
+}
+
+// List returns the tracks sorted by name
+func (r *Tracks) List() []Track {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]Track, 0, len(r.tracks))
+	for _, t := range r.tracks {
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Delete removes a track and its history
+func (r *Tracks) Delete(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tracks[name]; !exists {
+		return fmt.Errorf("track %s not found", name)
+	}
+	delete(r.tracks, name)
+	delete(r.history, name)
+	return nil
+}
+
+// Record assigns a promotion its ID and adds it to its track's history
+func (r *Tracks) Record(p Promotion) Promotion {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seq++
+	p.ID = fmt.Sprintf("promotion-%d", r.seq)
+	if p.At.IsZero() {
+		p.At = time.Now()
+	}
+	history := append(r.history[p.Track], p)
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	r.history[p.Track] = history
+	return p
+}
+
+// History returns the promotions of a track, newest first
+func (r *Tracks) History(name string) []Promotion {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	history := r.history[name]
+	result := make([]Promotion, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		result = append(result, history[i])
+	}
+	return result
+}
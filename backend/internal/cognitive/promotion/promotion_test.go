@@ -0,0 +1,141 @@
+package promotion
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/onboarding"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/pipeline"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/queries"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+)
+
+func testArtifacts() Artifacts {
+	return Artifacts{
+		Rules: []triggers.Spec{{
+			Name:    "triage-anomalies",
+			Actions: []triggers.Action{{Type: triggers.ActionExecutePipeline, PipelineID: "triage"}},
+			Enabled: true,
+		}},
+		Pipelines: []onboarding.Pipeline{{
+			ID:           "triage",
+			PipelineSpec: pipeline.PipelineSpec{Name: "Triage", Stages: []pipeline.StageSpec{{Kind: "anomaly-score"}}},
+		}},
+		Queries: []queries.Query{{Name: "all"}},
+		Ontology: onboarding.Ontology{
+			Concepts:    []onboarding.Concept{{Name: "Pod", Strength: 1, Confidence: 0.9}, {Name: "Resource"}},
+			Inheritance: []onboarding.Inheritance{{Child: "Pod", Parent: "Resource"}},
+		},
+	}
+}
+
+func TestArtifactsValidate(t *testing.T) {
+	a := testArtifacts()
+	if err := a.Validate(); err != nil {
+		t.Fatalf("expected valid artifacts, got %v", err)
+	}
+
+	a.Rules[0].Actions[0].PipelineID = "missing"
+	if err := a.Validate(); err == nil {
+		t.Error("expected a rule running a pipeline outside the bundle to be rejected")
+	}
+	a = testArtifacts()
+	a.Queries = append(a.Queries, queries.Query{Name: "all"})
+	if err := a.Validate(); err == nil {
+		t.Error("expected duplicate queries to be rejected")
+	}
+	a = testArtifacts()
+	a.Ontology.Inheritance[0].Parent = "Node"
+	if err := a.Validate(); err == nil {
+		t.Error("expected links to concepts outside the ontology to be rejected")
+	}
+}
+
+func TestBundleChecksum(t *testing.T) {
+	s := NewStore()
+	b, err := s.Add("acme-dev", testArtifacts(), nil)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := b.Verify(); err != nil {
+		t.Fatalf("expected a sealed bundle to verify, got %v", err)
+	}
+
+	// A bundle survives a round trip through JSON, but not tampering
+	data, _ := json.Marshal(b)
+	var decoded Bundle
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.Verify(); err != nil {
+		t.Errorf("expected the decoded bundle to verify, got %v", err)
+	}
+	decoded.Artifacts.Rules[0].Enabled = false
+	if err := decoded.Verify(); err == nil {
+		t.Error("expected a modified bundle to fail verification")
+	}
+	decoded = b
+	decoded.Format = Format + 1
+	if err := decoded.Verify(); err == nil {
+		t.Error("expected an unknown format to be rejected")
+	}
+}
+
+func TestStoreVersions(t *testing.T) {
+	s := NewStore()
+	for i := 0; i < maxVersions+2; i++ {
+		s.Add("acme-dev", testArtifacts(), nil)
+	}
+	list := s.List("acme-dev")
+	if len(list) != maxVersions || list[0].Version != maxVersions+2 || list[0].Counts["rules"] != 1 {
+		t.Fatalf("expected the last %d versions, newest first, got %+v", maxVersions, list[0])
+	}
+	if _, err := s.Get("acme-dev", 1); err == nil {
+		t.Error("expected the oldest version dropped")
+	}
+	if latest, err := s.Get("acme-dev", 0); err != nil || latest.Version != maxVersions+2 {
+		t.Errorf("expected version 0 to return the latest, got %d (%v)", latest.Version, err)
+	}
+	if n := s.Purge("acme-dev"); n != maxVersions {
+		t.Errorf("expected %d bundles purged, got %d", maxVersions, n)
+	}
+	if b, _ := s.Add("acme-dev", testArtifacts(), nil); b.Version != 1 {
+		t.Errorf("expected versions to restart after a purge, got %d", b.Version)
+	}
+}
+
+func TestTracks(t *testing.T) {
+	r := NewTracks()
+	if _, err := r.Set(Track{Name: "acme", Environments: []Environment{{Name: "dev", TenantID: "acme-dev"}}}); err == nil {
+		t.Error("expected a track with one environment to be rejected")
+	}
+	track, err := r.Set(Track{Name: "acme", Environments: []Environment{
+		{Name: "dev", TenantID: "acme-dev"},
+		{Name: "staging", TenantID: "acme-staging"},
+		{Name: "prod", TenantID: "acme-prod"},
+	}})
+	if err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	from, to, err := track.Next("staging")
+	if err != nil || from.TenantID != "acme-staging" || to.Name != "prod" {
+		t.Errorf("expected staging to promote to prod, got %v -> %v (%v)", from, to, err)
+	}
+	if _, _, err := track.Next("prod"); err == nil {
+		t.Error("expected the last environment not to promote")
+	}
+
+	r.Record(Promotion{Track: "acme", From: "dev", To: "staging", Version: 1, Checksum: "sha256:a"})
+	r.Record(Promotion{Track: "acme", From: "dev", To: "staging", Version: 2, Checksum: "sha256:b", Error: "failed"})
+	status, err := r.Status("acme")
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if d := status.Deployments["staging"]; d.Version != 1 || d.PromotionID != "promotion-1" {
+		t.Errorf("expected staging to run the last successful promotion, got %+v", d)
+	}
+	if history := r.History("acme"); len(history) != 2 || history[0].Version != 2 {
+		t.Errorf("expected the history newest first, got %+v", history)
+	}
+}
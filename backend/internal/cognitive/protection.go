@@ -0,0 +1,78 @@
+package cognitive
+
+import (
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/events"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/provenance"
+)
+
+// requireAdmin refuses principals without the admin role, who may not
+// override the protection of an atom
+func requireAdmin(atomID string, p acl.Principal) error {
+	if !p.HasRole(acl.AdminRole) {
+		return &acl.ForbiddenError{Kind: acl.KindAtom, ID: atomID}
+	}
+	return nil
+}
+
+// ProtectAtom protects an atom of a tenant: agents, decay and API updates
+// can no longer change or delete it. Attention allocation still moves its
+// importance.
+func (ce *CognitiveEngine) ProtectAtom(tenantID, atomID string) error {
+	return ce.setProtected(tenantID, atomID, true)
+}
+
+// UnprotectAtom lifts the protection of an atom. Only admins may.
+func (ce *CognitiveEngine) UnprotectAtom(tenantID, atomID string, p acl.Principal) error {
+	if err := requireAdmin(atomID, p); err != nil {
+		return err
+	}
+	return ce.setProtected(tenantID, atomID, false)
+}
+
+func (ce *CognitiveEngine) setProtected(tenantID, atomID string, protected bool) error {
+	if err := ce.shardManager.SetProtected(atomID, tenantID, protected); err != nil {
+		if _, shared := ce.findMountedAtom(atomID, tenantID); shared {
+			return fmt.Errorf("atom %s belongs to a read-only shared space", atomID)
+		}
+		return err
+	}
+	atom, err := ce.shardManager.GetAtom(atomID, tenantID)
+	if err != nil {
+		return err
+	}
+	ce.provenance.Record(provenance.OperationUpdate, tenantID, atomID, atom)
+	ce.eventBus.Publish(events.Event{
+		Type:     events.AtomUpdated,
+		TenantID: tenantID,
+		AtomID:   atomID,
+		Atom:     atom,
+	})
+	return nil
+}
+
+// ListProtectedAtoms returns the protected atoms of a tenant
+func (ce *CognitiveEngine) ListProtectedAtoms(tenantID string) []atomspace.Atom {
+	return ce.shardManager.QueryAtoms(tenantID, atomspace.IsProtected)
+}
+
+// OverrideUpdateAtom updates an atom whether or not it is protected, for
+// admins. Protection is kept. Admission webhooks still review the update.
+func (ce *CognitiveEngine) OverrideUpdateAtom(atomID, tenantID string, updater func(atomspace.Atom) error, p acl.Principal) error {
+	if err := requireAdmin(atomID, p); err != nil {
+		return err
+	}
+	return ce.updateAtom(atomID, tenantID, updater, true)
+}
+
+// OverrideDeleteAtom deletes an atom whether or not it is protected, for
+// admins
+func (ce *CognitiveEngine) OverrideDeleteAtom(atomID, tenantID string, p acl.Principal) error {
+	if err := requireAdmin(atomID, p); err != nil {
+		return err
+	}
+	return ce.deleteAtom(atomID, tenantID, true)
+}
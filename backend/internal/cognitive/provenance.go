@@ -0,0 +1,57 @@
+package cognitive
+
+import (
+	"github.com/Avik2024/erebus/backend/internal/cognitive/provenance"
+)
+
+// ConfigureProvenance enables or changes signing and the ledger for a
+// tenant with compliance requirements. Atoms already stored are signed when
+// signing is first enabled; from then on every write through the engine is
+// signed and, with the ledger enabled, recorded in a hash chain. Atoms
+// restored from snapshots are not recorded, so they verify only if they
+// match what was signed and recorded before.
+func (ce *CognitiveEngine) ConfigureProvenance(tenantID string, config provenance.Config) (provenance.Info, error) {
+	info, enabled, err := ce.provenance.Configure(tenantID, config)
+	if err != nil || !enabled {
+		return info, err
+	}
+	for _, atom := range ce.shardManager.QueryAtoms(tenantID, nil) {
+		ce.provenance.Sign(atom)
+	}
+	return ce.provenance.Info(tenantID)
+}
+
+// GetProvenance returns a tenant's provenance configuration, public keys
+// and ledger head
+func (ce *CognitiveEngine) GetProvenance(tenantID string) (provenance.Info, error) {
+	return ce.provenance.Info(tenantID)
+}
+
+// DisableProvenance drops a tenant's keys, signatures and ledger
+func (ce *CognitiveEngine) DisableProvenance(tenantID string) error {
+	return ce.provenance.Remove(tenantID)
+}
+
+// RotateSigningKey replaces a tenant's active signing key
+func (ce *CognitiveEngine) RotateSigningKey(tenantID string) (provenance.Key, error) {
+	return ce.provenance.RotateKey(tenantID)
+}
+
+// VerifyAtom checks an atom against its signature and ledger record
+func (ce *CognitiveEngine) VerifyAtom(tenantID, atomID string) (provenance.Verification, error) {
+	atom, err := ce.shardManager.GetAtom(atomID, tenantID)
+	if err != nil {
+		return provenance.Verification{}, err
+	}
+	return ce.provenance.Verify(tenantID, atom), nil
+}
+
+// GetLedger returns a tenant's ledger entries after a sequence number
+func (ce *CognitiveEngine) GetLedger(tenantID string, since uint64, limit int) ([]provenance.Entry, error) {
+	return ce.provenance.Entries(tenantID, since, limit)
+}
+
+// VerifyLedger checks the hash chain and signatures of a tenant's ledger
+func (ce *CognitiveEngine) VerifyLedger(tenantID string) (provenance.LedgerVerification, error) {
+	return ce.provenance.VerifyLedger(tenantID)
+}
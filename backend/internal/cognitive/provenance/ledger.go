@@ -0,0 +1,154 @@
+package provenance
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Entry is one write in a tenant's ledger. Its hash covers the previous
+// entry's hash, so changing, removing or reordering recorded entries
+// breaks the chain.
+type Entry struct {
+	Sequence  uint64    `json:"sequence"`
+	Time      time.Time `json:"time"`
+	Operation Operation `json:"operation"`
+	AtomID    string    `json:"atom_id"`
+	Digest    string    `json:"digest,omitempty"` // Of the written atom; empty on delete
+	PrevHash  string    `json:"prev_hash"`
+	Hash      string    `json:"hash"`
+	KeyID     string    `json:"key_id,omitempty"` // Key signing the hash, if the tenant has one
+	Signature []byte    `json:"signature,omitempty"`
+}
+
+// LedgerVerification is the result of verifying a tenant's ledger
+type LedgerVerification struct {
+	Valid   bool   `json:"valid"`
+	Entries int    `json:"entries"`
+	Anchor  string `json:"anchor"` // PrevHash of the oldest kept entry, trusted as pruned history
+	Head    string `json:"head"`
+	Invalid uint64 `json:"invalid,omitempty"` // Sequence of the first entry failing verification
+	Reason  string `json:"reason,omitempty"`
+}
+
+// ledger is the hash chain of a tenant's writes
+type ledger struct {
+	entries  []Entry
+	sequence uint64
+	head     string
+}
+
+// hash returns the hex hash of an entry chained to its predecessor
+func (e *Entry) hash() string {
+	h := sha256.New()
+	h.Write([]byte(e.PrevHash))
+	binary.Write(h, binary.BigEndian, e.Sequence)
+	binary.Write(h, binary.BigEndian, e.Time.UnixNano())
+	for _, field := range []string{string(e.Operation), e.AtomID, e.Digest} {
+		binary.Write(h, binary.BigEndian, uint32(len(field)))
+		h.Write([]byte(field))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// append chains a new entry, signed with the given key if there is one, and
+// prunes the ledger to max entries
+func (l *ledger) append(op Operation, atomID, digest string, max int, keyID string, key ed25519.PrivateKey) {
+	l.sequence++
+	entry := Entry{
+		Sequence:  l.sequence,
+		Time:      time.Now(),
+		Operation: op,
+		AtomID:    atomID,
+		Digest:    digest,
+		PrevHash:  l.head,
+	}
+	entry.Hash = entry.hash()
+	if key != nil {
+		hash, _ := hex.DecodeString(entry.Hash)
+		entry.KeyID = keyID
+		entry.Signature = ed25519.Sign(key, hash)
+	}
+	l.head = entry.Hash
+	l.entries = append(l.entries, entry)
+	l.prune(max)
+}
+
+func (l *ledger) prune(max int) {
+	if max > 0 && len(l.entries) > max {
+		l.entries = append([]Entry(nil), l.entries[len(l.entries)-max:]...)
+	}
+}
+
+// Entries returns the ledger entries of a tenant after a sequence number,
+// oldest first, at most limit of them if limit is positive
+func (m *Manager) Entries(tenantID string, since uint64, limit int) ([]Entry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, exists := m.tenants[tenantID]
+	if !exists {
+		return nil, fmt.Errorf("provenance not configured for tenant %s", tenantID)
+	}
+	var result []Entry
+	for _, e := range t.ledger.entries {
+		if e.Sequence <= since {
+			continue
+		}
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// VerifyLedger recomputes the hash chain of a tenant's ledger and checks
+// the signatures of its entries
+func (m *Manager) VerifyLedger(tenantID string) (LedgerVerification, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, exists := m.tenants[tenantID]
+	if !exists {
+		return LedgerVerification{}, fmt.Errorf("provenance not configured for tenant %s", tenantID)
+	}
+
+	entries := t.ledger.entries
+	v := LedgerVerification{Valid: true, Entries: len(entries), Head: t.ledger.head}
+	if len(entries) == 0 {
+		return v, nil
+	}
+	v.Anchor = entries[0].PrevHash
+
+	fail := func(e Entry, reason string) (LedgerVerification, error) {
+		v.Valid, v.Invalid, v.Reason = false, e.Sequence, reason
+		return v, nil
+	}
+	prev := v.Anchor
+	for i, e := range entries {
+		switch {
+		case i > 0 && e.Sequence != entries[i-1].Sequence+1:
+			return fail(e, "sequence is not contiguous")
+		case e.PrevHash != prev:
+			return fail(e, "entry is not chained to its predecessor")
+		case e.hash() != e.Hash:
+			return fail(e, "entry does not match its hash")
+		}
+		if e.KeyID != "" {
+			key, found := t.key(e.KeyID)
+			hash, _ := hex.DecodeString(e.Hash)
+			if !found || !ed25519.Verify(key.PublicKey, hash, e.Signature) {
+				return fail(e, "invalid entry signature")
+			}
+		}
+		prev = e.Hash
+	}
+	if prev != t.ledger.head {
+		return fail(entries[len(entries)-1], "last entry is not the head of the ledger")
+	}
+	return v, nil
+}
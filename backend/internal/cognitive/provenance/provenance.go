@@ -0,0 +1,373 @@
+package provenance
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/persistence"
+)
+
+// DefaultMaxEntries bounds the ledger of a tenant by default
+const DefaultMaxEntries = 10000
+
+// Operation is the kind of write recorded in the ledger
+type Operation string
+
+const (
+	OperationCreate Operation = "create"
+	OperationUpdate Operation = "update"
+	OperationDelete Operation = "delete"
+)
+
+// Config selects the provenance features of a tenant
+type Config struct {
+	Signing    bool `json:"signing"`               // Sign atoms as they are written
+	Ledger     bool `json:"ledger"`                // Record writes in a hash-chained ledger
+	MaxEntries int  `json:"max_entries,omitempty"` // Ledger entries kept; older ones are pruned
+}
+
+// Validate checks the configuration and fills in defaults
+func (c *Config) Validate() error {
+	if c.MaxEntries == 0 {
+		c.MaxEntries = DefaultMaxEntries
+	}
+	if c.MaxEntries < 0 {
+		return fmt.Errorf("max_entries must be positive")
+	}
+	return nil
+}
+
+// Key is a public signing key of a tenant. Rotated keys are kept so that
+// earlier signatures still verify.
+type Key struct {
+	ID        string            `json:"id"`
+	PublicKey ed25519.PublicKey `json:"public_key"`
+	CreatedAt time.Time         `json:"created_at"`
+	Active    bool              `json:"active"`
+}
+
+// Signature is the signature value attached to an atom
+type Signature struct {
+	KeyID    string    `json:"key_id"`
+	Digest   string    `json:"digest"` // Hex SHA-256 of the signed content
+	Value    []byte    `json:"signature"`
+	SignedAt time.Time `json:"signed_at"`
+}
+
+// Status is the outcome of verifying an atom
+type Status string
+
+const (
+	StatusValid    Status = "valid"
+	StatusTampered Status = "tampered" // The atom no longer matches what was signed or recorded
+	StatusUnsigned Status = "unsigned" // Neither signed nor recorded
+)
+
+// Verification is the result of verifying an atom
+type Verification struct {
+	AtomID    string     `json:"atom_id"`
+	Status    Status     `json:"status"`
+	Digest    string     `json:"digest,omitempty"` // Of the atom as stored now
+	Signature *Signature `json:"signature,omitempty"`
+	Recorded  string     `json:"recorded,omitempty"` // Digest of the atom's last ledger entry
+	Reason    string     `json:"reason,omitempty"`
+}
+
+// Info describes the provenance state of a tenant
+type Info struct {
+	Config     Config `json:"config"`
+	Keys       []Key  `json:"keys"`
+	Signatures int    `json:"signatures"`
+	Entries    int    `json:"entries"`
+	Head       string `json:"head,omitempty"` // Hash of the last ledger entry
+}
+
+// tenant is the provenance state of one tenant
+type tenant struct {
+	config     Config
+	keys       []Key
+	private    ed25519.PrivateKey // Of the active key
+	signatures map[string]Signature
+	recorded   map[string]string // atomID -> digest of its last ledger entry
+	ledger     ledger
+}
+
+// Manager signs the atoms of tenants that enabled provenance and keeps
+// their ledgers
+type Manager struct {
+	tenants map[string]*tenant
+	mu      sync.RWMutex
+}
+
+// NewManager creates a provenance manager without tenants
+func NewManager() *Manager {
+	return &Manager{tenants: make(map[string]*tenant)}
+}
+
+// Digest returns the hex SHA-256 of an atom's content: its identity, type,
+// name, tenant, truth value and outgoing set. Attention values and
+// timestamps change without changing what the atom states, so they are not
+// part of it.
+func Digest(atom atomspace.Atom) string {
+	record := persistence.RecordFromAtom(atom)
+	record.AttentionValue = atomspace.AttentionValue{}
+	record.CreatedAt = time.Time{}
+	record.UpdatedAt = time.Time{}
+	sum := sha256.Sum256(persistence.AppendRecord(nil, record))
+	return hex.EncodeToString(sum[:])
+}
+
+// Configure sets the provenance configuration of a tenant. A signing key is
+// generated when signing is first enabled. It reports whether signing was
+// newly enabled, so the caller can sign the atoms already stored.
+func (m *Manager) Configure(tenantID string, config Config) (Info, bool, error) {
+	if err := config.Validate(); err != nil {
+		return Info{}, false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, exists := m.tenants[tenantID]
+	if !exists {
+		t = &tenant{
+			signatures: make(map[string]Signature),
+			recorded:   make(map[string]string),
+		}
+		m.tenants[tenantID] = t
+	}
+	enabled := config.Signing && !t.config.Signing
+	if config.Signing && t.private == nil {
+		if _, err := t.rotate(); err != nil {
+			return Info{}, false, err
+		}
+	}
+	t.config = config
+	t.ledger.prune(config.MaxEntries)
+	return t.info(), enabled, nil
+}
+
+// Info returns the provenance state of a tenant
+func (m *Manager) Info(tenantID string) (Info, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, exists := m.tenants[tenantID]
+	if !exists {
+		return Info{}, fmt.Errorf("provenance not configured for tenant %s", tenantID)
+	}
+	return t.info(), nil
+}
+
+// Remove drops a tenant's keys, signatures and ledger
+func (m *Manager) Remove(tenantID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.tenants[tenantID]; !exists {
+		return fmt.Errorf("provenance not configured for tenant %s", tenantID)
+	}
+	delete(m.tenants, tenantID)
+	return nil
+}
+
+// RotateKey replaces the active signing key of a tenant. Atoms signed with
+// earlier keys keep verifying; they are re-signed with the new key when
+// they are next written.
+func (m *Manager) RotateKey(tenantID string) (Key, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, exists := m.tenants[tenantID]
+	if !exists || !t.config.Signing {
+		return Key{}, fmt.Errorf("signing not enabled for tenant %s", tenantID)
+	}
+	return t.rotate()
+}
+
+// Sign signs an atom with the active key of its tenant, if the tenant
+// signs its atoms
+func (m *Manager) Sign(atom atomspace.Atom) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t, exists := m.tenants[atom.GetTenantID()]; exists && t.config.Signing {
+		t.sign(atom.GetID(), Digest(atom))
+	}
+}
+
+// Record signs a written atom and appends the write to the ledger of its
+// tenant. Updates that leave the content unchanged, e.g. of attention
+// values, are not recorded. atom is nil on delete.
+func (m *Manager) Record(op Operation, tenantID, atomID string, atom atomspace.Atom) {
+	m.mu.RLock()
+	_, exists := m.tenants[tenantID]
+	m.mu.RUnlock()
+	if !exists {
+		return
+	}
+
+	digest := ""
+	if atom != nil {
+		digest = Digest(atom)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, exists := m.tenants[tenantID]
+	if !exists {
+		return
+	}
+	if op == OperationDelete {
+		delete(t.signatures, atomID)
+	} else if t.config.Signing {
+		if s, signed := t.signatures[atomID]; !signed || s.Digest != digest || s.KeyID != t.activeKey() {
+			t.sign(atomID, digest)
+		}
+	}
+	if !t.config.Ledger {
+		return
+	}
+	if recorded, ok := t.recorded[atomID]; ok && recorded == digest && op != OperationDelete {
+		return
+	}
+	t.ledger.append(op, atomID, digest, t.config.MaxEntries, t.activeKey(), t.private)
+	if op == OperationDelete {
+		delete(t.recorded, atomID)
+	} else {
+		t.recorded[atomID] = digest
+	}
+}
+
+// GetSignature returns the signature of an atom
+func (m *Manager) GetSignature(tenantID, atomID string) (Signature, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, exists := m.tenants[tenantID]
+	if !exists {
+		return Signature{}, false
+	}
+	s, signed := t.signatures[atomID]
+	return s, signed
+}
+
+// Verify checks an atom as stored now against its signature and its last
+// ledger entry
+func (m *Manager) Verify(tenantID string, atom atomspace.Atom) Verification {
+	v := Verification{AtomID: atom.GetID(), Digest: Digest(atom), Status: StatusUnsigned}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, exists := m.tenants[tenantID]
+	if !exists {
+		return v
+	}
+	if recorded, ok := t.recorded[v.AtomID]; ok {
+		v.Recorded = recorded
+		v.Status = StatusValid
+		if recorded != v.Digest {
+			v.Status = StatusTampered
+			v.Reason = "atom does not match its last ledger entry"
+			return v
+		}
+	}
+	s, signed := t.signatures[v.AtomID]
+	if !signed {
+		return v
+	}
+	v.Signature = &s
+	v.Status = StatusValid
+	key, found := t.key(s.KeyID)
+	digest, _ := hex.DecodeString(s.Digest)
+	switch {
+	case !found:
+		v.Status, v.Reason = StatusTampered, fmt.Sprintf("unknown signing key %s", s.KeyID)
+	case s.Digest != v.Digest:
+		v.Status, v.Reason = StatusTampered, "atom does not match its signed content"
+	case !ed25519.Verify(key.PublicKey, digest, s.Value):
+		v.Status, v.Reason = StatusTampered, "invalid signature"
+	}
+	return v
+}
+
+// GetStats returns provenance statistics
+func (m *Manager) GetStats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	signing, signatures, entries := 0, 0, 0
+	for _, t := range m.tenants {
+		if t.config.Signing {
+			signing++
+		}
+		signatures += len(t.signatures)
+		entries += len(t.ledger.entries)
+	}
+	return map[string]interface{}{
+		"tenants":    len(m.tenants),
+		"signing":    signing,
+		"signatures": signatures,
+		"entries":    entries,
+	}
+}
+
+func (t *tenant) info() Info {
+	return Info{
+		Config:     t.config,
+		Keys:       append([]Key(nil), t.keys...),
+		Signatures: len(t.signatures),
+		Entries:    len(t.ledger.entries),
+		Head:       t.ledger.head,
+	}
+}
+
+// rotate generates a new active key
+func (t *tenant) rotate() (Key, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	for i := range t.keys {
+		t.keys[i].Active = false
+	}
+	sum := sha256.Sum256(public)
+	key := Key{ID: hex.EncodeToString(sum[:8]), PublicKey: public, CreatedAt: time.Now(), Active: true}
+	t.keys = append(t.keys, key)
+	t.private = private
+	return key, nil
+}
+
+func (t *tenant) activeKey() string {
+	if len(t.keys) == 0 {
+		return ""
+	}
+	return t.keys[len(t.keys)-1].ID
+}
+
+func (t *tenant) key(id string) (Key, bool) {
+	for _, k := range t.keys {
+		if k.ID == id {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+func (t *tenant) sign(atomID, digest string) {
+	sum, _ := hex.DecodeString(digest)
+	t.signatures[atomID] = Signature{
+		KeyID:    t.activeKey(),
+		Digest:   digest,
+		Value:    ed25519.Sign(t.private, sum),
+		SignedAt: time.Now(),
+	}
+}
@@ -0,0 +1,147 @@
+package provenance
+
+import (
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func concept(name string) *atomspace.Node {
+	return atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, "t", atomspace.ConceptNodeType)
+}
+
+func TestDigest(t *testing.T) {
+	a := concept("checkout")
+	digest := Digest(a)
+
+	a.SetAttentionValue(atomspace.AttentionValue{STI: 100})
+	if Digest(a) != digest {
+		t.Error("Expected attention values not to change the digest")
+	}
+	a.SetTruthValue(atomspace.TruthValue{Strength: 0.5, Confidence: 0.5})
+	if Digest(a) == digest {
+		t.Error("Expected the truth value to change the digest")
+	}
+	if Digest(concept("payments")) == digest {
+		t.Error("Expected atoms to have distinct digests")
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	m := NewManager()
+	a := concept("checkout")
+	if v := m.Verify("t", a); v.Status != StatusUnsigned {
+		t.Errorf("Expected an unsigned atom, got %+v", v)
+	}
+
+	info, enabled, err := m.Configure("t", Config{Signing: true})
+	if err != nil || !enabled || len(info.Keys) != 1 || !info.Keys[0].Active {
+		t.Fatalf("Expected signing to be enabled with a key, got %+v %v %v", info, enabled, err)
+	}
+	m.Record(OperationCreate, "t", a.GetID(), a)
+	if v := m.Verify("t", a); v.Status != StatusValid || v.Signature == nil {
+		t.Errorf("Expected a valid signature, got %+v", v)
+	}
+
+	// Old signatures keep verifying after a rotation
+	if _, err := m.RotateKey("t"); err != nil {
+		t.Fatalf("RotateKey failed: %v", err)
+	}
+	if v := m.Verify("t", a); v.Status != StatusValid {
+		t.Errorf("Expected the signature of the rotated key to verify, got %+v", v)
+	}
+	m.Record(OperationUpdate, "t", a.GetID(), a)
+	if s, _ := m.GetSignature("t", a.GetID()); s.KeyID != m.tenants["t"].activeKey() {
+		t.Errorf("Expected the atom to be re-signed with the new key, got %s", s.KeyID)
+	}
+
+	a.SetTruthValue(atomspace.TruthValue{Strength: 0.1, Confidence: 0.9})
+	if v := m.Verify("t", a); v.Status != StatusTampered {
+		t.Errorf("Expected a change outside the engine to be detected, got %+v", v)
+	}
+
+	s := m.tenants["t"].signatures[a.GetID()]
+	s.Digest = Digest(a)
+	m.tenants["t"].signatures[a.GetID()] = s
+	if v := m.Verify("t", a); v.Status != StatusTampered || v.Reason != "invalid signature" {
+		t.Errorf("Expected a forged signature to be detected, got %+v", v)
+	}
+
+	m.Record(OperationDelete, "t", a.GetID(), nil)
+	if _, signed := m.GetSignature("t", a.GetID()); signed {
+		t.Error("Expected the signature of a deleted atom to be removed")
+	}
+}
+
+func TestLedger(t *testing.T) {
+	m := NewManager()
+	m.Configure("t", Config{Ledger: true, MaxEntries: 3})
+
+	a := concept("checkout")
+	m.Record(OperationCreate, "t", a.GetID(), a)
+	a.SetAttentionValue(atomspace.AttentionValue{STI: 10})
+	m.Record(OperationUpdate, "t", a.GetID(), a)
+	if entries, _ := m.Entries("t", 0, 0); len(entries) != 1 {
+		t.Fatalf("Expected updates of attention not to be recorded, got %d entries", len(entries))
+	}
+
+	a.SetTruthValue(atomspace.TruthValue{Strength: 0.8, Confidence: 0.8})
+	m.Record(OperationUpdate, "t", a.GetID(), a)
+	b := concept("payments")
+	m.Record(OperationCreate, "t", b.GetID(), b)
+	m.Record(OperationDelete, "t", b.GetID(), nil)
+
+	entries, _ := m.Entries("t", 0, 0)
+	if len(entries) != 3 || entries[0].Sequence != 2 || entries[2].Operation != OperationDelete || entries[0].KeyID != "" {
+		t.Fatalf("Expected the last 3 unsigned entries, got %+v", entries)
+	}
+	if since, _ := m.Entries("t", 3, 1); len(since) != 1 || since[0].Sequence != 4 {
+		t.Errorf("Expected the entry after sequence 3, got %+v", since)
+	}
+
+	v, _ := m.VerifyLedger("t")
+	if !v.Valid || v.Entries != 3 || v.Anchor != entries[0].PrevHash || v.Head != entries[2].Hash {
+		t.Errorf("Expected a valid pruned ledger, got %+v", v)
+	}
+	if v := m.Verify("t", a); v.Status != StatusValid || v.Recorded == "" {
+		t.Errorf("Expected the atom to match its ledger entry, got %+v", v)
+	}
+
+	// Rewriting history breaks the chain at the changed entry
+	m.tenants["t"].ledger.entries[1].AtomID = a.GetID()
+	if v, _ := m.VerifyLedger("t"); v.Valid || v.Invalid != 3 {
+		t.Errorf("Expected the changed entry to fail verification, got %+v", v)
+	}
+	m.tenants["t"].ledger.entries = append(m.tenants["t"].ledger.entries[:1], m.tenants["t"].ledger.entries[2])
+	if v, _ := m.VerifyLedger("t"); v.Valid || v.Invalid != 4 {
+		t.Errorf("Expected a removed entry to fail verification, got %+v", v)
+	}
+}
+
+func TestSignedLedger(t *testing.T) {
+	m := NewManager()
+	m.Configure("t", Config{Signing: true, Ledger: true})
+
+	a := concept("checkout")
+	m.Record(OperationCreate, "t", a.GetID(), a)
+	m.RotateKey("t")
+	a.SetTruthValue(atomspace.TruthValue{Strength: 0.8, Confidence: 0.8})
+	m.Record(OperationUpdate, "t", a.GetID(), a)
+
+	entries, _ := m.Entries("t", 0, 0)
+	if len(entries) != 2 || entries[0].KeyID == "" || entries[0].KeyID == entries[1].KeyID {
+		t.Fatalf("Expected entries signed with both keys, got %+v", entries)
+	}
+	if v, _ := m.VerifyLedger("t"); !v.Valid {
+		t.Errorf("Expected a valid ledger, got %+v", v)
+	}
+
+	// Recomputing the hash of a changed entry does not forge its signature
+	e := &m.tenants["t"].ledger.entries[1]
+	e.Digest = Digest(concept("payments"))
+	e.Hash = e.hash()
+	m.tenants["t"].ledger.head = e.Hash
+	if v, _ := m.VerifyLedger("t"); v.Valid || v.Reason != "invalid entry signature" {
+		t.Errorf("Expected the forged entry to fail verification, got %+v", v)
+	}
+}
@@ -0,0 +1,32 @@
+package cognitive
+
+import (
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/queries"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sharding"
+)
+
+// SetSavedQuery creates or replaces a tenant's saved query
+func (ce *CognitiveEngine) SetSavedQuery(tenantID string, q queries.Query) (queries.Query, error) {
+	return ce.savedQueries.Set(tenantID, q)
+}
+
+// GetSavedQuery returns a tenant's saved query
+func (ce *CognitiveEngine) GetSavedQuery(tenantID, name string) (queries.Query, error) {
+	return ce.savedQueries.Get(tenantID, name)
+}
+
+// ListSavedQueries returns a tenant's saved queries
+func (ce *CognitiveEngine) ListSavedQueries(tenantID string) []queries.Query {
+	return ce.savedQueries.List(tenantID)
+}
+
+// DeleteSavedQuery removes a tenant's saved query
+func (ce *CognitiveEngine) DeleteSavedQuery(tenantID, name string) error {
+	return ce.savedQueries.Delete(tenantID, name)
+}
+
+// RunSavedQuery runs a tenant's saved query, like FindAtoms
+func (ce *CognitiveEngine) RunSavedQuery(tenantID, name string) ([]atomspace.Atom, []sharding.ShardPlan, error) {
+	return ce.Reader(sharding.Consistency{}).RunSavedQuery(tenantID, name)
+}
@@ -0,0 +1,112 @@
+package queries
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// Query is an atom query saved under a name, so it can be run again and
+// promoted between tenants
+type Query struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Query       atomspace.Query `json:"query"`
+}
+
+// Validate checks a saved query for consistency
+func (q *Query) Validate() error {
+	if q.Name == "" {
+		return fmt.Errorf("query name is required")
+	}
+	if q.Query.Limit < 0 {
+		return fmt.Errorf("query limit must not be negative")
+	}
+	for name, r := range map[string]*atomspace.Range{"strength": q.Query.Strength, "confidence": q.Query.Confidence} {
+		if r != nil && r.Min > r.Max {
+			return fmt.Errorf("query %s range is empty", name)
+		}
+	}
+	return nil
+}
+
+// Registry holds the saved queries of each tenant
+type Registry struct {
+	queries map[string]map[string]Query // tenantID -> name -> query
+	mu      sync.RWMutex
+}
+
+// NewRegistry creates an empty query registry
+func NewRegistry() *Registry {
+	return &Registry{
+		queries: make(map[string]map[string]Query),
+	}
+}
+
+// Set creates or replaces a saved query
+func (r *Registry) Set(tenantID string, q Query) (Query, error) {
+	if err := q.Validate(); err != nil {
+		return Query{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tenant, exists := r.queries[tenantID]
+	if !exists {
+		tenant = make(map[string]Query)
+		r.queries[tenantID] = tenant
+	}
+	tenant[q.Name] = q
+	return q, nil
+}
+
+// Get returns a saved query
+func (r *Registry) Get(tenantID, name string) (Query, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	q, exists := r.queries[tenantID][name]
+	if !exists {
+		return Query{}, fmt.Errorf("query %s not found", name)
+	}
+	return q, nil
+}
+
+// List returns a tenant's saved queries sorted by name
+func (r *Registry) List(tenantID string) []Query {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]Query, 0, len(r.queries[tenantID]))
+	for _, q := range r.queries[tenantID] {
+		result = append(result, q)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Delete removes a saved query
+func (r *Registry) Delete(tenantID, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.queries[tenantID][name]; !exists {
+		return fmt.Errorf("query %s not found", name)
+	}
+	delete(r.queries[tenantID], name)
+	return nil
+}
+
+// Purge deletes all saved queries of a tenant and returns how many were
+// removed
+func (r *Registry) Purge(tenantID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := len(r.queries[tenantID])
+	delete(r.queries, tenantID)
+	return purged
+}
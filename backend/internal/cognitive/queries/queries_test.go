@@ -0,0 +1,41 @@
+package queries
+
+import (
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	concept := atomspace.ConceptNodeType
+	q := Query{Name: "strong-concepts", Query: atomspace.Query{Type: &concept, Strength: &atomspace.Range{Min: 0.8, Max: 1}}}
+	if _, err := r.Set("acme", q); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, err := r.Set("acme", Query{Name: "empty", Query: atomspace.Query{Confidence: &atomspace.Range{Min: 1, Max: 0}}}); err == nil {
+		t.Error("expected an empty range to be rejected")
+	}
+	if _, err := r.Set("acme", Query{}); err == nil {
+		t.Error("expected a query without a name to be rejected")
+	}
+
+	got, err := r.Get("acme", "strong-concepts")
+	if err != nil || got.Query.Strength.Min != 0.8 {
+		t.Fatalf("expected the saved query, got %+v (%v)", got, err)
+	}
+	if _, err := r.Get("globex", "strong-concepts"); err == nil {
+		t.Error("expected queries to be kept per tenant")
+	}
+	if list := r.List("acme"); len(list) != 1 {
+		t.Errorf("expected 1 query, got %d", len(list))
+	}
+
+	if err := r.Delete("acme", "strong-concepts"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	r.Set("acme", q)
+	if n := r.Purge("acme"); n != 1 || len(r.List("acme")) != 0 {
+		t.Errorf("expected the tenant's queries purged, removed %d", n)
+	}
+}
@@ -0,0 +1,134 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/recommendations"
+)
+
+// EnableRecommendations registers a recommendation agent for a tenant, or
+// updates the configuration of the existing one
+func (ce *CognitiveEngine) EnableRecommendations(tenantID string, config agents.RecommendationConfig) *agents.RecommendationAgent {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if agent, exists := ce.recommenders[tenantID]; exists {
+		agent.SetConfig(config)
+		return agent
+	}
+
+	agent := agents.NewRecommendationAgent(
+		fmt.Sprintf("recommendation-%s", tenantID),
+		"RecommendationAgent",
+		tenantID,
+		&tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID},
+		ce.costModel,
+		ce.recommendations,
+		config,
+	)
+	ce.recommenders[tenantID] = agent
+	ce.agentScheduler.RegisterAgent(agent)
+	return agent
+}
+
+// DisableRecommendations unregisters a tenant's recommendation agent.
+// Recommendations already made are kept.
+func (ce *CognitiveEngine) DisableRecommendations(tenantID string) error {
+	ce.mu.Lock()
+	agent, exists := ce.recommenders[tenantID]
+	delete(ce.recommenders, tenantID)
+	ce.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("recommendations not enabled for tenant %s", tenantID)
+	}
+	ce.agentScheduler.UnregisterAgent(agent.GetID())
+	return nil
+}
+
+// RunRecommendations reviews a tenant's resources immediately, enabling
+// recommendations with the default configuration if needed
+func (ce *CognitiveEngine) RunRecommendations(ctx context.Context, tenantID string) ([]recommendations.Recommendation, error) {
+	ce.mu.RLock()
+	agent, exists := ce.recommenders[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		agent = ce.EnableRecommendations(tenantID, agents.DefaultRecommendationConfig())
+	}
+	return agent.Recommend(ctx)
+}
+
+// GetRecommendationAgent returns a tenant's recommendation agent
+func (ce *CognitiveEngine) GetRecommendationAgent(tenantID string) (*agents.RecommendationAgent, error) {
+	ce.mu.RLock()
+	defer ce.mu.RUnlock()
+
+	agent, exists := ce.recommenders[tenantID]
+	if !exists {
+		return nil, fmt.Errorf("recommendations not enabled for tenant %s", tenantID)
+	}
+	return agent, nil
+}
+
+// ListRecommendations returns a tenant's recommendations with a status, or
+// all of them, the largest savings first
+func (ce *CognitiveEngine) ListRecommendations(tenantID string, status recommendations.Status) []recommendations.Recommendation {
+	return ce.recommendations.List(tenantID, status)
+}
+
+// GetRecommendation returns a recommendation of a tenant
+func (ce *CognitiveEngine) GetRecommendation(tenantID, id string) (recommendations.Recommendation, error) {
+	return ce.recommendations.Get(tenantID, id)
+}
+
+// SubmitRecommendation puts a recommendation in the tenant's approval queue
+func (ce *CognitiveEngine) SubmitRecommendation(tenantID, id string, p acl.Principal) (recommendations.Recommendation, error) {
+	return ce.recommendations.Submit(tenantID, id, p.User)
+}
+
+// DecideRecommendation approves or rejects a recommendation waiting for
+// approval. Approving a merge merges its concepts; approving any other
+// recommendation performs the recommendation agent's OnApproval action
+// with it. If either fails the recommendation stays in the queue.
+func (ce *CognitiveEngine) DecideRecommendation(ctx context.Context, tenantID, id string, approve bool, note string, p acl.Principal) (recommendations.Recommendation, error) {
+	rec, err := ce.recommendations.Get(tenantID, id)
+	if err != nil {
+		return recommendations.Recommendation{}, err
+	}
+	if rec.Status != recommendations.StatusPending {
+		return recommendations.Recommendation{}, fmt.Errorf("recommendation %s is %s, not pending approval", id, rec.Status)
+	}
+
+	if approve && rec.Kind == recommendations.KindMerge {
+		if len(rec.Resources) < 2 {
+			return recommendations.Recommendation{}, fmt.Errorf("recommendation %s names no atoms to merge", id)
+		}
+		if _, err := ce.MergeAtoms(tenantID, rec.Resources[0], rec.Resources[1:]); err != nil {
+			return recommendations.Recommendation{}, fmt.Errorf("merge failed: %w", err)
+		}
+	} else if approve {
+		if agent, err := ce.GetRecommendationAgent(tenantID); err == nil {
+			if action := agent.GetConfig().OnApproval; action != nil {
+				var input []atomspace.Atom
+				if atom, err := ce.GetAtom(rec.AtomID, tenantID); err == nil {
+					input = []atomspace.Atom{atom}
+				}
+				payload := map[string]interface{}{
+					"tenant_id":      tenantID,
+					"recommendation": rec,
+					"approved_by":    p.User,
+				}
+				performer := ce.sandbox.ForTenant(tenantID).Actions(ce.triggerManager)
+				if err := performer.Perform(ctx, *action, input, payload); err != nil {
+					return recommendations.Recommendation{}, fmt.Errorf("approval action failed: %w", err)
+				}
+			}
+		}
+	}
+	return ce.recommendations.Decide(tenantID, id, approve, p.User, note)
+}
@@ -0,0 +1,255 @@
+package recommendations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is what a recommendation proposes
+type Kind string
+
+const (
+	KindResize      Kind = "resize"      // Move an underutilized resource to a smaller size
+	KindScale       Kind = "scale"       // Add capacity to a saturated resource
+	KindConsolidate Kind = "consolidate" // Drain hosts whose workloads fit on the others
+	KindMerge       Kind = "merge"       // Merge concepts standing for the same entity
+)
+
+// Status is where a recommendation is in the approval queue
+type Status string
+
+const (
+	StatusOpen     Status = "open"    // Not submitted for approval
+	StatusPending  Status = "pending" // Waiting in the approval queue
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+)
+
+// Recommendation proposes a change to a tenant's infrastructure with the
+// savings expected from it
+type Recommendation struct {
+	ID             string    `json:"id"` // Stable across runs for the same kind and subject
+	Kind           Kind      `json:"kind"`
+	Subject        string    `json:"subject"`   // The resource, or the category of the hosts
+	Resources      []string  `json:"resources"` // The resource resized or scaled, or the hosts to drain
+	Utilization    float64   `json:"utilization"`
+	Reason         string    `json:"reason"`
+	MonthlySavings float64   `json:"monthly_savings"` // Negative when capacity is added
+	Currency       string    `json:"currency"`
+	Confidence     float64   `json:"confidence"`
+	AtomID         string    `json:"atom_id"`
+	Status         Status    `json:"status"`
+	SubmittedBy    string    `json:"submitted_by,omitempty"`
+	SubmittedAt    time.Time `json:"submitted_at,omitempty"`
+	DecidedBy      string    `json:"decided_by,omitempty"`
+	DecidedAt      time.Time `json:"decided_at,omitempty"`
+	Note           string    `json:"note,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ID returns the ID of the recommendation of a kind about a subject, a
+// resource or a category of hosts
+func ID(kind Kind, subject string) string {
+	sum := sha256.Sum256([]byte(string(kind) + "/" + subject))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (r *Recommendation) clone() Recommendation {
+	c := *r
+	c.Resources = append([]string(nil), r.Resources...)
+	return c
+}
+
+// Store holds each tenant's recommendations and their approval queue
+type Store struct {
+	recommendations map[string]map[string]*Recommendation // tenantID -> ID -> recommendation
+	onSubmit        func(tenantID string, rec Recommendation)
+	mu              sync.RWMutex
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{recommendations: make(map[string]map[string]*Recommendation)}
+}
+
+// OnSubmit sets a function called with each recommendation put in the
+// approval queue
+func (s *Store) OnSubmit(f func(tenantID string, rec Recommendation)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onSubmit = f
+}
+
+// Update replaces a tenant's open recommendations with those of a new run
+// and returns the IDs of those dropped. Recommendations already submitted
+// or decided keep their status, whether or not the run made them again, so
+// a rejected recommendation does not come back. Given kinds, only open
+// recommendations of those kinds are replaced, so that runs making
+// different kinds do not drop each other's.
+func (s *Store) Update(tenantID string, recs []Recommendation, kinds ...Kind) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := s.recommendations[tenantID]
+	next := make(map[string]*Recommendation, len(recs))
+	now := time.Now()
+	for _, rec := range recs {
+		stored := rec.clone()
+		stored.Status, stored.CreatedAt, stored.UpdatedAt = StatusOpen, now, now
+		if previous, ok := existing[rec.ID]; ok {
+			stored.Status, stored.CreatedAt = previous.Status, previous.CreatedAt
+			stored.SubmittedBy, stored.SubmittedAt = previous.SubmittedBy, previous.SubmittedAt
+			stored.DecidedBy, stored.DecidedAt, stored.Note = previous.DecidedBy, previous.DecidedAt, previous.Note
+		}
+		next[rec.ID] = &stored
+	}
+
+	var dropped []string
+	for id, previous := range existing {
+		if _, kept := next[id]; kept {
+			continue
+		}
+		if previous.Status == StatusOpen && hasKind(kinds, previous.Kind) {
+			dropped = append(dropped, id)
+			continue
+		}
+		next[id] = previous
+	}
+	s.recommendations[tenantID] = next
+	sort.Strings(dropped)
+	return dropped
+}
+
+// hasKind reports whether kind is one of kinds, or kinds is empty
+func hasKind(kinds []Kind, kind Kind) bool {
+	if len(kinds) == 0 {
+		return true
+	}
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns a tenant's recommendations with the given status, or all of
+// them, the largest savings first
+func (s *Store) List(tenantID string, status Status) []Recommendation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Recommendation, 0)
+	for _, rec := range s.recommendations[tenantID] {
+		if status == "" || rec.Status == status {
+			result = append(result, rec.clone())
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].MonthlySavings != result[j].MonthlySavings {
+			return result[i].MonthlySavings > result[j].MonthlySavings
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Get returns a recommendation of a tenant
+func (s *Store) Get(tenantID, id string) (Recommendation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rec, exists := s.recommendations[tenantID][id]
+	if !exists {
+		return Recommendation{}, fmt.Errorf("recommendation %s not found", id)
+	}
+	return rec.clone(), nil
+}
+
+// Submit puts an open recommendation in the approval queue
+func (s *Store) Submit(tenantID, id, by string) (Recommendation, error) {
+	s.mu.Lock()
+	rec, exists := s.recommendations[tenantID][id]
+	if !exists {
+		s.mu.Unlock()
+		return Recommendation{}, fmt.Errorf("recommendation %s not found", id)
+	}
+	if rec.Status != StatusOpen {
+		s.mu.Unlock()
+		return Recommendation{}, fmt.Errorf("recommendation %s is already %s", id, rec.Status)
+	}
+	rec.Status, rec.SubmittedBy, rec.SubmittedAt = StatusPending, by, time.Now()
+	submitted, onSubmit := rec.clone(), s.onSubmit
+	s.mu.Unlock()
+
+	if onSubmit != nil {
+		onSubmit(tenantID, submitted)
+	}
+	return submitted, nil
+}
+
+// Decide approves or rejects a recommendation waiting in the approval queue
+func (s *Store) Decide(tenantID, id string, approve bool, by, note string) (Recommendation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, exists := s.recommendations[tenantID][id]
+	if !exists {
+		return Recommendation{}, fmt.Errorf("recommendation %s not found", id)
+	}
+	if rec.Status != StatusPending {
+		return Recommendation{}, fmt.Errorf("recommendation %s is %s, not pending approval", id, rec.Status)
+	}
+	rec.Status = StatusRejected
+	if approve {
+		rec.Status = StatusApproved
+	}
+	rec.DecidedBy, rec.DecidedAt, rec.Note = by, time.Now(), note
+	return rec.clone(), nil
+}
+
+// Purge removes a tenant's recommendations and returns how many there were
+func (s *Store) Purge(tenantID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := len(s.recommendations[tenantID])
+	delete(s.recommendations, tenantID)
+	return removed
+}
+
+// GetStats returns store statistics
+func (s *Store) GetStats() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byStatus := make(map[string]int)
+	total := 0
+	for _, recs := range s.recommendations {
+		for _, rec := range recs {
+			byStatus[string(rec.Status)]++
+			total++
+		}
+	}
+	return map[string]interface{}{
+		"tenants":         len(s.recommendations),
+		"recommendations": total,
+		"by_status":       byStatus,
+	}
+}
+
+// ParseStatus checks a status filter; empty matches every status
+func ParseStatus(value string) (Status, error) {
+	status := Status(strings.ToLower(value))
+	switch status {
+	case "", StatusOpen, StatusPending, StatusApproved, StatusRejected:
+		return status, nil
+	}
+	return "", fmt.Errorf("unknown recommendation status: %s", value)
+}
@@ -0,0 +1,110 @@
+package recommendations
+
+import "testing"
+
+func recommendation(kind Kind, subject string, savings float64) Recommendation {
+	return Recommendation{
+		ID:             ID(kind, subject),
+		Kind:           kind,
+		Subject:        subject,
+		Resources:      []string{subject},
+		MonthlySavings: savings,
+		Currency:       "USD",
+		Confidence:     0.8,
+	}
+}
+
+func TestUpdateKeepsQueuedRecommendations(t *testing.T) {
+	s := NewStore()
+	resize := recommendation(KindResize, "cache/a", 100)
+	scale := recommendation(KindScale, "db/a", -50)
+	idle := recommendation(KindResize, "vm/b", 20)
+	s.Update("t1", []Recommendation{resize, scale, idle})
+
+	if _, err := s.Submit("t1", scale.ID, "bob"); err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	if _, err := s.Submit("t1", scale.ID, "bob"); err == nil {
+		t.Error("Expected a pending recommendation not to be submitted again")
+	}
+	if _, err := s.Submit("t1", idle.ID, "bob"); err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	if _, err := s.Decide("t1", idle.ID, false, "alice", "needed for batch jobs"); err != nil {
+		t.Fatalf("Failed to reject: %v", err)
+	}
+
+	// The next run makes the resize and the rejected recommendation again,
+	// but not the scale
+	resize.MonthlySavings = 120
+	dropped := s.Update("t1", []Recommendation{resize, idle})
+	if len(dropped) != 0 {
+		t.Errorf("Expected nothing dropped, got %v", dropped)
+	}
+	if rec, _ := s.Get("t1", scale.ID); rec.Status != StatusPending {
+		t.Errorf("Expected the pending recommendation kept, got %+v", rec)
+	}
+	if rec, _ := s.Get("t1", idle.ID); rec.Status != StatusRejected || rec.Note == "" {
+		t.Errorf("Expected the rejection kept, got %+v", rec)
+	}
+	if rec, _ := s.Get("t1", resize.ID); rec.MonthlySavings != 120 || rec.Status != StatusOpen {
+		t.Errorf("Expected the open recommendation refreshed, got %+v", rec)
+	}
+
+	dropped = s.Update("t1", nil)
+	if len(dropped) != 1 || dropped[0] != resize.ID {
+		t.Errorf("Expected the open recommendation dropped, got %v", dropped)
+	}
+	if list := s.List("t1", ""); len(list) != 2 || list[0].ID != idle.ID {
+		t.Errorf("Expected the queued recommendations, largest savings first, got %+v", list)
+	}
+}
+
+func TestUpdateKinds(t *testing.T) {
+	s := NewStore()
+	resize := recommendation(KindResize, "cache/a", 100)
+	merge := recommendation(KindMerge, "host-a/Host A", 0)
+	s.Update("t1", []Recommendation{resize})
+	s.Update("t1", []Recommendation{merge}, KindMerge)
+
+	if list := s.List("t1", StatusOpen); len(list) != 2 {
+		t.Errorf("Expected recommendations of other kinds kept, got %+v", list)
+	}
+	dropped := s.Update("t1", nil, KindResize, KindScale)
+	if len(dropped) != 1 || dropped[0] != resize.ID {
+		t.Errorf("Expected only the resize dropped, got %v", dropped)
+	}
+	if _, err := s.Get("t1", merge.ID); err != nil {
+		t.Errorf("Expected the merge kept: %v", err)
+	}
+}
+
+func TestDecide(t *testing.T) {
+	s := NewStore()
+	rec := recommendation(KindConsolidate, "KubernetesNode", 146)
+	s.Update("t1", []Recommendation{rec})
+
+	if _, err := s.Decide("t1", rec.ID, true, "alice", ""); err == nil {
+		t.Error("Expected an open recommendation not to be decided")
+	}
+	s.Submit("t1", rec.ID, "bob")
+	decided, err := s.Decide("t1", rec.ID, true, "alice", "")
+	if err != nil || decided.Status != StatusApproved || decided.DecidedBy != "alice" || decided.SubmittedBy != "bob" {
+		t.Errorf("Expected the recommendation approved, got %+v (%v)", decided, err)
+	}
+	if _, err := s.Decide("t1", rec.ID, false, "carol", ""); err == nil {
+		t.Error("Expected a decided recommendation not to be decided again")
+	}
+	if removed := s.Purge("t1"); removed != 1 {
+		t.Errorf("Expected one recommendation purged, got %d", removed)
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	if status, err := ParseStatus("Pending"); err != nil || status != StatusPending {
+		t.Errorf("Expected pending, got %s (%v)", status, err)
+	}
+	if _, err := ParseStatus("done"); err == nil {
+		t.Error("Expected an unknown status rejected")
+	}
+}
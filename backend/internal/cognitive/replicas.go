@@ -0,0 +1,41 @@
+package cognitive
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/events"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sharding"
+)
+
+// startReplication gives each shard a read replica and keeps the replicas
+// current from the atom events on the bus. Should the subscription fall
+// behind and drop events, the replicas are rebuilt from their primaries.
+// How far the subscription is behind bounds the staleness of the replicas.
+func (ce *CognitiveEngine) startReplication(config sharding.ReplicaConfig) {
+	ce.shardManager.EnableReplicas(config)
+
+	var subID atomic.Int64
+	ce.shardManager.SetReplicaLag(func() time.Duration {
+		return ce.eventBus.Lag(subID.Load())
+	})
+	var dropped int64
+	subID.Store(ce.eventBus.Subscribe("replicas", func(e events.Event) bool {
+		return e.Type == events.AtomAdded || e.Type == events.AtomUpdated || e.Type == events.AtomDeleted
+	}, func(e events.Event) {
+		// Events are handled one at a time, so dropped needs no lock
+		if n := ce.eventBus.Dropped(subID.Load()); n != dropped {
+			dropped = n
+			ce.shardManager.MarkReplicasStale()
+		}
+
+		switch e.Type {
+		case events.AtomAdded, events.AtomUpdated:
+			if e.Atom != nil {
+				ce.shardManager.Replicate(e.Atom)
+			}
+		case events.AtomDeleted:
+			ce.shardManager.ReplicateDelete(e.AtomID, e.TenantID)
+		}
+	}))
+}
@@ -0,0 +1,298 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/pipeline"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/reports"
+)
+
+// SetReport creates or replaces a report schedule and enables the report
+// agent for the tenant with the default configuration if needed
+func (ce *CognitiveEngine) SetReport(tenantID string, schedule reports.Schedule) (reports.Schedule, error) {
+	schedule, err := ce.reportRegistry.Set(tenantID, schedule)
+	if err != nil {
+		return reports.Schedule{}, err
+	}
+	ce.reportAgent(tenantID)
+	return schedule, nil
+}
+
+// GetReport returns a report schedule
+func (ce *CognitiveEngine) GetReport(tenantID, name string) (reports.Schedule, error) {
+	return ce.reportRegistry.Get(tenantID, name)
+}
+
+// ListReports returns a tenant's report schedules
+func (ce *CognitiveEngine) ListReports(tenantID string) []reports.Schedule {
+	return ce.reportRegistry.List(tenantID)
+}
+
+// DeleteReport removes a report schedule and the reports generated for it
+func (ce *CognitiveEngine) DeleteReport(tenantID, name string) error {
+	return ce.reportRegistry.Delete(tenantID, name)
+}
+
+// EnableReports registers a report agent for a tenant, or updates the
+// configuration of the existing one
+func (ce *CognitiveEngine) EnableReports(tenantID string, config agents.ReportConfig) *agents.ReportAgent {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if agent, exists := ce.reportAgents[tenantID]; exists {
+		agent.SetConfig(config)
+		return agent
+	}
+
+	agent := agents.NewReportAgent(
+		fmt.Sprintf("report-%s", tenantID),
+		"ReportAgent",
+		tenantID,
+		ce.reportRegistry,
+		ce,
+		ce.reportSender,
+		config,
+	)
+	agent.SetSecrets(ce.secrets.ForTenant(tenantID))
+	agent.SetSandbox(ce.sandbox.ForTenant(tenantID))
+	ce.reportAgents[tenantID] = agent
+	ce.agentScheduler.RegisterAgent(agent)
+	return agent
+}
+
+// DisableReports unregisters a tenant's report agent. Schedules and
+// generated reports are kept.
+func (ce *CognitiveEngine) DisableReports(tenantID string) error {
+	ce.mu.Lock()
+	agent, exists := ce.reportAgents[tenantID]
+	delete(ce.reportAgents, tenantID)
+	ce.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("reports not enabled for tenant %s", tenantID)
+	}
+	ce.agentScheduler.UnregisterAgent(agent.GetID())
+	return nil
+}
+
+// reportAgent returns a tenant's report agent, enabling it with the
+// default configuration if needed
+func (ce *CognitiveEngine) reportAgent(tenantID string) *agents.ReportAgent {
+	ce.mu.RLock()
+	agent, exists := ce.reportAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		agent = ce.EnableReports(tenantID, agents.DefaultReportConfig())
+	}
+	return agent
+}
+
+// GenerateReport generates, delivers and records a schedule's report
+// immediately
+func (ce *CognitiveEngine) GenerateReport(ctx context.Context, tenantID, name string) (reports.Report, error) {
+	return ce.reportAgent(tenantID).Generate(ctx, name)
+}
+
+// GetReportHistory returns the reports kept for a schedule, oldest first
+func (ce *CognitiveEngine) GetReportHistory(tenantID, name string) ([]reports.Report, error) {
+	if _, err := ce.reportRegistry.Get(tenantID, name); err != nil {
+		return nil, err
+	}
+	return ce.reportRegistry.History(tenantID, name), nil
+}
+
+// GetGeneratedReport returns a report of a schedule by sequence number, or
+// the latest one for a sequence of 0
+func (ce *CognitiveEngine) GetGeneratedReport(tenantID, name string, sequence int) (reports.Report, error) {
+	if sequence == 0 {
+		if report, exists := ce.reportRegistry.Latest(tenantID, name); exists {
+			return report, nil
+		}
+		return reports.Report{}, fmt.Errorf("no report %s generated yet", name)
+	}
+	return ce.reportRegistry.GetReport(tenantID, name, sequence)
+}
+
+// Collect gathers the sections of a schedule's digest for the period
+// [from, to). Sections whose source is not enabled for the tenant are left
+// out with a note.
+func (ce *CognitiveEngine) Collect(ctx context.Context, tenantID string, schedule reports.Schedule, from, to time.Time) (*reports.Digest, error) {
+	digest := &reports.Digest{
+		TenantID: tenantID,
+		Report:   schedule.Name,
+		From:     from,
+		To:       to,
+	}
+	if schedule.Includes(reports.SectionInferences) || schedule.Includes(reports.SectionAnomalies) {
+		atoms := ce.shardManager.QueryAtoms(tenantID, nil)
+		if schedule.Includes(reports.SectionInferences) {
+			digest.Inferences = ce.collectInferences(tenantID, atoms, from, to, schedule.MaxItems)
+		}
+		if schedule.Includes(reports.SectionAnomalies) {
+			digest.Anomalies = collectAnomalies(atoms, from, to, schedule.MaxItems)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if schedule.Includes(reports.SectionDrift) {
+		if findings, err := ce.GetDrift(tenantID); err != nil {
+			digest.Notes = append(digest.Notes, fmt.Sprintf("%s: %v", reports.SectionDrift, err))
+		} else {
+			d := &reports.Drift{Count: len(findings), Findings: findings}
+			sort.SliceStable(d.Findings, func(i, j int) bool { return d.Findings[i].Severity > d.Findings[j].Severity })
+			if len(d.Findings) > 0 {
+				d.MaxSeverity = d.Findings[0].Severity
+			}
+			if len(d.Findings) > schedule.MaxItems {
+				d.Findings = d.Findings[:schedule.MaxItems]
+			}
+			digest.Drift = d
+		}
+	}
+
+	if schedule.Includes(reports.SectionCost) {
+		if report, err := ce.GetCostReport(tenantID); err != nil {
+			digest.Notes = append(digest.Notes, fmt.Sprintf("%s: %v", reports.SectionCost, err))
+		} else {
+			c := &reports.Cost{
+				TotalMonthly: report.TotalMonthly,
+				ByCategory:   report.ByCategory,
+				Savings:      len(report.Savings),
+			}
+			for _, s := range report.Savings {
+				c.PotentialSavings += s.MonthlySavings
+			}
+			digest.Cost = c
+		}
+	}
+
+	if schedule.Includes(reports.SectionAgents) {
+		digest.Agents = ce.collectAgents(tenantID)
+	}
+	return digest, nil
+}
+
+// timestamped is implemented by the atoms of the AtomSpace
+type timestamped interface {
+	GetCreatedAt() time.Time
+	GetUpdatedAt() time.Time
+}
+
+func within(t, from, to time.Time) bool {
+	return !t.Before(from) && t.Before(to)
+}
+
+// collectInferences lists the atoms created during the period whose
+// derivation the learner remembers
+func (ce *CognitiveEngine) collectInferences(tenantID string, atoms []atomspace.Atom, from, to time.Time, maxItems int) *reports.Inferences {
+	inferences := &reports.Inferences{ByRule: make(map[string]int), Items: make([]reports.Item, 0)}
+	for _, atom := range atoms {
+		t, ok := atom.(timestamped)
+		if !ok || !within(t.GetCreatedAt(), from, to) {
+			continue
+		}
+		rule, derived := ce.learner.DerivedBy(tenantID, atom.GetID())
+		if !derived {
+			continue
+		}
+		inferences.Count++
+		inferences.ByRule[rule]++
+		item := reportItem(atom)
+		item.Detail = rule
+		inferences.Items = append(inferences.Items, item)
+	}
+	inferences.Items = topItems(inferences.Items, func(i reports.Item) float64 { return i.Confidence }, maxItems)
+	return inferences
+}
+
+// collectAnomalies counts the active anomalies and lists those changed
+// during the period
+func collectAnomalies(atoms []atomspace.Atom, from, to time.Time, maxItems int) *reports.Anomalies {
+	anomalies := &reports.Anomalies{Items: make([]reports.Item, 0)}
+	byID := make(map[string]atomspace.Atom, len(atoms))
+	for _, atom := range atoms {
+		byID[atom.GetID()] = atom
+	}
+	seen := make(map[string]bool)
+	for _, atom := range atoms {
+		link, ok := atom.(*atomspace.Link)
+		if !ok || link.GetType() != atomspace.InheritanceLinkType {
+			continue
+		}
+		outgoing := link.GetOutgoing()
+		if len(outgoing) != 2 || outgoing[1].GetName() != pipeline.AnomalyConcept || seen[outgoing[0].GetID()] {
+			continue
+		}
+		seen[outgoing[0].GetID()] = true
+
+		anomaly, exists := byID[outgoing[0].GetID()]
+		if !exists || anomaly.GetTruthValue().Strength <= 0 {
+			continue
+		}
+		anomalies.Active++
+		t, ok := anomaly.(timestamped)
+		if !ok {
+			continue
+		}
+		if within(t.GetCreatedAt(), from, to) {
+			anomalies.New++
+		}
+		if within(t.GetUpdatedAt(), from, to) {
+			anomalies.Items = append(anomalies.Items, reportItem(anomaly))
+		}
+	}
+	anomalies.Items = topItems(anomalies.Items, func(i reports.Item) float64 { return i.Strength }, maxItems)
+	return anomalies
+}
+
+// collectAgents reports the run and failure totals of a tenant's agents;
+// Digest.Compare turns them into activity during the period
+func (ce *CognitiveEngine) collectAgents(tenantID string) *reports.Agents {
+	health := make(map[string]agents.AgentHealth)
+	for _, h := range ce.GetAgentHealth(tenantID) {
+		health[h.AgentID] = h
+	}
+
+	summary := &reports.Agents{Agents: make([]reports.Agent, 0)}
+	for _, agent := range ce.GetAgentsByTenant(tenantID) {
+		a := reports.Agent{AgentID: agent.GetID(), Name: agent.GetName()}
+		if runs, ok := agent.GetStats()["run_count"].(int64); ok {
+			a.TotalRuns = runs
+		}
+		if h, exists := health[a.AgentID]; exists {
+			a.Health = string(h.State)
+			a.TotalFailures = h.TotalFailures
+			a.LastError = h.LastError
+		}
+		summary.Agents = append(summary.Agents, a)
+	}
+	sort.Slice(summary.Agents, func(i, j int) bool { return summary.Agents[i].AgentID < summary.Agents[j].AgentID })
+	return summary
+}
+
+func reportItem(atom atomspace.Atom) reports.Item {
+	tv := atom.GetTruthValue()
+	return reports.Item{AtomID: atom.GetID(), Name: atom.GetName(), Strength: tv.Strength, Confidence: tv.Confidence}
+}
+
+// topItems sorts items by key, highest first, and keeps at most n
+func topItems(items []reports.Item, key func(reports.Item) float64, n int) []reports.Item {
+	sort.SliceStable(items, func(i, j int) bool {
+		if key(items[i]) != key(items[j]) {
+			return key(items[i]) > key(items[j])
+		}
+		return items[i].Name < items[j].Name
+	})
+	if len(items) > n {
+		items = items[:n]
+	}
+	return items
+}
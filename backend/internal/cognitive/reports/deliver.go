@@ -0,0 +1,96 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Sender delivers rendered reports to sinks
+type Sender struct {
+	client   *http.Client
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSender creates a sender whose deliveries time out after timeout
+func NewSender(timeout time.Duration) *Sender {
+	if timeout <= 0 {
+		timeout = DefaultConfig().DeliveryTimeout
+	}
+	return &Sender{
+		client:   &http.Client{Timeout: timeout},
+		sendMail: smtp.SendMail,
+	}
+}
+
+// Deliver sends a report to every sink of its schedule and returns the
+// outcome of each delivery. A failed delivery does not stop the others.
+func (s *Sender) Deliver(ctx context.Context, tenantID string, sinks []Sink, report Report) []Delivery {
+	deliveries := make([]Delivery, 0, len(sinks))
+	for _, sink := range sinks {
+		var err error
+		switch sink.Type {
+		case SinkWebhook:
+			err = s.postWebhook(ctx, sink.URL, report)
+		case SinkEmail:
+			err = s.sendEmail(tenantID, sink, report)
+		default:
+			err = fmt.Errorf("unknown sink type: %s", sink.Type)
+		}
+		delivery := Delivery{Sink: sink.Type, Target: sink.Target(), DeliveredAt: time.Now()}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries
+}
+
+// postWebhook posts the rendered report with its content type
+func (s *Sender) postWebhook(ctx context.Context, url string, report Report) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(report.Content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", report.Format.ContentType())
+	req.Header.Set("X-Erebus-Report", report.Schedule)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sendEmail sends the rendered report as the body of a message
+func (s *Sender) sendEmail(tenantID string, sink Sink, report Report) error {
+	var auth smtp.Auth
+	if sink.Username != "" {
+		host, _, err := net.SplitHostPort(sink.SMTPAddr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", sink.Username, sink.Password, host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", sink.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(sink.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s digest for %s\r\n", report.Schedule, tenantID)
+	fmt.Fprintf(&msg, "Date: %s\r\n", report.GeneratedAt.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: %s\r\n\r\n", report.Format.ContentType())
+	msg.WriteString(strings.ReplaceAll(report.Content, "\n", "\r\n"))
+
+	return s.sendMail(sink.SMTPAddr, auth, sink.From, sink.To, msg.Bytes())
+}
@@ -0,0 +1,140 @@
+package reports
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var funcs = map[string]interface{}{
+	"time":    func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+	"percent": func(v float64) string { return fmt.Sprintf("%.1f%%", v*100) },
+	"usd":     func(v float64) string { return fmt.Sprintf("$%.2f", v) },
+	"signed":  func(v float64) string { return fmt.Sprintf("%+.1f%%", v*100) },
+	"truth":   func(i Item) string { return fmt.Sprintf("%.2f / %.2f", i.Strength, i.Confidence) },
+	"cell":    func(s string) string { return strings.ReplaceAll(s, "|", `\|`) },
+}
+
+const markdownTemplate = `# {{.Report}} digest for {{.TenantID}}
+
+{{time .From}} to {{time .To}}
+{{with .Inferences}}
+## New inferences
+
+{{.Count}} atoms derived{{range $rule, $n := .ByRule}}, {{$n}} by {{$rule}}{{end}}.
+{{if .Items}}
+| Atom | Strength / confidence | Rule |
+|------|-----------------------|------|
+{{range .Items}}| {{cell .Name}} | {{truth .}} | {{.Detail}} |
+{{end}}{{end}}{{end}}{{with .Anomalies}}
+## Anomalies
+
+{{.Active}} active, {{.New}} new.
+{{if .Items}}
+| Anomaly | Strength / confidence |
+|---------|-----------------------|
+{{range .Items}}| {{cell .Name}} | {{truth .}} |
+{{end}}{{end}}{{end}}{{with .Drift}}
+## Drift
+
+{{.Count}} findings, maximum severity {{printf "%.2f" .MaxSeverity}}.
+{{if .Findings}}
+| Kind | Declared | Observed | Attribute | Severity |
+|------|----------|----------|-----------|----------|
+{{range .Findings}}| {{.Kind}} | {{cell .Declared}} | {{cell .Observed}} | {{cell .Attribute}} | {{printf "%.2f" .Severity}} |
+{{end}}{{end}}{{end}}{{with .Cost}}
+## Cost
+
+{{usd .TotalMonthly}} per month{{if .PreviousMonthly}}, {{signed .Change}} from {{usd .PreviousMonthly}}{{end}}. {{.Savings}} savings proposed worth {{usd .PotentialSavings}} per month.
+{{if .ByCategory}}
+| Category | Monthly |
+|----------|---------|
+{{range $category, $monthly := .ByCategory}}| {{cell $category}} | {{usd $monthly}} |
+{{end}}{{end}}{{end}}{{with .Agents}}
+## Agent activity
+
+{{.Runs}} runs, {{.Failures}} failures.
+{{if .Agents}}
+| Agent | Health | Runs | Failures | Last error |
+|-------|--------|------|----------|------------|
+{{range .Agents}}| {{cell .Name}} ({{cell .AgentID}}) | {{.Health}} | {{.Runs}} | {{.Failures}} | {{cell .LastError}} |
+{{end}}{{end}}{{end}}{{if .Notes}}
+## Notes
+{{range .Notes}}
+- {{.}}{{end}}
+{{end}}`
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Report}} digest for {{.TenantID}}</title></head>
+<body>
+<h1>{{.Report}} digest for {{.TenantID}}</h1>
+<p>{{time .From}} to {{time .To}}</p>
+{{with .Inferences}}<h2>New inferences</h2>
+<p>{{.Count}} atoms derived{{range $rule, $n := .ByRule}}, {{$n}} by {{$rule}}{{end}}.</p>
+{{if .Items}}<table>
+<tr><th>Atom</th><th>Strength / confidence</th><th>Rule</th></tr>
+{{range .Items}}<tr><td>{{.Name}}</td><td>{{truth .}}</td><td>{{.Detail}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}{{with .Anomalies}}<h2>Anomalies</h2>
+<p>{{.Active}} active, {{.New}} new.</p>
+{{if .Items}}<table>
+<tr><th>Anomaly</th><th>Strength / confidence</th></tr>
+{{range .Items}}<tr><td>{{.Name}}</td><td>{{truth .}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}{{with .Drift}}<h2>Drift</h2>
+<p>{{.Count}} findings, maximum severity {{printf "%.2f" .MaxSeverity}}.</p>
+{{if .Findings}}<table>
+<tr><th>Kind</th><th>Declared</th><th>Observed</th><th>Attribute</th><th>Severity</th></tr>
+{{range .Findings}}<tr><td>{{.Kind}}</td><td>{{.Declared}}</td><td>{{.Observed}}</td><td>{{.Attribute}}</td><td>{{printf "%.2f" .Severity}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}{{with .Cost}}<h2>Cost</h2>
+<p>{{usd .TotalMonthly}} per month{{if .PreviousMonthly}}, {{signed .Change}} from {{usd .PreviousMonthly}}{{end}}. {{.Savings}} savings proposed worth {{usd .PotentialSavings}} per month.</p>
+{{if .ByCategory}}<table>
+<tr><th>Category</th><th>Monthly</th></tr>
+{{range $category, $monthly := .ByCategory}}<tr><td>{{$category}}</td><td>{{usd $monthly}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}{{with .Agents}}<h2>Agent activity</h2>
+<p>{{.Runs}} runs, {{.Failures}} failures.</p>
+{{if .Agents}}<table>
+<tr><th>Agent</th><th>Health</th><th>Runs</th><th>Failures</th><th>Last error</th></tr>
+{{range .Agents}}<tr><td>{{.Name}} ({{.AgentID}})</td><td>{{.Health}}</td><td>{{.Runs}}</td><td>{{.Failures}}</td><td>{{.LastError}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}{{if .Notes}}<h2>Notes</h2>
+<ul>
+{{range .Notes}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}</body>
+</html>
+`
+
+var (
+	markdown = template.Must(template.New("markdown").Funcs(funcs).Parse(markdownTemplate))
+	html     = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(htmlTemplate))
+)
+
+// Render renders a digest in a format
+func Render(d *Digest, format Format) (string, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case FormatJSON:
+		var data []byte
+		data, err = json.MarshalIndent(d, "", "  ")
+		buf.Write(data)
+	case FormatMarkdown:
+		err = markdown.Execute(&buf, d)
+	case FormatHTML:
+		err = html.Execute(&buf, d)
+	default:
+		return "", fmt.Errorf("unknown format: %s", format)
+	}
+	if err != nil {
+		return "", fmt.Errorf("rendering report failed: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,530 @@
+package reports
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/drift"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/secrets"
+)
+
+// Format is the rendering of a report
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+// ContentType returns the MIME type of a format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	}
+	return "application/json"
+}
+
+// Section is a part of a digest
+type Section string
+
+const (
+	SectionInferences Section = "inferences" // Atoms derived by inference rules
+	SectionAnomalies  Section = "anomalies"  // Anomaly concepts written by pipelines
+	SectionDrift      Section = "drift"      // Findings of the drift agent
+	SectionCost       Section = "cost"       // Cost report and its change
+	SectionAgents     Section = "agents"     // Runs and failures of the tenant's agents
+)
+
+// AllSections returns every section in rendering order
+func AllSections() []Section {
+	return []Section{SectionInferences, SectionAnomalies, SectionDrift, SectionCost, SectionAgents}
+}
+
+// SinkType is where a generated report is delivered
+type SinkType string
+
+const (
+	SinkWebhook SinkType = "webhook" // POST the rendered report to URL
+	SinkEmail   SinkType = "email"   // Send the rendered report through an SMTP server
+)
+
+// Sink is a delivery target of a report
+type Sink struct {
+	Type     SinkType `json:"type"`
+	URL      string   `json:"url,omitempty"`
+	SMTPAddr string   `json:"smtp_addr,omitempty"` // host:port
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       []string `json:"to,omitempty"`
+}
+
+// Validate checks the sink
+func (s *Sink) Validate() error {
+	switch s.Type {
+	case SinkWebhook:
+		if s.URL == "" {
+			return fmt.Errorf("webhook sink requires a url")
+		}
+	case SinkEmail:
+		if s.SMTPAddr == "" || s.From == "" || len(s.To) == 0 {
+			return fmt.Errorf("email sink requires smtp_addr, from and to")
+		}
+		for _, address := range append([]string{s.From}, s.To...) {
+			if strings.ContainsAny(address, "\r\n") {
+				return fmt.Errorf("invalid email address: %q", address)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown sink type: %s", s.Type)
+	}
+	return nil
+}
+
+// Target names the destination of the sink
+func (s *Sink) Target() string {
+	if s.Type == SinkEmail {
+		return fmt.Sprintf("%v", s.To)
+	}
+	return s.URL
+}
+
+// Schedule defines a digest generated periodically for a tenant
+type Schedule struct {
+	Name      string        `json:"name"`
+	Sections  []Section     `json:"sections,omitempty"` // Empty for all sections
+	Format    Format        `json:"format,omitempty"`
+	Interval  time.Duration `json:"interval_ns,omitempty"` // Time between reports and period covered by each
+	MaxItems  int           `json:"max_items,omitempty"`   // Items listed per section
+	Sinks     []Sink        `json:"sinks,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// Defaults of a schedule
+const (
+	DefaultInterval = 24 * time.Hour
+	DefaultMaxItems = 10
+	MinInterval     = time.Minute
+)
+
+// Validate checks the schedule and fills in defaults
+func (s *Schedule) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("report name is required")
+	}
+	if strings.ContainsAny(s.Name, "\r\n/") {
+		return fmt.Errorf("invalid report name: %q", s.Name)
+	}
+	if s.Format == "" {
+		s.Format = FormatMarkdown
+	}
+	switch s.Format {
+	case FormatJSON, FormatMarkdown, FormatHTML:
+	default:
+		return fmt.Errorf("unknown format: %s", s.Format)
+	}
+	if s.Interval == 0 {
+		s.Interval = DefaultInterval
+	}
+	if s.Interval < MinInterval {
+		return fmt.Errorf("interval must be at least %s", MinInterval)
+	}
+	if s.MaxItems <= 0 {
+		s.MaxItems = DefaultMaxItems
+	}
+	if len(s.Sections) == 0 {
+		s.Sections = AllSections()
+	}
+	for _, section := range s.Sections {
+		if !containsSection(AllSections(), section) {
+			return fmt.Errorf("unknown section: %s", section)
+		}
+	}
+	for i := range s.Sinks {
+		if err := s.Sinks[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Includes reports whether the schedule renders a section
+func (s *Schedule) Includes(section Section) bool {
+	return containsSection(s.Sections, section)
+}
+
+// Redacted returns a copy of the schedule without sink passwords. Secret
+// references are kept.
+func (s Schedule) Redacted() Schedule {
+	sinks := make([]Sink, len(s.Sinks))
+	for i, sink := range s.Sinks {
+		sink.Password = secrets.Redact(sink.Password)
+		sinks[i] = sink
+	}
+	s.Sinks = sinks
+	return s
+}
+
+func containsSection(sections []Section, section Section) bool {
+	for _, s := range sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// Item is an atom listed in a digest
+type Item struct {
+	AtomID     string  `json:"atom_id"`
+	Name       string  `json:"name"`
+	Strength   float64 `json:"strength"`
+	Confidence float64 `json:"confidence"`
+	Detail     string  `json:"detail,omitempty"`
+}
+
+// Inferences summarizes the atoms derived during the period
+type Inferences struct {
+	Count  int            `json:"count"`
+	ByRule map[string]int `json:"by_rule"`
+	Items  []Item         `json:"items"` // Most confident first
+}
+
+// Anomalies summarizes the active anomalies
+type Anomalies struct {
+	Active int    `json:"active"`
+	New    int    `json:"new"`   // Created during the period
+	Items  []Item `json:"items"` // Changed during the period, strongest first
+}
+
+// Drift summarizes the findings of the latest drift run
+type Drift struct {
+	Count       int             `json:"count"`
+	MaxSeverity float64         `json:"max_severity"`
+	Findings    []drift.Finding `json:"findings"` // Most severe first
+}
+
+// Cost summarizes the latest cost report and its change since the
+// previous digest
+type Cost struct {
+	TotalMonthly     float64            `json:"total_monthly"`
+	PreviousMonthly  float64            `json:"previous_monthly"`
+	Change           float64            `json:"change"` // Relative change, 0 without a previous total
+	ByCategory       map[string]float64 `json:"by_category"`
+	Savings          int                `json:"savings"`
+	PotentialSavings float64            `json:"potential_savings"` // Monthly
+}
+
+// Agent is the activity of one agent
+type Agent struct {
+	AgentID       string `json:"agent_id"`
+	Name          string `json:"name"`
+	Health        string `json:"health,omitempty"`
+	Runs          int64  `json:"runs"`     // During the period
+	Failures      int64  `json:"failures"` // During the period
+	TotalRuns     int64  `json:"total_runs"`
+	TotalFailures int64  `json:"total_failures"`
+	LastError     string `json:"last_error,omitempty"`
+}
+
+// Agents summarizes the activity of a tenant's agents
+type Agents struct {
+	Runs     int64   `json:"runs"`
+	Failures int64   `json:"failures"`
+	Agents   []Agent `json:"agents"`
+}
+
+// Digest is the content of a report over a period
+type Digest struct {
+	TenantID   string      `json:"tenant_id"`
+	Report     string      `json:"report"`
+	From       time.Time   `json:"from"`
+	To         time.Time   `json:"to"`
+	Inferences *Inferences `json:"inferences,omitempty"`
+	Anomalies  *Anomalies  `json:"anomalies,omitempty"`
+	Drift      *Drift      `json:"drift,omitempty"`
+	Cost       *Cost       `json:"cost,omitempty"`
+	Agents     *Agents     `json:"agents,omitempty"`
+	Notes      []string    `json:"notes,omitempty"` // Sections that could not be collected and why
+}
+
+// Compare fills in the changes since the previous digest of the same
+// schedule: the change of the monthly cost and the runs and failures of
+// each agent during the period. Without a previous digest, agents are
+// credited with all their runs and failures.
+func (d *Digest) Compare(previous *Digest) {
+	if d.Cost != nil && previous != nil && previous.Cost != nil {
+		d.Cost.PreviousMonthly = previous.Cost.TotalMonthly
+		if d.Cost.PreviousMonthly > 0 {
+			d.Cost.Change = (d.Cost.TotalMonthly - d.Cost.PreviousMonthly) / d.Cost.PreviousMonthly
+		}
+	}
+	if d.Agents == nil {
+		return
+	}
+	before := make(map[string]Agent)
+	if previous != nil && previous.Agents != nil {
+		for _, a := range previous.Agents.Agents {
+			before[a.AgentID] = a
+		}
+	}
+	d.Agents.Runs, d.Agents.Failures = 0, 0
+	for i := range d.Agents.Agents {
+		a := &d.Agents.Agents[i]
+		a.Runs, a.Failures = a.TotalRuns, a.TotalFailures
+		// Counters restart with the agent when it is re-enabled
+		if p, exists := before[a.AgentID]; exists && a.TotalRuns >= p.TotalRuns && a.TotalFailures >= p.TotalFailures {
+			a.Runs -= p.TotalRuns
+			a.Failures -= p.TotalFailures
+		}
+		d.Agents.Runs += a.Runs
+		d.Agents.Failures += a.Failures
+	}
+}
+
+// Delivery is the outcome of delivering a report to a sink
+type Delivery struct {
+	Sink        SinkType  `json:"sink"`
+	Target      string    `json:"target"`
+	Error       string    `json:"error,omitempty"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+// Report is a generated digest and its rendering
+type Report struct {
+	Sequence    int        `json:"sequence"` // Per schedule, starting at 1
+	Schedule    string     `json:"schedule"`
+	Format      Format     `json:"format"`
+	Digest      *Digest    `json:"digest"`
+	Content     string     `json:"content"`
+	Deliveries  []Delivery `json:"deliveries"`
+	GeneratedAt time.Time  `json:"generated_at"`
+}
+
+// Config controls the report registry
+type Config struct {
+	MaxHistory      int           // Reports kept per schedule
+	DeliveryTimeout time.Duration // Per sink
+}
+
+// DefaultConfig returns the default report configuration
+func DefaultConfig() Config {
+	return Config{
+		MaxHistory:      30,
+		DeliveryTimeout: 10 * time.Second,
+	}
+}
+
+type history struct {
+	reports []Report // Oldest first
+	next    int
+}
+
+// Registry holds the report schedules of each tenant and the reports
+// generated for them
+type Registry struct {
+	config    Config
+	schedules map[string]map[string]Schedule // tenantID -> name -> schedule
+	history   map[string]map[string]*history // tenantID -> name -> reports
+	mu        sync.RWMutex
+}
+
+// NewRegistry creates an empty report registry
+func NewRegistry(config Config) *Registry {
+	if config.MaxHistory <= 0 {
+		config.MaxHistory = DefaultConfig().MaxHistory
+	}
+	return &Registry{
+		config:    config,
+		schedules: make(map[string]map[string]Schedule),
+		history:   make(map[string]map[string]*history),
+	}
+}
+
+// Set creates or replaces a schedule. Reports already generated are kept.
+func (r *Registry) Set(tenantID string, s Schedule) (Schedule, error) {
+	if err := s.Validate(); err != nil {
+		return Schedule{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tenant, exists := r.schedules[tenantID]
+	if !exists {
+		tenant = make(map[string]Schedule)
+		r.schedules[tenantID] = tenant
+	}
+	if previous, exists := tenant[s.Name]; exists {
+		s.CreatedAt = previous.CreatedAt
+	} else {
+		s.CreatedAt = time.Now()
+	}
+	tenant[s.Name] = s
+	return s, nil
+}
+
+// Get returns a schedule
+func (r *Registry) Get(tenantID, name string) (Schedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, exists := r.schedules[tenantID][name]
+	if !exists {
+		return Schedule{}, fmt.Errorf("report %s not found", name)
+	}
+	return s, nil
+}
+
+// List returns a tenant's schedules sorted by name
+func (r *Registry) List(tenantID string) []Schedule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]Schedule, 0, len(r.schedules[tenantID]))
+	for _, s := range r.schedules[tenantID] {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Delete removes a schedule and its reports
+func (r *Registry) Delete(tenantID, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.schedules[tenantID][name]; !exists {
+		return fmt.Errorf("report %s not found", name)
+	}
+	delete(r.schedules[tenantID], name)
+	delete(r.history[tenantID], name)
+	return nil
+}
+
+// Due returns the schedules whose interval has elapsed since their latest
+// report, or since they were created if they have none
+func (r *Registry) Due(tenantID string, now time.Time) []Schedule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	due := make([]Schedule, 0)
+	for name, s := range r.schedules[tenantID] {
+		since := s.CreatedAt
+		if h := r.history[tenantID][name]; h != nil && len(h.reports) > 0 {
+			since = h.reports[len(h.reports)-1].GeneratedAt
+		}
+		if !now.Before(since.Add(s.Interval)) {
+			due = append(due, s)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].Name < due[j].Name })
+	return due
+}
+
+// Record numbers a generated report and adds it to the history of its
+// schedule
+func (r *Registry) Record(tenantID string, report Report) (Report, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.schedules[tenantID][report.Schedule]; !exists {
+		return Report{}, fmt.Errorf("report %s not found", report.Schedule)
+	}
+	tenant, exists := r.history[tenantID]
+	if !exists {
+		tenant = make(map[string]*history)
+		r.history[tenantID] = tenant
+	}
+	h, exists := tenant[report.Schedule]
+	if !exists {
+		h = &history{next: 1}
+		tenant[report.Schedule] = h
+	}
+	report.Sequence = h.next
+	h.next++
+	h.reports = append(h.reports, report)
+	if len(h.reports) > r.config.MaxHistory {
+		h.reports = h.reports[len(h.reports)-r.config.MaxHistory:]
+	}
+	return report, nil
+}
+
+// History returns the reports kept for a schedule, oldest first
+func (r *Registry) History(tenantID, name string) []Report {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	h := r.history[tenantID][name]
+	if h == nil {
+		return []Report{}
+	}
+	return append([]Report(nil), h.reports...)
+}
+
+// Latest returns the latest report of a schedule
+func (r *Registry) Latest(tenantID, name string) (Report, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	h := r.history[tenantID][name]
+	if h == nil || len(h.reports) == 0 {
+		return Report{}, false
+	}
+	return h.reports[len(h.reports)-1], true
+}
+
+// GetReport returns a report of a schedule by sequence number
+func (r *Registry) GetReport(tenantID, name string, sequence int) (Report, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if h := r.history[tenantID][name]; h != nil {
+		for _, report := range h.reports {
+			if report.Sequence == sequence {
+				return report, nil
+			}
+		}
+	}
+	return Report{}, fmt.Errorf("report %s #%d not found", name, sequence)
+}
+
+// Purge deletes all schedules of a tenant and the reports generated for
+// them and returns how many schedules were removed
+func (r *Registry) Purge(tenantID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := len(r.schedules[tenantID])
+	delete(r.schedules, tenantID)
+	delete(r.history, tenantID)
+	return purged
+}
+
+// GetStats returns registry statistics
+func (r *Registry) GetStats() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedules, generated := 0, 0
+	for _, tenant := range r.schedules {
+		schedules += len(tenant)
+	}
+	for _, tenant := range r.history {
+		for _, h := range tenant {
+			generated += h.next - 1
+		}
+	}
+	return map[string]interface{}{
+		"tenants":   len(r.schedules),
+		"schedules": schedules,
+		"generated": generated,
+	}
+}
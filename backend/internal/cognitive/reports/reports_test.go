@@ -0,0 +1,202 @@
+package reports
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/drift"
+)
+
+func testDigest() *Digest {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &Digest{
+		TenantID: "t",
+		Report:   "daily",
+		From:     from,
+		To:       from.Add(24 * time.Hour),
+		Inferences: &Inferences{
+			Count:  2,
+			ByRule: map[string]int{"deduction": 2},
+			Items:  []Item{{Name: "a|b", Strength: 0.9, Confidence: 0.8, Detail: "deduction"}},
+		},
+		Anomalies: &Anomalies{Active: 1, New: 1, Items: []Item{{Name: "anomaly:cpu:<node/n1>", Strength: 0.7, Confidence: 0.9}}},
+		Drift:     &Drift{Count: 1, MaxSeverity: 0.6, Findings: []drift.Finding{{Kind: drift.KindAttribute, Declared: "aws_instance.web", Attribute: "instance_type", Severity: 0.6}}},
+		Cost:      &Cost{TotalMonthly: 110, ByCategory: map[string]float64{"Compute": 110}},
+		Agents:    &Agents{Agents: []Agent{{AgentID: "mind-t", Name: "MindAgent", TotalRuns: 10, TotalFailures: 1}}},
+		Notes:     []string{"drift: not enabled"},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := Schedule{Name: "daily"}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("Expected a valid schedule: %v", err)
+	}
+	if s.Format != FormatMarkdown || s.Interval != DefaultInterval || len(s.Sections) != len(AllSections()) || s.MaxItems != DefaultMaxItems {
+		t.Errorf("Expected defaults to be filled in, got %+v", s)
+	}
+
+	invalid := []Schedule{
+		{},
+		{Name: "a/b"},
+		{Name: "x", Format: "pdf"},
+		{Name: "x", Interval: time.Second},
+		{Name: "x", Sections: []Section{"weather"}},
+		{Name: "x", Sinks: []Sink{{Type: SinkWebhook}}},
+		{Name: "x", Sinks: []Sink{{Type: SinkEmail, SMTPAddr: "mail:25", From: "a@b", To: []string{"c@d\r\nBcc: e@f"}}}},
+	}
+	for i, s := range invalid {
+		if s.Validate() == nil {
+			t.Errorf("Expected schedule %d to be rejected", i)
+		}
+	}
+
+	redacted := Schedule{Sinks: []Sink{{Type: SinkEmail, Password: "secret"}}}
+	if redacted.Redacted().Sinks[0].Password == "secret" || redacted.Sinks[0].Password != "secret" {
+		t.Error("Expected a redacted copy")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry(Config{MaxHistory: 2})
+	s, err := r.Set("t", Schedule{Name: "daily", Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	if due := r.Due("t", s.CreatedAt.Add(time.Minute)); len(due) != 0 {
+		t.Errorf("Expected nothing due before the first interval, got %+v", due)
+	}
+	if due := r.Due("t", s.CreatedAt.Add(time.Hour)); len(due) != 1 {
+		t.Fatalf("Expected the schedule to be due, got %+v", due)
+	}
+
+	generated := s.CreatedAt.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		report, err := r.Record("t", Report{Schedule: "daily", GeneratedAt: generated})
+		if err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+		if report.Sequence != i+1 {
+			t.Errorf("Expected sequence %d, got %d", i+1, report.Sequence)
+		}
+	}
+	if due := r.Due("t", generated.Add(30*time.Minute)); len(due) != 0 {
+		t.Errorf("Expected the schedule not to be due after a report, got %+v", due)
+	}
+
+	history := r.History("t", "daily")
+	if len(history) != 2 || history[0].Sequence != 2 {
+		t.Errorf("Expected the 2 latest reports to be kept, got %+v", history)
+	}
+	if _, err := r.GetReport("t", "daily", 1); err == nil {
+		t.Error("Expected the oldest report to be dropped")
+	}
+	if latest, ok := r.Latest("t", "daily"); !ok || latest.Sequence != 3 {
+		t.Errorf("Expected the latest report to be 3, got %+v", latest)
+	}
+
+	if _, err := r.Record("t", Report{Schedule: "weekly"}); err == nil {
+		t.Error("Expected a report of an unknown schedule to be rejected")
+	}
+	if err := r.Delete("t", "daily"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if len(r.History("t", "daily")) != 0 {
+		t.Error("Expected the history to be deleted with the schedule")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	previous := testDigest()
+	previous.Cost.TotalMonthly = 100
+
+	d := testDigest()
+	d.Agents.Agents[0].TotalRuns, d.Agents.Agents[0].TotalFailures = 15, 1
+	d.Agents.Agents = append(d.Agents.Agents, Agent{AgentID: "drift-t", TotalRuns: 3})
+	d.Compare(previous)
+
+	if d.Cost.PreviousMonthly != 100 || d.Cost.Change < 0.0999 || d.Cost.Change > 0.1001 {
+		t.Errorf("Expected a 10%% cost increase, got %+v", d.Cost)
+	}
+	if d.Agents.Agents[0].Runs != 5 || d.Agents.Agents[0].Failures != 0 || d.Agents.Agents[1].Runs != 3 || d.Agents.Runs != 8 {
+		t.Errorf("Expected runs during the period, got %+v", d.Agents)
+	}
+
+	first := testDigest()
+	first.Compare(nil)
+	if first.Agents.Runs != 10 || first.Agents.Failures != 1 || first.Cost.Change != 0 {
+		t.Errorf("Expected totals without a previous digest, got %+v %+v", first.Agents, first.Cost)
+	}
+}
+
+func TestRender(t *testing.T) {
+	d := testDigest()
+	d.Compare(nil)
+
+	md, err := Render(d, FormatMarkdown)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	for _, want := range []string{"# daily digest for t", "2 atoms derived, 2 by deduction", `a\|b`, "1 active, 1 new", "$110.00 per month", "10 runs, 1 failures", "- drift: not enabled"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected markdown to contain %q:\n%s", want, md)
+		}
+	}
+
+	html, err := Render(d, FormatHTML)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(html, "anomaly:cpu:&lt;node/n1&gt;") {
+		t.Errorf("Expected names to be escaped in HTML:\n%s", html)
+	}
+
+	d.Cost = nil
+	if md, _ = Render(d, FormatMarkdown); strings.Contains(md, "## Cost") {
+		t.Error("Expected omitted sections not to be rendered")
+	}
+	if _, err := Render(d, "pdf"); err == nil {
+		t.Error("Expected an unknown format to fail")
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	var contentType, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	var mailed []byte
+	sender := NewSender(time.Second)
+	sender.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		mailed = msg
+		return nil
+	}
+
+	report := Report{Schedule: "daily", Format: FormatMarkdown, Content: "# daily\nline", GeneratedAt: time.Now()}
+	sinks := []Sink{
+		{Type: SinkWebhook, URL: server.URL},
+		{Type: SinkEmail, SMTPAddr: "mail:25", From: "erebus@example.com", To: []string{"ops@example.com"}},
+		{Type: SinkWebhook, URL: server.URL + "/%zz"},
+	}
+	deliveries := sender.Deliver(context.Background(), "t", sinks, report)
+	if len(deliveries) != 3 || deliveries[0].Error != "" || deliveries[1].Error != "" || deliveries[2].Error == "" {
+		t.Fatalf("Expected the third delivery only to fail, got %+v", deliveries)
+	}
+	if contentType != FormatMarkdown.ContentType() || body != report.Content {
+		t.Errorf("Expected the rendered report to be posted, got %q %q", contentType, body)
+	}
+	if msg := string(mailed); !strings.Contains(msg, "Subject: daily digest for t\r\n") || !strings.Contains(msg, "# daily\r\nline") {
+		t.Errorf("Unexpected email: %q", msg)
+	}
+}
@@ -0,0 +1,151 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+// Policy controls how a failing operation is retried
+type Policy struct {
+	MaxAttempts    int           // Total attempts including the first; <= 1 disables retries
+	InitialBackoff time.Duration // Delay before the first retry
+	MaxBackoff     time.Duration // Upper bound on the delay between attempts
+	Multiplier     float64       // Backoff growth factor per attempt
+
+	// Retryable classifies errors; nil uses IsRetryable
+	Retryable func(error) bool
+}
+
+// DefaultPolicy retries three times with exponential backoff from 100ms
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2.0,
+	}
+}
+
+// NoRetry runs an operation exactly once
+func NoRetry() Policy {
+	return Policy{MaxAttempts: 1}
+}
+
+// permanentError marks an error as not worth retrying
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps err so that it is never retried
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsRetryable is the default classifier: everything except permanent errors
+// and context cancellation is retried
+func IsRetryable(err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// Backoff returns the delay before the given retry (1 for the first retry)
+func (p Policy) Backoff(retry int) time.Duration {
+	if retry < 1 || p.InitialBackoff <= 0 {
+		return 0
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+	return time.Duration(delay)
+}
+
+func (p Policy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// Do runs fn until it succeeds, fails with a non-retryable error, runs out
+// of attempts or ctx is done. It returns the number of retries performed.
+func (p Policy) Do(ctx context.Context, fn func(ctx context.Context) error) (int, error) {
+	retries := 0
+	for {
+		err := fn(ctx)
+		if err == nil {
+			return retries, nil
+		}
+		if retries+1 >= p.MaxAttempts || !p.retryable(err) {
+			return retries, err
+		}
+
+		retries++
+		timer := time.NewTimer(p.Backoff(retries))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return retries, err
+		case <-timer.C:
+		}
+	}
+}
+
+// Spec is the JSON form of a Policy
+type Spec struct {
+	MaxAttempts      int      `json:"max_attempts"`
+	InitialBackoffMS int      `json:"initial_backoff_ms"`
+	MaxBackoffMS     int      `json:"max_backoff_ms"`
+	Multiplier       float64  `json:"multiplier"`
+	NonRetryable     []string `json:"non_retryable,omitempty"` // Error substrings that are never retried
+}
+
+// Policy converts the spec, filling unset fields from DefaultPolicy
+func (s Spec) Policy() Policy {
+	p := DefaultPolicy()
+	if s.MaxAttempts > 0 {
+		p.MaxAttempts = s.MaxAttempts
+	}
+	if s.InitialBackoffMS > 0 {
+		p.InitialBackoff = time.Duration(s.InitialBackoffMS) * time.Millisecond
+	}
+	if s.MaxBackoffMS > 0 {
+		p.MaxBackoff = time.Duration(s.MaxBackoffMS) * time.Millisecond
+	}
+	if s.Multiplier > 0 {
+		p.Multiplier = s.Multiplier
+	}
+	if len(s.NonRetryable) > 0 {
+		patterns := append([]string(nil), s.NonRetryable...)
+		p.Retryable = func(err error) bool {
+			for _, pattern := range patterns {
+				if strings.Contains(err.Error(), pattern) {
+					return false
+				}
+			}
+			return IsRetryable(err)
+		}
+	}
+	return p
+}
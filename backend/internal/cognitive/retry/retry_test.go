@@ -0,0 +1,36 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	p := Policy{MaxAttempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond, Multiplier: 2}
+
+	expected := []time.Duration{0, 100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for retry, want := range expected {
+		if got := p.Backoff(retry); got != want {
+			t.Errorf("Backoff(%d) = %v, want %v", retry, got, want)
+		}
+	}
+}
+
+func TestDoStopsOnPermanentError(t *testing.T) {
+	p := Policy{MaxAttempts: 5, InitialBackoff: time.Millisecond}
+
+	calls := 0
+	retries, err := p.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls == 2 {
+			return Permanent(errors.New("bad request"))
+		}
+		return errors.New("unavailable")
+	})
+
+	if err == nil || calls != 2 || retries != 1 {
+		t.Errorf("Expected to stop after the permanent error: calls=%d retries=%d err=%v", calls, retries, err)
+	}
+}
@@ -0,0 +1,50 @@
+package cognitive
+
+import (
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/whatif"
+)
+
+// AnalyzeRules examines a tenant's inference rules without running them,
+// reporting rules firing on the same atoms, rules feeding each other in
+// loops and rules the tenant's atoms cannot trigger. If names are given,
+// that set of built-in rules is analyzed instead, so a rule set can be
+// checked before it is deployed.
+func (ce *CognitiveEngine) AnalyzeRules(tenantID string, names []string) (*inference.Analysis, error) {
+	ce.mu.RLock()
+	inferenceEngine, exists := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+
+	rules := inferenceEngine.GetRules()
+	if len(names) > 0 {
+		var err error
+		if rules, err = whatif.Rules(names); err != nil {
+			return nil, err
+		}
+	}
+	return inference.Analyze(rules, ce.atomPatterns(tenantID)), nil
+}
+
+// atomPatterns lists the kinds of atoms a tenant has: links by type and
+// name, nodes by type
+func (ce *CognitiveEngine) atomPatterns(tenantID string) []inference.Pattern {
+	seen := make(map[inference.Pattern]bool)
+	patterns := make([]inference.Pattern, 0)
+	for _, atom := range ce.QueryAtoms(tenantID, nil) {
+		p := inference.Pattern{Type: atom.GetType()}
+		if atomspace.IsLinkType(p.Type) {
+			p.Name = atom.GetName()
+		}
+		if !seen[p] {
+			seen[p] = true
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
@@ -0,0 +1,151 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/runbooks"
+)
+
+// SetRunbook creates or replaces a runbook, records its steps as atoms and
+// enables the runbook agent for the tenant with the default configuration
+// if needed
+func (ce *CognitiveEngine) SetRunbook(tenantID string, rb runbooks.Runbook) (runbooks.Runbook, error) {
+	if err := rb.Validate(); err != nil {
+		return runbooks.Runbook{}, err
+	}
+	space := &tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID}
+	if err := agents.RecordRunbook(space, tenantID, rb); err != nil {
+		return runbooks.Runbook{}, fmt.Errorf("recording runbook %s failed: %w", rb.Name, err)
+	}
+	rb, err := ce.runbookRegistry.Set(tenantID, rb)
+	if err != nil {
+		return runbooks.Runbook{}, err
+	}
+
+	ce.mu.RLock()
+	_, enabled := ce.runbookAgents[tenantID]
+	ce.mu.RUnlock()
+	if !enabled {
+		ce.EnableRunbooks(tenantID, agents.DefaultRunbookConfig())
+	}
+	return rb, nil
+}
+
+// LoadRunbooks parses one runbook or a list of runbooks and sets them. No
+// runbook is set unless all of them are valid.
+func (ce *CognitiveEngine) LoadRunbooks(tenantID string, data []byte) ([]runbooks.Runbook, error) {
+	parsed, err := runbooks.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	for i := range parsed {
+		if err := parsed[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	loaded := make([]runbooks.Runbook, 0, len(parsed))
+	for _, rb := range parsed {
+		rb, err := ce.SetRunbook(tenantID, rb)
+		if err != nil {
+			return loaded, err
+		}
+		loaded = append(loaded, rb)
+	}
+	return loaded, nil
+}
+
+// GetRunbook returns a runbook
+func (ce *CognitiveEngine) GetRunbook(tenantID, name string) (runbooks.Runbook, error) {
+	return ce.runbookRegistry.Get(tenantID, name)
+}
+
+// ListRunbooks returns a tenant's runbooks
+func (ce *CognitiveEngine) ListRunbooks(tenantID string) []runbooks.Runbook {
+	return ce.runbookRegistry.List(tenantID)
+}
+
+// DeleteRunbook removes a runbook. Atoms already written for it are kept.
+func (ce *CognitiveEngine) DeleteRunbook(tenantID, name string) error {
+	return ce.runbookRegistry.Delete(tenantID, name)
+}
+
+// EnableRunbooks registers a runbook agent for a tenant, or updates the
+// configuration of the existing one
+func (ce *CognitiveEngine) EnableRunbooks(tenantID string, config agents.RunbookConfig) *agents.RunbookAgent {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if agent, exists := ce.runbookAgents[tenantID]; exists {
+		agent.SetConfig(config)
+		return agent
+	}
+
+	agent := agents.NewRunbookAgent(
+		fmt.Sprintf("runbook-%s", tenantID),
+		"RunbookAgent",
+		tenantID,
+		&tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID},
+		ce.runbookRegistry,
+		ce.incidents,
+		ce.tenantActions(tenantID),
+		config,
+	)
+	ce.runbookAgents[tenantID] = agent
+	ce.agentScheduler.RegisterAgent(agent)
+	return agent
+}
+
+// DisableRunbooks unregisters a tenant's runbook agent. Runbooks and atoms
+// already written are kept.
+func (ce *CognitiveEngine) DisableRunbooks(tenantID string) error {
+	ce.mu.Lock()
+	agent, exists := ce.runbookAgents[tenantID]
+	delete(ce.runbookAgents, tenantID)
+	ce.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("runbooks not enabled for tenant %s", tenantID)
+	}
+	ce.agentScheduler.UnregisterAgent(agent.GetID())
+	return nil
+}
+
+// runbookAgent returns a tenant's runbook agent, enabling it with the
+// default configuration if needed
+func (ce *CognitiveEngine) runbookAgent(tenantID string) *agents.RunbookAgent {
+	ce.mu.RLock()
+	agent, exists := ce.runbookAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		agent = ce.EnableRunbooks(tenantID, agents.DefaultRunbookConfig())
+	}
+	return agent
+}
+
+// ApplyRunbooks matches a tenant's active incidents to its runbooks
+// immediately
+func (ce *CognitiveEngine) ApplyRunbooks(ctx context.Context, tenantID string) ([]runbooks.Run, error) {
+	return ce.runbookAgent(tenantID).Apply(ctx)
+}
+
+// ExecuteRunbook performs a runbook's steps on an incident, e.g. to carry
+// out a proposal
+func (ce *CognitiveEngine) ExecuteRunbook(ctx context.Context, tenantID, name, incidentID string) (runbooks.Run, error) {
+	return ce.runbookAgent(tenantID).Execute(ctx, name, incidentID)
+}
+
+// GetRunbookRuns returns the run history of a tenant's runbook agent
+func (ce *CognitiveEngine) GetRunbookRuns(tenantID string) []runbooks.Run {
+	ce.mu.RLock()
+	agent, exists := ce.runbookAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		return []runbooks.Run{}
+	}
+	return agent.GetRuns()
+}
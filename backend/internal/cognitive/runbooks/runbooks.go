@@ -0,0 +1,306 @@
+package runbooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/incidents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+)
+
+// Mode decides whether a matched runbook runs on its own
+type Mode string
+
+const (
+	ModePropose Mode = "propose" // Record the steps for approval
+	ModeExecute Mode = "execute" // Perform the steps
+)
+
+// When decides whether a step runs given the outcome of the step before it
+type When string
+
+const (
+	WhenOnSuccess When = "on_success"
+	WhenOnFailure When = "on_failure"
+	WhenAlways    When = "always"
+)
+
+// Outcome is the result of a step or a run
+type Outcome string
+
+const (
+	OutcomeSucceeded Outcome = "succeeded"
+	OutcomeFailed    Outcome = "failed"
+	OutcomeSkipped   Outcome = "skipped"
+	OutcomeProposed  Outcome = "proposed"
+)
+
+// Match selects the incidents a runbook applies to. Unset fields match
+// everything.
+type Match struct {
+	AlertNames    []string `json:"alert_names,omitempty"`    // Any alert of the incident has one of these names
+	SubjectPrefix string   `json:"subject_prefix,omitempty"` // Any subject of the incident starts with this
+	MinSeverity   string   `json:"min_severity,omitempty"`
+}
+
+// Matches reports whether an incident with the given alert names satisfies
+// the match
+func (m *Match) Matches(inc incidents.Incident, alertNames []string) bool {
+	if m.MinSeverity != "" && !incidents.SeverityAtLeast(inc.Severity, m.MinSeverity) {
+		return false
+	}
+	if m.SubjectPrefix != "" && !anyMatches(inc.Subjects, func(s string) bool { return strings.HasPrefix(s, m.SubjectPrefix) }) {
+		return false
+	}
+	if len(m.AlertNames) > 0 && !anyMatches(alertNames, func(name string) bool { return contains(m.AlertNames, name) }) {
+		return false
+	}
+	return true
+}
+
+// Condition gates a step on relations of the incident's subjects. It holds
+// when every subject has predicate(subject) with a strength in [Min, Max];
+// a Max of 0 means no upper bound.
+type Condition struct {
+	Predicate string  `json:"predicate"`
+	Min       float64 `json:"min,omitempty"`
+	Max       float64 `json:"max,omitempty"`
+}
+
+// Holds reports whether a strength is within the condition's bounds
+func (c *Condition) Holds(strength float64) bool {
+	return strength >= c.Min && (c.Max == 0 || strength <= c.Max)
+}
+
+// String renders the condition, e.g. "error_budget_remaining>=0.2"
+func (c *Condition) String() string {
+	s := fmt.Sprintf("%s>=%g", c.Predicate, c.Min)
+	if c.Max != 0 {
+		s += fmt.Sprintf("<=%g", c.Max)
+	}
+	return s
+}
+
+// Step is one action of a runbook
+type Step struct {
+	Name       string          `json:"name"`
+	Action     triggers.Action `json:"action"`
+	When       When            `json:"when,omitempty"`
+	Conditions []Condition     `json:"conditions,omitempty"`
+}
+
+// Runbook is an ordered list of steps applied to matching incidents
+type Runbook struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Match       Match     `json:"match"`
+	Steps       []Step    `json:"steps"`
+	Mode        Mode      `json:"mode"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Validate checks a runbook and fills in its defaults
+func (rb *Runbook) Validate() error {
+	if rb.Name == "" {
+		return fmt.Errorf("runbook name is required")
+	}
+	if strings.ContainsAny(rb.Name, "/:") {
+		return fmt.Errorf("runbook name must not contain '/' or ':'")
+	}
+	if len(rb.Steps) == 0 {
+		return fmt.Errorf("runbook %s has no steps", rb.Name)
+	}
+	switch rb.Mode {
+	case "":
+		rb.Mode = ModePropose
+	case ModePropose, ModeExecute:
+	default:
+		return fmt.Errorf("unknown runbook mode: %s", rb.Mode)
+	}
+
+	names := make(map[string]bool, len(rb.Steps))
+	for i := range rb.Steps {
+		step := &rb.Steps[i]
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("step-%d", i+1)
+		}
+		if names[step.Name] {
+			return fmt.Errorf("runbook %s has duplicate step %s", rb.Name, step.Name)
+		}
+		names[step.Name] = true
+		switch step.When {
+		case "":
+			step.When = WhenOnSuccess
+		case WhenOnSuccess, WhenOnFailure, WhenAlways:
+		default:
+			return fmt.Errorf("runbook %s step %s: unknown when: %s", rb.Name, step.Name, step.When)
+		}
+		if err := step.Action.Validate(); err != nil {
+			return fmt.Errorf("runbook %s step %s: %w", rb.Name, step.Name, err)
+		}
+		for _, c := range step.Conditions {
+			if c.Predicate == "" {
+				return fmt.Errorf("runbook %s step %s: condition requires a predicate", rb.Name, step.Name)
+			}
+			if c.Max != 0 && c.Max < c.Min {
+				return fmt.Errorf("runbook %s step %s: condition max is below min", rb.Name, step.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Parse decodes runbooks from JSON, either a single runbook or a list
+func Parse(data []byte) ([]Runbook, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		var list []Runbook
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("invalid runbooks: %w", err)
+		}
+		return list, nil
+	}
+	var rb Runbook
+	if err := json.Unmarshal(data, &rb); err != nil {
+		return nil, fmt.Errorf("invalid runbook: %w", err)
+	}
+	return []Runbook{rb}, nil
+}
+
+// StepResult is the outcome of one step of a run
+type StepResult struct {
+	Step     string        `json:"step"`
+	Outcome  Outcome       `json:"outcome"`
+	Reason   string        `json:"reason,omitempty"` // Why a step was skipped or failed
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Run is one application of a runbook to an incident
+type Run struct {
+	Runbook    string       `json:"runbook"`
+	IncidentID string       `json:"incident_id"`
+	Mode       Mode         `json:"mode"`
+	Outcome    Outcome      `json:"outcome"`
+	Steps      []StepResult `json:"steps"`
+	StartedAt  time.Time    `json:"started_at"`
+}
+
+// Registry holds the runbooks of each tenant
+type Registry struct {
+	runbooks map[string]map[string]Runbook // tenantID -> name -> runbook
+	mu       sync.RWMutex
+}
+
+// NewRegistry creates an empty runbook registry
+func NewRegistry() *Registry {
+	return &Registry{
+		runbooks: make(map[string]map[string]Runbook),
+	}
+}
+
+// Set creates or replaces a runbook
+func (r *Registry) Set(tenantID string, rb Runbook) (Runbook, error) {
+	if err := rb.Validate(); err != nil {
+		return Runbook{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tenant, exists := r.runbooks[tenantID]
+	if !exists {
+		tenant = make(map[string]Runbook)
+		r.runbooks[tenantID] = tenant
+	}
+	if previous, exists := tenant[rb.Name]; exists {
+		rb.CreatedAt = previous.CreatedAt
+	} else {
+		rb.CreatedAt = time.Now()
+	}
+	tenant[rb.Name] = rb
+	return rb, nil
+}
+
+// Get returns a runbook
+func (r *Registry) Get(tenantID, name string) (Runbook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rb, exists := r.runbooks[tenantID][name]
+	if !exists {
+		return Runbook{}, fmt.Errorf("runbook %s not found", name)
+	}
+	return rb, nil
+}
+
+// List returns a tenant's runbooks sorted by name
+func (r *Registry) List(tenantID string) []Runbook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]Runbook, 0, len(r.runbooks[tenantID]))
+	for _, rb := range r.runbooks[tenantID] {
+		result = append(result, rb)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Delete removes a runbook
+func (r *Registry) Delete(tenantID, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.runbooks[tenantID][name]; !exists {
+		return fmt.Errorf("runbook %s not found", name)
+	}
+	delete(r.runbooks[tenantID], name)
+	return nil
+}
+
+// Purge deletes all runbooks of a tenant and returns how many were removed
+func (r *Registry) Purge(tenantID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := len(r.runbooks[tenantID])
+	delete(r.runbooks, tenantID)
+	return purged
+}
+
+// GetStats returns registry statistics
+func (r *Registry) GetStats() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	total := 0
+	for _, tenant := range r.runbooks {
+		total += len(tenant)
+	}
+	return map[string]interface{}{
+		"tenants":  len(r.runbooks),
+		"runbooks": total,
+	}
+}
+
+func anyMatches(values []string, match func(string) bool) bool {
+	for _, v := range values {
+		if match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
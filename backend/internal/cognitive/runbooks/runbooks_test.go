@@ -0,0 +1,63 @@
+package runbooks
+
+import (
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/incidents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+)
+
+func TestParseAndValidate(t *testing.T) {
+	data := []byte(`[{"name":"restart-pod","match":{"alert_names":["PodCrashLooping"]},"steps":[
+		{"action":{"type":"execute_pipeline","pipeline_id":"restart"},"conditions":[{"predicate":"error_budget_remaining","min":0.2}]},
+		{"name":"page","when":"on_failure","action":{"type":"webhook","url":"https://pager.example.com"}}]}]`)
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(parsed) != 1 {
+		t.Fatalf("Expected 1 runbook, got %d", len(parsed))
+	}
+	rb := parsed[0]
+	if err := rb.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if rb.Mode != ModePropose || rb.Steps[0].Name != "step-1" || rb.Steps[0].When != WhenOnSuccess {
+		t.Errorf("Expected defaults to be filled in, got %+v", rb)
+	}
+	if got := rb.Steps[0].Conditions[0].String(); got != "error_budget_remaining>=0.2" {
+		t.Errorf("Unexpected condition rendering: %s", got)
+	}
+
+	invalid := []Runbook{
+		{Name: "empty"},
+		{Name: "a/b", Steps: []Step{{Action: triggers.Action{Type: triggers.ActionExecutePipeline, PipelineID: "p"}}}},
+		{Name: "bad-action", Steps: []Step{{Action: triggers.Action{Type: triggers.ActionWebhook, URL: "ftp://x"}}}},
+		{Name: "bad-when", Steps: []Step{{When: "sometimes", Action: triggers.Action{Type: triggers.ActionExecutePipeline, PipelineID: "p"}}}},
+	}
+	for _, rb := range invalid {
+		if err := rb.Validate(); err == nil {
+			t.Errorf("Expected runbook %s to be rejected", rb.Name)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	inc := incidents.Incident{Severity: "warning", Subjects: []string{"pod/default/web-1"}}
+	cases := []struct {
+		match Match
+		want  bool
+	}{
+		{Match{}, true},
+		{Match{AlertNames: []string{"PodCrashLooping"}}, true},
+		{Match{AlertNames: []string{"NodeDown"}}, false},
+		{Match{SubjectPrefix: "pod/"}, true},
+		{Match{SubjectPrefix: "node/"}, false},
+		{Match{MinSeverity: "critical"}, false},
+	}
+	for _, c := range cases {
+		if got := c.match.Matches(inc, []string{"PodCrashLooping"}); got != c.want {
+			t.Errorf("Match %+v: expected %v, got %v", c.match, c.want, got)
+		}
+	}
+}
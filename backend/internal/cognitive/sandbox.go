@@ -0,0 +1,44 @@
+package cognitive
+
+import (
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sandbox"
+)
+
+// SetSandbox selects whether a tenant's connectors and actions run against
+// canned data, and sets that data. In sandbox mode CMDB, Terraform and
+// billing sources are answered from the config, webhooks and report
+// deliveries are recorded but not sent, and pipelines run as usual, so
+// rules and pipelines can be developed without touching infrastructure.
+func (ce *CognitiveEngine) SetSandbox(tenantID string, config sandbox.Config) (sandbox.Config, error) {
+	return ce.sandbox.Set(tenantID, config)
+}
+
+// GetSandbox returns a tenant's sandbox config
+func (ce *CognitiveEngine) GetSandbox(tenantID string) (sandbox.Config, error) {
+	return ce.sandbox.Get(tenantID)
+}
+
+// SandboxEnabled reports whether a tenant is in sandbox mode
+func (ce *CognitiveEngine) SandboxEnabled(tenantID string) bool {
+	return ce.sandbox.Enabled(tenantID)
+}
+
+// SandboxRequests returns the requests a tenant's sandbox answered
+func (ce *CognitiveEngine) SandboxRequests(tenantID string) []sandbox.Request {
+	return ce.sandbox.Requests(tenantID)
+}
+
+// ClearSandboxRequests forgets the requests a tenant's sandbox answered
+func (ce *CognitiveEngine) ClearSandboxRequests(tenantID string) int {
+	return ce.sandbox.ClearRequests(tenantID)
+}
+
+// RemoveSandbox takes a tenant out of sandbox mode and drops its canned
+// data and recorded requests
+func (ce *CognitiveEngine) RemoveSandbox(tenantID string) error {
+	if _, err := ce.sandbox.Get(tenantID); err != nil {
+		return err
+	}
+	ce.sandbox.Purge(tenantID)
+	return nil
+}
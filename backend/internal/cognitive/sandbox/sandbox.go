@@ -0,0 +1,462 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/cmdb"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/cost"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/notifications"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/reports"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/terraform"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+)
+
+// DefaultMaxRequests is how many recorded requests a sandbox keeps unless
+// its config says otherwise
+const DefaultMaxRequests = 1000
+
+// Types of the requests a sandbox records
+const (
+	RequestCMDBFetch       = "cmdb.fetch"       // A CMDB source was read
+	RequestCMDBApply       = "cmdb.apply"       // An update was written back to a CMDB source
+	RequestTerraformLoad   = "terraform.load"   // A Terraform state was read
+	RequestCostRates       = "cost.rates"       // A billing export was read
+	RequestWebhook         = "webhook"          // A webhook action was posted
+	RequestExecutePipeline = "execute_pipeline" // A pipeline action was run
+	RequestReport          = "report"           // A report was delivered to a sink
+	RequestNotification    = "notification"     // A notification was sent to a channel
+)
+
+// Config declares a tenant's sandbox and the canned data standing in for
+// its external systems. Sources without canned data fail to sync.
+type Config struct {
+	Enabled     bool                       `json:"enabled"`
+	CMDB        map[string]*cmdb.Snapshot  `json:"cmdb,omitempty"`         // Snapshot each CMDB source returns, by source name
+	Terraform   map[string]json.RawMessage `json:"terraform,omitempty"`    // State file each Terraform source returns, by source name
+	Rates       []cost.Rate                `json:"rates,omitempty"`        // Rates billing exports return
+	Webhooks    map[string]int             `json:"webhooks,omitempty"`     // Status webhooks answer, by URL; 200 if unlisted
+	MaxRequests int                        `json:"max_requests,omitempty"` // Recorded requests kept; DefaultMaxRequests if 0
+}
+
+// Validate checks a config and parses its canned Terraform states
+func (c *Config) Validate() error {
+	for name, state := range c.Terraform {
+		if _, err := terraform.Parse(state); err != nil {
+			return fmt.Errorf("terraform source %s: %w", name, err)
+		}
+	}
+	for name, snapshot := range c.CMDB {
+		if snapshot == nil {
+			return fmt.Errorf("cmdb source %s has no snapshot", name)
+		}
+	}
+	for i := range c.Rates {
+		if err := c.Rates[i].Validate(); err != nil {
+			return err
+		}
+	}
+	for url, status := range c.Webhooks {
+		if status < 100 || status > 599 {
+			return fmt.Errorf("webhook %s: invalid status %d", url, status)
+		}
+	}
+	if c.MaxRequests < 0 {
+		return fmt.Errorf("max_requests must not be negative")
+	}
+	return nil
+}
+
+// Request is an interaction with an external system that the sandbox
+// answered instead. Credentials are never recorded.
+type Request struct {
+	Time   time.Time   `json:"time"`
+	Type   string      `json:"type"`
+	Target string      `json:"target"`         // Source name, URL, pipeline ID or sink target
+	Body   interface{} `json:"body,omitempty"` // What would have been sent, e.g. a CMDB update
+	Status int         `json:"status,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// tenantSandbox is the config and recorded requests of one tenant
+type tenantSandbox struct {
+	config   Config
+	states   map[string]*terraform.State // Parsed canned states
+	requests []Request
+	recorded int64
+}
+
+// Manager keeps each tenant's sandbox. Connectors and actions of a tenant
+// in sandbox mode are answered from canned data and recorded instead of
+// reaching infrastructure.
+type Manager struct {
+	tenants map[string]*tenantSandbox
+	mu      sync.RWMutex
+}
+
+// NewManager creates a manager with no tenant in sandbox mode
+func NewManager() *Manager {
+	return &Manager{tenants: make(map[string]*tenantSandbox)}
+}
+
+// Set replaces a tenant's sandbox config. Recorded requests are kept.
+func (m *Manager) Set(tenantID string, config Config) (Config, error) {
+	if err := config.Validate(); err != nil {
+		return Config{}, err
+	}
+	if config.MaxRequests == 0 {
+		config.MaxRequests = DefaultMaxRequests
+	}
+	states := make(map[string]*terraform.State, len(config.Terraform))
+	for name, data := range config.Terraform {
+		states[name], _ = terraform.Parse(data)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sb, exists := m.tenants[tenantID]
+	if !exists {
+		sb = &tenantSandbox{}
+		m.tenants[tenantID] = sb
+	}
+	sb.config = config
+	sb.states = states
+	if len(sb.requests) > config.MaxRequests {
+		sb.requests = append([]Request(nil), sb.requests[len(sb.requests)-config.MaxRequests:]...)
+	}
+	return config, nil
+}
+
+// Get returns a tenant's sandbox config
+func (m *Manager) Get(tenantID string) (Config, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sb, exists := m.tenants[tenantID]
+	if !exists {
+		return Config{}, fmt.Errorf("tenant %s has no sandbox", tenantID)
+	}
+	return sb.config, nil
+}
+
+// Enabled reports whether a tenant is in sandbox mode
+func (m *Manager) Enabled(tenantID string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sb, exists := m.tenants[tenantID]
+	return exists && sb.config.Enabled
+}
+
+// Requests returns a tenant's recorded requests, oldest first
+func (m *Manager) Requests(tenantID string) []Request {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sb, exists := m.tenants[tenantID]
+	if !exists {
+		return []Request{}
+	}
+	return append([]Request{}, sb.requests...)
+}
+
+// ClearRequests forgets a tenant's recorded requests, returning how many
+func (m *Manager) ClearRequests(tenantID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sb, exists := m.tenants[tenantID]
+	if !exists {
+		return 0
+	}
+	cleared := len(sb.requests)
+	sb.requests = nil
+	return cleared
+}
+
+// Purge removes a tenant's sandbox and its recorded requests, returning
+// how many requests were dropped
+func (m *Manager) Purge(tenantID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sb, exists := m.tenants[tenantID]
+	if !exists {
+		return 0
+	}
+	delete(m.tenants, tenantID)
+	return len(sb.requests)
+}
+
+// record appends a request to a tenant's sandbox, dropping the oldest
+// beyond its limit
+func (m *Manager) record(tenantID string, req Request) {
+	req.Time = time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sb, exists := m.tenants[tenantID]
+	if !exists {
+		return
+	}
+	sb.requests = append(sb.requests, req)
+	if over := len(sb.requests) - sb.config.MaxRequests; over > 0 {
+		sb.requests = append([]Request(nil), sb.requests[over:]...)
+	}
+	sb.recorded++
+}
+
+// GetStats returns sandbox statistics
+func (m *Manager) GetStats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	enabled := make([]string, 0)
+	recorded := int64(0)
+	for tenantID, sb := range m.tenants {
+		if sb.config.Enabled {
+			enabled = append(enabled, tenantID)
+		}
+		recorded += sb.recorded
+	}
+	sort.Strings(enabled)
+	return map[string]interface{}{
+		"tenants":  enabled,
+		"recorded": recorded,
+	}
+}
+
+// ForTenant returns the sandbox of a tenant's connectors and actions. It
+// follows the tenant's config as it changes.
+func (m *Manager) ForTenant(tenantID string) *Tenant {
+	return &Tenant{manager: m, tenantID: tenantID}
+}
+
+// Tenant is the sandbox of one tenant. A nil Tenant is never enabled.
+type Tenant struct {
+	manager  *Manager
+	tenantID string
+}
+
+// Enabled reports whether the tenant is in sandbox mode
+func (t *Tenant) Enabled() bool {
+	return t != nil && t.manager.Enabled(t.tenantID)
+}
+
+// CMDBClient returns a client answering a CMDB source with its canned
+// snapshot and recording the updates written back to it
+func (t *Tenant) CMDBClient(source cmdb.Source) cmdb.Client {
+	return &cmdbClient{sandbox: t, source: source.Name}
+}
+
+type cmdbClient struct {
+	sandbox *Tenant
+	source  string
+}
+
+func (c *cmdbClient) Fetch(ctx context.Context) (*cmdb.Snapshot, error) {
+	m := c.sandbox.manager
+	m.mu.RLock()
+	var snapshot *cmdb.Snapshot
+	if sb, exists := m.tenants[c.sandbox.tenantID]; exists {
+		snapshot = sb.config.CMDB[c.source]
+	}
+	m.mu.RUnlock()
+
+	req := Request{Type: RequestCMDBFetch, Target: c.source}
+	var err error
+	if snapshot == nil {
+		err = fmt.Errorf("sandbox has no snapshot of cmdb source %s", c.source)
+		req.Error = err.Error()
+	}
+	m.record(c.sandbox.tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+	// Callers keep the snapshot, so they get their own copy
+	copied := &cmdb.Snapshot{
+		Items:         append([]cmdb.CI{}, snapshot.Items...),
+		Relationships: append([]cmdb.Relationship{}, snapshot.Relationships...),
+	}
+	return copied, nil
+}
+
+func (c *cmdbClient) Apply(ctx context.Context, update cmdb.Update) error {
+	c.sandbox.manager.record(c.sandbox.tenantID, Request{Type: RequestCMDBApply, Target: c.source, Body: update})
+	return nil
+}
+
+// TerraformState returns the canned state of a Terraform source
+func (t *Tenant) TerraformState(source terraform.Source) (*terraform.State, error) {
+	m := t.manager
+	m.mu.RLock()
+	var state *terraform.State
+	if sb, exists := m.tenants[t.tenantID]; exists {
+		state = sb.states[source.Name]
+	}
+	m.mu.RUnlock()
+
+	req := Request{Type: RequestTerraformLoad, Target: source.Name}
+	if state == nil {
+		err := fmt.Errorf("sandbox has no state of terraform source %s", source.Name)
+		req.Error = err.Error()
+		m.record(t.tenantID, req)
+		return nil, err
+	}
+	m.record(t.tenantID, req)
+	copied := *state
+	copied.Resources = append([]terraform.Resource(nil), state.Resources...)
+	return &copied, nil
+}
+
+// CostSource returns a source answering a billing export with the canned
+// rates
+func (t *Tenant) CostSource(url string) cost.Source {
+	return &costSource{sandbox: t, url: url}
+}
+
+type costSource struct {
+	sandbox *Tenant
+	url     string
+}
+
+func (s *costSource) Name() string {
+	return "sandbox"
+}
+
+func (s *costSource) Rates(ctx context.Context) ([]cost.Rate, error) {
+	m := s.sandbox.manager
+	m.mu.RLock()
+	var rates []cost.Rate
+	if sb, exists := m.tenants[s.sandbox.tenantID]; exists {
+		rates = append(rates, sb.config.Rates...)
+	}
+	m.mu.RUnlock()
+
+	req := Request{Type: RequestCostRates, Target: s.url}
+	if len(rates) == 0 {
+		err := fmt.Errorf("sandbox has no rates")
+		req.Error = err.Error()
+		m.record(s.sandbox.tenantID, req)
+		return nil, err
+	}
+	m.record(s.sandbox.tenantID, req)
+	return rates, nil
+}
+
+// Actions returns the performer of the tenant's actions. In sandbox mode
+// webhooks are recorded and answered with their canned status, and
+// pipelines are recorded and run by next, as they only touch the tenant;
+// otherwise next performs every action.
+func (t *Tenant) Actions(next triggers.Performer) triggers.Performer {
+	return &actions{sandbox: t, next: next}
+}
+
+type actions struct {
+	sandbox *Tenant
+	next    triggers.Performer
+}
+
+func (a *actions) Perform(ctx context.Context, action triggers.Action, input []atomspace.Atom, payload map[string]interface{}) error {
+	if !a.sandbox.Enabled() {
+		return a.next.Perform(ctx, action, input, payload)
+	}
+	if err := action.Validate(); err != nil {
+		return err
+	}
+	m := a.sandbox.manager
+	switch action.Type {
+	case triggers.ActionWebhook:
+		status := a.sandbox.webhookStatus(action.URL)
+		req := Request{Type: RequestWebhook, Target: action.URL, Body: payload, Status: status}
+		var err error
+		if status >= 300 {
+			err = fmt.Errorf("webhook returned status %d", status)
+			req.Error = err.Error()
+		}
+		m.record(a.sandbox.tenantID, req)
+		return err
+	case triggers.ActionExecutePipeline:
+		req := Request{Type: RequestExecutePipeline, Target: action.PipelineID, Body: payload}
+		err := a.next.Perform(ctx, action, input, payload)
+		if err != nil {
+			req.Error = err.Error()
+		}
+		m.record(a.sandbox.tenantID, req)
+		return err
+	}
+	return a.next.Perform(ctx, action, input, payload)
+}
+
+// Notifications returns the sender of the tenant's notifications. In
+// sandbox mode notifications are recorded, and those to webhook and Slack
+// channels answered with the canned status of their URL; otherwise next
+// sends them.
+func (t *Tenant) Notifications(next notifications.Sender) notifications.Sender {
+	return &notifier{sandbox: t, next: next}
+}
+
+type notifier struct {
+	sandbox *Tenant
+	next    notifications.Sender
+}
+
+func (n *notifier) Send(ctx context.Context, channel notifications.Channel, notification notifications.Notification) error {
+	if !n.sandbox.Enabled() {
+		return n.next.Send(ctx, channel, notification)
+	}
+	req := Request{Type: RequestNotification, Target: channel.Target(), Body: map[string]interface{}{
+		"channel":  channel.Name,
+		"type":     channel.Type,
+		"topic":    notification.Topic,
+		"severity": notification.Severity,
+		"title":    notification.Title,
+	}}
+	var err error
+	if channel.Type == notifications.ChannelWebhook || channel.Type == notifications.ChannelSlack {
+		req.Status = n.sandbox.webhookStatus(channel.URL)
+		if req.Status >= 300 {
+			err = fmt.Errorf("channel returned status %d", req.Status)
+			req.Error = err.Error()
+		}
+	}
+	n.sandbox.manager.record(n.sandbox.tenantID, req)
+	return err
+}
+
+// webhookStatus returns the canned status of a webhook URL
+func (t *Tenant) webhookStatus(url string) int {
+	t.manager.mu.RLock()
+	defer t.manager.mu.RUnlock()
+	if sb, exists := t.manager.tenants[t.tenantID]; exists {
+		if status, ok := sb.config.Webhooks[url]; ok {
+			return status
+		}
+	}
+	return http.StatusOK
+}
+
+// Deliver records the delivery of a report to each sink instead of
+// sending it. Webhook sinks answer with their canned status.
+func (t *Tenant) Deliver(sinks []reports.Sink, report reports.Report) []reports.Delivery {
+	m := t.manager
+	deliveries := make([]reports.Delivery, 0, len(sinks))
+	for _, sink := range sinks {
+		delivery := reports.Delivery{Sink: sink.Type, Target: sink.Target(), DeliveredAt: time.Now()}
+		req := Request{Type: RequestReport, Target: sink.Target(), Body: map[string]interface{}{
+			"sink":     sink.Type,
+			"schedule": report.Schedule,
+			"sequence": report.Sequence,
+			"format":   report.Format,
+		}}
+		if sink.Type == reports.SinkWebhook {
+			req.Status = t.webhookStatus(sink.URL)
+			if req.Status >= 300 {
+				delivery.Error = fmt.Sprintf("webhook returned status %d", req.Status)
+				req.Error = delivery.Error
+			}
+		}
+		m.record(t.tenantID, req)
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries
+}
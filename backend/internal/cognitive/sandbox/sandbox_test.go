@@ -0,0 +1,119 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/cmdb"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/reports"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/terraform"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/triggers"
+)
+
+type countingPerformer struct {
+	performed int
+}
+
+func (p *countingPerformer) Perform(ctx context.Context, action triggers.Action, input []atomspace.Atom, payload map[string]interface{}) error {
+	p.performed++
+	return nil
+}
+
+func TestConfigValidate(t *testing.T) {
+	invalid := []Config{
+		{Terraform: map[string]json.RawMessage{"prod": json.RawMessage(`{"version": 3}`)}},
+		{CMDB: map[string]*cmdb.Snapshot{"sn": nil}},
+		{Webhooks: map[string]int{"http://hook": 42}},
+		{MaxRequests: -1},
+	}
+	for i, config := range invalid {
+		if err := config.Validate(); err == nil {
+			t.Errorf("Expected config %d to be rejected", i)
+		}
+	}
+}
+
+func TestRecordsOnlyWhileEnabled(t *testing.T) {
+	m := NewManager()
+	next := &countingPerformer{}
+	actions := m.ForTenant("acme").Actions(next)
+	webhook := triggers.Action{Type: triggers.ActionWebhook, URL: "http://hook"}
+
+	if err := actions.Perform(context.Background(), webhook, nil, nil); err != nil || next.performed != 1 {
+		t.Fatalf("Expected actions performed by next without a sandbox, got %d (%v)", next.performed, err)
+	}
+	if _, err := m.Set("acme", Config{Enabled: true, MaxRequests: 2}); err != nil {
+		t.Fatalf("Failed to set sandbox: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := actions.Perform(context.Background(), webhook, nil, map[string]interface{}{"i": i}); err != nil {
+			t.Fatalf("Expected the webhook answered: %v", err)
+		}
+	}
+	requests := m.Requests("acme")
+	if next.performed != 1 || len(requests) != 2 || requests[1].Body.(map[string]interface{})["i"] != 2 {
+		t.Errorf("Expected the last 2 webhooks recorded, got %+v", requests)
+	}
+	if m.Enabled("other") || len(m.Requests("other")) != 0 {
+		t.Error("Expected other tenants unaffected")
+	}
+	if cleared := m.ClearRequests("acme"); cleared != 2 || len(m.Requests("acme")) != 0 {
+		t.Errorf("Expected 2 requests cleared, got %d", cleared)
+	}
+}
+
+func TestCannedSources(t *testing.T) {
+	m := NewManager()
+	state := json.RawMessage(`{"version":4,"serial":7,"lineage":"l1","resources":[]}`)
+	snapshot := &cmdb.Snapshot{Items: []cmdb.CI{{ID: "a1", Name: "web-1"}}}
+	m.Set("acme", Config{Enabled: true, Terraform: map[string]json.RawMessage{"prod": state}, CMDB: map[string]*cmdb.Snapshot{"sn": snapshot}})
+	sb := m.ForTenant("acme")
+
+	loaded, err := sb.TerraformState(terraform.Source{Name: "prod"})
+	if err != nil || loaded.Serial != 7 {
+		t.Errorf("Expected the canned state, got %+v (%v)", loaded, err)
+	}
+	if _, err := sb.TerraformState(terraform.Source{Name: "staging"}); err == nil {
+		t.Error("Expected a source without a canned state to fail")
+	}
+
+	client := sb.CMDBClient(cmdb.Source{Name: "sn"})
+	fetched, err := client.Fetch(context.Background())
+	if err != nil || len(fetched.Items) != 1 {
+		t.Fatalf("Expected the canned snapshot, got %+v (%v)", fetched, err)
+	}
+	fetched.Items[0].Name = "changed"
+	if snapshot.Items[0].Name != "web-1" {
+		t.Error("Expected the canned snapshot left unchanged by callers")
+	}
+	if err := client.Apply(context.Background(), cmdb.Update{Kind: cmdb.UpdateAttribute, CI: "a1", Field: "ip_address", Value: "10.0.0.2"}); err != nil {
+		t.Errorf("Expected updates accepted: %v", err)
+	}
+	if _, err := sb.CostSource("http://billing").Rates(context.Background()); err == nil {
+		t.Error("Expected billing exports to fail without canned rates")
+	}
+
+	deliveries := sb.Deliver([]reports.Sink{{Type: reports.SinkWebhook, URL: "http://hook"}}, reports.Report{Schedule: "daily"})
+	if len(deliveries) != 1 || deliveries[0].Error != "" {
+		t.Errorf("Expected the delivery recorded, got %+v", deliveries)
+	}
+
+	var types []string
+	for _, req := range m.Requests("acme") {
+		types = append(types, req.Type)
+	}
+	want := []string{RequestTerraformLoad, RequestTerraformLoad, RequestCMDBFetch, RequestCMDBApply, RequestCostRates, RequestReport}
+	if len(types) != len(want) {
+		t.Fatalf("Expected requests %v, got %v", want, types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("Expected request %d to be %s, got %s", i, want[i], types[i])
+		}
+	}
+	if m.Purge("acme") != len(want) || m.Enabled("acme") {
+		t.Error("Expected the sandbox purged")
+	}
+}
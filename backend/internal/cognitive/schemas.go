@@ -0,0 +1,133 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/schemas"
+)
+
+// RegisterSchema binds a Go function to the GroundedSchemaNode of its name,
+// for ExecutionLinks of the tenants it is registered for to call
+func (ce *CognitiveEngine) RegisterSchema(s schemas.Schema) error {
+	return ce.schemas.Register(s)
+}
+
+// UnregisterSchema removes a schema. Calls of it wait until it is
+// registered again.
+func (ce *CognitiveEngine) UnregisterSchema(name string) error {
+	return ce.schemas.Unregister(name)
+}
+
+// ListSchemas returns the schemas a tenant's atoms may call
+func (ce *CognitiveEngine) ListSchemas(tenantID string) []schemas.Schema {
+	return ce.schemas.List(tenantID)
+}
+
+// AddExecution adds ExecutionLink(GroundedSchemaNode schema, arguments...)
+// to a tenant's space, with the number and function call atoms its
+// arguments need, and enables the execution agent for the tenant with the
+// default configuration if needed. The agent executes the call on its next
+// run.
+func (ce *CognitiveEngine) AddExecution(tenantID, schema string, args []GroundedArgument) (atomspace.Atom, error) {
+	if _, err := ce.schemas.Get(tenantID, schema); err != nil {
+		return nil, err
+	}
+	outgoing := []atomspace.Atom{atomspace.NewGroundedSchemaNode(schema, tenantID)}
+	for _, arg := range args {
+		atom, err := ce.groundedArgument(tenantID, arg, 0)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", schema, err)
+		}
+		outgoing = append(outgoing, atom)
+	}
+	for _, atom := range outgoing {
+		if err := ce.ensureGrounded(atom); err != nil {
+			return nil, err
+		}
+	}
+
+	link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.ExecutionLinkType, schema, outgoing), schema, tenantID, atomspace.ExecutionLinkType, outgoing)
+	if existing, err := ce.GetAtom(link.GetID(), tenantID); err == nil {
+		return existing, nil
+	}
+	if err := ce.AddAtom(link); err != nil {
+		return nil, err
+	}
+	ce.executionAgent(tenantID)
+	return link, nil
+}
+
+// EnableExecution registers an execution agent for a tenant, or updates
+// the configuration of the existing one
+func (ce *CognitiveEngine) EnableExecution(tenantID string, config agents.ExecutionConfig) *agents.ExecutionAgent {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if agent, exists := ce.executionAgents[tenantID]; exists {
+		agent.SetConfig(config)
+		return agent
+	}
+
+	agent := agents.NewExecutionAgent(
+		fmt.Sprintf("execution-%s", tenantID),
+		"ExecutionAgent",
+		tenantID,
+		&tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID},
+		ce.schemas,
+		ce.decisionPolicies,
+		ce.counterfactuals,
+		config,
+	)
+	agent.SetHold(ce.holdActions)
+	ce.executionAgents[tenantID] = agent
+	ce.agentScheduler.RegisterAgent(agent)
+	return agent
+}
+
+// DisableExecution unregisters a tenant's execution agent. Pending calls
+// stay in the AtomSpace.
+func (ce *CognitiveEngine) DisableExecution(tenantID string) error {
+	ce.mu.Lock()
+	agent, exists := ce.executionAgents[tenantID]
+	delete(ce.executionAgents, tenantID)
+	ce.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("execution not enabled for tenant %s", tenantID)
+	}
+	ce.agentScheduler.UnregisterAgent(agent.GetID())
+	return nil
+}
+
+// executionAgent returns a tenant's execution agent, enabling it with the
+// default configuration if needed
+func (ce *CognitiveEngine) executionAgent(tenantID string) *agents.ExecutionAgent {
+	ce.mu.RLock()
+	agent, exists := ce.executionAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		agent = ce.EnableExecution(tenantID, agents.DefaultExecutionConfig())
+	}
+	return agent
+}
+
+// ExecuteSchemas executes a tenant's pending calls immediately
+func (ce *CognitiveEngine) ExecuteSchemas(ctx context.Context, tenantID string) ([]schemas.Result, error) {
+	return ce.executionAgent(tenantID).Execute(ctx)
+}
+
+// GetExecutionResults returns the results of a tenant's recent calls
+func (ce *CognitiveEngine) GetExecutionResults(tenantID string) []schemas.Result {
+	ce.mu.RLock()
+	agent, exists := ce.executionAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		return []schemas.Result{}
+	}
+	return agent.GetResults()
+}
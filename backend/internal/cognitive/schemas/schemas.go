@@ -0,0 +1,198 @@
+package schemas
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/decisions"
+)
+
+// Func is a Go function bound to a GroundedSchemaNode. It may return an
+// atom as its output, e.g. a NumberNode or a concept, or nil if it has none.
+type Func func(ctx context.Context, call Call) (atomspace.Atom, error)
+
+// Call is one execution of a schema
+type Call struct {
+	TenantID  string
+	Schema    string
+	Link      atomspace.Atom   // The ExecutionLink calling the schema
+	Arguments []atomspace.Atom // The link's other atoms, as currently stored
+}
+
+// Schema binds a Go function to the GroundedSchemaNode of its name
+type Schema struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Arity       int             `json:"arity,omitempty"`      // Number of arguments; unchecked if 0
+	Tenants     []string        `json:"tenants,omitempty"`    // Tenants whose atoms may call it; all if empty
+	Timeout     time.Duration   `json:"timeout_ns,omitempty"` // Longest a call may take; DefaultTimeout if 0
+	Class       decisions.Class `json:"class,omitempty"`      // Action class whose decision threshold calls must meet
+	Func        Func            `json:"-"`
+}
+
+// DefaultTimeout bounds calls of schemas without a timeout
+const DefaultTimeout = 30 * time.Second
+
+// Validate checks a schema
+func (s *Schema) Validate() error {
+	if s.Name == "" || strings.ContainsAny(s.Name, " \t\n") {
+		return fmt.Errorf("schema name must be non-empty and without whitespace")
+	}
+	if s.Func == nil {
+		return fmt.Errorf("schema %s has no function", s.Name)
+	}
+	if s.Arity < 0 || s.Timeout < 0 {
+		return fmt.Errorf("schema %s has a negative arity or timeout", s.Name)
+	}
+	return nil
+}
+
+// visible reports whether a tenant may call the schema
+func (s *Schema) visible(tenantID string) bool {
+	if len(s.Tenants) == 0 {
+		return true
+	}
+	for _, allowed := range s.Tenants {
+		if allowed == tenantID {
+			return true
+		}
+	}
+	return false
+}
+
+// Outcome is the result of a call
+type Outcome string
+
+const (
+	OutcomeSucceeded Outcome = "succeeded"
+	OutcomeFailed    Outcome = "failed"
+	OutcomeHeld      Outcome = "held" // Below the belief its action class needs; not executed yet
+)
+
+// Result records a call of a schema
+type Result struct {
+	Call      string          `json:"call"` // ID of the ExecutionLink
+	Schema    string          `json:"schema"`
+	Class     decisions.Class `json:"class,omitempty"`
+	Arguments []string        `json:"arguments"`        // Names of the arguments
+	Output    string          `json:"output,omitempty"` // ID of the output atom
+	Outcome   Outcome         `json:"outcome"`
+	Error     string          `json:"error,omitempty"`
+	Attempt   int             `json:"attempt"`
+	StartedAt time.Time       `json:"started_at"`
+	Duration  time.Duration   `json:"duration_ns"`
+}
+
+// Registry holds the schemas ExecutionLinks may call. Schemas are code:
+// they are registered by the process embedding the engine, for all tenants
+// or some of them.
+type Registry struct {
+	schemas  map[string]Schema
+	calls    int64
+	failures int64
+	mu       sync.RWMutex
+}
+
+// NewRegistry creates an empty schema registry
+func NewRegistry() *Registry {
+	return &Registry{schemas: make(map[string]Schema)}
+}
+
+// Register adds a schema. A name is registered once; unregister it first to
+// replace it.
+func (r *Registry) Register(s Schema) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.schemas[s.Name]; exists {
+		return fmt.Errorf("schema %s already registered", s.Name)
+	}
+	s.Tenants = append([]string(nil), s.Tenants...)
+	r.schemas[s.Name] = s
+	return nil
+}
+
+// Unregister removes a schema
+func (r *Registry) Unregister(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.schemas[name]; !exists {
+		return fmt.Errorf("schema %s not found", name)
+	}
+	delete(r.schemas, name)
+	return nil
+}
+
+// Get returns a schema a tenant may call
+func (r *Registry) Get(tenantID, name string) (Schema, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, exists := r.schemas[name]
+	if !exists || !s.visible(tenantID) {
+		return Schema{}, fmt.Errorf("schema %s not found", name)
+	}
+	return s, nil
+}
+
+// List returns the schemas a tenant may call, sorted by name
+func (r *Registry) List(tenantID string) []Schema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]Schema, 0, len(r.schemas))
+	for _, s := range r.schemas {
+		if s.visible(tenantID) {
+			list = append(list, s)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Call runs the schema a call names within its timeout. A panic of the
+// function fails the call rather than the caller.
+func (r *Registry) Call(ctx context.Context, call Call) (output atomspace.Atom, err error) {
+	s, err := r.Get(call.TenantID, call.Schema)
+	if err != nil {
+		return nil, err
+	}
+	if s.Arity > 0 && len(call.Arguments) != s.Arity {
+		return nil, fmt.Errorf("schema %s takes %d arguments, got %d", s.Name, s.Arity, len(call.Arguments))
+	}
+
+	atomic.AddInt64(&r.calls, 1)
+	defer func() {
+		if p := recover(); p != nil {
+			output, err = nil, fmt.Errorf("schema %s panicked: %v", s.Name, p)
+		}
+		if err != nil {
+			atomic.AddInt64(&r.failures, 1)
+		}
+	}()
+
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return s.Func(ctx, call)
+}
+
+// GetStats returns registry statistics
+func (r *Registry) GetStats() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return map[string]interface{}{
+		"schemas":  len(r.schemas),
+		"calls":    atomic.LoadInt64(&r.calls),
+		"failures": atomic.LoadInt64(&r.failures),
+	}
+}
@@ -0,0 +1,74 @@
+package schemas
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	echo := func(ctx context.Context, call Call) (atomspace.Atom, error) {
+		return call.Arguments[0], nil
+	}
+	if err := r.Register(Schema{Name: "echo", Arity: 1, Tenants: []string{"acme"}, Func: echo}); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+	for _, invalid := range []Schema{
+		{Name: "echo", Func: echo},
+		{Name: "", Func: echo},
+		{Name: "two words", Func: echo},
+		{Name: "nofunc"},
+		{Name: "negative", Arity: -1, Func: echo},
+	} {
+		if err := r.Register(invalid); err == nil {
+			t.Errorf("Expected %q rejected", invalid.Name)
+		}
+	}
+
+	if len(r.List("acme")) != 1 || len(r.List("other")) != 0 {
+		t.Error("Expected the schema visible to acme only")
+	}
+	arg := atomspace.NewNumberNode(1, "acme")
+	output, err := r.Call(context.Background(), Call{TenantID: "acme", Schema: "echo", Arguments: []atomspace.Atom{arg}})
+	if err != nil || output != arg {
+		t.Errorf("Expected the argument echoed, got %v: %v", output, err)
+	}
+	if _, err := r.Call(context.Background(), Call{TenantID: "other", Schema: "echo", Arguments: []atomspace.Atom{arg}}); err == nil {
+		t.Error("Expected other tenants unable to call the schema")
+	}
+	if _, err := r.Call(context.Background(), Call{TenantID: "acme", Schema: "echo"}); err == nil {
+		t.Error("Expected a call with the wrong arity rejected")
+	}
+
+	if err := r.Unregister("echo"); err != nil {
+		t.Fatalf("Failed to unregister schema: %v", err)
+	}
+	if _, err := r.Get("acme", "echo"); err == nil {
+		t.Error("Expected the schema gone")
+	}
+}
+
+func TestCallFailures(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Schema{Name: "panics", Func: func(ctx context.Context, call Call) (atomspace.Atom, error) {
+		panic("boom")
+	}})
+	r.Register(Schema{Name: "slow", Timeout: 10 * time.Millisecond, Func: func(ctx context.Context, call Call) (atomspace.Atom, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}})
+
+	if _, err := r.Call(context.Background(), Call{TenantID: "acme", Schema: "panics"}); err == nil {
+		t.Error("Expected a panic to fail the call")
+	}
+	if _, err := r.Call(context.Background(), Call{TenantID: "acme", Schema: "slow"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the call to time out, got %v", err)
+	}
+	if stats := r.GetStats(); stats["calls"] != int64(2) || stats["failures"] != int64(2) {
+		t.Errorf("Unexpected stats %v", stats)
+	}
+}
@@ -0,0 +1,33 @@
+package cognitive
+
+import (
+	"context"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/secrets"
+)
+
+// SecretCheck is the outcome of resolving a secret reference. The value is
+// never returned.
+type SecretCheck struct {
+	Reference string `json:"reference"`
+	Resolved  bool   `json:"resolved"`
+	Error     string `json:"error,omitempty"`
+}
+
+// CheckSecrets resolves a tenant's secret references, such as those of a
+// connector configuration about to be applied, and reports which resolve
+func (ce *CognitiveEngine) CheckSecrets(ctx context.Context, tenantID string, references []string) []SecretCheck {
+	checks := make([]SecretCheck, 0, len(references))
+	for _, ref := range references {
+		check := SecretCheck{Reference: ref}
+		if !secrets.IsReference(ref) {
+			check.Error = "not a secret reference"
+		} else if _, err := ce.secrets.Resolve(ctx, tenantID, ref); err != nil {
+			check.Error = ce.secrets.Scrub(err.Error())
+		} else {
+			check.Resolved = true
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
@@ -0,0 +1,173 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// checkNamespace refuses tenant IDs and keys that could leave the tenant's
+// namespace of a backend
+func checkNamespace(tenantID, key string) error {
+	if tenantID == "" || tenantID == "." || tenantID == ".." || strings.ContainsAny(tenantID, `/\`) {
+		return fmt.Errorf("invalid tenant for secrets: %q", tenantID)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid secret key %q", key)
+		}
+	}
+	return nil
+}
+
+// EnvBackend reads secrets from environment variables named after the
+// tenant and the key: PREFIX + TENANT + "_" + KEY, upper-cased with every
+// other character than a letter or digit replaced by "_". The reference
+// secret://env/github-token of tenant acme reads
+// EREBUS_SECRET_ACME_GITHUB_TOKEN.
+type EnvBackend struct {
+	prefix string
+	lookup func(string) (string, bool)
+}
+
+// NewEnvBackend creates an env backend reading variables with a prefix
+func NewEnvBackend(prefix string) *EnvBackend {
+	return &EnvBackend{prefix: prefix, lookup: os.LookupEnv}
+}
+
+// envName converts a tenant or key to a part of a variable name
+func envName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// Variable returns the name of the variable holding a tenant's secret
+func (b *EnvBackend) Variable(tenantID, key string) string {
+	return b.prefix + envName(tenantID) + "_" + envName(key)
+}
+
+func (b *EnvBackend) Get(ctx context.Context, tenantID, key string) (string, error) {
+	if err := checkNamespace(tenantID, key); err != nil {
+		return "", err
+	}
+	name := b.Variable(tenantID, key)
+	value, ok := b.lookup(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s not set", name)
+	}
+	return value, nil
+}
+
+// FileBackend reads secrets from files under a directory per tenant, such
+// as a mounted Kubernetes secret: DIR/TENANT/KEY. A trailing newline is
+// removed.
+type FileBackend struct {
+	dir string
+}
+
+// NewFileBackend creates a file backend reading from a directory
+func NewFileBackend(dir string) *FileBackend {
+	return &FileBackend{dir: dir}
+}
+
+func (b *FileBackend) Get(ctx context.Context, tenantID, key string) (string, error) {
+	if err := checkNamespace(tenantID, key); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(filepath.Join(b.dir, tenantID, filepath.FromSlash(key)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("secret file %s not found for tenant %s", key, tenantID)
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// VaultBackend reads secrets from the KV version 2 engine of Vault, at
+// MOUNT/data/PREFIX/TENANT/PATH. A key is PATH#FIELD; the field is "value"
+// if left out.
+type VaultBackend struct {
+	addr   string
+	token  string
+	mount  string
+	prefix string
+	client *http.Client
+}
+
+// NewVaultBackend creates a Vault backend
+func NewVaultBackend(addr, token, mount, prefix string, timeout time.Duration) *VaultBackend {
+	if timeout <= 0 {
+		timeout = DefaultConfig().Timeout
+	}
+	return &VaultBackend{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		mount:  strings.Trim(mount, "/"),
+		prefix: strings.Trim(prefix, "/"),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (b *VaultBackend) Get(ctx context.Context, tenantID, key string) (string, error) {
+	secretPath, field, _ := strings.Cut(key, "#")
+	if field == "" {
+		field = "value"
+	}
+	if err := checkNamespace(tenantID, secretPath); err != nil {
+		return "", err
+	}
+
+	parts := []string{b.mount, "data"}
+	if b.prefix != "" {
+		parts = append(parts, b.prefix)
+	}
+	parts = append(parts, url.PathEscape(tenantID))
+	for _, part := range strings.Split(secretPath, "/") {
+		parts = append(parts, url.PathEscape(part))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.addr+"/v1/"+strings.Join(parts, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", b.token)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("vault secret %s not found for tenant %s", secretPath, tenantID)
+	}
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("vault returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid vault response: %w", err)
+	}
+	value, ok := body.Data.Data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no field %s", secretPath, field)
+	}
+	return value, nil
+}
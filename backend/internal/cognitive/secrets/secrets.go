@@ -0,0 +1,260 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scheme prefixes the secret references connector configurations may hold
+// instead of credentials: secret://BACKEND/KEY
+const Scheme = "secret://"
+
+// Mask replaces credentials in API responses, exports and errors
+const Mask = "***"
+
+// minScrubLength is the shortest resolved value scrubbed from text; shorter
+// ones would mask unrelated words
+const minScrubLength = 4
+
+// Config configures the secret backends. Backends left unset are off.
+type Config struct {
+	EnvPrefix   string        // Prefix of the environment variables of the env backend, e.g. EREBUS_SECRET_
+	FileDir     string        // Directory of the file backend, holding a directory per tenant
+	VaultAddr   string        // Address of the Vault server of the vault backend
+	VaultToken  string        // Token the vault backend reads secrets with
+	VaultMount  string        // Mount of the KV version 2 engine
+	VaultPrefix string        // Path under the mount holding a directory per tenant
+	CacheTTL    time.Duration // How long resolved values are reused
+	Timeout     time.Duration // Longest a backend may take to return a secret
+}
+
+// DefaultConfig returns the default secrets configuration: the env backend
+// on EREBUS_SECRET_ variables, values cached for 5 minutes
+func DefaultConfig() Config {
+	return Config{
+		EnvPrefix:   "EREBUS_SECRET_",
+		VaultMount:  "secret",
+		VaultPrefix: "erebus",
+		CacheTTL:    5 * time.Minute,
+		Timeout:     10 * time.Second,
+	}
+}
+
+// Backend reads the secrets of a tenant. Keys are confined to the tenant's
+// namespace, so a tenant's references never resolve another's secrets.
+type Backend interface {
+	Get(ctx context.Context, tenantID, key string) (string, error)
+}
+
+// Reference names a secret of a backend
+type Reference struct {
+	Backend string
+	Key     string
+}
+
+func (r Reference) String() string {
+	return Scheme + r.Backend + "/" + r.Key
+}
+
+// IsReference reports whether a configuration value is a secret reference
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, Scheme)
+}
+
+// ParseReference reads a secret://BACKEND/KEY reference
+func ParseReference(value string) (Reference, error) {
+	if !IsReference(value) {
+		return Reference{}, fmt.Errorf("not a secret reference")
+	}
+	backend, key, _ := strings.Cut(strings.TrimPrefix(value, Scheme), "/")
+	if backend == "" || key == "" {
+		return Reference{}, fmt.Errorf("secret reference must be secret://BACKEND/KEY")
+	}
+	return Reference{Backend: backend, Key: key}, nil
+}
+
+// Redact hides a credential of a configuration shown to users. References
+// are kept: they name a secret without revealing it.
+func Redact(value string) string {
+	if value == "" || IsReference(value) {
+		return value
+	}
+	return Mask
+}
+
+// Resolver resolves the secret references of one tenant
+type Resolver interface {
+	// Resolve returns the secret a value references, or the value itself
+	// if it is not a reference
+	Resolve(ctx context.Context, value string) (string, error)
+	// Scrub masks the resolved secrets appearing in a text, such as an
+	// error about to be recorded
+	Scrub(text string) string
+}
+
+// None resolves no references: values are used as they are
+var None Resolver = none{}
+
+type none struct{}
+
+func (none) Resolve(ctx context.Context, value string) (string, error) {
+	if IsReference(value) {
+		return "", fmt.Errorf("no secret store to resolve %s", value)
+	}
+	return value, nil
+}
+
+func (none) Scrub(text string) string {
+	return text
+}
+
+type cached struct {
+	value    string
+	expireAt time.Time
+}
+
+// Manager resolves the secret references of tenants through the configured
+// backends and remembers the values it resolved, so they can be scrubbed
+// from errors and logs.
+type Manager struct {
+	backends    map[string]Backend
+	ttl         time.Duration
+	cache       map[string]map[string]cached // tenantID -> reference -> value
+	resolutions int64
+	failures    int64
+	mu          sync.RWMutex
+}
+
+// NewManager creates a secret manager with the backends a configuration
+// enables
+func NewManager(cfg Config) *Manager {
+	m := &Manager{
+		backends: make(map[string]Backend),
+		ttl:      cfg.CacheTTL,
+		cache:    make(map[string]map[string]cached),
+	}
+	if cfg.EnvPrefix != "" {
+		m.backends["env"] = NewEnvBackend(cfg.EnvPrefix)
+	}
+	if cfg.FileDir != "" {
+		m.backends["file"] = NewFileBackend(cfg.FileDir)
+	}
+	if cfg.VaultAddr != "" {
+		m.backends["vault"] = NewVaultBackend(cfg.VaultAddr, cfg.VaultToken, cfg.VaultMount, cfg.VaultPrefix, cfg.Timeout)
+	}
+	return m
+}
+
+// SetBackend adds or replaces a backend
+func (m *Manager) SetBackend(name string, backend Backend) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.backends[name] = backend
+	m.cache = make(map[string]map[string]cached)
+}
+
+// Resolve returns the secret a tenant's value references, or the value
+// itself if it is not a reference
+func (m *Manager) Resolve(ctx context.Context, tenantID, value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+	ref, err := ParseReference(value)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.RLock()
+	entry, hit := m.cache[tenantID][value]
+	backend, exists := m.backends[ref.Backend]
+	m.mu.RUnlock()
+	if hit && time.Now().Before(entry.expireAt) {
+		return entry.value, nil
+	}
+	if !exists {
+		return "", fmt.Errorf("unknown secret backend %q", ref.Backend)
+	}
+
+	secret, err := backend.Get(ctx, tenantID, ref.Key)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.failures++
+		return "", fmt.Errorf("resolving %s: %w", value, err)
+	}
+	m.resolutions++
+	if m.cache[tenantID] == nil {
+		m.cache[tenantID] = make(map[string]cached)
+	}
+	m.cache[tenantID][value] = cached{value: secret, expireAt: time.Now().Add(m.ttl)}
+	return secret, nil
+}
+
+// Scrub masks every secret resolved for any tenant appearing in a text
+func (m *Manager) Scrub(text string) string {
+	if text == "" {
+		return text
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, refs := range m.cache {
+		for _, entry := range refs {
+			if len(entry.value) >= minScrubLength {
+				text = strings.ReplaceAll(text, entry.value, Mask)
+			}
+		}
+	}
+	return text
+}
+
+// ForTenant returns the resolver of a tenant's references
+func (m *Manager) ForTenant(tenantID string) Resolver {
+	return tenantResolver{manager: m, tenantID: tenantID}
+}
+
+type tenantResolver struct {
+	manager  *Manager
+	tenantID string
+}
+
+func (r tenantResolver) Resolve(ctx context.Context, value string) (string, error) {
+	return r.manager.Resolve(ctx, r.tenantID, value)
+}
+
+func (r tenantResolver) Scrub(text string) string {
+	return r.manager.Scrub(text)
+}
+
+// Purge forgets the secrets resolved for a tenant, returning how many
+func (m *Manager) Purge(tenantID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	removed := len(m.cache[tenantID])
+	delete(m.cache, tenantID)
+	return removed
+}
+
+// GetStats returns secret statistics. Values are never included.
+func (m *Manager) GetStats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	backends := make([]string, 0, len(m.backends))
+	for name := range m.backends {
+		backends = append(backends, name)
+	}
+	sort.Strings(backends)
+	cachedValues := 0
+	for _, refs := range m.cache {
+		cachedValues += len(refs)
+	}
+	return map[string]interface{}{
+		"backends":    backends,
+		"cached":      cachedValues,
+		"resolutions": m.resolutions,
+		"failures":    m.failures,
+	}
+}
@@ -0,0 +1,162 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type countingBackend struct {
+	values map[string]string
+	calls  int
+}
+
+func (b *countingBackend) Get(ctx context.Context, tenantID, key string) (string, error) {
+	b.calls++
+	value, ok := b.values[tenantID+"/"+key]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return value, nil
+}
+
+func TestParseReference(t *testing.T) {
+	ref, err := ParseReference("secret://vault/cmdb/servicenow#password")
+	if err != nil {
+		t.Fatalf("ParseReference failed: %v", err)
+	}
+	if ref.Backend != "vault" || ref.Key != "cmdb/servicenow#password" {
+		t.Errorf("Unexpected reference: %+v", ref)
+	}
+	for _, value := range []string{"plain", "secret://", "secret://env", "secret://env/"} {
+		if _, err := ParseReference(value); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+
+	if Redact("hunter22") != Mask || Redact("") != "" || Redact("secret://env/token") != "secret://env/token" {
+		t.Error("Expected literal credentials masked and references kept")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	backend := &countingBackend{values: map[string]string{"t1/token": "t1-token-value", "t2/token": "t2-token-value"}}
+	m := NewManager(Config{CacheTTL: time.Minute})
+	m.SetBackend("test", backend)
+	ctx := context.Background()
+
+	if value, err := m.Resolve(ctx, "t1", "not-a-reference"); err != nil || value != "not-a-reference" {
+		t.Errorf("Expected plain values unchanged, got %q (%v)", value, err)
+	}
+	// References resolve in the tenant's own namespace
+	for tenantID, expected := range map[string]string{"t1": "t1-token-value", "t2": "t2-token-value"} {
+		value, err := m.ForTenant(tenantID).Resolve(ctx, "secret://test/token")
+		if err != nil || value != expected {
+			t.Errorf("Expected %s for %s, got %q (%v)", expected, tenantID, value, err)
+		}
+	}
+	m.Resolve(ctx, "t1", "secret://test/token")
+	if backend.calls != 2 {
+		t.Errorf("Expected the cached value reused, got %d backend calls", backend.calls)
+	}
+	if _, err := m.Resolve(ctx, "t1", "secret://missing/token"); err == nil {
+		t.Error("Expected an unknown backend to fail")
+	}
+	if _, err := m.Resolve(ctx, "t3", "secret://test/token"); err == nil {
+		t.Error("Expected a missing secret to fail")
+	}
+
+	scrubbed := m.Scrub("401 Unauthorized: token t1-token-value rejected")
+	if strings.Contains(scrubbed, "t1-token-value") || !strings.Contains(scrubbed, Mask) {
+		t.Errorf("Expected the resolved secret scrubbed, got %q", scrubbed)
+	}
+	stats := m.GetStats()
+	if stats["resolutions"].(int64) != 2 || stats["failures"].(int64) != 1 {
+		t.Errorf("Unexpected stats: %v", stats)
+	}
+
+	if removed := m.Purge("t1"); removed != 1 {
+		t.Errorf("Expected 1 cached secret purged, got %d", removed)
+	}
+	if m.Scrub("t2-token-value") != Mask {
+		t.Error("Expected the other tenant's secret still scrubbed")
+	}
+
+	if _, err := None.Resolve(ctx, "secret://test/token"); err == nil {
+		t.Error("Expected references to fail without a secret store")
+	}
+}
+
+func TestEnvBackend(t *testing.T) {
+	b := NewEnvBackend("EREBUS_SECRET_")
+	b.lookup = func(name string) (string, bool) {
+		if name == "EREBUS_SECRET_ACME_PROD_GITHUB_TOKEN" {
+			return "ghp_value", true
+		}
+		return "", false
+	}
+	value, err := b.Get(context.Background(), "acme-prod", "github-token")
+	if err != nil || value != "ghp_value" {
+		t.Errorf("Expected the tenant's variable, got %q (%v)", value, err)
+	}
+	if _, err := b.Get(context.Background(), "other", "github-token"); err == nil {
+		t.Error("Expected another tenant's variable to be missing")
+	}
+}
+
+func TestFileBackend(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "t1", "netbox"), 0o700)
+	os.WriteFile(filepath.Join(dir, "t1", "netbox", "token"), []byte("nb-token\n"), 0o600)
+	os.WriteFile(filepath.Join(dir, "outside"), []byte("leaked"), 0o600)
+
+	b := NewFileBackend(dir)
+	value, err := b.Get(context.Background(), "t1", "netbox/token")
+	if err != nil || value != "nb-token" {
+		t.Errorf("Expected the file without its newline, got %q (%v)", value, err)
+	}
+	for _, key := range []string{"../outside", "netbox/../../outside"} {
+		if _, err := b.Get(context.Background(), "t1", key); err == nil {
+			t.Errorf("Expected %s to be refused", key)
+		}
+	}
+	if _, err := b.Get(context.Background(), "..", "outside"); err == nil {
+		t.Error("Expected an invalid tenant to be refused")
+	}
+}
+
+func TestVaultBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/kv/data/erebus/t1/cmdb" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data": {"data": {"value": "sn-token", "password": "sn-password"}}}`))
+	}))
+	defer server.Close()
+
+	b := NewVaultBackend(server.URL, "root", "kv", "erebus", time.Second)
+	ctx := context.Background()
+	if value, err := b.Get(ctx, "t1", "cmdb"); err != nil || value != "sn-token" {
+		t.Errorf("Expected the value field, got %q (%v)", value, err)
+	}
+	if value, err := b.Get(ctx, "t1", "cmdb#password"); err != nil || value != "sn-password" {
+		t.Errorf("Expected the password field, got %q (%v)", value, err)
+	}
+	if _, err := b.Get(ctx, "t1", "cmdb#missing"); err == nil {
+		t.Error("Expected a missing field to fail")
+	}
+	if _, err := b.Get(ctx, "t2", "cmdb"); err == nil {
+		t.Error("Expected another tenant's path to be missing")
+	}
+}
@@ -0,0 +1,105 @@
+package cognitive
+
+import (
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+)
+
+// SetRuleSelection replaces the strategy choosing which inference rules
+// fire in each iteration for a tenant. Bandit strategies fire the rules
+// with the best recent yield, so tenants with an iteration budget spend it
+// on productive rules; the yields learned so far are discarded.
+func (ce *CognitiveEngine) SetRuleSelection(tenantID string, config inference.SelectionConfig) error {
+	selector, err := inference.NewRuleSelector(config)
+	if err != nil {
+		return err
+	}
+
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	inferenceEngine, exists := ce.inferenceEngines[tenantID]
+	if !exists {
+		return fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+	inferenceEngine.SetSelector(selector)
+	ce.ruleSelections[tenantID] = config
+	return nil
+}
+
+// GetRuleSelection returns a tenant's rule selection strategy and the yield
+// of each rule fired under it
+func (ce *CognitiveEngine) GetRuleSelection(tenantID string) (inference.SelectionConfig, map[string]inference.RuleYield, error) {
+	ce.mu.RLock()
+	inferenceEngine, exists := ce.inferenceEngines[tenantID]
+	config := ce.ruleSelections[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		return inference.SelectionConfig{}, nil, fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+	return config, inferenceEngine.GetSelector().Yields(), nil
+}
+
+// SetInferencePipelining sets whether a tenant's inference iterations
+// overlap. Pipelined runs fire idle rules on a fresh snapshot as soon as any
+// rule's results are merged, trading some redundant derivations, which the
+// merge phase reconciles, for throughput.
+func (ce *CognitiveEngine) SetInferencePipelining(tenantID string, pipelined bool) error {
+	ce.mu.RLock()
+	defer ce.mu.RUnlock()
+
+	inferenceEngine, exists := ce.inferenceEngines[tenantID]
+	if !exists {
+		return fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+	inferenceEngine.SetPipelined(pipelined)
+	return nil
+}
+
+// GetInferencePipelining returns whether a tenant's inference iterations
+// overlap and what merging their results resolved so far
+func (ce *CognitiveEngine) GetInferencePipelining(tenantID string) (bool, inference.MergeStats, error) {
+	ce.mu.RLock()
+	inferenceEngine, exists := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		return false, inference.MergeStats{}, fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+	return inferenceEngine.IsPipelined(), inferenceEngine.GetMergeStats(), nil
+}
+
+// SetInferenceLimits bounds the derivation chains of a tenant's inference
+// runs. Derivations deeper than MaxDepth, or resting on the atom they
+// derive, are dropped, so mutually recursive rules end with a depth_limit
+// status instead of running until the iteration cap.
+func (ce *CognitiveEngine) SetInferenceLimits(tenantID string, limits inference.Limits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+
+	ce.mu.RLock()
+	defer ce.mu.RUnlock()
+
+	inferenceEngine, exists := ce.inferenceEngines[tenantID]
+	if !exists {
+		return fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+	inferenceEngine.SetLimits(limits)
+	return nil
+}
+
+// GetInferenceLimits returns the derivation limits of a tenant's inference
+// runs and how its last run ended
+func (ce *CognitiveEngine) GetInferenceLimits(tenantID string) (inference.Limits, inference.RunReport, error) {
+	ce.mu.RLock()
+	inferenceEngine, exists := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		return inference.Limits{}, inference.RunReport{}, fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+	return inferenceEngine.GetLimits(), inferenceEngine.LastRun(), nil
+}
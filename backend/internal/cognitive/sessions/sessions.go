@@ -0,0 +1,250 @@
+package sessions
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+)
+
+// Session is a short-lived working memory for interactive reasoning. Atoms
+// created and inferred inside a session live in an overlay over the tenant's
+// space and are discarded when the session expires.
+type Session struct {
+	ID           string
+	TenantID     string
+	Space        *atomspace.Overlay
+	CreatedAt    time.Time
+	LastAccessed time.Time
+	TTL          time.Duration
+	inference    *inference.InferenceEngine
+	mu           sync.RWMutex
+}
+
+// touch extends the session's lifetime
+func (s *Session) touch() {
+	s.mu.Lock()
+	s.LastAccessed = time.Now()
+	s.mu.Unlock()
+}
+
+// ExpiresAt returns when the session expires unless accessed again
+func (s *Session) ExpiresAt() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.LastAccessed.Add(s.TTL)
+}
+
+// RunInference runs inference over the session's working memory. Inferred
+// atoms are stored in the session only.
+func (s *Session) RunInference(ctx context.Context, maxIterations int) ([]atomspace.Atom, error) {
+	s.touch()
+	return s.inference.RunInference(ctx, s.TenantID, maxIterations)
+}
+
+// GetStats returns session statistics
+func (s *Session) GetStats() map[string]interface{} {
+	spaceStats := s.Space.GetStats(s.TenantID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return map[string]interface{}{
+		"session_id":    s.ID,
+		"tenant_id":     s.TenantID,
+		"created_at":    s.CreatedAt,
+		"last_accessed": s.LastAccessed,
+		"expires_at":    s.LastAccessed.Add(s.TTL),
+		"ttl_seconds":   int64(s.TTL.Seconds()),
+		"space":         spaceStats,
+	}
+}
+
+func (s *Session) close() {
+	s.inference.Close()
+	s.Space.Close()
+}
+
+// Manager owns all live sessions and expires idle ones
+type Manager struct {
+	sessions   map[string]*Session
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+	workers    int
+	onWork     func(tenantID string, elapsed time.Duration)
+	mu         sync.RWMutex
+	expired    int64
+	done       chan struct{}
+}
+
+// NewManager creates a session manager
+func NewManager(defaultTTL, maxTTL time.Duration, inferenceWorkers int) *Manager {
+	m := &Manager{
+		sessions:   make(map[string]*Session),
+		defaultTTL: defaultTTL,
+		maxTTL:     maxTTL,
+		workers:    inferenceWorkers,
+		done:       make(chan struct{}),
+	}
+
+	go m.janitor()
+
+	return m
+}
+
+// OnWork sets the handler the inference engines of new sessions call with
+// the time each rule application took
+func (m *Manager) OnWork(handler func(tenantID string, elapsed time.Duration)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onWork = handler
+}
+
+// janitor periodically removes expired sessions
+func (m *Manager) janitor() {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.expire(time.Now())
+		case <-m.done:
+			return
+		}
+	}
+}
+
+// expire closes every session idle past its TTL
+func (m *Manager) expire(now time.Time) {
+	m.mu.Lock()
+	var stale []*Session
+	for id, s := range m.sessions {
+		if now.After(s.ExpiresAt()) {
+			stale = append(stale, s)
+			delete(m.sessions, id)
+		}
+	}
+	m.expired += int64(len(stale))
+	m.mu.Unlock()
+
+	for _, s := range stale {
+		s.close()
+	}
+}
+
+// CreateSession opens a new session over the tenant's atomspace
+func (m *Manager) CreateSession(tenantID string, base atomspace.AtomSpaceInterface, ttl time.Duration) (*Session, error) {
+	if ttl <= 0 {
+		ttl = m.defaultTTL
+	}
+	if m.maxTTL > 0 && ttl > m.maxTTL {
+		return nil, fmt.Errorf("session TTL %s exceeds maximum %s", ttl, m.maxTTL)
+	}
+
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate session ID: %w", err)
+	}
+
+	space := atomspace.NewOverlay(base, 1)
+	engine := inference.NewInferenceEngine(space, m.workers)
+	engine.AddRule(inference.NewDeductionRule())
+	engine.AddRule(inference.NewInductionRule())
+	engine.AddRule(inference.NewAbductionRule())
+	m.mu.RLock()
+	if m.onWork != nil {
+		engine.OnWork(m.onWork)
+	}
+	m.mu.RUnlock()
+
+	now := time.Now()
+	s := &Session{
+		ID:           hex.EncodeToString(idBytes),
+		TenantID:     tenantID,
+		Space:        space,
+		CreatedAt:    now,
+		LastAccessed: now,
+		TTL:          ttl,
+		inference:    engine,
+	}
+
+	m.mu.Lock()
+	m.sessions[s.ID] = s
+	m.mu.Unlock()
+
+	return s, nil
+}
+
+// GetSession retrieves a live session of a tenant and extends its lifetime
+func (m *Manager) GetSession(sessionID, tenantID string) (*Session, error) {
+	m.mu.RLock()
+	s, exists := m.sessions[sessionID]
+	m.mu.RUnlock()
+
+	if !exists || s.TenantID != tenantID || time.Now().After(s.ExpiresAt()) {
+		return nil, fmt.Errorf("session %s not found", sessionID)
+	}
+
+	s.touch()
+	return s, nil
+}
+
+// CloseSession discards a session and its working memory
+func (m *Manager) CloseSession(sessionID, tenantID string) error {
+	m.mu.Lock()
+	s, exists := m.sessions[sessionID]
+	if !exists || s.TenantID != tenantID {
+		m.mu.Unlock()
+		return fmt.Errorf("session %s not found", sessionID)
+	}
+	delete(m.sessions, sessionID)
+	m.mu.Unlock()
+
+	s.close()
+	return nil
+}
+
+// GetSessionsByTenant returns the live sessions of a tenant
+func (m *Manager) GetSessionsByTenant(tenantID string) []*Session {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*Session
+	for _, s := range m.sessions {
+		if s.TenantID == tenantID {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// GetStats returns session manager statistics
+func (m *Manager) GetStats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return map[string]interface{}{
+		"active_sessions":     len(m.sessions),
+		"expired_sessions":    m.expired,
+		"default_ttl_seconds": int64(m.defaultTTL.Seconds()),
+	}
+}
+
+// Close shuts down the manager and discards all sessions
+func (m *Manager) Close() {
+	close(m.done)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, s := range m.sessions {
+		s.close()
+		delete(m.sessions, id)
+	}
+}
@@ -0,0 +1,57 @@
+package sharding
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func TestAddAtoms(t *testing.T) {
+	sm := NewShardManager(4, 4)
+	defer sm.Close()
+
+	addNodes(t, sm, "tenant", 10)
+
+	arena := atomspace.NewArena(1000, 1, 2)
+	atoms := make([]atomspace.Atom, 0, 1001)
+	for i := 0; i < 1000; i++ {
+		name := fmt.Sprintf("node-%d", i)
+		atoms = append(atoms, arena.NewNode("tenant/"+name, name, "tenant", atomspace.ConceptNodeType))
+	}
+	// A duplicate within the batch
+	atoms = append(atoms, atoms[500])
+
+	added := sm.AddAtoms(atoms)
+	if len(added) != 990 {
+		t.Errorf("added %d atoms, want 990 new ones", len(added))
+	}
+	if got := len(sm.QueryAtoms("tenant", nil)); got != 1000 {
+		t.Errorf("tenant holds %d atoms, want 1000", got)
+	}
+	var total int64
+	for _, shard := range sm.snapshotShards() {
+		total += shard.Load
+	}
+	if total != 1000 {
+		t.Errorf("total load = %d, want 1000", total)
+	}
+
+	// Bulk-added atoms are indexed like any other
+	if _, err := sm.GetAtom("tenant/node-999", "tenant"); err != nil {
+		t.Errorf("GetAtom after bulk add: %v", err)
+	}
+	if results := sm.SearchAtoms("tenant", "node-99", atomspace.SearchModePrefix, 100); len(results) != 11 {
+		t.Errorf("prefix search = %d results, want 11", len(results))
+	}
+	link := arena.NewLink("tenant/link", "similarity", "tenant", atomspace.SimilarityLinkType, []atomspace.Atom{atoms[9], atoms[1]})
+	if len(sm.AddAtoms([]atomspace.Atom{link})) != 1 {
+		t.Fatal("expected the link to be added")
+	}
+	if link.Outgoing[0] != atoms[1] {
+		t.Error("expected the similarity link's outgoing set in canonical order")
+	}
+	if atoms, _ := sm.FindAtoms("tenant", atomspace.Query{Type: &link.Type}); len(atoms) != 1 {
+		t.Errorf("links found = %d, want 1", len(atoms))
+	}
+}
@@ -0,0 +1,83 @@
+package sharding
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// ConsistencyLevel is how current the atoms a read sees must be
+type ConsistencyLevel string
+
+const (
+	// ConsistencyEventual reads replicas that have not missed changes,
+	// which may lag their primaries by the changes still being applied
+	ConsistencyEventual ConsistencyLevel = "eventual"
+	// ConsistencyBounded reads replicas no further than MaxStaleness
+	// behind their primaries, and the primaries of those that are
+	ConsistencyBounded ConsistencyLevel = "bounded"
+	// ConsistencyStrong reads the primaries, seeing every completed write
+	ConsistencyStrong ConsistencyLevel = "strong"
+)
+
+// Consistency is the consistency a read demands. The zero value reads
+// eventually consistent.
+type Consistency struct {
+	Level        ConsistencyLevel `json:"level,omitempty"`
+	MaxStaleness time.Duration    `json:"max_staleness,omitempty"` // Of bounded reads
+}
+
+// Validate checks the consistency is a known level, with a staleness
+// bound for bounded reads only
+func (c Consistency) Validate() error {
+	switch c.Level {
+	case "", ConsistencyEventual, ConsistencyStrong:
+		if c.MaxStaleness != 0 {
+			return fmt.Errorf("max staleness only applies to bounded reads")
+		}
+	case ConsistencyBounded:
+		if c.MaxStaleness <= 0 {
+			return fmt.Errorf("bounded reads need a positive max staleness")
+		}
+	default:
+		return fmt.Errorf("unknown consistency level %q", c.Level)
+	}
+	return nil
+}
+
+// Reader answers queries across shards at a consistency level
+type Reader struct {
+	sm          *ShardManager
+	consistency Consistency
+}
+
+// Reader returns a reader of the shards at the given consistency
+func (sm *ShardManager) Reader(c Consistency) Reader {
+	return Reader{sm: sm, consistency: c}
+}
+
+// SetReplicaLag sets how replicas report how far behind their primaries
+// they are, for bounded reads. Without it bounded reads go to primaries.
+func (sm *ShardManager) SetReplicaLag(lag func() time.Duration) {
+	sm.replicaLag.Store(&lag)
+}
+
+// space returns the atom space a shard is read from at the reader's
+// consistency, and whether it is the shard's replica
+func (r Reader) space(s *Shard) (*atomspace.AtomSpace, bool) {
+	space := s.reader()
+	if space == s.AtomSpace {
+		return space, false
+	}
+	switch r.consistency.Level {
+	case ConsistencyStrong:
+		return s.AtomSpace, false
+	case ConsistencyBounded:
+		lag := r.sm.replicaLag.Load()
+		if lag == nil || (*lag)() > r.consistency.MaxStaleness {
+			return s.AtomSpace, false
+		}
+	}
+	return space, true
+}
@@ -0,0 +1,70 @@
+package sharding
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func TestReadConsistency(t *testing.T) {
+	sm := NewShardManager(2, 2)
+	defer sm.Close()
+
+	addNodes(t, sm, "tenant", 20)
+	sm.EnableReplicas(DefaultReplicaConfig())
+	atom := atomspace.NewNode("tenant/late", "late", "tenant", atomspace.ConceptNodeType)
+	if err := sm.AddAtom(atom); err != nil {
+		t.Fatalf("AddAtom failed: %v", err)
+	}
+
+	// The replicas have not applied the new atom yet
+	bounded := Consistency{Level: ConsistencyBounded, MaxStaleness: time.Second}
+	var lag time.Duration
+	for _, tc := range []struct {
+		name        string
+		consistency Consistency
+		lag         *time.Duration
+		want        int
+	}{
+		{"eventual", Consistency{}, nil, 20},
+		{"strong", Consistency{Level: ConsistencyStrong}, nil, 21},
+		{"bounded without lag", bounded, nil, 21},
+		{"bounded within", bounded, &lag, 20},
+	} {
+		if tc.lag != nil {
+			sm.SetReplicaLag(func() time.Duration { return *tc.lag })
+		}
+		if got := len(sm.Reader(tc.consistency).QueryAtoms("tenant", nil)); got != tc.want {
+			t.Errorf("%s read = %d atoms, want %d", tc.name, got, tc.want)
+		}
+	}
+	lag = 2 * time.Second
+	if got := len(sm.Reader(bounded).QueryAtoms("tenant", nil)); got != 21 {
+		t.Errorf("read of replicas further behind than the bound = %d atoms, want 21", got)
+	}
+
+	// Plans tell which reads replicas answered
+	if _, plans := sm.Reader(Consistency{Level: ConsistencyStrong}).FindAtoms("tenant", atomspace.Query{Name: "late"}); plans[0].Replica {
+		t.Errorf("expected strong reads answered by primaries, got %+v", plans)
+	}
+	if results := sm.Reader(Consistency{Level: ConsistencyStrong}).SearchAtoms("tenant", "late", atomspace.SearchModePrefix, 10); len(results) != 1 {
+		t.Errorf("expected a strong search to find the new atom, got %d results", len(results))
+	}
+	if _, plans := sm.FindAtoms("tenant", atomspace.Query{Name: "late"}); !plans[0].Replica {
+		t.Errorf("expected eventual reads answered by replicas, got %+v", plans)
+	}
+}
+
+func TestConsistencyValidate(t *testing.T) {
+	for _, c := range []Consistency{{}, {Level: ConsistencyStrong}, {Level: ConsistencyBounded, MaxStaleness: time.Second}} {
+		if err := c.Validate(); err != nil {
+			t.Errorf("expected %+v valid, got %v", c, err)
+		}
+	}
+	for _, c := range []Consistency{{Level: "linearizable"}, {Level: ConsistencyBounded}, {Level: ConsistencyStrong, MaxStaleness: time.Second}} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("expected %+v rejected", c)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package sharding
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HotConfig controls hot shard detection
+type HotConfig struct {
+	Interval time.Duration // Window over which shard operation rates are measured
+	Factor   float64       // A shard is hot above this multiple of the mean rate
+	MinRate  float64       // Operations per second below which no shard is hot
+}
+
+// DefaultHotConfig returns the default hot shard detection configuration
+func DefaultHotConfig() HotConfig {
+	return HotConfig{
+		Interval: 10 * time.Second,
+		Factor:   2,
+		MinRate:  100,
+	}
+}
+
+// maxHotTenants is how many of a hot shard's busiest tenants are reported
+const maxHotTenants = 5
+
+// TenantHeat is a tenant's share of the operations on a shard
+type TenantHeat struct {
+	TenantID     string  `json:"tenant_id"`
+	OpsPerSecond float64 `json:"ops_per_second"`
+}
+
+// ShardHeat is the operation rate of a shard over the last window
+type ShardHeat struct {
+	ShardID      int          `json:"shard_id"`
+	OpsPerSecond float64      `json:"ops_per_second"`
+	Hot          bool         `json:"hot"`
+	DedicatedTo  string       `json:"dedicated_to,omitempty"`
+	Tenants      []TenantHeat `json:"tenants,omitempty"` // Busiest first
+}
+
+// Metrics receives the operation rates of shards
+type Metrics interface {
+	ShardHeat(shardID int, opsPerSecond float64, hot bool)
+}
+
+type noMetrics struct{}
+
+func (noMetrics) ShardHeat(shardID int, opsPerSecond float64, hot bool) {}
+
+// PrometheusMetrics exports shard heat as erebus_shard_* series
+type PrometheusMetrics struct {
+	ops *prometheus.GaugeVec
+	hot *prometheus.GaugeVec
+}
+
+// NewPrometheusMetrics creates shard metrics registered with reg
+func NewPrometheusMetrics(reg prometheus.Registerer) (*PrometheusMetrics, error) {
+	m := &PrometheusMetrics{
+		ops: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "erebus_shard_ops_per_second",
+				Help: "Atom operations routed to a shard per second",
+			},
+			[]string{"shard"},
+		),
+		hot: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "erebus_shard_hot",
+				Help: "Whether a shard is hot: 1 if its rate far exceeds the mean",
+			},
+			[]string{"shard"},
+		),
+	}
+	for _, c := range []prometheus.Collector{m.ops, m.hot} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// ShardHeat sets the gauges of a shard
+func (m *PrometheusMetrics) ShardHeat(shardID int, opsPerSecond float64, hot bool) {
+	shard := strconv.Itoa(shardID)
+	m.ops.WithLabelValues(shard).Set(opsPerSecond)
+	value := 0.0
+	if hot {
+		value = 1
+	}
+	m.hot.WithLabelValues(shard).Set(value)
+}
+
+// counter counts the operations routed to a shard, by tenant
+type counter struct {
+	mu      sync.Mutex
+	tenants map[string]int64
+}
+
+func (c *counter) add(tenantID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tenants == nil {
+		c.tenants = make(map[string]int64)
+	}
+	c.tenants[tenantID]++
+}
+
+// take returns and resets the counts
+func (c *counter) take() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := c.tenants
+	c.tenants = nil
+	return counts
+}
+
+// measure turns the counts of each shard over elapsed into heat, marking
+// shards hot whose rate exceeds both the minimum and a multiple of the mean
+func measure(counts []map[string]int64, elapsed time.Duration, config HotConfig) []ShardHeat {
+	heats := make([]ShardHeat, len(counts))
+	seconds := elapsed.Seconds()
+	if seconds <= 0 {
+		seconds = 1
+	}
+
+	total := 0.0
+	for shardID, tenants := range counts {
+		heat := ShardHeat{ShardID: shardID}
+		for tenantID, n := range tenants {
+			rate := float64(n) / seconds
+			heat.OpsPerSecond += rate
+			heat.Tenants = append(heat.Tenants, TenantHeat{TenantID: tenantID, OpsPerSecond: rate})
+		}
+		sort.Slice(heat.Tenants, func(i, j int) bool {
+			if heat.Tenants[i].OpsPerSecond != heat.Tenants[j].OpsPerSecond {
+				return heat.Tenants[i].OpsPerSecond > heat.Tenants[j].OpsPerSecond
+			}
+			return heat.Tenants[i].TenantID < heat.Tenants[j].TenantID
+		})
+		if len(heat.Tenants) > maxHotTenants {
+			heat.Tenants = heat.Tenants[:maxHotTenants]
+		}
+		total += heat.OpsPerSecond
+		heats[shardID] = heat
+	}
+
+	if len(heats) == 0 {
+		return heats
+	}
+	mean := total / float64(len(heats))
+	for i := range heats {
+		heats[i].Hot = heats[i].OpsPerSecond >= config.MinRate && heats[i].OpsPerSecond > config.Factor*mean
+	}
+	return heats
+}
+
+// SetHotConfig sets how hot shards are detected. It applies from the next
+// sample on.
+func (sm *ShardManager) SetHotConfig(config HotConfig) {
+	defaults := DefaultHotConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.Factor <= 0 {
+		config.Factor = defaults.Factor
+	}
+
+	sm.heatMu.Lock()
+	defer sm.heatMu.Unlock()
+	sm.hotConfig = config
+}
+
+// SetMetrics sets where shard heat is reported after each sample
+func (sm *ShardManager) SetMetrics(m Metrics) {
+	sm.heatMu.Lock()
+	defer sm.heatMu.Unlock()
+	sm.metrics = m
+}
+
+// heatMonitor samples the operation rate of each shard every interval
+func (sm *ShardManager) heatMonitor() {
+	last := time.Now()
+	for {
+		sm.heatMu.Lock()
+		interval := sm.hotConfig.Interval
+		sm.heatMu.Unlock()
+
+		select {
+		case now := <-time.After(interval):
+			sm.sampleHeat(now.Sub(last))
+			last = now
+		case <-sm.done:
+			return
+		}
+	}
+}
+
+// sampleHeat measures the operations counted since the last sample
+func (sm *ShardManager) sampleHeat(elapsed time.Duration) {
+	shards := sm.snapshotShards()
+	counts := make([]map[string]int64, len(shards))
+	for i, shard := range shards {
+		counts[i] = shard.ops.take()
+	}
+
+	sm.heatMu.Lock()
+	config, metrics := sm.hotConfig, sm.metrics
+	sm.heatMu.Unlock()
+
+	heats := measure(counts, elapsed, config)
+	for _, heat := range heats {
+		metrics.ShardHeat(heat.ShardID, heat.OpsPerSecond, heat.Hot)
+	}
+
+	sm.heatMu.Lock()
+	defer sm.heatMu.Unlock()
+	sm.heat = heats
+}
+
+// HotShards returns the operation rate of each shard over the last sample,
+// with the busiest tenants of each. Pinning a hot shard's busiest tenant to
+// shards of its own takes its load off the other tenants.
+func (sm *ShardManager) HotShards() []ShardHeat {
+	numShards := len(sm.snapshotShards())
+
+	sm.heatMu.Lock()
+	heats := make([]ShardHeat, numShards)
+	for i := range heats {
+		heats[i] = ShardHeat{ShardID: i}
+	}
+	copy(heats, sm.heat)
+	sm.heatMu.Unlock()
+
+	for i := range heats {
+		heats[i].DedicatedTo = sm.dedicatedTo(i)
+	}
+	return heats
+}
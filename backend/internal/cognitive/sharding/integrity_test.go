@@ -0,0 +1,74 @@
+package sharding
+
+import (
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	sm := NewShardManager(2, 2)
+	defer sm.Close()
+
+	addNodes(t, sm, "tenant", 10)
+	get := func(name string) atomspace.Atom {
+		t.Helper()
+		atom, err := sm.GetAtom("tenant/"+name, "tenant")
+		if err != nil {
+			t.Fatalf("GetAtom %s failed: %v", name, err)
+		}
+		return atom
+	}
+	for i, pair := range [][2]string{{"node-0", "node-1"}, {"node-2", "node-3"}} {
+		link := atomspace.NewLink(string(rune('a'+i)), "", "tenant", atomspace.InheritanceLinkType,
+			[]atomspace.Atom{get(pair[0]), get(pair[1])})
+		if err := sm.AddAtom(link); err != nil {
+			t.Fatalf("AddAtom failed: %v", err)
+		}
+	}
+
+	// Links to atoms on other shards are consistent
+	report := sm.CheckIntegrity(false)
+	if report.Atoms != 12 || report.Links != 2 || len(report.Issues) != 0 {
+		t.Fatalf("consistent shards: atoms = %d, links = %d, issues = %+v", report.Atoms, report.Links, report.Issues)
+	}
+
+	// A deleted outgoing atom leaves its link dangling
+	if err := sm.DeleteAtom("tenant/node-3", "tenant"); err != nil {
+		t.Fatalf("DeleteAtom failed: %v", err)
+	}
+	// An atom renamed without reindexing leaves its name and search
+	// entries behind
+	get("node-5").(*atomspace.Node).Name = "renamed"
+
+	report = sm.CheckIntegrity(false)
+	kinds := make(map[atomspace.IssueKind]int)
+	for _, issue := range report.Issues {
+		kinds[issue.Kind]++
+		if issue.Repaired {
+			t.Errorf("check without repair repaired %+v", issue)
+		}
+	}
+	if kinds[atomspace.IssueDanglingLink] != 1 || kinds[atomspace.IssueOrphanEntry] != 2 || kinds[atomspace.IssueMissingEntry] != 2 {
+		t.Fatalf("issues = %+v", report.Issues)
+	}
+	if len(sm.SearchAtoms("tenant", "renamed", atomspace.SearchModePrefix, 0)) != 0 {
+		t.Error("expected the renamed atom not to be searchable before the repair")
+	}
+
+	// Repairs rebuild the indices but leave dangling links
+	report = sm.CheckIntegrity(true)
+	if report.Repaired != 4 {
+		t.Errorf("repaired = %d, want 4", report.Repaired)
+	}
+	report = sm.CheckIntegrity(false)
+	if len(report.Issues) != 1 || report.Issues[0].Kind != atomspace.IssueDanglingLink || report.Issues[0].AtomID != "b" {
+		t.Errorf("issues after repair = %+v", report.Issues)
+	}
+	if len(sm.SearchAtoms("tenant", "renamed", atomspace.SearchModePrefix, 0)) != 1 {
+		t.Error("expected the renamed atom to be searchable after the repair")
+	}
+	if got := len(sm.QueryAtoms("tenant", nil)); got != 11 {
+		t.Errorf("atoms after repair = %d, want 11", got)
+	}
+}
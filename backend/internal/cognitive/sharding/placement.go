@@ -0,0 +1,318 @@
+package sharding
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// Placement pins a tenant's atoms to a set of shards. A dedicated set holds
+// only that tenant's atoms; other tenants are hashed across the remaining
+// shards.
+type Placement struct {
+	TenantID  string `json:"tenant_id"`
+	Shards    []int  `json:"shards"`
+	Dedicated bool   `json:"dedicated"`
+}
+
+// routingTable maps tenants onto shards. It is replaced, never modified,
+// when a placement changes.
+type routingTable struct {
+	placements map[string]Placement // tenantID -> pinned placement
+	dedicated  map[int]string       // shardID -> tenant it is dedicated to
+	shared     []int                // Shards of tenants without a placement
+}
+
+func newRoutingTable(numShards int) *routingTable {
+	t := &routingTable{
+		placements: make(map[string]Placement),
+		dedicated:  make(map[int]string),
+	}
+	for i := 0; i < numShards; i++ {
+		t.shared = append(t.shared, i)
+	}
+	return t
+}
+
+// route picks a shard of the tenant's set by hash. Without placements the
+// set is every shard, so atoms are hashed as they always were.
+func (t *routingTable) route(tenantID string, hash uint64) int {
+	shards := t.shared
+	if p, pinned := t.placements[tenantID]; pinned {
+		shards = p.Shards
+	}
+	return shards[hash%uint64(len(shards))]
+}
+
+// with returns a copy of the table with a tenant's placement replaced;
+// a placement without shards removes it
+func (t *routingTable) with(p Placement, numShards int) *routingTable {
+	next := &routingTable{
+		placements: make(map[string]Placement, len(t.placements)+1),
+		dedicated:  make(map[int]string),
+	}
+	for tenantID, existing := range t.placements {
+		if tenantID != p.TenantID {
+			next.placements[tenantID] = existing
+		}
+	}
+	if len(p.Shards) > 0 {
+		next.placements[p.TenantID] = p
+	}
+	for tenantID, existing := range next.placements {
+		if existing.Dedicated {
+			for _, shardID := range existing.Shards {
+				next.dedicated[shardID] = tenantID
+			}
+		}
+	}
+	for i := 0; i < numShards; i++ {
+		if _, taken := next.dedicated[i]; !taken {
+			next.shared = append(next.shared, i)
+		}
+	}
+	return next
+}
+
+// validate checks a placement against the placements of other tenants
+func (t *routingTable) validate(p Placement, numShards int) error {
+	if p.TenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+	if len(p.Shards) == 0 {
+		return fmt.Errorf("at least one shard is required")
+	}
+	seen := make(map[int]bool, len(p.Shards))
+	for _, shardID := range p.Shards {
+		if shardID < 0 || shardID >= numShards {
+			return fmt.Errorf("invalid shard ID: %d", shardID)
+		}
+		if seen[shardID] {
+			return fmt.Errorf("shard %d is listed twice", shardID)
+		}
+		seen[shardID] = true
+		if owner, taken := t.dedicated[shardID]; taken && owner != p.TenantID {
+			return fmt.Errorf("shard %d is dedicated to tenant %s", shardID, owner)
+		}
+	}
+	if !p.Dedicated {
+		return nil
+	}
+
+	for tenantID, existing := range t.placements {
+		if tenantID == p.TenantID {
+			continue
+		}
+		for _, shardID := range existing.Shards {
+			if seen[shardID] {
+				return fmt.Errorf("shard %d is pinned by tenant %s", shardID, tenantID)
+			}
+		}
+	}
+	free := 0
+	for i := 0; i < numShards; i++ {
+		if owner, taken := t.dedicated[i]; !seen[i] && (!taken || owner == p.TenantID) {
+			free++
+		}
+	}
+	if free == 0 {
+		return fmt.Errorf("dedicating shards %v would leave no shard for other tenants", p.Shards)
+	}
+	return nil
+}
+
+// ErrMigrating reports a layout change refused while atoms are still
+// moving after the previous one
+var ErrMigrating = errors.New("a shard migration is in progress")
+
+// PinTenant routes a tenant's atoms to the shards of a placement, replacing
+// any placement it had, and moves the atoms whose shard changed. Dedicating
+// shards also moves other tenants' atoms off them. Atoms move in batches
+// while reads and writes continue; it returns how many were moved.
+func (sm *ShardManager) PinTenant(p Placement) (int, error) {
+	p.Shards = append([]int(nil), p.Shards...)
+	sort.Ints(p.Shards)
+
+	if !sm.layoutMu.TryLock() {
+		return 0, ErrMigrating
+	}
+	defer sm.layoutMu.Unlock()
+
+	sm.placementMu.Lock()
+	current := sm.routes.Load()
+	if err := current.validate(p, sm.numShards); err != nil {
+		sm.placementMu.Unlock()
+		return 0, err
+	}
+	sm.beginMigration()
+	sm.routes.Store(current.with(p, sm.numShards))
+	sm.placementMu.Unlock()
+
+	return sm.migrate(), nil
+}
+
+// UnpinTenant returns a tenant to the shards shared by unpinned tenants and
+// returns how many atoms were moved
+func (sm *ShardManager) UnpinTenant(tenantID string) (int, error) {
+	if !sm.layoutMu.TryLock() {
+		return 0, ErrMigrating
+	}
+	defer sm.layoutMu.Unlock()
+
+	sm.placementMu.Lock()
+	current := sm.routes.Load()
+	if _, pinned := current.placements[tenantID]; !pinned {
+		sm.placementMu.Unlock()
+		return 0, fmt.Errorf("tenant %s is not pinned", tenantID)
+	}
+	sm.beginMigration()
+	sm.routes.Store(current.with(Placement{TenantID: tenantID}, sm.numShards))
+	sm.placementMu.Unlock()
+
+	return sm.migrate(), nil
+}
+
+// GetPlacement returns a tenant's placement
+func (sm *ShardManager) GetPlacement(tenantID string) (Placement, bool) {
+	p, pinned := sm.routes.Load().placements[tenantID]
+	return p, pinned
+}
+
+// ListPlacements returns the placements of all pinned tenants
+func (sm *ShardManager) ListPlacements() []Placement {
+	table := sm.routes.Load()
+	placements := make([]Placement, 0, len(table.placements))
+	for _, p := range table.placements {
+		placements = append(placements, p)
+	}
+	sort.Slice(placements, func(i, j int) bool {
+		return placements[i].TenantID < placements[j].TenantID
+	})
+	return placements
+}
+
+// migrationBatch is how many atoms move per hold of the placement lock
+const migrationBatch = 256
+
+// migrate moves every atom not on the shard it now routes to, a batch at a
+// time. Between batches atoms are found on either shard by locate. An atom
+// whose ID is already taken on its new shard stays where it is. The caller
+// holds layoutMu and began the migration before changing routes. Replicas
+// are rebuilt once atoms have moved.
+func (sm *ShardManager) migrate() int {
+	defer sm.syncReplicas()
+	defer sm.migrating.Store(false)
+
+	moved := 0
+	var strays int64
+	for _, from := range sm.snapshotShards() {
+		atoms := from.AtomSpace.AllAtoms()
+		for start := 0; start < len(atoms); start += migrationBatch {
+			select {
+			case <-sm.done:
+				return moved
+			default:
+			}
+			end := start + migrationBatch
+			if end > len(atoms) {
+				end = len(atoms)
+			}
+			n, s := sm.moveBatch(from, atoms[start:end])
+			moved += n
+			strays += s
+			sm.migrationMu.Lock()
+			sm.migration.Moved = moved
+			sm.migrationMu.Unlock()
+		}
+	}
+	sm.strays.Store(strays)
+	return moved
+}
+
+// beginMigration has lookups search every shard, and queries read the
+// primaries, whose atoms move under the placement lock, until atoms moved
+func (sm *ShardManager) beginMigration() {
+	sm.migrating.Store(true)
+	sm.MarkReplicasStale()
+}
+
+// moveBatch moves atoms of a shard to the shards they route to and returns
+// how many moved and how many could not
+func (sm *ShardManager) moveBatch(from *Shard, atoms []atomspace.Atom) (int, int64) {
+	sm.placementMu.Lock()
+	defer sm.placementMu.Unlock()
+
+	moved := 0
+	var strays int64
+	for _, atom := range atoms {
+		atomID, tenantID := atom.GetID(), atom.GetTenantID()
+		to := sm.shards[sm.getShardIDInternal(atomID, tenantID)]
+		if to == from {
+			continue
+		}
+		// Skip atoms deleted or replaced since the shard was listed
+		if current, err := from.AtomSpace.GetAtom(atomID, tenantID); err != nil || current != atom {
+			continue
+		}
+		if err := from.AtomSpace.ForceDeleteAtom(atomID, tenantID); err != nil {
+			continue
+		}
+		if err := to.AtomSpace.AddAtom(atom); err != nil {
+			from.AtomSpace.AddAtom(atom)
+			strays++
+			continue
+		}
+		from.mu.Lock()
+		from.Load--
+		from.mu.Unlock()
+		to.mu.Lock()
+		to.Load++
+		to.mu.Unlock()
+		moved++
+	}
+	return moved, strays
+}
+
+// locate returns the shard holding an atom: the shard it routes to, unless
+// the atom has not been moved there yet
+func (sm *ShardManager) locate(atomID, tenantID string) *Shard {
+	if shard, found := sm.find(atomID, tenantID); found {
+		return shard
+	}
+	return sm.GetShard(atomID, tenantID)
+}
+
+// find looks an atom up off its routed shard, while atoms are moving or
+// some could not be moved. Otherwise it reports the atom as not found.
+func (sm *ShardManager) find(atomID, tenantID string) (*Shard, bool) {
+	if !sm.migrating.Load() && sm.strays.Load() == 0 {
+		return nil, false
+	}
+	shard := sm.GetShard(atomID, tenantID)
+	if _, err := shard.AtomSpace.GetAtom(atomID, tenantID); err == nil {
+		return shard, true
+	}
+	for _, other := range sm.snapshotShards() {
+		if other == shard {
+			continue
+		}
+		if _, err := other.AtomSpace.GetAtom(atomID, tenantID); err == nil {
+			return other, true
+		}
+	}
+	return nil, false
+}
+
+// snapshotShards returns the current shards, routed and retired
+func (sm *ShardManager) snapshotShards() []*Shard {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return append([]*Shard(nil), sm.shards...)
+}
+
+// dedicatedTo returns the tenant a shard is dedicated to, if any
+func (sm *ShardManager) dedicatedTo(shardID int) string {
+	return sm.routes.Load().dedicated[shardID]
+}
@@ -0,0 +1,146 @@
+package sharding
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func addNodes(t *testing.T, sm *ShardManager, tenantID string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("node-%d", i)
+		if err := sm.AddAtom(atomspace.NewNode(tenantID+"/"+name, name, tenantID, atomspace.ConceptNodeType)); err != nil {
+			t.Fatalf("AddAtom failed: %v", err)
+		}
+	}
+}
+
+// distribution returns how many of a tenant's atoms each shard holds
+func distribution(sm *ShardManager, tenantID string) map[int]int {
+	shards := make(map[int]int)
+	for _, shard := range sm.shards {
+		if n := len(shard.AtomSpace.QueryAtoms(tenantID, nil)); n > 0 {
+			shards[shard.ID] = n
+		}
+	}
+	return shards
+}
+
+func TestPinTenant(t *testing.T) {
+	sm := NewShardManager(4, 4)
+	defer sm.Close()
+
+	addNodes(t, sm, "big", 50)
+	addNodes(t, sm, "small", 50)
+
+	moved, err := sm.PinTenant(Placement{TenantID: "big", Shards: []int{3}, Dedicated: true})
+	if err != nil {
+		t.Fatalf("PinTenant failed: %v", err)
+	}
+	if moved == 0 {
+		t.Error("expected atoms to move")
+	}
+	if got := distribution(sm, "big"); len(got) != 1 || got[3] != 50 {
+		t.Errorf("big atoms by shard = %v, want all on shard 3", got)
+	}
+	if got := distribution(sm, "small"); got[3] != 0 || len(got) == 0 {
+		t.Errorf("small atoms by shard = %v, want none on dedicated shard 3", got)
+	}
+	for _, tenantID := range []string{"big", "small"} {
+		if _, err := sm.GetAtom(tenantID+"/node-7", tenantID); err != nil {
+			t.Errorf("GetAtom(%s) after pinning: %v", tenantID, err)
+		}
+	}
+	if err := sm.UpdateAtom("small/node-7", "small", func(a atomspace.Atom) error { return nil }); err != nil {
+		t.Errorf("UpdateAtom after pinning: %v", err)
+	}
+
+	var total int64
+	for _, shard := range sm.shards {
+		total += shard.Load
+	}
+	if total != 100 {
+		t.Errorf("total load = %d, want 100", total)
+	}
+
+	// New atoms follow the placement too
+	addNodes(t, sm, "late", 10)
+	if got := distribution(sm, "late"); got[3] != 0 {
+		t.Errorf("late atoms by shard = %v, want none on dedicated shard 3", got)
+	}
+
+	if _, err := sm.UnpinTenant("big"); err != nil {
+		t.Fatalf("UnpinTenant failed: %v", err)
+	}
+	if got := distribution(sm, "big"); len(got) < 2 {
+		t.Errorf("big atoms by shard after unpinning = %v, want them spread", got)
+	}
+	if _, pinned := sm.GetPlacement("big"); pinned {
+		t.Error("expected no placement after unpinning")
+	}
+	if _, err := sm.UnpinTenant("big"); err == nil {
+		t.Error("expected unpinning an unpinned tenant to fail")
+	}
+}
+
+func TestPinTenantValidation(t *testing.T) {
+	sm := NewShardManager(2, 2)
+	defer sm.Close()
+
+	if _, err := sm.PinTenant(Placement{TenantID: "a", Shards: []int{0}, Dedicated: true}); err != nil {
+		t.Fatalf("PinTenant failed: %v", err)
+	}
+
+	cases := map[string]Placement{
+		"no shards":         {TenantID: "b"},
+		"unknown shard":     {TenantID: "b", Shards: []int{2}},
+		"duplicate shard":   {TenantID: "b", Shards: []int{1, 1}},
+		"dedicated to a":    {TenantID: "b", Shards: []int{0}},
+		"no shared shard":   {TenantID: "b", Shards: []int{1}, Dedicated: true},
+		"missing tenant ID": {Shards: []int{1}},
+	}
+	for name, p := range cases {
+		if _, err := sm.PinTenant(p); err == nil {
+			t.Errorf("%s: expected PinTenant to fail", name)
+		}
+	}
+
+	// Pinning without dedicating shares the shard with unpinned tenants
+	if _, err := sm.PinTenant(Placement{TenantID: "b", Shards: []int{1}}); err != nil {
+		t.Errorf("PinTenant to a shared shard failed: %v", err)
+	}
+	if got := len(sm.ListPlacements()); got != 2 {
+		t.Errorf("placements = %d, want 2", got)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	counts := []map[string]int64{
+		{"big": 9000, "small": 1000},
+		{"small": 500},
+		nil,
+		{"other": 500},
+	}
+	heats := measure(counts, 10*time.Second, DefaultHotConfig())
+
+	if !heats[0].Hot || heats[0].OpsPerSecond != 1000 {
+		t.Errorf("shard 0 = %+v, want hot at 1000 ops/s", heats[0])
+	}
+	if heats[0].Tenants[0].TenantID != "big" {
+		t.Errorf("busiest tenant of shard 0 = %s, want big", heats[0].Tenants[0].TenantID)
+	}
+	for _, heat := range heats[1:] {
+		if heat.Hot {
+			t.Errorf("shard %d is hot at %.0f ops/s", heat.ShardID, heat.OpsPerSecond)
+		}
+	}
+
+	// Below the minimum rate no shard is hot, however uneven
+	quiet := measure([]map[string]int64{{"a": 10}, nil}, 10*time.Second, DefaultHotConfig())
+	if quiet[0].Hot {
+		t.Error("expected a quiet shard not to be hot")
+	}
+}
@@ -0,0 +1,169 @@
+package sharding
+
+import (
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// LabelPredicate names the predicate of the EvaluationLinks that label
+// atoms: has_label(subject, label)
+const LabelPredicate = "has_label"
+
+// ShardPlan is the plan a shard answered a query with
+type ShardPlan struct {
+	TenantID string `json:"tenant_id"`
+	ShardID  int    `json:"shard_id"`
+	Replica  bool   `json:"replica"` // Whether the shard's replica answered rather than its primary
+	atomspace.Plan
+}
+
+// FindAtoms answers a structured query across all shards, eventually
+// consistent
+func (sm *ShardManager) FindAtoms(tenantID string, q atomspace.Query) ([]atomspace.Atom, []ShardPlan) {
+	return sm.Reader(Consistency{}).FindAtoms(tenantID, q)
+}
+
+// FindAtoms answers a structured query across all shards, each from the
+// index its planner picks and each stopping at the limit. Labels are resolved to the IDs of the atoms
+// carrying them first, and IDs are sent only to the shards holding them.
+func (r Reader) FindAtoms(tenantID string, q atomspace.Query) ([]atomspace.Atom, []ShardPlan) {
+	sm := r.sm
+	sm.placementMu.RLock()
+	defer sm.placementMu.RUnlock()
+
+	shards := sm.snapshotShards()
+	if len(q.Labels) > 0 {
+		q.IDs = intersect(q.IDs, r.labelled(shards, tenantID, q.Labels))
+	}
+	var byShard map[int][]string
+	if q.IDs != nil {
+		byShard = make(map[int][]string)
+		seen := make(map[string]bool, len(q.IDs))
+		for _, atomID := range q.IDs {
+			if seen[atomID] {
+				continue
+			}
+			seen[atomID] = true
+			shardID := sm.locate(atomID, tenantID).ID
+			byShard[shardID] = append(byShard[shardID], atomID)
+		}
+	}
+
+	type shardResult struct {
+		atoms []atomspace.Atom
+		plan  ShardPlan
+	}
+	results := make([]shardResult, len(shards))
+	done := make(chan struct{}, len(shards))
+	for i, shard := range shards {
+		go func(i int, shard *Shard) {
+			shardQuery := q
+			if byShard != nil {
+				shardQuery.IDs = append([]string{}, byShard[shard.ID]...)
+			}
+			space, replica := r.space(shard)
+			atoms, plan := space.Find(tenantID, shardQuery)
+			results[i] = shardResult{atoms: atoms, plan: ShardPlan{TenantID: tenantID, ShardID: shard.ID, Replica: replica, Plan: plan}}
+			done <- struct{}{}
+		}(i, shard)
+	}
+	for range shards {
+		<-done
+	}
+
+	var atoms []atomspace.Atom
+	plans := make([]ShardPlan, 0, len(shards))
+	for _, result := range results {
+		atoms = append(atoms, result.atoms...)
+		plans = append(plans, result.plan)
+	}
+	if q.Limit > 0 && len(atoms) > q.Limit {
+		atoms = atoms[:q.Limit]
+	}
+	return atoms, plans
+}
+
+// Incoming returns a tenant's links referring to an atom, which may be on
+// any shard
+func (r Reader) Incoming(tenantID, atomID string) []atomspace.Atom {
+	sm := r.sm
+	sm.placementMu.RLock()
+	defer sm.placementMu.RUnlock()
+
+	var links []atomspace.Atom
+	for _, shard := range sm.snapshotShards() {
+		space, _ := r.space(shard)
+		links = append(links, space.Incoming(tenantID, atomID)...)
+	}
+	return links
+}
+
+// labelled returns the IDs of a tenant's atoms carrying every label. A
+// label is carried through has_label(subject, label) links, found from the
+// label atoms by the incoming index; links may be on any shard.
+func (r Reader) labelled(shards []*Shard, tenantID string, labels []string) []string {
+	var ids []string
+	for i, label := range labels {
+		subjects := make(map[string]bool)
+		for _, holder := range shards {
+			holderSpace, _ := r.space(holder)
+			labelAtoms, _ := holderSpace.Find(tenantID, atomspace.Query{Name: label})
+			for _, labelAtom := range labelAtoms {
+				if _, isLink := labelAtom.(*atomspace.Link); isLink {
+					continue
+				}
+				for _, shard := range shards {
+					space, _ := r.space(shard)
+					for _, atom := range space.Incoming(tenantID, labelAtom.GetID()) {
+						link := atom.(*atomspace.Link)
+						outgoing := link.GetOutgoing()
+						if link.GetType() == atomspace.EvaluationLinkType && len(outgoing) == 3 &&
+							outgoing[0].GetName() == LabelPredicate && outgoing[2].GetID() == labelAtom.GetID() {
+							subjects[outgoing[1].GetID()] = true
+						}
+					}
+				}
+			}
+		}
+
+		var next []string
+		if i == 0 {
+			for atomID := range subjects {
+				next = append(next, atomID)
+			}
+		} else {
+			for _, atomID := range ids {
+				if subjects[atomID] {
+					next = append(next, atomID)
+				}
+			}
+		}
+		ids = next
+		if len(ids) == 0 {
+			break
+		}
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+	return ids
+}
+
+// intersect returns the IDs in both lists, where a nil list allows any ID
+func intersect(a, b []string) []string {
+	if a == nil {
+		return b
+	}
+	allowed := make(map[string]bool, len(b))
+	for _, atomID := range b {
+		allowed[atomID] = true
+	}
+	result := []string{}
+	seen := make(map[string]bool)
+	for _, atomID := range a {
+		if allowed[atomID] && !seen[atomID] {
+			seen[atomID] = true
+			result = append(result, atomID)
+		}
+	}
+	return result
+}
@@ -0,0 +1,169 @@
+package sharding
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func TestFindAtoms(t *testing.T) {
+	sm := NewShardManager(3, 3)
+	defer sm.Close()
+
+	tenantID := "tenant"
+	node := func(atomType atomspace.AtomType, name string) atomspace.Atom {
+		n := atomspace.NewNode(atomspace.GenerateAtomID(atomType, name, nil), name, tenantID, atomType)
+		if err := sm.AddAtom(n); err != nil {
+			t.Fatalf("AddAtom failed: %v", err)
+		}
+		return n
+	}
+	label := func(subject, value atomspace.Atom, predicate atomspace.Atom) {
+		outgoing := []atomspace.Atom{predicate, subject, value}
+		link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, LabelPredicate, outgoing), LabelPredicate, tenantID, atomspace.EvaluationLinkType, outgoing)
+		if err := sm.AddAtom(link); err != nil {
+			t.Fatalf("AddAtom failed: %v", err)
+		}
+	}
+
+	hasLabel := node(atomspace.PredicateNodeType, LabelPredicate)
+	teamA := node(atomspace.ConceptNodeType, "team-a")
+	prod := node(atomspace.ConceptNodeType, "prod")
+	for i := 0; i < 30; i++ {
+		svc := node(atomspace.ConceptNodeType, fmt.Sprintf("svc-%d", i))
+		if i%3 == 0 {
+			label(svc, teamA, hasLabel)
+		}
+		if i%2 == 0 {
+			label(svc, prod, hasLabel)
+		}
+	}
+
+	// A name is answered from the name index
+	atoms, plans := sm.FindAtoms(tenantID, atomspace.Query{Name: "svc-7"})
+	if len(atoms) != 1 || atoms[0].GetName() != "svc-7" {
+		t.Errorf("name query = %d atoms, want svc-7", len(atoms))
+	}
+	for _, plan := range plans {
+		if plan.Index != atomspace.IndexName {
+			t.Errorf("shard %d used %s, want the name index", plan.ShardID, plan.Index)
+		}
+	}
+
+	// Labels resolve to IDs through has_label links on any shard
+	atoms, plans = sm.FindAtoms(tenantID, atomspace.Query{Labels: []string{"team-a", "prod"}})
+	if len(atoms) != 5 {
+		t.Errorf("label query = %d atoms, want the 5 services labelled both", len(atoms))
+	}
+	candidates := 0
+	for _, plan := range plans {
+		if plan.Index != atomspace.IndexIDs {
+			t.Errorf("shard %d used %s, want the resolved IDs", plan.ShardID, plan.Index)
+		}
+		candidates += plan.Candidates
+	}
+	if candidates != 5 {
+		t.Errorf("candidates = %d, want each ID read once", candidates)
+	}
+	if atoms, _ := sm.FindAtoms(tenantID, atomspace.Query{Labels: []string{"unknown"}}); len(atoms) != 0 {
+		t.Errorf("unknown label matched %d atoms", len(atoms))
+	}
+
+	// Truth value ranges filter whatever index is read
+	if err := sm.UpdateAtom(teamA.GetID(), tenantID, func(a atomspace.Atom) error {
+		a.SetTruthValue(atomspace.TruthValue{Strength: 0.2, Confidence: 0.5})
+		return nil
+	}); err != nil {
+		t.Fatalf("UpdateAtom failed: %v", err)
+	}
+	concept := atomspace.ConceptNodeType
+	atoms, plans = sm.FindAtoms(tenantID, atomspace.Query{Type: &concept, Strength: &atomspace.Range{Min: 0, Max: 0.5}})
+	if len(atoms) != 1 || atoms[0].GetID() != teamA.GetID() {
+		t.Errorf("strength query = %d atoms, want team-a", len(atoms))
+	}
+	for _, plan := range plans {
+		if len(plan.Filters) == 0 || plan.Filters[len(plan.Filters)-1] != "strength" {
+			t.Errorf("shard %d filters = %v, want strength checked", plan.ShardID, plan.Filters)
+		}
+	}
+
+	// Another tenant's atoms make the shared type index costlier than a scan
+	for i := 0; i < 100; i++ {
+		other := atomspace.NewNode(fmt.Sprintf("other/%d", i), fmt.Sprintf("n-%d", i), "other", atomspace.ConceptNodeType)
+		if err := sm.AddAtom(other); err != nil {
+			t.Fatalf("AddAtom failed: %v", err)
+		}
+	}
+	small := atomspace.NewNode("small/1", "n", "small", atomspace.NodeType)
+	if err := sm.AddAtom(small); err != nil {
+		t.Fatalf("AddAtom failed: %v", err)
+	}
+	atoms, plans = sm.FindAtoms("small", atomspace.Query{Type: &concept})
+	if len(atoms) != 0 {
+		t.Errorf("small tenant concepts = %d, want 0", len(atoms))
+	}
+	for _, plan := range plans {
+		if plan.Index != atomspace.IndexTenant {
+			t.Errorf("shard %d used %s, want a scan of the small tenant", plan.ShardID, plan.Index)
+		}
+	}
+}
+
+func TestFindAtomsParallelScan(t *testing.T) {
+	sm := NewShardManager(1, 1)
+	defer sm.Close()
+
+	addNodes(t, sm, "big", 40000)
+	if err := sm.UpdateAtom("big/node-123", "big", func(a atomspace.Atom) error {
+		a.SetTruthValue(atomspace.TruthValue{Strength: 0.1, Confidence: 1})
+		return nil
+	}); err != nil {
+		t.Fatalf("UpdateAtom failed: %v", err)
+	}
+
+	// Truth values have no index, so the tenant is scanned
+	weak := &atomspace.Range{Min: 0, Max: 0.5}
+	atoms, plans := sm.FindAtoms("big", atomspace.Query{Strength: weak})
+	if len(atoms) != 1 || atoms[0].GetID() != "big/node-123" {
+		t.Errorf("scan = %d atoms, want big/node-123", len(atoms))
+	}
+	if plans[0].Index != atomspace.IndexTenant || plans[0].Candidates != 40000 {
+		t.Errorf("plan = %+v, want a scan of 40000 atoms", plans[0].Plan)
+	}
+	if runtime.GOMAXPROCS(0) > 1 && plans[0].Workers < 2 {
+		t.Errorf("scan used %d workers, want it split", plans[0].Workers)
+	}
+
+	// Workers stop once the limit is reached
+	atoms, plans = sm.FindAtoms("big", atomspace.Query{Limit: 25})
+	if len(atoms) != 25 || plans[0].Results != 25 {
+		t.Errorf("limited scan = %d atoms (%d on the shard), want 25", len(atoms), plans[0].Results)
+	}
+
+	if got := len(sm.QueryAtoms("big", func(a atomspace.Atom) bool { return a.GetTruthValue().Strength < 0.5 })); got != 1 {
+		t.Errorf("filtered query = %d atoms, want 1", got)
+	}
+}
+
+// BenchmarkFindAtomsScan measures full scans of a 1M-atom tenant
+func BenchmarkFindAtomsScan(b *testing.B) {
+	const n = 1000000
+	sm := NewShardManager(1, 1)
+	defer sm.Close()
+
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("node-%d", i)
+		if err := sm.AddAtom(atomspace.NewNode("big/"+name, name, "big", atomspace.ConceptNodeType)); err != nil {
+			b.Fatalf("AddAtom failed: %v", err)
+		}
+	}
+	query := atomspace.Query{Strength: &atomspace.Range{Min: 0, Max: 0.5}}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sm.FindAtoms("big", query)
+	}
+	b.ReportMetric(float64(n)*float64(b.N)/b.Elapsed().Seconds(), "atoms/s")
+}
@@ -0,0 +1,219 @@
+package sharding
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// ReplicaConfig controls read replicas of shards
+type ReplicaConfig struct {
+	Enabled      bool
+	SyncInterval time.Duration // How often replicas that missed changes are rebuilt
+}
+
+// DefaultReplicaConfig returns the default replica configuration, with
+// replicas disabled
+func DefaultReplicaConfig() ReplicaConfig {
+	return ReplicaConfig{
+		SyncInterval: time.Second,
+	}
+}
+
+// replica is a read-only copy of a shard's atoms, kept current by applying
+// the engine's change events. Scans of the replica do not contend with
+// writes to the primary. It holds the primary's atoms themselves, so values
+// changed in place are seen at once; only the sets of atoms can lag.
+type replica struct {
+	space   *atomspace.AtomSpace
+	stale   atomic.Bool // Changes were missed; reads go to the primary until rebuilt
+	applied atomic.Int64
+	syncs   atomic.Int64
+	mu      sync.Mutex // Serializes changes with rebuilds
+}
+
+func newReplica(workers int) *replica {
+	r := &replica{space: atomspace.NewAtomSpace(workers)}
+	r.stale.Store(true)
+	return r
+}
+
+// put adds an atom or replaces the copy held for it. It fails if the
+// replica holds another tenant's atom of the same ID, which the primary
+// would not.
+func (r *replica) put(atom atomspace.Atom) bool {
+	atomID, tenantID := atom.GetID(), atom.GetTenantID()
+	if current, err := r.space.GetAtom(atomID, tenantID); err == nil {
+		if current == atom {
+			// Changed in place; only the generation needs to move
+			r.space.ForceUpdateAtom(atomID, tenantID, func(atomspace.Atom) error { return nil })
+			return true
+		}
+		r.space.ForceDeleteAtom(atomID, tenantID)
+	}
+	return r.space.AddAtom(atom) == nil
+}
+
+// EnableReplicas gives every shard a read replica. Queries then read
+// replicas that are current, and the primary of any that missed changes.
+// Replicas start empty and are built by the next sync.
+func (sm *ShardManager) EnableReplicas(config ReplicaConfig) {
+	if config.SyncInterval <= 0 {
+		config.SyncInterval = DefaultReplicaConfig().SyncInterval
+	}
+	if !sm.replicated.CompareAndSwap(false, true) {
+		return
+	}
+
+	for _, shard := range sm.snapshotShards() {
+		shard.replica.CompareAndSwap(nil, newReplica(sm.shardWorkers))
+	}
+	sm.syncReplicas()
+	go sm.replicaMonitor(config.SyncInterval)
+}
+
+// Replicate applies an added or updated atom to the replica of its shard
+func (sm *ShardManager) Replicate(atom atomspace.Atom) {
+	sm.placementMu.RLock()
+	defer sm.placementMu.RUnlock()
+
+	r := sm.locate(atom.GetID(), atom.GetTenantID()).replica.Load()
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stale.Load() {
+		return
+	}
+	if !r.put(atom) {
+		r.stale.Store(true)
+		return
+	}
+	r.applied.Add(1)
+}
+
+// ReplicateDelete applies a deleted atom to the replicas holding it
+func (sm *ShardManager) ReplicateDelete(atomID, tenantID string) {
+	sm.placementMu.RLock()
+	defer sm.placementMu.RUnlock()
+
+	for _, shard := range sm.snapshotShards() {
+		r := shard.replica.Load()
+		if r == nil {
+			continue
+		}
+		r.mu.Lock()
+		if _, err := r.space.GetAtom(atomID, tenantID); err == nil {
+			r.space.ForceDeleteAtom(atomID, tenantID)
+			r.applied.Add(1)
+		}
+		r.mu.Unlock()
+	}
+}
+
+// MarkReplicasStale sends reads to the primaries until the replicas are
+// rebuilt, after changes they were not told about
+func (sm *ShardManager) MarkReplicasStale() {
+	for _, shard := range sm.snapshotShards() {
+		if r := shard.replica.Load(); r != nil {
+			r.stale.Store(true)
+		}
+	}
+}
+
+// replicaMonitor rebuilds stale replicas every interval
+func (sm *ShardManager) replicaMonitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sm.syncReplicas()
+		case <-sm.done:
+			return
+		}
+	}
+}
+
+// syncReplicas rebuilds the stale replicas from their primaries. No atoms
+// move between shards meanwhile; changes arriving wait for the rebuild.
+func (sm *ShardManager) syncReplicas() {
+	if sm.migrating.Load() {
+		return
+	}
+
+	sm.placementMu.RLock()
+	defer sm.placementMu.RUnlock()
+
+	for _, shard := range sm.snapshotShards() {
+		r := shard.replica.Load()
+		if r == nil || !r.stale.Load() {
+			continue
+		}
+		r.mu.Lock()
+		primary := shard.AtomSpace.AllAtoms()
+		held := make(map[string]bool, len(primary))
+		for _, atom := range primary {
+			held[atom.GetTenantID()+"/"+atom.GetID()] = true
+		}
+		for _, atom := range r.space.AllAtoms() {
+			if !held[atom.GetTenantID()+"/"+atom.GetID()] {
+				r.space.ForceDeleteAtom(atom.GetID(), atom.GetTenantID())
+			}
+		}
+		synced := true
+		for _, atom := range primary {
+			if current, err := r.space.GetAtom(atom.GetID(), atom.GetTenantID()); err != nil || current != atom {
+				synced = r.put(atom) && synced
+			}
+		}
+		r.stale.Store(!synced)
+		r.syncs.Add(1)
+		r.mu.Unlock()
+	}
+}
+
+// reader returns the atom space queries of a shard read: its replica if
+// it is current, else the primary
+func (s *Shard) reader() *atomspace.AtomSpace {
+	if r := s.replica.Load(); r != nil && !r.stale.Load() {
+		return r.space
+	}
+	return s.AtomSpace
+}
+
+// replicaGeneration returns the changes applied to a shard's replica
+func (s *Shard) replicaGeneration(tenantID string) uint64 {
+	if r := s.replica.Load(); r != nil {
+		return r.space.Generation(tenantID)
+	}
+	return 0
+}
+
+// ReplicaStats returns the state of each shard's replica, or nil if
+// replicas are disabled
+func (sm *ShardManager) ReplicaStats() []map[string]interface{} {
+	if !sm.replicated.Load() {
+		return nil
+	}
+
+	var stats []map[string]interface{}
+	for _, shard := range sm.snapshotShards() {
+		r := shard.replica.Load()
+		if r == nil {
+			continue
+		}
+		stats = append(stats, map[string]interface{}{
+			"shard_id": shard.ID,
+			"atoms":    len(r.space.AllAtoms()),
+			"stale":    r.stale.Load(),
+			"applied":  r.applied.Load(),
+			"syncs":    r.syncs.Load(),
+		})
+	}
+	return stats
+}
@@ -0,0 +1,66 @@
+package sharding
+
+import (
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func TestReplicas(t *testing.T) {
+	sm := NewShardManager(2, 2)
+	defer sm.Close()
+
+	addNodes(t, sm, "tenant", 20)
+	sm.EnableReplicas(DefaultReplicaConfig())
+
+	// The first sync builds every replica from its primary
+	for _, shard := range sm.snapshotShards() {
+		if shard.reader() == shard.AtomSpace {
+			t.Fatalf("shard %d reads its primary after the first sync", shard.ID)
+		}
+	}
+	if got := len(sm.QueryAtoms("tenant", nil)); got != 20 {
+		t.Errorf("query after sync = %d atoms, want 20", got)
+	}
+
+	// Queries see new atoms once their change is replicated
+	atom := atomspace.NewNode("tenant/late", "late", "tenant", atomspace.ConceptNodeType)
+	if err := sm.AddAtom(atom); err != nil {
+		t.Fatalf("AddAtom failed: %v", err)
+	}
+	if got := len(sm.QueryAtoms("tenant", nil)); got != 20 {
+		t.Errorf("query before replicating = %d atoms, want 20", got)
+	}
+	generation := sm.Generation("tenant")
+	sm.Replicate(atom)
+	if got := len(sm.QueryAtoms("tenant", nil)); got != 21 {
+		t.Errorf("query after replicating = %d atoms, want 21", got)
+	}
+	if sm.Generation("tenant") == generation {
+		t.Error("expected replicating to change the generation")
+	}
+
+	if err := sm.DeleteAtom("tenant/late", "tenant"); err != nil {
+		t.Fatalf("DeleteAtom failed: %v", err)
+	}
+	sm.ReplicateDelete("tenant/late", "tenant")
+	if got := len(sm.QueryAtoms("tenant", nil)); got != 20 {
+		t.Errorf("query after replicating a delete = %d atoms, want 20", got)
+	}
+
+	// Stale replicas send reads to the primary until rebuilt
+	addNodes(t, sm, "other", 5)
+	sm.MarkReplicasStale()
+	if got := len(sm.QueryAtoms("other", nil)); got != 5 {
+		t.Errorf("query of stale replicas = %d atoms, want the primaries' 5", got)
+	}
+	sm.syncReplicas()
+	for _, stats := range sm.ReplicaStats() {
+		if stats["stale"].(bool) {
+			t.Errorf("replica %v stale after sync", stats["shard_id"])
+		}
+	}
+	if got := len(sm.QueryAtoms("other", nil)); got != 5 {
+		t.Errorf("query after rebuilding = %d atoms, want 5", got)
+	}
+}
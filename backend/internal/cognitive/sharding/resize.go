@@ -0,0 +1,129 @@
+package sharding
+
+import (
+	"fmt"
+	"time"
+)
+
+// MigrationStatus is the progress of the last change of the shard count
+type MigrationStatus struct {
+	Active      bool      `json:"active"`
+	FromShards  int       `json:"from_shards"`
+	ToShards    int       `json:"to_shards"`
+	Moved       int       `json:"moved"`
+	Retired     int       `json:"retired"` // Dropped shards still holding atoms that could not move
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at,omitempty"`
+}
+
+// NumShards returns the number of shards atoms are routed to
+func (sm *ShardManager) NumShards() int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.numShards
+}
+
+// Resize changes the number of shards atoms are hashed across. Added shards
+// take writes at once; existing atoms then move to the shards they now hash
+// to in the background, in batches, while reads and writes continue and
+// find atoms on either shard. Dropped shards are closed once emptied.
+// Tenants pinned to a dropped shard must be repinned first.
+func (sm *ShardManager) Resize(numShards int) error {
+	if numShards < 1 {
+		return fmt.Errorf("invalid shard count: %d", numShards)
+	}
+	if !sm.layoutMu.TryLock() {
+		return ErrMigrating
+	}
+
+	sm.placementMu.Lock()
+	current := sm.routes.Load()
+	for tenantID, p := range current.placements {
+		for _, shardID := range p.Shards {
+			if shardID >= numShards {
+				sm.placementMu.Unlock()
+				sm.layoutMu.Unlock()
+				return fmt.Errorf("tenant %s is pinned to shard %d", tenantID, shardID)
+			}
+		}
+	}
+	next := current.with(Placement{}, numShards)
+	if len(next.shared) == 0 {
+		sm.placementMu.Unlock()
+		sm.layoutMu.Unlock()
+		return fmt.Errorf("%d shards would all be dedicated, leaving none for other tenants", numShards)
+	}
+
+	sm.mu.Lock()
+	from := sm.numShards
+	for i := len(sm.shards); i < numShards; i++ {
+		sm.shards = append(sm.shards, sm.newShard(i))
+	}
+	sm.numShards = numShards
+	sm.mu.Unlock()
+	sm.beginMigration()
+	sm.routes.Store(next)
+	sm.placementMu.Unlock()
+
+	sm.migrationMu.Lock()
+	sm.migration = MigrationStatus{
+		Active:     true,
+		FromShards: from,
+		ToShards:   numShards,
+		StartedAt:  time.Now(),
+	}
+	sm.migrationMu.Unlock()
+
+	go func() {
+		defer sm.layoutMu.Unlock()
+		sm.migrate()
+		select {
+		case <-sm.done:
+			return
+		default:
+		}
+		retired := sm.dropRetired()
+
+		sm.migrationMu.Lock()
+		defer sm.migrationMu.Unlock()
+		sm.migration.Active = false
+		sm.migration.Retired = retired
+		sm.migration.CompletedAt = time.Now()
+	}()
+	return nil
+}
+
+// Migration returns the progress of the last change of the shard count
+func (sm *ShardManager) Migration() MigrationStatus {
+	sm.migrationMu.Lock()
+	defer sm.migrationMu.Unlock()
+	return sm.migration
+}
+
+// dropRetired closes the shards beyond the routed count, from the last, as
+// long as they are empty, and returns how many retired shards remain
+func (sm *ShardManager) dropRetired() int {
+	sm.placementMu.Lock()
+	defer sm.placementMu.Unlock()
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for len(sm.shards) > sm.numShards {
+		last := sm.shards[len(sm.shards)-1]
+		if len(last.AtomSpace.AllAtoms()) > 0 {
+			break
+		}
+		for tenantID, generation := range last.AtomSpace.Generations() {
+			sm.retiredGenerations[tenantID] += generation
+		}
+		last.AtomSpace.Close()
+		if r := last.replica.Load(); r != nil {
+			for tenantID, generation := range r.space.Generations() {
+				sm.retiredGenerations[tenantID] += generation
+			}
+			r.space.Close()
+		}
+		sm.shards = sm.shards[:len(sm.shards)-1]
+	}
+	return len(sm.shards) - sm.numShards
+}
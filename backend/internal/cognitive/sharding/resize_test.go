@@ -0,0 +1,106 @@
+package sharding
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// awaitMigration waits for the last resize to finish moving atoms
+func awaitMigration(t *testing.T, sm *ShardManager) MigrationStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if status := sm.Migration(); !status.Active {
+			return status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("migration did not finish")
+	return MigrationStatus{}
+}
+
+func TestResize(t *testing.T) {
+	sm := NewShardManager(2, 2)
+	defer sm.Close()
+
+	addNodes(t, sm, "tenant", 1000)
+
+	if err := sm.Resize(5); err != nil {
+		t.Fatalf("Resize failed: %v", err)
+	}
+
+	// Reads and writes continue while atoms move
+	var wg sync.WaitGroup
+	errs := make(chan error, 1000)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			if _, err := sm.GetAtom(fmt.Sprintf("tenant/node-%d", i), "tenant"); err != nil {
+				errs <- err
+			}
+		}
+	}()
+	addNodes(t, sm, "late", 100)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("GetAtom during migration: %v", err)
+	}
+
+	status := awaitMigration(t, sm)
+	if status.FromShards != 2 || status.ToShards != 5 || status.Moved == 0 {
+		t.Errorf("migration = %+v, want atoms moved from 2 to 5 shards", status)
+	}
+	if got := distribution(sm, "tenant"); len(got) != 5 {
+		t.Errorf("atoms by shard after growing = %v, want all 5 shards used", got)
+	}
+
+	generation := sm.Generation("tenant")
+	if err := sm.Resize(2); err != nil {
+		t.Fatalf("Resize failed: %v", err)
+	}
+	awaitMigration(t, sm)
+	if sm.Generation("tenant") <= generation {
+		t.Errorf("generation went from %d to %d, want it to grow as atoms move", generation, sm.Generation("tenant"))
+	}
+	if n := len(sm.snapshotShards()); n != 2 {
+		t.Errorf("shards after shrinking = %d, want 2", n)
+	}
+	if got := distribution(sm, "tenant"); got[0]+got[1] != 1000 {
+		t.Errorf("atoms by shard after shrinking = %v, want 1000", got)
+	}
+	for _, tenantID := range []string{"tenant", "late"} {
+		if _, err := sm.GetAtom(tenantID+"/node-42", tenantID); err != nil {
+			t.Errorf("GetAtom(%s) after shrinking: %v", tenantID, err)
+		}
+	}
+
+	var total int64
+	for _, shard := range sm.snapshotShards() {
+		total += shard.Load
+	}
+	if total != 1100 {
+		t.Errorf("total load = %d, want 1100", total)
+	}
+}
+
+func TestResizeValidation(t *testing.T) {
+	sm := NewShardManager(4, 4)
+	defer sm.Close()
+
+	if err := sm.Resize(0); err == nil {
+		t.Error("expected resizing to no shards to fail")
+	}
+	if _, err := sm.PinTenant(Placement{TenantID: "big", Shards: []int{3}}); err != nil {
+		t.Fatalf("PinTenant failed: %v", err)
+	}
+	if err := sm.Resize(3); err == nil {
+		t.Error("expected dropping a shard a tenant is pinned to to fail")
+	}
+	if sm.NumShards() != 4 {
+		t.Errorf("shards = %d, want 4 after a refused resize", sm.NumShards())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"hash/fnv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
@@ -15,13 +16,16 @@ type Shard struct {
 	AtomSpace *atomspace.AtomSpace
 	Load      int64 // Current number of atoms in this shard
 	LastUsed  time.Time
+	ops       counter // Operations routed here since the last heat sample
+	replica   atomic.Pointer[replica] // Nil unless read replicas are enabled
 	mu        sync.RWMutex
 }
 
 // ShardManager manages dynamic sharding of atoms across multiple AtomSpaces
 type ShardManager struct {
-	shards       []*Shard
-	numShards    int
+	shards       []*Shard // Routed shards first, then retired ones still being emptied
+	numShards    int      // Shards atoms are routed to
+	shardWorkers int
 	rebalanceThreshold int64 // Rebalance when difference exceeds this
 	mu           sync.RWMutex
 	
@@ -29,6 +33,25 @@ type ShardManager struct {
 	routeChan    chan routeRequest
 	rebalanceChan chan struct{}
 	done         chan struct{}
+	
+	// Tenant placement: atoms are hashed across the shards a tenant is
+	// pinned to, or across the shards not dedicated to any tenant
+	routes       atomic.Pointer[routingTable]
+	placementMu  sync.RWMutex // Held exclusively while a batch of atoms moves between shards
+	layoutMu     sync.Mutex   // Held while placements or the shard count change
+	strays       atomic.Int64 // Atoms that could not be moved to their routed shard
+	migrating    atomic.Bool  // Whether atoms are moving to the shards they route to
+	migration    MigrationStatus
+	migrationMu  sync.Mutex
+	retiredGenerations map[string]uint64 // Changes counted by dropped shards, so generations never go back
+	replicated   atomic.Bool // Whether shards have read replicas
+	replicaLag   atomic.Pointer[func() time.Duration] // How far replicas are behind, for bounded reads
+	
+	// Hot shard detection
+	hotConfig    HotConfig
+	metrics      Metrics
+	heat         []ShardHeat // Last sample
+	heatMu       sync.Mutex
 }
 
 type routeRequest struct {
@@ -42,20 +65,20 @@ func NewShardManager(numShards int, workers int) *ShardManager {
 	sm := &ShardManager{
 		shards:             make([]*Shard, numShards),
 		numShards:          numShards,
+		shardWorkers:       workers / numShards,
 		rebalanceThreshold: 1000,
+		retiredGenerations: make(map[string]uint64),
 		routeChan:          make(chan routeRequest, 1000),
 		rebalanceChan:      make(chan struct{}, 1),
 		done:               make(chan struct{}),
+		hotConfig:          DefaultHotConfig(),
+		metrics:            noMetrics{},
 	}
+	sm.routes.Store(newRoutingTable(numShards))
 	
 	// Initialize shards
 	for i := 0; i < numShards; i++ {
-		sm.shards[i] = &Shard{
-			ID:        i,
-			AtomSpace: atomspace.NewAtomSpace(workers / numShards),
-			Load:      0,
-			LastUsed:  time.Now(),
-		}
+		sm.shards[i] = sm.newShard(i)
 	}
 	
 	// Start router workers
@@ -66,6 +89,9 @@ func NewShardManager(numShards int, workers int) *ShardManager {
 	// Start rebalancing monitor
 	go sm.rebalanceMonitor()
 	
+	// Start hot shard detection
+	go sm.heatMonitor()
+	
 	return sm
 }
 
@@ -117,7 +143,7 @@ func (sm *ShardManager) getShardIDInternal(atomID, tenantID string) int {
 	h := fnv.New64a()
 	h.Write([]byte(tenantID + ":" + atomID))
 	hash := h.Sum64()
-	return int(hash % uint64(sm.numShards))
+	return sm.routes.Load().route(tenantID, hash)
 }
 
 // GetShard returns the shard for a given atom
@@ -128,12 +154,27 @@ func (sm *ShardManager) GetShard(atomID, tenantID string) *Shard {
 	return sm.shards[shardID]
 }
 
-// GetShardByID returns a shard by its ID
+// newShard creates an empty shard
+func (sm *ShardManager) newShard(id int) *Shard {
+	shard := &Shard{
+		ID:        id,
+		AtomSpace: atomspace.NewAtomSpace(sm.shardWorkers),
+		Load:      0,
+		LastUsed:  time.Now(),
+	}
+	if sm.replicated.Load() {
+		shard.replica.Store(newReplica(sm.shardWorkers))
+	}
+	return shard
+}
+
+// GetShardByID returns a shard by its ID, including retired shards that
+// are still being emptied
 func (sm *ShardManager) GetShardByID(shardID int) (*Shard, error) {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 	
-	if shardID < 0 || shardID >= sm.numShards {
+	if shardID < 0 || shardID >= len(sm.shards) {
 		return nil, fmt.Errorf("invalid shard ID: %d", shardID)
 	}
 	
@@ -142,7 +183,14 @@ func (sm *ShardManager) GetShardByID(shardID int) (*Shard, error) {
 
 // AddAtom adds an atom to the appropriate shard
 func (sm *ShardManager) AddAtom(atom atomspace.Atom) error {
+	sm.placementMu.RLock()
+	defer sm.placementMu.RUnlock()
+	
 	shard := sm.GetShard(atom.GetID(), atom.GetTenantID())
+	shard.ops.add(atom.GetTenantID())
+	if holder, found := sm.find(atom.GetID(), atom.GetTenantID()); found && holder != shard {
+		return fmt.Errorf("atom with ID %s already exists", atom.GetID())
+	}
 	
 	shard.mu.Lock()
 	defer shard.mu.Unlock()
@@ -156,14 +204,87 @@ func (sm *ShardManager) AddAtom(atom atomspace.Atom) error {
 	return err
 }
 
+// AddAtoms adds a batch of atoms, each shard's share in one call, with the
+// shards loaded in parallel. Atoms already held are skipped, as are atoms
+// whose ID another tenant holds; the added atoms are returned.
+func (sm *ShardManager) AddAtoms(atoms []atomspace.Atom) []atomspace.Atom {
+	sm.placementMu.RLock()
+	defer sm.placementMu.RUnlock()
+	
+	shards := sm.snapshotShards()
+	batches := make([][]atomspace.Atom, len(shards))
+	for _, atom := range atoms {
+		// Routed directly, not through the router workers
+		shard := shards[sm.getShardIDInternal(atom.GetID(), atom.GetTenantID())]
+		if holder, found := sm.find(atom.GetID(), atom.GetTenantID()); found && holder != shard {
+			continue
+		}
+		batches[shard.ID] = append(batches[shard.ID], atom)
+	}
+	
+	added := make([][]atomspace.Atom, len(shards))
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		if len(batch) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(shard *Shard, batch []atomspace.Atom) {
+			defer wg.Done()
+			shard.mu.Lock()
+			defer shard.mu.Unlock()
+			
+			added[shard.ID] = shard.AtomSpace.AddAtoms(batch)
+			shard.Load += int64(len(added[shard.ID]))
+			shard.LastUsed = time.Now()
+		}(shards[i], batch)
+	}
+	wg.Wait()
+	
+	var result []atomspace.Atom
+	for _, shardAdded := range added {
+		result = append(result, shardAdded...)
+	}
+	return result
+}
+
 // GetAtom retrieves an atom from the appropriate shard
 func (sm *ShardManager) GetAtom(atomID, tenantID string) (atomspace.Atom, error) {
-	shard := sm.GetShard(atomID, tenantID)
+	sm.placementMu.RLock()
+	defer sm.placementMu.RUnlock()
+	
+	shard := sm.locate(atomID, tenantID)
+	shard.ops.add(tenantID)
 	return shard.AtomSpace.GetAtom(atomID, tenantID)
 }
 
-// QueryAtoms queries atoms across all shards for a tenant
+// AllAtoms returns the atoms of every tenant on every shard
+func (sm *ShardManager) AllAtoms() []atomspace.Atom {
+	var atoms []atomspace.Atom
+	for _, shard := range sm.snapshotShards() {
+		atoms = append(atoms, shard.AtomSpace.AllAtoms()...)
+	}
+	return atoms
+}
+
+// QueryAtoms queries atoms across all shards for a tenant, eventually
+// consistent
 func (sm *ShardManager) QueryAtoms(tenantID string, filter func(atomspace.Atom) bool) []atomspace.Atom {
+	return sm.Reader(Consistency{}).QueryAtoms(tenantID, filter)
+}
+
+// SearchAtoms searches atom names of a tenant across all shards, eventually
+// consistent
+func (sm *ShardManager) SearchAtoms(tenantID, query string, mode atomspace.SearchMode, limit int) []atomspace.SearchResult {
+	return sm.Reader(Consistency{}).SearchAtoms(tenantID, query, mode, limit)
+}
+
+// QueryAtoms queries atoms across all shards for a tenant
+func (r Reader) QueryAtoms(tenantID string, filter func(atomspace.Atom) bool) []atomspace.Atom {
+	sm := r.sm
+	sm.placementMu.RLock()
+	defer sm.placementMu.RUnlock()
+	
 	sm.mu.RLock()
 	numShards := len(sm.shards)
 	sm.mu.RUnlock()
@@ -178,7 +299,8 @@ func (sm *ShardManager) QueryAtoms(tenantID string, filter func(atomspace.Atom)
 	for i := 0; i < numShards; i++ {
 		go func(shardID int) {
 			shard, _ := sm.GetShardByID(shardID)
-			atoms := shard.AtomSpace.QueryAtoms(tenantID, filter)
+			space, _ := r.space(shard)
+			atoms := space.QueryAtoms(tenantID, filter)
 			resultChan <- shardResult{atoms: atoms}
 		}(i)
 	}
@@ -193,20 +315,118 @@ func (sm *ShardManager) QueryAtoms(tenantID string, filter func(atomspace.Atom)
 	return allAtoms
 }
 
-// UpdateAtom updates an atom in the appropriate shard
+// SearchAtoms searches atom names of a tenant across all shards, returning
+// the best-scored results first
+func (r Reader) SearchAtoms(tenantID, query string, mode atomspace.SearchMode, limit int) []atomspace.SearchResult {
+	sm := r.sm
+	sm.placementMu.RLock()
+	defer sm.placementMu.RUnlock()
+	
+	sm.mu.RLock()
+	numShards := len(sm.shards)
+	sm.mu.RUnlock()
+	
+	resultChan := make(chan []atomspace.SearchResult, numShards)
+	
+	for i := 0; i < numShards; i++ {
+		go func(shardID int) {
+			shard, _ := sm.GetShardByID(shardID)
+			space, _ := r.space(shard)
+			resultChan <- space.SearchAtoms(tenantID, query, mode, limit)
+		}(i)
+	}
+	
+	var results []atomspace.SearchResult
+	for i := 0; i < numShards; i++ {
+		results = append(results, <-resultChan...)
+	}
+	
+	atomspace.SortSearchResults(results)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	
+	return results
+}
+
+// UpdateAtom updates an atom in the appropriate shard. The updater may call
+// back into the shard manager, so no placement lock is held while it runs;
+// an atom missed because it was moving is looked up again once it moved.
 func (sm *ShardManager) UpdateAtom(atomID, tenantID string, updater func(atomspace.Atom) error) error {
-	shard := sm.GetShard(atomID, tenantID)
-	return shard.AtomSpace.UpdateAtom(atomID, tenantID, updater)
+	return sm.update(atomID, tenantID, updater, false)
+}
+
+// ForceUpdateAtom updates an atom whether or not it is protected
+func (sm *ShardManager) ForceUpdateAtom(atomID, tenantID string, updater func(atomspace.Atom) error) error {
+	return sm.update(atomID, tenantID, updater, true)
+}
+
+// SetProtected protects an atom or lifts its protection
+func (sm *ShardManager) SetProtected(atomID, tenantID string, protected bool) error {
+	return sm.ForceUpdateAtom(atomID, tenantID, func(atom atomspace.Atom) error {
+		p, ok := atom.(atomspace.Protectable)
+		if !ok {
+			return fmt.Errorf("atom %s cannot be protected", atomID)
+		}
+		p.SetProtected(protected)
+		return nil
+	})
+}
+
+func (sm *ShardManager) update(atomID, tenantID string, updater func(atomspace.Atom) error, force bool) error {
+	sm.placementMu.RLock()
+	shard := sm.locate(atomID, tenantID)
+	sm.placementMu.RUnlock()
+	shard.ops.add(tenantID)
+	
+	apply := func(space *atomspace.AtomSpace, updater func(atomspace.Atom) error) error {
+		if force {
+			return space.ForceUpdateAtom(atomID, tenantID, updater)
+		}
+		return space.UpdateAtom(atomID, tenantID, updater)
+	}
+	called := false
+	err := apply(shard.AtomSpace, func(atom atomspace.Atom) error {
+		called = true
+		return updater(atom)
+	})
+	if err != nil && !called {
+		sm.placementMu.RLock()
+		moved := sm.locate(atomID, tenantID)
+		sm.placementMu.RUnlock()
+		if moved != shard {
+			return apply(moved.AtomSpace, updater)
+		}
+	}
+	return err
 }
 
 // DeleteAtom deletes an atom from the appropriate shard
 func (sm *ShardManager) DeleteAtom(atomID, tenantID string) error {
-	shard := sm.GetShard(atomID, tenantID)
+	return sm.delete(atomID, tenantID, false)
+}
+
+// ForceDeleteAtom deletes an atom whether or not it is protected
+func (sm *ShardManager) ForceDeleteAtom(atomID, tenantID string) error {
+	return sm.delete(atomID, tenantID, true)
+}
+
+func (sm *ShardManager) delete(atomID, tenantID string, force bool) error {
+	sm.placementMu.RLock()
+	defer sm.placementMu.RUnlock()
+	
+	shard := sm.locate(atomID, tenantID)
+	shard.ops.add(tenantID)
 	
 	shard.mu.Lock()
 	defer shard.mu.Unlock()
 	
-	err := shard.AtomSpace.DeleteAtom(atomID, tenantID)
+	var err error
+	if force {
+		err = shard.AtomSpace.ForceDeleteAtom(atomID, tenantID)
+	} else {
+		err = shard.AtomSpace.DeleteAtom(atomID, tenantID)
+	}
 	if err == nil {
 		shard.Load--
 	}
@@ -214,6 +434,32 @@ func (sm *ShardManager) DeleteAtom(atomID, tenantID string) error {
 	return err
 }
 
+// PurgeTenant removes all atoms of a tenant from every shard and returns the
+// number of atoms removed
+func (sm *ShardManager) PurgeTenant(tenantID string) int {
+	sm.placementMu.RLock()
+	defer sm.placementMu.RUnlock()
+	
+	sm.mu.RLock()
+	shards := sm.shards
+	sm.mu.RUnlock()
+	
+	removed := 0
+	for _, shard := range shards {
+		shard.mu.Lock()
+		n := shard.AtomSpace.PurgeTenant(tenantID)
+		shard.Load -= int64(n)
+		shard.mu.Unlock()
+		if r := shard.replica.Load(); r != nil {
+			r.mu.Lock()
+			r.space.PurgeTenant(tenantID)
+			r.mu.Unlock()
+		}
+		removed += n
+	}
+	return removed
+}
+
 // needsRebalance checks if shards need rebalancing
 func (sm *ShardManager) needsRebalance() bool {
 	sm.mu.RLock()
@@ -289,6 +535,8 @@ func (sm *ShardManager) rebalance() {
 
 // GetShardStats returns statistics for all shards
 func (sm *ShardManager) GetShardStats() map[string]interface{} {
+	heats := sm.HotShards()
+	
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 	
@@ -302,9 +550,19 @@ func (sm *ShardManager) GetShardStats() map[string]interface{} {
 		shard.mu.RUnlock()
 		
 		shardStats[i] = map[string]interface{}{
-			"shard_id":  shard.ID,
-			"load":      load,
-			"last_used": lastUsed,
+			"shard_id":       shard.ID,
+			"load":           load,
+			"last_used":      lastUsed,
+		}
+		if i < len(heats) {
+			shardStats[i]["ops_per_second"] = heats[i].OpsPerSecond
+			shardStats[i]["hot"] = heats[i].Hot
+			if heats[i].DedicatedTo != "" {
+				shardStats[i]["dedicated_to"] = heats[i].DedicatedTo
+			}
+		}
+		if i >= sm.numShards {
+			shardStats[i]["retired"] = true
 		}
 		totalLoad += load
 	}
@@ -319,6 +577,10 @@ func (sm *ShardManager) GetShardStats() map[string]interface{} {
 		"total_load":   totalLoad,
 		"average_load": avgLoad,
 		"shards":       shardStats,
+		"placements":   len(sm.ListPlacements()),
+		"strays":       sm.strays.Load(),
+		"migration":    sm.Migration(),
+		"replicas":     sm.ReplicaStats(),
 	}
 }
 
@@ -326,12 +588,33 @@ func (sm *ShardManager) GetShardStats() map[string]interface{} {
 func (sm *ShardManager) Close() {
 	close(sm.done)
 	
+	// Wait for a migration to stop between batches
+	sm.layoutMu.Lock()
+	defer sm.layoutMu.Unlock()
+	
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 	
 	for _, shard := range sm.shards {
 		shard.AtomSpace.Close()
+		if r := shard.replica.Load(); r != nil {
+			r.space.Close()
+		}
+	}
+}
+
+// Generation returns the number of changes made to a tenant's atoms across
+// all shards, and applied to their replicas, so that it changes whenever
+// what queries read does
+func (sm *ShardManager) Generation(tenantID string) uint64 {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	
+	generation := sm.retiredGenerations[tenantID]
+	for _, shard := range sm.shards {
+		generation += shard.AtomSpace.Generation(tenantID) + shard.replicaGeneration(tenantID)
 	}
+	return generation
 }
 
 // GetTenantStats returns statistics for a specific tenant across all shards
@@ -0,0 +1,162 @@
+package cognitive
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/orgs"
+)
+
+// sharedTenantPrefix namespaces the pseudo-tenants that hold shared spaces
+const sharedTenantPrefix = "shared:"
+
+// SharedSpace is a read-only ontology space that tenants can mount. Its atoms
+// are stored in the shards under the pseudo-tenant SharedTenantID(ID).
+type SharedSpace struct {
+	ID          string
+	Name        string
+	Description string
+	CreatedAt   time.Time
+}
+
+// SharedTenantID returns the pseudo-tenant ID holding a shared space's atoms
+func SharedTenantID(spaceID string) string {
+	return sharedTenantPrefix + spaceID
+}
+
+// IsSharedTenantID reports whether a tenant ID addresses a shared space
+func IsSharedTenantID(tenantID string) bool {
+	return strings.HasPrefix(tenantID, sharedTenantPrefix)
+}
+
+// CreateSharedSpace registers a new shared ontology space
+func (ce *CognitiveEngine) CreateSharedSpace(spaceID, name, description string) (*SharedSpace, error) {
+	if spaceID == "" {
+		return nil, fmt.Errorf("shared space ID is required")
+	}
+
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if _, exists := ce.sharedSpaces[spaceID]; exists {
+		return nil, fmt.Errorf("shared space %s already exists", spaceID)
+	}
+
+	space := &SharedSpace{
+		ID:          spaceID,
+		Name:        name,
+		Description: description,
+		CreatedAt:   time.Now(),
+	}
+	ce.sharedSpaces[spaceID] = space
+
+	return space, nil
+}
+
+// GetSharedSpaces returns all shared spaces
+func (ce *CognitiveEngine) GetSharedSpaces() []*SharedSpace {
+	ce.mu.RLock()
+	defer ce.mu.RUnlock()
+
+	spaces := make([]*SharedSpace, 0, len(ce.sharedSpaces))
+	for _, space := range ce.sharedSpaces {
+		spaces = append(spaces, space)
+	}
+	sort.Slice(spaces, func(i, j int) bool { return spaces[i].ID < spaces[j].ID })
+	return spaces
+}
+
+// MountSharedSpace makes a shared space visible (read-only) to a tenant
+func (ce *CognitiveEngine) MountSharedSpace(tenantID, spaceID string) error {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if _, exists := ce.sharedSpaces[spaceID]; !exists {
+		return fmt.Errorf("shared space %s not found", spaceID)
+	}
+	for _, mounted := range ce.mounts[tenantID] {
+		if mounted == spaceID {
+			return fmt.Errorf("shared space %s already mounted by tenant %s", spaceID, tenantID)
+		}
+	}
+
+	ce.mounts[tenantID] = append(ce.mounts[tenantID], spaceID)
+	ce.orgs.Own(tenantID, orgs.SettingMount, spaceID)
+	return nil
+}
+
+// UnmountSharedSpace detaches a shared space from a tenant
+func (ce *CognitiveEngine) UnmountSharedSpace(tenantID, spaceID string) error {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	mounted := ce.mounts[tenantID]
+	for i, id := range mounted {
+		if id == spaceID {
+			ce.mounts[tenantID] = append(mounted[:i:i], mounted[i+1:]...)
+			ce.orgs.Own(tenantID, orgs.SettingMount, spaceID)
+			return nil
+		}
+	}
+	return fmt.Errorf("shared space %s not mounted by tenant %s", spaceID, tenantID)
+}
+
+// GetMounts returns the shared spaces mounted by a tenant
+func (ce *CognitiveEngine) GetMounts(tenantID string) []string {
+	ce.mu.RLock()
+	defer ce.mu.RUnlock()
+
+	return append([]string(nil), ce.mounts[tenantID]...)
+}
+
+// mountedTenantIDs returns the pseudo-tenant IDs of a tenant's mounts
+func (ce *CognitiveEngine) mountedTenantIDs(tenantID string) []string {
+	ce.mu.RLock()
+	defer ce.mu.RUnlock()
+
+	mounted := ce.mounts[tenantID]
+	ids := make([]string, len(mounted))
+	for i, spaceID := range mounted {
+		ids[i] = SharedTenantID(spaceID)
+	}
+	return ids
+}
+
+// findMountedAtom looks an atom up in the tenant's mounted shared spaces
+func (ce *CognitiveEngine) findMountedAtom(atomID, tenantID string) (atomspace.Atom, bool) {
+	for _, sharedID := range ce.mountedTenantIDs(tenantID) {
+		if atom, err := ce.shardManager.GetAtom(atomID, sharedID); err == nil {
+			return atom, true
+		}
+	}
+	return nil, false
+}
+
+// CreateSharedConceptNode creates a concept node inside a shared space
+func (ce *CognitiveEngine) CreateSharedConceptNode(spaceID, name string) (atomspace.Atom, error) {
+	if err := ce.requireSharedSpace(spaceID); err != nil {
+		return nil, err
+	}
+	return ce.CreateConceptNode(name, SharedTenantID(spaceID))
+}
+
+// CreateSharedInheritanceLink creates an inheritance link inside a shared space
+func (ce *CognitiveEngine) CreateSharedInheritanceLink(spaceID, sourceID, targetID string) (atomspace.Atom, error) {
+	if err := ce.requireSharedSpace(spaceID); err != nil {
+		return nil, err
+	}
+	return ce.CreateInheritanceLink(sourceID, targetID, SharedTenantID(spaceID))
+}
+
+func (ce *CognitiveEngine) requireSharedSpace(spaceID string) error {
+	ce.mu.RLock()
+	defer ce.mu.RUnlock()
+
+	if _, exists := ce.sharedSpaces[spaceID]; !exists {
+		return fmt.Errorf("shared space %s not found", spaceID)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package cognitive
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sharding"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/shares"
+)
+
+// CreateShare mints a read-only share link to a tenant's saved query or
+// to the subgraph around some of its atoms, living ttl or the configured
+// default. The token returned lets anyone view the current atoms in scope
+// until the link expires or is revoked.
+func (ce *CognitiveEngine) CreateShare(tenantID string, s shares.Share, ttl time.Duration, p acl.Principal) (shares.Share, string, error) {
+	ce.mu.RLock()
+	_, exists := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+	if !exists {
+		return shares.Share{}, "", fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+	if s.Scope.Query != "" {
+		if _, err := ce.savedQueries.Get(tenantID, s.Scope.Query); err != nil {
+			return shares.Share{}, "", err
+		}
+	}
+	for _, id := range s.Scope.Roots {
+		if _, err := ce.GetAtom(id, tenantID); err != nil {
+			return shares.Share{}, "", err
+		}
+	}
+	s.CreatedBy = p.User
+	return ce.shares.Create(tenantID, s, ttl)
+}
+
+// GetShare returns a live share link of a tenant
+func (ce *CognitiveEngine) GetShare(tenantID, id string) (shares.Share, error) {
+	return ce.shares.Get(tenantID, id)
+}
+
+// ListShares returns a tenant's live share links
+func (ce *CognitiveEngine) ListShares(tenantID string) []shares.Share {
+	return ce.shares.List(tenantID)
+}
+
+// RevokeShare removes a share link of a tenant before it expires
+func (ce *CognitiveEngine) RevokeShare(tenantID, id string) error {
+	return ce.shares.Revoke(tenantID, id)
+}
+
+// SharedView is what a share link shows
+type SharedView struct {
+	Share     shares.Share
+	Atoms     []atomspace.Atom
+	Truncated bool // More than shares.MaxAtoms atoms are in scope
+}
+
+// ViewShare resolves a share token to the atoms now in its scope: those
+// the saved query returns, or those within its depth of the roots. It
+// fails with shares.ErrInvalid for tokens malformed, not signed by this
+// engine, expired or revoked.
+func (ce *CognitiveEngine) ViewShare(token string) (*SharedView, error) {
+	s, err := ce.shares.Resolve(token)
+	if err != nil {
+		return nil, err
+	}
+	view := &SharedView{Share: s}
+	reader := ce.Reader(sharding.Consistency{})
+	if s.Scope.Query != "" {
+		atoms, _, err := reader.RunSavedQuery(s.TenantID, s.Scope.Query)
+		if err != nil {
+			return nil, err
+		}
+		if len(atoms) > shares.MaxAtoms {
+			atoms, view.Truncated = atoms[:shares.MaxAtoms], true
+		}
+		view.Atoms = atoms
+		return view, nil
+	}
+	view.Atoms, view.Truncated = shares.Subgraph(shareGraph{reader: reader, tenantID: s.TenantID}, s.Scope.Roots, s.Scope.Depth, shares.MaxAtoms)
+	return view, nil
+}
+
+// shareGraph walks out from the roots of a share link through the incoming
+// links of the atoms reached, rather than reading the whole tenant
+type shareGraph struct {
+	reader   AtomReader
+	tenantID string
+}
+
+func (g shareGraph) GetAtoms(ids []string) []atomspace.Atom {
+	atoms, _ := g.reader.FindAtoms(g.tenantID, atomspace.Query{IDs: ids})
+	return atoms
+}
+
+func (g shareGraph) Incoming(atomID string) []atomspace.Atom {
+	return g.reader.Incoming(g.tenantID, atomID)
+}
@@ -0,0 +1,367 @@
+package shares
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// MaxShares is the number of live share links a tenant may hold
+const MaxShares = 1000
+
+// MaxDepth bounds the hops of links a subgraph share follows
+const MaxDepth = 5
+
+// MaxAtoms bounds the atoms a share link shows
+const MaxAtoms = 5000
+
+// ErrInvalid is returned for share tokens that are malformed, not signed by
+// this engine, expired or revoked, without telling which
+var ErrInvalid = errors.New("share link invalid, expired or revoked")
+
+// Config configures share links
+type Config struct {
+	Secret     []byte        // Signs share tokens; random per process if empty
+	DefaultTTL time.Duration // Lifetime of a share link created without one
+	MaxTTL     time.Duration // Longest lifetime of a share link
+}
+
+// DefaultConfig returns share links living a day by default and 30 days
+// at most
+func DefaultConfig() Config {
+	return Config{DefaultTTL: 24 * time.Hour, MaxTTL: 30 * 24 * time.Hour}
+}
+
+// Scope is what a share link shows: the atoms of a saved query, or the
+// subgraph around root atoms
+type Scope struct {
+	Query string   `json:"query,omitempty"` // Name of the saved query
+	Roots []string `json:"roots,omitempty"` // IDs of the atoms the subgraph is around
+	Depth int      `json:"depth,omitempty"` // Hops of links followed from the roots; 1 if 0
+}
+
+// Validate checks a scope
+func (s *Scope) Validate() error {
+	if (s.Query == "") == (len(s.Roots) == 0) {
+		return fmt.Errorf("a share needs either a query or roots")
+	}
+	if s.Depth < 0 || s.Depth > MaxDepth {
+		return fmt.Errorf("depth must be between 0 and %d", MaxDepth)
+	}
+	if s.Query != "" && s.Depth != 0 {
+		return fmt.Errorf("depth only applies to roots")
+	}
+	return nil
+}
+
+// Share is a read-only view of a tenant's atoms reachable without an
+// account through a signed token until it expires or is revoked
+type Share struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	Description string    `json:"description,omitempty"`
+	Scope       Scope     `json:"scope"`
+	CreatedBy   string    `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Views       int64     `json:"views"`
+	LastViewed  time.Time `json:"last_viewed,omitempty"`
+}
+
+func (s *Share) clone() Share {
+	c := *s
+	c.Scope.Roots = append([]string(nil), s.Scope.Roots...)
+	return c
+}
+
+// claims are what a token carries, signed
+type claims struct {
+	TenantID  string `json:"t"`
+	ID        string `json:"s"`
+	ExpiresAt int64  `json:"e"`
+}
+
+// Manager mints and resolves share links
+type Manager struct {
+	config  Config
+	secret  []byte
+	shares  map[string]map[string]*Share // tenantID -> ID -> share
+	revoked int64
+	mu      sync.Mutex
+}
+
+// NewManager creates a manager without share links. Without a configured
+// secret tokens do not survive a restart.
+func NewManager(config Config) *Manager {
+	defaults := DefaultConfig()
+	if config.DefaultTTL <= 0 {
+		config.DefaultTTL = defaults.DefaultTTL
+	}
+	if config.MaxTTL <= 0 {
+		config.MaxTTL = defaults.MaxTTL
+	}
+	secret := config.Secret
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(fmt.Sprintf("failed to generate share secret: %v", err))
+		}
+	}
+	return &Manager{config: config, secret: secret, shares: make(map[string]map[string]*Share)}
+}
+
+// sign returns the token of a share
+func (m *Manager) sign(s *Share) string {
+	payload, _ := json.Marshal(claims{TenantID: s.TenantID, ID: s.ID, ExpiresAt: s.ExpiresAt.Unix()})
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Create mints a share link for a tenant living ttl, or the default
+// lifetime if 0, and returns it with its token. The token is only
+// returned here.
+func (m *Manager) Create(tenantID string, s Share, ttl time.Duration) (Share, string, error) {
+	if err := s.Scope.Validate(); err != nil {
+		return Share{}, "", err
+	}
+	if ttl < 0 || ttl > m.config.MaxTTL {
+		return Share{}, "", fmt.Errorf("ttl must be between 0 and %s", m.config.MaxTTL)
+	}
+	if ttl == 0 {
+		ttl = m.config.DefaultTTL
+	}
+	raw := make([]byte, 8)
+	if _, err := rand.Read(raw); err != nil {
+		return Share{}, "", err
+	}
+
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneLocked(tenantID, now)
+	if len(m.shares[tenantID]) >= MaxShares {
+		return Share{}, "", fmt.Errorf("tenant %s has %d share links", tenantID, MaxShares)
+	}
+	if m.shares[tenantID] == nil {
+		m.shares[tenantID] = make(map[string]*Share)
+	}
+	s.ID = "share-" + hex.EncodeToString(raw)
+	s.TenantID = tenantID
+	s.CreatedAt = now
+	s.ExpiresAt = now.Add(ttl).Truncate(time.Second)
+	s.Views, s.LastViewed = 0, time.Time{}
+	stored := s.clone()
+	m.shares[tenantID][s.ID] = &stored
+	return stored.clone(), m.sign(&stored), nil
+}
+
+// Resolve returns the share link a token was minted for and counts the
+// view
+func (m *Manager) Resolve(token string) (Share, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return Share{}, ErrInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Share{}, ErrInvalid
+	}
+	sum, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return Share{}, ErrInvalid
+	}
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write(payload)
+	if !hmac.Equal(sum, mac.Sum(nil)) {
+		return Share{}, ErrInvalid
+	}
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return Share{}, ErrInvalid
+	}
+
+	now := time.Now()
+	if now.Unix() >= c.ExpiresAt {
+		return Share{}, ErrInvalid
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, exists := m.shares[c.TenantID][c.ID]
+	if !exists || !now.Before(s.ExpiresAt) {
+		return Share{}, ErrInvalid
+	}
+	s.Views++
+	s.LastViewed = now
+	return s.clone(), nil
+}
+
+// Get returns a live share link of a tenant
+func (m *Manager) Get(tenantID, id string) (Share, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, exists := m.shares[tenantID][id]
+	if !exists || !time.Now().Before(s.ExpiresAt) {
+		return Share{}, fmt.Errorf("share %s not found", id)
+	}
+	return s.clone(), nil
+}
+
+// List returns a tenant's live share links, the newest first
+func (m *Manager) List(tenantID string) []Share {
+	m.mu.Lock()
+	m.pruneLocked(tenantID, time.Now())
+	list := make([]Share, 0, len(m.shares[tenantID]))
+	for _, s := range m.shares[tenantID] {
+		list = append(list, s.clone())
+	}
+	m.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.After(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// Revoke removes a share link of a tenant, so its token no longer resolves
+func (m *Manager) Revoke(tenantID, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.shares[tenantID][id]; !exists {
+		return fmt.Errorf("share %s not found", id)
+	}
+	delete(m.shares[tenantID], id)
+	if len(m.shares[tenantID]) == 0 {
+		delete(m.shares, tenantID)
+	}
+	m.revoked++
+	return nil
+}
+
+// Purge removes a tenant's share links, returning how many
+func (m *Manager) Purge(tenantID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	removed := len(m.shares[tenantID])
+	delete(m.shares, tenantID)
+	return removed
+}
+
+// pruneLocked drops a tenant's expired share links
+func (m *Manager) pruneLocked(tenantID string, now time.Time) {
+	for id, s := range m.shares[tenantID] {
+		if !now.Before(s.ExpiresAt) {
+			delete(m.shares[tenantID], id)
+		}
+	}
+	if len(m.shares[tenantID]) == 0 {
+		delete(m.shares, tenantID)
+	}
+}
+
+// GetStats returns the number of share links, their views and revocations
+func (m *Manager) GetStats() map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	shares := 0
+	var views int64
+	for _, tenant := range m.shares {
+		for _, s := range tenant {
+			shares++
+			views += s.Views
+		}
+	}
+	return map[string]interface{}{
+		"shares":  shares,
+		"views":   views,
+		"revoked": m.revoked,
+	}
+}
+
+// Graph is what a subgraph is walked over: the atoms a tenant sees by ID,
+// and the links referring to one of them
+type Graph interface {
+	GetAtoms(ids []string) []atomspace.Atom
+	Incoming(atomID string) []atomspace.Atom
+}
+
+// Subgraph returns the atoms within depth hops of links from the roots:
+// each hop adds the links referring to an atom reached and the atoms they
+// refer to. The walk stops once limit atoms are reached, nearest first and
+// the roots always among them; the atoms are returned sorted by ID, with
+// whether more were in reach.
+func Subgraph(g Graph, roots []string, depth, limit int) ([]atomspace.Atom, bool) {
+	if depth <= 0 {
+		depth = 1
+	}
+	reached := make(map[string]atomspace.Atom)
+	truncated := false
+	add := func(atom atomspace.Atom) bool {
+		if reached[atom.GetID()] != nil {
+			return false
+		}
+		if limit > 0 && len(reached) >= limit {
+			truncated = true
+			return false
+		}
+		reached[atom.GetID()] = atom
+		return true
+	}
+
+	var frontier []string
+	if len(roots) > 0 {
+		for _, atom := range g.GetAtoms(roots) {
+			if add(atom) {
+				frontier = append(frontier, atom.GetID())
+			}
+		}
+	}
+	for hop := 0; hop < depth && len(frontier) > 0 && !truncated; hop++ {
+		var next, targets []string
+		for _, id := range frontier {
+			for _, atom := range g.Incoming(id) {
+				link, ok := atom.(*atomspace.Link)
+				if !ok {
+					continue
+				}
+				if add(link) {
+					next = append(next, link.GetID())
+				}
+				for _, target := range link.GetOutgoing() {
+					if reached[target.GetID()] == nil {
+						targets = append(targets, target.GetID())
+					}
+				}
+			}
+		}
+		if len(targets) > 0 && !truncated {
+			for _, atom := range g.GetAtoms(targets) {
+				if add(atom) {
+					next = append(next, atom.GetID())
+				}
+			}
+		}
+		frontier = next
+	}
+
+	result := make([]atomspace.Atom, 0, len(reached))
+	for _, atom := range reached {
+		result = append(result, atom)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].GetID() < result[j].GetID() })
+	return result, truncated
+}
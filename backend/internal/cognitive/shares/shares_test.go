@@ -0,0 +1,133 @@
+package shares
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func TestCreateAndResolve(t *testing.T) {
+	m := NewManager(Config{Secret: []byte("secret")})
+	for _, scope := range []Scope{
+		{},
+		{Query: "q", Roots: []string{"a"}},
+		{Query: "q", Depth: 2},
+		{Roots: []string{"a"}, Depth: MaxDepth + 1},
+	} {
+		if _, _, err := m.Create("t", Share{Scope: scope}, 0); err == nil {
+			t.Errorf("expected %+v rejected", scope)
+		}
+	}
+	if _, _, err := m.Create("t", Share{Scope: Scope{Query: "q"}}, 365*24*time.Hour); err == nil {
+		t.Error("expected a ttl over the maximum rejected")
+	}
+
+	s, token, err := m.Create("t", Share{Scope: Scope{Query: "q"}, CreatedBy: "alice"}, time.Hour)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if s.ExpiresAt.Before(time.Now().Add(59*time.Minute)) || s.CreatedBy != "alice" {
+		t.Errorf("unexpected share %+v", s)
+	}
+	resolved, err := m.Resolve(token)
+	if err != nil || resolved.ID != s.ID || resolved.Views != 1 {
+		t.Fatalf("expected the share resolved, got %+v (%v)", resolved, err)
+	}
+
+	// Tampered tokens and tokens of another secret do not resolve
+	payload, signature, _ := strings.Cut(token, ".")
+	for _, bad := range []string{"", "x", payload, payload + ".AAAA", "e30." + signature} {
+		if _, err := m.Resolve(bad); err != ErrInvalid {
+			t.Errorf("expected %q invalid, got %v", bad, err)
+		}
+	}
+	if _, err := NewManager(Config{Secret: []byte("other")}).Resolve(token); err != ErrInvalid {
+		t.Errorf("expected a token of another secret invalid, got %v", err)
+	}
+
+	if err := m.Revoke("t", s.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := m.Resolve(token); err != ErrInvalid {
+		t.Errorf("expected a revoked share invalid, got %v", err)
+	}
+	if stats := m.GetStats(); stats["shares"] != 0 || stats["revoked"] != int64(1) {
+		t.Errorf("unexpected stats %v", stats)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	m := NewManager(Config{})
+	s, token, err := m.Create("t", Share{Scope: Scope{Roots: []string{"a"}}}, time.Hour)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	m.mu.Lock()
+	m.shares["t"][s.ID].ExpiresAt = time.Now().Add(-time.Second)
+	m.mu.Unlock()
+	if _, err := m.Resolve(token); err != ErrInvalid {
+		t.Errorf("expected an expired share invalid, got %v", err)
+	}
+	if list := m.List("t"); len(list) != 0 {
+		t.Errorf("expected expired shares pruned, got %+v", list)
+	}
+}
+
+func TestSubgraph(t *testing.T) {
+	concept := func(name string) atomspace.Atom {
+		return atomspace.NewNode(name, name, "t", atomspace.ConceptNodeType)
+	}
+	link := func(id string, outgoing ...atomspace.Atom) atomspace.Atom {
+		return atomspace.NewLink(id, id, "t", atomspace.LinkType, outgoing)
+	}
+	payment, db, disk, web := concept("payment"), concept("db"), concept("disk"), concept("web")
+	uses := link("uses", payment, db)
+	stores := link("stores", db, disk)
+	calls := link("calls", web, payment)
+	risk := link("risk", uses)
+	space := atomspace.NewAtomSpace(1)
+	for _, atom := range []atomspace.Atom{payment, db, disk, web, uses, stores, calls, risk, concept("other")} {
+		if err := space.AddAtom(atom); err != nil {
+			t.Fatalf("AddAtom failed: %v", err)
+		}
+	}
+	g := spaceGraph{space}
+
+	ids := func(atoms []atomspace.Atom) string {
+		names := make([]string, len(atoms))
+		for i, atom := range atoms {
+			names[i] = atom.GetID()
+		}
+		return strings.Join(names, ",")
+	}
+	if got, _ := Subgraph(g, []string{"payment"}, 1, 0); ids(got) != "calls,db,payment,uses,web" {
+		t.Errorf("unexpected subgraph at depth 1: %s", ids(got))
+	}
+	got, truncated := Subgraph(g, []string{"payment", "missing"}, 2, 0)
+	if ids(got) != "calls,db,disk,payment,risk,stores,uses,web" || truncated {
+		t.Errorf("unexpected subgraph at depth 2: %s", ids(got))
+	}
+	if got, truncated := Subgraph(g, []string{"payment"}, 2, 3); len(got) != 3 || !truncated {
+		t.Errorf("expected the subgraph truncated, got %s", ids(got))
+	}
+	// Roots are kept however they sort
+	if got, truncated := Subgraph(g, []string{"web"}, 2, 2); ids(got) != "calls,web" || !truncated {
+		t.Errorf("expected the root kept in a truncated subgraph, got %s", ids(got))
+	}
+}
+
+// spaceGraph walks the atoms of tenant t in an AtomSpace
+type spaceGraph struct {
+	space *atomspace.AtomSpace
+}
+
+func (g spaceGraph) GetAtoms(ids []string) []atomspace.Atom {
+	atoms, _ := g.space.Find("t", atomspace.Query{IDs: ids})
+	return atoms
+}
+
+func (g spaceGraph) Incoming(atomID string) []atomspace.Atom {
+	return g.space.Incoming("t", atomID)
+}
@@ -0,0 +1,133 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/slo"
+)
+
+// SetSLO creates or replaces an SLO, enabling SLO tracking for the tenant
+// with the default configuration if needed
+func (ce *CognitiveEngine) SetSLO(tenantID string, objective slo.SLO) (slo.SLO, error) {
+	objective, err := ce.sloRegistry.Set(tenantID, objective)
+	if err != nil {
+		return slo.SLO{}, err
+	}
+
+	ce.mu.RLock()
+	_, tracking := ce.sloAgents[tenantID]
+	ce.mu.RUnlock()
+	if !tracking {
+		ce.EnableSLOTracking(tenantID, agents.DefaultSLOConfig())
+	}
+	return objective, nil
+}
+
+// GetSLO returns an SLO
+func (ce *CognitiveEngine) GetSLO(tenantID, name string) (slo.SLO, error) {
+	return ce.sloRegistry.Get(tenantID, name)
+}
+
+// ListSLOs returns a tenant's SLOs
+func (ce *CognitiveEngine) ListSLOs(tenantID string) []slo.SLO {
+	return ce.sloRegistry.List(tenantID)
+}
+
+// DeleteSLO removes an SLO. Atoms already written for it are kept.
+func (ce *CognitiveEngine) DeleteSLO(tenantID, name string) error {
+	return ce.sloRegistry.Delete(tenantID, name)
+}
+
+// GetSLOStatus evaluates one SLO against its current samples
+func (ce *CognitiveEngine) GetSLOStatus(tenantID, name string) (slo.Status, error) {
+	objective, err := ce.sloRegistry.Get(tenantID, name)
+	if err != nil {
+		return slo.Status{}, err
+	}
+	return slo.Evaluate(objective, ce.timeSeries.Samples(tenantID, objective.Series), time.Now()), nil
+}
+
+// GetErrorBudget returns the lowest remaining error budget among a
+// service's SLOs. Services without SLOs have their full budget.
+func (ce *CognitiveEngine) GetErrorBudget(tenantID, service string) float64 {
+	remaining := 1.0
+	now := time.Now()
+	for _, objective := range ce.sloRegistry.List(tenantID) {
+		if objective.Service != service {
+			continue
+		}
+		status := slo.Evaluate(objective, ce.timeSeries.Samples(tenantID, objective.Series), now)
+		if status.BudgetRemaining < remaining {
+			remaining = status.BudgetRemaining
+		}
+	}
+	return remaining
+}
+
+// EnableSLOTracking registers an SLO agent for a tenant, or updates the
+// configuration of the existing one
+func (ce *CognitiveEngine) EnableSLOTracking(tenantID string, config agents.SLOConfig) *agents.SLOAgent {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if agent, exists := ce.sloAgents[tenantID]; exists {
+		agent.SetConfig(config)
+		return agent
+	}
+
+	agent := agents.NewSLOAgent(
+		fmt.Sprintf("slo-%s", tenantID),
+		"SLOAgent",
+		tenantID,
+		&tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID},
+		ce.sloRegistry,
+		ce.timeSeries,
+		config,
+	)
+	ce.sloAgents[tenantID] = agent
+	ce.agentScheduler.RegisterAgent(agent)
+	return agent
+}
+
+// DisableSLOTracking unregisters a tenant's SLO agent. SLO definitions and
+// atoms already written are kept.
+func (ce *CognitiveEngine) DisableSLOTracking(tenantID string) error {
+	ce.mu.Lock()
+	agent, exists := ce.sloAgents[tenantID]
+	delete(ce.sloAgents, tenantID)
+	ce.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("slo tracking not enabled for tenant %s", tenantID)
+	}
+	ce.agentScheduler.UnregisterAgent(agent.GetID())
+	return nil
+}
+
+// EvaluateSLOs computes a tenant's error budgets immediately, enabling SLO
+// tracking with the default configuration if needed
+func (ce *CognitiveEngine) EvaluateSLOs(ctx context.Context, tenantID string) ([]slo.Status, error) {
+	ce.mu.RLock()
+	agent, exists := ce.sloAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		agent = ce.EnableSLOTracking(tenantID, agents.DefaultSLOConfig())
+	}
+	return agent.Evaluate(ctx)
+}
+
+// GetSLOStatuses returns the statuses of a tenant's latest SLO run
+func (ce *CognitiveEngine) GetSLOStatuses(tenantID string) []slo.Status {
+	ce.mu.RLock()
+	agent, exists := ce.sloAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		return []slo.Status{}
+	}
+	return agent.GetStatuses()
+}
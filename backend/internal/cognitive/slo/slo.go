@@ -0,0 +1,204 @@
+package slo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/forecast"
+)
+
+// SLO is a service level objective over a time series of SLI samples, each
+// the share of good events in [0, 1] during its interval
+type SLO struct {
+	Name       string        `json:"name"`
+	Service    string        `json:"service"` // Concept name of the service, e.g. "service/payments"
+	Series     string        `json:"series"`  // Time series holding the SLI samples
+	Target     float64       `json:"target"`  // e.g. 0.999
+	Window     time.Duration `json:"window_ns"`
+	BurnWindow time.Duration `json:"burn_window_ns"` // Recent window the burn rate is measured over
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// Validate checks an SLO and fills in default windows
+func (s *SLO) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("slo name is required")
+	}
+	if s.Service == "" || s.Series == "" {
+		return fmt.Errorf("slo %s requires a service and a series", s.Name)
+	}
+	if s.Target <= 0 || s.Target >= 1 {
+		return fmt.Errorf("slo target must be between 0 and 1 exclusive")
+	}
+	if s.Window <= 0 {
+		s.Window = 30 * 24 * time.Hour
+	}
+	if s.BurnWindow <= 0 {
+		s.BurnWindow = time.Hour
+	}
+	if s.BurnWindow > s.Window {
+		return fmt.Errorf("burn window must not exceed the slo window")
+	}
+	return nil
+}
+
+// Status is the state of an SLO's error budget
+type Status struct {
+	SLO             string    `json:"slo"`
+	Service         string    `json:"service"`
+	Samples         int       `json:"samples"`
+	SLI             float64   `json:"sli"`              // Mean SLI over the window
+	BudgetConsumed  float64   `json:"budget_consumed"`  // Share of the error budget spent, may exceed 1
+	BudgetRemaining float64   `json:"budget_remaining"` // Share of the error budget left, in [0, 1]
+	BurnRate        float64   `json:"burn_rate"`        // Budget spend speed over the burn window, 1 spends it exactly over the window
+	ExhaustedAt     time.Time `json:"exhausted_at,omitempty"`
+	EvaluatedAt     time.Time `json:"evaluated_at"`
+}
+
+// Evaluate computes the error budget of an SLO from its SLI samples
+func Evaluate(s SLO, samples []forecast.Sample, now time.Time) Status {
+	status := Status{
+		SLO:             s.Name,
+		Service:         s.Service,
+		SLI:             1,
+		BudgetRemaining: 1,
+		EvaluatedAt:     now,
+	}
+	budget := 1 - s.Target
+
+	var sum, burnSum float64
+	var burnCount int
+	for _, sample := range samples {
+		age := now.Sub(sample.Time)
+		if age < 0 || age >= s.Window {
+			continue
+		}
+		value := math.Max(0, math.Min(1, sample.Value))
+		sum += value
+		status.Samples++
+		if age < s.BurnWindow {
+			burnSum += value
+			burnCount++
+		}
+	}
+	if status.Samples == 0 {
+		return status
+	}
+
+	status.SLI = sum / float64(status.Samples)
+	status.BudgetConsumed = (1 - status.SLI) / budget
+	status.BudgetRemaining = math.Max(0, 1-status.BudgetConsumed)
+	if burnCount > 0 {
+		status.BurnRate = (1 - burnSum/float64(burnCount)) / budget
+	}
+
+	// At the current burn rate the remaining budget lasts this long
+	if status.BudgetRemaining > 0 && status.BurnRate > 0 {
+		left := status.BudgetRemaining / status.BurnRate * float64(s.Window)
+		status.ExhaustedAt = now.Add(time.Duration(left))
+	} else if status.BudgetRemaining == 0 {
+		status.ExhaustedAt = now
+	}
+	return status
+}
+
+// Registry holds the SLOs of each tenant
+type Registry struct {
+	slos map[string]map[string]SLO // tenantID -> name -> SLO
+	mu   sync.RWMutex
+}
+
+// NewRegistry creates an empty SLO registry
+func NewRegistry() *Registry {
+	return &Registry{
+		slos: make(map[string]map[string]SLO),
+	}
+}
+
+// Set creates or replaces an SLO
+func (r *Registry) Set(tenantID string, s SLO) (SLO, error) {
+	if err := s.Validate(); err != nil {
+		return SLO{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tenant, exists := r.slos[tenantID]
+	if !exists {
+		tenant = make(map[string]SLO)
+		r.slos[tenantID] = tenant
+	}
+	if previous, exists := tenant[s.Name]; exists {
+		s.CreatedAt = previous.CreatedAt
+	} else {
+		s.CreatedAt = time.Now()
+	}
+	tenant[s.Name] = s
+	return s, nil
+}
+
+// Get returns an SLO
+func (r *Registry) Get(tenantID, name string) (SLO, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, exists := r.slos[tenantID][name]
+	if !exists {
+		return SLO{}, fmt.Errorf("slo %s not found", name)
+	}
+	return s, nil
+}
+
+// List returns a tenant's SLOs sorted by name
+func (r *Registry) List(tenantID string) []SLO {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]SLO, 0, len(r.slos[tenantID]))
+	for _, s := range r.slos[tenantID] {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Delete removes an SLO
+func (r *Registry) Delete(tenantID, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.slos[tenantID][name]; !exists {
+		return fmt.Errorf("slo %s not found", name)
+	}
+	delete(r.slos[tenantID], name)
+	return nil
+}
+
+// Purge deletes all SLOs of a tenant and returns how many were removed
+func (r *Registry) Purge(tenantID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := len(r.slos[tenantID])
+	delete(r.slos, tenantID)
+	return purged
+}
+
+// GetStats returns registry statistics
+func (r *Registry) GetStats() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	total := 0
+	for _, tenant := range r.slos {
+		total += len(tenant)
+	}
+	return map[string]interface{}{
+		"tenants": len(r.slos),
+		"slos":    total,
+	}
+}
@@ -0,0 +1,68 @@
+package slo
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/forecast"
+)
+
+func TestEvaluateErrorBudget(t *testing.T) {
+	now := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	s := SLO{Name: "availability", Service: "service/api", Series: "sli", Target: 0.99, Window: 10 * time.Hour, BurnWindow: 2 * time.Hour}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	// Ten hourly samples, the last two at 98% while the rest are perfect
+	var samples []forecast.Sample
+	for i := 0; i < 10; i++ {
+		value := 1.0
+		if i >= 8 {
+			value = 0.98
+		}
+		samples = append(samples, forecast.Sample{Time: now.Add(time.Duration(i-9) * time.Hour), Value: value})
+	}
+	// Outside the window
+	samples = append(samples, forecast.Sample{Time: now.Add(-24 * time.Hour), Value: 0})
+
+	status := Evaluate(s, samples, now)
+	if status.Samples != 10 {
+		t.Fatalf("Expected 10 samples in the window, got %d", status.Samples)
+	}
+	if math.Abs(status.BudgetConsumed-0.4) > 1e-9 || math.Abs(status.BudgetRemaining-0.6) > 1e-9 {
+		t.Errorf("Expected 40%% of the budget consumed, got %+v", status)
+	}
+	if math.Abs(status.BurnRate-2) > 1e-9 {
+		t.Errorf("Expected a burn rate of 2, got %f", status.BurnRate)
+	}
+	if want := now.Add(3 * time.Hour); !status.ExhaustedAt.Equal(want) {
+		t.Errorf("Expected exhaustion at %v, got %v", want, status.ExhaustedAt)
+	}
+
+	empty := Evaluate(s, nil, now)
+	if empty.BudgetRemaining != 1 || !empty.ExhaustedAt.IsZero() {
+		t.Errorf("Expected a full budget without samples, got %+v", empty)
+	}
+}
+
+func TestRegistryValidation(t *testing.T) {
+	r := NewRegistry()
+	if _, err := r.Set("t1", SLO{Name: "x", Service: "s", Series: "sli", Target: 1}); err == nil {
+		t.Error("Expected a target of 1 to be rejected")
+	}
+	s, err := r.Set("t1", SLO{Name: "x", Service: "s", Series: "sli", Target: 0.999})
+	if err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if s.Window != 30*24*time.Hour || s.BurnWindow != time.Hour {
+		t.Errorf("Expected default windows, got %v and %v", s.Window, s.BurnWindow)
+	}
+	if len(r.List("t2")) != 0 {
+		t.Error("Expected SLOs to be isolated per tenant")
+	}
+	if n := r.Purge("t1"); n != 1 || len(r.List("t1")) != 0 {
+		t.Errorf("Expected 1 purged SLO, got %d", n)
+	}
+}
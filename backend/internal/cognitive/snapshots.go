@@ -0,0 +1,169 @@
+package cognitive
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// periodicSnapshots writes a snapshot of each tenant to the snapshot
+// directory every interval, replacing the last one, so that the value logs
+// only need to hold the values that changed since. Each tenant's values
+// older than its snapshot, less its retention, are pruned from the logs.
+type periodicSnapshots struct {
+	dir      string
+	interval time.Duration
+	last     time.Time                 // When the last round of snapshots began
+	tenants  map[string]SnapshotStatus // tenantID -> last snapshot written
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+}
+
+// SnapshotStatus describes the last periodic snapshot of a tenant
+type SnapshotStatus struct {
+	TakenAt      time.Time `json:"taken_at"`
+	SizeBytes    int64     `json:"size_bytes"`
+	PrunedValues int       `json:"pruned_values"` // Atoms' values dropped from the value logs as the snapshot holds them
+	Error        string    `json:"error,omitempty"`
+}
+
+func snapshotPath(dir, tenantID string) string {
+	return filepath.Join(dir, url.PathEscape(tenantID)+".snap")
+}
+
+// runSnapshots snapshots every tenant each interval until the engine
+// closes. It starts once the shards are hydrated, so that a snapshot never
+// replaces one that was not restored yet.
+func (ce *CognitiveEngine) runSnapshots() {
+	defer ce.snapshots.wg.Done()
+
+	ticker := time.NewTicker(ce.snapshots.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ce.done:
+			return
+		case <-ticker.C:
+		}
+
+		ce.snapshots.mu.Lock()
+		ce.snapshots.last = time.Now()
+		ce.snapshots.mu.Unlock()
+		for _, tenantID := range ce.ListTenants() {
+			select {
+			case <-ce.done:
+				return
+			default:
+			}
+			status := ce.snapshotToDir(tenantID)
+			ce.snapshots.mu.Lock()
+			ce.snapshots.tenants[tenantID] = status
+			ce.snapshots.mu.Unlock()
+		}
+	}
+}
+
+// snapshotToDir replaces a tenant's snapshot in the snapshot directory and
+// prunes the logged values it holds
+func (ce *CognitiveEngine) snapshotToDir(tenantID string) SnapshotStatus {
+	status := SnapshotStatus{TakenAt: time.Now()}
+	path := snapshotPath(ce.snapshots.dir, tenantID)
+	size, err := ce.writeSnapshotFile(tenantID, path)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.SizeBytes = size
+
+	if ce.valueLogs != nil {
+		pruned, err := ce.valueLogs.prune(tenantID, status.TakenAt)
+		status.PrunedValues = pruned
+		if err != nil {
+			status.Error = fmt.Sprintf("failed to prune value logs: %v", err)
+		}
+	}
+	return status
+}
+
+// writeSnapshotFile writes a tenant's snapshot beside path and moves it in
+// place once synced, so that a crash leaves the previous snapshot intact
+func (ce *CognitiveEngine) writeSnapshotFile(tenantID, path string) (int64, error) {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	err = ce.SnapshotTenant(tenantID, f)
+	if err == nil {
+		err = f.Sync()
+	}
+	var size int64
+	if info, statErr := f.Stat(); statErr == nil {
+		size = info.Size()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return 0, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return size, nil
+}
+
+// forget removes a tenant's periodic snapshot, so that it is not restored
+// again, and reports whether there was one
+func (ps *periodicSnapshots) forget(tenantID string) (bool, error) {
+	ps.mu.Lock()
+	delete(ps.tenants, tenantID)
+	ps.mu.Unlock()
+
+	err := os.Remove(snapshotPath(ps.dir, tenantID))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GetStats returns the periodic snapshots' interval and last results
+func (ps *periodicSnapshots) GetStats() map[string]interface{} {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	tenants := make(map[string]SnapshotStatus, len(ps.tenants))
+	for tenantID, status := range ps.tenants {
+		tenants[tenantID] = status
+	}
+	stats := map[string]interface{}{
+		"dir":      ps.dir,
+		"interval": ps.interval.String(),
+		"tenants":  tenants,
+	}
+	if !ps.last.IsZero() {
+		stats["last_round_at"] = ps.last
+	}
+	return stats
+}
+
+// GetLogCompaction reports the size of each shard's value log and how far
+// it is from its next compaction, with the periodic snapshots that let
+// covered values be pruned
+func (ce *CognitiveEngine) GetLogCompaction() (map[string]interface{}, error) {
+	if ce.valueLogs == nil {
+		return nil, fmt.Errorf("value logs are not configured")
+	}
+
+	report := map[string]interface{}{
+		"value_logs": ce.valueLogs.GetStats(),
+	}
+	if ce.snapshots != nil {
+		report["snapshots"] = ce.snapshots.GetStats()
+	}
+	return report, nil
+}
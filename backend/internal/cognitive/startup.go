@@ -0,0 +1,244 @@
+package cognitive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/gitops"
+	"github.com/Avik2024/erebus/backend/internal/health"
+)
+
+// warmUp is a component the engine brings up in the background after it
+// is created. Until every warm-up finishes the engine has not started.
+type warmUp struct {
+	name     string
+	required bool // Whether failing keeps the engine from starting
+	began    time.Time
+	finished time.Time // Zero while warming up
+	err      error
+	details  map[string]interface{}
+}
+
+// beginWarmUp tracks a warm-up and returns the function reporting its
+// outcome
+func (ce *CognitiveEngine) beginWarmUp(name string, required bool) func(details map[string]interface{}, err error) {
+	w := &warmUp{name: name, required: required, began: time.Now()}
+	ce.warmUpMu.Lock()
+	ce.warmUps = append(ce.warmUps, w)
+	ce.warmUpMu.Unlock()
+
+	return func(details map[string]interface{}, err error) {
+		ce.warmUpMu.Lock()
+		defer ce.warmUpMu.Unlock()
+		w.finished, w.details, w.err = time.Now(), details, err
+	}
+}
+
+// startUp hydrates the shards from the snapshot directory and checks their
+// indices, then starts reconciling manifests, so that they apply on top of
+// the restored atoms.
+// The warm-ups are tracked before it runs in the background, so the
+// startup check never reports an engine that has not begun as started.
+func (ce *CognitiveEngine) startUp(cfg *Config) {
+	var hydrated, checked, reconciled func(map[string]interface{}, error)
+	if cfg.SnapshotDir != "" {
+		hydrated = ce.beginWarmUp("shards", true)
+	}
+	if cfg.StartupIntegrity != IntegrityOff {
+		checked = ce.beginWarmUp("integrity", false)
+	}
+	if ce.snapshots != nil {
+		ce.snapshots.wg.Add(1)
+	}
+	if cfg.GitOps.Enabled() {
+		reconciled = ce.beginWarmUp("gitops", false)
+	}
+
+	go func() {
+		if hydrated != nil {
+			files, atoms, err := ce.hydrate(cfg.SnapshotDir)
+			hydrated(map[string]interface{}{"snapshots": files, "atoms": atoms}, err)
+			if ce.snapshots != nil {
+				if err == nil {
+					go ce.runSnapshots()
+				} else {
+					ce.snapshots.wg.Done()
+				}
+			}
+		}
+		if checked != nil {
+			ce.checkStartupIntegrity(cfg.StartupIntegrity, checked)
+		}
+		if reconciled != nil {
+			interval := cfg.GitOps.Interval
+			if interval <= 0 {
+				interval = gitops.DefaultConfig().Interval
+			}
+			ce.runGitOps(interval, reconciled)
+		}
+	}()
+}
+
+// hydrate restores the *.snap snapshots of a directory in name order and
+// returns how many snapshots and atoms were restored. It stops at the
+// first snapshot that fails, so the engine does not start with part of a
+// tenant's atoms.
+func (ce *CognitiveEngine) hydrate(dir string) (int, int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.snap"))
+	if err != nil {
+		return 0, 0, err
+	}
+	sort.Strings(paths)
+
+	files, atoms := 0, 0
+	for _, path := range paths {
+		select {
+		case <-ce.done:
+			return files, atoms, fmt.Errorf("engine closed while hydrating")
+		default:
+		}
+		restored, err := ce.restoreSnapshotFile(path)
+		if err != nil {
+			return files, atoms, fmt.Errorf("restoring %s failed: %w", filepath.Base(path), err)
+		}
+		files++
+		atoms += restored
+	}
+	return files, atoms, nil
+}
+
+func (ce *CognitiveEngine) restoreSnapshotFile(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return ce.RestoreSnapshot(f)
+}
+
+// CheckStartup is a startup check reporting unavailable until the shards
+// are hydrated and the first reconciliation of manifests completed. A
+// failed hydration keeps the engine from starting; a failed
+// reconciliation is reported as degraded, as the next one may succeed.
+func (ce *CognitiveEngine) CheckStartup() health.CheckResult {
+	ce.warmUpMu.Lock()
+	defer ce.warmUpMu.Unlock()
+
+	status := health.StatusOK
+	details := make(map[string]interface{}, len(ce.warmUps))
+	for _, w := range ce.warmUps {
+		d := map[string]interface{}{"started_at": w.began}
+		for key, value := range w.details {
+			d[key] = value
+		}
+		switch {
+		case w.finished.IsZero():
+			status = health.StatusUnavailable
+			d["warming_up"] = true
+		case w.err != nil && w.required:
+			status = health.StatusUnavailable
+			d["error"] = w.err.Error()
+		case w.err != nil:
+			if status == health.StatusOK {
+				status = health.StatusDegraded
+			}
+			d["error"] = w.err.Error()
+		}
+		if !w.finished.IsZero() {
+			d["duration"] = w.finished.Sub(w.began).String()
+		}
+		details[w.name] = d
+	}
+	return health.CheckResult{Status: status, Details: details}
+}
+
+// RuntimeConfig returns the effective configuration of the engine, after
+// defaults were applied. Secrets and pluggable components are reported by
+// whether they are set, never by value.
+func (ce *CognitiveEngine) RuntimeConfig() map[string]interface{} {
+	cfg := ce.config
+	exporters := make([]string, 0, len(cfg.MeteringExporters))
+	for _, exporter := range cfg.MeteringExporters {
+		exporters = append(exporters, exporter.Name())
+	}
+	gitopsInterval := cfg.GitOps.Interval
+	if gitopsInterval <= 0 {
+		gitopsInterval = gitops.DefaultConfig().Interval
+	}
+
+	config := map[string]interface{}{
+		"num_shards":             ce.shardManager.NumShards(),
+		"workers_per_shard":      ce.workersPerShard,
+		"inference_workers":      ce.inferenceWorkers,
+		"agent_workers":          ce.agentWorkers,
+		"pipeline_workers":       ce.pipelineWorkers,
+		"durable_pipeline_queue": cfg.PipelineQueue != nil,
+		"session_ttl":            cfg.SessionTTL.String(),
+		"max_session_ttl":        cfg.MaxSessionTTL.String(),
+		"rule_selection":         ce.ruleSelection,
+		"pipelined_inference":    ce.pipelined,
+		"encryption":             ce.encryptor != nil,
+		"agent_metrics":          cfg.AgentMetrics != nil,
+		"shard_metrics":          cfg.ShardMetrics != nil,
+		"id_scheme":              atomspace.CurrentIDScheme(),
+		"intern_sweep_interval":  cfg.InternSweepInterval.String(),
+		"snapshot_dir":           cfg.SnapshotDir,
+		"snapshot_interval":      cfg.SnapshotInterval.String(),
+		"startup_integrity":      string(cfg.StartupIntegrity),
+		"history": map[string]interface{}{
+			"retention":    cfg.History.Retention.String(),
+			"max_versions": cfg.History.MaxVersions,
+		},
+		"hot_shards": map[string]interface{}{
+			"interval": cfg.HotShards.Interval.String(),
+			"factor":   cfg.HotShards.Factor,
+			"min_rate": cfg.HotShards.MinRate,
+		},
+		"replicas": map[string]interface{}{
+			"enabled":       cfg.Replicas.Enabled,
+			"sync_interval": cfg.Replicas.SyncInterval.String(),
+		},
+		"usage": map[string]interface{}{
+			"resolution": cfg.Usage.Resolution.String(),
+			"retention":  cfg.Usage.Retention.String(),
+		},
+		"metering": map[string]interface{}{
+			"interval":    ce.meter.Config().Interval.String(),
+			"max_pending": ce.meter.Config().MaxPending,
+			"exporters":   exporters,
+		},
+		"gitops": map[string]interface{}{
+			"enabled":  cfg.GitOps.Enabled(),
+			"source":   cfg.GitOps.Source(),
+			"interval": gitopsInterval.String(),
+			"prune":    cfg.GitOps.Prune,
+		},
+	}
+	if ce.elector != nil {
+		config["leader"] = map[string]interface{}{
+			"identity":     ce.elector.Identity(),
+			"retry_period": ce.elector.RetryPeriod().String(),
+		}
+	}
+	if ce.partitioner != nil {
+		config["partition"] = map[string]interface{}{
+			"identity":           ce.partitioner.Identity(),
+			"heartbeat_interval": ce.partitioner.HeartbeatInterval().String(),
+		}
+	}
+	if ce.valueLogs != nil {
+		config["value_log"] = map[string]interface{}{
+			"dir":                    ce.valueLogs.config.Dir,
+			"interval":               ce.valueLogs.config.Interval.String(),
+			"loss_window":            ce.valueLogs.config.LossWindow.String(),
+			"compaction_ratio":       ce.valueLogs.config.CompactionRatio,
+			"compaction_min_records": ce.valueLogs.config.CompactionMinRecords,
+			"retention":              ce.valueLogs.config.Retention.String(),
+		}
+	}
+	return config
+}
@@ -0,0 +1,182 @@
+package statsview
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Selection picks the statistics a caller needs by dotted path, e.g.
+// "sharding" for a whole section or "pipelines.total_pipelines" for one
+// value. Paths lead through nested objects.
+type Selection struct {
+	Include []string // Paths kept; every section if empty
+	Exclude []string // Paths dropped from those kept
+}
+
+// ParseSelection parses comma-separated lists of included and excluded
+// paths
+func ParseSelection(include, exclude string) Selection {
+	return Selection{Include: splitPaths(include), Exclude: splitPaths(exclude)}
+}
+
+func splitPaths(list string) []string {
+	var paths []string
+	for _, path := range strings.Split(list, ",") {
+		if path = strings.Trim(strings.TrimSpace(path), "."); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// Wants reports whether a top-level section has to be computed
+func (s Selection) Wants(section string) bool {
+	for _, path := range s.Exclude {
+		if path == section {
+			return false
+		}
+	}
+	if len(s.Include) == 0 {
+		return true
+	}
+	for _, path := range s.Include {
+		if first, _, _ := strings.Cut(path, "."); first == section {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply keeps the included paths of computed statistics and drops the
+// excluded ones. Nested objects are copied rather than changed, as they
+// may be cached.
+func (s Selection) Apply(stats map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(stats))
+	if len(s.Include) == 0 {
+		for key, value := range stats {
+			result[key] = value
+		}
+	} else {
+		for _, path := range s.Include {
+			if value, ok := lookup(stats, strings.Split(path, ".")); ok {
+				set(result, strings.Split(path, "."), value)
+			}
+		}
+	}
+	for _, path := range s.Exclude {
+		remove(result, strings.Split(path, "."))
+	}
+	return result
+}
+
+func lookup(m map[string]interface{}, path []string) (interface{}, bool) {
+	value, ok := m[path[0]]
+	if !ok || len(path) == 1 {
+		return value, ok
+	}
+	child, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return lookup(child, path[1:])
+}
+
+// set stores a value at a path of m, a map built by Apply, copying the
+// nested objects on the way
+func set(m map[string]interface{}, path []string, value interface{}) {
+	if len(path) == 1 {
+		m[path[0]] = value
+		return
+	}
+	child := copyObject(m[path[0]])
+	m[path[0]] = child
+	set(child, path[1:], value)
+}
+
+// remove drops a path of m, a map built by Apply, copying the nested
+// objects on the way
+func remove(m map[string]interface{}, path []string) {
+	if len(path) == 1 {
+		delete(m, path[0])
+		return
+	}
+	if _, ok := m[path[0]].(map[string]interface{}); !ok {
+		return
+	}
+	child := copyObject(m[path[0]])
+	m[path[0]] = child
+	remove(child, path[1:])
+}
+
+func copyObject(value interface{}) map[string]interface{} {
+	object, _ := value.(map[string]interface{})
+	copied := make(map[string]interface{}, len(object))
+	for key, v := range object {
+		copied[key] = v
+	}
+	return copied
+}
+
+// Cache keeps computed statistics for a short time, so callers polling
+// them share one computation
+type Cache struct {
+	ttl     time.Duration
+	entries map[string]entry
+	hits    int64
+	misses  int64
+	mu      sync.Mutex
+}
+
+type entry struct {
+	value    interface{}
+	computed time.Time
+}
+
+// NewCache creates a cache keeping statistics for ttl; with 0 nothing is
+// cached
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, entries: make(map[string]entry)}
+}
+
+// Get returns the statistics cached under a key, computing them if they
+// are missing or expired
+func (c *Cache) Get(key string, compute func() interface{}) interface{} {
+	if c.ttl <= 0 {
+		return compute()
+	}
+	now := time.Now()
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && now.Sub(e.computed) < c.ttl {
+		c.hits++
+		c.mu.Unlock()
+		return e.value
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	value := compute()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if now.Sub(e.computed) >= c.ttl {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = entry{value: value, computed: now}
+	return value
+}
+
+// GetStats returns cache statistics
+func (c *Cache) GetStats() map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return map[string]interface{}{
+		"ttl_ms":  c.ttl.Milliseconds(),
+		"entries": len(c.entries),
+		"hits":    c.hits,
+		"misses":  c.misses,
+	}
+}
@@ -0,0 +1,69 @@
+package statsview
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSelection(t *testing.T) {
+	sel := ParseSelection(" sharding, pipelines.total_pipelines,agents,,", "agents.agents, sharding")
+	for section, want := range map[string]bool{"sharding": false, "pipelines": true, "agents": true, "events": false} {
+		if got := sel.Wants(section); got != want {
+			t.Errorf("Wants(%s) = %v, want %v", section, got, want)
+		}
+	}
+	if !(Selection{}).Wants("events") || (Selection{Exclude: []string{"events"}}).Wants("events") {
+		t.Error("an empty selection should want every section not excluded")
+	}
+
+	agents := map[string]interface{}{"total_agents": 2, "agents": []string{"a", "b"}}
+	stats := map[string]interface{}{
+		"pipelines": map[string]interface{}{"total_pipelines": 1, "workers": 4},
+		"agents":    agents,
+	}
+	got := sel.Apply(stats)
+	want := map[string]interface{}{
+		"pipelines": map[string]interface{}{"total_pipelines": 1},
+		"agents":    map[string]interface{}{"total_agents": 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply = %v, want %v", got, want)
+	}
+	// The computed statistics, which may be cached, are left as they were
+	if len(agents) != 2 {
+		t.Errorf("Apply changed its input: %v", agents)
+	}
+
+	if got := (Selection{Include: []string{"pipelines.missing", "agents.total_agents.deeper"}}).Apply(stats); len(got) != 0 {
+		t.Errorf("Apply of missing paths = %v", got)
+	}
+}
+
+func TestCache(t *testing.T) {
+	computed := 0
+	compute := func() interface{} {
+		computed++
+		return computed
+	}
+
+	c := NewCache(time.Hour)
+	if c.Get("agents/", compute) != 1 || c.Get("agents/", compute) != 1 || c.Get("tenant/a", compute) != 2 {
+		t.Errorf("expected cached values, computed %d times", computed)
+	}
+	if stats := c.GetStats(); stats["hits"] != int64(1) || stats["misses"] != int64(2) || stats["entries"] != 2 {
+		t.Errorf("stats = %v", stats)
+	}
+
+	c = NewCache(10 * time.Millisecond)
+	first := c.Get("agents/", compute)
+	time.Sleep(20 * time.Millisecond)
+	if c.Get("agents/", compute) == first {
+		t.Error("expected an expired value computed again")
+	}
+
+	c = NewCache(0)
+	if c.Get("agents/", compute) == c.Get("agents/", compute) {
+		t.Error("expected nothing cached without a TTL")
+	}
+}
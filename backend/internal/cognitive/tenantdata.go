@@ -0,0 +1,402 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/admission"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/changes"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/cost"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/counterfactuals"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/decay"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/dlq"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/forecast"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/incidents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/learning"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/linktypes"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/maintenance"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/notifications"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/persistence"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/provenance"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/queries"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/reports"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/runbooks"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/slo"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/traces"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/watches"
+)
+
+// ExportedAtom is the machine-readable form of an atom in a tenant export
+type ExportedAtom struct {
+	ID             string             `json:"id"`
+	Type           atomspace.AtomType `json:"type"`
+	Name           string             `json:"name"`
+	TruthValue     map[string]float64 `json:"truth_value"`
+	AttentionValue map[string]int16   `json:"attention_value"`
+	Outgoing       []string           `json:"outgoing,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+	RefreshedAt    time.Time          `json:"refreshed_at"`
+}
+
+// TenantExport is a full dump of the data the engine holds for a tenant
+type TenantExport struct {
+	TenantID       string                          `json:"tenant_id"`
+	ExportedAt     time.Time                       `json:"exported_at"`
+	Atoms          []ExportedAtom                  `json:"atoms"`
+	Mounts         []string                        `json:"mounts"`
+	Pipelines      []map[string]interface{}        `json:"pipelines"`
+	Agents         []map[string]interface{}        `json:"agents"`
+	Triggers       []map[string]interface{}        `json:"triggers"`
+	Audit          []provenance.Entry              `json:"audit"`
+	DeadLetters    []dlq.Entry                     `json:"dead_letters"`
+	Incidents      []incidents.Incident            `json:"incidents"`
+	Changes        []changes.Event                 `json:"changes"`
+	SLOs           []slo.SLO                       `json:"slos"`
+	Runbooks       []runbooks.Runbook              `json:"runbooks"`
+	Reports        []reports.Schedule              `json:"reports"`
+	Notifications  []notifications.Channel         `json:"notification_channels"`
+	Maintenance    []maintenance.Window            `json:"maintenance_windows"`
+	Watches        []watches.Watch                 `json:"watches"`
+	AdmissionHooks []admission.Hook                `json:"admission_hooks"`
+	SavedQueries   []queries.Query                 `json:"saved_queries"`
+	DecayPolicies  []decay.Policy                  `json:"decay_policies"`
+	LinkTypes      []linktypes.LinkType            `json:"link_types"` // Overrides of the default link types
+	ACLs           map[acl.Kind]map[string]acl.ACL `json:"acls"`
+	TimeSeries     map[string][]forecast.Sample    `json:"time_series"`
+	CostRates      []cost.Rate                     `json:"cost_rates"`
+	Learned        []learning.Arm                  `json:"learned"`
+	Dependencies   []traces.Dependency             `json:"dependencies"`
+}
+
+// PurgeReport describes the outcome of purging a tenant's data
+type PurgeReport struct {
+	TenantID     string         `json:"tenant_id"`
+	StartedAt    time.Time      `json:"started_at"`
+	CompletedAt  time.Time      `json:"completed_at"`
+	Removed      map[string]int `json:"removed"`       // Kind of data -> items removed
+	KeysShredded bool           `json:"keys_shredded"` // Encrypted snapshots of the tenant can no longer be read
+	Remaining    map[string]int `json:"remaining"`     // Kind of data -> items still found afterwards
+	Verified     bool           `json:"verified"`      // Nothing of the tenant was found afterwards
+	Errors       []string       `json:"errors,omitempty"`
+}
+
+// ExportTenant returns everything the engine holds for a tenant
+func (ce *CognitiveEngine) ExportTenant(tenantID string) (*TenantExport, error) {
+	if !ce.hasTenantData(tenantID) {
+		return nil, fmt.Errorf("no data found for tenant %s", tenantID)
+	}
+
+	export := &TenantExport{
+		TenantID:       tenantID,
+		ExportedAt:     time.Now(),
+		Atoms:          make([]ExportedAtom, 0),
+		Mounts:         ce.GetMounts(tenantID),
+		Pipelines:      make([]map[string]interface{}, 0),
+		Agents:         make([]map[string]interface{}, 0),
+		Triggers:       make([]map[string]interface{}, 0),
+		Audit:          make([]provenance.Entry, 0),
+		DeadLetters:    ce.deadLetters.List(tenantID),
+		Incidents:      ce.incidents.List(tenantID, ""),
+		Changes:        ce.changes.List(tenantID, time.Time{}),
+		SLOs:           ce.sloRegistry.List(tenantID),
+		Runbooks:       ce.runbookRegistry.List(tenantID),
+		Reports:        make([]reports.Schedule, 0),
+		Notifications:  make([]notifications.Channel, 0),
+		Maintenance:    ce.maintenance.List(tenantID),
+		Watches:        ce.watches.List(tenantID),
+		AdmissionHooks: ce.admissionHooks.List(tenantID),
+		SavedQueries:   ce.savedQueries.List(tenantID),
+		DecayPolicies:  ce.decayPolicies.List(tenantID),
+		LinkTypes:      ce.linkTypes.Overrides(tenantID),
+		ACLs: map[acl.Kind]map[string]acl.ACL{
+			acl.KindAtom:     ce.acls.List(tenantID, acl.KindAtom),
+			acl.KindPipeline: ce.acls.List(tenantID, acl.KindPipeline),
+		},
+		TimeSeries:   make(map[string][]forecast.Sample),
+		CostRates:    ce.costModel.Rates(tenantID),
+		Learned:      ce.learner.GetArms(tenantID),
+		Dependencies: ce.traceTracker.Dependencies(tenantID, time.Now()),
+	}
+	for _, atom := range ce.shardManager.QueryAtoms(tenantID, nil) {
+		export.Atoms = append(export.Atoms, exportAtom(atom))
+	}
+	for _, p := range ce.pipelineOrch.GetPipelinesByTenant(tenantID) {
+		export.Pipelines = append(export.Pipelines, p.GetStats())
+	}
+	for _, schedule := range ce.reportRegistry.List(tenantID) {
+		export.Reports = append(export.Reports, schedule.Redacted())
+	}
+	for _, channel := range ce.notifications.ListChannels(tenantID) {
+		export.Notifications = append(export.Notifications, channel.Redacted())
+	}
+	for _, agent := range ce.agentScheduler.GetAgentsByTenant(tenantID) {
+		export.Agents = append(export.Agents, agent.GetStats())
+	}
+	for _, t := range ce.triggerManager.GetTriggersByTenant(tenantID) {
+		export.Triggers = append(export.Triggers, t.GetStats())
+	}
+	if entries, err := ce.provenance.Entries(tenantID, 0, 0); err == nil {
+		export.Audit = append(export.Audit, entries...)
+	}
+	for _, series := range ce.timeSeries.Series(tenantID) {
+		export.TimeSeries[series] = ce.timeSeries.Samples(tenantID, series)
+	}
+	return export, nil
+}
+
+// PurgeTenant deletes everything the engine holds for a tenant: its agents,
+// inference engine, pipelines, triggers, sessions, atoms and all stores,
+// and destroys its encryption keys so that snapshots written for it,
+// wherever they are kept, can no longer be read. The tenant is then looked
+// up again in every store and the report lists whatever was still found.
+// The tenant may be initialized again afterwards.
+func (ce *CognitiveEngine) PurgeTenant(ctx context.Context, tenantID string) (*PurgeReport, error) {
+	if !ce.hasTenantData(tenantID) {
+		return nil, fmt.Errorf("no data found for tenant %s", tenantID)
+	}
+	report := &PurgeReport{TenantID: tenantID, StartedAt: time.Now(), Removed: make(map[string]int)}
+
+	// Stop everything that could write to the tenant's atoms first
+	ce.mu.Lock()
+	inferenceEngine := ce.inferenceEngines[tenantID]
+	delete(ce.inferenceEngines, tenantID)
+	delete(ce.ruleSelections, tenantID)
+	delete(ce.patternMiners, tenantID)
+	delete(ce.clusterAgents, tenantID)
+	delete(ce.forecastAgents, tenantID)
+	delete(ce.costAgents, tenantID)
+	delete(ce.recommenders, tenantID)
+	delete(ce.resolvers, tenantID)
+	delete(ce.sloAgents, tenantID)
+	delete(ce.runbookAgents, tenantID)
+	delete(ce.terraformAgents, tenantID)
+	delete(ce.cmdbAgents, tenantID)
+	delete(ce.driftAgents, tenantID)
+	delete(ce.reportAgents, tenantID)
+	delete(ce.executionAgents, tenantID)
+	delete(ce.connectorAgents, tenantID)
+	report.Removed["mounts"] = len(ce.mounts[tenantID])
+	delete(ce.mounts, tenantID)
+	ce.mu.Unlock()
+	if inferenceEngine != nil {
+		inferenceEngine.Close()
+	}
+
+	tenantAgents := ce.agentScheduler.GetAgentsByTenant(tenantID)
+	for _, agent := range tenantAgents {
+		ce.agentScheduler.UnregisterAgent(agent.GetID())
+	}
+	report.Removed["agents"] = len(tenantAgents)
+	report.Removed["connectors"] = ce.connectors.Purge(tenantID)
+	ce.agentScheduler.RemoveRunPlan(tenantID)
+	ce.agentScheduler.Budgets().Purge(tenantID)
+	if err := ce.awaitAgentsRemoved(ctx, tenantID); err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	}
+
+	tenantTriggers := ce.triggerManager.GetTriggersByTenant(tenantID)
+	for _, t := range tenantTriggers {
+		ce.triggerManager.RemoveTrigger(t.ID, tenantID)
+	}
+	report.Removed["triggers"] = len(tenantTriggers)
+	tenantSessions := ce.sessionManager.GetSessionsByTenant(tenantID)
+	for _, s := range tenantSessions {
+		ce.sessionManager.CloseSession(s.ID, tenantID)
+	}
+	report.Removed["sessions"] = len(tenantSessions)
+	tenantPipelines := ce.pipelineOrch.GetPipelinesByTenant(tenantID)
+	for _, p := range tenantPipelines {
+		ce.pipelineOrch.DeletePipeline(p.ID)
+	}
+	report.Removed["pipelines"] = len(tenantPipelines)
+	report.Removed["artifacts"] = ce.artifacts.Purge(tenantID)
+
+	report.Removed["atoms"] = ce.shardManager.PurgeTenant(tenantID)
+	if _, pinned := ce.shardManager.GetPlacement(tenantID); pinned {
+		ce.shardManager.UnpinTenant(tenantID)
+		report.Removed["placement"] = 1
+	}
+	report.Removed["dead_letters"] = ce.deadLetters.Purge(tenantID)
+	report.Removed["incidents"] = ce.incidents.Purge(tenantID)
+	report.Removed["changes"] = ce.changes.Purge(tenantID)
+	report.Removed["secrets"] = ce.secrets.Purge(tenantID)
+	report.Removed["sandbox_requests"] = ce.sandbox.Purge(tenantID)
+	report.Removed["agent_recordings"] = ce.debugger.Purge(tenantID)
+	report.Removed["slos"] = ce.sloRegistry.Purge(tenantID)
+	report.Removed["runbooks"] = ce.runbookRegistry.Purge(tenantID)
+	report.Removed["reports"] = ce.reportRegistry.Purge(tenantID)
+	report.Removed["notifications"] = ce.notifications.Purge(tenantID)
+	report.Removed["maintenance"] = ce.maintenance.Purge(tenantID)
+	report.Removed["watches"] = ce.watches.Purge(tenantID)
+	report.Removed["shares"] = ce.shares.Purge(tenantID)
+	report.Removed["admission_hooks"] = ce.admissionHooks.Purge(tenantID)
+	report.Removed["saved_queries"] = ce.savedQueries.Purge(tenantID)
+	report.Removed["decay_policies"] = ce.decayPolicies.Purge(tenantID)
+	report.Removed["organization"] = ce.orgs.Purge(tenantID)
+	report.Removed["link_types"] = ce.linkTypes.Purge(tenantID)
+	report.Removed["bundles"] = ce.bundles.Purge(tenantID)
+	report.Removed["acls"] = ce.acls.Purge(tenantID)
+	report.Removed["history"] = ce.history.Purge(tenantID)
+	if ce.valueLogs != nil {
+		removed, err := ce.valueLogs.forget(tenantID)
+		report.Removed["logged_values"] = removed
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to rewrite value logs: %v", err))
+		}
+	}
+	if ce.snapshots != nil {
+		removed, err := ce.snapshots.forget(tenantID)
+		if removed {
+			report.Removed["snapshot"] = 1
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to remove snapshot: %v", err))
+		}
+	}
+	report.Removed["time_series"] = ce.timeSeries.Purge(tenantID)
+	report.Removed["stats_history"] = ce.statsHistory.Purge(tenantID)
+	report.Removed["cost_rates"] = len(ce.costModel.Rates(tenantID))
+	ce.costModel.Delete(tenantID)
+	report.Removed["recommendations"] = ce.recommendations.Purge(tenantID)
+	report.Removed["decision_policy"] = ce.decisionPolicies.Purge(tenantID)
+	report.Removed["attention_params"] = ce.attentionParams.Purge(tenantID)
+	report.Removed["counterfactuals"] = ce.counterfactuals.Purge(tenantID)
+	report.Removed["learned"] = ce.learner.Purge(tenantID)
+	report.Removed["dependencies"] = ce.traceTracker.Purge(tenantID)
+	if info, err := ce.provenance.Info(tenantID); err == nil {
+		report.Removed["audit"] = info.Entries
+		ce.provenance.Remove(tenantID)
+	}
+	if ce.encryptor != nil {
+		if err := ce.encryptor.Shred(ctx, tenantID); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to destroy encryption keys: %v", err))
+		} else {
+			report.KeysShredded = true
+		}
+	}
+
+	report.Remaining = ce.tenantFootprint(tenantID)
+	report.Verified = len(report.Remaining) == 0 && len(report.Errors) == 0
+	report.CompletedAt = time.Now()
+	return report, nil
+}
+
+// awaitAgentsRemoved waits until the scheduler has unregistered the
+// tenant's agents, which happens asynchronously
+func (ce *CognitiveEngine) awaitAgentsRemoved(ctx context.Context, tenantID string) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for len(ce.agentScheduler.GetAgentsByTenant(tenantID)) > 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("agents still registered: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// hasTenantData reports whether the tenant is initialized or anything is
+// stored for it
+func (ce *CognitiveEngine) hasTenantData(tenantID string) bool {
+	if IsSharedTenantID(tenantID) {
+		return false
+	}
+	ce.mu.RLock()
+	_, initialized := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+	return initialized || len(ce.tenantFootprint(tenantID)) > 0
+}
+
+// tenantFootprint counts what the engine holds for a tenant, by kind of
+// data, leaving out kinds with nothing stored
+func (ce *CognitiveEngine) tenantFootprint(tenantID string) map[string]int {
+	ce.mu.RLock()
+	_, initialized := ce.inferenceEngines[tenantID]
+	mounts := len(ce.mounts[tenantID])
+	ce.mu.RUnlock()
+
+	footprint := map[string]int{
+		"atoms":           len(ce.shardManager.QueryAtoms(tenantID, nil)),
+		"mounts":          mounts,
+		"agents":          len(ce.agentScheduler.GetAgentsByTenant(tenantID)),
+		"triggers":        len(ce.triggerManager.GetTriggersByTenant(tenantID)),
+		"sessions":        len(ce.sessionManager.GetSessionsByTenant(tenantID)),
+		"pipelines":       len(ce.pipelineOrch.GetPipelinesByTenant(tenantID)),
+		"dead_letters":    len(ce.deadLetters.List(tenantID)),
+		"incidents":       len(ce.incidents.List(tenantID, "")),
+		"changes":         len(ce.changes.List(tenantID, time.Time{})),
+		"connectors":      len(ce.connectors.List(tenantID)),
+		"slos":            len(ce.sloRegistry.List(tenantID)),
+		"runbooks":        len(ce.runbookRegistry.List(tenantID)),
+		"reports":         len(ce.reportRegistry.List(tenantID)),
+		"notifications":   len(ce.notifications.ListChannels(tenantID)),
+		"maintenance":     len(ce.maintenance.List(tenantID)),
+		"watches":         len(ce.watches.List(tenantID)),
+		"shares":          len(ce.shares.List(tenantID)),
+		"admission_hooks": len(ce.admissionHooks.List(tenantID)),
+		"saved_queries":   len(ce.savedQueries.List(tenantID)),
+		"decay_policies":  len(ce.decayPolicies.List(tenantID)),
+		"link_types":      len(ce.linkTypes.Overrides(tenantID)),
+		"bundles":         len(ce.bundles.List(tenantID)),
+		"acls":            len(ce.acls.List(tenantID, acl.KindAtom)) + len(ce.acls.List(tenantID, acl.KindPipeline)),
+		"time_series":     len(ce.timeSeries.Series(tenantID)),
+		"stats_history":   len(ce.statsHistory.Metrics(tenantID)),
+		"cost_rates":      len(ce.costModel.Rates(tenantID)),
+		"recommendations": len(ce.recommendations.List(tenantID, "")),
+		"counterfactuals": len(ce.counterfactuals.List(tenantID, counterfactuals.Filter{})),
+		"learned":         len(ce.learner.GetArms(tenantID)),
+		"dependencies":    len(ce.traceTracker.Dependencies(tenantID, time.Now())),
+		"history":         ce.history.Count(tenantID),
+	}
+	if initialized {
+		footprint["inference"] = 1
+	}
+	if _, err := ce.provenance.Info(tenantID); err == nil {
+		footprint["audit"] = 1
+	}
+	if ce.valueLogs != nil {
+		footprint["logged_values"] = ce.valueLogs.count(tenantID)
+	}
+	if _, pinned := ce.shardManager.GetPlacement(tenantID); pinned {
+		footprint["placement"] = 1
+	}
+	if _, set := ce.decisionPolicies.Get(tenantID); set {
+		footprint["decision_policy"] = 1
+	}
+	if _, set := ce.attentionParams.Get(tenantID); set {
+		footprint["attention_params"] = 1
+	}
+	for kind, n := range footprint {
+		if n == 0 {
+			delete(footprint, kind)
+		}
+	}
+	return footprint
+}
+
+func exportAtom(atom atomspace.Atom) ExportedAtom {
+	record := persistence.RecordFromAtom(atom)
+	return ExportedAtom{
+		ID:   record.ID,
+		Type: record.Type,
+		Name: record.Name,
+		TruthValue: map[string]float64{
+			"strength":   record.TruthValue.Strength,
+			"confidence": record.TruthValue.Confidence,
+		},
+		AttentionValue: map[string]int16{
+			"sti":  record.AttentionValue.STI,
+			"lti":  record.AttentionValue.LTI,
+			"vlti": record.AttentionValue.VLTI,
+		},
+		Outgoing:    record.Outgoing,
+		CreatedAt:   record.CreatedAt,
+		UpdatedAt:   record.UpdatedAt,
+		RefreshedAt: record.RefreshedAt,
+	}
+}
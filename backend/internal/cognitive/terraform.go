@@ -0,0 +1,144 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/terraform"
+)
+
+// EnableTerraform registers a Terraform agent for a tenant, or updates the
+// configuration of the existing one
+func (ce *CognitiveEngine) EnableTerraform(tenantID string, config agents.TerraformConfig) (*agents.TerraformAgent, error) {
+	names := make(map[string]bool, len(config.Sources))
+	for i := range config.Sources {
+		if err := config.Sources[i].Validate(); err != nil {
+			return nil, err
+		}
+		if names[config.Sources[i].Name] {
+			return nil, fmt.Errorf("duplicate terraform source: %s", config.Sources[i].Name)
+		}
+		names[config.Sources[i].Name] = true
+	}
+
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if agent, exists := ce.terraformAgents[tenantID]; exists {
+		agent.SetConfig(config)
+		return agent, nil
+	}
+
+	agent := agents.NewTerraformAgent(
+		fmt.Sprintf("terraform-%s", tenantID),
+		"TerraformAgent",
+		tenantID,
+		&tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID},
+		config,
+	)
+	agent.SetSecrets(ce.secrets.ForTenant(tenantID))
+	agent.SetSandbox(ce.sandbox.ForTenant(tenantID))
+	ce.terraformAgents[tenantID] = agent
+	ce.agentScheduler.RegisterAgent(agent)
+	return agent, nil
+}
+
+// DisableTerraform unregisters a tenant's Terraform agent. Atoms already
+// written are kept.
+func (ce *CognitiveEngine) DisableTerraform(tenantID string) error {
+	ce.mu.Lock()
+	agent, exists := ce.terraformAgents[tenantID]
+	delete(ce.terraformAgents, tenantID)
+	ce.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("terraform not enabled for tenant %s", tenantID)
+	}
+	ce.agentScheduler.UnregisterAgent(agent.GetID())
+	return nil
+}
+
+// SetTerraformSource adds or replaces a state source, enabling the
+// Terraform agent with the default configuration if needed
+func (ce *CognitiveEngine) SetTerraformSource(tenantID string, source terraform.Source) (agents.TerraformConfig, error) {
+	if err := source.Validate(); err != nil {
+		return agents.TerraformConfig{}, err
+	}
+
+	config := agents.DefaultTerraformConfig()
+	ce.mu.RLock()
+	agent, exists := ce.terraformAgents[tenantID]
+	ce.mu.RUnlock()
+	if exists {
+		config = agent.GetConfig()
+	}
+
+	replaced := false
+	for i := range config.Sources {
+		if config.Sources[i].Name == source.Name {
+			config.Sources[i] = source
+			replaced = true
+		}
+	}
+	if !replaced {
+		config.Sources = append(config.Sources, source)
+	}
+
+	agent, err := ce.EnableTerraform(tenantID, config)
+	if err != nil {
+		return agents.TerraformConfig{}, err
+	}
+	return agent.GetConfig(), nil
+}
+
+// RemoveTerraformSource stops mirroring a state. Atoms already written are
+// kept.
+func (ce *CognitiveEngine) RemoveTerraformSource(tenantID, name string) error {
+	ce.mu.RLock()
+	agent, exists := ce.terraformAgents[tenantID]
+	ce.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("terraform source %s not found", name)
+	}
+
+	config := agent.GetConfig()
+	sources := make([]terraform.Source, 0, len(config.Sources))
+	for _, s := range config.Sources {
+		if s.Name != name {
+			sources = append(sources, s)
+		}
+	}
+	if len(sources) == len(config.Sources) {
+		return fmt.Errorf("terraform source %s not found", name)
+	}
+	config.Sources = sources
+	agent.SetConfig(config)
+	return nil
+}
+
+// SyncTerraform reads a tenant's states immediately, writing those that
+// changed or, with force, all of them
+func (ce *CognitiveEngine) SyncTerraform(ctx context.Context, tenantID string, force bool) ([]agents.TerraformSourceStatus, error) {
+	ce.mu.RLock()
+	agent, exists := ce.terraformAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("terraform not enabled for tenant %s", tenantID)
+	}
+	return agent.Sync(ctx, force)
+}
+
+// GetTerraformStatus returns the configuration and sync status of a
+// tenant's Terraform agent
+func (ce *CognitiveEngine) GetTerraformStatus(tenantID string) (agents.TerraformConfig, []agents.TerraformSourceStatus, error) {
+	ce.mu.RLock()
+	agent, exists := ce.terraformAgents[tenantID]
+	ce.mu.RUnlock()
+
+	if !exists {
+		return agents.TerraformConfig{}, nil, fmt.Errorf("terraform not enabled for tenant %s", tenantID)
+	}
+	return agent.GetConfig(), agent.GetStatuses(), nil
+}
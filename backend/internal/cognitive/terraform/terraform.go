@@ -0,0 +1,227 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Resource is one resource instance declared in a Terraform state
+type Resource struct {
+	Address      string            `json:"address"` // e.g. module.net.aws_subnet.private["a"]
+	Mode         string            `json:"mode"`    // managed or data
+	Type         string            `json:"type"`
+	Name         string            `json:"name"`
+	Module       string            `json:"module,omitempty"`
+	Provider     string            `json:"provider"`
+	ID           string            `json:"id,omitempty"`         // The provider's ID of the resource
+	Attributes   map[string]string `json:"attributes,omitempty"` // Scalar, non-sensitive attributes
+	Dependencies []string          `json:"dependencies,omitempty"`
+}
+
+// State is a parsed Terraform state
+type State struct {
+	Version          int        `json:"version"`
+	TerraformVersion string     `json:"terraform_version"`
+	Serial           int64      `json:"serial"`
+	Lineage          string     `json:"lineage"`
+	Resources        []Resource `json:"resources"`
+}
+
+// Revision identifies a state snapshot; it changes whenever Terraform
+// writes the state
+func (s *State) Revision() string {
+	return fmt.Sprintf("%s/%d", s.Lineage, s.Serial)
+}
+
+type rawState struct {
+	Version          int    `json:"version"`
+	TerraformVersion string `json:"terraform_version"`
+	Serial           int64  `json:"serial"`
+	Lineage          string `json:"lineage"`
+	Resources        []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Provider  string `json:"provider"`
+		Instances []struct {
+			IndexKey            interface{}            `json:"index_key"`
+			Attributes          map[string]interface{} `json:"attributes"`
+			SensitiveAttributes []json.RawMessage      `json:"sensitive_attributes"`
+			Dependencies        []string               `json:"dependencies"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// Parse decodes a Terraform state file in format version 4, the format
+// written since Terraform 0.12
+func Parse(data []byte) (*State, error) {
+	var raw rawState
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid terraform state: %w", err)
+	}
+	if raw.Version != 4 {
+		return nil, fmt.Errorf("unsupported terraform state version: %d", raw.Version)
+	}
+
+	state := &State{
+		Version:          raw.Version,
+		TerraformVersion: raw.TerraformVersion,
+		Serial:           raw.Serial,
+		Lineage:          raw.Lineage,
+		Resources:        make([]Resource, 0, len(raw.Resources)),
+	}
+	for _, r := range raw.Resources {
+		base := r.Type + "." + r.Name
+		if r.Mode == "data" {
+			base = "data." + base
+		}
+		if r.Module != "" {
+			base = r.Module + "." + base
+		}
+
+		for _, inst := range r.Instances {
+			address := base
+			switch key := inst.IndexKey.(type) {
+			case string:
+				address += fmt.Sprintf("[%q]", key)
+			case float64:
+				address += fmt.Sprintf("[%d]", int64(key))
+			}
+
+			sensitive := sensitivePaths(inst.SensitiveAttributes)
+			attributes := make(map[string]string)
+			for name, value := range inst.Attributes {
+				if sensitive[name] {
+					continue
+				}
+				switch v := value.(type) {
+				case string:
+					attributes[name] = v
+				case float64, bool:
+					attributes[name] = fmt.Sprint(v)
+				}
+			}
+
+			dependencies := append([]string(nil), inst.Dependencies...)
+			sort.Strings(dependencies)
+			state.Resources = append(state.Resources, Resource{
+				Address:      address,
+				Mode:         r.Mode,
+				Type:         r.Type,
+				Name:         r.Name,
+				Module:       r.Module,
+				Provider:     providerName(r.Provider),
+				ID:           attributes["id"],
+				Attributes:   attributes,
+				Dependencies: dependencies,
+			})
+		}
+	}
+	sort.Slice(state.Resources, func(i, j int) bool { return state.Resources[i].Address < state.Resources[j].Address })
+	return state, nil
+}
+
+// sensitivePaths returns the top-level attribute names marked sensitive.
+// Each entry is a path such as [{"type":"get_attr","value":"password"}].
+func sensitivePaths(entries []json.RawMessage) map[string]bool {
+	result := make(map[string]bool)
+	for _, entry := range entries {
+		var path []struct {
+			Type  string      `json:"type"`
+			Value interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(entry, &path); err != nil || len(path) == 0 {
+			continue
+		}
+		if name, ok := path[0].Value.(string); ok && path[0].Type == "get_attr" {
+			result[name] = true
+		}
+	}
+	return result
+}
+
+// providerName shortens provider["registry.terraform.io/hashicorp/aws"] to
+// hashicorp/aws
+func providerName(provider string) string {
+	provider = strings.TrimPrefix(provider, "provider[")
+	provider = strings.TrimSuffix(provider, "]")
+	provider = strings.Trim(provider, `"`)
+	return strings.TrimPrefix(provider, "registry.terraform.io/")
+}
+
+// Source is where a Terraform state is read from: a local file or the URL
+// of an HTTP state backend
+type Source struct {
+	Name  string `json:"name"`
+	Path  string `json:"path,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Token string `json:"token,omitempty"` // Bearer token of the HTTP backend
+}
+
+// Validate checks that a source names exactly one location
+func (s *Source) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("terraform source name is required")
+	}
+	if (s.Path == "") == (s.URL == "") {
+		return fmt.Errorf("terraform source %s requires either a path or a url", s.Name)
+	}
+	if s.URL != "" && !strings.HasPrefix(s.URL, "http://") && !strings.HasPrefix(s.URL, "https://") {
+		return fmt.Errorf("terraform source %s requires an http(s) url", s.Name)
+	}
+	return nil
+}
+
+// Load reads and parses the state of a source
+func (s *Source) Load(ctx context.Context, client *http.Client) (*State, error) {
+	var data []byte
+	var err error
+	if s.Path != "" {
+		data, err = os.ReadFile(s.Path)
+	} else {
+		data, err = s.fetch(ctx, client)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading terraform state %s failed: %w", s.Name, err)
+	}
+	return Parse(data)
+}
+
+func (s *Source) fetch(ctx context.Context, client *http.Client) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("GET %s returned status %d", s.URL, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// BaseAddress strips the instance key from a resource address, giving the
+// form used in dependency lists
+func BaseAddress(address string) string {
+	if strings.HasSuffix(address, "]") {
+		if i := strings.LastIndex(address, "["); i > 0 {
+			return address[:i]
+		}
+	}
+	return address
+}
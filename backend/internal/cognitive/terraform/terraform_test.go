@@ -0,0 +1,76 @@
+package terraform
+
+import "testing"
+
+const sampleState = `{
+  "version": 4,
+  "terraform_version": "1.6.0",
+  "serial": 7,
+  "lineage": "abc",
+  "resources": [
+    {"mode": "managed", "type": "aws_vpc", "name": "main", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+     "instances": [{"attributes": {"id": "vpc-1", "cidr_block": "10.0.0.0/16"}}]},
+    {"module": "module.app", "mode": "managed", "type": "aws_instance", "name": "web", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+     "instances": [
+       {"index_key": 0, "attributes": {"id": "i-1", "instance_type": "t3.micro", "monitoring": true, "user_data": "secret", "tags": {"a": "b"}},
+        "sensitive_attributes": [[{"type": "get_attr", "value": "user_data"}]], "dependencies": ["aws_vpc.main"]},
+       {"index_key": "b", "attributes": {"id": "i-2"}}]},
+    {"mode": "data", "type": "aws_ami", "name": "ubuntu", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+     "instances": [{"attributes": {"id": "ami-1"}}]}
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	state, err := Parse([]byte(sampleState))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if state.Revision() != "abc/7" {
+		t.Errorf("Unexpected revision: %s", state.Revision())
+	}
+
+	want := []string{`aws_vpc.main`, `data.aws_ami.ubuntu`, `module.app.aws_instance.web["b"]`, `module.app.aws_instance.web[0]`}
+	if len(state.Resources) != len(want) {
+		t.Fatalf("Expected %d resources, got %d", len(want), len(state.Resources))
+	}
+	for i, address := range want {
+		if state.Resources[i].Address != address {
+			t.Errorf("Resource %d: expected %s, got %s", i, address, state.Resources[i].Address)
+		}
+	}
+
+	web := state.Resources[3]
+	if web.ID != "i-1" || web.Provider != "hashicorp/aws" || web.Module != "module.app" {
+		t.Errorf("Unexpected resource: %+v", web)
+	}
+	if web.Attributes["monitoring"] != "true" || web.Attributes["instance_type"] != "t3.micro" {
+		t.Errorf("Expected scalar attributes, got %v", web.Attributes)
+	}
+	if _, ok := web.Attributes["user_data"]; ok {
+		t.Error("Expected sensitive attributes to be dropped")
+	}
+	if _, ok := web.Attributes["tags"]; ok {
+		t.Error("Expected non-scalar attributes to be dropped")
+	}
+	if len(web.Dependencies) != 1 || BaseAddress(web.Address) != "module.app.aws_instance.web" {
+		t.Errorf("Unexpected dependencies or base address: %v %s", web.Dependencies, BaseAddress(web.Address))
+	}
+
+	if _, err := Parse([]byte(`{"version": 3}`)); err == nil {
+		t.Error("Expected state version 3 to be rejected")
+	}
+}
+
+func TestSourceValidate(t *testing.T) {
+	invalid := []Source{
+		{Path: "/tmp/x"},
+		{Name: "both", Path: "/tmp/x", URL: "http://x"},
+		{Name: "neither"},
+		{Name: "scheme", URL: "s3://bucket/key"},
+	}
+	for _, s := range invalid {
+		if err := s.Validate(); err == nil {
+			t.Errorf("Expected source %+v to be rejected", s)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package cognitive
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/forecast"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/traces"
+)
+
+// IngestTraces derives service dependencies from spans and records them
+// as calls links between services. Call rates, mean latencies and error
+// rates are appended to the tenant's time series, and dependencies without
+// calls within the expiry are retired. It returns the dependencies the
+// spans updated.
+func (ce *CognitiveEngine) IngestTraces(tenantID string, spans []traces.Span) ([]traces.Dependency, error) {
+	ce.mu.RLock()
+	_, initialized := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+	if !initialized {
+		return nil, fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+
+	now := time.Now()
+	deps := ce.traceTracker.Ingest(tenantID, spans, now)
+
+	space := &tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID}
+	for _, dep := range deps {
+		if _, err := traces.WriteDependency(space, tenantID, dep); err != nil {
+			return nil, fmt.Errorf("recording dependency %s -> %s failed: %w", dep.Caller, dep.Callee, err)
+		}
+		rate, latency, errorRate := traces.SeriesNames(dep.Edge)
+		ce.timeSeries.Append(tenantID, rate, forecast.Sample{Time: now, Value: dep.Rate})
+		ce.timeSeries.Append(tenantID, latency, forecast.Sample{Time: now, Value: dep.MeanLatencyMs})
+		ce.timeSeries.Append(tenantID, errorRate, forecast.Sample{Time: now, Value: dep.ErrorRate})
+	}
+
+	for _, edge := range ce.traceTracker.Expire(tenantID, now) {
+		traces.RetireDependency(space, tenantID, edge)
+	}
+	return deps, nil
+}
+
+// GetDependencies returns a tenant's service dependencies derived from
+// traces, with their statistics over the current window
+func (ce *CognitiveEngine) GetDependencies(tenantID string) []traces.Dependency {
+	return ce.traceTracker.Dependencies(tenantID, time.Now())
+}
@@ -0,0 +1,99 @@
+package traces
+
+import (
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// Names of the atoms written for service dependencies
+const (
+	ServicePrefix  = "service/"
+	ServiceConcept = "Service"
+	CallsPredicate = "calls"
+)
+
+var fullTV = atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+
+// callsEvidence is the number of calls at which a dependency's confidence
+// reaches one half
+const callsEvidence = 10.0
+
+// ServiceName is the concept name of a service
+func ServiceName(service string) string {
+	return ServicePrefix + service
+}
+
+// SeriesNames returns the time series the values of a dependency are
+// recorded in: calls per second, mean latency in milliseconds and error
+// rate
+func SeriesNames(edge Edge) (rate, latency, errorRate string) {
+	subject := ServiceName(edge.Caller) + "->" + ServiceName(edge.Callee)
+	return "call_rate:" + subject, "call_latency_ms:" + subject, "call_error_rate:" + subject
+}
+
+func callsLink(tenantID string, caller, callee atomspace.Atom) *atomspace.Link {
+	pred := atomspace.NewPredicateNode(CallsPredicate, tenantID)
+	outgoing := []atomspace.Atom{pred, caller, callee}
+	return atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, CallsPredicate, outgoing), CallsPredicate, tenantID, atomspace.EvaluationLinkType, outgoing)
+}
+
+// ensureService gets or creates a service concept inheriting Service
+func ensureService(space atomspace.AtomSpaceInterface, tenantID, service string) (atomspace.Atom, error) {
+	node, err := atomspace.Ensure(space, atomspace.NewConceptNode(ServiceName(service), tenantID), fullTV)
+	if err != nil {
+		return nil, err
+	}
+	category, err := atomspace.Ensure(space, atomspace.NewConceptNode(ServiceConcept, tenantID), fullTV)
+	if err != nil {
+		return nil, err
+	}
+	outgoing := []atomspace.Atom{node, category}
+	inheritance := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.InheritanceLinkType, "inheritance", outgoing), "inheritance", tenantID, atomspace.InheritanceLinkType, outgoing)
+	if _, err := atomspace.Ensure(space, inheritance, fullTV); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// WriteDependency records a dependency as a calls link between service
+// concepts:
+//
+//	calls(service/CALLER, service/CALLEE)
+//
+// Its strength is the fraction of successful calls and its confidence
+// grows with the number of calls in the window.
+func WriteDependency(space atomspace.AtomSpaceInterface, tenantID string, dep Dependency) (atomspace.Atom, error) {
+	caller, err := ensureService(space, tenantID, dep.Caller)
+	if err != nil {
+		return nil, err
+	}
+	callee, err := ensureService(space, tenantID, dep.Callee)
+	if err != nil {
+		return nil, err
+	}
+
+	tv := atomspace.TruthValue{
+		Strength:   1 - dep.ErrorRate,
+		Confidence: float64(dep.Calls) / (float64(dep.Calls) + callsEvidence),
+	}
+	link := callsLink(tenantID, caller, callee)
+	link.SetTruthValue(tv)
+	if existing, err := space.GetAtom(link.GetID(), tenantID); err == nil {
+		return existing, space.UpdateAtom(link.GetID(), tenantID, func(a atomspace.Atom) error {
+			a.SetTruthValue(tv)
+			return nil
+		})
+	}
+	return link, space.AddAtom(link)
+}
+
+// RetireDependency keeps the calls link of an expired dependency with a
+// strength of 0
+func RetireDependency(space atomspace.AtomSpaceInterface, tenantID string, edge Edge) error {
+	link := callsLink(tenantID, atomspace.NewConceptNode(ServiceName(edge.Caller), tenantID), atomspace.NewConceptNode(ServiceName(edge.Callee), tenantID))
+	return space.UpdateAtom(link.GetID(), tenantID, func(a atomspace.Atom) error {
+		tv := a.GetTruthValue()
+		tv.Strength = 0
+		a.SetTruthValue(tv)
+		return nil
+	})
+}
@@ -0,0 +1,145 @@
+package traces
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// OTLP/HTTP JSON encoding of an ExportTraceServiceRequest. Only the fields
+// needed to derive service dependencies are decoded.
+type otlpRequest struct {
+	ResourceSpans []struct {
+		Resource struct {
+			Attributes []otlpKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans []struct {
+			Spans []otlpSpan `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId"`
+	Name              string         `json:"name"`
+	Kind              otlpEnum       `json:"kind"`
+	StartTimeUnixNano otlpUint64     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   otlpUint64     `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes"`
+	Status            struct {
+		Code otlpEnum `json:"code"`
+	} `json:"status"`
+}
+
+type otlpKeyValue struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue *string     `json:"stringValue"`
+		IntValue    *otlpUint64 `json:"intValue"`
+	} `json:"value"`
+}
+
+// otlpUint64 accepts 64-bit integers encoded as JSON strings, as the
+// protobuf JSON mapping requires, or as numbers
+type otlpUint64 uint64
+
+func (v *otlpUint64) UnmarshalJSON(data []byte) error {
+	if s, err := strconv.Unquote(string(data)); err == nil {
+		data = []byte(s)
+	}
+	n, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer %s", data)
+	}
+	*v = otlpUint64(n)
+	return nil
+}
+
+// otlpEnum accepts enums encoded as numbers or as their names
+type otlpEnum int
+
+var otlpEnumNames = map[string]int{
+	"SPAN_KIND_UNSPECIFIED": 0,
+	"SPAN_KIND_INTERNAL":    1,
+	"SPAN_KIND_SERVER":      2,
+	"SPAN_KIND_CLIENT":      3,
+	"SPAN_KIND_PRODUCER":    4,
+	"SPAN_KIND_CONSUMER":    5,
+	"STATUS_CODE_UNSET":     0,
+	"STATUS_CODE_OK":        1,
+	"STATUS_CODE_ERROR":     2,
+}
+
+func (e *otlpEnum) UnmarshalJSON(data []byte) error {
+	if s, err := strconv.Unquote(string(data)); err == nil {
+		n, ok := otlpEnumNames[s]
+		if !ok {
+			return fmt.Errorf("unknown enum value %s", s)
+		}
+		*e = otlpEnum(n)
+		return nil
+	}
+	n, err := strconv.Atoi(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid enum value %s", data)
+	}
+	*e = otlpEnum(n)
+	return nil
+}
+
+const otlpStatusError = 2
+
+func attribute(attrs []otlpKeyValue, key string) string {
+	for _, kv := range attrs {
+		if kv.Key != key {
+			continue
+		}
+		if kv.Value.StringValue != nil {
+			return *kv.Value.StringValue
+		}
+		if kv.Value.IntValue != nil {
+			return strconv.FormatUint(uint64(*kv.Value.IntValue), 10)
+		}
+	}
+	return ""
+}
+
+// ParseOTLP decodes an OTLP/HTTP JSON trace export. Spans of resources
+// without a service.name attribute are skipped.
+func ParseOTLP(data []byte) ([]Span, error) {
+	var req otlpRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("invalid OTLP trace export: %w", err)
+	}
+
+	spans := make([]Span, 0)
+	for _, rs := range req.ResourceSpans {
+		service := attribute(rs.Resource.Attributes, "service.name")
+		if service == "" {
+			continue
+		}
+		for _, ss := range rs.ScopeSpans {
+			for _, s := range ss.Spans {
+				if s.TraceID == "" || s.SpanID == "" {
+					return nil, fmt.Errorf("span %q has no trace or span ID", s.Name)
+				}
+				spans = append(spans, Span{
+					TraceID:  s.TraceID,
+					SpanID:   s.SpanID,
+					ParentID: s.ParentSpanID,
+					Service:  service,
+					Name:     s.Name,
+					Kind:     SpanKind(s.Kind),
+					Start:    time.Unix(0, int64(s.StartTimeUnixNano)),
+					End:      time.Unix(0, int64(s.EndTimeUnixNano)),
+					Error:    s.Status.Code == otlpStatusError,
+					Peer:     attribute(s.Attributes, "peer.service"),
+				})
+			}
+		}
+	}
+	return spans, nil
+}
@@ -0,0 +1,367 @@
+package traces
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// SpanKind is the OpenTelemetry span kind
+type SpanKind int
+
+const (
+	SpanKindUnspecified SpanKind = iota
+	SpanKindInternal
+	SpanKindServer
+	SpanKindClient
+	SpanKindProducer
+	SpanKindConsumer
+)
+
+// Span is the part of a trace span relevant to service dependencies
+type Span struct {
+	TraceID  string    `json:"trace_id"`
+	SpanID   string    `json:"span_id"`
+	ParentID string    `json:"parent_id,omitempty"`
+	Service  string    `json:"service"`
+	Name     string    `json:"name"`
+	Kind     SpanKind  `json:"kind"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Error    bool      `json:"error"`
+	Peer     string    `json:"peer,omitempty"` // peer.service of client spans
+}
+
+// Duration returns how long the span took
+func (s *Span) Duration() time.Duration {
+	if s.End.Before(s.Start) {
+		return 0
+	}
+	return s.End.Sub(s.Start)
+}
+
+// Call is one request from a service to another derived from spans
+type Call struct {
+	Caller  string
+	Callee  string
+	At      time.Time
+	Latency time.Duration
+	Error   bool
+}
+
+// Edge identifies a dependency between two services
+type Edge struct {
+	Caller string `json:"caller"`
+	Callee string `json:"callee"`
+}
+
+// Dependency summarises the calls over an edge within the window
+type Dependency struct {
+	Edge
+	Calls         int       `json:"calls"`
+	Errors        int       `json:"errors"`
+	Rate          float64   `json:"rate"`            // Calls per second
+	ErrorRate     float64   `json:"error_rate"`      // Fraction of calls that failed
+	MeanLatencyMs float64   `json:"mean_latency_ms"` // Mean latency of the callee
+	MaxLatencyMs  float64   `json:"max_latency_ms"`
+	LastSeen      time.Time `json:"last_seen"`
+}
+
+// Config controls dependency derivation
+type Config struct {
+	Window   time.Duration // Calls this recent make up the dependency statistics
+	Expiry   time.Duration // Dependencies without calls this long are retired
+	MaxSpans int           // Spans remembered per tenant to join children sent in later exports
+}
+
+// DefaultConfig returns the default tracing configuration
+func DefaultConfig() Config {
+	return Config{
+		Window:   5 * time.Minute,
+		Expiry:   30 * time.Minute,
+		MaxSpans: 10000,
+	}
+}
+
+// bucketsPerWindow is the resolution of the sliding window
+const bucketsPerWindow = 10
+
+type bucket struct {
+	start      time.Time
+	calls      int
+	errors     int
+	latency    time.Duration
+	maxLatency time.Duration
+}
+
+type edgeState struct {
+	buckets  []bucket
+	lastSeen time.Time
+}
+
+// spanRef is a remembered span that later spans may be children of
+type spanRef struct {
+	service string
+	peer    string // Callee already counted for the span from its peer.service
+}
+
+type tenantState struct {
+	spans map[string]*spanRef // traceID/spanID -> span
+	order []string            // Keys of spans in the order they were remembered
+	edges map[Edge]*edgeState
+}
+
+// Tracker derives service dependencies from spans per tenant
+type Tracker struct {
+	config  Config
+	tenants map[string]*tenantState
+	mu      sync.Mutex
+}
+
+// NewTracker creates a dependency tracker
+func NewTracker(config Config) *Tracker {
+	defaults := DefaultConfig()
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.Expiry <= 0 {
+		config.Expiry = defaults.Expiry
+	}
+	if config.MaxSpans <= 0 {
+		config.MaxSpans = defaults.MaxSpans
+	}
+	return &Tracker{
+		config:  config,
+		tenants: make(map[string]*tenantState),
+	}
+}
+
+func (t *Tracker) tenant(tenantID string) *tenantState {
+	state, exists := t.tenants[tenantID]
+	if !exists {
+		state = &tenantState{
+			spans: make(map[string]*spanRef),
+			edges: make(map[Edge]*edgeState),
+		}
+		t.tenants[tenantID] = state
+	}
+	return state
+}
+
+func spanKey(traceID, spanID string) string {
+	return traceID + "/" + spanID
+}
+
+// remember keeps a span for joining, forgetting the oldest beyond MaxSpans
+func (t *Tracker) remember(state *tenantState, key string, ref *spanRef) {
+	if _, exists := state.spans[key]; !exists {
+		state.order = append(state.order, key)
+	}
+	state.spans[key] = ref
+	for len(state.order) > t.config.MaxSpans {
+		delete(state.spans, state.order[0])
+		state.order = state.order[1:]
+	}
+}
+
+// Derive returns the calls between services in a batch of spans, joining
+// children to parents remembered from earlier batches. A span whose parent
+// belongs to another service is a call from that service, timed by the
+// child. Client and producer spans naming a peer.service with no child in
+// the trace are calls to that peer, timed by the client.
+func (t *Tracker) Derive(tenantID string, spans []Span) []Call {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.derive(t.tenant(tenantID), spans)
+}
+
+func (t *Tracker) derive(state *tenantState, spans []Span) []Call {
+	for i := range spans {
+		s := &spans[i]
+		t.remember(state, spanKey(s.TraceID, s.SpanID), &spanRef{service: s.Service})
+	}
+
+	calls := make([]Call, 0)
+	joined := make(map[string]bool) // Spans with a child of another service
+	for i := range spans {
+		s := &spans[i]
+		if s.ParentID == "" {
+			continue
+		}
+		parentKey := spanKey(s.TraceID, s.ParentID)
+		parent, ok := state.spans[parentKey]
+		if !ok || parent.service == s.Service {
+			continue
+		}
+		joined[parentKey] = true
+		if parent.peer == s.Service {
+			continue // Already counted from the parent's peer.service
+		}
+		calls = append(calls, Call{Caller: parent.service, Callee: s.Service, At: s.End, Latency: s.Duration(), Error: s.Error})
+	}
+
+	for i := range spans {
+		s := &spans[i]
+		key := spanKey(s.TraceID, s.SpanID)
+		if s.Peer == "" || s.Peer == s.Service || joined[key] || (s.Kind != SpanKindClient && s.Kind != SpanKindProducer) {
+			continue
+		}
+		state.spans[key].peer = s.Peer
+		calls = append(calls, Call{Caller: s.Service, Callee: s.Peer, At: s.End, Latency: s.Duration(), Error: s.Error})
+	}
+	return calls
+}
+
+// Ingest derives the calls in a batch of spans and records them. It
+// returns the dependencies the batch updated.
+func (t *Tracker) Ingest(tenantID string, spans []Span, now time.Time) []Dependency {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.tenant(tenantID)
+	width := t.config.Window / bucketsPerWindow
+	updated := make(map[Edge]bool)
+	for _, call := range t.derive(state, spans) {
+		edge := Edge{Caller: call.Caller, Callee: call.Callee}
+		es, exists := state.edges[edge]
+		if !exists {
+			es = &edgeState{}
+			state.edges[edge] = es
+		}
+
+		// Calls are bucketed on receipt so clock skew between services
+		// does not drop them from the window
+		start := now.Truncate(width)
+		if n := len(es.buckets); n == 0 || !es.buckets[n-1].start.Equal(start) {
+			es.buckets = append(es.buckets, bucket{start: start})
+		}
+		b := &es.buckets[len(es.buckets)-1]
+		b.calls++
+		if call.Error {
+			b.errors++
+		}
+		b.latency += call.Latency
+		if call.Latency > b.maxLatency {
+			b.maxLatency = call.Latency
+		}
+		es.lastSeen = now
+		updated[edge] = true
+	}
+
+	deps := make([]Dependency, 0, len(updated))
+	for edge := range updated {
+		deps = append(deps, t.summarise(edge, state.edges[edge], now))
+	}
+	sortDependencies(deps)
+	return deps
+}
+
+// summarise computes the statistics of an edge, dropping buckets that left
+// the window
+func (t *Tracker) summarise(edge Edge, es *edgeState, now time.Time) Dependency {
+	cutoff := now.Add(-t.config.Window)
+	kept := es.buckets[:0]
+	for _, b := range es.buckets {
+		if b.start.Add(t.config.Window / bucketsPerWindow).After(cutoff) {
+			kept = append(kept, b)
+		}
+	}
+	es.buckets = kept
+
+	dep := Dependency{Edge: edge, LastSeen: es.lastSeen}
+	var latency, maxLatency time.Duration
+	for _, b := range es.buckets {
+		dep.Calls += b.calls
+		dep.Errors += b.errors
+		latency += b.latency
+		if b.maxLatency > maxLatency {
+			maxLatency = b.maxLatency
+		}
+	}
+	if dep.Calls > 0 {
+		dep.Rate = float64(dep.Calls) / t.config.Window.Seconds()
+		dep.ErrorRate = float64(dep.Errors) / float64(dep.Calls)
+		dep.MeanLatencyMs = float64(latency) / float64(dep.Calls) / float64(time.Millisecond)
+	}
+	dep.MaxLatencyMs = float64(maxLatency) / float64(time.Millisecond)
+	return dep
+}
+
+// Dependencies returns the current dependencies of a tenant
+func (t *Tracker) Dependencies(tenantID string, now time.Time) []Dependency {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, exists := t.tenants[tenantID]
+	if !exists {
+		return []Dependency{}
+	}
+	deps := make([]Dependency, 0, len(state.edges))
+	for edge, es := range state.edges {
+		if now.Sub(es.lastSeen) < t.config.Expiry {
+			deps = append(deps, t.summarise(edge, es, now))
+		}
+	}
+	sortDependencies(deps)
+	return deps
+}
+
+// Expire forgets the dependencies of a tenant without calls within the
+// expiry and returns them
+func (t *Tracker) Expire(tenantID string, now time.Time) []Edge {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, exists := t.tenants[tenantID]
+	if !exists {
+		return nil
+	}
+	expired := make([]Edge, 0)
+	for edge, es := range state.edges {
+		if now.Sub(es.lastSeen) >= t.config.Expiry {
+			delete(state.edges, edge)
+			expired = append(expired, edge)
+		}
+	}
+	return expired
+}
+
+// Purge forgets a tenant's spans and dependencies and returns how many
+// dependencies were removed
+func (t *Tracker) Purge(tenantID string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, exists := t.tenants[tenantID]
+	if !exists {
+		return 0
+	}
+	delete(t.tenants, tenantID)
+	return len(state.edges)
+}
+
+// GetStats returns tracker statistics
+func (t *Tracker) GetStats() map[string]interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	spans, edges := 0, 0
+	for _, state := range t.tenants {
+		spans += len(state.spans)
+		edges += len(state.edges)
+	}
+	return map[string]interface{}{
+		"tenants":      len(t.tenants),
+		"spans":        spans,
+		"dependencies": edges,
+	}
+}
+
+func sortDependencies(deps []Dependency) {
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Caller != deps[j].Caller {
+			return deps[i].Caller < deps[j].Caller
+		}
+		return deps[i].Callee < deps[j].Callee
+	})
+}
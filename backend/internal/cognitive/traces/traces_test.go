@@ -0,0 +1,110 @@
+package traces
+
+import (
+	"testing"
+	"time"
+)
+
+const sampleExport = `{
+  "resourceSpans": [
+    {"resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "frontend"}}]},
+     "scopeSpans": [{"spans": [
+       {"traceId": "t1", "spanId": "a", "name": "GET /", "kind": 2, "startTimeUnixNano": "1000000000", "endTimeUnixNano": "1300000000"},
+       {"traceId": "t1", "spanId": "b", "parentSpanId": "a", "name": "GET /cart", "kind": "SPAN_KIND_CLIENT", "startTimeUnixNano": "1010000000", "endTimeUnixNano": "1200000000"},
+       {"traceId": "t1", "spanId": "c", "parentSpanId": "a", "name": "SELECT", "kind": 3, "startTimeUnixNano": 1010000000, "endTimeUnixNano": 1050000000,
+        "attributes": [{"key": "peer.service", "value": {"stringValue": "postgres"}}]}
+     ]}]},
+    {"resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "cart"}}]},
+     "scopeSpans": [{"spans": [
+       {"traceId": "t1", "spanId": "d", "parentSpanId": "b", "name": "GET /cart", "kind": 2, "startTimeUnixNano": "1020000000", "endTimeUnixNano": "1120000000",
+        "status": {"code": "STATUS_CODE_ERROR"}}
+     ]}]},
+    {"resource": {"attributes": []},
+     "scopeSpans": [{"spans": [{"traceId": "t1", "spanId": "e", "name": "orphan"}]}]}
+  ]
+}`
+
+func TestParseOTLP(t *testing.T) {
+	spans, err := ParseOTLP([]byte(sampleExport))
+	if err != nil {
+		t.Fatalf("ParseOTLP failed: %v", err)
+	}
+	if len(spans) != 4 {
+		t.Fatalf("Expected 4 spans of named services, got %d", len(spans))
+	}
+	if spans[1].Kind != SpanKindClient || spans[1].Service != "frontend" || spans[1].ParentID != "a" {
+		t.Errorf("Unexpected client span: %+v", spans[1])
+	}
+	if spans[2].Peer != "postgres" || spans[2].Duration() != 40*time.Millisecond {
+		t.Errorf("Unexpected peer span: %+v", spans[2])
+	}
+	if !spans[3].Error || spans[3].Service != "cart" {
+		t.Errorf("Expected the cart span to have failed: %+v", spans[3])
+	}
+
+	if _, err := ParseOTLP([]byte(`{"resourceSpans": [{"scopeSpans": [{"spans": [{"kind": "BOGUS"}]}]}]}`)); err == nil {
+		t.Error("Expected an unknown span kind to be rejected")
+	}
+}
+
+func TestIngest(t *testing.T) {
+	spans, err := ParseOTLP([]byte(sampleExport))
+	if err != nil {
+		t.Fatalf("ParseOTLP failed: %v", err)
+	}
+	tracker := NewTracker(Config{Window: time.Minute})
+	now := time.Now()
+
+	deps := tracker.Ingest("t", spans, now)
+	if len(deps) != 2 {
+		t.Fatalf("Expected 2 dependencies, got %+v", deps)
+	}
+	cart, db := deps[0], deps[1]
+	if cart.Caller != "frontend" || cart.Callee != "cart" || cart.Calls != 1 || cart.ErrorRate != 1 || cart.MeanLatencyMs != 100 {
+		t.Errorf("Unexpected frontend -> cart dependency: %+v", cart)
+	}
+	if db.Callee != "postgres" || db.MeanLatencyMs != 40 || db.ErrorRate != 0 {
+		t.Errorf("Unexpected frontend -> postgres dependency: %+v", db)
+	}
+	if cart.Rate != 1.0/60 {
+		t.Errorf("Expected one call per minute, got %v", cart.Rate)
+	}
+
+	// A child exported after its parent is joined to it
+	child := []Span{{TraceID: "t1", SpanID: "f", ParentID: "b", Service: "cart", Start: now, End: now.Add(300 * time.Millisecond)}}
+	deps = tracker.Ingest("t", child, now)
+	if len(deps) != 1 || deps[0].Calls != 2 || deps[0].ErrorRate != 0.5 || deps[0].MaxLatencyMs != 300 {
+		t.Errorf("Expected the late child to be counted, got %+v", deps)
+	}
+
+	// A server span of a peer already counted from peer.service is not
+	// counted again
+	peer := []Span{{TraceID: "t1", SpanID: "g", ParentID: "c", Service: "postgres"}}
+	if deps := tracker.Ingest("t", peer, now); len(deps) != 0 {
+		t.Errorf("Expected the peer's span not to be counted twice, got %+v", deps)
+	}
+
+	// Calls leave the window but the dependency stays until it expires
+	later := now.Add(2 * time.Minute)
+	deps = tracker.Dependencies("t", later)
+	if len(deps) != 2 || deps[0].Calls != 0 {
+		t.Errorf("Expected dependencies without recent calls, got %+v", deps)
+	}
+	if expired := tracker.Expire("t", now.Add(time.Hour)); len(expired) != 2 {
+		t.Errorf("Expected 2 expired dependencies, got %+v", expired)
+	}
+	if deps := tracker.Dependencies("t", now); len(deps) != 0 {
+		t.Errorf("Expected no dependencies after expiry, got %+v", deps)
+	}
+}
+
+func TestMaxSpans(t *testing.T) {
+	tracker := NewTracker(Config{MaxSpans: 1})
+	tracker.Ingest("t", []Span{{TraceID: "t1", SpanID: "a", Service: "frontend"}}, time.Now())
+	tracker.Ingest("t", []Span{{TraceID: "t2", SpanID: "x", Service: "other"}}, time.Now())
+
+	calls := tracker.Derive("t", []Span{{TraceID: "t1", SpanID: "b", ParentID: "a", Service: "cart"}})
+	if len(calls) != 0 {
+		t.Errorf("Expected the forgotten parent not to be joined, got %+v", calls)
+	}
+}
@@ -0,0 +1,60 @@
+package cognitive
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/statsview"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/trends"
+)
+
+// runStatsHistory samples the engine's and each tenant's statistics into
+// their history every resolution until the engine is closed
+func (ce *CognitiveEngine) runStatsHistory(resolution time.Duration) {
+	ticker := time.NewTicker(resolution)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ce.done:
+			return
+		case now := <-ticker.C:
+			ce.sampleStats(now)
+			if ce.watchdog != nil {
+				ce.runWatchdog(now)
+			}
+		}
+	}
+}
+
+// sampleStats records the numeric statistics of the engine and of each
+// tenant
+func (ce *CognitiveEngine) sampleStats(now time.Time) {
+	ce.statsHistory.Record(trends.EngineScope, now, trends.Flatten(ce.GetStats("")))
+	totals := ce.meter.Totals()
+	for _, tenantID := range ce.ListTenants() {
+		stats := ce.SelectStats(tenantID, statsview.Selection{Include: []string{"tenant", "inference_merges", "inference_fixpoint"}})
+		ce.statsHistory.Record(tenantID, now, trends.Flatten(map[string]interface{}{
+			"tenant":             stats["tenant"],
+			"inference_merges":   stats["inference_merges"],
+			"inference_fixpoint": stats["inference_fixpoint"],
+			"metering":           totals[tenantID],
+		}))
+	}
+}
+
+// StatsMetrics returns the metrics with a history, of a tenant or, for an
+// empty tenant ID, of the engine
+func (ce *CognitiveEngine) StatsMetrics(tenantID string) []string {
+	return ce.statsHistory.Metrics(tenantID)
+}
+
+// StatsHistory returns the sampled values of a metric of a tenant or, for
+// an empty tenant ID, of the engine, such as the growth of a tenant's
+// atoms ("tenant.total_atoms") or its inference throughput
+// ("inference_merges.derived" queried as a rate)
+func (ce *CognitiveEngine) StatsHistory(tenantID string, q trends.Query) ([]trends.Point, error) {
+	if ce.statsHistory.Config().Resolution <= 0 {
+		return nil, fmt.Errorf("stats history is disabled")
+	}
+	return ce.statsHistory.Query(tenantID, q)
+}
@@ -0,0 +1,231 @@
+package trends
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EngineScope is the scope of engine-wide metrics; tenants' metrics are
+// scoped by tenant ID
+const EngineScope = ""
+
+// Config configures the history of engine statistics
+type Config struct {
+	Resolution time.Duration // How often statistics are sampled; 0 disables the history
+	Retention  time.Duration // How long samples are kept
+}
+
+// DefaultConfig returns one sample a minute kept for a day
+func DefaultConfig() Config {
+	return Config{Resolution: time.Minute, Retention: 24 * time.Hour}
+}
+
+// Point is the value of a metric at a time
+type Point struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Query selects the points of a metric
+type Query struct {
+	Metric string
+	Since  time.Time     // Points before are left out; none are if zero
+	Until  time.Time     // Points after are left out; none are if zero
+	Step   time.Duration // Points are averaged into buckets this wide; each is returned if 0
+	Rate   bool          // Per-second increase between consecutive points, for counters; decreases, as when a counter restarts, are left out
+}
+
+// Store keeps the samples of numeric statistics for a retention period,
+// by scope and metric. Metrics are named by the dotted path of their value
+// in the statistics sampled, such as "sharding.total_load".
+type Store struct {
+	config Config
+	scopes map[string]map[string][]Point // scope -> metric -> points, oldest first
+	mu     sync.RWMutex
+}
+
+// NewStore creates a store. A non-positive retention takes the default.
+func NewStore(config Config) *Store {
+	if config.Retention <= 0 {
+		config.Retention = DefaultConfig().Retention
+	}
+	return &Store{config: config, scopes: make(map[string]map[string][]Point)}
+}
+
+// Config returns the store's configuration
+func (s *Store) Config() Config {
+	return s.config
+}
+
+// Record appends a sample of a scope's metrics taken at a time, dropping
+// the points older than the retention
+func (s *Store) Record(scope string, at time.Time, values map[string]float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metrics := s.scopes[scope]
+	if metrics == nil {
+		metrics = make(map[string][]Point)
+		s.scopes[scope] = metrics
+	}
+	for metric, value := range values {
+		metrics[metric] = append(metrics[metric], Point{Time: at, Value: value})
+	}
+
+	cutoff := at.Add(-s.config.Retention)
+	for metric, points := range metrics {
+		i := sort.Search(len(points), func(i int) bool { return points[i].Time.After(cutoff) })
+		switch {
+		case i == len(points):
+			delete(metrics, metric)
+		case i > 0:
+			metrics[metric] = append([]Point(nil), points[i:]...)
+		}
+	}
+}
+
+// Metrics returns the names of a scope's metrics, sorted
+func (s *Store) Metrics(scope string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.scopes[scope]))
+	for metric := range s.scopes[scope] {
+		names = append(names, metric)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Query returns the points of a scope's metric selected by q
+func (s *Store) Query(scope string, q Query) ([]Point, error) {
+	s.mu.RLock()
+	stored, ok := s.scopes[scope][q.Metric]
+	var points []Point
+	for _, p := range stored {
+		if (q.Since.IsZero() || !p.Time.Before(q.Since)) && (q.Until.IsZero() || !p.Time.After(q.Until)) {
+			points = append(points, p)
+		}
+	}
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %s", q.Metric)
+	}
+	if q.Rate {
+		points = rates(points)
+	}
+	if q.Step > 0 {
+		points = downsample(points, q.Step)
+	}
+	if points == nil {
+		points = []Point{}
+	}
+	return points, nil
+}
+
+// rates returns the per-second increase between consecutive points, at
+// the later point
+func rates(points []Point) []Point {
+	var result []Point
+	for i := 1; i < len(points); i++ {
+		elapsed := points[i].Time.Sub(points[i-1].Time).Seconds()
+		increase := points[i].Value - points[i-1].Value
+		if elapsed <= 0 || increase < 0 {
+			continue
+		}
+		result = append(result, Point{Time: points[i].Time, Value: increase / elapsed})
+	}
+	return result
+}
+
+// downsample averages points into buckets of width step, each at its start
+func downsample(points []Point, step time.Duration) []Point {
+	var result []Point
+	var sum float64
+	var n int
+	for i, p := range points {
+		sum += p.Value
+		n++
+		start := p.Time.Truncate(step)
+		if i+1 == len(points) || !points[i+1].Time.Truncate(step).Equal(start) {
+			result = append(result, Point{Time: start, Value: sum / float64(n)})
+			sum, n = 0, 0
+		}
+	}
+	return result
+}
+
+// Purge drops a scope's metrics and returns how many there were
+func (s *Store) Purge(scope string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.scopes[scope])
+	delete(s.scopes, scope)
+	return n
+}
+
+// GetStats returns store statistics
+func (s *Store) GetStats() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	series, points := 0, 0
+	for _, metrics := range s.scopes {
+		series += len(metrics)
+		for _, p := range metrics {
+			points += len(p)
+		}
+	}
+	return map[string]interface{}{
+		"resolution": s.config.Resolution.String(),
+		"retention":  s.config.Retention.String(),
+		"scopes":     len(s.scopes),
+		"series":     series,
+		"points":     points,
+	}
+}
+
+// Flatten returns the numeric values of statistics by their dotted path,
+// as they encode to JSON, with booleans as 1 or 0. Values inside arrays,
+// whose order may change between samples, are left out.
+func Flatten(stats interface{}) map[string]float64 {
+	values := make(map[string]float64)
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return values
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return values
+	}
+	flatten("", decoded, values)
+	return values
+}
+
+func flatten(path string, v interface{}, values map[string]float64) {
+	switch v := v.(type) {
+	case float64:
+		if path != "" {
+			values[path] = v
+		}
+	case bool:
+		if path != "" {
+			values[path] = 0
+			if v {
+				values[path] = 1
+			}
+		}
+	case map[string]interface{}:
+		for key, child := range v {
+			if path != "" {
+				key = path + "." + key
+			}
+			flatten(key, child, values)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package trends
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	now := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	s := NewStore(Config{Resolution: time.Minute, Retention: time.Hour})
+
+	// An atom count growing by 60 a minute, over 90 minutes
+	for i := 90; i >= 0; i-- {
+		at := now.Add(time.Duration(-i) * time.Minute)
+		s.Record("acme", at, map[string]float64{"tenant.total_atoms": float64(6000 - 60*i)})
+	}
+	s.Record(EngineScope, now, map[string]float64{"sharding.total_load": 12})
+
+	points, err := s.Query("acme", Query{Metric: "tenant.total_atoms"})
+	if err != nil || len(points) != 60 || points[59].Value != 6000 {
+		t.Fatalf("expected the last hour of points retained, got %d: %v", len(points), err)
+	}
+	points, _ = s.Query("acme", Query{Metric: "tenant.total_atoms", Since: now.Add(-10 * time.Minute), Rate: true})
+	if len(points) != 10 || points[0].Value != 1 {
+		t.Errorf("expected a growth of one atom a second, got %+v", points)
+	}
+	points, _ = s.Query("acme", Query{Metric: "tenant.total_atoms", Since: now.Add(-29 * time.Minute), Step: 15 * time.Minute})
+	if len(points) != 3 || points[0].Time != now.Add(-30*time.Minute) || points[0].Value != 4650 {
+		t.Errorf("expected points averaged per quarter hour, got %+v", points)
+	}
+	if _, err := s.Query("acme", Query{Metric: "inference.runs"}); err == nil {
+		t.Error("expected an unknown metric rejected")
+	}
+	if metrics := s.Metrics(EngineScope); len(metrics) != 1 || metrics[0] != "sharding.total_load" {
+		t.Errorf("expected the engine's metrics listed, got %v", metrics)
+	}
+
+	if s.Purge("acme") != 1 || len(s.Metrics("acme")) != 0 {
+		t.Error("expected acme's metrics purged")
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	values := Flatten(map[string]interface{}{
+		"sharding": map[string]interface{}{"total_load": 12, "shards": []int{1, 2}},
+		"merges": struct {
+			Derived int64 `json:"derived"`
+		}{Derived: 3},
+		"interval": "1m0s",
+		"healthy":  true,
+	})
+	if len(values) != 3 || values["sharding.total_load"] != 12 || values["merges.derived"] != 3 || values["healthy"] != 1 {
+		t.Errorf("expected the numeric and boolean values by path, got %v", values)
+	}
+}
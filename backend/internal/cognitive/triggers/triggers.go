@@ -0,0 +1,425 @@
+package triggers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/events"
+)
+
+// Condition is a saved query predicate evaluated against engine events.
+// Unset fields match everything.
+type Condition struct {
+	Events        []events.EventType  `json:"events,omitempty"` // defaults to atom added/updated
+	AtomType      *atomspace.AtomType `json:"atom_type,omitempty"`
+	NamePrefix    string              `json:"name_prefix,omitempty"`
+	NameContains  string              `json:"name_contains,omitempty"`
+	MinStrength   float64             `json:"min_strength,omitempty"`
+	MinConfidence float64             `json:"min_confidence,omitempty"`
+}
+
+// Matches reports whether an event satisfies the condition
+func (c *Condition) Matches(event events.Event) bool {
+	eventTypes := c.Events
+	if len(eventTypes) == 0 {
+		eventTypes = []events.EventType{events.AtomAdded, events.AtomUpdated}
+	}
+	typeMatch := false
+	for _, t := range eventTypes {
+		if t == event.Type {
+			typeMatch = true
+			break
+		}
+	}
+	if !typeMatch {
+		return false
+	}
+
+	if event.Atom == nil {
+		// Non-atom events only match conditions without atom predicates
+		return c.AtomType == nil && c.NamePrefix == "" && c.NameContains == "" &&
+			c.MinStrength == 0 && c.MinConfidence == 0
+	}
+
+	if c.AtomType != nil && event.Atom.GetType() != *c.AtomType {
+		return false
+	}
+	if c.NamePrefix != "" && !strings.HasPrefix(event.Atom.GetName(), c.NamePrefix) {
+		return false
+	}
+	if c.NameContains != "" && !strings.Contains(event.Atom.GetName(), c.NameContains) {
+		return false
+	}
+
+	tv := event.Atom.GetTruthValue()
+	return tv.Strength >= c.MinStrength && tv.Confidence >= c.MinConfidence
+}
+
+// ActionType identifies what a trigger does when it fires
+type ActionType string
+
+const (
+	ActionExecutePipeline ActionType = "execute_pipeline"
+	ActionWebhook         ActionType = "webhook"
+	ActionNotify          ActionType = "notify" // Send a notification to the tenant's channels
+)
+
+// Action is performed when a trigger fires
+type Action struct {
+	Type       ActionType `json:"type"`
+	PipelineID string     `json:"pipeline_id,omitempty"`
+	URL        string     `json:"url,omitempty"`
+	Channel    string     `json:"channel,omitempty"` // Notification channel; every channel taking trigger notifications if empty
+}
+
+// Validate checks that the action is fully specified
+func (a *Action) Validate() error {
+	switch a.Type {
+	case ActionExecutePipeline:
+		if a.PipelineID == "" {
+			return fmt.Errorf("execute_pipeline action requires pipeline_id")
+		}
+	case ActionWebhook:
+		if !strings.HasPrefix(a.URL, "http://") && !strings.HasPrefix(a.URL, "https://") {
+			return fmt.Errorf("webhook action requires an http(s) url")
+		}
+	case ActionNotify:
+	default:
+		return fmt.Errorf("unknown action type: %s", a.Type)
+	}
+	return nil
+}
+
+// Trigger binds a condition to actions for a tenant
+type Trigger struct {
+	ID        string
+	TenantID  string
+	Name      string
+	Condition Condition
+	Actions   []Action
+	Enabled   bool
+	Cooldown  time.Duration // Minimum time between firings
+	CreatedAt time.Time
+	FireCount int64
+	LastFired time.Time
+	LastError string
+	mu        sync.RWMutex
+}
+
+// GetStats returns trigger statistics
+func (t *Trigger) GetStats() map[string]interface{} {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return map[string]interface{}{
+		"id":          t.ID,
+		"tenant_id":   t.TenantID,
+		"name":        t.Name,
+		"condition":   t.Condition,
+		"actions":     t.Actions,
+		"enabled":     t.Enabled,
+		"cooldown_ms": t.Cooldown.Milliseconds(),
+		"created_at":  t.CreatedAt,
+		"fire_count":  t.FireCount,
+		"last_fired":  t.LastFired,
+		"last_error":  t.LastError,
+	}
+}
+
+// Spec is the declarative part of a trigger, without its ID and firing
+// state
+type Spec struct {
+	Name            string    `json:"name"`
+	Condition       Condition `json:"condition"`
+	Actions         []Action  `json:"actions"`
+	CooldownSeconds int       `json:"cooldown_seconds,omitempty"`
+	Enabled         bool      `json:"enabled"`
+}
+
+// Spec returns the trigger's declarative part
+func (t *Trigger) Spec() Spec {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return Spec{
+		Name:            t.Name,
+		Condition:       t.Condition,
+		Actions:         append([]Action(nil), t.Actions...),
+		CooldownSeconds: int(t.Cooldown / time.Second),
+		Enabled:         t.Enabled,
+	}
+}
+
+// PipelineExecutor runs pipelines on behalf of triggers
+type PipelineExecutor interface {
+	ExecutePipeline(ctx context.Context, pipelineID string, input interface{}) (interface{}, error)
+}
+
+// Performer carries out actions, such as the manager itself or a sandbox
+// recording them
+type Performer interface {
+	Perform(ctx context.Context, action Action, input []atomspace.Atom, payload map[string]interface{}) error
+}
+
+// Manager evaluates triggers against events from the bus
+type Manager struct {
+	triggers   map[string]*Trigger // triggerID -> trigger
+	executor   PipelineExecutor
+	performers func(tenantID string) Performer // Nil if the manager performs every tenant's actions
+	client     *http.Client
+	bus        *events.Bus
+	subID      int64
+	timeout    time.Duration
+	mu         sync.RWMutex
+}
+
+// NewManager creates a trigger manager subscribed to the event bus
+func NewManager(bus *events.Bus, executor PipelineExecutor) *Manager {
+	m := &Manager{
+		triggers: make(map[string]*Trigger),
+		executor: executor,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		bus:      bus,
+		timeout:  30 * time.Second,
+	}
+
+	m.subID = bus.Subscribe("triggers", nil, m.handleEvent)
+
+	return m
+}
+
+// SetPerformers sets the function returning the performer of a tenant's
+// trigger actions
+func (m *Manager) SetPerformers(performers func(tenantID string) Performer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.performers = performers
+}
+
+// AddTrigger registers a new trigger
+func (m *Manager) AddTrigger(t *Trigger) error {
+	if t.ID == "" || t.TenantID == "" {
+		return fmt.Errorf("trigger requires an ID and tenant ID")
+	}
+	if len(t.Actions) == 0 {
+		return fmt.Errorf("trigger %s has no actions", t.ID)
+	}
+	for i := range t.Actions {
+		if err := t.Actions[i].Validate(); err != nil {
+			return fmt.Errorf("trigger %s action %d: %w", t.ID, i, err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.triggers[t.ID]; exists {
+		return fmt.Errorf("trigger %s already exists", t.ID)
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	m.triggers[t.ID] = t
+
+	return nil
+}
+
+// GetTrigger retrieves a trigger belonging to a tenant
+func (m *Manager) GetTrigger(triggerID, tenantID string) (*Trigger, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, exists := m.triggers[triggerID]
+	if !exists || t.TenantID != tenantID {
+		return nil, fmt.Errorf("trigger %s not found", triggerID)
+	}
+	return t, nil
+}
+
+// GetTriggersByTenant returns all triggers of a tenant
+func (m *Manager) GetTriggersByTenant(tenantID string) []*Trigger {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*Trigger
+	for _, t := range m.triggers {
+		if t.TenantID == tenantID {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// SetEnabled enables or disables a trigger
+func (m *Manager) SetEnabled(triggerID, tenantID string, enabled bool) error {
+	t, err := m.GetTrigger(triggerID, tenantID)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.Enabled = enabled
+	t.mu.Unlock()
+	return nil
+}
+
+// RemoveTrigger deletes a trigger
+func (m *Manager) RemoveTrigger(triggerID, tenantID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, exists := m.triggers[triggerID]
+	if !exists || t.TenantID != tenantID {
+		return fmt.Errorf("trigger %s not found", triggerID)
+	}
+	delete(m.triggers, triggerID)
+	return nil
+}
+
+// handleEvent evaluates all of the event tenant's triggers
+func (m *Manager) handleEvent(event events.Event) {
+	for _, t := range m.GetTriggersByTenant(event.TenantID) {
+		if !t.Condition.Matches(event) {
+			continue
+		}
+
+		t.mu.Lock()
+		if !t.Enabled || (t.Cooldown > 0 && time.Since(t.LastFired) < t.Cooldown) {
+			t.mu.Unlock()
+			continue
+		}
+		t.FireCount++
+		t.LastFired = time.Now()
+		t.mu.Unlock()
+
+		go m.fire(t, event)
+	}
+}
+
+// fire executes a trigger's actions
+func (m *Manager) fire(t *Trigger, event events.Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	var input []atomspace.Atom
+	if event.Atom != nil {
+		input = []atomspace.Atom{event.Atom}
+	}
+	payload := map[string]interface{}{
+		"trigger_id": t.ID,
+		"tenant_id":  t.TenantID,
+		"event":      event.Type,
+		"timestamp":  event.Timestamp,
+	}
+	if event.Atom != nil {
+		tv := event.Atom.GetTruthValue()
+		payload["atom"] = map[string]interface{}{
+			"atom_id": event.Atom.GetID(),
+			"name":    event.Atom.GetName(),
+			"type":    event.Atom.GetType(),
+			"truth_value": map[string]float64{
+				"strength":   tv.Strength,
+				"confidence": tv.Confidence,
+			},
+		}
+	}
+
+	var performer Performer = m
+	m.mu.RLock()
+	if m.performers != nil {
+		performer = m.performers(t.TenantID)
+	}
+	m.mu.RUnlock()
+
+	var errs []string
+	for _, action := range t.Actions {
+		if err := performer.Perform(ctx, action, input, payload); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", action.Type, err))
+		}
+	}
+
+	t.mu.Lock()
+	t.LastError = strings.Join(errs, "; ")
+	t.mu.Unlock()
+}
+
+// Perform executes a single action. Pipelines receive input and webhooks
+// receive payload as JSON.
+func (m *Manager) Perform(ctx context.Context, action Action, input []atomspace.Atom, payload map[string]interface{}) error {
+	if err := action.Validate(); err != nil {
+		return err
+	}
+	switch action.Type {
+	case ActionExecutePipeline:
+		var pipelineInput interface{}
+		if input != nil {
+			pipelineInput = input
+		}
+		_, err := m.executor.ExecutePipeline(ctx, action.PipelineID, pipelineInput)
+		return err
+	case ActionWebhook:
+		return m.postWebhook(ctx, action.URL, payload)
+	case ActionNotify:
+		return fmt.Errorf("notify action requires a notification service")
+	}
+	return nil
+}
+
+// postWebhook delivers a payload as JSON to a webhook URL
+func (m *Manager) postWebhook(ctx context.Context, url string, payload map[string]interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// GetStats returns trigger manager statistics
+func (m *Manager) GetStats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	enabled := 0
+	fired := int64(0)
+	for _, t := range m.triggers {
+		t.mu.RLock()
+		if t.Enabled {
+			enabled++
+		}
+		fired += t.FireCount
+		t.mu.RUnlock()
+	}
+
+	return map[string]interface{}{
+		"total_triggers":   len(m.triggers),
+		"enabled_triggers": enabled,
+		"total_fired":      fired,
+	}
+}
+
+// Close detaches the manager from the event bus
+func (m *Manager) Close() {
+	m.bus.Unsubscribe(m.subID)
+}
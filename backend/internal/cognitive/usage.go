@@ -0,0 +1,30 @@
+package cognitive
+
+import (
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/usage"
+)
+
+// RecordUsage adds the usage of an API request to its tenant, principal
+// and endpoint
+func (ce *CognitiveEngine) RecordUsage(key usage.Key, counters usage.Counters) {
+	ce.usageTracker.Record(key, counters)
+}
+
+// UsageSummary sums the API usage of the last window, within the retention,
+// of the keys matching a filter, grouped by tenant, principal or endpoint
+func (ce *CognitiveEngine) UsageSummary(window time.Duration, filter usage.Key, groupBy []string) ([]usage.Total, error) {
+	return ce.usageTracker.Summarize(time.Now().Add(-window), filter, groupBy)
+}
+
+// UsageBuckets returns the API usage of the keys matching a filter bucket by
+// bucket, for billing and chargeback exports
+func (ce *CognitiveEngine) UsageBuckets(since, until time.Time, filter usage.Key) []usage.Entry {
+	return ce.usageTracker.Buckets(since, until, filter)
+}
+
+// UsageConfig returns the bucket width and retention of usage tracking
+func (ce *CognitiveEngine) UsageConfig() usage.Config {
+	return ce.usageTracker.Config()
+}
@@ -0,0 +1,254 @@
+package usage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Key is what usage is recorded against
+type Key struct {
+	TenantID  string `json:"tenant_id,omitempty"` // Empty for requests outside any tenant
+	Principal string `json:"principal,omitempty"` // Authenticated user; empty if anonymous
+	Endpoint  string `json:"endpoint,omitempty"`  // Method and route, e.g. "GET /tenants/{tenantID}/atoms"
+}
+
+// Grouping dimensions of a summary
+const (
+	ByTenant    = "tenant"
+	ByPrincipal = "principal"
+	ByEndpoint  = "endpoint"
+)
+
+// Counters are the usage recorded against a key
+type Counters struct {
+	Requests         int64   `json:"requests"`
+	Errors           int64   `json:"errors"`    // Responses with a 4xx or 5xx status
+	BytesIn          int64   `json:"bytes_in"`  // Request bodies read
+	BytesOut         int64   `json:"bytes_out"` // Response bodies written, before compression
+	Seconds          float64 `json:"seconds"`   // Time spent handling requests
+	InferenceSeconds float64 `json:"inference_seconds"`
+}
+
+func (c *Counters) add(other Counters) {
+	c.Requests += other.Requests
+	c.Errors += other.Errors
+	c.BytesIn += other.BytesIn
+	c.BytesOut += other.BytesOut
+	c.Seconds += other.Seconds
+	c.InferenceSeconds += other.InferenceSeconds
+}
+
+// Total is the usage of a key over a window
+type Total struct {
+	Key
+	Counters
+}
+
+// Entry is the usage of a key within one bucket
+type Entry struct {
+	Start time.Time `json:"start"`
+	Key
+	Counters
+}
+
+// Config configures usage tracking
+type Config struct {
+	Resolution time.Duration // Width of the buckets usage is counted in
+	Retention  time.Duration // How long buckets are kept
+}
+
+// DefaultConfig returns one-minute buckets kept for a day
+func DefaultConfig() Config {
+	return Config{Resolution: time.Minute, Retention: 24 * time.Hour}
+}
+
+// Tracker counts usage in buckets of fixed width, so that it can be summed
+// over any rolling window within the retention
+type Tracker struct {
+	config  Config
+	buckets map[int64]map[Key]*Counters // Bucket start in resolution units -> usage
+	mu      sync.Mutex
+}
+
+// NewTracker creates a tracker. Non-positive settings take their defaults.
+func NewTracker(config Config) *Tracker {
+	defaults := DefaultConfig()
+	if config.Resolution <= 0 {
+		config.Resolution = defaults.Resolution
+	}
+	if config.Retention < config.Resolution {
+		config.Retention = defaults.Retention
+	}
+	return &Tracker{config: config, buckets: make(map[int64]map[Key]*Counters)}
+}
+
+// Config returns the tracker's configuration
+func (t *Tracker) Config() Config {
+	return t.config
+}
+
+// Record adds usage to a key at the current time
+func (t *Tracker) Record(key Key, c Counters) {
+	t.record(key, c, time.Now())
+}
+
+func (t *Tracker) record(key Key, c Counters, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	slot := at.UnixNano() / int64(t.config.Resolution)
+	bucket := t.buckets[slot]
+	if bucket == nil {
+		bucket = make(map[Key]*Counters)
+		t.buckets[slot] = bucket
+		t.expire(slot)
+	}
+	counters := bucket[key]
+	if counters == nil {
+		counters = &Counters{}
+		bucket[key] = counters
+	}
+	counters.add(c)
+}
+
+// expire drops the buckets past the retention, as a new bucket starts
+func (t *Tracker) expire(current int64) {
+	oldest := current - int64(t.config.Retention/t.config.Resolution)
+	for slot := range t.buckets {
+		if slot < oldest {
+			delete(t.buckets, slot)
+		}
+	}
+}
+
+// matches reports whether a key has the filter's non-empty fields
+func (filter Key) matches(key Key) bool {
+	return (filter.TenantID == "" || filter.TenantID == key.TenantID) &&
+		(filter.Principal == "" || filter.Principal == key.Principal) &&
+		(filter.Endpoint == "" || filter.Endpoint == key.Endpoint)
+}
+
+// Summarize sums the usage since a time of the keys matching a filter,
+// grouped by the given dimensions (none sums everything into one entry).
+// Entries are ordered by requests, most first.
+func (t *Tracker) Summarize(since time.Time, filter Key, groupBy []string) ([]Total, error) {
+	group := Key{}
+	for _, dimension := range groupBy {
+		switch dimension {
+		case ByTenant:
+			group.TenantID = "*"
+		case ByPrincipal:
+			group.Principal = "*"
+		case ByEndpoint:
+			group.Endpoint = "*"
+		default:
+			return nil, fmt.Errorf("unknown usage dimension %q, want tenant, principal or endpoint", dimension)
+		}
+	}
+
+	t.mu.Lock()
+	totals := make(map[Key]*Counters)
+	first := since.UnixNano() / int64(t.config.Resolution)
+	for slot, bucket := range t.buckets {
+		if slot < first {
+			continue
+		}
+		for key, counters := range bucket {
+			if !filter.matches(key) {
+				continue
+			}
+			grouped := Key{}
+			if group.TenantID != "" {
+				grouped.TenantID = key.TenantID
+			}
+			if group.Principal != "" {
+				grouped.Principal = key.Principal
+			}
+			if group.Endpoint != "" {
+				grouped.Endpoint = key.Endpoint
+			}
+			if totals[grouped] == nil {
+				totals[grouped] = &Counters{}
+			}
+			totals[grouped].add(*counters)
+		}
+	}
+	t.mu.Unlock()
+
+	entries := make([]Total, 0, len(totals))
+	for key, counters := range totals {
+		entries = append(entries, Total{Key: key, Counters: *counters})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Requests != entries[j].Requests {
+			return entries[i].Requests > entries[j].Requests
+		}
+		return lessKey(entries[i].Key, entries[j].Key)
+	})
+	return entries, nil
+}
+
+// Buckets returns the usage of the keys matching a filter in each bucket
+// starting within [since, until), ordered by time and key, for billing
+// exports. The bucket under way at until is included as far as it goes.
+func (t *Tracker) Buckets(since, until time.Time, filter Key) []Entry {
+	resolution := int64(t.config.Resolution)
+	first, end := since.UnixNano()/resolution, until.UnixNano()
+
+	t.mu.Lock()
+	var entries []Entry
+	for slot, bucket := range t.buckets {
+		if slot < first || slot*resolution >= end {
+			continue
+		}
+		start := time.Unix(0, slot*resolution).UTC()
+		for key, counters := range bucket {
+			if filter.matches(key) {
+				entries = append(entries, Entry{Start: start, Key: key, Counters: *counters})
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Start.Equal(entries[j].Start) {
+			return entries[i].Start.Before(entries[j].Start)
+		}
+		return lessKey(entries[i].Key, entries[j].Key)
+	})
+	return entries
+}
+
+func lessKey(a, b Key) bool {
+	if a.TenantID != b.TenantID {
+		return a.TenantID < b.TenantID
+	}
+	if a.Principal != b.Principal {
+		return a.Principal < b.Principal
+	}
+	return a.Endpoint < b.Endpoint
+}
+
+// WriteCSV writes entries as CSV with a header row
+func WriteCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"start", "tenant_id", "principal", "endpoint", "requests", "errors", "bytes_in", "bytes_out", "seconds", "inference_seconds"})
+	for _, e := range entries {
+		cw.Write([]string{
+			e.Start.Format(time.RFC3339), e.TenantID, e.Principal, e.Endpoint,
+			strconv.FormatInt(e.Requests, 10),
+			strconv.FormatInt(e.Errors, 10),
+			strconv.FormatInt(e.BytesIn, 10),
+			strconv.FormatInt(e.BytesOut, 10),
+			strconv.FormatFloat(e.Seconds, 'f', 3, 64),
+			strconv.FormatFloat(e.InferenceSeconds, 'f', 3, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,76 @@
+package usage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	tr := NewTracker(Config{Resolution: time.Minute, Retention: time.Hour})
+
+	query := Key{TenantID: "acme", Principal: "alice", Endpoint: "GET /tenants/{tenantID}/atoms"}
+	infer := Key{TenantID: "acme", Principal: "bob", Endpoint: "POST /tenants/{tenantID}/inference"}
+	other := Key{TenantID: "globex", Principal: "alice", Endpoint: "GET /tenants/{tenantID}/atoms"}
+	for i := 0; i < 10; i++ {
+		tr.record(query, Counters{Requests: 1, BytesOut: 100}, now.Add(time.Duration(-i)*time.Minute))
+	}
+	tr.record(infer, Counters{Requests: 1, BytesIn: 20, Seconds: 2.5, InferenceSeconds: 2.5}, now)
+	tr.record(other, Counters{Requests: 1, Errors: 1}, now.Add(-30*time.Minute))
+
+	// The last five minutes, per tenant
+	entries, err := tr.Summarize(now.Add(-5*time.Minute), Key{}, []string{ByTenant})
+	if err != nil {
+		t.Fatalf("Summarize failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].TenantID != "acme" || entries[0].Requests != 7 || entries[0].InferenceSeconds != 2.5 {
+		t.Errorf("expected 7 acme requests with 2.5 inference seconds, got %+v", entries)
+	}
+
+	// The whole hour for alice, per endpoint and tenant
+	entries, _ = tr.Summarize(now.Add(-time.Hour), Key{Principal: "alice"}, []string{ByTenant, ByEndpoint})
+	if len(entries) != 2 || entries[0].Requests != 10 || entries[0].BytesOut != 1000 || entries[1].Errors != 1 {
+		t.Errorf("expected alice's usage of both tenants, got %+v", entries)
+	}
+	if entries[0].Principal != "" {
+		t.Errorf("expected ungrouped dimensions blank, got %+v", entries[0].Key)
+	}
+	if _, err := tr.Summarize(now, Key{}, []string{"region"}); err == nil {
+		t.Error("expected an unknown dimension to be rejected")
+	}
+
+	// Buckets past the retention are dropped as new ones start
+	tr.record(query, Counters{Requests: 1}, now.Add(90*time.Minute))
+	if entries := tr.Buckets(time.Time{}, now.Add(time.Hour), Key{}); len(entries) != 0 {
+		t.Errorf("expected expired buckets dropped, got %d", len(entries))
+	}
+}
+
+func TestBucketsExport(t *testing.T) {
+	now := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	tr := NewTracker(DefaultConfig())
+	key := Key{TenantID: "acme", Endpoint: "GET /stats"}
+	tr.record(key, Counters{Requests: 1}, now)
+	tr.record(key, Counters{Requests: 2}, now.Add(30*time.Second))
+	tr.record(key, Counters{Requests: 1}, now.Add(time.Minute))
+	tr.record(Key{TenantID: "globex"}, Counters{Requests: 5}, now)
+
+	entries := tr.Buckets(now, now.Add(time.Hour), Key{TenantID: "acme"})
+	if len(entries) != 2 || entries[0].Requests != 3 || !entries[0].Start.Equal(now) || !entries[1].Start.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected two acme buckets in order, got %+v", entries)
+	}
+	if entries := tr.Buckets(now, now.Add(30*time.Second), Key{TenantID: "acme"}); len(entries) != 1 || entries[0].Requests != 3 {
+		t.Errorf("expected the bucket under way included, got %+v", entries)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, entries); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || lines[1] != "2024-01-31T12:00:00Z,acme,,GET /stats,3,0,0,0,0.000,0.000" {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+}
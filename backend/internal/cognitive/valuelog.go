@@ -0,0 +1,330 @@
+package cognitive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/persistence"
+	"github.com/Avik2024/erebus/backend/internal/health"
+)
+
+// valueLogs persists the truth and attention values of each shard's atoms
+// to a log of its own, in batches. Structural changes are persisted by
+// snapshots; values are replayed on top of them when they are restored.
+// Atoms that move to another shard are logged again there, so the newest
+// record of an atom across logs is the one replayed.
+type valueLogs struct {
+	config    persistence.ValueLogConfig
+	dir       error                    // Error creating the log directory
+	logs      []*persistence.ValueLog  // By shard ID; nil where the log failed to open
+	errs      []error                  // Last error of each shard's log
+	retention map[string]time.Duration // tenantID -> retention overriding the configured one
+	stop      chan struct{}
+	mu        sync.Mutex
+	wg        sync.WaitGroup
+}
+
+// startValueLogs opens a value log per shard and starts scanning the
+// shard's atoms for changed values. Logs that fail to open are reported by
+// the CheckValueLogs readiness check.
+func (ce *CognitiveEngine) startValueLogs(config persistence.ValueLogConfig) {
+	defaults := persistence.DefaultValueLogConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.LossWindow < config.Interval {
+		config.LossWindow = config.Interval
+	}
+	if config.CompactionRatio <= 0 {
+		config.CompactionRatio = defaults.CompactionRatio
+	}
+	if config.CompactionMinRecords <= 0 {
+		config.CompactionMinRecords = defaults.CompactionMinRecords
+	}
+
+	vl := &valueLogs{
+		config:    config,
+		retention: make(map[string]time.Duration),
+		stop:      make(chan struct{}),
+	}
+	vl.dir = os.MkdirAll(config.Dir, 0o700)
+	ce.valueLogs = vl
+	ce.openValueLogs(ce.shardManager.NumShards())
+}
+
+// openValueLogs opens the logs of shards that have none yet, as shards are
+// added
+func (ce *CognitiveEngine) openValueLogs(numShards int) {
+	vl := ce.valueLogs
+	vl.mu.Lock()
+	defer vl.mu.Unlock()
+
+	for shardID := len(vl.logs); shardID < numShards; shardID++ {
+		vl.logs = append(vl.logs, nil)
+		vl.errs = append(vl.errs, vl.dir)
+		if vl.dir != nil {
+			continue
+		}
+		path := filepath.Join(vl.config.Dir, fmt.Sprintf("shard-%d.vlog", shardID))
+		log, err := persistence.OpenSealedValueLog(path, ce.encryptor)
+		if err != nil {
+			vl.errs[shardID] = err
+			continue
+		}
+		log.SetCompaction(vl.config.CompactionRatio, vl.config.CompactionMinRecords)
+		vl.logs[shardID] = log
+		vl.wg.Add(1)
+		go ce.runValueLog(vl, shardID, log)
+	}
+}
+
+// runValueLog scans a shard every interval and flushes its changed values
+// once the oldest has waited for the loss window
+func (ce *CognitiveEngine) runValueLog(vl *valueLogs, shardID int, log *persistence.ValueLog) {
+	defer vl.wg.Done()
+
+	shard, err := ce.shardManager.GetShardByID(shardID)
+	if err != nil {
+		vl.setError(shardID, err)
+		return
+	}
+
+	ticker := time.NewTicker(vl.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			now := time.Now()
+			log.Observe(shard.AtomSpace.AllAtoms(), now)
+			if log.Due(now, vl.config.LossWindow) {
+				_, err := log.Flush()
+				vl.setError(shardID, err)
+			}
+		case <-vl.stop:
+			// Persist what changed since the last scan before the shards close
+			log.Observe(shard.AtomSpace.AllAtoms(), time.Now())
+			vl.setError(shardID, log.Close())
+			return
+		}
+	}
+}
+
+// close flushes and closes the logs once agents no longer change values
+func (vl *valueLogs) close() {
+	close(vl.stop)
+	vl.wg.Wait()
+}
+
+func (vl *valueLogs) setError(shardID int, err error) {
+	vl.mu.Lock()
+	defer vl.mu.Unlock()
+	vl.errs[shardID] = err
+}
+
+// replay applies the logged values of atoms that changed after they were
+// persisted in the snapshot they were restored from
+func (vl *valueLogs) replay(atoms []atomspace.Atom) {
+	newest := make(map[string]*persistence.AtomRecord)
+	for _, log := range vl.open() {
+		for _, rec := range log.Values() {
+			key := rec.TenantID + "/" + rec.ID
+			if last, ok := newest[key]; !ok || rec.UpdatedAt.After(last.UpdatedAt) {
+				newest[key] = rec
+			}
+		}
+	}
+
+	for _, atom := range atoms {
+		rec, ok := newest[atom.GetTenantID()+"/"+atom.GetID()]
+		if !ok {
+			continue
+		}
+		if t, ok := atom.(timestamped); ok && !rec.UpdatedAt.After(t.GetUpdatedAt()) {
+			continue
+		}
+		atom.SetTruthValue(rec.TruthValue)
+		atom.SetAttentionValue(rec.AttentionValue)
+		if f, ok := atom.(refreshable); ok {
+			refreshedAt := rec.RefreshedAt
+			if refreshedAt.IsZero() {
+				refreshedAt = rec.UpdatedAt
+			}
+			f.RestoreFreshness(refreshedAt, rec.DecayedAt)
+		}
+	}
+}
+
+// open returns the logs that opened
+func (vl *valueLogs) open() []*persistence.ValueLog {
+	vl.mu.Lock()
+	defer vl.mu.Unlock()
+
+	var logs []*persistence.ValueLog
+	for _, log := range vl.logs {
+		if log != nil {
+			logs = append(logs, log)
+		}
+	}
+	return logs
+}
+
+// forget drops a tenant's values from every shard's log
+func (vl *valueLogs) forget(tenantID string) (int, error) {
+	vl.mu.Lock()
+	delete(vl.retention, tenantID)
+	vl.mu.Unlock()
+
+	removed := 0
+	var firstErr error
+	for _, log := range vl.open() {
+		n, err := log.Forget(tenantID)
+		removed += n
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return removed, firstErr
+}
+
+// prune drops a tenant's values that a snapshot taken at snapshotAt holds
+// and that are older than the tenant's retention, returning how many
+// atoms' values were dropped
+func (vl *valueLogs) prune(tenantID string, snapshotAt time.Time) (int, error) {
+	before := snapshotAt.Add(-vl.retentionOf(tenantID))
+	covered := func(rec *persistence.AtomRecord) bool {
+		return rec.TenantID == tenantID && !rec.UpdatedAt.After(before)
+	}
+
+	pruned := 0
+	var firstErr error
+	for _, log := range vl.open() {
+		n, err := log.Prune(covered)
+		pruned += n
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return pruned, firstErr
+}
+
+func (vl *valueLogs) retentionOf(tenantID string) time.Duration {
+	vl.mu.Lock()
+	defer vl.mu.Unlock()
+
+	if retention, ok := vl.retention[tenantID]; ok {
+		return retention
+	}
+	return vl.config.Retention
+}
+
+// SetValueLogRetention sets how long a tenant's truth and attention values
+// stay in the value logs once a periodic snapshot holds them, overriding
+// the configured retention. Longer retention lets an older snapshot of the
+// tenant be restored with the values that changed since.
+func (ce *CognitiveEngine) SetValueLogRetention(tenantID string, retention time.Duration) error {
+	if ce.valueLogs == nil {
+		return fmt.Errorf("value logs are not configured")
+	}
+	if retention < 0 {
+		return fmt.Errorf("retention must not be negative")
+	}
+
+	ce.mu.RLock()
+	_, exists := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+
+	ce.valueLogs.mu.Lock()
+	defer ce.valueLogs.mu.Unlock()
+	ce.valueLogs.retention[tenantID] = retention
+	return nil
+}
+
+// GetValueLogRetention returns how long a tenant's values stay in the value
+// logs once a periodic snapshot holds them
+func (ce *CognitiveEngine) GetValueLogRetention(tenantID string) (time.Duration, error) {
+	if ce.valueLogs == nil {
+		return 0, fmt.Errorf("value logs are not configured")
+	}
+
+	ce.mu.RLock()
+	_, exists := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+	if !exists {
+		return 0, fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+	return ce.valueLogs.retentionOf(tenantID), nil
+}
+
+// count returns how many of a tenant's atoms have logged values
+func (vl *valueLogs) count(tenantID string) int {
+	n := 0
+	for _, log := range vl.open() {
+		n += log.Count(tenantID)
+	}
+	return n
+}
+
+// GetStats returns value log statistics by shard
+func (vl *valueLogs) GetStats() map[string]interface{} {
+	vl.mu.Lock()
+	defer vl.mu.Unlock()
+
+	shards := make([]map[string]interface{}, len(vl.logs))
+	for shardID, log := range vl.logs {
+		stats := map[string]interface{}{}
+		if log != nil {
+			stats = log.GetStats()
+		}
+		stats["shard_id"] = shardID
+		if err := vl.errs[shardID]; err != nil {
+			stats["error"] = err.Error()
+		}
+		shards[shardID] = stats
+	}
+	retention := make(map[string]string, len(vl.retention))
+	for tenantID, d := range vl.retention {
+		retention[tenantID] = d.String()
+	}
+	return map[string]interface{}{
+		"dir":                    vl.config.Dir,
+		"interval":               vl.config.Interval.String(),
+		"loss_window":            vl.config.LossWindow.String(),
+		"compaction_ratio":       vl.config.CompactionRatio,
+		"compaction_min_records": vl.config.CompactionMinRecords,
+		"retention":              vl.config.Retention.String(),
+		"tenant_retention":       retention,
+		"shards":                 shards,
+	}
+}
+
+// CheckValueLogs is a readiness check reporting degraded while a shard's
+// value log failed to open or flush, so its values would be lost on restart
+func (ce *CognitiveEngine) CheckValueLogs() health.CheckResult {
+	if ce.valueLogs == nil {
+		return health.CheckResult{Status: health.StatusOK}
+	}
+
+	ce.valueLogs.mu.Lock()
+	defer ce.valueLogs.mu.Unlock()
+
+	status := health.StatusOK
+	failing := make(map[string]interface{})
+	for shardID, err := range ce.valueLogs.errs {
+		if err != nil {
+			status = health.StatusDegraded
+			failing[fmt.Sprintf("shard-%d", shardID)] = err.Error()
+		}
+	}
+	return health.CheckResult{
+		Status:  status,
+		Details: map[string]interface{}{"failing_logs": failing},
+	}
+}
@@ -0,0 +1,50 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/incidents"
+)
+
+// runWatchdog evaluates the watchdog's rules against the stats just
+// sampled. Alerts that fire or resolve are recorded in the system tenant,
+// where they correlate into incidents like any other alert, and sent to the
+// watchdog's notification channels and those of the system tenant.
+func (ce *CognitiveEngine) runWatchdog(now time.Time) {
+	changed := ce.watchdog.Evaluate(now, ce.ListTenants())
+	if len(changed) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	systemTenant := ce.watchdog.SystemTenant()
+	ce.mu.RLock()
+	_, initialized := ce.inferenceEngines[systemTenant]
+	ce.mu.RUnlock()
+	if !initialized {
+		ce.InitializeTenant(systemTenant)
+	}
+	if _, err := ce.IngestAlerts(ctx, systemTenant, changed); err != nil {
+		ce.watchdog.RecordError(fmt.Errorf("recording alerts failed: %w", err))
+	}
+	ce.watchdog.Notify(ctx, changed)
+	ce.notifyWatchdog(changed)
+}
+
+// GetWatchdog returns the watchdog's rules, the alerts firing, the
+// incidents they opened in the system tenant and the latest failed
+// notifications
+func (ce *CognitiveEngine) GetWatchdog() (map[string]interface{}, error) {
+	if ce.watchdog == nil {
+		return nil, fmt.Errorf("watchdog is not enabled")
+	}
+	return map[string]interface{}{
+		"stats":                 ce.watchdog.GetStats(),
+		"rules":                 ce.watchdog.Rules(),
+		"firing":                ce.watchdog.Firing(),
+		"incidents":             ce.ListIncidents(ce.watchdog.SystemTenant(), incidents.StatusOpen),
+		"notification_failures": ce.watchdog.Failures(),
+	}, nil
+}
@@ -0,0 +1,146 @@
+package watchdog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/incidents"
+)
+
+// ChannelType is how a notification channel is reached
+type ChannelType string
+
+const (
+	ChannelWebhook ChannelType = "webhook" // POST {"alerts": [...]} to URL
+	ChannelSlack   ChannelType = "slack"   // POST a message to a Slack incoming webhook URL
+)
+
+// Channel is where alerts are notified
+type Channel struct {
+	Type        ChannelType `json:"type"`
+	URL         string      `json:"url"`
+	MinSeverity string      `json:"min_severity,omitempty"` // Alerts less severe are not notified; all are if empty
+}
+
+// Validate checks the channel
+func (c *Channel) Validate() error {
+	switch c.Type {
+	case ChannelWebhook, ChannelSlack:
+		if c.URL == "" {
+			return fmt.Errorf("%s channel requires a url", c.Type)
+		}
+	default:
+		return fmt.Errorf("unknown channel type: %s", c.Type)
+	}
+	return nil
+}
+
+// Delivery is the outcome of notifying a channel
+type Delivery struct {
+	Channel     ChannelType `json:"channel"`
+	URL         string      `json:"url"`
+	Alerts      int         `json:"alerts"`
+	Error       string      `json:"error,omitempty"`
+	DeliveredAt time.Time   `json:"delivered_at"`
+}
+
+type notifier struct {
+	client *http.Client
+}
+
+func newNotifier(timeout time.Duration) *notifier {
+	return &notifier{client: &http.Client{Timeout: timeout}}
+}
+
+// Notify sends alerts to every channel that takes them and returns the
+// outcome of each delivery. A failed delivery does not stop the others.
+func (w *Watchdog) Notify(ctx context.Context, alerts []incidents.Alert) []Delivery {
+	var deliveries []Delivery
+	for _, channel := range w.config.Channels {
+		var selected []incidents.Alert
+		for _, alert := range alerts {
+			if channel.MinSeverity == "" || incidents.SeverityAtLeast(alert.Severity, channel.MinSeverity) {
+				selected = append(selected, alert)
+			}
+		}
+		if len(selected) == 0 {
+			continue
+		}
+
+		var body interface{} = map[string]interface{}{"alerts": selected}
+		if channel.Type == ChannelSlack {
+			body = map[string]string{"text": slackText(selected)}
+		}
+		delivery := Delivery{Channel: channel.Type, URL: channel.URL, Alerts: len(selected), DeliveredAt: time.Now()}
+		if err := w.notifier.post(ctx, channel.URL, body); err != nil {
+			delivery.Error = err.Error()
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	w.mu.Lock()
+	for _, delivery := range deliveries {
+		if delivery.Error != "" {
+			w.failures = append(w.failures, delivery)
+		}
+	}
+	if len(w.failures) > maxFailures {
+		w.failures = append([]Delivery(nil), w.failures[len(w.failures)-maxFailures:]...)
+	}
+	w.mu.Unlock()
+	return deliveries
+}
+
+// Failures returns the latest failed notifications
+func (w *Watchdog) Failures() []Delivery {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]Delivery{}, w.failures...)
+}
+
+func (n *notifier) post(ctx context.Context, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("channel returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// slackText renders alerts as one line each
+func slackText(alerts []incidents.Alert) string {
+	var b strings.Builder
+	for i, alert := range alerts {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		state := "FIRING"
+		if alert.Status == incidents.AlertResolved {
+			state = "RESOLVED"
+		}
+		fmt.Fprintf(&b, "[%s] %s on %s (%s), value %s", state, alert.Name, alert.Subject, alert.Severity, alert.Annotations["value"])
+		if description := alert.Annotations["description"]; description != "" {
+			b.WriteString(": " + description)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,396 @@
+package watchdog
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/incidents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/trends"
+)
+
+// DefaultSystemTenant is the tenant the engine's own alerts are raised in
+const DefaultSystemTenant = "erebus-system"
+
+// Condition is how a rule judges the history of its metric
+type Condition string
+
+const (
+	// ConditionAbove fires when every sample of the last For is above the
+	// threshold, and the history covers For
+	ConditionAbove Condition = "above"
+	// ConditionIncrease fires when the metric increased by more than the
+	// threshold over the last For; decreases, as when a counter restarts,
+	// are left out
+	ConditionIncrease Condition = "increase"
+)
+
+// Scope is whose metric a rule watches
+type Scope string
+
+const (
+	ScopeEngine  Scope = "engine"  // The engine's statistics
+	ScopeTenants Scope = "tenants" // Each tenant's statistics, but the system tenant's
+)
+
+// Rule watches one metric of the stats history, named as in
+// trends.Flatten
+type Rule struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Metric      string        `json:"metric"`
+	Scope       Scope         `json:"scope"`
+	Condition   Condition     `json:"condition"`
+	Threshold   float64       `json:"threshold"`
+	For         time.Duration `json:"for"`
+	Severity    string        `json:"severity"`
+}
+
+// Validate checks the rule and fills in its defaults
+func (r *Rule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("rule name is required")
+	}
+	if r.Metric == "" {
+		return fmt.Errorf("rule %s has no metric", r.Name)
+	}
+	if r.Scope == "" {
+		r.Scope = ScopeEngine
+	}
+	if r.Scope != ScopeEngine && r.Scope != ScopeTenants {
+		return fmt.Errorf("rule %s has unknown scope %s", r.Name, r.Scope)
+	}
+	switch r.Condition {
+	case ConditionAbove:
+		if r.For < 0 {
+			return fmt.Errorf("rule %s has a negative duration", r.Name)
+		}
+	case ConditionIncrease:
+		if r.For <= 0 {
+			return fmt.Errorf("rule %s needs a positive duration to measure the increase over", r.Name)
+		}
+	default:
+		return fmt.Errorf("rule %s has unknown condition %s", r.Name, r.Condition)
+	}
+	if r.Severity == "" {
+		r.Severity = "warning"
+	}
+	return nil
+}
+
+// DefaultRules watches the event queue, shard migrations, agent failures
+// and the growth of tenants' atoms
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Name:        "EventQueueBacklog",
+			Description: "Events wait to be dispatched to subscribers",
+			Metric:      "events.pending",
+			Scope:       ScopeEngine,
+			Condition:   ConditionAbove,
+			Threshold:   1000,
+			For:         5 * time.Minute,
+			Severity:    "warning",
+		},
+		{
+			Name:        "ShardMigrationStuck",
+			Description: "Atoms have been moving to resized shards for too long",
+			Metric:      "sharding.migration.active",
+			Scope:       ScopeEngine,
+			Condition:   ConditionAbove,
+			Threshold:   0,
+			For:         30 * time.Minute,
+			Severity:    "critical",
+		},
+		{
+			Name:        "ShardMigrationIncomplete",
+			Description: "Dropped shards still hold atoms the last migration could not move",
+			Metric:      "sharding.migration.retired",
+			Scope:       ScopeEngine,
+			Condition:   ConditionAbove,
+			Threshold:   0,
+			Severity:    "warning",
+		},
+		{
+			Name:        "AgentErrorSpike",
+			Description: "Agent runs fail",
+			Metric:      "agents.failures",
+			Scope:       ScopeEngine,
+			Condition:   ConditionIncrease,
+			Threshold:   20,
+			For:         5 * time.Minute,
+			Severity:    "critical",
+		},
+		{
+			Name:        "TenantAtomExplosion",
+			Description: "A tenant's atoms grow unusually fast",
+			Metric:      "tenant.total_atoms",
+			Scope:       ScopeTenants,
+			Condition:   ConditionIncrease,
+			Threshold:   100000,
+			For:         10 * time.Minute,
+			Severity:    "warning",
+		},
+	}
+}
+
+// Config configures the watchdog
+type Config struct {
+	Enabled       bool
+	SystemTenant  string        // Tenant alerts are raised in; DefaultSystemTenant if empty
+	Rules         []Rule        // DefaultRules if nil
+	Channels      []Channel     // Notified when an alert fires or resolves
+	NotifyTimeout time.Duration // Of each notification
+}
+
+// DefaultConfig returns a disabled watchdog with the default rules
+func DefaultConfig() Config {
+	return Config{
+		SystemTenant:  DefaultSystemTenant,
+		NotifyTimeout: 10 * time.Second,
+	}
+}
+
+// Watchdog evaluates rules over the stats history and tracks the alerts
+// they raise
+type Watchdog struct {
+	config   Config
+	history  *trends.Store
+	notifier *notifier
+	firing   map[string]incidents.Alert // rule and scope -> alert
+	last     time.Time
+	raised   int64
+	resolved int64
+	failures []Delivery // Failed notifications, latest last
+	lastErr  string     // Of recording alerts
+	mu       sync.Mutex
+}
+
+// maxFailures bounds the failed notifications kept for status
+const maxFailures = 20
+
+// Validate checks the rules and channels and fills in the defaults
+func (c *Config) Validate() error {
+	if c.SystemTenant == "" {
+		c.SystemTenant = DefaultSystemTenant
+	}
+	if c.NotifyTimeout <= 0 {
+		c.NotifyTimeout = DefaultConfig().NotifyTimeout
+	}
+	if c.Rules == nil {
+		c.Rules = DefaultRules()
+	}
+	rules := make([]Rule, len(c.Rules))
+	names := make(map[string]bool, len(rules))
+	for i, rule := range c.Rules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate rule %s", rule.Name)
+		}
+		names[rule.Name] = true
+		rules[i] = rule
+	}
+	c.Rules = rules
+	for i := range c.Channels {
+		if err := c.Channels[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// New creates a watchdog over a stats history
+func New(config Config, history *trends.Store) (*Watchdog, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Watchdog{
+		config:   config,
+		history:  history,
+		notifier: newNotifier(config.NotifyTimeout),
+		firing:   make(map[string]incidents.Alert),
+	}, nil
+}
+
+// SystemTenant returns the tenant alerts are raised in
+func (w *Watchdog) SystemTenant() string {
+	return w.config.SystemTenant
+}
+
+// Evaluate judges every rule for the engine or each of the tenants and
+// returns the alerts that started firing or resolved since the last
+// evaluation
+func (w *Watchdog) Evaluate(now time.Time, tenantIDs []string) []incidents.Alert {
+	resolution := w.history.Config().Resolution
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.last = now
+	seen := make(map[string]bool)
+	var changed []incidents.Alert
+	for _, rule := range w.config.Rules {
+		scopes := []string{trends.EngineScope}
+		if rule.Scope == ScopeTenants {
+			scopes = scopes[:0]
+			for _, tenantID := range tenantIDs {
+				if tenantID != w.config.SystemTenant {
+					scopes = append(scopes, tenantID)
+				}
+			}
+		}
+		for _, scope := range scopes {
+			key := rule.Name + "\x00" + scope
+			seen[key] = true
+			value, fires := w.judge(rule, scope, now, resolution)
+			alert, wasFiring := w.firing[key]
+			switch {
+			case fires && !wasFiring:
+				alert = newAlert(rule, scope, value, now)
+				w.firing[key] = alert
+				w.raised++
+				changed = append(changed, alert)
+			case !fires && wasFiring:
+				changed = append(changed, w.resolve(key, alert, now))
+			}
+		}
+	}
+
+	// Alerts of purged tenants resolve
+	for key, alert := range w.firing {
+		if !seen[key] {
+			changed = append(changed, w.resolve(key, alert, now))
+		}
+	}
+	return changed
+}
+
+func (w *Watchdog) resolve(key string, alert incidents.Alert, now time.Time) incidents.Alert {
+	delete(w.firing, key)
+	w.resolved++
+	alert.Status = incidents.AlertResolved
+	alert.EndsAt = now
+	return alert
+}
+
+// judge returns the value of a rule's metric it judged and whether it fires
+func (w *Watchdog) judge(rule Rule, scope string, now time.Time, resolution time.Duration) (float64, bool) {
+	points, err := w.history.Query(scope, trends.Query{Metric: rule.Metric, Since: now.Add(-rule.For), Until: now})
+	if err != nil || len(points) == 0 {
+		return 0, false
+	}
+
+	switch rule.Condition {
+	case ConditionAbove:
+		// The first sample of the window is up to a resolution after its start
+		if rule.For > 0 && now.Sub(points[0].Time)+resolution < rule.For {
+			return 0, false
+		}
+		for _, p := range points {
+			if p.Value <= rule.Threshold {
+				return p.Value, false
+			}
+		}
+		return points[len(points)-1].Value, true
+	case ConditionIncrease:
+		var increase float64
+		for i := 1; i < len(points); i++ {
+			if d := points[i].Value - points[i-1].Value; d > 0 {
+				increase += d
+			}
+		}
+		return increase, increase > rule.Threshold
+	}
+	return 0, false
+}
+
+// newAlert raises a rule's alert on the engine or a tenant. Its subject is
+// erebus/engine or erebus/tenant/TENANT, so that alerts on the same scope
+// correlate into one incident.
+func newAlert(rule Rule, scope string, value float64, now time.Time) incidents.Alert {
+	subject := "erebus/engine"
+	labels := map[string]string{
+		"alertname": rule.Name,
+		"source":    "watchdog",
+		"metric":    rule.Metric,
+		"severity":  rule.Severity,
+	}
+	if scope != trends.EngineScope {
+		subject = "erebus/tenant/" + scope
+		labels["tenant"] = scope
+	}
+	labels["erebus_subject"] = subject
+
+	alert := incidents.Alert{
+		Name:     rule.Name,
+		Subject:  subject,
+		Severity: rule.Severity,
+		Status:   incidents.AlertFiring,
+		Labels:   labels,
+		Annotations: map[string]string{
+			"description": rule.Description,
+			"value":       fmt.Sprintf("%g", value),
+			"threshold":   fmt.Sprintf("%g", rule.Threshold),
+		},
+		StartsAt: now,
+	}
+	alert.Normalize()
+	return alert
+}
+
+// RecordError records the last failure to record alerts
+func (w *Watchdog) RecordError(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastErr = err.Error()
+}
+
+// Firing returns the alerts firing, sorted by name and subject
+func (w *Watchdog) Firing() []incidents.Alert {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	alerts := make([]incidents.Alert, 0, len(w.firing))
+	for _, alert := range w.firing {
+		alerts = append(alerts, alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].Name != alerts[j].Name {
+			return alerts[i].Name < alerts[j].Name
+		}
+		return alerts[i].Subject < alerts[j].Subject
+	})
+	return alerts
+}
+
+// Rules returns the rules evaluated
+func (w *Watchdog) Rules() []Rule {
+	return append([]Rule(nil), w.config.Rules...)
+}
+
+// GetStats returns watchdog statistics
+func (w *Watchdog) GetStats() map[string]interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stats := map[string]interface{}{
+		"system_tenant":         w.config.SystemTenant,
+		"rules":                 len(w.config.Rules),
+		"channels":              len(w.config.Channels),
+		"firing":                len(w.firing),
+		"raised":                w.raised,
+		"resolved":              w.resolved,
+		"notification_failures": len(w.failures),
+	}
+	if !w.last.IsZero() {
+		stats["last_evaluated_at"] = w.last
+	}
+	if w.lastErr != "" {
+		stats["last_error"] = w.lastErr
+	}
+	return stats
+}
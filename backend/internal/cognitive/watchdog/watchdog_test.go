@@ -0,0 +1,135 @@
+package watchdog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/incidents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/trends"
+)
+
+func TestEvaluate(t *testing.T) {
+	history := trends.NewStore(trends.Config{Resolution: time.Minute, Retention: time.Hour})
+	wd, err := New(Config{Rules: []Rule{
+		{Name: "Backlog", Metric: "events.pending", Condition: ConditionAbove, Threshold: 100, For: 3 * time.Minute, Severity: "critical"},
+		{Name: "Explosion", Metric: "tenant.total_atoms", Scope: ScopeTenants, Condition: ConditionIncrease, Threshold: 50, For: 5 * time.Minute},
+	}}, history)
+	if err != nil {
+		t.Fatalf("failed to create watchdog: %v", err)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var changed []incidents.Alert
+	for i := 0; i <= 2; i++ {
+		now := start.Add(time.Duration(i) * time.Minute)
+		history.Record(trends.EngineScope, now, map[string]float64{"events.pending": 500})
+		history.Record("acme", now, map[string]float64{"tenant.total_atoms": float64(10 + 30*i)})
+		history.Record(DefaultSystemTenant, now, map[string]float64{"tenant.total_atoms": float64(10 + 30*i)})
+		changed = wd.Evaluate(now, []string{"acme", DefaultSystemTenant})
+		if i < 2 && len(changed) != 0 {
+			t.Fatalf("expected no alert before the backlog is sustained, got %v at minute %d", changed, i)
+		}
+	}
+
+	// The samples of the backlog cover its 3 minutes, the first taken within
+	// a resolution of their start, and acme grew by 60 atoms; the system
+	// tenant is not watched
+	if len(changed) != 2 {
+		t.Fatalf("expected the backlog and explosion alerts, got %v", changed)
+	}
+	firing := wd.Firing()
+	if len(firing) != 2 || firing[0].Name != "Backlog" || firing[0].Subject != "erebus/engine" || firing[1].Subject != "erebus/tenant/acme" {
+		t.Errorf("unexpected firing alerts: %+v", firing)
+	}
+	if firing[0].Severity != "critical" || firing[1].Severity != "warning" || firing[0].Fingerprint == "" {
+		t.Errorf("expected the rules' severities and fingerprints, got %+v", firing)
+	}
+
+	// Firing alerts are not raised again, and resolve once the metric recovers
+	now := start.Add(3 * time.Minute)
+	history.Record(trends.EngineScope, now, map[string]float64{"events.pending": 500})
+	if changed := wd.Evaluate(now, []string{"acme"}); len(changed) != 0 {
+		t.Errorf("expected no change while firing, got %v", changed)
+	}
+	now = now.Add(time.Minute)
+	history.Record(trends.EngineScope, now, map[string]float64{"events.pending": 0})
+	changed = wd.Evaluate(now, nil)
+	if len(changed) != 2 || changed[0].Status != incidents.AlertResolved || changed[1].Status != incidents.AlertResolved {
+		t.Fatalf("expected both alerts resolved, the tenant's as it is gone, got %+v", changed)
+	}
+	if changed[0].Fingerprint != firing[0].Fingerprint {
+		t.Errorf("expected the resolution to keep the alert's fingerprint")
+	}
+	if stats := wd.GetStats(); stats["raised"] != int64(2) || stats["resolved"] != int64(2) || stats["firing"] != 0 {
+		t.Errorf("unexpected stats: %v", stats)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	config := Config{}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("expected the default rules valid: %v", err)
+	}
+	if len(config.Rules) != len(DefaultRules()) || config.SystemTenant != DefaultSystemTenant {
+		t.Errorf("expected the defaults filled in, got %+v", config)
+	}
+
+	invalid := []Config{
+		{Rules: []Rule{{Name: "a", Metric: "m", Condition: "below"}}},
+		{Rules: []Rule{{Name: "a", Metric: "m", Condition: ConditionIncrease}}},
+		{Rules: []Rule{{Name: "a", Metric: "m", Condition: ConditionAbove}, {Name: "a", Metric: "n", Condition: ConditionAbove}}},
+		{Channels: []Channel{{Type: ChannelSlack}}},
+	}
+	for _, c := range invalid {
+		if err := c.Validate(); err == nil {
+			t.Errorf("expected %+v rejected", c)
+		}
+	}
+}
+
+func TestNotify(t *testing.T) {
+	var webhook map[string][]incidents.Alert
+	var slack map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/webhook":
+			json.NewDecoder(r.Body).Decode(&webhook)
+		case "/slack":
+			json.NewDecoder(r.Body).Decode(&slack)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	wd, err := New(Config{Channels: []Channel{
+		{Type: ChannelWebhook, URL: server.URL + "/webhook"},
+		{Type: ChannelSlack, URL: server.URL + "/slack", MinSeverity: "critical"},
+		{Type: ChannelWebhook, URL: server.URL + "/broken"},
+	}}, trends.NewStore(trends.DefaultConfig()))
+	if err != nil {
+		t.Fatalf("failed to create watchdog: %v", err)
+	}
+
+	alerts := []incidents.Alert{
+		{Name: "AgentErrorSpike", Subject: "erebus/engine", Severity: "critical", Status: incidents.AlertFiring, Annotations: map[string]string{"value": "42"}},
+		{Name: "TenantAtomExplosion", Subject: "erebus/tenant/acme", Severity: "warning", Status: incidents.AlertResolved},
+	}
+	deliveries := wd.Notify(context.Background(), alerts)
+	if len(deliveries) != 3 || deliveries[0].Alerts != 2 || deliveries[1].Alerts != 1 || deliveries[2].Error == "" {
+		t.Fatalf("unexpected deliveries: %+v", deliveries)
+	}
+	if len(webhook["alerts"]) != 2 {
+		t.Errorf("expected both alerts posted to the webhook, got %v", webhook)
+	}
+	if slack["text"] != "[FIRING] AgentErrorSpike on erebus/engine (critical), value 42" {
+		t.Errorf("expected only the critical alert sent to slack, got %q", slack["text"])
+	}
+	if failures := wd.Failures(); len(failures) != 1 || failures[0].URL != server.URL+"/broken" {
+		t.Errorf("expected the failed delivery kept, got %+v", failures)
+	}
+}
@@ -0,0 +1,134 @@
+package cognitive
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/acl"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/events"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/notifications"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/watches"
+)
+
+// AddWatch subscribes a tenant to an atom, or to the atoms matching a
+// pattern. The tenant's notification channels are told when a watched
+// atom's truth or attention value crosses one of the watch's thresholds,
+// which are checked every Config.Watches.Interval, and, with on_delete,
+// when it is deleted.
+func (ce *CognitiveEngine) AddWatch(tenantID string, w watches.Watch, p acl.Principal) (watches.Watch, error) {
+	if w.AtomID != "" {
+		if _, err := ce.GetAtom(w.AtomID, tenantID); err != nil {
+			return watches.Watch{}, err
+		}
+	}
+	w.CreatedBy = p.User
+	added, err := ce.watches.Add(tenantID, w)
+	if err != nil {
+		return watches.Watch{}, err
+	}
+	ce.checkWatch(tenantID, added)
+	return added, nil
+}
+
+// GetWatch returns a watch of a tenant
+func (ce *CognitiveEngine) GetWatch(tenantID, id string) (watches.Watch, error) {
+	return ce.watches.Get(tenantID, id)
+}
+
+// ListWatches returns a tenant's watches
+func (ce *CognitiveEngine) ListWatches(tenantID string) []watches.Watch {
+	return ce.watches.List(tenantID)
+}
+
+// DeleteWatch removes a watch of a tenant
+func (ce *CognitiveEngine) DeleteWatch(tenantID, id string) error {
+	return ce.watches.Delete(tenantID, id)
+}
+
+// runWatches checks every tenant's watches each interval until the engine
+// is closed
+func (ce *CognitiveEngine) runWatches(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ce.done:
+			return
+		case <-ticker.C:
+			for _, tenantID := range ce.watches.Tenants() {
+				ce.checkWatches(tenantID)
+			}
+		}
+	}
+}
+
+// checkWatches checks a tenant's watches and notifies the thresholds
+// crossed, returning how many
+func (ce *CognitiveEngine) checkWatches(tenantID string) int {
+	fired := 0
+	for _, w := range ce.watches.List(tenantID) {
+		fired += ce.checkWatch(tenantID, w)
+	}
+	return fired
+}
+
+// checkWatch checks one watch against the current state of its atoms
+func (ce *CognitiveEngine) checkWatch(tenantID string, w watches.Watch) int {
+	var atoms []atomspace.Atom
+	if w.AtomID != "" {
+		if atom, err := ce.GetAtom(w.AtomID, tenantID); err == nil {
+			atoms = []atomspace.Atom{atom}
+		}
+	} else {
+		q := *w.Pattern
+		if q.Limit == 0 || q.Limit > watches.MaxPatternAtoms {
+			q.Limit = watches.MaxPatternAtoms
+		}
+		atoms, _ = ce.FindAtoms(tenantID, q)
+	}
+	firings := ce.watches.Check(tenantID, w.ID, atoms)
+	for _, f := range firings {
+		ce.notifyWatch(tenantID, f)
+	}
+	return len(firings)
+}
+
+// watchDeletions notifies the watches following deleted atoms
+func (ce *CognitiveEngine) watchDeletions(event events.Event) {
+	if event.Atom == nil {
+		return
+	}
+	for _, f := range ce.watches.Deleted(event.TenantID, event.Atom) {
+		ce.notifyWatch(event.TenantID, f)
+	}
+}
+
+// notifyWatch tells a tenant a watched atom crossed a threshold or was
+// deleted
+func (ce *CognitiveEngine) notifyWatch(tenantID string, f watches.Firing) {
+	name := f.AtomName
+	if name == "" {
+		name = f.AtomID
+	}
+	n := notifications.Notification{
+		TenantID: tenantID,
+		Topic:    notifications.TopicAtomWatch,
+		Severity: f.Watch.Severity,
+		Channel:  f.Watch.Channel,
+		Title:    fmt.Sprintf("Atom %s was deleted", name),
+		Fields:   map[string]string{"watch_id": f.Watch.ID, "atom_id": f.AtomID},
+	}
+	if n.Severity == "" {
+		n.Severity = "warning"
+	}
+	if t := f.Threshold; t != nil {
+		value := strconv.FormatFloat(f.Value, 'g', 4, 64)
+		n.Title = fmt.Sprintf("Atom %s %s is %s %s", name, t.Measure, t.Direction, strconv.FormatFloat(t.Value, 'g', 4, 64))
+		n.Message = fmt.Sprintf("%s is now %s", t.Measure, value)
+		n.Fields["measure"] = t.Measure
+		n.Fields["value"] = value
+	}
+	ce.notify(n)
+}
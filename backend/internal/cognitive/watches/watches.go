@@ -0,0 +1,328 @@
+package watches
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+// MaxWatches is the number of watches a tenant may hold
+const MaxWatches = 1000
+
+// MaxPatternAtoms bounds the atoms a pattern watch follows
+const MaxPatternAtoms = 1000
+
+// Config configures atom watches
+type Config struct {
+	Interval time.Duration // How often watched atoms are checked; 0 only notifies deletions
+}
+
+// DefaultConfig checks watched atoms every 30 seconds
+func DefaultConfig() Config {
+	return Config{Interval: 30 * time.Second}
+}
+
+// Measures of an atom a threshold applies to
+const (
+	MeasureStrength   = "strength"
+	MeasureConfidence = "confidence"
+	MeasureSTI        = "sti"
+	MeasureLTI        = "lti"
+)
+
+// Directions in which a threshold is crossed
+const (
+	DirectionAbove = "above"
+	DirectionBelow = "below"
+)
+
+// Threshold is crossed when a measure of a watched atom goes above or
+// below Value, having not been before
+type Threshold struct {
+	Measure   string  `json:"measure"`
+	Direction string  `json:"direction"`
+	Value     float64 `json:"value"`
+}
+
+// beyond reports whether a measure is past the threshold
+func (t *Threshold) beyond(value float64) bool {
+	if t.Direction == DirectionAbove {
+		return value > t.Value
+	}
+	return value < t.Value
+}
+
+// measure returns the measure of an atom a threshold applies to
+func measure(atom atomspace.Atom, name string) float64 {
+	switch name {
+	case MeasureStrength:
+		return atom.GetTruthValue().Strength
+	case MeasureConfidence:
+		return atom.GetTruthValue().Confidence
+	case MeasureSTI:
+		return float64(atom.GetAttentionValue().STI)
+	default:
+		return float64(atom.GetAttentionValue().LTI)
+	}
+}
+
+// Watch subscribes to an atom, or to the atoms matching a pattern, and
+// notifies when their values cross its thresholds or, with OnDelete, when
+// they are deleted
+type Watch struct {
+	ID         string           `json:"id"`
+	AtomID     string           `json:"atom_id,omitempty"`
+	Pattern    *atomspace.Query `json:"pattern,omitempty"` // Type, name and labels of the atoms watched
+	Thresholds []Threshold      `json:"thresholds,omitempty"`
+	OnDelete   bool             `json:"on_delete"`
+	Channel    string           `json:"channel,omitempty"`  // Notification channel; every channel taking the topic if empty
+	Severity   string           `json:"severity,omitempty"` // Of the notifications; warning if empty
+	CreatedBy  string           `json:"created_by,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	Fired      int64            `json:"fired"`
+	LastFired  time.Time        `json:"last_fired,omitempty"`
+}
+
+// Validate checks a watch
+func (w *Watch) Validate() error {
+	if (w.AtomID == "") == (w.Pattern == nil) {
+		return fmt.Errorf("a watch needs either an atom_id or a pattern")
+	}
+	if w.Pattern != nil && w.Pattern.Type == nil && w.Pattern.Name == "" && len(w.Pattern.Labels) == 0 && w.Pattern.IDs == nil {
+		return fmt.Errorf("a pattern needs a type, name, labels or IDs")
+	}
+	if len(w.Thresholds) == 0 && !w.OnDelete {
+		return fmt.Errorf("a watch needs thresholds or on_delete")
+	}
+	for i, t := range w.Thresholds {
+		switch t.Measure {
+		case MeasureStrength, MeasureConfidence, MeasureSTI, MeasureLTI:
+		default:
+			return fmt.Errorf("threshold %d: unknown measure %q", i, t.Measure)
+		}
+		if t.Direction != DirectionAbove && t.Direction != DirectionBelow {
+			return fmt.Errorf("threshold %d: direction must be %s or %s", i, DirectionAbove, DirectionBelow)
+		}
+	}
+	return nil
+}
+
+// Firing is a watched atom crossing a threshold, or being deleted
+type Firing struct {
+	Watch     Watch
+	AtomID    string
+	AtomName  string
+	Threshold *Threshold // Nil when the atom was deleted
+	Value     float64    // Of the threshold's measure
+}
+
+// watched is a watch with what it last saw of each of its atoms
+type watched struct {
+	Watch
+	seen map[string][]bool // atomID -> whether each threshold was crossed
+}
+
+func (w *watched) fired(now time.Time) Watch {
+	w.Fired++
+	w.LastFired = now
+	return w.clone()
+}
+
+func (w *Watch) clone() Watch {
+	c := *w
+	c.Thresholds = append([]Threshold(nil), w.Thresholds...)
+	if w.Pattern != nil {
+		pattern := *w.Pattern
+		c.Pattern = &pattern
+	}
+	return c
+}
+
+// Manager keeps each tenant's watches
+type Manager struct {
+	watches map[string]map[string]*watched // tenantID -> ID -> watch
+	seq     int64
+	fired   int64
+	mu      sync.Mutex
+}
+
+// NewManager creates a manager without watches
+func NewManager() *Manager {
+	return &Manager{watches: make(map[string]map[string]*watched)}
+}
+
+// Add adds a watch for a tenant and returns it with its ID. Its atoms'
+// values are taken as seen from their first check, so a threshold already
+// crossed does not notify.
+func (m *Manager) Add(tenantID string, w Watch) (Watch, error) {
+	if err := w.Validate(); err != nil {
+		return Watch{}, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.watches[tenantID]) >= MaxWatches {
+		return Watch{}, fmt.Errorf("tenant %s has %d watches", tenantID, MaxWatches)
+	}
+	if m.watches[tenantID] == nil {
+		m.watches[tenantID] = make(map[string]*watched)
+	}
+	m.seq++
+	w.ID = fmt.Sprintf("watch-%d", m.seq)
+	w.CreatedAt = time.Now()
+	w.Fired, w.LastFired = 0, time.Time{}
+	entry := &watched{Watch: w.clone(), seen: make(map[string][]bool)}
+	m.watches[tenantID][w.ID] = entry
+	return entry.clone(), nil
+}
+
+// Get returns a watch of a tenant
+func (m *Manager) Get(tenantID, id string) (Watch, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, exists := m.watches[tenantID][id]
+	if !exists {
+		return Watch{}, fmt.Errorf("watch %s not found", id)
+	}
+	return w.clone(), nil
+}
+
+// List returns a tenant's watches in the order they were added
+func (m *Manager) List(tenantID string) []Watch {
+	m.mu.Lock()
+	list := make([]Watch, 0, len(m.watches[tenantID]))
+	for _, w := range m.watches[tenantID] {
+		list = append(list, w.clone())
+	}
+	m.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// Delete removes a watch of a tenant
+func (m *Manager) Delete(tenantID, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.watches[tenantID][id]; !exists {
+		return fmt.Errorf("watch %s not found", id)
+	}
+	delete(m.watches[tenantID], id)
+	if len(m.watches[tenantID]) == 0 {
+		delete(m.watches, tenantID)
+	}
+	return nil
+}
+
+// Purge removes a tenant's watches, returning how many
+func (m *Manager) Purge(tenantID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	removed := len(m.watches[tenantID])
+	delete(m.watches, tenantID)
+	return removed
+}
+
+// Tenants returns the tenants holding watches
+func (m *Manager) Tenants() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tenants := make([]string, 0, len(m.watches))
+	for tenantID := range m.watches {
+		tenants = append(tenants, tenantID)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// Check compares the current atoms of a watch with what it saw last, and
+// returns the thresholds they crossed since. An atom watch whose atom is
+// no longer among them was deleted; a pattern watch forgets the atoms that
+// no longer match.
+func (m *Manager) Check(tenantID, id string, atoms []atomspace.Atom) []Firing {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, exists := m.watches[tenantID][id]
+	if !exists {
+		return nil
+	}
+
+	var firings []Firing
+	present := make(map[string]bool, len(atoms))
+	for _, atom := range atoms {
+		present[atom.GetID()] = true
+		crossed := make([]bool, len(w.Thresholds))
+		before, seen := w.seen[atom.GetID()]
+		for i := range w.Thresholds {
+			t := &w.Thresholds[i]
+			value := measure(atom, t.Measure)
+			crossed[i] = t.beyond(value)
+			if seen && crossed[i] && !before[i] {
+				threshold := *t
+				firings = append(firings, Firing{AtomID: atom.GetID(), AtomName: atom.GetName(), Threshold: &threshold, Value: value})
+			}
+		}
+		w.seen[atom.GetID()] = crossed
+	}
+	for atomID := range w.seen {
+		if present[atomID] {
+			continue
+		}
+		delete(w.seen, atomID)
+		if w.AtomID != "" && w.OnDelete {
+			firings = append(firings, Firing{AtomID: atomID})
+		}
+	}
+	for i := range firings {
+		firings[i].Watch = w.fired(now)
+	}
+	m.fired += int64(len(firings))
+	return firings
+}
+
+// Deleted returns the firings of the watches of a tenant that followed a
+// deleted atom and notify deletions
+func (m *Manager) Deleted(tenantID string, atom atomspace.Atom) []Firing {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var firings []Firing
+	for _, w := range m.watches[tenantID] {
+		if _, seen := w.seen[atom.GetID()]; !seen {
+			continue
+		}
+		delete(w.seen, atom.GetID())
+		if w.OnDelete {
+			firings = append(firings, Firing{Watch: w.fired(now), AtomID: atom.GetID(), AtomName: atom.GetName()})
+		}
+	}
+	m.fired += int64(len(firings))
+	return firings
+}
+
+// GetStats returns the number of watches and of their firings
+func (m *Manager) GetStats() map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	watches, atoms := 0, 0
+	for _, tenant := range m.watches {
+		for _, w := range tenant {
+			watches++
+			atoms += len(w.seen)
+		}
+	}
+	return map[string]interface{}{
+		"watches":       watches,
+		"watched_atoms": atoms,
+		"fired":         m.fired,
+	}
+}
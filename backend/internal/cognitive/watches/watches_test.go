@@ -0,0 +1,78 @@
+package watches
+
+import (
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+)
+
+func TestCheck(t *testing.T) {
+	m := NewManager()
+	concept := atomspace.ConceptNodeType
+	for _, w := range []Watch{
+		{OnDelete: true},
+		{AtomID: "a", Pattern: &atomspace.Query{Type: &concept}, OnDelete: true},
+		{Pattern: &atomspace.Query{}, OnDelete: true},
+		{AtomID: "a"},
+		{AtomID: "a", Thresholds: []Threshold{{Measure: "load", Direction: DirectionAbove}}},
+		{AtomID: "a", Thresholds: []Threshold{{Measure: MeasureSTI, Direction: "over"}}},
+	} {
+		if _, err := m.Add("t", w); err == nil {
+			t.Errorf("expected %+v rejected", w)
+		}
+	}
+
+	atom := atomspace.NewNode("a", "web-1", "t", concept)
+	atom.SetTruthValue(atomspace.TruthValue{Strength: 0.9, Confidence: 0.9})
+	w, err := m.Add("t", Watch{
+		AtomID:     "a",
+		Thresholds: []Threshold{{Measure: MeasureStrength, Direction: DirectionBelow, Value: 0.5}},
+		OnDelete:   true,
+	})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	// The first check only records the values seen
+	if firings := m.Check("t", w.ID, []atomspace.Atom{atom}); len(firings) != 0 {
+		t.Errorf("expected no firing on the first check, got %+v", firings)
+	}
+	atom.SetTruthValue(atomspace.TruthValue{Strength: 0.3, Confidence: 0.9})
+	firings := m.Check("t", w.ID, []atomspace.Atom{atom})
+	if len(firings) != 1 || firings[0].Value != 0.3 || firings[0].Threshold.Value != 0.5 || firings[0].Watch.Fired != 1 {
+		t.Fatalf("expected the strength falling below 0.5, got %+v", firings)
+	}
+	// A threshold stays crossed until the value is back
+	if firings := m.Check("t", w.ID, []atomspace.Atom{atom}); len(firings) != 0 {
+		t.Errorf("expected no firing while below, got %+v", firings)
+	}
+
+	// A deleted atom is notified once, from its event or a check
+	if firings := m.Deleted("t", atom); len(firings) != 1 || firings[0].Threshold != nil {
+		t.Errorf("expected the deletion, got %+v", firings)
+	}
+	if firings := m.Check("t", w.ID, nil); len(firings) != 0 {
+		t.Errorf("expected the deletion notified once, got %+v", firings)
+	}
+
+	pattern, _ := m.Add("t", Watch{Pattern: &atomspace.Query{Type: &concept}, Thresholds: []Threshold{{Measure: MeasureSTI, Direction: DirectionAbove, Value: 50}}})
+	m.Check("t", pattern.ID, []atomspace.Atom{atom})
+	atom.SetAttentionValue(atomspace.AttentionValue{STI: 80})
+	if firings := m.Check("t", pattern.ID, []atomspace.Atom{atom}); len(firings) != 1 || firings[0].AtomName != "web-1" {
+		t.Errorf("expected the STI rising above 50, got %+v", firings)
+	}
+	// Atoms leaving a pattern are forgotten, not notified as deleted
+	if firings := m.Check("t", pattern.ID, nil); len(firings) != 0 {
+		t.Errorf("expected no firing, got %+v", firings)
+	}
+
+	if stats := m.GetStats(); stats["watches"] != 2 || stats["fired"] != int64(3) {
+		t.Errorf("stats = %v", stats)
+	}
+	if list := m.List("other"); len(list) != 0 {
+		t.Errorf("another tenant sees watches %v", list)
+	}
+	if removed := m.Purge("t"); removed != 2 || len(m.Tenants()) != 0 {
+		t.Errorf("Purge = %d", removed)
+	}
+}
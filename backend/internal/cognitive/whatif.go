@@ -0,0 +1,187 @@
+package cognitive
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/impact"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/pipeline"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/slo"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/traces"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/whatif"
+)
+
+// Simulate applies hypothetical changes to a scratch overlay over a
+// tenant's space, runs the requested rules and pipelines in it and reports
+// the predicted outcome: the atoms at risk, the SLOs of impacted services
+// and the atoms that would be created or modified. The tenant's space is
+// never modified.
+func (ce *CognitiveEngine) Simulate(ctx context.Context, tenantID string, req whatif.Request) (*whatif.Report, error) {
+	ce.mu.RLock()
+	_, initialized := ce.inferenceEngines[tenantID]
+	ce.mu.RUnlock()
+	if !initialized {
+		return nil, fmt.Errorf("tenant %s not initialized", tenantID)
+	}
+	if req.Impact.Relations == nil {
+		req.Impact.Relations = ce.ImpactRelations(tenantID)
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	base := &tenantAtomSpaceWrapper{engine: ce, tenantID: tenantID}
+	space := atomspace.NewOverlay(base, 1)
+	defer space.Close()
+
+	report := &whatif.Report{
+		Changes:   make([]whatif.Applied, 0, len(req.Changes)),
+		Pipelines: make([]whatif.PipelineResult, 0, len(req.Pipelines)),
+	}
+	for i, change := range req.Changes {
+		applied, err := whatif.Apply(space, tenantID, change)
+		if err != nil {
+			return nil, fmt.Errorf("change %d: %w", i, err)
+		}
+		report.Changes = append(report.Changes, applied)
+	}
+
+	// Impact propagates over the topology as it was, including what the
+	// changes removed
+	atoms := space.QueryAtoms(tenantID, nil)
+	for _, id := range space.HiddenAtomIDs() {
+		if atom, err := base.GetAtom(id, tenantID); err == nil {
+			atoms = append(atoms, atom)
+		}
+	}
+	graph := impact.NewGraph(atoms, req.Impact.Relations)
+	results := make([]*impact.Result, 0)
+	losses := make([]float64, 0)
+	for _, applied := range report.Changes {
+		if applied.Root == "" {
+			continue
+		}
+		result, err := graph.Analyze(applied.Root, req.Impact)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+		losses = append(losses, applied.Loss())
+	}
+	report.AtRisk = whatif.MergeImpacts(results, losses, req.Impact.MinConfidence)
+
+	services := make(map[string]bool)
+	for _, atom := range atoms {
+		if link, ok := atom.(*atomspace.Link); ok && link.GetType() == atomspace.InheritanceLinkType && len(link.GetOutgoing()) == 2 &&
+			link.GetOutgoing()[1].GetName() == traces.ServiceConcept {
+			services[link.GetOutgoing()[0].GetName()] = true
+		}
+	}
+	confidence := make(map[string]float64)
+	report.AtRiskServices = make([]impact.Impacted, 0)
+	for _, imp := range report.AtRisk {
+		confidence[imp.Name] = imp.Confidence
+		if services[imp.Name] || strings.HasPrefix(imp.Name, traces.ServicePrefix) {
+			report.AtRiskServices = append(report.AtRiskServices, imp)
+		}
+	}
+	for _, applied := range report.Changes {
+		if applied.Root != "" && applied.Loss() > confidence[applied.Root] {
+			confidence[applied.Root] = applied.Loss()
+		}
+	}
+
+	now := time.Now()
+	report.Violations = make([]whatif.Violation, 0)
+	for _, s := range ce.sloRegistry.List(tenantID) {
+		c := confidence[s.Service]
+		if c == 0 || c < req.ViolationThreshold {
+			continue
+		}
+		status := slo.Evaluate(s, ce.timeSeries.Samples(tenantID, s.Series), now)
+		report.Violations = append(report.Violations, whatif.Violation{
+			SLO:             s.Name,
+			Service:         s.Service,
+			Confidence:      c,
+			BudgetRemaining: status.BudgetRemaining,
+		})
+	}
+
+	rules, _ := whatif.Rules(req.Rules)
+	inferenceEngine := inference.NewInferenceEngine(space, 1)
+	defer inferenceEngine.Close()
+	inferenceEngine.OnWork(ce.meterInference)
+	for _, rule := range rules {
+		inferenceEngine.AddRule(rule)
+	}
+	if len(rules) > 0 {
+		if _, err := inferenceEngine.RunInference(ctx, tenantID, req.MaxIterations); err != nil {
+			return nil, err
+		}
+	}
+
+	// Pipelines get no scheduler and no time series store, so they cannot
+	// reach live agents or samples
+	sc := pipeline.StageContext{TenantID: tenantID, AtomSpace: space, Inference: inferenceEngine, Attention: ce.attentionParams.Params}
+	for i, spec := range req.Pipelines {
+		report.Pipelines = append(report.Pipelines, ce.simulatePipeline(ctx, sc, fmt.Sprintf("whatif-%d", i), spec))
+	}
+
+	for _, atom := range space.LocalAtoms(tenantID) {
+		original, err := base.GetAtom(atom.GetID(), tenantID)
+		switch {
+		case err != nil:
+			report.NewAtoms = append(report.NewAtoms, atom)
+		case original.GetTruthValue() != atom.GetTruthValue():
+			report.ModifiedAtoms = append(report.ModifiedAtoms, atom)
+		}
+	}
+	report.RemovedAtomIDs = space.HiddenAtomIDs()
+	return report, nil
+}
+
+// simulatePipeline builds a pipeline against a scratch space and runs it
+// once
+func (ce *CognitiveEngine) simulatePipeline(ctx context.Context, sc pipeline.StageContext, id string, spec pipeline.PipelineSpec) whatif.PipelineResult {
+	result := whatif.PipelineResult{Name: spec.Name}
+
+	p := pipeline.NewPipeline(id, spec.Name, sc.TenantID)
+	p.SetRetryPolicy(ce.stageRetryPolicy)
+	if spec.Retry != nil {
+		p.SetRetryPolicy(spec.Retry.Policy())
+	}
+	if spec.InputType != "" {
+		if err := p.SetInputType(spec.InputType); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+	}
+	for i, stageSpec := range spec.Stages {
+		stage, err := ce.stageRegistry.Build(sc, stageSpec)
+		if err == nil {
+			err = p.AddStage(stage)
+		}
+		if err != nil {
+			result.Error = fmt.Sprintf("stage %d: %v", i, err)
+			return result
+		}
+	}
+
+	if err := p.Validate(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	output, err := p.Execute(ctx, nil)
+	if err != nil {
+		result.Error = err.Error()
+	}
+	if atoms, ok := output.([]atomspace.Atom); ok {
+		result.Atoms = len(atoms)
+	}
+	return result
+}
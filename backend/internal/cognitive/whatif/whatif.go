@@ -0,0 +1,363 @@
+package whatif
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/impact"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/inference"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/pipeline"
+)
+
+// ChangeType is the kind of a hypothetical change
+type ChangeType string
+
+const (
+	ChangeRemove      ChangeType = "remove"       // Remove an atom and every link referencing it
+	ChangeScale       ChangeType = "scale"        // Scale a workload, losing capacity
+	ChangeSetTruth    ChangeType = "set_truth"    // Set the truth value of an atom
+	ChangeAddRelation ChangeType = "add_relation" // Add predicate(args...)
+)
+
+// Change is one hypothetical change of a simulation. Targets and
+// arguments are atom IDs or concept names.
+type Change struct {
+	Type       ChangeType `json:"type"`
+	Target     string     `json:"target,omitempty"`
+	Replicas   *int       `json:"replicas,omitempty"`   // scale: replicas after the change
+	Current    int        `json:"current,omitempty"`    // scale: replicas before the change
+	Strength   *float64   `json:"strength,omitempty"`   // set_truth and add_relation
+	Confidence *float64   `json:"confidence,omitempty"` // set_truth and add_relation
+	Predicate  string     `json:"predicate,omitempty"`  // add_relation
+	Args       []string   `json:"args,omitempty"`       // add_relation
+}
+
+// Validate checks a change
+func (c *Change) Validate() error {
+	switch c.Type {
+	case ChangeRemove:
+		if c.Target == "" {
+			return fmt.Errorf("remove requires a target")
+		}
+	case ChangeScale:
+		if c.Target == "" || c.Replicas == nil || *c.Replicas < 0 {
+			return fmt.Errorf("scale requires a target and a non-negative replica count")
+		}
+		if *c.Replicas > 0 && c.Current <= 0 {
+			return fmt.Errorf("scaling %s to %d replicas requires the current replica count", c.Target, *c.Replicas)
+		}
+	case ChangeSetTruth:
+		if c.Target == "" || (c.Strength == nil && c.Confidence == nil) {
+			return fmt.Errorf("set_truth requires a target and a strength or confidence")
+		}
+	case ChangeAddRelation:
+		if c.Predicate == "" || len(c.Args) == 0 {
+			return fmt.Errorf("add_relation requires a predicate and arguments")
+		}
+	default:
+		return fmt.Errorf("unknown change type: %q", c.Type)
+	}
+	for _, v := range []*float64{c.Strength, c.Confidence} {
+		if v != nil && (*v < 0 || *v > 1) {
+			return fmt.Errorf("truth values must be between 0 and 1")
+		}
+	}
+	return nil
+}
+
+// Loss is the share of the target's capacity a change takes away
+func (c *Change) Loss() float64 {
+	switch c.Type {
+	case ChangeRemove:
+		return 1
+	case ChangeScale:
+		if *c.Replicas == 0 {
+			return 1
+		}
+		return math.Max(0, 1-float64(*c.Replicas)/float64(c.Current))
+	}
+	return 0
+}
+
+// Applied is a change as applied to the scratch space
+type Applied struct {
+	Change
+	Root    string   `json:"root,omitempty"` // Name of the atom the change impacts, if any
+	AtomIDs []string `json:"atom_ids"`       // Atoms removed, updated or added
+}
+
+// Violation is an SLO predicted to be violated by a simulation
+type Violation struct {
+	SLO             string  `json:"slo"`
+	Service         string  `json:"service"`
+	Confidence      float64 `json:"confidence"`       // Confidence the service is impacted
+	BudgetRemaining float64 `json:"budget_remaining"` // Error budget left before the change
+}
+
+// PipelineResult is the outcome of a pipeline run in the scratch space
+type PipelineResult struct {
+	Name  string `json:"name"`
+	Atoms int    `json:"atoms"`
+	Error string `json:"error,omitempty"`
+}
+
+// Request describes a simulation
+type Request struct {
+	Changes            []Change                `json:"changes"`
+	Rules              []string                `json:"rules"` // Inference rules run after the changes
+	MaxIterations      int                     `json:"max_iterations"`
+	Pipelines          []pipeline.PipelineSpec `json:"pipelines"` // Pipelines run after the rules
+	Impact             impact.Options          `json:"impact"`
+	ViolationThreshold float64                 `json:"violation_threshold"` // Impact confidence at which SLOs count as violated
+}
+
+// DefaultRequest returns a request without changes using the default
+// options
+func DefaultRequest() Request {
+	return Request{
+		MaxIterations:      5,
+		Impact:             impact.DefaultOptions(),
+		ViolationThreshold: 0.5,
+	}
+}
+
+// Validate checks a request
+func (r *Request) Validate() error {
+	if len(r.Changes) == 0 {
+		return fmt.Errorf("at least one change is required")
+	}
+	for i := range r.Changes {
+		if err := r.Changes[i].Validate(); err != nil {
+			return fmt.Errorf("change %d: %w", i, err)
+		}
+	}
+	if _, err := Rules(r.Rules); err != nil {
+		return err
+	}
+	for _, spec := range r.Pipelines {
+		for _, stage := range spec.Stages {
+			if stage.Kind == "agent-execution" {
+				return fmt.Errorf("pipeline %s: agent-execution stages cannot run in a simulation", spec.Name)
+			}
+		}
+	}
+	if r.ViolationThreshold < 0 || r.ViolationThreshold > 1 {
+		return fmt.Errorf("violation_threshold must be between 0 and 1")
+	}
+	return r.Impact.Validate()
+}
+
+// Report is the predicted outcome of a simulation
+type Report struct {
+	Changes        []Applied         `json:"changes"`
+	AtRisk         []impact.Impacted `json:"at_risk"`
+	AtRiskServices []impact.Impacted `json:"at_risk_services"`
+	Violations     []Violation       `json:"violations"`
+	Pipelines      []PipelineResult  `json:"pipelines"`
+	NewAtoms       []atomspace.Atom  `json:"-"` // Atoms the simulation would create
+	ModifiedAtoms  []atomspace.Atom  `json:"-"` // Existing atoms whose truth value would change
+	RemovedAtomIDs []string          `json:"removed_atom_ids"`
+}
+
+// Rules returns the inference rules with the given names
+func Rules(names []string) ([]inference.InferenceRule, error) {
+	rules := make([]inference.InferenceRule, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case "deduction":
+			rules = append(rules, inference.NewDeductionRule())
+		case "induction":
+			rules = append(rules, inference.NewInductionRule())
+		case "abduction":
+			rules = append(rules, inference.NewAbductionRule())
+		default:
+			return nil, fmt.Errorf("unknown rule: %s", name)
+		}
+	}
+	return rules, nil
+}
+
+// resolve finds an atom by ID or concept name
+func resolve(space atomspace.AtomSpaceInterface, tenantID, target string) (atomspace.Atom, error) {
+	if atom, err := space.GetAtom(target, tenantID); err == nil {
+		return atom, nil
+	}
+	if atom, err := space.GetAtom(atomspace.GenerateAtomID(atomspace.ConceptNodeType, target, nil), tenantID); err == nil {
+		return atom, nil
+	}
+	return nil, fmt.Errorf("atom %s not found", target)
+}
+
+// Apply makes a change in a scratch space
+func Apply(space *atomspace.Overlay, tenantID string, c Change) (Applied, error) {
+	applied := Applied{Change: c, AtomIDs: make([]string, 0)}
+
+	switch c.Type {
+	case ChangeRemove:
+		target, err := resolve(space, tenantID, c.Target)
+		if err != nil {
+			return applied, err
+		}
+		applied.Root = target.GetName()
+		for _, id := range referencing(space.QueryAtoms(tenantID, nil), target.GetID()) {
+			if err := space.DeleteAtom(id, tenantID); err != nil {
+				return applied, err
+			}
+			applied.AtomIDs = append(applied.AtomIDs, id)
+		}
+
+	case ChangeScale:
+		target, err := resolve(space, tenantID, c.Target)
+		if err != nil {
+			return applied, err
+		}
+		applied.Root = target.GetName()
+		remaining := 1 - c.Loss()
+		err = space.UpdateAtom(target.GetID(), tenantID, func(a atomspace.Atom) error {
+			tv := a.GetTruthValue()
+			tv.Strength *= remaining
+			a.SetTruthValue(tv)
+			return nil
+		})
+		if err != nil {
+			return applied, err
+		}
+		applied.AtomIDs = append(applied.AtomIDs, target.GetID())
+
+	case ChangeSetTruth:
+		target, err := resolve(space, tenantID, c.Target)
+		if err != nil {
+			return applied, err
+		}
+		err = space.UpdateAtom(target.GetID(), tenantID, func(a atomspace.Atom) error {
+			tv := a.GetTruthValue()
+			if c.Strength != nil {
+				tv.Strength = *c.Strength
+			}
+			if c.Confidence != nil {
+				tv.Confidence = *c.Confidence
+			}
+			a.SetTruthValue(tv)
+			return nil
+		})
+		if err != nil {
+			return applied, err
+		}
+		applied.AtomIDs = append(applied.AtomIDs, target.GetID())
+
+	case ChangeAddRelation:
+		tv := atomspace.TruthValue{Strength: 1.0, Confidence: 1.0}
+		if c.Strength != nil {
+			tv.Strength = *c.Strength
+		}
+		if c.Confidence != nil {
+			tv.Confidence = *c.Confidence
+		}
+		pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, c.Predicate, nil), c.Predicate, tenantID, atomspace.PredicateNodeType)
+		outgoing := []atomspace.Atom{pred}
+		for _, name := range c.Args {
+			arg, err := resolve(space, tenantID, name)
+			if err != nil {
+				arg = atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, tenantID, atomspace.ConceptNodeType)
+				if err := space.AddAtom(arg); err != nil {
+					return applied, err
+				}
+				applied.AtomIDs = append(applied.AtomIDs, arg.GetID())
+			}
+			outgoing = append(outgoing, arg)
+		}
+		if _, err := space.GetAtom(pred.GetID(), tenantID); err != nil {
+			if err := space.AddAtom(pred); err != nil {
+				return applied, err
+			}
+		}
+		link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, c.Predicate, outgoing), c.Predicate, tenantID, atomspace.EvaluationLinkType, outgoing)
+		link.SetTruthValue(tv)
+		if _, err := space.GetAtom(link.GetID(), tenantID); err == nil {
+			err = space.UpdateAtom(link.GetID(), tenantID, func(a atomspace.Atom) error {
+				a.SetTruthValue(tv)
+				return nil
+			})
+			if err != nil {
+				return applied, err
+			}
+		} else if err := space.AddAtom(link); err != nil {
+			return applied, err
+		}
+		applied.AtomIDs = append(applied.AtomIDs, link.GetID())
+	}
+
+	return applied, nil
+}
+
+// referencing returns an atom and every link reaching it through outgoing
+// sets, links before the atoms they reference
+func referencing(atoms []atomspace.Atom, atomID string) []string {
+	incoming := make(map[string][]string)
+	for _, atom := range atoms {
+		if link, ok := atom.(*atomspace.Link); ok {
+			for _, out := range link.GetOutgoing() {
+				incoming[out.GetID()] = append(incoming[out.GetID()], link.GetID())
+			}
+		}
+	}
+
+	order := make([]string, 0)
+	visited := map[string]bool{atomID: true}
+	frontier := []string{atomID}
+	for len(frontier) > 0 {
+		order = append(order, frontier...)
+		var next []string
+		for _, id := range frontier {
+			for _, linkID := range incoming[id] {
+				if !visited[linkID] {
+					visited[linkID] = true
+					next = append(next, linkID)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	return order
+}
+
+// MergeImpacts combines the blast radii of the changed roots, scaling each
+// by the capacity its change takes away and keeping the most confident
+// path of every atom. Roots themselves are left out.
+func MergeImpacts(results []*impact.Result, losses []float64, minConfidence float64) []impact.Impacted {
+	roots := make(map[string]bool, len(results))
+	for _, r := range results {
+		roots[r.Source] = true
+	}
+
+	best := make(map[string]impact.Impacted)
+	for i, r := range results {
+		for _, imp := range r.Impacted {
+			imp.Confidence *= losses[i]
+			if roots[imp.Name] || imp.Confidence < minConfidence || imp.Confidence == 0 {
+				continue
+			}
+			if existing, ok := best[imp.Name]; !ok || imp.Confidence > existing.Confidence {
+				best[imp.Name] = imp
+			}
+		}
+	}
+
+	merged := make([]impact.Impacted, 0, len(best))
+	for _, imp := range best {
+		merged = append(merged, imp)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].Confidence != merged[j].Confidence {
+			return merged[i].Confidence > merged[j].Confidence
+		}
+		return merged[i].Name < merged[j].Name
+	})
+	return merged
+}
@@ -0,0 +1,129 @@
+package whatif
+
+import (
+	"testing"
+
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/impact"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/pipeline"
+)
+
+func concept(name string) *atomspace.Node {
+	return atomspace.NewNode(atomspace.GenerateAtomID(atomspace.ConceptNodeType, name, nil), name, "t", atomspace.ConceptNodeType)
+}
+
+// testBase holds runs_on(pod/web-1, node/n1)
+func testBase(t *testing.T) (*atomspace.AtomSpace, atomspace.Atom) {
+	base := atomspace.NewAtomSpace(1)
+	t.Cleanup(base.Close)
+
+	pod, node := concept("pod/web-1"), concept("node/n1")
+	pred := atomspace.NewNode(atomspace.GenerateAtomID(atomspace.PredicateNodeType, "runs_on", nil), "runs_on", "t", atomspace.PredicateNodeType)
+	outgoing := []atomspace.Atom{pred, pod, node}
+	link := atomspace.NewLink(atomspace.GenerateAtomID(atomspace.EvaluationLinkType, "runs_on", outgoing), "runs_on", "t", atomspace.EvaluationLinkType, outgoing)
+	for _, atom := range []atomspace.Atom{pod, node, pred, link} {
+		if err := base.AddAtom(atom); err != nil {
+			t.Fatalf("AddAtom failed: %v", err)
+		}
+	}
+	return base, link
+}
+
+func TestApplyRemove(t *testing.T) {
+	base, link := testBase(t)
+	space := atomspace.NewOverlay(base, 1)
+	defer space.Close()
+
+	applied, err := Apply(space, "t", Change{Type: ChangeRemove, Target: "node/n1"})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if applied.Root != "node/n1" || len(applied.AtomIDs) != 2 || applied.AtomIDs[0] != link.GetID() {
+		t.Errorf("Expected the link and the node to be removed, link first, got %+v", applied)
+	}
+	if _, err := space.GetAtom(link.GetID(), "t"); err == nil {
+		t.Error("Expected the link to be hidden in the overlay")
+	}
+	if _, err := base.GetAtom(link.GetID(), "t"); err != nil {
+		t.Error("Expected the base to keep the link")
+	}
+
+	if _, err := Apply(space, "t", Change{Type: ChangeRemove, Target: "node/n1"}); err == nil {
+		t.Error("Expected removing a removed atom to fail")
+	}
+}
+
+func TestApplyScaleAndRelation(t *testing.T) {
+	base, _ := testBase(t)
+	space := atomspace.NewOverlay(base, 1)
+	defer space.Close()
+
+	one := 1
+	applied, err := Apply(space, "t", Change{Type: ChangeScale, Target: "pod/web-1", Replicas: &one, Current: 4})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if applied.Loss() != 0.75 {
+		t.Errorf("Expected a loss of 0.75, got %v", applied.Loss())
+	}
+	pod, _ := space.GetAtom(concept("pod/web-1").GetID(), "t")
+	if pod.GetTruthValue().Strength != 0.25 {
+		t.Errorf("Expected the scaled pod to keep a quarter of its strength, got %v", pod.GetTruthValue())
+	}
+
+	applied, err = Apply(space, "t", Change{Type: ChangeAddRelation, Predicate: "runs_on", Args: []string{"pod/web-2", "node/n1"}})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if len(applied.AtomIDs) != 2 || applied.Root != "" {
+		t.Errorf("Expected the new pod and the link to be added, got %+v", applied)
+	}
+	if len(base.QueryAtoms("t", nil)) != 4 {
+		t.Error("Expected the base to be unchanged")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	zero := 0
+	req := DefaultRequest()
+	req.Changes = []Change{{Type: ChangeScale, Target: "service/cart", Replicas: &zero}}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Expected a valid request: %v", err)
+	}
+
+	two := 2
+	invalid := []Request{
+		{},
+		{Changes: []Change{{Type: "explode", Target: "x"}}},
+		{Changes: []Change{{Type: ChangeScale, Target: "x", Replicas: &two}}},
+	}
+	for i, r := range invalid {
+		if r.Validate() == nil {
+			t.Errorf("Expected request %d to be rejected", i)
+		}
+	}
+
+	req.Rules = []string{"magic"}
+	if req.Validate() == nil {
+		t.Error("Expected an unknown rule to be rejected")
+	}
+	req.Rules = nil
+	req.Pipelines = []pipeline.PipelineSpec{{Name: "p", Stages: []pipeline.StageSpec{{Kind: "agent-execution"}}}}
+	if req.Validate() == nil {
+		t.Error("Expected agent-execution stages to be rejected")
+	}
+}
+
+func TestMergeImpacts(t *testing.T) {
+	results := []*impact.Result{
+		{Source: "node/n1", Impacted: []impact.Impacted{{Name: "pod/a", Confidence: 0.9}, {Name: "service/cart", Confidence: 0.5}}},
+		{Source: "service/cart", Impacted: []impact.Impacted{{Name: "service/frontend", Confidence: 0.7}, {Name: "pod/a", Confidence: 0.6}}},
+	}
+	merged := MergeImpacts(results, []float64{1, 0.5}, 0.1)
+	if len(merged) != 2 {
+		t.Fatalf("Expected the roots to be left out, got %+v", merged)
+	}
+	if merged[0].Name != "pod/a" || merged[0].Confidence != 0.9 || merged[1].Name != "service/frontend" || merged[1].Confidence != 0.35 {
+		t.Errorf("Unexpected merge: %+v", merged)
+	}
+}
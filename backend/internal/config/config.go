@@ -3,7 +3,9 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
@@ -34,6 +36,153 @@ type Config struct {
 		JWTSecret string
 		APIKey    string
 	}
+
+	Persistence struct {
+		ValueLogDir                  string        // Per-shard logs of truth and attention values; empty disables them
+		ValueLogInterval             time.Duration // How often shards are scanned for changed values
+		ValueLogLossWindow           time.Duration // Longest a changed value stays unflushed
+		ValueLogCompactionRatio      float64       // Records per atom, plus the minimum, a log holds before it is compacted
+		ValueLogCompactionMinRecords int           // Records a log always may hold before it is compacted
+		ValueLogRetention            time.Duration // How long values stay logged once a tenant snapshot holds them
+		SnapshotDir                  string        // Tenant snapshots (*.snap) restored at startup; none if empty
+		SnapshotInterval             time.Duration // How often tenant snapshots in SnapshotDir are replaced; 0 never
+		StartupIntegrity             string        // Check of shard indices once snapshots are restored: check, repair, or none if empty
+	}
+
+	Sharding struct {
+		ReadReplicas        bool          // Serve queries from per-shard replicas
+		ReplicaSyncInterval time.Duration // How often replicas that missed changes are rebuilt
+	}
+
+	HTTP struct {
+		MaxBodyBytes      int64         // Largest request body the cognitive API accepts
+		MaxImportBytes    int64         // Largest body of bulk imports, which are decoded as they stream in
+		ReadHeaderTimeout time.Duration // Longest a client may take to send request headers
+		ReadTimeout       time.Duration // Longest a client may take to send a whole request, imports included
+		WriteTimeout      time.Duration // Longest a response may take, exports included
+		IdleTimeout       time.Duration // How long idle keep-alive connections are kept
+		HandlerTimeout    time.Duration // Longest expensive cognitive endpoints, like inference, may run; 0 is unbounded
+		DrainDelay        time.Duration // How long the server reports unready before it stops accepting requests
+		ShutdownTimeout   time.Duration // Longest running requests are waited for at shutdown
+	}
+
+	CORS struct {
+		AllowedOrigins   []string      // Origins browsers may call the API from; unset uses the environment's defaults
+		AllowedHeaders   []string      // Request headers they may send; unset uses the defaults
+		AllowCredentials bool          // Allow cookies and HTTP authentication
+		MaxAge           time.Duration // How long preflight responses are cached; 0 uses the default
+	}
+
+	Atoms struct {
+		IDScheme string // How atom IDs are derived: sha256, hash (128 bits) or ulid
+	}
+
+	Usage struct {
+		Resolution time.Duration // Width of the buckets API usage is counted in
+		Retention  time.Duration // How long API usage is kept
+	}
+
+	Stats struct {
+		HistoryResolution time.Duration // How often engine and tenant stats are sampled for their history; 0 disables it
+		HistoryRetention  time.Duration // How long sampled stats are kept
+		CacheTTL          time.Duration // How long the expensive aggregations of the stats endpoints are cached; 0 never
+	}
+
+	Decay struct {
+		Interval time.Duration // How often tenants' truth-value decay policies are applied
+	}
+
+	Watches struct {
+		Interval time.Duration // How often watched atoms are checked against their thresholds
+	}
+
+	Shares struct {
+		Secret     string        // Signs read-only share links; random per process if empty, voiding links on restart
+		DefaultTTL time.Duration // Lifetime of a share link created without one
+		MaxTTL     time.Duration // Longest lifetime of a share link
+	}
+
+	Federation struct {
+		Connections  map[string]string            // Postgres URLs federated SQL lookups may query, by connection name
+		Tenants      map[string][]string          // Tenants that may query each connection; all if a connection is not listed
+		Queries      map[string]map[string]string // SQL lookups may run by name, by connection then query name, each key bound as $1
+		AllowedHosts []string                     // Hosts federated HTTP lookups may call, e.g. cmdb.internal or *.example.com
+		Timeout      time.Duration                // Longest one lookup may take
+		MaxRows      int                          // Most rows one lookup may return
+	}
+
+	Secrets struct {
+		EnvPrefix   string        // Prefix of the variables secret://env/KEY references read, as PREFIX<TENANT>_<KEY>; the env backend is off if empty
+		FileDir     string        // Directory secret://file/KEY references read, as DIR/TENANT/KEY; off if empty
+		VaultAddr   string        // Vault server secret://vault/PATH#FIELD references read, from the KV v2 engine; off if empty
+		VaultToken  string        // Token Vault secrets are read with
+		VaultMount  string        // Mount of the KV v2 engine
+		VaultPrefix string        // Path under the mount holding a directory per tenant
+		CacheTTL    time.Duration // How long resolved secrets are reused
+	}
+
+	Connectors struct {
+		File           string        // JSON of each tenant's connectors, declared at startup; none if empty
+		Interval       time.Duration // How often connectors sync unless their spec sets an interval
+		InitialBackoff time.Duration // Delay before retrying a failing connector, doubling with each failure
+		MaxBackoff     time.Duration // Longest delay between retries of a failing connector
+	}
+
+	Watchdog struct {
+		Enabled         bool   // Raise alerts on the engine's own health from the stats history
+		SystemTenant    string // Tenant the alerts are recorded in
+		WebhookURL      string // Alerts are posted as JSON here if set
+		SlackWebhookURL string // Alerts are sent to this Slack incoming webhook if set
+		MinSeverity     string // Alerts less severe are not notified
+	}
+
+	Notifications struct {
+		Timeout      time.Duration // Of each delivery to a tenant's notification channel
+		PagerDutyURL string        // Events API v2 endpoint of PagerDuty channels
+	}
+
+	Metering struct {
+		Interval        time.Duration // How often billable usage is sampled and exported
+		CSVPath         string        // File billable events are appended to; none if empty
+		StripeSecretKey string        // Reports usage to Stripe if set
+		StripeItemsFile string        // JSON of each tenant's subscription item per event type
+	}
+
+	GitOps struct {
+		Dir      string        // Directory of manifests the engine converges to, or where Repo is checked out; none if empty
+		Repo     string        // Git repository of the manifests, fetched before each reconciliation
+		Branch   string        // Branch of the repository; its default branch if empty
+		Path     string        // Directory of the manifests within the repository
+		Interval time.Duration // How often the engine reconciles
+		Prune    bool          // Remove resources of declared tenants that no manifest declares
+	}
+
+	Leader struct {
+		Election      string        // How replicas elect the one running agents: kubernetes (a Lease) or postgres (an advisory lock on Database.URL); every replica runs them if empty
+		Name          string        // Name of the lease or lock, shared by the replicas
+		Identity      string        // Name of this replica; the hostname if empty
+		LeaseDuration time.Duration // How long a Kubernetes lease is held without renewal
+		RetryPeriod   time.Duration // How often the lease or lock is acquired or renewed
+	}
+
+	Pipelines struct {
+		Queue                     string        // Where submitted and scheduled executions wait: memory, or redis (on Redis.URL) to survive restarts and share them across replicas
+		QueueKey                  string        // Prefix of the Redis keys of the queue
+		VisibilityTimeout         time.Duration // How long a replica may go silent while running a job before another takes it over
+		PollInterval              time.Duration // How often an empty Redis queue is polled
+		ArtifactDir               string        // Where the artifacts stages attach to executions are written; kept in memory if empty
+		ArtifactMaxBytes          int64         // Largest artifact
+		ArtifactExecutionMaxBytes int64         // Most bytes of artifacts one execution may attach
+		ArtifactRetention         time.Duration // How long artifacts are kept; forever if 0
+	}
+
+	Partition struct {
+		Membership        string        // How replicas spread agents among themselves: kubernetes (a Lease per replica) or postgres (a table on Database.URL); leader election decides which runs them if empty
+		Group             string        // Name of the group of replicas sharing the agents
+		Identity          string        // Name of this replica; the hostname if empty
+		HeartbeatInterval time.Duration // How often this replica renews its membership and reads the others
+		TTL               time.Duration // How long a replica stays a member without heartbeating
+	}
 }
 
 // Load reads configuration from .env, config.yaml, env variables, and defaults
@@ -76,6 +225,98 @@ func Load() (*Config, error) {
 	viper.SetDefault("security.jwtsecret", "changeme")
 	viper.SetDefault("security.apikey", "")
 
+	viper.SetDefault("persistence.valuelogdir", "")
+	viper.SetDefault("persistence.valueloginterval", 5*time.Second)
+	viper.SetDefault("persistence.valueloglosswindow", 30*time.Second)
+	viper.SetDefault("persistence.valuelogcompactionratio", 2.0)
+	viper.SetDefault("persistence.valuelogcompactionminrecords", 1024)
+	viper.SetDefault("persistence.valuelogretention", time.Duration(0))
+	viper.SetDefault("persistence.snapshotdir", "")
+	viper.SetDefault("persistence.snapshotinterval", time.Duration(0))
+	viper.SetDefault("persistence.startupintegrity", "")
+
+	viper.SetDefault("sharding.readreplicas", false)
+	viper.SetDefault("sharding.replicasyncinterval", time.Second)
+
+	viper.SetDefault("http.maxbodybytes", 10<<20)
+	viper.SetDefault("http.maximportbytes", 4<<30)
+	viper.SetDefault("http.readheadertimeout", 10*time.Second)
+	viper.SetDefault("http.readtimeout", 10*time.Minute)
+	viper.SetDefault("http.writetimeout", 10*time.Minute)
+	viper.SetDefault("http.idletimeout", 2*time.Minute)
+	viper.SetDefault("http.handlertimeout", time.Minute)
+	viper.SetDefault("http.draindelay", 5*time.Second)
+	viper.SetDefault("http.shutdowntimeout", 30*time.Second)
+
+	viper.SetDefault("cors.allowedorigins", []string{})
+	viper.SetDefault("cors.allowedheaders", []string{})
+	viper.SetDefault("cors.allowcredentials", false)
+	viper.SetDefault("cors.maxage", time.Duration(0))
+
+	viper.SetDefault("atoms.idscheme", "sha256")
+
+	viper.SetDefault("usage.resolution", time.Minute)
+	viper.SetDefault("usage.retention", 24*time.Hour)
+	viper.SetDefault("stats.historyresolution", time.Minute)
+	viper.SetDefault("stats.historyretention", 24*time.Hour)
+	viper.SetDefault("stats.cachettl", 2*time.Second)
+	viper.SetDefault("decay.interval", time.Minute)
+	viper.SetDefault("watches.interval", 30*time.Second)
+	viper.SetDefault("shares.secret", "")
+	viper.SetDefault("shares.defaultttl", 24*time.Hour)
+	viper.SetDefault("shares.maxttl", 30*24*time.Hour)
+	viper.SetDefault("federation.connections", map[string]string{})
+	viper.SetDefault("federation.allowedhosts", []string{})
+	viper.SetDefault("federation.timeout", 10*time.Second)
+	viper.SetDefault("federation.maxrows", 10000)
+	viper.SetDefault("secrets.envprefix", "EREBUS_SECRET_")
+	viper.SetDefault("secrets.filedir", "")
+	viper.SetDefault("secrets.vaultaddr", "")
+	viper.SetDefault("secrets.vaulttoken", "")
+	viper.SetDefault("secrets.vaultmount", "secret")
+	viper.SetDefault("secrets.vaultprefix", "erebus")
+	viper.SetDefault("secrets.cachettl", 5*time.Minute)
+	viper.SetDefault("connectors.file", "")
+	viper.SetDefault("connectors.interval", 5*time.Minute)
+	viper.SetDefault("connectors.initialbackoff", 10*time.Second)
+	viper.SetDefault("connectors.maxbackoff", 10*time.Minute)
+	viper.SetDefault("watchdog.enabled", true)
+	viper.SetDefault("watchdog.systemtenant", "erebus-system")
+	viper.SetDefault("notifications.timeout", 10*time.Second)
+	viper.SetDefault("notifications.pagerdutyurl", "https://events.pagerduty.com/v2/enqueue")
+
+	viper.SetDefault("metering.interval", time.Hour)
+	viper.SetDefault("metering.csvpath", "")
+	viper.SetDefault("metering.stripesecretkey", "")
+	viper.SetDefault("metering.stripeitemsfile", "")
+
+	viper.SetDefault("gitops.dir", "")
+	viper.SetDefault("gitops.repo", "")
+	viper.SetDefault("gitops.branch", "")
+	viper.SetDefault("gitops.path", "")
+	viper.SetDefault("gitops.interval", time.Minute)
+	viper.SetDefault("gitops.prune", true)
+
+	viper.SetDefault("leader.election", "")
+	viper.SetDefault("leader.name", "erebusd")
+	viper.SetDefault("leader.identity", "")
+	viper.SetDefault("leader.leaseduration", 15*time.Second)
+	viper.SetDefault("leader.retryperiod", 2*time.Second)
+
+	viper.SetDefault("pipelines.queue", "memory")
+	viper.SetDefault("pipelines.queuekey", "erebus:pipeline-jobs")
+	viper.SetDefault("pipelines.visibilitytimeout", 30*time.Second)
+	viper.SetDefault("pipelines.pollinterval", 500*time.Millisecond)
+	viper.SetDefault("pipelines.artifactmaxbytes", 8<<20)
+	viper.SetDefault("pipelines.artifactexecutionmaxbytes", 64<<20)
+	viper.SetDefault("pipelines.artifactretention", 7*24*time.Hour)
+
+	viper.SetDefault("partition.membership", "")
+	viper.SetDefault("partition.group", "erebusd-agents")
+	viper.SetDefault("partition.identity", "")
+	viper.SetDefault("partition.heartbeatinterval", 2*time.Second)
+	viper.SetDefault("partition.ttl", 15*time.Second)
+
 	// ----------------------------
 	// Read config.yaml if exists
 	// ----------------------------
@@ -101,7 +342,49 @@ func Load() (*Config, error) {
 	}
 
 	log.Printf("App config loaded: Env=%s, Port=%s, DB=%s, Redis Enabled=%v",
-		cfg.App.Env, cfg.App.Port, cfg.Database.URL, cfg.Redis.Enabled)
+		cfg.App.Env, cfg.App.Port, redactURL(cfg.Database.URL), cfg.Redis.Enabled)
 
 	return &cfg, nil
 }
+
+// redacted replaces secrets that are set
+const redacted = "xxxxx"
+
+// Redacted returns a copy of the configuration safe to show operators:
+// secrets are replaced, and passwords removed from URLs
+func (c Config) Redacted() Config {
+	c.Database.URL = redactURL(c.Database.URL)
+	c.Redis.URL = redactURL(c.Redis.URL)
+	c.Redis.Password = redactSecret(c.Redis.Password)
+	c.Security.JWTSecret = redactSecret(c.Security.JWTSecret)
+	c.Security.APIKey = redactSecret(c.Security.APIKey)
+	c.Metering.StripeSecretKey = redactSecret(c.Metering.StripeSecretKey)
+	c.Secrets.VaultToken = redactSecret(c.Secrets.VaultToken)
+	c.Watchdog.WebhookURL = redactSecret(c.Watchdog.WebhookURL)
+	c.Watchdog.SlackWebhookURL = redactSecret(c.Watchdog.SlackWebhookURL)
+	c.Shares.Secret = redactSecret(c.Shares.Secret)
+	c.GitOps.Repo = redactURL(c.GitOps.Repo)
+	connections := make(map[string]string, len(c.Federation.Connections))
+	for name, u := range c.Federation.Connections {
+		connections[name] = redactURL(u)
+	}
+	c.Federation.Connections = connections
+	return c
+}
+
+func redactSecret(s string) string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// redactURL removes the password of a URL; values that are not URLs with
+// credentials are returned unchanged
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	return u.Redacted()
+}
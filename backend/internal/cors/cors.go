@@ -0,0 +1,173 @@
+package cors
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config configures which cross-origin browser requests are allowed
+type Config struct {
+	AllowedOrigins   []string      // Exact origins, "*" for any, or wildcard subdomains like "https://*.example.com"
+	AllowedMethods   []string      // Methods preflight requests may ask for
+	AllowedHeaders   []string      // Request headers preflight requests may ask for; "*" allows any
+	ExposedHeaders   []string      // Response headers scripts may read
+	AllowCredentials bool          // Allow cookies and HTTP authentication; requires listed origins
+	MaxAge           time.Duration // How long browsers may cache a preflight response
+}
+
+// DefaultConfig returns the configuration of an environment. Development
+// allows the usual local frontend dev servers; every other environment
+// allows no cross-origin requests until origins are configured. The
+// identity headers of the cognitive API are allowed so browsers may send
+// them.
+func DefaultConfig(env string) Config {
+	cfg := Config{
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+		AllowedHeaders: []string{"Accept", "Accept-Encoding", "Authorization", "Content-Type", "X-Request-Id", "X-Erebus-User", "X-Erebus-Roles", "X-Erebus-Key"},
+		ExposedHeaders: []string{"X-Request-Id"},
+		MaxAge:         10 * time.Minute,
+	}
+	switch strings.ToLower(env) {
+	case "dev", "development", "local":
+		cfg.AllowedOrigins = []string{
+			"http://localhost:3000",
+			"http://localhost:5173",
+			"http://localhost:8080",
+			"http://127.0.0.1:3000",
+			"http://127.0.0.1:5173",
+			"http://127.0.0.1:8080",
+		}
+	}
+	return cfg
+}
+
+// Validate checks the configuration
+func (c Config) Validate() error {
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" && c.AllowCredentials {
+			return errors.New("credentials cannot be allowed for any origin; list the origins")
+		}
+		if origin != "*" && !strings.Contains(origin, "://") {
+			return errors.New("allowed origin " + strconv.Quote(origin) + " needs a scheme, like https://")
+		}
+	}
+	if c.MaxAge < 0 {
+		return errors.New("max age cannot be negative")
+	}
+	return nil
+}
+
+// Handler returns a middleware answering the preflight requests of allowed
+// origins and marking their requests readable. Requests of other origins
+// are served without CORS headers, so browsers keep their responses from
+// scripts; their preflight requests are refused.
+func Handler(cfg Config) func(next http.Handler) http.Handler {
+	// Simple methods never need to be listed
+	methods := map[string]bool{http.MethodGet: true, http.MethodHead: true, http.MethodPost: true}
+	for _, method := range cfg.AllowedMethods {
+		methods[strings.ToUpper(method)] = true
+	}
+	headers := make(map[string]bool)
+	anyHeader := false
+	for _, header := range cfg.AllowedHeaders {
+		if header == "*" {
+			anyHeader = true
+		}
+		headers[http.CanonicalHeaderKey(header)] = true
+	}
+	allowMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	exposeHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			allowed, anyOrigin := cfg.allows(origin)
+			if !allowed {
+				if preflight {
+					http.Error(w, "origin not allowed", http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			if anyOrigin && !cfg.AllowCredentials {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if cfg.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				if exposeHeaders != "" {
+					h.Set("Access-Control-Expose-Headers", exposeHeaders)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !methods[strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))] {
+				http.Error(w, "method not allowed", http.StatusForbidden)
+				return
+			}
+			requested := r.Header.Get("Access-Control-Request-Headers")
+			for _, header := range strings.Split(requested, ",") {
+				header = strings.TrimSpace(header)
+				if header != "" && !anyHeader && !headers[http.CanonicalHeaderKey(header)] {
+					http.Error(w, "header "+header+" not allowed", http.StatusForbidden)
+					return
+				}
+			}
+			h.Set("Access-Control-Allow-Methods", allowMethods)
+			if anyHeader {
+				h.Set("Access-Control-Allow-Headers", requested)
+			} else if allowHeaders != "" {
+				h.Set("Access-Control-Allow-Headers", allowHeaders)
+			}
+			if cfg.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// allows reports whether an origin is allowed, and whether through "*"
+func (c Config) allows(origin string) (allowed, anyOrigin bool) {
+	for _, pattern := range c.AllowedOrigins {
+		switch {
+		case pattern == "*":
+			return true, true
+		case strings.EqualFold(pattern, origin):
+			return true, false
+		}
+		// https://*.example.com allows subdomains, not example.com itself
+		if scheme, host, ok := strings.Cut(pattern, "://*."); ok {
+			prefix := strings.ToLower(scheme + "://")
+			lower := strings.ToLower(origin)
+			if strings.HasPrefix(lower, prefix) && strings.HasSuffix(lower, "."+strings.ToLower(host)) &&
+				len(lower) > len(prefix)+len(host)+1 {
+				return true, false
+			}
+		}
+	}
+	return false, false
+}
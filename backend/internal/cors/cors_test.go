@@ -0,0 +1,104 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serve(cfg Config, method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+	h := Handler(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest(method, "/api/cognitive/tenants", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	cfg := DefaultConfig("dev")
+
+	w := serve(cfg, http.MethodGet, "http://localhost:5173", nil)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "http://localhost:5173" {
+		t.Errorf("expected the dev server origin allowed, got %q", got)
+	}
+	if w.Header().Get("Access-Control-Allow-Credentials") != "" {
+		t.Error("expected credentials not allowed by default")
+	}
+
+	w = serve(cfg, http.MethodOptions, "http://localhost:5173", map[string]string{
+		"Access-Control-Request-Method":  http.MethodPut,
+		"Access-Control-Request-Headers": "content-type, x-request-id, x-erebus-user, x-erebus-roles, x-erebus-key",
+	})
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Methods") == "" || w.Header().Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("expected the preflight answered, got %d %v", w.Code, w.Header())
+	}
+
+	w = serve(cfg, http.MethodOptions, "http://localhost:5173", map[string]string{
+		"Access-Control-Request-Method":  http.MethodPut,
+		"Access-Control-Request-Headers": "x-secret",
+	})
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected an unlisted header refused, got %d", w.Code)
+	}
+
+	// Production allows no origins until they are configured
+	w = serve(DefaultConfig("production"), http.MethodGet, "http://localhost:5173", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("expected the request served without CORS headers, got %d %v", w.Code, w.Header())
+	}
+	w = serve(DefaultConfig("production"), http.MethodOptions, "https://evil.example", map[string]string{
+		"Access-Control-Request-Method": http.MethodDelete,
+	})
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected the preflight refused, got %d", w.Code)
+	}
+
+	// Requests without an Origin are not cross-origin
+	if w = serve(cfg, http.MethodGet, "", nil); w.Header().Get("Vary") != "" {
+		t.Errorf("expected no CORS handling, got %v", w.Header())
+	}
+}
+
+func TestAllowedOrigins(t *testing.T) {
+	cfg := DefaultConfig("production")
+	cfg.AllowedOrigins = []string{"https://*.example.com", "https://app.erebus.dev"}
+	cfg.AllowCredentials = true
+
+	for origin, want := range map[string]bool{
+		"https://ui.example.com":      true,
+		"https://a.b.example.com":     true,
+		"https://example.com":         false,
+		"http://ui.example.com":       false,
+		"https://evilexample.com":     false,
+		"https://app.erebus.dev":      true,
+		"https://app.erebus.dev.evil": false,
+	} {
+		w := serve(cfg, http.MethodGet, origin, nil)
+		if got := w.Header().Get("Access-Control-Allow-Origin") == origin; got != want {
+			t.Errorf("origin %s allowed = %v, want %v", origin, got, want)
+		}
+		if want && w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+			t.Errorf("origin %s: expected credentials allowed", origin)
+		}
+	}
+
+	cfg.AllowedOrigins = []string{"*"}
+	if cfg.Validate() == nil {
+		t.Error("expected credentials for any origin rejected")
+	}
+	cfg.AllowCredentials = false
+	if w := serve(cfg, http.MethodGet, "https://anywhere.io", nil); w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("expected any origin allowed, got %v", w.Header())
+	}
+	if (Config{AllowedOrigins: []string{"example.com"}}).Validate() == nil {
+		t.Error("expected an origin without a scheme rejected")
+	}
+}
@@ -1,7 +1,9 @@
 package health
 
 import (
+	"encoding/json"
 	"net/http"
+	"sync"
 
 	"go.uber.org/zap"
 )
@@ -21,3 +23,106 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte("Erebus is alive 🚀"))
 }
 
+// Status is the outcome of a readiness check
+type Status string
+
+const (
+	StatusOK          Status = "ok"
+	StatusDegraded    Status = "degraded"    // Serving, but something needs attention
+	StatusUnavailable Status = "unavailable" // Not ready to serve
+)
+
+// CheckResult is reported by a readiness check
+type CheckResult struct {
+	Status  Status                 `json:"status"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Check reports the readiness of one component
+type Check func() CheckResult
+
+var (
+	checks   = make(map[string]Check)
+	checksMu sync.RWMutex
+)
+
+// RegisterCheck adds a named readiness check reported by ReadyHandler
+func RegisterCheck(name string, check Check) {
+	checksMu.Lock()
+	defer checksMu.Unlock()
+	checks[name] = check
+}
+
+// ReadyHandler runs the registered readiness checks. It responds 503 when
+// any check is unavailable; degraded checks are reported but keep the
+// service ready.
+func ReadyHandler(w http.ResponseWriter, r *http.Request) {
+	checksMu.RLock()
+	results := run(checks)
+	checksMu.RUnlock()
+	writeResults(w, results)
+}
+
+var (
+	startupChecks = make(map[string]Check)
+	started       bool
+	startupMu     sync.Mutex
+)
+
+// RegisterStartupCheck adds a named startup check reported by
+// StartupHandler
+func RegisterStartupCheck(name string, check Check) {
+	startupMu.Lock()
+	defer startupMu.Unlock()
+	startupChecks[name] = check
+}
+
+// StartupHandler runs the registered startup checks until none is
+// unavailable, responding 503 meanwhile. Startup happens once: afterwards
+// it responds ok without running the checks, so that orchestrators hand
+// over to the liveness and readiness probes.
+func StartupHandler(w http.ResponseWriter, r *http.Request) {
+	startupMu.Lock()
+	if started {
+		startupMu.Unlock()
+		writeResults(w, map[string]CheckResult{})
+		return
+	}
+	results := run(startupChecks)
+	started = overallStatus(results) != StatusUnavailable
+	startupMu.Unlock()
+	writeResults(w, results)
+}
+
+func run(checks map[string]Check) map[string]CheckResult {
+	results := make(map[string]CheckResult, len(checks))
+	for name, check := range checks {
+		results[name] = check()
+	}
+	return results
+}
+
+func overallStatus(results map[string]CheckResult) Status {
+	overall := StatusOK
+	for _, result := range results {
+		switch {
+		case result.Status == StatusUnavailable:
+			overall = StatusUnavailable
+		case result.Status == StatusDegraded && overall == StatusOK:
+			overall = StatusDegraded
+		}
+	}
+	return overall
+}
+
+func writeResults(w http.ResponseWriter, results map[string]CheckResult) {
+	overall := overallStatus(results)
+	w.Header().Set("Content-Type", "application/json")
+	if overall == StatusUnavailable {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": overall,
+		"checks": results,
+	})
+}
@@ -17,3 +17,52 @@ func TestHandler(t *testing.T) {
 		t.Errorf("expected %q, got %q", expected, w.Body.String())
 	}
 }
+
+func TestReadyHandler(t *testing.T) {
+	RegisterCheck("degraded-component", func() CheckResult {
+		return CheckResult{Status: StatusDegraded}
+	})
+
+	w := httptest.NewRecorder()
+	ReadyHandler(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected degraded checks to stay ready, got %d", w.Code)
+	}
+
+	RegisterCheck("down-component", func() CheckResult {
+		return CheckResult{Status: StatusUnavailable}
+	})
+
+	w = httptest.NewRecorder()
+	ReadyHandler(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 with an unavailable check, got %d", w.Code)
+	}
+}
+
+func TestStartupHandler(t *testing.T) {
+	status := StatusUnavailable
+	RegisterStartupCheck("hydration", func() CheckResult {
+		return CheckResult{Status: status}
+	})
+
+	w := httptest.NewRecorder()
+	StartupHandler(w, httptest.NewRequest(http.MethodGet, "/startupz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 while starting, got %d", w.Code)
+	}
+
+	status = StatusOK
+	w = httptest.NewRecorder()
+	StartupHandler(w, httptest.NewRequest(http.MethodGet, "/startupz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 once started, got %d", w.Code)
+	}
+
+	status = StatusUnavailable
+	w = httptest.NewRecorder()
+	StartupHandler(w, httptest.NewRequest(http.MethodGet, "/startupz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected startup to stay complete, got %d", w.Code)
+	}
+}
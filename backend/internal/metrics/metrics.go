@@ -111,6 +111,10 @@ func (r *statusRecorder) WriteHeader(code int) {
 	r.ResponseWriter.WriteHeader(code)
 }
 
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // RegisterMetricsEndpoint exposes /metrics
 func RegisterMetricsEndpoint(r chi.Router) {
 	r.Handle("/metrics", promhttp.Handler())
@@ -0,0 +1,351 @@
+// Erebus web UI: browses tenants, their knowledge graph, pipelines and
+// agents through the cognitive API of the serving erebusd.
+(function () {
+  "use strict";
+
+  const API = "/api/cognitive";
+  const LINK_TYPE = 4; // Atom types from LinkType on are links
+  const TYPE_NAMES = ["Node", "Concept", "Predicate", "Variable", "Link", "Inheritance", "Similarity", "Execution", "Evaluation"];
+
+  let tenant = null;
+  let atoms = [];
+  let selectedPipeline = null;
+
+  const $ = (id) => document.getElementById(id);
+
+  async function call(method, path, body) {
+    const opts = { method, headers: {} };
+    if (body !== undefined) {
+      opts.headers["Content-Type"] = "application/json";
+      opts.body = JSON.stringify(body);
+    }
+    const resp = await fetch(API + path, opts);
+    if (!resp.ok) {
+      throw new Error((await resp.text()).trim() || resp.statusText);
+    }
+    return resp.json();
+  }
+
+  function tenantPath(path) {
+    return "/tenants/" + encodeURIComponent(tenant) + path;
+  }
+
+  function showError(err) {
+    const box = $("error");
+    box.textContent = err ? err.message : "";
+    box.hidden = !err;
+  }
+
+  // guard runs an action, reporting its failure
+  function guard(action) {
+    return async function (event) {
+      if (event) event.preventDefault();
+      try {
+        showError(null);
+        await action.apply(this, arguments);
+      } catch (err) {
+        showError(err);
+      }
+    };
+  }
+
+  function cell(row, text, className) {
+    const td = document.createElement("td");
+    td.textContent = text === undefined || text === null ? "" : String(text);
+    if (className) td.className = className;
+    row.appendChild(td);
+    return td;
+  }
+
+  function button(label, onClick) {
+    const b = document.createElement("button");
+    b.type = "button";
+    b.textContent = label;
+    b.addEventListener("click", guard(onClick));
+    return b;
+  }
+
+  // Tenants
+
+  async function loadTenants() {
+    const data = await call("GET", "/tenants");
+    const list = $("tenant-list");
+    list.replaceChildren();
+    for (const id of data.tenants) {
+      const li = document.createElement("li");
+      li.textContent = id;
+      li.classList.toggle("active", id === tenant);
+      li.addEventListener("click", guard(() => selectTenant(id)));
+      list.appendChild(li);
+    }
+    const stats = await call("GET", "/stats");
+    const shards = stats.sharding ? stats.sharding.num_shards : 0;
+    $("summary").textContent = data.count + " tenants, " + shards + " shards";
+  }
+
+  async function selectTenant(id) {
+    tenant = id;
+    selectedPipeline = null;
+    $("empty").hidden = true;
+    $("tenant").hidden = false;
+    await loadTenants();
+    await loadTab(activeTab());
+  }
+
+  // Tabs
+
+  function activeTab() {
+    return document.querySelector(".tabs button.active").dataset.tab;
+  }
+
+  async function loadTab(name) {
+    document.querySelectorAll(".tabs button").forEach((b) => b.classList.toggle("active", b.dataset.tab === name));
+    document.querySelectorAll(".tab").forEach((t) => (t.hidden = t.id !== "tab-" + name));
+    if (name === "graph") await loadGraph();
+    if (name === "pipelines") await loadPipelines();
+    if (name === "agents") await loadAgents();
+  }
+
+  // Graph
+
+  async function loadGraph() {
+    const data = await call("GET", tenantPath("/atoms"));
+    atoms = data.atoms || [];
+    const nodes = atoms.filter((a) => a.type < LINK_TYPE);
+    for (const id of ["link-source", "link-target"]) {
+      const select = $(id);
+      select.replaceChildren();
+      for (const n of nodes) {
+        const option = document.createElement("option");
+        option.value = n.atom_id;
+        option.textContent = n.name;
+        select.appendChild(option);
+      }
+    }
+    drawGraph(nodes, atoms.filter((a) => a.type >= LINK_TYPE && (a.outgoing || []).length === 2));
+  }
+
+  // layout places nodes with a few hundred steps of a force simulation
+  function layout(nodes, edges, width, height) {
+    const pos = new Map();
+    nodes.forEach((n, i) => {
+      const angle = (2 * Math.PI * i) / Math.max(nodes.length, 1);
+      pos.set(n.atom_id, { x: width / 2 + (width / 3) * Math.cos(angle), y: height / 2 + (height / 3) * Math.sin(angle) });
+    });
+    const k = Math.sqrt((width * height) / Math.max(nodes.length, 1)) * 0.6;
+    for (let step = 0; step < 300; step++) {
+      const force = new Map(nodes.map((n) => [n.atom_id, { x: 0, y: 0 }]));
+      for (const a of nodes) {
+        for (const b of nodes) {
+          if (a === b) continue;
+          const pa = pos.get(a.atom_id), pb = pos.get(b.atom_id);
+          const dx = pa.x - pb.x, dy = pa.y - pb.y;
+          const d = Math.max(Math.hypot(dx, dy), 1);
+          const f = force.get(a.atom_id);
+          f.x += (dx / d) * (k * k) / d;
+          f.y += (dy / d) * (k * k) / d;
+        }
+      }
+      for (const e of edges) {
+        const pa = pos.get(e.outgoing[0]), pb = pos.get(e.outgoing[1]);
+        if (!pa || !pb) continue;
+        const dx = pa.x - pb.x, dy = pa.y - pb.y;
+        const d = Math.max(Math.hypot(dx, dy), 1);
+        const fa = force.get(e.outgoing[0]), fb = force.get(e.outgoing[1]);
+        fa.x -= (dx / d) * (d * d) / k; fa.y -= (dy / d) * (d * d) / k;
+        fb.x += (dx / d) * (d * d) / k; fb.y += (dy / d) * (d * d) / k;
+      }
+      const temperature = 10 * (1 - step / 300) + 0.5;
+      for (const n of nodes) {
+        const p = pos.get(n.atom_id), f = force.get(n.atom_id);
+        const m = Math.max(Math.hypot(f.x, f.y), 1);
+        p.x = Math.min(width - 20, Math.max(20, p.x + (f.x / m) * Math.min(m, temperature)));
+        p.y = Math.min(height - 20, Math.max(20, p.y + (f.y / m) * Math.min(m, temperature)));
+      }
+    }
+    return pos;
+  }
+
+  function svg(name, attrs) {
+    const el = document.createElementNS("http://www.w3.org/2000/svg", name);
+    for (const [k, v] of Object.entries(attrs)) el.setAttribute(k, v);
+    return el;
+  }
+
+  function drawGraph(nodes, edges) {
+    const graph = $("graph");
+    const width = graph.clientWidth || 800, height = graph.clientHeight || 500;
+    graph.replaceChildren();
+    graph.setAttribute("viewBox", "0 0 " + width + " " + height);
+    const pos = layout(nodes, edges, width, height);
+
+    for (const e of edges) {
+      const a = pos.get(e.outgoing[0]), b = pos.get(e.outgoing[1]);
+      if (!a || !b) continue;
+      const line = svg("line", { x1: a.x, y1: a.y, x2: b.x, y2: b.y });
+      const title = svg("title", {});
+      title.textContent = TYPE_NAMES[e.type] + " (" + e.truth_value.strength.toFixed(2) + ", " + e.truth_value.confidence.toFixed(2) + ")";
+      line.appendChild(title);
+      graph.appendChild(line);
+    }
+    for (const n of nodes) {
+      const p = pos.get(n.atom_id);
+      const circle = svg("circle", { cx: p.x, cy: p.y, r: 8, "data-id": n.atom_id });
+      circle.addEventListener("click", guard(() => showAtom(n.atom_id)));
+      graph.appendChild(circle);
+      const label = svg("text", { x: p.x + 11, y: p.y + 4 });
+      label.textContent = n.name;
+      graph.appendChild(label);
+    }
+  }
+
+  async function showAtom(atomID) {
+    const atom = await call("GET", tenantPath("/atoms/" + encodeURIComponent(atomID)));
+    const related = atoms.filter((a) => (a.outgoing || []).includes(atomID));
+    const names = new Map(atoms.map((a) => [a.atom_id, a.name]));
+
+    const dl = document.createElement("dl");
+    const add = (term, value) => {
+      const dt = document.createElement("dt");
+      dt.textContent = term;
+      const dd = document.createElement("dd");
+      dd.textContent = value;
+      dl.append(dt, dd);
+    };
+    add("Name", atom.name);
+    add("Type", TYPE_NAMES[atom.type] || atom.type);
+    add("ID", atom.atom_id);
+    add("Truth value", atom.truth_value.strength.toFixed(3) + " strength, " + atom.truth_value.confidence.toFixed(3) + " confidence");
+    add("Attention", "STI " + atom.attention_value.sti + ", LTI " + atom.attention_value.lti);
+    for (const link of related) {
+      add(TYPE_NAMES[link.type] || "Link", link.outgoing.map((id) => names.get(id) || id).join(" → "));
+    }
+    const details = $("atom-details");
+    details.classList.remove("muted");
+    details.replaceChildren(dl);
+  }
+
+  async function search() {
+    const q = $("search").value.trim();
+    const matches = new Set();
+    if (q) {
+      const data = await call("GET", tenantPath("/atoms/search?limit=100&q=" + encodeURIComponent(q)));
+      for (const r of data.results || []) matches.add(r.atom_id);
+    }
+    document.querySelectorAll("#graph circle").forEach((c) => c.classList.toggle("match", matches.has(c.dataset.id)));
+  }
+
+  // Pipelines
+
+  async function loadPipelines() {
+    const data = await call("GET", tenantPath("/pipelines"));
+    const list = $("pipeline-list");
+    list.replaceChildren();
+    for (const p of data.pipelines) {
+      const row = document.createElement("tr");
+      row.classList.toggle("selected", p.id === selectedPipeline);
+      cell(row, p.name || p.id);
+      cell(row, p.state, "state-" + p.state);
+      cell(row, p.stages);
+      const actions = cell(row, "");
+      actions.append(
+        button("Run", async () => {
+          await call("POST", tenantPath("/pipelines/" + encodeURIComponent(p.id) + "/execute?async=true"));
+          selectedPipeline = p.id;
+          await loadPipelines();
+        }),
+        " ",
+        button("Executions", async () => {
+          selectedPipeline = p.id;
+          await loadPipelines();
+        })
+      );
+      list.appendChild(row);
+    }
+    await loadExecutions();
+  }
+
+  async function loadExecutions() {
+    const visible = selectedPipeline !== null;
+    $("executions").hidden = !visible;
+    $("executions-title").hidden = !visible;
+    if (!visible) return;
+    const data = await call("GET", tenantPath("/pipelines/" + encodeURIComponent(selectedPipeline) + "/executions"));
+    $("executions-title").textContent = "Executions of " + selectedPipeline;
+    const list = $("execution-list");
+    list.replaceChildren();
+    for (const e of (data.executions || []).slice().reverse()) {
+      const row = document.createElement("tr");
+      cell(row, e.id);
+      cell(row, e.state, "state-" + e.state);
+      cell(row, new Date(e.queued_at).toLocaleString());
+      cell(row, e.retries);
+      cell(row, e.error);
+      list.appendChild(row);
+    }
+  }
+
+  // Agents
+
+  async function loadAgents() {
+    const [data, health] = await Promise.all([call("GET", tenantPath("/agents")), call("GET", tenantPath("/agents/health"))]);
+    const byID = new Map((health.agents || []).map((h) => [h.agent_id, h]));
+    const list = $("agent-list");
+    list.replaceChildren();
+    for (const a of data.agents) {
+      const h = byID.get(a.id) || { state: "healthy" };
+      const row = document.createElement("tr");
+      cell(row, a.name || a.id);
+      cell(row, a.state);
+      cell(row, h.state, "state-" + h.state);
+      cell(row, a.run_count);
+      cell(row, a.avg_time_ms);
+      cell(row, h.last_error);
+      const actions = cell(row, "");
+      if (h.state === "quarantined" || h.state === "backoff") {
+        actions.appendChild(
+          button("Release", async () => {
+            await call("POST", tenantPath("/agents/" + encodeURIComponent(a.id) + "/release"));
+            await loadAgents();
+          })
+        );
+      }
+      list.appendChild(row);
+    }
+  }
+
+  // Wiring
+
+  $("tenant-form").addEventListener("submit", guard(async () => {
+    const id = $("tenant-id").value.trim();
+    await call("POST", "/tenants/" + encodeURIComponent(id) + "/init");
+    $("tenant-id").value = "";
+    await selectTenant(id);
+  }));
+  $("concept-form").addEventListener("submit", guard(async () => {
+    await call("POST", tenantPath("/concepts"), { name: $("concept-name").value.trim() });
+    $("concept-name").value = "";
+    await loadGraph();
+  }));
+  $("link-add").addEventListener("click", guard(async () => {
+    await call("POST", tenantPath("/links/inheritance"), { source_id: $("link-source").value, target_id: $("link-target").value });
+    await loadGraph();
+  }));
+  $("inference-run").addEventListener("click", guard(async () => {
+    await call("POST", tenantPath("/inference"), { max_iterations: 10 });
+    await loadGraph();
+  }));
+  $("search").addEventListener("input", guard(search));
+  $("pipeline-form").addEventListener("submit", guard(async () => {
+    await call("POST", tenantPath("/pipelines"), { use_default: true });
+    await loadPipelines();
+  }));
+  document.querySelectorAll(".tabs button").forEach((b) => b.addEventListener("click", guard(() => loadTab(b.dataset.tab))));
+
+  // Pipelines and agents change on their own; keep their tabs current
+  setInterval(guard(async () => {
+    if (tenant && !document.hidden && activeTab() !== "graph") await loadTab(activeTab());
+  }), 5000);
+
+  guard(loadTenants)();
+})();
@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// assets holds the web UI, built into the binary so that evaluating Erebus
+// needs no separate frontend deployment
+//
+//go:embed static
+var assets embed.FS
+
+// Handler serves the web UI under prefix, e.g. "/ui". The UI talks to the
+// cognitive API of the same server.
+func Handler(prefix string) http.Handler {
+	static, err := fs.Sub(assets, "static")
+	if err != nil {
+		panic(err)
+	}
+	files := http.StripPrefix(prefix, http.FileServer(http.FS(static)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == prefix {
+			http.Redirect(w, r, prefix+"/", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		files.ServeHTTP(w, r)
+	})
+}
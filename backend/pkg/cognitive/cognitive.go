@@ -0,0 +1,281 @@
+// Package cognitive embeds the Erebus cognitive engine in a Go process,
+// without running erebusd.
+//
+// An Engine holds the AtomSpace, inference engines and agents of any
+// number of tenants:
+//
+//	engine, err := cognitive.New(cognitive.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	defer engine.Close()
+//
+//	engine.InitializeTenant("acme")
+//	cat, _ := engine.CreateConceptNode("cat", "acme")
+//	animal, _ := engine.CreateConceptNode("animal", "acme")
+//	engine.CreateInheritanceLink(cat.GetID(), animal.GetID(), "acme")
+//	inferred, err := engine.RunInference(ctx, "acme", 10)
+//
+// Embedding has no global side effects: no Prometheus metrics are
+// registered unless Options.MetricsRegisterer is set, and no HTTP routes
+// exist unless Handler is mounted.
+//
+// The API of this package is stable. The internal packages it wraps are
+// not, and are only reachable through the HTTP API.
+package cognitive
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	core "github.com/Avik2024/erebus/backend/internal/cognitive"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/agents"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/api"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/atomspace"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/persistence"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/schemas"
+	"github.com/Avik2024/erebus/backend/internal/cognitive/sharding"
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type (
+	// Config configures the engine's shards, workers and subsystems
+	Config = core.Config
+
+	// Atom is a node or link of the AtomSpace
+	Atom = atomspace.Atom
+	// AtomType is the type of an atom
+	AtomType = atomspace.AtomType
+	// TruthValue is the probabilistic truth of an atom
+	TruthValue = atomspace.TruthValue
+	// AttentionValue is the importance of an atom
+	AttentionValue = atomspace.AttentionValue
+	// SearchMode selects how atom names are matched
+	SearchMode = atomspace.SearchMode
+	// SearchResult is an atom matched by a name search
+	SearchResult = atomspace.SearchResult
+
+	// KeyProvider wraps the keys encrypting snapshots, typically with a KMS
+	KeyProvider = persistence.KeyProvider
+
+	// Schema binds a Go function to the GroundedSchemaNode of its name
+	Schema = schemas.Schema
+	// SchemaFunc is the Go function of a schema
+	SchemaFunc = schemas.Func
+	// SchemaCall is one execution of a schema by an ExecutionLink
+	SchemaCall = schemas.Call
+)
+
+// Atom types
+const (
+	ConceptNodeType     = atomspace.ConceptNodeType
+	PredicateNodeType   = atomspace.PredicateNodeType
+	VariableNodeType    = atomspace.VariableNodeType
+	InheritanceLinkType = atomspace.InheritanceLinkType
+	SimilarityLinkType  = atomspace.SimilarityLinkType
+	ExecutionLinkType   = atomspace.ExecutionLinkType
+	EvaluationLinkType  = atomspace.EvaluationLinkType
+
+	NumberNodeType            = atomspace.NumberNodeType
+	GroundedPredicateNodeType = atomspace.GroundedPredicateNodeType
+	GroundedSchemaNodeType    = atomspace.GroundedSchemaNodeType
+)
+
+// Search modes
+const (
+	SearchModeAuto      = atomspace.SearchModeAuto
+	SearchModePrefix    = atomspace.SearchModePrefix
+	SearchModeSubstring = atomspace.SearchModeSubstring
+	SearchModeFuzzy     = atomspace.SearchModeFuzzy
+)
+
+// DefaultConfig returns the configuration erebusd runs with
+func DefaultConfig() *Config {
+	return core.DefaultConfig()
+}
+
+// NewLocalKeyProvider returns a key provider keeping per-tenant keys in
+// dir, sealed with a 32-byte master key. An empty dir keeps them in memory.
+func NewLocalKeyProvider(master []byte, dir string) (KeyProvider, error) {
+	return persistence.NewLocalKeyProvider(master, dir)
+}
+
+// NewNode returns a node for AddAtom, identified by its type and name
+func NewNode(name, tenantID string, atomType AtomType) Atom {
+	return atomspace.NewNode(atomspace.GenerateAtomID(atomType, name, nil), name, tenantID, atomType)
+}
+
+// Batch collects nodes for a bulk Import, allocated together rather than
+// one by one
+type Batch struct {
+	arena *atomspace.Arena
+	atoms []Atom
+}
+
+// NewBatch returns a batch with room for size nodes; it grows past that
+func NewBatch(size int) *Batch {
+	return &Batch{arena: atomspace.NewArena(size, 0, 0), atoms: make([]Atom, 0, size)}
+}
+
+// AddNode adds a node, identified by its type and name, to the batch
+func (b *Batch) AddNode(name, tenantID string, atomType AtomType) Atom {
+	node := b.arena.NewNode(atomspace.GenerateAtomID(atomType, name, nil), name, tenantID, atomType)
+	b.atoms = append(b.atoms, node)
+	return node
+}
+
+// Options configures an embedded engine
+type Options struct {
+	// Config of the engine; nil uses DefaultConfig
+	Config *Config
+	// MetricsRegisterer registers the erebus_agent_* supervision metrics
+	// and the erebus_shard_* heat metrics. Nil registers no metrics.
+	MetricsRegisterer prometheus.Registerer
+}
+
+// Engine is an embedded cognitive engine. It is safe for concurrent use.
+type Engine struct {
+	engine *core.CognitiveEngine
+}
+
+// New starts an engine
+func New(opts Options) (*Engine, error) {
+	cfg := DefaultConfig()
+	if opts.Config != nil {
+		copied := *opts.Config
+		cfg = &copied
+	}
+	if opts.MetricsRegisterer != nil {
+		m, err := agents.NewPrometheusMetrics(opts.MetricsRegisterer)
+		if err != nil {
+			return nil, err
+		}
+		cfg.AgentMetrics = m
+		sm, err := sharding.NewPrometheusMetrics(opts.MetricsRegisterer)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ShardMetrics = sm
+	}
+	return &Engine{engine: core.NewCognitiveEngine(cfg)}, nil
+}
+
+// Close stops the engine's workers and agents
+func (e *Engine) Close() error {
+	return e.engine.Close()
+}
+
+// InitializeTenant creates a tenant's inference engine and agents. It must
+// be called before running inference for the tenant.
+func (e *Engine) InitializeTenant(tenantID string) error {
+	return e.engine.InitializeTenant(tenantID)
+}
+
+// AddAtom adds an atom to its tenant's AtomSpace
+func (e *Engine) AddAtom(atom Atom) error {
+	return e.engine.AddAtom(atom)
+}
+
+// Import adds the atoms of a batch in per-shard bulk writes, far faster
+// than AddAtom per atom for large loads. Atoms that already exist are
+// skipped; the number imported is returned.
+func (e *Engine) Import(b *Batch) (int, error) {
+	return e.engine.ImportAtoms(b.atoms)
+}
+
+// CreateConceptNode adds a concept node to a tenant's AtomSpace
+func (e *Engine) CreateConceptNode(name, tenantID string) (Atom, error) {
+	return e.engine.CreateConceptNode(name, tenantID)
+}
+
+// CreateInheritanceLink adds an inheritance link between two of a
+// tenant's atoms
+func (e *Engine) CreateInheritanceLink(sourceID, targetID, tenantID string) (Atom, error) {
+	return e.engine.CreateInheritanceLink(sourceID, targetID, tenantID)
+}
+
+// GetAtom returns one of a tenant's atoms
+func (e *Engine) GetAtom(atomID, tenantID string) (Atom, error) {
+	return e.engine.GetAtom(atomID, tenantID)
+}
+
+// QueryAtoms returns a tenant's atoms matching filter; nil matches all
+func (e *Engine) QueryAtoms(tenantID string, filter func(Atom) bool) []Atom {
+	return e.engine.QueryAtoms(tenantID, filter)
+}
+
+// SearchAtoms returns up to limit of a tenant's atoms by name, best first
+func (e *Engine) SearchAtoms(tenantID, query string, mode SearchMode, limit int) []SearchResult {
+	return e.engine.SearchAtoms(tenantID, query, mode, limit)
+}
+
+// UpdateAtom changes one of a tenant's atoms through updater
+func (e *Engine) UpdateAtom(atomID, tenantID string, updater func(Atom) error) error {
+	return e.engine.UpdateAtom(atomID, tenantID, updater)
+}
+
+// DeleteAtom removes one of a tenant's atoms
+func (e *Engine) DeleteAtom(atomID, tenantID string) error {
+	return e.engine.DeleteAtom(atomID, tenantID)
+}
+
+// RunInference derives new atoms for a tenant and returns them
+func (e *Engine) RunInference(ctx context.Context, tenantID string, maxIterations int) ([]Atom, error) {
+	return e.engine.RunInference(ctx, tenantID, maxIterations)
+}
+
+// RegisterSchema binds a Go function to a GroundedSchemaNode. Each tenant's
+// execution agent, enabled by AddExecution or the HTTP API, calls it for
+// the ExecutionLink(GroundedSchemaNode, arguments...) atoms of the tenant.
+func (e *Engine) RegisterSchema(s Schema) error {
+	return e.engine.RegisterSchema(s)
+}
+
+// UnregisterSchema removes a schema registered by RegisterSchema
+func (e *Engine) UnregisterSchema(name string) error {
+	return e.engine.UnregisterSchema(name)
+}
+
+// AddExecution adds a call of a registered schema with existing atoms as
+// its arguments
+func (e *Engine) AddExecution(tenantID, schema string, argumentIDs ...string) (Atom, error) {
+	args := make([]core.GroundedArgument, len(argumentIDs))
+	for i, id := range argumentIDs {
+		args[i].AtomID = id
+	}
+	return e.engine.AddExecution(tenantID, schema, args)
+}
+
+// ExecuteSchemas executes a tenant's pending calls of registered schemas
+// immediately, rather than on the execution agent's next run
+func (e *Engine) ExecuteSchemas(ctx context.Context, tenantID string) error {
+	_, err := e.engine.ExecuteSchemas(ctx, tenantID)
+	return err
+}
+
+// Snapshot writes a tenant's atoms to w, encrypted if the configuration
+// has a key provider
+func (e *Engine) Snapshot(tenantID string, w io.Writer) error {
+	return e.engine.SnapshotTenant(tenantID, w)
+}
+
+// Restore loads the atoms of a snapshot and returns how many were restored
+func (e *Engine) Restore(r io.Reader) (int, error) {
+	return e.engine.RestoreSnapshot(r)
+}
+
+// Stats returns engine statistics, with those of a tenant if tenantID is
+// not empty
+func (e *Engine) Stats(tenantID string) map[string]interface{} {
+	return e.engine.GetStats(tenantID)
+}
+
+// Handler returns the engine's HTTP API, served under /api/cognitive as
+// by erebusd, for services that want to expose it
+func (e *Engine) Handler() http.Handler {
+	r := chi.NewRouter()
+	api.NewCognitiveHandler(e.engine).RegisterRoutes(r)
+	return r
+}
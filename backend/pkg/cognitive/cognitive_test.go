@@ -0,0 +1,109 @@
+package cognitive
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestEmbeddedEngine(t *testing.T) {
+	engine, err := New(Options{})
+	if err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	defer engine.Close()
+
+	tenantID := "acme"
+	if err := engine.InitializeTenant(tenantID); err != nil {
+		t.Fatalf("Failed to initialize tenant: %v", err)
+	}
+	cat, _ := engine.CreateConceptNode("cat", tenantID)
+	mammal, _ := engine.CreateConceptNode("mammal", tenantID)
+	animal := NewNode("animal", tenantID, ConceptNodeType)
+	if err := engine.AddAtom(animal); err != nil {
+		t.Fatalf("Failed to add atom: %v", err)
+	}
+	engine.CreateInheritanceLink(cat.GetID(), mammal.GetID(), tenantID)
+	engine.CreateInheritanceLink(mammal.GetID(), animal.GetID(), tenantID)
+
+	if _, err := engine.RunInference(context.Background(), tenantID, 5); err != nil {
+		t.Fatalf("Inference failed: %v", err)
+	}
+	if results := engine.SearchAtoms(tenantID, "mam", SearchModePrefix, 10); len(results) != 1 {
+		t.Errorf("Expected to find mammal, got %d results", len(results))
+	}
+
+	batch := NewBatch(2)
+	batch.AddNode("dog", tenantID, ConceptNodeType)
+	batch.AddNode("cat", tenantID, ConceptNodeType)
+	if n, err := engine.Import(batch); err != nil || n != 1 {
+		t.Errorf("Expected the new node of the batch to be imported, got %d: %v", n, err)
+	}
+
+	var snapshot bytes.Buffer
+	if err := engine.Snapshot(tenantID, &snapshot); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	restored, _ := New(Options{})
+	defer restored.Close()
+	if n, err := restored.Restore(&snapshot); err != nil || n != len(engine.QueryAtoms(tenantID, nil)) {
+		t.Errorf("Expected every atom to be restored, got %d: %v", n, err)
+	}
+
+	w := httptest.NewRecorder()
+	engine.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/cognitive/tenants/acme/atoms/"+cat.GetID(), nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected the HTTP API to serve the atom, got %d", w.Code)
+	}
+}
+
+func TestSchemas(t *testing.T) {
+	engine, _ := New(Options{})
+	defer engine.Close()
+	engine.InitializeTenant("acme")
+
+	var restarted []string
+	err := engine.RegisterSchema(Schema{Name: "restart", Arity: 1, Func: func(ctx context.Context, call SchemaCall) (Atom, error) {
+		restarted = append(restarted, call.Arguments[0].GetName())
+		return nil, nil
+	}})
+	if err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+	pod, _ := engine.CreateConceptNode("pod/web-1", "acme")
+	if _, err := engine.AddExecution("acme", "restart", pod.GetID()); err != nil {
+		t.Fatalf("Failed to add execution: %v", err)
+	}
+	if err := engine.ExecuteSchemas(context.Background(), "acme"); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	if len(restarted) != 1 || restarted[0] != "pod/web-1" {
+		t.Errorf("Expected the schema called once with the pod, got %v", restarted)
+	}
+}
+
+func TestMetricsRegistration(t *testing.T) {
+	engine, _ := New(Options{})
+	engine.Close()
+
+	// Nothing was registered globally, so the host may use the same names
+	c := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "erebus_agent_health", Help: "host"}, []string{"tenant", "agent"})
+	if err := prometheus.DefaultRegisterer.Register(c); err != nil {
+		t.Errorf("Expected no metrics to be registered by default, got %v", err)
+	}
+	prometheus.DefaultRegisterer.Unregister(c)
+
+	reg := prometheus.NewRegistry()
+	engine, err := New(Options{MetricsRegisterer: reg})
+	if err != nil {
+		t.Fatalf("Failed to start engine with metrics: %v", err)
+	}
+	defer engine.Close()
+	if _, err := New(Options{MetricsRegisterer: reg}); err == nil {
+		t.Error("Expected a second engine on the same registry to be rejected")
+	}
+}